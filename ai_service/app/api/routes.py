@@ -2,9 +2,10 @@
 
 import time
 
-from fastapi import APIRouter, HTTPException, status
+from fastapi import APIRouter, Depends, HTTPException, status
 
 from app.core.logging import get_logger
+from app.core.service_auth import verify_service_signature
 from app.models.requests import AnalyzeBlueprintRequest, GenerateBidRequest
 from app.models.responses import AnalyzeBlueprintResponse, GenerateBidResponse
 from app.services.bid_service import BidService
@@ -16,6 +17,15 @@ logger = get_logger(__name__)
 
 router = APIRouter()
 
+# Scope wording for bids on existing buildings, matching the demolition and
+# protection line items the backend prices for these project types
+PROJECT_TYPE_GUIDANCE = {
+    "renovation": "Renovation of an existing building: describe selective demolition, "
+    "dust protection of occupied areas, and work around existing conditions in the scope",
+    "addition": "Addition to an existing building: describe the new construction and the "
+    "tie-in to existing structure, including demolition and protection at the connection",
+}
+
 
 @router.get("/health")
 async def health() -> dict[str, str]:
@@ -30,6 +40,7 @@ async def health() -> dict[str, str]:
 
 @router.post(
     "/analyze-blueprint",
+    dependencies=[Depends(verify_service_signature)],
     response_model=AnalyzeBlueprintResponse,
     status_code=status.HTTP_200_OK,
 )
@@ -92,10 +103,25 @@ async def analyze_blueprint(request: AnalyzeBlueprintRequest) -> AnalyzeBlueprin
         else:
             vision_bytes = file_bytes
 
+        # On re-analysis, ask the model to keep the previous room names
+        context = request.options
+        if request.prior_analysis:
+            logger.info(
+                "using_prior_analysis",
+                rooms=len(request.prior_analysis.get("rooms") or []),
+                truncated=bool(request.prior_analysis.get("truncated")),
+            )
+            context = {
+                **(request.options or {}),
+                "prior_analysis": request.prior_analysis,
+                "naming_guidance": "Reuse the room names from prior_analysis for rooms "
+                "that are unchanged",
+            }
+
         analysis = await vision_service.analyze_blueprint(
             vision_bytes,
             ocr_result.raw_text,
-            request.options,
+            context,
         )
 
         # Calculate processing time
@@ -137,6 +163,7 @@ async def analyze_blueprint(request: AnalyzeBlueprintRequest) -> AnalyzeBlueprin
 
 @router.post(
     "/generate-bid",
+    dependencies=[Depends(verify_service_signature)],
     response_model=GenerateBidResponse,
     status_code=status.HTTP_200_OK,
 )
@@ -168,6 +195,11 @@ async def generate_bid(request: GenerateBidRequest) -> GenerateBidResponse:
             "project_id": request.project_id,
             "blueprint_id": request.blueprint_id,
         }
+        if request.project_type:
+            project_info["project_type"] = request.project_type
+            guidance = PROJECT_TYPE_GUIDANCE.get(request.project_type)
+            if guidance:
+                project_info["project_type_guidance"] = guidance
 
         # Generate bid
         logger.info("generating_bid_package")
@@ -46,6 +46,13 @@ class Settings(BaseSettings):
     # Redis (for caching)
     redis_url: str = Field(default="redis://redis:6379/0", description="Redis URL")
 
+    # Service-to-service auth: requests from the Go backend are signed with
+    # this secret. During a rotation the previous secret is accepted too.
+    service_secret: str = Field(default="", description="Shared request signing secret")
+    service_secret_previous: str = Field(
+        default="", description="Previous signing secret, accepted during rotation"
+    )
+
     # Sentry
     sentry_dsn: str = Field(default="", description="Sentry DSN for error tracking")
 
@@ -0,0 +1,77 @@
+"""Verification of requests signed by the Go backend.
+
+The backend sends the Unix time in X-Service-Timestamp and an HMAC-SHA256 of
+"<timestamp>.<body>" in X-Service-Signature. See backend/internal/serviceauth.
+"""
+
+import hashlib
+import hmac
+import time
+
+from fastapi import HTTPException, Request, status
+
+from app.core.config import get_settings
+from app.core.logging import get_logger
+
+logger = get_logger(__name__)
+
+HEADER_TIMESTAMP = "X-Service-Timestamp"
+HEADER_SIGNATURE = "X-Service-Signature"
+
+# How far a request's timestamp may be from our clock before it is a replay
+MAX_SKEW_SECONDS = 5 * 60
+
+
+def sign(secret: str, timestamp: str, body: bytes) -> str:
+    """Return the hex signature of a request body sent at timestamp."""
+    message = timestamp.encode() + b"." + body
+    return hmac.new(secret.encode(), message, hashlib.sha256).hexdigest()
+
+
+def verify(
+    secrets: list[str],
+    timestamp: str | None,
+    signature: str | None,
+    body: bytes,
+    now: float | None = None,
+) -> str | None:
+    """Check a signature against the active secrets.
+
+    Returns None when the request is valid, otherwise the reason it is not.
+    """
+    if not timestamp or not signature:
+        return "missing signature"
+    try:
+        sent = int(timestamp)
+    except ValueError:
+        return "invalid timestamp"
+    if abs((now if now is not None else time.time()) - sent) > MAX_SKEW_SECONDS:
+        return "stale timestamp"
+    for secret in secrets:
+        if secret and hmac.compare_digest(signature, sign(secret, timestamp, body)):
+            return None
+    return "invalid signature"
+
+
+async def verify_service_signature(request: Request) -> None:
+    """FastAPI dependency rejecting unsigned or stale requests with 401.
+
+    Verification is skipped when no service secret is configured.
+    """
+    settings = get_settings()
+    secrets = [settings.service_secret, settings.service_secret_previous]
+    if not settings.service_secret:
+        return
+
+    reason = verify(
+        secrets,
+        request.headers.get(HEADER_TIMESTAMP),
+        request.headers.get(HEADER_SIGNATURE),
+        await request.body(),
+    )
+    if reason is not None:
+        logger.warning("service_request_rejected", path=request.url.path, reason=reason)
+        raise HTTPException(
+            status_code=status.HTTP_401_UNAUTHORIZED,
+            detail="Invalid service signature",
+        )
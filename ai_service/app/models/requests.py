@@ -10,6 +10,11 @@ class AnalyzeBlueprintRequest(BaseModel):
     s3_key: str = Field(..., description="S3 key where blueprint is stored")
     project_name: str | None = Field(None, description="Optional project name")
     options: dict | None = Field(None, description="Optional analysis options")
+    prior_analysis: dict | None = Field(
+        None,
+        description="Rooms and openings from the previous analysis, sent on re-analysis "
+        "so room names stay consistent",
+    )
 
 
 class GenerateBidRequest(BaseModel):
@@ -21,3 +26,8 @@ class GenerateBidRequest(BaseModel):
     pricing_rules: dict | None = Field(None, description="Optional pricing rules")
     company_info: dict | None = Field(None, description="Optional company information")
     markup_percentage: float = Field(default=20.0, description="Markup percentage", ge=0, le=100)
+    project_type: str | None = Field(
+        None,
+        description="new_construction, renovation or addition; renovations and additions "
+        "are priced with demolition and protection",
+    )
@@ -75,6 +75,7 @@ class LineItem(BaseModel):
     unit: str = Field(..., description="Unit of measurement")
     unit_cost: float = Field(..., description="Cost per unit")
     total: float = Field(..., description="Total cost for line item")
+    notes: str | None = Field(None, max_length=500, description="Assumptions behind this item")
 
 
 class GenerateBidResponse(BaseModel):
@@ -120,7 +120,7 @@ export default function BlueprintUploadScreen() {
       // Convert file URI to blob for upload
       const response = await fetch(selectedFile.uri);
       const blob = await response.blob();
-      await blueprintsApi.uploadToS3(uploadUrlData.upload_url, blob);
+      await blueprintsApi.uploadToS3(uploadUrlData.upload_url, blob, uploadUrlData.upload_headers);
 
       setUploadProgress(80);
 
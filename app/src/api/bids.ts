@@ -1,9 +1,9 @@
 import apiClient from './client';
-import { Bid, GenerateBidRequest, PricingSummary } from '../types';
+import { Bid, BidData, BidListParams, BidPDFResponse, GenerateBidRequest, ListPage, PricingSummary, UpdateBidStatusRequest } from '../types';
 
 export const bidsApi = {
-  getProjectBids: async (projectId: string): Promise<Bid[]> => {
-    const response = await apiClient.get<Bid[]>(`/projects/${projectId}/bids`);
+  getProjectBids: async (projectId: string, params?: BidListParams): Promise<ListPage<Bid>> => {
+    const response = await apiClient.get<ListPage<Bid>>(`/projects/${projectId}/bids`, { params });
     return response.data;
   },
 
@@ -12,13 +12,23 @@ export const bidsApi = {
     return response.data;
   },
 
+  updateBid: async (bidId: string, data: BidData): Promise<Bid> => {
+    const response = await apiClient.put<Bid>(`/bids/${bidId}`, data);
+    return response.data;
+  },
+
+  updateBidStatus: async (bidId: string, data: UpdateBidStatusRequest): Promise<Bid> => {
+    const response = await apiClient.patch<Bid>(`/bids/${bidId}/status`, data);
+    return response.data;
+  },
+
   generateBid: async (projectId: string, data: GenerateBidRequest): Promise<Bid> => {
     const response = await apiClient.post<Bid>(`/projects/${projectId}/generate-bid`, data);
     return response.data;
   },
 
-  getBidPDF: async (bidId: string): Promise<{ pdf_url: string }> => {
-    const response = await apiClient.get<{ pdf_url: string }>(`/bids/${bidId}/pdf`);
+  getBidPDF: async (bidId: string): Promise<BidPDFResponse> => {
+    const response = await apiClient.get<BidPDFResponse>(`/bids/${bidId}/pdf`);
     return response.data;
   },
 
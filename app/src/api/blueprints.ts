@@ -7,6 +7,8 @@ import {
   TriggerAnalysisResponse,
   AnalysisResult,
   TakeoffSummary,
+  TakeoffAdjustment,
+  UpdateTakeoffAdjustmentsResponse,
 } from '../types';
 import axios from 'axios';
 
@@ -32,9 +34,14 @@ export const blueprintsApi = {
     return response.data;
   },
 
-  uploadToS3: async (uploadUrl: string, file: Blob | File): Promise<void> => {
+  uploadToS3: async (
+    uploadUrl: string,
+    file: Blob | File,
+    uploadHeaders?: Record<string, string>
+  ): Promise<void> => {
     await axios.put(uploadUrl, file, {
       headers: {
+        ...uploadHeaders,
         'Content-Type': file.type,
       },
     });
@@ -65,6 +72,17 @@ export const blueprintsApi = {
     return response.data;
   },
 
+  updateTakeoffAdjustments: async (
+    blueprintId: string,
+    adjustments: TakeoffAdjustment[]
+  ): Promise<UpdateTakeoffAdjustmentsResponse> => {
+    const response = await apiClient.put<UpdateTakeoffAdjustmentsResponse>(
+      `/blueprints/${blueprintId}/takeoff-adjustments`,
+      { adjustments }
+    );
+    return response.data;
+  },
+
   delete: async (id: string): Promise<void> => {
     await apiClient.delete(`/blueprints/${id}`);
   },
@@ -6,7 +6,7 @@ const TOKEN_KEY = 'auth_token';
 
 // Create axios instance
 const apiClient = axios.create({
-  baseURL: API_BASE_URL,
+  baseURL: `${API_BASE_URL}/v1`,
   timeout: 30000,
   headers: {
     'Content-Type': 'application/json',
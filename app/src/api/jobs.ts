@@ -11,4 +11,9 @@ export const jobsApi = {
     const response = await apiClient.get<Job[]>(`/blueprints/${blueprintId}/jobs`);
     return response.data;
   },
+
+  retry: async (id: string): Promise<Job> => {
+    const response = await apiClient.post<Job>(`/jobs/${id}/retry`);
+    return response.data;
+  },
 };
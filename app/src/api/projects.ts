@@ -1,5 +1,5 @@
 import apiClient from './client';
-import { Project, CreateProjectRequest } from '../types';
+import { Project, CreateProjectRequest, UpdateProjectRequest } from '../types';
 
 export const projectsApi = {
   getAll: async (): Promise<Project[]> => {
@@ -17,7 +17,7 @@ export const projectsApi = {
     return response.data;
   },
 
-  update: async (id: string, data: Partial<CreateProjectRequest>): Promise<Project> => {
+  update: async (id: string, data: UpdateProjectRequest): Promise<Project> => {
     const response = await apiClient.put<Project>(`/projects/${id}`, data);
     return response.data;
   },
@@ -11,8 +11,8 @@ export const useBids = (projectId: string) => {
     try {
       setIsLoading(true);
       setError(null);
-      const bids = await bidsApi.getProjectBids(projectId);
-      setData(bids);
+      const page = await bidsApi.getProjectBids(projectId);
+      setData(page.items);
     } catch (err) {
       setError(err as Error);
     } finally {
@@ -1,6 +1,6 @@
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
 import { projectsApi } from '../api/projects';
-import { CreateProjectRequest } from '../types';
+import { CreateProjectRequest, UpdateProjectRequest } from '../types';
 
 export const useProjects = () => {
   return useQuery({
@@ -32,7 +32,7 @@ export const useUpdateProject = () => {
   const queryClient = useQueryClient();
 
   return useMutation({
-    mutationFn: ({ id, data }: { id: string; data: Partial<CreateProjectRequest> }) =>
+    mutationFn: ({ id, data }: { id: string; data: UpdateProjectRequest }) =>
       projectsApi.update(id, data),
     onSuccess: (_, variables) => {
       queryClient.invalidateQueries({ queryKey: ['projects'] });
@@ -11,10 +11,61 @@ export interface User {
   email: string;
   name?: string;
   company_name?: string;
+  tax_settings?: TaxSettings;
+  impact_thresholds?: ImpactThresholds;
+  pdf_layout?: PDFLayout;
   created_at: string;
   updated_at: string;
 }
 
+// Tax Types
+export type TaxBasis = 'materials_only' | 'labor_and_materials' | 'total_contract';
+
+export interface TaxRule {
+  rate: number; // Percent
+  basis: TaxBasis;
+}
+
+export interface TaxSettings {
+  default_rate: number;
+  basis: TaxBasis;
+  region_overrides?: Record<string, TaxRule>;
+}
+
+export interface TaxSummary {
+  rate: number;
+  basis: TaxBasis;
+  region?: string;
+  taxable_amount: number;
+  exempt?: boolean;
+  note?: string;
+}
+
+// Bid Document Layout Types
+export type PDFSection =
+  | 'cover'
+  | 'project_info'
+  | 'scope'
+  | 'line_items'
+  | 'trade_breakdown'
+  | 'cost_summary'
+  | 'inclusions'
+  | 'exclusions'
+  | 'schedule'
+  | 'terms'
+  | 'signature';
+
+export interface PDFSectionLayout {
+  section: PDFSection;
+  enabled: boolean;
+  title?: string; // Replaces the default heading
+}
+
+// Sections in print order; sections left out are not printed
+export interface PDFLayout {
+  sections: PDFSectionLayout[];
+}
+
 export interface LoginRequest {
   email: string;
   password: string;
@@ -26,13 +77,14 @@ export interface LoginResponse {
 }
 
 // Project Types
-export type ProjectStatus = 'active' | 'completed' | 'archived';
+export type ProjectStatus = 'draft' | 'active' | 'completed' | 'archived';
 
 export interface Project {
   id: string;
   name: string;
   description?: string;
   status: ProjectStatus;
+  tax_exempt: boolean;
   created_at: string;
   updated_at: string;
   user_id: string;
@@ -41,6 +93,20 @@ export interface Project {
 export interface CreateProjectRequest {
   name: string;
   description?: string;
+  status?: ProjectStatus; // draft or active; defaults to draft
+  budget?: number;
+  region?: string;
+  client_name?: string;
+}
+
+// Fields left out are unchanged. DELETE archives a project; setting the
+// status back to active restores it.
+export interface UpdateProjectRequest {
+  name?: string;
+  description?: string;
+  status?: ProjectStatus;
+  region?: string;
+  client_name?: string;
 }
 
 // Blueprint Types
@@ -60,10 +126,24 @@ export interface Blueprint {
   version: number;
   parent_blueprint_id?: string;
   is_latest: boolean;
+  takeoff_adjustments?: TakeoffAdjustment[];
+  // Read from the file when the upload completes; absent when unreadable
+  page_count?: number;
+  page_sizes?: BlueprintPageSize[];
+  document_title?: string;
+  document_created_at?: string;
   created_at: string;
   updated_at: string;
 }
 
+export interface BlueprintPageSize {
+  page: number;
+  width: number;
+  height: number;
+  // Points for PDF pages, pixels for images
+  unit: 'pt' | 'px';
+}
+
 export interface UploadUrlRequest {
   filename: string;
   content_type: string;
@@ -73,6 +153,8 @@ export interface UploadUrlResponse {
   blueprint_id: string;
   upload_url: string;
   expires_at: string;
+  // Signed into upload_url; must be sent with the upload
+  upload_headers?: Record<string, string>;
 }
 
 export interface CompleteUploadRequest {
@@ -148,12 +230,16 @@ export interface Material {
 export interface TakeoffSummary {
   total_area: number;
   total_perimeter: number;
+  wall_area: number;
+  ceiling_height: number;
   opening_counts: Record<string, number>;
   fixture_counts: Record<string, number>;
   room_count: number;
   room_breakdown: RoomSummary[];
   opening_breakdown: OpeningSummary[];
   fixture_breakdown: FixtureSummary[];
+  adjustments?: AppliedTakeoffAdjustment[];
+  orphaned_adjustments?: TakeoffAdjustment[];
 }
 
 export interface RoomSummary {
@@ -161,18 +247,51 @@ export interface RoomSummary {
   room_type?: string;
   area: number;
   dimensions: string;
+  adjusted?: AdjustedQuantity;
 }
 
 export interface OpeningSummary {
   opening_type: string;
   count: number;
   size: string;
+  adjusted?: AdjustedQuantity;
 }
 
 export interface FixtureSummary {
   fixture_type: string;
   category: string;
   count: number;
+  adjusted?: AdjustedQuantity;
+}
+
+// Takeoff adjustments: keys are a room name, "<opening type>:<size>" or
+// "<fixture category>:<fixture type>"; room areas are in square feet
+export type TakeoffEntity = 'room' | 'opening' | 'fixture';
+
+export interface TakeoffAdjustment {
+  entity_type: TakeoffEntity;
+  key: string;
+  field?: 'area' | 'count';
+  operation?: 'set' | 'add';
+  value: number;
+  note?: string;
+}
+
+export interface AppliedTakeoffAdjustment extends TakeoffAdjustment {
+  original_value: number;
+  adjusted_value: number;
+}
+
+export interface AdjustedQuantity {
+  field: string;
+  original_value: number;
+  note?: string;
+}
+
+export interface UpdateTakeoffAdjustmentsResponse {
+  blueprint_id: string;
+  takeoff_adjustments: TakeoffAdjustment[];
+  orphaned_adjustments: TakeoffAdjustment[];
 }
 
 export interface Coordinate {
@@ -200,16 +319,58 @@ export interface Bid {
   markup_percentage?: number;
   final_price?: number;
   status: BidStatus;
+  status_changed_at?: string;
+  status_note?: string;
   bid_data?: string; // JSONB stored as string
   pdf_url?: string;
   pdf_s3_key?: string;
+  pdf_job_id?: string; // Returned when the PDF is queued for the worker
   version: number;
   parent_bid_id?: string;
   is_latest: boolean;
+  blueprint_ids: string[]; // More than one for a combined bid
+  source_blueprints?: BidSourceBlueprint[]; // Returned by GET /bids/{id}
   created_at: string;
   updated_at: string;
 }
 
+// A bid's PDF URL, or the job still rendering it (HTTP 202)
+// A page of a paginated list; total counts matches across all pages
+export interface ListPage<T> {
+  items: T[];
+  total: number;
+  limit: number;
+  offset: number;
+}
+
+export type BidSort = 'created_at_desc' | 'created_at_asc' | 'final_price_asc' | 'final_price_desc';
+
+export interface BidListParams {
+  blueprint_id?: string;
+  status?: BidStatus;
+  sort?: BidSort;
+  limit?: number;
+  offset?: number;
+}
+
+export interface BidPDFResponse {
+  pdf_url?: string;
+  status?: 'generating';
+  job_id?: string;
+}
+
+// Bids move draft -> sent -> accepted or rejected
+export interface UpdateBidStatusRequest {
+  status: BidStatus;
+  status_note?: string;
+}
+
+export interface BidSourceBlueprint {
+  blueprint_id: string;
+  filename: string;
+  version: number;
+}
+
 export interface LineItem {
   description: string;
   trade: string;
@@ -217,6 +378,9 @@ export interface LineItem {
   unit: string;
   unit_cost: number;
   total: number;
+  material_cost?: number;
+  labor_cost?: number;
+  tax_amount?: number;
 }
 
 export interface BidData {
@@ -229,7 +393,9 @@ export interface BidData {
   material_cost: number;
   subtotal: number;
   markup_amount: number;
-  total_price: number;
+  tax_amount?: number;
+  total_price: number; // Includes tax_amount
+  tax?: TaxSummary;
   exclusions: string[];
   inclusions: string[];
   schedule: Record<string, string>;
@@ -240,6 +406,7 @@ export interface BidData {
 
 export interface GenerateBidRequest {
   blueprint_id: string;
+  blueprint_ids?: string[]; // Prices a combined bid from several blueprints
   markup_percentage?: number;
   company_name?: string;
   bid_name?: string;
@@ -259,7 +426,9 @@ export interface PricingSummary {
   subtotal: number;
   overhead_amount: number;
   markup_amount: number;
-  total_price: number;
+  tax_amount: number;
+  total_price: number; // Includes tax_amount
+  tax?: TaxSummary;
   costs_by_trade: Record<string, number>;
 }
 
@@ -303,7 +472,7 @@ export interface BlueprintChange {
   description: string;
   old_value?: unknown;
   new_value?: unknown;
-  impact?: string; // High, Medium, Low
+  impact?: ChangeImpact;
 }
 
 export interface BidChange {
@@ -313,7 +482,24 @@ export interface BidChange {
   description: string;
   old_value?: unknown;
   new_value?: unknown;
-  impact?: string; // High, Medium, Low
+  impact?: ChangeImpact;
+}
+
+export type ChangeImpact = 'High' | 'Medium' | 'Low';
+
+// Percent change bands: more than high_percent is High, less than low_percent
+// is Low, anything between is Medium
+export interface ImpactBand {
+  low_percent: number;
+  high_percent: number;
+}
+
+// Thresholds comparison impact labels are decided by. Bands are keyed by
+// change category: room, measurement, material, quantity, line_item, cost.
+export interface ImpactThresholds {
+  bands: Record<string, ImpactBand>;
+  dollar_floor: number; // Dollar changes under this are Low
+  weigh_costs_by_final_price: boolean;
 }
 
 export interface ComparisonSummary {
@@ -330,6 +516,7 @@ export interface BlueprintComparison {
   to_version: number;
   changes: BlueprintChange[];
   summary: ComparisonSummary;
+  impact_thresholds: ImpactThresholds;
 }
 
 export interface BidComparison {
@@ -337,4 +524,5 @@ export interface BidComparison {
   to_version: number;
   changes: BidChange[];
   summary: ComparisonSummary;
+  impact_thresholds: ImpactThresholds;
 }
@@ -0,0 +1,55 @@
+// Command backfill-ocr populates the blueprints.raw_ocr_text search column from
+// analyses that were stored before OCR text indexing existed.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+)
+
+func main() {
+	batchSize := flag.Int("batch-size", 500, "number of blueprints to update per batch")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	slog.SetDefault(logger)
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := repository.NewDatabase(cfg)
+	if err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	blueprintRepo := repository.NewBlueprintRepository(db)
+	ctx := context.Background()
+
+	var total int64
+	for {
+		updated, err := blueprintRepo.BackfillOCRText(ctx, *batchSize)
+		if err != nil {
+			slog.Error("OCR backfill failed", "updated_so_far", total, "error", err)
+			os.Exit(1)
+		}
+		total += updated
+		if updated == 0 {
+			break
+		}
+		slog.Info("Backfilled OCR text batch", "updated", updated, "total", total)
+	}
+
+	slog.Info("OCR backfill complete", "total", total)
+}
@@ -0,0 +1,71 @@
+// Command backfill-trade-costs populates the bids.costs_by_trade analytics
+// column from the line items of bids generated before it existed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func main() {
+	batchSize := flag.Int("batch-size", 500, "number of bids to update per batch")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	slog.SetDefault(logger)
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := repository.NewDatabase(cfg)
+	if err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	bidRepo := repository.NewBidRepository(db)
+	ctx := context.Background()
+
+	var total int
+	for {
+		bids, err := bidRepo.GetMissingCostsByTrade(ctx, *batchSize)
+		if err != nil {
+			slog.Error("Trade cost backfill failed", "updated_so_far", total, "error", err)
+			os.Exit(1)
+		}
+		if len(bids) == 0 {
+			break
+		}
+
+		for _, bid := range bids {
+			// Unreadable bid data is recorded as no trade costs so the bid
+			// is not picked up again
+			var bidData models.GenerateBidResponse
+			if err := json.Unmarshal([]byte(*bid.BidData), &bidData); err != nil {
+				slog.Warn("Skipping bid with unreadable bid data", "bid_id", bid.ID, "error", err)
+			}
+			if err := bidRepo.UpdateCostsByTrade(ctx, bid.ID, services.CostsByTrade(bidData.LineItems)); err != nil {
+				slog.Error("Trade cost backfill failed", "bid_id", bid.ID, "updated_so_far", total, "error", err)
+				os.Exit(1)
+			}
+		}
+		total += len(bids)
+		slog.Info("Backfilled bid trade costs batch", "updated", len(bids), "total", total)
+	}
+
+	slog.Info("Trade cost backfill complete", "total", total)
+}
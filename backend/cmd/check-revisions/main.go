@@ -0,0 +1,72 @@
+// Command check-revisions re-validates the analysis stored with every
+// blueprint revision and reports the rows that no longer parse, such as those
+// written before analyses were validated. It changes nothing.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func main() {
+	batchSize := flag.Int("batch-size", 500, "number of revisions to read per batch")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	slog.SetDefault(logger)
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := repository.NewDatabase(cfg)
+	if err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	revisionRepo := repository.NewBlueprintRevisionRepository(db)
+	ctx := context.Background()
+
+	var checked, corrupt int
+	after := uuid.Nil
+	for {
+		revisions, err := revisionRepo.ListAfter(ctx, after, *batchSize)
+		if err != nil {
+			slog.Error("Revision check failed", "checked_so_far", checked, "error", err)
+			os.Exit(1)
+		}
+		if len(revisions) == 0 {
+			break
+		}
+		for _, revision := range revisions {
+			if _, err := services.ParseAnalysisData(revision.AnalysisData); err != nil {
+				corrupt++
+				slog.Warn("Corrupt revision analysis",
+					"revision_id", revision.ID,
+					"blueprint_id", revision.BlueprintID,
+					"version", revision.Version,
+					"error", err)
+			}
+		}
+		checked += len(revisions)
+		after = revisions[len(revisions)-1].ID
+	}
+
+	slog.Info("Revision check complete", "checked", checked, "corrupt", corrupt)
+	if corrupt > 0 {
+		os.Exit(2)
+	}
+}
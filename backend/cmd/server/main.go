@@ -12,8 +12,11 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/go-chi/chi/v5"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/errreport"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/handlers"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
 )
@@ -38,14 +41,13 @@ func main() {
 		"port", cfg.Server.Port)
 
 	// Initialize Sentry for error tracking
-	sentryDSN := os.Getenv("SENTRY_DSN")
-	if sentryDSN != "" {
-		err := sentry.Init(sentry.ClientOptions{
-			Dsn:              sentryDSN,
+	if cfg.Sentry.DSN != "" {
+		err := errreport.Init(sentry.ClientOptions{
+			Dsn:              cfg.Sentry.DSN,
 			Environment:      cfg.Server.Env,
 			TracesSampleRate: 1.0,
 			Release:          "backend@1.0.0",
-		})
+		}, cfg.Sentry.ErrorSampleRate)
 		if err != nil {
 			slog.Warn("Failed to initialize Sentry", "error", err)
 		} else {
@@ -69,11 +71,19 @@ func main() {
 	jobRepo := repository.NewJobRepository(db)
 	bidRepo := repository.NewBidRepository(db)
 	bidRevisionRepo := repository.NewBidRevisionRepository(db)
+	bidDraftRepo := repository.NewBidDraftRepository(db)
+	bidAcceptanceRepo := repository.NewBidAcceptanceRepository(db)
+	companyProfileRepo := repository.NewCompanyProfileRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
 	userRepo := repository.NewUserRepository(db)
 	materialRepo := repository.NewMaterialRepository(db.Pool)
 	laborRateRepo := repository.NewLaborRateRepository(db.Pool)
 	regionalRepo := repository.NewRegionalAdjustmentRepository(db.Pool)
 	companyOverrideRepo := repository.NewCompanyPricingOverrideRepository(db.Pool)
+	projectOverrideRepo := repository.NewProjectPricingOverrideRepository(db)
+	objectDeletionRepo := repository.NewObjectDeletionRepository(db)
+	blueprintAssetRepo := repository.NewBlueprintAssetRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
 
 	// Initialize services
 	s3Service, err := services.NewS3Service(cfg)
@@ -88,10 +98,29 @@ func main() {
 		// Don't exit - bucket might exist already or will be created by admin
 	}
 
-	aiService := services.NewAIService(cfg)
+	// Warn when the bucket does not store objects the way it is configured to
+	problems, err := s3Service.CheckStorageSettings(context.Background())
+	if err != nil {
+		slog.Warn("Failed to check S3 storage settings", "error", err)
+	}
+	for _, problem := range problems {
+		slog.Warn("S3 bucket does not honor storage settings", "problem", problem)
+	}
+
+	aiService := services.NewAIProvider(cfg)
 
 	// Initialize auth service
-	authService := services.NewAuthService(cfg.Auth.JWTSecret, cfg.Auth.TokenExpiry)
+	authService := services.NewAuthService(cfg.Auth.JWTSecret, cfg.Auth.TokenExpiry).
+		WithBcryptCost(cfg.Auth.BcryptCost).
+		WithRefreshTokens(repository.NewRefreshTokenRepository(db), cfg.Auth.RefreshTokenExpiry)
+	if hashTime, err := authService.MeasureHashTime(); err != nil {
+		slog.Warn("Failed to benchmark password hashing", "error", err)
+	} else {
+		slog.Info("Password hashing configured", "bcrypt_cost", authService.BcryptCost(), "hash_time", hashTime.String())
+	}
+
+	// Read-only API keys for external integrations
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, services.DefaultAPIKeyCacheTTL)
 
 	// Initialize Redis client for caching
 	redisClient, err := services.NewRedisClient()
@@ -104,9 +133,48 @@ func main() {
 
 	// Initialize cost integration service with caching
 	costIntegrationService := services.NewCachedCostIntegrationService(materialRepo, laborRateRepo, regionalRepo, redisClient)
+	costIntegrationService.SetSyncWorkers(cfg.CostProvider.SyncWorkers)
+	if cfg.CostProvider.Enabled {
+		costIntegrationService.RegisterProvider(services.NewHTTPCostProvider(services.OneBuildProviderConfig(cfg.CostProvider)))
+		slog.Info("1build cost provider enabled", "base_url", cfg.CostProvider.BaseURL)
+	}
+
+	// Handlers and the worker publish events; audit logging, cache
+	// invalidation and webhooks subscribe to them. Deferred first so pending
+	// async subscribers, including webhook retries, finish after the worker
+	// has stopped.
+	bus := events.NewBus()
+	services.NewAuditSubscriber(slog.Default()).Register(bus)
+	services.RegisterCacheInvalidation(bus, costIntegrationService)
+	webhookService := services.NewWebhookService(webhookRepo, blueprintRepo, projectRepo)
+	webhookService.Register(bus)
+	defer bus.Wait()
+
+	// Duplication copies blueprints within a request or in a worker job
+	projectDuplicator := services.NewProjectDuplicator(projectRepo, blueprintRepo, s3Service, cfg.S3.UserQuotaBytes)
+
+	// The worker renders bid PDFs queued when bids are generated; bids
+	// without blueprints are rendered within the request
+	rasterizer, _ := aiService.(services.PDFPageRasterizer)
+	bidPDFGenerator := services.NewBidPDFGenerator(
+		bidRepo, projectRepo, blueprintRepo, userRepo, companyProfileRepo,
+		services.NewBidPDFPublisher(s3Service, objectDeletionRepo, cfg.S3.SupersededRetention),
+		services.NewBlueprintPageRenderer(s3Service, rasterizer),
+		s3Service,
+	).WithAcceptances(bidAcceptanceRepo)
+
+	// The worker sweeps daily; admins can also sweep on demand
+	retentionSweeper := services.NewRetentionSweeper(repository.NewRetentionRepository(db), cfg.Retention)
 
 	// Initialize worker
-	worker := services.NewWorker(jobRepo, blueprintRepo, aiService, cfg)
+	worker := services.NewWorker(jobRepo, blueprintRepo, aiService, cfg).
+		WithObjectCleanup(services.NewObjectCleaner(objectDeletionRepo, s3Service)).
+		WithDraftCleanup(services.NewDraftCleaner(bidDraftRepo)).
+		WithAutoRevisions(services.NewAutoRevisioner(blueprintRevisionRepo, userRepo)).
+		WithProjectDuplication(projectDuplicator).
+		WithBidPDFs(bidPDFGenerator).
+		WithRetentionSweep(retentionSweeper).
+		WithEvents(bus)
 	ctx, cancel := context.WithCancel(context.Background())
 	worker.Start(ctx)
 	defer func() {
@@ -114,25 +182,34 @@ func main() {
 		worker.Stop()
 	}()
 
-	// Initialize handlers
-	handler := handlers.NewHandler(
-		db,
-		projectRepo,
-		blueprintRepo,
-		blueprintRevisionRepo,
-		jobRepo,
-		bidRepo,
-		bidRevisionRepo,
-		userRepo,
-		materialRepo,
-		laborRateRepo,
-		regionalRepo,
-		companyOverrideRepo,
-		s3Service,
-		aiService,
-		authService,
-		costIntegrationService,
-	)
+	// Initialize handler groups
+	pricingSources := handlers.NewPricingSources(materialRepo, laborRateRepo, regionalRepo, companyOverrideRepo, costIntegrationService, cfg).
+		WithProjectOverrides(projectOverrideRepo)
+	systemHandlers := handlers.NewSystemHandlers(db, aiService, jobRepo, cfg)
+	authHandlers := handlers.NewAuthHandlers(userRepo, authService, cfg)
+	projectHandlers := handlers.NewProjectHandlers(projectRepo, jobRepo, projectDuplicator, cfg)
+	blueprintDeleter := services.NewBlueprintDeleter(bidRepo, blueprintRepo, s3Service)
+	blueprintHandlers := handlers.NewBlueprintHandlers(projectRepo, blueprintRepo, blueprintAssetRepo, userRepo, s3Service, blueprintDeleter, cfg)
+	jobHandlers := handlers.NewJobHandlers(projectRepo, blueprintRepo, jobRepo, cfg)
+	bidHandlers := handlers.NewBidHandlers(projectRepo, blueprintRepo, bidRepo, bidRevisionRepo, bidDraftRepo, userRepo, companyProfileRepo, jobRepo, pricingSources, bidPDFGenerator, s3Service, aiService, db, bus, cfg)
+	revisionHandlers := handlers.NewRevisionHandlers(projectRepo, blueprintRepo, blueprintRevisionRepo, blueprintAssetRepo, bidRepo, bidRevisionRepo, userRepo, s3Service, db)
+	costHandlers := handlers.NewCostHandlers(pricingSources, costIntegrationService, bus)
+	projectOverrideHandlers := handlers.NewProjectPricingOverrideHandlers(projectRepo, projectOverrideRepo, bus)
+	// Deletes are checked against the stored cost data, not the cache
+	costDataOverrides := services.NewEnhancedPricingService(materialRepo, laborRateRepo, regionalRepo, companyOverrideRepo).
+		OverrideValidator(companyOverrideRepo)
+	costDataHandlers := handlers.NewCostDataHandlers(materialRepo, laborRateRepo, costDataOverrides, bus)
+	adminHandlers := handlers.NewAdminHandlers(userRepo, authService, materialRepo, bus, retentionSweeper)
+	apiKeyHandlers := handlers.NewAPIKeyHandlers(apiKeyService)
+	pdfLayoutHandlers := handlers.NewPDFLayoutHandlers(userRepo)
+	companyProfileHandlers := handlers.NewCompanyProfileHandlers(companyProfileRepo)
+	webhookHandlers := handlers.NewWebhookHandlers(webhookService)
+	bidShareHandlers := handlers.NewBidShareHandlers(projectRepo, bidRepo, bidRevisionRepo, repository.NewBidShareRepository(db), bidAcceptanceRepo, repository.NewBidShareEventRepository(db), db, bus, cfg)
+	var analyticsCache handlers.ResponseCache
+	if redisClient != nil {
+		analyticsCache = redisClient
+	}
+	analyticsHandlers := handlers.NewAnalyticsHandlers(bidRepo, analyticsCache)
 
 	// Setup router
 	r := chi.NewRouter()
@@ -140,99 +217,52 @@ func main() {
 	// Middleware - order matters!
 	r.Use(middleware.CorrelationID)
 	r.Use(middleware.Logger)
+	// Compression sits inside the logger so logged sizes are bytes on the wire
+	r.Use(middleware.Compress(middleware.DefaultCompressMinSize))
 	r.Use(middleware.Recovery)
-	
-	// Security middleware
-	if cfg.Security.EnableSecurityHeaders {
-		securityConfig := middleware.SecurityHeadersConfig{
-			EnableHSTS:           cfg.Security.EnableHSTS,
-			HSTSMaxAge:           cfg.Security.HSTSMaxAge,
-			EnableCSP:            cfg.Security.EnableCSP,
-			CSPDirectives:        cfg.Security.CSPDirectives,
-			EnableXFrameOptions:  true,
-			XFrameOptionsValue:   "DENY",
-			EnableXContentType:   true,
-			EnableReferrerPolicy: true,
-			ReferrerPolicyValue:  "strict-origin-when-cross-origin",
-		}
-		r.Use(middleware.SecurityHeaders(securityConfig))
-	}
-	
-	// CORS with configured origins
-	r.Use(middleware.CORSWithConfig(cfg.Security.CORSAllowedOrigins))
-	
-	// Rate limiting
-	if cfg.RateLimit.Enabled {
-		rateLimitConfig := middleware.RateLimitConfig{
-			IPRequestsPerMinute:   cfg.RateLimit.IPRequestsPerMinute,
-			UserRequestsPerMinute: cfg.RateLimit.UserRequestsPerMinute,
-			Enabled:               true,
-		}
-		r.Use(middleware.RateLimit(rateLimitConfig))
-	}
-	
-	// Request body size limit
-	r.Use(middleware.RequestBodyLimit(cfg.Security.MaxRequestBodyBytes))
-
-	// Public routes
-	r.Get("/", handler.Root)
-	r.Get("/health", handler.Health)
-
-	// Auth routes (public)
-	r.Post("/auth/signup", handler.Signup)
-	r.Post("/auth/login", handler.Login)
-	
-	// Protected routes
-	r.Group(func(r chi.Router) {
-		r.Use(middleware.Auth(authService))
-		
-		// User routes
-		r.Get("/auth/me", handler.GetCurrentUser)
-
-		// Blueprint upload routes
-		r.Post("/projects/{id}/blueprints/upload-url", handler.CreateUploadURL)
-		r.Post("/blueprints/{id}/complete-upload", handler.CompleteUpload)
-
-		// Blueprint analysis routes
-		r.Get("/blueprints/{id}/analysis", handler.GetBlueprintAnalysis)
-		r.Get("/blueprints/{id}/takeoff-summary", handler.GetBlueprintTakeoffSummary)
-
-		// Job routes
-		r.Post("/blueprints/{id}/analyze", handler.AnalyzeBlueprint)
-		r.Get("/jobs/{id}", handler.GetJobStatus)
-
-		// Bid routes
-		r.Get("/projects/{id}/pricing-summary", handler.GetPricingSummary)
-		r.Post("/projects/{id}/generate-bid", handler.GenerateBid)
-		r.Get("/projects/{id}/bids", handler.GetProjectBids)
-		r.Get("/bids/{id}", handler.GetBid)
-		r.Get("/bids/{id}/pdf", handler.GetBidPDF)
-		r.Get("/bids/{id}/csv", handler.GetBidCSV)
-		r.Get("/bids/{id}/excel", handler.GetBidExcel)
-		
-		// Blueprint revision routes
-		r.Get("/blueprints/{id}/revisions", handler.GetBlueprintRevisions)
-		r.Post("/blueprints/{id}/revisions", handler.CreateBlueprintRevision)
-		r.Get("/blueprints/{id}/compare", handler.CompareBlueprintRevisions)
-		
-		// Bid revision routes
-		r.Get("/bids/{id}/revisions", handler.GetBidRevisions)
-		r.Post("/bids/{id}/revisions", handler.CreateBidRevision)
-		r.Get("/bids/{id}/compare", handler.CompareBidRevisions)
-
-		// Cost database routes
-		r.Get("/api/materials", handler.GetMaterials)
-		r.Get("/api/labor-rates", handler.GetLaborRates)
-		r.Get("/api/regional-adjustments", handler.GetRegionalAdjustments)
-		
-		// Company pricing override routes
-		r.Get("/api/company/pricing-overrides", handler.GetCompanyPricingOverrides)
-		r.Post("/api/company/pricing-overrides", handler.CreateCompanyPricingOverride)
-		r.Put("/api/company/pricing-overrides/{id}", handler.UpdateCompanyPricingOverride)
-		r.Delete("/api/company/pricing-overrides/{id}", handler.DeleteCompanyPricingOverride)
-		
-		// Admin route for syncing cost data (should add admin check in production)
-		r.Post("/api/admin/sync-cost-data", handler.SyncCostData)
+
+	// Security headers, CORS, per-IP rate limiting and the body size limit
+	useSecurityMiddleware(r, cfg)
+
+	// Unversioned system routes
+	systemHandlers.Routes(r)
+
+	// API routes, under /v1 and at the deprecated unprefixed paths
+	requireAuth := middleware.AuthWithAPIKeys(authService, userRepo, apiKeyService)
+	handlers.MountAPI(r, cfg.Server.LegacyRoutesSunset, func(r chi.Router) {
+		// Public routes
+		authHandlers.PublicRoutes(r)
+		bidShareHandlers.PublicRoutes(r)
+
+		// Protected routes
+		r.Group(func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(userRateLimit(cfg))
+
+			authHandlers.Routes(r)
+			projectHandlers.Routes(r)
+			blueprintHandlers.Routes(r)
+			jobHandlers.Routes(r)
+			bidHandlers.Routes(r)
+			revisionHandlers.Routes(r)
+			costHandlers.Routes(r)
+			projectOverrideHandlers.Routes(r)
+			analyticsHandlers.Routes(r)
+			apiKeyHandlers.Routes(r)
+			pdfLayoutHandlers.Routes(r)
+			companyProfileHandlers.Routes(r)
+			webhookHandlers.Routes(r)
+			bidShareHandlers.Routes(r)
+
+			// Admin routes
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireRole(models.UserRoleAdmin))
+
+				adminHandlers.Routes(r)
+				costHandlers.AdminRoutes(r)
+				costDataHandlers.AdminRoutes(r)
+			})
+		})
 	})
 
 	// Create HTTP server
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+)
+
+// useSecurityMiddleware adds the request-wide protections: security headers,
+// CORS for the configured origins, per-IP rate limiting and the request body
+// limit. Per-user limiting needs the authenticated user, so protected routes
+// add userRateLimit after auth.
+func useSecurityMiddleware(r chi.Router, cfg *config.Config) {
+	if cfg.Security.EnableSecurityHeaders {
+		r.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+			EnableHSTS:           cfg.Security.EnableHSTS,
+			HSTSMaxAge:           cfg.Security.HSTSMaxAge,
+			EnableCSP:            cfg.Security.EnableCSP,
+			CSPDirectives:        cfg.Security.CSPDirectives,
+			EnableXFrameOptions:  true,
+			XFrameOptionsValue:   "DENY",
+			EnableXContentType:   true,
+			EnableReferrerPolicy: true,
+			ReferrerPolicyValue:  "strict-origin-when-cross-origin",
+		}))
+	}
+
+	r.Use(middleware.CORSWithConfig(cfg.Security.CORSAllowedOrigins))
+
+	if cfg.RateLimit.Enabled {
+		r.Use(middleware.IPRateLimit(cfg.RateLimit.IPRequestsPerMinute))
+	}
+
+	r.Use(middleware.RequestBodyLimit(cfg.Security.MaxRequestBodyBytes))
+}
+
+// userRateLimit limits authenticated requests per user; mount it after auth
+func userRateLimit(cfg *config.Config) func(http.Handler) http.Handler {
+	if !cfg.RateLimit.Enabled {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	return middleware.UserRateLimit(cfg.RateLimit.UserRequestsPerMinute)
+}
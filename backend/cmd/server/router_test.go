@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+type activeAccounts struct{}
+
+func (activeAccounts) GetAccountStatus(ctx context.Context, userID uuid.UUID) (models.AccountStatus, error) {
+	return models.AccountStatus{}, nil
+}
+
+// newTestServer composes the server's middleware the way main does, with a
+// public and a protected route
+func newTestServer(t *testing.T, cfg *config.Config) (*services.AuthService, *httptest.Server) {
+	t.Helper()
+	authService := services.NewAuthService("test-secret", time.Hour)
+
+	r := chi.NewRouter()
+	r.Use(middleware.CorrelationID)
+	useSecurityMiddleware(r, cfg)
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	r.Post("/public", ok)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Auth(authService, activeAccounts{}))
+		r.Use(userRateLimit(cfg))
+		r.Get("/protected", ok)
+	})
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return authService, server
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		RateLimit: config.RateLimitConfig{Enabled: true, IPRequestsPerMinute: 5, UserRequestsPerMinute: 3},
+		Security: config.SecurityConfig{
+			EnableSecurityHeaders: true,
+			EnableHSTS:            true,
+			HSTSMaxAge:            600,
+			EnableCSP:             true,
+			CSPDirectives:         "default-src 'self'",
+			CORSAllowedOrigins:    []string{"https://app.example.com"},
+			MaxRequestBodyBytes:   64,
+		},
+	}
+}
+
+func send(t *testing.T, server *httptest.Server, method, path, ip, token, body string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Forwarded-For", ip)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+// sendForError sends a request and decodes its error body
+func sendForError(t *testing.T, server *httptest.Server, method, path, ip, token, body string) (*http.Response, map[string]string) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Forwarded-For", ip)
+	req.Header.Set(middleware.CorrelationIDHeader, "corr-123")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var errBody map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&errBody); err != nil {
+		t.Fatalf("failed to decode error body: %v", err)
+	}
+	return resp, errBody
+}
+
+func TestServerMiddleware_SecurityHeadersAndCORS(t *testing.T) {
+	_, server := newTestServer(t, testConfig())
+
+	resp := send(t, server, http.MethodPost, "/public", "10.0.0.1", "", "{}", http.Header{"Origin": {"https://app.example.com"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	for header, want := range map[string]string{
+		"Strict-Transport-Security":   "max-age=600; includeSubDomains; preload",
+		"Content-Security-Policy":     "default-src 'self'",
+		"X-Content-Type-Options":      "nosniff",
+		"Access-Control-Allow-Origin": "https://app.example.com",
+		"X-Ratelimit-Limit":           "5",
+	} {
+		if got := resp.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	resp = send(t, server, http.MethodPost, "/public", "10.0.0.2", "", "{}", http.Header{"Origin": {"https://evil.example.com"}})
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("unlisted origin allowed: %q", got)
+	}
+
+	cfg := testConfig()
+	cfg.Security.EnableSecurityHeaders = false
+	_, plain := newTestServer(t, cfg)
+	if resp = send(t, plain, http.MethodPost, "/public", "10.0.0.1", "", "{}", nil); resp.Header.Get("Strict-Transport-Security") != "" {
+		t.Error("security headers sent while disabled")
+	}
+}
+
+func TestServerMiddleware_RequestBodyLimit(t *testing.T) {
+	_, server := newTestServer(t, testConfig())
+
+	if resp := send(t, server, http.MethodPost, "/public", "10.0.0.1", "", strings.Repeat("x", 64), nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("body at the limit status = %d, want 200", resp.StatusCode)
+	}
+	resp, body := sendForError(t, server, http.MethodPost, "/public", "10.0.0.1", "", strings.Repeat("x", 65))
+	if resp.StatusCode != http.StatusRequestEntityTooLarge || body["code"] != middleware.CodeRequestTooLarge || body["correlation_id"] != "corr-123" {
+		t.Errorf("body over the limit = %d %v, want 413 %s with the correlation ID", resp.StatusCode, body, middleware.CodeRequestTooLarge)
+	}
+}
+
+func TestServerMiddleware_RateLimits(t *testing.T) {
+	authService, server := newTestServer(t, testConfig())
+
+	// Anonymous requests are limited by IP before authentication
+	for i := 0; i < 5; i++ {
+		if resp := send(t, server, http.MethodPost, "/public", "10.0.0.1", "", "", nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i+1, resp.StatusCode)
+		}
+	}
+	resp, body := sendForError(t, server, http.MethodPost, "/public", "10.0.0.1", "", "")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("6th request = %d, Retry-After %q, want 429 with Retry-After", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	if body["code"] != middleware.CodeRateLimited || body["correlation_id"] != "corr-123" {
+		t.Errorf("6th request body = %v, want %s with the correlation ID", body, middleware.CodeRateLimited)
+	}
+	if resp = send(t, server, http.MethodPost, "/public", "10.0.0.2", "", "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("other IP status = %d, want 200", resp.StatusCode)
+	}
+
+	// A user is limited across IPs once authenticated
+	token, err := authService.GenerateToken(uuid.New().String(), "pat@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if resp = send(t, server, http.MethodGet, "/protected", fmt.Sprintf("10.1.0.%d", i+1), token, "", nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("user request %d status = %d, want 200", i+1, resp.StatusCode)
+		}
+	}
+	if resp = send(t, server, http.MethodGet, "/protected", "10.1.0.9", token, "", nil); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("4th user request status = %d, want 429", resp.StatusCode)
+	}
+
+	cfg := testConfig()
+	cfg.RateLimit.Enabled = false
+	_, unlimited := newTestServer(t, cfg)
+	for i := 0; i < 7; i++ {
+		if resp = send(t, unlimited, http.MethodPost, "/public", "10.0.0.1", "", "", nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("disabled limit request %d status = %d, want 200", i+1, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package errreport sends recovered panics and service errors to Sentry.
+//
+// Each request gets its own hub, cloned from the process hub by the Recovery
+// middleware, so tags set while serving one request never appear on another
+// request's events. Code without a request hub, such as the worker, reports
+// through the process hub and passes its context as tags.
+package errreport
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Tag names shared by panics and errors
+const (
+	TagCorrelationID = "correlation_id"
+	TagUserID        = "user_id"
+	TagRoute         = "route"
+	TagMethod        = "method"
+	TagComponent     = "component"
+)
+
+// errorSampleRate holds the float64 bits of the non-panic sample rate
+var errorSampleRate atomic.Uint64
+
+func init() {
+	errorSampleRate.Store(math.Float64bits(1))
+}
+
+// Init configures the process hub and the fraction of non-panic errors
+// CaptureError reports. Stack traces are attached to every event.
+func Init(options sentry.ClientOptions, sampleRate float64) error {
+	options.AttachStacktrace = true
+	if err := sentry.Init(options); err != nil {
+		return err
+	}
+	SetErrorSampleRate(sampleRate)
+	return nil
+}
+
+// SetErrorSampleRate sets the fraction of non-panic errors reported, clamped
+// to 0-1
+func SetErrorSampleRate(rate float64) {
+	errorSampleRate.Store(math.Float64bits(math.Max(0, math.Min(1, rate))))
+}
+
+// WithRequestHub returns ctx carrying a hub of its own, cloned from the
+// process hub, with the request's URL and headers attached to its scope. The
+// body is left out because it can carry credentials.
+func WithRequestHub(ctx context.Context, r *http.Request) context.Context {
+	hub := sentry.CurrentHub().Clone()
+	if r != nil {
+		withoutBody := r.WithContext(ctx)
+		withoutBody.Body = http.NoBody
+		hub.Scope().SetRequest(withoutBody)
+	}
+	return sentry.SetHubOnContext(ctx, hub)
+}
+
+// hub returns the request hub in ctx, or the process hub outside a request
+func hub(ctx context.Context) *sentry.Hub {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		return hub
+	}
+	return sentry.CurrentHub()
+}
+
+// SetTag tags every later event of the request in ctx. It does nothing
+// outside a request, where tags would leak onto the process hub.
+func SetTag(ctx context.Context, key, value string) {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil && value != "" {
+		hub.Scope().SetTag(key, value)
+	}
+}
+
+// SetUser records the authenticated user on the request in ctx
+func SetUser(ctx context.Context, userID string) {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil && userID != "" {
+		hub.Scope().SetUser(sentry.User{ID: userID})
+		hub.Scope().SetTag(TagUserID, userID)
+	}
+}
+
+// CapturePanic reports a value recovered from a panic. Panics are never
+// sampled. It must be called from the deferred function that recovered, so
+// the attached stack trace shows where the panic happened.
+func CapturePanic(ctx context.Context, recovered interface{}) {
+	hub(ctx).RecoverWithContext(ctx, recovered)
+}
+
+// CaptureError reports a failure that was handled, such as an AI service or
+// storage error, subject to the error sample rate. Tags are added to this
+// event only.
+func CaptureError(ctx context.Context, err error, tags map[string]string) {
+	if err == nil || !sampled() {
+		return
+	}
+	eventHub := hub(ctx).Clone()
+	eventHub.Scope().SetTags(tags)
+	eventHub.CaptureException(err)
+}
+
+func sampled() bool {
+	rate := math.Float64frombits(errorSampleRate.Load())
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
@@ -0,0 +1,120 @@
+package errreport
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// transportStub keeps events instead of sending them
+type transportStub struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *transportStub) Configure(options sentry.ClientOptions)    {}
+func (t *transportStub) Flush(timeout time.Duration) bool          { return true }
+func (t *transportStub) FlushWithContext(ctx context.Context) bool { return true }
+func (t *transportStub) Close()                                    {}
+
+func (t *transportStub) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *transportStub) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+func initStub(t *testing.T, sampleRate float64) *transportStub {
+	t.Helper()
+	transport := &transportStub{}
+	if err := Init(sentry.ClientOptions{Transport: transport}, sampleRate); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(func() {
+		sentry.CurrentHub().BindClient(nil)
+		SetErrorSampleRate(1)
+	})
+	return transport
+}
+
+func TestCaptureError_SampleRate(t *testing.T) {
+	transport := initStub(t, 0)
+	for i := 0; i < 10; i++ {
+		CaptureError(context.Background(), errors.New("AI service unavailable"), nil)
+	}
+	if got := len(transport.Events()); got != 0 {
+		t.Fatalf("sample rate 0 sent %d events, want 0", got)
+	}
+
+	SetErrorSampleRate(1)
+	CaptureError(context.Background(), errors.New("AI service unavailable"), map[string]string{TagComponent: "ai"})
+	events := transport.Events()
+	if len(events) != 1 || events[0].Tags[TagComponent] != "ai" {
+		t.Fatalf("sample rate 1 events = %v, want one tagged component=ai", events)
+	}
+}
+
+func TestCapturePanic_IgnoresSampleRate(t *testing.T) {
+	transport := initStub(t, 0)
+	CapturePanic(context.Background(), "nil map write")
+	if got := len(transport.Events()); got != 1 {
+		t.Fatalf("panic sent %d events, want 1", got)
+	}
+}
+
+func TestCaptureError_TagsStayOnTheirEvent(t *testing.T) {
+	transport := initStub(t, 1)
+	ctx := WithRequestHub(context.Background(), nil)
+	SetTag(ctx, TagCorrelationID, "req-1")
+
+	CaptureError(ctx, errors.New("upload failed"), map[string]string{TagComponent: "s3"})
+	CaptureError(ctx, errors.New("AI timeout"), nil)
+
+	events := transport.Events()
+	if len(events) != 2 {
+		t.Fatalf("sent %d events, want 2", len(events))
+	}
+	for _, event := range events {
+		if event.Tags[TagCorrelationID] != "req-1" {
+			t.Errorf("event %q correlation tag = %q, want req-1", event.Exception[0].Value, event.Tags[TagCorrelationID])
+		}
+	}
+	if _, ok := events[1].Tags[TagComponent]; ok {
+		t.Errorf("component tag of the first capture leaked onto the second: %v", events[1].Tags)
+	}
+}
+
+func TestRequestHubsDoNotShareTags(t *testing.T) {
+	transport := initStub(t, 1)
+	first := WithRequestHub(context.Background(), nil)
+	second := WithRequestHub(context.Background(), nil)
+	SetTag(first, TagCorrelationID, "req-1")
+	SetUser(second, "user-2")
+
+	CaptureError(first, errors.New("first"), nil)
+	CaptureError(second, errors.New("second"), nil)
+	CaptureError(context.Background(), errors.New("worker"), nil)
+
+	events := transport.Events()
+	if len(events) != 3 {
+		t.Fatalf("sent %d events, want 3", len(events))
+	}
+	if events[0].Tags[TagCorrelationID] != "req-1" || events[0].Tags[TagUserID] != "" {
+		t.Errorf("first request tags = %v", events[0].Tags)
+	}
+	if events[1].Tags[TagCorrelationID] != "" || events[1].Tags[TagUserID] != "user-2" || events[1].User.ID != "user-2" {
+		t.Errorf("second request tags = %v, user = %v", events[1].Tags, events[1].User)
+	}
+	if len(events[2].Tags) != 0 {
+		t.Errorf("process hub picked up request tags: %v", events[2].Tags)
+	}
+}
@@ -0,0 +1,130 @@
+// Package events is an in-process publish/subscribe bus. Handlers and the
+// worker publish what happened; side effects such as audit logging and cache
+// invalidation subscribe to it instead of being called inline.
+//
+// Delivery semantics:
+//
+//   - Sync subscribers run on the publishing goroutine, one at a time in the
+//     order they subscribed, and have all returned when Publish returns. Use
+//     them for effects the publisher's caller must observe, such as dropping
+//     a cache before the response that changed the data.
+//   - Async subscribers each run on their own goroutine with a context that
+//     keeps the publisher's values but is never cancelled, since the request
+//     usually ends first. There is no ordering between async subscribers, or
+//     between the deliveries of two events to the same async subscriber.
+//   - Subscribers cannot fail the publisher. They have no error result and
+//     must log their own failures. A subscriber that panics is recovered and
+//     logged, and the remaining subscribers still receive the event.
+//   - Events published with no subscribers are dropped.
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Event is something that happened. Name identifies its type to subscribers.
+type Event interface {
+	EventName() string
+}
+
+// Publisher publishes events. Handlers depend on this rather than on Bus so
+// tests can record events with a Recorder.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Mode selects how a subscriber is called
+type Mode int
+
+const (
+	// Sync subscribers finish before Publish returns
+	Sync Mode = iota
+	// Async subscribers run in the background; Wait blocks until they finish
+	Async
+)
+
+type subscriber struct {
+	name   string
+	mode   Mode
+	handle func(ctx context.Context, event Event)
+}
+
+// Bus delivers published events to the subscribers for their type
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]subscriber
+	pending     sync.WaitGroup
+	logger      *slog.Logger
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[string][]subscriber),
+		logger:      slog.Default(),
+	}
+}
+
+// Subscribe calls handle with every event of type E published on the bus.
+// name identifies the subscriber in logs.
+func Subscribe[E Event](bus *Bus, name string, mode Mode, handle func(ctx context.Context, event E)) {
+	var zero E
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.subscribers[zero.EventName()] = append(bus.subscribers[zero.EventName()], subscriber{
+		name: name,
+		mode: mode,
+		handle: func(ctx context.Context, event Event) {
+			handle(ctx, event.(E))
+		},
+	})
+}
+
+// Publish delivers an event to its subscribers. Subscribers may publish
+// further events.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subscribers := append([]subscriber(nil), b.subscribers[event.EventName()]...)
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if sub.mode == Async {
+			b.pending.Add(1)
+			go func(sub subscriber) {
+				defer b.pending.Done()
+				b.deliver(context.WithoutCancel(ctx), sub, event)
+			}(sub)
+			continue
+		}
+		b.deliver(ctx, sub, event)
+	}
+}
+
+// Wait blocks until every async delivery started so far has finished, so
+// shutdown does not drop them
+func (b *Bus) Wait() {
+	b.pending.Wait()
+}
+
+// deliver calls one subscriber, isolating the publisher and the other
+// subscribers from its panic
+func (b *Bus) deliver(ctx context.Context, sub subscriber, event Event) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			b.logger.Error("Event subscriber panicked",
+				"event", event.EventName(),
+				"subscriber", sub.name,
+				"panic", recovered)
+		}
+	}()
+	sub.handle(ctx, event)
+}
+
+// discard drops every event
+type discard struct{}
+
+func (discard) Publish(ctx context.Context, event Event) {}
+
+// Discard is a Publisher with no subscribers
+var Discard Publisher = discard{}
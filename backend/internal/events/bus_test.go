@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type pinged struct{ N int }
+
+func (pinged) EventName() string { return "test.pinged" }
+
+type ponged struct{ N int }
+
+func (ponged) EventName() string { return "test.ponged" }
+
+func TestBus_SyncSubscribersRunInOrderBeforePublishReturns(t *testing.T) {
+	bus := NewBus()
+	var calls []string
+	Subscribe(bus, "first", Sync, func(ctx context.Context, event pinged) {
+		calls = append(calls, "first")
+	})
+	Subscribe(bus, "second", Sync, func(ctx context.Context, event pinged) {
+		calls = append(calls, "second")
+	})
+	Subscribe(bus, "other", Sync, func(ctx context.Context, event ponged) {
+		calls = append(calls, "other")
+	})
+
+	bus.Publish(context.Background(), pinged{N: 1})
+
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Errorf("calls = %v, want [first second]", calls)
+	}
+}
+
+func TestBus_AsyncSubscribersFinishByWait(t *testing.T) {
+	bus := NewBus()
+	var mu sync.Mutex
+	var total int
+	release := make(chan struct{})
+	Subscribe(bus, "adder", Async, func(ctx context.Context, event pinged) {
+		<-release
+		mu.Lock()
+		total += event.N
+		mu.Unlock()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.Publish(ctx, pinged{N: 2})
+	bus.Publish(ctx, pinged{N: 3})
+	// The request ending must not cancel subscribers still running
+	cancel()
+	close(release)
+	bus.Wait()
+
+	if total != 5 {
+		t.Errorf("total = %d, want 5", total)
+	}
+}
+
+func TestBus_AsyncSubscribersOutliveThePublishersContext(t *testing.T) {
+	bus := NewBus()
+	errs := make(chan error, 1)
+	Subscribe(bus, "ctx", Async, func(ctx context.Context, event pinged) {
+		errs <- ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bus.Publish(ctx, pinged{})
+	bus.Wait()
+
+	if err := <-errs; err != nil {
+		t.Errorf("subscriber context error = %v, want nil", err)
+	}
+}
+
+func TestBus_PanickingSubscriberDoesNotStopOthers(t *testing.T) {
+	bus := NewBus()
+	var delivered bool
+	Subscribe(bus, "broken", Sync, func(ctx context.Context, event pinged) {
+		panic("boom")
+	})
+	Subscribe(bus, "async broken", Async, func(ctx context.Context, event pinged) {
+		panic("boom")
+	})
+	Subscribe(bus, "healthy", Sync, func(ctx context.Context, event pinged) {
+		delivered = true
+	})
+
+	bus.Publish(context.Background(), pinged{})
+	bus.Wait()
+
+	if !delivered {
+		t.Error("expected the subscriber after a panicking one to receive the event")
+	}
+}
+
+func TestBus_PublishWithoutSubscribers(t *testing.T) {
+	bus := NewBus()
+	bus.Publish(context.Background(), pinged{})
+	bus.Wait()
+}
+
+func TestBus_SubscribersMayPublish(t *testing.T) {
+	bus := NewBus()
+	var got []ponged
+	Subscribe(bus, "relay", Sync, func(ctx context.Context, event pinged) {
+		bus.Publish(ctx, ponged{N: event.N + 1})
+	})
+	Subscribe(bus, "sink", Sync, func(ctx context.Context, event ponged) {
+		got = append(got, event)
+	})
+
+	bus.Publish(context.Background(), pinged{N: 1})
+
+	if len(got) != 1 || got[0].N != 2 {
+		t.Errorf("relayed events = %v, want [{2}]", got)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := &Recorder{}
+	recorder.Publish(context.Background(), pinged{N: 1})
+	recorder.Publish(context.Background(), ponged{N: 2})
+	recorder.Publish(context.Background(), pinged{N: 3})
+
+	if all := recorder.Events(); len(all) != 3 {
+		t.Errorf("Events() returned %d events, want 3", len(all))
+	}
+	pings := Recorded[pinged](recorder)
+	if len(pings) != 2 || pings[0].N != 1 || pings[1].N != 3 {
+		t.Errorf("Recorded[pinged]() = %v, want [{1} {3}]", pings)
+	}
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// BidCreated is published when a generated bid is saved
+type BidCreated struct {
+	BidID         uuid.UUID
+	ProjectID     uuid.UUID
+	UserID        string
+	FinalPrice    float64
+	CorrelationID string
+}
+
+func (BidCreated) EventName() string { return "bid.created" }
+
+// BidOverBudget is published when a generated bid's final price exceeds its
+// project's budget
+type BidOverBudget struct {
+	BidID         uuid.UUID
+	ProjectID     uuid.UUID
+	UserID        string
+	BudgetStatus  models.BudgetStatus
+	CorrelationID string
+}
+
+func (BidOverBudget) EventName() string { return "bid.over_budget" }
+
+// BidStatusChanged is published when a bid moves between draft, sent,
+// accepted and rejected. UserID is empty when a client answered through a
+// share link.
+type BidStatusChanged struct {
+	BidID         uuid.UUID
+	ProjectID     uuid.UUID
+	From          models.BidStatus
+	To            models.BidStatus
+	Version       int
+	UserID        string
+	CorrelationID string
+}
+
+func (BidStatusChanged) EventName() string { return "bid.status_changed" }
+
+// BidAccepted is published when a client accepts a shared bid online. It
+// follows the bid's BidStatusChanged.
+type BidAccepted struct {
+	ProjectID     uuid.UUID
+	Acceptance    models.BidAcceptance
+	CorrelationID string
+}
+
+func (BidAccepted) EventName() string { return "bid.accepted" }
+
+// BidFirstViewed is published the first time a client opens a bid share
+// link
+type BidFirstViewed struct {
+	BidID     uuid.UUID
+	ProjectID uuid.UUID
+	ShareID   uuid.UUID
+	ViewedAt  time.Time
+}
+
+func (BidFirstViewed) EventName() string { return "bid.first_viewed" }
+
+// AnalysisCompleted is published when the worker stores a blueprint's
+// analysis
+type AnalysisCompleted struct {
+	JobID       uuid.UUID
+	BlueprintID uuid.UUID
+	ProjectID   uuid.UUID
+	JobType     models.JobType
+}
+
+func (AnalysisCompleted) EventName() string { return "analysis.completed" }
+
+// JobFailed is published when the worker gives up on a job of any type
+type JobFailed struct {
+	JobID       uuid.UUID
+	BlueprintID uuid.UUID
+	JobType     models.JobType
+	Error       string
+}
+
+func (JobFailed) EventName() string { return "job.failed" }
+
+// OverrideChange is what happened to a company or project pricing override
+type OverrideChange string
+
+const (
+	OverrideCreated OverrideChange = "created"
+	OverrideUpdated OverrideChange = "updated"
+	OverrideDeleted OverrideChange = "deleted"
+)
+
+// OverrideChanged is published when a company pricing override is created,
+// updated or deleted. Override is its state after the change, or before a
+// deletion.
+type OverrideChanged struct {
+	Change        OverrideChange
+	Override      models.CompanyPricingOverride
+	CorrelationID string
+}
+
+func (OverrideChanged) EventName() string { return "pricing.override_changed" }
+
+// ProjectOverrideChanged is published when a project pricing override is
+// created, updated or deleted, with the user who changed it
+type ProjectOverrideChanged struct {
+	Change        OverrideChange
+	Override      models.ProjectPricingOverride
+	UserID        string
+	CorrelationID string
+}
+
+func (ProjectOverrideChanged) EventName() string { return "pricing.project_override_changed" }
+
+// MaterialPricesAdjusted is published when a bulk adjustment is written to
+// stored material prices
+type MaterialPricesAdjusted struct {
+	UserID       string
+	Percent      float64
+	Filter       models.MaterialPriceFilter
+	RowsAffected int64
+}
+
+func (MaterialPricesAdjusted) EventName() string { return "materials.bulk_adjust" }
+
+// CostDataChange is what an admin did to a material or labor rate
+type CostDataChange string
+
+const (
+	CostDataCreated CostDataChange = "created"
+	CostDataUpdated CostDataChange = "updated"
+	CostDataDeleted CostDataChange = "deleted"
+)
+
+// MaterialChanged is published when an admin creates, updates or deletes a
+// material. Material is its state after the change, or before a deletion.
+type MaterialChanged struct {
+	Change        CostDataChange
+	Material      models.MaterialCost
+	UserID        string
+	CorrelationID string
+}
+
+func (MaterialChanged) EventName() string { return "materials.changed" }
+
+// LaborRateChanged is published when an admin creates, updates or deletes a
+// labor rate. LaborRate is its state after the change, or before a deletion.
+type LaborRateChanged struct {
+	Change        CostDataChange
+	LaborRate     models.LaborRate
+	UserID        string
+	CorrelationID string
+}
+
+func (LaborRateChanged) EventName() string { return "labor_rates.changed" }
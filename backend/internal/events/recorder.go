@@ -0,0 +1,38 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Recorder is a Publisher that keeps published events instead of delivering
+// them, so handler tests can assert on what was published without wiring
+// subscribers
+type Recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *Recorder) Publish(ctx context.Context, event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns every published event, in publish order
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+// Recorded returns the published events of type E, in publish order
+func Recorded[E Event](r *Recorder) []E {
+	var matching []E
+	for _, event := range r.Events() {
+		if typed, ok := event.(E); ok {
+			matching = append(matching, typed)
+		}
+	}
+	return matching
+}
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// AdminHandlers serves admin-only user management, bulk price changes and
+// retention sweeps
+type AdminHandlers struct {
+	userRepo     UserStore
+	authService  *services.AuthService
+	materialRepo *repository.MaterialRepository
+	events       events.Publisher
+	retention    *services.RetentionSweeper
+}
+
+func NewAdminHandlers(userRepo UserStore, authService *services.AuthService, materialRepo *repository.MaterialRepository, publisher events.Publisher, retention *services.RetentionSweeper) *AdminHandlers {
+	return &AdminHandlers{
+		userRepo:     userRepo,
+		authService:  authService,
+		materialRepo: materialRepo,
+		events:       publisher,
+		retention:    retention,
+	}
+}
+
+// Routes registers the admin routes; mount them behind
+// middleware.RequireRole(models.UserRoleAdmin). Each handler also checks the
+// role stored for the user and refuses API keys.
+func (h *AdminHandlers) Routes(r chi.Router) {
+	r.Post("/api/admin/materials/bulk-adjust", h.BulkAdjustMaterials)
+	r.Get("/api/admin/users", h.ListUsers)
+	r.Get("/api/admin/users/{id}", h.GetUserDetail)
+	r.Post("/api/admin/users/{id}/suspend", h.SuspendUser)
+	r.Post("/api/admin/users/{id}/unsuspend", h.UnsuspendUser)
+	r.Patch("/api/admin/users/{id}/role", h.UpdateUserRole)
+	r.Post("/api/admin/retention/sweep", h.SweepRetention)
+}
+
+type BulkAdjustMaterialsRequest struct {
+	Category *string `json:"category"`
+	Region   *string `json:"region"`
+	Source   *string `json:"source"`
+	Percent  float64 `json:"percent"`
+	DryRun   bool    `json:"dry_run"`
+}
+
+// requireAdmin responds 403 and returns false unless the caller has the admin role
+func requireAdmin(w http.ResponseWriter, r *http.Request, users UserStore) bool {
+	if _, err := uuid.Parse(getUserID(r.Context())); err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
+
+	// Admin routes are for people, not integrations
+	if middleware.IsReadOnly(r.Context()) {
+		respondAPIError(w, http.StatusForbidden, middleware.CodeAPIKeyReadOnly, "API keys cannot use admin routes")
+		return false
+	}
+
+	if !isAdmin(r.Context(), users) {
+		respondError(w, http.StatusForbidden, "Admin access required")
+		return false
+	}
+	return true
+}
+
+// isAdmin reports whether the authenticated user has the admin role
+func isAdmin(ctx context.Context, users UserStore) bool {
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		return false
+	}
+	user, err := users.GetUserByID(ctx, uid)
+	return err == nil && user.Role == models.UserRoleAdmin
+}
+
+// BulkAdjustMaterials applies a percentage price change to every material
+// matching the optional category/region/source filter (admin only). The
+// change is one statement that applies or fails as a whole, so it reports
+// stats rather than a per-item models.BulkResult.
+func (h *AdminHandlers) BulkAdjustMaterials(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	var req BulkAdjustMaterialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adjuster := services.NewMaterialPriceAdjuster(h.materialRepo, h.events)
+
+	filter := models.MaterialPriceFilter{Category: req.Category, Region: req.Region, Source: req.Source}
+	stats, err := adjuster.Adjust(r.Context(), getUserID(r.Context()), filter, req.Percent, req.DryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAdjustment) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("Failed to bulk adjust material prices",
+			"error", err,
+			"correlation_id", getCorrelationID(r.Context()))
+		respondError(w, http.StatusInternalServerError, "Failed to adjust material prices")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+const (
+	defaultAdminUserLimit = 50
+	maxAdminUserLimit     = 200
+)
+
+type AdminUserListResponse struct {
+	Users      []*models.User `json:"users"`
+	Limit      int            `json:"limit"`
+	Offset     int            `json:"offset"`
+	NextOffset *int           `json:"next_offset,omitempty"` // Set when more users match
+}
+
+type AdminUserDetailResponse struct {
+	User     *models.User         `json:"user"`
+	Activity *models.UserActivity `json:"activity"`
+}
+
+// parseUserSearchFilter reads email, created_after (RFC3339 or YYYY-MM-DD),
+// limit and offset query parameters
+func parseUserSearchFilter(r *http.Request) (models.UserSearchFilter, error) {
+	query := r.URL.Query()
+	filter := models.UserSearchFilter{Limit: defaultAdminUserLimit}
+
+	if email := strings.TrimSpace(query.Get("email")); email != "" {
+		filter.Email = &email
+	}
+	if raw := query.Get("created_after"); raw != "" {
+		createdAfter, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			createdAfter, err = time.Parse("2006-01-02", raw)
+		}
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_after: use RFC3339 or YYYY-MM-DD")
+		}
+		ts := models.NewTimestamp(createdAfter)
+		filter.CreatedAfter = &ts
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return filter, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = min(limit, maxAdminUserLimit)
+	}
+	if raw := query.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return filter, fmt.Errorf("invalid offset")
+		}
+		filter.Offset = offset
+	}
+	return filter, nil
+}
+
+// ListUsers searches users by email substring and creation date (admin only)
+func (h *AdminHandlers) ListUsers(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	filter, err := parseUserSearchFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Fetch one extra row to know whether there is another page
+	pageSize := filter.Limit
+	filter.Limit++
+	users, err := h.userRepo.SearchUsers(r.Context(), filter)
+	if err != nil {
+		slog.Error("Failed to search users", "error", err, "correlation_id", getCorrelationID(r.Context()))
+		respondError(w, http.StatusInternalServerError, "Failed to search users")
+		return
+	}
+
+	response := AdminUserListResponse{Users: users, Limit: pageSize, Offset: filter.Offset}
+	if len(users) > pageSize {
+		response.Users = users[:pageSize]
+		next := filter.Offset + pageSize
+		response.NextOffset = &next
+	}
+
+	slog.Info("Admin searched users",
+		"audit_event", "admin.users_searched",
+		"user_id", getUserID(r.Context()),
+		"email_filter", stringValue(filter.Email),
+		"results", len(response.Users))
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// GetUserDetail returns a user with aggregate activity counts (admin only)
+func (h *AdminHandlers) GetUserDetail(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondNotFound(w)
+		return
+	}
+
+	activity, err := h.userRepo.GetUserActivity(r.Context(), userID)
+	if err != nil {
+		slog.Error("Failed to get user activity", "target_user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get user activity")
+		return
+	}
+
+	slog.Info("Admin viewed user",
+		"audit_event", "admin.user_viewed",
+		"user_id", getUserID(r.Context()),
+		"target_user_id", userID)
+
+	respondJSON(w, http.StatusOK, AdminUserDetailResponse{User: user, Activity: activity})
+}
+
+// SuspendUser blocks a user's access on their next request (admin only)
+func (h *AdminHandlers) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserSuspended(w, r, true)
+}
+
+// UnsuspendUser restores a suspended user's access (admin only)
+func (h *AdminHandlers) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserSuspended(w, r, false)
+}
+
+func (h *AdminHandlers) setUserSuspended(w http.ResponseWriter, r *http.Request, suspended bool) {
+	userID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	if suspended && userID.String() == getUserID(r.Context()) {
+		respondError(w, http.StatusBadRequest, "Admins cannot suspend their own account")
+		return
+	}
+
+	// Revoked first, so a failure leaves the account active rather than
+	// suspended with refresh tokens outliving the suspension. The auth
+	// middleware's per-request check rejects existing access tokens.
+	if suspended {
+		if err := h.authService.RevokeUserRefreshTokens(r.Context(), userID); err != nil {
+			slog.Error("Failed to revoke refresh tokens", "target_user_id", userID, "error", err)
+			respondError(w, http.StatusInternalServerError, "Failed to update user")
+			return
+		}
+	}
+
+	if err := h.userRepo.SetSuspended(r.Context(), userID, suspended); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			respondNotFound(w)
+			return
+		}
+		slog.Error("Failed to update user suspension", "target_user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	event := "admin.user_unsuspended"
+	if suspended {
+		event = "admin.user_suspended"
+	}
+	slog.Warn("Admin changed account suspension",
+		"audit_event", event,
+		"user_id", getUserID(r.Context()),
+		"target_user_id", userID,
+		"correlation_id", getCorrelationID(r.Context()))
+
+	user, err := h.userRepo.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondNotFound(w)
+		return
+	}
+	respondJSON(w, http.StatusOK, user)
+}
+
+type UpdateUserRoleRequest struct {
+	Role models.UserRole `json:"role"`
+}
+
+// UpdateUserRole grants or removes the admin role (admin only). The user's
+// access and refresh tokens are revoked, so the role takes effect when they
+// next log in.
+func (h *AdminHandlers) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Role != models.UserRoleUser && req.Role != models.UserRoleAdmin {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("role must be %q or %q", models.UserRoleUser, models.UserRoleAdmin))
+		return
+	}
+
+	// Keeps the last admin from locking everyone out
+	if userID.String() == getUserID(r.Context()) {
+		respondError(w, http.StatusBadRequest, "Admins cannot change their own role")
+		return
+	}
+
+	if err := h.authService.RevokeUserRefreshTokens(r.Context(), userID); err != nil {
+		slog.Error("Failed to revoke refresh tokens", "target_user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	if err := h.userRepo.SetRole(r.Context(), userID, req.Role, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			respondNotFound(w)
+			return
+		}
+		slog.Error("Failed to update user role", "target_user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	slog.Warn("Admin changed user role",
+		"audit_event", "admin.user_role_changed",
+		"user_id", getUserID(r.Context()),
+		"target_user_id", userID,
+		"role", req.Role,
+		"correlation_id", getCorrelationID(r.Context()))
+
+	user, err := h.userRepo.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondNotFound(w)
+		return
+	}
+	respondJSON(w, http.StatusOK, user)
+}
+
+type SweepRetentionRequest struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// SweepRetention runs the retention sweep now instead of waiting for the
+// worker's next daily run (admin only). A dry run reports what would be
+// deleted without deleting it. An empty body runs a real sweep.
+func (h *AdminHandlers) SweepRetention(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r, h.userRepo) {
+		return
+	}
+
+	var req SweepRetentionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if h.retention == nil {
+		respondError(w, http.StatusServiceUnavailable, "Retention sweep is not configured")
+		return
+	}
+
+	report := h.retention.Sweep(r.Context(), getUserID(r.Context()), req.DryRun)
+
+	slog.Warn("Admin ran retention sweep",
+		"audit_event", "admin.retention_swept",
+		"user_id", getUserID(r.Context()),
+		"dry_run", req.DryRun,
+		"correlation_id", getCorrelationID(r.Context()))
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
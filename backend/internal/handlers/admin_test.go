@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// countingRetentionStore has rows in every phase and counts deletes
+type countingRetentionStore struct {
+	deletes int
+}
+
+func (s *countingRetentionStore) CountExpiredJobs(ctx context.Context, before time.Time) (int64, error) {
+	return 3, nil
+}
+func (s *countingRetentionStore) DeleteExpiredJobs(ctx context.Context, before time.Time, limit int) (int64, error) {
+	s.deletes++
+	return 0, nil
+}
+func (s *countingRetentionStore) CountOrphanedBlueprintRevisions(ctx context.Context) (int64, error) {
+	return 2, nil
+}
+func (s *countingRetentionStore) DeleteOrphanedBlueprintRevisions(ctx context.Context, limit int) (int64, error) {
+	s.deletes++
+	return 0, nil
+}
+func (s *countingRetentionStore) CountOrphanedBidRevisions(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+func (s *countingRetentionStore) DeleteOrphanedBidRevisions(ctx context.Context, limit int) (int64, error) {
+	s.deletes++
+	return 0, nil
+}
+func (s *countingRetentionStore) CountExpiredDrafts(ctx context.Context, now time.Time) (int64, error) {
+	return 1, nil
+}
+func (s *countingRetentionStore) DeleteExpiredDrafts(ctx context.Context, now time.Time, limit int) (int64, error) {
+	s.deletes++
+	return 0, nil
+}
+
+func TestSweepRetention(t *testing.T) {
+	adminID, userID := uuid.New(), uuid.New()
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{
+		adminID: {ID: adminID, Role: models.UserRoleAdmin},
+		userID:  {ID: userID, Role: models.UserRoleUser},
+	}}
+	store := &countingRetentionStore{}
+	h := NewAdminHandlers(users, nil, nil, events.Discard, services.NewRetentionSweeper(store, config.RetentionConfig{
+		JobRetention: 90 * 24 * time.Hour,
+		Interval:     24 * time.Hour,
+		BatchSize:    1000,
+	}))
+	router := chi.NewRouter()
+	h.Routes(router)
+
+	if rec := serveAsUser(router, userID, http.MethodPost, "/api/admin/retention/sweep", `{"dry_run": true}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-admin sweep: status = %d, want 403", rec.Code)
+	}
+
+	rec := serveAsUser(router, adminID, http.MethodPost, "/api/admin/retention/sweep", `{"dry_run": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dry run: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var report models.RetentionReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if !report.DryRun || len(report.Phases) != 4 || report.Phases[0].Rows != 3 || store.deletes != 0 {
+		t.Errorf("dry run report = %+v with %d deletes, want counts and nothing deleted", report, store.deletes)
+	}
+
+	// An empty body is a real sweep
+	if rec := serveAsUser(router, adminID, http.MethodPost, "/api/admin/retention/sweep", ""); rec.Code != http.StatusOK {
+		t.Fatalf("sweep: status = %d, want 200", rec.Code)
+	}
+	if store.deletes != 4 {
+		t.Errorf("sweep ran %d deletes, want one per phase", store.deletes)
+	}
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	adminID, userID := uuid.New(), uuid.New()
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{
+		adminID: {ID: adminID, Role: models.UserRoleAdmin},
+		userID:  {ID: userID, Role: models.UserRoleUser},
+	}}
+	authService, refreshTokens := newAdminTestAuthService(userID, adminID)
+	h := NewAdminHandlers(users, authService, nil, events.Discard, nil)
+	router := chi.NewRouter()
+	h.Routes(router)
+	path := "/api/admin/users/" + userID.String() + "/role"
+
+	if rec := serveAsUser(router, userID, http.MethodPatch, path, `{"role":"admin"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-admin promotion: status = %d, want 403", rec.Code)
+	}
+	if rec := serveAsUser(router, adminID, http.MethodPatch, path, `{"role":"owner"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown role: status = %d, want 400", rec.Code)
+	}
+	if rec := serveAsUser(router, adminID, http.MethodPatch, "/api/admin/users/"+adminID.String()+"/role", `{"role":"user"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("own role: status = %d, want 400", rec.Code)
+	}
+	if rec := serveAsUser(router, adminID, http.MethodPatch, "/api/admin/users/"+uuid.New().String()+"/role", `{"role":"admin"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown user: status = %d, want 404", rec.Code)
+	}
+
+	rec := serveAsUser(router, adminID, http.MethodPatch, path, `{"role":"admin"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("promotion: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var user models.User
+	if err := json.NewDecoder(rec.Body).Decode(&user); err != nil {
+		t.Fatalf("failed to decode user: %v", err)
+	}
+	if user.Role != models.UserRoleAdmin {
+		t.Errorf("role = %q, want admin", user.Role)
+	}
+	// Tokens carrying the old role stop working
+	if _, ok := users.revokedAt[userID]; !ok {
+		t.Error("promotion did not revoke the user's tokens")
+	}
+	assertRefreshTokensRevoked(t, refreshTokens, userID)
+}
+
+// newAdminTestAuthService returns an auth service holding a live refresh
+// token for each user
+func newAdminTestAuthService(userIDs ...uuid.UUID) (*services.AuthService, *fakeRefreshTokenStore) {
+	store := &fakeRefreshTokenStore{tokens: make(map[string]*models.RefreshToken)}
+	for _, userID := range userIDs {
+		hash := uuid.NewString()
+		store.tokens[hash] = &models.RefreshToken{ID: uuid.New(), UserID: userID, TokenHash: hash, ExpiresAt: models.NewTimestamp(time.Now().Add(time.Hour))}
+	}
+	authService := services.NewAuthService("test-secret", time.Hour).WithRefreshTokens(store, time.Hour)
+	return authService, store
+}
+
+// assertRefreshTokensRevoked fails t unless exactly userID's refresh tokens
+// are revoked
+func assertRefreshTokensRevoked(t *testing.T, store *fakeRefreshTokenStore, userID uuid.UUID) {
+	t.Helper()
+	for _, token := range store.tokens {
+		if revoked := token.RevokedAt != nil; revoked != (token.UserID == userID) {
+			t.Errorf("refresh token of %s revoked = %v, want only %s's revoked", token.UserID, revoked, userID)
+		}
+	}
+}
+
+func TestSuspendUser(t *testing.T) {
+	adminID, userID := uuid.New(), uuid.New()
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{
+		adminID: {ID: adminID, Role: models.UserRoleAdmin},
+		userID:  {ID: userID, Role: models.UserRoleUser},
+	}}
+	authService, refreshTokens := newAdminTestAuthService(userID, adminID)
+	h := NewAdminHandlers(users, authService, nil, events.Discard, nil)
+	router := chi.NewRouter()
+	h.Routes(router)
+	path := "/api/admin/users/" + userID.String()
+
+	if rec := serveAsUser(router, userID, http.MethodPost, path+"/suspend", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-admin suspension: status = %d, want 403", rec.Code)
+	}
+	if rec := serveAsUser(router, adminID, http.MethodPost, "/api/admin/users/"+adminID.String()+"/suspend", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("own suspension: status = %d, want 400", rec.Code)
+	}
+	for _, token := range refreshTokens.tokens {
+		if token.RevokedAt != nil {
+			t.Fatalf("refused suspensions revoked %s's refresh token", token.UserID)
+		}
+	}
+
+	if rec := serveAsUser(router, adminID, http.MethodPost, path+"/suspend", ""); rec.Code != http.StatusOK {
+		t.Fatalf("suspension: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if !users.users[userID].Suspended {
+		t.Error("user not suspended")
+	}
+	// Refresh tokens stop working along with access tokens
+	assertRefreshTokensRevoked(t, refreshTokens, userID)
+
+	if rec := serveAsUser(router, adminID, http.MethodPost, path+"/unsuspend", ""); rec.Code != http.StatusOK || users.users[userID].Suspended {
+		t.Errorf("unsuspension: status = %d, suspended %v; want 200 and reinstated", rec.Code, users.users[userID].Suspended)
+	}
+}
@@ -2,26 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
 )
 
-// GetBlueprintAnalysis returns the normalized analysis data for a blueprint
-func (h *Handler) GetBlueprintAnalysis(w http.ResponseWriter, r *http.Request) {
-	blueprintID, err := uuid.Parse(chi.URLParam(r, "id"))
+// GetBlueprintAnalysis returns the analysis data for a blueprint as the AI
+// service returned it, or with normalized=true in a takeoff-ready shape:
+// measurements in feet or SF, room dimensions parsed into sides, and a
+// warning for each value that could not be converted
+func (h *BlueprintHandlers) GetBlueprintAnalysis(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid blueprint ID")
+		respondInvalidID(w)
 		return
 	}
 
 	// Get blueprint record
-	blueprint, err := h.blueprintRepo.GetByID(r.Context(), blueprintID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "Blueprint not found")
+	blueprint, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
 		return
 	}
 
@@ -37,22 +38,33 @@ func (h *Handler) GetBlueprintAnalysis(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "Failed to parse analysis data")
 		return
 	}
+	if blueprint.AnalysisModel != nil {
+		analysisResult.AnalysisModel = blueprint.AnalysisModel
+	}
+	if r.URL.Query().Get("normalized") == "true" {
+		services.NewAnalysisNormalizationService().Normalize(&analysisResult)
+	}
 
 	respondJSON(w, http.StatusOK, analysisResult)
 }
 
-// GetBlueprintTakeoffSummary returns the calculated takeoff summary for a blueprint
-func (h *Handler) GetBlueprintTakeoffSummary(w http.ResponseWriter, r *http.Request) {
-	blueprintID, err := uuid.Parse(chi.URLParam(r, "id"))
+// GetBlueprintTakeoffSummary returns the calculated takeoff summary for a
+// blueprint in the unit system named by the units query parameter
+func (h *BlueprintHandlers) GetBlueprintTakeoffSummary(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid blueprint ID")
+		respondInvalidID(w)
+		return
+	}
+
+	units, ok := requestUnitSystem(w, r, h.userRepo)
+	if !ok {
 		return
 	}
 
 	// Get blueprint record
-	blueprint, err := h.blueprintRepo.GetByID(r.Context(), blueprintID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "Blueprint not found")
+	blueprint, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
 		return
 	}
 
@@ -70,12 +82,83 @@ func (h *Handler) GetBlueprintTakeoffSummary(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Calculate takeoff summary
+	// Calculate takeoff summary, with the estimator's adjustments applied on
+	// top of the AI quantities
+	adjustments := services.ApplyAdjustments(analysisResult, blueprint.TakeoffAdjustments)
 	summary, err := takeoffService.CalculateTakeoffSummary(analysisResult)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to calculate takeoff summary")
 		return
 	}
+	adjustments.Annotate(summary)
+	services.NewUnitConversionService(units).ConvertTakeoffSummary(summary)
+
+	respondJSON(w, http.StatusOK, summary)
+}
+
+// GetProjectTakeoffSummary merges the takeoff of every analyzed blueprint in a
+// project using discipline-aware rules, reporting which sheet each aggregate
+// came from
+func (h *BlueprintHandlers) GetProjectTakeoffSummary(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	units, ok := requestUnitSystem(w, r, h.userRepo)
+	if !ok {
+		return
+	}
+
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return
+	}
+
+	blueprints, err := h.blueprintRepo.GetByProjectID(r.Context(), project.ID)
+	if err != nil {
+		slog.Error("Failed to get project blueprints", "project_id", project.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get blueprints")
+		return
+	}
+
+	takeoffService := services.NewTakeoffService()
+	var sheets []services.SheetAnalysis
+	var adjustments services.TakeoffAdjustmentResult
+	for _, bp := range blueprints {
+		if bp.AnalysisData == nil || *bp.AnalysisData == "" {
+			continue
+		}
+		analysis, err := takeoffService.ParseAnalysisData(*bp.AnalysisData)
+		if err != nil {
+			slog.Warn("Skipping blueprint with unparseable analysis", "blueprint_id", bp.ID, "error", err)
+			continue
+		}
+		adjustments.Merge(services.ApplyAdjustments(analysis, bp.TakeoffAdjustments))
+		sheets = append(sheets, services.SheetAnalysis{
+			Sheet: models.SheetRef{
+				BlueprintID: bp.ID,
+				Filename:    bp.Filename,
+				SheetType:   services.InferSheetType(bp, analysis),
+			},
+			Analysis: analysis,
+		})
+	}
+
+	if len(sheets) == 0 {
+		respondError(w, http.StatusNotFound, "No analyzed blueprints in project")
+		return
+	}
+
+	summary, err := takeoffService.MergeAnalyses(sheets)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to calculate takeoff summary")
+		return
+	}
+	summary.ProjectID = project.ID
+	adjustments.Annotate(summary.Takeoff)
+	services.NewUnitConversionService(units).ConvertTakeoffSummary(summary.Takeoff)
 
 	respondJSON(w, http.StatusOK, summary)
 }
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// analyticsCacheTTL is how long a company analytics response is reused
+const analyticsCacheTTL = time.Hour
+
+// ResponseCache stores encoded responses by key; services.RedisClient
+// implements it
+type ResponseCache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	IsAvailable() bool
+}
+
+// AnalyticsHandlers serves company-level bid analytics
+type AnalyticsHandlers struct {
+	bidRepo BidAnalyticsStore
+	cache   ResponseCache
+}
+
+// NewAnalyticsHandlers creates the analytics handlers. cache may be nil to
+// aggregate on every request.
+func NewAnalyticsHandlers(bidRepo BidAnalyticsStore, cache ResponseCache) *AnalyticsHandlers {
+	return &AnalyticsHandlers{bidRepo: bidRepo, cache: cache}
+}
+
+// Routes registers the analytics routes
+func (h *AnalyticsHandlers) Routes(r chi.Router) {
+	r.Get("/api/company/analytics", h.GetCompanyAnalytics)
+}
+
+// GetCompanyAnalytics aggregates the user's bids between the from and to
+// query dates (YYYY-MM-DD, both inclusive, or RFC 3339 instants) grouped by
+// week, month, quarter or year. The range defaults to the last 12 months
+// including the current one. Responses are cached for an hour.
+func (h *AnalyticsHandlers) GetCompanyAnalytics(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, err := parseAnalyticsFilter(r.URL.Query(), *userID, time.Now())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cacheKey := analyticsCacheKey(filter)
+	if h.cache != nil && h.cache.IsAvailable() {
+		if cached, err := h.cache.Get(r.Context(), cacheKey); err == nil {
+			respondJSON(w, http.StatusOK, json.RawMessage(cached))
+			return
+		}
+	}
+
+	analytics, err := h.bidRepo.GetCompanyAnalytics(r.Context(), filter)
+	if err != nil {
+		slog.Error("Failed to get company analytics", "user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get analytics")
+		return
+	}
+
+	if h.cache != nil && h.cache.IsAvailable() {
+		if data, err := json.Marshal(analytics); err == nil {
+			if err := h.cache.Set(r.Context(), cacheKey, data, analyticsCacheTTL); err != nil {
+				slog.Warn("Failed to cache company analytics", "error", err)
+			}
+		}
+	}
+
+	respondJSON(w, http.StatusOK, analytics)
+}
+
+// parseAnalyticsFilter reads from, to and group_by. Date-only bounds are
+// whole UTC days, so the default range ends at the start of tomorrow and
+// repeated requests on the same day share a cache entry.
+func parseAnalyticsFilter(query url.Values, userID uuid.UUID, now time.Time) (models.CompanyAnalyticsFilter, error) {
+	filter := models.CompanyAnalyticsFilter{UserID: userID, GroupBy: models.AnalyticsGroupByMonth}
+	if groupBy := query.Get("group_by"); groupBy != "" {
+		switch groupBy {
+		case models.AnalyticsGroupByWeek, models.AnalyticsGroupByMonth, models.AnalyticsGroupByQuarter, models.AnalyticsGroupByYear:
+			filter.GroupBy = groupBy
+		default:
+			return filter, fmt.Errorf("group_by must be week, month, quarter or year")
+		}
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	filter.To = today.AddDate(0, 0, 1)
+	if value := query.Get("to"); value != "" {
+		to, err := parseAnalyticsTime(value, true)
+		if err != nil {
+			return filter, fmt.Errorf("to must be a date (YYYY-MM-DD) or RFC 3339 time")
+		}
+		filter.To = to
+	}
+
+	filter.From = time.Date(today.Year(), today.Month()-11, 1, 0, 0, 0, 0, time.UTC)
+	if value := query.Get("from"); value != "" {
+		from, err := parseAnalyticsTime(value, false)
+		if err != nil {
+			return filter, fmt.Errorf("from must be a date (YYYY-MM-DD) or RFC 3339 time")
+		}
+		filter.From = from
+	}
+
+	if !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+	return filter, nil
+}
+
+// parseAnalyticsTime parses a date or RFC 3339 time as UTC. An end date
+// covers the whole day, so it becomes the start of the next.
+func parseAnalyticsTime(value string, end bool) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		if end {
+			date = date.AddDate(0, 0, 1)
+		}
+		return date, nil
+	}
+	instant, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return instant.UTC(), nil
+}
+
+func analyticsCacheKey(filter models.CompanyAnalyticsFilter) string {
+	return fmt.Sprintf("analytics:company:%s:%s:%d:%d", filter.UserID, filter.GroupBy, filter.From.Unix(), filter.To.Unix())
+}
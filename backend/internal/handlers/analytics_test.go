@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type fakeAnalyticsStore struct {
+	calls   int
+	filters []models.CompanyAnalyticsFilter
+}
+
+func (f *fakeAnalyticsStore) GetCompanyAnalytics(ctx context.Context, filter models.CompanyAnalyticsFilter) (*models.CompanyAnalytics, error) {
+	f.calls++
+	f.filters = append(f.filters, filter)
+	return &models.CompanyAnalytics{
+		From:    models.NewTimestamp(filter.From),
+		To:      models.NewTimestamp(filter.To),
+		GroupBy: filter.GroupBy,
+		Totals:  models.BidAggregate{CountsByStatus: map[models.BidStatus]int{}, TradeMix: []models.TradeCostShare{}},
+		Periods: []models.BidAggregate{},
+	}, nil
+}
+
+type fakeResponseCache struct {
+	entries map[string]string
+	ttls    map[string]time.Duration
+}
+
+func (f *fakeResponseCache) Get(ctx context.Context, key string) (string, error) {
+	if value, ok := f.entries[key]; ok {
+		return value, nil
+	}
+	return "", errors.New("cache miss")
+}
+
+func (f *fakeResponseCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	f.entries[key] = string(value.([]byte))
+	f.ttls[key] = ttl
+	return nil
+}
+
+func (f *fakeResponseCache) IsAvailable() bool { return true }
+
+func TestParseAnalyticsFilter(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2024, 6, 15, 13, 30, 0, 0, time.UTC)
+
+	filter, err := parseAnalyticsFilter(url.Values{}, userID, now)
+	if err != nil {
+		t.Fatalf("parseAnalyticsFilter() error = %v", err)
+	}
+	if filter.GroupBy != models.AnalyticsGroupByMonth || filter.UserID != userID {
+		t.Errorf("filter = %+v, want monthly for the user", filter)
+	}
+	if want := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC); !filter.From.Equal(want) {
+		t.Errorf("default from = %v, want %v", filter.From, want)
+	}
+	if want := time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC); !filter.To.Equal(want) {
+		t.Errorf("default to = %v, want the start of tomorrow %v", filter.To, want)
+	}
+
+	filter, err = parseAnalyticsFilter(url.Values{"from": {"2024-01-01"}, "to": {"2024-03-31"}, "group_by": {"quarter"}}, userID, now)
+	if err != nil {
+		t.Fatalf("parseAnalyticsFilter() error = %v", err)
+	}
+	if !filter.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !filter.To.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) || filter.GroupBy != "quarter" {
+		t.Errorf("filter = %+v, want Q1 2024 with an inclusive end date", filter)
+	}
+
+	invalid := []url.Values{
+		{"group_by": {"decade"}},
+		{"from": {"January"}},
+		{"to": {"2024-13-01"}},
+		{"from": {"2024-03-01"}, "to": {"2024-02-01"}},
+	}
+	for _, query := range invalid {
+		if _, err := parseAnalyticsFilter(query, userID, now); err == nil {
+			t.Errorf("parseAnalyticsFilter(%v) succeeded, want an error", query)
+		}
+	}
+}
+
+func TestGetCompanyAnalytics_CachesResponses(t *testing.T) {
+	store := &fakeAnalyticsStore{}
+	cache := &fakeResponseCache{entries: map[string]string{}, ttls: map[string]time.Duration{}}
+	router := chi.NewRouter()
+	NewAnalyticsHandlers(store, cache).Routes(router)
+
+	userID := uuid.New()
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/company/analytics"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, userID.String()))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		rec := get("?from=2024-01-01&to=2024-12-31")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+		}
+		var analytics models.CompanyAnalytics
+		if err := json.NewDecoder(rec.Body).Decode(&analytics); err != nil {
+			t.Fatalf("failed to decode analytics: %v", err)
+		}
+		if analytics.GroupBy != "month" || analytics.Periods == nil || analytics.Totals.BidCount != 0 {
+			t.Errorf("analytics = %+v, want an empty monthly range", analytics)
+		}
+	}
+	if store.calls != 1 {
+		t.Errorf("store called %d times, want the second request served from cache", store.calls)
+	}
+	if store.filters[0].UserID != userID {
+		t.Errorf("filter user = %v, want the requesting user", store.filters[0].UserID)
+	}
+	for _, ttl := range cache.ttls {
+		if ttl != time.Hour {
+			t.Errorf("cache TTL = %v, want an hour", ttl)
+		}
+	}
+
+	if rec := get("?group_by=day"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid group_by: status = %d, want 400", rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/company/analytics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", rec.Code)
+	}
+}
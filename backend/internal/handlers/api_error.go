@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+)
+
+// Generic error codes, one per status, for failures without a more specific
+// code. Middleware that rejects requests shares these codes.
+const (
+	CodeBadRequest      = middleware.CodeBadRequest
+	CodeUnauthenticated = middleware.CodeUnauthenticated
+	CodeForbidden       = "FORBIDDEN"
+	CodeConflict        = "CONFLICT"
+	CodeRateLimited     = middleware.CodeRateLimited
+	CodeInternalError   = middleware.CodeInternalError
+)
+
+// Error codes for malformed requests. Invalid and missing parameter errors
+// name the parameter in their details.
+const (
+	CodeInvalidRequestBody = "INVALID_REQUEST_BODY"
+	CodeValidationFailed   = "VALIDATION_FAILED"
+	CodeInvalidParameter   = "INVALID_PARAMETER"
+	CodeMissingParameter   = "MISSING_PARAMETER"
+)
+
+// Error codes for blueprints that can't be priced or bid from
+const (
+	CodeBlueprintNotAnalyzed  = "BLUEPRINT_NOT_ANALYZED"
+	CodeBlueprintNotInProject = "BLUEPRINT_NOT_IN_PROJECT"
+	CodeNoAnalyzedBlueprints  = "NO_ANALYZED_BLUEPRINTS"
+	CodeTooManyBlueprints     = "TOO_MANY_BLUEPRINTS"
+	CodeBlueprintPageMissing  = "BLUEPRINT_PAGE_MISSING"
+	CodeBidDataUnavailable    = "BID_DATA_UNAVAILABLE"
+	CodeOverBudget            = "OVER_BUDGET"
+)
+
+// Error codes for authentication and accounts
+const (
+	CodeInvalidCredentials  = "INVALID_CREDENTIALS"
+	CodeInvalidRefreshToken = "INVALID_REFRESH_TOKEN"
+	CodeEmailExists         = "EMAIL_EXISTS"
+	CodeWeakPassword        = "WEAK_PASSWORD"
+)
+
+// Error codes for pricing overrides, storage and revisions
+const (
+	CodeOverrideExists     = "OVERRIDE_EXISTS"
+	CodeStorageUnavailable = "STORAGE_UNAVAILABLE"
+	CodeRevisionIsLatest   = "REVISION_IS_LATEST"
+	CodeBidAccepted        = "BID_ACCEPTED"
+)
+
+// Error codes for bid status changes and the job queue. A refused transition
+// lists the allowed ones in the details, and a full queue its depth, limit
+// and retry guidance.
+const (
+	CodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+	CodeQueueFull               = "QUEUE_FULL"
+)
+
+// Error codes for the cost database. Removing a material or labor rate that
+// company overrides depend on lists the override IDs in the details.
+const (
+	CodeMaterialExists        = "MATERIAL_EXISTS"
+	CodeLaborRateExists       = "LABOR_RATE_EXISTS"
+	CodeReferencedByOverrides = "REFERENCED_BY_OVERRIDES"
+)
+
+// APIError is the body of an error response: a stable machine-readable code,
+// a message for people and optional details such as the offending parameter
+type APIError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// apiErrorBody is an APIError as written. Error repeats the message for
+// clients that predate codes, and the correlation ID ties the response to
+// the server's logs.
+type apiErrorBody struct {
+	Error string `json:"error"`
+	APIError
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// respondAPIError writes an error response with code and message
+func respondAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeAPIError(w, status, &APIError{Code: code, Message: message})
+}
+
+// respondAPIErrorDetails writes an error response with code, message and
+// details
+func respondAPIErrorDetails(w http.ResponseWriter, status int, code, message string, details map[string]interface{}) {
+	writeAPIError(w, status, &APIError{Code: code, Message: message, Details: details})
+}
+
+// respondInvalidParam writes the 400 response for an invalid query parameter
+func respondInvalidParam(w http.ResponseWriter, param, message string) {
+	respondAPIErrorDetails(w, http.StatusBadRequest, CodeInvalidParameter, message, map[string]interface{}{"param": param})
+}
+
+// respondMissingParam writes the 400 response for a missing query parameter
+func respondMissingParam(w http.ResponseWriter, param, message string) {
+	respondAPIErrorDetails(w, http.StatusBadRequest, CodeMissingParameter, message, map[string]interface{}{"param": param})
+}
+
+// writeAPIError writes apiErr with status. The correlation ID is read from
+// the response header the CorrelationID middleware sets before any handler
+// runs.
+func writeAPIError(w http.ResponseWriter, status int, apiErr *APIError) {
+	respondJSON(w, status, apiErrorBody{
+		Error:         apiErr.Message,
+		APIError:      *apiErr,
+		CorrelationID: w.Header().Get(middleware.CorrelationIDHeader),
+	})
+}
+
+// statusErrorCode is the generic code for an error status
+func statusErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeResourceNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusInternalServerError:
+		return CodeInternalError
+	}
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+// repositoryErrors map repository sentinel errors to the status, code and
+// message they are reported with
+var repositoryErrors = []struct {
+	err     error
+	status  int
+	code    string
+	message string
+}{
+	{pgx.ErrNoRows, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrAPIKeyNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrBidShareNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrBlueprintNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrCompanyProfileNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrProjectPricingOverrideNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrRefreshTokenNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrRegionalAdjustmentNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrUserNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrWebhookNotFound, http.StatusNotFound, CodeResourceNotFound, "Resource not found"},
+	{repository.ErrEmailAlreadyExists, http.StatusConflict, CodeEmailExists, "Email already exists"},
+	{repository.ErrProjectPricingOverrideExists, http.StatusConflict, CodeOverrideExists, "Override already exists for this item"},
+	{repository.ErrMaterialExists, http.StatusConflict, CodeMaterialExists, "A material with this name already exists in the region"},
+	{repository.ErrLaborRateExists, http.StatusConflict, CodeLaborRateExists, "A labor rate for this trade already exists in the region"},
+}
+
+// isRepositoryError reports whether err is one of the repository sentinel
+// errors respondRepositoryError maps
+func isRepositoryError(err error) bool {
+	for _, mapped := range repositoryErrors {
+		if errors.Is(err, mapped.err) {
+			return true
+		}
+	}
+	return false
+}
+
+// respondRepositoryError writes the response for a repository error: the
+// mapped status, code and message for known sentinel errors, so missing rows
+// get the uniform 404, and a 500 with message otherwise
+func respondRepositoryError(w http.ResponseWriter, err error, message string) {
+	for _, mapped := range repositoryErrors {
+		if errors.Is(err, mapped.err) {
+			respondAPIError(w, mapped.status, mapped.code, mapped.message)
+			return
+		}
+	}
+	respondAPIError(w, http.StatusInternalServerError, CodeInternalError, message)
+}
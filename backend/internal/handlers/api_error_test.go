@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAPIErrorCodes(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	empty := &models.Project{ID: uuid.New(), UserID: userID}
+	analysis := `{"rooms":[{"name":"Office","dimensions":"10x20","area":200}],"confidence_score":0.9}`
+	pending := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID}
+	foreign := &models.Blueprint{ID: uuid.New(), ProjectID: uuid.New(), AnalysisData: &analysis}
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, Version: 1}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse battery"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	user := &models.User{ID: userID, Email: "jane@example.com", PasswordHash: string(hash)}
+
+	projects := &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project, empty.ID: empty}}
+	blueprints := &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{pending.ID: pending, foreign.ID: foreign}}
+	bids := &fakeBidStore{bids: []*models.Bid{bid}}
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{userID: user}}
+	authService := services.NewAuthService(authTestSecret, time.Hour).
+		WithBcryptCost(bcrypt.MinCost).
+		WithRefreshTokens(&fakeRefreshTokenStore{tokens: make(map[string]*models.RefreshToken)}, 24*time.Hour)
+
+	router := chi.NewRouter()
+	router.Use(middleware.CorrelationID)
+	(&BidHandlers{
+		PricingSources: NewPricingSources(nil, nil, nil, nil, nil, nil),
+		projectRepo:    projects,
+		blueprintRepo:  blueprints,
+		bidRepo:        bids,
+		userRepo:       users,
+		config:         &config.Config{},
+	}).Routes(router)
+	NewRevisionHandlers(projects, blueprints, &fakeBlueprintRevisionStore{}, nil, bids, &fakeBidRevisionStore{}, users, nil, nil).Routes(router)
+	NewAuthHandlers(users, authService, nil).PublicRoutes(router)
+	NewCostHandlers(&PricingSources{}, nil, events.Discard).Routes(router)
+
+	projectPath := "/projects/" + project.ID.String()
+	bidPath := "/bids/" + bid.ID.String()
+	tests := []struct {
+		name         string
+		anonymous    bool
+		method, path string
+		body         string
+		status       int
+		code         string
+		param        string
+	}{
+		{"malformed ID", false, http.MethodGet, "/projects/not-a-uuid/bids", "", http.StatusBadRequest, CodeInvalidID, ""},
+		{"unknown project", false, http.MethodGet, "/projects/" + uuid.NewString() + "/bids", "", http.StatusNotFound, CodeResourceNotFound, ""},
+		{"invalid sort", false, http.MethodGet, projectPath + "/bids?sort=cheapest", "", http.StatusBadRequest, CodeInvalidParameter, "sort"},
+		{"missing blueprint_id", false, http.MethodGet, projectPath + "/pricing-summary", "", http.StatusBadRequest, CodeMissingParameter, "blueprint_id"},
+		{"unanalyzed blueprint", false, http.MethodGet, projectPath + "/pricing-summary?blueprint_id=" + pending.ID.String(), "", http.StatusBadRequest, CodeBlueprintNotAnalyzed, ""},
+		{"another project's blueprint", false, http.MethodGet, projectPath + "/pricing-summary?blueprint_id=" + foreign.ID.String(), "", http.StatusBadRequest, CodeBlueprintNotInProject, ""},
+		{"malformed bid request", false, http.MethodPost, projectPath + "/generate-bid", "{", http.StatusBadRequest, CodeInvalidRequestBody, ""},
+		{"nothing to bid from", false, http.MethodPost, "/projects/" + empty.ID.String() + "/generate-bid", "{}", http.StatusBadRequest, CodeNoAnalyzedBlueprints, ""},
+		{"rename without a name", false, http.MethodPatch, bidPath, "{}", http.StatusBadRequest, CodeValidationFailed, ""},
+		{"compare without versions", false, http.MethodGet, bidPath + "/compare?from=1", "", http.StatusBadRequest, CodeMissingParameter, "from,to"},
+		{"compare with a bad version", false, http.MethodGet, bidPath + "/compare?from=first&to=2", "", http.StatusBadRequest, CodeInvalidParameter, "from"},
+		{"weak password", true, http.MethodPost, "/auth/signup", `{"email":"new@example.com","password":"short"}`, http.StatusBadRequest, CodeWeakPassword, ""},
+		{"wrong password", true, http.MethodPost, "/auth/login", `{"email":"jane@example.com","password":"wrong horse battery"}`, http.StatusUnauthorized, CodeInvalidCredentials, ""},
+		{"unknown refresh token", true, http.MethodPost, "/auth/refresh", `{"refresh_token":"unknown"}`, http.StatusUnauthorized, CodeInvalidRefreshToken, ""},
+		{"anonymous override check", true, http.MethodGet, "/api/company/pricing-overrides/validate", "", http.StatusUnauthorized, CodeUnauthenticated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(middleware.CorrelationIDHeader, "corr-"+tt.name)
+			if !tt.anonymous {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, userID.String()))
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			var body struct {
+				Error string `json:"error"`
+				APIError
+				CorrelationID string `json:"correlation_id"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if rec.Code != tt.status || body.Code != tt.code {
+				t.Errorf("status = %d, code %q; want %d %q (%s)", rec.Code, body.Code, tt.status, tt.code, body.Message)
+			}
+			if body.Message == "" || body.Error != body.Message {
+				t.Errorf("error = %q, message %q; want the same message in both", body.Error, body.Message)
+			}
+			if body.CorrelationID != "corr-"+tt.name {
+				t.Errorf("correlation ID = %q, want the request's", body.CorrelationID)
+			}
+			if tt.param != "" && body.Details["param"] != tt.param {
+				t.Errorf("details = %v, want param %q", body.Details, tt.param)
+			}
+		})
+	}
+}
+
+func TestRespondRepositoryError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("failed to get bid: %w", pgx.ErrNoRows), http.StatusNotFound, CodeResourceNotFound},
+		{repository.ErrUserNotFound, http.StatusNotFound, CodeResourceNotFound},
+		{repository.ErrEmailAlreadyExists, http.StatusConflict, CodeEmailExists},
+		{repository.ErrProjectPricingOverrideExists, http.StatusConflict, CodeOverrideExists},
+		{context.DeadlineExceeded, http.StatusInternalServerError, CodeInternalError},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		respondRepositoryError(rec, tt.err, "Failed to save")
+		var body APIError
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if rec.Code != tt.status || body.Code != tt.code {
+			t.Errorf("%v: status = %d, code %q; want %d %q", tt.err, rec.Code, body.Code, tt.status, tt.code)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// maxAPIKeyNameLength matches the api_keys.name column
+const maxAPIKeyNameLength = 255
+
+// APIKeyHandlers lets users issue and revoke read-only API keys for external
+// integrations
+type APIKeyHandlers struct {
+	apiKeys *services.APIKeyService
+}
+
+func NewAPIKeyHandlers(apiKeys *services.APIKeyService) *APIKeyHandlers {
+	return &APIKeyHandlers{apiKeys: apiKeys}
+}
+
+// Routes registers the API key routes
+func (h *APIKeyHandlers) Routes(r chi.Router) {
+	r.Post("/api/company/api-keys", h.CreateAPIKey)
+	r.Get("/api/company/api-keys", h.ListAPIKeys)
+	r.Delete("/api/company/api-keys/{id}", h.RevokeAPIKey)
+}
+
+// CreateAPIKeyRequest names a new key and optionally when it stops working
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateAPIKeyResponse is the only response that includes the key itself
+type CreateAPIKeyResponse struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+// ListAPIKeysResponse lists a user's keys by prefix, without the keys
+type ListAPIKeysResponse struct {
+	APIKeys []*models.APIKey `json:"api_keys"`
+}
+
+func (h *APIKeyHandlers) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if utf8.RuneCountInString(req.Name) > maxAPIKeyNameLength {
+		respondError(w, http.StatusBadRequest, "name must be at most 255 characters")
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		respondError(w, http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	key, plain, err := h.apiKeys.Create(r.Context(), *userID, req.Name, req.ExpiresAt)
+	if err != nil {
+		slog.Error("Failed to create API key", "user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	slog.Info("API key created",
+		"audit_event", "api_key.created",
+		"user_id", userID,
+		"api_key_id", key.ID,
+		"prefix", key.Prefix,
+		"expires_at", key.ExpiresAt)
+
+	respondJSON(w, http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: plain})
+}
+
+func (h *APIKeyHandlers) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	keys, err := h.apiKeys.List(r.Context(), *userID)
+	if err != nil {
+		slog.Error("Failed to list API keys", "user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListAPIKeysResponse{APIKeys: keys})
+}
+
+func (h *APIKeyHandlers) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.apiKeys.Revoke(r.Context(), *userID, keyID); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			respondNotFound(w)
+			return
+		}
+		slog.Error("Failed to revoke API key", "api_key_id", keyID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+
+	slog.Info("API key revoked",
+		"audit_event", "api_key.revoked",
+		"user_id", userID,
+		"api_key_id", keyID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
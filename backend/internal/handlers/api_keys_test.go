@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// newAPIKeyTestRouter serves the API key and job routes behind the auth
+// middleware, and returns a JWT for the one user
+func newAPIKeyTestRouter(t *testing.T) (*fakeAPIKeyStore, *models.Blueprint, string, chi.Router) {
+	t.Helper()
+	user := &models.User{ID: uuid.New(), Email: "jane@example.com"}
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{user.ID: user}}
+	authService := services.NewAuthService(authTestSecret, time.Hour)
+	token, err := authService.GenerateToken(user.ID.String(), user.Email, user.Role)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	store := &fakeAPIKeyStore{}
+	blueprint := &models.Blueprint{ID: uuid.New(), UploadStatus: models.UploadStatusUploaded}
+	jobs := NewJobHandlers(&fakeProjectStore{}, &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}},
+		&fakeJobStore{}, &config.Config{})
+
+	// One service, as in main, so a revocation clears the cache auth reads
+	apiKeys := services.NewAPIKeyService(store, time.Minute)
+	router := chi.NewRouter()
+	router.Group(func(r chi.Router) {
+		r.Use(middleware.AuthWithAPIKeys(authService, users, apiKeys))
+		NewAPIKeyHandlers(apiKeys).Routes(r)
+		jobs.Routes(r)
+	})
+	return store, blueprint, token, router
+}
+
+func serveWithAuthorization(router chi.Router, method, target, authorization, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", authorization)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKeys_CreateListRevoke(t *testing.T) {
+	store, blueprint, token, router := newAPIKeyTestRouter(t)
+
+	rec := serveWithAuthorization(router, http.MethodPost, "/api/company/api-keys", "Bearer "+token, `{"name":" Dashboard "}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var created CreateAPIKeyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !strings.HasPrefix(created.Key, services.APIKeyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Fatalf("key %q does not start with its prefix %q", created.Key, created.Prefix)
+	}
+	if created.Name != "Dashboard" || created.Scope != models.APIKeyScopeReadOnly {
+		t.Errorf("created = %+v", created.APIKey)
+	}
+	if stored := store.keys[created.ID]; stored.KeyHash == "" || strings.Contains(stored.KeyHash, created.Key) {
+		t.Error("expected only a hash of the key stored")
+	}
+	apiKey := "ApiKey " + created.Key
+
+	// Reads work with the key and are counted against it
+	rec = serveWithAuthorization(router, http.MethodGet, "/api/company/api-keys", apiKey, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list with key status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), created.Key) || strings.Contains(rec.Body.String(), "key_hash") {
+		t.Errorf("list exposes the key: %s", rec.Body.String())
+	}
+	if used := store.keys[created.ID]; used.LastUsedAt == nil || used.RequestCount != 1 {
+		t.Errorf("after one request last_used_at = %v, count = %d", used.LastUsedAt, used.RequestCount)
+	}
+
+	// Mutating routes refuse the key
+	for _, target := range []string{"/api/company/api-keys", "/blueprints/" + blueprint.ID.String() + "/analyze"} {
+		rec = serveWithAuthorization(router, http.MethodPost, target, apiKey, `{"name":"escalate"}`)
+		var body map[string]string
+		json.NewDecoder(rec.Body).Decode(&body)
+		if rec.Code != http.StatusForbidden || body["code"] != middleware.CodeAPIKeyReadOnly {
+			t.Errorf("POST %s with key = %d %v, want 403 %s", target, rec.Code, body, middleware.CodeAPIKeyReadOnly)
+		}
+	}
+	if len(store.keys) != 1 {
+		t.Errorf("read-only key created %d keys", len(store.keys)-1)
+	}
+
+	rec = serveWithAuthorization(router, http.MethodDelete, "/api/company/api-keys/"+created.ID.String(), "Bearer "+token, "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec = serveWithAuthorization(router, http.MethodGet, "/api/company/api-keys", apiKey, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key status = %d, want 401", rec.Code)
+	}
+}
+
+func TestAPIKeys_CreateValidation(t *testing.T) {
+	_, _, token, router := newAPIKeyTestRouter(t)
+
+	for name, body := range map[string]string{
+		"missing name":    `{}`,
+		"long name":       `{"name":"` + strings.Repeat("k", maxAPIKeyNameLength+1) + `"}`,
+		"expired already": `{"name":"old","expires_at":"2020-01-01T00:00:00Z"}`,
+	} {
+		if rec := serveWithAuthorization(router, http.MethodPost, "/api/company/api-keys", "Bearer "+token, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, rec.Code)
+		}
+	}
+}
+
+func TestAPIKeys_RevokeOtherUsersKey(t *testing.T) {
+	store, _, token, router := newAPIKeyTestRouter(t)
+	other := &models.APIKey{ID: uuid.New(), UserID: uuid.New(), Prefix: "fom_otheruser"}
+	store.Create(context.Background(), other)
+
+	rec := serveWithAuthorization(router, http.MethodDelete, "/api/company/api-keys/"+other.ID.String(), "Bearer "+token, "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if store.keys[other.ID].RevokedAt != nil {
+		t.Error("another user's key was revoked")
+	}
+}
@@ -2,15 +2,78 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
 )
 
+// AuthHandlers serves signup, login and the current user's account
+type AuthHandlers struct {
+	userRepo              UserStore
+	authService           *services.AuthService
+	loginThrottle         *services.LoginThrottle
+	authRequestsPerMinute int
+	// Built on the first registration so every mount of the routes shares them
+	signupRateLimit  func(http.Handler) http.Handler
+	loginRateLimit   func(http.Handler) http.Handler
+	refreshRateLimit func(http.Handler) http.Handler
+}
+
+func NewAuthHandlers(userRepo UserStore, authService *services.AuthService, cfg *config.Config) *AuthHandlers {
+	// Signup and login each get their own strict per-IP limiter
+	authRequestsPerMinute := 0
+	if cfg != nil && cfg.RateLimit.Enabled {
+		authRequestsPerMinute = cfg.RateLimit.AuthRequestsPerMinute
+	}
+	return &AuthHandlers{
+		userRepo:              userRepo,
+		authService:           authService,
+		loginThrottle:         newLoginThrottle(cfg),
+		authRequestsPerMinute: authRequestsPerMinute,
+	}
+}
+
+// PublicRoutes registers the unauthenticated signup, login, refresh and
+// logout routes
+func (h *AuthHandlers) PublicRoutes(r chi.Router) {
+	if h.signupRateLimit == nil {
+		h.signupRateLimit = middleware.AuthRateLimit(h.authRequestsPerMinute)
+		h.loginRateLimit = middleware.AuthRateLimit(h.authRequestsPerMinute)
+		h.refreshRateLimit = middleware.AuthRateLimit(h.authRequestsPerMinute)
+	}
+	r.With(h.signupRateLimit).Post("/auth/signup", h.Signup)
+	r.With(h.loginRateLimit).Post("/auth/login", h.Login)
+	// Refresh and logout work with an expired access token, so they are
+	// authenticated by the refresh token alone
+	r.With(h.refreshRateLimit).Post("/auth/refresh", h.Refresh)
+	r.With(h.refreshRateLimit).Post("/auth/logout", h.Logout)
+}
+
+// Routes registers the authenticated user routes
+func (h *AuthHandlers) Routes(r chi.Router) {
+	r.Get("/auth/me", h.GetCurrentUser)
+	r.Put("/auth/me/bid-defaults", h.UpdateBidDefaults)
+	r.Put("/auth/me/revision-settings", h.UpdateRevisionSettings)
+	r.Put("/auth/me/unit-system", h.UpdateUnitSystem)
+	r.Get("/auth/me/quickbooks-items", h.GetQuickBooksItems)
+	r.Put("/auth/me/quickbooks-items", h.UpdateQuickBooksItems)
+	r.Put("/auth/me/tax-settings", h.UpdateTaxSettings)
+	r.Put("/auth/me/impact-thresholds", h.UpdateImpactThresholds)
+	r.Post("/auth/change-password", h.ChangePassword)
+}
+
 type SignupRequest struct {
 	Email       string  `json:"email"`
 	Password    string  `json:"password"`
@@ -23,22 +86,86 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// RefreshTokenRequest carries the refresh token to rotate or revoke
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthResponse carries a new access and refresh token pair. Token repeats
+// the access token for clients that predate refresh tokens.
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token        string       `json:"token"`
+	AccessToken  string       `json:"access_token"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	ExpiresIn    int          `json:"expires_in"` // Seconds until the access token expires
+	User         UserResponse `json:"user"`
+}
+
+// newAuthResponse pairs issued tokens with the user they were issued to
+func newAuthResponse(tokens *services.TokenPair, user *models.User) AuthResponse {
+	return AuthResponse{
+		Token:        tokens.AccessToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+		User: UserResponse{
+			ID:          user.ID.String(),
+			Email:       user.Email,
+			Name:        user.Name,
+			CompanyName: user.CompanyName,
+			CreatedAt:   user.CreatedAt,
+			UpdatedAt:   user.UpdatedAt,
+		},
+	}
 }
 
 type UserResponse struct {
-	ID          string  `json:"id"`
-	Email       string  `json:"email"`
-	Name        *string `json:"name"`
-	CompanyName *string `json:"company_name"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
+	ID                     string            `json:"id"`
+	Email                  string            `json:"email"`
+	Name                   *string           `json:"name"`
+	CompanyName            *string           `json:"company_name"`
+	DefaultInclusions      []string          `json:"default_inclusions,omitempty"`
+	DefaultExclusions      []string          `json:"default_exclusions,omitempty"`
+	AutoBlueprintRevisions *bool             `json:"auto_blueprint_revisions,omitempty"`
+	UnitSystem             models.UnitSystem `json:"unit_system,omitempty"`
+	CreatedAt              models.Timestamp  `json:"created_at"`
+	UpdatedAt              models.Timestamp  `json:"updated_at"`
+}
+
+type UpdateBidDefaultsRequest struct {
+	DefaultInclusions []string `json:"default_inclusions"`
+	DefaultExclusions []string `json:"default_exclusions"`
+}
+
+type UpdateRevisionSettingsRequest struct {
+	AutoBlueprintRevisions *bool `json:"auto_blueprint_revisions"`
+}
+
+type UpdateUnitSystemRequest struct {
+	UnitSystem string `json:"unit_system"`
+}
+
+// QuickBooksItemsRequest replaces the company's QuickBooks item names, keyed
+// by trade or "markup", and the catch-all item for unmapped line items
+type QuickBooksItemsRequest struct {
+	Items       map[string]string `json:"items"`
+	DefaultItem *string           `json:"default_item"`
+}
+
+// QuickBooksItemsResponse is the company's item mapping merged over the
+// defaults, as exports apply it
+type QuickBooksItemsResponse struct {
+	Items       map[string]string `json:"items"`
+	DefaultItem string            `json:"default_item"`
 }
 
 // Signup handles user registration
-func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
+func (h *AuthHandlers) Signup(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	correlationID := getCorrelationID(ctx)
 
@@ -47,18 +174,18 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 		slog.Error("Failed to decode signup request",
 			"error", err,
 			"correlation_id", correlationID)
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
 	// Validate input
 	if req.Email == "" || req.Password == "" {
-		respondError(w, http.StatusBadRequest, "Email and password are required")
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "Email and password are required")
 		return
 	}
 
-	if len(req.Password) < 8 {
-		respondError(w, http.StatusBadRequest, "Password must be at least 8 characters")
+	if err := services.ValidatePasswordStrength(req.Password); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeWeakPassword, err.Error())
 		return
 	}
 
@@ -68,40 +195,39 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 		slog.Error("Failed to hash password",
 			"error", err,
 			"correlation_id", correlationID)
-		respondError(w, http.StatusInternalServerError, "Failed to create user")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to create user")
 		return
 	}
 
 	// Create user
 	user := &models.User{
-		ID:           uuid.New(),
-		Email:        req.Email,
-		PasswordHash: hashedPassword,
-		Name:         req.Name,
-		CompanyName:  req.CompanyName,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:                     uuid.New(),
+		Email:                  req.Email,
+		PasswordHash:           hashedPassword,
+		Name:                   req.Name,
+		CompanyName:            req.CompanyName,
+		Role:                   models.UserRoleUser, // Signup never grants another role
+		AutoBlueprintRevisions: true,
+		CreatedAt:              models.Now(),
+		UpdatedAt:              models.Now(),
 	}
 
 	if err := h.userRepo.CreateUser(ctx, user); err != nil {
-		if err == repository.ErrEmailAlreadyExists {
-			respondError(w, http.StatusConflict, "Email already exists")
-			return
+		if !isRepositoryError(err) {
+			slog.Error("Failed to create user",
+				"error", err,
+				"correlation_id", correlationID)
 		}
-		slog.Error("Failed to create user",
-			"error", err,
-			"correlation_id", correlationID)
-		respondError(w, http.StatusInternalServerError, "Failed to create user")
+		respondRepositoryError(w, err, "Failed to create user")
 		return
 	}
 
-	// Generate JWT token
-	token, err := h.authService.GenerateToken(user.ID.String(), user.Email)
+	tokens, err := h.authService.IssueTokens(ctx, user.ID, user.Email, user.Role)
 	if err != nil {
 		slog.Error("Failed to generate token",
 			"error", err,
 			"correlation_id", correlationID)
-		respondError(w, http.StatusInternalServerError, "Failed to generate token")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate token")
 		return
 	}
 
@@ -110,21 +236,11 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 		"email", user.Email,
 		"correlation_id", correlationID)
 
-	respondJSON(w, http.StatusCreated, AuthResponse{
-		Token: token,
-		User: UserResponse{
-			ID:          user.ID.String(),
-			Email:       user.Email,
-			Name:        user.Name,
-			CompanyName: user.CompanyName,
-			CreatedAt:   user.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
-		},
-	})
+	respondJSON(w, http.StatusCreated, newAuthResponse(tokens, user))
 }
 
 // Login handles user authentication
-func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+func (h *AuthHandlers) Login(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	correlationID := getCorrelationID(ctx)
 
@@ -133,43 +249,76 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		slog.Error("Failed to decode login request",
 			"error", err,
 			"correlation_id", correlationID)
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
 	// Validate input
 	if req.Email == "" || req.Password == "" {
-		respondError(w, http.StatusBadRequest, "Email and password are required")
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "Email and password are required")
 		return
 	}
 
+	// Reject locked accounts before touching credentials. The response is
+	// identical whether or not the account exists.
+	if h.loginThrottle != nil {
+		if retryAfter, locked := h.loginThrottle.Check(req.Email); locked {
+			respondLoginLocked(w, retryAfter)
+			return
+		}
+	}
+
 	// Get user by email
 	user, err := h.userRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		if err == repository.ErrUserNotFound {
-			respondError(w, http.StatusUnauthorized, "Invalid email or password")
+			h.recordLoginFailure(w, req.Email, correlationID)
 			return
 		}
 		slog.Error("Failed to get user by email",
 			"error", err,
 			"correlation_id", correlationID)
-		respondError(w, http.StatusInternalServerError, "Failed to authenticate")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to authenticate")
 		return
 	}
 
 	// Verify password
 	if err := h.authService.VerifyPassword(user.PasswordHash, req.Password); err != nil {
-		respondError(w, http.StatusUnauthorized, "Invalid email or password")
+		h.recordLoginFailure(w, req.Email, correlationID)
 		return
 	}
 
-	// Generate JWT token
-	token, err := h.authService.GenerateToken(user.ID.String(), user.Email)
+	if h.loginThrottle != nil {
+		h.loginThrottle.Reset(req.Email)
+	}
+
+	// Upgrade hashes made below the configured cost while the password is
+	// at hand; a failure leaves the old hash working
+	if h.authService.NeedsRehash(user.PasswordHash) {
+		if hash, err := h.authService.HashPassword(req.Password); err != nil {
+			slog.Warn("Failed to rehash password",
+				"error", err,
+				"user_id", user.ID,
+				"correlation_id", correlationID)
+		} else if err := h.userRepo.UpdatePasswordHash(ctx, user.ID, hash); err != nil {
+			slog.Warn("Failed to store rehashed password",
+				"error", err,
+				"user_id", user.ID,
+				"correlation_id", correlationID)
+		} else {
+			slog.Info("Password rehashed at the configured cost",
+				"user_id", user.ID,
+				"bcrypt_cost", h.authService.BcryptCost(),
+				"correlation_id", correlationID)
+		}
+	}
+
+	tokens, err := h.authService.IssueTokens(ctx, user.ID, user.Email, user.Role)
 	if err != nil {
 		slog.Error("Failed to generate token",
 			"error", err,
 			"correlation_id", correlationID)
-		respondError(w, http.StatusInternalServerError, "Failed to generate token")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate token")
 		return
 	}
 
@@ -178,27 +327,126 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		"email", user.Email,
 		"correlation_id", correlationID)
 
-	respondJSON(w, http.StatusOK, AuthResponse{
-		Token: token,
-		User: UserResponse{
-			ID:          user.ID.String(),
-			Email:       user.Email,
-			Name:        user.Name,
-			CompanyName: user.CompanyName,
-			CreatedAt:   user.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
-		},
-	})
+	respondJSON(w, http.StatusOK, newAuthResponse(tokens, user))
+}
+
+// Refresh trades a refresh token for a new access and refresh token pair.
+// The presented token is revoked, so replaying it after rotation fails.
+func (h *AuthHandlers) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := getCorrelationID(ctx)
+
+	var req RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if req.RefreshToken == "" {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "refresh_token is required")
+		return
+	}
+
+	consumed, err := h.authService.ConsumeRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			slog.Warn("Rejected refresh token",
+				"audit_event", "auth.refresh_rejected",
+				"correlation_id", correlationID)
+			respondAPIError(w, http.StatusUnauthorized, CodeInvalidRefreshToken, "Invalid or expired refresh token")
+			return
+		}
+		slog.Error("Failed to consume refresh token",
+			"error", err,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to refresh token")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(ctx, consumed.UserID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			respondAPIError(w, http.StatusUnauthorized, CodeInvalidRefreshToken, "Invalid or expired refresh token")
+			return
+		}
+		slog.Error("Failed to get user",
+			"error", err,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to refresh token")
+		return
+	}
+	if user.Suspended {
+		respondAPIError(w, http.StatusForbidden, middleware.CodeAccountSuspended, "Account suspended")
+		return
+	}
+
+	tokens, err := h.authService.IssueTokens(ctx, user.ID, user.Email, user.Role)
+	if err != nil {
+		slog.Error("Failed to generate token",
+			"error", err,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate token")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newAuthResponse(tokens, user))
+}
+
+// Logout revokes a refresh token. The access token stays valid until it
+// expires. Unknown tokens are accepted so the response reveals nothing.
+func (h *AuthHandlers) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if req.RefreshToken == "" {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "refresh_token is required")
+		return
+	}
+
+	if err := h.authService.RevokeRefreshToken(ctx, req.RefreshToken); err != nil {
+		slog.Error("Failed to revoke refresh token",
+			"error", err,
+			"correlation_id", getCorrelationID(ctx))
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to log out")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// recordLoginFailure counts a failed login and responds with either a generic
+// 401 or, when the failure triggers a lockout, a 429
+func (h *AuthHandlers) recordLoginFailure(w http.ResponseWriter, email, correlationID string) {
+	if h.loginThrottle != nil {
+		if lockout := h.loginThrottle.RecordFailure(email); lockout > 0 {
+			slog.Warn("Account locked after repeated failed logins",
+				"audit_event", "auth.lockout",
+				"email", email,
+				"lockout", lockout.String(),
+				"correlation_id", correlationID)
+			respondLoginLocked(w, lockout)
+			return
+		}
+	}
+	respondAPIError(w, http.StatusUnauthorized, CodeInvalidCredentials, "Invalid email or password")
+}
+
+// respondLoginLocked writes a 429 that does not reveal whether the account exists
+func respondLoginLocked(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	respondAPIError(w, http.StatusTooManyRequests, CodeRateLimited, "Too many login attempts. Please try again later.")
 }
 
 // GetCurrentUser returns the authenticated user's information
-func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+func (h *AuthHandlers) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	correlationID := getCorrelationID(ctx)
 	userID := getUserID(ctx)
 
 	if userID == "" {
-		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
 		return
 	}
 
@@ -207,29 +455,421 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 		slog.Error("Failed to parse user ID",
 			"error", err,
 			"correlation_id", correlationID)
-		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidID, "Invalid user ID")
 		return
 	}
 
 	user, err := h.userRepo.GetUserByID(ctx, uid)
 	if err != nil {
 		if err == repository.ErrUserNotFound {
-			respondError(w, http.StatusNotFound, "User not found")
+			respondAPIError(w, http.StatusNotFound, CodeResourceNotFound, "User not found")
 			return
 		}
 		slog.Error("Failed to get user",
 			"error", err,
 			"correlation_id", correlationID)
-		respondError(w, http.StatusInternalServerError, "Failed to get user")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get user")
 		return
 	}
 
 	respondJSON(w, http.StatusOK, UserResponse{
-		ID:          user.ID.String(),
-		Email:       user.Email,
-		Name:        user.Name,
-		CompanyName: user.CompanyName,
-		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
+		ID:                     user.ID.String(),
+		Email:                  user.Email,
+		Name:                   user.Name,
+		CompanyName:            user.CompanyName,
+		DefaultInclusions:      user.DefaultInclusions,
+		DefaultExclusions:      user.DefaultExclusions,
+		AutoBlueprintRevisions: &user.AutoBlueprintRevisions,
+		UnitSystem:             user.UnitSystem,
+		CreatedAt:              user.CreatedAt,
+		UpdatedAt:              user.UpdatedAt,
 	})
 }
+
+// UpdateBidDefaults replaces the standing inclusions and exclusions that are
+// merged into every bid the user generates
+func (h *AuthHandlers) UpdateBidDefaults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := getCorrelationID(ctx)
+
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var req UpdateBidDefaultsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	inclusions := cleanTerms(req.DefaultInclusions)
+	exclusions := cleanTerms(req.DefaultExclusions)
+
+	if err := h.userRepo.UpdateBidDefaults(ctx, uid, inclusions, exclusions); err != nil {
+		slog.Error("Failed to update bid defaults",
+			"error", err,
+			"user_id", uid,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update bid defaults")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"default_inclusions": inclusions,
+		"default_exclusions": exclusions,
+	})
+}
+
+// UpdateRevisionSettings turns automatic blueprint revisions on or off. With
+// them off, re-running an analysis replaces the previous result without
+// keeping a snapshot of it.
+func (h *AuthHandlers) UpdateRevisionSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := getCorrelationID(ctx)
+
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var req UpdateRevisionSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if req.AutoBlueprintRevisions == nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "auto_blueprint_revisions is required")
+		return
+	}
+
+	if err := h.userRepo.SetAutoBlueprintRevisions(ctx, uid, *req.AutoBlueprintRevisions); err != nil {
+		if err == repository.ErrUserNotFound {
+			respondAPIError(w, http.StatusNotFound, CodeResourceNotFound, "User not found")
+			return
+		}
+		slog.Error("Failed to update revision settings",
+			"error", err,
+			"user_id", uid,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update revision settings")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"auto_blueprint_revisions": *req.AutoBlueprintRevisions,
+	})
+}
+
+// UpdateUnitSystem sets the unit system takeoff summaries, pricing summaries
+// and bid exports use when a request doesn't name one
+func (h *AuthHandlers) UpdateUnitSystem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := getCorrelationID(ctx)
+
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var req UpdateUnitSystemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	system, err := services.ParseUnitSystem(req.UnitSystem)
+	if err != nil || system == "" {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "unit_system must be \"imperial\" or \"metric\"")
+		return
+	}
+
+	if err := h.userRepo.SetUnitSystem(ctx, uid, system); err != nil {
+		if err == repository.ErrUserNotFound {
+			respondAPIError(w, http.StatusNotFound, CodeResourceNotFound, "User not found")
+			return
+		}
+		slog.Error("Failed to update unit system",
+			"error", err,
+			"user_id", uid,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update unit system")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"unit_system": system,
+	})
+}
+
+// GetQuickBooksItems returns the QuickBooks item names bid exports use
+func (h *AuthHandlers) GetQuickBooksItems(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(ctx, uid)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			respondAPIError(w, http.StatusNotFound, CodeResourceNotFound, "User not found")
+			return
+		}
+		slog.Error("Failed to get user",
+			"error", err,
+			"correlation_id", getCorrelationID(ctx))
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get user")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quickBooksItemsResponse(user.QuickBooksItems, user.QuickBooksDefaultItem))
+}
+
+// UpdateQuickBooksItems replaces the company's QuickBooks item mapping. Keys
+// are normalized to canonical trades; unknown trades are rejected.
+func (h *AuthHandlers) UpdateQuickBooksItems(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := getCorrelationID(ctx)
+
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var req QuickBooksItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	items, err := services.NormalizeQuickBooksItemNames(req.Items)
+	if err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+	defaultItem := req.DefaultItem
+	if defaultItem != nil {
+		trimmed := strings.TrimSpace(*defaultItem)
+		defaultItem = &trimmed
+		if trimmed == "" {
+			defaultItem = nil
+		}
+	}
+
+	if err := h.userRepo.UpdateQuickBooksItems(ctx, uid, items, defaultItem); err != nil {
+		slog.Error("Failed to update QuickBooks items",
+			"error", err,
+			"user_id", uid,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update QuickBooks items")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quickBooksItemsResponse(items, defaultItem))
+}
+
+// UpdateTaxSettingsRequest replaces the company's sales tax rules; a null
+// tax_settings stops charging tax
+type UpdateTaxSettingsRequest struct {
+	TaxSettings *models.TaxSettings `json:"tax_settings"`
+}
+
+// UpdateTaxSettings replaces the sales tax rules estimates and bids are
+// charged under. Override regions are normalized to match project regions.
+func (h *AuthHandlers) UpdateTaxSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var req UpdateTaxSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	if err := services.ValidateTaxSettings(req.TaxSettings); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	if err := h.userRepo.UpdateTaxSettings(ctx, uid, req.TaxSettings); err != nil {
+		slog.Error("Failed to update tax settings",
+			"error", err,
+			"user_id", uid,
+			"correlation_id", getCorrelationID(ctx))
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update tax settings")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, req)
+}
+
+// UpdateImpactThresholdsRequest replaces the company's comparison impact
+// thresholds; a null impact_thresholds restores the defaults
+type UpdateImpactThresholdsRequest struct {
+	ImpactThresholds *models.ImpactThresholds `json:"impact_thresholds"`
+}
+
+// UpdateImpactThresholds replaces the thresholds revision comparisons label
+// changes High, Medium or Low by. The response holds the thresholds in effect,
+// the defaults merged with the saved overrides.
+func (h *AuthHandlers) UpdateImpactThresholds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var req UpdateImpactThresholdsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	if err := services.ValidateImpactThresholds(req.ImpactThresholds); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	if err := h.userRepo.UpdateImpactThresholds(ctx, uid, req.ImpactThresholds); err != nil {
+		slog.Error("Failed to update impact thresholds",
+			"error", err,
+			"user_id", uid,
+			"correlation_id", getCorrelationID(ctx))
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update impact thresholds")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, services.NewImpactClassifier(req.ImpactThresholds).Thresholds())
+}
+
+func quickBooksItemsResponse(items map[string]string, defaultItem *string) QuickBooksItemsResponse {
+	merged := services.DefaultQuickBooksItemNames()
+	for key, item := range items {
+		merged[key] = item
+	}
+	response := QuickBooksItemsResponse{Items: merged, DefaultItem: services.DefaultQuickBooksItem}
+	if defaultItem != nil {
+		response.DefaultItem = *defaultItem
+	}
+	return response
+}
+
+// ChangePassword replaces the user's password after checking the current one.
+// Every access and refresh token issued before the change is revoked, and a
+// new pair for the caller is returned.
+func (h *AuthHandlers) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := getCorrelationID(ctx)
+
+	uid, err := uuid.Parse(getUserID(ctx))
+	if err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "Current and new passwords are required")
+		return
+	}
+	if err := services.ValidatePasswordStrength(req.NewPassword); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeWeakPassword, err.Error())
+		return
+	}
+	if req.NewPassword == req.CurrentPassword {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "New password must differ from the current password")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(ctx, uid)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			respondAPIError(w, http.StatusNotFound, CodeResourceNotFound, "User not found")
+			return
+		}
+		slog.Error("Failed to get user",
+			"error", err,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to change password")
+		return
+	}
+
+	if err := h.authService.VerifyPassword(user.PasswordHash, req.CurrentPassword); err != nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeInvalidCredentials, "Current password is incorrect")
+		return
+	}
+
+	hashedPassword, err := h.authService.HashPassword(req.NewPassword)
+	if err != nil {
+		slog.Error("Failed to hash password",
+			"error", err,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to change password")
+		return
+	}
+
+	// Revoked first, so a failure leaves the old password in place rather
+	// than refresh tokens outliving it
+	if err := h.authService.RevokeUserRefreshTokens(ctx, uid); err != nil {
+		slog.Error("Failed to revoke refresh tokens",
+			"error", err,
+			"user_id", uid,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to change password")
+		return
+	}
+
+	if err := h.userRepo.ChangePassword(ctx, uid, hashedPassword, time.Now()); err != nil {
+		slog.Error("Failed to change password",
+			"error", err,
+			"user_id", uid,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to change password")
+		return
+	}
+
+	// Issued after the revocation, so it survives it
+	tokens, err := h.authService.IssueTokens(ctx, user.ID, user.Email, user.Role)
+	if err != nil {
+		slog.Error("Failed to generate token",
+			"error", err,
+			"correlation_id", correlationID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate token")
+		return
+	}
+
+	slog.Info("Password changed",
+		"audit_event", "auth.password_changed",
+		"user_id", user.ID,
+		"correlation_id", correlationID)
+
+	respondJSON(w, http.StatusOK, newAuthResponse(tokens, user))
+}
+
+// cleanTerms trims each term and drops blanks
+func cleanTerms(terms []string) []string {
+	cleaned := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			cleaned = append(cleaned, term)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const authTestSecret = "test-secret"
+
+// newAuthTestRouter serves the public and authenticated auth routes for one
+// user whose password hash is made at hashCost
+func newAuthTestRouter(t *testing.T, password string, hashCost, configuredCost int) (*models.User, *fakeUserStore, *services.AuthService, chi.Router) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), hashCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	user := &models.User{ID: uuid.New(), Email: "jane@example.com", PasswordHash: string(hash)}
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{user.ID: user}}
+	authService := services.NewAuthService(authTestSecret, time.Hour).
+		WithBcryptCost(configuredCost).
+		WithRefreshTokens(&fakeRefreshTokenStore{tokens: make(map[string]*models.RefreshToken)}, 24*time.Hour)
+
+	h := NewAuthHandlers(users, authService, nil)
+	router := chi.NewRouter()
+	h.PublicRoutes(router)
+	router.Group(func(r chi.Router) {
+		r.Use(middleware.Auth(authService, users))
+		h.Routes(r)
+	})
+	return user, users, authService, router
+}
+
+// issuedTokenAt signs a token for user as if it had been issued at issuedAt
+func issuedTokenAt(t *testing.T, user *models.User, issuedAt time.Time) string {
+	t.Helper()
+	claims := services.Claims{
+		UserID: user.ID.String(),
+		Email:  user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(issuedAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(authTestSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func serveAuth(router chi.Router, method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSignup_RejectsWeakPasswords(t *testing.T) {
+	_, users, _, router := newAuthTestRouter(t, "Existing-pass1", bcrypt.MinCost, bcrypt.MinCost)
+
+	rec := serveAuth(router, http.MethodPost, "/auth/signup", "", `{"email": "new@example.com", "password": "password1"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if !strings.Contains(body["error"], "commonly used") || !strings.Contains(body["error"], "at least 12 characters") {
+		t.Errorf("error = %q, want both failed requirements listed", body["error"])
+	}
+	if len(users.users) != 1 {
+		t.Errorf("expected no user to be created, have %d", len(users.users))
+	}
+
+	rec = serveAuth(router, http.MethodPost, "/auth/signup", "", `{"email": "new@example.com", "password": "Tall-Ladder-42"}`)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("strong password: status = %d, body %s; want 201", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSignup_IgnoresRole(t *testing.T) {
+	_, users, authService, router := newAuthTestRouter(t, "Existing-pass1", bcrypt.MinCost, bcrypt.MinCost)
+
+	rec := serveAuth(router, http.MethodPost, "/auth/signup", "", `{"email": "new@example.com", "password": "Tall-Ladder-42", "role": "admin"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s; want 201", rec.Code, rec.Body.String())
+	}
+	created, err := users.GetUserByEmail(context.Background(), "new@example.com")
+	if err != nil || created.Role != models.UserRoleUser {
+		t.Fatalf("created user = %+v, %v; want the user role", created, err)
+	}
+	var body AuthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	claims, err := authService.ValidateToken(body.Token)
+	if err != nil || claims.Role != models.UserRoleUser {
+		t.Errorf("token claims = %+v, %v; want the user role", claims, err)
+	}
+}
+
+func TestLogin_RehashesBelowConfiguredCost(t *testing.T) {
+	password := "Tall-Ladder-42"
+	user, _, _, router := newAuthTestRouter(t, password, bcrypt.MinCost, bcrypt.MinCost+1)
+	body := `{"email": "jane@example.com", "password": "` + password + `"}`
+
+	rec := serveAuth(router, http.MethodPost, "/auth/login", "", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	cost, err := bcrypt.Cost([]byte(user.PasswordHash))
+	if err != nil || cost != bcrypt.MinCost+1 {
+		t.Fatalf("hash cost = %d (%v), want the configured %d", cost, err, bcrypt.MinCost+1)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		t.Errorf("rehashed password no longer verifies: %v", err)
+	}
+
+	// A hash at the configured cost is left alone
+	rehashed := user.PasswordHash
+	if rec := serveAuth(router, http.MethodPost, "/auth/login", "", body); rec.Code != http.StatusOK || user.PasswordHash != rehashed {
+		t.Errorf("second login: status = %d, hash changed = %v", rec.Code, user.PasswordHash != rehashed)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	current := "Tall-Ladder-42"
+	user, users, _, router := newAuthTestRouter(t, current, bcrypt.MinCost, bcrypt.MinCost)
+	oldToken := issuedTokenAt(t, user, time.Now().Add(-time.Hour))
+	oldRefresh := loginForTokens(t, router, current).RefreshToken
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"wrong current password", `{"current_password": "Wrong-Ladder-42", "new_password": "Short-Stair-77"}`, http.StatusUnauthorized},
+		{"weak new password", `{"current_password": "` + current + `", "new_password": "12345678"}`, http.StatusBadRequest},
+		{"unchanged password", `{"current_password": "` + current + `", "new_password": "` + current + `"}`, http.StatusBadRequest},
+		{"missing current password", `{"new_password": "Short-Stair-77"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := serveAuth(router, http.MethodPost, "/auth/change-password", oldToken, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+	if len(users.revokedAt) != 0 {
+		t.Fatal("a rejected change revoked tokens")
+	}
+
+	rec := serveAuth(router, http.MethodPost, "/auth/change-password", oldToken,
+		`{"current_password": "`+current+`", "new_password": "Short-Stair-77"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var got AuthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("Short-Stair-77")); err != nil {
+		t.Errorf("new password does not verify: %v", err)
+	}
+
+	// Tokens from before the change are revoked; the returned one works
+	if rec := serveAuth(router, http.MethodGet, "/auth/me", oldToken, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("old token: status = %d, want 401", rec.Code)
+	}
+	if rec := serveAuth(router, http.MethodGet, "/auth/me", got.Token, ""); rec.Code != http.StatusOK {
+		t.Errorf("new token: status = %d, want 200", rec.Code)
+	}
+	if rec := serveAuth(router, http.MethodPost, "/auth/refresh", "", `{"refresh_token": "`+oldRefresh+`"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("old refresh token: status = %d, want 401", rec.Code)
+	}
+	if rec := serveAuth(router, http.MethodPost, "/auth/refresh", "", `{"refresh_token": "`+got.RefreshToken+`"}`); rec.Code != http.StatusOK {
+		t.Errorf("new refresh token: status = %d, want 200", rec.Code)
+	}
+}
+
+// loginForTokens logs in as the test user and returns the issued tokens
+func loginForTokens(t *testing.T, router chi.Router, password string) AuthResponse {
+	t.Helper()
+	rec := serveAuth(router, http.MethodPost, "/auth/login", "", `{"email": "jane@example.com", "password": "`+password+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var tokens AuthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&tokens); err != nil {
+		t.Fatalf("failed to decode login response: %v", err)
+	}
+	return tokens
+}
+
+func TestRefresh_RotatesTokens(t *testing.T) {
+	password := "Tall-Ladder-42"
+	_, _, _, router := newAuthTestRouter(t, password, bcrypt.MinCost, bcrypt.MinCost)
+
+	login := loginForTokens(t, router, password)
+	if login.AccessToken == "" || login.Token != login.AccessToken || login.RefreshToken == "" || login.ExpiresIn != 3600 {
+		t.Fatalf("login tokens = %+v, want an access token lasting an hour and a refresh token", login)
+	}
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		return serveAuth(router, http.MethodPost, "/auth/refresh", "", `{"refresh_token": "`+token+`"}`)
+	}
+	rec := refresh(login.RefreshToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var rotated AuthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&rotated); err != nil {
+		t.Fatalf("failed to decode refresh response: %v", err)
+	}
+	if rotated.RefreshToken == "" || rotated.RefreshToken == login.RefreshToken {
+		t.Fatalf("refresh returned refresh token %q, want a new one", rotated.RefreshToken)
+	}
+	if rec := serveAuth(router, http.MethodGet, "/auth/me", rotated.AccessToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("refreshed access token: status = %d, want 200", rec.Code)
+	}
+
+	// Replaying the rotated token fails; the new one still works once
+	if rec := refresh(login.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("reused refresh token: status = %d, want 401", rec.Code)
+	}
+	if rec := refresh("not-a-token"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unknown refresh token: status = %d, want 401", rec.Code)
+	}
+	if rec := serveAuth(router, http.MethodPost, "/auth/refresh", "", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing refresh token: status = %d, want 400", rec.Code)
+	}
+
+	// Logging out revokes the refresh token
+	if rec := serveAuth(router, http.MethodPost, "/auth/logout", "", `{"refresh_token": "`+rotated.RefreshToken+`"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("logout: status = %d, want 204", rec.Code)
+	}
+	if rec := refresh(rotated.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: status = %d, want 401", rec.Code)
+	}
+	if rec := serveAuth(router, http.MethodPost, "/auth/logout", "", `{"refresh_token": "not-a-token"}`); rec.Code != http.StatusNoContent {
+		t.Errorf("logout with unknown token: status = %d, want 204", rec.Code)
+	}
+}
+
+func TestRefresh_RejectsSuspendedUser(t *testing.T) {
+	password := "Tall-Ladder-42"
+	user, _, _, router := newAuthTestRouter(t, password, bcrypt.MinCost, bcrypt.MinCost)
+	login := loginForTokens(t, router, password)
+
+	user.Suspended = true
+	if rec := serveAuth(router, http.MethodPost, "/auth/refresh", "", `{"refresh_token": "`+login.RefreshToken+`"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", rec.Code)
+	}
+}
+
+func TestChangePassword_RequiresUser(t *testing.T) {
+	h := NewAuthHandlers(&fakeUserStore{users: map[uuid.UUID]*models.User{}}, services.NewAuthService(authTestSecret, time.Hour), nil)
+	req := httptest.NewRequest(http.MethodPost, "/auth/change-password", strings.NewReader(`{}`))
+	req = req.WithContext(context.Background())
+	rec := httptest.NewRecorder()
+	h.ChangePassword(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestUpdateRevisionSettings(t *testing.T) {
+	user, _, _, router := newAuthTestRouter(t, "Tall-Ladder-42", bcrypt.MinCost, bcrypt.MinCost)
+	user.AutoBlueprintRevisions = true
+	token := issuedTokenAt(t, user, time.Now())
+
+	if rec := serveAuth(router, http.MethodPut, "/auth/me/revision-settings", token, `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing setting: status = %d, want 400", rec.Code)
+	}
+	rec := serveAuth(router, http.MethodPut, "/auth/me/revision-settings", token, `{"auto_blueprint_revisions": false}`)
+	if rec.Code != http.StatusOK || user.AutoBlueprintRevisions {
+		t.Fatalf("status = %d, enabled = %v; want 200 and auto revisions off", rec.Code, user.AutoBlueprintRevisions)
+	}
+
+	var me UserResponse
+	rec = serveAuth(router, http.MethodGet, "/auth/me", token, "")
+	if err := json.NewDecoder(rec.Body).Decode(&me); err != nil || me.AutoBlueprintRevisions == nil || *me.AutoBlueprintRevisions {
+		t.Errorf("profile auto_blueprint_revisions = %v, want false", me.AutoBlueprintRevisions)
+	}
+}
@@ -1,100 +1,407 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/errreport"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
 )
 
+// BidHandlers serves pricing summaries, bid generation and bid exports
+type BidHandlers struct {
+	*PricingSources
+	projectRepo     ProjectStore
+	blueprintRepo   BlueprintStore
+	bidRepo         BidStore
+	bidRevisionRepo BidRevisionStore
+	bidDraftRepo    BidDraftStore
+	userRepo        UserStore
+	profileRepo     CompanyProfileStore
+	jobRepo         BidPDFJobStore
+	s3Service       *services.S3Service
+	aiService       services.AIProvider
+	pdfGenerator    *services.BidPDFGenerator
+	tx              Transactor
+	events          events.Publisher
+	config          *config.Config
+	// Built on the first registration so every mount of the routes shares it
+	previewRateLimit func(http.Handler) http.Handler
+}
+
+func NewBidHandlers(
+	projectRepo ProjectStore,
+	blueprintRepo BlueprintStore,
+	bidRepo BidStore,
+	bidRevisionRepo BidRevisionStore,
+	bidDraftRepo BidDraftStore,
+	userRepo UserStore,
+	profileRepo CompanyProfileStore,
+	jobRepo BidPDFJobStore,
+	pricing *PricingSources,
+	pdfGenerator *services.BidPDFGenerator,
+	s3Service *services.S3Service,
+	aiService services.AIProvider,
+	tx Transactor,
+	publisher events.Publisher,
+	cfg *config.Config,
+) *BidHandlers {
+	return &BidHandlers{
+		PricingSources:  pricing,
+		projectRepo:     projectRepo,
+		blueprintRepo:   blueprintRepo,
+		bidRepo:         bidRepo,
+		bidRevisionRepo: bidRevisionRepo,
+		bidDraftRepo:    bidDraftRepo,
+		userRepo:        userRepo,
+		profileRepo:     profileRepo,
+		jobRepo:         jobRepo,
+		s3Service:       s3Service,
+		aiService:       aiService,
+		pdfGenerator:    pdfGenerator,
+		tx:              tx,
+		events:          publisher,
+		config:          cfg,
+	}
+}
+
+// Routes registers the pricing and bid routes
+func (h *BidHandlers) Routes(r chi.Router) {
+	// Bid previews persist nothing, so they get a stricter per-user limit
+	if h.previewRateLimit == nil {
+		previewRequestsPerMinute := 0
+		if h.config != nil && h.config.RateLimit.Enabled {
+			previewRequestsPerMinute = h.config.RateLimit.PreviewRequestsPerMinute
+		}
+		h.previewRateLimit = middleware.UserRateLimit(previewRequestsPerMinute)
+	}
+
+	r.Get("/projects/{id}/pricing-summary", h.GetPricingSummary)
+	r.Get("/projects/{id}/pricing-summary/compare-regions", h.ComparePricingRegions)
+	r.Get("/blueprints/{id}/pricing-comparison", h.CompareBlueprintPricing)
+	r.Post("/projects/{id}/generate-bid", h.GenerateBid)
+	r.With(h.previewRateLimit).Post("/projects/{id}/bids/preview", h.PreviewBid)
+	r.Get("/projects/{id}/bids", h.GetProjectBids)
+	r.Get("/bids/{id}", h.GetBid)
+	r.Put("/bids/{id}", h.UpdateBid)
+	r.Patch("/bids/{id}", h.RenameBid)
+	r.Patch("/bids/{id}/status", h.UpdateBidStatus)
+	r.Post("/bids/{id}/reprice", h.RepriceBid)
+	r.Get("/bids/{id}/draft", h.GetBidDraft)
+	r.Put("/bids/{id}/draft", h.SaveBidDraft)
+	r.Delete("/bids/{id}/draft", h.DiscardBidDraft)
+	r.Post("/bids/{id}/draft/commit", h.CommitBidDraft)
+	r.Get("/bids/{id}/pdf", h.GetBidPDF)
+	r.Get("/bids/{id}/csv", h.GetBidCSV)
+	r.Get("/bids/{id}/excel", h.GetBidExcel)
+	r.Get("/bids/{id}/export", h.ExportBid)
+}
+
 // GenerateBidRequest represents the request to generate a bid
 type GenerateBidRequest struct {
 	BlueprintID      uuid.UUID  `json:"blueprint_id"`
+	// BlueprintIDs prices a combined bid from several blueprints' merged
+	// takeoffs. Without it the bid is priced from BlueprintID alone, and
+	// without either from every analyzed blueprint in the project.
+	BlueprintIDs []uuid.UUID `json:"blueprint_ids,omitempty"`
 	MarkupPercentage float64    `json:"markup_percentage"`
 	CompanyName      *string    `json:"company_name"`
 	BidName          *string    `json:"bid_name"`
+
+	// AcknowledgeOverBudget confirms generation when the estimate exceeds the
+	// project budget by more than the configured threshold
+	AcknowledgeOverBudget bool `json:"acknowledge_over_budget"`
+
+	// Alternates are optional line items priced outside the base bid
+	Alternates []models.LineItem `json:"alternates,omitempty"`
+
+	// IncludeBlueprintPages lists page numbers of the first blueprint to embed
+	// in the PDF
+	IncludeBlueprintPages []int `json:"include_blueprint_pages,omitempty"`
+
+	// IncludeEstimateRange adds the estimate's confidence range to the AI
+	// prompt, the stored bid and the PDF
+	IncludeEstimateRange bool `json:"include_estimate_range"`
+
+	// IncludeSignatureBlock adds name, signature and date lines for the
+	// contractor and the client to the PDF
+	IncludeSignatureBlock bool `json:"include_signature_block"`
+
+	// Force generates the bid from an analysis whose confidence score is
+	// below the configured minimum. The bid is flagged and its PDF carries a
+	// caveat.
+	Force bool `json:"force"`
+}
+
+// PreviewBidRequest is a GenerateBid body plus whether to ask the AI service
+// for the bid text
+type PreviewBidRequest struct {
+	GenerateBidRequest
+	IncludeAIText bool `json:"include_ai_text"`
+}
+
+// BidPreviewResponse is a generated bid that was not saved
+type BidPreviewResponse struct {
+	models.GenerateBidResponse
+	Preview      bool                 `json:"preview"`
+	BudgetStatus *models.BudgetStatus `json:"budget_status,omitempty"`
+}
+
+// RenameBidRequest represents a PATCH to a bid; only the name can change
+type RenameBidRequest struct {
+	Name *string `json:"name"`
 }
 
-// GetProjectBids returns all bids for a project
-func (h *Handler) GetProjectBids(w http.ResponseWriter, r *http.Request) {
-	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+// RepriceBidResponse is a repriced bid with the line item prices that moved.
+// Revision is nil when no price changed.
+type RepriceBidResponse struct {
+	Bid      *models.Bid         `json:"bid"`
+	Revision *models.BidRevision `json:"revision,omitempty"`
+	services.RepriceResult
+}
+
+// GetProjectBids returns one page of a project's bids. The blueprint_id query
+// parameter keeps only bids priced from that blueprint, combined bids included;
+// status keeps bids in that status. Bids are sorted newest first unless sort
+// is created_at_asc, final_price_asc or final_price_desc.
+func (h *BidHandlers) GetProjectBids(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid project ID")
+		respondInvalidID(w)
+		return
+	}
+
+	filter := models.BidListFilter{ProjectID: projectID, Sort: models.BidSortCreatedDesc}
+	query := r.URL.Query()
+	if value := query.Get("blueprint_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			respondAPIError(w, http.StatusBadRequest, CodeInvalidID, "Invalid blueprint_id")
+			return
+		}
+		filter.BlueprintID = &id
+	}
+	if value := query.Get("status"); value != "" {
+		status, err := services.ParseBidStatus(value)
+		if err != nil {
+			respondInvalidParam(w, "status", err.Error())
+			return
+		}
+		filter.Status = &status
+	}
+	if value := query.Get("sort"); value != "" {
+		filter.Sort = models.BidSort(value)
+		if !filter.Sort.IsValid() {
+			respondInvalidParam(w, "sort", "sort must be one of created_at_desc, created_at_asc, final_price_asc or final_price_desc")
+			return
+		}
+	}
+	if filter.Limit, filter.Offset, err = parsePagination(r); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
 		return
 	}
 
-	bids, err := h.bidRepo.GetByProjectID(r.Context(), projectID)
+	bids, total, err := h.bidRepo.List(r.Context(), filter)
 	if err != nil {
 		slog.Error("Failed to get bids", "project_id", projectID, "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get bids")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get bids")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, bids)
+	// Flag bids against the project budget
+	for _, bid := range bids {
+		if bid.FinalPrice != nil {
+			bid.BudgetStatus = services.EvaluateBudget(project.Budget, *bid.FinalPrice)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, models.ListPage[*models.Bid]{Items: bids, Total: total, Limit: filter.Limit, Offset: filter.Offset})
 }
 
-// GenerateBid generates a new bid for a project
-func (h *Handler) GenerateBid(w http.ResponseWriter, r *http.Request) {
-	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
-	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid project ID")
-		return
+// bidInputs is everything bid generation prepares before calling the AI
+// service; building it reads but never writes
+type bidInputs struct {
+	projectID        uuid.UUID
+	project          *models.Project
+	blueprint        *models.Blueprint   // The first of blueprints
+	blueprints       []*models.Blueprint // Every blueprint the bid prices
+	takeoff          *models.TakeoffSummary
+	pricingSummary   *models.PricingSummary
+	markupPercentage float64
+	confidenceRange  *models.ConfidenceRange // Set when the request includes the estimate range
+	companyProfile   *models.CompanyProfile  // Nil when the company has no profile
+	lowConfidence    bool                    // Forced past the analysis confidence gate
+	aiRequest        map[string]interface{}
+}
+
+// bidBlueprints loads the blueprints a bid prices, checking each belongs to
+// the project and is analyzed. It writes the error response and returns false
+// otherwise.
+func (h *BidHandlers) bidBlueprints(w http.ResponseWriter, r *http.Request, projectID uuid.UUID, ids []uuid.UUID) ([]*models.Blueprint, bool) {
+	blueprints := make([]*models.Blueprint, 0, len(ids))
+	for _, id := range ids {
+		blueprint, err := h.blueprintRepo.GetByID(r.Context(), id)
+		if err != nil {
+			respondNotFound(w)
+			return nil, false
+		}
+		if blueprint.ProjectID != projectID {
+			respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotInProject, "Blueprint does not belong to this project")
+			return nil, false
+		}
+		if blueprint.AnalysisData == nil {
+			respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotAnalyzed, fmt.Sprintf("Blueprint %s must be analyzed before generating bid", blueprint.Filename))
+			return nil, false
+		}
+		blueprints = append(blueprints, blueprint)
 	}
+	return blueprints, true
+}
 
-	var req GenerateBidRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
-		return
+// analyzedProjectBlueprints loads every analyzed blueprint in the project
+// for a bid that names none, writing the error response and returning false
+// when there are none or more than a bid can price
+func (h *BidHandlers) analyzedProjectBlueprints(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) ([]*models.Blueprint, bool) {
+	all, err := h.blueprintRepo.GetByProjectID(r.Context(), projectID)
+	if err != nil {
+		slog.Error("Failed to get project blueprints", "project_id", projectID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get blueprints")
+		return nil, false
+	}
+	blueprints := services.AnalyzedBlueprints(all)
+	if len(blueprints) == 0 {
+		respondAPIError(w, http.StatusBadRequest, CodeNoAnalyzedBlueprints, "Project has no analyzed blueprints to generate a bid from")
+		return nil, false
+	}
+	if len(blueprints) > services.MaxBidBlueprints {
+		respondAPIError(w, http.StatusBadRequest, CodeTooManyBlueprints, fmt.Sprintf("Project has more than %d analyzed blueprints; choose them with blueprint_ids", services.MaxBidBlueprints))
+		return nil, false
 	}
+	return blueprints, true
+}
 
-	// Validate blueprint exists and belongs to project
-	blueprint, err := h.blueprintRepo.GetByID(r.Context(), req.BlueprintID)
+// buildBidInputs validates a bid request and prices the blueprints' takeoff,
+// writing the error response and returning false when the request is invalid
+func (h *BidHandlers) buildBidInputs(w http.ResponseWriter, r *http.Request, req *GenerateBidRequest, timer *services.PhaseTimer) (*bidInputs, bool) {
+	projectID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Blueprint not found")
-		return
+		respondInvalidID(w)
+		return nil, false
+	}
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return nil, false
 	}
 
-	if blueprint.ProjectID != projectID {
-		respondError(w, http.StatusBadRequest, "Blueprint does not belong to this project")
-		return
+	if err := services.ValidateBlueprintPages(req.IncludeBlueprintPages); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return nil, false
 	}
 
-	// Get blueprint analysis data
-	if blueprint.AnalysisData == nil {
-		respondError(w, http.StatusBadRequest, "Blueprint must be analyzed before generating bid")
-		return
+	if req.BidName != nil {
+		name, err := services.ValidateBidName(*req.BidName)
+		if err != nil {
+			respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+			return nil, false
+		}
+		req.BidName = &name
 	}
 
-	// Parse takeoff data
+	blueprintIDs, err := services.BidBlueprintIDs(req.BlueprintID, req.BlueprintIDs)
+	if err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return nil, false
+	}
+	var blueprints []*models.Blueprint
+	if len(blueprintIDs) == 0 {
+		blueprints, ok = h.analyzedProjectBlueprints(w, r, projectID)
+	} else {
+		blueprints, ok = h.bidBlueprints(w, r, projectID, blueprintIDs)
+	}
+	if !ok {
+		return nil, false
+	}
+	blueprintIDs = services.BlueprintIDs(blueprints)
+	blueprint := blueprints[0]
+	if err := services.CheckBlueprintPagesExist(blueprint, req.IncludeBlueprintPages); err != nil {
+		respondAPIError(w, http.StatusUnprocessableEntity, CodeBlueprintPageMissing, err.Error())
+		return nil, false
+	}
+
+	// Parse takeoff data, merged across the blueprints of a combined bid
+	stopPricing := timer.Start("pricing")
 	pricingService := services.NewPricingService()
-	takeoff, analysis, err := pricingService.ParseTakeoffData(*blueprint.AnalysisData)
+	takeoff, analysis, err := services.BidTakeoff(blueprints)
 	if err != nil {
 		slog.Error("Failed to parse takeoff data", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to parse takeoff data")
-		return
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse takeoff data")
+		return nil, false
+	}
+	lowConfidence, ok := h.checkAnalysisConfidence(w, analysis, req.Force)
+	if !ok {
+		return nil, false
 	}
 
-	// Generate pricing summary
-	pricingConfig := pricingService.GetDefaultPricingConfig()
+	// Generate pricing summary with the company's and the project's overrides
+	pricingConfig, err := h.enhancedPricingService().GetPricingConfig(r.Context(), requestUserID(r), &projectID, nil)
+	if err != nil {
+		slog.Error("Failed to get pricing config", "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate pricing summary")
+		return nil, false
+	}
 	pricingSummary, err := pricingService.GeneratePricingSummary(takeoff, analysis, pricingConfig)
 	if err != nil {
 		slog.Error("Failed to generate pricing summary", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to generate pricing summary")
-		return
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate pricing summary")
+		return nil, false
 	}
+	stopPricing()
 
-	// Prepare AI service request
+	// Require explicit confirmation when the estimate is further over budget
+	// than the company allows
+	profile := h.companyProfile(r.Context(), project.UserID)
+	estimateBudget := services.EvaluateBudget(project.Budget, pricingSummary.TotalPrice)
+	ackThreshold := services.BudgetAckThreshold(profile, h.config.Budget.AckThresholdPercent)
+	if !req.AcknowledgeOverBudget && services.RequiresBudgetAcknowledgement(estimateBudget, ackThreshold) {
+		respondAPIErrorDetails(w, http.StatusConflict, CodeOverBudget,
+			"Estimate exceeds project budget; resubmit with acknowledge_over_budget=true to continue",
+			map[string]interface{}{"budget_status": estimateBudget})
+		return nil, false
+	}
+
+	// Prepare AI service request, with the company's profile when it has one
 	companyInfo := map[string]string{
 		"name":      "Quality Construction Co.",
 		"license":   "CA-123456",
 		"insurance": "Fully insured and bonded",
 	}
+	if profile != nil {
+		companyInfo = map[string]string{"name": profile.Name}
+		if profile.LicenseNumber != nil {
+			companyInfo["license"] = *profile.LicenseNumber
+		}
+		if profile.InsuranceInfo != nil {
+			companyInfo["insurance"] = *profile.InsuranceInfo
+		}
+	}
 	if req.CompanyName != nil {
 		companyInfo["name"] = *req.CompanyName
 	}
@@ -106,41 +413,249 @@ func (h *Handler) GenerateBid(w http.ResponseWriter, r *http.Request) {
 
 	aiRequest := map[string]interface{}{
 		"project_id":        projectID.String(),
-		"blueprint_id":      req.BlueprintID.String(),
+		"blueprint_id":      blueprint.ID.String(),
 		"takeoff_data":      analysis,
 		"pricing_rules": map[string]interface{}{
-			"material_prices": pricingConfig.MaterialPrices,
-			"labor_rates":     pricingConfig.LaborRates,
+			"material_prices":       pricingConfig.MaterialPrices,
+			"labor_rates":           pricingConfig.LaborRates,
+			"project_type_modifier": pricingConfig.ProjectTypeModifiers[projectPricingType(project)],
 		},
+		"project_type":      projectPricingType(project),
 		"company_info":      companyInfo,
 		"markup_percentage": markupPercentage,
 	}
+	if len(req.Alternates) > 0 {
+		aiRequest["alternates"] = req.Alternates
+	}
+	if len(blueprints) > 1 {
+		aiRequest["blueprint_ids"] = blueprintIDs
+	}
+
+	var confidenceRange *models.ConfidenceRange
+	if req.IncludeEstimateRange {
+		confidenceRange = services.EstimateConfidenceRange(pricingSummary, analysis, h.confidenceRangeParams())
+		if confidenceRange != nil {
+			aiRequest["estimate_range"] = confidenceRange
+		}
+	}
+
+	return &bidInputs{
+		projectID:        projectID,
+		project:          project,
+		blueprint:        blueprint,
+		blueprints:       blueprints,
+		takeoff:          takeoff,
+		pricingSummary:   pricingSummary,
+		markupPercentage: markupPercentage,
+		confidenceRange:  confidenceRange,
+		companyProfile:   profile,
+		lowConfidence:    lowConfidence,
+		aiRequest:        aiRequest,
+	}, true
+}
+
+// checkAnalysisConfidence refuses pricing from an analysis below the
+// configured confidence minimum with a 422, unless forced. It reports
+// whether a low-confidence analysis was forced through.
+func (h *BidHandlers) checkAnalysisConfidence(w http.ResponseWriter, analysis *models.AnalysisResult, force bool) (bool, bool) {
+	minConfidence := services.DefaultMinAnalysisConfidence
+	if h.config != nil {
+		minConfidence = h.config.AI.MinConfidence
+	}
+	var lowConfidence *services.LowConfidenceError
+	if !errors.As(services.CheckAnalysisConfidence(analysis, minConfidence), &lowConfidence) {
+		return false, true
+	}
+	if force {
+		return true, true
+	}
+	respondAPIErrorDetails(w, http.StatusUnprocessableEntity, CodeLowConfidence,
+		"Analysis confidence is too low to price; resubmit with force=true to continue",
+		map[string]interface{}{
+			"confidence_score": lowConfidence.Score,
+			"min_confidence":   lowConfidence.MinConfidence,
+		})
+	return false, false
+}
 
-	// Call AI service to generate bid
-	slog.Info("Calling AI service to generate bid", "project_id", projectID)
-	bidResponseJSON, err := h.aiService.GenerateBid(r.Context(), aiRequest)
+// generateBidResponse calls the AI service and parses its bid. Failures
+// are logged and reported; the caller decides how to respond.
+func (h *BidHandlers) generateBidResponse(r *http.Request, inputs *bidInputs, timer *services.PhaseTimer) (*models.GenerateBidResponse, string, *models.AIModelInfo, error) {
+	slog.Info("Calling AI service to generate bid", "project_id", inputs.projectID)
+	stopAI := timer.Start("ai")
+	bidResponseJSON, generationModel, err := h.aiService.GenerateBid(r.Context(), inputs.aiRequest)
+	stopAI()
 	if err != nil {
 		slog.Error("Failed to generate bid with AI service", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to generate bid")
-		return
+		errreport.CaptureError(r.Context(), err, map[string]string{
+			errreport.TagComponent: "ai",
+			"project_id":           inputs.projectID.String(),
+		})
+		return nil, "", nil, err
 	}
 
-	// Parse AI response
 	var aiResponse models.GenerateBidResponse
 	if err := json.Unmarshal([]byte(bidResponseJSON), &aiResponse); err != nil {
 		slog.Error("Failed to parse AI response", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to parse bid response")
+		return nil, "", nil, fmt.Errorf("failed to parse bid response: %w", err)
+	}
+	aiResponse.GenerationMode = models.BidGenerationModeAI
+	return &aiResponse, bidResponseJSON, generationModel, nil
+}
+
+// finalizeBidResponse validates a generated bid, prices alternates, charges
+// the company's sales tax and merges its standing terms. It reports whether
+// the response was changed.
+func (h *BidHandlers) finalizeBidResponse(w http.ResponseWriter, r *http.Request, inputs *bidInputs, req *GenerateBidRequest, response *models.GenerateBidResponse) (bool, bool) {
+	// Never store a bid with negative line items or totals
+	if err := services.ValidateLineItems(response.LineItems); err != nil || response.TotalPrice < 0 {
+		slog.Error("Generated bid failed validation",
+			"project_id", inputs.projectID,
+			"total_price", response.TotalPrice,
+			"error", err,
+			"correlation_id", getCorrelationID(r.Context()))
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Generated bid failed validation")
+		return false, false
+	}
+
+	// Group AI trade spellings under the canonical trades
+	adjusted := false
+	if warnings, changed := services.NormalizeLineItemTrades(response.LineItems); changed || len(warnings) > 0 {
+		response.Warnings = append(response.Warnings, warnings...)
+		adjusted = true
+	}
+
+	// Record which items were priced from the takeoff so a later reprice
+	// knows what it may refresh
+	if services.MarkLineItemProvenance(response.LineItems, inputs.pricingSummary) {
+		adjusted = true
+	}
+	if response.BlueprintID == "" {
+		response.BlueprintID = inputs.blueprint.ID.String()
+		adjusted = true
+	}
+	response.SourceBlueprints = services.BidSourceBlueprints(inputs.blueprints)
+	adjusted = true
+
+	// Keep alternates out of the base totals and price each group separately
+	if len(req.Alternates) > 0 || hasAlternates(response.LineItems) {
+		response.LineItems = mergeRequestedAlternates(response.LineItems, req.Alternates)
+		services.ApplyAlternates(response, inputs.markupPercentage)
+		adjusted = true
+	}
+
+	if services.TrimLineItemNotes(response) {
+		adjusted = true
+	}
+
+	// Work pricing didn't recognize stays on the bid for the estimator
+	if services.FlagLineItemsForReview(response, inputs.pricingSummary) {
+		adjusted = true
+	}
+
+	if inputs.confidenceRange != nil {
+		response.ConfidenceRange = inputs.confidenceRange
+		adjusted = true
+	}
+
+	if inputs.lowConfidence {
+		response.GeneratedFromLowConfidence = true
+		adjusted = true
+	}
+
+	if inputs.takeoff != nil && len(inputs.takeoff.OpeningSchedule) > 0 {
+		response.OpeningSchedule = inputs.takeoff.OpeningSchedule
+		adjusted = true
+	}
+
+	if inputs.takeoff != nil {
+		if h.setBidUnitMetrics(response, inputs.takeoff.TotalArea, projectPricingType(inputs.project)) {
+			response.Warnings = append(response.Warnings, response.UnitMetrics.Warning)
+		}
+		adjusted = true
+	}
+
+	// Company standing inclusions/exclusions must appear on every bid
+	owner, err := h.userRepo.GetUserByID(r.Context(), inputs.project.UserID)
+	if err != nil {
+		slog.Error("Failed to load company bid defaults", "error", err, "user_id", inputs.project.UserID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate bid")
+		return false, false
+	}
+	if tax := services.NewTaxSummary(owner.TaxSettings, projectRegion(r, inputs.project), inputs.project.TaxExempt); tax != nil {
+		services.ApplyBidTax(response, tax)
+		adjusted = true
+	}
+	if len(owner.DefaultInclusions) > 0 || len(owner.DefaultExclusions) > 0 {
+		services.MergeCompanyTerms(response, owner.DefaultInclusions, owner.DefaultExclusions)
+		adjusted = true
+		if len(response.Warnings) > 0 {
+			slog.Warn("Bid terms conflict with company defaults",
+				"project_id", inputs.projectID,
+				"warnings", response.Warnings,
+				"correlation_id", getCorrelationID(r.Context()))
+		}
+	}
+
+	return adjusted, true
+}
+
+// GenerateBid generates a new bid for a project
+func (h *BidHandlers) GenerateBid(w http.ResponseWriter, r *http.Request) {
+	timer := services.NewPhaseTimer()
+
+	if _, err := parseUUIDParam(r, "id"); err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req GenerateBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
+	inputs, ok := h.buildBidInputs(w, r, &req, timer)
+	if !ok {
+		return
+	}
+	projectID, project, blueprint := inputs.projectID, inputs.project, inputs.blueprint
+	pricingSummary, markupPercentage := inputs.pricingSummary, inputs.markupPercentage
+
+	// Call AI service to generate bid. Pricing doesn't depend on it, so when
+	// it fails the bid is written from the pricing summary instead.
+	response, bidResponseJSON, generationModel, err := h.generateBidResponse(r, inputs, timer)
+	if err != nil {
+		slog.Warn("Generating bid without the AI service",
+			"project_id", projectID,
+			"error", err,
+			"correlation_id", getCorrelationID(r.Context()))
+		fallback := services.DeterministicBidResponse(pricingSummary, inputs.takeoff, markupPercentage)
+		fallback.ProjectID = projectID.String()
+		fallback.Status = string(models.BidStatusDraft)
+		response, generationModel = &fallback, nil
+	}
+	aiResponse := *response
+
+	adjustedResponse, ok := h.finalizeBidResponse(w, r, inputs, &req, &aiResponse)
+	if !ok {
+		return
+	}
+
+	// Identical bids must store identical BidData
+	if services.SortBidLineItems(&aiResponse) {
+		adjustedResponse = true
+	}
+	if adjustedResponse || bidResponseJSON == "" {
+		if adjusted, err := json.Marshal(aiResponse); err == nil {
+			bidResponseJSON = string(adjusted)
+		}
+	}
+
 	// Create bid record
 	bidID := uuid.New()
-	now := time.Now()
-	
-	bidName := fmt.Sprintf("Bid-%s", time.Now().Format("20060102-150405"))
-	if req.BidName != nil {
-		bidName = *req.BidName
-	}
+	now := models.Now()
+	bidName := h.newBidName(r.Context(), projectID, project.Name, req.BidName, now.Time)
 
 	bid := &models.Bid{
 		ID:               bidID,
@@ -153,6 +668,9 @@ func (h *Handler) GenerateBid(w http.ResponseWriter, r *http.Request) {
 		FinalPrice:       &aiResponse.TotalPrice,
 		Status:           models.BidStatusDraft,
 		BidData:          &bidResponseJSON,
+		GenerationModel:  generationModel,
+		CostsByTrade:     services.CostsByTrade(aiResponse.LineItems),
+		BlueprintIDs:     services.BlueprintIDs(inputs.blueprints),
 		Version:          1,
 		IsLatest:         true,
 		CreatedAt:        now,
@@ -161,71 +679,470 @@ func (h *Handler) GenerateBid(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.bidRepo.Create(r.Context(), bid); err != nil {
 		slog.Error("Failed to create bid record", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to save bid")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to save bid")
 		return
 	}
+	h.events.Publish(r.Context(), events.BidCreated{
+		BidID:         bidID,
+		ProjectID:     projectID,
+		UserID:        getUserID(r.Context()),
+		FinalPrice:    aiResponse.TotalPrice,
+		CorrelationID: getCorrelationID(r.Context()),
+	})
 
-	// Generate PDF
-	project, err := h.projectRepo.GetByID(r.Context(), projectID)
-	if err != nil {
-		slog.Warn("Failed to get project for PDF generation", "error", err)
-		project = &models.Project{Name: "Unknown Project"}
+	bid.BudgetStatus = services.EvaluateBudget(project.Budget, aiResponse.TotalPrice)
+	if bid.BudgetStatus != nil && bid.BudgetStatus.Status == models.BudgetStateOver {
+		h.events.Publish(r.Context(), events.BidOverBudget{
+			BidID:         bidID,
+			ProjectID:     projectID,
+			UserID:        getUserID(r.Context()),
+			BudgetStatus:  *bid.BudgetStatus,
+			CorrelationID: getCorrelationID(r.Context()),
+		})
 	}
 
-	pdfService := services.NewPDFService()
-	pdfBytes, err := pdfService.GenerateBidPDF(bid, &aiResponse, project.Name)
-	if err != nil {
-		slog.Error("Failed to generate PDF", "error", err)
-		// Don't fail the request - PDF can be generated later
+	// The worker renders and uploads the PDF, so a large bid doesn't hold the
+	// request open; GetBidPDF reports it as generating until then
+	pdfRequest := &models.BidPDFRequest{
+		IncludeBlueprintPages: req.IncludeBlueprintPages,
+		IncludeEstimateRange:  req.IncludeEstimateRange,
+		IncludeSignatureBlock: req.IncludeSignatureBlock,
+	}
+	if job, err := h.queueBidPDF(r.Context(), bid, blueprint.ID, pdfRequest); err != nil {
+		// Don't fail the request - GetBidPDF queues the PDF again
+		slog.Error("Failed to queue PDF generation", "bid_id", bidID, "error", err)
 	} else {
-		// Upload PDF to S3
-		pdfKey := pdfService.GeneratePDFFilename(projectID, bidID)
-		pdfURL, err := h.s3Service.UploadFile(r.Context(), pdfKey, pdfBytes, "application/pdf")
+		bid.PDFJobID = &job.ID
+	}
+
+	logArgs := []any{"bid_id", bidID, "project_id", projectID, "correlation_id", getCorrelationID(r.Context())}
+	slog.Info("Bid generated successfully", append(logArgs, timer.LogArgs()...)...)
+
+	bid.Warnings = aiResponse.Warnings
+
+	// Expose the phase breakdown when debugging slow generation
+	if r.URL.Query().Get("debug") == "true" {
+		bid.Timings = timer.Timings()
+	}
+
+	respondJSON(w, http.StatusAccepted, bid)
+}
+
+// PreviewBid prices a bid request like GenerateBid but persists nothing: no
+// bid, revision, job or PDF. Without include_ai_text the AI service is not
+// called and the bid text is left empty.
+func (h *BidHandlers) PreviewBid(w http.ResponseWriter, r *http.Request) {
+	timer := services.NewPhaseTimer()
+
+	if _, err := parseUUIDParam(r, "id"); err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req PreviewBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	inputs, ok := h.buildBidInputs(w, r, &req.GenerateBidRequest, timer)
+	if !ok {
+		return
+	}
+
+	var response models.GenerateBidResponse
+	if req.IncludeAIText {
+		generated, _, _, err := h.generateBidResponse(r, inputs, timer)
 		if err != nil {
-			slog.Error("Failed to upload PDF to S3", "error", err)
-		} else {
-			// Update bid with PDF URL
-			bid.PDFURL = &pdfURL
-			bid.PDFS3Key = &pdfKey
-			bid.UpdatedAt = time.Now()
-			if err := h.bidRepo.Update(r.Context(), bid); err != nil {
-				slog.Error("Failed to update bid with PDF URL", "error", err)
-			}
+			respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate bid")
+			return
 		}
+		response = *generated
+	} else {
+		response = services.PricedBidResponse(inputs.pricingSummary, inputs.markupPercentage)
+		response.ProjectID = inputs.projectID.String()
+		response.Status = "preview"
 	}
 
-	slog.Info("Bid generated successfully", "bid_id", bidID, "project_id", projectID)
-	respondJSON(w, http.StatusOK, bid)
+	if _, ok := h.finalizeBidResponse(w, r, inputs, &req.GenerateBidRequest, &response); !ok {
+		return
+	}
+
+	slog.Info("Bid previewed",
+		"project_id", inputs.projectID,
+		"include_ai_text", req.IncludeAIText,
+		"correlation_id", getCorrelationID(r.Context()))
+
+	respondJSON(w, http.StatusOK, BidPreviewResponse{
+		GenerateBidResponse: response,
+		Preview:             true,
+		BudgetStatus:        services.EvaluateBudget(inputs.project.Budget, response.TotalPrice),
+	})
+}
+
+// newBidName returns the requested name, or a descriptive default, made
+// unique among the project's existing bids. Basic bid generation is priced
+// without a regional adjustment, so defaults are labelled National.
+func (h *BidHandlers) newBidName(ctx context.Context, projectID uuid.UUID, projectName string, requested *string, now time.Time) string {
+	existing, err := h.bidRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		slog.Warn("Failed to load existing bids for naming", "project_id", projectID, "error", err)
+	}
+
+	name := services.DefaultBidName(projectName, len(existing)+1, "", now)
+	if requested != nil {
+		name = *requested
+	}
+	return services.UniqueBidName(name, bidNames(existing, uuid.Nil))
+}
+
+// bidNames returns the names of bids, skipping the bid with id exclude
+func bidNames(bids []*models.Bid, exclude uuid.UUID) []string {
+	names := make([]string, 0, len(bids))
+	for _, bid := range bids {
+		if bid.Name != nil && bid.ID != exclude {
+			names = append(names, *bid.Name)
+		}
+	}
+	return names
 }
 
 // GetBid returns a specific bid
-func (h *Handler) GetBid(w http.ResponseWriter, r *http.Request) {
-	bidID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *BidHandlers) GetBid(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid bid ID")
+		respondInvalidID(w)
+		return
+	}
+
+	bid, _, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
 		return
 	}
+	bid.SourceBlueprints = h.sourceBlueprints(r.Context(), bid)
+
+	respondJSON(w, http.StatusOK, bid)
+}
+
+// sourceBlueprints describes the blueprints a bid was priced from as they
+// were when it was priced. Bids priced before that was recorded describe the
+// blueprints as they are now, and by ID alone once deleted.
+func (h *BidHandlers) sourceBlueprints(ctx context.Context, bid *models.Bid) []models.BidSourceBlueprint {
+	if bid.BidData != nil {
+		var bidResponse models.GenerateBidResponse
+		if err := json.Unmarshal([]byte(*bid.BidData), &bidResponse); err == nil && len(bidResponse.SourceBlueprints) > 0 {
+			return bidResponse.SourceBlueprints
+		}
+	}
+
+	var sources []models.BidSourceBlueprint
+	for _, id := range bid.BlueprintIDs {
+		source := models.BidSourceBlueprint{BlueprintID: id}
+		if blueprint, err := h.blueprintRepo.GetByID(ctx, id); err == nil {
+			source.Filename, source.Version = blueprint.Filename, blueprint.Version
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
 
-	bid, err := h.bidRepo.GetByID(r.Context(), bidID)
+// loadOwnedBid returns a bid and its project when the project belongs to the
+// requesting user. It writes a 404 and returns false otherwise.
+func (h *BidHandlers) loadOwnedBid(w http.ResponseWriter, r *http.Request, bidID uuid.UUID) (*models.Bid, *models.Project, bool) {
+	return loadUserBid(w, r, h.bidRepo, h.projectRepo, bidID)
+}
+
+// RenameBid changes a bid's name. The rename is recorded as a new
+// bid revision, and a name already used on the project gets a " (2)" suffix.
+// Accepted bids can't be renamed.
+func (h *BidHandlers) RenameBid(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Bid not found")
+		respondInvalidID(w)
+		return
+	}
+
+	var req RenameBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if req.Name == nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "name is required")
+		return
+	}
+	name, err := services.ValidateBidName(*req.Name)
+	if err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	bid, _, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok || !requireEditableBid(w, bid) {
+		return
+	}
+
+	if bid.Name != nil && *bid.Name == name {
+		respondJSON(w, http.StatusOK, bid)
+		return
+	}
+
+	existing, err := h.bidRepo.GetByProjectID(r.Context(), bid.ProjectID)
+	if err != nil {
+		slog.Error("Failed to get bids", "project_id", bid.ProjectID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update bid")
+		return
+	}
+	name = services.UniqueBidName(name, bidNames(existing, bid.ID))
+
+	// Compare against the bid as it was so the revision notes the rename
+	before := newBidRevision(bid, bid.Version, "")
+	bid.Name = &name
+	revision, err := createBidRevision(r.Context(), h.bidRevisionRepo, bid, getUserID(r.Context()), before, models.BidRevisionReasonRename)
+	if err != nil {
+		slog.Error("Failed to create bid revision", "bid_id", bidID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update bid")
+		return
+	}
+
+	bid.Version = revision.Version
+	bid.UpdatedAt = models.Now()
+	if err := h.bidRepo.Update(r.Context(), bid); err != nil {
+		slog.Error("Failed to rename bid", "bid_id", bidID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update bid")
 		return
 	}
 
 	respondJSON(w, http.StatusOK, bid)
 }
 
-// GetBidPDF returns the PDF URL for a bid or generates it if not exists
-func (h *Handler) GetBidPDF(w http.ResponseWriter, r *http.Request) {
-	bidID, err := uuid.Parse(chi.URLParam(r, "id"))
+// RepriceBid refreshes a bid's prices from current cost data, company
+// overrides and the region query parameter without calling the AI service.
+// Takeoff-priced line items get new unit costs; manual items are kept unless
+// overwrite_manual=true. A changed bid is saved as a "reprice" revision and
+// its PDF is regenerated on next download. Accepted bids can't be repriced.
+func (h *BidHandlers) RepriceBid(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid bid ID")
+		respondInvalidID(w)
 		return
 	}
 
-	bid, err := h.bidRepo.GetByID(r.Context(), bidID)
+	bid, project, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok || !requireEditableBid(w, bid) {
+		return
+	}
+
+	if bid.BidData == nil {
+		respondAPIError(w, http.StatusBadRequest, CodeBidDataUnavailable, "Bid data not available")
+		return
+	}
+	var bidResponse models.GenerateBidResponse
+	if err := json.Unmarshal([]byte(*bid.BidData), &bidResponse); err != nil {
+		slog.Error("Failed to parse bid data", "bid_id", bidID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse bid data")
+		return
+	}
+
+	blueprints, ok := h.repriceBlueprints(w, r, bid, &bidResponse)
+	if !ok {
+		return
+	}
+
+	pricingService := h.enhancedPricingService()
+	takeoff, analysis, err := services.BidTakeoff(blueprints)
+	if err != nil {
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse takeoff data")
+		return
+	}
+
+	var region *string
+	if value := r.URL.Query().Get("region"); value != "" {
+		region = &value
+	}
+	summary, err := pricingService.GenerateProjectPricingSummary(r.Context(), takeoff, analysis, requestUserID(r), &project.ID, region, projectPricingType(project))
+	if err != nil {
+		slog.Error("Failed to generate pricing summary", "bid_id", bidID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate pricing summary")
+		return
+	}
+
+	markupPercentage := bidMarkupPercentage(bid)
+
+	before := newBidRevision(bid, bid.Version, "")
+	overwriteManual := r.URL.Query().Get("overwrite_manual") == "true"
+	result := services.RepriceBid(&bidResponse, summary, markupPercentage, overwriteManual)
+	if len(result.Changes) == 0 {
+		respondJSON(w, http.StatusOK, RepriceBidResponse{Bid: bid, RepriceResult: result})
+		return
+	}
+
+	bidResponse.BlueprintID = blueprints[0].ID.String()
+	bidResponse.SourceBlueprints = services.BidSourceBlueprints(blueprints)
+	services.SortBidLineItems(&bidResponse)
+	h.setBidUnitMetrics(&bidResponse, takeoff.TotalArea, projectPricingType(project))
+	bidData, err := json.Marshal(bidResponse)
+	if err != nil {
+		slog.Error("Failed to encode repriced bid", "bid_id", bidID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to reprice bid")
+		return
+	}
+	bidDataStr := string(bidData)
+	bid.BidData = &bidDataStr
+	bid.TotalCost = &bidResponse.Subtotal
+	bid.LaborCost = &bidResponse.LaborCost
+	bid.MaterialCost = &bidResponse.MaterialCost
+	bid.FinalPrice = &bidResponse.TotalPrice
+	bid.CostsByTrade = services.CostsByTrade(bidResponse.LineItems)
+	bid.BlueprintIDs = services.BlueprintIDs(blueprints)
+	// The PDF hash covers the bid data, so the next download re-renders it
+	// and schedules the stale object for deletion
+	bid.PDFURL = nil
+
+	revision, err := createBidRevision(r.Context(), h.bidRevisionRepo, bid, getUserID(r.Context()), before, models.BidRevisionReasonReprice)
+	if err != nil {
+		slog.Error("Failed to create bid revision", "bid_id", bidID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to reprice bid")
+		return
+	}
+
+	bid.Version = revision.Version
+	bid.UpdatedAt = models.Now()
+	if err := h.bidRepo.Update(r.Context(), bid); err != nil {
+		slog.Error("Failed to save repriced bid", "bid_id", bidID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to reprice bid")
+		return
+	}
+
+	slog.Info("Bid repriced",
+		"bid_id", bidID,
+		"changes", len(result.Changes),
+		"old_total", result.OldTotalPrice,
+		"new_total", result.NewTotalPrice,
+		"correlation_id", getCorrelationID(r.Context()))
+
+	bid.BudgetStatus = services.EvaluateBudget(project.Budget, bidResponse.TotalPrice)
+	respondJSON(w, http.StatusOK, RepriceBidResponse{Bid: bid, Revision: revision, RepriceResult: result})
+}
+
+// repriceBlueprints returns the analyzed blueprints a bid is repriced from:
+// the blueprint_id query parameter, the blueprints the bid is linked to, the
+// blueprint recorded in the bid data, or the project's only analyzed
+// blueprint. It writes the error response and returns false when none
+// applies.
+func (h *BidHandlers) repriceBlueprints(w http.ResponseWriter, r *http.Request, bid *models.Bid, bidResponse *models.GenerateBidResponse) ([]*models.Blueprint, bool) {
+	blueprintIDStr := r.URL.Query().Get("blueprint_id")
+	if blueprintIDStr == "" && len(bid.BlueprintIDs) > 0 {
+		return h.repriceLinkedBlueprints(w, r, bid)
+	}
+	if blueprintIDStr == "" {
+		blueprintIDStr = bidResponse.BlueprintID
+	}
+
+	var blueprint *models.Blueprint
+	if blueprintIDStr != "" {
+		blueprintID, err := uuid.Parse(blueprintIDStr)
+		if err != nil {
+			respondInvalidID(w)
+			return nil, false
+		}
+		blueprint, err = h.blueprintRepo.GetByID(r.Context(), blueprintID)
+		if err != nil {
+			respondNotFound(w)
+			return nil, false
+		}
+		if blueprint.ProjectID != bid.ProjectID {
+			respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotInProject, "Blueprint does not belong to this project")
+			return nil, false
+		}
+	} else {
+		blueprints, err := h.blueprintRepo.GetByProjectID(r.Context(), bid.ProjectID)
+		if err != nil {
+			slog.Error("Failed to get blueprints", "project_id", bid.ProjectID, "error", err)
+			respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to reprice bid")
+			return nil, false
+		}
+		for _, candidate := range blueprints {
+			if candidate.AnalysisData == nil {
+				continue
+			}
+			if blueprint != nil {
+				respondMissingParam(w, "blueprint_id", "blueprint_id query parameter required")
+				return nil, false
+			}
+			blueprint = candidate
+		}
+		if blueprint == nil {
+			respondAPIError(w, http.StatusBadRequest, CodeNoAnalyzedBlueprints, "Project has no analyzed blueprint to reprice from")
+			return nil, false
+		}
+	}
+
+	if blueprint.AnalysisData == nil {
+		respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotAnalyzed, "Blueprint must be analyzed first")
+		return nil, false
+	}
+	return []*models.Blueprint{blueprint}, true
+}
+
+// repriceLinkedBlueprints loads the blueprints a bid is linked to, so a
+// combined bid is repriced from every one of them
+func (h *BidHandlers) repriceLinkedBlueprints(w http.ResponseWriter, r *http.Request, bid *models.Bid) ([]*models.Blueprint, bool) {
+	blueprints := make([]*models.Blueprint, 0, len(bid.BlueprintIDs))
+	for _, id := range bid.BlueprintIDs {
+		blueprint, err := h.blueprintRepo.GetByID(r.Context(), id)
+		if err != nil {
+			respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotInProject, "A blueprint this bid was priced from no longer exists; pass blueprint_id to reprice from another")
+			return nil, false
+		}
+		if blueprint.AnalysisData == nil {
+			respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotAnalyzed, "Blueprint must be analyzed first")
+			return nil, false
+		}
+		blueprints = append(blueprints, blueprint)
+	}
+	return blueprints, true
+}
+
+// GetBidPDF returns the PDF URL for a bid or generates it if not exists.
+// Drafts are never included, except that draft=true previews the requesting
+// user's draft as a PDF returned directly and not stored. The stored PDF is
+// in imperial units; a metric PDF is likewise returned directly.
+func (h *BidHandlers) GetBidPDF(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	bid, project, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
+		return
+	}
+
+	if r.URL.Query().Get("draft") == "true" {
+		h.previewBidDraftPDF(w, r, bid, project)
+		return
+	}
+
+	units, ok := requestUnitSystem(w, r, h.userRepo)
+	if !ok {
+		return
+	}
+	if units == models.UnitSystemMetric {
+		h.renderMetricBidPDF(w, r, bid, project)
+		return
+	}
+
+	// The worker is already rendering the PDF
+	job, err := h.jobRepo.GetActiveBidPDFJob(r.Context(), bid.ID)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Bid not found")
+		slog.Error("Failed to check for PDF generation job", "bid_id", bid.ID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate PDF")
+		return
+	}
+	if job != nil {
+		respondPDFGenerating(w, job)
 		return
 	}
 
@@ -239,72 +1156,134 @@ func (h *Handler) GetBidPDF(w http.ResponseWriter, r *http.Request) {
 
 	// Generate PDF if it doesn't exist
 	if bid.BidData == nil {
-		respondError(w, http.StatusInternalServerError, "Bid data not available")
+		respondAPIError(w, http.StatusInternalServerError, CodeBidDataUnavailable, "Bid data not available")
 		return
 	}
-
-	// Parse bid data
-	pdfService := services.NewPDFService()
-	bidResponse, err := pdfService.ParseBidDataFromJSON(*bid.BidData)
-	if err != nil {
+	if _, err := services.NewPDFService().ParseBidDataFromJSON(*bid.BidData); err != nil {
 		slog.Error("Failed to parse bid data", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to parse bid data")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse bid data")
+		return
+	}
+
+	// Jobs belong to a blueprint, so bids priced before their blueprints were
+	// recorded are rendered inline
+	if len(bid.BlueprintIDs) == 0 {
+		rendered, err := h.pdfGenerator.Generate(r.Context(), bid.ID, uuid.Nil, nil)
+		if err != nil {
+			slog.Error("Failed to generate PDF", "bid_id", bid.ID, "error", err)
+			respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate PDF")
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]string{
+			"pdf_url": *rendered.PDFURL,
+		})
 		return
 	}
 
-	// Get project name
-	project, err := h.projectRepo.GetByID(r.Context(), bid.ProjectID)
+	job, err = h.queueBidPDF(r.Context(), bid, bid.BlueprintIDs[0], nil)
+	var queueErr *services.QueueFullError
+	if errors.As(err, &queueErr) {
+		respondQueueFull(w, queueErr)
+		return
+	}
 	if err != nil {
-		slog.Warn("Failed to get project", "error", err)
-		project = &models.Project{Name: "Unknown Project"}
+		slog.Error("Failed to queue PDF generation", "bid_id", bid.ID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate PDF")
+		return
 	}
+	respondPDFGenerating(w, job)
+}
 
-	// Generate PDF
-	pdfBytes, err := pdfService.GenerateBidPDF(bid, bidResponse, project.Name)
+// queueBidPDF queues a job for the worker to render a bid's PDF. Requested
+// blueprint pages are taken from blueprintID. It returns a
+// *services.QueueFullError when the job would exceed a queue ceiling.
+func (h *BidHandlers) queueBidPDF(ctx context.Context, bid *models.Bid, blueprintID uuid.UUID, request *models.BidPDFRequest) (*models.Job, error) {
+	queueErr, err := checkQueueCapacity(ctx, h.jobRepo, h.config, 1)
 	if err != nil {
-		slog.Error("Failed to generate PDF", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to generate PDF")
+		return nil, err
+	}
+	if queueErr != nil {
+		return nil, queueErr
+	}
+
+	now := models.Now()
+	job := &models.Job{
+		ID:          uuid.New(),
+		BlueprintID: blueprintID,
+		JobType:     models.JobTypePDFGeneration,
+		Status:      models.JobStatusQueued,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		BidID:       &bid.ID,
+		PDFRequest:  request,
+	}
+	if err := h.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	slog.Info("Queued PDF generation", "bid_id", bid.ID, "job_id", job.ID)
+	return job, nil
+}
+
+// respondPDFGenerating tells the client a bid's PDF is still being rendered
+// by the job it can poll
+func respondPDFGenerating(w http.ResponseWriter, job *models.Job) {
+	respondJSON(w, http.StatusAccepted, map[string]string{
+		"status": "generating",
+		"job_id": job.ID.String(),
+	})
+}
+
+// renderMetricBidPDF renders a bid with its quantities in metric units to a
+// PDF that is returned directly rather than stored
+func (h *BidHandlers) renderMetricBidPDF(w http.ResponseWriter, r *http.Request, bid *models.Bid, project *models.Project) {
+	if bid.BidData == nil {
+		respondAPIError(w, http.StatusInternalServerError, CodeBidDataUnavailable, "Bid data not available")
 		return
 	}
 
-	// Upload to S3
-	pdfKey := pdfService.GeneratePDFFilename(bid.ProjectID, bidID)
-	pdfURL, err := h.s3Service.UploadFile(r.Context(), pdfKey, pdfBytes, "application/pdf")
+	pdfService := services.NewPDFService()
+	bidResponse, err := pdfService.ParseBidDataFromJSON(*bid.BidData)
 	if err != nil {
-		slog.Error("Failed to upload PDF to S3", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to upload PDF")
+		slog.Error("Failed to parse bid data", "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse bid data")
 		return
 	}
+	services.NewUnitConversionService(models.UnitSystemMetric).ConvertBidResponse(bidResponse)
 
-	// Update bid with PDF URL
-	bid.PDFURL = &pdfURL
-	bid.PDFS3Key = &pdfKey
-	bid.UpdatedAt = time.Now()
-	if err := h.bidRepo.Update(r.Context(), bid); err != nil {
-		slog.Error("Failed to update bid with PDF URL", "error", err)
+	options := &services.PDFOptions{Layout: h.companyPDFLayout(r.Context(), project.UserID)}
+	pdfBytes, err := pdfService.GenerateBidPDFWithOptions(bid, bidResponse, project.Name, options)
+	if err != nil {
+		slog.Error("Failed to generate metric PDF", "bid_id", bid.ID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate PDF")
+		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{
-		"pdf_url": pdfURL,
-	})
+	filename := fmt.Sprintf("bid-%s-metric.pdf", bid.ID.String()[:8])
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%s", filename))
+	w.Write(pdfBytes)
 }
 
 // GetBidCSV returns the CSV export for a bid
-func (h *Handler) GetBidCSV(w http.ResponseWriter, r *http.Request) {
-	bidID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *BidHandlers) GetBidCSV(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid bid ID")
+		respondInvalidID(w)
 		return
 	}
 
-	bid, err := h.bidRepo.GetByID(r.Context(), bidID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "Bid not found")
+	units, ok := requestUnitSystem(w, r, h.userRepo)
+	if !ok {
+		return
+	}
+
+	bid, project, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
 		return
 	}
 
 	if bid.BidData == nil {
-		respondError(w, http.StatusInternalServerError, "Bid data not available")
+		respondAPIError(w, http.StatusInternalServerError, CodeBidDataUnavailable, "Bid data not available")
 		return
 	}
 
@@ -313,22 +1292,17 @@ func (h *Handler) GetBidCSV(w http.ResponseWriter, r *http.Request) {
 	bidResponse, err := exportService.ParseBidDataFromJSON(*bid.BidData)
 	if err != nil {
 		slog.Error("Failed to parse bid data", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to parse bid data")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse bid data")
 		return
 	}
-
-	// Get project name
-	project, err := h.projectRepo.GetByID(r.Context(), bid.ProjectID)
-	if err != nil {
-		slog.Warn("Failed to get project", "error", err)
-		project = &models.Project{Name: "Unknown Project"}
-	}
+	services.NewUnitConversionService(units).ConvertBidResponse(bidResponse)
 
 	// Generate CSV
-	csvBytes, err := exportService.GenerateBidCSV(bid, bidResponse, project.Name)
+	layout := h.companyPDFLayout(r.Context(), project.UserID)
+	csvBytes, err := exportService.GenerateBidCSVWithLayout(bid, bidResponse, project.Name, layout)
 	if err != nil {
 		slog.Error("Failed to generate CSV", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to generate CSV")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate CSV")
 		return
 	}
 
@@ -340,21 +1314,25 @@ func (h *Handler) GetBidCSV(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetBidExcel returns the Excel export for a bid
-func (h *Handler) GetBidExcel(w http.ResponseWriter, r *http.Request) {
-	bidID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *BidHandlers) GetBidExcel(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid bid ID")
+		respondInvalidID(w)
 		return
 	}
 
-	bid, err := h.bidRepo.GetByID(r.Context(), bidID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "Bid not found")
+	units, ok := requestUnitSystem(w, r, h.userRepo)
+	if !ok {
+		return
+	}
+
+	bid, project, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
 		return
 	}
 
 	if bid.BidData == nil {
-		respondError(w, http.StatusInternalServerError, "Bid data not available")
+		respondAPIError(w, http.StatusInternalServerError, CodeBidDataUnavailable, "Bid data not available")
 		return
 	}
 
@@ -363,85 +1341,318 @@ func (h *Handler) GetBidExcel(w http.ResponseWriter, r *http.Request) {
 	bidResponse, err := exportService.ParseBidDataFromJSON(*bid.BidData)
 	if err != nil {
 		slog.Error("Failed to parse bid data", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to parse bid data")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse bid data")
 		return
 	}
+	services.NewUnitConversionService(units).ConvertBidResponse(bidResponse)
 
-	// Get project name
-	project, err := h.projectRepo.GetByID(r.Context(), bid.ProjectID)
-	if err != nil {
-		slog.Warn("Failed to get project", "error", err)
-		project = &models.Project{Name: "Unknown Project"}
-	}
-
-	// Generate Excel-compatible CSV
-	excelBytes, err := exportService.GenerateBidExcel(bid, bidResponse, project.Name)
+	// Generate the Excel workbook
+	layout := h.companyPDFLayout(r.Context(), project.UserID)
+	excelBytes, err := exportService.GenerateBidExcelWithLayout(bid, bidResponse, project.Name, layout)
 	if err != nil {
 		slog.Error("Failed to generate Excel export", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to generate Excel export")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate Excel export")
 		return
 	}
 
 	// Set headers for Excel download
-	// Note: Using .csv extension with Excel-compatible encoding (UTF-8 BOM)
-	// For true .xlsx format, would need excelize library
-	filename := fmt.Sprintf("bid-%s-%s.csv", bid.ID.String()[:8], time.Now().Format("20060102"))
-	w.Header().Set("Content-Type", "application/vnd.ms-excel")
+	filename := fmt.Sprintf("bid-%s-%s.xlsx", bid.ID.String()[:8], time.Now().Format("20060102"))
+	w.Header().Set("Content-Type", services.XLSXContentType)
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	w.Write(excelBytes)
 }
 
-// GetPricingSummary returns the pricing summary for a blueprint
-func (h *Handler) GetPricingSummary(w http.ResponseWriter, r *http.Request) {
-	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
-	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid project ID")
+// GetPricingSummary returns the pricing summary for a blueprint, with line
+// item quantities in the unit system named by the units query parameter. An
+// analysis below the confidence minimum is refused unless force=true.
+func (h *BidHandlers) GetPricingSummary(w http.ResponseWriter, r *http.Request) {
+	project, blueprint, ok := h.loadPricingBlueprint(w, r)
+	if !ok {
 		return
 	}
-
-	blueprintIDStr := r.URL.Query().Get("blueprint_id")
-	if blueprintIDStr == "" {
-		respondError(w, http.StatusBadRequest, "blueprint_id query parameter required")
+	units, ok := requestUnitSystem(w, r, h.userRepo)
+	if !ok {
 		return
 	}
 
-	blueprintID, err := uuid.Parse(blueprintIDStr)
+	// Parse and generate pricing from database prices, regional adjustments
+	// and company and project overrides, recording where each price came from
+	pricingService := h.enhancedPricingService()
+	takeoff, analysis, err := services.BlueprintTakeoff(blueprint)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid blueprint ID")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse takeoff data")
+		return
+	}
+	if _, ok := h.checkAnalysisConfidence(w, analysis, r.URL.Query().Get("force") == "true"); !ok {
 		return
 	}
 
-	// Get blueprint
-	blueprint, err := h.blueprintRepo.GetByID(r.Context(), blueprintID)
+	var region *string
+	if value := r.URL.Query().Get("region"); value != "" {
+		region = &value
+	}
+
+	projectType := projectPricingType(project)
+	pricingSummary, err := pricingService.GenerateProjectPricingSummary(r.Context(), takeoff, analysis, requestUserID(r), &project.ID, region, projectType)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Blueprint not found")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate pricing summary")
 		return
 	}
 
-	if blueprint.ProjectID != projectID {
-		respondError(w, http.StatusBadRequest, "Blueprint does not belong to this project")
+	taxSettings, ok := h.companyTaxSettings(w, r, project)
+	if !ok {
+		return
+	}
+	services.ApplyPricingTax(pricingSummary, services.NewTaxSummary(taxSettings, projectRegion(r, project), project.TaxExempt))
+	pricingSummary.BudgetStatus = services.EvaluateBudget(project.Budget, pricingSummary.TotalPrice)
+	pricingSummary.UnitMetrics = h.unitMetrics(services.UnitMetricsInput{
+		Area:         takeoff.TotalArea,
+		TotalPrice:   pricingSummary.TotalPrice,
+		MaterialCost: pricingSummary.MaterialCost,
+		LaborCost:    pricingSummary.LaborCost,
+		CostsByTrade: pricingSummary.CostsByTrade,
+	}, projectType)
+	// Only displayed quantities change; every total above is already final
+	services.NewUnitConversionService(units).ConvertPricingSummary(pricingSummary)
+
+	respondJSON(w, http.StatusOK, pricingSummary)
+}
+
+// ComparePricingRegions prices a blueprint's takeoff under each requested
+// region side by side
+func (h *BidHandlers) ComparePricingRegions(w http.ResponseWriter, r *http.Request) {
+	project, blueprint, ok := h.loadPricingBlueprint(w, r)
+	if !ok {
 		return
 	}
+	h.respondRegionComparison(w, r, project, blueprint)
+}
 
+// CompareBlueprintPricing prices a blueprint's takeoff under each region in
+// the regions query parameter side by side, with each region's difference
+// from the first
+func (h *BidHandlers) CompareBlueprintPricing(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+	blueprint, project, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
+		return
+	}
 	if blueprint.AnalysisData == nil {
-		respondError(w, http.StatusBadRequest, "Blueprint must be analyzed first")
+		respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotAnalyzed, "Blueprint must be analyzed first")
 		return
 	}
+	h.respondRegionComparison(w, r, project, blueprint)
+}
 
-	// Parse and generate pricing
-	pricingService := services.NewPricingService()
-	takeoff, analysis, err := pricingService.ParseTakeoffData(*blueprint.AnalysisData)
+// respondRegionComparison prices an analyzed blueprint in each region of the
+// regions query parameter and writes the comparison. Unknown regions are
+// rejected with a 400.
+func (h *BidHandlers) respondRegionComparison(w http.ResponseWriter, r *http.Request, project *models.Project, blueprint *models.Blueprint) {
+	regions, err := services.ParseCompareRegions(r.URL.Query().Get("regions"))
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to parse takeoff data")
+		respondInvalidParam(w, "regions", err.Error())
 		return
 	}
 
-	pricingConfig := pricingService.GetDefaultPricingConfig()
-	pricingSummary, err := pricingService.GeneratePricingSummary(takeoff, analysis, pricingConfig)
+	pricingService := h.enhancedPricingService()
+	if err := pricingService.ValidateRegions(r.Context(), regions); err != nil {
+		if errors.Is(err, services.ErrUnknownRegion) {
+			respondInvalidParam(w, "regions", err.Error())
+			return
+		}
+		slog.Error("Failed to validate compared regions", "error", err, "blueprint_id", blueprint.ID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate pricing summary")
+		return
+	}
+
+	takeoff, analysis, err := services.BlueprintTakeoff(blueprint)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to generate pricing summary")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to parse takeoff data")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, pricingSummary)
+	taxSettings, ok := h.companyTaxSettings(w, r, project)
+	if !ok {
+		return
+	}
+
+	comparison, err := pricingService.CompareRegions(r.Context(), takeoff, analysis, requestUserID(r), &project.ID, regions, projectPricingType(project), taxSettings, project.TaxExempt)
+	if err != nil {
+		slog.Error("Failed to compare regional pricing", "error", err, "blueprint_id", blueprint.ID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate pricing summary")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, comparison)
+}
+
+// companyTaxSettings loads the sales tax rules of the company that owns
+// project, writing the error response and returning false when it cannot
+func (h *BidHandlers) companyTaxSettings(w http.ResponseWriter, r *http.Request, project *models.Project) (*models.TaxSettings, bool) {
+	owner, err := h.userRepo.GetUserByID(r.Context(), project.UserID)
+	if err != nil {
+		slog.Error("Failed to load company tax settings", "error", err, "user_id", project.UserID)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate pricing summary")
+		return nil, false
+	}
+	return owner.TaxSettings, true
+}
+
+// companyPDFLayout is the bid document layout of the company that owns a
+// project, nil for the default. Documents still render in the default layout
+// when it can't be loaded.
+func (h *BidHandlers) companyPDFLayout(ctx context.Context, ownerID uuid.UUID) *models.PDFLayout {
+	owner, err := h.userRepo.GetUserByID(ctx, ownerID)
+	if err != nil {
+		slog.Warn("Failed to load company PDF layout", "error", err, "user_id", ownerID)
+		return nil
+	}
+	return owner.PDFLayout
+}
+
+// companyProfile is the profile of the company that owns a project, nil when
+// it has none. Documents fall back to the default branding when it can't be
+// loaded.
+func (h *BidHandlers) companyProfile(ctx context.Context, ownerID uuid.UUID) *models.CompanyProfile {
+	if h.profileRepo == nil {
+		return nil
+	}
+	profile, err := h.profileRepo.GetByUserID(ctx, ownerID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrCompanyProfileNotFound) {
+			slog.Warn("Failed to load company profile", "error", err, "user_id", ownerID)
+		}
+		return nil
+	}
+	return profile
+}
+
+// projectRegion is the region a project is priced and taxed in: the region
+// query parameter, else the project's own region
+func projectRegion(r *http.Request, project *models.Project) string {
+	if region := r.URL.Query().Get("region"); region != "" {
+		return region
+	}
+	if project != nil && project.Region != nil {
+		return *project.Region
+	}
+	return ""
+}
+
+// setBidUnitMetrics sets a bid's costs per square foot of its takeoff area
+// and reports whether they fall outside the band for the project type
+func (h *BidHandlers) setBidUnitMetrics(response *models.GenerateBidResponse, area float64, projectType models.ProjectType) bool {
+	response.UnitMetrics = h.unitMetrics(services.UnitMetricsInput{
+		Area:         area,
+		TotalPrice:   response.TotalPrice,
+		MaterialCost: response.MaterialCost,
+		LaborCost:    response.LaborCost,
+		CostsByTrade: services.CostsByTrade(response.LineItems),
+	}, projectType)
+	return response.UnitMetrics.Warning != ""
+}
+
+// projectPricingType is the project type a project is priced as; projects
+// stored without one are new construction
+func projectPricingType(project *models.Project) models.ProjectType {
+	if project == nil || project.ProjectType == "" {
+		return models.ProjectTypeNewConstruction
+	}
+	return project.ProjectType
+}
+
+// loadPricingBlueprint resolves the requesting user's project named by the
+// ID path parameter and the analyzed blueprint named by the blueprint_id query
+// parameter, writing the error response and returning false when either is
+// invalid
+func (h *BidHandlers) loadPricingBlueprint(w http.ResponseWriter, r *http.Request) (*models.Project, *models.Blueprint, bool) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return nil, nil, false
+	}
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return nil, nil, false
+	}
+
+	blueprintIDStr := r.URL.Query().Get("blueprint_id")
+	if blueprintIDStr == "" {
+		respondMissingParam(w, "blueprint_id", "blueprint_id query parameter required")
+		return nil, nil, false
+	}
+
+	blueprintID, err := uuid.Parse(blueprintIDStr)
+	if err != nil {
+		respondInvalidID(w)
+		return nil, nil, false
+	}
+
+	// Get blueprint
+	blueprint, err := h.blueprintRepo.GetByID(r.Context(), blueprintID)
+	if err != nil {
+		respondNotFound(w)
+		return nil, nil, false
+	}
+
+	if blueprint.ProjectID != projectID {
+		respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotInProject, "Blueprint does not belong to this project")
+		return nil, nil, false
+	}
+
+	if blueprint.AnalysisData == nil {
+		respondAPIError(w, http.StatusBadRequest, CodeBlueprintNotAnalyzed, "Blueprint must be analyzed first")
+		return nil, nil, false
+	}
+
+	return project, blueprint, true
+}
+
+// requestUserID returns the authenticated user's ID, or nil when absent
+func requestUserID(r *http.Request) *uuid.UUID {
+	uid, err := uuid.Parse(getUserID(r.Context()))
+	if err != nil {
+		return nil
+	}
+	return &uid
+}
+
+// hasAlternates reports whether any line item is flagged as an alternate
+func hasAlternates(items []models.LineItem) bool {
+	for _, item := range items {
+		if item.IsAlternate {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeRequestedAlternates appends requested alternates the AI response did not
+// already price, matched by group and description
+func mergeRequestedAlternates(items, requested []models.LineItem) []models.LineItem {
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if item.IsAlternate {
+			seen[item.AlternateGroup+"|"+item.Description] = true
+		}
+	}
+
+	for _, alt := range requested {
+		alt.IsAlternate = true
+		if alt.Provenance == "" {
+			alt.Provenance = models.LineItemProvenanceManual
+		}
+		if seen[alt.AlternateGroup+"|"+alt.Description] {
+			continue
+		}
+		if alt.Total == 0 {
+			alt.Total = math.Round(alt.Quantity*alt.UnitCost*100) / 100
+		}
+		items = append(items, alt)
+	}
+	return items
 }
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// defaultDraftTTL applies when no draft configuration is loaded
+const defaultDraftTTL = 72 * time.Hour
+
+// SaveBidDraftRequest is an edited copy of a bid's data, with an optional new
+// markup percentage, held until the draft is committed
+type SaveBidDraftRequest struct {
+	BidData          *models.GenerateBidResponse `json:"bid_data"`
+	MarkupPercentage *float64                    `json:"markup_percentage"`
+}
+
+// CommitBidDraftResponse is the bid after a draft was applied and the
+// revision that recorded it
+type CommitBidDraftResponse struct {
+	Bid      *models.Bid         `json:"bid"`
+	Revision *models.BidRevision `json:"revision"`
+}
+
+// GetBidDraft returns the requesting user's unexpired draft of a bid
+func (h *BidHandlers) GetBidDraft(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	if _, _, ok := h.loadOwnedBid(w, r, bidID); !ok {
+		return
+	}
+
+	draft, err := h.bidDraftRepo.Get(r.Context(), bidID, *requestUserID(r), time.Now())
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Draft not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, draft)
+}
+
+// SaveBidDraft stores the requesting user's edits to a bid without changing
+// the bid or creating a revision. The draft remembers the bid version it was
+// started from, which later saves keep, and expires after the configured TTL
+// unless saved again.
+func (h *BidHandlers) SaveBidDraft(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req SaveBidDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.BidData == nil {
+		respondError(w, http.StatusBadRequest, "bid_data is required")
+		return
+	}
+	if req.MarkupPercentage != nil && *req.MarkupPercentage < 0 {
+		respondError(w, http.StatusBadRequest, "markup_percentage cannot be negative")
+		return
+	}
+	if err := services.ValidateLineItems(req.BidData.LineItems); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := services.ValidateLineItemNotes(req.BidData.LineItems); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, group := range req.BidData.Alternates {
+		if err := services.ValidateLineItemNotes(group.LineItems); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	bid, _, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
+		return
+	}
+
+	bidData, err := json.Marshal(req.BidData)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid bid data")
+		return
+	}
+
+	now := time.Now()
+	draft := &models.BidDraft{
+		ID:               uuid.New(),
+		BidID:            bid.ID,
+		UserID:           *requestUserID(r),
+		BaseVersion:      bid.Version,
+		BidData:          string(bidData),
+		MarkupPercentage: req.MarkupPercentage,
+		CreatedAt:        models.NewTimestamp(now),
+		UpdatedAt:        models.NewTimestamp(now),
+		ExpiresAt:        models.NewTimestamp(now.Add(h.draftTTL())),
+	}
+	if err := h.bidDraftRepo.Save(r.Context(), draft); err != nil {
+		slog.Error("Failed to save bid draft", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to save draft")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, draft)
+}
+
+// DiscardBidDraft deletes the requesting user's draft of a bid
+func (h *BidHandlers) DiscardBidDraft(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	if _, _, ok := h.loadOwnedBid(w, r, bidID); !ok {
+		return
+	}
+
+	if err := h.bidDraftRepo.Delete(r.Context(), bidID, *requestUserID(r)); err != nil {
+		slog.Error("Failed to discard bid draft", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to discard draft")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CommitBidDraft applies the requesting user's draft to the bid as a single
+// "edit" revision and deletes the draft. A draft started from an older bid
+// version than the current one is rejected with 409 so changes saved since,
+// by anyone, are not overwritten. Drafts of accepted bids can't be committed.
+func (h *BidHandlers) CommitBidDraft(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	bid, project, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
+		return
+	}
+
+	userID := *requestUserID(r)
+	draft, err := h.bidDraftRepo.Get(r.Context(), bidID, userID, time.Now())
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Draft not found")
+		return
+	}
+	if draft.BaseVersion != bid.Version {
+		respondError(w, http.StatusConflict, fmt.Sprintf(
+			"Bid changed since the draft was started: draft is based on version %d, current version is %d",
+			draft.BaseVersion, bid.Version))
+		return
+	}
+
+	edited, markupPercentage, ok := h.applyBidDraft(w, bid, draft)
+	if !ok {
+		return
+	}
+
+	revision, err := h.saveBidEdit(r.Context(), bid, edited, markupPercentage, userID.String())
+	if errors.Is(err, repository.ErrBidVersionChanged) {
+		// Another commit or edit was saved since the version check
+		respondError(w, http.StatusConflict, "Bid changed since the draft was started")
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to save edited bid", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to commit draft")
+		return
+	}
+
+	// The edit is saved; a draft left behind only expires
+	if err := h.bidDraftRepo.Delete(r.Context(), bidID, userID); err != nil {
+		slog.Warn("Failed to delete committed bid draft", "bid_id", bidID, "error", err)
+	}
+
+	slog.Info("Bid draft committed",
+		"bid_id", bidID,
+		"version", bid.Version,
+		"correlation_id", getCorrelationID(r.Context()))
+
+	bid.BudgetStatus = services.EvaluateBudget(project.Budget, edited.TotalPrice)
+	respondJSON(w, http.StatusOK, CommitBidDraftResponse{Bid: bid, Revision: revision})
+}
+
+// previewBidDraftPDF renders the requesting user's draft of a bid in their
+// project to a PDF that is returned directly rather than stored
+func (h *BidHandlers) previewBidDraftPDF(w http.ResponseWriter, r *http.Request, bid *models.Bid, project *models.Project) {
+	draft, err := h.bidDraftRepo.Get(r.Context(), bid.ID, *requestUserID(r), time.Now())
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Draft not found")
+		return
+	}
+
+	edited, markupPercentage, ok := h.applyBidDraft(w, bid, draft)
+	if !ok {
+		return
+	}
+
+	preview := *bid
+	preview.TotalCost = &edited.Subtotal
+	preview.LaborCost = &edited.LaborCost
+	preview.MaterialCost = &edited.MaterialCost
+	preview.MarkupPercentage = &markupPercentage
+	preview.FinalPrice = &edited.TotalPrice
+
+	options := &services.PDFOptions{Layout: h.companyPDFLayout(r.Context(), project.UserID)}
+	pdfBytes, err := services.NewPDFService().GenerateBidPDFWithOptions(&preview, edited, project.Name, options)
+	if err != nil {
+		slog.Error("Failed to generate draft PDF", "bid_id", bid.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to generate PDF")
+		return
+	}
+
+	filename := fmt.Sprintf("bid-%s-draft.pdf", bid.ID.String()[:8])
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%s", filename))
+	w.Write(pdfBytes)
+}
+
+// applyBidDraft recalculates a draft's bid data against the bid it edits and
+// returns it with the markup percentage it applies. It writes the error
+// response and returns false when the draft cannot be applied.
+func (h *BidHandlers) applyBidDraft(w http.ResponseWriter, bid *models.Bid, draft *models.BidDraft) (*models.GenerateBidResponse, float64, bool) {
+	var edited models.GenerateBidResponse
+	if err := json.Unmarshal([]byte(draft.BidData), &edited); err != nil {
+		slog.Error("Failed to parse bid draft", "bid_id", bid.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to parse draft")
+		return nil, 0, false
+	}
+
+	markupPercentage := bidMarkupPercentage(bid)
+	if draft.MarkupPercentage != nil {
+		markupPercentage = *draft.MarkupPercentage
+	}
+
+	if !applyBidEdit(w, bid, &edited, markupPercentage) {
+		return nil, 0, false
+	}
+	return &edited, markupPercentage, true
+}
+
+func (h *BidHandlers) draftTTL() time.Duration {
+	if h.config == nil || h.config.Drafts.TTL <= 0 {
+		return defaultDraftTTL
+	}
+	return h.config.Drafts.TTL
+}
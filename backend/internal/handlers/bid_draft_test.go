@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+)
+
+// newDraftTestBid returns handlers serving a version 1 bid of doors, a window
+// and site protection owned by userID
+func newDraftTestBid(t *testing.T, userID uuid.UUID) (*models.Bid, *fakeBidRevisionStore, *fakeBidDraftStore, chi.Router) {
+	t.Helper()
+	h, bid, revisions, drafts := newDraftTestHandlers(t, userID)
+	router := chi.NewRouter()
+	h.Routes(router)
+	return bid, revisions, drafts, router
+}
+
+// newDraftTestHandlers returns the handlers newDraftTestBid serves, before
+// their routes are registered, so a test can swap a dependency
+func newDraftTestHandlers(t *testing.T, userID uuid.UUID) (*BidHandlers, *models.Bid, *fakeBidRevisionStore, *fakeBidDraftStore) {
+	t.Helper()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	bidData, _ := json.Marshal(models.GenerateBidResponse{
+		ScopeOfWork: "Install doors and windows",
+		LineItems: []models.LineItem{
+			{Description: "Interior door installation", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 400, Total: 800, Provenance: models.LineItemProvenanceAuto},
+			{Description: "Window installation", Trade: "carpentry", Quantity: 1, Unit: "each", UnitCost: 700, Total: 700, Provenance: models.LineItemProvenanceAuto},
+			{Description: "Site protection", Trade: "general", Quantity: 1, Unit: "lot", UnitCost: 250, Total: 250, Provenance: models.LineItemProvenanceManual},
+		},
+		MaterialCost: 1750,
+		Subtotal:     1750,
+		MarkupAmount: 350,
+		TotalPrice:   2100,
+	})
+	bidDataStr := string(bidData)
+	subtotal, labor, material, markup, final := 1750.0, 0.0, 1750.0, 20.0, 2100.0
+	pdfURL := "https://example.com/bid.pdf"
+	bid := &models.Bid{
+		ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusDraft, Version: 1,
+		TotalCost: &subtotal, LaborCost: &labor, MaterialCost: &material, MarkupPercentage: &markup, FinalPrice: &final,
+		BidData: &bidDataStr, PDFURL: &pdfURL,
+	}
+	revisions := &fakeBidRevisionStore{revisions: []*models.BidRevision{newBidRevision(bid, 1, userID.String())}}
+	drafts := &fakeBidDraftStore{}
+
+	h := &BidHandlers{
+		projectRepo:     &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		bidRepo:         &fakeBidStore{bids: []*models.Bid{bid}},
+		bidRevisionRepo: revisions,
+		bidDraftRepo:    drafts,
+		events:          events.Discard,
+	}
+	return h, bid, revisions, drafts
+}
+
+func serveAsUser(router chi.Router, userID uuid.UUID, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, userID.String()))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// editedWindowDraft raises the window to 800.00 and the markup to 25%
+const editedWindowDraft = `{
+	"bid_data": {
+		"scope_of_work": "Install doors and windows",
+		"line_items": [
+			{"description": "Interior door installation", "trade": "carpentry", "quantity": 2, "unit": "each", "unit_cost": 400, "total": 800, "provenance": "auto"},
+			{"description": "Window installation", "trade": "carpentry", "quantity": 1, "unit": "each", "unit_cost": 800, "total": 700, "provenance": "auto"},
+			{"description": "Site protection", "trade": "general", "quantity": 1, "unit": "lot", "unit_cost": 250, "total": 250, "provenance": "manual"}
+		],
+		"material_cost": 1750, "subtotal": 1750, "markup_amount": 350, "total_price": 2100
+	},
+	"markup_percentage": 25
+}`
+
+func TestCommitBidDraft(t *testing.T) {
+	userID := uuid.New()
+	bid, revisions, drafts, router := newDraftTestBid(t, userID)
+	draftURL := "/bids/" + bid.ID.String() + "/draft"
+
+	// Saving twice leaves the bid and its revisions untouched
+	for i := 0; i < 2; i++ {
+		if rec := serveAsUser(router, userID, http.MethodPut, draftURL, editedWindowDraft); rec.Code != http.StatusOK {
+			t.Fatalf("save draft: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+		}
+	}
+	if bid.Version != 1 || *bid.FinalPrice != 2100 || len(revisions.revisions) != 1 || len(drafts.drafts) != 1 {
+		t.Fatalf("saving a draft changed the bid: version %d, final price %v, %d revisions, %d drafts",
+			bid.Version, *bid.FinalPrice, len(revisions.revisions), len(drafts.drafts))
+	}
+	rec := serveAsUser(router, userID, http.MethodGet, draftURL, "")
+	var draft models.BidDraft
+	if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&draft) != nil || draft.BaseVersion != 1 {
+		t.Fatalf("get draft: status = %d, draft %+v; want the version 1 draft", rec.Code, draft)
+	}
+
+	rec = serveAsUser(router, userID, http.MethodPost, draftURL+"/commit", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("commit: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var got CommitBidDraftResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(revisions.revisions) != 2 {
+		t.Fatalf("commit created %d revisions, want exactly one", len(revisions.revisions)-1)
+	}
+	if got.Revision == nil || got.Revision.Version != 2 || got.Revision.Reason == nil || *got.Revision.Reason != models.BidRevisionReasonEdit {
+		t.Fatalf("revision = %+v, want a version 2 edit revision", got.Revision)
+	}
+	// The window total follows its new unit cost and the totals move with it
+	if got.Bid.Version != 2 || *got.Bid.MaterialCost != 1850 || *got.Bid.MarkupPercentage != 25 || *got.Bid.FinalPrice != 2312.5 || got.Bid.PDFURL != nil {
+		t.Errorf("bid = %+v, want version 2 at 1850.00 plus 25%% markup and the PDF invalidated", got.Bid)
+	}
+	var edited models.GenerateBidResponse
+	if err := json.Unmarshal([]byte(*got.Bid.BidData), &edited); err != nil {
+		t.Fatalf("failed to decode bid data: %v", err)
+	}
+	for _, item := range edited.LineItems {
+		if item.Description == "Window installation" && (item.Total != 800 || item.Provenance != models.LineItemProvenanceManual) {
+			t.Errorf("window = %+v, want an 800.00 manual item", item)
+		}
+		if item.Description == "Interior door installation" && item.Provenance != models.LineItemProvenanceAuto {
+			t.Errorf("door = %+v, want the unedited item kept auto", item)
+		}
+	}
+
+	// The committed draft is gone
+	if rec := serveAsUser(router, userID, http.MethodGet, draftURL, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get committed draft: status = %d, want 404", rec.Code)
+	}
+	if rec := serveAsUser(router, userID, http.MethodPost, draftURL+"/commit", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("recommit: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestCommitBidDraft_StaleBase(t *testing.T) {
+	userID := uuid.New()
+	bid, revisions, drafts, router := newDraftTestBid(t, userID)
+	draftURL := "/bids/" + bid.ID.String() + "/draft"
+
+	if rec := serveAsUser(router, userID, http.MethodPut, draftURL, editedWindowDraft); rec.Code != http.StatusOK {
+		t.Fatalf("save draft: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+
+	// The bid moves to version 2 underneath the draft
+	if rec := serveAsUser(router, userID, http.MethodPatch, "/bids/"+bid.ID.String(), `{"name": "Renamed"}`); rec.Code != http.StatusOK {
+		t.Fatalf("rename: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+
+	rec := serveAsUser(router, userID, http.MethodPost, draftURL+"/commit", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("commit: status = %d, body %s; want 409", rec.Code, rec.Body.String())
+	}
+	if len(revisions.revisions) != 2 || bid.Version != 2 || *bid.FinalPrice != 2100 {
+		t.Errorf("stale commit changed the bid: version %d, final price %v, %d revisions", bid.Version, *bid.FinalPrice, len(revisions.revisions))
+	}
+	if len(drafts.drafts) != 1 {
+		t.Errorf("stale commit removed the draft; %d drafts left", len(drafts.drafts))
+	}
+
+	// Discarding the draft lets the user start again from the current version
+	if rec := serveAsUser(router, userID, http.MethodDelete, draftURL, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("discard: status = %d, want 204", rec.Code)
+	}
+	if rec := serveAsUser(router, userID, http.MethodPut, draftURL, editedWindowDraft); rec.Code != http.StatusOK {
+		t.Fatalf("save draft: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	if rec := serveAsUser(router, userID, http.MethodPost, draftURL+"/commit", ""); rec.Code != http.StatusOK {
+		t.Errorf("commit after rebasing: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+}
+
+// movedBidStore is a bid store where another save lands between a handler's
+// version check and its own save
+type movedBidStore struct {
+	*fakeBidStore
+}
+
+func (s movedBidStore) LockVersion(ctx context.Context, id uuid.UUID, version int) error {
+	return repository.ErrBidVersionChanged
+}
+
+func TestCommitBidDraft_ConcurrentCommit(t *testing.T) {
+	userID := uuid.New()
+	h, bid, revisions, drafts := newDraftTestHandlers(t, userID)
+	tx := &fakeTransactor{}
+	bids := h.bidRepo.(*fakeBidStore)
+	h.tx, h.bidRepo = tx, movedBidStore{bids}
+	router := chi.NewRouter()
+	h.Routes(router)
+	draftURL := "/bids/" + bid.ID.String() + "/draft"
+
+	if rec := serveAsUser(router, userID, http.MethodPut, draftURL, editedWindowDraft); rec.Code != http.StatusOK {
+		t.Fatalf("save draft: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+
+	// The draft passes the version check, then loses to the other save
+	rec := serveAsUser(router, userID, http.MethodPost, draftURL+"/commit", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("commit: status = %d, body %s; want 409", rec.Code, rec.Body.String())
+	}
+	if len(revisions.revisions) != 1 || bid.Version != 1 || tx.commits != 0 || tx.rollbacks != 1 {
+		t.Errorf("losing commit wrote: version %d, %d revisions, %d commits, %d rollbacks", bid.Version, len(revisions.revisions), tx.commits, tx.rollbacks)
+	}
+	if len(drafts.drafts) != 1 {
+		t.Errorf("losing commit removed the draft; %d drafts left", len(drafts.drafts))
+	}
+
+	// Uncontended, the revision and the bid are saved in one transaction
+	h.bidRepo = bids
+	if rec := serveAsUser(router, userID, http.MethodPost, draftURL+"/commit", ""); rec.Code != http.StatusOK {
+		t.Fatalf("commit: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	if len(revisions.revisions) != 2 || bid.Version != 2 || tx.commits != 1 {
+		t.Errorf("commit: version %d, %d revisions, %d commits; want version 2 saved in one transaction", bid.Version, len(revisions.revisions), tx.commits)
+	}
+}
+
+func TestCommitBidDraft_LineItemNotes(t *testing.T) {
+	userID := uuid.New()
+	bid, _, drafts, router := newDraftTestBid(t, userID)
+	draftURL := "/bids/" + bid.ID.String() + "/draft"
+
+	notesDraft := func(notes string) string {
+		encoded, _ := json.Marshal(notes)
+		return strings.Replace(editedWindowDraft,
+			`"unit_cost": 250, "total": 250, "provenance": "manual"}`,
+			`"unit_cost": 250, "total": 250, "provenance": "manual", "notes": `+string(encoded)+`}`, 1)
+	}
+
+	if rec := serveAsUser(router, userID, http.MethodPut, draftURL, notesDraft(strings.Repeat("x", 501))); rec.Code != http.StatusBadRequest {
+		t.Fatalf("save draft with 501-character notes: status = %d, want 400", rec.Code)
+	}
+	if len(drafts.drafts) != 0 {
+		t.Fatal("a rejected draft was saved")
+	}
+
+	if rec := serveAsUser(router, userID, http.MethodPut, draftURL, notesDraft("Assumes owner removes existing furniture")); rec.Code != http.StatusOK {
+		t.Fatalf("save draft: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	rec := serveAsUser(router, userID, http.MethodPost, draftURL+"/commit", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("commit: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var got CommitBidDraftResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	var edited models.GenerateBidResponse
+	if err := json.Unmarshal([]byte(*got.Bid.BidData), &edited); err != nil {
+		t.Fatalf("failed to decode bid data: %v", err)
+	}
+	for _, item := range edited.LineItems {
+		if item.Description == "Site protection" && (item.Notes != "Assumes owner removes existing furniture" || item.Total != 250) {
+			t.Errorf("site protection = %+v, want its notes kept and its total unchanged", item)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// UpdateBid replaces a bid's data with an edited copy and saves it as an
+// "edit" revision. Line item totals, the subtotal, markup and total price are
+// recalculated with the bid's markup percentage, so totals sent by the client
+// are ignored. Accepted bids are final and cannot be edited.
+func (h *BidHandlers) UpdateBid(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var edited models.GenerateBidResponse
+	if err := json.NewDecoder(r.Body).Decode(&edited); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	bid, project, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
+		return
+	}
+	markupPercentage := bidMarkupPercentage(bid)
+	if !applyBidEdit(w, bid, &edited, markupPercentage) {
+		return
+	}
+
+	revision, err := h.saveBidEdit(r.Context(), bid, &edited, markupPercentage, getUserID(r.Context()))
+	if errors.Is(err, repository.ErrBidVersionChanged) {
+		respondError(w, http.StatusConflict, "Bid changed while it was being edited")
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to save edited bid", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update bid")
+		return
+	}
+
+	slog.Info("Bid edited",
+		"bid_id", bidID,
+		"version", revision.Version,
+		"correlation_id", getCorrelationID(r.Context()))
+
+	bid.BudgetStatus = services.EvaluateBudget(project.Budget, edited.TotalPrice)
+	respondJSON(w, http.StatusOK, bid)
+}
+
+// applyBidEdit recalculates edited, a hand-edited copy of a bid's data, in
+// place. It writes the error response and returns false when the bid is
+// accepted, has no data to edit or the edit is invalid.
+func applyBidEdit(w http.ResponseWriter, bid *models.Bid, edited *models.GenerateBidResponse, markupPercentage float64) bool {
+	if !requireEditableBid(w, bid) {
+		return false
+	}
+	if bid.BidData == nil {
+		respondError(w, http.StatusBadRequest, "Bid data not available")
+		return false
+	}
+	var original models.GenerateBidResponse
+	if err := json.Unmarshal([]byte(*bid.BidData), &original); err != nil {
+		slog.Error("Failed to parse bid data", "bid_id", bid.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to parse bid data")
+		return false
+	}
+
+	// An edit changes the bid's content, not which bid and takeoff it belongs to
+	edited.BidID = original.BidID
+	edited.ProjectID = original.ProjectID
+	edited.BlueprintID = original.BlueprintID
+	edited.SourceBlueprints = original.SourceBlueprints
+	if err := services.ApplyBidEdits(&original, edited, markupPercentage); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// saveBidEdit stores recalculated bid data on the bid as an "edit" revision
+// and saves the bid, in one transaction. It returns
+// repository.ErrBidVersionChanged when the bid moved past the version it was
+// loaded at, so of concurrent edits only the first is saved.
+func (h *BidHandlers) saveBidEdit(ctx context.Context, bid *models.Bid, edited *models.GenerateBidResponse, markupPercentage float64, userID string) (*models.BidRevision, error) {
+	bidData, err := json.Marshal(edited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode edited bid: %w", err)
+	}
+
+	baseVersion := bid.Version
+	before := newBidRevision(bid, baseVersion, "")
+	bidDataStr := string(bidData)
+	bid.BidData = &bidDataStr
+	bid.TotalCost = &edited.Subtotal
+	bid.LaborCost = &edited.LaborCost
+	bid.MaterialCost = &edited.MaterialCost
+	bid.MarkupPercentage = &markupPercentage
+	bid.FinalPrice = &edited.TotalPrice
+	bid.CostsByTrade = services.CostsByTrade(edited.LineItems)
+	// The PDF hash covers the bid data, so the next download re-renders it
+	bid.PDFURL = nil
+
+	var revision *models.BidRevision
+	err = inTx(ctx, h.tx, func(ctx context.Context) error {
+		// Locked before the revision is numbered, so a concurrent edit
+		// waits here and then finds the version moved
+		if err := h.bidRepo.LockVersion(ctx, bid.ID, baseVersion); err != nil {
+			return err
+		}
+		revision, err = createBidRevision(ctx, h.bidRevisionRepo, bid, userID, before, models.BidRevisionReasonEdit)
+		if err != nil {
+			return fmt.Errorf("failed to create bid revision: %w", err)
+		}
+		bid.Version = revision.Version
+		bid.UpdatedAt = models.Now()
+		return h.bidRepo.Update(ctx, bid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// bidMarkupPercentage is the markup a bid was priced with, 20% when unset
+func bidMarkupPercentage(bid *models.Bid) float64 {
+	if bid.MarkupPercentage != nil {
+		return *bid.MarkupPercentage
+	}
+	return 20.0
+}
+
+// requireEditableBid writes a 409 and returns false when bid is accepted.
+// Accepted bids are final: their name, prices and revisions are what the
+// client signed.
+func requireEditableBid(w http.ResponseWriter, bid *models.Bid) bool {
+	if bid.Status == models.BidStatusAccepted {
+		respondAPIError(w, http.StatusConflict, CodeBidAccepted, "Accepted bids cannot be changed")
+		return false
+	}
+	return true
+}
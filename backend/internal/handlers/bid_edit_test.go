@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// editedWindowBid raises the window to 800.00, adds an exclusion and sends
+// totals that don't match its line items
+const editedWindowBid = `{
+	"scope_of_work": "Install doors and windows",
+	"line_items": [
+		{"description": "Interior door installation", "trade": "carpentry", "quantity": 2, "unit": "each", "unit_cost": 400, "total": 1, "provenance": "auto"},
+		{"description": "Window installation", "trade": "carpentry", "quantity": 1, "unit": "each", "unit_cost": 800, "total": 700, "provenance": "auto"},
+		{"description": "Site protection", "trade": "general", "quantity": 1, "unit": "lot", "unit_cost": 250, "total": 250, "provenance": "manual"}
+	],
+	"exclusions": ["Permit fees"],
+	"payment_terms": "Net 15",
+	"material_cost": 5, "subtotal": 5, "markup_amount": 1, "total_price": 6
+}`
+
+func TestUpdateBid(t *testing.T) {
+	userID := uuid.New()
+	bid, revisions, _, router := newDraftTestBid(t, userID)
+	bidURL := "/bids/" + bid.ID.String()
+
+	rec := serveAsUser(router, userID, http.MethodPut, bidURL, editedWindowBid)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var got models.Bid
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	// Totals are recomputed at the bid's 20% markup, not taken from the request
+	if got.Version != 2 || *got.MaterialCost != 1850 || *got.TotalCost != 1850 || *got.FinalPrice != 2220 || got.PDFURL != nil {
+		t.Errorf("bid = %+v, want version 2 at 1850.00 plus 20%% markup and the PDF invalidated", got)
+	}
+	if len(revisions.revisions) != 2 || revisions.revisions[1].Reason == nil || *revisions.revisions[1].Reason != models.BidRevisionReasonEdit {
+		t.Fatalf("revisions = %d, want a version 2 edit revision", len(revisions.revisions))
+	}
+
+	var data models.GenerateBidResponse
+	if err := json.Unmarshal([]byte(*got.BidData), &data); err != nil {
+		t.Fatalf("failed to decode bid data: %v", err)
+	}
+	if data.Subtotal != 1850 || data.MarkupAmount != 370 || data.TotalPrice != 2220 {
+		t.Errorf("bid data totals = %v + %v = %v, want 1850 + 370 = 2220", data.Subtotal, data.MarkupAmount, data.TotalPrice)
+	}
+	for _, item := range data.LineItems {
+		if item.Total != item.Quantity*item.UnitCost {
+			t.Errorf("%s total = %v, want %v", item.Description, item.Total, item.Quantity*item.UnitCost)
+		}
+	}
+	if len(data.Exclusions) != 1 || data.PaymentTerms != "Net 15" {
+		t.Errorf("exclusions %v, payment terms %q; want the edited values", data.Exclusions, data.PaymentTerms)
+	}
+
+	// Invalid line items are rejected without saving
+	negative := `{"line_items": [{"description": "Credit", "trade": "general", "quantity": 1, "unit": "lot", "unit_cost": -50}]}`
+	if rec := serveAsUser(router, userID, http.MethodPut, bidURL, negative); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative line item: status = %d, want 400", rec.Code)
+	}
+
+	// Accepted bids are final
+	bid.Status = models.BidStatusAccepted
+	if rec := serveAsUser(router, userID, http.MethodPut, bidURL, editedWindowBid); rec.Code != http.StatusConflict {
+		t.Errorf("edit accepted bid: status = %d, want 409", rec.Code)
+	}
+	if bid.Version != 2 || len(revisions.revisions) != 2 {
+		t.Errorf("rejected edits changed the bid: version %d, %d revisions", bid.Version, len(revisions.revisions))
+	}
+}
+
+func TestAcceptedBidIsFinal(t *testing.T) {
+	userID := uuid.New()
+	h, bid, revisions, _ := newDraftTestHandlers(t, userID)
+	router := chi.NewRouter()
+	h.Routes(router)
+	(&RevisionHandlers{projectRepo: h.projectRepo, bidRepo: h.bidRepo, bidRevisionRepo: revisions}).Routes(router)
+	bidURL := "/bids/" + bid.ID.String()
+
+	if rec := serveAsUser(router, userID, http.MethodPut, bidURL+"/draft", editedWindowDraft); rec.Code != http.StatusOK {
+		t.Fatalf("save draft: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	bid.Status = models.BidStatusAccepted
+	want := *bid
+
+	tests := []struct {
+		name, method, target, body string
+	}{
+		{"edit", http.MethodPut, bidURL, editedWindowBid},
+		{"commit draft", http.MethodPost, bidURL + "/draft/commit", ""},
+		{"rename", http.MethodPatch, bidURL, `{"name": "Renamed"}`},
+		{"reprice", http.MethodPost, bidURL + "/reprice", ""},
+		{"create revision", http.MethodPost, bidURL + "/revisions", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAsUser(router, userID, tt.method, tt.target, tt.body)
+			var body apiErrorBody
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || rec.Code != http.StatusConflict || body.Code != CodeBidAccepted {
+				t.Errorf("status = %d, code %q; want 409 %s", rec.Code, body.Code, CodeBidAccepted)
+			}
+			if !reflect.DeepEqual(*bid, want) || len(revisions.revisions) != 1 {
+				t.Errorf("accepted bid changed: %+v with %d revisions", *bid, len(revisions.revisions))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// QuickBooksValidationResponse lists the line items a QuickBooks export would
+// put under the catch-all item
+type QuickBooksValidationResponse struct {
+	DefaultItem string                       `json:"default_item"`
+	Warnings    []services.QuickBooksWarning `json:"warnings"`
+}
+
+// ExportBid exports a bid in the format named by the format query parameter:
+// csv, xlsx or quickbooks
+func (h *BidHandlers) ExportBid(w http.ResponseWriter, r *http.Request) {
+	if _, err := parseUUIDParam(r, "id"); err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	switch services.ExportFormat(r.URL.Query().Get("format")) {
+	case services.ExportFormatCSV:
+		h.GetBidCSV(w, r)
+	case services.ExportFormatExcel:
+		h.GetBidExcel(w, r)
+	case services.ExportFormatQuickBooks:
+		h.exportBidQuickBooks(w, r)
+	default:
+		respondError(w, http.StatusBadRequest, "format must be csv, xlsx or quickbooks")
+	}
+}
+
+// exportBidQuickBooks returns a bid as a QuickBooks estimate import CSV, with
+// the number of line items exported under the catch-all item in the
+// X-Export-Warnings header. With validate=true the warnings are returned as
+// JSON instead of the file.
+func (h *BidHandlers) exportBidQuickBooks(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	bid, project, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
+		return
+	}
+	if bid.BidData == nil {
+		respondError(w, http.StatusInternalServerError, "Bid data not available")
+		return
+	}
+
+	exportService := services.NewExportService()
+	bidResponse, err := exportService.ParseBidDataFromJSON(*bid.BidData)
+	if err != nil {
+		slog.Error("Failed to parse bid data", "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to parse bid data")
+		return
+	}
+
+	owner, err := h.userRepo.GetUserByID(r.Context(), project.UserID)
+	if err != nil {
+		slog.Error("Failed to load QuickBooks item mapping", "error", err, "user_id", project.UserID)
+		respondError(w, http.StatusInternalServerError, "Failed to generate QuickBooks export")
+		return
+	}
+	defaultItem := services.DefaultQuickBooksItem
+	if owner.QuickBooksDefaultItem != nil && *owner.QuickBooksDefaultItem != "" {
+		defaultItem = *owner.QuickBooksDefaultItem
+	}
+
+	// Projects have no separate client, so the estimate is for the project
+	data, warnings, err := exportService.GenerateBidQuickBooks(bid, bidResponse, project.Name, owner.QuickBooksItems, defaultItem)
+	if err != nil {
+		slog.Error("Failed to generate QuickBooks export", "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to generate QuickBooks export")
+		return
+	}
+
+	if r.URL.Query().Get("validate") == "true" {
+		if warnings == nil {
+			warnings = []services.QuickBooksWarning{}
+		}
+		respondJSON(w, http.StatusOK, QuickBooksValidationResponse{DefaultItem: defaultItem, Warnings: warnings})
+		return
+	}
+
+	w.Header().Set("Content-Type", services.QuickBooksContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", exportService.GenerateQuickBooksFilename(bid.ID)))
+	w.Header().Set("X-Export-Warnings", strconv.Itoa(len(warnings)))
+	w.Write(data)
+}
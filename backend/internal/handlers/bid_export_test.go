@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestExportBid_QuickBooks(t *testing.T) {
+	owner := &models.User{ID: uuid.New(), QuickBooksItems: map[string]string{"carpentry": "Finish Carpentry"}}
+	project := &models.Project{ID: uuid.New(), UserID: owner.ID, Name: "Smith Kitchen"}
+	bidData, _ := json.Marshal(models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Interior door installation", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 400, Total: 800},
+			{Description: "Pool enclosure", Trade: "aquatic engineering", Quantity: 1, Unit: "lot", UnitCost: 3000, Total: 3000},
+		},
+	})
+	bidDataStr := string(bidData)
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, BidData: &bidDataStr}
+
+	h := &BidHandlers{
+		projectRepo: &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		bidRepo:     &fakeBidStore{bids: []*models.Bid{bid}},
+		userRepo:    &fakeUserStore{users: map[uuid.UUID]*models.User{owner.ID: owner}},
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+	exportURL := "/bids/" + bid.ID.String() + "/export"
+
+	rec := serveAsUser(router, owner.ID, http.MethodGet, exportURL+"?format=quickbooks", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != services.QuickBooksContentType || rec.Header().Get("X-Export-Warnings") != "1" {
+		t.Errorf("headers = %v, want a QuickBooks CSV with one warning", rec.Header())
+	}
+	if !strings.Contains(rec.Body.String(), "Smith Kitchen,") || !strings.Contains(rec.Body.String(), "Finish Carpentry,Interior door installation") {
+		t.Errorf("export does not use the project and item mapping:\n%s", rec.Body.String())
+	}
+
+	rec = serveAsUser(router, owner.ID, http.MethodGet, exportURL+"?format=quickbooks&validate=true", "")
+	var validation QuickBooksValidationResponse
+	if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&validation) != nil {
+		t.Fatalf("validate: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if validation.DefaultItem != services.DefaultQuickBooksItem || len(validation.Warnings) != 1 || validation.Warnings[0].Description != "Pool enclosure" {
+		t.Errorf("validation = %+v, want the pool enclosure under the catch-all item", validation)
+	}
+
+	// Another user's bid is not found, and unknown formats are rejected
+	if rec := serveAsUser(router, uuid.New(), http.MethodGet, exportURL+"?format=quickbooks", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("other user: status = %d, want 404", rec.Code)
+	}
+	if rec := serveAsUser(router, owner.ID, http.MethodGet, exportURL+"?format=iif", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestGetBidCSV_UnitSystem(t *testing.T) {
+	owner := &models.User{ID: uuid.New(), UnitSystem: models.UnitSystemMetric}
+	project := &models.Project{ID: uuid.New(), UserID: owner.ID, Name: "Smith Kitchen"}
+	bidData, _ := json.Marshal(models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Flooring", Trade: "flooring", Quantity: 100, Unit: "sq ft", UnitCost: 5, Total: 500},
+		},
+	})
+	bidDataStr := string(bidData)
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, BidData: &bidDataStr}
+
+	h := &BidHandlers{
+		projectRepo: &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		bidRepo:     &fakeBidStore{bids: []*models.Bid{bid}},
+		userRepo:    &fakeUserStore{users: map[uuid.UUID]*models.User{owner.ID: owner}},
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+	csvURL := "/bids/" + bid.ID.String() + "/csv"
+
+	// The company default applies unless the request names a unit system
+	rec := serveAsUser(router, owner.ID, http.MethodGet, csvURL, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Flooring,flooring,9.29,m2,53.82,500.00") {
+		t.Errorf("default: status = %d, want the flooring in m2:\n%s", rec.Code, rec.Body.String())
+	}
+	rec = serveAsUser(router, owner.ID, http.MethodGet, csvURL+"?units=imperial", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Flooring,flooring,100.00,sq ft,5.00,500.00") {
+		t.Errorf("imperial: status = %d, want the flooring in sq ft:\n%s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,558 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// BidShareHandlers shares bids with clients who have no account: a link
+// shows the client a read-only bid and lets them accept it, signed with their
+// name and email, or reject it, once
+type BidShareHandlers struct {
+	projectRepo     ProjectStore
+	bidRepo         BidStore
+	bidRevisionRepo BidRevisionStore
+	shareRepo       BidShareStore
+	acceptanceRepo  BidAcceptanceStore
+	eventRepo       BidShareEventStore
+	tx              Transactor
+	events          events.Publisher
+	expiry          time.Duration
+	baseURL         string
+
+	publicRequestsPerMinute int
+	viewRateLimit           func(http.Handler) http.Handler
+	respondRateLimit        func(http.Handler) http.Handler
+	eventRateLimit          func(http.Handler) http.Handler
+}
+
+func NewBidShareHandlers(projectRepo ProjectStore, bidRepo BidStore, bidRevisionRepo BidRevisionStore, shareRepo BidShareStore, acceptanceRepo BidAcceptanceStore, eventRepo BidShareEventStore, tx Transactor, publisher events.Publisher, cfg *config.Config) *BidShareHandlers {
+	h := &BidShareHandlers{
+		projectRepo:     projectRepo,
+		bidRepo:         bidRepo,
+		bidRevisionRepo: bidRevisionRepo,
+		shareRepo:       shareRepo,
+		acceptanceRepo:  acceptanceRepo,
+		eventRepo:       eventRepo,
+		tx:              tx,
+		events:          publisher,
+		expiry:          services.DefaultBidShareExpiry,
+	}
+	if cfg != nil {
+		h.expiry = cfg.BidShares.Expiry
+		h.baseURL = cfg.BidShares.BaseURL
+		if cfg.RateLimit.Enabled {
+			h.publicRequestsPerMinute = cfg.RateLimit.AuthRequestsPerMinute
+		}
+	}
+	return h
+}
+
+// Routes registers the authenticated routes that share a bid, revoke its
+// links and show what clients did with them
+func (h *BidShareHandlers) Routes(r chi.Router) {
+	r.Post("/bids/{id}/share", h.ShareBid)
+	r.Delete("/bids/{id}/shares/{shareId}", h.RevokeBidShare)
+	r.Get("/bids/{id}/engagement", h.GetBidEngagement)
+}
+
+// PublicRoutes registers the routes clients reach through a share link.
+// They are authenticated by the token alone, so they get the strict per-IP
+// limit the auth routes have. Accepting and responding share one limit;
+// engagement events have their own, so a chatty page can't use up the
+// client's views.
+func (h *BidShareHandlers) PublicRoutes(r chi.Router) {
+	if h.viewRateLimit == nil {
+		h.viewRateLimit = middleware.AuthRateLimit(h.publicRequestsPerMinute)
+		h.respondRateLimit = middleware.AuthRateLimit(h.publicRequestsPerMinute)
+		h.eventRateLimit = middleware.AuthRateLimit(h.publicRequestsPerMinute)
+	}
+	r.With(h.viewRateLimit).Get("/public/bids/{token}", h.GetSharedBid)
+	r.With(h.respondRateLimit).Post("/public/bids/{token}/respond", h.RespondToSharedBid)
+	r.With(h.respondRateLimit).Post("/public/bids/{token}/accept", h.AcceptSharedBid)
+	r.With(h.eventRateLimit).Post("/public/bids/{token}/events", h.RecordSharedBidEvent)
+}
+
+// ShareBidResponse is the only response that includes the share token
+type ShareBidResponse struct {
+	*models.BidShare
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// RespondToSharedBidRequest is a client's decision on a shared bid, signed
+// with their name. Accepting also needs their email.
+type RespondToSharedBidRequest struct {
+	Decision string  `json:"decision"`
+	Comment  *string `json:"comment"`
+	Name     string  `json:"name"`
+	Email    string  `json:"email"`
+}
+
+// RecordSharedBidEventRequest is something the client's page saw the client
+// do with a shared bid
+type RecordSharedBidEventRequest struct {
+	Type string `json:"type"`
+}
+
+// AcceptSharedBidRequest is a client's signature accepting a shared bid
+type AcceptSharedBidRequest struct {
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Comment *string `json:"comment"`
+}
+
+// ShareBid creates a link to a bid for its client. Sharing a draft bid sends
+// it; accepted and rejected bids cannot be shared.
+func (h *BidShareHandlers) ShareBid(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	bid, _, ok := loadUserBid(w, r, h.bidRepo, h.projectRepo, bidID)
+	if !ok {
+		return
+	}
+	if bid.Status != models.BidStatusDraft && bid.Status != models.BidStatusSent {
+		respondError(w, http.StatusConflict, "Only draft and sent bids can be shared")
+		return
+	}
+
+	token, tokenHash, err := services.NewBidShareToken()
+	if err != nil {
+		slog.Error("Failed to generate bid share token", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to share bid")
+		return
+	}
+	now := time.Now()
+	share := &models.BidShare{
+		ID:        uuid.New(),
+		BidID:     bid.ID,
+		TokenHash: tokenHash,
+		CreatedBy: requestUserID(r),
+		ExpiresAt: models.NewTimestamp(now.Add(h.expiry)),
+		CreatedAt: models.NewTimestamp(now),
+	}
+	if err := h.shareRepo.Create(r.Context(), share); err != nil {
+		slog.Error("Failed to create bid share", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to share bid")
+		return
+	}
+
+	if bid.Status == models.BidStatusDraft {
+		changedAt := models.NewTimestamp(now)
+		bid.Status = models.BidStatusSent
+		bid.StatusNote = nil
+		bid.StatusChangedAt = &changedAt
+		bid.UpdatedAt = changedAt
+		if err := h.bidRepo.Update(r.Context(), bid); err != nil {
+			slog.Error("Failed to mark shared bid sent", "bid_id", bidID, "error", err)
+			respondError(w, http.StatusInternalServerError, "Failed to share bid")
+			return
+		}
+		h.events.Publish(r.Context(), events.BidStatusChanged{
+			BidID:         bid.ID,
+			ProjectID:     bid.ProjectID,
+			From:          models.BidStatusDraft,
+			To:            models.BidStatusSent,
+			Version:       bid.Version,
+			UserID:        getUserID(r.Context()),
+			CorrelationID: getCorrelationID(r.Context()),
+		})
+	}
+
+	slog.Info("Bid shared",
+		"audit_event", "bid.shared",
+		"bid_id", bid.ID,
+		"project_id", bid.ProjectID,
+		"share_id", share.ID,
+		"expires_at", share.ExpiresAt,
+		"user_id", getUserID(r.Context()),
+		"correlation_id", getCorrelationID(r.Context()))
+
+	respondJSON(w, http.StatusCreated, ShareBidResponse{
+		BidShare: share,
+		Token:    token,
+		URL:      services.BidShareURL(h.baseURL, token),
+	})
+}
+
+// GetSharedBid shows a client the bid shared with them and records the
+// view. Unknown tokens are 404 and expired or revoked ones 410.
+func (h *BidShareHandlers) GetSharedBid(w http.ResponseWriter, r *http.Request) {
+	share, ok := h.loadShare(w, r)
+	if !ok {
+		return
+	}
+	bid, data, ok := h.loadSharedBid(w, r, share)
+	if !ok {
+		return
+	}
+	h.recordView(r, share, bid)
+	respondJSON(w, http.StatusOK, services.NewPublicBid(bid, data, share))
+}
+
+// RecordSharedBidEvent records a section the client read or a PDF download,
+// as the client's page reports them. Once a link has
+// services.MaxBidShareEvents events, later ones are dropped but still
+// answered 204.
+func (h *BidShareHandlers) RecordSharedBidEvent(w http.ResponseWriter, r *http.Request) {
+	var req RecordSharedBidEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	eventType, err := services.ParseBidShareEventType(req.Type)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	share, ok := h.loadShare(w, r)
+	if !ok {
+		return
+	}
+	if err := h.recordEvent(r, share, eventType, time.Now()); err != nil {
+		slog.Error("Failed to record bid share event", "share_id", share.ID, "event_type", eventType, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to record event")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBidEngagement shows the owner what clients did through each of a bid's
+// share links: when they viewed it, how often and by how many viewers, the
+// sections they read and their PDF downloads
+func (h *BidShareHandlers) GetBidEngagement(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	bid, _, ok := loadUserBid(w, r, h.bidRepo, h.projectRepo, bidID)
+	if !ok {
+		return
+	}
+	shares, err := h.shareRepo.ListByBidID(r.Context(), bid.ID)
+	if err != nil {
+		slog.Error("Failed to list bid shares", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get bid engagement")
+		return
+	}
+	shareEvents, err := h.eventRepo.ListByBidID(r.Context(), bid.ID)
+	if err != nil {
+		slog.Error("Failed to list bid share events", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get bid engagement")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, services.AggregateBidEngagement(bid.ID, shares, shareEvents))
+}
+
+// RespondToSharedBid records a client's acceptance or rejection of a shared
+// bid and moves the bid to that status. An acceptance is recorded as
+// AcceptSharedBid records it.
+func (h *BidShareHandlers) RespondToSharedBid(w http.ResponseWriter, r *http.Request) {
+	var req RespondToSharedBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	h.respond(w, r, req.Decision, req.Comment, req.Name, req.Email)
+}
+
+// AcceptSharedBid records a client's signed acceptance of a shared bid: the
+// name and email they typed, with the address, browser and time they
+// accepted from. Accepting again through the same link returns the bid as
+// accepted.
+func (h *BidShareHandlers) AcceptSharedBid(w http.ResponseWriter, r *http.Request) {
+	var req AcceptSharedBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	h.respond(w, r, string(models.BidStatusAccepted), req.Comment, req.Name, req.Email)
+}
+
+// respond moves a shared bid to the client's decision. Each link takes one
+// response; a different second one is refused with 409, as is a response to
+// a bid that is no longer sent. Accepting saves an "accepted" revision, as
+// accepting through PATCH /bids/{id}/status does, and an acceptance record
+// the bid's PDF is stamped with.
+func (h *BidShareHandlers) respond(w http.ResponseWriter, r *http.Request, decision string, comment *string, name, email string) {
+	response, err := services.ParseBidShareResponse(decision, comment, name)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	accepting := response.Status == models.BidStatusAccepted
+	if accepting {
+		if email, err = services.ParseBidSignerEmail(email); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	share, ok := h.loadShare(w, r)
+	if !ok {
+		return
+	}
+	if share.RespondedAt != nil {
+		// Accepting twice is answered as the first acceptance was
+		if accepting && share.Response != nil && *share.Response == models.BidStatusAccepted {
+			if bid, data, ok := h.loadSharedBid(w, r, share); ok {
+				respondJSON(w, http.StatusOK, services.NewPublicBid(bid, data, share))
+			}
+			return
+		}
+		respondError(w, http.StatusConflict, "This bid has already been answered")
+		return
+	}
+	bid, data, ok := h.loadSharedBid(w, r, share)
+	if !ok {
+		return
+	}
+	from := bid.Status
+	if err := services.CheckBidStatusTransition(from, response.Status); err != nil {
+		respondError(w, http.StatusConflict, "This bid is no longer open for a response")
+		return
+	}
+
+	now := time.Now()
+	changedAt := models.NewTimestamp(now)
+	var acceptance *models.BidAcceptance
+	if accepting {
+		acceptance = &models.BidAcceptance{
+			ID:          uuid.New(),
+			BidID:       bid.ID,
+			ShareID:     share.ID,
+			SignerName:  response.ResponderName,
+			SignerEmail: email,
+			IPAddress:   middleware.ClientIP(r),
+			UserAgent:   r.UserAgent(),
+			AcceptedAt:  changedAt,
+		}
+	}
+
+	// The revision, the bid, the link and the acceptance are written
+	// together. A response that lost the race to the link rolls back the rest.
+	before := newBidRevision(bid, bid.Version, "")
+	bid.Status = response.Status
+	bid.StatusNote = response.Comment
+	bid.StatusChangedAt = &changedAt
+	bid.UpdatedAt = changedAt
+	if accepting {
+		// The PDF is stamped with the acceptance, so the next download
+		// re-renders it
+		bid.PDFURL = nil
+	}
+	err = inTx(r.Context(), h.tx, func(ctx context.Context) error {
+		if accepting {
+			revision, err := createBidRevision(ctx, h.bidRevisionRepo, bid, "", before, models.BidRevisionReasonAccepted)
+			if err != nil {
+				return fmt.Errorf("failed to create bid revision: %w", err)
+			}
+			bid.Version = revision.Version
+		}
+		if err := h.bidRepo.Update(ctx, bid); err != nil {
+			return err
+		}
+		if share, err = h.shareRepo.Respond(ctx, share.ID, response, now); err != nil {
+			return err
+		}
+		if accepting {
+			return h.acceptanceRepo.Create(ctx, acceptance)
+		}
+		return nil
+	})
+	if err != nil {
+		// Another response got in first, or the link expired or was revoked
+		// meanwhile
+		if errors.Is(err, repository.ErrBidShareNotFound) {
+			respondError(w, http.StatusConflict, "This bid has already been answered")
+			return
+		}
+		slog.Error("Failed to record bid share response", "bid_id", bid.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to record response")
+		return
+	}
+
+	slog.Info("Client responded to bid",
+		"audit_event", "bid.client_responded",
+		"bid_id", bid.ID,
+		"project_id", bid.ProjectID,
+		"share_id", share.ID,
+		"from", from,
+		"to", response.Status,
+		"version", bid.Version,
+		"responder_name", response.ResponderName,
+		"correlation_id", getCorrelationID(r.Context()))
+	h.events.Publish(r.Context(), events.BidStatusChanged{
+		BidID:         bid.ID,
+		ProjectID:     bid.ProjectID,
+		From:          from,
+		To:            response.Status,
+		Version:       bid.Version,
+		CorrelationID: getCorrelationID(r.Context()),
+	})
+	if acceptance != nil {
+		h.events.Publish(r.Context(), events.BidAccepted{
+			ProjectID:     bid.ProjectID,
+			Acceptance:    *acceptance,
+			CorrelationID: getCorrelationID(r.Context()),
+		})
+	}
+
+	respondJSON(w, http.StatusOK, services.NewPublicBid(bid, data, share))
+}
+
+// RevokeBidShare stops one of a bid's share links from working. A response
+// already given through it stands.
+func (h *BidShareHandlers) RevokeBidShare(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+	shareID, err := parseUUIDParam(r, "shareId")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	bid, _, ok := loadUserBid(w, r, h.bidRepo, h.projectRepo, bidID)
+	if !ok {
+		return
+	}
+	share, err := h.shareRepo.Revoke(r.Context(), bid.ID, shareID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrBidShareNotFound) {
+			respondNotFound(w)
+			return
+		}
+		slog.Error("Failed to revoke bid share", "bid_id", bidID, "share_id", shareID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to revoke share")
+		return
+	}
+
+	slog.Info("Bid share revoked",
+		"audit_event", "bid.share_revoked",
+		"bid_id", bid.ID,
+		"project_id", bid.ProjectID,
+		"share_id", share.ID,
+		"user_id", getUserID(r.Context()),
+		"correlation_id", getCorrelationID(r.Context()))
+
+	respondJSON(w, http.StatusOK, share)
+}
+
+// recordView records a client opening a share link and tells the owner the
+// first time it is opened. Failures are logged; they don't fail the view.
+func (h *BidShareHandlers) recordView(r *http.Request, share *models.BidShare, bid *models.Bid) {
+	now := time.Now()
+	if err := h.recordEvent(r, share, models.BidShareEventViewed, now); err != nil {
+		slog.Error("Failed to record bid share view", "share_id", share.ID, "error", err)
+	}
+
+	first, err := h.shareRepo.MarkViewed(r.Context(), share.ID, now)
+	if err != nil {
+		slog.Error("Failed to mark bid share viewed", "share_id", share.ID, "error", err)
+		return
+	}
+	if first {
+		h.events.Publish(r.Context(), events.BidFirstViewed{
+			BidID:     bid.ID,
+			ProjectID: bid.ProjectID,
+			ShareID:   share.ID,
+			ViewedAt:  now,
+		})
+	}
+}
+
+// recordEvent stores something a client did through a share link, with a
+// hash of their IP address. Events past the link's cap are dropped.
+func (h *BidShareHandlers) recordEvent(r *http.Request, share *models.BidShare, eventType models.BidShareEventType, at time.Time) error {
+	event := &models.BidShareEvent{
+		ID:        uuid.New(),
+		ShareID:   share.ID,
+		BidID:     share.BidID,
+		EventType: eventType,
+		IPHash:    services.HashViewerIP(share.ID, middleware.ClientIP(r)),
+		UserAgent: r.UserAgent(),
+		CreatedAt: models.NewTimestamp(at),
+	}
+	stored, err := h.eventRepo.Record(r.Context(), event, services.MaxBidShareEvents)
+	if err != nil {
+		return err
+	}
+	if !stored {
+		slog.Warn("Bid share event limit reached", "share_id", share.ID, "event_type", eventType)
+	}
+	return nil
+}
+
+// loadShare finds the share for the request's token, writing 404 when the
+// token is unknown and 410 when the link was revoked or has expired
+func (h *BidShareHandlers) loadShare(w http.ResponseWriter, r *http.Request) (*models.BidShare, bool) {
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		respondNotFound(w)
+		return nil, false
+	}
+	share, err := h.shareRepo.GetByTokenHash(r.Context(), services.HashBidShareToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrBidShareNotFound) {
+			respondNotFound(w)
+			return nil, false
+		}
+		slog.Error("Failed to get bid share", "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get shared bid")
+		return nil, false
+	}
+	if share.RevokedAt != nil {
+		respondError(w, http.StatusGone, "This link has been revoked")
+		return nil, false
+	}
+	if !time.Now().Before(share.ExpiresAt.Time) {
+		respondError(w, http.StatusGone, "This link has expired")
+		return nil, false
+	}
+	return share, true
+}
+
+// loadSharedBid loads a share's bid and its bid data
+func (h *BidShareHandlers) loadSharedBid(w http.ResponseWriter, r *http.Request, share *models.BidShare) (*models.Bid, *models.GenerateBidResponse, bool) {
+	bid, err := h.bidRepo.GetByID(r.Context(), share.BidID)
+	if err != nil {
+		respondNotFound(w)
+		return nil, nil, false
+	}
+	if bid.BidData == nil {
+		respondError(w, http.StatusInternalServerError, "Bid data not available")
+		return nil, nil, false
+	}
+	data, err := services.NewPDFService().ParseBidDataFromJSON(*bid.BidData)
+	if err != nil {
+		slog.Error("Failed to parse bid data", "bid_id", bid.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to parse bid data")
+		return nil, nil, false
+	}
+	return bid, data, true
+}
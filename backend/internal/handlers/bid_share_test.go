@@ -0,0 +1,503 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// shareTest serves a sent bid through the bid share routes, authenticated and
+// public, and records what the handlers published
+type shareTest struct {
+	bid         *models.Bid
+	revisions   *fakeBidRevisionStore
+	shares      *fakeBidShareStore
+	acceptances *fakeBidAcceptanceStore
+	shareEvents *fakeBidShareEventStore
+	tx          *fakeTransactor
+	events      *events.Recorder
+	router      chi.Router
+}
+
+// newShareTest shares a sent bid owned by userID
+func newShareTest(t *testing.T, userID uuid.UUID) *shareTest {
+	t.Helper()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	bidData, _ := json.Marshal(models.GenerateBidResponse{
+		ScopeOfWork: "Install doors and windows",
+		LineItems: []models.LineItem{
+			{Description: "Window installation", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 700, Total: 1400,
+				MaterialCost: 1000, LaborCost: 400, PriceSource: &models.PriceSource{Source: "default", RegionalFactor: 1}},
+		},
+		LaborCost:    400,
+		MaterialCost: 1000,
+		Subtotal:     1400,
+		MarkupAmount: 280,
+		TotalPrice:   1680,
+		PaymentTerms: "50% deposit",
+		Warnings:     []string{"Check the window sizes"},
+	})
+	bidDataStr := string(bidData)
+	markup := 20.0
+	pdfURL := "https://example.com/bid.pdf"
+	bid := &models.Bid{
+		ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusSent, Version: 1,
+		MarkupPercentage: &markup, BidData: &bidDataStr, PDFURL: &pdfURL,
+	}
+	revisions := &fakeBidRevisionStore{revisions: []*models.BidRevision{newBidRevision(bid, 1, userID.String())}}
+	shares := &fakeBidShareStore{}
+	acceptances := &fakeBidAcceptanceStore{}
+	shareEvents := &fakeBidShareEventStore{}
+	tx := &fakeTransactor{}
+	recorder := &events.Recorder{}
+
+	cfg := &config.Config{BidShares: config.BidShareConfig{Expiry: time.Hour, BaseURL: "https://app.example.com/shared/bids/"}}
+	h := NewBidShareHandlers(
+		&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		&fakeBidStore{bids: []*models.Bid{bid}},
+		revisions,
+		shares,
+		acceptances,
+		shareEvents,
+		tx,
+		recorder,
+		cfg,
+	)
+	router := chi.NewRouter()
+	h.PublicRoutes(router)
+	h.Routes(router)
+	return &shareTest{bid: bid, revisions: revisions, shares: shares, acceptances: acceptances, shareEvents: shareEvents, tx: tx, events: recorder, router: router}
+}
+
+// shareTestBid shares bid as userID and returns the share token
+func shareTestBid(t *testing.T, router chi.Router, userID uuid.UUID, bid *models.Bid) string {
+	t.Helper()
+	rec := serveAsUser(router, userID, http.MethodPost, "/bids/"+bid.ID.String()+"/share", "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("share: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var shared ShareBidResponse
+	if err := json.NewDecoder(rec.Body).Decode(&shared); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if shared.URL != "https://app.example.com/shared/bids/"+shared.Token {
+		t.Errorf("url = %q, want the base URL and token", shared.URL)
+	}
+	return shared.Token
+}
+
+func servePublic(router chi.Router, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestShareBid(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	bid, shares, router, recorder := st.bid, st.shares, st.router, st.events
+
+	// Sharing a draft sends it
+	bid.Status = models.BidStatusDraft
+	token := shareTestBid(t, router, userID, bid)
+	if bid.Status != models.BidStatusSent || bid.StatusChangedAt == nil {
+		t.Errorf("shared draft = status %s, changed at %v; want sent", bid.Status, bid.StatusChangedAt)
+	}
+	if changes := events.Recorded[events.BidStatusChanged](recorder); len(changes) != 1 || changes[0].To != models.BidStatusSent || changes[0].UserID != userID.String() {
+		t.Errorf("published status changes = %+v, want draft -> sent by the user", changes)
+	}
+	share := shares.shares[services.HashBidShareToken(token)]
+	if share == nil || share.CreatedBy == nil || *share.CreatedBy != userID {
+		t.Fatalf("stored share = %+v, want it created by the user and stored by hash", share)
+	}
+	if until := time.Until(share.ExpiresAt.Time); until <= 0 || until > time.Hour {
+		t.Errorf("share expires in %v, want the configured hour", until)
+	}
+
+	if rec := serveAsUser(router, uuid.New(), http.MethodPost, "/bids/"+bid.ID.String()+"/share", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's share: status = %d, want 404", rec.Code)
+	}
+
+	bid.Status = models.BidStatusAccepted
+	if rec := serveAsUser(router, userID, http.MethodPost, "/bids/"+bid.ID.String()+"/share", ""); rec.Code != http.StatusConflict {
+		t.Errorf("accepted bid share: status = %d, want 409", rec.Code)
+	}
+}
+
+func TestGetSharedBid(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	bid, shares, router := st.bid, st.shares, st.router
+	token := shareTestBid(t, router, userID, bid)
+
+	rec := servePublic(router, http.MethodGet, "/public/bids/"+token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("view: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	var view models.PublicBid
+	if err := json.Unmarshal([]byte(body), &view); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if view.TotalPrice != 1680 || len(view.LineItems) != 1 || view.LineItems[0].Total != 1400 || view.PaymentTerms != "50% deposit" || view.PDFURL == nil {
+		t.Errorf("view = %+v, want the bid's scope, line items, totals, terms and PDF", view)
+	}
+	for _, internal := range []string{"labor_cost", "material_cost", "markup_percentage", "price_source", "project_id", "user_id", "warnings", bid.ID.String(), userID.String()} {
+		if strings.Contains(body, internal) {
+			t.Errorf("view includes %q: %s", internal, body)
+		}
+	}
+
+	if rec := servePublic(router, http.MethodGet, "/public/bids/unknown", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown token: status = %d, want 404", rec.Code)
+	}
+
+	shares.shares[services.HashBidShareToken(token)].ExpiresAt = models.NewTimestamp(time.Now().Add(-time.Minute))
+	if rec := servePublic(router, http.MethodGet, "/public/bids/"+token, ""); rec.Code != http.StatusGone {
+		t.Errorf("expired token: status = %d, want 410", rec.Code)
+	}
+}
+
+func TestRespondToSharedBid(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	bid, revisions, shares, router, recorder := st.bid, st.revisions, st.shares, st.router, st.events
+	token := shareTestBid(t, router, userID, bid)
+	path := "/public/bids/" + token + "/respond"
+
+	invalid := []string{
+		`{"decision":"sent","name":"Pat Owner"}`,
+		`{"decision":"accepted","name":"  ","email":"pat@example.com"}`,
+		`{"decision":"accepted","name":"Pat Owner"}`,
+		`{"decision":"accepted","name":"Pat Owner","email":"pat@example.com","comment":"` + strings.Repeat("x", 1001) + `"}`,
+	}
+	for _, body := range invalid {
+		if rec := servePublic(router, http.MethodPost, path, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %.40s: status = %d, want 400", body, rec.Code)
+		}
+	}
+
+	rec := servePublic(router, http.MethodPost, path, `{"decision":"accepted","name":" Pat Owner ","email":"pat@example.com","comment":" Start in June "}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("accept: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if bid.Status != models.BidStatusAccepted || bid.StatusNote == nil || *bid.StatusNote != "Start in June" || bid.StatusChangedAt == nil || bid.Version != 2 {
+		t.Errorf("accepted bid = status %s, note %v, version %d", bid.Status, bid.StatusNote, bid.Version)
+	}
+	accepted := revisions.revisions[len(revisions.revisions)-1]
+	if accepted.Status != models.BidStatusAccepted || accepted.Reason == nil || *accepted.Reason != models.BidRevisionReasonAccepted || accepted.CreatedBy != nil {
+		t.Errorf("accepted revision = %+v, want an accepted revision created by no user", accepted)
+	}
+	share := shares.shares[services.HashBidShareToken(token)]
+	if share.RespondedAt == nil || share.ResponderName == nil || *share.ResponderName != "Pat Owner" {
+		t.Errorf("share = %+v, want who responded and when", share)
+	}
+	changes := events.Recorded[events.BidStatusChanged](recorder)
+	if len(changes) != 1 || changes[0].From != models.BidStatusSent || changes[0].To != models.BidStatusAccepted || changes[0].Version != 2 || changes[0].UserID != "" {
+		t.Errorf("published status changes = %+v, want sent -> accepted by the client", changes)
+	}
+	if len(st.acceptances.acceptances) != 1 || st.acceptances.acceptances[0].SignerEmail != "pat@example.com" {
+		t.Errorf("acceptances = %+v, want the acceptance recorded", st.acceptances.acceptances)
+	}
+	if st.tx.commits != 1 {
+		t.Errorf("committed %d transactions, want the response written in one", st.tx.commits)
+	}
+
+	// A link takes one response
+	rec = servePublic(router, http.MethodPost, path, `{"decision":"rejected","name":"Pat Owner"}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("second response: status = %d, want 409", rec.Code)
+	}
+	if bid.Status != models.BidStatusAccepted {
+		t.Errorf("second response moved the bid to %s", bid.Status)
+	}
+
+	// The view still shows the response
+	var view models.PublicBid
+	rec = servePublic(router, http.MethodGet, "/public/bids/"+token, "")
+	if err := json.NewDecoder(rec.Body).Decode(&view); err != nil || view.Response == nil || *view.Response != models.BidStatusAccepted {
+		t.Errorf("view after responding = %+v, %v; want the acceptance", view, err)
+	}
+}
+
+func TestRespondToSharedBid_Expired(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	bid, shares, router := st.bid, st.shares, st.router
+	token := shareTestBid(t, router, userID, bid)
+	shares.shares[services.HashBidShareToken(token)].ExpiresAt = models.NewTimestamp(time.Now().Add(-time.Minute))
+
+	rec := servePublic(router, http.MethodPost, "/public/bids/"+token+"/respond", `{"decision":"rejected","name":"Pat Owner"}`)
+	if rec.Code != http.StatusGone {
+		t.Errorf("expired token: status = %d, want 410", rec.Code)
+	}
+	if bid.Status != models.BidStatusSent {
+		t.Errorf("expired response moved the bid to %s", bid.Status)
+	}
+}
+
+func TestRespondToSharedBid_BidNoLongerSent(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	bid, router := st.bid, st.router
+	token := shareTestBid(t, router, userID, bid)
+
+	// The contractor recorded the rejection before the client answered
+	bid.Status = models.BidStatusRejected
+	rec := servePublic(router, http.MethodPost, "/public/bids/"+token+"/respond", `{"decision":"accepted","name":"Pat Owner","email":"pat@example.com"}`)
+	if rec.Code != http.StatusConflict || bid.Status != models.BidStatusRejected {
+		t.Errorf("response to a rejected bid: status = %d, bid %s; want 409 and the bid unchanged", rec.Code, bid.Status)
+	}
+}
+
+func TestRespondToSharedBid_LostRace(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	token := shareTestBid(t, st.router, userID, st.bid)
+
+	// Another response consumed the link after this one was checked
+	st.shares.respondErr = repository.ErrBidShareNotFound
+	rec := servePublic(st.router, http.MethodPost, "/public/bids/"+token+"/respond", `{"decision":"accepted","name":"Pat Owner","email":"pat@example.com"}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("lost race: status = %d, want 409", rec.Code)
+	}
+	if st.tx.rollbacks != 1 || st.tx.commits != 0 {
+		t.Errorf("transactions = %d committed, %d rolled back; want the bid update rolled back", st.tx.commits, st.tx.rollbacks)
+	}
+	if got := events.Recorded[events.BidStatusChanged](st.events); len(got) != 0 {
+		t.Errorf("lost race published %d status changes", len(got))
+	}
+}
+
+func TestAcceptSharedBid(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	bid, router, recorder := st.bid, st.router, st.events
+	token := shareTestBid(t, router, userID, bid)
+	path := "/public/bids/" + token + "/accept"
+
+	for _, body := range []string{
+		`{"name":"Pat Owner"}`,
+		`{"name":"Pat Owner","email":"pat.example.com"}`,
+		`{"name":" ","email":"pat@example.com"}`,
+	} {
+		if rec := servePublic(router, http.MethodPost, path, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s: status = %d, want 400", body, rec.Code)
+		}
+	}
+
+	accept := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":" Pat Owner ","email":" pat@example.com "}`))
+		req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Test)")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	rec := accept()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("accept: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	// The bid moves to accepted with a revision, and its PDF is rendered
+	// again with the stamp on the next download
+	if bid.Status != models.BidStatusAccepted || bid.Version != 2 || bid.StatusChangedAt == nil || bid.PDFURL != nil {
+		t.Errorf("accepted bid = status %s, version %d, PDF %v; want accepted at version 2 with the PDF cleared", bid.Status, bid.Version, bid.PDFURL)
+	}
+	if revision := st.revisions.revisions[len(st.revisions.revisions)-1]; revision.Reason == nil || *revision.Reason != models.BidRevisionReasonAccepted {
+		t.Errorf("latest revision = %+v, want the accepted revision", revision)
+	}
+	share := st.shares.shares[services.HashBidShareToken(token)]
+	if len(st.acceptances.acceptances) != 1 {
+		t.Fatalf("recorded %d acceptances, want 1", len(st.acceptances.acceptances))
+	}
+	acceptance := st.acceptances.acceptances[0]
+	if acceptance.BidID != bid.ID || acceptance.ShareID != share.ID || acceptance.SignerName != "Pat Owner" || acceptance.SignerEmail != "pat@example.com" ||
+		acceptance.IPAddress != "203.0.113.7" || acceptance.UserAgent != "Mozilla/5.0 (Test)" || !acceptance.AcceptedAt.Time.Equal(bid.StatusChangedAt.Time) {
+		t.Errorf("acceptance = %+v, want the signer, their address, browser and the time of acceptance", acceptance)
+	}
+	if st.tx.commits != 1 {
+		t.Errorf("committed %d transactions, want the acceptance written in one", st.tx.commits)
+	}
+
+	// The owner is notified
+	if changes := events.Recorded[events.BidStatusChanged](recorder); len(changes) != 1 || changes[0].To != models.BidStatusAccepted {
+		t.Errorf("published status changes = %+v, want sent -> accepted", changes)
+	}
+	accepted := events.Recorded[events.BidAccepted](recorder)
+	if len(accepted) != 1 || accepted[0].ProjectID != bid.ProjectID || accepted[0].Acceptance.ID != acceptance.ID {
+		t.Errorf("published acceptances = %+v, want the acceptance", accepted)
+	}
+
+	// Accepting again answers with the first acceptance and records nothing
+	rec = accept()
+	var view models.PublicBid
+	if err := json.NewDecoder(rec.Body).Decode(&view); err != nil || rec.Code != http.StatusOK || view.Response == nil || *view.Response != models.BidStatusAccepted {
+		t.Errorf("second accept = %d %+v, %v; want 200 and the acceptance", rec.Code, view, err)
+	}
+	if len(st.acceptances.acceptances) != 1 || bid.Version != 2 || st.tx.commits != 1 || len(events.Recorded[events.BidAccepted](recorder)) != 1 {
+		t.Errorf("second accept recorded %d acceptances, version %d, %d commits; want nothing new", len(st.acceptances.acceptances), bid.Version, st.tx.commits)
+	}
+
+	// Rejecting after accepting is refused
+	if rec := servePublic(router, http.MethodPost, "/public/bids/"+token+"/respond", `{"decision":"rejected","name":"Pat Owner"}`); rec.Code != http.StatusConflict {
+		t.Errorf("reject after accepting: status = %d, want 409", rec.Code)
+	}
+}
+
+func TestAcceptSharedBid_RevokedOrExpired(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	bid, shares, router := st.bid, st.shares, st.router
+	const body = `{"name":"Pat Owner","email":"pat@example.com"}`
+
+	expired := shareTestBid(t, router, userID, bid)
+	shares.shares[services.HashBidShareToken(expired)].ExpiresAt = models.NewTimestamp(time.Now().Add(-time.Minute))
+	if rec := servePublic(router, http.MethodPost, "/public/bids/"+expired+"/accept", body); rec.Code != http.StatusGone {
+		t.Errorf("expired link: status = %d, want 410", rec.Code)
+	}
+
+	revoked := shareTestBid(t, router, userID, bid)
+	share := shares.shares[services.HashBidShareToken(revoked)]
+	revokePath := "/bids/" + bid.ID.String() + "/shares/" + share.ID.String()
+	if rec := serveAsUser(router, uuid.New(), http.MethodDelete, revokePath, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's revoke: status = %d, want 404", rec.Code)
+	}
+	if rec := serveAsUser(router, userID, http.MethodDelete, "/bids/"+bid.ID.String()+"/shares/"+uuid.NewString(), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown share revoke: status = %d, want 404", rec.Code)
+	}
+	if rec := serveAsUser(router, userID, http.MethodDelete, revokePath, ""); rec.Code != http.StatusOK || share.RevokedAt == nil {
+		t.Fatalf("revoke: status = %d, revoked at %v; want 200 and the share revoked", rec.Code, share.RevokedAt)
+	}
+	if rec := servePublic(router, http.MethodGet, "/public/bids/"+revoked, ""); rec.Code != http.StatusGone {
+		t.Errorf("view through a revoked link: status = %d, want 410", rec.Code)
+	}
+	if rec := servePublic(router, http.MethodPost, "/public/bids/"+revoked+"/accept", body); rec.Code != http.StatusGone {
+		t.Errorf("accept through a revoked link: status = %d, want 410", rec.Code)
+	}
+
+	if bid.Status != models.BidStatusSent || len(st.acceptances.acceptances) != 0 {
+		t.Errorf("bid = %s with %d acceptances, want it still sent with none", bid.Status, len(st.acceptances.acceptances))
+	}
+}
+
+// servePublicFrom sends a public request from a client's address
+func servePublicFrom(router chi.Router, ip, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("X-Forwarded-For", ip)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Test)")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetSharedBid_RecordsViews(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	token := shareTestBid(t, st.router, userID, st.bid)
+	share := st.shares.shares[services.HashBidShareToken(token)]
+
+	for _, ip := range []string{"203.0.113.7", "203.0.113.7", "198.51.100.2"} {
+		if rec := servePublicFrom(st.router, ip, http.MethodGet, "/public/bids/"+token, ""); rec.Code != http.StatusOK {
+			t.Fatalf("view: status = %d", rec.Code)
+		}
+	}
+
+	if len(st.shareEvents.events) != 3 {
+		t.Fatalf("recorded %d events, want one per view", len(st.shareEvents.events))
+	}
+	view := st.shareEvents.events[0]
+	if view.EventType != models.BidShareEventViewed || view.ShareID != share.ID || view.BidID != st.bid.ID ||
+		view.IPHash != services.HashViewerIP(share.ID, "203.0.113.7") || view.UserAgent != "Mozilla/5.0 (Test)" {
+		t.Errorf("view event = %+v, want a view with the hashed address and user agent", view)
+	}
+
+	// The owner is notified of the first view only
+	viewed := events.Recorded[events.BidFirstViewed](st.events)
+	if len(viewed) != 1 || viewed[0].ShareID != share.ID || viewed[0].ProjectID != st.bid.ProjectID || share.FirstViewedAt == nil {
+		t.Errorf("published first views = %+v, want one for the share", viewed)
+	}
+
+	// Views past the link's cap are dropped but still served
+	for len(st.shareEvents.events) < services.MaxBidShareEvents {
+		st.shareEvents.events = append(st.shareEvents.events, view)
+	}
+	if rec := servePublic(st.router, http.MethodGet, "/public/bids/"+token, ""); rec.Code != http.StatusOK || len(st.shareEvents.events) != services.MaxBidShareEvents {
+		t.Errorf("view past the cap: status = %d with %d events, want 200 and nothing stored", rec.Code, len(st.shareEvents.events))
+	}
+}
+
+func TestRecordSharedBidEvent(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	token := shareTestBid(t, st.router, userID, st.bid)
+	path := "/public/bids/" + token + "/events"
+
+	for _, body := range []string{`not json`, `{"type":"viewed"}`, `{"type":"clicked"}`, `{}`} {
+		if rec := servePublic(st.router, http.MethodPost, path, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s: status = %d, want 400", body, rec.Code)
+		}
+	}
+	if rec := servePublic(st.router, http.MethodPost, "/public/bids/unknown/events", `{"type":"downloaded_pdf"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown token: status = %d, want 404", rec.Code)
+	}
+
+	for _, eventType := range []string{"viewed_summary", "viewed_line_items", "downloaded_pdf"} {
+		if rec := servePublicFrom(st.router, "203.0.113.7", http.MethodPost, path, `{"type":"`+eventType+`"}`); rec.Code != http.StatusNoContent {
+			t.Errorf("POST %s: status = %d, want 204", eventType, rec.Code)
+		}
+	}
+	if len(st.shareEvents.events) != 3 || st.shareEvents.events[2].EventType != models.BidShareEventDownloadedPDF {
+		t.Errorf("events = %+v, want the three reported events", st.shareEvents.events)
+	}
+	if got := events.Recorded[events.BidFirstViewed](st.events); len(got) != 0 {
+		t.Errorf("reported events published %d first views, want none", len(got))
+	}
+
+	st.shares.shares[services.HashBidShareToken(token)].ExpiresAt = models.NewTimestamp(time.Now().Add(-time.Minute))
+	if rec := servePublic(st.router, http.MethodPost, path, `{"type":"downloaded_pdf"}`); rec.Code != http.StatusGone {
+		t.Errorf("expired link: status = %d, want 410", rec.Code)
+	}
+}
+
+func TestGetBidEngagement(t *testing.T) {
+	userID := uuid.New()
+	st := newShareTest(t, userID)
+	token := shareTestBid(t, st.router, userID, st.bid)
+	shareTestBid(t, st.router, userID, st.bid)
+
+	servePublicFrom(st.router, "203.0.113.7", http.MethodGet, "/public/bids/"+token, "")
+	servePublicFrom(st.router, "203.0.113.7", http.MethodGet, "/public/bids/"+token, "")
+	servePublicFrom(st.router, "198.51.100.2", http.MethodGet, "/public/bids/"+token, "")
+	servePublicFrom(st.router, "203.0.113.7", http.MethodPost, "/public/bids/"+token+"/events", `{"type":"downloaded_pdf"}`)
+
+	path := "/bids/" + st.bid.ID.String() + "/engagement"
+	if rec := serveAsUser(st.router, uuid.New(), http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's engagement: status = %d, want 404", rec.Code)
+	}
+	rec := serveAsUser(st.router, userID, http.MethodGet, path, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("engagement: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var engagement models.BidEngagement
+	if err := json.NewDecoder(rec.Body).Decode(&engagement); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if engagement.TotalViews != 3 || engagement.UniqueViewers != 2 || engagement.PDFDownloads != 1 || engagement.FirstViewedAt == nil || engagement.LastViewedAt == nil {
+		t.Errorf("engagement = %+v, want 3 views by 2 viewers and 1 download", engagement)
+	}
+	if len(engagement.Shares) != 2 || engagement.Shares[0].TotalViews != 3 || engagement.Shares[1].TotalViews != 0 {
+		t.Errorf("share engagement = %+v, want the viewed share and the unviewed one", engagement.Shares)
+	}
+}
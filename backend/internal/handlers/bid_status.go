@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// UpdateBidStatusRequest moves a bid to Status, optionally noting why
+type UpdateBidStatusRequest struct {
+	Status     string  `json:"status"`
+	StatusNote *string `json:"status_note"`
+}
+
+// UpdateBidStatus moves a bid through draft, sent and accepted or rejected.
+// Any other move is refused with 422 and the transitions allowed from the
+// bid's status. Accepting a bid saves it as an "accepted" revision, so the
+// accepted version stays on record after later edits.
+func (h *BidHandlers) UpdateBidStatus(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req UpdateBidStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	status, err := services.ParseBidStatus(req.Status)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	note, err := services.ValidateBidStatusNote(req.StatusNote)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bid, _, ok := h.loadOwnedBid(w, r, bidID)
+	if !ok {
+		return
+	}
+
+	from := bid.Status
+	if err := services.CheckBidStatusTransition(from, status); err != nil {
+		allowed := services.AllowedBidStatusTransitions(from)
+		if allowed == nil {
+			allowed = []models.BidStatus{}
+		}
+		respondAPIErrorDetails(w, http.StatusUnprocessableEntity, CodeInvalidStatusTransition, err.Error(), map[string]interface{}{
+			"status":              from,
+			"allowed_transitions": allowed,
+		})
+		return
+	}
+
+	before := newBidRevision(bid, bid.Version, "")
+	now := models.Now()
+	bid.Status = status
+	bid.StatusNote = note
+	bid.StatusChangedAt = &now
+
+	if status == models.BidStatusAccepted {
+		revision, err := createBidRevision(r.Context(), h.bidRevisionRepo, bid, getUserID(r.Context()), before, models.BidRevisionReasonAccepted)
+		if err != nil {
+			slog.Error("Failed to create bid revision", "bid_id", bidID, "error", err)
+			respondError(w, http.StatusInternalServerError, "Failed to update bid status")
+			return
+		}
+		bid.Version = revision.Version
+	}
+
+	bid.UpdatedAt = now
+	if err := h.bidRepo.Update(r.Context(), bid); err != nil {
+		slog.Error("Failed to update bid status", "bid_id", bidID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update bid status")
+		return
+	}
+
+	h.events.Publish(r.Context(), events.BidStatusChanged{
+		BidID:         bid.ID,
+		ProjectID:     bid.ProjectID,
+		From:          from,
+		To:            status,
+		Version:       bid.Version,
+		UserID:        getUserID(r.Context()),
+		CorrelationID: getCorrelationID(r.Context()),
+	})
+
+	respondJSON(w, http.StatusOK, bid)
+}
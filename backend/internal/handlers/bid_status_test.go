@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestUpdateBidStatus(t *testing.T) {
+	userID := uuid.New()
+	h, bid, revisions, _ := newDraftTestHandlers(t, userID)
+	recorder := &events.Recorder{}
+	h.events = recorder
+	router := chi.NewRouter()
+	h.Routes(router)
+	path := "/bids/" + bid.ID.String() + "/status"
+
+	// Skipping sent is refused with the allowed transitions
+	rec := serveAsUser(router, userID, http.MethodPatch, path, `{"status":"accepted"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("draft -> accepted: status = %d, want 422", rec.Code)
+	}
+	var refused struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details struct {
+			Status  models.BidStatus   `json:"status"`
+			Allowed []models.BidStatus `json:"allowed_transitions"`
+		} `json:"details"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&refused); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if refused.Code != CodeInvalidStatusTransition || refused.Details.Status != models.BidStatusDraft ||
+		len(refused.Details.Allowed) != 1 || refused.Details.Allowed[0] != models.BidStatusSent || !strings.Contains(refused.Message, "allowed: sent") {
+		t.Errorf("refusal = %+v, want %s with only sent allowed", refused, CodeInvalidStatusTransition)
+	}
+
+	rec = serveAsUser(router, userID, http.MethodPatch, path, `{"status":"sent","status_note":" Emailed to the owner "}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("draft -> sent: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if bid.Status != models.BidStatusSent || bid.StatusChangedAt == nil || bid.StatusNote == nil || *bid.StatusNote != "Emailed to the owner" {
+		t.Errorf("sent bid = status %s, changed at %v, note %v", bid.Status, bid.StatusChangedAt, bid.StatusNote)
+	}
+	if bid.Version != 1 || len(revisions.revisions) != 1 {
+		t.Errorf("sending created a revision: version %d, %d revisions", bid.Version, len(revisions.revisions))
+	}
+
+	rec = serveAsUser(router, userID, http.MethodPatch, path, `{"status":"accepted"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("sent -> accepted: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if bid.Status != models.BidStatusAccepted || bid.StatusNote != nil || bid.Version != 2 {
+		t.Errorf("accepted bid = status %s, note %v, version %d", bid.Status, bid.StatusNote, bid.Version)
+	}
+	accepted := revisions.revisions[len(revisions.revisions)-1]
+	if accepted.Version != 2 || accepted.Status != models.BidStatusAccepted || accepted.Reason == nil || *accepted.Reason != models.BidRevisionReasonAccepted {
+		t.Errorf("accepted revision = %+v, want version 2 frozen as accepted", accepted)
+	}
+
+	changes := events.Recorded[events.BidStatusChanged](recorder)
+	if len(changes) != 2 || changes[0].From != models.BidStatusDraft || changes[0].To != models.BidStatusSent ||
+		changes[1].To != models.BidStatusAccepted || changes[1].Version != 2 || changes[1].UserID != userID.String() {
+		t.Errorf("published status changes = %+v, want draft -> sent -> accepted by the user", changes)
+	}
+
+	// Accepted is final
+	rec = serveAsUser(router, userID, http.MethodPatch, path, `{"status":"rejected"}`)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), `"allowed_transitions":[]`) {
+		t.Errorf("accepted -> rejected: status = %d, body %s; want 422 with no transitions", rec.Code, rec.Body.String())
+	}
+	if got := len(events.Recorded[events.BidStatusChanged](recorder)); got != 2 {
+		t.Errorf("refused transition published a status change: %d events", got)
+	}
+
+	failures := []struct {
+		user uuid.UUID
+		body string
+		want int
+	}{
+		{userID, `{"status":"won"}`, http.StatusBadRequest},
+		{userID, `{"status":"sent","status_note":"` + strings.Repeat("x", 1001) + `"}`, http.StatusBadRequest},
+		{uuid.New(), `{"status":"rejected"}`, http.StatusNotFound},
+	}
+	for _, tt := range failures {
+		if rec := serveAsUser(router, tt.user, http.MethodPatch, path, tt.body); rec.Code != tt.want {
+			t.Errorf("PATCH %.40s: status = %d, want %d", tt.body, rec.Code, tt.want)
+		}
+	}
+}
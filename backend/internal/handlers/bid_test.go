@@ -0,0 +1,806 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestBidNames(t *testing.T) {
+	renamed := uuid.New()
+	first, second := "Base Bid", "Alternate"
+	bids := []*models.Bid{
+		{ID: uuid.New(), Name: &first},
+		{ID: renamed, Name: &second},
+		{ID: uuid.New()},
+	}
+
+	if got := bidNames(bids, renamed); !reflect.DeepEqual(got, []string{"Base Bid"}) {
+		t.Errorf("bidNames() = %v, want the other named bids", got)
+	}
+}
+
+func TestRenameBidRevision(t *testing.T) {
+	oldName := "Bid-20240117-153042"
+	finalPrice := 12000.0
+	userID := uuid.New()
+	bid := &models.Bid{ID: uuid.New(), Name: &oldName, FinalPrice: &finalPrice, Status: models.BidStatusDraft, Version: 2}
+
+	// RenameBid snapshots the bid before changing its name
+	before := newBidRevision(bid, bid.Version, "")
+	newName := "Office Remodel – Bid #1 (National) – January 17, 2024"
+	bid.Name = &newName
+	after := newBidRevision(bid, bid.Version+1, userID.String())
+
+	if after.BidID != bid.ID || after.Version != 3 || *after.Name != newName {
+		t.Errorf("unexpected revision snapshot: %+v", after)
+	}
+	if after.CreatedBy == nil || *after.CreatedBy != userID {
+		t.Errorf("expected revision to record the renaming user, got %v", after.CreatedBy)
+	}
+	if before.CreatedBy != nil {
+		t.Error("expected no user on a snapshot without a user ID")
+	}
+
+	comparison, err := services.NewComparisonService().CompareBidRevisions(before, after)
+	if err != nil {
+		t.Fatalf("CompareBidRevisions() error = %v", err)
+	}
+	digest := comparison.Digest()
+	if digest.FromVersion != 2 || digest.ToVersion != 3 || digest.Summary.ChangesByCategory["name"] != 1 || digest.Summary.TotalChanges != 1 {
+		t.Errorf("expected the revision digest to record only the rename, got %+v", digest)
+	}
+}
+
+// newTestBidHandlers serves BidHandlers' routes over in-memory stores
+func newTestBidHandlers(projects *fakeProjectStore, blueprints *fakeBlueprintStore, bids *fakeBidStore) chi.Router {
+	// Every project's company exists, without tax settings
+	users := &fakeUserStore{users: make(map[uuid.UUID]*models.User)}
+	for _, project := range projects.projects {
+		users.users[project.UserID] = &models.User{ID: project.UserID}
+	}
+	h := &BidHandlers{
+		PricingSources: NewPricingSources(nil, nil, nil, nil, nil, nil),
+		projectRepo:    projects,
+		blueprintRepo:  blueprints,
+		bidRepo:        bids,
+		userRepo:       users,
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+	return router
+}
+
+func TestGetBid(t *testing.T) {
+	name := "Base Bid"
+	project := &models.Project{ID: uuid.New(), UserID: uuid.New()}
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, Name: &name, Status: models.BidStatusDraft}
+	router := newTestBidHandlers(&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}}, &fakeBlueprintStore{}, &fakeBidStore{bids: []*models.Bid{bid}})
+
+	rec := serveAsUser(router, project.UserID, http.MethodGet, "/bids/"+bid.ID.String(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var got models.Bid
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode bid: %v", err)
+	}
+	if got.ID != bid.ID || got.Name == nil || *got.Name != name {
+		t.Errorf("GetBid returned %+v", got)
+	}
+
+	rec = serveAsUser(router, project.UserID, http.MethodGet, "/bids/"+uuid.New().String(), "")
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), CodeResourceNotFound) {
+		t.Errorf("unknown bid: status = %d, body %s; want 404", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetProjectBids(t *testing.T) {
+	budget := 10000.0
+	project := &models.Project{ID: uuid.New(), UserID: uuid.New(), Budget: &budget}
+	over, under, cheapest := 12500.0, 8000.0, 5000.0
+	buildingA, buildingB := uuid.New(), uuid.New()
+	bids := &fakeBidStore{bids: []*models.Bid{
+		{ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusDraft, FinalPrice: &over, BlueprintIDs: []uuid.UUID{buildingA, buildingB}},
+		{ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusSent, FinalPrice: &under, BlueprintIDs: []uuid.UUID{buildingB}},
+		{ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusDraft, FinalPrice: &cheapest},
+		{ID: uuid.New(), ProjectID: uuid.New(), FinalPrice: &under},
+	}}
+	router := newTestBidHandlers(&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}}, &fakeBlueprintStore{}, bids)
+	base := "/projects/" + project.ID.String() + "/bids"
+	list := func(t *testing.T, query string) models.ListPage[models.Bid] {
+		t.Helper()
+		rec := serveAsUser(router, project.UserID, http.MethodGet, base+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, want 200", query, rec.Code)
+		}
+		var page models.ListPage[models.Bid]
+		if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+			t.Fatalf("failed to decode bids: %v", err)
+		}
+		return page
+	}
+
+	page := list(t, "")
+	if len(page.Items) != 3 || page.Total != 3 || page.Limit != defaultListLimit || page.Offset != 0 {
+		t.Fatalf("got %d of %d bids (limit %d, offset %d), want the project's 3 bids on one default page",
+			len(page.Items), page.Total, page.Limit, page.Offset)
+	}
+	got := page.Items
+	if got[0].BudgetStatus == nil || got[0].BudgetStatus.Status != models.BudgetStateOver {
+		t.Errorf("expected the first bid flagged over budget, got %+v", got[0].BudgetStatus)
+	}
+	if got[1].BudgetStatus == nil || got[1].BudgetStatus.Status == models.BudgetStateOver {
+		t.Errorf("expected the second bid within budget, got %+v", got[1].BudgetStatus)
+	}
+
+	// Filtering by blueprint keeps the combined bid that includes it
+	page = list(t, "?blueprint_id="+buildingA.String())
+	if len(page.Items) != 1 || page.Items[0].ID != bids.bids[0].ID {
+		t.Errorf("blueprint_id filter returned %d bids, want only the combined bid", len(page.Items))
+	}
+
+	page = list(t, "?status=draft&sort=final_price_asc")
+	if len(page.Items) != 2 || page.Items[0].ID != bids.bids[2].ID || page.Items[1].ID != bids.bids[0].ID {
+		t.Errorf("draft bids by price = %+v, want the cheapest draft first", page.Items)
+	}
+
+	page = list(t, "?sort=final_price_desc&limit=1&offset=1")
+	if len(page.Items) != 1 || page.Items[0].ID != bids.bids[1].ID || page.Total != 3 || page.Limit != 1 || page.Offset != 1 {
+		t.Errorf("second page of one = %+v, want the second most expensive bid of 3", page)
+	}
+
+	if page = list(t, "?limit=1000"); page.Limit != maxListLimit {
+		t.Errorf("limit = %d, want it capped at %d", page.Limit, maxListLimit)
+	}
+
+	for _, query := range []string{"?blueprint_id=nope", "?status=pending", "?sort=name", "?limit=0", "?offset=-1"} {
+		if rec := serveAsUser(router, project.UserID, http.MethodGet, base+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", query, rec.Code)
+		}
+	}
+
+	bids.err = errors.New("db down")
+	rec := serveAsUser(router, project.UserID, http.MethodGet, base, "")
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("store failure: status = %d, want 500", rec.Code)
+	}
+}
+
+func TestGetPricingSummary(t *testing.T) {
+	budget := 1000.0
+	project := &models.Project{ID: uuid.New(), UserID: uuid.New(), Budget: &budget}
+	analysis := `{"rooms":[{"name":"Office","dimensions":"10x20","area":200}],"openings":[{"opening_type":"door","count":2}],"confidence_score":0.9}`
+	analyzed := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, AnalysisData: &analysis}
+	pending := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID}
+	other := &models.Blueprint{ID: uuid.New(), ProjectID: uuid.New(), AnalysisData: &analysis}
+
+	router := newTestBidHandlers(
+		&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		&fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{analyzed.ID: analyzed, pending.ID: pending, other.ID: other}},
+		&fakeBidStore{},
+	)
+	get := func(query string) *httptest.ResponseRecorder {
+		return serveAsUser(router, project.UserID, http.MethodGet, "/projects/"+project.ID.String()+"/pricing-summary"+query, "")
+	}
+
+	rec := get("?blueprint_id=" + analyzed.ID.String())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var summary models.PricingSummary
+	if err := json.NewDecoder(rec.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode summary: %v", err)
+	}
+	if summary.TotalPrice <= 0 || len(summary.LineItems) == 0 {
+		t.Errorf("expected priced line items, got %+v", summary)
+	}
+	if summary.CostsByTrade["carpentry"] == 0 {
+		t.Errorf("expected the doors priced under carpentry, got %v", summary.CostsByTrade)
+	}
+	if summary.BudgetStatus == nil || summary.BudgetStatus.Status != models.BudgetStateOver {
+		t.Errorf("expected the summary flagged against the budget, got %+v", summary.BudgetStatus)
+	}
+	if summary.ConfidenceRange == nil || summary.ConfidenceRange.Likely != summary.TotalPrice {
+		t.Errorf("expected a confidence range around the total, got %+v", summary.ConfidenceRange)
+	}
+	if metrics := summary.UnitMetrics; metrics == nil || metrics.AreaSF != 200 ||
+		math.Abs(metrics.CostPerSF-summary.TotalPrice/200) > 0.01 || metrics.ExpectedRange == nil {
+		t.Errorf("expected costs per SF of the 200 SF takeoff, got %+v", metrics)
+	}
+
+	rec = get("?units=metric&blueprint_id=" + analyzed.ID.String())
+	var metric models.PricingSummary
+	if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&metric) != nil {
+		t.Fatalf("metric: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if metric.UnitSystem != models.UnitSystemMetric || metric.TotalPrice != summary.TotalPrice {
+		t.Errorf("metric summary = %s priced %.2f, want metric at the same %.2f", metric.UnitSystem, metric.TotalPrice, summary.TotalPrice)
+	}
+	for _, item := range metric.LineItems {
+		if item.Unit == "sq ft" || item.Unit == "SF" || item.Unit == "LF" {
+			t.Errorf("metric summary has imperial line item %+v", item)
+		}
+	}
+
+	failures := map[string]int{
+		"?units=cubits&blueprint_id=" + analyzed.ID.String(): http.StatusBadRequest,
+		"":                                     http.StatusBadRequest,
+		"?blueprint_id=" + uuid.NewString():    http.StatusNotFound,
+		"?blueprint_id=" + other.ID.String():   http.StatusBadRequest,
+		"?blueprint_id=" + pending.ID.String(): http.StatusBadRequest,
+	}
+	for query, want := range failures {
+		if rec := get(query); rec.Code != want {
+			t.Errorf("GET pricing-summary%s: status = %d, want %d", query, rec.Code, want)
+		}
+	}
+}
+
+func TestCompareBlueprintPricing(t *testing.T) {
+	project := &models.Project{ID: uuid.New(), UserID: uuid.New()}
+	analysis := `{"rooms":[{"name":"Office","dimensions":"10x20","area":200}],"openings":[{"opening_type":"window","count":2}],"confidence_score":0.9}`
+	analyzed := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, AnalysisData: &analysis}
+	pending := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID}
+
+	h := &BidHandlers{
+		PricingSources: &PricingSources{costDataService: fakeCostDataService{}, rangeParams: services.DefaultConfidenceRangeParams},
+		projectRepo:    &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprintRepo:  &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{analyzed.ID: analyzed, pending.ID: pending}},
+		userRepo:       &fakeUserStore{users: map[uuid.UUID]*models.User{project.UserID: {ID: project.UserID}}},
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+	get := func(blueprintID uuid.UUID, regions string) *httptest.ResponseRecorder {
+		return serveAsUser(router, project.UserID, http.MethodGet, "/blueprints/"+blueprintID.String()+"/pricing-comparison?regions="+regions, "")
+	}
+
+	rec := get(analyzed.ID, "california,texas")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var comparison models.RegionComparison
+	if err := json.NewDecoder(rec.Body).Decode(&comparison); err != nil {
+		t.Fatalf("failed to decode comparison: %v", err)
+	}
+	// Texas' 0.9 factor prices carpentry below California's 1.25
+	carpentry := comparison.Trades["carpentry"]
+	if delta := comparison.TradeDeltas["carpentry"]["texas"]; delta >= 0 || delta != math.Round((carpentry["texas"]-carpentry["california"])*100)/100 {
+		t.Errorf("texas carpentry delta = %v, want Texas' carpentry below California's %v", delta, carpentry)
+	}
+	ca, tx := comparison.Totals["california"], comparison.Totals["texas"]
+	if comparison.Baseline != "california" || comparison.PercentDifferences["texas"] != math.Round((tx-ca)/ca*100*100)/100 {
+		t.Errorf("percent differences = %v from %s, want Texas against California", comparison.PercentDifferences, comparison.Baseline)
+	}
+
+	failures := map[string]struct {
+		blueprintID uuid.UUID
+		regions     string
+		want        int
+	}{
+		"unknown region":       {analyzed.ID, "california,atlantis", http.StatusBadRequest},
+		"too many regions":     {analyzed.ID, "a,b,c,d,e,f", http.StatusBadRequest},
+		"no regions":           {analyzed.ID, "", http.StatusBadRequest},
+		"unanalyzed blueprint": {pending.ID, "california", http.StatusBadRequest},
+		"missing blueprint":    {uuid.New(), "california", http.StatusNotFound},
+	}
+	for name, tt := range failures {
+		if rec := get(tt.blueprintID, tt.regions); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestRepriceBid(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	analysis := `{"rooms":[],"openings":[{"opening_type":"door","count":2},{"opening_type":"window","count":1}],"confidence_score":0.9}`
+	blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, AnalysisData: &analysis}
+
+	bidData, _ := json.Marshal(models.GenerateBidResponse{
+		BlueprintID: blueprint.ID.String(),
+		ScopeOfWork: "Install doors and windows",
+		LineItems: []models.LineItem{
+			{Description: "Interior door installation", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 400, Total: 800, Provenance: models.LineItemProvenanceAuto},
+			{Description: "Window installation", Trade: "carpentry", Quantity: 1, Unit: "each", UnitCost: 700, Total: 700, Provenance: models.LineItemProvenanceManual},
+			{Description: "Site protection", Trade: "general", Quantity: 1, Unit: "lot", UnitCost: 250, Total: 250, Provenance: models.LineItemProvenanceManual},
+		},
+		MaterialCost: 1750,
+		Subtotal:     1750,
+		MarkupAmount: 350,
+		TotalPrice:   2100,
+	})
+	bidDataStr := string(bidData)
+	subtotal, labor, material, markup, final := 1750.0, 0.0, 1750.0, 20.0, 2100.0
+	pdfURL := "https://example.com/bid.pdf"
+	bid := &models.Bid{
+		ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusDraft, Version: 1,
+		TotalCost: &subtotal, LaborCost: &labor, MaterialCost: &material, MarkupPercentage: &markup, FinalPrice: &final,
+		BidData: &bidDataStr, PDFURL: &pdfURL,
+	}
+	revisions := &fakeBidRevisionStore{revisions: []*models.BidRevision{newBidRevision(bid, 1, userID.String())}}
+
+	h := &BidHandlers{
+		PricingSources:  NewPricingSources(nil, nil, nil, nil, nil, nil),
+		projectRepo:     &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprintRepo:   &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}},
+		bidRepo:         &fakeBidStore{bids: []*models.Bid{bid}},
+		bidRevisionRepo: revisions,
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+
+	req := httptest.NewRequest(http.MethodPost, "/bids/"+bid.ID.String()+"/reprice", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, userID.String()))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+
+	var got RepriceBidResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	// Only the takeoff-priced door moves to the default 450.00; the manual
+	// window and site protection keep their prices
+	want := []services.LineItemPriceChange{{
+		Description: "Interior door installation", Trade: "carpentry", Quantity: 2, Unit: "each",
+		OldUnitCost: 400, NewUnitCost: 450, OldTotal: 800, NewTotal: 900,
+	}}
+	if !reflect.DeepEqual(got.Changes, want) {
+		t.Errorf("Changes = %+v, want %+v", got.Changes, want)
+	}
+	if got.SkippedManual != 2 || got.OldTotalPrice != 2100 || got.NewTotalPrice != 2220 {
+		t.Errorf("result = %+v, want 2 manual items skipped and the total moved from 2100 to 2220", got.RepriceResult)
+	}
+
+	var repriced models.GenerateBidResponse
+	if err := json.Unmarshal([]byte(*got.Bid.BidData), &repriced); err != nil {
+		t.Fatalf("failed to decode repriced bid data: %v", err)
+	}
+	for _, item := range repriced.LineItems {
+		if item.Description == "Window installation" && item.UnitCost != 700 {
+			t.Errorf("manual window unit cost = %v, want 700 preserved", item.UnitCost)
+		}
+	}
+	if repriced.ScopeOfWork != "Install doors and windows" || repriced.Subtotal != 1850 || repriced.MarkupAmount != 370 {
+		t.Errorf("repriced bid = %+v, want the scope kept and the totals recalculated", repriced)
+	}
+	if *got.Bid.FinalPrice != 2220 || *got.Bid.MaterialCost != 1850 || got.Bid.PDFURL != nil || got.Bid.Version != 2 {
+		t.Errorf("bid = %+v, want new totals, version 2 and the PDF invalidated", got.Bid)
+	}
+
+	if got.Revision == nil || got.Revision.Version != 2 || got.Revision.Reason == nil || *got.Revision.Reason != models.BidRevisionReasonReprice {
+		t.Fatalf("revision = %+v, want a version 2 reprice revision", got.Revision)
+	}
+	var digest models.ComparisonDigest
+	if err := json.Unmarshal([]byte(*got.Revision.ChangesSummary), &digest); err != nil {
+		t.Fatalf("failed to decode changes summary: %v", err)
+	}
+	if digest.FromVersion != 1 || digest.NetCostDelta == nil || *digest.NetCostDelta != 120 {
+		t.Errorf("digest = %+v, want a 120.00 delta from version 1", digest)
+	}
+	if digest.Summary.ChangesByCategory["line_item"] != 1 {
+		t.Errorf("ChangesByCategory = %v, want only the door line item changed", digest.Summary.ChangesByCategory)
+	}
+
+	// Another user's bid is not found
+	req = httptest.NewRequest(http.MethodPost, "/bids/"+bid.ID.String()+"/reprice", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, uuid.NewString()))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("other user: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestPreviewBid_Blueprints(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Campus"}
+	newBlueprint := func(projectID uuid.UUID, filename, analysis string) *models.Blueprint {
+		return &models.Blueprint{ID: uuid.New(), ProjectID: projectID, Filename: filename, Version: 2, AnalysisData: &analysis}
+	}
+	buildingA := newBlueprint(project.ID, "building-a.pdf", `{"rooms":[{"name":"Lobby","area":400}],"openings":[{"opening_type":"door","count":2}]}`)
+	buildingB := newBlueprint(project.ID, "building-b.pdf", `{"rooms":[{"name":"Office","area":600}],"openings":[{"opening_type":"door","count":3}]}`)
+	other := newBlueprint(uuid.New(), "elsewhere.pdf", `{"rooms":[{"name":"Shed","area":100}]}`)
+	unanalyzed := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "building-c.pdf"}
+
+	h := &BidHandlers{
+		PricingSources: NewPricingSources(nil, nil, nil, nil, nil, nil),
+		projectRepo:    &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprintRepo: &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{
+			buildingA.ID: buildingA, buildingB.ID: buildingB, other.ID: other, unanalyzed.ID: unanalyzed,
+		}},
+		bidRepo:  &fakeBidStore{},
+		userRepo: &fakeUserStore{users: map[uuid.UUID]*models.User{userID: {ID: userID}}},
+		config:   &config.Config{},
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+
+	preview := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/projects/"+project.ID.String()+"/bids/preview", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, userID.String()))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) BidPreviewResponse {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+		}
+		var got BidPreviewResponse
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode preview: %v", err)
+		}
+		return got
+	}
+
+	t.Run("single blueprint", func(t *testing.T) {
+		got := decode(t, preview(`{"blueprint_id":"`+buildingA.ID.String()+`"}`))
+		want := []models.BidSourceBlueprint{{BlueprintID: buildingA.ID, Filename: "building-a.pdf", Version: 2}}
+		if !reflect.DeepEqual(got.SourceBlueprints, want) || got.BlueprintID != buildingA.ID.String() {
+			t.Errorf("sources = %+v, blueprint %s; want building A only", got.SourceBlueprints, got.BlueprintID)
+		}
+		if got.UnitMetrics == nil || got.UnitMetrics.AreaSF != 400 {
+			t.Errorf("unit metrics = %+v, want building A's 400 SF", got.UnitMetrics)
+		}
+	})
+
+	t.Run("combined bid merges takeoffs", func(t *testing.T) {
+		got := decode(t, preview(`{"blueprint_ids":["`+buildingA.ID.String()+`","`+buildingB.ID.String()+`","`+buildingA.ID.String()+`"]}`))
+		if len(got.SourceBlueprints) != 2 || got.SourceBlueprints[1].Filename != "building-b.pdf" || got.BlueprintID != buildingA.ID.String() {
+			t.Errorf("sources = %+v, blueprint %s; want buildings A and B", got.SourceBlueprints, got.BlueprintID)
+		}
+		if got.UnitMetrics == nil || got.UnitMetrics.AreaSF != 1000 {
+			t.Errorf("unit metrics = %+v, want both buildings' 1000 SF", got.UnitMetrics)
+		}
+		doors := 0.0
+		for _, item := range got.LineItems {
+			if item.Description == "Interior door installation" {
+				doors = item.Quantity
+			}
+		}
+		if doors != 5 {
+			t.Errorf("door quantity = %v, want 5 across both buildings", doors)
+		}
+	})
+
+	t.Run("no blueprints prices every analyzed blueprint", func(t *testing.T) {
+		got := decode(t, preview(`{"blueprint_ids":[]}`))
+		if len(got.SourceBlueprints) != 2 {
+			t.Errorf("sources = %+v, want buildings A and B", got.SourceBlueprints)
+		}
+		if got.UnitMetrics == nil || got.UnitMetrics.AreaSF != 1000 {
+			t.Errorf("unit metrics = %+v, want both buildings' 1000 SF", got.UnitMetrics)
+		}
+	})
+
+	t.Run("rejects blueprints from another project", func(t *testing.T) {
+		rec := preview(`{"blueprint_ids":["` + buildingA.ID.String() + `","` + other.ID.String() + `"]}`)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "does not belong") {
+			t.Errorf("status = %d, body %s; want 400", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("rejects unanalyzed blueprints", func(t *testing.T) {
+		rec := preview(`{"blueprint_ids":["` + buildingA.ID.String() + `","` + unanalyzed.ID.String() + `"]}`)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "building-c.pdf") {
+			t.Errorf("status = %d, body %s; want 400 naming the blueprint", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("rejects a blueprint_id outside blueprint_ids", func(t *testing.T) {
+		rec := preview(`{"blueprint_id":"` + buildingB.ID.String() + `","blueprint_ids":["` + buildingA.ID.String() + `"]}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestGetBidPDF_QueuesJob(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Office Remodel"}
+	blueprintID := uuid.New()
+	bidData := `{"scope_of_work": "Remodel", "line_items": [], "total_price": 1200}`
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusDraft, Version: 1, BidData: &bidData, BlueprintIDs: []uuid.UUID{blueprintID}}
+	brokenData := `{"line_items": "not a list"}`
+	broken := &models.Bid{ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusDraft, Version: 1, BidData: &brokenData, BlueprintIDs: []uuid.UUID{blueprintID}}
+
+	jobs := &fakeJobStore{}
+	router := chi.NewRouter()
+	(&BidHandlers{
+		projectRepo: &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		bidRepo:     &fakeBidStore{bids: []*models.Bid{bid, broken}},
+		userRepo:    &fakeUserStore{users: map[uuid.UUID]*models.User{userID: {ID: userID}}},
+		jobRepo:     jobs,
+		config:      &config.Config{Worker: config.WorkerConfig{PollInterval: 5 * time.Second, MaxQueuedJobsPerUser: 2}},
+	}).Routes(router)
+
+	getPDF := func(bid *models.Bid) (int, map[string]string) {
+		rec := serveAsUser(router, userID, http.MethodGet, "/bids/"+bid.ID.String()+"/pdf", "")
+		var body map[string]string
+		json.NewDecoder(rec.Body).Decode(&body)
+		return rec.Code, body
+	}
+
+	// A user at the queue ceiling is told to retry
+	jobs.depth = models.QueueDepth{Total: 2, User: 2}
+	if code, body := getPDF(bid); code != http.StatusTooManyRequests || body["code"] != "QUEUE_FULL" || len(jobs.jobs) != 0 {
+		t.Fatalf("full queue: status = %d, body %v, %d jobs; want 429 QUEUE_FULL and no job", code, body, len(jobs.jobs))
+	}
+	jobs.depth = models.QueueDepth{}
+
+	// Without a PDF the worker is asked to render one
+	code, body := getPDF(bid)
+	if code != http.StatusAccepted || body["status"] != "generating" {
+		t.Fatalf("first request: status = %d, body %v; want 202 generating", code, body)
+	}
+	job := jobs.jobs[uuid.MustParse(body["job_id"])]
+	if job == nil || job.JobType != models.JobTypePDFGeneration || *job.BidID != bid.ID || job.BlueprintID != blueprintID {
+		t.Fatalf("queued job = %+v, want a PDF job for the bid", job)
+	}
+
+	// While it runs, requests report it rather than queueing another
+	job.Status = models.JobStatusProcessing
+	if code, body := getPDF(bid); code != http.StatusAccepted || body["job_id"] != job.ID.String() || len(jobs.jobs) != 1 {
+		t.Errorf("while processing: status = %d, body %v, %d jobs; want 202 for the running job", code, body, len(jobs.jobs))
+	}
+
+	job.Status = models.JobStatusCompleted
+	url := "https://s3.example.com/bids/rendered.pdf"
+	bid.PDFURL = &url
+	if code, body := getPDF(bid); code != http.StatusOK || body["pdf_url"] != url {
+		t.Errorf("once rendered: status = %d, body %v; want 200 with the PDF URL", code, body)
+	}
+
+	// Bid data that can't be rendered is reported without queueing a job
+	if code, _ := getPDF(broken); code != http.StatusInternalServerError || len(jobs.jobs) != 1 {
+		t.Errorf("unparseable bid: status = %d with %d jobs, want 500 and no new job", code, len(jobs.jobs))
+	}
+}
+
+func TestGenerateBid_ConfidenceGate(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Office Remodel"}
+	newBlueprint := func(confidence string) *models.Blueprint {
+		analysis := `{"rooms":[{"name":"Office","dimensions":"10x20","area":200}],"openings":[{"opening_type":"door","count":2}],"confidence_score":` + confidence + `}`
+		return &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "plans.pdf", Version: 1, AnalysisData: &analysis}
+	}
+	blurry, clear := newBlueprint("0.42"), newBlueprint("0.9")
+
+	bids := &fakeBidStore{}
+	h := &BidHandlers{
+		PricingSources: NewPricingSources(nil, nil, nil, nil, nil, nil),
+		projectRepo:    &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprintRepo:  &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blurry.ID: blurry, clear.ID: clear}},
+		bidRepo:        bids,
+		userRepo:       &fakeUserStore{users: map[uuid.UUID]*models.User{userID: {ID: userID}}},
+		jobRepo:        &fakeJobStore{},
+		aiService:      services.NewStubAIProvider(0, 0),
+		events:         events.NewBus(),
+		config:         &config.Config{AI: config.AIConfig{MinConfidence: 0.6}},
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+
+	generate := func(t *testing.T, body string) (*httptest.ResponseRecorder, *models.GenerateBidResponse) {
+		t.Helper()
+		rec := serveAsUser(router, userID, http.MethodPost, "/projects/"+project.ID.String()+"/generate-bid", body)
+		if rec.Code != http.StatusAccepted {
+			return rec, nil
+		}
+		bid := bids.bids[len(bids.bids)-1]
+		var data models.GenerateBidResponse
+		if err := json.Unmarshal([]byte(*bid.BidData), &data); err != nil {
+			t.Fatalf("failed to decode bid data: %v", err)
+		}
+		return rec, &data
+	}
+
+	t.Run("blocked", func(t *testing.T) {
+		rec, _ := generate(t, `{"blueprint_id":"`+blurry.ID.String()+`"}`)
+		var body APIError
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if rec.Code != http.StatusUnprocessableEntity || body.Code != CodeLowConfidence || body.Details["confidence_score"] != 0.42 || body.Details["min_confidence"] != 0.6 {
+			t.Errorf("status = %d, body %v; want 422 low_confidence with the score", rec.Code, body)
+		}
+		if len(bids.bids) != 0 {
+			t.Errorf("blocked request saved %d bids", len(bids.bids))
+		}
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		rec, data := generate(t, `{"blueprint_id":"`+blurry.ID.String()+`","force":true}`)
+		if data == nil {
+			t.Fatalf("status = %d, body %s; want 202", rec.Code, rec.Body.String())
+		}
+		if !data.GeneratedFromLowConfidence {
+			t.Error("forced bid data is not flagged as generated from low confidence")
+		}
+	})
+
+	t.Run("above threshold", func(t *testing.T) {
+		rec, data := generate(t, `{"blueprint_id":"`+clear.ID.String()+`"}`)
+		if data == nil {
+			t.Fatalf("status = %d, body %s; want 202", rec.Code, rec.Body.String())
+		}
+		if data.GeneratedFromLowConfidence {
+			t.Error("bid from a confident analysis is flagged as low confidence")
+		}
+	})
+
+	t.Run("pricing summary", func(t *testing.T) {
+		base := "/projects/" + project.ID.String() + "/pricing-summary?blueprint_id=" + blurry.ID.String()
+		if rec := serveAsUser(router, userID, http.MethodGet, base, ""); rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), CodeLowConfidence) {
+			t.Errorf("status = %d, body %s; want 422 low_confidence", rec.Code, rec.Body.String())
+		}
+		if rec := serveAsUser(router, userID, http.MethodGet, base+"&force=true", ""); rec.Code != http.StatusOK {
+			t.Errorf("forced: status = %d, want 200", rec.Code)
+		}
+	})
+}
+
+func TestGenerateBid_BudgetAcknowledgement(t *testing.T) {
+	budget, threshold := 100.0, 10.0
+	strict, lenient := uuid.New(), uuid.New()
+	analysis := `{"rooms":[{"name":"Office","dimensions":"10x20","area":200}],"openings":[{"opening_type":"door","count":2}],"confidence_score":0.9}`
+	projects := &fakeProjectStore{projects: map[uuid.UUID]*models.Project{}}
+	blueprints := &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{}}
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{}}
+	projectFor := func(userID uuid.UUID) (*models.Project, *models.Blueprint) {
+		project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Office Remodel", Budget: &budget}
+		blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "plans.pdf", Version: 1, AnalysisData: &analysis}
+		projects.projects[project.ID] = project
+		blueprints.blueprints[blueprint.ID] = blueprint
+		users.users[userID] = &models.User{ID: userID}
+		return project, blueprint
+	}
+	strictProject, strictBlueprint := projectFor(strict)
+	lenientProject, lenientBlueprint := projectFor(lenient)
+
+	recorder := &events.Recorder{}
+	h := &BidHandlers{
+		PricingSources: NewPricingSources(nil, nil, nil, nil, nil, nil),
+		projectRepo:    projects,
+		blueprintRepo:  blueprints,
+		bidRepo:        &fakeBidStore{},
+		userRepo:       users,
+		profileRepo: &fakeCompanyProfileStore{profiles: map[uuid.UUID]*models.CompanyProfile{
+			strict: {UserID: strict, Name: "Strict Builders", BudgetAckThresholdPercent: &threshold},
+		}},
+		jobRepo:   &fakeJobStore{},
+		aiService: services.NewStubAIProvider(0, 0),
+		events:    recorder,
+		// The configured default leaves acknowledgement off
+		config: &config.Config{AI: config.AIConfig{MinConfidence: 0.6}},
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+	generate := func(userID uuid.UUID, project *models.Project, body string) *httptest.ResponseRecorder {
+		return serveAsUser(router, userID, http.MethodPost, "/projects/"+project.ID.String()+"/generate-bid", body)
+	}
+
+	rec := generate(strict, strictProject, `{"blueprint_id":"`+strictBlueprint.ID.String()+`"}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), CodeOverBudget) {
+		t.Errorf("over the company's threshold: status = %d, body %s; want 409 %s", rec.Code, rec.Body.String(), CodeOverBudget)
+	}
+	if got := events.Recorded[events.BidOverBudget](recorder); len(got) != 0 {
+		t.Errorf("blocked request published %d over-budget events", len(got))
+	}
+
+	rec = generate(strict, strictProject, `{"blueprint_id":"`+strictBlueprint.ID.String()+`","acknowledge_over_budget":true}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("acknowledged: status = %d, body %s; want 202", rec.Code, rec.Body.String())
+	}
+	rec = generate(lenient, lenientProject, `{"blueprint_id":"`+lenientBlueprint.ID.String()+`"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("company on the default: status = %d, body %s; want 202", rec.Code, rec.Body.String())
+	}
+
+	published := events.Recorded[events.BidOverBudget](recorder)
+	if len(published) != 2 {
+		t.Fatalf("published %d over-budget events, want 2", len(published))
+	}
+	for _, event := range published {
+		if event.BudgetStatus.Status != models.BudgetStateOver || event.BudgetStatus.Budget != budget || event.BidID == uuid.Nil {
+			t.Errorf("over-budget event = %+v", event)
+		}
+	}
+}
+
+func TestGenerateBid_AIFallback(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Office Remodel"}
+	analysis := `{"rooms":[{"name":"Office","dimensions":"10x20","area":200}],"openings":[{"opening_type":"door","count":2}],"confidence_score":0.9}`
+	blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "plans.pdf", Version: 1, AnalysisData: &analysis}
+
+	newRouter := func(ai services.AIProvider) (chi.Router, *fakeBidStore) {
+		bids := &fakeBidStore{}
+		h := &BidHandlers{
+			PricingSources: NewPricingSources(nil, nil, nil, nil, nil, nil),
+			projectRepo:    &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+			blueprintRepo:  &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}},
+			bidRepo:        bids,
+			userRepo:       &fakeUserStore{users: map[uuid.UUID]*models.User{userID: {ID: userID}}},
+			jobRepo:        &fakeJobStore{},
+			aiService:      ai,
+			events:         events.NewBus(),
+			config:         &config.Config{AI: config.AIConfig{MinConfidence: 0.6}},
+		}
+		router := chi.NewRouter()
+		h.Routes(router)
+		return router, bids
+	}
+	generate := func(t *testing.T, ai services.AIProvider) (*models.Bid, *models.Bid, *models.GenerateBidResponse) {
+		t.Helper()
+		router, bids := newRouter(ai)
+		rec := serveAsUser(router, userID, http.MethodPost, "/projects/"+project.ID.String()+"/generate-bid", `{"blueprint_id":"`+blueprint.ID.String()+`"}`)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, body %s; want 202", rec.Code, rec.Body.String())
+		}
+		var returned models.Bid
+		if err := json.NewDecoder(rec.Body).Decode(&returned); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(bids.bids) != 1 {
+			t.Fatalf("saved %d bids, want 1", len(bids.bids))
+		}
+		stored := bids.bids[0]
+		var data models.GenerateBidResponse
+		if err := json.Unmarshal([]byte(*stored.BidData), &data); err != nil {
+			t.Fatalf("failed to decode bid data: %v", err)
+		}
+		return &returned, stored, &data
+	}
+
+	t.Run("AI unavailable", func(t *testing.T) {
+		returned, stored, data := generate(t, services.NewStubAIProvider(0, 1))
+		if data.GenerationMode != models.BidGenerationModeDeterministic || stored.GenerationModel != nil {
+			t.Errorf("generation mode = %q, model %v; want deterministic with no model", data.GenerationMode, stored.GenerationModel)
+		}
+		if stored.Status != models.BidStatusDraft || len(data.LineItems) == 0 || data.TotalPrice <= 0 || *stored.FinalPrice != data.TotalPrice {
+			t.Errorf("stored bid = status %s, %d items, total %.2f; want a priced draft", stored.Status, len(data.LineItems), data.TotalPrice)
+		}
+		if !strings.Contains(data.ScopeOfWork, "Office") {
+			t.Errorf("scope = %q, want it to list the takeoff's rooms", data.ScopeOfWork)
+		}
+		if !slices.Contains(returned.Warnings, services.AIUnavailableWarning) || !slices.Contains(data.Warnings, services.AIUnavailableWarning) {
+			t.Errorf("warnings = %v returned, %v stored; want the AI unavailable warning", returned.Warnings, data.Warnings)
+		}
+		if returned.PDFJobID == nil {
+			t.Error("expected the PDF queued for the fallback bid")
+		}
+	})
+
+	t.Run("AI available", func(t *testing.T) {
+		returned, stored, data := generate(t, services.NewStubAIProvider(0, 0))
+		if data.GenerationMode != models.BidGenerationModeAI || stored.GenerationModel == nil {
+			t.Errorf("generation mode = %q, model %v; want ai with the model", data.GenerationMode, stored.GenerationModel)
+		}
+		if slices.Contains(returned.Warnings, services.AIUnavailableWarning) {
+			t.Errorf("warnings = %v, want no AI unavailable warning", returned.Warnings)
+		}
+	})
+}
@@ -1,25 +1,84 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/errreport"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
 )
 
+// BlueprintHandlers serves blueprint uploads, analysis data and text search
+type BlueprintHandlers struct {
+	projectRepo        ProjectStore
+	blueprintRepo      BlueprintStore
+	blueprintAssetRepo BlueprintAssetStore
+	userRepo           UserStore
+	s3Service          *services.S3Service
+	deleter            *services.BlueprintDeleter
+	fileValidator      *services.FileValidator
+	uploadScanner      *services.UploadScanner
+}
+
+func NewBlueprintHandlers(
+	projectRepo ProjectStore,
+	blueprintRepo BlueprintStore,
+	blueprintAssetRepo BlueprintAssetStore,
+	userRepo UserStore,
+	s3Service *services.S3Service,
+	deleter *services.BlueprintDeleter,
+	cfg *config.Config,
+) *BlueprintHandlers {
+	return &BlueprintHandlers{
+		projectRepo:        projectRepo,
+		blueprintRepo:      blueprintRepo,
+		blueprintAssetRepo: blueprintAssetRepo,
+		userRepo:           userRepo,
+		s3Service:          s3Service,
+		deleter:            deleter,
+		fileValidator:      services.NewFileValidator(),
+		uploadScanner:      newUploadScanner(cfg, s3Service),
+	}
+}
+
+// Routes registers the blueprint upload and analysis routes
+func (h *BlueprintHandlers) Routes(r chi.Router) {
+	// Blueprint upload routes
+	r.Post("/projects/{id}/blueprints/upload-url", h.CreateUploadURL)
+	r.Post("/blueprints/{id}/complete-upload", h.CompleteUpload)
+	r.Put("/blueprints/{id}", h.UpdateBlueprint)
+	r.Delete("/blueprints/{id}", h.DeleteBlueprint)
+	r.Put("/blueprints/{id}/room-finishes", h.UpdateRoomFinishes)
+	r.Put("/blueprints/{id}/takeoff-adjustments", h.UpdateTakeoffAdjustments)
+
+	// Blueprint analysis routes
+	r.Get("/blueprints/{id}/analysis", h.GetBlueprintAnalysis)
+	r.Get("/blueprints/{id}/assets", h.GetBlueprintAssets)
+	r.Get("/blueprints/{id}/takeoff-summary", h.GetBlueprintTakeoffSummary)
+	r.Get("/projects/{id}/takeoff-summary", h.GetProjectTakeoffSummary)
+	r.Get("/projects/{id}/blueprints/search-text", h.SearchBlueprintText)
+}
+
 type UploadURLRequest struct {
 	Filename    string `json:"filename"`
 	ContentType string `json:"content_type"`
 }
 
 type UploadURLResponse struct {
-	BlueprintID uuid.UUID `json:"blueprint_id"`
-	UploadURL   string    `json:"upload_url"`
-	ExpiresAt   time.Time `json:"expires_at"`
+	BlueprintID uuid.UUID        `json:"blueprint_id"`
+	UploadURL   string           `json:"upload_url"`
+	ExpiresAt   models.Timestamp `json:"expires_at"`
+	// UploadHeaders must be sent with the upload; they are signed into the URL
+	UploadHeaders map[string]string `json:"upload_headers,omitempty"`
 }
 
 type CompleteUploadResponse struct {
@@ -28,34 +87,32 @@ type CompleteUploadResponse struct {
 	Filename string    `json:"filename"`
 }
 
-func (h *Handler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
-	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *BlueprintHandlers) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid project ID")
+		respondInvalidID(w)
 		return
 	}
 
 	var req UploadURLRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
 	if req.Filename == "" || req.ContentType == "" {
-		respondError(w, http.StatusBadRequest, "filename and content_type are required")
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "filename and content_type are required")
 		return
 	}
 
 	// Validate content type
 	if err := h.fileValidator.ValidateContentType(req.ContentType); err != nil {
-		respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid content type: %v", err))
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, fmt.Sprintf("Invalid content type: %v", err))
 		return
 	}
 
-	// Verify project exists (simplified - in production, verify user ownership)
-	project, err := h.projectRepo.GetByID(r.Context(), projectID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "Project not found")
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
 		return
 	}
 
@@ -72,19 +129,20 @@ func (h *Handler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
 		AnalysisStatus: models.AnalysisStatusNotStarted,
 		Version:        1,
 		IsLatest:       true,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		CreatedAt:      models.Now(),
+		UpdatedAt:      models.Now(),
 	}
 
 	if err := h.blueprintRepo.Create(r.Context(), blueprint); err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to create blueprint record")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to create blueprint record")
 		return
 	}
 
 	// Generate presigned URL
 	uploadURL, err := h.s3Service.GeneratePresignedUploadURL(r.Context(), s3Key, req.ContentType)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to generate upload URL")
+		errreport.CaptureError(r.Context(), err, map[string]string{errreport.TagComponent: "s3", "blueprint_id": blueprint.ID.String()})
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate upload URL")
 		return
 	}
 
@@ -92,47 +150,90 @@ func (h *Handler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
 	// The S3 service is configured with the expiry duration from config
 	expiresAt := time.Now().Add(5 * time.Minute) // This matches the default S3_PRESIGN_EXPIRY
 	respondJSON(w, http.StatusOK, UploadURLResponse{
-		BlueprintID: blueprintID,
-		UploadURL:   uploadURL,
-		ExpiresAt:   expiresAt,
+		BlueprintID:   blueprintID,
+		UploadURL:     uploadURL,
+		ExpiresAt:     models.NewTimestamp(expiresAt),
+		UploadHeaders: h.s3Service.BlueprintUploadHeaders(),
 	})
 }
 
-func (h *Handler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
-	blueprintID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *BlueprintHandlers) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid blueprint ID")
+		respondInvalidID(w)
 		return
 	}
 
 	// Get blueprint record
-	blueprint, err := h.blueprintRepo.GetByID(r.Context(), blueprintID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "Blueprint not found")
+	blueprint, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
 		return
 	}
 
 	// Verify file exists in S3
-	exists, fileSize, err := h.s3Service.ObjectExists(r.Context(), blueprint.S3Key)
+	stat, err := h.s3Service.StatObject(r.Context(), blueprint.S3Key)
+	if err != nil {
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to verify file")
+		return
+	}
+
+	if stat == nil {
+		respondAPIError(w, http.StatusNotFound, CodeResourceNotFound, "File not found in storage")
+		return
+	}
+
+	// Scan before the file can be analyzed or served back
+	scan, err := h.uploadScanner.ScanUpload(r.Context(), blueprint.S3Key)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to verify file")
+		slog.Error("Virus scan failed", "blueprint_id", blueprint.ID, "error", err)
+		respondAPIError(w, http.StatusServiceUnavailable, CodeScanUnavailable, "File scanning is unavailable, please retry")
 		return
 	}
+	blueprint.ScanResult = &scan.Result
+
+	if scan.Rejected {
+		blueprint.UploadStatus = models.UploadStatusFailed
+		blueprint.UpdatedAt = models.Now()
+		if err := h.blueprintRepo.Update(r.Context(), blueprint); err != nil {
+			slog.Error("Failed to mark blueprint rejected", "blueprint_id", blueprint.ID, "error", err)
+		}
+
+		slog.Warn("Blueprint upload rejected by virus scan",
+			"audit_event", "blueprint.upload_rejected",
+			"blueprint_id", blueprint.ID,
+			"project_id", blueprint.ProjectID,
+			"user_id", getUserID(r.Context()),
+			"scan_result", scan.Result,
+			"correlation_id", getCorrelationID(r.Context()))
 
-	if !exists {
-		respondError(w, http.StatusNotFound, "File not found in storage")
+		respondAPIError(w, http.StatusUnprocessableEntity, CodeFileRejectedMalware, "File rejected: malware detected")
 		return
 	}
 
+	// Read the page structure up front; a file that can't be parsed still uploads
+	h.recordPageMetadata(r.Context(), blueprint)
+
 	// Update blueprint record
+	fileSize := stat.Size
 	blueprint.UploadStatus = models.UploadStatusUploaded
 	blueprint.FileSize = &fileSize
-	blueprint.UpdatedAt = time.Now()
+	blueprint.UpdatedAt = models.Now()
 
 	if err := h.blueprintRepo.Update(r.Context(), blueprint); err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to update blueprint")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update blueprint")
+		return
+	}
+
+	// A takeoff job still running against the previous file sees the new
+	// generation when it finishes and discards its result
+	generation, err := h.blueprintRepo.IncrementUploadGeneration(r.Context(), blueprint.ID)
+	if err != nil {
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update blueprint")
 		return
 	}
+	blueprint.UploadGeneration = generation
+
+	recordBlueprintAsset(r.Context(), h.blueprintAssetRepo, originalAsset(blueprint, stat.ETag, stat.StorageClass))
 
 	respondJSON(w, http.StatusOK, CompleteUploadResponse{
 		ID:       blueprint.ID,
@@ -140,3 +241,312 @@ func (h *Handler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
 		Filename: blueprint.Filename,
 	})
 }
+
+// recordPageMetadata sets a blueprint's page count, page sizes and document
+// metadata from its uploaded file. Failures are logged and leave the page
+// count unknown.
+func (h *BlueprintHandlers) recordPageMetadata(ctx context.Context, blueprint *models.Blueprint) {
+	blueprint.PageCount = nil
+	blueprint.PageSizes = nil
+	blueprint.DocumentTitle = nil
+	blueprint.DocumentCreatedAt = nil
+	if blueprint.MimeType == nil || h.s3Service == nil {
+		return
+	}
+
+	data, err := h.s3Service.DownloadFile(ctx, blueprint.S3Key)
+	if err != nil {
+		slog.Warn("Failed to download blueprint for page metadata", "blueprint_id", blueprint.ID, "error", err)
+		return
+	}
+	metadata, err := services.ExtractBlueprintMetadata(data, *blueprint.MimeType)
+	if err != nil {
+		slog.Warn("Failed to read blueprint page metadata",
+			"blueprint_id", blueprint.ID,
+			"mime_type", *blueprint.MimeType,
+			"error", err,
+			"correlation_id", getCorrelationID(ctx))
+	}
+	applyPageMetadata(blueprint, metadata)
+}
+
+func applyPageMetadata(blueprint *models.Blueprint, metadata *services.BlueprintMetadata) {
+	if metadata == nil {
+		return
+	}
+	pageCount := metadata.PageCount
+	blueprint.PageCount = &pageCount
+	blueprint.PageSizes = metadata.PageSizes
+	blueprint.DocumentTitle = metadata.Title
+	if metadata.CreatedAt != nil {
+		created := models.NewTimestamp(*metadata.CreatedAt)
+		blueprint.DocumentCreatedAt = &created
+	}
+}
+
+type UpdateBlueprintRequest struct {
+	SheetType *models.SheetType `json:"sheet_type"`
+}
+
+// UpdateBlueprint updates user-editable blueprint metadata
+func (h *BlueprintHandlers) UpdateBlueprint(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req UpdateBlueprintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	if req.SheetType != nil && !req.SheetType.IsValid() {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "sheet_type must be one of architectural, electrical, plumbing, mechanical, unknown")
+		return
+	}
+
+	blueprint, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
+		return
+	}
+
+	if req.SheetType != nil {
+		blueprint.SheetType = req.SheetType
+	}
+	blueprint.UpdatedAt = models.Now()
+
+	if err := h.blueprintRepo.Update(r.Context(), blueprint); err != nil {
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update blueprint")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, blueprint)
+}
+
+// DeleteBlueprintResponse is the 409 body when bids were priced from the
+// blueprint; those bids must be deleted first
+type DeleteBlueprintResponse struct {
+	Error  string      `json:"error"`
+	BidIDs []uuid.UUID `json:"bid_ids"`
+}
+
+// DeleteBlueprint removes a blueprint, its revisions, assets and unfinished
+// jobs, and its stored files
+func (h *BlueprintHandlers) DeleteBlueprint(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	blueprint, project, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.deleter.Delete(r.Context(), blueprint)
+	if err != nil {
+		var inUse *services.BlueprintInUseError
+		if errors.As(err, &inUse) {
+			respondJSON(w, http.StatusConflict, DeleteBlueprintResponse{
+				Error:  "Blueprint is used by bids; delete them first",
+				BidIDs: inUse.BidIDs,
+			})
+			return
+		}
+		if !isRepositoryError(err) {
+			slog.Error("Failed to delete blueprint", "blueprint_id", blueprint.ID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to delete blueprint")
+		return
+	}
+
+	slog.Info("Blueprint deleted",
+		"audit_event", "blueprint.deleted",
+		"blueprint_id", blueprint.ID,
+		"project_id", project.ID,
+		"user_id", project.UserID,
+		"cancelled_jobs", deletion.CancelledJobs,
+		"objects", len(deletion.S3Keys))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type UpdateRoomFinishesRequest struct {
+	RoomFinishes map[string]models.FloorFinish `json:"room_finishes"`
+}
+
+// UpdateRoomFinishes replaces the per-room floor finish selections for a blueprint
+func (h *BlueprintHandlers) UpdateRoomFinishes(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req UpdateRoomFinishesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	if err := services.ValidateRoomFinishes(req.RoomFinishes); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	if _, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID); !ok {
+		return
+	}
+
+	if err := h.blueprintRepo.UpdateRoomFinishes(r.Context(), blueprintID, req.RoomFinishes); err != nil {
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update room finishes")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"blueprint_id":  blueprintID,
+		"room_finishes": req.RoomFinishes,
+	})
+}
+
+type UpdateTakeoffAdjustmentsRequest struct {
+	Adjustments []models.TakeoffAdjustment `json:"adjustments"`
+}
+
+// UpdateTakeoffAdjustments replaces the estimator's adjustments to a
+// blueprint's takeoff. The analysis is left as it is; the response reports
+// which adjustments match nothing in the current analysis.
+func (h *BlueprintHandlers) UpdateTakeoffAdjustments(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req UpdateTakeoffAdjustmentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	if req.Adjustments == nil {
+		req.Adjustments = []models.TakeoffAdjustment{}
+	}
+	if err := services.ValidateTakeoffAdjustments(req.Adjustments); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	blueprint, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
+		return
+	}
+
+	if err := h.blueprintRepo.UpdateTakeoffAdjustments(r.Context(), blueprintID, req.Adjustments); err != nil {
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to update takeoff adjustments")
+		return
+	}
+
+	orphaned := []models.TakeoffAdjustment{}
+	if blueprint.AnalysisData != nil && *blueprint.AnalysisData != "" {
+		if analysis, err := services.NewTakeoffService().ParseAnalysisData(*blueprint.AnalysisData); err == nil {
+			if result := services.ApplyAdjustments(analysis, req.Adjustments); result.Orphaned != nil {
+				orphaned = result.Orphaned
+			}
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"blueprint_id":         blueprintID,
+		"takeoff_adjustments":  req.Adjustments,
+		"orphaned_adjustments": orphaned,
+	})
+}
+
+// originalAsset describes a blueprint's uploaded file
+func originalAsset(blueprint *models.Blueprint, checksum, storageClass string) *models.BlueprintAsset {
+	asset := &models.BlueprintAsset{
+		ID:          uuid.New(),
+		BlueprintID: blueprint.ID,
+		Kind:        models.BlueprintAssetOriginal,
+		S3Key:       blueprint.S3Key,
+		SizeBytes:   blueprint.FileSize,
+		CreatedAt:   models.Now(),
+	}
+	if checksum != "" {
+		asset.Checksum = &checksum
+	}
+	if storageClass != "" {
+		asset.StorageClass = &storageClass
+	}
+	return asset
+}
+
+// revisionAsset describes the object an archived blueprint revision points at
+func revisionAsset(revision *models.BlueprintRevision) *models.BlueprintAsset {
+	return &models.BlueprintAsset{
+		ID:          uuid.New(),
+		BlueprintID: revision.BlueprintID,
+		Kind:        models.BlueprintAssetRevision,
+		S3Key:       revision.S3Key,
+		SizeBytes:   revision.FileSize,
+		CreatedAt:   revision.CreatedAt,
+	}
+}
+
+// recordBlueprintAsset tracks a stored object. Failures are logged rather
+// than failing the request that stored it.
+func recordBlueprintAsset(ctx context.Context, assets BlueprintAssetStore, asset *models.BlueprintAsset) {
+	if assets == nil {
+		return
+	}
+	if err := assets.Record(ctx, asset); err != nil {
+		slog.Error("Failed to record blueprint asset",
+			"blueprint_id", asset.BlueprintID,
+			"kind", asset.Kind,
+			"s3_key", asset.S3Key,
+			"error", err)
+	}
+}
+
+// GetBlueprintAssets lists every S3 object stored for a blueprint with a
+// presigned download link, for debugging and support
+func (h *BlueprintHandlers) GetBlueprintAssets(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	blueprint, err := h.blueprintRepo.GetByID(r.Context(), blueprintID)
+	if err != nil {
+		respondNotFound(w)
+		return
+	}
+	project, err := h.projectRepo.GetByID(r.Context(), blueprint.ProjectID)
+	if err != nil || (project.UserID.String() != getUserID(r.Context()) && !isAdmin(r.Context(), h.userRepo)) {
+		respondNotFound(w)
+		return
+	}
+
+	assets, err := h.blueprintAssetRepo.ListByBlueprint(r.Context(), blueprintID)
+	if err != nil {
+		slog.Error("Failed to list blueprint assets", "blueprint_id", blueprintID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to list blueprint assets")
+		return
+	}
+
+	for _, asset := range assets {
+		url, err := h.s3Service.GeneratePresignedDownloadURL(r.Context(), asset.S3Key)
+		if err != nil {
+			slog.Warn("Failed to presign blueprint asset", "s3_key", asset.S3Key, "error", err)
+			continue
+		}
+		asset.DownloadURL = &url
+	}
+
+	respondJSON(w, http.StatusOK, assets)
+}
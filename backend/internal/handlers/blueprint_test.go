@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestBlueprintAssets(t *testing.T) {
+	size := int64(2048)
+	blueprint := &models.Blueprint{ID: uuid.New(), S3Key: "projects/p/blueprints/b/A-101.pdf", FileSize: &size}
+
+	original := originalAsset(blueprint, "9e107d9d372bb6826bd81d3542a419d6", "STANDARD_IA")
+	if original.Kind != models.BlueprintAssetOriginal || original.BlueprintID != blueprint.ID || original.S3Key != blueprint.S3Key {
+		t.Errorf("unexpected original asset: %+v", original)
+	}
+	if original.SizeBytes == nil || *original.SizeBytes != size || original.Checksum == nil {
+		t.Errorf("expected size and checksum on the original asset, got %+v", original)
+	}
+	if original.StorageClass == nil || *original.StorageClass != "STANDARD_IA" {
+		t.Errorf("expected the storage class on the original asset, got %v", original.StorageClass)
+	}
+	if unchecked := originalAsset(blueprint, "", ""); unchecked.Checksum != nil || unchecked.StorageClass != nil {
+		t.Errorf("expected no checksum or storage class without a HEAD result, got %+v", unchecked)
+	}
+
+	revision := &models.BlueprintRevision{BlueprintID: blueprint.ID, S3Key: blueprint.S3Key, FileSize: &size, CreatedAt: models.Now()}
+	asset := revisionAsset(revision)
+	if asset.Kind != models.BlueprintAssetRevision || asset.S3Key != revision.S3Key || asset.CreatedAt != revision.CreatedAt {
+		t.Errorf("unexpected revision asset: %+v", asset)
+	}
+}
+
+func TestTakeoffAdjustments(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	analysis := `{"rooms":[{"name":"Office","area":200},{"name":"Storage","area":80}],"openings":[{"opening_type":"door","count":2,"size":"36x80"}]}`
+	blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, AnalysisData: &analysis}
+	blueprints := &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}}
+	h := &BlueprintHandlers{
+		projectRepo:   &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprintRepo: blueprints,
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+
+	serve := func(method, path, body string, user uuid.UUID) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, user.String()))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	path := "/blueprints/" + blueprint.ID.String() + "/takeoff-adjustments"
+	body := `{"adjustments":[
+		{"entity_type":"room","key":"Office","operation":"add","value":200,"note":"OCR missed the alcove"},
+		{"entity_type":"room","key":"Storage","value":0,"note":"Out of scope"},
+		{"entity_type":"room","key":"Lobby","value":300}
+	]}`
+
+	rec := serve(http.MethodPut, path, body, userID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var saved struct {
+		Adjustments []models.TakeoffAdjustment `json:"takeoff_adjustments"`
+		Orphaned    []models.TakeoffAdjustment `json:"orphaned_adjustments"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&saved); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(saved.Adjustments) != 3 || saved.Adjustments[1].Operation != models.TakeoffAdjustmentSet || saved.Adjustments[1].Field != "area" {
+		t.Errorf("saved adjustments = %+v, want three with defaults filled in", saved.Adjustments)
+	}
+	if len(saved.Orphaned) != 1 || saved.Orphaned[0].Key != "Lobby" {
+		t.Errorf("orphaned = %+v, want the lobby adjustment", saved.Orphaned)
+	}
+	if *blueprint.AnalysisData != analysis {
+		t.Error("expected the stored analysis left untouched")
+	}
+
+	getSummary := func() models.TakeoffSummary {
+		t.Helper()
+		rec := serve(http.MethodGet, "/blueprints/"+blueprint.ID.String()+"/takeoff-summary", "", userID)
+		var summary models.TakeoffSummary
+		if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&summary) != nil {
+			t.Fatalf("GET takeoff-summary: status = %d, body %s", rec.Code, rec.Body.String())
+		}
+		return summary
+	}
+	summary := getSummary()
+	if summary.TotalArea != 400 || summary.RoomCount != 1 {
+		t.Errorf("adjusted takeoff = %v SF over %d rooms, want 400 SF over 1", summary.TotalArea, summary.RoomCount)
+	}
+	if mark := summary.RoomBreakdown[0].Adjusted; mark == nil || mark.OriginalValue != 200 || mark.Note != "OCR missed the alcove" {
+		t.Errorf("office mark = %+v, want the original 200 SF and the note", mark)
+	}
+	if len(summary.Adjustments) != 2 || len(summary.OrphanedAdjustments) != 1 {
+		t.Errorf("takeoff adjustments = %+v, orphaned = %+v", summary.Adjustments, summary.OrphanedAdjustments)
+	}
+
+	// A re-analysis that renames the office orphans its adjustment
+	reanalysis := `{"rooms":[{"name":"Office 101","area":210},{"name":"Storage","area":80}]}`
+	blueprint.AnalysisData = &reanalysis
+	summary = getSummary()
+	if summary.TotalArea != 210 || len(summary.OrphanedAdjustments) != 2 {
+		t.Errorf("after re-analysis: %v SF with orphaned %+v, want 210 SF with the office and lobby orphaned", summary.TotalArea, summary.OrphanedAdjustments)
+	}
+
+	failures := []struct {
+		name string
+		body string
+		user uuid.UUID
+		want int
+	}{
+		{"negative set", `{"adjustments":[{"entity_type":"room","key":"Office","value":-1}]}`, userID, http.StatusBadRequest},
+		{"unknown entity", `{"adjustments":[{"entity_type":"wall","key":"North","value":1}]}`, userID, http.StatusBadRequest},
+		{"invalid body", `{`, userID, http.StatusBadRequest},
+		{"other user", body, uuid.New(), http.StatusNotFound},
+	}
+	for _, tc := range failures {
+		if rec := serve(http.MethodPut, path, tc.body, tc.user); rec.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+	if rec := serve(http.MethodPut, "/blueprints/"+uuid.NewString()+"/takeoff-adjustments", body, userID); rec.Code != http.StatusNotFound {
+		t.Errorf("missing blueprint: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestDeleteBlueprint(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	priced := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, S3Key: "projects/p/blueprints/priced/A-101.pdf"}
+	mistaken := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, S3Key: "projects/p/blueprints/mistaken/A-102.pdf"}
+	revisionKey := "projects/p/blueprints/mistaken/revisions/1/A-102.pdf"
+
+	blueprints := &fakeBlueprintStore{
+		blueprints:   map[uuid.UUID]*models.Blueprint{priced.ID: priced, mistaken.ID: mistaken},
+		revisionKeys: map[uuid.UUID][]string{mistaken.ID: {revisionKey}},
+	}
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, BlueprintIDs: []uuid.UUID{priced.ID}}
+	objects := &fakeObjectStore{
+		objects:    map[string][]byte{priced.S3Key: nil, mistaken.S3Key: nil, revisionKey: nil},
+		failDelete: map[string]bool{revisionKey: true},
+	}
+	h := &BlueprintHandlers{
+		projectRepo:   &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprintRepo: blueprints,
+		deleter:       services.NewBlueprintDeleter(&fakeBidStore{bids: []*models.Bid{bid}}, blueprints, objects),
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+
+	// A blueprint a bid was priced from is kept, and the bid named
+	rec := serveAsUser(router, userID, http.MethodDelete, "/blueprints/"+priced.ID.String(), "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("delete priced: status = %d, body %s; want 409", rec.Code, rec.Body.String())
+	}
+	var conflict DeleteBlueprintResponse
+	if err := json.NewDecoder(rec.Body).Decode(&conflict); err != nil || len(conflict.BidIDs) != 1 || conflict.BidIDs[0] != bid.ID {
+		t.Errorf("conflict = %+v (%v), want the blocking bid", conflict, err)
+	}
+	if _, ok := blueprints.blueprints[priced.ID]; !ok {
+		t.Error("priced blueprint was deleted")
+	}
+	if _, ok := objects.objects[priced.S3Key]; !ok {
+		t.Error("priced blueprint's file was deleted")
+	}
+
+	// Another user can't delete it
+	if rec := serveAsUser(router, uuid.New(), http.MethodDelete, "/blueprints/"+mistaken.ID.String(), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's delete: status = %d, want 404", rec.Code)
+	}
+
+	// The file is deleted even though its revision's can't be
+	rec = serveAsUser(router, userID, http.MethodDelete, "/blueprints/"+mistaken.ID.String(), "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status = %d, body %s; want 204", rec.Code, rec.Body.String())
+	}
+	if _, ok := blueprints.blueprints[mistaken.ID]; ok {
+		t.Error("blueprint was not deleted")
+	}
+	if _, ok := objects.objects[mistaken.S3Key]; ok {
+		t.Error("blueprint's file was not deleted")
+	}
+	if _, ok := objects.objects[revisionKey]; !ok {
+		t.Error("fake should have failed to delete the revision's file")
+	}
+
+	if rec := serveAsUser(router, userID, http.MethodDelete, "/blueprints/"+mistaken.ID.String(), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second delete: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestGetProjectTakeoffSummary_OverlappingRooms(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	newBlueprint := func(filename, analysis string) *models.Blueprint {
+		return &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: filename, AnalysisData: &analysis}
+	}
+	first := newBlueprint("floor-1.pdf", `{"rooms":[{"name":"Bedroom","area":140},{"name":"Bath","area":50}],"openings":[{"opening_type":"door","count":2}]}`)
+	second := newBlueprint("floor-2.pdf", `{"rooms":[{"name":"Bedroom","area":160}],"openings":[{"opening_type":"door","count":1}]}`)
+	unanalyzed := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "site.pdf"}
+	h := &BlueprintHandlers{
+		projectRepo: &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprintRepo: &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{
+			first.ID: first, second.ID: second, unanalyzed.ID: unanalyzed,
+		}},
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+
+	rec := serveAsUser(router, userID, http.MethodGet, "/projects/"+project.ID.String()+"/takeoff-summary", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var summary models.ProjectTakeoffSummary
+	if err := json.NewDecoder(rec.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if summary.BlueprintCount != 2 || summary.Takeoff.RoomCount != 3 || summary.Takeoff.TotalArea != 350 || summary.Takeoff.OpeningCounts["door"] != 3 {
+		t.Errorf("summary = %d blueprints, %d rooms, %v SF, %v; want 2 blueprints, 3 rooms, 350 SF and 3 doors",
+			summary.BlueprintCount, summary.Takeoff.RoomCount, summary.Takeoff.TotalArea, summary.Takeoff.OpeningCounts)
+	}
+	bedrooms := make(map[uuid.UUID]float64)
+	for _, room := range summary.Takeoff.RoomBreakdown {
+		if room.Name == "Bedroom" && room.SourceBlueprintID != nil {
+			bedrooms[*room.SourceBlueprintID] = room.Area
+		}
+	}
+	if bedrooms[first.ID] != 140 || bedrooms[second.ID] != 160 {
+		t.Errorf("bedrooms by blueprint = %v, want one from each floor", bedrooms)
+	}
+	if !strings.Contains(strings.Join(summary.Takeoff.Warnings, "\n"), `"Bedroom" appears on 2 blueprints`) {
+		t.Errorf("warnings = %v, want the shared bedroom flagged", summary.Takeoff.Warnings)
+	}
+}
+
+func TestGetBlueprintAnalysis_Normalized(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	analysis := `{"rooms":[{"name":"Kitchen","dimensions":"15x12","area":180}],"measurements":[{"measurement_type":"ceiling_height","value":96,"unit":"in"}]}`
+	blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "plans.pdf", AnalysisData: &analysis}
+	h := &BlueprintHandlers{
+		projectRepo:   &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprintRepo: &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}},
+	}
+	router := chi.NewRouter()
+	h.Routes(router)
+	get := func(t *testing.T, query string) models.AnalysisResult {
+		t.Helper()
+		rec := serveAsUser(router, userID, http.MethodGet, "/blueprints/"+blueprint.ID.String()+"/analysis"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+		}
+		var result models.AnalysisResult
+		if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return result
+	}
+
+	raw := get(t, "")
+	if raw.Measurements[0].Value != 96 || raw.Measurements[0].Normalized || raw.Rooms[0].WidthFeet != nil {
+		t.Errorf("raw analysis = %+v, want it as stored", raw)
+	}
+
+	normalized := get(t, "?normalized=true")
+	if m := normalized.Measurements[0]; m.Value != 8 || m.Unit != "ft" || !m.Normalized {
+		t.Errorf("normalized ceiling height = %+v, want 8 ft", m)
+	}
+	if room := normalized.Rooms[0]; !room.Normalized || room.WidthFeet == nil || *room.WidthFeet != 12 || *room.LengthFeet != 15 {
+		t.Errorf("normalized room = %+v, want 12 x 15 ft", room)
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// assertBulkEnvelope checks the invariants every bulk response keeps: the
+// counts match the item statuses, indexes are unique and failed or skipped
+// items say why
+func assertBulkEnvelope(t *testing.T, result models.BulkResult) {
+	t.Helper()
+	counts := make(map[models.BulkItemStatus]int)
+	indexes := make(map[int]bool)
+	for _, item := range result.Items {
+		counts[item.Status]++
+		if indexes[item.Index] {
+			t.Errorf("duplicate item index %d", item.Index)
+		}
+		indexes[item.Index] = true
+		if item.Status != models.BulkItemSucceeded && (item.ErrorCode == "" || item.Message == "") {
+			t.Errorf("%s item %q has no error code or message", item.Status, item.Key)
+		}
+	}
+	if result.Total != len(result.Items) {
+		t.Errorf("total = %d, but %d items", result.Total, len(result.Items))
+	}
+	if result.Succeeded != counts[models.BulkItemSucceeded] || result.Failed != counts[models.BulkItemFailed] || result.Skipped != counts[models.BulkItemSkipped] {
+		t.Errorf("counts %d/%d/%d succeeded/failed/skipped do not match items %v",
+			result.Succeeded, result.Failed, result.Skipped, counts)
+	}
+	if result.Succeeded+result.Failed+result.Skipped != result.Total {
+		t.Errorf("counts do not add up to total %d", result.Total)
+	}
+}
+
+func TestBulkStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(*models.BulkResult)
+		want  int
+	}{
+		{"empty", func(b *models.BulkResult) {}, http.StatusOK},
+		{"all succeeded", func(b *models.BulkResult) {
+			b.Succeed(0, "a", nil)
+			b.Succeed(1, "b", nil)
+		}, http.StatusOK},
+		{"only skipped", func(b *models.BulkResult) {
+			b.Skip(0, "a", "already_done", "a: already done")
+		}, http.StatusOK},
+		{"mixed", func(b *models.BulkResult) {
+			b.Succeed(0, "a", nil)
+			b.Fail(1, "b", "broken", "b: broken")
+		}, http.StatusMultiStatus},
+		{"nothing succeeded", func(b *models.BulkResult) {
+			b.Skip(0, "a", "already_done", "a: already done")
+			b.Fail(1, "b", "broken", "b: broken")
+		}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := models.NewBulkResult()
+			tt.build(&result)
+			assertBulkEnvelope(t, result)
+			if got := bulkStatus(result); got != tt.want {
+				t.Errorf("bulkStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeCostIntegration returns report from every sync and records the
+// providers synced
+type fakeCostIntegration struct {
+	report *services.SyncReport
+	synced []string
+}
+
+func (f *fakeCostIntegration) Sync(ctx context.Context, providerNames []string, region string) (*services.SyncReport, error) {
+	f.synced = append(f.synced, providerNames...)
+	return f.report, nil
+}
+
+func (f *fakeCostIntegration) SyncAll(ctx context.Context, region string) (*services.SyncReport, error) {
+	return nil, errors.New("not used")
+}
+
+func TestSyncCostDataResponse_IncludesReport(t *testing.T) {
+	report := &services.SyncReport{
+		Created: 4, Updated: 1, Failed: 1,
+		Providers: []services.ProviderSyncReport{
+			{Provider: "lowes", Created: 2, Updated: 1, Errors: []services.SyncItemError{}},
+			{Provider: "rsmeans", Created: 2, Failed: 1, Errors: []services.SyncItemError{
+				{DataSet: services.SyncDataSetMaterials, Item: "Drywall", Code: services.SyncErrorInvalidItem, Message: "Material price must be positive"},
+			}},
+		},
+	}
+	body, err := json.Marshal(SyncCostDataResponse{Provider: "all", Region: "california", SyncReport: report})
+	if err != nil {
+		t.Fatalf("failed to encode response: %v", err)
+	}
+
+	var decoded struct {
+		Region    string                        `json:"region"`
+		Created   int                           `json:"created"`
+		Failed    int                           `json:"failed"`
+		Providers []services.ProviderSyncReport `json:"providers"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if decoded.Region != "california" || decoded.Created != 4 || decoded.Failed != 1 || len(decoded.Providers) != 2 {
+		t.Errorf("response = %s, want the report's counts and providers at the top level", body)
+	}
+	if errs := decoded.Providers[1].Errors; len(errs) != 1 || errs[0].Item != "Drywall" || errs[0].Code != services.SyncErrorInvalidItem {
+		t.Errorf("rsmeans errors = %+v, want the invalid drywall", errs)
+	}
+	if got := syncStatus(report); got != http.StatusMultiStatus {
+		t.Errorf("syncStatus() = %d, want 207", got)
+	}
+}
+
+func TestSyncCostData_InvalidProvider(t *testing.T) {
+	service := &fakeCostIntegration{report: &services.SyncReport{}}
+	h := NewCostHandlers(&PricingSources{costDataService: fakeCostDataService{}}, service, events.Discard)
+	router := chi.NewRouter()
+	h.AdminRoutes(router)
+
+	rec := serveAsUser(router, uuid.New(), http.MethodPost, "/api/admin/sync-cost-data", `{"provider":"acme"}`)
+	if rec.Code != http.StatusBadRequest || len(service.synced) != 0 {
+		t.Errorf("status = %d, synced %v; want 400 and nothing synced", rec.Code, service.synced)
+	}
+}
+
+func TestSyncStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		report services.SyncReport
+		want   int
+	}{
+		{"nothing to sync", services.SyncReport{}, http.StatusOK},
+		{"all stored", services.SyncReport{Created: 2, Updated: 3}, http.StatusOK},
+		{"some failed", services.SyncReport{Updated: 3, Failed: 1}, http.StatusMultiStatus},
+		{"nothing stored", services.SyncReport{Failed: 2}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if got := syncStatus(&tt.report); got != tt.want {
+			t.Errorf("%s: syncStatus() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
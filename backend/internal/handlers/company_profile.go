@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// CompanyProfileHandlers manages the company name, contact details and logo
+// printed on a company's bid documents
+type CompanyProfileHandlers struct {
+	profileRepo CompanyProfileStore
+}
+
+func NewCompanyProfileHandlers(profileRepo CompanyProfileStore) *CompanyProfileHandlers {
+	return &CompanyProfileHandlers{profileRepo: profileRepo}
+}
+
+// Routes registers the company profile routes
+func (h *CompanyProfileHandlers) Routes(r chi.Router) {
+	r.Get("/api/company/profile", h.GetCompanyProfile)
+	r.Put("/api/company/profile", h.UpdateCompanyProfile)
+}
+
+// GetCompanyProfile returns the company's profile. Companies without one get
+// a 404, and their bids the default branding.
+func (h *CompanyProfileHandlers) GetCompanyProfile(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	profile, err := h.profileRepo.GetByUserID(r.Context(), *userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCompanyProfileNotFound) {
+			respondError(w, http.StatusNotFound, "Company profile not found")
+			return
+		}
+		slog.Error("Failed to get company profile", "error", err, "user_id", userID)
+		respondError(w, http.StatusInternalServerError, "Failed to get company profile")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, profile)
+}
+
+// UpdateCompanyProfile creates or replaces the company's profile. Bid PDFs
+// rendered from then on carry it.
+func (h *CompanyProfileHandlers) UpdateCompanyProfile(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var profile models.CompanyProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	profile.UserID = *userID
+	if err := services.NormalizeCompanyProfile(&profile); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := models.Now()
+	profile.CreatedAt = now
+	profile.UpdatedAt = now
+	if err := h.profileRepo.Save(r.Context(), &profile); err != nil {
+		slog.Error("Failed to save company profile",
+			"error", err,
+			"user_id", userID,
+			"correlation_id", getCorrelationID(r.Context()))
+		respondError(w, http.StatusInternalServerError, "Failed to save company profile")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, profile)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestCompanyProfile_GetAndUpdate(t *testing.T) {
+	userID := uuid.New()
+	profiles := &fakeCompanyProfileStore{}
+	router := chi.NewRouter()
+	NewCompanyProfileHandlers(profiles).Routes(router)
+
+	if rec := serveAsUser(router, userID, http.MethodGet, "/api/company/profile", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("get without a profile: status = %d, want 404", rec.Code)
+	}
+
+	body := `{"name": " Acme Builders ", "phone": "555-0100", "website": "  ", "license_number": "CA-999",
+		"logo_s3_key": "company-logos/` + userID.String() + `/logo.png"}`
+	rec := serveAsUser(router, userID, http.MethodPut, "/api/company/profile", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put: status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+
+	rec = serveAsUser(router, userID, http.MethodGet, "/api/company/profile", "")
+	var got models.CompanyProfile
+	if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&got) != nil {
+		t.Fatalf("get: status = %d, want the saved profile", rec.Code)
+	}
+	if got.UserID != userID || got.Name != "Acme Builders" || got.Website != nil || got.LicenseNumber == nil || *got.LicenseNumber != "CA-999" {
+		t.Errorf("profile = %+v, want the trimmed profile of the requesting user", got)
+	}
+
+	invalid := map[string]string{
+		"missing name":        `{"phone": "555-0100"}`,
+		"bad email":           `{"name": "Acme", "email": "acme.example.com"}`,
+		"another user's logo": `{"name": "Acme", "logo_s3_key": "company-logos/` + uuid.New().String() + `/logo.png"}`,
+		"blueprint as logo":   `{"name": "Acme", "logo_s3_key": "blueprints/plans.pdf"}`,
+		"unsupported logo":    `{"name": "Acme", "logo_s3_key": "company-logos/` + userID.String() + `/logo.svg"}`,
+		"malformed body":      `{"name":`,
+	}
+	for name, body := range invalid {
+		if rec := serveAsUser(router, userID, http.MethodPut, "/api/company/profile", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, rec.Code)
+		}
+	}
+	if profiles.profiles[userID].Name != "Acme Builders" {
+		t.Errorf("rejected updates changed the profile: %+v", profiles.profiles[userID])
+	}
+}
+
+func TestGetBidPDF_CompanyProfile(t *testing.T) {
+	var logo bytes.Buffer
+	if err := png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("failed to encode logo: %v", err)
+	}
+
+	newPDFBid := func(t *testing.T, profile *models.CompanyProfile) (*models.Bid, *models.Project, *fakeObjectStore, chi.Router) {
+		t.Helper()
+		userID := uuid.New()
+		project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Office Remodel"}
+		bidData := `{"scope_of_work": "Remodel", "line_items": [{"description": "Framing", "trade": "framing", "quantity": 1, "unit": "lot", "unit_cost": 1000, "total": 1000}], "total_price": 1200}`
+		bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusDraft, Version: 1, BidData: &bidData}
+		objects := &fakeObjectStore{}
+		profiles := &fakeCompanyProfileStore{}
+		if profile != nil {
+			profile.UserID = userID
+			logoKey := services.CompanyLogoKeyPrefix(userID) + "logo.png"
+			profile.LogoS3Key = &logoKey
+			objects.objects = map[string][]byte{logoKey: logo.Bytes()}
+			profiles.profiles = map[uuid.UUID]*models.CompanyProfile{userID: profile}
+		}
+
+		projects := &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}}
+		bids := &fakeBidStore{bids: []*models.Bid{bid}}
+		users := &fakeUserStore{users: map[uuid.UUID]*models.User{userID: {ID: userID}}}
+		router := chi.NewRouter()
+		(&BidHandlers{
+			projectRepo: projects,
+			bidRepo:     bids,
+			userRepo:    users,
+			profileRepo: profiles,
+			jobRepo:     &fakeJobStore{},
+			pdfGenerator: services.NewBidPDFGenerator(bids, projects, &fakeBlueprintStore{}, users, profiles,
+				services.NewBidPDFPublisher(objects, nil, 0), services.NewBlueprintPageRenderer(objects, nil), objects),
+		}).Routes(router)
+		return bid, project, objects, router
+	}
+	logosBefore, _ := filepath.Glob(filepath.Join(os.TempDir(), "company-logo-*"))
+
+	t.Run("without a profile", func(t *testing.T) {
+		bid, project, objects, router := newPDFBid(t, nil)
+		rec := serveAsUser(router, project.UserID, http.MethodGet, "/bids/"+bid.ID.String()+"/pdf", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+		}
+		if bid.PDFHash == nil || *bid.PDFHash != services.BidPDFHash(bid, project.Name, nil) || len(objects.objects) != 1 {
+			t.Errorf("PDF hash = %v with %d objects, want one PDF with the default branding", bid.PDFHash, len(objects.objects))
+		}
+	})
+
+	t.Run("with a profile", func(t *testing.T) {
+		phone := "555-0100"
+		profile := &models.CompanyProfile{Name: "Acme Builders", Phone: &phone}
+		bid, project, objects, router := newPDFBid(t, profile)
+		rec := serveAsUser(router, project.UserID, http.MethodGet, "/bids/"+bid.ID.String()+"/pdf", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+		}
+		// The logo is identified by its key, not the temporary file it was rendered from
+		branded := &services.PDFOptions{CompanyInfo: services.CompanyProfileInfo(profile), IncludeLogo: true, LogoPath: "elsewhere.png"}
+		if bid.PDFHash == nil || *bid.PDFHash != services.BidPDFHash(bid, project.Name, branded) {
+			t.Errorf("PDF hash = %v, want the PDF rendered with the company profile and logo", bid.PDFHash)
+		}
+		if len(objects.objects) != 2 {
+			t.Errorf("stored %d objects, want the logo and one PDF", len(objects.objects))
+		}
+	})
+
+	logosAfter, _ := filepath.Glob(filepath.Join(os.TempDir(), "company-logo-*"))
+	if len(logosAfter) > len(logosBefore) {
+		t.Errorf("downloaded logos left behind: %v", logosAfter)
+	}
+}
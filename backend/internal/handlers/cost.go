@@ -2,49 +2,97 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"time"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/trades"
 )
 
-// GetMaterials returns all materials, optionally filtered by category and region
-func (h *Handler) GetMaterials(w http.ResponseWriter, r *http.Request) {
-	category := r.URL.Query().Get("category")
-	region := r.URL.Query().Get("region")
+// CostHandlers serves the cost database, company pricing overrides and cost
+// data syncs
+type CostHandlers struct {
+	*PricingSources
+	costIntegrationService CostIntegrationServiceInterface
+	events                 events.Publisher
+}
+
+func NewCostHandlers(pricing *PricingSources, costIntegrationService CostIntegrationServiceInterface, publisher events.Publisher) *CostHandlers {
+	return &CostHandlers{PricingSources: pricing, costIntegrationService: costIntegrationService, events: publisher}
+}
+
+// Routes registers the cost database and pricing override routes
+func (h *CostHandlers) Routes(r chi.Router) {
+	// Cost database routes
+	r.Get("/api/materials", h.GetMaterials)
+	r.Get("/api/materials/effective", h.GetEffectiveMaterials)
+	r.Get("/api/labor-rates", h.GetLaborRates)
+	r.Get("/api/labor-rates/effective", h.GetEffectiveLaborRates)
+	r.Get("/api/regional-adjustments", h.GetRegionalAdjustments)
+
+	// Company pricing override routes
+	r.Get("/api/company/pricing-overrides", h.GetCompanyPricingOverrides)
+	r.Get("/api/company/pricing-overrides/validate", h.ValidateCompanyPricingOverrides)
+	r.Post("/api/company/pricing-overrides", h.CreateCompanyPricingOverride)
+	r.Put("/api/company/pricing-overrides/{id}", h.UpdateCompanyPricingOverride)
+	r.Delete("/api/company/pricing-overrides/{id}", h.DeleteCompanyPricingOverride)
+}
+
+// AdminRoutes registers the cost routes for admins; mount them behind
+// middleware.RequireRole(models.UserRoleAdmin)
+func (h *CostHandlers) AdminRoutes(r chi.Router) {
+	r.Post("/api/admin/sync-cost-data", h.SyncCostData)
+}
 
-	var categoryPtr, regionPtr *string
-	if category != "" {
-		categoryPtr = &category
+// GetMaterials returns one page of materials, optionally filtered by category,
+// region and a name substring (q)
+func (h *CostHandlers) GetMaterials(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var filter models.MaterialListFilter
+	if category := query.Get("category"); category != "" {
+		filter.Category = &category
 	}
-	if region != "" {
-		regionPtr = &region
+	if region := query.Get("region"); region != "" {
+		filter.Region = &region
+	}
+	if q := strings.TrimSpace(query.Get("q")); q != "" {
+		filter.Query = &q
+	}
+	var err error
+	if filter.Limit, filter.Offset, err = parsePagination(r); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
 	}
 
 	// Use cached service if available, otherwise fall back to repository
-	var materials []models.MaterialCost
-	var err error
-	
+	var page *models.ListPage[models.MaterialCost]
 	if h.costDataService != nil {
-		materials, err = h.costDataService.GetMaterials(r.Context(), categoryPtr, regionPtr)
+		page, err = h.costDataService.ListMaterials(r.Context(), filter)
 	} else {
-		materials, err = h.materialRepo.GetAll(r.Context(), categoryPtr, regionPtr)
+		var materials []models.MaterialCost
+		var total int
+		materials, total, err = h.materialRepo.List(r.Context(), filter)
+		page = &models.ListPage[models.MaterialCost]{Items: materials, Total: total, Limit: filter.Limit, Offset: filter.Offset}
 	}
-	
+
 	if err != nil {
 		slog.Error("Failed to get materials", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get materials")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get materials")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, materials)
+	respondJSON(w, http.StatusOK, page)
 }
 
 // GetLaborRates returns all labor rates, optionally filtered by trade and region
-func (h *Handler) GetLaborRates(w http.ResponseWriter, r *http.Request) {
+func (h *CostHandlers) GetLaborRates(w http.ResponseWriter, r *http.Request) {
 	trade := r.URL.Query().Get("trade")
 	region := r.URL.Query().Get("region")
 
@@ -68,7 +116,55 @@ func (h *Handler) GetLaborRates(w http.ResponseWriter, r *http.Request) {
 	
 	if err != nil {
 		slog.Error("Failed to get labor rates", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get labor rates")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get labor rates")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rates)
+}
+
+// GetEffectiveMaterials returns the material prices the authenticated user's
+// pricing summaries use in an optional region, after the regional factor and
+// company overrides, with the parts of each calculation
+func (h *CostHandlers) GetEffectiveMaterials(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var region *string
+	if value := r.URL.Query().Get("region"); value != "" {
+		region = &value
+	}
+	prices, err := h.enhancedPricingService().EffectiveMaterialPrices(r.Context(), userID, region)
+	if err != nil {
+		slog.Error("Failed to resolve material prices", "user_id", userID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get effective material prices")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, prices)
+}
+
+// GetEffectiveLaborRates returns the labor rates the authenticated user's
+// pricing summaries use in an optional region, with the parts of each
+// calculation
+func (h *CostHandlers) GetEffectiveLaborRates(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	var region *string
+	if value := r.URL.Query().Get("region"); value != "" {
+		region = &value
+	}
+	rates, err := h.enhancedPricingService().EffectiveLaborRates(r.Context(), userID, region)
+	if err != nil {
+		slog.Error("Failed to resolve labor rates", "user_id", userID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get effective labor rates")
 		return
 	}
 
@@ -76,11 +172,11 @@ func (h *Handler) GetLaborRates(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetRegionalAdjustments returns all regional adjustments
-func (h *Handler) GetRegionalAdjustments(w http.ResponseWriter, r *http.Request) {
+func (h *CostHandlers) GetRegionalAdjustments(w http.ResponseWriter, r *http.Request) {
 	adjustments, err := h.regionalRepo.GetAll(r.Context())
 	if err != nil {
 		slog.Error("Failed to get regional adjustments", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get regional adjustments")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get regional adjustments")
 		return
 	}
 
@@ -88,19 +184,45 @@ func (h *Handler) GetRegionalAdjustments(w http.ResponseWriter, r *http.Request)
 }
 
 // GetCompanyPricingOverrides returns all pricing overrides for the authenticated user
-func (h *Handler) GetCompanyPricingOverrides(w http.ResponseWriter, r *http.Request) {
+func (h *CostHandlers) GetCompanyPricingOverrides(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("user_id").(uuid.UUID)
 
 	overrides, err := h.companyOverrideRepo.GetByUserID(r.Context(), userID)
 	if err != nil {
 		slog.Error("Failed to get pricing overrides", "user_id", userID, "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get pricing overrides")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get pricing overrides")
 		return
 	}
 
 	respondJSON(w, http.StatusOK, overrides)
 }
 
+// ValidateCompanyPricingOverrides reports the authenticated user's overrides
+// whose item keys no longer match a material category or labor trade
+func (h *CostHandlers) ValidateCompanyPricingOverrides(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondAPIError(w, http.StatusUnauthorized, CodeUnauthenticated, "Unauthorized")
+		return
+	}
+
+	overrides, err := h.companyOverrideRepo.GetByUserID(r.Context(), *userID)
+	if err != nil {
+		slog.Error("Failed to get pricing overrides", "user_id", userID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get pricing overrides")
+		return
+	}
+
+	report, err := h.enhancedPricingService().OverrideValidator(h.companyOverrideRepo).Validate(r.Context(), overrides)
+	if err != nil {
+		slog.Error("Failed to validate pricing overrides", "user_id", userID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to validate pricing overrides")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
 // CreateCompanyPricingOverrideRequest represents a request to create a pricing override
 type CreateCompanyPricingOverrideRequest struct {
 	OverrideType  string  `json:"override_type"`
@@ -111,35 +233,30 @@ type CreateCompanyPricingOverrideRequest struct {
 }
 
 // CreateCompanyPricingOverride creates a new pricing override for the authenticated user
-func (h *Handler) CreateCompanyPricingOverride(w http.ResponseWriter, r *http.Request) {
+func (h *CostHandlers) CreateCompanyPricingOverride(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("user_id").(uuid.UUID)
 
 	var req CreateCompanyPricingOverrideRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
-	// Validate override type
-	validTypes := map[string]bool{
-		"material":      true,
-		"labor":         true,
-		"overhead":      true,
-		"profit_margin": true,
-	}
-	if !validTypes[req.OverrideType] {
-		respondError(w, http.StatusBadRequest, "Invalid override type")
+	itemKey, err := validatePricingOverride(req.OverrideType, req.ItemKey, req.OverrideValue, req.IsPercentage)
+	if err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
 		return
 	}
+	req.ItemKey = itemKey
 
 	// Check if override already exists
 	existing, err := h.companyOverrideRepo.GetByUserIDTypeAndKey(r.Context(), userID, req.OverrideType, req.ItemKey)
 	if err == nil && existing != nil {
-		respondError(w, http.StatusConflict, "Override already exists for this item")
+		respondAPIError(w, http.StatusConflict, CodeOverrideExists, "Override already exists for this item")
 		return
 	}
 
-	now := time.Now()
+	now := models.Now()
 	override := &models.CompanyPricingOverride{
 		ID:            uuid.New(),
 		UserID:        userID,
@@ -154,13 +271,62 @@ func (h *Handler) CreateCompanyPricingOverride(w http.ResponseWriter, r *http.Re
 
 	if err := h.companyOverrideRepo.Create(r.Context(), override); err != nil {
 		slog.Error("Failed to create pricing override", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to create pricing override")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to create pricing override")
 		return
 	}
+	h.publishOverrideChanged(r, events.OverrideCreated, override)
 
 	respondJSON(w, http.StatusCreated, override)
 }
 
+// validPricingOverrideTypes are the override types pricing applies
+var validPricingOverrideTypes = map[string]bool{
+	"material":              true,
+	"labor":                 true,
+	"overhead":              true,
+	"profit_margin":         true,
+	"trade_minimum":         true,
+	"project_type_modifier": true,
+	"productivity":          true,
+}
+
+// validatePricingOverride checks a company or project override's type, key
+// and value and returns the key it is stored under
+func validatePricingOverride(overrideType, itemKey string, value float64, isPercentage bool) (string, error) {
+	if !validPricingOverrideTypes[overrideType] {
+		return "", errors.New("Invalid override type")
+	}
+
+	// Project type modifiers are keyed "<project_type>.<field>"
+	if overrideType == "project_type_modifier" {
+		if _, _, err := services.ParseProjectTypeModifierKey(itemKey); err != nil {
+			return "", err
+		}
+	}
+
+	// Productivity overrides replace or scale a default rate, which must stay
+	// above zero hours of work per unit
+	if overrideType == "productivity" {
+		if _, known := services.DefaultProductivityRates()[itemKey]; !known {
+			return "", fmt.Errorf("Unknown productivity rate %q", itemKey)
+		}
+		if !isPercentage && value <= 0 {
+			return "", errors.New("Productivity rate must be greater than zero")
+		}
+	}
+
+	// Labor and minimum charge overrides are keyed by canonical trade so they
+	// match labor rates and trade subtotals
+	if overrideType == "labor" || overrideType == "trade_minimum" {
+		trade, known := trades.Normalize(itemKey)
+		if !known || strings.TrimSpace(itemKey) == "" {
+			return "", fmt.Errorf("Unknown trade %q", itemKey)
+		}
+		itemKey = trade
+	}
+	return itemKey, nil
+}
+
 // UpdateCompanyPricingOverrideRequest represents a request to update a pricing override
 type UpdateCompanyPricingOverrideRequest struct {
 	OverrideValue float64 `json:"override_value"`
@@ -169,30 +335,30 @@ type UpdateCompanyPricingOverrideRequest struct {
 }
 
 // UpdateCompanyPricingOverride updates a pricing override
-func (h *Handler) UpdateCompanyPricingOverride(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(uuid.UUID)
-	overrideID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *CostHandlers) UpdateCompanyPricingOverride(w http.ResponseWriter, r *http.Request) {
+	overrideID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid override ID")
+		respondInvalidID(w)
 		return
 	}
+	userID := r.Context().Value("user_id").(uuid.UUID)
 
 	// Get existing override
 	override, err := h.companyOverrideRepo.GetByID(r.Context(), overrideID)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Override not found")
+		respondNotFound(w)
 		return
 	}
 
 	// Verify ownership
 	if override.UserID != userID {
-		respondError(w, http.StatusForbidden, "You don't have permission to update this override")
+		respondNotFound(w)
 		return
 	}
 
 	var req UpdateCompanyPricingOverrideRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
@@ -200,59 +366,104 @@ func (h *Handler) UpdateCompanyPricingOverride(w http.ResponseWriter, r *http.Re
 	override.OverrideValue = req.OverrideValue
 	override.IsPercentage = req.IsPercentage
 	override.Notes = req.Notes
-	override.UpdatedAt = time.Now()
+	override.UpdatedAt = models.Now()
 
 	if err := h.companyOverrideRepo.Update(r.Context(), override); err != nil {
-		slog.Error("Failed to update pricing override", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to update pricing override")
+		if !isRepositoryError(err) {
+			slog.Error("Failed to update pricing override", "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to update pricing override")
 		return
 	}
+	h.publishOverrideChanged(r, events.OverrideUpdated, override)
 
 	respondJSON(w, http.StatusOK, override)
 }
 
 // DeleteCompanyPricingOverride deletes a pricing override
-func (h *Handler) DeleteCompanyPricingOverride(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(uuid.UUID)
-	overrideID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *CostHandlers) DeleteCompanyPricingOverride(w http.ResponseWriter, r *http.Request) {
+	overrideID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid override ID")
+		respondInvalidID(w)
 		return
 	}
+	userID := r.Context().Value("user_id").(uuid.UUID)
 
 	// Get existing override
 	override, err := h.companyOverrideRepo.GetByID(r.Context(), overrideID)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Override not found")
+		respondNotFound(w)
 		return
 	}
 
 	// Verify ownership
 	if override.UserID != userID {
-		respondError(w, http.StatusForbidden, "You don't have permission to delete this override")
+		respondNotFound(w)
 		return
 	}
 
 	if err := h.companyOverrideRepo.Delete(r.Context(), overrideID); err != nil {
-		slog.Error("Failed to delete pricing override", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to delete pricing override")
+		if !isRepositoryError(err) {
+			slog.Error("Failed to delete pricing override", "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to delete pricing override")
 		return
 	}
+	h.publishOverrideChanged(r, events.OverrideDeleted, override)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *CostHandlers) publishOverrideChanged(r *http.Request, change events.OverrideChange, override *models.CompanyPricingOverride) {
+	h.events.Publish(r.Context(), events.OverrideChanged{
+		Change:        change,
+		Override:      *override,
+		CorrelationID: getCorrelationID(r.Context()),
+	})
+}
+
 // SyncCostDataRequest represents a request to sync cost data from external providers
 type SyncCostDataRequest struct {
 	Provider string `json:"provider"`
 	Region   string `json:"region"`
 }
 
-// SyncCostData syncs cost data from external providers (admin only)
-func (h *Handler) SyncCostData(w http.ResponseWriter, r *http.Request) {
+// SyncCostDataResponse reports what each provider's sync created, updated
+// and failed to store, with an error for each failed item or data set
+type SyncCostDataResponse struct {
+	Provider               string                    `json:"provider"`
+	Region                 string                    `json:"region"`
+	NewlyOrphanedOverrides []models.OrphanedOverride `json:"newly_orphaned_overrides"`
+	// MaterialConflicts are the region's material categories priced by more
+	// than one provider after the sync, with the provider pricing uses
+	MaterialConflicts []services.MaterialConflict `json:"material_conflicts"`
+	*services.SyncReport
+}
+
+// syncProviders are the cost data providers a sync can name when the
+// integration service cannot list its own; "all" syncs each
+var syncProviders = []string{"rsmeans", "homedepot", "lowes"}
+
+// costProviderLister is implemented by integration services that report the
+// providers registered with them, such as one behind a feature flag
+type costProviderLister interface {
+	ProviderNames() []string
+}
+
+// syncProviderNames returns the providers a sync can name
+func (h *CostHandlers) syncProviderNames() []string {
+	if lister, ok := h.costIntegrationService.(costProviderLister); ok {
+		return lister.ProviderNames()
+	}
+	return syncProviders
+}
+
+// SyncCostData syncs cost data from external providers (admin only). Items
+// a provider gets wrong are reported and the rest are still stored.
+func (h *CostHandlers) SyncCostData(w http.ResponseWriter, r *http.Request) {
 	var req SyncCostDataRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
@@ -260,36 +471,56 @@ func (h *Handler) SyncCostData(w http.ResponseWriter, r *http.Request) {
 		req.Region = "national"
 	}
 
-	// Sync based on provider
-	switch req.Provider {
-	case "all":
-		if err := h.costIntegrationService.SyncAll(r.Context(), req.Region); err != nil {
-			slog.Error("Failed to sync all cost data", "error", err)
-			respondError(w, http.StatusInternalServerError, "Failed to sync cost data")
-			return
-		}
-	case "rsmeans", "homedepot", "lowes":
-		if err := h.costIntegrationService.SyncMaterials(r.Context(), req.Provider, req.Region); err != nil {
-			slog.Error("Failed to sync materials", "provider", req.Provider, "error", err)
-			respondError(w, http.StatusInternalServerError, "Failed to sync materials")
-			return
+	providers := h.syncProviderNames()
+	if req.Provider != "all" {
+		known := false
+		for _, provider := range providers {
+			known = known || provider == req.Provider
 		}
-		if err := h.costIntegrationService.SyncLaborRates(r.Context(), req.Provider, req.Region); err != nil {
-			slog.Error("Failed to sync labor rates", "provider", req.Provider, "error", err)
-			respondError(w, http.StatusInternalServerError, "Failed to sync labor rates")
+		if !known {
+			respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, "Invalid provider")
 			return
 		}
-		if err := h.costIntegrationService.SyncRegionalAdjustment(r.Context(), req.Provider, req.Region); err != nil {
-			slog.Error("Failed to sync regional adjustment", "provider", req.Provider, "error", err)
-			respondError(w, http.StatusInternalServerError, "Failed to sync regional adjustment")
-			return
-		}
-	default:
-		respondError(w, http.StatusBadRequest, "Invalid provider")
+		providers = []string{req.Provider}
+	}
+
+	// Overrides orphaned by this sync are reported afterwards
+	pricing := h.enhancedPricingService()
+	overrideValidator := pricing.OverrideValidator(h.companyOverrideRepo)
+	beforeSync := overrideValidator.Snapshot(r.Context())
+
+	report, err := h.costIntegrationService.Sync(r.Context(), providers, req.Region)
+	if err != nil {
+		slog.Error("Failed to sync cost data", "provider", req.Provider, "region", req.Region, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to sync cost data")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{
-		"message": "Cost data synced successfully",
-	})
+	response := SyncCostDataResponse{
+		Provider:          req.Provider,
+		Region:            req.Region,
+		MaterialConflicts: []services.MaterialConflict{},
+		SyncReport:        report,
+	}
+	response.NewlyOrphanedOverrides = overrideValidator.ReportNewlyOrphaned(r.Context(), beforeSync)
+	if conflicts, err := pricing.MaterialConflicts(r.Context(), &req.Region); err != nil {
+		slog.Warn("Failed to report material price conflicts", "region", req.Region, "error", err)
+	} else {
+		response.MaterialConflicts = conflicts
+	}
+
+	respondJSON(w, syncStatus(report), response)
+}
+
+// syncStatus is 200 when everything synced, 207 when some items failed and
+// 400 when nothing was stored, as bulkStatus is for bulk results
+func syncStatus(report *services.SyncReport) int {
+	switch {
+	case report.Failed == 0:
+		return http.StatusOK
+	case report.Created+report.Updated == 0:
+		return http.StatusBadRequest
+	default:
+		return http.StatusMultiStatus
+	}
 }
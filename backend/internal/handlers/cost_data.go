@@ -0,0 +1,406 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/trades"
+)
+
+// Column widths of the cost database
+const (
+	maxCostItemNameLength = 255
+	maxCostUnitLength     = 50
+	maxCostRegionLength   = 100
+)
+
+// CostDataHandlers lets admins add, edit and remove materials and labor rates
+// by hand, such as a specialty stone a company buys directly. Every change is
+// published, which drops the cost caches and records the audit event.
+type CostDataHandlers struct {
+	materialRepo  MaterialStore
+	laborRateRepo LaborRateStore
+	validator     *services.OverrideValidator
+	events        events.Publisher
+}
+
+// NewCostDataHandlers creates the handlers. validator supplies the material
+// categories pricing knows and the company overrides a delete would orphan.
+func NewCostDataHandlers(materialRepo MaterialStore, laborRateRepo LaborRateStore, validator *services.OverrideValidator, publisher events.Publisher) *CostDataHandlers {
+	return &CostDataHandlers{materialRepo: materialRepo, laborRateRepo: laborRateRepo, validator: validator, events: publisher}
+}
+
+// AdminRoutes registers the cost database edit routes; mount them behind
+// middleware.RequireRole(models.UserRoleAdmin)
+func (h *CostDataHandlers) AdminRoutes(r chi.Router) {
+	r.Post("/api/materials", h.CreateMaterial)
+	r.Put("/api/materials/{id}", h.UpdateMaterial)
+	r.Delete("/api/materials/{id}", h.DeleteMaterial)
+	r.Post("/api/labor-rates", h.CreateLaborRate)
+	r.Put("/api/labor-rates/{id}", h.UpdateLaborRate)
+	r.Delete("/api/labor-rates/{id}", h.DeleteLaborRate)
+}
+
+// MaterialRequest is the body of a material create or update
+type MaterialRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Category    string  `json:"category"`
+	Unit        string  `json:"unit"`
+	BasePrice   float64 `json:"base_price"`
+	Region      *string `json:"region"`
+}
+
+// LaborRateRequest is the body of a labor rate create or update
+type LaborRateRequest struct {
+	Trade       string  `json:"trade"`
+	Description *string `json:"description"`
+	HourlyRate  float64 `json:"hourly_rate"`
+	Region      *string `json:"region"`
+}
+
+// validateMaterial trims a material request and checks it names one of the
+// known categories, a unit and a price
+func validateMaterial(req *MaterialRequest, categories map[string]bool) error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Category = strings.ToLower(strings.TrimSpace(req.Category))
+	req.Unit = strings.TrimSpace(req.Unit)
+	req.Region = trimmedOrNil(req.Region)
+
+	if req.Name == "" || len(req.Name) > maxCostItemNameLength {
+		return fmt.Errorf("Name is required and must be at most %d characters", maxCostItemNameLength)
+	}
+	if !categories[req.Category] {
+		return fmt.Errorf("Unknown material category %q", req.Category)
+	}
+	if req.Unit == "" || len(req.Unit) > maxCostUnitLength {
+		return fmt.Errorf("Unit is required and must be at most %d characters", maxCostUnitLength)
+	}
+	if req.BasePrice <= 0 {
+		return errors.New("Base price must be greater than zero")
+	}
+	return validateCostRegion(req.Region)
+}
+
+// validateLaborRate trims a labor rate request, canonicalizes its trade so it
+// matches labor overrides and trade subtotals, and checks it has a rate
+func validateLaborRate(req *LaborRateRequest) error {
+	req.Region = trimmedOrNil(req.Region)
+
+	trade, known := trades.Normalize(req.Trade)
+	if !known || strings.TrimSpace(req.Trade) == "" {
+		return fmt.Errorf("Unknown trade %q", req.Trade)
+	}
+	req.Trade = trade
+	if req.HourlyRate <= 0 {
+		return errors.New("Hourly rate must be greater than zero")
+	}
+	return validateCostRegion(req.Region)
+}
+
+func validateCostRegion(region *string) error {
+	if region != nil && len(*region) > maxCostRegionLength {
+		return fmt.Errorf("Region must be at most %d characters", maxCostRegionLength)
+	}
+	return nil
+}
+
+// validMaterial validates a material request against the categories pricing
+// knows, from its defaults and the cost database, and writes the error
+// response when it is invalid
+func (h *CostDataHandlers) validMaterial(w http.ResponseWriter, r *http.Request, req *MaterialRequest) bool {
+	keys, err := h.validator.CurrentKeys(r.Context())
+	if err != nil {
+		slog.Error("Failed to load material categories", "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to load material categories")
+		return false
+	}
+	if err := validateMaterial(req, keys.Materials); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return false
+	}
+	return true
+}
+
+// CreateMaterial adds a material by hand (admin only)
+func (h *CostDataHandlers) CreateMaterial(w http.ResponseWriter, r *http.Request) {
+	var req MaterialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if !h.validMaterial(w, r, &req) {
+		return
+	}
+
+	now := models.Now()
+	material := &models.MaterialCost{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+		Category:    req.Category,
+		Unit:        req.Unit,
+		BasePrice:   req.BasePrice,
+		Source:      models.CostSourceManual,
+		Region:      req.Region,
+		CreatedBy:   requestUserID(r),
+		LastUpdated: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.materialRepo.Create(r.Context(), material); err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to create material", "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to create material")
+		return
+	}
+	h.publishMaterialChanged(r, events.CostDataCreated, material)
+
+	respondJSON(w, http.StatusCreated, material)
+}
+
+// UpdateMaterial replaces a material's fields (admin only). An edited
+// material is manual from then on, whichever provider stored it.
+func (h *CostDataHandlers) UpdateMaterial(w http.ResponseWriter, r *http.Request) {
+	materialID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req MaterialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if !h.validMaterial(w, r, &req) {
+		return
+	}
+
+	material, err := h.materialRepo.GetByID(r.Context(), materialID)
+	if err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to get material", "material_id", materialID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to get material")
+		return
+	}
+
+	now := models.Now()
+	material.Name = req.Name
+	material.Description = req.Description
+	material.Category = req.Category
+	material.Unit = req.Unit
+	material.BasePrice = req.BasePrice
+	material.Region = req.Region
+	material.Source, material.SourceID = models.CostSourceManual, nil
+	material.LastUpdated, material.UpdatedAt = now, now
+	if err := h.materialRepo.Update(r.Context(), material); err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to update material", "material_id", materialID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to update material")
+		return
+	}
+	h.publishMaterialChanged(r, events.CostDataUpdated, material)
+
+	respondJSON(w, http.StatusOK, material)
+}
+
+// DeleteMaterial removes a material (admin only). A material whose category
+// company overrides depend on is kept, with a 409 listing the overrides.
+func (h *CostDataHandlers) DeleteMaterial(w http.ResponseWriter, r *http.Request) {
+	materialID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	material, err := h.materialRepo.GetByID(r.Context(), materialID)
+	if err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to get material", "material_id", materialID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to get material")
+		return
+	}
+	if !h.checkNotReferenced(w, r, materialID, "material") {
+		return
+	}
+
+	if err := h.materialRepo.Delete(r.Context(), materialID); err != nil {
+		slog.Error("Failed to delete material", "material_id", materialID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to delete material")
+		return
+	}
+	h.publishMaterialChanged(r, events.CostDataDeleted, material)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateLaborRate adds a labor rate by hand (admin only)
+func (h *CostDataHandlers) CreateLaborRate(w http.ResponseWriter, r *http.Request) {
+	var req LaborRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if err := validateLaborRate(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	now := models.Now()
+	rate := &models.LaborRate{
+		ID:          uuid.New(),
+		Trade:       req.Trade,
+		Description: req.Description,
+		HourlyRate:  req.HourlyRate,
+		Source:      models.CostSourceManual,
+		Region:      req.Region,
+		CreatedBy:   requestUserID(r),
+		LastUpdated: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.laborRateRepo.Create(r.Context(), rate); err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to create labor rate", "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to create labor rate")
+		return
+	}
+	h.publishLaborRateChanged(r, events.CostDataCreated, rate)
+
+	respondJSON(w, http.StatusCreated, rate)
+}
+
+// UpdateLaborRate replaces a labor rate's fields (admin only). An edited
+// rate is manual from then on, whichever provider stored it.
+func (h *CostDataHandlers) UpdateLaborRate(w http.ResponseWriter, r *http.Request) {
+	rateID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req LaborRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if err := validateLaborRate(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	rate, err := h.laborRateRepo.GetByID(r.Context(), rateID)
+	if err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to get labor rate", "labor_rate_id", rateID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to get labor rate")
+		return
+	}
+
+	now := models.Now()
+	rate.Trade = req.Trade
+	rate.Description = req.Description
+	rate.HourlyRate = req.HourlyRate
+	rate.Region = req.Region
+	rate.Source, rate.SourceID = models.CostSourceManual, nil
+	rate.LastUpdated, rate.UpdatedAt = now, now
+	if err := h.laborRateRepo.Update(r.Context(), rate); err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to update labor rate", "labor_rate_id", rateID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to update labor rate")
+		return
+	}
+	h.publishLaborRateChanged(r, events.CostDataUpdated, rate)
+
+	respondJSON(w, http.StatusOK, rate)
+}
+
+// DeleteLaborRate removes a labor rate (admin only). A rate whose trade
+// company overrides depend on is kept, with a 409 listing the overrides.
+func (h *CostDataHandlers) DeleteLaborRate(w http.ResponseWriter, r *http.Request) {
+	rateID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	rate, err := h.laborRateRepo.GetByID(r.Context(), rateID)
+	if err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to get labor rate", "labor_rate_id", rateID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to get labor rate")
+		return
+	}
+	if !h.checkNotReferenced(w, r, rateID, "labor rate") {
+		return
+	}
+
+	if err := h.laborRateRepo.Delete(r.Context(), rateID); err != nil {
+		slog.Error("Failed to delete labor rate", "labor_rate_id", rateID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to delete labor rate")
+		return
+	}
+	h.publishLaborRateChanged(r, events.CostDataDeleted, rate)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkNotReferenced responds 409 with the IDs of the company overrides that
+// removing the material or labor rate would orphan, and returns false, when
+// there are any
+func (h *CostDataHandlers) checkNotReferenced(w http.ResponseWriter, r *http.Request, id uuid.UUID, kind string) bool {
+	orphaned, err := h.validator.OrphanedByRemoval(r.Context(), id)
+	if err != nil {
+		slog.Error("Failed to check company overrides before delete", "id", id, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to check pricing overrides")
+		return false
+	}
+	if len(orphaned) == 0 {
+		return true
+	}
+
+	overrideIDs := make([]uuid.UUID, 0, len(orphaned))
+	for _, override := range orphaned {
+		overrideIDs = append(overrideIDs, override.ID)
+	}
+	respondAPIErrorDetails(w, http.StatusConflict, CodeReferencedByOverrides,
+		"Company pricing overrides depend on this "+kind,
+		map[string]interface{}{"override_ids": overrideIDs})
+	return false
+}
+
+func (h *CostDataHandlers) publishMaterialChanged(r *http.Request, change events.CostDataChange, material *models.MaterialCost) {
+	h.events.Publish(r.Context(), events.MaterialChanged{
+		Change:        change,
+		Material:      *material,
+		UserID:        getUserID(r.Context()),
+		CorrelationID: getCorrelationID(r.Context()),
+	})
+}
+
+func (h *CostDataHandlers) publishLaborRateChanged(r *http.Request, change events.CostDataChange, rate *models.LaborRate) {
+	h.events.Publish(r.Context(), events.LaborRateChanged{
+		Change:        change,
+		LaborRate:     *rate,
+		UserID:        getUserID(r.Context()),
+		CorrelationID: getCorrelationID(r.Context()),
+	})
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// fakeCostCache counts the cost cache invalidations cost data changes cause
+type fakeCostCache struct {
+	materials, laborRates int
+}
+
+func (f *fakeCostCache) InvalidateMaterialsCache(ctx context.Context)  { f.materials++ }
+func (f *fakeCostCache) InvalidateLaborRatesCache(ctx context.Context) { f.laborRates++ }
+
+// newCostDataTestRouter serves the admin cost data routes over a database
+// holding a synced quartz material and a masonry rate, each the only source
+// of a key a company override depends on
+func newCostDataTestRouter(t *testing.T) (*fakeCostDatabase, *fakeCostCache, []models.CompanyPricingOverride, chi.Router) {
+	t.Helper()
+	sourceID := "hd-1234"
+	db := &fakeCostDatabase{
+		materials: []models.MaterialCost{{ID: uuid.New(), Name: "Quartz slab", Category: "countertop_quartz", Unit: "sq ft", BasePrice: 85, Source: "homedepot", SourceID: &sourceID}},
+		rates:     []models.LaborRate{{ID: uuid.New(), Trade: "masonry", HourlyRate: 80, Source: "rsmeans"}},
+	}
+	overrides := []models.CompanyPricingOverride{
+		{ID: uuid.New(), OverrideType: "material", ItemKey: "countertop_quartz", OverrideValue: 90},
+		{ID: uuid.New(), OverrideType: "labor", ItemKey: "Masonry", OverrideValue: 85},
+		{ID: uuid.New(), OverrideType: "material", ItemKey: "lumber", OverrideValue: 3.5},
+	}
+	validator := services.NewEnhancedPricingService(nil, nil, nil, nil).WithCostData(db).
+		OverrideValidator(&fakeCompanyOverrideLister{overrides: overrides})
+
+	cache := &fakeCostCache{}
+	bus := events.NewBus()
+	services.RegisterCacheInvalidation(bus, cache)
+
+	router := chi.NewRouter()
+	router.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(models.UserRoleAdmin))
+		NewCostDataHandlers(fakeMaterialStore{db}, fakeLaborRateStore{db}, validator, bus).AdminRoutes(r)
+	})
+	return db, cache, overrides, router
+}
+
+func serveAsRole(router chi.Router, userID uuid.UUID, role models.UserRole, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx := context.WithValue(req.Context(), middleware.ContextKeyUserID, userID.String())
+	req = req.WithContext(context.WithValue(ctx, middleware.ContextKeyRole, role))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCostData_MaterialLifecycle(t *testing.T) {
+	db, cache, _, router := newCostDataTestRouter(t)
+	adminID := uuid.New()
+
+	rec := serveAsRole(router, uuid.New(), models.UserRoleUser, http.MethodPost, "/api/materials",
+		`{"name":"Bluestone","category":"countertop_quartz","unit":"sq ft","base_price":40}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("non-admin create status = %d, want 403", rec.Code)
+	}
+
+	rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodPost, "/api/materials",
+		`{"name":" Bluestone ","category":"Countertop_Quartz","unit":"sq ft","base_price":40,"region":" northeast "}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var created models.MaterialCost
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Name != "Bluestone" || created.Category != "countertop_quartz" || created.Source != models.CostSourceManual ||
+		created.Region == nil || *created.Region != "northeast" {
+		t.Errorf("created = %+v", created)
+	}
+	if created.CreatedBy == nil || *created.CreatedBy != adminID {
+		t.Errorf("created_by = %v, want %s", created.CreatedBy, adminID)
+	}
+
+	rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodPost, "/api/materials",
+		`{"name":"Bluestone","category":"lumber","unit":"each","base_price":12,"region":"northeast"}`)
+	if body := decodeErrorBody(t, rec); rec.Code != http.StatusConflict || body["code"] != CodeMaterialExists {
+		t.Errorf("duplicate create = %d %v, want 409 %s", rec.Code, body, CodeMaterialExists)
+	}
+
+	// Editing a synced material makes it manual
+	synced := db.materials[0].ID
+	rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodPut, "/api/materials/"+synced.String(),
+		`{"name":"Quartz slab","category":"countertop_quartz","unit":"sq ft","base_price":92.5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if updated := db.materials[0]; updated.BasePrice != 92.5 || updated.Source != models.CostSourceManual || updated.SourceID != nil {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	missing := "/api/materials/" + uuid.New().String()
+	if rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodPut, missing,
+		`{"name":"Gone","category":"lumber","unit":"each","base_price":1}`); rec.Code != http.StatusNotFound {
+		t.Errorf("update missing status = %d, want 404", rec.Code)
+	}
+	if rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodDelete, missing, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("delete missing status = %d, want 404", rec.Code)
+	}
+
+	// The new material also provides countertop_quartz, so the synced one
+	// can go without orphaning the override
+	if rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodDelete, "/api/materials/"+synced.String(), ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if len(db.materials) != 1 || db.materials[0].ID != created.ID {
+		t.Errorf("materials after delete = %+v", db.materials)
+	}
+	if cache.materials != 3 || cache.laborRates != 0 {
+		t.Errorf("cache invalidations = %d materials, %d labor rates, want 3 and 0", cache.materials, cache.laborRates)
+	}
+}
+
+func TestCostData_DeleteReferencedByOverrides(t *testing.T) {
+	db, cache, overrides, router := newCostDataTestRouter(t)
+	adminID := uuid.New()
+
+	tests := []struct {
+		target     string
+		overrideID uuid.UUID
+	}{
+		{"/api/materials/" + db.materials[0].ID.String(), overrides[0].ID},
+		{"/api/labor-rates/" + db.rates[0].ID.String(), overrides[1].ID},
+	}
+	for _, tt := range tests {
+		rec := serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodDelete, tt.target, "")
+		var body struct {
+			Code    string `json:"code"`
+			Details struct {
+				OverrideIDs []uuid.UUID `json:"override_ids"`
+			} `json:"details"`
+		}
+		json.NewDecoder(rec.Body).Decode(&body)
+		if rec.Code != http.StatusConflict || body.Code != CodeReferencedByOverrides {
+			t.Errorf("DELETE %s = %d %s, want 409 %s", tt.target, rec.Code, body.Code, CodeReferencedByOverrides)
+		}
+		if len(body.Details.OverrideIDs) != 1 || body.Details.OverrideIDs[0] != tt.overrideID {
+			t.Errorf("DELETE %s override_ids = %v, want [%s]", tt.target, body.Details.OverrideIDs, tt.overrideID)
+		}
+	}
+	if len(db.materials) != 1 || len(db.rates) != 1 {
+		t.Errorf("referenced rows were deleted: %d materials, %d labor rates", len(db.materials), len(db.rates))
+	}
+	if cache.materials != 0 || cache.laborRates != 0 {
+		t.Errorf("refused deletes invalidated the cache")
+	}
+}
+
+func TestCostData_LaborRateLifecycle(t *testing.T) {
+	db, cache, _, router := newCostDataTestRouter(t)
+	adminID := uuid.New()
+
+	rec := serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodPost, "/api/labor-rates",
+		`{"trade":"Tile","hourly_rate":68}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var created models.LaborRate
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.Trade != "flooring" || created.Source != models.CostSourceManual || created.CreatedBy == nil || *created.CreatedBy != adminID {
+		t.Errorf("created = %+v, want a manual flooring rate by the admin", created)
+	}
+
+	rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodPost, "/api/labor-rates", `{"trade":"flooring","hourly_rate":70}`)
+	if body := decodeErrorBody(t, rec); rec.Code != http.StatusConflict || body["code"] != CodeLaborRateExists {
+		t.Errorf("duplicate create = %d %v, want 409 %s", rec.Code, body, CodeLaborRateExists)
+	}
+
+	rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodPut, "/api/labor-rates/"+created.ID.String(),
+		`{"trade":"flooring","hourly_rate":72,"description":"Tile and LVP"}`)
+	if rec.Code != http.StatusOK || db.rates[1].HourlyRate != 72 {
+		t.Errorf("update status = %d, rate %+v", rec.Code, db.rates[1])
+	}
+
+	if rec = serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodDelete, "/api/labor-rates/"+created.ID.String(), ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if len(db.rates) != 1 || cache.laborRates != 3 || cache.materials != 0 {
+		t.Errorf("after delete %d rates, invalidations %d labor rates and %d materials", len(db.rates), cache.laborRates, cache.materials)
+	}
+}
+
+func TestCostData_Validation(t *testing.T) {
+	db, cache, _, router := newCostDataTestRouter(t)
+	adminID := uuid.New()
+	long := strings.Repeat("x", maxCostItemNameLength+1)
+
+	tests := []struct {
+		name, target, body, code string
+	}{
+		{"malformed body", "/api/materials", `{"name":`, CodeInvalidRequestBody},
+		{"blank name", "/api/materials", `{"name":"  ","category":"lumber","unit":"each","base_price":3}`, CodeValidationFailed},
+		{"long name", "/api/materials", `{"name":"` + long + `","category":"lumber","unit":"each","base_price":3}`, CodeValidationFailed},
+		{"unknown category", "/api/materials", `{"name":"Slate","category":"slate_roofing","unit":"sq","base_price":3}`, CodeValidationFailed},
+		{"missing unit", "/api/materials", `{"name":"Studs","category":"lumber","base_price":3}`, CodeValidationFailed},
+		{"zero price", "/api/materials", `{"name":"Studs","category":"lumber","unit":"each","base_price":0}`, CodeValidationFailed},
+		{"unknown trade", "/api/labor-rates", `{"trade":"astrology","hourly_rate":50}`, CodeValidationFailed},
+		{"blank trade", "/api/labor-rates", `{"trade":"","hourly_rate":50}`, CodeValidationFailed},
+		{"negative rate", "/api/labor-rates", `{"trade":"plumbing","hourly_rate":-5}`, CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		rec := serveAsRole(router, adminID, models.UserRoleAdmin, http.MethodPost, tt.target, tt.body)
+		if body := decodeErrorBody(t, rec); rec.Code != http.StatusBadRequest || body["code"] != tt.code {
+			t.Errorf("%s: %d %v, want 400 %s", tt.name, rec.Code, body, tt.code)
+		}
+	}
+	if len(db.materials) != 1 || len(db.rates) != 1 || cache.materials+cache.laborRates != 0 {
+		t.Error("invalid requests changed the cost database")
+	}
+}
+
+func decodeErrorBody(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	return body
+}
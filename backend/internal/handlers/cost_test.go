@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// fakeCostDataService serves Californian database prices for doors and
+// carpentry; every other key falls back to defaults. Only California and
+// Texas have regional adjustments.
+type fakeCostDataService struct{}
+
+var fakeRegionalFactors = map[string]float64{"california": 1.25, "texas": 0.9}
+
+func (fakeCostDataService) GetMaterials(ctx context.Context, category, region *string) ([]models.MaterialCost, error) {
+	return []models.MaterialCost{{Category: "door", BasePrice: 400, Source: "lowes"}}, nil
+}
+
+func (fakeCostDataService) ListMaterials(ctx context.Context, filter models.MaterialListFilter) (*models.ListPage[models.MaterialCost], error) {
+	materials := []models.MaterialCost{}
+	if filter.Offset == 0 && (filter.Query == nil || strings.Contains("Interior door", *filter.Query)) {
+		materials = append(materials, models.MaterialCost{Name: "Interior door", Category: "door", BasePrice: 400, Source: "lowes"})
+	}
+	return &models.ListPage[models.MaterialCost]{Items: materials, Total: len(materials), Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+func (fakeCostDataService) GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
+	return []models.LaborRate{{Trade: "carpentry", HourlyRate: 80, Source: "rsmeans"}}, nil
+}
+
+func (fakeCostDataService) GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error) {
+	factor, ok := fakeRegionalFactors[region]
+	if !ok {
+		return nil, repository.ErrRegionalAdjustmentNotFound
+	}
+	return &models.RegionalAdjustment{Region: region, AdjustmentFactor: factor}, nil
+}
+
+func getEffectivePrices(t *testing.T, router chi.Router, userID uuid.UUID, target string) map[string]services.EffectivePrice {
+	t.Helper()
+	rec := serveAsUser(router, userID, http.MethodGet, target, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d, body %s; want 200", target, rec.Code, rec.Body.String())
+	}
+	var list services.EffectivePriceList
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	prices := make(map[string]services.EffectivePrice, len(list.Prices))
+	for _, price := range list.Prices {
+		prices[price.Key] = price
+	}
+	return prices
+}
+
+func TestEffectivePrices_MatchPricingSummary(t *testing.T) {
+	pricing := &PricingSources{costDataService: fakeCostDataService{}, rangeParams: services.DefaultConfidenceRangeParams}
+	h := NewCostHandlers(pricing, nil, events.Discard)
+	router := chi.NewRouter()
+	h.Routes(router)
+	userID := uuid.New()
+
+	materials := getEffectivePrices(t, router, userID, "/api/materials/effective?region=california")
+	labor := getEffectivePrices(t, router, userID, "/api/labor-rates/effective?region=california")
+
+	door := materials["door"]
+	if door.EffectivePrice != 500 || door.BasePrice != 400 || door.RegionalFactor != 1.25 || door.DefaultUsed || door.Overridden {
+		t.Errorf("door = %+v, want 400.00 x1.25 from the database", door)
+	}
+	if window := materials["window"]; !window.DefaultUsed || window.BasePrice != 850 {
+		t.Errorf("window = %+v, want the 850.00 default", window)
+	}
+
+	// The endpoint's prices are the unit costs pricing charges the same user
+	region := "california"
+	analysis := &models.AnalysisResult{
+		Openings: []models.Opening{{OpeningType: "door", Count: 2}, {OpeningType: "window", Count: 1}},
+	}
+	summary, err := pricing.enhancedPricingService().GeneratePricingSummary(context.Background(), nil, analysis, &userID, &region)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	want := map[string]float64{
+		"Interior door installation": materials["door"].EffectivePrice,
+		"Window installation":        materials["window"].EffectivePrice,
+		"Labor - carpentry":          labor["carpentry"].EffectivePrice,
+	}
+	for _, item := range summary.LineItems {
+		if price, ok := want[item.Description]; ok && item.UnitCost != price {
+			t.Errorf("%s: unit cost %v, effective price %v", item.Description, item.UnitCost, price)
+		}
+		delete(want, item.Description)
+	}
+	if len(want) > 0 {
+		t.Errorf("pricing summary is missing line items %v", want)
+	}
+}
+
+func TestEffectivePrices_RequiresUser(t *testing.T) {
+	h := NewCostHandlers(&PricingSources{}, nil, events.Discard)
+	router := chi.NewRouter()
+	h.Routes(router)
+
+	for _, target := range []string{"/api/materials/effective", "/api/labor-rates/effective"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("GET %s: status = %d, want 401", target, rec.Code)
+		}
+	}
+}
+
+func TestGetMaterials_Paginated(t *testing.T) {
+	h := NewCostHandlers(&PricingSources{costDataService: fakeCostDataService{}}, nil, events.Discard)
+	router := chi.NewRouter()
+	h.Routes(router)
+	userID := uuid.New()
+
+	list := func(t *testing.T, query string) models.ListPage[models.MaterialCost] {
+		t.Helper()
+		rec := serveAsUser(router, userID, http.MethodGet, "/api/materials"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, want 200", query, rec.Code)
+		}
+		var page models.ListPage[models.MaterialCost]
+		if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+			t.Fatalf("failed to decode materials: %v", err)
+		}
+		return page
+	}
+
+	if page := list(t, ""); len(page.Items) != 1 || page.Total != 1 || page.Limit != defaultListLimit {
+		t.Errorf("default page = %+v, want the door on a page of %d", page, defaultListLimit)
+	}
+	if page := list(t, "?q=door&limit=500"); len(page.Items) != 1 || page.Limit != maxListLimit {
+		t.Errorf("door search = %+v, want the door with the limit capped at %d", page, maxListLimit)
+	}
+	if page := list(t, "?q=window"); len(page.Items) != 0 || page.Items == nil {
+		t.Errorf("window search = %+v, want an empty list", page)
+	}
+
+	for _, query := range []string{"?limit=abc", "?offset=-5"} {
+		if rec := serveAsUser(router, userID, http.MethodGet, "/api/materials"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
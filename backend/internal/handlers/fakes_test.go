@@ -0,0 +1,1134 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+)
+
+// In-memory stores for handler tests
+
+var errFakeNotFound = errors.New("not found")
+
+type fakeProjectStore struct {
+	projects map[uuid.UUID]*models.Project
+}
+
+func (f *fakeProjectStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	if project, ok := f.projects[id]; ok {
+		return project, nil
+	}
+	return nil, errFakeNotFound
+}
+
+func (f *fakeProjectStore) GetByIDAndUserID(ctx context.Context, id, userID uuid.UUID) (*models.Project, error) {
+	if project, ok := f.projects[id]; ok && project.UserID == userID {
+		return project, nil
+	}
+	return nil, errFakeNotFound
+}
+
+func (f *fakeProjectStore) Create(ctx context.Context, project *models.Project) error {
+	if f.projects == nil {
+		f.projects = make(map[uuid.UUID]*models.Project)
+	}
+	f.projects[project.ID] = project
+	return nil
+}
+
+func (f *fakeProjectStore) GetByUserID(ctx context.Context, userID uuid.UUID, status *models.ProjectStatus) ([]*models.Project, error) {
+	projects := []*models.Project{}
+	for _, project := range f.projects {
+		if project.UserID != userID {
+			continue
+		}
+		if status == nil && project.Status == models.ProjectStatusArchived || status != nil && project.Status != *status {
+			continue
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+func (f *fakeProjectStore) Update(ctx context.Context, project *models.Project) error {
+	if _, ok := f.projects[project.ID]; !ok {
+		return errFakeNotFound
+	}
+	f.projects[project.ID] = project
+	return nil
+}
+
+func (f *fakeProjectStore) UpdateBudget(ctx context.Context, id uuid.UUID, budget *float64) error {
+	project, ok := f.projects[id]
+	if !ok {
+		return errFakeNotFound
+	}
+	project.Budget = budget
+	return nil
+}
+
+func (f *fakeProjectStore) UpdateProjectType(ctx context.Context, id uuid.UUID, projectType models.ProjectType) error {
+	project, ok := f.projects[id]
+	if !ok {
+		return errFakeNotFound
+	}
+	project.ProjectType = projectType
+	return nil
+}
+
+func (f *fakeProjectStore) UpdateTaxExempt(ctx context.Context, id uuid.UUID, exempt bool) error {
+	project, ok := f.projects[id]
+	if !ok {
+		return errFakeNotFound
+	}
+	project.TaxExempt = exempt
+	return nil
+}
+
+type fakeBlueprintStore struct {
+	blueprints map[uuid.UUID]*models.Blueprint
+	// revisionKeys are the objects of each blueprint's revisions, which
+	// Delete reports with the blueprint's own file
+	revisionKeys map[uuid.UUID][]string
+	updateErr    error
+}
+
+func (f *fakeBlueprintStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Blueprint, error) {
+	if blueprint, ok := f.blueprints[id]; ok {
+		return blueprint, nil
+	}
+	return nil, errFakeNotFound
+}
+
+func (f *fakeBlueprintStore) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]*models.Blueprint, error) {
+	var blueprints []*models.Blueprint
+	for _, blueprint := range f.blueprints {
+		if blueprint.ProjectID == projectID {
+			blueprints = append(blueprints, blueprint)
+		}
+	}
+	return blueprints, nil
+}
+
+func (f *fakeBlueprintStore) Create(ctx context.Context, blueprint *models.Blueprint) error {
+	if f.blueprints == nil {
+		f.blueprints = make(map[uuid.UUID]*models.Blueprint)
+	}
+	f.blueprints[blueprint.ID] = blueprint
+	return nil
+}
+
+func (f *fakeBlueprintStore) Update(ctx context.Context, blueprint *models.Blueprint) error {
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	if _, ok := f.blueprints[blueprint.ID]; !ok {
+		return errFakeNotFound
+	}
+	f.blueprints[blueprint.ID] = blueprint
+	return nil
+}
+
+func (f *fakeBlueprintStore) IncrementUploadGeneration(ctx context.Context, id uuid.UUID) (int, error) {
+	blueprint, ok := f.blueprints[id]
+	if !ok {
+		return 0, errFakeNotFound
+	}
+	blueprint.UploadGeneration++
+	return blueprint.UploadGeneration, nil
+}
+
+func (f *fakeBlueprintStore) UpdateRoomFinishes(ctx context.Context, id uuid.UUID, finishes map[string]models.FloorFinish) error {
+	blueprint, ok := f.blueprints[id]
+	if !ok {
+		return errFakeNotFound
+	}
+	blueprint.RoomFinishes = finishes
+	return nil
+}
+
+func (f *fakeBlueprintStore) UpdateTakeoffAdjustments(ctx context.Context, id uuid.UUID, adjustments []models.TakeoffAdjustment) error {
+	blueprint, ok := f.blueprints[id]
+	if !ok {
+		return errFakeNotFound
+	}
+	blueprint.TakeoffAdjustments = adjustments
+	return nil
+}
+
+func (f *fakeBlueprintStore) CopyOCRText(ctx context.Context, fromID, toID uuid.UUID) error {
+	return nil
+}
+
+// GetStorageUsedByUser counts every blueprint; handler tests have one user
+func (f *fakeBlueprintStore) GetStorageUsedByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var used int64
+	for _, blueprint := range f.blueprints {
+		if blueprint.FileSize != nil {
+			used += *blueprint.FileSize
+		}
+	}
+	return used, nil
+}
+
+func (f *fakeBlueprintStore) SearchOCRText(ctx context.Context, projectID uuid.UUID, searchQuery string, limit int) ([]models.BlueprintTextMatch, error) {
+	return nil, nil
+}
+
+func (f *fakeBlueprintStore) Delete(ctx context.Context, id uuid.UUID) (*models.BlueprintDeletion, error) {
+	blueprint, ok := f.blueprints[id]
+	if !ok {
+		return nil, repository.ErrBlueprintNotFound
+	}
+	delete(f.blueprints, id)
+	keys := append([]string{blueprint.S3Key}, f.revisionKeys[id]...)
+	return &models.BlueprintDeletion{S3Keys: keys}, nil
+}
+
+// fakeJobStore fails to create jobs for the blueprints in failCreate
+type fakeJobStore struct {
+	jobs       map[uuid.UUID]*models.Job
+	active     map[uuid.UUID]bool
+	failCreate map[uuid.UUID]bool
+	depth      models.QueueDepth // Reported as the queue depth
+}
+
+func (f *fakeJobStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	if job, ok := f.jobs[id]; ok {
+		return job, nil
+	}
+	return nil, errFakeNotFound
+}
+
+func (f *fakeJobStore) Create(ctx context.Context, job *models.Job) error {
+	if f.failCreate[job.BlueprintID] {
+		return errors.New("insert failed")
+	}
+	if f.jobs == nil {
+		f.jobs = make(map[uuid.UUID]*models.Job)
+	}
+	f.jobs[job.ID] = job
+	return nil
+}
+
+func (f *fakeJobStore) Update(ctx context.Context, job *models.Job) error {
+	f.jobs[job.ID] = job
+	return nil
+}
+
+func (f *fakeJobStore) GetActiveTakeoffBlueprintIDs(ctx context.Context, blueprintIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	active := make(map[uuid.UUID]bool)
+	for _, id := range blueprintIDs {
+		if f.active[id] {
+			active[id] = true
+		}
+	}
+	return active, nil
+}
+
+func (f *fakeJobStore) GetActiveBidPDFJob(ctx context.Context, bidID uuid.UUID) (*models.Job, error) {
+	for _, job := range f.jobs {
+		if job.JobType == models.JobTypePDFGeneration && job.BidID != nil && *job.BidID == bidID &&
+			(job.Status == models.JobStatusQueued || job.Status == models.JobStatusProcessing) {
+			return job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeJobStore) GetQueueDepth(ctx context.Context, userID uuid.UUID) (models.QueueDepth, error) {
+	return f.depth, nil
+}
+
+type fakeBidStore struct {
+	bids []*models.Bid
+	err  error // Returned by every call when set
+}
+
+func (f *fakeBidStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Bid, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, bid := range f.bids {
+		if bid.ID == id {
+			return bid, nil
+		}
+	}
+	return nil, errFakeNotFound
+}
+
+func (f *fakeBidStore) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]*models.Bid, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var bids []*models.Bid
+	for _, bid := range f.bids {
+		if bid.ProjectID == projectID {
+			bids = append(bids, bid)
+		}
+	}
+	return bids, nil
+}
+
+// List filters and pages the project's bids. Created-at sorts keep insertion
+// order; price sorts order by final price.
+func (f *fakeBidStore) List(ctx context.Context, filter models.BidListFilter) ([]*models.Bid, int, error) {
+	if f.err != nil {
+		return nil, 0, f.err
+	}
+	bids := []*models.Bid{}
+	for _, bid := range f.bids {
+		if bid.ProjectID != filter.ProjectID || (filter.Status != nil && bid.Status != *filter.Status) {
+			continue
+		}
+		if filter.BlueprintID != nil && !slices.Contains(bid.BlueprintIDs, *filter.BlueprintID) {
+			continue
+		}
+		bids = append(bids, bid)
+	}
+	price := func(bid *models.Bid) float64 {
+		if bid.FinalPrice == nil {
+			return 0
+		}
+		return *bid.FinalPrice
+	}
+	switch filter.Sort {
+	case models.BidSortFinalPriceAsc:
+		slices.SortStableFunc(bids, func(a, b *models.Bid) int { return cmp.Compare(price(a), price(b)) })
+	case models.BidSortFinalPriceDesc:
+		slices.SortStableFunc(bids, func(a, b *models.Bid) int { return cmp.Compare(price(b), price(a)) })
+	}
+
+	total := len(bids)
+	start := min(filter.Offset, total)
+	end := min(start+filter.Limit, total)
+	return bids[start:end], total, nil
+}
+
+func (f *fakeBidStore) Create(ctx context.Context, bid *models.Bid) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.bids = append(f.bids, bid)
+	return nil
+}
+
+func (f *fakeBidStore) Update(ctx context.Context, bid *models.Bid) error {
+	if f.err != nil {
+		return f.err
+	}
+	for i, existing := range f.bids {
+		if existing.ID == bid.ID {
+			f.bids[i] = bid
+			return nil
+		}
+	}
+	return errFakeNotFound
+}
+
+func (f *fakeBidStore) LockVersion(ctx context.Context, id uuid.UUID, version int) error {
+	if f.err != nil {
+		return f.err
+	}
+	for _, bid := range f.bids {
+		if bid.ID == id && bid.Version == version {
+			return nil
+		}
+	}
+	return repository.ErrBidVersionChanged
+}
+
+func (f *fakeBidStore) GetIDsByBlueprint(ctx context.Context, blueprintID uuid.UUID) ([]uuid.UUID, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	ids := []uuid.UUID{}
+	for _, bid := range f.bids {
+		if slices.Contains(bid.BlueprintIDs, blueprintID) {
+			ids = append(ids, bid.ID)
+		}
+	}
+	return ids, nil
+}
+
+// UpdatePDF is a no-op: bids are shared by pointer, so the PDF fields are
+// already set
+func (f *fakeBidStore) UpdatePDF(ctx context.Context, bid *models.Bid) error {
+	return f.err
+}
+
+type fakeBlueprintRevisionStore struct {
+	revisions []*models.BlueprintRevision
+}
+
+func (f *fakeBlueprintRevisionStore) Create(ctx context.Context, revision *models.BlueprintRevision) error {
+	f.revisions = append(f.revisions, revision)
+	return nil
+}
+
+func (f *fakeBlueprintRevisionStore) GetByBlueprintID(ctx context.Context, blueprintID uuid.UUID) ([]*models.BlueprintRevision, error) {
+	var revisions []*models.BlueprintRevision
+	for _, revision := range f.revisions {
+		if revision.BlueprintID == blueprintID {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, nil
+}
+
+func (f *fakeBlueprintRevisionStore) GetByVersion(ctx context.Context, blueprintID uuid.UUID, version int) (*models.BlueprintRevision, error) {
+	for _, revision := range f.revisions {
+		if revision.BlueprintID == blueprintID && revision.Version == version {
+			return revision, nil
+		}
+	}
+	return nil, errFakeNotFound
+}
+
+func (f *fakeBlueprintRevisionStore) GetLatestVersion(ctx context.Context, blueprintID uuid.UUID) (int, error) {
+	latest := 0
+	for _, revision := range f.revisions {
+		if revision.BlueprintID == blueprintID && revision.Version > latest {
+			latest = revision.Version
+		}
+	}
+	return latest, nil
+}
+
+type fakeBidRevisionStore struct {
+	revisions []*models.BidRevision
+}
+
+func (f *fakeBidRevisionStore) Create(ctx context.Context, revision *models.BidRevision) error {
+	f.revisions = append(f.revisions, revision)
+	return nil
+}
+
+func (f *fakeBidRevisionStore) GetByBidID(ctx context.Context, bidID uuid.UUID) ([]*models.BidRevision, error) {
+	var revisions []*models.BidRevision
+	for _, revision := range f.revisions {
+		if revision.BidID == bidID {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, nil
+}
+
+func (f *fakeBidRevisionStore) GetByVersion(ctx context.Context, bidID uuid.UUID, version int) (*models.BidRevision, error) {
+	for _, revision := range f.revisions {
+		if revision.BidID == bidID && revision.Version == version {
+			return revision, nil
+		}
+	}
+	return nil, errFakeNotFound
+}
+
+func (f *fakeBidRevisionStore) GetLatestVersion(ctx context.Context, bidID uuid.UUID) (int, error) {
+	latest := 0
+	for _, revision := range f.revisions {
+		if revision.BidID == bidID && revision.Version > latest {
+			latest = revision.Version
+		}
+	}
+	return latest, nil
+}
+
+// fakeBidDraftStore keeps one draft per bid and user, keeping the base
+// version of an unexpired draft on resave as the repository does
+type fakeBidDraftStore struct {
+	drafts []*models.BidDraft
+}
+
+func (f *fakeBidDraftStore) Save(ctx context.Context, draft *models.BidDraft) error {
+	for i, existing := range f.drafts {
+		if existing.BidID == draft.BidID && existing.UserID == draft.UserID {
+			if existing.ExpiresAt.After(draft.UpdatedAt.Time) {
+				draft.ID, draft.BaseVersion, draft.CreatedAt = existing.ID, existing.BaseVersion, existing.CreatedAt
+			}
+			f.drafts[i] = draft
+			return nil
+		}
+	}
+	f.drafts = append(f.drafts, draft)
+	return nil
+}
+
+func (f *fakeBidDraftStore) Get(ctx context.Context, bidID, userID uuid.UUID, now time.Time) (*models.BidDraft, error) {
+	for _, draft := range f.drafts {
+		if draft.BidID == bidID && draft.UserID == userID && draft.ExpiresAt.After(now) {
+			return draft, nil
+		}
+	}
+	return nil, errFakeNotFound
+}
+
+func (f *fakeBidDraftStore) Delete(ctx context.Context, bidID, userID uuid.UUID) error {
+	for i, draft := range f.drafts {
+		if draft.BidID == bidID && draft.UserID == userID {
+			f.drafts = append(f.drafts[:i], f.drafts[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// fakeCompanyProfileStore holds company profiles by user ID
+type fakeCompanyProfileStore struct {
+	profiles map[uuid.UUID]*models.CompanyProfile
+}
+
+func (f *fakeCompanyProfileStore) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.CompanyProfile, error) {
+	profile, ok := f.profiles[userID]
+	if !ok {
+		return nil, repository.ErrCompanyProfileNotFound
+	}
+	return profile, nil
+}
+
+func (f *fakeCompanyProfileStore) Save(ctx context.Context, profile *models.CompanyProfile) error {
+	if f.profiles == nil {
+		f.profiles = make(map[uuid.UUID]*models.CompanyProfile)
+	}
+	if existing, ok := f.profiles[profile.UserID]; ok {
+		profile.CreatedAt = existing.CreatedAt
+	}
+	f.profiles[profile.UserID] = profile
+	return nil
+}
+
+// fakeObjectStore keeps uploaded objects in memory and serves downloads.
+// Deleting a key in failDelete fails.
+type fakeObjectStore struct {
+	objects    map[string][]byte
+	failDelete map[string]bool
+}
+
+func (f *fakeObjectStore) UploadFile(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	if f.objects == nil {
+		f.objects = make(map[string][]byte)
+	}
+	f.objects[key] = data
+	return f.ObjectURL(key), nil
+}
+
+func (f *fakeObjectStore) ObjectURL(key string) string {
+	return "https://s3.example.com/" + key
+}
+
+func (f *fakeObjectStore) ArchiveObject(ctx context.Context, key string) error {
+	return nil
+}
+
+func (f *fakeObjectStore) DeleteFile(ctx context.Context, key string) error {
+	if f.failDelete[key] {
+		return errors.New("storage unavailable")
+	}
+	delete(f.objects, key)
+	return nil
+}
+
+func (f *fakeObjectStore) DownloadFile(ctx context.Context, key string) ([]byte, error) {
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, errFakeNotFound
+	}
+	return data, nil
+}
+
+// fakeUserStore holds users by ID and records token revocations
+type fakeUserStore struct {
+	users     map[uuid.UUID]*models.User
+	revokedAt map[uuid.UUID]time.Time
+}
+
+func (f *fakeUserStore) CreateUser(ctx context.Context, user *models.User) error {
+	for _, existing := range f.users {
+		if existing.Email == user.Email {
+			return repository.ErrEmailAlreadyExists
+		}
+	}
+	f.users[user.ID] = user
+	return nil
+}
+
+func (f *fakeUserStore) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (f *fakeUserStore) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if user, ok := f.users[id]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (f *fakeUserStore) SearchUsers(ctx context.Context, filter models.UserSearchFilter) ([]*models.User, error) {
+	return nil, nil
+}
+
+func (f *fakeUserStore) GetUserActivity(ctx context.Context, id uuid.UUID) (*models.UserActivity, error) {
+	return &models.UserActivity{}, nil
+}
+
+func (f *fakeUserStore) SetSuspended(ctx context.Context, id uuid.UUID, suspended bool) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.Suspended = suspended
+	return nil
+}
+
+func (f *fakeUserStore) SetRole(ctx context.Context, id uuid.UUID, role models.UserRole, revokedAt time.Time) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.Role = role
+	if f.revokedAt == nil {
+		f.revokedAt = make(map[uuid.UUID]time.Time)
+	}
+	f.revokedAt[id] = revokedAt
+	return nil
+}
+
+func (f *fakeUserStore) UpdateBidDefaults(ctx context.Context, id uuid.UUID, inclusions, exclusions []string) error {
+	return nil
+}
+
+func (f *fakeUserStore) SetAutoBlueprintRevisions(ctx context.Context, id uuid.UUID, enabled bool) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.AutoBlueprintRevisions = enabled
+	return nil
+}
+
+func (f *fakeUserStore) SetUnitSystem(ctx context.Context, id uuid.UUID, system models.UnitSystem) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.UnitSystem = system
+	return nil
+}
+
+func (f *fakeUserStore) UpdateQuickBooksItems(ctx context.Context, id uuid.UUID, items map[string]string, defaultItem *string) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.QuickBooksItems = items
+	user.QuickBooksDefaultItem = defaultItem
+	return nil
+}
+
+func (f *fakeUserStore) UpdateTaxSettings(ctx context.Context, id uuid.UUID, settings *models.TaxSettings) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.TaxSettings = settings
+	return nil
+}
+
+func (f *fakeUserStore) UpdateImpactThresholds(ctx context.Context, id uuid.UUID, thresholds *models.ImpactThresholds) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.ImpactThresholds = thresholds
+	return nil
+}
+
+func (f *fakeUserStore) UpdatePDFLayout(ctx context.Context, id uuid.UUID, layout *models.PDFLayout) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.PDFLayout = layout
+	return nil
+}
+
+func (f *fakeUserStore) UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.PasswordHash = passwordHash
+	return nil
+}
+
+func (f *fakeUserStore) ChangePassword(ctx context.Context, id uuid.UUID, passwordHash string, revokedAt time.Time) error {
+	if err := f.UpdatePasswordHash(ctx, id, passwordHash); err != nil {
+		return err
+	}
+	if f.revokedAt == nil {
+		f.revokedAt = make(map[uuid.UUID]time.Time)
+	}
+	f.revokedAt[id] = revokedAt
+	return nil
+}
+
+func (f *fakeUserStore) GetAccountStatus(ctx context.Context, id uuid.UUID) (models.AccountStatus, error) {
+	status := models.AccountStatus{}
+	if revokedAt, ok := f.revokedAt[id]; ok {
+		status.TokensRevokedAt = &revokedAt
+	}
+	return status, nil
+}
+
+type fakeAPIKeyStore struct {
+	keys map[uuid.UUID]*models.APIKey
+}
+
+func (f *fakeAPIKeyStore) Create(ctx context.Context, key *models.APIKey) error {
+	if f.keys == nil {
+		f.keys = make(map[uuid.UUID]*models.APIKey)
+	}
+	stored := *key
+	f.keys[key.ID] = &stored
+	return nil
+}
+
+func (f *fakeAPIKeyStore) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	for _, key := range f.keys {
+		if key.Prefix == prefix {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (f *fakeAPIKeyStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	keys := []*models.APIKey{}
+	for _, key := range f.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (f *fakeAPIKeyStore) Revoke(ctx context.Context, id, userID uuid.UUID, at time.Time) error {
+	key, ok := f.keys[id]
+	if !ok || key.UserID != userID {
+		return repository.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		revokedAt := models.NewTimestamp(at)
+		key.RevokedAt = &revokedAt
+	}
+	return nil
+}
+
+func (f *fakeAPIKeyStore) RecordUsage(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if key, ok := f.keys[id]; ok {
+		usedAt := models.NewTimestamp(at)
+		key.LastUsedAt = &usedAt
+		key.RequestCount++
+	}
+	return nil
+}
+
+type fakeWebhookStore struct {
+	webhooks   map[uuid.UUID]*models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func (f *fakeWebhookStore) Create(ctx context.Context, webhook *models.Webhook) error {
+	if f.webhooks == nil {
+		f.webhooks = make(map[uuid.UUID]*models.Webhook)
+	}
+	stored := *webhook
+	f.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (f *fakeWebhookStore) GetByID(ctx context.Context, id, userID uuid.UUID) (*models.Webhook, error) {
+	webhook, ok := f.webhooks[id]
+	if !ok || webhook.UserID != userID {
+		return nil, repository.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (f *fakeWebhookStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+	for _, webhook := range f.webhooks {
+		if webhook.UserID == userID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (f *fakeWebhookStore) ListByEvent(ctx context.Context, userID uuid.UUID, eventType string) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+	for _, webhook := range f.webhooks {
+		if webhook.UserID == userID && slices.Contains(webhook.EventTypes, eventType) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (f *fakeWebhookStore) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	if _, err := f.GetByID(ctx, id, userID); err != nil {
+		return err
+	}
+	delete(f.webhooks, id)
+	return nil
+}
+
+func (f *fakeWebhookStore) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	f.deliveries = append(f.deliveries, delivery)
+	return nil
+}
+
+func (f *fakeWebhookStore) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return nil
+}
+
+func (f *fakeWebhookStore) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	deliveries := []*models.WebhookDelivery{}
+	for _, delivery := range f.deliveries {
+		if delivery.WebhookID == webhookID && len(deliveries) < limit {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+type fakeRefreshTokenStore struct {
+	tokens map[string]*models.RefreshToken
+}
+
+func (f *fakeRefreshTokenStore) Create(ctx context.Context, token *models.RefreshToken) error {
+	f.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (f *fakeRefreshTokenStore) Consume(ctx context.Context, tokenHash string, at time.Time) (*models.RefreshToken, error) {
+	token, ok := f.tokens[tokenHash]
+	if !ok || token.RevokedAt != nil || !at.Before(token.ExpiresAt.Time) {
+		return nil, repository.ErrRefreshTokenNotFound
+	}
+	token.RevokedAt = models.NewTimestampPtr(&at)
+	return token, nil
+}
+
+func (f *fakeRefreshTokenStore) Revoke(ctx context.Context, tokenHash string, at time.Time) error {
+	token, ok := f.tokens[tokenHash]
+	if !ok {
+		return repository.ErrRefreshTokenNotFound
+	}
+	if token.RevokedAt == nil {
+		token.RevokedAt = models.NewTimestampPtr(&at)
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokenStore) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	for _, token := range f.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = models.NewTimestampPtr(&at)
+		}
+	}
+	return nil
+}
+
+type fakeBidShareStore struct {
+	shares     map[string]*models.BidShare
+	respondErr error
+}
+
+func (f *fakeBidShareStore) Create(ctx context.Context, share *models.BidShare) error {
+	if f.shares == nil {
+		f.shares = make(map[string]*models.BidShare)
+	}
+	f.shares[share.TokenHash] = share
+	return nil
+}
+
+func (f *fakeBidShareStore) GetByTokenHash(ctx context.Context, tokenHash string) (*models.BidShare, error) {
+	share, ok := f.shares[tokenHash]
+	if !ok {
+		return nil, repository.ErrBidShareNotFound
+	}
+	return share, nil
+}
+
+func (f *fakeBidShareStore) Respond(ctx context.Context, id uuid.UUID, response models.BidShareResponse, at time.Time) (*models.BidShare, error) {
+	if f.respondErr != nil {
+		return nil, f.respondErr
+	}
+	for _, share := range f.shares {
+		if share.ID != id {
+			continue
+		}
+		if share.RespondedAt != nil || share.RevokedAt != nil || !at.Before(share.ExpiresAt.Time) {
+			break
+		}
+		share.RespondedAt = models.NewTimestampPtr(&at)
+		share.Response = &response.Status
+		share.ResponseComment = response.Comment
+		share.ResponderName = &response.ResponderName
+		return share, nil
+	}
+	return nil, repository.ErrBidShareNotFound
+}
+
+func (f *fakeBidShareStore) Revoke(ctx context.Context, bidID, id uuid.UUID, at time.Time) (*models.BidShare, error) {
+	for _, share := range f.shares {
+		if share.ID != id || share.BidID != bidID {
+			continue
+		}
+		if share.RevokedAt == nil {
+			share.RevokedAt = models.NewTimestampPtr(&at)
+		}
+		return share, nil
+	}
+	return nil, repository.ErrBidShareNotFound
+}
+
+func (f *fakeBidShareStore) ListByBidID(ctx context.Context, bidID uuid.UUID) ([]*models.BidShare, error) {
+	var shares []*models.BidShare
+	for _, share := range f.shares {
+		if share.BidID == bidID {
+			shares = append(shares, share)
+		}
+	}
+	slices.SortFunc(shares, func(a, b *models.BidShare) int { return a.CreatedAt.Time.Compare(b.CreatedAt.Time) })
+	return shares, nil
+}
+
+func (f *fakeBidShareStore) MarkViewed(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	for _, share := range f.shares {
+		if share.ID == id && share.FirstViewedAt == nil {
+			share.FirstViewedAt = models.NewTimestampPtr(&at)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeBidShareEventStore struct {
+	events []models.BidShareEvent
+}
+
+func (f *fakeBidShareEventStore) Record(ctx context.Context, event *models.BidShareEvent, limit int) (bool, error) {
+	count := 0
+	for _, stored := range f.events {
+		if stored.ShareID == event.ShareID {
+			count++
+		}
+	}
+	if count >= limit {
+		return false, nil
+	}
+	f.events = append(f.events, *event)
+	return true, nil
+}
+
+func (f *fakeBidShareEventStore) ListByBidID(ctx context.Context, bidID uuid.UUID) ([]models.BidShareEvent, error) {
+	var events []models.BidShareEvent
+	for _, event := range f.events {
+		if event.BidID == bidID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+type fakeBidAcceptanceStore struct {
+	acceptances []*models.BidAcceptance
+}
+
+func (f *fakeBidAcceptanceStore) Create(ctx context.Context, acceptance *models.BidAcceptance) error {
+	f.acceptances = append(f.acceptances, acceptance)
+	return nil
+}
+
+// fakeTransactor counts the transactions that committed and rolled back.
+// The fake stores don't undo writes, so tests check the count instead.
+type fakeTransactor struct {
+	commits   int
+	rollbacks int
+}
+
+func (f *fakeTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		f.rollbacks++
+		return err
+	}
+	f.commits++
+	return nil
+}
+
+type fakeProjectPricingOverrideStore struct {
+	overrides []*models.ProjectPricingOverride
+}
+
+func (f *fakeProjectPricingOverrideStore) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.ProjectPricingOverride, error) {
+	var overrides []models.ProjectPricingOverride
+	for _, override := range f.overrides {
+		if override.ProjectID == projectID {
+			overrides = append(overrides, *override)
+		}
+	}
+	return overrides, nil
+}
+
+func (f *fakeProjectPricingOverrideStore) GetByID(ctx context.Context, id uuid.UUID) (*models.ProjectPricingOverride, error) {
+	for _, override := range f.overrides {
+		if override.ID == id {
+			stored := *override
+			return &stored, nil
+		}
+	}
+	return nil, repository.ErrProjectPricingOverrideNotFound
+}
+
+func (f *fakeProjectPricingOverrideStore) Create(ctx context.Context, override *models.ProjectPricingOverride) error {
+	for _, existing := range f.overrides {
+		if existing.ProjectID == override.ProjectID && existing.OverrideType == override.OverrideType && existing.ItemKey == override.ItemKey {
+			return repository.ErrProjectPricingOverrideExists
+		}
+	}
+	stored := *override
+	f.overrides = append(f.overrides, &stored)
+	return nil
+}
+
+func (f *fakeProjectPricingOverrideStore) Update(ctx context.Context, override *models.ProjectPricingOverride) error {
+	for _, existing := range f.overrides {
+		if existing.ID == override.ID {
+			*existing = *override
+			return nil
+		}
+	}
+	return repository.ErrProjectPricingOverrideNotFound
+}
+
+func (f *fakeProjectPricingOverrideStore) Delete(ctx context.Context, id uuid.UUID) error {
+	for i, existing := range f.overrides {
+		if existing.ID == id {
+			f.overrides = append(f.overrides[:i], f.overrides[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrProjectPricingOverrideNotFound
+}
+
+// fakeCostDatabase holds materials and labor rates, serving them as the
+// cost data pricing reads and, through its stores, as the rows admins edit
+type fakeCostDatabase struct {
+	materials []models.MaterialCost
+	rates     []models.LaborRate
+}
+
+func (f *fakeCostDatabase) GetMaterials(ctx context.Context, category, region *string) ([]models.MaterialCost, error) {
+	return f.materials, nil
+}
+
+func (f *fakeCostDatabase) GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
+	return f.rates, nil
+}
+
+func (f *fakeCostDatabase) GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error) {
+	return nil, repository.ErrRegionalAdjustmentNotFound
+}
+
+type fakeMaterialStore struct{ db *fakeCostDatabase }
+
+func (f fakeMaterialStore) GetByID(ctx context.Context, id uuid.UUID) (*models.MaterialCost, error) {
+	for _, material := range f.db.materials {
+		if material.ID == id {
+			return &material, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (f fakeMaterialStore) Create(ctx context.Context, material *models.MaterialCost) error {
+	for _, existing := range f.db.materials {
+		if existing.Name == material.Name && reflect.DeepEqual(existing.Region, material.Region) {
+			return repository.ErrMaterialExists
+		}
+	}
+	f.db.materials = append(f.db.materials, *material)
+	return nil
+}
+
+func (f fakeMaterialStore) Update(ctx context.Context, material *models.MaterialCost) error {
+	for i := range f.db.materials {
+		if f.db.materials[i].ID == material.ID {
+			f.db.materials[i] = *material
+		}
+	}
+	return nil
+}
+
+func (f fakeMaterialStore) Delete(ctx context.Context, id uuid.UUID) error {
+	f.db.materials = slices.DeleteFunc(f.db.materials, func(m models.MaterialCost) bool { return m.ID == id })
+	return nil
+}
+
+type fakeLaborRateStore struct{ db *fakeCostDatabase }
+
+func (f fakeLaborRateStore) GetByID(ctx context.Context, id uuid.UUID) (*models.LaborRate, error) {
+	for _, rate := range f.db.rates {
+		if rate.ID == id {
+			return &rate, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (f fakeLaborRateStore) Create(ctx context.Context, rate *models.LaborRate) error {
+	for _, existing := range f.db.rates {
+		if existing.Trade == rate.Trade && reflect.DeepEqual(existing.Region, rate.Region) {
+			return repository.ErrLaborRateExists
+		}
+	}
+	f.db.rates = append(f.db.rates, *rate)
+	return nil
+}
+
+func (f fakeLaborRateStore) Update(ctx context.Context, rate *models.LaborRate) error {
+	for i := range f.db.rates {
+		if f.db.rates[i].ID == rate.ID {
+			f.db.rates[i] = *rate
+		}
+	}
+	return nil
+}
+
+func (f fakeLaborRateStore) Delete(ctx context.Context, id uuid.UUID) error {
+	f.db.rates = slices.DeleteFunc(f.db.rates, func(lr models.LaborRate) bool { return lr.ID == id })
+	return nil
+}
+
+type fakeCompanyOverrideLister struct {
+	overrides []models.CompanyPricingOverride
+}
+
+func (f *fakeCompanyOverrideLister) GetAll(ctx context.Context) ([]models.CompanyPricingOverride, error) {
+	return f.overrides, nil
+}
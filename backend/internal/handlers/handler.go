@@ -5,7 +5,12 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
@@ -14,40 +19,63 @@ import (
 
 // CostIntegrationServiceInterface defines the interface for cost integration service
 type CostIntegrationServiceInterface interface {
-	SyncMaterials(ctx context.Context, providerName, region string) error
-	SyncLaborRates(ctx context.Context, providerName, region string) error
-	SyncRegionalAdjustment(ctx context.Context, providerName, region string) error
-	SyncAll(ctx context.Context, region string) error
+	Sync(ctx context.Context, providerNames []string, region string) (*services.SyncReport, error)
+	SyncAll(ctx context.Context, region string) (*services.SyncReport, error)
 }
 
 // CostDataServiceInterface defines the interface for cost data retrieval (with or without cache)
 type CostDataServiceInterface interface {
 	GetMaterials(ctx context.Context, category, region *string) ([]models.MaterialCost, error)
+	ListMaterials(ctx context.Context, filter models.MaterialListFilter) (*models.ListPage[models.MaterialCost], error)
 	GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error)
 	GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error)
 }
 
+// SystemHandlers serves the API root and health check
+type SystemHandlers struct {
+	db        HealthChecker
+	aiService services.AIProvider
+	jobRepo   JobStore
+	config    *config.Config
+}
+
+func NewSystemHandlers(db HealthChecker, aiService services.AIProvider, jobRepo JobStore, cfg *config.Config) *SystemHandlers {
+	return &SystemHandlers{
+		db:        db,
+		aiService: aiService,
+		jobRepo:   jobRepo,
+		config:    cfg,
+	}
+}
+
+// Routes registers the public root and health routes
+func (h *SystemHandlers) Routes(r chi.Router) {
+	r.Get("/", h.Root)
+	r.Get("/health", h.Health)
+}
+
+// Handler bundles every handler group behind the original constructor.
+//
+// Deprecated: construct the handler groups (NewBidHandlers, NewBlueprintHandlers,
+// ...) and register them with their Routes methods. Handler will be removed
+// in the next release.
 type Handler struct {
-	db                       *repository.Database
-	projectRepo              *repository.ProjectRepository
-	blueprintRepo            *repository.BlueprintRepository
-	blueprintRevisionRepo    *repository.BlueprintRevisionRepository
-	jobRepo                  *repository.JobRepository
-	bidRepo                  *repository.BidRepository
-	bidRevisionRepo          *repository.BidRevisionRepository
-	userRepo                 *repository.UserRepository
-	materialRepo             *repository.MaterialRepository
-	laborRateRepo            *repository.LaborRateRepository
-	regionalRepo             *repository.RegionalAdjustmentRepository
-	companyOverrideRepo      *repository.CompanyPricingOverrideRepository
-	s3Service                *services.S3Service
-	aiService                *services.AIService
-	authService              *services.AuthService
-	fileValidator            *services.FileValidator
-	costIntegrationService   CostIntegrationServiceInterface
-	costDataService          CostDataServiceInterface
+	*SystemHandlers
+	*AuthHandlers
+	*ProjectHandlers
+	*BlueprintHandlers
+	*JobHandlers
+	*BidHandlers
+	*RevisionHandlers
+	*CostHandlers
+	*AnalyticsHandlers
+	*AdminHandlers
+	*APIKeyHandlers
 }
 
+// NewHandler builds every handler group from the full dependency list.
+//
+// Deprecated: construct the handler groups directly; see Handler.
 func NewHandler(
 	db *repository.Database,
 	projectRepo *repository.ProjectRepository,
@@ -61,43 +89,87 @@ func NewHandler(
 	laborRateRepo *repository.LaborRateRepository,
 	regionalRepo *repository.RegionalAdjustmentRepository,
 	companyOverrideRepo *repository.CompanyPricingOverrideRepository,
+	objectDeletionRepo *repository.ObjectDeletionRepository,
+	blueprintAssetRepo *repository.BlueprintAssetRepository,
 	s3Service *services.S3Service,
-	aiService *services.AIService,
+	aiService services.AIProvider,
 	authService *services.AuthService,
 	costIntegrationService CostIntegrationServiceInterface,
+	cfg *config.Config,
 ) *Handler {
-	// Use costIntegrationService as costDataService if it supports the interface
-	var costDataService CostDataServiceInterface
-	if cds, ok := costIntegrationService.(CostDataServiceInterface); ok {
-		costDataService = cds
-	} else {
-		// Fallback to nil - handlers will use repositories directly
-		slog.Warn("CostIntegrationService does not implement CostDataServiceInterface, handlers will use direct repository access")
+	pricing := NewPricingSources(materialRepo, laborRateRepo, regionalRepo, companyOverrideRepo, costIntegrationService, cfg)
+
+	// Nothing waits on this bus at shutdown, so audit lines still being
+	// written when the process exits are lost
+	bus := events.NewBus()
+	services.NewAuditSubscriber(slog.Default()).Register(bus)
+	if cache, ok := costIntegrationService.(services.MaterialsCacheInvalidator); ok {
+		services.RegisterCacheInvalidation(bus, cache)
 	}
-	
+
+	companyProfileRepo := repository.NewCompanyProfileRepository(db)
+	pdfGenerator := newBidPDFGenerator(cfg, bidRepo, projectRepo, blueprintRepo, userRepo, companyProfileRepo, repository.NewBidAcceptanceRepository(db), objectDeletionRepo, s3Service, aiService)
+
 	return &Handler{
-		db:                       db,
-		projectRepo:              projectRepo,
-		blueprintRepo:            blueprintRepo,
-		blueprintRevisionRepo:    blueprintRevisionRepo,
-		jobRepo:                  jobRepo,
-		bidRepo:                  bidRepo,
-		bidRevisionRepo:          bidRevisionRepo,
-		userRepo:                 userRepo,
-		materialRepo:             materialRepo,
-		laborRateRepo:            laborRateRepo,
-		regionalRepo:             regionalRepo,
-		companyOverrideRepo:      companyOverrideRepo,
-		s3Service:                s3Service,
-		aiService:                aiService,
-		authService:              authService,
-		fileValidator:            services.NewFileValidator(),
-		costIntegrationService:   costIntegrationService,
-		costDataService:          costDataService,
-	}
-}
-
-func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+		SystemHandlers:    NewSystemHandlers(db, aiService, jobRepo, cfg),
+		AuthHandlers:      NewAuthHandlers(userRepo, authService, cfg),
+		ProjectHandlers:   NewProjectHandlers(projectRepo, jobRepo, services.NewProjectDuplicator(projectRepo, blueprintRepo, s3Service, cfg.S3.UserQuotaBytes), cfg),
+		BlueprintHandlers: NewBlueprintHandlers(projectRepo, blueprintRepo, blueprintAssetRepo, userRepo, s3Service, services.NewBlueprintDeleter(bidRepo, blueprintRepo, s3Service), cfg),
+		JobHandlers:       NewJobHandlers(projectRepo, blueprintRepo, jobRepo, cfg),
+		BidHandlers:       NewBidHandlers(projectRepo, blueprintRepo, bidRepo, bidRevisionRepo, repository.NewBidDraftRepository(db), userRepo, companyProfileRepo, jobRepo, pricing, pdfGenerator, s3Service, aiService, db, bus, cfg),
+		RevisionHandlers:  NewRevisionHandlers(projectRepo, blueprintRepo, blueprintRevisionRepo, blueprintAssetRepo, bidRepo, bidRevisionRepo, userRepo, s3Service, db),
+		CostHandlers:      NewCostHandlers(pricing, costIntegrationService, bus),
+		AnalyticsHandlers: NewAnalyticsHandlers(bidRepo, nil),
+		AdminHandlers:     NewAdminHandlers(userRepo, authService, materialRepo, bus, services.NewRetentionSweeper(repository.NewRetentionRepository(db), cfg.Retention)),
+		APIKeyHandlers:    NewAPIKeyHandlers(services.NewAPIKeyService(repository.NewAPIKeyRepository(db), services.DefaultAPIKeyCacheTTL)),
+	}
+}
+
+// PublicRoutes registers the routes served without authentication
+func (h *Handler) PublicRoutes(r chi.Router) {
+	h.SystemHandlers.Routes(r)
+	h.AuthHandlers.PublicRoutes(r)
+}
+
+// Routes registers every authenticated route; the caller applies the auth
+// middleware
+func (h *Handler) Routes(r chi.Router) {
+	h.AuthHandlers.Routes(r)
+	h.ProjectHandlers.Routes(r)
+	h.BlueprintHandlers.Routes(r)
+	h.JobHandlers.Routes(r)
+	h.BidHandlers.Routes(r)
+	h.RevisionHandlers.Routes(r)
+	h.CostHandlers.Routes(r)
+	h.AnalyticsHandlers.Routes(r)
+	h.AdminHandlers.Routes(r)
+	h.APIKeyHandlers.Routes(r)
+}
+
+// newLoginThrottle builds the failed-login throttle from rate limit config
+func newLoginThrottle(cfg *config.Config) *services.LoginThrottle {
+	throttleConfig := services.DefaultLoginThrottleConfig()
+	if cfg != nil {
+		throttleConfig = services.LoginThrottleConfig{
+			MaxFailures: cfg.RateLimit.LoginMaxFailures,
+			BaseLockout: cfg.RateLimit.LoginLockoutBase,
+			MaxLockout:  cfg.RateLimit.LoginLockoutMax,
+		}
+	}
+	return services.NewLoginThrottle(throttleConfig, nil)
+}
+
+// newUploadScanner builds the upload virus scanner from scan config. Scanning
+// is skipped when disabled or when there is no object storage to read from.
+func newUploadScanner(cfg *config.Config, s3Service *services.S3Service) *services.UploadScanner {
+	if cfg == nil || !cfg.Scan.Enabled || s3Service == nil {
+		return services.NewUploadScanner(nil, nil, false, false)
+	}
+	scanner := services.NewClamAVScanner(cfg.Scan.ClamAVAddress, cfg.Scan.Timeout)
+	return services.NewUploadScanner(scanner, s3Service, true, cfg.Scan.FailOpen)
+}
+
+func (h *SystemHandlers) Health(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	healthStatus := map[string]interface{}{
 		"status":  "ok",
@@ -121,10 +193,16 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 		healthStatus["ai_service"] = "ok"
 	}
 
+	// Report queue depth for backpressure visibility
+	if depth, err := h.jobRepo.GetQueueDepth(ctx, uuid.Nil); err == nil {
+		healthStatus["queue_depth"] = depth.Total
+		healthStatus["queue_limit"] = h.config.Worker.MaxQueuedJobs
+	}
+
 	respondJSON(w, http.StatusOK, healthStatus)
 }
 
-func (h *Handler) Root(w http.ResponseWriter, r *http.Request) {
+func (h *SystemHandlers) Root(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{
 		"message": "Construction Estimation & Bidding Automation API",
 		"version": "1.0.0",
@@ -140,8 +218,29 @@ func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	}
 }
 
+// respondError writes an error response with the generic code for status.
+// Prefer respondAPIError where a more specific code applies.
 func respondError(w http.ResponseWriter, status int, message string) {
-	respondJSON(w, status, map[string]string{"error": message})
+	respondAPIError(w, status, statusErrorCode(status), message)
+}
+
+// bulkStatus is the HTTP status for a bulk operation: 200 when no item
+// failed, 400 when items failed and none succeeded, 207 Multi-Status otherwise
+func bulkStatus(result models.BulkResult) int {
+	switch {
+	case result.Failed == 0:
+		return http.StatusOK
+	case result.Succeeded == 0:
+		return http.StatusBadRequest
+	default:
+		return http.StatusMultiStatus
+	}
+}
+
+// respondBulk writes body, a response embedding result, with the status
+// bulkStatus gives for result
+func respondBulk(w http.ResponseWriter, result models.BulkResult, body interface{}) {
+	respondJSON(w, bulkStatus(result), body)
 }
 
 // Helper functions to extract values from context
@@ -165,3 +264,46 @@ func getCorrelationID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// inTx runs fn in a transaction, or directly when there is no database to
+// start one on
+func inTx(ctx context.Context, tx Transactor, fn func(ctx context.Context) error) error {
+	if tx == nil {
+		return fn(ctx)
+	}
+	return tx.InTx(ctx, fn)
+}
+
+// newBidPDFGenerator builds the bid PDF generator. Superseded PDFs are only
+// scheduled for deletion when there is a repository to record them in.
+func newBidPDFGenerator(
+	cfg *config.Config,
+	bidRepo *repository.BidRepository,
+	projectRepo *repository.ProjectRepository,
+	blueprintRepo *repository.BlueprintRepository,
+	userRepo *repository.UserRepository,
+	profileRepo *repository.CompanyProfileRepository,
+	acceptanceRepo *repository.BidAcceptanceRepository,
+	objectDeletionRepo *repository.ObjectDeletionRepository,
+	s3Service *services.S3Service,
+	aiService services.AIProvider,
+) *services.BidPDFGenerator {
+	var deletions services.ObjectDeletionScheduler
+	if objectDeletionRepo != nil {
+		deletions = objectDeletionRepo
+	}
+	var retention time.Duration
+	if cfg != nil {
+		retention = cfg.S3.SupersededRetention
+	}
+	var files services.BlueprintFileSource
+	if s3Service != nil {
+		files = s3Service
+	}
+	rasterizer, _ := aiService.(services.PDFPageRasterizer)
+
+	publisher := services.NewBidPDFPublisher(s3Service, deletions, retention)
+	pages := services.NewBlueprintPageRenderer(files, rasterizer)
+	return services.NewBidPDFGenerator(bidRepo, projectRepo, blueprintRepo, userRepo, profileRepo, publisher, pages, files).
+		WithAcceptances(acceptanceRepo)
+}
@@ -1,43 +1,111 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
 )
 
+// JobHandlers queues blueprint analysis and reports job status
+type JobHandlers struct {
+	projectRepo   ProjectStore
+	blueprintRepo BlueprintStore
+	jobRepo       JobStore
+	config        *config.Config
+}
+
+func NewJobHandlers(projectRepo ProjectStore, blueprintRepo BlueprintStore, jobRepo JobStore, cfg *config.Config) *JobHandlers {
+	return &JobHandlers{
+		projectRepo:   projectRepo,
+		blueprintRepo: blueprintRepo,
+		jobRepo:       jobRepo,
+		config:        cfg,
+	}
+}
+
+// Routes registers the analysis job routes
+func (h *JobHandlers) Routes(r chi.Router) {
+	r.Post("/blueprints/{id}/analyze", h.AnalyzeBlueprint)
+	r.Post("/projects/{id}/analyze-all", h.AnalyzeAllBlueprints)
+	r.Get("/jobs/{id}", h.GetJobStatus)
+	r.Post("/jobs/{id}/retry", h.RetryJob)
+}
+
 type AnalyzeResponse struct {
 	JobID  uuid.UUID `json:"job_id"`
 	Status string    `json:"status"`
 }
 
+// Reasons a blueprint is skipped by analyze-all, reported as item error
+// codes. An enqueue failure is reported as a failed item.
+const (
+	SkipReasonAlreadyAnalyzed  = "already_analyzed"
+	SkipReasonUploadIncomplete = "upload_incomplete"
+	SkipReasonJobActive        = "job_already_active"
+	SkipReasonEnqueueFailed    = "enqueue_failed"
+)
+
+var skipReasonMessages = map[string]string{
+	SkipReasonAlreadyAnalyzed:  "already analyzed; pass reanalyze=true to analyze again",
+	SkipReasonUploadIncomplete: "upload has not completed",
+	SkipReasonJobActive:        "analysis is already queued or running",
+	SkipReasonEnqueueFailed:    "failed to queue analysis",
+}
+
+type BatchAnalyzeJob struct {
+	BlueprintID uuid.UUID `json:"blueprint_id"`
+	JobID       uuid.UUID `json:"job_id"`
+}
+
+type SkippedBlueprint struct {
+	BlueprintID uuid.UUID `json:"blueprint_id"`
+	Filename    string    `json:"filename"`
+	Reason      string    `json:"reason"`
+}
+
+// AnalyzeAllResponse has one item per project blueprint, keyed by blueprint
+// ID and indexed in project order. Queued items carry a BatchAnalyzeJob.
+type AnalyzeAllResponse struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	models.BulkResult
+}
+
 type JobStatusResponse struct {
-	ID           uuid.UUID  `json:"id"`
-	BlueprintID  uuid.UUID  `json:"blueprint_id"`
-	JobType      string     `json:"job_type"`
-	Status       string     `json:"status"`
-	StartedAt    *time.Time `json:"started_at"`
-	CompletedAt  *time.Time `json:"completed_at"`
-	ErrorMessage *string    `json:"error_message"`
-	ResultData   *string    `json:"result_data"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-}
-
-func (h *Handler) AnalyzeBlueprint(w http.ResponseWriter, r *http.Request) {
-	blueprintID, err := uuid.Parse(chi.URLParam(r, "id"))
+	ID              uuid.UUID         `json:"id"`
+	BlueprintID     uuid.UUID         `json:"blueprint_id"`
+	JobType         string            `json:"job_type"`
+	Status          string            `json:"status"`
+	Progress        int               `json:"progress"`
+	ProgressMessage *string           `json:"progress_message,omitempty"`
+	StartedAt       *models.Timestamp `json:"started_at"`
+	CompletedAt     *models.Timestamp `json:"completed_at"`
+	ErrorMessage    *string           `json:"error_message"`
+	ResultData      *string           `json:"result_data"`
+	TargetProjectID *uuid.UUID        `json:"target_project_id,omitempty"`
+	BidID           *uuid.UUID        `json:"bid_id,omitempty"`
+	CreatedAt       models.Timestamp  `json:"created_at"`
+	UpdatedAt       models.Timestamp  `json:"updated_at"`
+}
+
+func (h *JobHandlers) AnalyzeBlueprint(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid blueprint ID")
+		respondInvalidID(w)
 		return
 	}
 
 	// Get blueprint record
-	blueprint, err := h.blueprintRepo.GetByID(r.Context(), blueprintID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "Blueprint not found")
+	blueprint, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
 		return
 	}
 
@@ -47,61 +115,348 @@ func (h *Handler) AnalyzeBlueprint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create job record
-	jobID := uuid.New()
+	// Refuse to queue a second analysis while one is in flight
+	active, err := h.jobRepo.GetActiveTakeoffBlueprintIDs(r.Context(), []uuid.UUID{blueprintID})
+	if err != nil {
+		slog.Error("Failed to check active jobs", "blueprint_id", blueprintID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create job")
+		return
+	}
+	if active[blueprintID] {
+		respondError(w, http.StatusConflict, "Analysis already in progress for this blueprint")
+		return
+	}
+
+	if !h.ensureQueueCapacity(w, r, 1) {
+		return
+	}
+
+	// reanalyze=true asks the worker to send the previous analysis as context
+	reanalyze := r.URL.Query().Get("reanalyze") == "true"
+
+	job, err := h.enqueueTakeoffJob(r.Context(), blueprint, reanalyze)
+	if err != nil {
+		slog.Error("Failed to enqueue analysis", "blueprint_id", blueprintID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create job")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, AnalyzeResponse{
+		JobID:  job.ID,
+		Status: string(job.Status),
+	})
+}
+
+// AnalyzeAllBlueprints queues takeoff jobs for every eligible blueprint in a
+// project. Each blueprint is handled independently, so partial success is
+// reported rather than rolled back.
+func (h *JobHandlers) AnalyzeAllBlueprints(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	reanalyze := r.URL.Query().Get("reanalyze") == "true"
+
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return
+	}
+
+	blueprints, err := h.blueprintRepo.GetByProjectID(r.Context(), project.ID)
+	if err != nil {
+		slog.Error("Failed to get project blueprints", "project_id", project.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get blueprints")
+		return
+	}
+
+	blueprintIDs := make([]uuid.UUID, len(blueprints))
+	for i, bp := range blueprints {
+		blueprintIDs[i] = bp.ID
+	}
+	active, err := h.jobRepo.GetActiveTakeoffBlueprintIDs(r.Context(), blueprintIDs)
+	if err != nil {
+		slog.Error("Failed to check active jobs", "project_id", project.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to check active jobs")
+		return
+	}
+
+	toAnalyze, skipped := planBatchAnalysis(blueprints, active, reanalyze)
+
+	// The batch is admitted or rejected as a whole so callers never get a
+	// silently truncated analysis run
+	if len(toAnalyze) > 0 && !h.ensureQueueCapacity(w, r, len(toAnalyze)) {
+		return
+	}
+
+	skippedByID := make(map[uuid.UUID]SkippedBlueprint, len(skipped))
+	for _, skip := range skipped {
+		skippedByID[skip.BlueprintID] = skip
+	}
+
+	// Items are reported in project order
+	response := AnalyzeAllResponse{ProjectID: project.ID, BulkResult: models.NewBulkResult()}
+	for i, bp := range blueprints {
+		if skip, ok := skippedByID[bp.ID]; ok {
+			response.Skip(i, bp.ID.String(), skip.Reason, skipReasonMessage(skip))
+			continue
+		}
+		job, err := h.enqueueTakeoffJob(r.Context(), bp, reanalyze)
+		if err != nil {
+			slog.Error("Failed to enqueue analysis", "blueprint_id", bp.ID, "error", err)
+			skip := SkippedBlueprint{BlueprintID: bp.ID, Filename: bp.Filename, Reason: SkipReasonEnqueueFailed}
+			response.Fail(i, bp.ID.String(), skip.Reason, skipReasonMessage(skip))
+			continue
+		}
+		response.Succeed(i, bp.ID.String(), BatchAnalyzeJob{BlueprintID: bp.ID, JobID: job.ID})
+	}
+
+	slog.Info("Batch analysis queued",
+		"project_id", project.ID,
+		"queued", response.Succeeded,
+		"skipped", response.Skipped,
+		"failed", response.Failed,
+		"correlation_id", getCorrelationID(r.Context()))
+
+	respondBulk(w, response.BulkResult, response)
+}
+
+// skipReasonMessage describes why analyze-all did not queue a blueprint
+func skipReasonMessage(skip SkippedBlueprint) string {
+	return fmt.Sprintf("%s: %s", skip.Filename, skipReasonMessages[skip.Reason])
+}
+
+// planBatchAnalysis splits a project's blueprints into those that should be
+// analyzed and those that are skipped, with the reason for each skip
+func planBatchAnalysis(blueprints []*models.Blueprint, active map[uuid.UUID]bool, reanalyze bool) ([]*models.Blueprint, []SkippedBlueprint) {
+	toAnalyze := []*models.Blueprint{}
+	skipped := []SkippedBlueprint{}
+
+	for _, bp := range blueprints {
+		var reason string
+		switch {
+		case bp.UploadStatus != models.UploadStatusUploaded:
+			reason = SkipReasonUploadIncomplete
+		case active[bp.ID]:
+			reason = SkipReasonJobActive
+		case !reanalyze && bp.AnalysisStatus == models.AnalysisStatusCompleted:
+			reason = SkipReasonAlreadyAnalyzed
+		}
+
+		if reason != "" {
+			skipped = append(skipped, SkippedBlueprint{BlueprintID: bp.ID, Filename: bp.Filename, Reason: reason})
+			continue
+		}
+		toAnalyze = append(toAnalyze, bp)
+	}
+
+	return toAnalyze, skipped
+}
+
+// ensureQueueCapacity checks the queue ceilings before requested jobs are
+// created. It writes a 429 and returns false when the queue is full.
+func (h *JobHandlers) ensureQueueCapacity(w http.ResponseWriter, r *http.Request, requested int) bool {
+	return ensureQueueCapacity(w, r, h.jobRepo, h.config, requested)
+}
+
+// ensureQueueCapacity checks the queue ceilings before requested jobs are
+// created for the request's user. It writes a 429 QUEUE_FULL and returns
+// false when the queue is full.
+func ensureQueueCapacity(w http.ResponseWriter, r *http.Request, jobs QueueDepthReader, cfg *config.Config, requested int) bool {
+	queueErr, err := checkQueueCapacity(r.Context(), jobs, cfg, requested)
+	if err != nil {
+		slog.Error("Failed to get queue depth", "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create job")
+		return false
+	}
+	if queueErr != nil {
+		respondQueueFull(w, queueErr)
+		return false
+	}
+	return true
+}
+
+// checkQueueCapacity returns the QueueFullError for a full queue when
+// requested more jobs for the context's user would exceed a ceiling
+func checkQueueCapacity(ctx context.Context, jobs QueueDepthReader, cfg *config.Config, requested int) (*services.QueueFullError, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	userID, _ := uuid.Parse(getUserID(ctx))
+
+	depth, err := jobs.GetQueueDepth(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	queueErr := services.CheckQueueCapacity(depth, requested, &cfg.Worker)
+	if queueErr != nil {
+		slog.Warn("Job queue full",
+			"scope", queueErr.Scope,
+			"queue_depth", depth.Total,
+			"user_queue_depth", depth.User,
+			"requested", requested,
+			"correlation_id", getCorrelationID(ctx))
+	}
+	return queueErr, nil
+}
+
+// respondQueueFull writes the 429 response for job creation that would
+// exceed a queue ceiling, with Retry-After guidance
+func respondQueueFull(w http.ResponseWriter, queueErr *services.QueueFullError) {
+	retryAfter := int(math.Ceil(queueErr.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	respondAPIErrorDetails(w, http.StatusTooManyRequests, CodeQueueFull, "Job queue is full, retry later", map[string]interface{}{
+		"scope":               queueErr.Scope,
+		"queue_depth":         queueErr.Depth.Total,
+		"user_queue_depth":    queueErr.Depth.User,
+		"limit":               queueErr.Limit,
+		"retry_after_seconds": retryAfter,
+	})
+}
+
+// enqueueTakeoffJob creates a queued takeoff job and marks the blueprint
+// queued. reanalyze is stored on the job for the worker.
+func (h *JobHandlers) enqueueTakeoffJob(ctx context.Context, blueprint *models.Blueprint, reanalyze bool) (*models.Job, error) {
 	job := &models.Job{
-		ID:          jobID,
-		BlueprintID: blueprintID,
+		ID:          uuid.New(),
+		BlueprintID: blueprint.ID,
 		JobType:     models.JobTypeTakeoff,
 		Status:      models.JobStatusQueued,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   models.Now(),
+		UpdatedAt:   models.Now(),
 		RetryCount:  0,
+		// Recorded so the worker can tell the file was replaced mid-analysis
+		UploadGeneration: blueprint.UploadGeneration,
+		Reanalyze:        reanalyze,
 	}
 
-	if err := h.jobRepo.Create(r.Context(), job); err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to create job")
-		return
+	if err := h.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
 	}
 
 	// Update blueprint analysis status to queued
 	blueprint.AnalysisStatus = models.AnalysisStatusQueued
-	blueprint.UpdatedAt = time.Now()
-	if err := h.blueprintRepo.Update(r.Context(), blueprint); err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to update blueprint status")
+	blueprint.UpdatedAt = models.Now()
+	if err := h.blueprintRepo.Update(ctx, blueprint); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (h *JobHandlers) GetJobStatus(w http.ResponseWriter, r *http.Request) {
+	jobID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, AnalyzeResponse{
-		JobID:  jobID,
-		Status: string(models.JobStatusQueued),
-	})
+	// Get job record; a job is the user's when its blueprint is
+	job, err := h.jobRepo.GetByID(r.Context(), jobID)
+	if err != nil {
+		respondNotFound(w)
+		return
+	}
+	if _, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, job.BlueprintID); !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newJobStatusResponse(job))
 }
 
-func (h *Handler) GetJobStatus(w http.ResponseWriter, r *http.Request) {
-	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+// RetryJob requeues a failed job of the user's for a fresh set of attempts.
+// An analysis job analyzes the blueprint's current upload.
+func (h *JobHandlers) RetryJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid job ID")
+		respondInvalidID(w)
 		return
 	}
 
-	// Get job record
 	job, err := h.jobRepo.GetByID(r.Context(), jobID)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Job not found")
+		respondNotFound(w)
+		return
+	}
+	blueprint, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, job.BlueprintID)
+	if !ok {
 		return
 	}
 
-	respondJSON(w, http.StatusOK, JobStatusResponse{
-		ID:           job.ID,
-		BlueprintID:  job.BlueprintID,
-		JobType:      string(job.JobType),
-		Status:       string(job.Status),
-		StartedAt:    job.StartedAt,
-		CompletedAt:  job.CompletedAt,
-		ErrorMessage: job.ErrorMessage,
-		ResultData:   job.ResultData,
-		CreatedAt:    job.CreatedAt,
-		UpdatedAt:    job.UpdatedAt,
-	})
+	if job.Status != models.JobStatusFailed {
+		respondError(w, http.StatusConflict, fmt.Sprintf("Only failed jobs can be retried; job is %s", job.Status))
+		return
+	}
+	if job.JobType == models.JobTypeTakeoff {
+		active, err := h.jobRepo.GetActiveTakeoffBlueprintIDs(r.Context(), []uuid.UUID{blueprint.ID})
+		if err != nil {
+			slog.Error("Failed to check active jobs", "blueprint_id", blueprint.ID, "error", err)
+			respondError(w, http.StatusInternalServerError, "Failed to retry job")
+			return
+		}
+		if active[blueprint.ID] {
+			respondError(w, http.StatusConflict, "Analysis already in progress for this blueprint")
+			return
+		}
+	}
+
+	if !h.ensureQueueCapacity(w, r, 1) {
+		return
+	}
+
+	job.Status = models.JobStatusQueued
+	job.RetryCount = 0
+	job.StartedAt = nil
+	job.CompletedAt = nil
+	job.ErrorMessage = nil
+	job.Progress = 0
+	job.ProgressMessage = nil
+	job.UpdatedAt = models.Now()
+	if job.JobType == models.JobTypeTakeoff {
+		job.UploadGeneration = blueprint.UploadGeneration
+	}
+	if err := h.jobRepo.Update(r.Context(), job); err != nil {
+		slog.Error("Failed to requeue job", "job_id", job.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to retry job")
+		return
+	}
+
+	if job.JobType == models.JobTypeTakeoff {
+		blueprint.AnalysisStatus = models.AnalysisStatusQueued
+		blueprint.UpdatedAt = models.Now()
+		if err := h.blueprintRepo.Update(r.Context(), blueprint); err != nil {
+			slog.Error("Failed to update blueprint status to queued", "blueprint_id", blueprint.ID, "error", err)
+		}
+	}
+
+	slog.Info("Job requeued by user",
+		"audit_event", "job.retried",
+		"job_id", job.ID,
+		"job_type", job.JobType,
+		"user_id", getUserID(r.Context()),
+		"correlation_id", getCorrelationID(r.Context()))
+
+	respondJSON(w, http.StatusOK, newJobStatusResponse(job))
+}
+
+// newJobStatusResponse reports a job's progress and outcome
+func newJobStatusResponse(job *models.Job) JobStatusResponse {
+	return JobStatusResponse{
+		ID:              job.ID,
+		BlueprintID:     job.BlueprintID,
+		JobType:         string(job.JobType),
+		Status:          string(job.Status),
+		Progress:        job.Progress,
+		ProgressMessage: job.ProgressMessage,
+		StartedAt:       job.StartedAt,
+		CompletedAt:     job.CompletedAt,
+		ErrorMessage:    job.ErrorMessage,
+		ResultData:      job.ResultData,
+		TargetProjectID: job.TargetProjectID,
+		BidID:           job.BidID,
+		CreatedAt:       job.CreatedAt,
+		UpdatedAt:       job.UpdatedAt,
+	}
 }
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestPlanBatchAnalysis(t *testing.T) {
+	fresh := &models.Blueprint{ID: uuid.New(), Filename: "A-101.pdf", UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusNotStarted}
+	failed := &models.Blueprint{ID: uuid.New(), Filename: "A-102.pdf", UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusFailed}
+	analyzed := &models.Blueprint{ID: uuid.New(), Filename: "A-103.pdf", UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusCompleted}
+	pending := &models.Blueprint{ID: uuid.New(), Filename: "A-104.pdf", UploadStatus: models.UploadStatusPending, AnalysisStatus: models.AnalysisStatusNotStarted}
+	inFlight := &models.Blueprint{ID: uuid.New(), Filename: "A-105.pdf", UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusQueued}
+
+	blueprints := []*models.Blueprint{fresh, failed, analyzed, pending, inFlight}
+	active := map[uuid.UUID]bool{inFlight.ID: true}
+
+	tests := []struct {
+		name        string
+		reanalyze   bool
+		wantQueued  []uuid.UUID
+		wantSkipped map[uuid.UUID]string
+	}{
+		{
+			name:       "mixed project",
+			reanalyze:  false,
+			wantQueued: []uuid.UUID{fresh.ID, failed.ID},
+			wantSkipped: map[uuid.UUID]string{
+				analyzed.ID: SkipReasonAlreadyAnalyzed,
+				pending.ID:  SkipReasonUploadIncomplete,
+				inFlight.ID: SkipReasonJobActive,
+			},
+		},
+		{
+			name:       "reanalyze includes analyzed blueprints",
+			reanalyze:  true,
+			wantQueued: []uuid.UUID{fresh.ID, failed.ID, analyzed.ID},
+			wantSkipped: map[uuid.UUID]string{
+				pending.ID:  SkipReasonUploadIncomplete,
+				inFlight.ID: SkipReasonJobActive,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queued, skipped := planBatchAnalysis(blueprints, active, tt.reanalyze)
+
+			if len(queued) != len(tt.wantQueued) {
+				t.Fatalf("expected %d queued, got %d", len(tt.wantQueued), len(queued))
+			}
+			for i, bp := range queued {
+				if bp.ID != tt.wantQueued[i] {
+					t.Errorf("queued[%d]: expected %s, got %s", i, tt.wantQueued[i], bp.ID)
+				}
+			}
+
+			if len(skipped) != len(tt.wantSkipped) {
+				t.Fatalf("expected %d skipped, got %d", len(tt.wantSkipped), len(skipped))
+			}
+			for _, s := range skipped {
+				if want := tt.wantSkipped[s.BlueprintID]; s.Reason != want {
+					t.Errorf("blueprint %s: expected reason %q, got %q", s.Filename, want, s.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeAllBlueprints(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	fresh := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "A-101.pdf", UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusNotStarted}
+	analyzed := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "A-102.pdf", UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusCompleted}
+	broken := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "A-103.pdf", UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusNotStarted}
+
+	tests := []struct {
+		name       string
+		failCreate map[uuid.UUID]bool
+		wantStatus int
+		wantItems  map[uuid.UUID]models.BulkItemStatus
+	}{
+		{
+			name:       "queued and skipped",
+			wantStatus: http.StatusOK,
+			wantItems: map[uuid.UUID]models.BulkItemStatus{
+				fresh.ID:    models.BulkItemSucceeded,
+				analyzed.ID: models.BulkItemSkipped,
+				broken.ID:   models.BulkItemSucceeded,
+			},
+		},
+		{
+			name:       "one enqueue failure",
+			failCreate: map[uuid.UUID]bool{broken.ID: true},
+			wantStatus: http.StatusMultiStatus,
+			wantItems: map[uuid.UUID]models.BulkItemStatus{
+				fresh.ID:    models.BulkItemSucceeded,
+				analyzed.ID: models.BulkItemSkipped,
+				broken.ID:   models.BulkItemFailed,
+			},
+		},
+		{
+			name:       "every enqueue failed",
+			failCreate: map[uuid.UUID]bool{fresh.ID: true, broken.ID: true},
+			wantStatus: http.StatusBadRequest,
+			wantItems: map[uuid.UUID]models.BulkItemStatus{
+				fresh.ID:    models.BulkItemFailed,
+				analyzed.ID: models.BulkItemSkipped,
+				broken.ID:   models.BulkItemFailed,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blueprints := &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{}}
+			for _, bp := range []*models.Blueprint{fresh, analyzed, broken} {
+				copied := *bp
+				blueprints.blueprints[bp.ID] = &copied
+			}
+			h := NewJobHandlers(&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+				blueprints, &fakeJobStore{failCreate: tt.failCreate}, &config.Config{})
+			router := chi.NewRouter()
+			h.Routes(router)
+
+			rec := serveAsUser(router, userID, http.MethodPost, "/projects/"+project.ID.String()+"/analyze-all", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, body %s; want %d", rec.Code, rec.Body.String(), tt.wantStatus)
+			}
+
+			var body AnalyzeAllResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			assertBulkEnvelope(t, body.BulkResult)
+			if body.ProjectID != project.ID || body.Total != len(tt.wantItems) {
+				t.Fatalf("unexpected envelope: %+v", body)
+			}
+			for _, item := range body.Items {
+				id := uuid.MustParse(item.Key)
+				if item.Status != tt.wantItems[id] {
+					t.Errorf("blueprint %s: status %s, want %s", item.Key, item.Status, tt.wantItems[id])
+				}
+				if id == analyzed.ID && item.ErrorCode != SkipReasonAlreadyAnalyzed {
+					t.Errorf("analyzed blueprint error code = %q", item.ErrorCode)
+				}
+				if item.Status == models.BulkItemFailed && item.ErrorCode != SkipReasonEnqueueFailed {
+					t.Errorf("failed blueprint error code = %q", item.ErrorCode)
+				}
+				if item.Status == models.BulkItemSucceeded && item.Result == nil {
+					t.Errorf("queued blueprint %s has no job", item.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeBlueprint_Reanalyze(t *testing.T) {
+	userID := uuid.New()
+	for _, tt := range []struct {
+		query string
+		want  bool
+	}{
+		{query: "", want: false},
+		{query: "?reanalyze=true", want: true},
+	} {
+		project := &models.Project{ID: uuid.New(), UserID: userID}
+		blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusCompleted}
+		jobs := &fakeJobStore{}
+		h := NewJobHandlers(&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}}, &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}},
+			jobs, &config.Config{})
+		router := chi.NewRouter()
+		h.Routes(router)
+
+		rec := serveAsUser(router, userID, http.MethodPost, "/blueprints/"+blueprint.ID.String()+"/analyze"+tt.query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: status = %d, body %s", tt.query, rec.Code, rec.Body.String())
+		}
+		if len(jobs.jobs) != 1 {
+			t.Fatalf("%q: expected one job, got %d", tt.query, len(jobs.jobs))
+		}
+		for _, job := range jobs.jobs {
+			if job.Reanalyze != tt.want {
+				t.Errorf("%q: job reanalyze = %v, want %v", tt.query, job.Reanalyze, tt.want)
+			}
+		}
+	}
+}
+
+func TestRetryJob(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID}
+	blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, UploadStatus: models.UploadStatusUploaded,
+		AnalysisStatus: models.AnalysisStatusFailed, UploadGeneration: 2}
+	message := "ai service unavailable"
+	completedAt := models.Now()
+	failed := &models.Job{ID: uuid.New(), BlueprintID: blueprint.ID, JobType: models.JobTypeTakeoff, Status: models.JobStatusFailed,
+		RetryCount: 3, ErrorMessage: &message, CompletedAt: &completedAt, Progress: 40, UploadGeneration: 1}
+	completed := &models.Job{ID: uuid.New(), BlueprintID: blueprint.ID, JobType: models.JobTypeTakeoff, Status: models.JobStatusCompleted}
+	jobs := &fakeJobStore{jobs: map[uuid.UUID]*models.Job{failed.ID: failed, completed.ID: completed}}
+	h := NewJobHandlers(&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		&fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}}, jobs, &config.Config{})
+	router := chi.NewRouter()
+	h.Routes(router)
+	retry := func(userID, jobID uuid.UUID) *httptest.ResponseRecorder {
+		return serveAsUser(router, userID, http.MethodPost, "/jobs/"+jobID.String()+"/retry", "")
+	}
+
+	if rec := retry(uuid.New(), failed.ID); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's job: status = %d, want 404", rec.Code)
+	}
+	if rec := retry(userID, uuid.New()); rec.Code != http.StatusNotFound {
+		t.Errorf("missing job: status = %d, want 404", rec.Code)
+	}
+	if rec := retry(userID, completed.ID); rec.Code != http.StatusConflict {
+		t.Errorf("completed job: status = %d, want 409", rec.Code)
+	}
+
+	jobs.active = map[uuid.UUID]bool{blueprint.ID: true}
+	if rec := retry(userID, failed.ID); rec.Code != http.StatusConflict {
+		t.Errorf("blueprint with an active analysis: status = %d, want 409", rec.Code)
+	}
+	jobs.active = nil
+
+	rec := retry(userID, failed.ID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	var got JobStatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || got.Status != string(models.JobStatusQueued) {
+		t.Errorf("response = %+v (%v), want the queued job", got, err)
+	}
+	if failed.Status != models.JobStatusQueued || failed.RetryCount != 0 || failed.ErrorMessage != nil || failed.CompletedAt != nil || failed.Progress != 0 {
+		t.Errorf("job = %s after %d retries (%v), want queued with a fresh set of retries", failed.Status, failed.RetryCount, failed.ErrorMessage)
+	}
+	if failed.UploadGeneration != 2 || blueprint.AnalysisStatus != models.AnalysisStatusQueued {
+		t.Errorf("job generation = %d, blueprint %s; want the current upload queued", failed.UploadGeneration, blueprint.AnalysisStatus)
+	}
+
+	// Once queued it is no longer failed
+	if rec := retry(userID, failed.ID); rec.Code != http.StatusConflict {
+		t.Errorf("queued job: status = %d, want 409", rec.Code)
+	}
+}
+
+func TestRespondQueueFull(t *testing.T) {
+	cfg := &config.WorkerConfig{PollInterval: 5 * time.Second, MaxQueuedJobsPerUser: 3}
+
+	// A seeded queue at the user ceiling rejects the next job
+	depth := models.QueueDepth{Total: 40, User: 3}
+	queueErr := services.CheckQueueCapacity(depth, 1, cfg)
+	if queueErr == nil {
+		t.Fatal("expected queue to be full")
+	}
+
+	rec := httptest.NewRecorder()
+	respondQueueFull(rec, queueErr)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status 429, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "5" {
+		t.Errorf("expected Retry-After 5, got %q", got)
+	}
+
+	var body apiErrorBody
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Code != CodeQueueFull || body.Message == "" || body.Details["scope"] != "user" {
+		t.Errorf("unexpected code/scope: %s/%v", body.Code, body.Details["scope"])
+	}
+	if body.Details["queue_depth"] != 40.0 || body.Details["user_queue_depth"] != 3.0 || body.Details["limit"] != 3.0 || body.Details["retry_after_seconds"] != 5.0 {
+		t.Errorf("unexpected depth in response: %+v", body.Details)
+	}
+
+	// Once a job completes the same request is admitted
+	if err := services.CheckQueueCapacity(models.QueueDepth{Total: 39, User: 2}, 1, cfg); err != nil {
+		t.Errorf("expected capacity after completion, got %v", err)
+	}
+}
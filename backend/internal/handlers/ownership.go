@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Ownership checks for routes that name a project, or a resource under one,
+// by ID. A resource is the requesting user's when the project it belongs to
+// is. Missing and foreign resources both get the uniform 404, so another
+// user's IDs can't be probed.
+
+// loadUserProject returns a project when it belongs to the requesting user.
+// It writes a 404 and returns false otherwise.
+func loadUserProject(w http.ResponseWriter, r *http.Request, projects ProjectStore, projectID uuid.UUID) (*models.Project, bool) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondNotFound(w)
+		return nil, false
+	}
+	project, err := projects.GetByIDAndUserID(r.Context(), projectID, *userID)
+	if err != nil {
+		respondNotFound(w)
+		return nil, false
+	}
+	return project, true
+}
+
+// loadUserBlueprint returns a blueprint and its project when the project
+// belongs to the requesting user. It writes a 404 and returns false otherwise.
+func loadUserBlueprint(w http.ResponseWriter, r *http.Request, blueprints BlueprintStore, projects ProjectStore, blueprintID uuid.UUID) (*models.Blueprint, *models.Project, bool) {
+	blueprint, err := blueprints.GetByID(r.Context(), blueprintID)
+	if err != nil {
+		respondNotFound(w)
+		return nil, nil, false
+	}
+	project, ok := loadUserProject(w, r, projects, blueprint.ProjectID)
+	if !ok {
+		return nil, nil, false
+	}
+	return blueprint, project, true
+}
+
+// loadUserBid returns a bid and its project when the project belongs to the
+// requesting user. It writes a 404 and returns false otherwise.
+func loadUserBid(w http.ResponseWriter, r *http.Request, bids BidStore, projects ProjectStore, bidID uuid.UUID) (*models.Bid, *models.Project, bool) {
+	bid, err := bids.GetByID(r.Context(), bidID)
+	if err != nil {
+		respondNotFound(w)
+		return nil, nil, false
+	}
+	project, ok := loadUserProject(w, r, projects, bid.ProjectID)
+	if !ok {
+		return nil, nil, false
+	}
+	return bid, project, true
+}
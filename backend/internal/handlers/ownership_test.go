@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestOwnership_SecondUserCannotReachResources(t *testing.T) {
+	ownerID, intruderID := uuid.New(), uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: ownerID, Name: "Owner's project"}
+	analysis := `{"rooms":[{"name":"Office","dimensions":"10x20","area":200}],"openings":[{"opening_type":"door","count":2}],"confidence_score":0.9}`
+	blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID, Filename: "plans.pdf", UploadStatus: models.UploadStatusUploaded, AnalysisData: &analysis}
+	name := "Base Bid"
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, Name: &name, Version: 1, BlueprintIDs: []uuid.UUID{blueprint.ID}}
+	job := &models.Job{ID: uuid.New(), BlueprintID: blueprint.ID, JobType: models.JobTypeTakeoff, Status: models.JobStatusCompleted}
+
+	projects := &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}}
+	blueprints := &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}}
+	bids := &fakeBidStore{bids: []*models.Bid{bid}}
+	jobs := &fakeJobStore{jobs: map[uuid.UUID]*models.Job{job.ID: job}}
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{ownerID: {ID: ownerID}, intruderID: {ID: intruderID}}}
+	bidRevisions := &fakeBidRevisionStore{}
+	blueprintRevisions := &fakeBlueprintRevisionStore{}
+
+	router := chi.NewRouter()
+	(&BidHandlers{
+		PricingSources:  NewPricingSources(nil, nil, nil, nil, nil, nil),
+		projectRepo:     projects,
+		blueprintRepo:   blueprints,
+		bidRepo:         bids,
+		bidRevisionRepo: bidRevisions,
+		userRepo:        users,
+		config:          &config.Config{},
+	}).Routes(router)
+	(&BlueprintHandlers{projectRepo: projects, blueprintRepo: blueprints, userRepo: users, fileValidator: services.NewFileValidator()}).Routes(router)
+	NewJobHandlers(projects, blueprints, jobs, &config.Config{}).Routes(router)
+	NewRevisionHandlers(projects, blueprints, blueprintRevisions, nil, bids, bidRevisions, users, nil, nil).Routes(router)
+
+	projectPath := "/projects/" + project.ID.String()
+	blueprintPath := "/blueprints/" + blueprint.ID.String()
+	bidPath := "/bids/" + bid.ID.String()
+	routes := []struct {
+		method, path, body string
+	}{
+		{http.MethodGet, projectPath + "/bids", ""},
+		{http.MethodGet, projectPath + "/pricing-summary?blueprint_id=" + blueprint.ID.String(), ""},
+		{http.MethodPost, projectPath + "/generate-bid", `{"blueprint_id":"` + blueprint.ID.String() + `"}`},
+		{http.MethodPost, projectPath + "/blueprints/upload-url", `{"filename":"plans.pdf","content_type":"application/pdf"}`},
+		{http.MethodPost, projectPath + "/analyze-all", ""},
+		{http.MethodGet, projectPath + "/takeoff-summary", ""},
+		{http.MethodGet, blueprintPath + "/analysis", ""},
+		{http.MethodGet, blueprintPath + "/takeoff-summary", ""},
+		{http.MethodPut, blueprintPath, `{"sheet_type":"electrical"}`},
+		{http.MethodPost, blueprintPath + "/analyze", ""},
+		{http.MethodGet, blueprintPath + "/revisions", ""},
+		{http.MethodPost, blueprintPath + "/revisions", ""},
+		{http.MethodGet, "/jobs/" + job.ID.String(), ""},
+		{http.MethodGet, bidPath, ""},
+		{http.MethodPatch, bidPath, `{"name":"Taken"}`},
+		{http.MethodGet, bidPath + "/pdf", ""},
+		{http.MethodGet, bidPath + "/csv", ""},
+		{http.MethodGet, bidPath + "/revisions", ""},
+		{http.MethodPost, bidPath + "/revisions", ""},
+		{http.MethodGet, bidPath + "/compare?from=1&to=2", ""},
+	}
+	for _, route := range routes {
+		rec := serveAsUser(router, intruderID, route.method, route.path, route.body)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s as another user: status = %d, body %s; want 404", route.method, route.path, rec.Code, rec.Body.String())
+		}
+	}
+
+	if *bid.Name != name || bid.Version != 1 || blueprint.SheetType != nil {
+		t.Errorf("another user changed the owner's resources: bid %q v%d, sheet type %v", *bid.Name, bid.Version, blueprint.SheetType)
+	}
+	if len(jobs.jobs) != 1 || len(bidRevisions.revisions) != 0 || len(blueprintRevisions.revisions) != 0 {
+		t.Errorf("another user created %d jobs, %d bid revisions and %d blueprint revisions",
+			len(jobs.jobs)-1, len(bidRevisions.revisions), len(blueprintRevisions.revisions))
+	}
+
+	// The owner still reaches every resource
+	for _, path := range []string{projectPath + "/bids", blueprintPath + "/analysis", "/jobs/" + job.ID.String(), bidPath, bidPath + "/revisions"} {
+		if rec := serveAsUser(router, ownerID, http.MethodGet, path, ""); rec.Code != http.StatusOK {
+			t.Errorf("GET %s as the owner: status = %d, body %s; want 200", path, rec.Code, rec.Body.String())
+		}
+	}
+}
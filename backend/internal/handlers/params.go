@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// Error codes for malformed IDs and missing resources. Not-found responses are
+// identical for every resource type, and for resources the caller doesn't own,
+// so existence can't be probed.
+const (
+	CodeInvalidID        = "INVALID_ID"
+	CodeResourceNotFound = "RESOURCE_NOT_FOUND"
+)
+
+// Error codes for uploads refused by the virus scan
+const (
+	CodeFileRejectedMalware = "FILE_REJECTED_MALWARE"
+	CodeScanUnavailable     = "SCAN_UNAVAILABLE"
+)
+
+// CodeLowConfidence refuses pricing from an analysis whose confidence score
+// is below the configured minimum
+const CodeLowConfidence = "low_confidence"
+
+// InvalidIDError reports a path parameter that is not a valid UUID
+type InvalidIDError struct {
+	Param string
+	Value string
+}
+
+func (e *InvalidIDError) Error() string {
+	return fmt.Sprintf("invalid %s: %q is not a UUID", e.Param, e.Value)
+}
+
+// parseUUIDParam parses a UUID chi path parameter, returning *InvalidIDError
+// when it is malformed
+func parseUUIDParam(r *http.Request, name string) (uuid.UUID, error) {
+	value := chi.URLParam(r, name)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, &InvalidIDError{Param: name, Value: value}
+	}
+	return id, nil
+}
+
+// respondInvalidID writes the uniform 400 response for a malformed ID
+func respondInvalidID(w http.ResponseWriter) {
+	respondAPIError(w, http.StatusBadRequest, CodeInvalidID, "Invalid ID")
+}
+
+// respondNotFound writes the uniform 404 response for missing or foreign resources
+func respondNotFound(w http.ResponseWriter) {
+	respondAPIError(w, http.StatusNotFound, CodeResourceNotFound, "Resource not found")
+}
+
+// requestUnitSystem resolves the units query parameter, falling back to the
+// requesting user's default unit system and then to imperial. It writes a
+// 400 response and returns false when the parameter is invalid.
+func requestUnitSystem(w http.ResponseWriter, r *http.Request, users UserStore) (models.UnitSystem, bool) {
+	system, err := services.ParseUnitSystem(r.URL.Query().Get("units"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+	if system != "" {
+		return system, true
+	}
+
+	if uid := requestUserID(r); uid != nil && users != nil {
+		if user, err := users.GetUserByID(r.Context(), *uid); err == nil && user.UnitSystem != "" {
+			return user.UnitSystem, true
+		}
+	}
+	return models.UnitSystemImperial, true
+}
+
+// Page sizes for paginated lists
+const (
+	defaultListLimit = 25
+	maxListLimit     = 100
+)
+
+// parsePagination parses the limit and offset query parameters of a list.
+// Limit defaults to 25 and is capped at 100.
+func parsePagination(r *http.Request) (limit, offset int, err error) {
+	query := r.URL.Query()
+	limit = defaultListLimit
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return 0, 0, fmt.Errorf("invalid limit")
+		}
+		limit = min(limit, maxListLimit)
+	}
+	if raw := query.Get("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset")
+		}
+	}
+	return limit, offset, nil
+}
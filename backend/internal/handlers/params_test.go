@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestParseUUIDParam(t *testing.T) {
+	router := chi.NewRouter()
+	var gotErr error
+	router.Get("/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, gotErr = parseUUIDParam(r, "id")
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/things/not-a-uuid", nil))
+
+	var invalid *InvalidIDError
+	if !errors.As(gotErr, &invalid) {
+		t.Fatalf("expected InvalidIDError, got %v", gotErr)
+	}
+	if invalid.Param != "id" || invalid.Value != "not-a-uuid" {
+		t.Errorf("unexpected error fields: %+v", invalid)
+	}
+}
+
+// TestMalformedIDRoutes checks that every ID-bearing route rejects a malformed
+// ID with the same 400 body before touching any dependency
+func TestMalformedIDRoutes(t *testing.T) {
+	projects := &ProjectHandlers{}
+	blueprints := &BlueprintHandlers{}
+	jobs := &JobHandlers{}
+	bids := &BidHandlers{}
+	revisions := &RevisionHandlers{}
+	costs := &CostHandlers{}
+	admin := &AdminHandlers{}
+	webhooks := &WebhookHandlers{}
+	costData := &CostDataHandlers{}
+	projectOverrides := &ProjectPricingOverrideHandlers{}
+	shares := &BidShareHandlers{}
+	apiKeys := &APIKeyHandlers{}
+
+	routes := []struct {
+		method  string
+		pattern string
+		handler http.HandlerFunc
+	}{
+		{http.MethodGet, "/projects/{id}", projects.GetProject},
+		{http.MethodPut, "/projects/{id}", projects.UpdateProject},
+		{http.MethodDelete, "/projects/{id}", projects.DeleteProject},
+		{http.MethodPut, "/projects/{id}/budget", projects.UpdateProjectBudget},
+		{http.MethodPut, "/projects/{id}/project-type", projects.UpdateProjectType},
+		{http.MethodPut, "/projects/{id}/tax-exempt", projects.UpdateTaxExempt},
+		{http.MethodPost, "/projects/{id}/duplicate", projects.DuplicateProject},
+		{http.MethodPost, "/projects/{id}/blueprints/upload-url", blueprints.CreateUploadURL},
+		{http.MethodPost, "/blueprints/{id}/complete-upload", blueprints.CompleteUpload},
+		{http.MethodPut, "/blueprints/{id}", blueprints.UpdateBlueprint},
+		{http.MethodDelete, "/blueprints/{id}", blueprints.DeleteBlueprint},
+		{http.MethodPut, "/blueprints/{id}/room-finishes", blueprints.UpdateRoomFinishes},
+		{http.MethodPut, "/blueprints/{id}/takeoff-adjustments", blueprints.UpdateTakeoffAdjustments},
+		{http.MethodGet, "/blueprints/{id}/analysis", blueprints.GetBlueprintAnalysis},
+		{http.MethodGet, "/blueprints/{id}/assets", blueprints.GetBlueprintAssets},
+		{http.MethodGet, "/blueprints/{id}/takeoff-summary", blueprints.GetBlueprintTakeoffSummary},
+		{http.MethodGet, "/projects/{id}/takeoff-summary", blueprints.GetProjectTakeoffSummary},
+		{http.MethodGet, "/projects/{id}/blueprints/search-text", blueprints.SearchBlueprintText},
+		{http.MethodPost, "/blueprints/{id}/analyze", jobs.AnalyzeBlueprint},
+		{http.MethodPost, "/projects/{id}/analyze-all", jobs.AnalyzeAllBlueprints},
+		{http.MethodGet, "/jobs/{id}", jobs.GetJobStatus},
+		{http.MethodPost, "/jobs/{id}/retry", jobs.RetryJob},
+		{http.MethodGet, "/projects/{id}/pricing-summary", bids.GetPricingSummary},
+		{http.MethodGet, "/projects/{id}/pricing-summary/compare-regions", bids.ComparePricingRegions},
+		{http.MethodGet, "/blueprints/{id}/pricing-comparison", bids.CompareBlueprintPricing},
+		{http.MethodPost, "/projects/{id}/generate-bid", bids.GenerateBid},
+		{http.MethodPost, "/projects/{id}/bids/preview", bids.PreviewBid},
+		{http.MethodGet, "/projects/{id}/bids", bids.GetProjectBids},
+		{http.MethodGet, "/bids/{id}", bids.GetBid},
+		{http.MethodPut, "/bids/{id}", bids.UpdateBid},
+		{http.MethodPatch, "/bids/{id}", bids.RenameBid},
+		{http.MethodPatch, "/bids/{id}/status", bids.UpdateBidStatus},
+		{http.MethodPost, "/bids/{id}/reprice", bids.RepriceBid},
+		{http.MethodGet, "/bids/{id}/draft", bids.GetBidDraft},
+		{http.MethodPut, "/bids/{id}/draft", bids.SaveBidDraft},
+		{http.MethodDelete, "/bids/{id}/draft", bids.DiscardBidDraft},
+		{http.MethodPost, "/bids/{id}/draft/commit", bids.CommitBidDraft},
+		{http.MethodGet, "/bids/{id}/pdf", bids.GetBidPDF},
+		{http.MethodGet, "/bids/{id}/csv", bids.GetBidCSV},
+		{http.MethodGet, "/bids/{id}/excel", bids.GetBidExcel},
+		{http.MethodGet, "/bids/{id}/export", bids.ExportBid},
+		{http.MethodPost, "/bids/{id}/share", shares.ShareBid},
+		{http.MethodDelete, "/bids/{id}/shares/{shareId}", shares.RevokeBidShare},
+		{http.MethodGet, "/bids/{id}/engagement", shares.GetBidEngagement},
+		{http.MethodGet, "/blueprints/{id}/revisions", revisions.GetBlueprintRevisions},
+		{http.MethodPost, "/blueprints/{id}/revisions", revisions.CreateBlueprintRevision},
+		{http.MethodPost, "/blueprints/{id}/revisions/{version}/restore", revisions.RestoreBlueprintRevision},
+		{http.MethodGet, "/blueprints/{id}/compare", revisions.CompareBlueprintRevisions},
+		{http.MethodGet, "/bids/{id}/revisions", revisions.GetBidRevisions},
+		{http.MethodPost, "/bids/{id}/revisions", revisions.CreateBidRevision},
+		{http.MethodGet, "/bids/{id}/compare", revisions.CompareBidRevisions},
+		{http.MethodGet, "/bids/{id}/compare/pdf", revisions.GetBidComparisonPDF},
+		{http.MethodPut, "/api/company/pricing-overrides/{id}", costs.UpdateCompanyPricingOverride},
+		{http.MethodDelete, "/api/company/pricing-overrides/{id}", costs.DeleteCompanyPricingOverride},
+		{http.MethodGet, "/projects/{id}/pricing-overrides", projectOverrides.GetProjectPricingOverrides},
+		{http.MethodPost, "/projects/{id}/pricing-overrides", projectOverrides.CreateProjectPricingOverride},
+		{http.MethodPut, "/projects/{id}/pricing-overrides/{overrideId}", projectOverrides.UpdateProjectPricingOverride},
+		{http.MethodDelete, "/projects/{id}/pricing-overrides/{overrideId}", projectOverrides.DeleteProjectPricingOverride},
+		{http.MethodPut, "/api/materials/{id}", costData.UpdateMaterial},
+		{http.MethodDelete, "/api/materials/{id}", costData.DeleteMaterial},
+		{http.MethodPut, "/api/labor-rates/{id}", costData.UpdateLaborRate},
+		{http.MethodDelete, "/api/labor-rates/{id}", costData.DeleteLaborRate},
+		{http.MethodGet, "/api/admin/users/{id}", admin.GetUserDetail},
+		{http.MethodPost, "/api/admin/users/{id}/suspend", admin.SuspendUser},
+		{http.MethodPost, "/api/admin/users/{id}/unsuspend", admin.UnsuspendUser},
+		{http.MethodPatch, "/api/admin/users/{id}/role", admin.UpdateUserRole},
+		{http.MethodDelete, "/api/company/api-keys/{id}", apiKeys.RevokeAPIKey},
+		{http.MethodDelete, "/api/webhooks/{id}", webhooks.DeleteWebhook},
+		{http.MethodGet, "/api/webhooks/{id}/deliveries", webhooks.ListWebhookDeliveries},
+	}
+
+	router := chi.NewRouter()
+	for _, route := range routes {
+		router.Method(route.method, route.pattern, route.handler)
+	}
+
+	// Only the id is malformed; any other parameter is valid
+	otherParams := regexp.MustCompile(`\{\w+\}`)
+	for _, route := range routes {
+		path := otherParams.ReplaceAllString(strings.Replace(route.pattern, "{id}", "not-a-uuid", 1), uuid.NewString())
+		t.Run(route.method+" "+route.pattern, func(t *testing.T) {
+			req := httptest.NewRequest(route.method, path, strings.NewReader(`{}`))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", rec.Code)
+			}
+			var body map[string]string
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			if body["code"] != CodeInvalidID || body["error"] != "Invalid ID" {
+				t.Errorf("unexpected body: %v", body)
+			}
+		})
+	}
+}
+
+func TestRespondNotFound_Uniform(t *testing.T) {
+	a := httptest.NewRecorder()
+	b := httptest.NewRecorder()
+	respondNotFound(a)
+	respondNotFound(b)
+
+	if a.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", a.Code)
+	}
+	if a.Body.String() != b.Body.String() || !strings.Contains(a.Body.String(), CodeResourceNotFound) {
+		t.Errorf("expected identical not-found bodies, got %q", a.Body.String())
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// samplePDFCompanyName stands in on previews for users without a company name
+const samplePDFCompanyName = "Your Company"
+
+// PDFLayoutHandlers manages the order, visibility and titles of the sections
+// in a company's bid documents
+type PDFLayoutHandlers struct {
+	userRepo UserStore
+	pdf      *services.PDFService
+}
+
+func NewPDFLayoutHandlers(userRepo UserStore) *PDFLayoutHandlers {
+	return &PDFLayoutHandlers{userRepo: userRepo, pdf: services.NewPDFService()}
+}
+
+// Routes registers the PDF layout routes
+func (h *PDFLayoutHandlers) Routes(r chi.Router) {
+	r.Get("/api/company/pdf-layout", h.GetPDFLayout)
+	r.Put("/api/company/pdf-layout", h.UpdatePDFLayout)
+	r.Post("/api/company/pdf-layout/preview", h.PreviewPDFLayout)
+}
+
+// GetPDFLayout returns the company's layout, or the default when none is saved
+func (h *PDFLayoutHandlers) GetPDFLayout(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	layout := user.PDFLayout
+	if layout == nil {
+		layout = services.DefaultPDFLayout()
+	}
+	respondJSON(w, http.StatusOK, layout)
+}
+
+// UpdatePDFLayout replaces the layout bid PDFs and CSV exports are rendered
+// with. Sections left out of the list are not printed.
+func (h *PDFLayoutHandlers) UpdatePDFLayout(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	layout, ok := decodePDFLayout(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userRepo.UpdatePDFLayout(r.Context(), *userID, layout); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			respondNotFound(w)
+			return
+		}
+		slog.Error("Failed to update PDF layout",
+			"error", err,
+			"user_id", userID,
+			"correlation_id", getCorrelationID(r.Context()))
+		respondError(w, http.StatusInternalServerError, "Failed to update PDF layout")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, layout)
+}
+
+// PreviewPDFLayout renders a sample bid in the posted layout without saving
+// it, so a layout can be tried out before it is applied to real bids
+func (h *PDFLayoutHandlers) PreviewPDFLayout(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	layout, ok := decodePDFLayout(w, r)
+	if !ok {
+		return
+	}
+
+	companyName := samplePDFCompanyName
+	if user.CompanyName != nil && *user.CompanyName != "" {
+		companyName = *user.CompanyName
+	}
+	options := &services.PDFOptions{
+		CompanyInfo:           &models.CompanyInfo{Name: companyName, Email: &user.Email},
+		IncludeCover:          true,
+		IncludeSignatureBlock: true,
+		Layout:                layout,
+	}
+
+	bid, response, projectName := services.SamplePDFBid()
+	pdfBytes, err := h.pdf.GenerateBidPDFWithOptions(bid, response, projectName, options)
+	if err != nil {
+		slog.Error("Failed to generate PDF layout preview",
+			"error", err,
+			"user_id", user.ID,
+			"correlation_id", getCorrelationID(r.Context()))
+		respondError(w, http.StatusInternalServerError, "Failed to generate PDF")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "inline; filename=pdf-layout-preview.pdf")
+	w.Write(pdfBytes)
+}
+
+func (h *PDFLayoutHandlers) currentUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetUserByID(r.Context(), *userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			respondNotFound(w)
+			return nil, false
+		}
+		slog.Error("Failed to get user", "error", err, "user_id", userID)
+		respondError(w, http.StatusInternalServerError, "Failed to get user")
+		return nil, false
+	}
+	return user, true
+}
+
+func decodePDFLayout(w http.ResponseWriter, r *http.Request) (*models.PDFLayout, bool) {
+	var layout models.PDFLayout
+	if err := json.NewDecoder(r.Body).Decode(&layout); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return nil, false
+	}
+	if err := services.ValidatePDFLayout(&layout); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return &layout, true
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestPDFLayoutRoutes(t *testing.T) {
+	userID := uuid.New()
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{userID: {ID: userID, Email: "owner@example.com"}}}
+	router := chi.NewRouter()
+	NewPDFLayoutHandlers(users).Routes(router)
+
+	rec := serveAsUser(router, userID, http.MethodGet, "/api/company/pdf-layout", "")
+	var layout models.PDFLayout
+	if err := json.NewDecoder(rec.Body).Decode(&layout); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, error = %v; want 200", rec.Code, err)
+	}
+	if len(layout.Sections) != 11 || layout.Sections[0].Section != models.PDFSectionCover {
+		t.Errorf("unconfigured layout = %+v, want the default", layout.Sections)
+	}
+
+	body := `{"sections": [{"section": "cost_summary", "enabled": true, "title": " Your Investment "}, {"section": "scope", "enabled": false}]}`
+	if rec := serveAsUser(router, userID, http.MethodPut, "/api/company/pdf-layout", body); rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body %s; want 200", rec.Code, rec.Body.String())
+	}
+	saved := users.users[userID].PDFLayout
+	if saved == nil || len(saved.Sections) != 2 || saved.Sections[0].Title != "Your Investment" {
+		t.Errorf("saved layout = %+v, want the trimmed layout", saved)
+	}
+
+	unknown := `{"sections": [{"section": "appendix", "enabled": true}]}`
+	for method, target := range map[string]string{
+		http.MethodPut:  "/api/company/pdf-layout",
+		http.MethodPost: "/api/company/pdf-layout/preview",
+	} {
+		if rec := serveAsUser(router, userID, method, target, unknown); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s %s unknown section: status = %d, want 400", method, target, rec.Code)
+		}
+	}
+	if users.users[userID].PDFLayout != saved {
+		t.Error("rejected layout replaced the saved one")
+	}
+
+	rec = serveAsUser(router, userID, http.MethodPost, "/api/company/pdf-layout/preview", body)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("preview status = %d, content type %q; want a PDF", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF")) {
+		t.Error("preview body is not a PDF")
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// PricingSources are the cost data repositories shared by the handler groups
+// that price takeoffs. The repositories may be nil, in which case pricing
+// falls back to default prices.
+type PricingSources struct {
+	materialRepo        *repository.MaterialRepository
+	laborRateRepo       *repository.LaborRateRepository
+	regionalRepo        *repository.RegionalAdjustmentRepository
+	companyOverrideRepo *repository.CompanyPricingOverrideRepository
+	projectOverrides    services.ProjectOverrideSource
+	costDataService     CostDataServiceInterface
+	rangeParams         services.ConfidenceRangeParams
+	costPerSFBands      map[models.ProjectType]models.CostPerSFBand
+	providerPrecedence  []string
+}
+
+func NewPricingSources(
+	materialRepo *repository.MaterialRepository,
+	laborRateRepo *repository.LaborRateRepository,
+	regionalRepo *repository.RegionalAdjustmentRepository,
+	companyOverrideRepo *repository.CompanyPricingOverrideRepository,
+	costIntegrationService CostIntegrationServiceInterface,
+	cfg *config.Config,
+) *PricingSources {
+	// Use costIntegrationService as costDataService if it supports the interface
+	var costDataService CostDataServiceInterface
+	if cds, ok := costIntegrationService.(CostDataServiceInterface); ok {
+		costDataService = cds
+	} else {
+		// Fallback to nil - handlers will use repositories directly
+		slog.Warn("CostIntegrationService does not implement CostDataServiceInterface, handlers will use direct repository access")
+	}
+
+	rangeParams := services.DefaultConfidenceRangeParams
+	costPerSFBands := services.DefaultCostPerSFBands()
+	var providerPrecedence []string
+	if cfg != nil {
+		if len(cfg.CostProvider.Precedence) > 0 {
+			providerPrecedence = cfg.CostProvider.Precedence
+		}
+		rangeParams = services.ConfidenceRangeParams{
+			DefaultPriceUncertainty:  cfg.EstimateRange.DefaultPriceUncertainty,
+			ProviderPriceUncertainty: cfg.EstimateRange.ProviderPriceUncertainty,
+			OverridePriceUncertainty: cfg.EstimateRange.OverridePriceUncertainty,
+			MaxQuantityUncertainty:   cfg.EstimateRange.MaxQuantityUncertainty,
+		}
+		if bands, err := services.ParseCostPerSFBands(cfg.EstimateRange.CostPerSFBands); err != nil {
+			slog.Warn("Invalid COST_PER_SF_BANDS, using defaults", "error", err)
+		} else {
+			costPerSFBands = bands
+		}
+	}
+
+	return &PricingSources{
+		materialRepo:        materialRepo,
+		laborRateRepo:       laborRateRepo,
+		regionalRepo:        regionalRepo,
+		companyOverrideRepo: companyOverrideRepo,
+		costDataService:     costDataService,
+		rangeParams:         rangeParams,
+		costPerSFBands:      costPerSFBands,
+		providerPrecedence:  providerPrecedence,
+	}
+}
+
+// WithProjectOverrides prices projects with their pricing overrides from source
+func (p *PricingSources) WithProjectOverrides(source services.ProjectOverrideSource) *PricingSources {
+	p.projectOverrides = source
+	return p
+}
+
+// enhancedPricingService builds database-backed pricing that reads cost data
+// through the cache when one is configured
+func (p *PricingSources) enhancedPricingService() *services.EnhancedPricingService {
+	return services.NewEnhancedPricingService(p.materialRepo, p.laborRateRepo, p.regionalRepo, p.companyOverrideRepo).
+		WithCostData(p.costDataService).
+		WithConfidenceRange(p.rangeParams).
+		WithProviderPrecedence(p.providerPrecedence).
+		WithProjectOverrides(p.projectOverrides)
+}
+
+// confidenceRangeParams returns the configured estimate range factors
+func (p *PricingSources) confidenceRangeParams() services.ConfidenceRangeParams {
+	return p.rangeParams
+}
+
+// unitMetrics computes an estimate's costs per square foot, checked against
+// the configured band for the project type
+func (p *PricingSources) unitMetrics(input services.UnitMetricsInput, projectType models.ProjectType) *models.UnitMetrics {
+	var band *models.CostPerSFBand
+	if expected, ok := p.costPerSFBands[projectType]; ok {
+		band = &expected
+	}
+	return services.ComputeUnitMetrics(input, band)
+}
@@ -0,0 +1,549 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// maxProjectNameLength matches the projects.name column
+const maxProjectNameLength = 255
+
+// ProjectHandlers serves projects, their settings and duplication
+type ProjectHandlers struct {
+	projectRepo ProjectStore
+	jobRepo     JobStore
+	duplicator  *services.ProjectDuplicator
+	config      *config.Config
+}
+
+func NewProjectHandlers(projectRepo ProjectStore, jobRepo JobStore, duplicator *services.ProjectDuplicator, cfg *config.Config) *ProjectHandlers {
+	return &ProjectHandlers{
+		projectRepo: projectRepo,
+		jobRepo:     jobRepo,
+		duplicator:  duplicator,
+		config:      cfg,
+	}
+}
+
+// Routes registers the project routes
+func (h *ProjectHandlers) Routes(r chi.Router) {
+	r.Post("/projects", h.CreateProject)
+	r.Get("/projects", h.ListProjects)
+	r.Get("/projects/{id}", h.GetProject)
+	r.Put("/projects/{id}", h.UpdateProject)
+	r.Delete("/projects/{id}", h.DeleteProject)
+	r.Put("/projects/{id}/budget", h.UpdateProjectBudget)
+	r.Put("/projects/{id}/project-type", h.UpdateProjectType)
+	r.Put("/projects/{id}/tax-exempt", h.UpdateTaxExempt)
+	r.Post("/projects/{id}/duplicate", h.DuplicateProject)
+}
+
+// CreateProjectRequest describes a new project. Status defaults to draft and
+// project_type to new construction.
+type CreateProjectRequest struct {
+	Name        string   `json:"name"`
+	Description *string  `json:"description"`
+	Status      string   `json:"status"`
+	ProjectType string   `json:"project_type"`
+	Budget      *float64 `json:"budget"`
+	Region      *string  `json:"region"`
+	ClientName  *string  `json:"client_name"`
+}
+
+// CreateProject creates a project owned by the authenticated user
+func (h *ProjectHandlers) CreateProject(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	name, err := validateProjectName(req.Name)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	status, err := services.ParseProjectStatus(req.Status)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if status != models.ProjectStatusDraft && status != models.ProjectStatusActive {
+		respondError(w, http.StatusBadRequest, "new projects must be draft or active")
+		return
+	}
+	projectType, err := services.ParseProjectType(req.ProjectType)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Budget != nil && *req.Budget < 0 {
+		respondError(w, http.StatusBadRequest, "budget must not be negative")
+		return
+	}
+
+	now := models.Now()
+	project := &models.Project{
+		ID:          uuid.New(),
+		UserID:      *userID,
+		Name:        name,
+		Description: req.Description,
+		Status:      status,
+		ProjectType: projectType,
+		Budget:      req.Budget,
+		Region:      trimmedOrNil(req.Region),
+		ClientName:  trimmedOrNil(req.ClientName),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.projectRepo.Create(r.Context(), project); err != nil {
+		slog.Error("Failed to create project", "user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create project")
+		return
+	}
+
+	slog.Info("Project created",
+		"audit_event", "project.created",
+		"project_id", project.ID,
+		"user_id", userID)
+
+	respondJSON(w, http.StatusCreated, project)
+}
+
+// ListProjects lists the authenticated user's projects, most recently updated
+// first. Archived projects are only listed with ?status=archived.
+func (h *ProjectHandlers) ListProjects(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var status *models.ProjectStatus
+	if raw := r.URL.Query().Get("status"); raw != "" {
+		parsed, err := services.ParseProjectStatus(raw)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		status = &parsed
+	}
+
+	projects, err := h.projectRepo.GetByUserID(r.Context(), *userID, status)
+	if err != nil {
+		slog.Error("Failed to list projects", "user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list projects")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, projects)
+}
+
+// GetProject returns one of the authenticated user's projects
+func (h *ProjectHandlers) GetProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.ownedProject(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, project)
+}
+
+// UpdateProjectRequest changes the fields it sets and leaves the rest
+type UpdateProjectRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Status      *string `json:"status"`
+	Region      *string `json:"region"`
+	ClientName  *string `json:"client_name"`
+}
+
+// UpdateProject renames a project, edits its details or moves it to another
+// status. Budget, project type and tax exemption have their own routes.
+func (h *ProjectHandlers) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	var req UpdateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	project, ok := h.ownedProject(w, r)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		name, err := validateProjectName(*req.Name)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		project.Name = name
+	}
+	if req.Status != nil {
+		status, err := services.ParseProjectStatus(*req.Status)
+		if err == nil {
+			err = services.CheckProjectStatusTransition(project.Status, status)
+		}
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		project.Status = status
+	}
+	if req.Description != nil {
+		project.Description = req.Description
+	}
+	if req.Region != nil {
+		project.Region = trimmedOrNil(req.Region)
+	}
+	if req.ClientName != nil {
+		project.ClientName = trimmedOrNil(req.ClientName)
+	}
+	project.UpdatedAt = models.Now()
+
+	if err := h.projectRepo.Update(r.Context(), project); err != nil {
+		slog.Error("Failed to update project", "project_id", project.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update project")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, project)
+}
+
+// DeleteProject archives a project. Its blueprints and bids are kept, and it
+// can be restored by moving it back to active.
+func (h *ProjectHandlers) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.ownedProject(w, r)
+	if !ok {
+		return
+	}
+
+	if project.Status != models.ProjectStatusArchived {
+		project.Status = models.ProjectStatusArchived
+		project.UpdatedAt = models.Now()
+		if err := h.projectRepo.Update(r.Context(), project); err != nil {
+			slog.Error("Failed to archive project", "project_id", project.ID, "error", err)
+			respondError(w, http.StatusInternalServerError, "Failed to delete project")
+			return
+		}
+
+		slog.Info("Project archived",
+			"audit_event", "project.archived",
+			"project_id", project.ID,
+			"user_id", project.UserID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownedProject loads the project named by the id path parameter for the
+// project routes. It writes a 404 when the project does not exist, a 403
+// when another user owns it, and returns false in both cases.
+func (h *ProjectHandlers) ownedProject(w http.ResponseWriter, r *http.Request) (*models.Project, bool) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return nil, false
+	}
+
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	project, err := h.projectRepo.GetByID(r.Context(), projectID)
+	if err != nil {
+		respondNotFound(w)
+		return nil, false
+	}
+	if project.UserID != *userID {
+		respondError(w, http.StatusForbidden, "You do not have access to this project")
+		return nil, false
+	}
+	return project, true
+}
+
+// validateProjectName trims a project name and checks it is present and fits
+// the column
+func validateProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if len(name) > maxProjectNameLength {
+		return "", fmt.Errorf("name must be at most %d characters", maxProjectNameLength)
+	}
+	return name, nil
+}
+
+// trimmedOrNil trims an optional text field, clearing it when only whitespace
+// is left
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// UpdateProjectBudgetRequest sets or clears (null) a project's budget
+type UpdateProjectBudgetRequest struct {
+	Budget *float64 `json:"budget"`
+}
+
+// UpdateProjectBudget sets the budget used for over-budget bid warnings
+func (h *ProjectHandlers) UpdateProjectBudget(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req UpdateProjectBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Budget != nil && *req.Budget < 0 {
+		respondError(w, http.StatusBadRequest, "budget must not be negative")
+		return
+	}
+
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return
+	}
+
+	if err := h.projectRepo.UpdateBudget(r.Context(), project.ID, req.Budget); err != nil {
+		slog.Error("Failed to update project budget", "project_id", project.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update project budget")
+		return
+	}
+
+	project.Budget = req.Budget
+	respondJSON(w, http.StatusOK, project)
+}
+
+// UpdateProjectTypeRequest sets whether a project is new construction, a
+// renovation or an addition
+type UpdateProjectTypeRequest struct {
+	ProjectType string `json:"project_type"`
+}
+
+// UpdateProjectType sets the project type used to price renovation and
+// addition work
+func (h *ProjectHandlers) UpdateProjectType(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req UpdateProjectTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	projectType, err := services.ParseProjectType(req.ProjectType)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return
+	}
+
+	if err := h.projectRepo.UpdateProjectType(r.Context(), project.ID, projectType); err != nil {
+		slog.Error("Failed to update project type", "project_id", project.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update project type")
+		return
+	}
+
+	project.ProjectType = projectType
+	respondJSON(w, http.StatusOK, project)
+}
+
+// UpdateTaxExemptRequest sets whether a project is priced without sales tax
+type UpdateTaxExemptRequest struct {
+	TaxExempt bool `json:"tax_exempt"`
+}
+
+// UpdateTaxExempt marks a project exempt from the company's sales tax, e.g.
+// for a nonprofit or government client
+func (h *ProjectHandlers) UpdateTaxExempt(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req UpdateTaxExemptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return
+	}
+
+	if err := h.projectRepo.UpdateTaxExempt(r.Context(), project.ID, req.TaxExempt); err != nil {
+		slog.Error("Failed to update project tax exemption", "project_id", project.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update project tax exemption")
+		return
+	}
+
+	project.TaxExempt = req.TaxExempt
+	respondJSON(w, http.StatusOK, project)
+}
+
+// DuplicateProjectRequest names a duplicate project and optionally moves it
+// to a new region or client
+type DuplicateProjectRequest struct {
+	Name       string  `json:"name"`
+	Region     *string `json:"region"`
+	ClientName *string `json:"client_name"`
+}
+
+// DuplicateProjectResponse identifies the duplicate. Status is "completed"
+// when the blueprints were copied within the request, or "queued" with a
+// JobID to poll at GET /jobs/{id} when a job copies them.
+type DuplicateProjectResponse struct {
+	ProjectID      uuid.UUID  `json:"project_id"`
+	Status         string     `json:"status"`
+	BlueprintCount int        `json:"blueprint_count"`
+	JobID          *uuid.UUID `json:"job_id,omitempty"`
+}
+
+// DuplicateProject copies a project and its blueprints for repeat work. Projects
+// with more than Worker.DuplicateSyncMaxBlueprints blueprints are copied by a
+// job; the duplicate project exists as soon as this returns either way.
+func (h *ProjectHandlers) DuplicateProject(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	var req DuplicateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	source, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return
+	}
+
+	blueprints, err := h.duplicator.SourceBlueprints(r.Context(), source.ID)
+	if err != nil {
+		slog.Error("Failed to get blueprints to duplicate", "project_id", source.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to duplicate project")
+		return
+	}
+
+	if err := h.duplicator.CheckQuota(r.Context(), source.UserID, blueprints); err != nil {
+		var quotaErr *services.StorageQuotaError
+		if errors.As(err, &quotaErr) {
+			respondError(w, http.StatusForbidden, quotaErr.Error())
+			return
+		}
+		slog.Error("Failed to check storage quota", "user_id", source.UserID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to duplicate project")
+		return
+	}
+
+	// Large copies run on the worker, so the queue needs room before the
+	// project is created
+	async := len(blueprints) > h.config.Worker.DuplicateSyncMaxBlueprints
+	if async && !ensureQueueCapacity(w, r, h.jobRepo, h.config, 1) {
+		return
+	}
+
+	project, err := h.duplicator.CreateProject(r.Context(), source, services.DuplicateProjectOptions{
+		Name:       req.Name,
+		Region:     req.Region,
+		ClientName: req.ClientName,
+	})
+	if err != nil {
+		slog.Error("Failed to create duplicate project", "project_id", source.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to duplicate project")
+		return
+	}
+
+	response := DuplicateProjectResponse{
+		ProjectID:      project.ID,
+		BlueprintCount: len(blueprints),
+	}
+
+	if async {
+		job := &models.Job{
+			ID:              uuid.New(),
+			BlueprintID:     blueprints[0].ID,
+			JobType:         models.JobTypeProjectDuplicate,
+			Status:          models.JobStatusQueued,
+			CreatedAt:       models.Now(),
+			UpdatedAt:       models.Now(),
+			TargetProjectID: &project.ID,
+		}
+		if err := h.jobRepo.Create(r.Context(), job); err != nil {
+			slog.Error("Failed to queue project duplication", "project_id", project.ID, "error", err)
+			respondError(w, http.StatusInternalServerError, "Failed to duplicate project")
+			return
+		}
+		response.Status = string(models.JobStatusQueued)
+		response.JobID = &job.ID
+	} else {
+		if _, err := h.duplicator.CopyBlueprints(r.Context(), project, blueprints, nil); err != nil {
+			slog.Error("Failed to copy blueprints to duplicate project", "project_id", project.ID, "error", err)
+			respondError(w, http.StatusInternalServerError, "Failed to duplicate project")
+			return
+		}
+		response.Status = string(models.JobStatusCompleted)
+	}
+
+	slog.Info("Project duplicated",
+		"audit_event", "project.duplicated",
+		"source_project_id", source.ID,
+		"project_id", project.ID,
+		"blueprint_count", len(blueprints),
+		"async", response.JobID != nil)
+
+	status := http.StatusCreated
+	if response.JobID != nil {
+		status = http.StatusAccepted
+	}
+	respondJSON(w, status, response)
+}
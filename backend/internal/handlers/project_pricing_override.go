@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// ProjectPricingOverrideHandlers manages the pricing overrides of a single
+// project, which pricing applies on top of the owner's company overrides
+type ProjectPricingOverrideHandlers struct {
+	projectRepo  ProjectStore
+	overrideRepo ProjectPricingOverrideStore
+	events       events.Publisher
+}
+
+func NewProjectPricingOverrideHandlers(projectRepo ProjectStore, overrideRepo ProjectPricingOverrideStore, publisher events.Publisher) *ProjectPricingOverrideHandlers {
+	return &ProjectPricingOverrideHandlers{projectRepo: projectRepo, overrideRepo: overrideRepo, events: publisher}
+}
+
+// Routes registers the project pricing override routes
+func (h *ProjectPricingOverrideHandlers) Routes(r chi.Router) {
+	r.Get("/projects/{id}/pricing-overrides", h.GetProjectPricingOverrides)
+	r.Post("/projects/{id}/pricing-overrides", h.CreateProjectPricingOverride)
+	r.Put("/projects/{id}/pricing-overrides/{overrideId}", h.UpdateProjectPricingOverride)
+	r.Delete("/projects/{id}/pricing-overrides/{overrideId}", h.DeleteProjectPricingOverride)
+}
+
+// GetProjectPricingOverrides lists a project's pricing overrides
+func (h *ProjectPricingOverrideHandlers) GetProjectPricingOverrides(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.ownedProject(w, r)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.overrideRepo.GetByProjectID(r.Context(), project.ID)
+	if err != nil {
+		slog.Error("Failed to get project pricing overrides", "project_id", project.ID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get pricing overrides")
+		return
+	}
+	if overrides == nil {
+		overrides = []models.ProjectPricingOverride{}
+	}
+	respondJSON(w, http.StatusOK, overrides)
+}
+
+// CreateProjectPricingOverride adds a pricing override to a project. It takes
+// the same fields as a company override.
+func (h *ProjectPricingOverrideHandlers) CreateProjectPricingOverride(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.ownedProject(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateCompanyPricingOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	itemKey, err := validatePricingOverride(req.OverrideType, req.ItemKey, req.OverrideValue, req.IsPercentage)
+	if err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	now := models.Now()
+	override := &models.ProjectPricingOverride{
+		ID:            uuid.New(),
+		ProjectID:     project.ID,
+		OverrideType:  req.OverrideType,
+		ItemKey:       itemKey,
+		OverrideValue: req.OverrideValue,
+		IsPercentage:  req.IsPercentage,
+		Notes:         req.Notes,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := h.overrideRepo.Create(r.Context(), override); err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to create project pricing override", "project_id", project.ID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to create pricing override")
+		return
+	}
+	h.publishChanged(r, events.OverrideCreated, override)
+
+	respondJSON(w, http.StatusCreated, override)
+}
+
+// UpdateProjectPricingOverride changes a project override's value, kind and
+// notes
+func (h *ProjectPricingOverrideHandlers) UpdateProjectPricingOverride(w http.ResponseWriter, r *http.Request) {
+	override, ok := h.ownedOverride(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateCompanyPricingOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+	if _, err := validatePricingOverride(override.OverrideType, override.ItemKey, req.OverrideValue, req.IsPercentage); err != nil {
+		respondAPIError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
+		return
+	}
+
+	override.OverrideValue = req.OverrideValue
+	override.IsPercentage = req.IsPercentage
+	override.Notes = req.Notes
+	override.UpdatedAt = models.Now()
+	if err := h.overrideRepo.Update(r.Context(), override); err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to update project pricing override", "override_id", override.ID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to update pricing override")
+		return
+	}
+	h.publishChanged(r, events.OverrideUpdated, override)
+
+	respondJSON(w, http.StatusOK, override)
+}
+
+// DeleteProjectPricingOverride removes a project override
+func (h *ProjectPricingOverrideHandlers) DeleteProjectPricingOverride(w http.ResponseWriter, r *http.Request) {
+	override, ok := h.ownedOverride(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.overrideRepo.Delete(r.Context(), override.ID); err != nil {
+		if !isRepositoryError(err) {
+			slog.Error("Failed to delete project pricing override", "override_id", override.ID, "error", err)
+		}
+		respondRepositoryError(w, err, "Failed to delete pricing override")
+		return
+	}
+	h.publishChanged(r, events.OverrideDeleted, override)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownedProject loads the project named by the id path parameter when it
+// belongs to the requesting user, writing a 404 otherwise
+func (h *ProjectPricingOverrideHandlers) ownedProject(w http.ResponseWriter, r *http.Request) (*models.Project, bool) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return nil, false
+	}
+	return loadUserProject(w, r, h.projectRepo, projectID)
+}
+
+// ownedOverride loads the override named by the overrideId path parameter
+// when it belongs to the project named by id and the project to the
+// requesting user, writing a 404 otherwise
+func (h *ProjectPricingOverrideHandlers) ownedOverride(w http.ResponseWriter, r *http.Request) (*models.ProjectPricingOverride, bool) {
+	project, ok := h.ownedProject(w, r)
+	if !ok {
+		return nil, false
+	}
+	overrideID, err := parseUUIDParam(r, "overrideId")
+	if err != nil {
+		respondInvalidID(w)
+		return nil, false
+	}
+
+	override, err := h.overrideRepo.GetByID(r.Context(), overrideID)
+	if err != nil || override.ProjectID != project.ID {
+		respondNotFound(w)
+		return nil, false
+	}
+	return override, true
+}
+
+func (h *ProjectPricingOverrideHandlers) publishChanged(r *http.Request, change events.OverrideChange, override *models.ProjectPricingOverride) {
+	h.events.Publish(r.Context(), events.ProjectOverrideChanged{
+		Change:        change,
+		Override:      *override,
+		UserID:        getUserID(r.Context()),
+		CorrelationID: getCorrelationID(r.Context()),
+	})
+}
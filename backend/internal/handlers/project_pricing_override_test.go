@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestProjectPricingOverrides(t *testing.T) {
+	project := &models.Project{ID: uuid.New(), UserID: uuid.New()}
+	otherProject := &models.Project{ID: uuid.New(), UserID: uuid.New()}
+	store := &fakeProjectPricingOverrideStore{}
+	h := NewProjectPricingOverrideHandlers(
+		&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project, otherProject.ID: otherProject}},
+		store,
+		events.Discard,
+	)
+	router := chi.NewRouter()
+	h.Routes(router)
+	path := "/projects/" + project.ID.String() + "/pricing-overrides"
+
+	rec := serveAsUser(router, project.UserID, http.MethodPost, path, `{"override_type":"labor","item_key":" Electrical ","override_value":110}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var created models.ProjectPricingOverride
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ProjectID != project.ID || created.ItemKey != "electrical" || created.OverrideValue != 110 {
+		t.Errorf("created = %+v, want the project's electrical rate", created)
+	}
+
+	failures := []struct {
+		name   string
+		userID uuid.UUID
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"duplicate", project.UserID, http.MethodPost, path, `{"override_type":"labor","item_key":"electrical","override_value":120}`, http.StatusConflict},
+		{"unknown type", project.UserID, http.MethodPost, path, `{"override_type":"tax","item_key":"sales","override_value":5}`, http.StatusBadRequest},
+		{"unknown trade", project.UserID, http.MethodPost, path, `{"override_type":"labor","item_key":"juggling","override_value":90}`, http.StatusBadRequest},
+		{"another user's project", otherProject.UserID, http.MethodGet, path, "", http.StatusNotFound},
+		{"another user's create", otherProject.UserID, http.MethodPost, path, `{"override_type":"labor","item_key":"plumbing","override_value":90}`, http.StatusNotFound},
+		{"override of another project", otherProject.UserID, http.MethodDelete, "/projects/" + otherProject.ID.String() + "/pricing-overrides/" + created.ID.String(), "", http.StatusNotFound},
+		{"malformed update", project.UserID, http.MethodPut, path + "/" + created.ID.String(), `{"override_value":`, http.StatusBadRequest},
+	}
+	for _, tt := range failures {
+		if rec := serveAsUser(router, tt.userID, tt.method, tt.path, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+
+	rec = serveAsUser(router, project.UserID, http.MethodPut, path+"/"+created.ID.String(), `{"override_value":15,"is_percentage":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if stored := store.overrides[0]; stored.OverrideValue != 15 || !stored.IsPercentage {
+		t.Errorf("stored override = %+v, want a 15%% adjustment", stored)
+	}
+
+	rec = serveAsUser(router, project.UserID, http.MethodGet, path, "")
+	var listed []models.ProjectPricingOverride
+	if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&listed) != nil || len(listed) != 1 {
+		t.Errorf("list: status = %d, body %s; want the one override", rec.Code, rec.Body.String())
+	}
+
+	if rec := serveAsUser(router, project.UserID, http.MethodDelete, path+"/"+created.ID.String(), ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: status = %d, want 204", rec.Code)
+	}
+	if len(store.overrides) != 0 {
+		t.Errorf("overrides after delete = %v, want none", store.overrides)
+	}
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+type fakeObjectCopier struct {
+	copied []string
+}
+
+func (f *fakeObjectCopier) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	f.copied = append(f.copied, dstKey)
+	return nil
+}
+
+type duplicateTest struct {
+	source     *models.Project
+	projects   *fakeProjectStore
+	blueprints *fakeBlueprintStore
+	jobs       *fakeJobStore
+	bids       *fakeBidStore
+	objects    *fakeObjectCopier
+	router     chi.Router
+}
+
+// newDuplicateTest serves a project owned by userID with count uploaded
+// blueprints of 100 bytes each, a superseded blueprint version and a bid.
+// Projects of more than syncMax blueprints are duplicated by a job, and a
+// user may have up to 5 queued jobs.
+func newDuplicateTest(userID uuid.UUID, count, syncMax int, quotaBytes int64) *duplicateTest {
+	source := &models.Project{ID: uuid.New(), UserID: userID, Name: "Prototype store"}
+	size := int64(100)
+	analysis := `{"rooms":[]}`
+	blueprints := &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{}}
+	for i := 0; i < count; i++ {
+		id := uuid.New()
+		blueprints.blueprints[id] = &models.Blueprint{
+			ID: id, ProjectID: source.ID, Filename: "sheet.pdf", S3Key: "projects/src/" + id.String(),
+			FileSize: &size, UploadStatus: models.UploadStatusUploaded, AnalysisData: &analysis, IsLatest: true,
+		}
+	}
+	superseded := uuid.New()
+	blueprints.blueprints[superseded] = &models.Blueprint{
+		ID: superseded, ProjectID: source.ID, Filename: "old.pdf", UploadStatus: models.UploadStatusUploaded, IsLatest: false,
+	}
+
+	test := &duplicateTest{
+		source:     source,
+		projects:   &fakeProjectStore{projects: map[uuid.UUID]*models.Project{source.ID: source}},
+		blueprints: blueprints,
+		jobs:       &fakeJobStore{},
+		bids:       &fakeBidStore{bids: []*models.Bid{{ID: uuid.New(), ProjectID: source.ID}}},
+		objects:    &fakeObjectCopier{},
+	}
+	duplicator := services.NewProjectDuplicator(test.projects, test.blueprints, test.objects, quotaBytes)
+	cfg := &config.Config{Worker: config.WorkerConfig{DuplicateSyncMaxBlueprints: syncMax, MaxQueuedJobsPerUser: 5, PollInterval: 5 * time.Second}}
+	test.router = chi.NewRouter()
+	NewProjectHandlers(test.projects, test.jobs, duplicator, cfg).Routes(test.router)
+	return test
+}
+
+func (d *duplicateTest) duplicate(userID uuid.UUID, body string) (*DuplicateProjectResponse, int) {
+	rec := serveAsUser(d.router, userID, http.MethodPost, "/projects/"+d.source.ID.String()+"/duplicate", body)
+	var response DuplicateProjectResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &response)
+	return &response, rec.Code
+}
+
+func (d *duplicateTest) blueprintsIn(projectID uuid.UUID) []*models.Blueprint {
+	blueprints, _ := d.blueprints.GetByProjectID(context.Background(), projectID)
+	return blueprints
+}
+
+func TestDuplicateProject_Synchronous(t *testing.T) {
+	userID := uuid.New()
+	d := newDuplicateTest(userID, 2, 10, 0)
+
+	response, code := d.duplicate(userID, `{"name": "Store #42", "region": "Denver, CO"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201", code)
+	}
+	if response.Status != "completed" || response.JobID != nil || response.BlueprintCount != 2 {
+		t.Errorf("response = %+v, want 2 blueprints copied without a job", response)
+	}
+
+	project := d.projects.projects[response.ProjectID]
+	if project == nil || project.Name != "Store #42" || project.UserID != userID || project.Region == nil || *project.Region != "Denver, CO" {
+		t.Fatalf("duplicate project = %+v, want Store #42 in Denver owned by the caller", project)
+	}
+	copies := d.blueprintsIn(project.ID)
+	if len(copies) != 2 || len(d.objects.copied) != 2 {
+		t.Fatalf("expected the 2 latest blueprints and their files copied, got %d rows and %d objects", len(copies), len(d.objects.copied))
+	}
+	for _, blueprint := range copies {
+		if blueprint.AnalysisData == nil {
+			t.Errorf("blueprint %s was copied without its analysis", blueprint.ID)
+		}
+	}
+
+	// Bids and jobs stay with the source project
+	if len(d.jobs.jobs) != 0 {
+		t.Errorf("expected no jobs, got %d", len(d.jobs.jobs))
+	}
+	if bids, _ := d.bids.GetByProjectID(context.Background(), project.ID); len(bids) != 0 {
+		t.Errorf("expected no bids on the duplicate, got %d", len(bids))
+	}
+	if len(d.blueprintsIn(d.source.ID)) != 3 {
+		t.Error("source project blueprints changed")
+	}
+}
+
+func TestDuplicateProject_QueueFull(t *testing.T) {
+	userID := uuid.New()
+	d := newDuplicateTest(userID, 3, 2, 0)
+	d.jobs.depth = models.QueueDepth{Total: 5, User: 5}
+
+	if _, code := d.duplicate(userID, `{"name": "Store #42"}`); code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", code)
+	}
+	if len(d.projects.projects) != 1 || len(d.jobs.jobs) != 0 {
+		t.Errorf("a full queue left %d projects and %d jobs, want only the source", len(d.projects.projects), len(d.jobs.jobs))
+	}
+
+	// Small projects are copied inline and don't need the queue
+	small := newDuplicateTest(userID, 2, 2, 0)
+	small.jobs.depth = models.QueueDepth{Total: 5, User: 5}
+	if _, code := small.duplicate(userID, `{"name": "Store #43"}`); code != http.StatusCreated {
+		t.Errorf("inline copy with a full queue: status = %d, want 201", code)
+	}
+}
+
+func TestDuplicateProject_LargeProjectQueuesJob(t *testing.T) {
+	userID := uuid.New()
+	d := newDuplicateTest(userID, 3, 2, 0)
+
+	response, code := d.duplicate(userID, `{"name": "Store #43"}`)
+	if code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", code)
+	}
+	if response.Status != "queued" || response.JobID == nil {
+		t.Fatalf("response = %+v, want a queued job", response)
+	}
+
+	job := d.jobs.jobs[*response.JobID]
+	if job == nil || job.JobType != models.JobTypeProjectDuplicate || job.TargetProjectID == nil || *job.TargetProjectID != response.ProjectID {
+		t.Fatalf("job = %+v, want a duplication job targeting the new project", job)
+	}
+	if _, exists := d.projects.projects[response.ProjectID]; !exists {
+		t.Error("the duplicate project should exist before the job runs")
+	}
+	if len(d.blueprintsIn(response.ProjectID)) != 0 || len(d.objects.copied) != 0 {
+		t.Error("blueprints should be left for the job to copy")
+	}
+}
+
+func TestDuplicateProject_QuotaExceeded(t *testing.T) {
+	userID := uuid.New()
+	// 200 bytes stored; the copy needs 200 more
+	d := newDuplicateTest(userID, 2, 10, 399)
+
+	_, code := d.duplicate(userID, `{"name": "Store #44"}`)
+	if code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", code)
+	}
+	if len(d.projects.projects) != 1 || len(d.objects.copied) != 0 {
+		t.Errorf("nothing should be created over quota: %d projects, %d objects", len(d.projects.projects), len(d.objects.copied))
+	}
+}
+
+func TestDuplicateProject_Validation(t *testing.T) {
+	userID := uuid.New()
+	d := newDuplicateTest(userID, 1, 10, 0)
+
+	if _, code := d.duplicate(uuid.New(), `{"name": "Not mine"}`); code != http.StatusNotFound {
+		t.Errorf("another user's project: status = %d, want 404", code)
+	}
+	if _, code := d.duplicate(userID, `{"name": "  "}`); code != http.StatusBadRequest {
+		t.Errorf("blank name: status = %d, want 400", code)
+	}
+	if len(d.projects.projects) != 1 {
+		t.Errorf("expected no project created, got %d projects", len(d.projects.projects))
+	}
+}
+
+func TestUpdateProjectType(t *testing.T) {
+	userID := uuid.New()
+	d := newDuplicateTest(userID, 0, 10, 0)
+	target := "/projects/" + d.source.ID.String() + "/project-type"
+
+	rec := serveAsUser(d.router, userID, http.MethodPut, target, `{"project_type": "renovation"}`)
+	if rec.Code != http.StatusOK || d.source.ProjectType != models.ProjectTypeRenovation {
+		t.Fatalf("status = %d, project type = %q; want 200 and renovation", rec.Code, d.source.ProjectType)
+	}
+
+	for _, tc := range []struct {
+		userID uuid.UUID
+		body   string
+		want   int
+	}{
+		{userID, `{"project_type": "remodel"}`, http.StatusBadRequest},
+		{uuid.New(), `{"project_type": "addition"}`, http.StatusNotFound},
+	} {
+		if rec := serveAsUser(d.router, tc.userID, http.MethodPut, target, tc.body); rec.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.body, rec.Code, tc.want)
+		}
+	}
+	if d.source.ProjectType != models.ProjectTypeRenovation {
+		t.Errorf("project type = %q after rejected updates", d.source.ProjectType)
+	}
+
+	// Duplicates are priced the same way as their source
+	response, code := d.duplicate(userID, `{"name": "Store #43"}`)
+	if code != http.StatusCreated || d.projects.projects[response.ProjectID].ProjectType != models.ProjectTypeRenovation {
+		t.Errorf("duplicate status = %d, want the renovation type copied", code)
+	}
+}
+
+func TestUpdateTaxExempt(t *testing.T) {
+	userID := uuid.New()
+	d := newDuplicateTest(userID, 0, 10, 0)
+	target := "/projects/" + d.source.ID.String() + "/tax-exempt"
+
+	rec := serveAsUser(d.router, userID, http.MethodPut, target, `{"tax_exempt": true}`)
+	if rec.Code != http.StatusOK || !d.source.TaxExempt {
+		t.Fatalf("status = %d, tax exempt = %v; want 200 and exempt", rec.Code, d.source.TaxExempt)
+	}
+
+	if rec := serveAsUser(d.router, uuid.New(), http.MethodPut, target, `{"tax_exempt": false}`); rec.Code != http.StatusNotFound {
+		t.Errorf("other user: status = %d, want 404", rec.Code)
+	}
+	if !d.source.TaxExempt {
+		t.Error("exemption cleared by another user's request")
+	}
+
+	// Duplicates of exempt work stay exempt
+	response, code := d.duplicate(userID, `{"name": "Store #43"}`)
+	if code != http.StatusCreated || !d.projects.projects[response.ProjectID].TaxExempt {
+		t.Errorf("duplicate status = %d, want the exemption copied", code)
+	}
+}
+
+func TestProjectCRUD(t *testing.T) {
+	ownerID, otherID := uuid.New(), uuid.New()
+	projects := &fakeProjectStore{projects: map[uuid.UUID]*models.Project{}}
+	router := chi.NewRouter()
+	NewProjectHandlers(projects, &fakeJobStore{}, nil, &config.Config{}).Routes(router)
+
+	rec := serveAsUser(router, ownerID, http.MethodPost, "/projects", `{"name": "  Main St Clinic ", "description": "Tenant fit-out", "client_name": " Acme "}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var created models.Project
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode project: %v", err)
+	}
+	if created.UserID != ownerID || created.Name != "Main St Clinic" || created.Status != models.ProjectStatusDraft ||
+		created.ProjectType != models.ProjectTypeNewConstruction || created.ClientName == nil || *created.ClientName != "Acme" {
+		t.Errorf("created = %+v, want a draft owned by the caller", created)
+	}
+	target := "/projects/" + created.ID.String()
+
+	other := &models.Project{ID: uuid.New(), UserID: otherID, Name: "Someone else's", Status: models.ProjectStatusActive}
+	projects.projects[other.ID] = other
+
+	list := func(userID uuid.UUID, query string) []models.Project {
+		t.Helper()
+		rec := serveAsUser(router, userID, http.MethodGet, "/projects"+query, "")
+		var listed []models.Project
+		if err := json.NewDecoder(rec.Body).Decode(&listed); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("GET /projects%s status = %d, error = %v", query, rec.Code, err)
+		}
+		return listed
+	}
+	if listed := list(ownerID, ""); len(listed) != 1 || listed[0].ID != created.ID {
+		t.Errorf("listed = %+v, want only the caller's project", listed)
+	}
+
+	if rec := serveAsUser(router, ownerID, http.MethodGet, target, ""); rec.Code != http.StatusOK {
+		t.Errorf("owner GET status = %d, want 200", rec.Code)
+	}
+	for _, tc := range []struct{ method, body string }{
+		{http.MethodGet, ""},
+		{http.MethodPut, `{"name": "Mine now"}`},
+		{http.MethodDelete, ""},
+	} {
+		if rec := serveAsUser(router, otherID, tc.method, target, tc.body); rec.Code != http.StatusForbidden {
+			t.Errorf("%s by another user status = %d, want 403", tc.method, rec.Code)
+		}
+	}
+	if rec := serveAsUser(router, ownerID, http.MethodGet, "/projects/"+uuid.NewString(), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET missing project status = %d, want 404", rec.Code)
+	}
+
+	rec = serveAsUser(router, ownerID, http.MethodPut, target, `{"name": "Main Street Clinic", "status": "active", "region": "us-west"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	updated := projects.projects[created.ID]
+	if updated.Name != "Main Street Clinic" || updated.Status != models.ProjectStatusActive || updated.Region == nil ||
+		updated.Description == nil || *updated.Description != "Tenant fit-out" {
+		t.Errorf("updated = %+v, want the new name, status and region with the description kept", updated)
+	}
+
+	rec = serveAsUser(router, ownerID, http.MethodDelete, target, "")
+	if rec.Code != http.StatusNoContent || projects.projects[created.ID].Status != models.ProjectStatusArchived {
+		t.Fatalf("DELETE status = %d, project status %s; want 204 and archived", rec.Code, projects.projects[created.ID].Status)
+	}
+	if listed := list(ownerID, ""); len(listed) != 0 {
+		t.Errorf("listed = %+v, want archived projects left out", listed)
+	}
+	if listed := list(ownerID, "?status=archived"); len(listed) != 1 {
+		t.Errorf("listed archived = %+v, want the archived project", listed)
+	}
+	if rec := serveAsUser(router, ownerID, http.MethodPut, target, `{"status": "active"}`); rec.Code != http.StatusOK {
+		t.Errorf("restoring an archived project status = %d, want 200", rec.Code)
+	}
+}
+
+func TestProjectCRUD_Validation(t *testing.T) {
+	ownerID := uuid.New()
+	draft := &models.Project{ID: uuid.New(), UserID: ownerID, Name: "Draft", Status: models.ProjectStatusDraft}
+	projects := &fakeProjectStore{projects: map[uuid.UUID]*models.Project{draft.ID: draft}}
+	router := chi.NewRouter()
+	NewProjectHandlers(projects, &fakeJobStore{}, nil, &config.Config{}).Routes(router)
+
+	tests := []struct {
+		name, method, target, body string
+	}{
+		{"missing name", http.MethodPost, "/projects", `{"description": "No name"}`},
+		{"blank name", http.MethodPost, "/projects", `{"name": "   "}`},
+		{"name too long", http.MethodPost, "/projects", `{"name": "` + strings.Repeat("x", 256) + `"}`},
+		{"unknown status", http.MethodPost, "/projects", `{"name": "Shop", "status": "paused"}`},
+		{"created archived", http.MethodPost, "/projects", `{"name": "Shop", "status": "archived"}`},
+		{"negative budget", http.MethodPost, "/projects", `{"name": "Shop", "budget": -1}`},
+		{"unknown list status", http.MethodGet, "/projects?status=paused", ""},
+		{"blank rename", http.MethodPut, "/projects/" + draft.ID.String(), `{"name": ""}`},
+		{"skipped transition", http.MethodPut, "/projects/" + draft.ID.String(), `{"status": "completed"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serveAsUser(router, ownerID, tt.method, tt.target, tt.body); rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (body %s)", rec.Code, rec.Body.String())
+			}
+		})
+	}
+	if draft.Name != "Draft" || draft.Status != models.ProjectStatusDraft {
+		t.Errorf("project = %+v, want it unchanged by rejected updates", draft)
+	}
+}
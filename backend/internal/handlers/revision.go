@@ -1,42 +1,107 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/errreport"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
 )
 
+// RevisionHandlers serves blueprint and bid revision history
+type RevisionHandlers struct {
+	projectRepo           ProjectStore
+	blueprintRepo         BlueprintStore
+	blueprintRevisionRepo BlueprintRevisionStore
+	blueprintAssetRepo    BlueprintAssetStore
+	bidRepo               BidStore
+	bidRevisionRepo       BidRevisionStore
+	userRepo              UserStore
+	s3Service             *services.S3Service
+	tx                    Transactor
+}
+
+func NewRevisionHandlers(
+	projectRepo ProjectStore,
+	blueprintRepo BlueprintStore,
+	blueprintRevisionRepo BlueprintRevisionStore,
+	blueprintAssetRepo BlueprintAssetStore,
+	bidRepo BidStore,
+	bidRevisionRepo BidRevisionStore,
+	userRepo UserStore,
+	s3Service *services.S3Service,
+	tx Transactor,
+) *RevisionHandlers {
+	return &RevisionHandlers{
+		projectRepo:           projectRepo,
+		blueprintRepo:         blueprintRepo,
+		blueprintRevisionRepo: blueprintRevisionRepo,
+		blueprintAssetRepo:    blueprintAssetRepo,
+		bidRepo:               bidRepo,
+		bidRevisionRepo:       bidRevisionRepo,
+		userRepo:              userRepo,
+		s3Service:             s3Service,
+		tx:                    tx,
+	}
+}
+
+// Routes registers the revision routes
+func (h *RevisionHandlers) Routes(r chi.Router) {
+	// Blueprint revision routes
+	r.Get("/blueprints/{id}/revisions", h.GetBlueprintRevisions)
+	r.Post("/blueprints/{id}/revisions", h.CreateBlueprintRevision)
+	r.Post("/blueprints/{id}/revisions/{version}/restore", h.RestoreBlueprintRevision)
+	r.Get("/blueprints/{id}/compare", h.CompareBlueprintRevisions)
+
+	// Bid revision routes
+	r.Get("/bids/{id}/revisions", h.GetBidRevisions)
+	r.Post("/bids/{id}/revisions", h.CreateBidRevision)
+	r.Get("/bids/{id}/compare", h.CompareBidRevisions)
+	r.Get("/bids/{id}/compare/pdf", h.GetBidComparisonPDF)
+}
+
 // GetBlueprintRevisions returns all revisions for a blueprint
-func (h *Handler) GetBlueprintRevisions(w http.ResponseWriter, r *http.Request) {
-	blueprintID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *RevisionHandlers) GetBlueprintRevisions(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid blueprint ID")
+		respondInvalidID(w)
+		return
+	}
+
+	if _, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID); !ok {
 		return
 	}
 
 	revisions, err := h.blueprintRevisionRepo.GetByBlueprintID(r.Context(), blueprintID)
 	if err != nil {
 		slog.Error("Failed to get blueprint revisions", "blueprint_id", blueprintID, "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get blueprint revisions")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get blueprint revisions")
 		return
 	}
 
+	// Flag revisions whose analysis cannot be compared, so they can be greyed out
+	for _, revision := range revisions {
+		if _, err := services.ParseAnalysisData(revision.AnalysisData); err != nil {
+			revision.AnalysisUnreadable = true
+		}
+	}
+
 	respondJSON(w, http.StatusOK, revisions)
 }
 
 // CompareBlueprintRevisions compares two blueprint versions and returns the differences
-func (h *Handler) CompareBlueprintRevisions(w http.ResponseWriter, r *http.Request) {
-	blueprintID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *RevisionHandlers) CompareBlueprintRevisions(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid blueprint ID")
+		respondInvalidID(w)
 		return
 	}
 
@@ -44,41 +109,52 @@ func (h *Handler) CompareBlueprintRevisions(w http.ResponseWriter, r *http.Reque
 	toVersionStr := r.URL.Query().Get("to")
 
 	if fromVersionStr == "" || toVersionStr == "" {
-		respondError(w, http.StatusBadRequest, "from and to version query parameters are required")
+		respondMissingParam(w, "from,to", "from and to version query parameters are required")
 		return
 	}
 
 	fromVersion, err := strconv.Atoi(fromVersionStr)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid from version")
+		respondInvalidParam(w, "from", "Invalid from version")
 		return
 	}
 
 	toVersion, err := strconv.Atoi(toVersionStr)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid to version")
+		respondInvalidParam(w, "to", "Invalid to version")
+		return
+	}
+
+	_, project, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
 		return
 	}
 
 	// Get revisions
 	fromRevision, err := h.blueprintRevisionRepo.GetByVersion(r.Context(), blueprintID, fromVersion)
 	if err != nil {
-		respondError(w, http.StatusNotFound, fmt.Sprintf("From version %d not found", fromVersion))
+		respondNotFound(w)
 		return
 	}
 
 	toRevision, err := h.blueprintRevisionRepo.GetByVersion(r.Context(), blueprintID, toVersion)
 	if err != nil {
-		respondError(w, http.StatusNotFound, fmt.Sprintf("To version %d not found", toVersion))
+		respondNotFound(w)
 		return
 	}
 
 	// Compare revisions
-	comparisonService := services.NewComparisonService()
-	comparison, err := comparisonService.CompareBlueprintRevisions(fromRevision, toRevision)
+	comparison, err := services.NewComparisonService().
+		WithImpactThresholds(h.companyImpactThresholds(r.Context(), project)).
+		CompareBlueprintRevisions(fromRevision, toRevision)
 	if err != nil {
 		slog.Error("Failed to compare blueprint revisions", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to compare revisions")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to compare revisions")
+		return
+	}
+
+	if r.URL.Query().Get("summary") == "true" {
+		respondJSON(w, http.StatusOK, comparison.Digest())
 		return
 	}
 
@@ -86,94 +162,127 @@ func (h *Handler) CompareBlueprintRevisions(w http.ResponseWriter, r *http.Reque
 }
 
 // CreateBlueprintRevision creates a new revision snapshot when a blueprint is updated
-func (h *Handler) CreateBlueprintRevision(w http.ResponseWriter, r *http.Request) {
-	blueprintID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *RevisionHandlers) CreateBlueprintRevision(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid blueprint ID")
+		respondInvalidID(w)
 		return
 	}
 
 	// Get current blueprint
-	blueprint, err := h.blueprintRepo.GetByID(r.Context(), blueprintID)
+	blueprint, _, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
+		return
+	}
+
+	revision, err := services.CreateBlueprintRevision(r.Context(), h.blueprintRevisionRepo, blueprint, requestUserID(r), "")
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Blueprint not found")
+		slog.Error("Failed to create blueprint revision", "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to create revision")
 		return
 	}
+	recordBlueprintAsset(r.Context(), h.blueprintAssetRepo, revisionAsset(revision))
 
-	// Get next version number
-	latestVersion, err := h.blueprintRevisionRepo.GetLatestVersion(r.Context(), blueprintID)
+	// Save the blueprint's new version
+	blueprint.UpdatedAt = models.Now()
+	if err := h.blueprintRepo.Update(r.Context(), blueprint); err != nil {
+		slog.Warn("Failed to update blueprint version", "error", err)
+	}
+
+	respondJSON(w, http.StatusCreated, revision)
+}
+
+// RestoreBlueprintRevisionResponse is the blueprint rolled back to an
+// earlier revision and the revision recording the rollback.
+// AffectedBidIDs are bids priced from the analysis the restore replaced.
+type RestoreBlueprintRevisionResponse struct {
+	Blueprint      *models.Blueprint         `json:"blueprint"`
+	Revision       *models.BlueprintRevision `json:"revision"`
+	AffectedBidIDs []uuid.UUID               `json:"affected_bid_ids,omitempty"`
+	Warnings       []string                  `json:"warnings,omitempty"`
+}
+
+// RestoreBlueprintRevision rolls a blueprint back to one of its revisions
+func (h *RevisionHandlers) RestoreBlueprintRevision(w http.ResponseWriter, r *http.Request) {
+	blueprintID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		slog.Error("Failed to get latest version", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get latest version")
+		respondInvalidID(w)
 		return
 	}
 
-	newVersion := latestVersion + 1
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		respondInvalidParam(w, "version", "Invalid version")
+		return
+	}
 
-	// Create revision from current blueprint
-	revision := &models.BlueprintRevision{
-		ID:           uuid.New(),
-		BlueprintID:  blueprintID,
-		Version:      newVersion,
-		Filename:     blueprint.Filename,
-		S3Key:        blueprint.S3Key,
-		FileSize:     blueprint.FileSize,
-		MimeType:     blueprint.MimeType,
-		AnalysisData: blueprint.AnalysisData,
-		CreatedAt:    time.Now(),
+	blueprint, project, ok := loadUserBlueprint(w, r, h.blueprintRepo, h.projectRepo, blueprintID)
+	if !ok {
+		return
 	}
 
-	// Get user ID from context if available
-	userID := getUserID(r.Context())
-	if userID != "" {
-		if uid, err := uuid.Parse(userID); err == nil {
-			revision.CreatedBy = &uid
-		}
+	target, err := h.blueprintRevisionRepo.GetByVersion(r.Context(), blueprintID, version)
+	if err != nil {
+		respondNotFound(w)
+		return
 	}
 
-	// Compare with previous version if exists
-	if latestVersion > 0 {
-		prevRevision, err := h.blueprintRevisionRepo.GetByVersion(r.Context(), blueprintID, latestVersion)
-		if err == nil {
-			comparisonService := services.NewComparisonService()
-			comparison, err := comparisonService.CompareBlueprintRevisions(prevRevision, revision)
-			if err == nil {
-				// Store changes summary
-				summaryJSON, _ := json.Marshal(comparison)
-				summaryStr := string(summaryJSON)
-				revision.ChangesSummary = &summaryStr
-			}
+	// The snapshot, the restore revision and the restored blueprint are
+	// saved together, so a failed save leaves no restore on record
+	var revision *models.BlueprintRevision
+	err = inTx(r.Context(), h.tx, func(ctx context.Context) error {
+		var err error
+		revision, err = services.RestoreBlueprintRevision(ctx, h.blueprintRevisionRepo, blueprint, target, requestUserID(r))
+		if err != nil {
+			return err
 		}
+		blueprint.UpdatedAt = models.Now()
+		if err := h.blueprintRepo.Update(ctx, blueprint); err != nil {
+			return fmt.Errorf("failed to save restored blueprint: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, services.ErrRestoreLatestRevision) {
+		respondAPIError(w, http.StatusConflict, CodeRevisionIsLatest, "Blueprint is already at this version")
+		return
 	}
-
-	if err := h.blueprintRevisionRepo.Create(r.Context(), revision); err != nil {
-		slog.Error("Failed to create blueprint revision", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to create revision")
+	if err != nil {
+		slog.Error("Failed to restore blueprint revision", "blueprint_id", blueprintID, "version", version, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to restore revision")
 		return
 	}
+	recordBlueprintAsset(r.Context(), h.blueprintAssetRepo, revisionAsset(revision))
 
-	// Update blueprint version
-	blueprint.Version = newVersion
-	blueprint.UpdatedAt = time.Now()
-	if err := h.blueprintRepo.Update(r.Context(), blueprint); err != nil {
-		slog.Warn("Failed to update blueprint version", "error", err)
+	response := RestoreBlueprintRevisionResponse{Blueprint: blueprint, Revision: revision}
+	bids, err := h.bidRepo.GetByProjectID(r.Context(), project.ID)
+	if err != nil {
+		slog.Warn("Failed to get bids affected by restore", "blueprint_id", blueprintID, "error", err)
+	}
+	response.AffectedBidIDs = services.BidsPricedAfterRevision(bids, blueprintID, version)
+	if len(response.AffectedBidIDs) > 0 {
+		response.Warnings = append(response.Warnings, fmt.Sprintf(
+			"%d bid(s) were priced from the analysis this restore replaced and may need regenerating", len(response.AffectedBidIDs)))
 	}
 
-	respondJSON(w, http.StatusCreated, revision)
+	respondJSON(w, http.StatusOK, response)
 }
 
 // GetBidRevisions returns all revisions for a bid
-func (h *Handler) GetBidRevisions(w http.ResponseWriter, r *http.Request) {
-	bidID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *RevisionHandlers) GetBidRevisions(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid bid ID")
+		respondInvalidID(w)
+		return
+	}
+
+	if _, _, ok := loadUserBid(w, r, h.bidRepo, h.projectRepo, bidID); !ok {
 		return
 	}
 
 	revisions, err := h.bidRevisionRepo.GetByBidID(r.Context(), bidID)
 	if err != nil {
 		slog.Error("Failed to get bid revisions", "bid_id", bidID, "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get bid revisions")
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to get bid revisions")
 		return
 	}
 
@@ -181,88 +290,179 @@ func (h *Handler) GetBidRevisions(w http.ResponseWriter, r *http.Request) {
 }
 
 // CompareBidRevisions compares two bid versions and returns the differences
-func (h *Handler) CompareBidRevisions(w http.ResponseWriter, r *http.Request) {
-	bidID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *RevisionHandlers) CompareBidRevisions(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid bid ID")
+		respondInvalidID(w)
+		return
+	}
+
+	bid, project, ok := loadUserBid(w, r, h.bidRepo, h.projectRepo, bidID)
+	if !ok {
+		return
+	}
+
+	comparison, _, _, ok := h.loadBidComparison(w, r, bid, project)
+	if !ok {
+		return
+	}
+
+	if r.URL.Query().Get("summary") == "true" {
+		respondJSON(w, http.StatusOK, comparison.Digest())
 		return
 	}
 
+	respondJSON(w, http.StatusOK, comparison)
+}
+
+// GetBidComparisonPDF renders the comparison between two bid versions as a
+// PDF. With save=true the PDF is also stored in S3 and its URL returned in
+// the Content-Location header.
+func (h *RevisionHandlers) GetBidComparisonPDF(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	bid, project, ok := loadUserBid(w, r, h.bidRepo, h.projectRepo, bidID)
+	if !ok {
+		return
+	}
+
+	comparison, fromRevision, toRevision, ok := h.loadBidComparison(w, r, bid, project)
+	if !ok {
+		return
+	}
+
+	pdfBytes, err := services.NewPDFService().GenerateComparisonPDF(comparison, fromRevision, toRevision, project.Name)
+	if err != nil {
+		slog.Error("Failed to generate comparison PDF", "bid_id", bidID, "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to generate PDF")
+		return
+	}
+
+	if r.URL.Query().Get("save") == "true" {
+		if h.s3Service == nil {
+			respondAPIError(w, http.StatusServiceUnavailable, CodeStorageUnavailable, "PDF storage is not configured")
+			return
+		}
+		key := fmt.Sprintf("bids/%s/%s/comparisons/v%d-v%d.pdf", bid.ProjectID, bid.ID, comparison.FromVersion, comparison.ToVersion)
+		url, err := h.s3Service.UploadFile(r.Context(), key, pdfBytes, "application/pdf")
+		if err != nil {
+			slog.Error("Failed to upload comparison PDF", "bid_id", bidID, "error", err)
+			errreport.CaptureError(r.Context(), err, map[string]string{errreport.TagComponent: "s3", "bid_id": bidID.String()})
+			respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to save PDF")
+			return
+		}
+		w.Header().Set("Content-Location", url)
+	}
+
+	filename := fmt.Sprintf("bid-%s-v%d-v%d-comparison.pdf", bid.ID.String()[:8], comparison.FromVersion, comparison.ToVersion)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.Write(pdfBytes)
+}
+
+// loadBidComparison compares the versions of a bid in project named by the
+// from and to query parameters. It writes the error response and returns
+// false on failure.
+func (h *RevisionHandlers) loadBidComparison(w http.ResponseWriter, r *http.Request, bid *models.Bid, project *models.Project) (*models.BidComparison, *models.BidRevision, *models.BidRevision, bool) {
 	fromVersionStr := r.URL.Query().Get("from")
 	toVersionStr := r.URL.Query().Get("to")
 
 	if fromVersionStr == "" || toVersionStr == "" {
-		respondError(w, http.StatusBadRequest, "from and to version query parameters are required")
-		return
+		respondMissingParam(w, "from,to", "from and to version query parameters are required")
+		return nil, nil, nil, false
 	}
 
 	fromVersion, err := strconv.Atoi(fromVersionStr)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid from version")
-		return
+		respondInvalidParam(w, "from", "Invalid from version")
+		return nil, nil, nil, false
 	}
 
 	toVersion, err := strconv.Atoi(toVersionStr)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid to version")
-		return
+		respondInvalidParam(w, "to", "Invalid to version")
+		return nil, nil, nil, false
 	}
 
 	// Get revisions
-	fromRevision, err := h.bidRevisionRepo.GetByVersion(r.Context(), bidID, fromVersion)
+	fromRevision, err := h.bidRevisionRepo.GetByVersion(r.Context(), bid.ID, fromVersion)
 	if err != nil {
-		respondError(w, http.StatusNotFound, fmt.Sprintf("From version %d not found", fromVersion))
-		return
+		respondNotFound(w)
+		return nil, nil, nil, false
 	}
 
-	toRevision, err := h.bidRevisionRepo.GetByVersion(r.Context(), bidID, toVersion)
+	toRevision, err := h.bidRevisionRepo.GetByVersion(r.Context(), bid.ID, toVersion)
 	if err != nil {
-		respondError(w, http.StatusNotFound, fmt.Sprintf("To version %d not found", toVersion))
-		return
+		respondNotFound(w)
+		return nil, nil, nil, false
 	}
 
 	// Compare revisions
-	comparisonService := services.NewComparisonService()
-	comparison, err := comparisonService.CompareBidRevisions(fromRevision, toRevision)
+	comparison, err := services.NewComparisonService().
+		WithImpactThresholds(h.companyImpactThresholds(r.Context(), project)).
+		CompareBidRevisions(fromRevision, toRevision)
 	if err != nil {
 		slog.Error("Failed to compare bid revisions", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to compare revisions")
-		return
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to compare revisions")
+		return nil, nil, nil, false
 	}
 
-	respondJSON(w, http.StatusOK, comparison)
+	return comparison, fromRevision, toRevision, true
+}
+
+// companyImpactThresholds are the comparison impact thresholds of the company
+// that owns a project, nil for the defaults. Comparisons are still labelled by
+// the defaults when they can't be loaded.
+func (h *RevisionHandlers) companyImpactThresholds(ctx context.Context, project *models.Project) *models.ImpactThresholds {
+	owner, err := h.userRepo.GetUserByID(ctx, project.UserID)
+	if err != nil {
+		slog.Warn("Failed to load company impact thresholds", "error", err, "user_id", project.UserID)
+		return nil
+	}
+	return owner.ImpactThresholds
 }
 
 // CreateBidRevision creates a new revision snapshot when a bid is updated
-func (h *Handler) CreateBidRevision(w http.ResponseWriter, r *http.Request) {
-	bidID, err := uuid.Parse(chi.URLParam(r, "id"))
+func (h *RevisionHandlers) CreateBidRevision(w http.ResponseWriter, r *http.Request) {
+	bidID, err := parseUUIDParam(r, "id")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid bid ID")
+		respondInvalidID(w)
 		return
 	}
 
 	// Get current bid
-	bid, err := h.bidRepo.GetByID(r.Context(), bidID)
-	if err != nil {
-		respondError(w, http.StatusNotFound, "Bid not found")
+	bid, _, ok := loadUserBid(w, r, h.bidRepo, h.projectRepo, bidID)
+	if !ok || !requireEditableBid(w, bid) {
 		return
 	}
 
-	// Get next version number
-	latestVersion, err := h.bidRevisionRepo.GetLatestVersion(r.Context(), bidID)
+	revision, err := createBidRevision(r.Context(), h.bidRevisionRepo, bid, getUserID(r.Context()), nil, "")
 	if err != nil {
-		slog.Error("Failed to get latest version", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to get latest version")
+		slog.Error("Failed to create bid revision", "error", err)
+		respondAPIError(w, http.StatusInternalServerError, CodeInternalError, "Failed to create revision")
 		return
 	}
 
-	newVersion := latestVersion + 1
+	// Update bid version
+	bid.Version = revision.Version
+	bid.UpdatedAt = models.Now()
+	if err := h.bidRepo.Update(r.Context(), bid); err != nil {
+		slog.Warn("Failed to update bid version", "error", err)
+	}
 
-	// Create revision from current bid
+	respondJSON(w, http.StatusCreated, revision)
+}
+
+// newBidRevision snapshots a bid as revision version
+func newBidRevision(bid *models.Bid, version int, userID string) *models.BidRevision {
 	revision := &models.BidRevision{
 		ID:               uuid.New(),
-		BidID:            bidID,
-		Version:          newVersion,
+		BidID:            bid.ID,
+		Version:          version,
 		Name:             bid.Name,
 		TotalCost:        bid.TotalCost,
 		LaborCost:        bid.LaborCost,
@@ -271,44 +471,48 @@ func (h *Handler) CreateBidRevision(w http.ResponseWriter, r *http.Request) {
 		FinalPrice:       bid.FinalPrice,
 		Status:           bid.Status,
 		BidData:          bid.BidData,
-		CreatedAt:        time.Now(),
+		GenerationModel:  bid.GenerationModel,
+		BlueprintIDs:     bid.BlueprintIDs,
+		CreatedAt:        models.Now(),
 	}
-
-	// Get user ID from context if available
-	userID := getUserID(r.Context())
-	if userID != "" {
-		if uid, err := uuid.Parse(userID); err == nil {
-			revision.CreatedBy = &uid
-		}
+	if uid, err := uuid.Parse(userID); err == nil {
+		revision.CreatedBy = &uid
 	}
+	return revision
+}
 
-	// Compare with previous version if exists
-	if latestVersion > 0 {
-		prevRevision, err := h.bidRevisionRepo.GetByVersion(r.Context(), bidID, latestVersion)
-		if err == nil {
-			comparisonService := services.NewComparisonService()
-			comparison, err := comparisonService.CompareBidRevisions(prevRevision, revision)
-			if err == nil {
-				// Store changes summary
-				summaryJSON, _ := json.Marshal(comparison)
-				summaryStr := string(summaryJSON)
-				revision.ChangesSummary = &summaryStr
-			}
-		}
+// createBidRevision stores the bid's current state as its next revision,
+// recording reason when it is not empty. The changes summary compares against
+// previous, or the latest stored revision when previous is nil. The caller
+// updates the bid's version.
+func createBidRevision(ctx context.Context, revisions BidRevisionStore, bid *models.Bid, userID string, previous *models.BidRevision, reason string) (*models.BidRevision, error) {
+	latestVersion, err := revisions.GetLatestVersion(ctx, bid.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest version: %w", err)
 	}
 
-	if err := h.bidRevisionRepo.Create(r.Context(), revision); err != nil {
-		slog.Error("Failed to create bid revision", "error", err)
-		respondError(w, http.StatusInternalServerError, "Failed to create revision")
-		return
+	revision := newBidRevision(bid, latestVersion+1, userID)
+	if reason != "" {
+		revision.Reason = &reason
 	}
 
-	// Update bid version
-	bid.Version = newVersion
-	bid.UpdatedAt = time.Now()
-	if err := h.bidRepo.Update(r.Context(), bid); err != nil {
-		slog.Warn("Failed to update bid version", "error", err)
+	if previous == nil && latestVersion > 0 {
+		if prevRevision, err := revisions.GetByVersion(ctx, bid.ID, latestVersion); err == nil {
+			previous = prevRevision
+		}
+	}
+	if previous != nil {
+		comparison, err := services.NewComparisonService().CompareBidRevisions(previous, revision)
+		if err == nil {
+			// Store the compact digest; the full change list can be recomputed
+			summaryJSON, _ := json.Marshal(comparison.Digest())
+			summaryStr := string(summaryJSON)
+			revision.ChangesSummary = &summaryStr
+		}
 	}
 
-	respondJSON(w, http.StatusCreated, revision)
+	if err := revisions.Create(ctx, revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
 }
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// newCorruptRevisionRouter serves the revision routes over an owner's
+// blueprint whose second revision holds an analysis that does not parse
+func newCorruptRevisionRouter() (blueprintID, ownerID uuid.UUID, router chi.Router) {
+	project := &models.Project{ID: uuid.New(), UserID: uuid.New()}
+	blueprint := &models.Blueprint{ID: uuid.New(), ProjectID: project.ID}
+	blueprintID = blueprint.ID
+	good := `{"rooms":[{"name":"Kitchen","dimensions":"12x15","area":180}]}`
+	corrupt := `"{\"rooms\": [truncated"`
+	revisions := &fakeBlueprintRevisionStore{revisions: []*models.BlueprintRevision{
+		{ID: uuid.New(), BlueprintID: blueprintID, Version: 1, Filename: "plans.pdf", AnalysisData: &good},
+		{ID: uuid.New(), BlueprintID: blueprintID, Version: 2, Filename: "plans-v2.pdf", AnalysisData: &corrupt},
+	}}
+
+	projects := &fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}}
+	blueprints := &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}}
+	router = chi.NewRouter()
+	NewRevisionHandlers(projects, blueprints, revisions, nil, &fakeBidStore{}, &fakeBidRevisionStore{}, &fakeUserStore{}, nil, nil).Routes(router)
+	return blueprintID, project.UserID, router
+}
+
+func TestGetBlueprintRevisions_FlagsUnreadable(t *testing.T) {
+	blueprintID, ownerID, router := newCorruptRevisionRouter()
+
+	rec := serveAsUser(router, ownerID, http.MethodGet, "/blueprints/"+blueprintID.String()+"/revisions", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var revisions []models.BlueprintRevision
+	if err := json.NewDecoder(rec.Body).Decode(&revisions); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	unreadable := map[int]bool{}
+	for _, revision := range revisions {
+		unreadable[revision.Version] = revision.AnalysisUnreadable
+	}
+	if unreadable[1] || !unreadable[2] {
+		t.Errorf("unreadable = %v, want only version 2 flagged", unreadable)
+	}
+}
+
+func TestCompareBlueprintRevisions_CorruptRevision(t *testing.T) {
+	blueprintID, ownerID, router := newCorruptRevisionRouter()
+
+	rec := serveAsUser(router, ownerID, http.MethodGet, "/blueprints/"+blueprintID.String()+"/compare?from=1&to=2", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var comparison models.BlueprintComparison
+	if err := json.NewDecoder(rec.Body).Decode(&comparison); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(comparison.ParseErrors) != 1 || comparison.ParseErrors[0].Version != 2 {
+		t.Errorf("parse_errors = %+v, want version 2", comparison.ParseErrors)
+	}
+	if len(comparison.Changes) != 1 || comparison.Changes[0].Category != "file" {
+		t.Errorf("changes = %+v, want only the filename change", comparison.Changes)
+	}
+}
+
+func TestCompareBidRevisions_CompanyImpactThresholds(t *testing.T) {
+	ownerID, projectID, bidID := uuid.New(), uuid.New(), uuid.New()
+	users := &fakeUserStore{users: map[uuid.UUID]*models.User{ownerID: {
+		ID:               ownerID,
+		ImpactThresholds: &models.ImpactThresholds{DollarFloor: 500},
+	}}}
+	projects := &fakeProjectStore{projects: map[uuid.UUID]*models.Project{projectID: {ID: projectID, UserID: ownerID}}}
+	bids := &fakeBidStore{bids: []*models.Bid{{ID: bidID, ProjectID: projectID}}}
+	before, after := 10000.0, 10200.0
+	revisions := &fakeBidRevisionStore{revisions: []*models.BidRevision{
+		{ID: uuid.New(), BidID: bidID, Version: 1, FinalPrice: &before},
+		{ID: uuid.New(), BidID: bidID, Version: 2, FinalPrice: &after},
+	}}
+
+	router := chi.NewRouter()
+	NewRevisionHandlers(projects, &fakeBlueprintStore{}, &fakeBlueprintRevisionStore{}, nil, bids, revisions, users, nil, nil).Routes(router)
+
+	rec := serveAsUser(router, ownerID, http.MethodGet, "/bids/"+bidID.String()+"/compare?from=1&to=2", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var comparison models.BidComparison
+	if err := json.NewDecoder(rec.Body).Decode(&comparison); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(comparison.Changes) != 1 || *comparison.Changes[0].Impact != models.ImpactLow {
+		t.Errorf("changes = %+v, want a Low final price change under the company's $500 floor", comparison.Changes)
+	}
+	if comparison.ImpactThresholds.DollarFloor != 500 {
+		t.Errorf("impact_thresholds = %+v, want the company's", comparison.ImpactThresholds)
+	}
+}
+
+func TestRestoreBlueprintRevision(t *testing.T) {
+	project := &models.Project{ID: uuid.New(), UserID: uuid.New()}
+	v1 := `{"rooms":[{"name":"Kitchen","dimensions":"12x15","area":180}]}`
+	v2 := `{"rooms":[{"name":"Kitchen","dimensions":"12x15","area":180},{"name":"Bath","dimensions":"8x10","area":80}]}`
+	blueprint := &models.Blueprint{
+		ID: uuid.New(), ProjectID: project.ID, Filename: "plans-v2.pdf", S3Key: "blueprints/plans-v2.pdf",
+		AnalysisStatus: models.AnalysisStatusCompleted, AnalysisData: &v2, Version: 2,
+	}
+	revisions := &fakeBlueprintRevisionStore{revisions: []*models.BlueprintRevision{
+		{ID: uuid.New(), BlueprintID: blueprint.ID, Version: 1, Filename: "plans.pdf", S3Key: "blueprints/plans.pdf", AnalysisData: &v1},
+		{ID: uuid.New(), BlueprintID: blueprint.ID, Version: 2, Filename: "plans-v2.pdf", S3Key: "blueprints/plans-v2.pdf", AnalysisData: &v2},
+	}}
+	bidData, _ := json.Marshal(models.GenerateBidResponse{SourceBlueprints: []models.BidSourceBlueprint{{BlueprintID: blueprint.ID, Version: 2}}})
+	bidDataStr := string(bidData)
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, BidData: &bidDataStr, BlueprintIDs: []uuid.UUID{blueprint.ID}}
+
+	blueprints := &fakeBlueprintStore{blueprints: map[uuid.UUID]*models.Blueprint{blueprint.ID: blueprint}}
+	tx := &fakeTransactor{}
+	router := chi.NewRouter()
+	NewRevisionHandlers(
+		&fakeProjectStore{projects: map[uuid.UUID]*models.Project{project.ID: project}},
+		blueprints, revisions, nil, &fakeBidStore{bids: []*models.Bid{bid}}, &fakeBidRevisionStore{}, &fakeUserStore{}, nil, tx,
+	).Routes(router)
+	path := "/blueprints/" + blueprint.ID.String() + "/revisions/"
+
+	if rec := serveAsUser(router, uuid.New(), http.MethodPost, path+"1/restore", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's restore: status = %d, want 404", rec.Code)
+	}
+	if rec := serveAsUser(router, project.UserID, http.MethodPost, path+"9/restore", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown version: status = %d, want 404", rec.Code)
+	}
+	if rec := serveAsUser(router, project.UserID, http.MethodPost, path+"latest/restore", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid version: status = %d, want 400", rec.Code)
+	}
+	if rec := serveAsUser(router, project.UserID, http.MethodPost, path+"2/restore", ""); rec.Code != http.StatusConflict {
+		t.Errorf("restore to the latest version: status = %d, want 409", rec.Code)
+	}
+
+	// A restore whose blueprint can't be saved rolls back its revision
+	rollbacks := tx.rollbacks
+	blueprints.updateErr = errors.New("connection reset")
+	if rec := serveAsUser(router, project.UserID, http.MethodPost, path+"1/restore", ""); rec.Code != http.StatusInternalServerError || tx.rollbacks != rollbacks+1 {
+		t.Errorf("failed save: status = %d, %d rollbacks; want 500 and the restore rolled back", rec.Code, tx.rollbacks)
+	}
+	blueprints.updateErr = nil
+	revisions.revisions = revisions.revisions[:2]
+	blueprint.Filename, blueprint.S3Key, blueprint.AnalysisData, blueprint.Version = "plans-v2.pdf", "blueprints/plans-v2.pdf", &v2, 2
+
+	rec := serveAsUser(router, project.UserID, http.MethodPost, path+"1/restore", "")
+	if rec.Code != http.StatusOK || tx.commits != 1 {
+		t.Fatalf("restore: status = %d with %d commits, body %s", rec.Code, tx.commits, rec.Body.String())
+	}
+	var restored RestoreBlueprintRevisionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&restored); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if blueprint.Filename != "plans.pdf" || blueprint.S3Key != "blueprints/plans.pdf" || *blueprint.AnalysisData != v1 || blueprint.Version != 3 {
+		t.Errorf("blueprint = %s at version %d, want version 1's file and analysis at version 3", blueprint.Filename, blueprint.Version)
+	}
+	if restored.Revision == nil || restored.Revision.Version != 3 || restored.Revision.Reason == nil || *restored.Revision.Reason != models.BlueprintRevisionReasonRestore {
+		t.Fatalf("revision = %+v, want a restore revision at version 3", restored.Revision)
+	}
+	var digest models.ComparisonDigest
+	if err := json.Unmarshal([]byte(*restored.Revision.ChangesSummary), &digest); err != nil || digest.Note != "Restored from version 1" {
+		t.Errorf("changes summary = %s, want it noted as restored from version 1", *restored.Revision.ChangesSummary)
+	}
+	if len(restored.AffectedBidIDs) != 1 || restored.AffectedBidIDs[0] != bid.ID || len(restored.Warnings) != 1 {
+		t.Errorf("affected bids = %v, warnings %v; want the bid priced from version 2", restored.AffectedBidIDs, restored.Warnings)
+	}
+
+	// The restore shows up as the Bath removed again
+	rec = serveAsUser(router, project.UserID, http.MethodGet, "/blueprints/"+blueprint.ID.String()+"/compare?from=2&to=3", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("compare: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var comparison models.BlueprintComparison
+	if err := json.NewDecoder(rec.Body).Decode(&comparison); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(comparison.Changes) != 1 || comparison.Changes[0].Category != "room" || comparison.Changes[0].ChangeType != models.ChangeTypeRemoved {
+		t.Errorf("changes = %+v, want only the Bath removed", comparison.Changes)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+)
+
+// APIPrefixV1 is the path prefix of version 1 of the API
+const APIPrefixV1 = "/v1"
+
+// LegacyRoutesDeprecatedAt is when the unprefixed API routes were deprecated
+// in favor of /v1
+var LegacyRoutesDeprecatedAt = time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC)
+
+// MountAPI registers the API under /v1 and, until the legacy routes are
+// removed, at the root as a deprecated alias whose responses carry the
+// Deprecation and Sunset headers. register is run once per mount, so both
+// share the same handlers; it registers every API route, public and
+// protected, but not the unversioned root and health routes.
+func MountAPI(r chi.Router, legacySunset time.Time, register func(r chi.Router)) {
+	r.Route(APIPrefixV1, func(r chi.Router) {
+		r.Use(middleware.APIVersion(middleware.APIVersion1))
+		register(r)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Deprecated(LegacyRoutesDeprecatedAt, legacySunset, APIPrefixV1))
+		r.Use(middleware.APIVersion(middleware.APIVersion1))
+		register(r)
+	})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+)
+
+func TestMountAPIServesEveryLegacyRouteUnderV1(t *testing.T) {
+	r := chi.NewRouter()
+	(&SystemHandlers{}).Routes(r)
+	auth := &AuthHandlers{}
+	MountAPI(r, time.Date(2027, time.April, 15, 0, 0, 0, 0, time.UTC), func(r chi.Router) {
+		auth.PublicRoutes(r)
+		auth.Routes(r)
+		(&ProjectHandlers{}).Routes(r)
+		(&BlueprintHandlers{}).Routes(r)
+		(&JobHandlers{}).Routes(r)
+		(&BidHandlers{}).Routes(r)
+		(&RevisionHandlers{}).Routes(r)
+		(&CostHandlers{}).Routes(r)
+		(&AnalyticsHandlers{}).Routes(r)
+		(&AdminHandlers{}).Routes(r)
+		(&APIKeyHandlers{}).Routes(r)
+	})
+
+	routes := make(map[string]bool)
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes[method+" "+strings.TrimSuffix(route, "/")] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk routes: %v", err)
+	}
+
+	var legacy int
+	for route := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		if strings.HasPrefix(path, APIPrefixV1+"/") || path == "" || path == "/health" {
+			continue
+		}
+		legacy++
+		if !routes[method+" "+APIPrefixV1+path] {
+			t.Errorf("%s has no %s counterpart", route, APIPrefixV1)
+		}
+	}
+	if legacy == 0 {
+		t.Fatal("expected legacy routes to be mounted")
+	}
+}
+
+func TestMountAPIDeprecatesLegacyRoutes(t *testing.T) {
+	sunset := time.Date(2027, time.April, 15, 0, 0, 0, 0, time.UTC)
+	r := chi.NewRouter()
+	MountAPI(r, sunset, func(r chi.Router) {
+		r.Get("/projects", func(w http.ResponseWriter, r *http.Request) {
+			if version := middleware.RequestedAPIVersion(r.Context()); version != middleware.APIVersion1 {
+				t.Errorf("expected API version 1, got %d", version)
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/projects", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Deprecation"); got != "@1792022400" {
+		t.Errorf("expected Deprecation @1792022400, got %q", got)
+	}
+	if got := w.Header().Get("Sunset"); got != "Thu, 15 Apr 2027 00:00:00 GMT" {
+		t.Errorf("unexpected Sunset %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `</v1/projects>; rel="successor-version"` {
+		t.Errorf("unexpected Link %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/projects", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	for _, header := range []string{"Deprecation", "Sunset", "Link"} {
+		if got := w.Header().Get(header); got != "" {
+			t.Errorf("expected no %s header under /v1, got %q", header, got)
+		}
+	}
+	if got := w.Header().Get(middleware.APIVersionHeader); got != "1" {
+		t.Errorf("expected API-Version 1, got %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+const (
+	minSearchQueryLength = 3
+	defaultSearchLimit   = 20
+	maxSearchLimit       = 100
+)
+
+type BlueprintTextSearchResponse struct {
+	ProjectID uuid.UUID                   `json:"project_id"`
+	Query     string                      `json:"query"`
+	Results   []models.BlueprintTextMatch `json:"results"`
+}
+
+// SearchBlueprintText searches the raw OCR text of all blueprints in a project
+func (h *BlueprintHandlers) SearchBlueprintText(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+
+	query, err := parseSearchQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := defaultSearchLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(parsed, maxSearchLimit)
+	}
+
+	// Only search projects owned by the requesting user
+	project, ok := loadUserProject(w, r, h.projectRepo, projectID)
+	if !ok {
+		return
+	}
+
+	results, err := h.blueprintRepo.SearchOCRText(r.Context(), project.ID, query, limit)
+	if err != nil {
+		slog.Error("Failed to search blueprint text", "project_id", project.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to search blueprints")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, BlueprintTextSearchResponse{
+		ProjectID: project.ID,
+		Query:     query,
+		Results:   results,
+	})
+}
+
+// parseSearchQuery trims the raw query and enforces the minimum length
+func parseSearchQuery(raw string) (string, error) {
+	query := strings.TrimSpace(raw)
+	if utf8.RuneCountInString(query) < minSearchQueryLength {
+		return "", fmt.Errorf("q must be at least %d characters", minSearchQueryLength)
+	}
+	return query, nil
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "valid query", raw: "electrical panel", want: "electrical panel"},
+		{name: "trims whitespace", raw: "  kitchen  ", want: "kitchen"},
+		{name: "exactly minimum length", raw: "bay", want: "bay"},
+		{name: "too short", raw: "ab", wantErr: true},
+		{name: "short after trimming", raw: "  ab  ", wantErr: true},
+		{name: "empty", raw: "", wantErr: true},
+		{name: "multibyte counted as characters", raw: "évé", want: "évé"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSearchQuery(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got none", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// The stores below are the slices of the repositories the handler groups
+// use. The repository types implement them; tests substitute fakes.
+
+// Transactor runs fn in a database transaction. Store calls made with the
+// context fn receives take part in it.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// HealthChecker reports whether the database is reachable
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// ProjectStore reads and updates projects
+type ProjectStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error)
+	GetByIDAndUserID(ctx context.Context, id, userID uuid.UUID) (*models.Project, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, status *models.ProjectStatus) ([]*models.Project, error)
+	Create(ctx context.Context, project *models.Project) error
+	Update(ctx context.Context, project *models.Project) error
+	UpdateBudget(ctx context.Context, id uuid.UUID, budget *float64) error
+	UpdateProjectType(ctx context.Context, id uuid.UUID, projectType models.ProjectType) error
+	UpdateTaxExempt(ctx context.Context, id uuid.UUID, exempt bool) error
+}
+
+// BlueprintStore reads and writes blueprints
+type BlueprintStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Blueprint, error)
+	GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]*models.Blueprint, error)
+	Create(ctx context.Context, blueprint *models.Blueprint) error
+	Update(ctx context.Context, blueprint *models.Blueprint) error
+	IncrementUploadGeneration(ctx context.Context, id uuid.UUID) (int, error)
+	UpdateRoomFinishes(ctx context.Context, id uuid.UUID, finishes map[string]models.FloorFinish) error
+	UpdateTakeoffAdjustments(ctx context.Context, id uuid.UUID, adjustments []models.TakeoffAdjustment) error
+	SearchOCRText(ctx context.Context, projectID uuid.UUID, searchQuery string, limit int) ([]models.BlueprintTextMatch, error)
+}
+
+// BlueprintRevisionStore reads and writes blueprint revisions
+type BlueprintRevisionStore interface {
+	Create(ctx context.Context, revision *models.BlueprintRevision) error
+	GetByBlueprintID(ctx context.Context, blueprintID uuid.UUID) ([]*models.BlueprintRevision, error)
+	GetByVersion(ctx context.Context, blueprintID uuid.UUID, version int) (*models.BlueprintRevision, error)
+	GetLatestVersion(ctx context.Context, blueprintID uuid.UUID) (int, error)
+}
+
+// BlueprintAssetStore records the stored objects behind a blueprint
+type BlueprintAssetStore interface {
+	Record(ctx context.Context, asset *models.BlueprintAsset) error
+	ListByBlueprint(ctx context.Context, blueprintID uuid.UUID) ([]*models.BlueprintAsset, error)
+}
+
+// JobStore creates jobs and reports on the queue
+type JobStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	Create(ctx context.Context, job *models.Job) error
+	Update(ctx context.Context, job *models.Job) error
+	GetActiveTakeoffBlueprintIDs(ctx context.Context, blueprintIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	GetQueueDepth(ctx context.Context, userID uuid.UUID) (models.QueueDepth, error)
+}
+
+// QueueDepthReader counts queued jobs for the queue ceilings
+type QueueDepthReader interface {
+	GetQueueDepth(ctx context.Context, userID uuid.UUID) (models.QueueDepth, error)
+}
+
+// BidPDFJobStore queues bid PDF generation jobs and finds running ones
+type BidPDFJobStore interface {
+	QueueDepthReader
+	Create(ctx context.Context, job *models.Job) error
+	GetActiveBidPDFJob(ctx context.Context, bidID uuid.UUID) (*models.Job, error)
+}
+
+// BidStore reads and writes bids
+type BidStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Bid, error)
+	GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]*models.Bid, error)
+	List(ctx context.Context, filter models.BidListFilter) ([]*models.Bid, int, error)
+	Create(ctx context.Context, bid *models.Bid) error
+	Update(ctx context.Context, bid *models.Bid) error
+	LockVersion(ctx context.Context, id uuid.UUID, version int) error
+}
+
+// BidAnalyticsStore aggregates a company's bids
+type BidAnalyticsStore interface {
+	GetCompanyAnalytics(ctx context.Context, filter models.CompanyAnalyticsFilter) (*models.CompanyAnalytics, error)
+}
+
+// BidRevisionStore reads and writes bid revisions
+type BidRevisionStore interface {
+	Create(ctx context.Context, revision *models.BidRevision) error
+	GetByBidID(ctx context.Context, bidID uuid.UUID) ([]*models.BidRevision, error)
+	GetByVersion(ctx context.Context, bidID uuid.UUID, version int) (*models.BidRevision, error)
+	GetLatestVersion(ctx context.Context, bidID uuid.UUID) (int, error)
+}
+
+// BidShareStore reads, answers and revokes the links bids are shared with
+// clients by
+type BidShareStore interface {
+	Create(ctx context.Context, share *models.BidShare) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.BidShare, error)
+	Respond(ctx context.Context, id uuid.UUID, response models.BidShareResponse, at time.Time) (*models.BidShare, error)
+	Revoke(ctx context.Context, bidID, id uuid.UUID, at time.Time) (*models.BidShare, error)
+	ListByBidID(ctx context.Context, bidID uuid.UUID) ([]*models.BidShare, error)
+	MarkViewed(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+}
+
+// BidShareEventStore records and lists what clients do through share links
+type BidShareEventStore interface {
+	Record(ctx context.Context, event *models.BidShareEvent, limit int) (bool, error)
+	ListByBidID(ctx context.Context, bidID uuid.UUID) ([]models.BidShareEvent, error)
+}
+
+// BidAcceptanceStore records clients' online acceptances of shared bids
+type BidAcceptanceStore interface {
+	Create(ctx context.Context, acceptance *models.BidAcceptance) error
+}
+
+// BidDraftStore reads and writes users' uncommitted bid drafts
+type BidDraftStore interface {
+	Save(ctx context.Context, draft *models.BidDraft) error
+	Get(ctx context.Context, bidID, userID uuid.UUID, now time.Time) (*models.BidDraft, error)
+	Delete(ctx context.Context, bidID, userID uuid.UUID) error
+}
+
+// CompanyProfileStore reads and saves companies' bid document branding
+type CompanyProfileStore interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.CompanyProfile, error)
+	Save(ctx context.Context, profile *models.CompanyProfile) error
+}
+
+// ProjectPricingOverrideStore reads and writes project pricing overrides
+type ProjectPricingOverrideStore interface {
+	GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.ProjectPricingOverride, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ProjectPricingOverride, error)
+	Create(ctx context.Context, override *models.ProjectPricingOverride) error
+	Update(ctx context.Context, override *models.ProjectPricingOverride) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// MaterialStore writes the materials in the cost database
+type MaterialStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.MaterialCost, error)
+	Create(ctx context.Context, material *models.MaterialCost) error
+	Update(ctx context.Context, material *models.MaterialCost) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// LaborRateStore writes the labor rates in the cost database
+type LaborRateStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.LaborRate, error)
+	Create(ctx context.Context, rate *models.LaborRate) error
+	Update(ctx context.Context, rate *models.LaborRate) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// UserStore reads and updates users
+type UserStore interface {
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	SearchUsers(ctx context.Context, filter models.UserSearchFilter) ([]*models.User, error)
+	GetUserActivity(ctx context.Context, id uuid.UUID) (*models.UserActivity, error)
+	SetSuspended(ctx context.Context, id uuid.UUID, suspended bool) error
+	SetRole(ctx context.Context, id uuid.UUID, role models.UserRole, revokedAt time.Time) error
+	UpdateBidDefaults(ctx context.Context, id uuid.UUID, inclusions, exclusions []string) error
+	SetAutoBlueprintRevisions(ctx context.Context, id uuid.UUID, enabled bool) error
+	SetUnitSystem(ctx context.Context, id uuid.UUID, system models.UnitSystem) error
+	UpdateQuickBooksItems(ctx context.Context, id uuid.UUID, items map[string]string, defaultItem *string) error
+	UpdateTaxSettings(ctx context.Context, id uuid.UUID, settings *models.TaxSettings) error
+	UpdateImpactThresholds(ctx context.Context, id uuid.UUID, thresholds *models.ImpactThresholds) error
+	UpdatePDFLayout(ctx context.Context, id uuid.UUID, layout *models.PDFLayout) error
+	UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error
+	ChangePassword(ctx context.Context, id uuid.UUID, passwordHash string, revokedAt time.Time) error
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+var timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z$`)
+
+// collectTimestamps walks a decoded JSON payload and returns every value
+// under a key ending in "_at" or named "last_updated", keyed by path
+func collectTimestamps(path string, v interface{}, found map[string]interface{}) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			childPath := path + "." + key
+			if (strings.HasSuffix(key, "_at") || key == "last_updated") && child != nil {
+				found[childPath] = child
+				continue
+			}
+			collectTimestamps(childPath, child, found)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectTimestamps(path+"[]", child, found)
+		}
+	}
+}
+
+func TestResponseTimestampsAreRFC3339UTC(t *testing.T) {
+	// A non-UTC zone and sub-millisecond precision, as a driver may return
+	zone := time.FixedZone("EST", -5*60*60)
+	raw := time.Date(2024, 3, 1, 9, 30, 15, 123456789, zone)
+	ts := models.NewTimestamp(raw)
+	local := models.Timestamp{Time: raw}
+
+	userID := uuid.New()
+	projectID := uuid.New()
+	name := "Jane"
+
+	payloads := map[string]interface{}{
+		"user":               UserResponse{ID: userID.String(), Email: "jane@example.com", Name: &name, CreatedAt: local, UpdatedAt: ts},
+		"project":            models.Project{ID: projectID, UserID: userID, Name: "Office", CreatedAt: local, UpdatedAt: ts},
+		"blueprint":          models.Blueprint{ID: uuid.New(), ProjectID: projectID, Filename: "A-101.pdf", CreatedAt: local, UpdatedAt: ts},
+		"job":                JobStatusResponse{ID: uuid.New(), StartedAt: &local, CompletedAt: &ts, CreatedAt: local, UpdatedAt: ts},
+		"bid":                []models.Bid{{ID: uuid.New(), ProjectID: projectID, Status: models.BidStatusDraft, CreatedAt: local, UpdatedAt: ts}},
+		"blueprint_revision": models.BlueprintRevision{ID: uuid.New(), CreatedAt: local},
+		"bid_revision":       models.BidRevision{ID: uuid.New(), CreatedAt: local},
+		"upload_url":         UploadURLResponse{BlueprintID: uuid.New(), UploadURL: "https://example.com", ExpiresAt: local},
+	}
+
+	for name, payload := range payloads {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			respondJSON(rec, http.StatusOK, payload)
+
+			var decoded interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			found := make(map[string]interface{})
+			collectTimestamps("$", decoded, found)
+			if len(found) == 0 {
+				t.Fatal("expected the payload to contain timestamps")
+			}
+
+			for path, value := range found {
+				str, ok := value.(string)
+				if !ok || !timestampPattern.MatchString(str) {
+					t.Errorf("%s = %v, want RFC3339 UTC with milliseconds", path, value)
+					continue
+				}
+				parsed, err := time.Parse(time.RFC3339, str)
+				if err != nil {
+					t.Errorf("%s = %q does not parse as RFC3339: %v", path, str, err)
+					continue
+				}
+				if _, offset := parsed.Zone(); offset != 0 {
+					t.Errorf("%s = %q is not UTC", path, str)
+				}
+				if !parsed.Equal(raw.Truncate(time.Millisecond)) {
+					t.Errorf("%s = %q, want instant %s", path, str, raw.UTC().Format(models.TimestampFormat))
+				}
+			}
+		})
+	}
+}
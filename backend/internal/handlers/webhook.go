@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// webhookDeliveryLimit is how many recent deliveries are listed per webhook
+const webhookDeliveryLimit = 50
+
+// WebhookHandlers lets users register endpoints that are notified when
+// analysis or bid generation finishes, instead of polling job status
+type WebhookHandlers struct {
+	webhooks *services.WebhookService
+}
+
+func NewWebhookHandlers(webhooks *services.WebhookService) *WebhookHandlers {
+	return &WebhookHandlers{webhooks: webhooks}
+}
+
+// Routes registers the webhook routes
+func (h *WebhookHandlers) Routes(r chi.Router) {
+	r.Post("/api/webhooks", h.CreateWebhook)
+	r.Get("/api/webhooks", h.ListWebhooks)
+	r.Delete("/api/webhooks/{id}", h.DeleteWebhook)
+	r.Get("/api/webhooks/{id}/deliveries", h.ListWebhookDeliveries)
+}
+
+// CreateWebhookRequest is the endpoint to notify and the events it receives
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+}
+
+// CreateWebhookResponse is the only response that includes the signing
+// secret
+type CreateWebhookResponse struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+// ListWebhooksResponse lists a user's webhooks, without their secrets
+type ListWebhooksResponse struct {
+	Webhooks []*models.Webhook `json:"webhooks"`
+}
+
+// ListWebhookDeliveriesResponse lists a webhook's recent deliveries
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []*models.WebhookDelivery `json:"deliveries"`
+}
+
+func (h *WebhookHandlers) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if err := services.ValidateWebhook(req.URL, req.EventTypes); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webhook, err := h.webhooks.Create(r.Context(), *userID, req.URL, req.EventTypes)
+	if err != nil {
+		slog.Error("Failed to create webhook", "user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+
+	slog.Info("Webhook created",
+		"audit_event", "webhook.created",
+		"user_id", userID,
+		"webhook_id", webhook.ID,
+		"event_types", webhook.EventTypes)
+
+	respondJSON(w, http.StatusCreated, CreateWebhookResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+func (h *WebhookHandlers) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	webhooks, err := h.webhooks.List(r.Context(), *userID)
+	if err != nil {
+		slog.Error("Failed to list webhooks", "user_id", userID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list webhooks")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListWebhooksResponse{Webhooks: webhooks})
+}
+
+func (h *WebhookHandlers) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.webhooks.Delete(r.Context(), *userID, webhookID); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			respondNotFound(w)
+			return
+		}
+		slog.Error("Failed to delete webhook", "webhook_id", webhookID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to delete webhook")
+		return
+	}
+
+	slog.Info("Webhook deleted",
+		"audit_event", "webhook.deleted",
+		"user_id", userID,
+		"webhook_id", webhookID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries returns a webhook's most recent deliveries with the
+// receiver's response, so users can see why notifications aren't arriving
+func (h *WebhookHandlers) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondInvalidID(w)
+		return
+	}
+	userID := requestUserID(r)
+	if userID == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	deliveries, err := h.webhooks.Deliveries(r.Context(), *userID, webhookID, webhookDeliveryLimit)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			respondNotFound(w)
+			return
+		}
+		slog.Error("Failed to list webhook deliveries", "webhook_id", webhookID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list webhook deliveries")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListWebhookDeliveriesResponse{Deliveries: deliveries})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestWebhooks_CreateListDelete(t *testing.T) {
+	userID := uuid.New()
+	store := &fakeWebhookStore{}
+	router := chi.NewRouter()
+	NewWebhookHandlers(services.NewWebhookService(store, &fakeBlueprintStore{}, &fakeProjectStore{})).Routes(router)
+
+	body := `{"url": " https://example.com/hooks ", "event_types": ["bid.created", "analysis.completed", "bid.created"]}`
+	rec := serveAsUser(router, userID, http.MethodPost, "/api/webhooks", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body %s; want 201", rec.Code, rec.Body.String())
+	}
+	var created CreateWebhookResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Secret == "" || created.URL != "https://example.com/hooks" ||
+		strings.Join(created.EventTypes, ",") != "analysis.completed,bid.created" {
+		t.Errorf("created = %+v, want the trimmed URL, distinct events and a secret", created.Webhook)
+	}
+
+	// The secret is only shown on creation
+	rec = serveAsUser(router, userID, http.MethodGet, "/api/webhooks", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), created.Secret) {
+		t.Fatalf("list: status = %d, body %s; want the webhook without its secret", rec.Code, rec.Body.String())
+	}
+	var listed ListWebhooksResponse
+	if err := json.NewDecoder(rec.Body).Decode(&listed); err != nil || len(listed.Webhooks) != 1 {
+		t.Fatalf("list = %+v (%v), want the one webhook", listed, err)
+	}
+
+	invalid := map[string]string{
+		"missing url":    `{"event_types": ["bid.created"]}`,
+		"not http":       `{"url": "ftp://example.com", "event_types": ["bid.created"]}`,
+		"no events":      `{"url": "https://example.com/hooks"}`,
+		"unknown event":  `{"url": "https://example.com/hooks", "event_types": ["bid.deleted"]}`,
+		"malformed body": `{"url":`,
+	}
+	for name, body := range invalid {
+		if rec := serveAsUser(router, userID, http.MethodPost, "/api/webhooks", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, rec.Code)
+		}
+	}
+
+	target := "/api/webhooks/" + created.ID.String()
+	store.deliveries = []*models.WebhookDelivery{{ID: uuid.New(), WebhookID: created.ID, EventType: models.WebhookEventBidCreated, Status: models.WebhookDeliveryFailed}}
+	rec = serveAsUser(router, userID, http.MethodGet, target+"/deliveries", "")
+	var deliveries ListWebhookDeliveriesResponse
+	if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&deliveries) != nil || len(deliveries.Deliveries) != 1 {
+		t.Fatalf("deliveries: status = %d, want the recorded delivery", rec.Code)
+	}
+
+	// Another user can neither see the deliveries nor delete the webhook
+	otherUser := uuid.New()
+	if rec := serveAsUser(router, otherUser, http.MethodGet, target+"/deliveries", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's deliveries: status = %d, want 404", rec.Code)
+	}
+	if rec := serveAsUser(router, otherUser, http.MethodDelete, target, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's delete: status = %d, want 404", rec.Code)
+	}
+
+	if rec := serveAsUser(router, userID, http.MethodDelete, target, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status = %d, want 204", rec.Code)
+	}
+	if len(store.webhooks) != 0 {
+		t.Errorf("%d webhooks left after delete, want none", len(store.webhooks))
+	}
+}
@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/middleware"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/serviceauth"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// newSignedAIServer stands in for the AI service: it verifies request
+// signatures against secrets and answers analyses with the stub provider
+func newSignedAIServer(t *testing.T, secrets ...string) *httptest.Server {
+	t.Helper()
+	stub := services.NewStubAIProvider(0, 0)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/analyze", func(w http.ResponseWriter, r *http.Request) {
+		var req services.AnalyzeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		analysis, model, err := stub.AnalyzeBlueprint(r.Context(), req.BlueprintID, req.S3Key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(services.AnalyzeResponse{Success: true, Data: json.RawMessage(analysis), Model: model})
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	verifier := serviceauth.NewVerifier(serviceauth.DefaultMaxSkew, secrets...)
+	server := httptest.NewServer(middleware.ServiceSignature(verifier)(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newSignedAIService(url, secret string) *services.AIService {
+	return services.NewAIService(&config.Config{AI: config.AIConfig{
+		ServiceURL:    url,
+		Timeout:       5 * time.Second,
+		ServiceSecret: secret,
+	}})
+}
+
+// TestSignedAIRoundTrip sends analyses through the HTTP AI client to a
+// signature-checking server backed by the stub provider
+func TestSignedAIRoundTrip(t *testing.T) {
+	server := newSignedAIServer(t, "current-secret", "previous-secret")
+	blueprintID := uuid.New()
+
+	analysis, model, err := newSignedAIService(server.URL, "current-secret").AnalyzeBlueprint(t.Context(), blueprintID, "projects/p/blueprints/b/A-101.pdf")
+	require.NoError(t, err)
+	require.NotNil(t, model)
+	var parsed struct {
+		BlueprintID string `json:"blueprint_id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(analysis), &parsed))
+	assert.Equal(t, blueprintID.String(), parsed.BlueprintID)
+
+	// Bodyless requests are signed too
+	require.NoError(t, newSignedAIService(server.URL, "current-secret").Health(t.Context()))
+
+	// A backend still on the previous secret keeps working during a rotation
+	_, _, err = newSignedAIService(server.URL, "previous-secret").AnalyzeBlueprint(t.Context(), blueprintID, "a.pdf")
+	require.NoError(t, err)
+
+	// Unsigned and wrongly signed requests are rejected
+	_, _, err = newSignedAIService(server.URL, "").AnalyzeBlueprint(t.Context(), blueprintID, "a.pdf")
+	assert.ErrorContains(t, err, "status 401")
+	_, _, err = newSignedAIService(server.URL, "wrong-secret").AnalyzeBlueprint(t.Context(), blueprintID, "a.pdf")
+	assert.ErrorContains(t, err, "status 401")
+}
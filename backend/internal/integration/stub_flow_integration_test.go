@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// TestStubProviderWorkflow runs analysis, takeoff, pricing and bid generation
+// against the stub AI provider with no external services
+func TestStubProviderWorkflow(t *testing.T) {
+	ctx := context.Background()
+	provider := services.NewStubAIProvider(0, 0)
+
+	projectID := uuid.New()
+	blueprintID := uuid.New()
+	s3Key := "projects/" + projectID.String() + "/blueprints/" + blueprintID.String() + "/A-101.pdf"
+
+	// Worker step: analyze the uploaded blueprint
+	analysisJSON, modelInfo, err := provider.AnalyzeBlueprint(ctx, blueprintID, s3Key)
+	require.NoError(t, err)
+	require.NotNil(t, modelInfo)
+
+	takeoffService := services.NewTakeoffService()
+	analysis, err := takeoffService.ParseAnalysisData(analysisJSON)
+	require.NoError(t, err)
+	assert.Equal(t, blueprintID.String(), analysis.BlueprintID)
+
+	summary, err := takeoffService.CalculateTakeoffSummary(analysis)
+	require.NoError(t, err)
+	assert.Greater(t, summary.TotalArea, 0.0)
+
+	// Bid step: price the takeoff and generate a bid the way GenerateBid does
+	pricingService := services.NewPricingService()
+	takeoff, parsed, err := pricingService.ParseTakeoffData(analysisJSON)
+	require.NoError(t, err)
+	pricing, err := pricingService.GeneratePricingSummary(takeoff, parsed, pricingService.GetDefaultPricingConfig())
+	require.NoError(t, err)
+	require.NotEmpty(t, pricing.LineItems)
+
+	bidRequest := map[string]interface{}{
+		"project_id":        projectID.String(),
+		"blueprint_id":      blueprintID.String(),
+		"takeoff_data":      parsed,
+		"markup_percentage": 20.0,
+	}
+	bidJSON, _, err := provider.GenerateBid(ctx, bidRequest)
+	require.NoError(t, err)
+
+	var bid models.GenerateBidResponse
+	require.NoError(t, json.Unmarshal([]byte(bidJSON), &bid))
+	assert.Equal(t, projectID.String(), bid.ProjectID)
+	assert.NotEmpty(t, bid.LineItems)
+	assert.InDelta(t, bid.Subtotal*1.2, bid.TotalPrice, 0.01)
+
+	// The same inputs always produce the same bid
+	again, _, err := provider.GenerateBid(ctx, bidRequest)
+	require.NoError(t, err)
+	assert.JSONEq(t, bidJSON, again)
+
+	// A preview of the same inputs, priced without the AI service, matches
+	// the generated bid's line items and totals
+	preview := services.PricedBidResponse(pricing, 20.0)
+	assert.Equal(t, bid.LineItems, preview.LineItems)
+	assert.Equal(t, bid.LaborCost, preview.LaborCost)
+	assert.Equal(t, bid.MaterialCost, preview.MaterialCost)
+	assert.Equal(t, bid.Subtotal, preview.Subtotal)
+	assert.Equal(t, bid.MarkupAmount, preview.MarkupAmount)
+	assert.Equal(t, bid.TotalPrice, preview.TotalPrice)
+}
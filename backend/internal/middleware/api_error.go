@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Generic error codes for middleware rejections without a more specific
+// code. Handlers report the same failures with the same codes.
+const (
+	CodeBadRequest      = "BAD_REQUEST"
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeRateLimited     = "RATE_LIMITED"
+	CodeInternalError   = "INTERNAL_ERROR"
+)
+
+// errorBody is an error response in the shape handlers write. Error repeats
+// the message for clients that predate codes.
+type errorBody struct {
+	Error         string `json:"error"`
+	Code          string `json:"code"`
+	Message       string `json:"message"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// writeError writes an error response with code and message, tagged with
+// the request's correlation ID
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	correlationID, _ := r.Context().Value(ContextKeyCorrelationID).(string)
+	if correlationID == "" {
+		correlationID = w.Header().Get(CorrelationIDHeader)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorBody{Error: message, Code: code, Message: message, CorrelationID: correlationID})
+}
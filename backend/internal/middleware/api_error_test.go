@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestMiddlewareErrorsCarryCodeAndCorrelationID(t *testing.T) {
+	authService := services.NewAuthService("test-secret", time.Hour)
+	userToken, err := authService.GenerateToken(uuid.New().String(), "jane@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	panics := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		auth    string
+		status  int
+		code    string
+	}{
+		{"missing authorization", Auth(authService, nil)(ok), "", http.StatusUnauthorized, CodeUnauthenticated},
+		{"malformed authorization", Auth(authService, nil)(ok), "Token abc", http.StatusUnauthorized, CodeUnauthenticated},
+		{"invalid token", Auth(authService, nil)(ok), "Bearer not-a-token", http.StatusUnauthorized, CodeUnauthenticated},
+		{"missing role", Auth(authService, nil)(RequireRole(models.UserRoleAdmin)(ok)), "Bearer " + userToken, http.StatusForbidden, CodeRoleRequired},
+		{"panic", Recovery(panics), "", http.StatusInternalServerError, CodeInternalError},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/projects", nil)
+		req.Header.Set(CorrelationIDHeader, "corr-123")
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		CorrelationID(tt.handler).ServeHTTP(w, req)
+
+		var body map[string]string
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: failed to decode body: %v", tt.name, err)
+		}
+		if w.Code != tt.status || body["code"] != tt.code || body["correlation_id"] != "corr-123" || body["error"] == "" || body["error"] != body["message"] {
+			t.Errorf("%s: %d %v, want %d with code %s and correlation ID corr-123", tt.name, w.Code, body, tt.status, tt.code)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("%s: Content-Type = %q", tt.name, contentType)
+		}
+	}
+}
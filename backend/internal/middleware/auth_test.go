@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+type fakeSuspensions struct {
+	suspended map[uuid.UUID]bool
+	revokedAt map[uuid.UUID]time.Time
+}
+
+func (f *fakeSuspensions) GetAccountStatus(ctx context.Context, userID uuid.UUID) (models.AccountStatus, error) {
+	status := models.AccountStatus{Suspended: f.suspended[userID]}
+	if revokedAt, ok := f.revokedAt[userID]; ok {
+		status.TokensRevokedAt = &revokedAt
+	}
+	return status, nil
+}
+
+func TestAuth_RejectsUserSuspendedMidSession(t *testing.T) {
+	authService := services.NewAuthService("test-secret", time.Hour)
+	userID := uuid.New()
+	token, err := authService.GenerateToken(userID.String(), "jane@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	suspensions := &fakeSuspensions{suspended: map[uuid.UUID]bool{}}
+	handler := Auth(authService, suspensions)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/projects", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 before suspension, got %d", w.Code)
+	}
+
+	// The same still-valid token is rejected once the account is suspended
+	suspensions.suspended[userID] = true
+	w := request()
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 after suspension, got %d", w.Code)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body["code"] != CodeAccountSuspended {
+		t.Errorf("expected code %s, got %v", CodeAccountSuspended, body)
+	}
+
+	suspensions.suspended[userID] = false
+	if w := request(); w.Code != http.StatusOK {
+		t.Errorf("expected 200 after unsuspension, got %d", w.Code)
+	}
+}
+
+func TestAuth_RejectsTokensIssuedBeforeRevocation(t *testing.T) {
+	authService := services.NewAuthService("test-secret", time.Hour)
+	userID := uuid.New()
+	token, err := authService.GenerateToken(userID.String(), "jane@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	accounts := &fakeSuspensions{revokedAt: map[uuid.UUID]time.Time{}}
+	handler := Auth(authService, accounts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(token string) int {
+		req := httptest.NewRequest("GET", "/api/projects", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A password change two seconds after the token was issued revokes it
+	accounts.revokedAt[userID] = time.Now().Add(2 * time.Second)
+	if code := request(token); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a revoked token, got %d", code)
+	}
+
+	// A token issued in the same second as the revocation is kept
+	accounts.revokedAt[userID] = time.Now()
+	fresh, err := authService.GenerateToken(userID.String(), "jane@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if code := request(fresh); code != http.StatusOK {
+		t.Errorf("expected 200 for a token issued after the revocation, got %d", code)
+	}
+}
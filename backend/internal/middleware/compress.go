@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultCompressMinSize is the smallest response body worth compressing
+const DefaultCompressMinSize = 1024
+
+// compressibleTypes are the content types compressed; anything else (PDFs,
+// ZIP-based spreadsheets, images) is usually compressed already
+var compressibleTypes = []string{
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"text/",
+	"image/svg+xml",
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// Compress gzips responses for clients that accept it. Bodies smaller than
+// minSize, content types that are not compressible and responses that
+// already set Content-Encoding are sent unchanged. Brotli is not offered as
+// the standard library has no encoder.
+func Compress(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+			cw := &compressWriter{ResponseWriter: w, minSize: minSize, statusCode: http.StatusOK}
+			defer cw.Close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressWriter buffers the start of a response until it can decide
+// whether to compress it: once minSize bytes are written, or when the
+// handler finishes or flushes
+type compressWriter struct {
+	http.ResponseWriter
+	minSize     int
+	statusCode  int
+	wroteHeader bool // WriteHeader was called by the handler
+	decided     bool
+	gz          *gzip.Writer
+	buf         []byte
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.statusCode = code
+
+	// Informational and bodiless responses go straight through
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.decided = true
+		cw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		if !cw.shouldCompress() {
+			cw.start(false)
+		} else if len(cw.buf)+len(p) < cw.minSize {
+			cw.buf = append(cw.buf, p...)
+			return len(p), nil
+		} else {
+			cw.start(true)
+		}
+	}
+
+	if cw.gz != nil {
+		return cw.gz.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// shouldCompress checks the headers the handler has set so far
+func (cw *compressWriter) shouldCompress() bool {
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	if contentType == "" {
+		return false
+	}
+	for _, compressible := range compressibleTypes {
+		if strings.HasPrefix(contentType, compressible) {
+			return true
+		}
+	}
+	return false
+}
+
+// start writes the status line and any buffered bytes, compressed or not
+func (cw *compressWriter) start(compress bool) {
+	cw.decided = true
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", "gzip")
+		// The handler's length describes the uncompressed body
+		header.Del("Content-Length")
+		gz := gzipWriters.Get().(*gzip.Writer)
+		gz.Reset(cw.ResponseWriter)
+		cw.gz = gz
+	}
+	cw.ResponseWriter.WriteHeader(cw.statusCode)
+
+	if len(cw.buf) > 0 {
+		buffered := cw.buf
+		cw.buf = nil
+		if cw.gz != nil {
+			cw.gz.Write(buffered)
+		} else {
+			cw.ResponseWriter.Write(buffered)
+		}
+	}
+}
+
+// Close sends a response that stayed under minSize uncompressed and
+// finishes the gzip stream
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if !cw.wroteHeader && len(cw.buf) == 0 {
+			// Nothing was written; let net/http send its default response
+			cw.decided = true
+			return nil
+		}
+		cw.start(false)
+	}
+	if cw.gz == nil {
+		return nil
+	}
+	err := cw.gz.Close()
+	gzipWriters.Put(cw.gz)
+	cw.gz = nil
+	return err
+}
+
+// Flush decides on compression with what has been written so far, so
+// streamed responses are not held back
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.start(cw.shouldCompress())
+	}
+	if cw.gz != nil {
+		cw.gz.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
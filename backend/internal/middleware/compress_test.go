@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// largeAnalysisJSON builds an analysis payload well over the compression
+// threshold, shaped like the repetitive results the API returns
+func largeAnalysisJSON(t *testing.T) []byte {
+	t.Helper()
+	roomType := "office"
+	result := models.AnalysisResult{}
+	for i := 0; i < 200; i++ {
+		result.Rooms = append(result.Rooms, models.Room{
+			Name:       fmt.Sprintf("Room %d", i),
+			Dimensions: "12' x 14'",
+			Area:       168,
+			RoomType:   &roomType,
+		})
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal analysis: %v", err)
+	}
+	return data
+}
+
+func serveCompressed(handler http.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/blueprints/1/analysis", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	Logger(Compress(DefaultCompressMinSize)(handler)).ServeHTTP(w, req)
+	return w
+}
+
+func TestCompress_GzipsLargeJSON(t *testing.T) {
+	body := largeAnalysisJSON(t)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.WriteHeader(http.StatusOK)
+		// Written in pieces, as json.Encoder may
+		w.Write(body[:100])
+		w.Write(body[100:])
+	}
+
+	w := serveCompressed(handler, "br;q=1.0, gzip;q=0.8")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if got := w.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content-Length = %q, want it dropped for the compressed body", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", got)
+	}
+	if w.Body.Len() >= len(body) {
+		t.Errorf("compressed body is %d bytes, original %d", w.Body.Len(), len(body))
+	}
+
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to decompress: %v", err)
+	}
+	if !bytes.Equal(decompressed, body) {
+		t.Error("decompressed body differs from the original")
+	}
+}
+
+func TestCompress_LeavesResponsesUnchanged(t *testing.T) {
+	large := largeAnalysisJSON(t)
+	pdf := append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("0"), 4096)...)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		body           []byte
+	}{
+		{"small JSON", "gzip", "application/json", []byte(`{"status":"ok"}`)},
+		{"PDF download", "gzip", "application/pdf", pdf},
+		{"no Accept-Encoding", "", "application/json", large},
+		{"gzip refused", "gzip;q=0, identity", "application/json", large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Set("Content-Length", fmt.Sprint(len(tt.body)))
+				w.Write(tt.body)
+			}, tt.acceptEncoding)
+
+			if got := w.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("Content-Encoding = %q, want none", got)
+			}
+			if got := w.Header().Get("Content-Length"); got != fmt.Sprint(len(tt.body)) {
+				t.Errorf("Content-Length = %q, want %d", got, len(tt.body))
+			}
+			if !bytes.Equal(w.Body.Bytes(), tt.body) {
+				t.Error("body was modified")
+			}
+		})
+	}
+}
+
+func TestCompress_KeepsStatusOfSmallErrors(t *testing.T) {
+	w := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"Not found"}`))
+	}, "gzip")
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Not found") {
+		t.Errorf("body = %q, want the error passed through", w.Body.String())
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                    false,
+		"gzip":                true,
+		"GZIP, deflate":       true,
+		"br, gzip;q=0.5":      true,
+		"*":                   true,
+		"gzip;q=0":            false,
+		"deflate, identity":   false,
+		"br;q=1, gzip; q=0.0": false,
+	}
+	for header, want := range tests {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -7,39 +7,79 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/errreport"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
 )
 
+// responseWriter captures the status code and body size for the Logger. It
+// may wrap another middleware's writer, so it passes Flush through and
+// exposes Unwrap for http.ResponseController.
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode   int
+	wroteHeader  bool
+	bytesWritten int64
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	// An implicit 200, as net/http sends on the first write
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytesWritten += int64(n)
+	return n, err
+}
+
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 type contextKey string
 
+// CodeAccountSuspended is returned with 403 for requests from suspended accounts
+const CodeAccountSuspended = "ACCOUNT_SUSPENDED"
+
 const (
 	ContextKeyUserID        contextKey = "user_id"
 	ContextKeyEmail         contextKey = "email"
+	ContextKeyRole          contextKey = "role"
 	ContextKeyCorrelationID contextKey = "correlation_id"
+	// Set for requests authenticated with an API key
+	ContextKeyAPIKeyID contextKey = "api_key_id"
+	ContextKeyReadOnly contextKey = "read_only"
 )
 
+// CorrelationIDHeader carries the correlation ID on requests and responses
+const CorrelationIDHeader = "X-Correlation-ID"
+
 // CorrelationID middleware adds a correlation ID to each request
 func CorrelationID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check for existing correlation ID in header
-		correlationID := r.Header.Get("X-Correlation-ID")
+		correlationID := r.Header.Get(CorrelationIDHeader)
 		if correlationID == "" {
 			correlationID = uuid.New().String()
 		}
 
 		// Add correlation ID to response header
-		w.Header().Set("X-Correlation-ID", correlationID)
+		w.Header().Set(CorrelationIDHeader, correlationID)
 
 		// Add correlation ID to context
 		ctx := context.WithValue(r.Context(), ContextKeyCorrelationID, correlationID)
@@ -75,6 +115,7 @@ func Logger(next http.Handler) http.Handler {
 			"path", r.URL.Path,
 			"status", wrapped.statusCode,
 			"duration_ms", duration.Milliseconds(),
+			"bytes", wrapped.bytesWritten,
 			"remote_addr", r.RemoteAddr,
 			"correlation_id", correlationID,
 		)
@@ -129,8 +170,8 @@ func CORSWithConfig(allowedOrigins []string) func(http.Handler) http.Handler {
 			}
 
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Correlation-ID")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Correlation-ID, X-RateLimit-Limit, X-RateLimit-Remaining")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Correlation-ID, API-Version")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Correlation-ID, X-RateLimit-Limit, X-RateLimit-Remaining, API-Version, Deprecation, Sunset, Link")
 
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusOK)
@@ -142,17 +183,31 @@ func CORSWithConfig(allowedOrigins []string) func(http.Handler) http.Handler {
 	}
 }
 
+// Recovery turns a panic into a 500 and reports it to Sentry. Each request
+// gets its own Sentry hub, so the correlation ID, method, user and route
+// tagged on one request's events never leak onto another's.
 func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Get correlation ID from context
+		correlationID := ""
+		if val := r.Context().Value(ContextKeyCorrelationID); val != nil {
+			if id, ok := val.(string); ok {
+				correlationID = id
+			}
+		}
+
+		ctx := errreport.WithRequestHub(r.Context(), r)
+		errreport.SetTag(ctx, errreport.TagCorrelationID, correlationID)
+		errreport.SetTag(ctx, errreport.TagMethod, r.Method)
+		r = r.WithContext(ctx)
+
 		defer func() {
 			if err := recover(); err != nil {
-				// Get correlation ID from context
-				correlationID := ""
-				if val := r.Context().Value(ContextKeyCorrelationID); val != nil {
-					if id, ok := val.(string); ok {
-						correlationID = id
-					}
+				// The route is only known once chi has matched it
+				if routeContext := chi.RouteContext(ctx); routeContext != nil {
+					errreport.SetTag(ctx, errreport.TagRoute, routeContext.RoutePattern())
 				}
+				errreport.CapturePanic(ctx, err)
 
 				slog.Error("Panic recovered",
 					"error", err,
@@ -160,8 +215,7 @@ func Recovery(next http.Handler) http.Handler {
 					"method", r.Method,
 					"correlation_id", correlationID,
 				)
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(`{"error":"Internal server error"}`))
+				writeError(w, r, http.StatusInternalServerError, CodeInternalError, "Internal server error")
 			}
 		}()
 
@@ -169,20 +223,82 @@ func Recovery(next http.Handler) http.Handler {
 	})
 }
 
-// RequestBodyLimit limits the size of request bodies
+// CodeRequestTooLarge is returned with 413 for bodies over the request size limit
+const CodeRequestTooLarge = "REQUEST_TOO_LARGE"
+
+// RequestBodyLimit limits the size of request bodies. A body declaring a
+// larger Content-Length is refused with 413 before it is read; one that
+// turns out larger fails to decode in the handler. A non-positive limit
+// disables it.
 func RequestBodyLimit(maxBytes int64) func(http.Handler) http.Handler {
+	if maxBytes <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				correlationID, _ := r.Context().Value(ContextKeyCorrelationID).(string)
+				slog.Warn("Rejected request body over size limit",
+					"content_length", r.ContentLength,
+					"max_bytes", maxBytes,
+					"path", r.URL.Path,
+					"correlation_id", correlationID)
+				writeError(w, r, http.StatusRequestEntityTooLarge, CodeRequestTooLarge, "Request body too large")
+				return
+			}
+
 			// Limit request body size
 			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
-			
+
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-// Auth middleware validates JWT tokens and adds user info to context
-func Auth(authService *services.AuthService) func(http.Handler) http.Handler {
+// AccountChecker reports whether a user account is suspended and when its
+// tokens were last revoked
+type AccountChecker interface {
+	GetAccountStatus(ctx context.Context, id uuid.UUID) (models.AccountStatus, error)
+}
+
+// APIKeyAuthenticator resolves an API key to its record, failing for unknown,
+// revoked and expired keys
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*models.APIKey, error)
+}
+
+// CodeAPIKeyReadOnly is returned with 403 when a read-only API key is used
+// for a request that would change data
+const CodeAPIKeyReadOnly = "API_KEY_READ_ONLY"
+
+// IsReadOnly reports whether the request was authenticated with a read-only
+// API key
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(ContextKeyReadOnly).(bool)
+	return readOnly
+}
+
+// isSafeMethod reports whether an HTTP method only reads
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// Auth middleware validates JWT tokens and adds user info to context. When
+// accounts is non-nil the account is checked on every request, so a
+// suspension or password change takes effect mid-session without waiting for
+// the token to expire.
+func Auth(authService *services.AuthService, accounts AccountChecker) func(http.Handler) http.Handler {
+	return AuthWithAPIKeys(authService, accounts, nil)
+}
+
+// AuthWithAPIKeys is Auth that also accepts "Authorization: ApiKey <key>"
+// when apiKeys is non-nil. Key requests act as the key's owner with the
+// read-only flag in context, and are refused with 403 API_KEY_READ_ONLY
+// unless their method only reads.
+func AuthWithAPIKeys(authService *services.AuthService, accounts AccountChecker, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get correlation ID from context
@@ -197,19 +313,65 @@ func Auth(authService *services.AuthService) func(http.Handler) http.Handler {
 				slog.Warn("Missing authorization header",
 					"path", r.URL.Path,
 					"correlation_id", correlationID)
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write([]byte(`{"error":"Missing authorization header"}`))
+				writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Missing authorization header")
 				return
 			}
 
-			// Extract bearer token
+			// Extract bearer token or API key
 			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
+			if len(parts) != 2 || !(parts[0] == "Bearer" || (parts[0] == "ApiKey" && apiKeys != nil)) {
 				slog.Warn("Invalid authorization header format",
 					"path", r.URL.Path,
 					"correlation_id", correlationID)
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write([]byte(`{"error":"Invalid authorization header format"}`))
+				writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Invalid authorization header format")
+				return
+			}
+
+			if parts[0] == "ApiKey" {
+				key, err := apiKeys.Authenticate(r.Context(), parts[1])
+				if err != nil {
+					slog.Warn("Invalid API key",
+						"error", err,
+						"path", r.URL.Path,
+						"correlation_id", correlationID)
+					writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Invalid or expired API key")
+					return
+				}
+				if accounts != nil {
+					status, err := accounts.GetAccountStatus(r.Context(), key.UserID)
+					if err != nil {
+						slog.Error("Failed to check account suspension",
+							"error", err,
+							"user_id", key.UserID,
+							"correlation_id", correlationID)
+						writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Invalid or expired API key")
+						return
+					}
+					if status.Suspended {
+						slog.Warn("Rejected API key of suspended account",
+							"api_key_id", key.ID,
+							"user_id", key.UserID,
+							"path", r.URL.Path,
+							"correlation_id", correlationID)
+						writeError(w, r, http.StatusForbidden, CodeAccountSuspended, "Account suspended")
+						return
+					}
+				}
+				if !isSafeMethod(r.Method) {
+					slog.Warn("Rejected write with read-only API key",
+						"api_key_id", key.ID,
+						"method", r.Method,
+						"path", r.URL.Path,
+						"correlation_id", correlationID)
+					writeError(w, r, http.StatusForbidden, CodeAPIKeyReadOnly, "API keys are read-only")
+					return
+				}
+
+				errreport.SetUser(r.Context(), key.UserID.String())
+				ctx := context.WithValue(r.Context(), ContextKeyUserID, key.UserID.String())
+				ctx = context.WithValue(ctx, ContextKeyAPIKeyID, key.ID.String())
+				ctx = context.WithValue(ctx, ContextKeyReadOnly, true)
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 
@@ -222,14 +384,50 @@ func Auth(authService *services.AuthService) func(http.Handler) http.Handler {
 					"error", err,
 					"path", r.URL.Path,
 					"correlation_id", correlationID)
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write([]byte(`{"error":"Invalid or expired token"}`))
+				writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Invalid or expired token")
 				return
 			}
 
+			if accounts != nil {
+				userID, err := uuid.Parse(claims.UserID)
+				if err != nil {
+					writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Invalid or expired token")
+					return
+				}
+				status, err := accounts.GetAccountStatus(r.Context(), userID)
+				if err != nil {
+					slog.Error("Failed to check account suspension",
+						"error", err,
+						"user_id", claims.UserID,
+						"correlation_id", correlationID)
+					writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Invalid or expired token")
+					return
+				}
+				if status.Suspended {
+					slog.Warn("Rejected request from suspended account",
+						"user_id", claims.UserID,
+						"path", r.URL.Path,
+						"correlation_id", correlationID)
+					writeError(w, r, http.StatusForbidden, CodeAccountSuspended, "Account suspended")
+					return
+				}
+				// Token times have whole-second precision, so a token issued
+				// in the second of the revocation stays valid
+				if status.TokensRevokedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Before(status.TokensRevokedAt.Truncate(time.Second))) {
+					slog.Warn("Rejected revoked token",
+						"user_id", claims.UserID,
+						"path", r.URL.Path,
+						"correlation_id", correlationID)
+					writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Invalid or expired token")
+					return
+				}
+			}
+
 			// Add user info to context
+			errreport.SetUser(r.Context(), claims.UserID)
 			ctx := context.WithValue(r.Context(), ContextKeyUserID, claims.UserID)
 			ctx = context.WithValue(ctx, ContextKeyEmail, claims.Email)
+			ctx = context.WithValue(ctx, ContextKeyRole, claims.Role)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -159,7 +159,10 @@ type RateLimitConfig struct {
 	Enabled               bool
 }
 
-// RateLimit creates a rate limiting middleware
+// RateLimit creates a rate limiting middleware that limits by IP and, for
+// authenticated requests, by user. It only sees the user when mounted after
+// Auth; the server instead splits the two with IPRateLimit and UserRateLimit
+// so anonymous requests are limited before authentication runs.
 func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 	if !config.Enabled {
 		// Return a no-op middleware if rate limiting is disabled
@@ -181,7 +184,7 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 			}
 
 			// Extract client IP
-			clientIP := getClientIP(r)
+			clientIP := ClientIP(r)
 
 			// Check IP-based rate limit
 			ipBucket := limiter.getIPBucket(clientIP)
@@ -194,8 +197,7 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(config.IPRequestsPerMinute))
 				w.Header().Set("X-RateLimit-Remaining", "0")
 				w.Header().Set("Retry-After", "60")
-				w.WriteHeader(http.StatusTooManyRequests)
-				w.Write([]byte(`{"error":"Rate limit exceeded. Please try again later."}`))
+				writeError(w, r, http.StatusTooManyRequests, CodeRateLimited, "Rate limit exceeded. Please try again later.")
 				return
 			}
 
@@ -219,8 +221,7 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 					w.Header().Set("X-RateLimit-Limit", strconv.Itoa(config.UserRequestsPerMinute))
 					w.Header().Set("X-RateLimit-Remaining", "0")
 					w.Header().Set("Retry-After", "60")
-					w.WriteHeader(http.StatusTooManyRequests)
-					w.Write([]byte(`{"error":"Rate limit exceeded. Please try again later."}`))
+					writeError(w, r, http.StatusTooManyRequests, CodeRateLimited, "Rate limit exceeded. Please try again later.")
 					return
 				}
 				// Set user rate limit headers for authenticated requests
@@ -235,8 +236,131 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 	}
 }
 
-// getClientIP extracts the client IP address from the request
-func getClientIP(r *http.Request) string {
+// IPRateLimit limits every request by client IP. It runs before
+// authentication, so it is the only limit anonymous and unauthenticated
+// requests meet. A non-positive limit disables it.
+func IPRateLimit(requestsPerMinute int) func(http.Handler) http.Handler {
+	if requestsPerMinute <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	limiter := NewRateLimiter(requestsPerMinute, 0)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientIP := ClientIP(r)
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(requestsPerMinute))
+			if !limiter.getIPBucket(clientIP).Allow() {
+				correlationID, _ := r.Context().Value(ContextKeyCorrelationID).(string)
+				slog.Warn("Rate limit exceeded for IP",
+					"ip", clientIP,
+					"path", r.URL.Path,
+					"correlation_id", correlationID)
+
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("Retry-After", "60")
+				writeError(w, r, http.StatusTooManyRequests, CodeRateLimited, "Rate limit exceeded. Please try again later.")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthRateLimit creates a strict per-IP rate limiter for an auth endpoint.
+// Each call owns its own buckets, so applying it separately to signup and
+// login throttles them independently. A non-positive limit disables it.
+func AuthRateLimit(requestsPerMinute int) func(http.Handler) http.Handler {
+	if requestsPerMinute <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	limiter := NewRateLimiter(requestsPerMinute, 0)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientIP := ClientIP(r)
+
+			if !limiter.getIPBucket(clientIP).Allow() {
+				correlationID := ""
+				if val := r.Context().Value(ContextKeyCorrelationID); val != nil {
+					if id, ok := val.(string); ok {
+						correlationID = id
+					}
+				}
+
+				slog.Warn("Auth rate limit exceeded for IP",
+					"ip", clientIP,
+					"path", r.URL.Path,
+					"correlation_id", correlationID)
+
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(requestsPerMinute))
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("Retry-After", "60")
+				writeError(w, r, http.StatusTooManyRequests, CodeRateLimited, "Too many requests. Please try again later.")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UserRateLimit creates a per-user rate limiter. The server mounts one after
+// Auth for every protected route, and handlers add stricter ones to
+// endpoints that are cheap to call repeatedly. Requests without an
+// authenticated user are limited by IP. A non-positive limit disables it.
+func UserRateLimit(requestsPerMinute int) func(http.Handler) http.Handler {
+	if requestsPerMinute <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	limiter := NewRateLimiter(requestsPerMinute, requestsPerMinute)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := ""
+			if val := r.Context().Value(ContextKeyUserID); val != nil {
+				if id, ok := val.(string); ok {
+					userID = id
+				}
+			}
+
+			var bucket *TokenBucket
+			if userID != "" {
+				bucket = limiter.getUserBucket(userID)
+			} else {
+				bucket = limiter.getIPBucket(ClientIP(r))
+			}
+
+			if !bucket.Allow() {
+				slog.Warn("Endpoint rate limit exceeded",
+					"user_id", userID,
+					"path", r.URL.Path)
+
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(requestsPerMinute))
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("Retry-After", "60")
+				writeError(w, r, http.StatusTooManyRequests, CodeRateLimited, "Too many requests. Please try again later.")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP extracts the client IP address from the request, preferring the
+// addresses proxies forward
+func ClientIP(r *http.Request) string {
 	// Check X-Forwarded-For header (set by proxies)
 	xff := r.Header.Get("X-Forwarded-For")
 	if xff != "" {
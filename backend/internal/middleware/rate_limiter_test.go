@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -192,10 +193,79 @@ func TestGetClientIP(t *testing.T) {
 				req.Header.Set("X-Real-IP", tt.xRealIP)
 			}
 
-			ip := getClientIP(req)
+			ip := ClientIP(req)
 			if ip != tt.expectedIP {
 				t.Errorf("Expected IP %s, got %s", tt.expectedIP, ip)
 			}
 		})
 	}
 }
+
+func TestAuthRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	login := AuthRateLimit(3)(ok)
+	signup := AuthRateLimit(3)(ok)
+
+	send := func(h http.Handler, path string) int {
+		req := httptest.NewRequest("POST", path, nil)
+		req.RemoteAddr = "10.0.0.1:12345"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := send(login, "/auth/login"); code != http.StatusOK {
+			t.Fatalf("Login request %d: expected 200, got %d", i+1, code)
+		}
+	}
+	if code := send(login, "/auth/login"); code != http.StatusTooManyRequests {
+		t.Fatalf("Expected login to be throttled (429), got %d", code)
+	}
+
+	// Signup has its own buckets and is unaffected by login traffic
+	if code := send(signup, "/auth/signup"); code != http.StatusOK {
+		t.Fatalf("Expected signup to be allowed, got %d", code)
+	}
+
+	t.Run("Disabled when limit is zero", func(t *testing.T) {
+		h := AuthRateLimit(0)(ok)
+		for i := 0; i < 10; i++ {
+			if code := send(h, "/auth/login"); code != http.StatusOK {
+				t.Fatalf("Request %d: expected 200, got %d", i+1, code)
+			}
+		}
+	})
+}
+
+func TestUserRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	preview := UserRateLimit(2)(ok)
+
+	send := func(userID string) int {
+		req := httptest.NewRequest("POST", "/projects/1/bids/preview", nil)
+		req.RemoteAddr = "10.0.0.1:12345"
+		req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserID, userID))
+		w := httptest.NewRecorder()
+		preview.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := send("user-a"); code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i+1, code)
+		}
+	}
+	if code := send("user-a"); code != http.StatusTooManyRequests {
+		t.Fatalf("Expected user-a to be throttled (429), got %d", code)
+	}
+
+	// Users behind the same IP have separate buckets
+	if code := send("user-b"); code != http.StatusOK {
+		t.Fatalf("Expected user-b to be allowed, got %d", code)
+	}
+}
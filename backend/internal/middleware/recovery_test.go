@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/errreport"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+// sentryTransportStub keeps events instead of sending them
+type sentryTransportStub struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *sentryTransportStub) Configure(options sentry.ClientOptions)    {}
+func (t *sentryTransportStub) Flush(timeout time.Duration) bool          { return true }
+func (t *sentryTransportStub) FlushWithContext(ctx context.Context) bool { return true }
+func (t *sentryTransportStub) Close()                                    {}
+
+func (t *sentryTransportStub) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *sentryTransportStub) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+func newSentryStub(t *testing.T) *sentryTransportStub {
+	t.Helper()
+	transport := &sentryTransportStub{}
+	if err := errreport.Init(sentry.ClientOptions{Transport: transport}, 1); err != nil {
+		t.Fatalf("errreport.Init() error = %v", err)
+	}
+	t.Cleanup(func() { sentry.CurrentHub().BindClient(nil) })
+	return transport
+}
+
+// panickingRouter mirrors main.go: correlation IDs and recovery wrap the
+// authenticated routes
+func panickingRouter(authService *services.AuthService) http.Handler {
+	r := chi.NewRouter()
+	r.Use(CorrelationID)
+	r.Use(Recovery)
+	r.Group(func(r chi.Router) {
+		r.Use(Auth(authService, nil))
+		r.Post("/projects/{id}/bids", func(w http.ResponseWriter, r *http.Request) {
+			var bids map[string]int
+			bids[chi.URLParam(r, "id")]++
+		})
+	})
+	return r
+}
+
+func TestRecovery_ReportsPanicToSentry(t *testing.T) {
+	transport := newSentryStub(t)
+	authService := services.NewAuthService("test-secret", time.Hour)
+	userID := uuid.New()
+	token, err := authService.GenerateToken(userID.String(), "jane@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/projects/"+uuid.NewString()+"/bids", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Correlation-ID", "req-panic")
+	w := httptest.NewRecorder()
+	panickingRouter(authService).ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("sent %d events, want 1", len(events))
+	}
+	event := events[0]
+	want := map[string]string{
+		errreport.TagCorrelationID: "req-panic",
+		errreport.TagUserID:        userID.String(),
+		errreport.TagRoute:         "/projects/{id}/bids",
+		errreport.TagMethod:        "POST",
+	}
+	for key, value := range want {
+		if event.Tags[key] != value {
+			t.Errorf("tag %s = %q, want %q", key, event.Tags[key], value)
+		}
+	}
+	if event.Level != sentry.LevelFatal {
+		t.Errorf("level = %q, want fatal", event.Level)
+	}
+	if len(event.Exception) == 0 || event.Exception[len(event.Exception)-1].Stacktrace == nil {
+		t.Errorf("expected the panic to carry a stack trace, got %+v", event.Exception)
+	}
+	if event.Request == nil || event.Request.Method != "POST" {
+		t.Errorf("request = %+v, want the POST request attached", event.Request)
+	}
+}
+
+func TestRecovery_ConcurrentRequestsKeepTheirOwnTags(t *testing.T) {
+	transport := newSentryStub(t)
+	authService := services.NewAuthService("test-secret", time.Hour)
+	router := panickingRouter(authService)
+
+	const requests = 20
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			userID := fmt.Sprintf("user-%d", i)
+			token, err := authService.GenerateToken(userID, userID+"@example.com", models.UserRoleUser)
+			if err != nil {
+				t.Errorf("GenerateToken() error = %v", err)
+				return
+			}
+			req := httptest.NewRequest("POST", "/projects/"+uuid.NewString()+"/bids", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("X-Correlation-ID", "req-"+userID)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}(i)
+	}
+	wg.Wait()
+
+	events := transport.Events()
+	if len(events) != requests {
+		t.Fatalf("sent %d events, want %d", len(events), requests)
+	}
+	for _, event := range events {
+		if event.Tags[errreport.TagCorrelationID] != "req-"+event.Tags[errreport.TagUserID] {
+			t.Errorf("correlation %q reported with user %q", event.Tags[errreport.TagCorrelationID], event.Tags[errreport.TagUserID])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// CodeRoleRequired is returned with 403 for requests without the role a route needs
+const CodeRoleRequired = "ROLE_REQUIRED"
+
+// UserRole returns the role from the authenticated user's token claims.
+// Requests authenticated with an API key have none.
+func UserRole(ctx context.Context) models.UserRole {
+	role, _ := ctx.Value(ContextKeyRole).(models.UserRole)
+	return role
+}
+
+// RequireRole rejects requests whose token does not carry role. It runs
+// after Auth, which only accepts tokens issued since the user's role last
+// changed, so the claim is current.
+func RequireRole(role models.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if UserRole(r.Context()) != role {
+				correlationID, _ := r.Context().Value(ContextKeyCorrelationID).(string)
+				userID, _ := r.Context().Value(ContextKeyUserID).(string)
+				slog.Warn("Rejected request without required role",
+					"required_role", role,
+					"user_id", userID,
+					"path", r.URL.Path,
+					"correlation_id", correlationID)
+				writeError(w, r, http.StatusForbidden, CodeRoleRequired, "Insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
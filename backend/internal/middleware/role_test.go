@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/services"
+)
+
+func TestRequireRole(t *testing.T) {
+	authService := services.NewAuthService("test-secret", time.Hour)
+	handler := Auth(authService, nil)(RequireRole(models.UserRoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	request := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/admin/sync-cost-data", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	userToken, err := authService.GenerateToken(uuid.New().String(), "jane@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	w := request(userToken)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a user, got %d", w.Code)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body["code"] != CodeRoleRequired {
+		t.Errorf("expected code %s, got %v", CodeRoleRequired, body)
+	}
+
+	adminToken, err := authService.GenerateToken(uuid.New().String(), "admin@example.com", models.UserRoleAdmin)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if w := request(adminToken); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for an admin, got %d", w.Code)
+	}
+
+	// A user who rewrites their token's role breaks its signature
+	parts := strings.Split(userToken, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	tampered := strings.Replace(string(payload), `"role":"user"`, `"role":"admin"`, 1)
+	if tampered == string(payload) {
+		t.Fatalf("payload %s has no user role to tamper with", payload)
+	}
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(tampered))
+	if w := request(strings.Join(parts, ".")); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a tampered token, got %d", w.Code)
+	}
+}
+
+func TestRequireRole_RejectsAPIKeys(t *testing.T) {
+	handler := RequireRole(models.UserRoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// API key requests carry a user but no role
+	req := httptest.NewRequest("GET", "/api/admin/users", nil)
+	ctx := context.WithValue(req.Context(), ContextKeyUserID, uuid.New().String())
+	ctx = context.WithValue(ctx, ContextKeyReadOnly, true)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req.WithContext(ctx))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for an API key, got %d", w.Code)
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/serviceauth"
+)
+
+// ServiceSignature rejects requests from internal services, such as AI
+// service callbacks, that are unsigned, stale or signed with an unknown
+// secret. The body is read to verify it and restored for the handler.
+func ServiceSignature(verifier *serviceauth.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, CodeBadRequest, "Failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if err := verifier.Verify(r.Header, body); err != nil {
+				correlationID, _ := r.Context().Value(ContextKeyCorrelationID).(string)
+				slog.Warn("Rejected service request",
+					"error", err,
+					"path", r.URL.Path,
+					"correlation_id", correlationID)
+				writeError(w, r, http.StatusUnauthorized, CodeUnauthenticated, "Invalid service signature")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/serviceauth"
+)
+
+func TestServiceSignature(t *testing.T) {
+	body := `{"job_id":"j1","status":"completed"}`
+	var received string
+	handler := ServiceSignature(serviceauth.NewVerifier(serviceauth.DefaultMaxSkew, "secret"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			received = string(data)
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	signed := httptest.NewRequest(http.MethodPost, "/callbacks/analysis", strings.NewReader(body))
+	serviceauth.NewSigner("secret").SignRequest(signed, []byte(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signed)
+	if rec.Code != http.StatusOK || received != body {
+		t.Errorf("signed request: status %d, handler read %q; want 200 with the body intact", rec.Code, received)
+	}
+
+	unsigned := httptest.NewRequest(http.MethodPost, "/callbacks/analysis", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, unsigned)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned request: status %d, want 401", rec.Code)
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// API versions. A version is the shape of every response; an endpoint whose
+// shape changes serves both shapes and branches on RequestedAPIVersion.
+const (
+	APIVersion1 = 1
+	// LatestAPIVersion is the highest version a client may request
+	LatestAPIVersion = APIVersion1
+)
+
+// APIVersionHeader lets a client ask for a version other than the one its
+// path prefix names, so a new shape of one endpoint can ship before the next
+// prefix does
+const APIVersionHeader = "API-Version"
+
+// CodeUnsupportedAPIVersion is returned with 400 for an API-Version header
+// naming a version the server does not serve
+const CodeUnsupportedAPIVersion = "UNSUPPORTED_API_VERSION"
+
+const ContextKeyAPIVersion contextKey = "api_version"
+
+// APIVersion records the version a request was routed under, overridden by
+// an API-Version header, and echoes it in the response
+func APIVersion(version int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested := version
+			if header := r.Header.Get(APIVersionHeader); header != "" {
+				parsed, err := strconv.Atoi(header)
+				if err != nil || parsed < APIVersion1 || parsed > LatestAPIVersion {
+					writeError(w, r, http.StatusBadRequest, CodeUnsupportedAPIVersion, "Unsupported API version")
+					return
+				}
+				requested = parsed
+			}
+
+			w.Header().Set(APIVersionHeader, strconv.Itoa(requested))
+			ctx := context.WithValue(r.Context(), ContextKeyAPIVersion, requested)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequestedAPIVersion returns the API version a request asked for, or
+// version 1 outside the versioned routes
+func RequestedAPIVersion(ctx context.Context) int {
+	if version, ok := ctx.Value(ContextKeyAPIVersion).(int); ok {
+		return version
+	}
+	return APIVersion1
+}
+
+// Deprecated marks responses from a deprecated route with the Deprecation
+// (RFC 9745) and Sunset (RFC 8594) headers and links the route under
+// successorPrefix as its replacement
+func Deprecated(deprecatedAt, sunset time.Time, successorPrefix string) func(http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunsetDate)
+			w.Header().Add("Link", "<"+successorPrefix+r.URL.Path+`>; rel="successor-version"`)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
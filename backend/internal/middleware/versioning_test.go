@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIVersion(t *testing.T) {
+	var seen int
+	handler := APIVersion(APIVersion1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestedAPIVersion(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantHeader string
+	}{
+		{"path version", "", http.StatusOK, "1"},
+		{"requested version", "1", http.StatusOK, "1"},
+		{"unreleased version", "2", http.StatusBadRequest, ""},
+		{"malformed version", "v1", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = 0
+			req := httptest.NewRequest(http.MethodGet, "/v1/projects", nil)
+			if tt.header != "" {
+				req.Header.Set(APIVersionHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get(APIVersionHeader); got != tt.wantHeader {
+				t.Errorf("expected API-Version %q, got %q", tt.wantHeader, got)
+			}
+			if tt.wantStatus == http.StatusOK && seen != APIVersion1 {
+				t.Errorf("expected requested version 1, got %d", seen)
+			}
+		})
+	}
+}
+
+func TestRequestedAPIVersionDefaultsToV1(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	if got := RequestedAPIVersion(req.Context()); got != APIVersion1 {
+		t.Errorf("expected version 1, got %d", got)
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,10 +17,65 @@ type User struct {
 	CompanyPhone *string    `json:"company_phone,omitempty"`
 	CompanyAddress *string  `json:"company_address,omitempty"`
 	LicenseNumber *string   `json:"license_number,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	DefaultInclusions []string `json:"default_inclusions,omitempty"` // Standing inclusions added to every bid
+	DefaultExclusions []string `json:"default_exclusions,omitempty"` // Standing exclusions added to every bid
+	AutoBlueprintRevisions bool `json:"auto_blueprint_revisions"` // Snapshot analyses before they are overwritten
+	QuickBooksItems map[string]string `json:"quickbooks_items,omitempty"` // QuickBooks item name per trade slug, over the defaults
+	QuickBooksDefaultItem *string `json:"quickbooks_default_item,omitempty"` // Item for line items without a mapping
+	TaxSettings  *TaxSettings `json:"tax_settings,omitempty"` // Sales tax charged on estimates and bids; none when nil
+	ImpactThresholds *ImpactThresholds `json:"impact_thresholds,omitempty"` // Revision comparison impact thresholds over the defaults
+	PDFLayout    *PDFLayout `json:"pdf_layout,omitempty"` // Bid document section order; the default when nil
+	UnitSystem   UnitSystem `json:"unit_system"` // Default units for takeoff and pricing output
+	Role         UserRole   `json:"role"`
+	Suspended    bool       `json:"suspended"`
+	SuspendedAt  *Timestamp `json:"suspended_at,omitempty"`
+	CreatedAt    Timestamp  `json:"created_at"`
+	UpdatedAt    Timestamp  `json:"updated_at"`
 }
 
+// AccountStatus is the account state the auth middleware checks on every
+// request
+type AccountStatus struct {
+	Suspended bool
+	// TokensRevokedAt rejects tokens issued before it; set on password change
+	TokensRevokedAt *time.Time
+}
+
+// UserSearchFilter narrows the admin user list. Email matches as a
+// case-insensitive substring.
+type UserSearchFilter struct {
+	Email        *string
+	CreatedAfter *Timestamp
+	Limit        int
+	Offset       int
+}
+
+// UserActivity aggregates what a user has created, for admin review
+type UserActivity struct {
+	Projects       int   `json:"projects"`
+	Bids           int   `json:"bids"`
+	Blueprints     int   `json:"blueprints"`
+	StorageBytes   int64 `json:"storage_bytes"`
+	AnalysisJobs   int   `json:"analysis_jobs"`   // AI blueprint analyses
+	GeneratedBids  int   `json:"generated_bids"`  // Bids with AI generation metadata
+}
+
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
+)
+
+// UnitSystem is the measurement system quantities are displayed in. Takeoff
+// and pricing math is always done in imperial units.
+type UnitSystem string
+
+const (
+	UnitSystemImperial UnitSystem = "imperial"
+	UnitSystemMetric   UnitSystem = "metric"
+)
+
 type ProjectStatus string
 
 const (
@@ -29,14 +85,46 @@ const (
 	ProjectStatusArchived  ProjectStatus = "archived"
 )
 
+// ProjectType is the kind of work a project is, which changes how it is priced
+type ProjectType string
+
+const (
+	ProjectTypeNewConstruction ProjectType = "new_construction"
+	ProjectTypeRenovation      ProjectType = "renovation"
+	ProjectTypeAddition        ProjectType = "addition"
+)
+
 type Project struct {
 	ID          uuid.UUID     `json:"id"`
 	UserID      uuid.UUID     `json:"user_id"`
 	Name        string        `json:"name"`
 	Description *string       `json:"description"`
 	Status      ProjectStatus `json:"status"`
-	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
+	ProjectType ProjectType   `json:"project_type"`
+	Budget      *float64      `json:"budget,omitempty"`
+	Region      *string       `json:"region,omitempty"`
+	ClientName  *string       `json:"client_name,omitempty"`
+	TaxExempt   bool          `json:"tax_exempt"` // Priced without sales tax
+	CreatedAt   Timestamp     `json:"created_at"`
+	UpdatedAt   Timestamp     `json:"updated_at"`
+}
+
+// BudgetState describes how a price compares to a project budget
+type BudgetState string
+
+const (
+	BudgetStateUnder BudgetState = "under"
+	BudgetStateOver  BudgetState = "over"
+)
+
+// BudgetStatus compares a bid or pricing total against the project budget.
+// It is informational only and is never persisted.
+type BudgetStatus struct {
+	Budget  float64     `json:"budget"`
+	Price   float64     `json:"price"`
+	Status  BudgetState `json:"status"`
+	Delta   float64     `json:"delta"`   // Price minus budget; positive when over
+	Percent float64     `json:"percent"` // Delta as a percentage of budget
 }
 
 type UploadStatus string
@@ -70,8 +158,85 @@ type Blueprint struct {
 	Version           int            `json:"version"`
 	ParentBlueprintID *uuid.UUID     `json:"parent_blueprint_id,omitempty"`
 	IsLatest          bool           `json:"is_latest"`
-	CreatedAt         time.Time      `json:"created_at"`
-	UpdatedAt         time.Time      `json:"updated_at"`
+	AnalysisModel     *AIModelInfo   `json:"analysis_model,omitempty"`
+	SheetType         *SheetType     `json:"sheet_type,omitempty"`
+	RoomFinishes      map[string]FloorFinish `json:"room_finishes,omitempty"` // room name -> floor finish
+	TakeoffAdjustments []TakeoffAdjustment   `json:"takeoff_adjustments,omitempty"`
+	ScanResult        *string        `json:"scan_result,omitempty"` // Virus scan outcome, e.g. "clean" or "infected: <signature>"
+	UploadGeneration  int            `json:"upload_generation"` // Incremented each time an upload of the file completes
+	// Read from the file when its upload completes; PageCount is nil when
+	// the file could not be parsed or has no pages, e.g. a CAD drawing
+	PageCount         *int                `json:"page_count"`
+	PageSizes         []BlueprintPageSize `json:"page_sizes,omitempty"`
+	DocumentTitle     *string             `json:"document_title,omitempty"`
+	DocumentCreatedAt *Timestamp          `json:"document_created_at,omitempty"`
+	CreatedAt         Timestamp      `json:"created_at"`
+	UpdatedAt         Timestamp      `json:"updated_at"`
+}
+
+// Units blueprint page sizes are measured in
+const (
+	PageSizeUnitPoints = "pt" // PDF pages, 1/72 inch
+	PageSizeUnitPixels = "px" // Image uploads
+)
+
+// BlueprintPageSize is the size of one page of a blueprint file
+type BlueprintPageSize struct {
+	Page   int     `json:"page"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Unit   string  `json:"unit"`
+}
+
+// SheetType classifies a blueprint sheet by discipline
+type SheetType string
+
+const (
+	SheetTypeArchitectural SheetType = "architectural"
+	SheetTypeElectrical    SheetType = "electrical"
+	SheetTypePlumbing      SheetType = "plumbing"
+	SheetTypeMechanical    SheetType = "mechanical"
+	SheetTypeUnknown       SheetType = "unknown"
+)
+
+// IsValid reports whether t is a recognized sheet type
+func (t SheetType) IsValid() bool {
+	switch t {
+	case SheetTypeArchitectural, SheetTypeElectrical, SheetTypePlumbing, SheetTypeMechanical, SheetTypeUnknown:
+		return true
+	}
+	return false
+}
+
+// FloorFinish identifies the floor covering selected for a room
+type FloorFinish string
+
+const (
+	FloorFinishTile     FloorFinish = "tile"
+	FloorFinishLVP      FloorFinish = "lvp"
+	FloorFinishCarpet   FloorFinish = "carpet"
+	FloorFinishHardwood FloorFinish = "hardwood"
+	FloorFinishNone     FloorFinish = "none"
+)
+
+// FloorFinishes lists the priced finishes in line item order
+var FloorFinishes = []FloorFinish{FloorFinishTile, FloorFinishLVP, FloorFinishCarpet, FloorFinishHardwood}
+
+// IsValid reports whether f is a recognized floor finish
+func (f FloorFinish) IsValid() bool {
+	switch f {
+	case FloorFinishTile, FloorFinishLVP, FloorFinishCarpet, FloorFinishHardwood, FloorFinishNone:
+		return true
+	}
+	return false
+}
+
+// BlueprintTextMatch is a single hit from a full-text search over blueprint OCR text
+type BlueprintTextMatch struct {
+	BlueprintID uuid.UUID `json:"blueprint_id"`
+	Filename    string    `json:"filename"`
+	Rank        float64   `json:"rank"`
+	Snippet     string    `json:"snippet"`
 }
 
 type JobType string
@@ -80,6 +245,11 @@ const (
 	JobTypeTakeoff       JobType = "takeoff"
 	JobTypeEstimate      JobType = "estimate"
 	JobTypeBidGeneration JobType = "bid_generation"
+	// JobTypeProjectDuplicate copies a project's blueprints into a duplicate
+	// project created when the job was queued
+	JobTypeProjectDuplicate JobType = "project_duplicate"
+	// JobTypePDFGeneration renders a bid's PDF and uploads it to storage
+	JobTypePDFGeneration JobType = "pdf_generation"
 )
 
 type JobStatus string
@@ -89,6 +259,9 @@ const (
 	JobStatusProcessing JobStatus = "processing"
 	JobStatusCompleted  JobStatus = "completed"
 	JobStatusFailed     JobStatus = "failed"
+	// JobStatusStale marks a job whose result was discarded because the
+	// blueprint's file was re-uploaded while it ran
+	JobStatusStale      JobStatus = "stale"
 )
 
 type Job struct {
@@ -96,13 +269,41 @@ type Job struct {
 	BlueprintID  uuid.UUID  `json:"blueprint_id"`
 	JobType      JobType    `json:"job_type"`
 	Status       JobStatus  `json:"status"`
-	StartedAt    *time.Time `json:"started_at"`
-	CompletedAt  *time.Time `json:"completed_at"`
+	StartedAt    *Timestamp `json:"started_at"`
+	CompletedAt  *Timestamp `json:"completed_at"`
 	ErrorMessage *string    `json:"error_message"`
 	ResultData   *string    `json:"result_data"` // JSONB stored as string
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	CreatedAt    Timestamp  `json:"created_at"`
+	UpdatedAt    Timestamp  `json:"updated_at"`
 	RetryCount   int        `json:"retry_count"`
+	// Progress is the worker's estimate of completion, 0-100
+	Progress        int     `json:"progress"`
+	ProgressMessage *string `json:"progress_message,omitempty"`
+	// UploadGeneration is the blueprint's upload generation when the job was created
+	UploadGeneration int `json:"upload_generation"`
+	// TargetProjectID is the project a duplication job copies into
+	TargetProjectID *uuid.UUID `json:"target_project_id,omitempty"`
+	// Reanalyze marks a takeoff queued with reanalyze=true; the worker sends
+	// the previous analysis as context and aligns room names with it
+	Reanalyze bool `json:"reanalyze,omitempty"`
+	// BidID is the bid a PDF generation job renders
+	BidID *uuid.UUID `json:"bid_id,omitempty"`
+	// PDFRequest is the PDF options a PDF generation job renders with
+	PDFRequest *BidPDFRequest `json:"pdf_request,omitempty"`
+}
+
+// BidPDFRequest is the optional content requested for a bid PDF when it is
+// generated with the bid
+type BidPDFRequest struct {
+	IncludeBlueprintPages []int `json:"include_blueprint_pages,omitempty"`
+	IncludeEstimateRange  bool  `json:"include_estimate_range,omitempty"`
+	IncludeSignatureBlock bool  `json:"include_signature_block,omitempty"`
+}
+
+// QueueDepth is an approximate count of queued jobs, globally and for one user
+type QueueDepth struct {
+	Total int `json:"total"`
+	User  int `json:"user"`
 }
 
 type BidStatus string
@@ -114,6 +315,36 @@ const (
 	BidStatusRejected BidStatus = "rejected"
 )
 
+// BidSort orders a project's bid list
+type BidSort string
+
+const (
+	BidSortCreatedDesc    BidSort = "created_at_desc"
+	BidSortCreatedAsc     BidSort = "created_at_asc"
+	BidSortFinalPriceAsc  BidSort = "final_price_asc"
+	BidSortFinalPriceDesc BidSort = "final_price_desc"
+)
+
+// IsValid reports whether s is a recognized bid list sort
+func (s BidSort) IsValid() bool {
+	switch s {
+	case BidSortCreatedDesc, BidSortCreatedAsc, BidSortFinalPriceAsc, BidSortFinalPriceDesc:
+		return true
+	}
+	return false
+}
+
+// BidListFilter narrows, orders and pages a project's bids. BlueprintID
+// keeps bids priced from that blueprint, combined bids included.
+type BidListFilter struct {
+	ProjectID   uuid.UUID
+	BlueprintID *uuid.UUID
+	Status      *BidStatus
+	Sort        BidSort
+	Limit       int
+	Offset      int
+}
+
 type Bid struct {
 	ID               uuid.UUID  `json:"id"`
 	ProjectID        uuid.UUID  `json:"project_id"`
@@ -125,14 +356,58 @@ type Bid struct {
 	MarkupPercentage *float64   `json:"markup_percentage"`
 	FinalPrice       *float64   `json:"final_price"`
 	Status           BidStatus  `json:"status"`
+	// StatusChangedAt is when the status last changed, and StatusNote the
+	// note given with that change
+	StatusChangedAt *Timestamp `json:"status_changed_at,omitempty"`
+	StatusNote      *string    `json:"status_note,omitempty"`
 	BidData          *string    `json:"bid_data"` // JSONB stored as string
 	PDFURL           *string    `json:"pdf_url"`
 	PDFS3Key         *string    `json:"pdf_s3_key"`
+	PDFHash          *string    `json:"pdf_hash,omitempty"` // SHA-256 of the inputs the stored PDF was rendered from
 	Version          int        `json:"version"`
 	ParentBidID      *uuid.UUID `json:"parent_bid_id,omitempty"`
 	IsLatest         bool       `json:"is_latest"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
+	GenerationModel  *AIModelInfo `json:"generation_model,omitempty"`
+	// BlueprintIDs are the blueprints whose takeoffs priced the bid; a bid
+	// priced from several is a combined bid
+	BlueprintIDs []uuid.UUID `json:"blueprint_ids"`
+	// CostsByTrade is denormalized from BidData's line items so analytics
+	// can aggregate trade costs without decoding every bid
+	CostsByTrade map[string]float64 `json:"costs_by_trade,omitempty"`
+	CreatedAt        Timestamp  `json:"created_at"`
+	UpdatedAt        Timestamp  `json:"updated_at"`
+
+	// BudgetStatus is computed at response time from the project budget
+	BudgetStatus *BudgetStatus `json:"budget_status,omitempty"`
+
+	// SourceBlueprints describes BlueprintIDs, filled in on GET /bids/{id}
+	SourceBlueprints []BidSourceBlueprint `json:"source_blueprints,omitempty"`
+
+	// Warnings are the generated bid's review warnings, returned only when
+	// the bid is generated
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Timings is the per-phase generation breakdown, returned only on request
+	Timings map[string]int64 `json:"timings,omitempty"`
+
+	// PDFJobID is the job rendering the bid's PDF, returned when it is queued
+	PDFJobID *uuid.UUID `json:"pdf_job_id,omitempty"`
+}
+
+// ObjectDeletion is an S3 object scheduled for deletion by the worker once
+// DeleteAfter has passed, e.g. a bid PDF superseded by a regenerated one
+type ObjectDeletion struct {
+	ID          uuid.UUID `json:"id"`
+	S3Key       string    `json:"s3_key"`
+	DeleteAfter Timestamp `json:"delete_after"`
+	CreatedAt   Timestamp `json:"created_at"`
+}
+
+// AIModelInfo identifies the AI model and prompt that produced an analysis or bid
+type AIModelInfo struct {
+	Name           string `json:"name,omitempty"`
+	Version        string `json:"version,omitempty"`
+	PromptRevision string `json:"prompt_revision,omitempty"`
 }
 
 // Analysis models - match Python AI service response and TypeScript frontend
@@ -142,6 +417,13 @@ type Room struct {
 	Dimensions string  `json:"dimensions"`
 	Area       float64 `json:"area"`
 	RoomType   *string `json:"room_type,omitempty"`
+	// SourceBlueprintID is set on rooms merged from several blueprints
+	SourceBlueprintID *uuid.UUID `json:"source_blueprint_id,omitempty"`
+
+	// Parsed from Dimensions, shorter side first, when the analysis is normalized
+	WidthFeet  *float64 `json:"width_feet,omitempty"`
+	LengthFeet *float64 `json:"length_feet,omitempty"`
+	Normalized bool     `json:"normalized,omitempty"`
 }
 
 type Opening struct {
@@ -149,8 +431,26 @@ type Opening struct {
 	Count       int     `json:"count"`
 	Size        string  `json:"size"`
 	Details     *string `json:"details,omitempty"`
+
+	// Normalized from Size and OpeningType when the takeoff is computed
+	WidthInches    *float64     `json:"width_inches,omitempty"`
+	HeightInches   *float64     `json:"height_inches,omitempty"`
+	Classification OpeningClass `json:"classification,omitempty"`
+	Normalized     bool         `json:"normalized,omitempty"` // Size parsed when the analysis is normalized
 }
 
+// OpeningClass is what an opening is for scheduling and pricing, derived
+// from its type and size
+type OpeningClass string
+
+const (
+	OpeningClassInteriorDoor OpeningClass = "interior_door"
+	OpeningClassExteriorDoor OpeningClass = "exterior_door"
+	OpeningClassSlidingDoor  OpeningClass = "sliding_door"
+	OpeningClassWindow       OpeningClass = "window"
+	OpeningClassOther        OpeningClass = "other"
+)
+
 type Fixture struct {
 	FixtureType string  `json:"fixture_type"`
 	Category    string  `json:"category"`
@@ -163,6 +463,7 @@ type Measurement struct {
 	Value           float64 `json:"value"`
 	Unit            string  `json:"unit"`
 	Location        *string `json:"location,omitempty"`
+	Normalized      bool    `json:"normalized,omitempty"` // Value converted to feet or SF
 }
 
 type Material struct {
@@ -183,46 +484,181 @@ type AnalysisResult struct {
 	RawOCRText       *string       `json:"raw_ocr_text,omitempty"`
 	ConfidenceScore  float64       `json:"confidence_score"`
 	ProcessingTimeMs int           `json:"processing_time_ms"`
+	AnalysisModel    *AIModelInfo  `json:"analysis_model,omitempty"`
+	SheetType        *string       `json:"sheet_type,omitempty"`
+	// NormalizationNotes record adjustments made to the AI's output, such as
+	// room names aligned with the previous analysis
+	NormalizationNotes []string `json:"normalization_notes,omitempty"`
+	// NormalizationWarnings list what a normalized analysis could not
+	// convert, such as measurements in unknown units
+	NormalizationWarnings []string `json:"normalization_warnings,omitempty"`
 }
 
 // TakeoffSummary represents aggregated takeoff calculations
 type TakeoffSummary struct {
+	UnitSystem      UnitSystem         `json:"unit_system,omitempty"` // System the areas, lengths and sizes are in
+	AreaUnit        string             `json:"area_unit,omitempty"`   // Unit of TotalArea and room areas
+	LengthUnit      string             `json:"length_unit,omitempty"` // Unit of TotalPerimeter and dimensions
 	TotalArea       float64            `json:"total_area"`        // Sum of all room areas (SF)
 	TotalPerimeter  float64            `json:"total_perimeter"`   // Sum of all room perimeters (LF)
+	WallArea        float64            `json:"wall_area"`         // Sum of room perimeters times ceiling height (SF)
+	CeilingHeight   float64            `json:"ceiling_height"`    // Wall height used for WallArea (LF)
 	OpeningCounts   map[string]int     `json:"opening_counts"`    // Count by opening type (door, window)
 	FixtureCounts   map[string]int     `json:"fixture_counts"`    // Count by fixture category
 	RoomCount       int                `json:"room_count"`        // Total number of rooms
 	RoomBreakdown   []RoomSummary      `json:"room_breakdown"`    // Per-room details
 	OpeningBreakdown []OpeningSummary  `json:"opening_breakdown"` // Per-opening details
+	OpeningSchedule []OpeningScheduleEntry `json:"opening_schedule"` // Door/window schedule by classification and size
 	FixtureBreakdown []FixtureSummary  `json:"fixture_breakdown"` // Per-fixture details
+	UnmeasuredRooms []string           `json:"unmeasured_rooms,omitempty"` // Rooms with no usable area
+	Warnings        []string           `json:"warnings,omitempty"`         // Data quality issues found in the analysis
+	NeedsReview     bool               `json:"needs_review"`               // Too many unmeasured rooms to trust the takeoff
+	Adjustments     []AppliedTakeoffAdjustment `json:"adjustments,omitempty"`          // Estimator adjustments applied to the quantities
+	OrphanedAdjustments []TakeoffAdjustment    `json:"orphaned_adjustments,omitempty"` // Adjustments whose key matches nothing in the analysis
+	UnrecognizedOpenings []UnrecognizedItem    `json:"unrecognized_openings,omitempty"` // Opening types pricing has no price for
+	UnrecognizedFixtures []UnrecognizedItem    `json:"unrecognized_fixtures,omitempty"` // Fixture types in categories pricing has no price for
+}
+
+// UnrecognizedItem is an opening or fixture type from the analysis that
+// pricing does not recognize, with its total count
+type UnrecognizedItem struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// TakeoffEntity is the kind of takeoff item an adjustment changes
+type TakeoffEntity string
+
+const (
+	TakeoffEntityRoom    TakeoffEntity = "room"
+	TakeoffEntityOpening TakeoffEntity = "opening"
+	TakeoffEntityFixture TakeoffEntity = "fixture"
+)
+
+// TakeoffAdjustmentOp is how an adjustment's value is applied
+type TakeoffAdjustmentOp string
+
+const (
+	TakeoffAdjustmentSet TakeoffAdjustmentOp = "set" // replace the quantity
+	TakeoffAdjustmentAdd TakeoffAdjustmentOp = "add" // add to the quantity; negative values subtract
+)
+
+// TakeoffAdjustment is an estimator's change to a takeoff quantity. Key
+// names the items it applies to: a room name, "<opening type>:<size>" or
+// "<fixture category>:<fixture type>", where leaving off the part after the
+// colon matches every size or type. Room areas are in square feet.
+type TakeoffAdjustment struct {
+	EntityType TakeoffEntity       `json:"entity_type"`
+	Key        string              `json:"key"`
+	Field      string              `json:"field"` // "area" for rooms, "count" for openings and fixtures
+	Operation  TakeoffAdjustmentOp `json:"operation"`
+	Value      float64             `json:"value"`
+	Note       string              `json:"note,omitempty"`
+}
+
+// AppliedTakeoffAdjustment is an adjustment with the total quantity of the
+// items it matched before and after it was applied
+type AppliedTakeoffAdjustment struct {
+	TakeoffAdjustment
+	OriginalValue float64 `json:"original_value"`
+	AdjustedValue float64 `json:"adjusted_value"`
+}
+
+// AdjustedQuantity marks a takeoff breakdown entry changed by an adjustment
+type AdjustedQuantity struct {
+	Field         string  `json:"field"`
+	OriginalValue float64 `json:"original_value"` // Total of the matched items before the adjustment
+	Note          string  `json:"note,omitempty"`
+}
+
+// SheetRef identifies the blueprint sheet an aggregate was taken from
+type SheetRef struct {
+	BlueprintID uuid.UUID `json:"blueprint_id"`
+	Filename    string    `json:"filename"`
+	SheetType   SheetType `json:"sheet_type"`
+}
+
+// ProjectTakeoffSummary is a takeoff merged across a project's sheets. Sources
+// maps each aggregate ("rooms", "openings", "fixtures.<category>") to the
+// sheets it was counted from.
+type ProjectTakeoffSummary struct {
+	ProjectID uuid.UUID             `json:"project_id"`
+	Takeoff   *TakeoffSummary       `json:"takeoff"`
+	Sources   map[string][]SheetRef `json:"sources"`
+	// BlueprintCount is how many analyzed blueprints were merged. Rooms are
+	// not de-duplicated, so a room drawn on two blueprints counts twice.
+	BlueprintCount int `json:"blueprint_count"`
 }
 
 type RoomSummary struct {
-	Name       string  `json:"name"`
-	RoomType   *string `json:"room_type,omitempty"`
-	Area       float64 `json:"area"`
-	Dimensions string  `json:"dimensions"`
+	Name       string       `json:"name"`
+	RoomType   *string      `json:"room_type,omitempty"`
+	Area       float64      `json:"area"`
+	Dimensions string       `json:"dimensions"`
+	Finish     *FloorFinish `json:"finish,omitempty"` // explicit floor finish selection
+	Adjusted   *AdjustedQuantity `json:"adjusted,omitempty"`
+	SourceBlueprintID *uuid.UUID `json:"source_blueprint_id,omitempty"` // Blueprint the room came from, when several were merged
 }
 
 type OpeningSummary struct {
-	OpeningType string `json:"opening_type"`
-	Count       int    `json:"count"`
-	Size        string `json:"size"`
+	OpeningType    string       `json:"opening_type"`
+	Count          int          `json:"count"`
+	Size           string       `json:"size"`
+	WidthInches    *float64     `json:"width_inches,omitempty"`
+	HeightInches   *float64     `json:"height_inches,omitempty"`
+	Classification OpeningClass `json:"classification"`
+	Adjusted       *AdjustedQuantity `json:"adjusted,omitempty"`
+}
+
+// OpeningScheduleEntry is one row of a door/window schedule: the openings
+// of one classification and size. Size is the parsed size in inches, or the
+// size as written when it could not be parsed.
+type OpeningScheduleEntry struct {
+	Classification OpeningClass `json:"classification"`
+	Size           string       `json:"size"`
+	WidthInches    *float64     `json:"width_inches,omitempty"`
+	HeightInches   *float64     `json:"height_inches,omitempty"`
+	Count          int          `json:"count"`
 }
 
 type FixtureSummary struct {
-	FixtureType string `json:"fixture_type"`
-	Category    string `json:"category"`
-	Count       int    `json:"count"`
+	FixtureType string            `json:"fixture_type"`
+	Category    string            `json:"category"`
+	Count       int               `json:"count"`
+	Adjusted    *AdjustedQuantity `json:"adjusted,omitempty"`
 }
 
 // Pricing models for cost estimation
 
 type PricingConfig struct {
-	MaterialPrices map[string]float64 `json:"material_prices"` // Material name -> price per unit
-	LaborRates     map[string]float64 `json:"labor_rates"`     // Trade -> hourly rate
-	OverheadRate   float64            `json:"overhead_rate"`   // Overhead percentage
-	ProfitMargin   float64            `json:"profit_margin"`   // Profit margin percentage
+	MaterialPrices    map[string]float64     `json:"material_prices"`               // Material name -> price per unit
+	LaborRates        map[string]float64     `json:"labor_rates"`                   // Trade -> hourly rate
+	OverheadRate      float64                `json:"overhead_rate"`                 // Overhead percentage
+	ProfitMargin      float64                `json:"profit_margin"`                 // Profit margin percentage
+	FinishLaborSplits map[string]float64     `json:"finish_labor_splits,omitempty"` // Floor finish -> labor share of installed cost
+	RoomTypeFinishes  map[string]FloorFinish `json:"room_type_finishes,omitempty"`  // Room type -> default floor finish
+	TradeMinimums     map[string]float64     `json:"trade_minimums,omitempty"`      // Trade -> minimum service charge
+	ProjectTypeModifiers map[ProjectType]ProjectTypeModifier `json:"project_type_modifiers,omitempty"` // Project type -> extra costs
+	ProductivityRates map[string]float64    `json:"productivity_rates,omitempty"`   // Work item -> units installed per labor hour
+	AppliedOverrides  []uuid.UUID            `json:"applied_overrides,omitempty"`   // Company overrides that changed a price or rate
+	SkippedOverrides  []SkippedOverride      `json:"skipped_overrides,omitempty"`   // Company overrides that had no effect
+}
+
+// ProjectTypeModifier is the extra cost of a kind of project over new
+// construction. Per-SF amounts apply to the takeoff's total area.
+type ProjectTypeModifier struct {
+	DemolitionPerSF float64 `json:"demolition_per_sf"` // Demolition of the affected area
+	LaborMultiplier float64 `json:"labor_multiplier"`  // Labor productivity penalty on estimated hours; 1 is none
+	ProtectionPerSF float64 `json:"protection_per_sf"` // Dust protection and general conditions
+}
+
+// SkippedOverride is a company pricing override that did not apply, e.g.
+// because its item key matches no current material category or labor trade
+type SkippedOverride struct {
+	OverrideID   uuid.UUID `json:"override_id"`
+	OverrideType string    `json:"override_type"`
+	ItemKey      string    `json:"item_key"`
+	Reason       string    `json:"reason"`
 }
 
 type LineItem struct {
@@ -232,22 +668,215 @@ type LineItem struct {
 	Unit        string  `json:"unit"`
 	UnitCost    float64 `json:"unit_cost"`
 	Total       float64 `json:"total"`
+	// Alternates are priced separately from the base bid and accepted independently
+	IsAlternate    bool   `json:"is_alternate,omitempty"`
+	AlternateGroup string `json:"alternate_group,omitempty"`
+	// PriceSource records where the unit cost came from; internal only, not printed on PDFs
+	PriceSource *PriceSource `json:"price_source,omitempty"`
+	// Provenance is LineItemProvenanceAuto when the quantity came from the
+	// takeoff; empty on bids generated before provenance was recorded
+	Provenance string `json:"provenance,omitempty"`
+	// Notes are the estimator's assumptions for this item, printed under it
+	Notes string `json:"notes,omitempty"`
+	// MaterialCost and LaborCost split Total for sales tax; set by the
+	// pricing services, zero on items with no recorded split
+	MaterialCost float64 `json:"material_cost,omitempty"`
+	LaborCost    float64 `json:"labor_cost,omitempty"`
+	// TaxAmount is the sales tax charged on this item after markup
+	TaxAmount float64 `json:"tax_amount,omitempty"`
+	// NeedsReview marks a zero-cost placeholder for work the analysis found
+	// but pricing does not recognize; the estimator prices it by hand
+	NeedsReview bool `json:"needs_review,omitempty"`
+}
+
+// Line item provenance values. Repricing refreshes auto items and leaves
+// manual ones alone unless asked to overwrite them.
+const (
+	LineItemProvenanceAuto   = "auto"
+	LineItemProvenanceManual = "manual"
+)
+
+// PriceSource describes how a unit cost was resolved
+type PriceSource struct {
+	Source         string     `json:"source"`                // Provider name (e.g. "lowes"), "company_override", "project_override" or "default"
+	OverrideID     *uuid.UUID `json:"override_id,omitempty"` // Set when Source is "company_override" or "project_override"
+	LastUpdated    *Timestamp `json:"last_updated,omitempty"` // When a provider price was last synced
+	RegionalFactor float64    `json:"regional_factor"`        // Regional multiplier applied to the base price
+}
+
+// AlternateGroup is a named set of alternate line items. Price is the
+// marked-up amount accepting the alternate adds to the base total.
+type AlternateGroup struct {
+	Name      string     `json:"name"`
+	LineItems []LineItem `json:"line_items"`
+	Cost      float64    `json:"cost"`
+	Price     float64    `json:"price"`
+}
+
+// TaxBasis is the part of a price sales tax is charged on
+type TaxBasis string
+
+const (
+	// TaxBasisMaterialsOnly taxes the material share of each line item
+	TaxBasisMaterialsOnly TaxBasis = "materials_only"
+	// TaxBasisLaborAndMaterials taxes every line item but not overhead
+	TaxBasisLaborAndMaterials TaxBasis = "labor_and_materials"
+	// TaxBasisTotalContract taxes the whole contract price
+	TaxBasisTotalContract TaxBasis = "total_contract"
+)
+
+// TaxRule is a sales tax rate, in percent, and the basis it is charged on
+type TaxRule struct {
+	Rate  float64  `json:"rate"`
+	Basis TaxBasis `json:"basis"`
+}
+
+// TaxSettings are a company's sales tax rules. RegionOverrides, keyed by
+// pricing region, replace the default for projects in that region; an
+// override without a basis keeps the default basis.
+type TaxSettings struct {
+	DefaultRate     float64            `json:"default_rate"`
+	Basis           TaxBasis           `json:"basis"`
+	RegionOverrides map[string]TaxRule `json:"region_overrides,omitempty"`
+}
+
+// TaxSummary records the sales tax charged on an estimate or bid so it can be
+// recalculated when the line items change
+type TaxSummary struct {
+	Rate          float64  `json:"rate"`
+	Basis         TaxBasis `json:"basis"`
+	Region        string   `json:"region,omitempty"` // Region whose override applied
+	TaxableAmount float64  `json:"taxable_amount"`
+	Exempt        bool     `json:"exempt,omitempty"`
+	Note          string   `json:"note,omitempty"` // Printed with the tax line, e.g. the exemption
 }
 
 type PricingSummary struct {
+	UnitSystem       UnitSystem         `json:"unit_system,omitempty"` // System line item quantities are in
 	LineItems        []LineItem         `json:"line_items"`
 	LaborCost        float64            `json:"labor_cost"`
 	MaterialCost     float64            `json:"material_cost"`
 	Subtotal         float64            `json:"subtotal"`
 	OverheadAmount   float64            `json:"overhead_amount"`
 	MarkupAmount     float64            `json:"markup_amount"`
-	TotalPrice       float64            `json:"total_price"`
+	TaxAmount        float64            `json:"tax_amount"`
+	TotalPrice       float64            `json:"total_price"` // Includes TaxAmount
+	Tax              *TaxSummary        `json:"tax,omitempty"`
 	CostsByTrade     map[string]float64 `json:"costs_by_trade"`
+	BudgetStatus     *BudgetStatus      `json:"budget_status,omitempty"`
+	AppliedOverrides []uuid.UUID        `json:"applied_overrides,omitempty"`
+	SkippedOverrides []SkippedOverride  `json:"skipped_overrides,omitempty"`
+	ConfidenceRange  *ConfidenceRange   `json:"confidence_range,omitempty"`
+	UnitMetrics      *UnitMetrics       `json:"unit_metrics,omitempty"`
+	Notes            []string           `json:"notes,omitempty"` // Adjustments worth explaining to the customer, e.g. minimum charges
+}
+
+// UnitMetrics are an estimate's costs per square foot of takeoff area. When
+// the area is unknown only OmittedReason is set.
+type UnitMetrics struct {
+	AreaSF        float64            `json:"area_sf,omitempty"`
+	CostPerSF     float64            `json:"cost_per_sf,omitempty"`
+	MaterialPerSF float64            `json:"material_per_sf,omitempty"`
+	LaborPerSF    float64            `json:"labor_per_sf,omitempty"`
+	TradePerSF    map[string]float64 `json:"trade_per_sf,omitempty"`
+	OmittedReason string             `json:"omitted_reason,omitempty"`
+	ExpectedRange *CostPerSFBand     `json:"expected_range,omitempty"` // Band for the project type
+	Warning       string             `json:"warning,omitempty"`        // Set when CostPerSF is outside ExpectedRange
+}
+
+// CostPerSFBand is the cost per square foot expected for a project type
+type CostPerSFBand struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ConfidenceRange brackets an estimate's total price by the uncertainty of
+// its inputs. FactorsApplied documents each uncertainty used.
+type ConfidenceRange struct {
+	Low            float64                 `json:"low"`
+	Likely         float64                 `json:"likely"`
+	High           float64                 `json:"high"`
+	FactorsApplied []ConfidenceRangeFactor `json:"factors_applied"`
+}
+
+// ConfidenceRangeFactor is one input class's contribution to a confidence
+// range. Uncertainty is a fraction of the affected totals (0.15 = ±15%).
+type ConfidenceRangeFactor struct {
+	Input       string  `json:"input"`
+	Uncertainty float64 `json:"uncertainty"`
+	LineItems   int     `json:"line_items,omitempty"` // Line items priced from this class
+	Detail      string  `json:"detail,omitempty"`
+}
+
+// RegionComparison prices one takeoff under several regional adjustments.
+// Per-region values are maps keyed by region so the frontend can render
+// regions as table columns.
+type RegionComparison struct {
+	Regions   []string                      `json:"regions"`
+	LineItems []RegionLineItemComparison    `json:"line_items"`
+	Trades    map[string]map[string]float64 `json:"trades"`    // Trade -> region -> total
+	Subtotals map[string]float64            `json:"subtotals"` // Region -> subtotal before overhead and markup
+	Taxes     map[string]float64            `json:"taxes"`     // Region -> sales tax included in the total
+	Totals    map[string]float64            `json:"totals"`    // Region -> total price
+	Spread    RegionSpread                  `json:"spread"`
+	// Baseline is the first requested region, which deltas are measured from
+	Baseline           string                        `json:"baseline"`
+	TradeDeltas        map[string]map[string]float64 `json:"trade_deltas"`        // Trade -> region -> total minus the baseline's
+	PercentDifferences map[string]float64            `json:"percent_differences"` // Region -> total's percent above or below the baseline's
+}
+
+// RegionLineItemComparison is one line item priced in each region
+type RegionLineItemComparison struct {
+	Description string             `json:"description"`
+	Trade       string             `json:"trade"`
+	Unit        string             `json:"unit"`
+	Quantity    map[string]float64 `json:"quantity"`
+	UnitCost    map[string]float64 `json:"unit_cost"`
+	Total       map[string]float64 `json:"total"`
+}
+
+// RegionSpread compares the cheapest and most expensive region's total price
+type RegionSpread struct {
+	LowestRegion  string  `json:"lowest_region"`
+	HighestRegion string  `json:"highest_region"`
+	Difference    float64 `json:"difference"`
+	Percent       float64 `json:"percent"` // Difference as a percentage of the lowest total
 }
 
 // Bid generation request/response models
 
 // CompanyInfo represents company branding and contact information for PDF export
+// PDFSection identifies a section of the bid document
+type PDFSection string
+
+const (
+	PDFSectionCover          PDFSection = "cover"
+	PDFSectionProjectInfo    PDFSection = "project_info"
+	PDFSectionScope          PDFSection = "scope"
+	PDFSectionLineItems      PDFSection = "line_items"
+	PDFSectionTradeBreakdown PDFSection = "trade_breakdown"
+	PDFSectionCostSummary    PDFSection = "cost_summary"
+	PDFSectionInclusions     PDFSection = "inclusions"
+	PDFSectionExclusions     PDFSection = "exclusions"
+	PDFSectionSchedule       PDFSection = "schedule"
+	PDFSectionTerms          PDFSection = "terms"
+	PDFSectionSignature      PDFSection = "signature"
+)
+
+// PDFSectionLayout places one section in the bid document. Title replaces
+// the section's default heading.
+type PDFSectionLayout struct {
+	Section PDFSection `json:"section"`
+	Enabled bool       `json:"enabled"`
+	Title   string     `json:"title,omitempty"`
+}
+
+// PDFLayout is a company's bid document structure, sections in print order.
+// Sections left out are not printed.
+type PDFLayout struct {
+	Sections []PDFSectionLayout `json:"sections"`
+}
+
 type CompanyInfo struct {
 	Name           string  `json:"name"`
 	Logo           *string `json:"logo,omitempty"`            // S3 URL or path to logo image
@@ -259,6 +888,25 @@ type CompanyInfo struct {
 	InsuranceInfo  *string `json:"insurance_info,omitempty"`
 }
 
+// CompanyProfile is a company's name, contact details and logo, printed on
+// its bid documents. LogoS3Key names a PNG or JPEG logo in object storage.
+type CompanyProfile struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Name          string    `json:"name"`
+	Address       *string   `json:"address,omitempty"`
+	Phone         *string   `json:"phone,omitempty"`
+	Email         *string   `json:"email,omitempty"`
+	Website       *string   `json:"website,omitempty"`
+	LicenseNumber *string   `json:"license_number,omitempty"`
+	InsuranceInfo *string   `json:"insurance_info,omitempty"`
+	LogoS3Key     *string   `json:"logo_s3_key,omitempty"`
+	// BudgetAckThresholdPercent overrides the configured percent over budget
+	// beyond which bid generation must be acknowledged. Zero disables it.
+	BudgetAckThresholdPercent *float64  `json:"budget_ack_threshold_percent,omitempty"`
+	CreatedAt                 Timestamp `json:"created_at"`
+	UpdatedAt                 Timestamp `json:"updated_at"`
+}
+
 type GenerateBidRequest struct {
 	ProjectID        uuid.UUID      `json:"project_id"`
 	BlueprintID      uuid.UUID      `json:"blueprint_id"`
@@ -271,6 +919,7 @@ type GenerateBidRequest struct {
 type GenerateBidResponse struct {
 	BidID            string     `json:"bid_id"`
 	ProjectID        string     `json:"project_id"`
+	BlueprintID      string     `json:"blueprint_id,omitempty"` // Blueprint whose takeoff priced the bid
 	Status           string     `json:"status"`
 	ScopeOfWork      string     `json:"scope_of_work"`
 	LineItems        []LineItem `json:"line_items"`
@@ -278,13 +927,44 @@ type GenerateBidResponse struct {
 	MaterialCost     float64    `json:"material_cost"`
 	Subtotal         float64    `json:"subtotal"`
 	MarkupAmount     float64    `json:"markup_amount"`
-	TotalPrice       float64    `json:"total_price"`
+	TaxAmount        float64    `json:"tax_amount,omitempty"`
+	TotalPrice       float64    `json:"total_price"` // Includes TaxAmount
+	Tax              *TaxSummary `json:"tax,omitempty"` // How TaxAmount was charged; nil on bids priced without tax
+	Alternates       []AlternateGroup `json:"alternates,omitempty"`
 	Exclusions       []string   `json:"exclusions"`
 	Inclusions       []string   `json:"inclusions"`
 	Schedule         map[string]string `json:"schedule"`
 	PaymentTerms     string     `json:"payment_terms"`
 	WarrantyTerms    string     `json:"warranty_terms"`
 	ClosingStatement string     `json:"closing_statement"`
+	Warnings         []string   `json:"warnings,omitempty"` // Issues for the estimator to review before sending
+	ConfidenceRange  *ConfidenceRange `json:"confidence_range,omitempty"` // Set when the bid was generated with include_estimate_range
+	OpeningSchedule  []OpeningScheduleEntry `json:"opening_schedule,omitempty"` // Door/window schedule from the priced takeoff
+	UnitMetrics      *UnitMetrics `json:"unit_metrics,omitempty"` // Costs per square foot of the priced takeoff
+	SourceBlueprints []BidSourceBlueprint `json:"source_blueprints,omitempty"` // Blueprints priced, as they were when the bid was priced
+	ReviewFlags      []ReviewFlag `json:"review_flags,omitempty"` // Line items the estimator must price by hand
+	GeneratedFromLowConfidence bool `json:"generated_from_low_confidence,omitempty"` // Set when the bid was forced from an analysis below the confidence threshold
+	GenerationMode   string     `json:"generation_mode,omitempty"` // BidGenerationModeAI or BidGenerationModeDeterministic; empty on bids generated before modes were recorded
+}
+
+// How a bid's text was written
+const (
+	BidGenerationModeAI            = "ai"            // By the AI service
+	BidGenerationModeDeterministic = "deterministic" // From the pricing summary and takeoff, when the AI service was unavailable
+)
+
+// ReviewFlag points the estimator at a line item that needs review
+type ReviewFlag struct {
+	LineItem string `json:"line_item"` // Description of the flagged line item
+	Trade    string `json:"trade"`
+	Reason   string `json:"reason"`
+}
+
+// BidSourceBlueprint is a blueprint a bid was priced from
+type BidSourceBlueprint struct {
+	BlueprintID uuid.UUID `json:"blueprint_id"`
+	Filename    string    `json:"filename"`
+	Version     int       `json:"version"`
 }
 
 type BidPDFInfo struct {
@@ -292,6 +972,55 @@ type BidPDFInfo struct {
 	S3Key  string `json:"s3_key"`
 }
 
+// Company analytics models
+
+// Company analytics period widths
+const (
+	AnalyticsGroupByWeek    = "week"
+	AnalyticsGroupByMonth   = "month"
+	AnalyticsGroupByQuarter = "quarter"
+	AnalyticsGroupByYear    = "year"
+)
+
+// CompanyAnalyticsFilter scopes analytics to the bids on a user's projects
+// created in [From, To), grouped into GroupBy periods
+type CompanyAnalyticsFilter struct {
+	UserID  uuid.UUID
+	From    time.Time
+	To      time.Time
+	GroupBy string
+}
+
+// CompanyAnalytics aggregates a company's bids over a date range
+type CompanyAnalytics struct {
+	From    Timestamp      `json:"from"`
+	To      Timestamp      `json:"to"`
+	GroupBy string         `json:"group_by"`
+	Totals  BidAggregate   `json:"totals"`
+	Periods []BidAggregate `json:"periods"`
+}
+
+// BidAggregate summarizes the bids in one period, or in the whole range
+type BidAggregate struct {
+	PeriodStart             *Timestamp        `json:"period_start,omitempty"` // Nil on the range totals
+	BidCount                int               `json:"bid_count"`
+	CountsByStatus          map[BidStatus]int `json:"counts_by_status"`
+	TotalFinalPrice         float64           `json:"total_final_price"`
+	AverageFinalPrice       float64           `json:"average_final_price"`
+	AverageMarkupPercentage float64           `json:"average_markup_percentage"`
+	// WinRate is accepted / (accepted + rejected), nil until a bid is decided
+	WinRate  *float64         `json:"win_rate"`
+	TradeMix []TradeCostShare `json:"trade_mix"`
+}
+
+// TradeCostShare is a trade's cost and share of each bid's trade costs,
+// averaged across the bids with trade costs; bids without the trade count as zero
+type TradeCostShare struct {
+	Trade        string  `json:"trade"`
+	AverageCost  float64 `json:"average_cost"`
+	AverageShare float64 `json:"average_share"`
+}
+
 // Cost database models
 
 type MaterialCost struct {
@@ -304,9 +1033,65 @@ type MaterialCost struct {
 	Source      string     `json:"source"`
 	SourceID    *string    `json:"source_id"`
 	Region      *string    `json:"region"`
-	LastUpdated time.Time  `json:"last_updated"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"` // Admin who added it by hand; nil for synced rows
+	LastUpdated Timestamp  `json:"last_updated"`
+	CreatedAt   Timestamp  `json:"created_at"`
+	UpdatedAt   Timestamp  `json:"updated_at"`
+}
+
+// CostSourceManual is the source of materials and labor rates admins add or
+// edit by hand rather than sync from a provider
+const CostSourceManual = "manual"
+
+// MaterialListFilter narrows and pages the materials list. Query matches the
+// name as a case-insensitive substring.
+type MaterialListFilter struct {
+	Category *string
+	Region   *string
+	Query    *string
+	Limit    int
+	Offset   int
+}
+
+// MaterialPriceFilter selects materials for a bulk price adjustment; nil fields match all rows
+type MaterialPriceFilter struct {
+	Category *string `json:"category,omitempty"`
+	Region   *string `json:"region,omitempty"`
+	Source   *string `json:"source,omitempty"`
+}
+
+// PriceAdjustmentStats summarizes the rows changed (or, for a dry run, that
+// would change) by a bulk price adjustment, with their new prices
+type PriceAdjustmentStats struct {
+	RowsAffected int64   `json:"rows_affected"`
+	MinPrice     float64 `json:"min_price"`
+	MaxPrice     float64 `json:"max_price"`
+	AvgPrice     float64 `json:"avg_price"`
+	DryRun       bool    `json:"dry_run"`
+}
+
+// Retention sweep phases
+const (
+	RetentionPhaseJobs               = "jobs"
+	RetentionPhaseBlueprintRevisions = "orphaned_blueprint_revisions"
+	RetentionPhaseBidRevisions       = "orphaned_bid_revisions"
+	RetentionPhaseDrafts             = "expired_drafts"
+)
+
+// RetentionPhaseResult is the rows one retention phase removed (or, for a
+// dry run, would remove) and the delete batches it took
+type RetentionPhaseResult struct {
+	Phase   string `json:"phase"`
+	Rows    int64  `json:"rows"`
+	Batches int    `json:"batches"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RetentionReport summarizes a retention sweep
+type RetentionReport struct {
+	DryRun     bool                   `json:"dry_run"`
+	JobsBefore Timestamp              `json:"jobs_before"`
+	Phases     []RetentionPhaseResult `json:"phases"`
 }
 
 type LaborRate struct {
@@ -317,9 +1102,10 @@ type LaborRate struct {
 	Source      string     `json:"source"`
 	SourceID    *string    `json:"source_id"`
 	Region      *string    `json:"region"`
-	LastUpdated time.Time  `json:"last_updated"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"` // Admin who added it by hand; nil for synced rows
+	LastUpdated Timestamp  `json:"last_updated"`
+	CreatedAt   Timestamp  `json:"created_at"`
+	UpdatedAt   Timestamp  `json:"updated_at"`
 }
 
 type RegionalAdjustment struct {
@@ -330,9 +1116,9 @@ type RegionalAdjustment struct {
 	AdjustmentFactor   float64    `json:"adjustment_factor"`
 	CostOfLivingIndex  *int       `json:"cost_of_living_index"`
 	Source             string     `json:"source"`
-	LastUpdated        time.Time  `json:"last_updated"`
-	CreatedAt          time.Time  `json:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at"`
+	LastUpdated        Timestamp  `json:"last_updated"`
+	CreatedAt          Timestamp  `json:"created_at"`
+	UpdatedAt          Timestamp  `json:"updated_at"`
 }
 
 type CompanyPricingOverride struct {
@@ -343,8 +1129,67 @@ type CompanyPricingOverride struct {
 	OverrideValue float64    `json:"override_value"`
 	IsPercentage  bool       `json:"is_percentage"`
 	Notes         *string    `json:"notes"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
+	CreatedAt     Timestamp  `json:"created_at"`
+	UpdatedAt     Timestamp  `json:"updated_at"`
+}
+
+// ProjectPricingOverride is a company pricing override for one project. It
+// takes precedence over the project owner's company overrides.
+type ProjectPricingOverride struct {
+	ID            uuid.UUID `json:"id"`
+	ProjectID     uuid.UUID `json:"project_id"`
+	OverrideType  string    `json:"override_type"`
+	ItemKey       string    `json:"item_key"`
+	OverrideValue float64   `json:"override_value"`
+	IsPercentage  bool      `json:"is_percentage"`
+	Notes         *string   `json:"notes"`
+	CreatedAt     Timestamp `json:"created_at"`
+	UpdatedAt     Timestamp `json:"updated_at"`
+}
+
+// OrphanedOverride is a company pricing override whose item key no longer
+// matches a current material category or labor trade
+type OrphanedOverride struct {
+	Override   CompanyPricingOverride `json:"override"`
+	Suggestion *string                `json:"suggestion,omitempty"` // Closest current key, if any is similar
+}
+
+// OverrideValidationReport lists the orphaned overrides among those checked
+type OverrideValidationReport struct {
+	Checked  int                `json:"checked"`
+	Orphaned []OrphanedOverride `json:"orphaned"`
+}
+
+// BlueprintAssetKind is the role of an S3 object stored for a blueprint
+type BlueprintAssetKind string
+
+const (
+	BlueprintAssetOriginal  BlueprintAssetKind = "original"
+	BlueprintAssetConverted BlueprintAssetKind = "converted"
+	BlueprintAssetRevision  BlueprintAssetKind = "revision"
+	BlueprintAssetThumbnail BlueprintAssetKind = "thumbnail"
+)
+
+// BlueprintAsset is one S3 object stored for a blueprint
+type BlueprintAsset struct {
+	ID          uuid.UUID          `json:"id"`
+	BlueprintID uuid.UUID          `json:"blueprint_id"`
+	Kind        BlueprintAssetKind `json:"kind"`
+	S3Key       string             `json:"s3_key"`
+	SizeBytes   *int64             `json:"size_bytes"`
+	Checksum    *string            `json:"checksum"`
+	// StorageClass is the S3 storage class the object was stored with
+	StorageClass *string   `json:"storage_class"`
+	CreatedAt    Timestamp `json:"created_at"`
+	DownloadURL  *string   `json:"download_url,omitempty"` // Presigned link; not stored
+}
+
+// BlueprintDeletion is what deleting a blueprint removed: the S3 keys of its
+// file, revisions and assets, and how many of its jobs were still queued or
+// processing
+type BlueprintDeletion struct {
+	S3Keys        []string `json:"-"`
+	CancelledJobs int      `json:"cancelled_jobs"`
 }
 
 // Revision tracking models
@@ -358,11 +1203,26 @@ type BlueprintRevision struct {
 	FileSize       *int64     `json:"file_size"`
 	MimeType       *string    `json:"mime_type"`
 	AnalysisData   *string    `json:"analysis_data"`
+	AnalysisModel  *AIModelInfo `json:"analysis_model,omitempty"`
 	ChangesSummary *string    `json:"changes_summary"` // JSONB stored as string
+	Reason         *string    `json:"reason,omitempty"` // Why the revision was made; nil for manual snapshots
 	CreatedBy      *uuid.UUID `json:"created_by"`
-	CreatedAt      time.Time  `json:"created_at"`
+	CreatedAt      Timestamp  `json:"created_at"`
+	AnalysisUnreadable bool   `json:"analysis_unreadable,omitempty"` // AnalysisData does not parse; set when listing, not stored
 }
 
+// BlueprintRevisionReasonPreAnalysisOverwrite marks the automatic snapshot of
+// a blueprint's analysis taken before a new analysis replaces it
+const BlueprintRevisionReasonPreAnalysisOverwrite = "pre_analysis_overwrite"
+
+// BlueprintRevisionReasonRestore marks the revision made when a blueprint is
+// rolled back to an earlier revision
+const BlueprintRevisionReasonRestore = "restore"
+
+// BlueprintRevisionReasonPreRestore marks the snapshot of a blueprint's
+// unsaved file or analysis taken before a restore replaces it
+const BlueprintRevisionReasonPreRestore = "pre_restore"
+
 type BidRevision struct {
 	ID               uuid.UUID  `json:"id"`
 	BidID            uuid.UUID  `json:"bid_id"`
@@ -375,9 +1235,240 @@ type BidRevision struct {
 	FinalPrice       *float64   `json:"final_price"`
 	Status           BidStatus  `json:"status"`
 	BidData          *string    `json:"bid_data"`
+	GenerationModel  *AIModelInfo `json:"generation_model,omitempty"`
+	BlueprintIDs     []uuid.UUID `json:"blueprint_ids"`
 	ChangesSummary   *string    `json:"changes_summary"` // JSONB stored as string
+	Reason           *string    `json:"reason,omitempty"` // Why the revision was made, e.g. BidRevisionReasonReprice
 	CreatedBy        *uuid.UUID `json:"created_by"`
-	CreatedAt        time.Time  `json:"created_at"`
+	CreatedAt        Timestamp  `json:"created_at"`
+}
+
+// Bid revision reasons recorded by the endpoints that revise a bid
+const (
+	BidRevisionReasonRename  = "rename"
+	BidRevisionReasonReprice = "reprice"
+	BidRevisionReasonEdit    = "edit"
+	// BidRevisionReasonAccepted freezes the version of a bid that was accepted
+	BidRevisionReasonAccepted = "accepted"
+)
+
+// BidDraft is a user's uncommitted edit of a bid. It is saved as a revision
+// only when committed, and expires after ExpiresAt.
+type BidDraft struct {
+	ID               uuid.UUID `json:"id"`
+	BidID            uuid.UUID `json:"bid_id"`
+	UserID           uuid.UUID `json:"user_id"`
+	BaseVersion      int       `json:"base_version"` // Bid version the draft was started from
+	BidData          string    `json:"bid_data"`
+	MarkupPercentage *float64  `json:"markup_percentage,omitempty"`
+	CreatedAt        Timestamp `json:"created_at"`
+	UpdatedAt        Timestamp `json:"updated_at"`
+	ExpiresAt        Timestamp `json:"expires_at"`
+}
+
+// APIKeyScopeReadOnly limits an API key to requests that change nothing
+const APIKeyScopeReadOnly = "read_only"
+
+// APIKey lets an external integration call the API as the key's owner. Only
+// a hash of the key is stored; the key itself is shown once, on creation.
+type APIKey struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Name         string     `json:"name"`
+	Prefix       string     `json:"prefix"` // Leading characters of the key, to tell keys apart
+	KeyHash      string     `json:"-"`
+	Scope        string     `json:"scope"`
+	ExpiresAt    *Timestamp `json:"expires_at,omitempty"`
+	RevokedAt    *Timestamp `json:"revoked_at,omitempty"`
+	LastUsedAt   *Timestamp `json:"last_used_at,omitempty"`
+	RequestCount int64      `json:"request_count"`
+	CreatedAt    Timestamp  `json:"created_at"`
+}
+
+// RefreshToken trades for a new access token until it expires or is
+// revoked. Using one revokes it; only a hash of the token is stored.
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt Timestamp  `json:"expires_at"`
+	RevokedAt *Timestamp `json:"revoked_at,omitempty"`
+	CreatedAt Timestamp  `json:"created_at"`
+}
+
+// BidShare is a link that lets a client without an account view a bid until
+// it expires or is revoked and accept or reject the bid once. Only a hash of
+// the link's token is stored.
+type BidShare struct {
+	ID        uuid.UUID  `json:"id"`
+	BidID     uuid.UUID  `json:"bid_id"`
+	TokenHash string     `json:"-"`
+	CreatedBy *uuid.UUID `json:"-"`
+	ExpiresAt Timestamp  `json:"expires_at"`
+	// RespondedAt is when the client accepted or rejected the bid through
+	// the link; Response is the status they chose
+	RespondedAt     *Timestamp `json:"responded_at,omitempty"`
+	Response        *BidStatus `json:"response,omitempty"`
+	ResponseComment *string    `json:"response_comment,omitempty"`
+	ResponderName   *string    `json:"responder_name,omitempty"`
+	RevokedAt       *Timestamp `json:"revoked_at,omitempty"`
+	FirstViewedAt   *Timestamp `json:"first_viewed_at,omitempty"`
+	CreatedAt       Timestamp  `json:"created_at"`
+}
+
+// BidShareResponse is a client's answer to a shared bid
+type BidShareResponse struct {
+	Status        BidStatus
+	Comment       *string
+	ResponderName string
+}
+
+// BidAcceptance is a client's signed online acceptance of a shared bid: the
+// name and email they typed, and the address, browser and time they accepted
+// from
+type BidAcceptance struct {
+	ID          uuid.UUID `json:"id"`
+	BidID       uuid.UUID `json:"bid_id"`
+	ShareID     uuid.UUID `json:"share_id"`
+	SignerName  string    `json:"signer_name"`
+	SignerEmail string    `json:"signer_email"`
+	IPAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	AcceptedAt  Timestamp `json:"accepted_at"`
+}
+
+// BidShareEventType is something a client did with a shared bid
+type BidShareEventType string
+
+const (
+	// BidShareEventViewed is recorded when the client opens the link
+	BidShareEventViewed BidShareEventType = "viewed"
+	// The client's page reports the sections the client read and PDF
+	// downloads
+	BidShareEventViewedSummary   BidShareEventType = "viewed_summary"
+	BidShareEventViewedLineItems BidShareEventType = "viewed_line_items"
+	BidShareEventDownloadedPDF   BidShareEventType = "downloaded_pdf"
+)
+
+// BidShareEvent is one thing a client did through a share link. The viewer
+// is identified by a hash of their IP address.
+type BidShareEvent struct {
+	ID        uuid.UUID         `json:"id"`
+	ShareID   uuid.UUID         `json:"share_id"`
+	BidID     uuid.UUID         `json:"bid_id"`
+	EventType BidShareEventType `json:"event_type"`
+	IPHash    string            `json:"-"`
+	UserAgent string            `json:"-"`
+	CreatedAt Timestamp         `json:"created_at"`
+}
+
+// BidShareEngagement is what clients did through one share link. Unique
+// viewers count each IP address once a day.
+type BidShareEngagement struct {
+	ShareID       uuid.UUID  `json:"share_id"`
+	CreatedAt     Timestamp  `json:"created_at"`
+	ExpiresAt     Timestamp  `json:"expires_at"`
+	RevokedAt     *Timestamp `json:"revoked_at,omitempty"`
+	FirstViewedAt *Timestamp `json:"first_viewed_at,omitempty"`
+	LastViewedAt  *Timestamp `json:"last_viewed_at,omitempty"`
+	TotalViews    int        `json:"total_views"`
+	UniqueViewers int        `json:"unique_viewers"`
+	SummaryViews  int        `json:"summary_views"`
+	LineItemViews int        `json:"line_item_views"`
+	PDFDownloads  int        `json:"pdf_downloads"`
+}
+
+// BidEngagement is what clients did with a bid across its share links
+type BidEngagement struct {
+	BidID         uuid.UUID            `json:"bid_id"`
+	FirstViewedAt *Timestamp           `json:"first_viewed_at,omitempty"`
+	LastViewedAt  *Timestamp           `json:"last_viewed_at,omitempty"`
+	TotalViews    int                  `json:"total_views"`
+	UniqueViewers int                  `json:"unique_viewers"`
+	PDFDownloads  int                  `json:"pdf_downloads"`
+	Shares        []BidShareEngagement `json:"shares"`
+}
+
+// PublicBid is a shared bid as its client sees it: the scope, priced line
+// items, totals and terms, without costs, sources or anyone's IDs
+type PublicBid struct {
+	Name             *string           `json:"name,omitempty"`
+	Status           BidStatus         `json:"status"`
+	ScopeOfWork      string            `json:"scope_of_work"`
+	LineItems        []PublicLineItem  `json:"line_items"`
+	Subtotal         float64           `json:"subtotal"`
+	MarkupAmount     float64           `json:"markup_amount"`
+	TaxAmount        float64           `json:"tax_amount,omitempty"`
+	TotalPrice       float64           `json:"total_price"`
+	Exclusions       []string          `json:"exclusions"`
+	Inclusions       []string          `json:"inclusions"`
+	Schedule         map[string]string `json:"schedule,omitempty"`
+	PaymentTerms     string            `json:"payment_terms"`
+	WarrantyTerms    string            `json:"warranty_terms"`
+	ClosingStatement string            `json:"closing_statement"`
+	PDFURL           *string           `json:"pdf_url,omitempty"`
+	ExpiresAt        Timestamp         `json:"expires_at"`
+	RespondedAt      *Timestamp        `json:"responded_at,omitempty"`
+	Response         *BidStatus        `json:"response,omitempty"`
+}
+
+// PublicLineItem is a line item as a shared bid's client sees it
+type PublicLineItem struct {
+	Description    string  `json:"description"`
+	Trade          string  `json:"trade"`
+	Quantity       float64 `json:"quantity"`
+	Unit           string  `json:"unit"`
+	UnitCost       float64 `json:"unit_cost"`
+	Total          float64 `json:"total"`
+	IsAlternate    bool    `json:"is_alternate,omitempty"`
+	AlternateGroup string  `json:"alternate_group,omitempty"`
+	Notes          string  `json:"notes,omitempty"`
+}
+
+// Webhook event types a user can subscribe to
+const (
+	WebhookEventAnalysisCompleted = "analysis.completed"
+	WebhookEventJobFailed         = "job.failed"
+	WebhookEventBidCreated        = "bid.created"
+	WebhookEventBidOverBudget     = "bid.over_budget"
+	WebhookEventBidStatusChanged  = "bid.status_changed"
+	WebhookEventBidAccepted       = "bid.accepted"
+	WebhookEventBidFirstViewed    = "bid.first_viewed"
+)
+
+// Webhook is a user's endpoint for event notifications. The secret signs
+// each payload; it is shown once, on creation.
+type Webhook struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  Timestamp `json:"created_at"`
+}
+
+// WebhookDeliveryStatus is how far delivering an event to a webhook got
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery records sending one event to a webhook. ResponseCode and
+// ErrorMessage are from the latest attempt.
+type WebhookDelivery struct {
+	ID           uuid.UUID             `json:"id"`
+	WebhookID    uuid.UUID             `json:"webhook_id"`
+	EventType    string                `json:"event_type"`
+	Payload      json.RawMessage       `json:"payload"`
+	Status       WebhookDeliveryStatus `json:"status"`
+	Attempts     int                   `json:"attempts"`
+	ResponseCode *int                  `json:"response_code,omitempty"`
+	ErrorMessage *string               `json:"error_message,omitempty"`
+	CreatedAt    Timestamp             `json:"created_at"`
+	UpdatedAt    Timestamp             `json:"updated_at"`
 }
 
 // Comparison result models
@@ -390,13 +1481,43 @@ const (
 	ChangeTypeModified ChangeType = "modified"
 )
 
+// ChangeImpact is how much a change between revisions matters
+type ChangeImpact string
+
+const (
+	ImpactHigh   ChangeImpact = "High"
+	ImpactMedium ChangeImpact = "Medium"
+	ImpactLow    ChangeImpact = "Low"
+)
+
+// ImpactBand labels a change by how far a value moved, in percent: more than
+// HighPercent is High, less than LowPercent is Low and anything between is
+// Medium
+type ImpactBand struct {
+	LowPercent  float64 `json:"low_percent"`
+	HighPercent float64 `json:"high_percent"`
+}
+
+// ImpactThresholds decide the impact labels of revision comparisons. A
+// company's thresholds override the defaults band by band.
+type ImpactThresholds struct {
+	// Bands by change category: room, measurement, material, quantity,
+	// line_item and cost
+	Bands map[string]ImpactBand `json:"bands"`
+	// DollarFloor makes any dollar change smaller than it Low
+	DollarFloor float64 `json:"dollar_floor"`
+	// WeighCostsByFinalPrice bands dollar changes by their share of the final
+	// price instead of labelling them by the kind of change
+	WeighCostsByFinalPrice bool `json:"weigh_costs_by_final_price"`
+}
+
 type BlueprintChange struct {
 	ChangeType  ChangeType  `json:"change_type"`
 	Category    string      `json:"category"` // room, opening, fixture, measurement, material
 	Description string      `json:"description"`
 	OldValue    interface{} `json:"old_value,omitempty"`
 	NewValue    interface{} `json:"new_value,omitempty"`
-	Impact      *string     `json:"impact,omitempty"` // High, Medium, Low
+	Impact      *ChangeImpact `json:"impact,omitempty"`
 }
 
 type BlueprintComparison struct {
@@ -404,6 +1525,28 @@ type BlueprintComparison struct {
 	ToVersion   int                `json:"to_version"`
 	Changes     []BlueprintChange  `json:"changes"`
 	Summary     ComparisonSummary  `json:"summary"`
+	NetAreaDelta float64           `json:"net_area_delta"` // Change in total room area (SF)
+	ParseErrors []ComparisonParseError `json:"parse_errors,omitempty"` // Set when only file metadata could be compared
+	ImpactThresholds ImpactThresholds  `json:"impact_thresholds"` // Thresholds the impact labels were decided by
+}
+
+// ComparisonParseError identifies a revision whose analysis could not be read
+type ComparisonParseError struct {
+	Version int    `json:"version"`
+	Side    string `json:"side"` // from or to
+	Error   string `json:"error"`
+}
+
+// Digest returns the compact form of the comparison
+func (c *BlueprintComparison) Digest() *ComparisonDigest {
+	delta := c.NetAreaDelta
+	return &ComparisonDigest{
+		FromVersion:  c.FromVersion,
+		ToVersion:    c.ToVersion,
+		Summary:      c.Summary,
+		NetAreaDelta: &delta,
+		ParseErrors:  c.ParseErrors,
+	}
 }
 
 type BidChange struct {
@@ -413,7 +1556,7 @@ type BidChange struct {
 	Description string      `json:"description"`
 	OldValue    interface{} `json:"old_value,omitempty"`
 	NewValue    interface{} `json:"new_value,omitempty"`
-	Impact      *string     `json:"impact,omitempty"` // High, Medium, Low
+	Impact      *ChangeImpact `json:"impact,omitempty"`
 }
 
 type BidComparison struct {
@@ -421,6 +1564,23 @@ type BidComparison struct {
 	ToVersion   int               `json:"to_version"`
 	Changes     []BidChange       `json:"changes"`
 	Summary     ComparisonSummary `json:"summary"`
+	NetCostDelta float64          `json:"net_cost_delta"` // Change in final price
+	// BlueprintSetChanged means the revisions were priced from different
+	// blueprints, so cost changes may reflect scope rather than pricing
+	BlueprintSetChanged bool `json:"blueprint_set_changed,omitempty"`
+	// ImpactThresholds are the thresholds the impact labels were decided by
+	ImpactThresholds ImpactThresholds `json:"impact_thresholds"`
+}
+
+// Digest returns the compact form of the comparison
+func (c *BidComparison) Digest() *ComparisonDigest {
+	delta := c.NetCostDelta
+	return &ComparisonDigest{
+		FromVersion:  c.FromVersion,
+		ToVersion:    c.ToVersion,
+		Summary:      c.Summary,
+		NetCostDelta: &delta,
+	}
 }
 
 type ComparisonSummary struct {
@@ -431,3 +1591,89 @@ type ComparisonSummary struct {
 	HighImpactCount  int            `json:"high_impact_count"`
 	ChangesByCategory map[string]int `json:"changes_by_category"`
 }
+
+// ComparisonDigest is a comparison without its change list. It is returned for
+// ?summary=true and is the form stored in revision changes_summary columns.
+type ComparisonDigest struct {
+	FromVersion  int               `json:"from_version"`
+	ToVersion    int               `json:"to_version"`
+	Summary      ComparisonSummary `json:"summary"`
+	NetCostDelta *float64          `json:"net_cost_delta,omitempty"`
+	NetAreaDelta *float64          `json:"net_area_delta,omitempty"`
+	ParseErrors  []ComparisonParseError `json:"parse_errors,omitempty"`
+	Note         string            `json:"note,omitempty"` // e.g. "Restored from version 2"
+}
+
+// BulkItemStatus is the outcome of one item in a bulk operation
+type BulkItemStatus string
+
+const (
+	BulkItemSucceeded BulkItemStatus = "succeeded"
+	BulkItemFailed    BulkItemStatus = "failed"
+	BulkItemSkipped   BulkItemStatus = "skipped" // Nothing to do, e.g. already analyzed
+)
+
+// BulkItemResult is the outcome of one item in a bulk operation. Index is its
+// position in the request (or in the set the operation worked through) and
+// Key identifies it, e.g. a blueprint ID.
+type BulkItemResult struct {
+	Index     int            `json:"index"`
+	Key       string         `json:"key"`
+	Status    BulkItemStatus `json:"status"`
+	ErrorCode string         `json:"error_code,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Result    interface{}    `json:"result,omitempty"` // Per-item payload on success
+}
+
+// ListPage is the response envelope of paged list endpoints: one page of
+// items and the number of items matching across all pages
+type ListPage[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// BulkResult is the response envelope shared by endpoints that act on many
+// items and can partly fail. Build it with Succeed, Fail and Skip so the
+// counts always match the item statuses.
+type BulkResult struct {
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Skipped   int              `json:"skipped"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+// NewBulkResult creates an empty result whose items encode as [] rather than null
+func NewBulkResult() BulkResult {
+	return BulkResult{Items: []BulkItemResult{}}
+}
+
+// Succeed records a successful item with an optional payload
+func (b *BulkResult) Succeed(index int, key string, result interface{}) {
+	b.add(BulkItemResult{Index: index, Key: key, Status: BulkItemSucceeded, Result: result})
+}
+
+// Fail records a failed item with a machine-readable code and a message
+func (b *BulkResult) Fail(index int, key, code, message string) {
+	b.add(BulkItemResult{Index: index, Key: key, Status: BulkItemFailed, ErrorCode: code, Message: message})
+}
+
+// Skip records an item that needed no work, with the reason as its code
+func (b *BulkResult) Skip(index int, key, code, message string) {
+	b.add(BulkItemResult{Index: index, Key: key, Status: BulkItemSkipped, ErrorCode: code, Message: message})
+}
+
+func (b *BulkResult) add(item BulkItemResult) {
+	b.Items = append(b.Items, item)
+	b.Total++
+	switch item.Status {
+	case BulkItemSucceeded:
+		b.Succeeded++
+	case BulkItemFailed:
+		b.Failed++
+	case BulkItemSkipped:
+		b.Skipped++
+	}
+}
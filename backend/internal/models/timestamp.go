@@ -0,0 +1,72 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// TimestampFormat is RFC3339 with millisecond precision. Timestamps are
+// always rendered in UTC, so the offset is always "Z".
+const TimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// Timestamp is a time.Time that marshals to JSON as RFC3339 UTC with
+// millisecond precision regardless of the server's zone or the precision the
+// database driver returned. It scans from and writes to timestamp columns.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps t, converted to UTC
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t.UTC()}
+}
+
+// Now returns the current time as a Timestamp
+func Now() Timestamp {
+	return NewTimestamp(time.Now())
+}
+
+// NewTimestampPtr wraps t, returning nil for nil
+func NewTimestampPtr(t *time.Time) *Timestamp {
+	if t == nil {
+		return nil
+	}
+	ts := NewTimestamp(*t)
+	return &ts
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.Time.UTC().Format(TimestampFormat) + `"`), nil
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	parsed, err := time.Parse(`"`+time.RFC3339Nano+`"`, string(data))
+	if err != nil {
+		return err
+	}
+	t.Time = parsed.UTC()
+	return nil
+}
+
+// Scan implements sql.Scanner, converting scanned times to UTC
+func (t *Timestamp) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		t.Time = time.Time{}
+	case time.Time:
+		t.Time = v.UTC()
+	default:
+		return fmt.Errorf("cannot scan %T into Timestamp", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer
+func (t Timestamp) Value() (driver.Value, error) {
+	return t.Time.UTC(), nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamp_MarshalJSON(t *testing.T) {
+	zone := time.FixedZone("PST", -8*60*60)
+	tests := []struct {
+		name string
+		in   time.Time
+		want string
+	}{
+		{"local zone converted to UTC", time.Date(2024, 1, 2, 16, 4, 5, 0, zone), `"2024-01-03T00:04:05.000Z"`},
+		{"sub-millisecond precision truncated", time.Date(2024, 1, 2, 3, 4, 5, 987654321, time.UTC), `"2024-01-02T03:04:05.987Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(Timestamp{Time: tt.in})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"2024-01-02T10:04:05+02:00"`), &ts); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if ts.Location() != time.UTC || ts.Hour() != 8 {
+		t.Errorf("Unmarshal() = %v, want 08:04:05 UTC", ts.Time)
+	}
+
+	var ptr *Timestamp
+	if err := json.Unmarshal([]byte(`null`), &ptr); err != nil || ptr != nil {
+		t.Errorf("Unmarshal(null) = %v, %v; want nil", ptr, err)
+	}
+}
+
+func TestTimestamp_Scan(t *testing.T) {
+	zone := time.FixedZone("JST", 9*60*60)
+	var ts Timestamp
+	if err := ts.Scan(time.Date(2024, 1, 2, 9, 0, 0, 0, zone)); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if ts.Location() != time.UTC || ts.Hour() != 0 {
+		t.Errorf("Scan() = %v, want midnight UTC", ts.Time)
+	}
+
+	if err := ts.Scan(nil); err != nil || !ts.IsZero() {
+		t.Errorf("Scan(nil) = %v, %v; want zero time", ts.Time, err)
+	}
+	if err := ts.Scan("2024-01-02"); err == nil {
+		t.Error("Scan() expected an error for a string source")
+	}
+}
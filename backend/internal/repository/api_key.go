@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// ErrAPIKeyNotFound is returned when an API key does not exist or belongs to
+// another user
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scope, expires_at, revoked_at, last_used_at, request_count, created_at`
+
+type APIKeyRepository struct {
+	db *Database
+}
+
+func NewAPIKeyRepository(db *Database) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scope,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.LastUsedAt,
+		&key.RequestCount,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Create stores a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `INSERT INTO api_keys (` + apiKeyColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		key.ID,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scope,
+		key.ExpiresAt,
+		key.RevokedAt,
+		key.LastUsedAt,
+		key.RequestCount,
+		key.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetByPrefix returns the API key with the given prefix, revoked or not
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+	key, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, prefix))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return key, nil
+}
+
+// ListByUser returns a user's API keys, newest first, including revoked ones
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke marks a user's API key revoked at the given time. Revoking an
+// already revoked key keeps the original time.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, userID uuid.UUID, at time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $3) WHERE id = $1 AND user_id = $2`
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID, models.NewTimestamp(at))
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// RecordUsage counts a request made with a key and sets its last use
+func (r *APIKeyRepository) RecordUsage(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $2, request_count = request_count + 1 WHERE id = $1`
+	if _, err := r.db.Pool.Exec(ctx, query, id, models.NewTimestamp(at)); err != nil {
+		return fmt.Errorf("failed to record api key usage: %w", err)
+	}
+	return nil
+}
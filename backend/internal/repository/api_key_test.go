@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestAPIKeyRepository_UsageAndRevocation(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	if _, err := db.Pool.Exec(ctx,
+		`INSERT INTO users (id, email, password_hash) VALUES ($1, $2, 'x')`,
+		userID, userID.String()+"@example.com"); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	key := &models.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      "Dashboard",
+		Prefix:    "fom_" + uuid.NewString()[:8],
+		KeyHash:   "hash",
+		Scope:     models.APIKeyScopeReadOnly,
+		CreatedAt: models.Now(),
+	}
+	if err := repo.Create(ctx, key); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	usedAt := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := repo.RecordUsage(ctx, key.ID, usedAt); err != nil {
+			t.Fatalf("RecordUsage failed: %v", err)
+		}
+	}
+	got, err := repo.GetByPrefix(ctx, key.Prefix)
+	if err != nil {
+		t.Fatalf("GetByPrefix failed: %v", err)
+	}
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(usedAt) || got.RequestCount != 2 {
+		t.Errorf("last_used_at = %v, request_count = %d; want %v and 2", got.LastUsedAt, got.RequestCount, usedAt)
+	}
+
+	if err := repo.Revoke(ctx, key.ID, uuid.New(), usedAt); err != ErrAPIKeyNotFound {
+		t.Errorf("Revoke() by another user = %v, want ErrAPIKeyNotFound", err)
+	}
+	if err := repo.Revoke(ctx, key.ID, userID, usedAt); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	keys, err := repo.ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListByUser failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("expected the revoked key listed with revoked_at, got %+v", keys)
+	}
+}
@@ -2,12 +2,18 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
+// ErrBidVersionChanged is returned when a bid is no longer at the version a
+// change was based on
+var ErrBidVersionChanged = errors.New("bid version changed")
+
 type BidRepository struct {
 	db *Database
 }
@@ -16,17 +22,14 @@ func NewBidRepository(db *Database) *BidRepository {
 	return &BidRepository{db: db}
 }
 
-func (r *BidRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Bid, error) {
-	query := `
-		SELECT id, project_id, job_id, name, total_cost, labor_cost, material_cost, 
-		       markup_percentage, final_price, status, bid_data, pdf_url, pdf_s3_key, 
-		       version, parent_bid_id, is_latest, created_at, updated_at
-		FROM bids
-		WHERE id = $1
-	`
+const bidColumns = `id, project_id, job_id, name, total_cost, labor_cost, material_cost, 
+		       markup_percentage, final_price, status, bid_data, pdf_url, pdf_s3_key, pdf_hash,
+		       version, parent_bid_id, is_latest, generation_model, costs_by_trade, blueprint_ids,
+		       status_changed_at, status_note, created_at, updated_at`
 
+func scanBid(row pgx.Row) (*models.Bid, error) {
 	var bid models.Bid
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&bid.ID,
 		&bid.ProjectID,
 		&bid.JobID,
@@ -40,25 +43,51 @@ func (r *BidRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Bid,
 		&bid.BidData,
 		&bid.PDFURL,
 		&bid.PDFS3Key,
+		&bid.PDFHash,
 		&bid.Version,
 		&bid.ParentBidID,
 		&bid.IsLatest,
+		&bid.GenerationModel,
+		&bid.CostsByTrade,
+		&bid.BlueprintIDs,
+		&bid.StatusChangedAt,
+		&bid.StatusNote,
 		&bid.CreatedAt,
 		&bid.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &bid, nil
+}
+
+// blueprintIDsParam writes a missing blueprint list as an empty array, since
+// the column is NOT NULL
+func blueprintIDsParam(ids []uuid.UUID) []uuid.UUID {
+	if ids == nil {
+		return []uuid.UUID{}
+	}
+	return ids
+}
 
+func (r *BidRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Bid, error) {
+	query := `
+		SELECT ` + bidColumns + `
+		FROM bids
+		WHERE id = $1
+	`
+
+	bid, err := scanBid(r.db.Pool.QueryRow(ctx, query, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bid: %w", err)
 	}
 
-	return &bid, nil
+	return bid, nil
 }
 
 func (r *BidRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]*models.Bid, error) {
 	query := `
-		SELECT id, project_id, job_id, name, total_cost, labor_cost, material_cost, 
-		       markup_percentage, final_price, status, bid_data, pdf_url, pdf_s3_key, 
-		       version, parent_bid_id, is_latest, created_at, updated_at
+		SELECT ` + bidColumns + `
 		FROM bids
 		WHERE project_id = $1
 		ORDER BY created_at DESC
@@ -72,42 +101,117 @@ func (r *BidRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID)
 
 	var bids []*models.Bid
 	for rows.Next() {
-		var bid models.Bid
-		err := rows.Scan(
-			&bid.ID,
-			&bid.ProjectID,
-			&bid.JobID,
-			&bid.Name,
-			&bid.TotalCost,
-			&bid.LaborCost,
-			&bid.MaterialCost,
-			&bid.MarkupPercentage,
-			&bid.FinalPrice,
-			&bid.Status,
-			&bid.BidData,
-			&bid.PDFURL,
-			&bid.PDFS3Key,
-			&bid.Version,
-			&bid.ParentBidID,
-			&bid.IsLatest,
-			&bid.CreatedAt,
-			&bid.UpdatedAt,
-		)
+		bid, err := scanBid(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan bid: %w", err)
 		}
-		bids = append(bids, &bid)
+		bids = append(bids, bid)
 	}
 
 	return bids, nil
 }
 
+// GetIDsByBlueprint returns the IDs of the bids that price a blueprint or
+// were generated from one of its analysis jobs, oldest first
+func (r *BidRepository) GetIDsByBlueprint(ctx context.Context, blueprintID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT id
+		FROM bids
+		WHERE $1 = ANY(blueprint_ids)
+		   OR job_id IN (SELECT id FROM jobs WHERE blueprint_id = $1)
+		ORDER BY created_at ASC, id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bids by blueprint: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan bid id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// bidSortOrders are the ORDER BY clauses of each bid list sort. Bids without
+// a price sort last either way.
+var bidSortOrders = map[models.BidSort]string{
+	models.BidSortCreatedDesc:    "created_at DESC, id",
+	models.BidSortCreatedAsc:     "created_at ASC, id",
+	models.BidSortFinalPriceAsc:  "final_price ASC NULLS LAST, created_at DESC, id",
+	models.BidSortFinalPriceDesc: "final_price DESC NULLS LAST, created_at DESC, id",
+}
+
+// bidListClause builds the WHERE clause for a bid list, numbering
+// placeholders from $1
+func bidListClause(filter models.BidListFilter) (string, []interface{}) {
+	clause := "project_id = $1"
+	args := []interface{}{filter.ProjectID}
+	if filter.BlueprintID != nil {
+		args = append(args, *filter.BlueprintID)
+		clause += fmt.Sprintf(" AND $%d = ANY(blueprint_ids)", len(args))
+	}
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		clause += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	return clause, args
+}
+
+// List returns one page of a project's bids matching the filter, and the
+// number of bids matching across all pages
+func (r *BidRepository) List(ctx context.Context, filter models.BidListFilter) ([]*models.Bid, int, error) {
+	clause, args := bidListClause(filter)
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM bids WHERE "+clause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bids: %w", err)
+	}
+
+	order, ok := bidSortOrders[filter.Sort]
+	if !ok {
+		order = bidSortOrders[models.BidSortCreatedDesc]
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM bids
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, bidColumns, clause, order, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Pool.Query(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bids: %w", err)
+	}
+	defer rows.Close()
+
+	bids := []*models.Bid{}
+	for rows.Next() {
+		bid, err := scanBid(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan bid: %w", err)
+		}
+		bids = append(bids, bid)
+	}
+
+	return bids, total, rows.Err()
+}
+
 func (r *BidRepository) Create(ctx context.Context, bid *models.Bid) error {
 	query := `
 		INSERT INTO bids (id, project_id, job_id, name, total_cost, labor_cost, material_cost, 
-		                  markup_percentage, final_price, status, bid_data, pdf_url, pdf_s3_key, 
-		                  version, parent_bid_id, is_latest, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		                  markup_percentage, final_price, status, bid_data, pdf_url, pdf_s3_key, pdf_hash,
+		                  version, parent_bid_id, is_latest, generation_model, costs_by_trade, blueprint_ids,
+		                  status_changed_at, status_note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query,
@@ -124,9 +228,15 @@ func (r *BidRepository) Create(ctx context.Context, bid *models.Bid) error {
 		bid.BidData,
 		bid.PDFURL,
 		bid.PDFS3Key,
+		bid.PDFHash,
 		bid.Version,
 		bid.ParentBidID,
 		bid.IsLatest,
+		bid.GenerationModel,
+		bid.CostsByTrade,
+		blueprintIDsParam(bid.BlueprintIDs),
+		bid.StatusChangedAt,
+		bid.StatusNote,
 		bid.CreatedAt,
 		bid.UpdatedAt,
 	)
@@ -144,11 +254,12 @@ func (r *BidRepository) Update(ctx context.Context, bid *models.Bid) error {
 		SET name = $1, total_cost = $2, labor_cost = $3, material_cost = $4, 
 		    markup_percentage = $5, final_price = $6, status = $7, bid_data = $8, 
 		    pdf_url = $9, pdf_s3_key = $10, version = $11, parent_bid_id = $12, 
-		    is_latest = $13, updated_at = $14
-		WHERE id = $15
+		    is_latest = $13, generation_model = $14, updated_at = $15, pdf_hash = $16,
+		    costs_by_trade = $17, blueprint_ids = $18, status_changed_at = $19, status_note = $20
+		WHERE id = $21
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		bid.Name,
 		bid.TotalCost,
 		bid.LaborCost,
@@ -162,7 +273,13 @@ func (r *BidRepository) Update(ctx context.Context, bid *models.Bid) error {
 		bid.Version,
 		bid.ParentBidID,
 		bid.IsLatest,
+		bid.GenerationModel,
 		bid.UpdatedAt,
+		bid.PDFHash,
+		bid.CostsByTrade,
+		blueprintIDsParam(bid.BlueprintIDs),
+		bid.StatusChangedAt,
+		bid.StatusNote,
 		bid.ID,
 	)
 
@@ -172,3 +289,78 @@ func (r *BidRepository) Update(ctx context.Context, bid *models.Bid) error {
 
 	return nil
 }
+
+// LockVersion locks a bid for the rest of the transaction started with InTx
+// when it is still at version, and returns ErrBidVersionChanged otherwise.
+// A concurrent caller at the same version waits for the first to commit and
+// then finds the version moved.
+func (r *BidRepository) LockVersion(ctx context.Context, id uuid.UUID, version int) error {
+	query := `UPDATE bids SET updated_at = NOW() WHERE id = $1 AND version = $2`
+
+	tag, err := r.db.conn(ctx).Exec(ctx, query, id, version)
+	if err != nil {
+		return fmt.Errorf("failed to lock bid: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrBidVersionChanged
+	}
+	return nil
+}
+
+// UpdatePDF stores a bid's PDF fields. The update is skipped when the bid's
+// version no longer matches, so a PDF rendered from data edited since it was
+// loaded is not stored over the edit.
+func (r *BidRepository) UpdatePDF(ctx context.Context, bid *models.Bid) error {
+	query := `
+		UPDATE bids
+		SET pdf_url = $1, pdf_s3_key = $2, pdf_hash = $3, updated_at = $4
+		WHERE id = $5 AND version = $6
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, bid.PDFURL, bid.PDFS3Key, bid.PDFHash, bid.UpdatedAt, bid.ID, bid.Version)
+	if err != nil {
+		return fmt.Errorf("failed to update bid PDF: %w", err)
+	}
+
+	return nil
+}
+
+// GetMissingCostsByTrade returns up to limit bids with bid data but no
+// denormalized trade costs, for backfilling
+func (r *BidRepository) GetMissingCostsByTrade(ctx context.Context, limit int) ([]*models.Bid, error) {
+	query := `
+		SELECT ` + bidColumns + `
+		FROM bids
+		WHERE costs_by_trade IS NULL AND bid_data IS NOT NULL
+		ORDER BY created_at
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bids missing trade costs: %w", err)
+	}
+	defer rows.Close()
+
+	var bids []*models.Bid
+	for rows.Next() {
+		bid, err := scanBid(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bid: %w", err)
+		}
+		bids = append(bids, bid)
+	}
+
+	return bids, rows.Err()
+}
+
+// UpdateCostsByTrade sets a bid's denormalized trade costs
+func (r *BidRepository) UpdateCostsByTrade(ctx context.Context, id uuid.UUID, costs map[string]float64) error {
+	if costs == nil {
+		costs = map[string]float64{}
+	}
+	if _, err := r.db.Pool.Exec(ctx, `UPDATE bids SET costs_by_trade = $1 WHERE id = $2`, costs, id); err != nil {
+		return fmt.Errorf("failed to update bid trade costs: %w", err)
+	}
+	return nil
+}
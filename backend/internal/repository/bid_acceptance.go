@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// ErrBidAcceptanceNotFound is returned when a bid has no online acceptance
+var ErrBidAcceptanceNotFound = errors.New("bid acceptance not found")
+
+const bidAcceptanceColumns = `id, bid_id, share_id, signer_name, signer_email, ip_address, user_agent, accepted_at`
+
+type BidAcceptanceRepository struct {
+	db *Database
+}
+
+func NewBidAcceptanceRepository(db *Database) *BidAcceptanceRepository {
+	return &BidAcceptanceRepository{db: db}
+}
+
+func scanBidAcceptance(row pgx.Row) (*models.BidAcceptance, error) {
+	var acceptance models.BidAcceptance
+	err := row.Scan(
+		&acceptance.ID,
+		&acceptance.BidID,
+		&acceptance.ShareID,
+		&acceptance.SignerName,
+		&acceptance.SignerEmail,
+		&acceptance.IPAddress,
+		&acceptance.UserAgent,
+		&acceptance.AcceptedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &acceptance, nil
+}
+
+// Create stores a client's acceptance of a shared bid. It takes part in a
+// transaction started with InTx.
+func (r *BidAcceptanceRepository) Create(ctx context.Context, acceptance *models.BidAcceptance) error {
+	query := `INSERT INTO bid_acceptances (` + bidAcceptanceColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.db.conn(ctx).Exec(ctx, query,
+		acceptance.ID,
+		acceptance.BidID,
+		acceptance.ShareID,
+		acceptance.SignerName,
+		acceptance.SignerEmail,
+		acceptance.IPAddress,
+		acceptance.UserAgent,
+		acceptance.AcceptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bid acceptance: %w", err)
+	}
+	return nil
+}
+
+// GetLatestByBidID returns the most recent online acceptance of a bid
+func (r *BidAcceptanceRepository) GetLatestByBidID(ctx context.Context, bidID uuid.UUID) (*models.BidAcceptance, error) {
+	query := `SELECT ` + bidAcceptanceColumns + ` FROM bid_acceptances WHERE bid_id = $1 ORDER BY accepted_at DESC LIMIT 1`
+	acceptance, err := scanBidAcceptance(r.db.Pool.QueryRow(ctx, query, bidID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBidAcceptanceNotFound
+		}
+		return nil, fmt.Errorf("failed to get bid acceptance: %w", err)
+	}
+	return acceptance, nil
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// analyticsScope selects the latest version of each bid on the user's
+// projects created in [$2, $3), tagged with the start of its period
+const analyticsScope = `
+	WITH scoped AS (
+		SELECT b.status, b.final_price, b.markup_percentage, b.costs_by_trade,
+		       date_trunc('%s', b.created_at) AS period
+		FROM bids b
+		JOIN projects p ON p.id = b.project_id
+		WHERE p.user_id = $1 AND b.created_at >= $2 AND b.created_at < $3 AND b.is_latest
+	)
+`
+
+// analyticsPeriods maps each accepted group_by to its date_trunc field
+var analyticsPeriods = map[string]string{
+	models.AnalyticsGroupByWeek:    "week",
+	models.AnalyticsGroupByMonth:   "month",
+	models.AnalyticsGroupByQuarter: "quarter",
+	models.AnalyticsGroupByYear:    "year",
+}
+
+// statusAggregate is the sums behind one period's bids in one status. Sums
+// and counts rather than averages are read so periods roll up into totals.
+type statusAggregate struct {
+	period          time.Time
+	status          models.BidStatus
+	bids            int
+	finalPriceSum   float64
+	finalPriceCount int
+	markupSum       float64
+	markupCount     int
+	tradeCostBids   int // Bids with trade costs recorded
+}
+
+// tradeAggregate is one trade's cost and cost share summed over a period's bids
+type tradeAggregate struct {
+	period   time.Time
+	trade    string
+	costSum  float64
+	shareSum float64
+}
+
+// GetCompanyAnalytics aggregates bid counts by status, final prices, markup,
+// win rate and trade cost mix for the user's bids in the filter's range
+func (r *BidRepository) GetCompanyAnalytics(ctx context.Context, filter models.CompanyAnalyticsFilter) (*models.CompanyAnalytics, error) {
+	field, ok := analyticsPeriods[filter.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported analytics grouping %q", filter.GroupBy)
+	}
+	scope := fmt.Sprintf(analyticsScope, field)
+
+	statusQuery := scope + `
+		SELECT period, status, COUNT(*),
+		       COALESCE(SUM(final_price), 0), COUNT(final_price),
+		       COALESCE(SUM(markup_percentage), 0), COUNT(markup_percentage),
+		       COUNT(costs_by_trade)
+		FROM scoped
+		GROUP BY period, status
+	`
+	rows, err := r.db.Pool.Query(ctx, statusQuery, filter.UserID, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate bids: %w", err)
+	}
+	var statuses []statusAggregate
+	for rows.Next() {
+		var row statusAggregate
+		if err := rows.Scan(&row.period, &row.status, &row.bids, &row.finalPriceSum, &row.finalPriceCount,
+			&row.markupSum, &row.markupCount, &row.tradeCostBids); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan bid aggregate: %w", err)
+		}
+		statuses = append(statuses, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to aggregate bids: %w", err)
+	}
+
+	// Each trade's share is of the bid's summed trade costs
+	tradeQuery := scope + `
+		SELECT s.period, t.key,
+		       SUM(t.value::float8),
+		       COALESCE(SUM(t.value::float8 / NULLIF(bid_total.total, 0)), 0)
+		FROM scoped s
+		CROSS JOIN LATERAL jsonb_each_text(s.costs_by_trade) AS t
+		CROSS JOIN LATERAL (
+			SELECT SUM(value::float8) AS total FROM jsonb_each_text(s.costs_by_trade)
+		) AS bid_total
+		GROUP BY s.period, t.key
+	`
+	rows, err = r.db.Pool.Query(ctx, tradeQuery, filter.UserID, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate bid trade costs: %w", err)
+	}
+	var tradeCosts []tradeAggregate
+	for rows.Next() {
+		var row tradeAggregate
+		if err := rows.Scan(&row.period, &row.trade, &row.costSum, &row.shareSum); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan trade aggregate: %w", err)
+		}
+		tradeCosts = append(tradeCosts, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to aggregate bid trade costs: %w", err)
+	}
+
+	return buildCompanyAnalytics(filter, statuses, tradeCosts), nil
+}
+
+// aggregateSums accumulates status and trade rows for one period or the range
+type aggregateSums struct {
+	statuses []statusAggregate
+	trades   map[string]*tradeAggregate
+}
+
+func (a *aggregateSums) addTrade(row tradeAggregate) {
+	if a.trades == nil {
+		a.trades = make(map[string]*tradeAggregate)
+	}
+	sum, ok := a.trades[row.trade]
+	if !ok {
+		sum = &tradeAggregate{trade: row.trade}
+		a.trades[row.trade] = sum
+	}
+	sum.costSum += row.costSum
+	sum.shareSum += row.shareSum
+}
+
+// buildCompanyAnalytics turns the summed rows into per-period and range
+// aggregates, in period order
+func buildCompanyAnalytics(filter models.CompanyAnalyticsFilter, statuses []statusAggregate, tradeCosts []tradeAggregate) *models.CompanyAnalytics {
+	periods := make(map[time.Time]*aggregateSums)
+	sumsFor := func(period time.Time) *aggregateSums {
+		if sums, ok := periods[period]; ok {
+			return sums
+		}
+		sums := &aggregateSums{}
+		periods[period] = sums
+		return sums
+	}
+
+	var totals aggregateSums
+	for _, row := range statuses {
+		sums := sumsFor(row.period)
+		sums.statuses = append(sums.statuses, row)
+		totals.statuses = append(totals.statuses, row)
+	}
+	for _, row := range tradeCosts {
+		sumsFor(row.period).addTrade(row)
+		totals.addTrade(row)
+	}
+
+	starts := make([]time.Time, 0, len(periods))
+	for start := range periods {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	analytics := &models.CompanyAnalytics{
+		From:    models.NewTimestamp(filter.From),
+		To:      models.NewTimestamp(filter.To),
+		GroupBy: filter.GroupBy,
+		Totals:  totals.aggregate(),
+		Periods: make([]models.BidAggregate, 0, len(starts)),
+	}
+	for _, start := range starts {
+		aggregate := periods[start].aggregate()
+		periodStart := models.NewTimestamp(start)
+		aggregate.PeriodStart = &periodStart
+		analytics.Periods = append(analytics.Periods, aggregate)
+	}
+	return analytics
+}
+
+func (a *aggregateSums) aggregate() models.BidAggregate {
+	aggregate := models.BidAggregate{
+		CountsByStatus: make(map[models.BidStatus]int),
+		TradeMix:       []models.TradeCostShare{},
+	}
+
+	var finalPriceCount, markupCount, tradeCostBids int
+	var markupSum float64
+	for _, row := range a.statuses {
+		aggregate.BidCount += row.bids
+		aggregate.CountsByStatus[row.status] += row.bids
+		aggregate.TotalFinalPrice += row.finalPriceSum
+		finalPriceCount += row.finalPriceCount
+		markupSum += row.markupSum
+		markupCount += row.markupCount
+		tradeCostBids += row.tradeCostBids
+	}
+
+	aggregate.TotalFinalPrice = roundTo(aggregate.TotalFinalPrice, 2)
+	if finalPriceCount > 0 {
+		aggregate.AverageFinalPrice = roundTo(aggregate.TotalFinalPrice/float64(finalPriceCount), 2)
+	}
+	if markupCount > 0 {
+		aggregate.AverageMarkupPercentage = roundTo(markupSum/float64(markupCount), 2)
+	}
+
+	accepted := aggregate.CountsByStatus[models.BidStatusAccepted]
+	if decided := accepted + aggregate.CountsByStatus[models.BidStatusRejected]; decided > 0 {
+		winRate := roundTo(float64(accepted)/float64(decided), 4)
+		aggregate.WinRate = &winRate
+	}
+
+	if tradeCostBids > 0 {
+		for _, trade := range a.trades {
+			aggregate.TradeMix = append(aggregate.TradeMix, models.TradeCostShare{
+				Trade:        trade.trade,
+				AverageCost:  roundTo(trade.costSum/float64(tradeCostBids), 2),
+				AverageShare: roundTo(trade.shareSum/float64(tradeCostBids), 4),
+			})
+		}
+		sort.Slice(aggregate.TradeMix, func(i, j int) bool {
+			if aggregate.TradeMix[i].AverageCost != aggregate.TradeMix[j].AverageCost {
+				return aggregate.TradeMix[i].AverageCost > aggregate.TradeMix[j].AverageCost
+			}
+			return aggregate.TradeMix[i].Trade < aggregate.TradeMix[j].Trade
+		})
+	}
+	return aggregate
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+var (
+	january  = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	february = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// checkSeededAnalytics asserts the aggregates of an accepted and a rejected
+// bid in January and a draft in February
+func checkSeededAnalytics(t *testing.T, analytics *models.CompanyAnalytics) {
+	t.Helper()
+
+	half := 0.5
+	wantTotals := models.BidAggregate{
+		BidCount:                3,
+		CountsByStatus:          map[models.BidStatus]int{models.BidStatusAccepted: 1, models.BidStatusRejected: 1, models.BidStatusDraft: 1},
+		TotalFinalPrice:         6000,
+		AverageFinalPrice:       2000,
+		AverageMarkupPercentage: 15,
+		WinRate:                 &half,
+		TradeMix: []models.TradeCostShare{
+			{Trade: "drywall", AverageCost: 533.33, AverageShare: 0.5833},
+			{Trade: "electrical", AverageCost: 166.67, AverageShare: 0.3333},
+			{Trade: "painting", AverageCost: 66.67, AverageShare: 0.0833},
+		},
+	}
+	if !reflect.DeepEqual(analytics.Totals, wantTotals) {
+		t.Errorf("Totals = %+v, want %+v", analytics.Totals, wantTotals)
+	}
+
+	if len(analytics.Periods) != 2 {
+		t.Fatalf("expected January and February periods, got %+v", analytics.Periods)
+	}
+	jan, feb := analytics.Periods[0], analytics.Periods[1]
+	if jan.PeriodStart == nil || !jan.PeriodStart.Equal(january) || feb.PeriodStart == nil || !feb.PeriodStart.Equal(february) {
+		t.Errorf("period starts = %v, %v", jan.PeriodStart, feb.PeriodStart)
+	}
+	wantJanMix := []models.TradeCostShare{
+		{Trade: "drywall", AverageCost: 800, AverageShare: 0.875},
+		{Trade: "painting", AverageCost: 100, AverageShare: 0.125},
+	}
+	if jan.BidCount != 2 || jan.TotalFinalPrice != 4000 || jan.AverageMarkupPercentage != 15 || jan.WinRate == nil || *jan.WinRate != 0.5 || !reflect.DeepEqual(jan.TradeMix, wantJanMix) {
+		t.Errorf("January = %+v", jan)
+	}
+	if feb.BidCount != 1 || feb.CountsByStatus[models.BidStatusDraft] != 1 || feb.AverageFinalPrice != 2000 || feb.WinRate != nil {
+		t.Errorf("February = %+v, want one undecided draft", feb)
+	}
+	if len(feb.TradeMix) != 1 || feb.TradeMix[0] != (models.TradeCostShare{Trade: "electrical", AverageCost: 500, AverageShare: 1}) {
+		t.Errorf("February trade mix = %+v", feb.TradeMix)
+	}
+}
+
+func TestBuildCompanyAnalytics(t *testing.T) {
+	filter := models.CompanyAnalyticsFilter{From: january, To: january.AddDate(0, 2, 0), GroupBy: models.AnalyticsGroupByMonth}
+	statuses := []statusAggregate{
+		{period: february, status: models.BidStatusDraft, bids: 1, finalPriceSum: 2000, finalPriceCount: 1, markupSum: 15, markupCount: 1, tradeCostBids: 1},
+		{period: january, status: models.BidStatusAccepted, bids: 1, finalPriceSum: 1000, finalPriceCount: 1, markupSum: 20, markupCount: 1, tradeCostBids: 1},
+		{period: january, status: models.BidStatusRejected, bids: 1, finalPriceSum: 3000, finalPriceCount: 1, markupSum: 10, markupCount: 1, tradeCostBids: 1},
+	}
+	tradeCosts := []tradeAggregate{
+		{period: january, trade: "drywall", costSum: 1600, shareSum: 1.75},
+		{period: january, trade: "painting", costSum: 200, shareSum: 0.25},
+		{period: february, trade: "electrical", costSum: 500, shareSum: 1},
+	}
+
+	checkSeededAnalytics(t, buildCompanyAnalytics(filter, statuses, tradeCosts))
+}
+
+func TestBuildCompanyAnalytics_EmptyRange(t *testing.T) {
+	analytics := buildCompanyAnalytics(models.CompanyAnalyticsFilter{GroupBy: models.AnalyticsGroupByMonth}, nil, nil)
+
+	if analytics.Totals.BidCount != 0 || analytics.Totals.AverageFinalPrice != 0 || analytics.Totals.WinRate != nil {
+		t.Errorf("Totals = %+v, want zero counts and no win rate", analytics.Totals)
+	}
+	if analytics.Periods == nil || len(analytics.Periods) != 0 || analytics.Totals.TradeMix == nil {
+		t.Errorf("expected empty, non-nil periods and trade mix, got %+v", analytics)
+	}
+}
+
+func TestBidRepository_GetCompanyAnalytics(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewBidRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	project, err := NewProjectRepository(db).GetByID(ctx, projectID)
+	if err != nil {
+		t.Fatalf("failed to load project: %v", err)
+	}
+
+	seed := func(createdAt time.Time, status models.BidStatus, finalPrice, markup float64, costs map[string]float64, latest bool) {
+		bid := &models.Bid{
+			ID:               uuid.New(),
+			ProjectID:        projectID,
+			Status:           status,
+			FinalPrice:       &finalPrice,
+			MarkupPercentage: &markup,
+			CostsByTrade:     costs,
+			Version:          1,
+			IsLatest:         latest,
+			CreatedAt:        models.NewTimestamp(createdAt),
+			UpdatedAt:        models.NewTimestamp(createdAt),
+		}
+		if err := repo.Create(ctx, bid); err != nil {
+			t.Fatalf("failed to seed bid: %v", err)
+		}
+	}
+	seed(january.AddDate(0, 0, 4), models.BidStatusAccepted, 1000, 20, map[string]float64{"drywall": 600, "painting": 200}, true)
+	seed(january.AddDate(0, 0, 20), models.BidStatusRejected, 3000, 10, map[string]float64{"drywall": 1000}, true)
+	seed(february.AddDate(0, 0, 9), models.BidStatusDraft, 2000, 15, map[string]float64{"electrical": 500}, true)
+	// Superseded versions and bids outside the range are not counted
+	seed(january.AddDate(0, 0, 5), models.BidStatusAccepted, 9999, 50, nil, false)
+	seed(february.AddDate(0, 1, 0), models.BidStatusAccepted, 9999, 50, nil, true)
+
+	filter := models.CompanyAnalyticsFilter{UserID: project.UserID, From: january, To: january.AddDate(0, 2, 0), GroupBy: models.AnalyticsGroupByMonth}
+	analytics, err := repo.GetCompanyAnalytics(ctx, filter)
+	if err != nil {
+		t.Fatalf("GetCompanyAnalytics failed: %v", err)
+	}
+	checkSeededAnalytics(t, analytics)
+
+	filter.From, filter.To = january.AddDate(-1, 0, 0), january
+	empty, err := repo.GetCompanyAnalytics(ctx, filter)
+	if err != nil {
+		t.Fatalf("GetCompanyAnalytics failed: %v", err)
+	}
+	if empty.Totals.BidCount != 0 || len(empty.Periods) != 0 || empty.Totals.WinRate != nil {
+		t.Errorf("expected no bids in an empty range, got %+v", empty)
+	}
+
+	if _, err := repo.GetCompanyAnalytics(ctx, models.CompanyAnalyticsFilter{GroupBy: "decade"}); err == nil {
+		t.Error("expected an unsupported grouping to fail")
+	}
+}
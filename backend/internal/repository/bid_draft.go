@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type BidDraftRepository struct {
+	db *Database
+}
+
+func NewBidDraftRepository(db *Database) *BidDraftRepository {
+	return &BidDraftRepository{db: db}
+}
+
+// Save creates the user's draft of a bid or replaces its contents. A replaced
+// draft keeps its ID, and its creation time and base version unless it had
+// expired; the stored values are read back into draft.
+func (r *BidDraftRepository) Save(ctx context.Context, draft *models.BidDraft) error {
+	query := `
+		INSERT INTO bid_drafts (id, bid_id, user_id, base_version, bid_data, markup_percentage,
+		                        created_at, updated_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (bid_id, user_id) DO UPDATE
+		SET base_version = CASE WHEN bid_drafts.expires_at <= EXCLUDED.updated_at
+		                        THEN EXCLUDED.base_version ELSE bid_drafts.base_version END,
+		    created_at = CASE WHEN bid_drafts.expires_at <= EXCLUDED.updated_at
+		                      THEN EXCLUDED.created_at ELSE bid_drafts.created_at END,
+		    bid_data = EXCLUDED.bid_data,
+		    markup_percentage = EXCLUDED.markup_percentage,
+		    updated_at = EXCLUDED.updated_at,
+		    expires_at = EXCLUDED.expires_at
+		RETURNING id, base_version, created_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		draft.ID,
+		draft.BidID,
+		draft.UserID,
+		draft.BaseVersion,
+		draft.BidData,
+		draft.MarkupPercentage,
+		draft.CreatedAt,
+		draft.UpdatedAt,
+		draft.ExpiresAt,
+	).Scan(&draft.ID, &draft.BaseVersion, &draft.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save bid draft: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the user's draft of a bid unless it expired before now
+func (r *BidDraftRepository) Get(ctx context.Context, bidID, userID uuid.UUID, now time.Time) (*models.BidDraft, error) {
+	query := `
+		SELECT id, bid_id, user_id, base_version, bid_data, markup_percentage,
+		       created_at, updated_at, expires_at
+		FROM bid_drafts
+		WHERE bid_id = $1 AND user_id = $2 AND expires_at > $3
+	`
+
+	var draft models.BidDraft
+	err := r.db.Pool.QueryRow(ctx, query, bidID, userID, models.NewTimestamp(now)).Scan(
+		&draft.ID,
+		&draft.BidID,
+		&draft.UserID,
+		&draft.BaseVersion,
+		&draft.BidData,
+		&draft.MarkupPercentage,
+		&draft.CreatedAt,
+		&draft.UpdatedAt,
+		&draft.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bid draft: %w", err)
+	}
+
+	return &draft, nil
+}
+
+// Delete discards the user's draft of a bid; a missing draft is not an error
+func (r *BidDraftRepository) Delete(ctx context.Context, bidID, userID uuid.UUID) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM bid_drafts WHERE bid_id = $1 AND user_id = $2`, bidID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete bid draft: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes drafts that expired at or before now and returns how
+// many were removed
+func (r *BidDraftRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM bid_drafts WHERE expires_at <= $1`, models.NewTimestamp(now))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired bid drafts: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestBidDraftRepository(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewBidDraftRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	project, err := NewProjectRepository(db).GetByID(ctx, projectID)
+	if err != nil {
+		t.Fatalf("failed to load project: %v", err)
+	}
+	bid := &models.Bid{ID: uuid.New(), ProjectID: projectID, Status: models.BidStatusDraft, Version: 3, IsLatest: true, CreatedAt: models.Now(), UpdatedAt: models.Now()}
+	if err := NewBidRepository(db).Create(ctx, bid); err != nil {
+		t.Fatalf("failed to seed bid: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	draft := &models.BidDraft{
+		ID:          uuid.New(),
+		BidID:       bid.ID,
+		UserID:      project.UserID,
+		BaseVersion: 3,
+		BidData:     `{"total_price": 100}`,
+		CreatedAt:   models.NewTimestamp(now),
+		UpdatedAt:   models.NewTimestamp(now),
+		ExpiresAt:   models.NewTimestamp(now.Add(time.Hour)),
+	}
+	if err := repo.Save(ctx, draft); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	firstID := draft.ID
+
+	// Saving again replaces the contents but keeps the base version
+	markup := 25.0
+	resave := &models.BidDraft{
+		ID:               uuid.New(),
+		BidID:            bid.ID,
+		UserID:           project.UserID,
+		BaseVersion:      4,
+		BidData:          `{"total_price": 200}`,
+		MarkupPercentage: &markup,
+		CreatedAt:        models.NewTimestamp(now),
+		UpdatedAt:        models.NewTimestamp(now),
+		ExpiresAt:        models.NewTimestamp(now.Add(time.Hour)),
+	}
+	if err := repo.Save(ctx, resave); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if resave.ID != firstID || resave.BaseVersion != 3 {
+		t.Errorf("resaved draft = %+v, want ID %s and base version 3 kept", resave, firstID)
+	}
+
+	got, err := repo.Get(ctx, bid.ID, project.UserID, now)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.MarkupPercentage == nil || *got.MarkupPercentage != 25 || got.BaseVersion != 3 {
+		t.Errorf("draft = %+v, want the resaved markup on base version 3", got)
+	}
+
+	if _, err := repo.Get(ctx, bid.ID, project.UserID, now.Add(2*time.Hour)); err == nil {
+		t.Error("expected an expired draft not to be returned")
+	}
+	removed, err := repo.DeleteExpired(ctx, now.Add(2*time.Hour))
+	if err != nil || removed != 1 {
+		t.Errorf("DeleteExpired = %d, %v; want 1 removed", removed, err)
+	}
+	if err := repo.Delete(ctx, bid.ID, project.UserID); err != nil {
+		t.Errorf("Delete of a missing draft failed: %v", err)
+	}
+}
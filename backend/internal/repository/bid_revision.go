@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
@@ -16,15 +17,46 @@ func NewBidRevisionRepository(db *Database) *BidRevisionRepository {
 	return &BidRevisionRepository{db: db}
 }
 
+const bidRevisionColumns = `id, bid_id, version, name, total_cost, labor_cost, material_cost, 
+		       markup_percentage, final_price, status, bid_data, generation_model, 
+		       changes_summary, reason, created_by, created_at, blueprint_ids`
+
+func scanBidRevision(row pgx.Row) (*models.BidRevision, error) {
+	var revision models.BidRevision
+	err := row.Scan(
+		&revision.ID,
+		&revision.BidID,
+		&revision.Version,
+		&revision.Name,
+		&revision.TotalCost,
+		&revision.LaborCost,
+		&revision.MaterialCost,
+		&revision.MarkupPercentage,
+		&revision.FinalPrice,
+		&revision.Status,
+		&revision.BidData,
+		&revision.GenerationModel,
+		&revision.ChangesSummary,
+		&revision.Reason,
+		&revision.CreatedBy,
+		&revision.CreatedAt,
+		&revision.BlueprintIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
 func (r *BidRevisionRepository) Create(ctx context.Context, revision *models.BidRevision) error {
 	query := `
 		INSERT INTO bid_revisions (id, bid_id, version, name, total_cost, labor_cost, 
 		                          material_cost, markup_percentage, final_price, status, 
-		                          bid_data, changes_summary, created_by, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		                          bid_data, generation_model, changes_summary, reason, created_by, created_at, blueprint_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		revision.ID,
 		revision.BidID,
 		revision.Version,
@@ -36,9 +68,12 @@ func (r *BidRevisionRepository) Create(ctx context.Context, revision *models.Bid
 		revision.FinalPrice,
 		revision.Status,
 		revision.BidData,
+		revision.GenerationModel,
 		revision.ChangesSummary,
+		revision.Reason,
 		revision.CreatedBy,
 		revision.CreatedAt,
+		blueprintIDsParam(revision.BlueprintIDs),
 	)
 
 	if err != nil {
@@ -50,43 +85,22 @@ func (r *BidRevisionRepository) Create(ctx context.Context, revision *models.Bid
 
 func (r *BidRevisionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BidRevision, error) {
 	query := `
-		SELECT id, bid_id, version, name, total_cost, labor_cost, material_cost, 
-		       markup_percentage, final_price, status, bid_data, changes_summary, 
-		       created_by, created_at
+		SELECT ` + bidRevisionColumns + `
 		FROM bid_revisions
 		WHERE id = $1
 	`
 
-	var revision models.BidRevision
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&revision.ID,
-		&revision.BidID,
-		&revision.Version,
-		&revision.Name,
-		&revision.TotalCost,
-		&revision.LaborCost,
-		&revision.MaterialCost,
-		&revision.MarkupPercentage,
-		&revision.FinalPrice,
-		&revision.Status,
-		&revision.BidData,
-		&revision.ChangesSummary,
-		&revision.CreatedBy,
-		&revision.CreatedAt,
-	)
-
+	revision, err := scanBidRevision(r.db.Pool.QueryRow(ctx, query, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bid revision: %w", err)
 	}
 
-	return &revision, nil
+	return revision, nil
 }
 
 func (r *BidRevisionRepository) GetByBidID(ctx context.Context, bidID uuid.UUID) ([]*models.BidRevision, error) {
 	query := `
-		SELECT id, bid_id, version, name, total_cost, labor_cost, material_cost, 
-		       markup_percentage, final_price, status, bid_data, changes_summary, 
-		       created_by, created_at
+		SELECT ` + bidRevisionColumns + `
 		FROM bid_revisions
 		WHERE bid_id = $1
 		ORDER BY version DESC
@@ -100,27 +114,11 @@ func (r *BidRevisionRepository) GetByBidID(ctx context.Context, bidID uuid.UUID)
 
 	var revisions []*models.BidRevision
 	for rows.Next() {
-		var revision models.BidRevision
-		err := rows.Scan(
-			&revision.ID,
-			&revision.BidID,
-			&revision.Version,
-			&revision.Name,
-			&revision.TotalCost,
-			&revision.LaborCost,
-			&revision.MaterialCost,
-			&revision.MarkupPercentage,
-			&revision.FinalPrice,
-			&revision.Status,
-			&revision.BidData,
-			&revision.ChangesSummary,
-			&revision.CreatedBy,
-			&revision.CreatedAt,
-		)
+		revision, err := scanBidRevision(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan bid revision: %w", err)
 		}
-		revisions = append(revisions, &revision)
+		revisions = append(revisions, revision)
 	}
 
 	return revisions, nil
@@ -128,36 +126,17 @@ func (r *BidRevisionRepository) GetByBidID(ctx context.Context, bidID uuid.UUID)
 
 func (r *BidRevisionRepository) GetByVersion(ctx context.Context, bidID uuid.UUID, version int) (*models.BidRevision, error) {
 	query := `
-		SELECT id, bid_id, version, name, total_cost, labor_cost, material_cost, 
-		       markup_percentage, final_price, status, bid_data, changes_summary, 
-		       created_by, created_at
+		SELECT ` + bidRevisionColumns + `
 		FROM bid_revisions
 		WHERE bid_id = $1 AND version = $2
 	`
 
-	var revision models.BidRevision
-	err := r.db.Pool.QueryRow(ctx, query, bidID, version).Scan(
-		&revision.ID,
-		&revision.BidID,
-		&revision.Version,
-		&revision.Name,
-		&revision.TotalCost,
-		&revision.LaborCost,
-		&revision.MaterialCost,
-		&revision.MarkupPercentage,
-		&revision.FinalPrice,
-		&revision.Status,
-		&revision.BidData,
-		&revision.ChangesSummary,
-		&revision.CreatedBy,
-		&revision.CreatedAt,
-	)
-
+	revision, err := scanBidRevision(r.db.conn(ctx).QueryRow(ctx, query, bidID, version))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bid revision by version: %w", err)
 	}
 
-	return &revision, nil
+	return revision, nil
 }
 
 func (r *BidRevisionRepository) GetLatestVersion(ctx context.Context, bidID uuid.UUID) (int, error) {
@@ -168,7 +147,7 @@ func (r *BidRevisionRepository) GetLatestVersion(ctx context.Context, bidID uuid
 	`
 
 	var version int
-	err := r.db.Pool.QueryRow(ctx, query, bidID).Scan(&version)
+	err := r.db.conn(ctx).QueryRow(ctx, query, bidID).Scan(&version)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get latest bid version: %w", err)
 	}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// ErrBidShareNotFound is returned when a bid share is unknown, or when
+// responding through one that has expired, was revoked or was already used
+var ErrBidShareNotFound = errors.New("bid share not found")
+
+const bidShareColumns = `id, bid_id, token_hash, created_by, expires_at, responded_at, response, response_comment, responder_name, revoked_at, first_viewed_at, created_at`
+
+type BidShareRepository struct {
+	db *Database
+}
+
+func NewBidShareRepository(db *Database) *BidShareRepository {
+	return &BidShareRepository{db: db}
+}
+
+func scanBidShare(row pgx.Row) (*models.BidShare, error) {
+	var share models.BidShare
+	err := row.Scan(
+		&share.ID,
+		&share.BidID,
+		&share.TokenHash,
+		&share.CreatedBy,
+		&share.ExpiresAt,
+		&share.RespondedAt,
+		&share.Response,
+		&share.ResponseComment,
+		&share.ResponderName,
+		&share.RevokedAt,
+		&share.FirstViewedAt,
+		&share.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// Create stores a new bid share
+func (r *BidShareRepository) Create(ctx context.Context, share *models.BidShare) error {
+	query := `INSERT INTO bid_shares (` + bidShareColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		share.ID,
+		share.BidID,
+		share.TokenHash,
+		share.CreatedBy,
+		share.ExpiresAt,
+		share.RespondedAt,
+		share.Response,
+		share.ResponseComment,
+		share.ResponderName,
+		share.RevokedAt,
+		share.FirstViewedAt,
+		share.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bid share: %w", err)
+	}
+	return nil
+}
+
+// GetByTokenHash returns the bid share with the given token hash, expired,
+// revoked or not
+func (r *BidShareRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.BidShare, error) {
+	query := `SELECT ` + bidShareColumns + ` FROM bid_shares WHERE token_hash = $1`
+	share, err := scanBidShare(r.db.Pool.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBidShareNotFound
+		}
+		return nil, fmt.Errorf("failed to get bid share: %w", err)
+	}
+	return share, nil
+}
+
+// ListByBidID returns a bid's shares, oldest first
+func (r *BidShareRepository) ListByBidID(ctx context.Context, bidID uuid.UUID) ([]*models.BidShare, error) {
+	query := `SELECT ` + bidShareColumns + ` FROM bid_shares WHERE bid_id = $1 ORDER BY created_at, id`
+	rows, err := r.db.Pool.Query(ctx, query, bidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bid shares: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []*models.BidShare
+	for rows.Next() {
+		share, err := scanBidShare(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bid share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list bid shares: %w", err)
+	}
+	return shares, nil
+}
+
+// MarkViewed records the first view of a bid share and reports whether this
+// was it. Only one of several racing first views is reported.
+func (r *BidShareRepository) MarkViewed(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	query := `UPDATE bid_shares SET first_viewed_at = $2 WHERE id = $1 AND first_viewed_at IS NULL`
+	tag, err := r.db.Pool.Exec(ctx, query, id, models.NewTimestamp(at))
+	if err != nil {
+		return false, fmt.Errorf("failed to mark bid share viewed: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Respond records the client's response on an unexpired, unrevoked bid
+// share that has none yet and returns the share. The check and the update are one
+// statement, so two responses racing through the same link cannot both be
+// recorded. It takes part in a transaction started with InTx.
+func (r *BidShareRepository) Respond(ctx context.Context, id uuid.UUID, response models.BidShareResponse, at time.Time) (*models.BidShare, error) {
+	query := `
+		UPDATE bid_shares
+		SET responded_at = $2, response = $3, response_comment = $4, responder_name = $5
+		WHERE id = $1 AND responded_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+		RETURNING ` + bidShareColumns
+
+	share, err := scanBidShare(r.db.conn(ctx).QueryRow(ctx, query,
+		id,
+		models.NewTimestamp(at),
+		response.Status,
+		response.Comment,
+		response.ResponderName,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBidShareNotFound
+		}
+		return nil, fmt.Errorf("failed to respond to bid share: %w", err)
+	}
+	return share, nil
+}
+
+// Revoke stops a bid's share from working and returns it. Revoking a share
+// again keeps the first revocation time.
+func (r *BidShareRepository) Revoke(ctx context.Context, bidID, id uuid.UUID, at time.Time) (*models.BidShare, error) {
+	query := `
+		UPDATE bid_shares
+		SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE id = $1 AND bid_id = $2
+		RETURNING ` + bidShareColumns
+
+	share, err := scanBidShare(r.db.Pool.QueryRow(ctx, query, id, bidID, models.NewTimestamp(at)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBidShareNotFound
+		}
+		return nil, fmt.Errorf("failed to revoke bid share: %w", err)
+	}
+	return share, nil
+}
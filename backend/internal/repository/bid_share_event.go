@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+const bidShareEventColumns = `id, share_id, bid_id, event_type, ip_hash, user_agent, created_at`
+
+type BidShareEventRepository struct {
+	db *Database
+}
+
+func NewBidShareEventRepository(db *Database) *BidShareEventRepository {
+	return &BidShareEventRepository{db: db}
+}
+
+func scanBidShareEvent(row pgx.Row) (models.BidShareEvent, error) {
+	var event models.BidShareEvent
+	err := row.Scan(
+		&event.ID,
+		&event.ShareID,
+		&event.BidID,
+		&event.EventType,
+		&event.IPHash,
+		&event.UserAgent,
+		&event.CreatedAt,
+	)
+	return event, err
+}
+
+// Record stores an event unless its share already has limit events, and
+// reports whether it was stored. Events recorded at the same moment may
+// overshoot the limit slightly.
+func (r *BidShareEventRepository) Record(ctx context.Context, event *models.BidShareEvent, limit int) (bool, error) {
+	query := `
+		INSERT INTO bid_share_events (` + bidShareEventColumns + `)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE (SELECT COUNT(*) FROM bid_share_events WHERE share_id = $2) < $8`
+
+	tag, err := r.db.Pool.Exec(ctx, query,
+		event.ID,
+		event.ShareID,
+		event.BidID,
+		event.EventType,
+		event.IPHash,
+		event.UserAgent,
+		event.CreatedAt,
+		limit,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record bid share event: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListByBidID returns the events of all of a bid's shares, oldest first
+func (r *BidShareEventRepository) ListByBidID(ctx context.Context, bidID uuid.UUID) ([]models.BidShareEvent, error) {
+	query := `SELECT ` + bidShareEventColumns + ` FROM bid_share_events WHERE bid_id = $1 ORDER BY created_at, id`
+	rows, err := r.db.Pool.Query(ctx, query, bidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bid share events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.BidShareEvent
+	for rows.Next() {
+		event, err := scanBidShareEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bid share event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list bid share events: %w", err)
+	}
+	return events, nil
+}
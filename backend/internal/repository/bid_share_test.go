@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestBidShareRepository_Respond(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewBidShareRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	bid := &models.Bid{ID: uuid.New(), ProjectID: projectID, Status: models.BidStatusSent, Version: 1, IsLatest: true, CreatedAt: models.Now(), UpdatedAt: models.Now()}
+	if err := NewBidRepository(db).Create(ctx, bid); err != nil {
+		t.Fatalf("failed to seed bid: %v", err)
+	}
+
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	create := func() *models.BidShare {
+		t.Helper()
+		share := &models.BidShare{
+			ID:        uuid.New(),
+			BidID:     bid.ID,
+			TokenHash: uuid.NewString(),
+			ExpiresAt: models.NewTimestamp(now.Add(time.Hour)),
+			CreatedAt: models.NewTimestamp(now),
+		}
+		if err := repo.Create(ctx, share); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		return share
+	}
+
+	share := create()
+	found, err := repo.GetByTokenHash(ctx, share.TokenHash)
+	if err != nil || found.ID != share.ID || found.RespondedAt != nil {
+		t.Fatalf("GetByTokenHash = %+v, %v; want the share, unanswered", found, err)
+	}
+
+	comment := "Please start in June"
+	response := models.BidShareResponse{Status: models.BidStatusAccepted, Comment: &comment, ResponderName: "Pat Owner"}
+	responded, err := repo.Respond(ctx, share.ID, response, now)
+	if err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+	if responded.RespondedAt == nil || responded.Response == nil || *responded.Response != models.BidStatusAccepted ||
+		responded.ResponseComment == nil || *responded.ResponseComment != comment || responded.ResponderName == nil || *responded.ResponderName != "Pat Owner" {
+		t.Errorf("responded share = %+v, want the response recorded", responded)
+	}
+	if _, err := repo.Respond(ctx, share.ID, response, now); err != ErrBidShareNotFound {
+		t.Errorf("second Respond error = %v, want ErrBidShareNotFound", err)
+	}
+
+	expiring := create()
+	if _, err := repo.Respond(ctx, expiring.ID, response, now.Add(time.Hour)); err != ErrBidShareNotFound {
+		t.Errorf("Respond through an expired share error = %v, want ErrBidShareNotFound", err)
+	}
+
+	revoked := create()
+	if _, err := repo.Revoke(ctx, uuid.New(), revoked.ID, now); err != ErrBidShareNotFound {
+		t.Errorf("Revoke through another bid error = %v, want ErrBidShareNotFound", err)
+	}
+	found, err = repo.Revoke(ctx, bid.ID, revoked.ID, now)
+	if err != nil || found.RevokedAt == nil || !found.RevokedAt.Time.Equal(now) {
+		t.Fatalf("Revoke = %+v, %v; want the share revoked now", found, err)
+	}
+	if found, err = repo.Revoke(ctx, bid.ID, revoked.ID, now.Add(time.Minute)); err != nil || !found.RevokedAt.Time.Equal(now) {
+		t.Errorf("second Revoke = %+v, %v; want the first revocation time kept", found, err)
+	}
+	if _, err := repo.Respond(ctx, revoked.ID, response, now); err != ErrBidShareNotFound {
+		t.Errorf("Respond through a revoked share error = %v, want ErrBidShareNotFound", err)
+	}
+
+	if _, err := repo.GetByTokenHash(ctx, "unknown"); err != ErrBidShareNotFound {
+		t.Errorf("GetByTokenHash of an unknown token error = %v, want ErrBidShareNotFound", err)
+	}
+}
+
+func TestBidAcceptanceRepository(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewBidAcceptanceRepository(db)
+	shares := NewBidShareRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	bid := &models.Bid{ID: uuid.New(), ProjectID: projectID, Status: models.BidStatusSent, Version: 1, IsLatest: true, CreatedAt: models.Now(), UpdatedAt: models.Now()}
+	if err := NewBidRepository(db).Create(ctx, bid); err != nil {
+		t.Fatalf("failed to seed bid: %v", err)
+	}
+	if _, err := repo.GetLatestByBidID(ctx, bid.ID); err != ErrBidAcceptanceNotFound {
+		t.Errorf("GetLatestByBidID without an acceptance error = %v, want ErrBidAcceptanceNotFound", err)
+	}
+
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	accept := func(at time.Time, name string) *models.BidAcceptance {
+		t.Helper()
+		share := &models.BidShare{ID: uuid.New(), BidID: bid.ID, TokenHash: uuid.NewString(), ExpiresAt: models.NewTimestamp(now.Add(time.Hour)), CreatedAt: models.NewTimestamp(now)}
+		if err := shares.Create(ctx, share); err != nil {
+			t.Fatalf("failed to seed share: %v", err)
+		}
+		acceptance := &models.BidAcceptance{
+			ID: uuid.New(), BidID: bid.ID, ShareID: share.ID, SignerName: name, SignerEmail: "pat@example.com",
+			IPAddress: "203.0.113.7", UserAgent: "Mozilla/5.0", AcceptedAt: models.NewTimestamp(at),
+		}
+		if err := repo.Create(ctx, acceptance); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if err := repo.Create(ctx, acceptance); err == nil {
+			t.Error("second acceptance through the same share succeeded")
+		}
+		return acceptance
+	}
+
+	accept(now, "Pat Owner")
+	latest := accept(now.Add(time.Minute), "Sam Owner")
+	found, err := repo.GetLatestByBidID(ctx, bid.ID)
+	if err != nil {
+		t.Fatalf("GetLatestByBidID failed: %v", err)
+	}
+	if found.ID != latest.ID || found.SignerName != "Sam Owner" || found.SignerEmail != "pat@example.com" ||
+		found.IPAddress != "203.0.113.7" || found.UserAgent != "Mozilla/5.0" || !found.AcceptedAt.Time.Equal(latest.AcceptedAt.Time) {
+		t.Errorf("GetLatestByBidID = %+v, want the latest acceptance", found)
+	}
+}
+
+func TestBidShareEventRepository(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewBidShareEventRepository(db)
+	shares := NewBidShareRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	bid := &models.Bid{ID: uuid.New(), ProjectID: projectID, Status: models.BidStatusSent, Version: 1, IsLatest: true, CreatedAt: models.Now(), UpdatedAt: models.Now()}
+	if err := NewBidRepository(db).Create(ctx, bid); err != nil {
+		t.Fatalf("failed to seed bid: %v", err)
+	}
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	share := &models.BidShare{ID: uuid.New(), BidID: bid.ID, TokenHash: uuid.NewString(), ExpiresAt: models.NewTimestamp(now.Add(time.Hour)), CreatedAt: models.NewTimestamp(now)}
+	if err := shares.Create(ctx, share); err != nil {
+		t.Fatalf("failed to seed share: %v", err)
+	}
+
+	first, err := shares.MarkViewed(ctx, share.ID, now)
+	if err != nil || !first {
+		t.Errorf("first MarkViewed = %v, %v; want the first view", first, err)
+	}
+	if again, err := shares.MarkViewed(ctx, share.ID, now.Add(time.Minute)); err != nil || again {
+		t.Errorf("second MarkViewed = %v, %v; want it not the first", again, err)
+	}
+	listed, err := shares.ListByBidID(ctx, bid.ID)
+	if err != nil || len(listed) != 1 || listed[0].FirstViewedAt == nil || !listed[0].FirstViewedAt.Time.Equal(now) {
+		t.Errorf("ListByBidID = %+v, %v; want the share first viewed now", listed, err)
+	}
+
+	record := func(eventType models.BidShareEventType, at time.Time) bool {
+		t.Helper()
+		event := &models.BidShareEvent{ID: uuid.New(), ShareID: share.ID, BidID: bid.ID, EventType: eventType, IPHash: "hash", UserAgent: "Mozilla/5.0", CreatedAt: models.NewTimestamp(at)}
+		stored, err := repo.Record(ctx, event, 2)
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		return stored
+	}
+	if !record(models.BidShareEventDownloadedPDF, now.Add(time.Minute)) || !record(models.BidShareEventViewed, now) {
+		t.Fatal("Record under the limit was not stored")
+	}
+	if record(models.BidShareEventViewed, now.Add(2*time.Minute)) {
+		t.Error("Record over the limit was stored")
+	}
+
+	events, err := repo.ListByBidID(ctx, bid.ID)
+	if err != nil {
+		t.Fatalf("ListByBidID failed: %v", err)
+	}
+	if len(events) != 2 || events[0].EventType != models.BidShareEventViewed || events[1].EventType != models.BidShareEventDownloadedPDF || events[0].IPHash != "hash" {
+		t.Errorf("events = %+v, want the two stored events oldest first", events)
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestBidListClause(t *testing.T) {
+	projectID, blueprintID := uuid.New(), uuid.New()
+	status := models.BidStatusSent
+
+	clause, args := bidListClause(models.BidListFilter{ProjectID: projectID, BlueprintID: &blueprintID, Status: &status})
+
+	if clause != "project_id = $1 AND $2 = ANY(blueprint_ids) AND status = $3" {
+		t.Errorf("unexpected clause %q", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{projectID, blueprintID, status}) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	if clause, args := bidListClause(models.BidListFilter{ProjectID: projectID}); clause != "project_id = $1" || len(args) != 1 {
+		t.Errorf("expected only the project filter, got %q %v", clause, args)
+	}
+}
+
+func TestBidRepository_LockVersion(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewBidRepository(db)
+	ctx := context.Background()
+
+	bid := &models.Bid{ID: uuid.New(), ProjectID: seedSearchProject(t, db), Status: models.BidStatusDraft, Version: 2, IsLatest: true, CreatedAt: models.Now(), UpdatedAt: models.Now()}
+	if err := repo.Create(ctx, bid); err != nil {
+		t.Fatalf("failed to seed bid: %v", err)
+	}
+
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		if err := repo.LockVersion(ctx, bid.ID, 2); err != nil {
+			return err
+		}
+		bid.Version = 3
+		return repo.Update(ctx, bid)
+	})
+	if err != nil {
+		t.Fatalf("LockVersion at the current version failed: %v", err)
+	}
+
+	// A save based on the old version finds it moved
+	err = db.InTx(ctx, func(ctx context.Context) error {
+		return repo.LockVersion(ctx, bid.ID, 2)
+	})
+	if !errors.Is(err, ErrBidVersionChanged) {
+		t.Errorf("LockVersion at a stale version = %v, want ErrBidVersionChanged", err)
+	}
+	if err := repo.LockVersion(ctx, uuid.New(), 1); !errors.Is(err, ErrBidVersionChanged) {
+		t.Errorf("LockVersion of a missing bid = %v, want ErrBidVersionChanged", err)
+	}
+}
@@ -2,12 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
+var ErrBlueprintNotFound = errors.New("blueprint not found")
+
 type BlueprintRepository struct {
 	db *Database
 }
@@ -16,17 +20,14 @@ func NewBlueprintRepository(db *Database) *BlueprintRepository {
 	return &BlueprintRepository{db: db}
 }
 
-func (r *BlueprintRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Blueprint, error) {
-	query := `
-		SELECT id, project_id, filename, s3_key, file_size, mime_type, upload_status, 
+const blueprintColumns = `id, project_id, filename, s3_key, file_size, mime_type, upload_status, 
 		       analysis_status, analysis_data, version, parent_blueprint_id, is_latest, 
-		       created_at, updated_at
-		FROM blueprints
-		WHERE id = $1
-	`
+		       analysis_model, sheet_type, room_finishes, takeoff_adjustments, scan_result, upload_generation, 
+		       page_count, page_sizes, document_title, document_created_at, created_at, updated_at`
 
+func scanBlueprint(row pgx.Row) (*models.Blueprint, error) {
 	var blueprint models.Blueprint
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&blueprint.ID,
 		&blueprint.ProjectID,
 		&blueprint.Filename,
@@ -39,23 +40,74 @@ func (r *BlueprintRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 		&blueprint.Version,
 		&blueprint.ParentBlueprintID,
 		&blueprint.IsLatest,
+		&blueprint.AnalysisModel,
+		&blueprint.SheetType,
+		&blueprint.RoomFinishes,
+		&blueprint.TakeoffAdjustments,
+		&blueprint.ScanResult,
+		&blueprint.UploadGeneration,
+		&blueprint.PageCount,
+		&blueprint.PageSizes,
+		&blueprint.DocumentTitle,
+		&blueprint.DocumentCreatedAt,
 		&blueprint.CreatedAt,
 		&blueprint.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &blueprint, nil
+}
 
+func (r *BlueprintRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Blueprint, error) {
+	query := `
+		SELECT ` + blueprintColumns + `
+		FROM blueprints
+		WHERE id = $1
+	`
+
+	blueprint, err := scanBlueprint(r.db.Pool.QueryRow(ctx, query, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get blueprint: %w", err)
 	}
 
-	return &blueprint, nil
+	return blueprint, nil
+}
+
+func (r *BlueprintRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]*models.Blueprint, error) {
+	query := `
+		SELECT ` + blueprintColumns + `
+		FROM blueprints
+		WHERE project_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blueprints: %w", err)
+	}
+	defer rows.Close()
+
+	var blueprints []*models.Blueprint
+	for rows.Next() {
+		blueprint, err := scanBlueprint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blueprint: %w", err)
+		}
+		blueprints = append(blueprints, blueprint)
+	}
+
+	return blueprints, nil
 }
 
 func (r *BlueprintRepository) Create(ctx context.Context, blueprint *models.Blueprint) error {
 	query := `
 		INSERT INTO blueprints (id, project_id, filename, s3_key, file_size, mime_type, 
 		                        upload_status, analysis_status, analysis_data, version, 
-		                        parent_blueprint_id, is_latest, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		                        parent_blueprint_id, is_latest, analysis_model, sheet_type, 
+		                        room_finishes, takeoff_adjustments, scan_result, upload_generation, 
+		                        page_count, page_sizes, document_title, document_created_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query,
@@ -71,6 +123,16 @@ func (r *BlueprintRepository) Create(ctx context.Context, blueprint *models.Blue
 		blueprint.Version,
 		blueprint.ParentBlueprintID,
 		blueprint.IsLatest,
+		blueprint.AnalysisModel,
+		blueprint.SheetType,
+		blueprint.RoomFinishes,
+		blueprint.TakeoffAdjustments,
+		blueprint.ScanResult,
+		blueprint.UploadGeneration,
+		blueprint.PageCount,
+		blueprint.PageSizes,
+		blueprint.DocumentTitle,
+		blueprint.DocumentCreatedAt,
 		blueprint.CreatedAt,
 		blueprint.UpdatedAt,
 	)
@@ -86,11 +148,13 @@ func (r *BlueprintRepository) Update(ctx context.Context, blueprint *models.Blue
 	query := `
 		UPDATE blueprints
 		SET file_size = $1, upload_status = $2, analysis_status = $3, analysis_data = $4, 
-		    version = $5, parent_blueprint_id = $6, is_latest = $7, updated_at = $8
-		WHERE id = $9
+		    version = $5, parent_blueprint_id = $6, is_latest = $7, analysis_model = $8, 
+		    sheet_type = $9, scan_result = $10, page_count = $11, page_sizes = $12, 
+		    document_title = $13, document_created_at = $14, updated_at = $15
+		WHERE id = $16
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		blueprint.FileSize,
 		blueprint.UploadStatus,
 		blueprint.AnalysisStatus,
@@ -98,6 +162,13 @@ func (r *BlueprintRepository) Update(ctx context.Context, blueprint *models.Blue
 		blueprint.Version,
 		blueprint.ParentBlueprintID,
 		blueprint.IsLatest,
+		blueprint.AnalysisModel,
+		blueprint.SheetType,
+		blueprint.ScanResult,
+		blueprint.PageCount,
+		blueprint.PageSizes,
+		blueprint.DocumentTitle,
+		blueprint.DocumentCreatedAt,
 		blueprint.UpdatedAt,
 		blueprint.ID,
 	)
@@ -108,3 +179,185 @@ func (r *BlueprintRepository) Update(ctx context.Context, blueprint *models.Blue
 
 	return nil
 }
+
+// IncrementUploadGeneration records a completed upload of the blueprint's
+// file and returns the new generation. Update never writes the generation,
+// so a worker holding an older copy of the blueprint cannot roll it back.
+func (r *BlueprintRepository) IncrementUploadGeneration(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `
+		UPDATE blueprints
+		SET upload_generation = upload_generation + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING upload_generation
+	`
+
+	var generation int
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&generation); err != nil {
+		return 0, fmt.Errorf("failed to increment upload generation: %w", err)
+	}
+	return generation, nil
+}
+
+// GetUploadGeneration returns the blueprint's current upload generation
+func (r *BlueprintRepository) GetUploadGeneration(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `SELECT upload_generation FROM blueprints WHERE id = $1`
+
+	var generation int
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&generation); err != nil {
+		return 0, fmt.Errorf("failed to get upload generation: %w", err)
+	}
+	return generation, nil
+}
+
+// Delete removes a blueprint with its revisions, assets and jobs in one
+// statement. Jobs still queued or processing are cancelled by the delete; a
+// worker already running one fails when it next reads the blueprint. The
+// returned S3 keys are distinct and name objects the caller should delete.
+func (r *BlueprintRepository) Delete(ctx context.Context, id uuid.UUID) (*models.BlueprintDeletion, error) {
+	query := `
+		WITH cancelled AS (
+			DELETE FROM jobs WHERE blueprint_id = $1 AND status IN ('queued', 'processing') RETURNING id
+		), revisions AS (
+			DELETE FROM blueprint_revisions WHERE blueprint_id = $1 RETURNING s3_key
+		), assets AS (
+			DELETE FROM blueprint_assets WHERE blueprint_id = $1 RETURNING s3_key
+		), deleted AS (
+			DELETE FROM blueprints WHERE id = $1 RETURNING s3_key
+		)
+		SELECT
+			(SELECT COUNT(*) FROM deleted),
+			(SELECT COUNT(*) FROM cancelled),
+			ARRAY(
+				SELECT s3_key FROM deleted
+				UNION SELECT s3_key FROM revisions
+				UNION SELECT s3_key FROM assets
+				ORDER BY 1
+			)
+	`
+
+	var deleted int
+	deletion := &models.BlueprintDeletion{}
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&deleted, &deletion.CancelledJobs, &deletion.S3Keys); err != nil {
+		return nil, fmt.Errorf("failed to delete blueprint: %w", err)
+	}
+	if deleted == 0 {
+		return nil, ErrBlueprintNotFound
+	}
+	return deletion, nil
+}
+
+// GetStorageUsedByUser sums the file sizes of the blueprints in a user's projects
+func (r *BlueprintRepository) GetStorageUsedByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(b.file_size), 0)
+		FROM blueprints b
+		JOIN projects p ON p.id = b.project_id
+		WHERE p.user_id = $1
+	`
+
+	var used int64
+	if err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&used); err != nil {
+		return 0, fmt.Errorf("failed to get storage used: %w", err)
+	}
+	return used, nil
+}
+
+// UpdateRoomFinishes replaces the room name -> floor finish selections for a blueprint
+func (r *BlueprintRepository) UpdateRoomFinishes(ctx context.Context, id uuid.UUID, finishes map[string]models.FloorFinish) error {
+	query := `UPDATE blueprints SET room_finishes = $1, updated_at = NOW() WHERE id = $2`
+
+	if _, err := r.db.Pool.Exec(ctx, query, finishes, id); err != nil {
+		return fmt.Errorf("failed to update room finishes: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTakeoffAdjustments replaces the estimator's takeoff adjustments for a blueprint
+func (r *BlueprintRepository) UpdateTakeoffAdjustments(ctx context.Context, id uuid.UUID, adjustments []models.TakeoffAdjustment) error {
+	query := `UPDATE blueprints SET takeoff_adjustments = $1, updated_at = NOW() WHERE id = $2`
+
+	if _, err := r.db.Pool.Exec(ctx, query, adjustments, id); err != nil {
+		return fmt.Errorf("failed to update takeoff adjustments: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateOCRText stores the raw OCR text extracted during analysis. The search
+// vector is a generated column, so it is refreshed by the database.
+func (r *BlueprintRepository) UpdateOCRText(ctx context.Context, id uuid.UUID, text *string) error {
+	query := `UPDATE blueprints SET raw_ocr_text = $1 WHERE id = $2`
+
+	if _, err := r.db.Pool.Exec(ctx, query, text, id); err != nil {
+		return fmt.Errorf("failed to update blueprint OCR text: %w", err)
+	}
+
+	return nil
+}
+
+// CopyOCRText copies the raw OCR text of one blueprint to another
+func (r *BlueprintRepository) CopyOCRText(ctx context.Context, fromID, toID uuid.UUID) error {
+	query := `UPDATE blueprints SET raw_ocr_text = (SELECT raw_ocr_text FROM blueprints WHERE id = $1) WHERE id = $2`
+
+	if _, err := r.db.Pool.Exec(ctx, query, fromID, toID); err != nil {
+		return fmt.Errorf("failed to copy blueprint OCR text: %w", err)
+	}
+
+	return nil
+}
+
+// SearchOCRText runs a full-text query against the OCR text of the latest
+// version of each of a project's blueprints and returns matches ordered by
+// relevance with highlighted snippets.
+func (r *BlueprintRepository) SearchOCRText(ctx context.Context, projectID uuid.UUID, searchQuery string, limit int) ([]models.BlueprintTextMatch, error) {
+	query := `
+		SELECT id, filename, ts_rank(ocr_tsv, q) AS rank,
+		       ts_headline('english', raw_ocr_text, q,
+		                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=3, MaxWords=20, MinWords=5')
+		FROM blueprints, plainto_tsquery('english', $2) q
+		WHERE project_id = $1 AND is_latest = TRUE AND ocr_tsv @@ q
+		ORDER BY rank DESC, filename ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, projectID, searchQuery, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search blueprint OCR text: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []models.BlueprintTextMatch{}
+	for rows.Next() {
+		var m models.BlueprintTextMatch
+		if err := rows.Scan(&m.BlueprintID, &m.Filename, &m.Rank, &m.Snippet); err != nil {
+			return nil, fmt.Errorf("failed to scan OCR search result: %w", err)
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
+}
+
+// BackfillOCRText copies raw OCR text out of stored analysis data for up to
+// batchSize blueprints that predate OCR indexing. It returns the number of rows
+// updated so callers can loop until nothing is left.
+func (r *BlueprintRepository) BackfillOCRText(ctx context.Context, batchSize int) (int64, error) {
+	query := `
+		UPDATE blueprints
+		SET raw_ocr_text = analysis_data->>'raw_ocr_text'
+		WHERE id IN (
+			SELECT id FROM blueprints
+			WHERE raw_ocr_text IS NULL
+			  AND analysis_data->>'raw_ocr_text' IS NOT NULL
+			LIMIT $1
+		)
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to backfill blueprint OCR text: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type BlueprintAssetRepository struct {
+	db *Database
+}
+
+func NewBlueprintAssetRepository(db *Database) *BlueprintAssetRepository {
+	return &BlueprintAssetRepository{db: db}
+}
+
+// Record stores an asset. An object already recorded for the blueprint, such
+// as a revision that reuses the original's key, is left unchanged.
+func (r *BlueprintAssetRepository) Record(ctx context.Context, asset *models.BlueprintAsset) error {
+	query := `
+		INSERT INTO blueprint_assets (id, blueprint_id, kind, s3_key, size_bytes, checksum, storage_class, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (blueprint_id, s3_key) DO NOTHING
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		asset.ID,
+		asset.BlueprintID,
+		asset.Kind,
+		asset.S3Key,
+		asset.SizeBytes,
+		asset.Checksum,
+		asset.StorageClass,
+		asset.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record blueprint asset: %w", err)
+	}
+
+	return nil
+}
+
+// ListByBlueprint returns a blueprint's assets, oldest first
+func (r *BlueprintAssetRepository) ListByBlueprint(ctx context.Context, blueprintID uuid.UUID) ([]*models.BlueprintAsset, error) {
+	query := `
+		SELECT id, blueprint_id, kind, s3_key, size_bytes, checksum, storage_class, created_at
+		FROM blueprint_assets
+		WHERE blueprint_id = $1
+		ORDER BY created_at ASC, s3_key
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blueprint assets: %w", err)
+	}
+	defer rows.Close()
+
+	assets := []*models.BlueprintAsset{}
+	for rows.Next() {
+		var asset models.BlueprintAsset
+		if err := rows.Scan(&asset.ID, &asset.BlueprintID, &asset.Kind, &asset.S3Key,
+			&asset.SizeBytes, &asset.Checksum, &asset.StorageClass, &asset.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan blueprint asset: %w", err)
+		}
+		assets = append(assets, &asset)
+	}
+
+	return assets, rows.Err()
+}
+
+// DeleteByBlueprint removes a blueprint's asset records and returns their S3
+// keys so the caller can delete the objects
+func (r *BlueprintAssetRepository) DeleteByBlueprint(ctx context.Context, blueprintID uuid.UUID) ([]string, error) {
+	rows, err := r.db.Pool.Query(ctx, `DELETE FROM blueprint_assets WHERE blueprint_id = $1 RETURNING s3_key`, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete blueprint assets: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan blueprint asset key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestBlueprintAssetRepository_Lifecycle(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	repo := NewBlueprintAssetRepository(db)
+	blueprints := NewBlueprintRepository(db)
+
+	projectID := seedSearchProject(t, db)
+	blueprintID := seedSearchBlueprint(t, blueprints, projectID, "A-101.pdf", "")
+
+	size := func(n int64) *int64 { return &n }
+	record := func(kind models.BlueprintAssetKind, key string, bytes int64) {
+		asset := &models.BlueprintAsset{
+			ID:          uuid.New(),
+			BlueprintID: blueprintID,
+			Kind:        kind,
+			S3Key:       key,
+			SizeBytes:   size(bytes),
+			CreatedAt:   models.Now(),
+		}
+		if err := repo.Record(ctx, asset); err != nil {
+			t.Fatalf("Record(%s) error = %v", kind, err)
+		}
+	}
+
+	record(models.BlueprintAssetOriginal, "test/A-101.pdf", 1000)
+	// A revision of the unchanged file reuses the original object
+	record(models.BlueprintAssetRevision, "test/A-101.pdf", 1000)
+	record(models.BlueprintAssetRevision, "test/A-101.v2.pdf", 1200)
+	record(models.BlueprintAssetConverted, "test/A-101.jpg", 300)
+
+	assets, err := repo.ListByBlueprint(ctx, blueprintID)
+	if err != nil {
+		t.Fatalf("ListByBlueprint() error = %v", err)
+	}
+	if len(assets) != 3 || assets[0].Kind != models.BlueprintAssetOriginal {
+		t.Fatalf("expected 3 assets starting with the original, got %+v", assets)
+	}
+
+	var ownerID uuid.UUID
+	if err := db.Pool.QueryRow(ctx, `SELECT user_id FROM projects WHERE id = $1`, projectID).Scan(&ownerID); err != nil {
+		t.Fatalf("failed to load project owner: %v", err)
+	}
+	activity, err := NewUserRepository(db).GetUserActivity(ctx, ownerID)
+	if err != nil {
+		t.Fatalf("GetUserActivity() error = %v", err)
+	}
+	if activity.StorageBytes != 2500 {
+		t.Errorf("expected storage to count every asset, got %d bytes", activity.StorageBytes)
+	}
+
+	keys, err := repo.DeleteByBlueprint(ctx, blueprintID)
+	if err != nil {
+		t.Fatalf("DeleteByBlueprint() error = %v", err)
+	}
+	sort.Strings(keys)
+	if want := []string{"test/A-101.jpg", "test/A-101.pdf", "test/A-101.v2.pdf"}; len(keys) != 3 || keys[0] != want[0] || keys[1] != want[1] || keys[2] != want[2] {
+		t.Errorf("DeleteByBlueprint() keys = %v, want %v", keys, want)
+	}
+	if remaining, _ := repo.ListByBlueprint(ctx, blueprintID); len(remaining) != 0 {
+		t.Errorf("expected no assets after deletion, got %d", len(remaining))
+	}
+
+	// Deleting the blueprint row removes any assets left behind
+	record(models.BlueprintAssetOriginal, "test/A-101.pdf", 1000)
+	if _, err := db.Pool.Exec(ctx, `DELETE FROM blueprints WHERE id = $1`, blueprintID); err != nil {
+		t.Fatalf("failed to delete blueprint: %v", err)
+	}
+	if remaining, _ := repo.ListByBlueprint(ctx, blueprintID); len(remaining) != 0 {
+		t.Errorf("expected assets to be removed with the blueprint, got %d", len(remaining))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
@@ -16,15 +17,41 @@ func NewBlueprintRevisionRepository(db *Database) *BlueprintRevisionRepository {
 	return &BlueprintRevisionRepository{db: db}
 }
 
+const blueprintRevisionColumns = `id, blueprint_id, version, filename, s3_key, file_size, mime_type, 
+		       analysis_data, analysis_model, changes_summary, reason, created_by, created_at`
+
+func scanBlueprintRevision(row pgx.Row) (*models.BlueprintRevision, error) {
+	var revision models.BlueprintRevision
+	err := row.Scan(
+		&revision.ID,
+		&revision.BlueprintID,
+		&revision.Version,
+		&revision.Filename,
+		&revision.S3Key,
+		&revision.FileSize,
+		&revision.MimeType,
+		&revision.AnalysisData,
+		&revision.AnalysisModel,
+		&revision.ChangesSummary,
+		&revision.Reason,
+		&revision.CreatedBy,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
 func (r *BlueprintRevisionRepository) Create(ctx context.Context, revision *models.BlueprintRevision) error {
 	query := `
 		INSERT INTO blueprint_revisions (id, blueprint_id, version, filename, s3_key, 
-		                                 file_size, mime_type, analysis_data, changes_summary, 
-		                                 created_by, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		                                 file_size, mime_type, analysis_data, analysis_model, 
+		                                 changes_summary, reason, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		revision.ID,
 		revision.BlueprintID,
 		revision.Version,
@@ -33,7 +60,9 @@ func (r *BlueprintRevisionRepository) Create(ctx context.Context, revision *mode
 		revision.FileSize,
 		revision.MimeType,
 		revision.AnalysisData,
+		revision.AnalysisModel,
 		revision.ChangesSummary,
+		revision.Reason,
 		revision.CreatedBy,
 		revision.CreatedAt,
 	)
@@ -47,38 +76,22 @@ func (r *BlueprintRevisionRepository) Create(ctx context.Context, revision *mode
 
 func (r *BlueprintRevisionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BlueprintRevision, error) {
 	query := `
-		SELECT id, blueprint_id, version, filename, s3_key, file_size, mime_type, 
-		       analysis_data, changes_summary, created_by, created_at
+		SELECT ` + blueprintRevisionColumns + `
 		FROM blueprint_revisions
 		WHERE id = $1
 	`
 
-	var revision models.BlueprintRevision
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&revision.ID,
-		&revision.BlueprintID,
-		&revision.Version,
-		&revision.Filename,
-		&revision.S3Key,
-		&revision.FileSize,
-		&revision.MimeType,
-		&revision.AnalysisData,
-		&revision.ChangesSummary,
-		&revision.CreatedBy,
-		&revision.CreatedAt,
-	)
-
+	revision, err := scanBlueprintRevision(r.db.Pool.QueryRow(ctx, query, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get blueprint revision: %w", err)
 	}
 
-	return &revision, nil
+	return revision, nil
 }
 
 func (r *BlueprintRevisionRepository) GetByBlueprintID(ctx context.Context, blueprintID uuid.UUID) ([]*models.BlueprintRevision, error) {
 	query := `
-		SELECT id, blueprint_id, version, filename, s3_key, file_size, mime_type, 
-		       analysis_data, changes_summary, created_by, created_at
+		SELECT ` + blueprintRevisionColumns + `
 		FROM blueprint_revisions
 		WHERE blueprint_id = $1
 		ORDER BY version DESC
@@ -92,24 +105,11 @@ func (r *BlueprintRevisionRepository) GetByBlueprintID(ctx context.Context, blue
 
 	var revisions []*models.BlueprintRevision
 	for rows.Next() {
-		var revision models.BlueprintRevision
-		err := rows.Scan(
-			&revision.ID,
-			&revision.BlueprintID,
-			&revision.Version,
-			&revision.Filename,
-			&revision.S3Key,
-			&revision.FileSize,
-			&revision.MimeType,
-			&revision.AnalysisData,
-			&revision.ChangesSummary,
-			&revision.CreatedBy,
-			&revision.CreatedAt,
-		)
+		revision, err := scanBlueprintRevision(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan blueprint revision: %w", err)
 		}
-		revisions = append(revisions, &revision)
+		revisions = append(revisions, revision)
 	}
 
 	return revisions, nil
@@ -117,32 +117,17 @@ func (r *BlueprintRevisionRepository) GetByBlueprintID(ctx context.Context, blue
 
 func (r *BlueprintRevisionRepository) GetByVersion(ctx context.Context, blueprintID uuid.UUID, version int) (*models.BlueprintRevision, error) {
 	query := `
-		SELECT id, blueprint_id, version, filename, s3_key, file_size, mime_type, 
-		       analysis_data, changes_summary, created_by, created_at
+		SELECT ` + blueprintRevisionColumns + `
 		FROM blueprint_revisions
 		WHERE blueprint_id = $1 AND version = $2
 	`
 
-	var revision models.BlueprintRevision
-	err := r.db.Pool.QueryRow(ctx, query, blueprintID, version).Scan(
-		&revision.ID,
-		&revision.BlueprintID,
-		&revision.Version,
-		&revision.Filename,
-		&revision.S3Key,
-		&revision.FileSize,
-		&revision.MimeType,
-		&revision.AnalysisData,
-		&revision.ChangesSummary,
-		&revision.CreatedBy,
-		&revision.CreatedAt,
-	)
-
+	revision, err := scanBlueprintRevision(r.db.conn(ctx).QueryRow(ctx, query, blueprintID, version))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get blueprint revision by version: %w", err)
 	}
 
-	return &revision, nil
+	return revision, nil
 }
 
 func (r *BlueprintRevisionRepository) GetLatestVersion(ctx context.Context, blueprintID uuid.UUID) (int, error) {
@@ -153,10 +138,39 @@ func (r *BlueprintRevisionRepository) GetLatestVersion(ctx context.Context, blue
 	`
 
 	var version int
-	err := r.db.Pool.QueryRow(ctx, query, blueprintID).Scan(&version)
+	err := r.db.conn(ctx).QueryRow(ctx, query, blueprintID).Scan(&version)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get latest blueprint version: %w", err)
 	}
 
 	return version, nil
 }
+
+// ListAfter returns up to limit revisions ordered by ID, starting after the
+// given ID, for walking every revision in batches
+func (r *BlueprintRevisionRepository) ListAfter(ctx context.Context, after uuid.UUID, limit int) ([]*models.BlueprintRevision, error) {
+	query := `
+		SELECT ` + blueprintRevisionColumns + `
+		FROM blueprint_revisions
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blueprint revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*models.BlueprintRevision
+	for rows.Next() {
+		revision, err := scanBlueprintRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blueprint revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func seedSearchProject(t *testing.T, db *Database) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	userID := uuid.New()
+	if _, err := db.Pool.Exec(ctx,
+		`INSERT INTO users (id, email, password_hash) VALUES ($1, $2, 'x')`,
+		userID, userID.String()+"@example.com"); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	project := &models.Project{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      "Search Project",
+		Status:    models.ProjectStatusActive,
+		CreatedAt: models.Now(),
+		UpdatedAt: models.Now(),
+	}
+	if err := NewProjectRepository(db).Create(ctx, project); err != nil {
+		t.Fatalf("failed to seed project: %v", err)
+	}
+	return project.ID
+}
+
+func seedSearchBlueprint(t *testing.T, repo *BlueprintRepository, projectID uuid.UUID, filename, text string) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	blueprint := &models.Blueprint{
+		ID:             uuid.New(),
+		ProjectID:      projectID,
+		Filename:       filename,
+		S3Key:          "test/" + filename,
+		UploadStatus:   models.UploadStatusUploaded,
+		AnalysisStatus: models.AnalysisStatusCompleted,
+		Version:        1,
+		IsLatest:       true,
+		CreatedAt:      models.Now(),
+		UpdatedAt:      models.Now(),
+	}
+	if err := repo.Create(ctx, blueprint); err != nil {
+		t.Fatalf("failed to seed blueprint: %v", err)
+	}
+	if err := repo.UpdateOCRText(ctx, blueprint.ID, &text); err != nil {
+		t.Fatalf("failed to store OCR text: %v", err)
+	}
+	return blueprint.ID
+}
+
+func TestBlueprintRepository_SearchOCRText(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewBlueprintRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	otherProjectID := seedSearchProject(t, db)
+
+	strong := seedSearchBlueprint(t, repo, projectID, "E-101.pdf",
+		"Electrical panel schedule. Main panel 200A. Sub panel in garage. Panel clearance 36 inches.")
+	weak := seedSearchBlueprint(t, repo, projectID, "A-101.pdf",
+		"Kitchen layout with island. Electrical panel located in utility room.")
+	seedSearchBlueprint(t, repo, projectID, "P-101.pdf", "Plumbing riser diagram and water heater.")
+	seedSearchBlueprint(t, repo, otherProjectID, "E-201.pdf", "Electrical panel for the other project.")
+
+	t.Run("ranks by relevance", func(t *testing.T) {
+		results, err := repo.SearchOCRText(ctx, projectID, "electrical panel", 10)
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(results))
+		}
+		if results[0].BlueprintID != strong || results[1].BlueprintID != weak {
+			t.Errorf("expected %s ranked above %s, got %+v", strong, weak, results)
+		}
+		if results[0].Rank < results[1].Rank {
+			t.Errorf("expected descending rank, got %f then %f", results[0].Rank, results[1].Rank)
+		}
+	})
+
+	t.Run("snippets highlight matches", func(t *testing.T) {
+		results, err := repo.SearchOCRText(ctx, projectID, "water heater", 10)
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		if results[0].Filename != "P-101.pdf" {
+			t.Errorf("expected filename P-101.pdf, got %s", results[0].Filename)
+		}
+		if !strings.Contains(results[0].Snippet, "<mark>") || !strings.Contains(results[0].Snippet, "</mark>") {
+			t.Errorf("expected highlighted snippet, got %q", results[0].Snippet)
+		}
+	})
+
+	t.Run("scoped to project", func(t *testing.T) {
+		results, err := repo.SearchOCRText(ctx, otherProjectID, "electrical panel", 10)
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Filename != "E-201.pdf" {
+			t.Errorf("expected only the other project's blueprint, got %+v", results)
+		}
+	})
+
+	t.Run("skips superseded versions", func(t *testing.T) {
+		superseded := seedSearchBlueprint(t, repo, projectID, "M-101.pdf", "Ductwork layout with rooftop unit.")
+		blueprint, err := repo.GetByID(ctx, superseded)
+		if err != nil {
+			t.Fatalf("failed to load blueprint: %v", err)
+		}
+		blueprint.IsLatest = false
+		if err := repo.Update(ctx, blueprint); err != nil {
+			t.Fatalf("failed to supersede blueprint: %v", err)
+		}
+
+		results, err := repo.SearchOCRText(ctx, projectID, "rooftop unit", 10)
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("expected superseded versions skipped, got %+v", results)
+		}
+	})
+}
+
+func TestBlueprintRepository_Delete(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewBlueprintRepository(db)
+	jobs := NewJobRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	blueprintID := seedSearchBlueprint(t, repo, projectID, "A-101.pdf", "Floor plan")
+	if err := NewBlueprintRevisionRepository(db).Create(ctx, &models.BlueprintRevision{
+		ID: uuid.New(), BlueprintID: blueprintID, Version: 1, Filename: "A-101.pdf", S3Key: "test/revisions/A-101.pdf", CreatedAt: models.Now(),
+	}); err != nil {
+		t.Fatalf("failed to seed revision: %v", err)
+	}
+	for _, status := range []models.JobStatus{models.JobStatusQueued, models.JobStatusProcessing, models.JobStatusCompleted} {
+		job := &models.Job{ID: uuid.New(), BlueprintID: blueprintID, JobType: models.JobTypeTakeoff, Status: status, CreatedAt: models.Now(), UpdatedAt: models.Now()}
+		if err := jobs.Create(ctx, job); err != nil {
+			t.Fatalf("failed to seed job: %v", err)
+		}
+	}
+
+	if ids, err := NewBidRepository(db).GetIDsByBlueprint(ctx, blueprintID); err != nil || len(ids) != 0 {
+		t.Errorf("GetIDsByBlueprint = %v, %v; want no bids", ids, err)
+	}
+
+	deletion, err := repo.Delete(ctx, blueprintID)
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if want := []string{"test/A-101.pdf", "test/revisions/A-101.pdf"}; !slices.Equal(deletion.S3Keys, want) {
+		t.Errorf("S3Keys = %v, want %v", deletion.S3Keys, want)
+	}
+	if deletion.CancelledJobs != 2 {
+		t.Errorf("CancelledJobs = %d, want the queued and processing jobs", deletion.CancelledJobs)
+	}
+	var remaining int
+	if err := db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM jobs WHERE blueprint_id = $1`, blueprintID).Scan(&remaining); err != nil || remaining != 0 {
+		t.Errorf("%d jobs left (%v), want none", remaining, err)
+	}
+
+	if _, err := repo.Delete(ctx, blueprintID); err != ErrBlueprintNotFound {
+		t.Errorf("second Delete error = %v, want ErrBlueprintNotFound", err)
+	}
+}
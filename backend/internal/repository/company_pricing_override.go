@@ -46,6 +46,37 @@ func (r *CompanyPricingOverrideRepository) GetByUserID(ctx context.Context, user
 	return overrides, rows.Err()
 }
 
+// GetAll returns the material and labor overrides of every user, the ones
+// with an item key
+func (r *CompanyPricingOverrideRepository) GetAll(ctx context.Context) ([]models.CompanyPricingOverride, error) {
+	query := `
+		SELECT id, user_id, override_type, item_key, override_value, is_percentage, notes,
+		       created_at, updated_at
+		FROM company_pricing_overrides
+		WHERE override_type IN ('material', 'labor')
+		ORDER BY user_id, override_type, item_key
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []models.CompanyPricingOverride
+	for rows.Next() {
+		var cpo models.CompanyPricingOverride
+		err := rows.Scan(&cpo.ID, &cpo.UserID, &cpo.OverrideType, &cpo.ItemKey, &cpo.OverrideValue,
+			&cpo.IsPercentage, &cpo.Notes, &cpo.CreatedAt, &cpo.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, cpo)
+	}
+
+	return overrides, rows.Err()
+}
+
 // GetByUserIDAndType returns pricing overrides for a user filtered by type
 func (r *CompanyPricingOverrideRepository) GetByUserIDAndType(ctx context.Context, userID uuid.UUID, overrideType string) ([]models.CompanyPricingOverride, error) {
 	query := `
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+var ErrCompanyProfileNotFound = errors.New("company profile not found")
+
+type CompanyProfileRepository struct {
+	db *Database
+}
+
+func NewCompanyProfileRepository(db *Database) *CompanyProfileRepository {
+	return &CompanyProfileRepository{db: db}
+}
+
+// GetByUserID returns a user's company profile
+func (r *CompanyProfileRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.CompanyProfile, error) {
+	query := `
+		SELECT user_id, name, address, phone, email, website, license_number,
+		       insurance_info, logo_s3_key, budget_ack_threshold_percent,
+		       created_at, updated_at
+		FROM company_profiles
+		WHERE user_id = $1
+	`
+
+	var profile models.CompanyProfile
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(
+		&profile.UserID,
+		&profile.Name,
+		&profile.Address,
+		&profile.Phone,
+		&profile.Email,
+		&profile.Website,
+		&profile.LicenseNumber,
+		&profile.InsuranceInfo,
+		&profile.LogoS3Key,
+		&profile.BudgetAckThresholdPercent,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCompanyProfileNotFound
+		}
+		return nil, fmt.Errorf("failed to get company profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
+// Save creates a user's company profile or replaces its fields. A replaced
+// profile keeps its creation time, which is read back into profile.
+func (r *CompanyProfileRepository) Save(ctx context.Context, profile *models.CompanyProfile) error {
+	query := `
+		INSERT INTO company_profiles (user_id, name, address, phone, email, website,
+		                              license_number, insurance_info, logo_s3_key,
+		                              budget_ack_threshold_percent, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (user_id) DO UPDATE
+		SET name = EXCLUDED.name,
+		    address = EXCLUDED.address,
+		    phone = EXCLUDED.phone,
+		    email = EXCLUDED.email,
+		    website = EXCLUDED.website,
+		    license_number = EXCLUDED.license_number,
+		    insurance_info = EXCLUDED.insurance_info,
+		    logo_s3_key = EXCLUDED.logo_s3_key,
+		    budget_ack_threshold_percent = EXCLUDED.budget_ack_threshold_percent,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		profile.UserID,
+		profile.Name,
+		profile.Address,
+		profile.Phone,
+		profile.Email,
+		profile.Website,
+		profile.LicenseNumber,
+		profile.InsuranceInfo,
+		profile.LogoS3Key,
+		profile.BudgetAckThresholdPercent,
+		profile.CreatedAt,
+		profile.UpdatedAt,
+	).Scan(&profile.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save company profile: %w", err)
+	}
+
+	return nil
+}
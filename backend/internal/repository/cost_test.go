@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"testing"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
@@ -28,9 +27,9 @@ func TestMaterialRepository_CreateAndGet(t *testing.T) {
 		Source:      "test",
 		SourceID:    strPtr("TEST-001"),
 		Region:      strPtr("national"),
-		LastUpdated: time.Now(),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		LastUpdated: models.Now(),
+		CreatedAt:   models.Now(),
+		UpdatedAt:   models.Now(),
 	}
 
 	// This would require a real database connection
@@ -58,9 +57,9 @@ func TestLaborRateRepository_CreateAndGet(t *testing.T) {
 		Source:      "test",
 		SourceID:    strPtr("TEST-LAB-001"),
 		Region:      strPtr("national"),
-		LastUpdated: time.Now(),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		LastUpdated: models.Now(),
+		CreatedAt:   models.Now(),
+		UpdatedAt:   models.Now(),
 	}
 
 	_ = ctx
@@ -80,9 +79,9 @@ func TestRegionalAdjustmentRepository_CreateAndGet(t *testing.T) {
 		City:             strPtr("Test City"),
 		AdjustmentFactor: 1.15,
 		Source:           "test",
-		LastUpdated:      time.Now(),
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+		LastUpdated:      models.Now(),
+		CreatedAt:        models.Now(),
+		UpdatedAt:        models.Now(),
 	}
 
 	_ = ctx
@@ -103,8 +102,8 @@ func TestCompanyPricingOverrideRepository_CreateAndGet(t *testing.T) {
 		OverrideValue: 15.00,
 		IsPercentage:  false,
 		Notes:         strPtr("Test override"),
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		CreatedAt:     models.Now(),
+		UpdatedAt:     models.Now(),
 	}
 
 	_ = ctx
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDatabase_InTx(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	insertUser := func(ctx context.Context, id uuid.UUID) error {
+		_, err := db.conn(ctx).Exec(ctx,
+			`INSERT INTO users (id, email, password_hash) VALUES ($1, $2, 'x')`,
+			id, id.String()+"@example.com")
+		return err
+	}
+	userExists := func(id uuid.UUID) bool {
+		var exists bool
+		if err := db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists); err != nil {
+			t.Fatalf("failed to check user: %v", err)
+		}
+		return exists
+	}
+
+	rolledBack, committed := uuid.New(), uuid.New()
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM users WHERE id = ANY($1)`, []uuid.UUID{rolledBack, committed})
+	})
+
+	failure := errors.New("later write failed")
+	err := db.InTx(ctx, func(ctx context.Context) error {
+		if err := insertUser(ctx, rolledBack); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("InTx() error = %v, want fn's error", err)
+	}
+	if userExists(rolledBack) {
+		t.Error("write before the failure was committed")
+	}
+
+	// A nested call joins the outer transaction
+	err = db.InTx(ctx, func(ctx context.Context) error {
+		return db.InTx(ctx, func(ctx context.Context) error {
+			return insertUser(ctx, committed)
+		})
+	})
+	if err != nil {
+		t.Fatalf("InTx() error = %v", err)
+	}
+	if !userExists(committed) {
+		t.Error("committed write is missing")
+	}
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
@@ -18,7 +21,7 @@ func NewJobRepository(db *Database) *JobRepository {
 
 func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
 	query := `
-		SELECT id, blueprint_id, job_type, status, started_at, completed_at, error_message, result_data, created_at, updated_at, retry_count
+		SELECT id, blueprint_id, job_type, status, started_at, completed_at, error_message, result_data, created_at, updated_at, retry_count, progress, progress_message, upload_generation, target_project_id, reanalyze, bid_id, pdf_request
 		FROM jobs
 		WHERE id = $1
 	`
@@ -36,6 +39,13 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job,
 		&job.CreatedAt,
 		&job.UpdatedAt,
 		&job.RetryCount,
+		&job.Progress,
+		&job.ProgressMessage,
+		&job.UploadGeneration,
+		&job.TargetProjectID,
+		&job.Reanalyze,
+		&job.BidID,
+		&job.PDFRequest,
 	)
 
 	if err != nil {
@@ -47,8 +57,8 @@ func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job,
 
 func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
 	query := `
-		INSERT INTO jobs (id, blueprint_id, job_type, status, started_at, completed_at, error_message, result_data, created_at, updated_at, retry_count)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO jobs (id, blueprint_id, job_type, status, started_at, completed_at, error_message, result_data, created_at, updated_at, retry_count, progress, progress_message, upload_generation, target_project_id, reanalyze, bid_id, pdf_request)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query,
@@ -63,6 +73,13 @@ func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
 		job.CreatedAt,
 		job.UpdatedAt,
 		job.RetryCount,
+		job.Progress,
+		job.ProgressMessage,
+		job.UploadGeneration,
+		job.TargetProjectID,
+		job.Reanalyze,
+		job.BidID,
+		job.PDFRequest,
 	)
 
 	if err != nil {
@@ -75,8 +92,9 @@ func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
 func (r *JobRepository) Update(ctx context.Context, job *models.Job) error {
 	query := `
 		UPDATE jobs
-		SET status = $1, started_at = $2, completed_at = $3, error_message = $4, result_data = $5, updated_at = $6, retry_count = $7
-		WHERE id = $8
+		SET status = $1, started_at = $2, completed_at = $3, error_message = $4, result_data = $5, updated_at = $6, retry_count = $7,
+			progress = $8, progress_message = $9
+		WHERE id = $10
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query,
@@ -87,6 +105,8 @@ func (r *JobRepository) Update(ctx context.Context, job *models.Job) error {
 		job.ResultData,
 		job.UpdatedAt,
 		job.RetryCount,
+		job.Progress,
+		job.ProgressMessage,
 		job.ID,
 	)
 
@@ -97,9 +117,48 @@ func (r *JobRepository) Update(ctx context.Context, job *models.Job) error {
 	return nil
 }
 
+// UpdateProgress records a running job's progress. The update is skipped when
+// the stored progress is already higher, so progress never moves backwards.
+func (r *JobRepository) UpdateProgress(ctx context.Context, id uuid.UUID, progress int, message string) error {
+	query := `
+		UPDATE jobs
+		SET progress = $2, progress_message = $3, updated_at = $4
+		WHERE id = $1 AND progress <= $2
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, id, progress, message, models.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update job progress: %w", err)
+	}
+
+	return nil
+}
+
+// GetAverageDuration returns the mean run time of the most recent completed
+// jobs of a type, or zero when there are none
+func (r *JobRepository) GetAverageDuration(ctx context.Context, jobType models.JobType) (time.Duration, error) {
+	query := `
+		SELECT COALESCE(AVG(EXTRACT(EPOCH FROM (completed_at - started_at))), 0)
+		FROM (
+			SELECT started_at, completed_at
+			FROM jobs
+			WHERE job_type = $1 AND status = $2 AND started_at IS NOT NULL AND completed_at IS NOT NULL
+			ORDER BY completed_at DESC
+			LIMIT 50
+		) recent
+	`
+
+	var seconds float64
+	if err := r.db.Pool.QueryRow(ctx, query, jobType, models.JobStatusCompleted).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("failed to get average job duration: %w", err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
 func (r *JobRepository) GetQueuedJobs(ctx context.Context, limit int) ([]*models.Job, error) {
 	query := `
-		SELECT id, blueprint_id, job_type, status, started_at, completed_at, error_message, result_data, created_at, updated_at, retry_count
+		SELECT id, blueprint_id, job_type, status, started_at, completed_at, error_message, result_data, created_at, updated_at, retry_count, progress, progress_message, upload_generation, target_project_id, reanalyze, bid_id, pdf_request
 		FROM jobs
 		WHERE status = $1
 		ORDER BY created_at ASC
@@ -127,6 +186,13 @@ func (r *JobRepository) GetQueuedJobs(ctx context.Context, limit int) ([]*models
 			&job.CreatedAt,
 			&job.UpdatedAt,
 			&job.RetryCount,
+			&job.Progress,
+			&job.ProgressMessage,
+			&job.UploadGeneration,
+			&job.TargetProjectID,
+			&job.Reanalyze,
+			&job.BidID,
+			&job.PDFRequest,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
@@ -136,3 +202,123 @@ func (r *JobRepository) GetQueuedJobs(ctx context.Context, limit int) ([]*models
 
 	return jobs, nil
 }
+
+// GetStaleJobs returns up to limit processing jobs started before
+// startedBefore, oldest first. Their worker most likely died mid-job.
+func (r *JobRepository) GetStaleJobs(ctx context.Context, startedBefore time.Time, limit int) ([]*models.Job, error) {
+	query := `
+		SELECT id, blueprint_id, job_type, status, started_at, completed_at, error_message, result_data, created_at, updated_at, retry_count, progress, progress_message, upload_generation, target_project_id, reanalyze, bid_id, pdf_request
+		FROM jobs
+		WHERE status = $1 AND started_at < $2
+		ORDER BY started_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, models.JobStatusProcessing, startedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.Job
+	for rows.Next() {
+		var job models.Job
+		err := rows.Scan(
+			&job.ID,
+			&job.BlueprintID,
+			&job.JobType,
+			&job.Status,
+			&job.StartedAt,
+			&job.CompletedAt,
+			&job.ErrorMessage,
+			&job.ResultData,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+			&job.RetryCount,
+			&job.Progress,
+			&job.ProgressMessage,
+			&job.UploadGeneration,
+			&job.TargetProjectID,
+			&job.Reanalyze,
+			&job.BidID,
+			&job.PDFRequest,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// GetActiveTakeoffBlueprintIDs returns the subset of blueprintIDs that already
+// have a queued or processing takeoff job
+func (r *JobRepository) GetActiveTakeoffBlueprintIDs(ctx context.Context, blueprintIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	query := `
+		SELECT DISTINCT blueprint_id
+		FROM jobs
+		WHERE blueprint_id = ANY($1) AND job_type = $2 AND status IN ($3, $4)
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, blueprintIDs, models.JobTypeTakeoff, models.JobStatusQueued, models.JobStatusProcessing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active jobs: %w", err)
+	}
+	defer rows.Close()
+
+	active := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan blueprint ID: %w", err)
+		}
+		active[id] = true
+	}
+
+	return active, nil
+}
+
+// GetActiveBidPDFJob returns the newest queued or processing PDF generation
+// job for a bid, or nil when there is none
+func (r *JobRepository) GetActiveBidPDFJob(ctx context.Context, bidID uuid.UUID) (*models.Job, error) {
+	query := `
+		SELECT id
+		FROM jobs
+		WHERE bid_id = $1 AND job_type = $2 AND status IN ($3, $4)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var id uuid.UUID
+	err := r.db.Pool.QueryRow(ctx, query, bidID, models.JobTypePDFGeneration, models.JobStatusQueued, models.JobStatusProcessing).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active PDF job: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// GetQueueDepth counts queued jobs overall and for the given user's projects in a
+// single query. The result is approximate under concurrent inserts, which is
+// acceptable for backpressure.
+func (r *JobRepository) GetQueueDepth(ctx context.Context, userID uuid.UUID) (models.QueueDepth, error) {
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE p.user_id = $2)
+		FROM jobs j
+		JOIN blueprints b ON b.id = j.blueprint_id
+		JOIN projects p ON p.id = b.project_id
+		WHERE j.status = $1
+	`
+
+	var depth models.QueueDepth
+	err := r.db.Pool.QueryRow(ctx, query, models.JobStatusQueued, userID).Scan(&depth.Total, &depth.User)
+	if err != nil {
+		return depth, fmt.Errorf("failed to get queue depth: %w", err)
+	}
+
+	return depth, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestJobRepository_GetQueueDepth(t *testing.T) {
+	db := newTestDatabase(t)
+	jobRepo := NewJobRepository(db)
+	blueprintRepo := NewBlueprintRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	project, err := NewProjectRepository(db).GetByID(ctx, projectID)
+	if err != nil {
+		t.Fatalf("failed to load project: %v", err)
+	}
+	blueprintID := seedSearchBlueprint(t, blueprintRepo, projectID, "Q-101.pdf", "queue depth")
+
+	before, err := jobRepo.GetQueueDepth(ctx, project.UserID)
+	if err != nil {
+		t.Fatalf("GetQueueDepth failed: %v", err)
+	}
+	if before.User != 0 {
+		t.Fatalf("expected empty user queue, got %d", before.User)
+	}
+
+	var jobs []*models.Job
+	for i := 0; i < 3; i++ {
+		job := &models.Job{
+			ID:          uuid.New(),
+			BlueprintID: blueprintID,
+			JobType:     models.JobTypeTakeoff,
+			Status:      models.JobStatusQueued,
+			CreatedAt:   models.Now(),
+			UpdatedAt:   models.Now(),
+		}
+		if err := jobRepo.Create(ctx, job); err != nil {
+			t.Fatalf("failed to seed job: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	full, err := jobRepo.GetQueueDepth(ctx, project.UserID)
+	if err != nil {
+		t.Fatalf("GetQueueDepth failed: %v", err)
+	}
+	if full.User != 3 {
+		t.Errorf("expected user depth 3, got %d", full.User)
+	}
+	if full.Total < before.Total+3 {
+		t.Errorf("expected total depth at least %d, got %d", before.Total+3, full.Total)
+	}
+
+	// Completing a job frees capacity
+	now := models.Now()
+	jobs[0].Status = models.JobStatusCompleted
+	jobs[0].CompletedAt = &now
+	jobs[0].UpdatedAt = now
+	if err := jobRepo.Update(ctx, jobs[0]); err != nil {
+		t.Fatalf("failed to complete job: %v", err)
+	}
+
+	after, err := jobRepo.GetQueueDepth(ctx, project.UserID)
+	if err != nil {
+		t.Fatalf("GetQueueDepth failed: %v", err)
+	}
+	if after.User != 2 {
+		t.Errorf("expected user depth 2 after completion, got %d", after.User)
+	}
+}
+
+func TestJobRepository_UpdateProgress(t *testing.T) {
+	db := newTestDatabase(t)
+	jobRepo := NewJobRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	blueprintID := seedSearchBlueprint(t, NewBlueprintRepository(db), projectID, "P-101.pdf", "progress")
+
+	job := &models.Job{
+		ID:          uuid.New(),
+		BlueprintID: blueprintID,
+		JobType:     models.JobTypeTakeoff,
+		Status:      models.JobStatusProcessing,
+		CreatedAt:   models.Now(),
+		UpdatedAt:   models.Now(),
+	}
+	if err := jobRepo.Create(ctx, job); err != nil {
+		t.Fatalf("failed to seed job: %v", err)
+	}
+
+	if err := jobRepo.UpdateProgress(ctx, job.ID, 40, "Analyzing blueprint"); err != nil {
+		t.Fatalf("UpdateProgress failed: %v", err)
+	}
+	// A late, lower report does not move progress backwards
+	if err := jobRepo.UpdateProgress(ctx, job.ID, 20, "Submitted for AI analysis"); err != nil {
+		t.Fatalf("UpdateProgress failed: %v", err)
+	}
+
+	stored, err := jobRepo.GetByID(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if stored.Progress != 40 || stored.ProgressMessage == nil || *stored.ProgressMessage != "Analyzing blueprint" {
+		t.Errorf("progress = %d %v, want 40 Analyzing blueprint", stored.Progress, stored.ProgressMessage)
+	}
+}
+
+func TestJobRepository_GetStaleJobs(t *testing.T) {
+	db := newTestDatabase(t)
+	jobRepo := NewJobRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	blueprintID := seedSearchBlueprint(t, NewBlueprintRepository(db), projectID, "S-101.pdf", "stale")
+
+	cutoff := time.Now().UTC().Add(-10 * time.Minute)
+	seed := func(status models.JobStatus, startedAt time.Time) *models.Job {
+		started := models.NewTimestamp(startedAt)
+		job := &models.Job{
+			ID:          uuid.New(),
+			BlueprintID: blueprintID,
+			JobType:     models.JobTypeTakeoff,
+			Status:      status,
+			StartedAt:   &started,
+			CreatedAt:   models.Now(),
+			UpdatedAt:   models.Now(),
+		}
+		if err := jobRepo.Create(ctx, job); err != nil {
+			t.Fatalf("failed to seed job: %v", err)
+		}
+		return job
+	}
+	stale := seed(models.JobStatusProcessing, cutoff.Add(-time.Minute))
+	seed(models.JobStatusProcessing, cutoff.Add(time.Minute))
+	seed(models.JobStatusFailed, cutoff.Add(-time.Hour))
+
+	jobs, err := jobRepo.GetStaleJobs(ctx, cutoff, 100)
+	if err != nil {
+		t.Fatalf("GetStaleJobs failed: %v", err)
+	}
+	var found []uuid.UUID
+	for _, job := range jobs {
+		if job.BlueprintID == blueprintID {
+			found = append(found, job.ID)
+		}
+	}
+	if len(found) != 1 || found[0] != stale.ID {
+		t.Errorf("stale jobs = %v, want only the job processing since before the cutoff", found)
+	}
+}
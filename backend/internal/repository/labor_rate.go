@@ -2,13 +2,20 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
+// ErrLaborRateExists is returned when a labor rate is already stored for the
+// same trade and region
+var ErrLaborRateExists = errors.New("labor rate already exists")
+
 type LaborRateRepository struct {
 	db *pgxpool.Pool
 }
@@ -20,7 +27,7 @@ func NewLaborRateRepository(db *pgxpool.Pool) *LaborRateRepository {
 // GetAll returns all labor rates, optionally filtered by trade and region
 func (r *LaborRateRepository) GetAll(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
 	query := `
-		SELECT id, trade, description, hourly_rate, source, source_id, region,
+		SELECT id, trade, description, hourly_rate, source, source_id, region, created_by,
 		       last_updated, created_at, updated_at
 		FROM labor_rates
 		WHERE 1=1
@@ -51,7 +58,7 @@ func (r *LaborRateRepository) GetAll(ctx context.Context, trade, region *string)
 	for rows.Next() {
 		var lr models.LaborRate
 		err := rows.Scan(&lr.ID, &lr.Trade, &lr.Description, &lr.HourlyRate, &lr.Source,
-			&lr.SourceID, &lr.Region, &lr.LastUpdated, &lr.CreatedAt, &lr.UpdatedAt)
+			&lr.SourceID, &lr.Region, &lr.CreatedBy, &lr.LastUpdated, &lr.CreatedAt, &lr.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -64,7 +71,7 @@ func (r *LaborRateRepository) GetAll(ctx context.Context, trade, region *string)
 // GetByID returns a labor rate by ID
 func (r *LaborRateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.LaborRate, error) {
 	query := `
-		SELECT id, trade, description, hourly_rate, source, source_id, region,
+		SELECT id, trade, description, hourly_rate, source, source_id, region, created_by,
 		       last_updated, created_at, updated_at
 		FROM labor_rates
 		WHERE id = $1
@@ -73,7 +80,7 @@ func (r *LaborRateRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 	var lr models.LaborRate
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&lr.ID, &lr.Trade, &lr.Description, &lr.HourlyRate, &lr.Source,
-		&lr.SourceID, &lr.Region, &lr.LastUpdated, &lr.CreatedAt, &lr.UpdatedAt,
+		&lr.SourceID, &lr.Region, &lr.CreatedBy, &lr.LastUpdated, &lr.CreatedAt, &lr.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -85,7 +92,7 @@ func (r *LaborRateRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 // GetByTrade returns a labor rate by trade and optional region
 func (r *LaborRateRepository) GetByTrade(ctx context.Context, trade string, region *string) (*models.LaborRate, error) {
 	query := `
-		SELECT id, trade, description, hourly_rate, source, source_id, region,
+		SELECT id, trade, description, hourly_rate, source, source_id, region, created_by,
 		       last_updated, created_at, updated_at
 		FROM labor_rates
 		WHERE trade = $1
@@ -102,7 +109,7 @@ func (r *LaborRateRepository) GetByTrade(ctx context.Context, trade string, regi
 	var lr models.LaborRate
 	err := r.db.QueryRow(ctx, query, args...).Scan(
 		&lr.ID, &lr.Trade, &lr.Description, &lr.HourlyRate, &lr.Source,
-		&lr.SourceID, &lr.Region, &lr.LastUpdated, &lr.CreatedAt, &lr.UpdatedAt,
+		&lr.SourceID, &lr.Region, &lr.CreatedBy, &lr.LastUpdated, &lr.CreatedAt, &lr.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -111,20 +118,26 @@ func (r *LaborRateRepository) GetByTrade(ctx context.Context, trade string, regi
 	return &lr, nil
 }
 
-// Create creates a new labor rate
+// Create creates a new labor rate. A rate already stored for the same trade
+// and region returns ErrLaborRateExists.
 func (r *LaborRateRepository) Create(ctx context.Context, rate *models.LaborRate) error {
 	query := `
-		INSERT INTO labor_rates (id, trade, description, hourly_rate, source, source_id, region, last_updated, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO labor_rates (id, trade, description, hourly_rate, source, source_id, region, created_by, last_updated, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 	_, err := r.db.Exec(ctx, query,
 		rate.ID, rate.Trade, rate.Description, rate.HourlyRate, rate.Source,
-		rate.SourceID, rate.Region, rate.LastUpdated, rate.CreatedAt, rate.UpdatedAt,
+		rate.SourceID, rate.Region, rate.CreatedBy, rate.LastUpdated, rate.CreatedAt, rate.UpdatedAt,
 	)
+	// PostgreSQL error code 23505 is unique_violation
+	if err != nil && strings.Contains(err.Error(), "23505") {
+		return ErrLaborRateExists
+	}
 	return err
 }
 
-// Update updates a labor rate
+// Update updates a labor rate. Moving it to the trade and region of another
+// returns ErrLaborRateExists.
 func (r *LaborRateRepository) Update(ctx context.Context, rate *models.LaborRate) error {
 	query := `
 		UPDATE labor_rates
@@ -136,9 +149,69 @@ func (r *LaborRateRepository) Update(ctx context.Context, rate *models.LaborRate
 		rate.ID, rate.Trade, rate.Description, rate.HourlyRate, rate.Source,
 		rate.SourceID, rate.Region, rate.LastUpdated, rate.UpdatedAt,
 	)
+	if err != nil && strings.Contains(err.Error(), "23505") {
+		return ErrLaborRateExists
+	}
 	return err
 }
 
+// UpsertBatch stores labor rates in one statement, inserting new ones and
+// updating the rate and description of those already stored for the same
+// trade and region. It returns how many rows were created and how many were
+// updated. The batch must not repeat a trade and region.
+func (r *LaborRateRepository) UpsertBatch(ctx context.Context, rates []models.LaborRate, at time.Time) (created, updated int, err error) {
+	if len(rates) == 0 {
+		return 0, 0, nil
+	}
+
+	var (
+		trades, sources                  []string
+		descriptions, sourceIDs, regions []*string
+		hourlyRates                      []float64
+	)
+	for _, rate := range rates {
+		trades = append(trades, rate.Trade)
+		descriptions = append(descriptions, rate.Description)
+		hourlyRates = append(hourlyRates, rate.HourlyRate)
+		sources = append(sources, rate.Source)
+		sourceIDs = append(sourceIDs, rate.SourceID)
+		regions = append(regions, rate.Region)
+	}
+
+	query := `
+		INSERT INTO labor_rates (trade, description, hourly_rate, source, source_id, region, last_updated, created_at, updated_at)
+		SELECT trade, description, hourly_rate, source, source_id, region, $7, $7, $7
+		FROM unnest($1::text[], $2::text[], $3::numeric[], $4::text[], $5::text[], $6::text[])
+		     AS lr(trade, description, hourly_rate, source, source_id, region)
+		ON CONFLICT (trade, region) DO UPDATE
+		SET hourly_rate = EXCLUDED.hourly_rate, description = EXCLUDED.description,
+		    last_updated = EXCLUDED.last_updated, updated_at = EXCLUDED.updated_at
+		RETURNING (xmax = 0)
+	`
+	rows, err := r.db.Query(ctx, query,
+		trades, descriptions, hourlyRates, sources, sourceIDs, regions, models.NewTimestamp(at))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to upsert labor rates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var inserted bool
+		if err := rows.Scan(&inserted); err != nil {
+			return 0, 0, fmt.Errorf("failed to upsert labor rates: %w", err)
+		}
+		if inserted {
+			created++
+		} else {
+			updated++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to upsert labor rates: %w", err)
+	}
+	return created, updated, nil
+}
+
 // Delete deletes a labor rate
 func (r *LaborRateRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM labor_rates WHERE id = $1`
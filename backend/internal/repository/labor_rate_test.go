@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestLaborRateRepository_UpsertBatch(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewLaborRateRepository(db.Pool)
+	ctx := context.Background()
+
+	// A region unique to this run keeps the rows apart from seeded rates
+	region := "upsert_test_" + uuid.NewString()[:8]
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), "DELETE FROM labor_rates WHERE region = $1", region)
+	})
+	rate := func(trade string, hourly float64) models.LaborRate {
+		return models.LaborRate{Trade: trade, HourlyRate: hourly, Source: "rsmeans", Region: &region}
+	}
+
+	at := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	created, updated, err := repo.UpsertBatch(ctx, []models.LaborRate{rate("carpentry", 78), rate("electrical", 98)}, at)
+	if err != nil || created != 2 || updated != 0 {
+		t.Fatalf("first UpsertBatch = %d created, %d updated, %v; want 2 created", created, updated, err)
+	}
+
+	created, updated, err = repo.UpsertBatch(ctx, []models.LaborRate{rate("carpentry", 82)}, at.Add(time.Hour))
+	if err != nil || created != 0 || updated != 1 {
+		t.Fatalf("second UpsertBatch = %d created, %d updated, %v; want 1 updated", created, updated, err)
+	}
+
+	carpentry, err := repo.GetByTrade(ctx, "carpentry", &region)
+	if err != nil {
+		t.Fatalf("GetByTrade failed: %v", err)
+	}
+	if carpentry.HourlyRate != 82 {
+		t.Errorf("carpentry = %+v, want the second batch's rate", carpentry)
+	}
+}
@@ -2,13 +2,20 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
+// ErrMaterialExists is returned when a material is already stored under the
+// same name and region
+var ErrMaterialExists = errors.New("material already exists")
+
 type MaterialRepository struct {
 	db *pgxpool.Pool
 }
@@ -20,7 +27,7 @@ func NewMaterialRepository(db *pgxpool.Pool) *MaterialRepository {
 // GetAll returns all materials, optionally filtered by category and region
 func (r *MaterialRepository) GetAll(ctx context.Context, category, region *string) ([]models.MaterialCost, error) {
 	query := `
-		SELECT id, name, description, category, unit, base_price, source, source_id, region, 
+		SELECT id, name, description, category, unit, base_price, source, source_id, region, created_by,
 		       last_updated, created_at, updated_at
 		FROM materials
 		WHERE 1=1
@@ -51,7 +58,7 @@ func (r *MaterialRepository) GetAll(ctx context.Context, category, region *strin
 	for rows.Next() {
 		var m models.MaterialCost
 		err := rows.Scan(&m.ID, &m.Name, &m.Description, &m.Category, &m.Unit, &m.BasePrice,
-			&m.Source, &m.SourceID, &m.Region, &m.LastUpdated, &m.CreatedAt, &m.UpdatedAt)
+			&m.Source, &m.SourceID, &m.Region, &m.CreatedBy, &m.LastUpdated, &m.CreatedAt, &m.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -61,10 +68,69 @@ func (r *MaterialRepository) GetAll(ctx context.Context, category, region *strin
 	return materials, rows.Err()
 }
 
+// materialListClause builds the WHERE clause for a materials list,
+// numbering placeholders from $1
+func materialListClause(filter models.MaterialListFilter) (string, []interface{}) {
+	clause := "1=1"
+	var args []interface{}
+	if filter.Category != nil {
+		args = append(args, *filter.Category)
+		clause += fmt.Sprintf(" AND category = $%d", len(args))
+	}
+	if filter.Region != nil {
+		args = append(args, *filter.Region)
+		clause += fmt.Sprintf(" AND (region = $%d OR region = 'national' OR region IS NULL)", len(args))
+	}
+	if filter.Query != nil && *filter.Query != "" {
+		args = append(args, "%"+escapeLike(*filter.Query)+"%")
+		clause += fmt.Sprintf(" AND name ILIKE $%d", len(args))
+	}
+	return clause, args
+}
+
+// List returns one page of materials, optionally filtered by category,
+// region and name substring, and the number matching across all pages
+func (r *MaterialRepository) List(ctx context.Context, filter models.MaterialListFilter) ([]models.MaterialCost, int, error) {
+	clause, args := materialListClause(filter)
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM materials WHERE "+clause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count materials: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, name, description, category, unit, base_price, source, source_id, region, created_by,
+		       last_updated, created_at, updated_at
+		FROM materials
+		WHERE %s
+		ORDER BY category, name, id
+		LIMIT $%d OFFSET $%d
+	`, clause, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list materials: %w", err)
+	}
+	defer rows.Close()
+
+	materials := []models.MaterialCost{}
+	for rows.Next() {
+		var m models.MaterialCost
+		err := rows.Scan(&m.ID, &m.Name, &m.Description, &m.Category, &m.Unit, &m.BasePrice,
+			&m.Source, &m.SourceID, &m.Region, &m.CreatedBy, &m.LastUpdated, &m.CreatedAt, &m.UpdatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan material: %w", err)
+		}
+		materials = append(materials, m)
+	}
+
+	return materials, total, rows.Err()
+}
+
 // GetByID returns a material by ID
 func (r *MaterialRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MaterialCost, error) {
 	query := `
-		SELECT id, name, description, category, unit, base_price, source, source_id, region,
+		SELECT id, name, description, category, unit, base_price, source, source_id, region, created_by,
 		       last_updated, created_at, updated_at
 		FROM materials
 		WHERE id = $1
@@ -73,7 +139,7 @@ func (r *MaterialRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	var m models.MaterialCost
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&m.ID, &m.Name, &m.Description, &m.Category, &m.Unit, &m.BasePrice,
-		&m.Source, &m.SourceID, &m.Region, &m.LastUpdated, &m.CreatedAt, &m.UpdatedAt,
+		&m.Source, &m.SourceID, &m.Region, &m.CreatedBy, &m.LastUpdated, &m.CreatedAt, &m.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -85,7 +151,7 @@ func (r *MaterialRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 // GetByName returns a material by name and optional region
 func (r *MaterialRepository) GetByName(ctx context.Context, name string, region *string) (*models.MaterialCost, error) {
 	query := `
-		SELECT id, name, description, category, unit, base_price, source, source_id, region,
+		SELECT id, name, description, category, unit, base_price, source, source_id, region, created_by,
 		       last_updated, created_at, updated_at
 		FROM materials
 		WHERE name = $1
@@ -102,7 +168,7 @@ func (r *MaterialRepository) GetByName(ctx context.Context, name string, region
 	var m models.MaterialCost
 	err := r.db.QueryRow(ctx, query, args...).Scan(
 		&m.ID, &m.Name, &m.Description, &m.Category, &m.Unit, &m.BasePrice,
-		&m.Source, &m.SourceID, &m.Region, &m.LastUpdated, &m.CreatedAt, &m.UpdatedAt,
+		&m.Source, &m.SourceID, &m.Region, &m.CreatedBy, &m.LastUpdated, &m.CreatedAt, &m.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -111,21 +177,27 @@ func (r *MaterialRepository) GetByName(ctx context.Context, name string, region
 	return &m, nil
 }
 
-// Create creates a new material
+// Create creates a new material. A material already stored under the same
+// name and region returns ErrMaterialExists.
 func (r *MaterialRepository) Create(ctx context.Context, material *models.MaterialCost) error {
 	query := `
-		INSERT INTO materials (id, name, description, category, unit, base_price, source, source_id, region, last_updated, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO materials (id, name, description, category, unit, base_price, source, source_id, region, created_by, last_updated, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 	_, err := r.db.Exec(ctx, query,
 		material.ID, material.Name, material.Description, material.Category, material.Unit,
-		material.BasePrice, material.Source, material.SourceID, material.Region,
+		material.BasePrice, material.Source, material.SourceID, material.Region, material.CreatedBy,
 		material.LastUpdated, material.CreatedAt, material.UpdatedAt,
 	)
+	// PostgreSQL error code 23505 is unique_violation
+	if err != nil && strings.Contains(err.Error(), "23505") {
+		return ErrMaterialExists
+	}
 	return err
 }
 
-// Update updates a material
+// Update updates a material. Renaming it to the name and region of another
+// returns ErrMaterialExists.
 func (r *MaterialRepository) Update(ctx context.Context, material *models.MaterialCost) error {
 	query := `
 		UPDATE materials
@@ -138,12 +210,135 @@ func (r *MaterialRepository) Update(ctx context.Context, material *models.Materi
 		material.BasePrice, material.Source, material.SourceID, material.Region,
 		material.LastUpdated, material.UpdatedAt,
 	)
+	if err != nil && strings.Contains(err.Error(), "23505") {
+		return ErrMaterialExists
+	}
 	return err
 }
 
+// UpsertBatch stores materials in one statement, inserting new ones and
+// updating the price and description of those already stored under the same
+// name and region. It returns how many rows were created and how many were
+// updated. The batch must not repeat a name and region.
+func (r *MaterialRepository) UpsertBatch(ctx context.Context, materials []models.MaterialCost, at time.Time) (created, updated int, err error) {
+	if len(materials) == 0 {
+		return 0, 0, nil
+	}
+
+	var (
+		names, categories, units, sources []string
+		descriptions, sourceIDs, regions  []*string
+		prices                            []float64
+	)
+	for _, m := range materials {
+		names = append(names, m.Name)
+		descriptions = append(descriptions, m.Description)
+		categories = append(categories, m.Category)
+		units = append(units, m.Unit)
+		prices = append(prices, m.BasePrice)
+		sources = append(sources, m.Source)
+		sourceIDs = append(sourceIDs, m.SourceID)
+		regions = append(regions, m.Region)
+	}
+
+	query := `
+		INSERT INTO materials (name, description, category, unit, base_price, source, source_id, region, last_updated, created_at, updated_at)
+		SELECT name, description, category, unit, base_price, source, source_id, region, $9, $9, $9
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::numeric[], $6::text[], $7::text[], $8::text[])
+		     AS m(name, description, category, unit, base_price, source, source_id, region)
+		ON CONFLICT (name, region) DO UPDATE
+		SET base_price = EXCLUDED.base_price, description = EXCLUDED.description,
+		    last_updated = EXCLUDED.last_updated, updated_at = EXCLUDED.updated_at
+		RETURNING (xmax = 0)
+	`
+	rows, err := r.db.Query(ctx, query,
+		names, descriptions, categories, units, prices, sources, sourceIDs, regions, models.NewTimestamp(at))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to upsert materials: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var inserted bool
+		if err := rows.Scan(&inserted); err != nil {
+			return 0, 0, fmt.Errorf("failed to upsert materials: %w", err)
+		}
+		if inserted {
+			created++
+		} else {
+			updated++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to upsert materials: %w", err)
+	}
+	return created, updated, nil
+}
+
 // Delete deletes a material
 func (r *MaterialRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM materials WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, id)
 	return err
 }
+
+// BulkAdjustPrices multiplies base_price by factor for every material matching
+// filter in a single UPDATE and returns stats over the new prices. With dryRun
+// the same stats are computed without writing.
+func (r *MaterialRepository) BulkAdjustPrices(ctx context.Context, filter models.MaterialPriceFilter, factor float64, dryRun bool) (*models.PriceAdjustmentStats, error) {
+	where, args := materialFilterClause(filter, 2)
+	args = append([]interface{}{factor}, args...)
+
+	var query string
+	if dryRun {
+		query = `
+			SELECT COUNT(*), COALESCE(MIN(ROUND(base_price * $1, 2)), 0),
+			       COALESCE(MAX(ROUND(base_price * $1, 2)), 0), COALESCE(AVG(ROUND(base_price * $1, 2)), 0)
+			FROM materials
+			WHERE ` + where
+	} else {
+		query = `
+			WITH updated AS (
+				UPDATE materials
+				SET base_price = ROUND(base_price * $1, 2), updated_at = NOW()
+				WHERE ` + where + `
+				RETURNING base_price
+			)
+			SELECT COUNT(*), COALESCE(MIN(base_price), 0), COALESCE(MAX(base_price), 0), COALESCE(AVG(base_price), 0)
+			FROM updated`
+	}
+
+	stats := &models.PriceAdjustmentStats{DryRun: dryRun}
+	err := r.db.QueryRow(ctx, query, args...).Scan(&stats.RowsAffected, &stats.MinPrice, &stats.MaxPrice, &stats.AvgPrice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust material prices: %w", err)
+	}
+
+	return stats, nil
+}
+
+// materialFilterClause builds the WHERE clause for a material price filter,
+// numbering placeholders from firstArg
+func materialFilterClause(filter models.MaterialPriceFilter, firstArg int) (string, []interface{}) {
+	clause := "1=1"
+	args := []interface{}{}
+	argCount := firstArg
+
+	for _, cond := range []struct {
+		column string
+		value  *string
+	}{
+		{"category", filter.Category},
+		{"region", filter.Region},
+		{"source", filter.Source},
+	} {
+		if cond.value == nil {
+			continue
+		}
+		clause += fmt.Sprintf(" AND %s = $%d", cond.column, argCount)
+		args = append(args, *cond.value)
+		argCount++
+	}
+
+	return clause, args
+}
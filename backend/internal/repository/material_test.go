@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestMaterialFilterClause(t *testing.T) {
+	category, source := "lumber", "custom"
+
+	clause, args := materialFilterClause(models.MaterialPriceFilter{Category: &category, Source: &source}, 2)
+
+	if clause != "1=1 AND category = $2 AND source = $3" {
+		t.Errorf("unexpected clause %q", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"lumber", "custom"}) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	if clause, args := materialFilterClause(models.MaterialPriceFilter{}, 2); clause != "1=1" || len(args) != 0 {
+		t.Errorf("expected empty filter to match all rows, got %q %v", clause, args)
+	}
+}
+
+func TestMaterialListClause(t *testing.T) {
+	category, region, query := "lumber", "west", "2x4_"
+
+	clause, args := materialListClause(models.MaterialListFilter{Category: &category, Region: &region, Query: &query})
+
+	want := "1=1 AND category = $1 AND (region = $2 OR region = 'national' OR region IS NULL) AND name ILIKE $3"
+	if clause != want {
+		t.Errorf("unexpected clause %q", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"lumber", "west", `%2x4\_%`}) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	blank := ""
+	if clause, args := materialListClause(models.MaterialListFilter{Query: &blank}); clause != "1=1" || len(args) != 0 {
+		t.Errorf("expected a blank query to match all rows, got %q %v", clause, args)
+	}
+}
+
+func TestMaterialRepository_BulkAdjustPrices(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewMaterialRepository(db.Pool)
+	ctx := context.Background()
+
+	// A category unique to this run keeps the filter scoped to seeded rows
+	category := "bulk_test_" + uuid.NewString()[:8]
+	region := "national"
+	seed := func(name, source string, price float64) uuid.UUID {
+		id := uuid.New()
+		_, err := db.Pool.Exec(ctx, `
+			INSERT INTO materials (id, name, category, unit, base_price, source, region, last_updated, created_at, updated_at)
+			VALUES ($1, $2, $3, 'each', $4, $5, $6, $7, $7, $7)`,
+			id, fmt.Sprintf("%s %s", name, category), category, price, source, region, time.Now())
+		if err != nil {
+			t.Fatalf("failed to seed material: %v", err)
+		}
+		return id
+	}
+	manual := seed("Manual", "custom", 100)
+	synced := seed("Synced", "lowes", 200)
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), "DELETE FROM materials WHERE category = $1", category)
+	})
+
+	priceOf := func(id uuid.UUID) float64 {
+		var price float64
+		if err := db.Pool.QueryRow(ctx, "SELECT base_price FROM materials WHERE id = $1", id).Scan(&price); err != nil {
+			t.Fatalf("failed to read price: %v", err)
+		}
+		return price
+	}
+
+	filter := models.MaterialPriceFilter{Category: &category}
+
+	preview, err := repo.BulkAdjustPrices(ctx, filter, 1.08, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if preview.RowsAffected != 2 || preview.MinPrice != 108 || preview.MaxPrice != 216 || preview.AvgPrice != 162 {
+		t.Errorf("unexpected dry run stats: %+v", preview)
+	}
+	if priceOf(manual) != 100 {
+		t.Error("dry run must not write prices")
+	}
+
+	source := "custom"
+	stats, err := repo.BulkAdjustPrices(ctx, models.MaterialPriceFilter{Category: &category, Source: &source}, 1.08, false)
+	if err != nil {
+		t.Fatalf("adjustment failed: %v", err)
+	}
+	if stats.RowsAffected != 1 || stats.MinPrice != 108 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if priceOf(manual) != 108 || priceOf(synced) != 200 {
+		t.Errorf("expected only the custom row to change, got manual=%v synced=%v", priceOf(manual), priceOf(synced))
+	}
+}
+
+func TestMaterialRepository_UpsertBatch(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewMaterialRepository(db.Pool)
+	ctx := context.Background()
+
+	category := "upsert_test_" + uuid.NewString()[:8]
+	region := "national"
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), "DELETE FROM materials WHERE category = $1", category)
+	})
+	material := func(name string, price float64) models.MaterialCost {
+		return models.MaterialCost{Name: name + " " + category, Category: category, Unit: "each", BasePrice: price, Source: "lowes", Region: &region}
+	}
+
+	at := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	created, updated, err := repo.UpsertBatch(ctx, []models.MaterialCost{material("Door", 400), material("Window", 800)}, at)
+	if err != nil || created != 2 || updated != 0 {
+		t.Fatalf("first UpsertBatch = %d created, %d updated, %v; want 2 created", created, updated, err)
+	}
+
+	created, updated, err = repo.UpsertBatch(ctx, []models.MaterialCost{material("Door", 450), material("Trim", 12)}, at.Add(time.Hour))
+	if err != nil || created != 1 || updated != 1 {
+		t.Fatalf("second UpsertBatch = %d created, %d updated, %v; want 1 of each", created, updated, err)
+	}
+
+	door, err := repo.GetByName(ctx, "Door "+category, &region)
+	if err != nil {
+		t.Fatalf("GetByName failed: %v", err)
+	}
+	if door.BasePrice != 450 || !door.LastUpdated.Time.Equal(at.Add(time.Hour)) {
+		t.Errorf("door = %+v, want the second batch's price and time", door)
+	}
+	materials, err := repo.GetAll(ctx, &category, &region)
+	if err != nil || len(materials) != 3 {
+		t.Errorf("GetAll = %d materials, %v; want 3", len(materials), err)
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type ObjectDeletionRepository struct {
+	db *Database
+}
+
+func NewObjectDeletionRepository(db *Database) *ObjectDeletionRepository {
+	return &ObjectDeletionRepository{db: db}
+}
+
+// Schedule records that the object at key should be deleted after deleteAfter
+func (r *ObjectDeletionRepository) Schedule(ctx context.Context, key string, deleteAfter time.Time) error {
+	query := `
+		INSERT INTO object_deletions (id, s3_key, delete_after, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, uuid.New(), key, models.NewTimestamp(deleteAfter), models.Now())
+	if err != nil {
+		return fmt.Errorf("failed to schedule object deletion: %w", err)
+	}
+
+	return nil
+}
+
+// GetDue returns up to limit deletions whose retention window has passed, oldest first
+func (r *ObjectDeletionRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]*models.ObjectDeletion, error) {
+	query := `
+		SELECT id, s3_key, delete_after, created_at
+		FROM object_deletions
+		WHERE delete_after <= $1
+		ORDER BY delete_after ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, models.NewTimestamp(now), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due object deletions: %w", err)
+	}
+	defer rows.Close()
+
+	var deletions []*models.ObjectDeletion
+	for rows.Next() {
+		var deletion models.ObjectDeletion
+		if err := rows.Scan(&deletion.ID, &deletion.S3Key, &deletion.DeleteAfter, &deletion.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan object deletion: %w", err)
+		}
+		deletions = append(deletions, &deletion)
+	}
+
+	return deletions, rows.Err()
+}
+
+// Delete removes a scheduled deletion once its object is gone
+func (r *ObjectDeletionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM object_deletions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete object deletion: %w", err)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
@@ -16,43 +17,105 @@ func NewProjectRepository(db *Database) *ProjectRepository {
 	return &ProjectRepository{db: db}
 }
 
-func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
-	query := `
-		SELECT id, user_id, name, description, status, created_at, updated_at
-		FROM projects
-		WHERE id = $1
-	`
+const projectColumns = `id, user_id, name, description, status, project_type, budget, region, client_name, tax_exempt, created_at, updated_at`
 
+func scanProject(row pgx.Row) (*models.Project, error) {
 	var project models.Project
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&project.ID,
 		&project.UserID,
 		&project.Name,
 		&project.Description,
 		&project.Status,
+		&project.ProjectType,
+		&project.Budget,
+		&project.Region,
+		&project.ClientName,
+		&project.TaxExempt,
 		&project.CreatedAt,
 		&project.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1`
 
+	project, err := scanProject(r.db.Pool.QueryRow(ctx, query, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
 
-	return &project, nil
+	return project, nil
+}
+
+// GetByIDAndUserID gets a project only when it belongs to the user, so a
+// foreign project reads the same as a missing one
+func (r *ProjectRepository) GetByIDAndUserID(ctx context.Context, id, userID uuid.UUID) (*models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1 AND user_id = $2`
+
+	project, err := scanProject(r.db.Pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+// GetByUserID lists a user's projects, most recently updated first. Archived
+// projects are left out unless status asks for them; a nil status lists the
+// rest.
+func (r *ProjectRepository) GetByUserID(ctx context.Context, userID uuid.UUID, status *models.ProjectStatus) ([]*models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE user_id = $1 AND status <> 'archived' ORDER BY updated_at DESC`
+	args := []interface{}{userID}
+	if status != nil {
+		query = `SELECT ` + projectColumns + ` FROM projects WHERE user_id = $1 AND status = $2 ORDER BY updated_at DESC`
+		args = append(args, *status)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []*models.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
 }
 
 func (r *ProjectRepository) Create(ctx context.Context, project *models.Project) error {
 	query := `
-		INSERT INTO projects (id, user_id, name, description, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO projects (id, user_id, name, description, status, project_type, budget, region, client_name, tax_exempt, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
+	// Projects created without a type are new construction, as in the column default
+	if project.ProjectType == "" {
+		project.ProjectType = models.ProjectTypeNewConstruction
+	}
+
 	_, err := r.db.Pool.Exec(ctx, query,
 		project.ID,
 		project.UserID,
 		project.Name,
 		project.Description,
 		project.Status,
+		project.ProjectType,
+		project.Budget,
+		project.Region,
+		project.ClientName,
+		project.TaxExempt,
 		project.CreatedAt,
 		project.UpdatedAt,
 	)
@@ -63,3 +126,70 @@ func (r *ProjectRepository) Create(ctx context.Context, project *models.Project)
 
 	return nil
 }
+
+// Update saves a project's name, description, status, region and client
+// name. Budget, project type and tax exemption have their own updates.
+func (r *ProjectRepository) Update(ctx context.Context, project *models.Project) error {
+	query := `
+		UPDATE projects
+		SET name = $1, description = $2, status = $3, region = $4, client_name = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query,
+		project.Name,
+		project.Description,
+		project.Status,
+		project.Region,
+		project.ClientName,
+		project.UpdatedAt,
+		project.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) UpdateBudget(ctx context.Context, id uuid.UUID, budget *float64) error {
+	query := `
+		UPDATE projects
+		SET budget = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, budget, id); err != nil {
+		return fmt.Errorf("failed to update project budget: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) UpdateProjectType(ctx context.Context, id uuid.UUID, projectType models.ProjectType) error {
+	query := `
+		UPDATE projects
+		SET project_type = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, projectType, id); err != nil {
+		return fmt.Errorf("failed to update project type: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTaxExempt sets whether the project is priced without sales tax
+func (r *ProjectRepository) UpdateTaxExempt(ctx context.Context, id uuid.UUID, exempt bool) error {
+	query := `
+		UPDATE projects
+		SET tax_exempt = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, exempt, id); err != nil {
+		return fmt.Errorf("failed to update project tax exemption: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+var (
+	ErrProjectPricingOverrideNotFound = errors.New("project pricing override not found")
+	// ErrProjectPricingOverrideExists is returned when a project already has
+	// an override of the type for the item
+	ErrProjectPricingOverrideExists = errors.New("project pricing override already exists")
+)
+
+const projectPricingOverrideColumns = `id, project_id, override_type, item_key, override_value, is_percentage, notes, created_at, updated_at`
+
+type ProjectPricingOverrideRepository struct {
+	db *Database
+}
+
+func NewProjectPricingOverrideRepository(db *Database) *ProjectPricingOverrideRepository {
+	return &ProjectPricingOverrideRepository{db: db}
+}
+
+func scanProjectPricingOverride(row pgx.Row) (*models.ProjectPricingOverride, error) {
+	var override models.ProjectPricingOverride
+	err := row.Scan(
+		&override.ID,
+		&override.ProjectID,
+		&override.OverrideType,
+		&override.ItemKey,
+		&override.OverrideValue,
+		&override.IsPercentage,
+		&override.Notes,
+		&override.CreatedAt,
+		&override.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// GetByProjectID returns a project's pricing overrides
+func (r *ProjectPricingOverrideRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.ProjectPricingOverride, error) {
+	query := `
+		SELECT ` + projectPricingOverrideColumns + `
+		FROM project_pricing_overrides
+		WHERE project_id = $1
+		ORDER BY override_type, item_key
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project pricing overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []models.ProjectPricingOverride
+	for rows.Next() {
+		override, err := scanProjectPricingOverride(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project pricing override: %w", err)
+		}
+		overrides = append(overrides, *override)
+	}
+	return overrides, rows.Err()
+}
+
+// GetByID returns a project pricing override
+func (r *ProjectPricingOverrideRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ProjectPricingOverride, error) {
+	query := `SELECT ` + projectPricingOverrideColumns + ` FROM project_pricing_overrides WHERE id = $1`
+	override, err := scanProjectPricingOverride(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrProjectPricingOverrideNotFound
+		}
+		return nil, fmt.Errorf("failed to get project pricing override: %w", err)
+	}
+	return override, nil
+}
+
+// Create stores a project pricing override
+func (r *ProjectPricingOverrideRepository) Create(ctx context.Context, override *models.ProjectPricingOverride) error {
+	query := `INSERT INTO project_pricing_overrides (` + projectPricingOverrideColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		override.ID,
+		override.ProjectID,
+		override.OverrideType,
+		override.ItemKey,
+		override.OverrideValue,
+		override.IsPercentage,
+		override.Notes,
+		override.CreatedAt,
+		override.UpdatedAt,
+	)
+	if err != nil {
+		// PostgreSQL error code 23505 is unique_violation
+		if strings.Contains(err.Error(), "23505") {
+			return ErrProjectPricingOverrideExists
+		}
+		return fmt.Errorf("failed to create project pricing override: %w", err)
+	}
+	return nil
+}
+
+// Update changes a project pricing override's value, kind and notes
+func (r *ProjectPricingOverrideRepository) Update(ctx context.Context, override *models.ProjectPricingOverride) error {
+	query := `
+		UPDATE project_pricing_overrides
+		SET override_value = $2, is_percentage = $3, notes = $4, updated_at = $5
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		override.ID, override.OverrideValue, override.IsPercentage, override.Notes, override.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update project pricing override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProjectPricingOverrideNotFound
+	}
+	return nil
+}
+
+// Delete removes a project pricing override
+func (r *ProjectPricingOverrideRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM project_pricing_overrides WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project pricing override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProjectPricingOverrideNotFound
+	}
+	return nil
+}
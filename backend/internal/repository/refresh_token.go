@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// ErrRefreshTokenNotFound is returned when a refresh token is unknown, or
+// when consuming one that is revoked or expired
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+const refreshTokenColumns = `id, user_id, token_hash, expires_at, revoked_at, created_at`
+
+type RefreshTokenRepository struct {
+	db *Database
+}
+
+func NewRefreshTokenRepository(db *Database) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create stores a new refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (` + refreshTokenColumns + `) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.RevokedAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// Consume revokes the unexpired, unrevoked refresh token with the given hash
+// and returns it. The check and the revocation are one statement, so two
+// requests racing with the same token cannot both consume it.
+func (r *RefreshTokenRepository) Consume(ctx context.Context, tokenHash string, at time.Time) (*models.RefreshToken, error) {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = $2
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > $2
+		RETURNING ` + refreshTokenColumns
+
+	var token models.RefreshToken
+	err := r.db.Pool.QueryRow(ctx, query, tokenHash, models.NewTimestamp(at)).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to consume refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// Revoke revokes the refresh token with the given hash. Revoking an already
+// revoked token keeps the original time.
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, tokenHash string, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = COALESCE(revoked_at, $2) WHERE token_hash = $1`
+	tag, err := r.db.Pool.Exec(ctx, query, tokenHash, models.NewTimestamp(at))
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRefreshTokenNotFound
+	}
+	return nil
+}
+
+// RevokeAllForUser revokes every refresh token a user holds
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+	if _, err := r.db.Pool.Exec(ctx, query, userID, models.NewTimestamp(at)); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestRefreshTokenRepository_ConsumeAndRevoke(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewRefreshTokenRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	if _, err := db.Pool.Exec(ctx,
+		`INSERT INTO users (id, email, password_hash) VALUES ($1, $2, 'x')`,
+		userID, userID.String()+"@example.com"); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	create := func() *models.RefreshToken {
+		t.Helper()
+		token := &models.RefreshToken{
+			ID:        uuid.New(),
+			UserID:    userID,
+			TokenHash: uuid.NewString(),
+			ExpiresAt: models.NewTimestamp(now.Add(time.Hour)),
+			CreatedAt: models.NewTimestamp(now),
+		}
+		if err := repo.Create(ctx, token); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		return token
+	}
+
+	token := create()
+	consumed, err := repo.Consume(ctx, token.TokenHash, now)
+	if err != nil || consumed.ID != token.ID || consumed.RevokedAt == nil {
+		t.Fatalf("Consume = %+v, %v; want the token, revoked", consumed, err)
+	}
+	if _, err := repo.Consume(ctx, token.TokenHash, now); err != ErrRefreshTokenNotFound {
+		t.Errorf("second Consume error = %v, want ErrRefreshTokenNotFound", err)
+	}
+
+	expiring := create()
+	if _, err := repo.Consume(ctx, expiring.TokenHash, now.Add(time.Hour)); err != ErrRefreshTokenNotFound {
+		t.Errorf("Consume of an expired token error = %v, want ErrRefreshTokenNotFound", err)
+	}
+
+	active := create()
+	if err := repo.RevokeAllForUser(ctx, userID, now); err != nil {
+		t.Fatalf("RevokeAllForUser failed: %v", err)
+	}
+	if _, err := repo.Consume(ctx, active.TokenHash, now); err != ErrRefreshTokenNotFound {
+		t.Errorf("Consume after RevokeAllForUser error = %v, want ErrRefreshTokenNotFound", err)
+	}
+	if err := repo.Revoke(ctx, "unknown", now); err != ErrRefreshTokenNotFound {
+		t.Errorf("Revoke of an unknown token error = %v, want ErrRefreshTokenNotFound", err)
+	}
+}
@@ -2,12 +2,17 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
+// ErrRegionalAdjustmentNotFound is returned when a region has no adjustment
+var ErrRegionalAdjustmentNotFound = errors.New("regional adjustment not found")
+
 type RegionalAdjustmentRepository struct {
 	db *pgxpool.Pool
 }
@@ -81,6 +86,9 @@ func (r *RegionalAdjustmentRepository) GetByRegion(ctx context.Context, region s
 		&ra.CostOfLivingIndex, &ra.Source, &ra.LastUpdated, &ra.CreatedAt, &ra.UpdatedAt,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRegionalAdjustmentNotFound
+		}
 		return nil, err
 	}
 
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// RetentionRepository finds and deletes rows past their retention. Each
+// delete removes at most limit rows in its own statement, so a sweep of a
+// large backlog never holds locks for long.
+type RetentionRepository struct {
+	db *Database
+}
+
+func NewRetentionRepository(db *Database) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// expiredJobsWhere matches finished jobs that ended before $1, except the
+// most recent job of each blueprint, which is kept whatever its age
+const expiredJobsWhere = `
+	j.status IN ('completed', 'failed', 'stale')
+	AND COALESCE(j.completed_at, j.updated_at) < $1
+	AND j.id <> (
+		SELECT latest.id FROM jobs latest
+		WHERE latest.blueprint_id = j.blueprint_id
+		ORDER BY latest.created_at DESC, latest.id DESC
+		LIMIT 1
+	)
+`
+
+// CountExpiredJobs counts the jobs DeleteExpiredJobs would remove
+func (r *RetentionRepository) CountExpiredJobs(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM jobs j WHERE ` + expiredJobsWhere
+	if err := r.db.Pool.QueryRow(ctx, query, models.NewTimestamp(before)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count expired jobs: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteExpiredJobs deletes up to limit finished jobs that ended before
+// before, keeping each blueprint's most recent job
+func (r *RetentionRepository) DeleteExpiredJobs(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM jobs WHERE id IN (
+			SELECT j.id FROM jobs j WHERE ` + expiredJobsWhere + `
+			LIMIT $2
+		)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, models.NewTimestamp(before), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CountOrphanedBlueprintRevisions counts revisions whose blueprint is gone
+func (r *RetentionRepository) CountOrphanedBlueprintRevisions(ctx context.Context) (int64, error) {
+	var count int64
+	query := `
+		SELECT COUNT(*) FROM blueprint_revisions r
+		WHERE NOT EXISTS (SELECT 1 FROM blueprints b WHERE b.id = r.blueprint_id)
+	`
+	if err := r.db.Pool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count orphaned blueprint revisions: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteOrphanedBlueprintRevisions deletes up to limit revisions whose
+// blueprint is gone, left behind by deletions from before the cascade
+func (r *RetentionRepository) DeleteOrphanedBlueprintRevisions(ctx context.Context, limit int) (int64, error) {
+	query := `
+		DELETE FROM blueprint_revisions WHERE id IN (
+			SELECT r.id FROM blueprint_revisions r
+			WHERE NOT EXISTS (SELECT 1 FROM blueprints b WHERE b.id = r.blueprint_id)
+			LIMIT $1
+		)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete orphaned blueprint revisions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CountOrphanedBidRevisions counts revisions whose bid is gone
+func (r *RetentionRepository) CountOrphanedBidRevisions(ctx context.Context) (int64, error) {
+	var count int64
+	query := `
+		SELECT COUNT(*) FROM bid_revisions r
+		WHERE NOT EXISTS (SELECT 1 FROM bids b WHERE b.id = r.bid_id)
+	`
+	if err := r.db.Pool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count orphaned bid revisions: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteOrphanedBidRevisions deletes up to limit revisions whose bid is gone
+func (r *RetentionRepository) DeleteOrphanedBidRevisions(ctx context.Context, limit int) (int64, error) {
+	query := `
+		DELETE FROM bid_revisions WHERE id IN (
+			SELECT r.id FROM bid_revisions r
+			WHERE NOT EXISTS (SELECT 1 FROM bids b WHERE b.id = r.bid_id)
+			LIMIT $1
+		)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete orphaned bid revisions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CountExpiredDrafts counts bid drafts that expired at or before now
+func (r *RetentionRepository) CountExpiredDrafts(ctx context.Context, now time.Time) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM bid_drafts WHERE expires_at <= $1`
+	if err := r.db.Pool.QueryRow(ctx, query, models.NewTimestamp(now)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count expired bid drafts: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteExpiredDrafts deletes up to limit bid drafts that expired at or
+// before now
+func (r *RetentionRepository) DeleteExpiredDrafts(ctx context.Context, now time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM bid_drafts WHERE id IN (
+			SELECT id FROM bid_drafts WHERE expires_at <= $1 LIMIT $2
+		)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, models.NewTimestamp(now), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired bid drafts: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestRetentionRepository_DeleteExpiredJobs(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewRetentionRepository(db)
+	jobRepo := NewJobRepository(db)
+	blueprintRepo := NewBlueprintRepository(db)
+	ctx := context.Background()
+
+	projectID := seedSearchProject(t, db)
+	busy := seedSearchBlueprint(t, blueprintRepo, projectID, "R-101.pdf", "retention")
+	quiet := seedSearchBlueprint(t, blueprintRepo, projectID, "R-102.pdf", "retention")
+
+	// Far enough in the past that no other test's jobs are expired
+	cutoff := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := cutoff.Add(-30 * 24 * time.Hour)
+	seedJob := func(blueprintID uuid.UUID, status models.JobStatus, at time.Time) uuid.UUID {
+		t.Helper()
+		ts := models.NewTimestamp(at)
+		job := &models.Job{
+			ID:          uuid.New(),
+			BlueprintID: blueprintID,
+			JobType:     models.JobTypeTakeoff,
+			Status:      status,
+			CreatedAt:   ts,
+			UpdatedAt:   ts,
+		}
+		if status != models.JobStatusQueued {
+			job.CompletedAt = &ts
+		}
+		if err := jobRepo.Create(ctx, job); err != nil {
+			t.Fatalf("failed to seed job: %v", err)
+		}
+		return job.ID
+	}
+
+	expired := []uuid.UUID{
+		seedJob(busy, models.JobStatusCompleted, old),
+		seedJob(busy, models.JobStatusFailed, old.Add(time.Hour)),
+		seedJob(busy, models.JobStatusStale, old.Add(2*time.Hour)),
+	}
+	kept := []uuid.UUID{
+		seedJob(busy, models.JobStatusQueued, old.Add(3*time.Hour)),     // not finished
+		seedJob(busy, models.JobStatusCompleted, cutoff.Add(time.Hour)), // inside retention, and busy's latest
+		seedJob(quiet, models.JobStatusCompleted, old),                  // quiet's only, so latest, job
+	}
+
+	count, err := repo.CountExpiredJobs(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("CountExpiredJobs failed: %v", err)
+	}
+	if count != int64(len(expired)) {
+		t.Errorf("CountExpiredJobs = %d, want %d", count, len(expired))
+	}
+
+	// Deletes are capped at the batch size
+	first, err := repo.DeleteExpiredJobs(ctx, cutoff, 2)
+	if err != nil {
+		t.Fatalf("DeleteExpiredJobs failed: %v", err)
+	}
+	second, err := repo.DeleteExpiredJobs(ctx, cutoff, 2)
+	if err != nil {
+		t.Fatalf("DeleteExpiredJobs failed: %v", err)
+	}
+	if first != 2 || second != 1 {
+		t.Errorf("batches deleted %d then %d rows, want 2 then 1", first, second)
+	}
+
+	for _, id := range expired {
+		if _, err := jobRepo.GetByID(ctx, id); err == nil {
+			t.Errorf("expected expired job %s to be deleted", id)
+		}
+	}
+	for _, id := range kept {
+		if _, err := jobRepo.GetByID(ctx, id); err != nil {
+			t.Errorf("expected job %s to be kept: %v", id, err)
+		}
+	}
+}
+
+func TestRetentionRepository_DeleteOrphanedRevisions(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewRetentionRepository(db)
+	ctx := context.Background()
+
+	// The foreign keys cascade now, so orphans can only be seeded with
+	// constraint triggers off, as they were before the cascade existed
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire connection: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, `SET session_replication_role = replica`); err != nil {
+		t.Skipf("cannot disable foreign keys to seed orphans: %v", err)
+	}
+	defer conn.Exec(ctx, `SET session_replication_role = DEFAULT`)
+
+	var orphans []uuid.UUID
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		if _, err := conn.Exec(ctx,
+			`INSERT INTO blueprint_revisions (id, blueprint_id, version, filename, s3_key, created_at) VALUES ($1, $2, 1, 'gone.pdf', 'gone.pdf', NOW())`,
+			id, uuid.New()); err != nil {
+			t.Fatalf("failed to seed orphaned blueprint revision: %v", err)
+		}
+		orphans = append(orphans, id)
+	}
+	bidOrphan := uuid.New()
+	if _, err := conn.Exec(ctx,
+		`INSERT INTO bid_revisions (id, bid_id, version, status, created_at) VALUES ($1, $2, 1, 'draft', NOW())`,
+		bidOrphan, uuid.New()); err != nil {
+		t.Fatalf("failed to seed orphaned bid revision: %v", err)
+	}
+
+	var deleted int64
+	for {
+		n, err := repo.DeleteOrphanedBlueprintRevisions(ctx, 2)
+		if err != nil {
+			t.Fatalf("DeleteOrphanedBlueprintRevisions failed: %v", err)
+		}
+		deleted += n
+		if n < 2 {
+			break
+		}
+	}
+	if deleted < int64(len(orphans)) {
+		t.Errorf("deleted %d orphaned blueprint revisions, want at least %d", deleted, len(orphans))
+	}
+	if n, err := repo.DeleteOrphanedBidRevisions(ctx, 1000); err != nil || n < 1 {
+		t.Errorf("DeleteOrphanedBidRevisions = %d, %v; want the orphan deleted", n, err)
+	}
+
+	var remaining int
+	if err := db.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM blueprint_revisions WHERE id = ANY($1)`, orphans).Scan(&remaining); err != nil {
+		t.Fatalf("failed to count revisions: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("%d orphaned blueprint revisions remain", remaining)
+	}
+}
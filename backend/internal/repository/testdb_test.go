@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newTestDatabase connects to the database named by TEST_DATABASE_URL, which is
+// expected to have all migrations applied. Tests are skipped when it is unset.
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("Integration test - requires TEST_DATABASE_URL")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return &Database{Pool: pool}
+}
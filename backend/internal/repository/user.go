@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
@@ -52,60 +54,348 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) erro
 	return nil
 }
 
-// GetUserByEmail retrieves a user by email
-func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, company_name, created_at, updated_at
-		FROM users
-		WHERE email = $1
-	`
+const userColumns = `id, email, password_hash, name, company_name,
+		       COALESCE(default_inclusions, '{}'), COALESCE(default_exclusions, '{}'),
+		       auto_blueprint_revisions, quickbooks_items, quickbooks_default_item, tax_settings, impact_thresholds, pdf_layout, unit_system, role, suspended, suspended_at, created_at, updated_at`
 
+func scanUser(row pgx.Row) (*models.User, error) {
 	var user models.User
-	err := r.db.Pool.QueryRow(ctx, query, email).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Name,
 		&user.CompanyName,
+		&user.DefaultInclusions,
+		&user.DefaultExclusions,
+		&user.AutoBlueprintRevisions,
+		&user.QuickBooksItems,
+		&user.QuickBooksDefaultItem,
+		&user.TaxSettings,
+		&user.ImpactThresholds,
+		&user.PDFLayout,
+		&user.UnitSystem,
+		&user.Role,
+		&user.Suspended,
+		&user.SuspendedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
-
 	return &user, nil
 }
 
+// GetUserByEmail retrieves a user by email
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	return scanUser(r.db.Pool.QueryRow(ctx, query, email))
+}
+
 // GetUserByID retrieves a user by ID
 func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, company_name, created_at, updated_at
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return scanUser(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// userSearchClause builds the WHERE clause for a user search, numbering
+// placeholders from $1
+func userSearchClause(filter models.UserSearchFilter) (string, []interface{}) {
+	clause := "1=1"
+	var args []interface{}
+	if filter.Email != nil && *filter.Email != "" {
+		args = append(args, "%"+escapeLike(*filter.Email)+"%")
+		clause += fmt.Sprintf(" AND email ILIKE $%d", len(args))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		clause += fmt.Sprintf(" AND created_at > $%d", len(args))
+	}
+	return clause, args
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SearchUsers lists users matching the filter, newest first
+func (r *UserRepository) SearchUsers(ctx context.Context, filter models.UserSearchFilter) ([]*models.User, error) {
+	clause, args := userSearchClause(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM users
-		WHERE id = $1
+		WHERE %s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d
+	`, userColumns, clause, len(args)-1, len(args))
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// GetUserActivity returns aggregate counts of a user's projects, bids,
+// blueprint storage and AI usage
+func (r *UserRepository) GetUserActivity(ctx context.Context, id uuid.UUID) (*models.UserActivity, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM projects WHERE user_id = $1),
+			(SELECT COUNT(*) FROM bids b JOIN projects p ON p.id = b.project_id WHERE p.user_id = $1),
+			(SELECT COUNT(*) FROM blueprints bp JOIN projects p ON p.id = bp.project_id WHERE p.user_id = $1),
+			(SELECT COALESCE(SUM(a.size_bytes), 0) FROM blueprint_assets a JOIN blueprints bp ON bp.id = a.blueprint_id JOIN projects p ON p.id = bp.project_id WHERE p.user_id = $1),
+			(SELECT COUNT(*) FROM jobs j JOIN blueprints bp ON bp.id = j.blueprint_id JOIN projects p ON p.id = bp.project_id WHERE p.user_id = $1),
+			(SELECT COUNT(*) FROM bids b JOIN projects p ON p.id = b.project_id WHERE p.user_id = $1 AND b.generation_model IS NOT NULL)
 	`
 
-	var user models.User
+	var activity models.UserActivity
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Name,
-		&user.CompanyName,
-		&user.CreatedAt,
-		&user.UpdatedAt,
+		&activity.Projects,
+		&activity.Bids,
+		&activity.Blueprints,
+		&activity.StorageBytes,
+		&activity.AnalysisJobs,
+		&activity.GeneratedBids,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user activity: %w", err)
+	}
+
+	return &activity, nil
+}
 
+// SetSuspended suspends or reinstates a user
+func (r *UserRepository) SetSuspended(ctx context.Context, id uuid.UUID, suspended bool) error {
+	query := `
+		UPDATE users
+		SET suspended = $1,
+		    suspended_at = CASE WHEN $1 THEN NOW() ELSE NULL END,
+		    updated_at = NOW()
+		WHERE id = $2
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, suspended, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrUserNotFound
+		return fmt.Errorf("failed to update user suspension: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// IsUserSuspended reports whether a user is suspended
+func (r *UserRepository) IsUserSuspended(ctx context.Context, id uuid.UUID) (bool, error) {
+	var suspended bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT suspended FROM users WHERE id = $1`, id).Scan(&suspended)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrUserNotFound
 		}
-		return nil, err
+		return false, err
 	}
+	return suspended, nil
+}
 
-	return &user, nil
+// GetAccountStatus returns whether a user is suspended and when their tokens
+// were last revoked; the auth middleware calls it on every request
+func (r *UserRepository) GetAccountStatus(ctx context.Context, id uuid.UUID) (models.AccountStatus, error) {
+	var status models.AccountStatus
+	err := r.db.Pool.QueryRow(ctx, `SELECT suspended, tokens_revoked_at FROM users WHERE id = $1`, id).
+		Scan(&status.Suspended, &status.TokensRevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status, ErrUserNotFound
+		}
+		return status, err
+	}
+	return status, nil
+}
+
+// UpdatePasswordHash replaces a user's password hash without revoking their
+// tokens, e.g. to re-hash at a higher bcrypt cost
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	tag, err := r.db.Pool.Exec(ctx, `UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`, passwordHash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update password hash: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ChangePassword replaces a user's password hash and revokes the tokens
+// issued before revokedAt
+func (r *UserRepository) ChangePassword(ctx context.Context, id uuid.UUID, passwordHash string, revokedAt time.Time) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, tokens_revoked_at = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, passwordHash, revokedAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SetRole changes a user's role and revokes the tokens issued before
+// revokedAt, whose claims carry the old role
+func (r *UserRepository) SetRole(ctx context.Context, id uuid.UUID, role models.UserRole, revokedAt time.Time) error {
+	query := `
+		UPDATE users
+		SET role = $1, tokens_revoked_at = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, role, revokedAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateBidDefaults replaces the company's standing bid inclusions and exclusions
+func (r *UserRepository) UpdateBidDefaults(ctx context.Context, id uuid.UUID, inclusions, exclusions []string) error {
+	query := `
+		UPDATE users
+		SET default_inclusions = $1, default_exclusions = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, inclusions, exclusions, id)
+	return err
+}
+
+// UpdateQuickBooksItems replaces the company's QuickBooks item mapping and
+// catch-all item; a nil defaultItem restores the built-in catch-all
+func (r *UserRepository) UpdateQuickBooksItems(ctx context.Context, id uuid.UUID, items map[string]string, defaultItem *string) error {
+	query := `
+		UPDATE users
+		SET quickbooks_items = $1, quickbooks_default_item = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, items, defaultItem, id)
+	return err
+}
+
+// UpdateTaxSettings replaces the company's sales tax rules; nil removes them
+func (r *UserRepository) UpdateTaxSettings(ctx context.Context, id uuid.UUID, settings *models.TaxSettings) error {
+	query := `
+		UPDATE users
+		SET tax_settings = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, settings, id)
+	return err
+}
+
+// UpdateImpactThresholds replaces the company's revision comparison impact
+// thresholds; nil restores the defaults
+func (r *UserRepository) UpdateImpactThresholds(ctx context.Context, id uuid.UUID, thresholds *models.ImpactThresholds) error {
+	query := `
+		UPDATE users
+		SET impact_thresholds = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, thresholds, id)
+	return err
+}
+
+// UpdatePDFLayout replaces the company's bid document layout; nil restores
+// the default
+func (r *UserRepository) UpdatePDFLayout(ctx context.Context, id uuid.UUID, layout *models.PDFLayout) error {
+	query := `
+		UPDATE users
+		SET pdf_layout = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, layout, id)
+	return err
+}
+
+// SetAutoBlueprintRevisions turns automatic snapshots of overwritten
+// blueprint analyses on or off for the company
+func (r *UserRepository) SetAutoBlueprintRevisions(ctx context.Context, id uuid.UUID, enabled bool) error {
+	query := `
+		UPDATE users
+		SET auto_blueprint_revisions = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, enabled, id)
+	if err != nil {
+		return fmt.Errorf("failed to update auto revision setting: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SetUnitSystem sets the company's default unit system for takeoff and
+// pricing output
+func (r *UserRepository) SetUnitSystem(ctx context.Context, id uuid.UUID, system models.UnitSystem) error {
+	query := `
+		UPDATE users
+		SET unit_system = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, system, id)
+	if err != nil {
+		return fmt.Errorf("failed to update unit system: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// AutoBlueprintRevisionsEnabled reports whether the owner of a blueprint's
+// project keeps automatic snapshots of overwritten analyses
+func (r *UserRepository) AutoBlueprintRevisionsEnabled(ctx context.Context, blueprintID uuid.UUID) (bool, error) {
+	query := `
+		SELECT u.auto_blueprint_revisions
+		FROM blueprints b
+		JOIN projects p ON p.id = b.project_id
+		JOIN users u ON u.id = p.user_id
+		WHERE b.id = $1
+	`
+
+	var enabled bool
+	if err := r.db.Pool.QueryRow(ctx, query, blueprintID).Scan(&enabled); err != nil {
+		return false, fmt.Errorf("failed to get auto revision setting: %w", err)
+	}
+	return enabled, nil
 }
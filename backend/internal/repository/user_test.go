@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestUserSearchClause(t *testing.T) {
+	email := "100%_a"
+	after := models.NewTimestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	clause, args := userSearchClause(models.UserSearchFilter{Email: &email, CreatedAfter: &after})
+
+	if clause != "1=1 AND email ILIKE $1 AND created_at > $2" {
+		t.Errorf("unexpected clause %q", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{`%100\%\_a%`, after}) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	if clause, args := userSearchClause(models.UserSearchFilter{}); clause != "1=1" || len(args) != 0 {
+		t.Errorf("expected empty filter to match all users, got %q %v", clause, args)
+	}
+}
+
+func TestUserRepository_SearchAndSuspend(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	// An email domain unique to this run keeps the search scoped to seeded rows
+	domain := "@search-" + uuid.NewString()[:8] + ".test"
+	cutoff := time.Now().UTC().Add(-time.Hour)
+	seed := func(local string, createdAt time.Time) *models.User {
+		user := &models.User{
+			ID:           uuid.New(),
+			Email:        local + domain,
+			PasswordHash: "hash",
+			CreatedAt:    models.NewTimestamp(createdAt),
+			UpdatedAt:    models.NewTimestamp(createdAt),
+		}
+		if err := repo.CreateUser(ctx, user); err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+		t.Cleanup(func() {
+			db.Pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, user.ID)
+		})
+		return user
+	}
+	older := seed("older", cutoff.Add(-24*time.Hour))
+	newer := seed("newer", cutoff.Add(30*time.Minute))
+
+	email := domain
+	users, err := repo.SearchUsers(ctx, models.UserSearchFilter{Email: &email, Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchUsers() error = %v", err)
+	}
+	if len(users) != 2 || users[0].ID != newer.ID || users[1].ID != older.ID {
+		t.Fatalf("expected both users newest first, got %+v", users)
+	}
+
+	after := models.NewTimestamp(cutoff)
+	users, err = repo.SearchUsers(ctx, models.UserSearchFilter{Email: &email, CreatedAfter: &after, Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchUsers() error = %v", err)
+	}
+	if len(users) != 1 || users[0].ID != newer.ID {
+		t.Fatalf("expected only the newer user, got %+v", users)
+	}
+
+	users, err = repo.SearchUsers(ctx, models.UserSearchFilter{Email: &email, Limit: 1, Offset: 1})
+	if err != nil || len(users) != 1 || users[0].ID != older.ID {
+		t.Fatalf("expected the second page to hold the older user, got %+v (%v)", users, err)
+	}
+
+	if err := repo.SetSuspended(ctx, older.ID, true); err != nil {
+		t.Fatalf("SetSuspended() error = %v", err)
+	}
+	if suspended, err := repo.IsUserSuspended(ctx, older.ID); err != nil || !suspended {
+		t.Errorf("IsUserSuspended() = %v, %v; want true", suspended, err)
+	}
+	user, err := repo.GetUserByID(ctx, older.ID)
+	if err != nil || !user.Suspended || user.SuspendedAt == nil {
+		t.Errorf("expected suspension to be stored, got %+v (%v)", user, err)
+	}
+
+	if err := repo.SetSuspended(ctx, older.ID, false); err != nil {
+		t.Fatalf("SetSuspended() error = %v", err)
+	}
+	if suspended, _ := repo.IsUserSuspended(ctx, older.ID); suspended {
+		t.Error("expected the user to be unsuspended")
+	}
+
+	if err := repo.SetSuspended(ctx, uuid.New(), true); err != ErrUserNotFound {
+		t.Errorf("SetSuspended() on a missing user = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestUserRepository_ChangePassword(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{
+		ID:           uuid.New(),
+		Email:        "password-" + uuid.NewString()[:8] + "@example.test",
+		PasswordHash: "old-hash",
+		CreatedAt:    models.Now(),
+		UpdatedAt:    models.Now(),
+	}
+	if err := repo.CreateUser(ctx, user); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, user.ID)
+	})
+
+	// A re-hash keeps existing tokens valid
+	if err := repo.UpdatePasswordHash(ctx, user.ID, "rehashed"); err != nil {
+		t.Fatalf("UpdatePasswordHash() error = %v", err)
+	}
+	status, err := repo.GetAccountStatus(ctx, user.ID)
+	if err != nil || status.Suspended || status.TokensRevokedAt != nil {
+		t.Errorf("GetAccountStatus() = %+v, %v; want no revocation after a re-hash", status, err)
+	}
+
+	revokedAt := time.Now().UTC().Truncate(time.Second)
+	if err := repo.ChangePassword(ctx, user.ID, "new-hash", revokedAt); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	status, err = repo.GetAccountStatus(ctx, user.ID)
+	if err != nil || status.TokensRevokedAt == nil || !status.TokensRevokedAt.Equal(revokedAt) {
+		t.Errorf("GetAccountStatus() = %+v, %v; want tokens revoked at %v", status, err, revokedAt)
+	}
+	stored, err := repo.GetUserByID(ctx, user.ID)
+	if err != nil || stored.PasswordHash != "new-hash" {
+		t.Errorf("expected the new hash to be stored, got %+v (%v)", stored, err)
+	}
+
+	if err := repo.ChangePassword(ctx, uuid.New(), "hash", revokedAt); err != ErrUserNotFound {
+		t.Errorf("ChangePassword() on a missing user = %v, want ErrUserNotFound", err)
+	}
+}
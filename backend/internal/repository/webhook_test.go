@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestWebhookRepository_EventsAndDeliveries(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	if _, err := db.Pool.Exec(ctx,
+		`INSERT INTO users (id, email, password_hash) VALUES ($1, $2, 'x')`,
+		userID, userID.String()+"@example.com"); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	webhook := &models.Webhook{
+		ID:         uuid.New(),
+		UserID:     userID,
+		URL:        "https://example.com/hooks",
+		Secret:     "secret",
+		EventTypes: []string{models.WebhookEventAnalysisCompleted, models.WebhookEventJobFailed},
+		CreatedAt:  models.Now(),
+	}
+	if err := repo.Create(ctx, webhook); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if found, err := repo.ListByEvent(ctx, userID, models.WebhookEventJobFailed); err != nil || len(found) != 1 || found[0].Secret != "secret" {
+		t.Errorf("ListByEvent(job.failed) = %v, %v; want the webhook with its secret", found, err)
+	}
+	if found, err := repo.ListByEvent(ctx, userID, models.WebhookEventBidCreated); err != nil || len(found) != 0 {
+		t.Errorf("ListByEvent(bid.created) = %v, %v; want none", found, err)
+	}
+	if _, err := repo.GetByID(ctx, webhook.ID, uuid.New()); err != ErrWebhookNotFound {
+		t.Errorf("GetByID as another user error = %v, want ErrWebhookNotFound", err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhook.ID,
+		EventType: models.WebhookEventJobFailed,
+		Payload:   json.RawMessage(`{"event": "job.failed"}`),
+		Status:    models.WebhookDeliveryPending,
+		CreatedAt: models.Now(),
+		UpdatedAt: models.Now(),
+	}
+	if err := repo.CreateDelivery(ctx, delivery); err != nil {
+		t.Fatalf("CreateDelivery failed: %v", err)
+	}
+	code := 502
+	delivery.Status = models.WebhookDeliveryFailed
+	delivery.Attempts = 5
+	delivery.ResponseCode = &code
+	if err := repo.UpdateDelivery(ctx, delivery); err != nil {
+		t.Fatalf("UpdateDelivery failed: %v", err)
+	}
+
+	deliveries, err := repo.ListDeliveries(ctx, webhook.ID, 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("ListDeliveries = %v, %v; want the one delivery", deliveries, err)
+	}
+	if got := deliveries[0]; got.Status != models.WebhookDeliveryFailed || got.Attempts != 5 || got.ResponseCode == nil || *got.ResponseCode != code {
+		t.Errorf("delivery = %+v, want the updated outcome", got)
+	}
+
+	// Deleting a webhook removes its deliveries
+	if err := repo.Delete(ctx, webhook.ID, userID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if deliveries, err := repo.ListDeliveries(ctx, webhook.ID, 10); err != nil || len(deliveries) != 0 {
+		t.Errorf("ListDeliveries after delete = %v, %v; want none", deliveries, err)
+	}
+	if err := repo.Delete(ctx, webhook.ID, userID); err != ErrWebhookNotFound {
+		t.Errorf("second Delete error = %v, want ErrWebhookNotFound", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package serviceauth signs and verifies requests between internal services
+// with a shared secret. A request carries the Unix time it was sent in
+// X-Service-Timestamp and an HMAC-SHA256 of that timestamp and the request
+// body in X-Service-Signature. Verifiers accept more than one secret so the
+// secret can be rotated without downtime: deploy the new secret as current
+// with the old one as previous, then drop the previous once every service
+// signs with the new one.
+package serviceauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request headers carrying the signature
+const (
+	HeaderTimestamp = "X-Service-Timestamp"
+	HeaderSignature = "X-Service-Signature"
+)
+
+// DefaultMaxSkew is how far a request's timestamp may be from the verifier's
+// clock, in either direction, before it is rejected as a replay
+const DefaultMaxSkew = 5 * time.Minute
+
+var (
+	ErrMissingSignature = errors.New("request is not signed")
+	ErrInvalidTimestamp = errors.New("invalid signature timestamp")
+	ErrStaleTimestamp   = errors.New("signature timestamp outside the replay window")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Sign returns the hex HMAC-SHA256 of timestamp + "." + body under secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Signer signs outbound requests with one secret
+type Signer struct {
+	secret string
+	now    func() time.Time
+}
+
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: secret, now: time.Now}
+}
+
+// SignRequest sets the timestamp and signature headers for a request whose
+// body is body. A nil Signer leaves the request unsigned.
+func (s *Signer) SignRequest(req *http.Request, body []byte) {
+	if s == nil {
+		return
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(s.secret, timestamp, body))
+}
+
+// Verifier checks signed requests against the active secrets
+type Verifier struct {
+	secrets []string
+	maxSkew time.Duration
+	now     func() time.Time
+}
+
+// NewVerifier accepts signatures made with any non-empty secret in secrets,
+// normally the current and previous secret during a rotation
+func NewVerifier(maxSkew time.Duration, secrets ...string) *Verifier {
+	v := &Verifier{maxSkew: maxSkew, now: time.Now}
+	for _, secret := range secrets {
+		if secret != "" {
+			v.secrets = append(v.secrets, secret)
+		}
+	}
+	return v
+}
+
+// Verify checks a request's timestamp and signature headers against its body
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	timestamp := header.Get(HeaderTimestamp)
+	signature := header.Get(HeaderSignature)
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	skew := v.now().Sub(time.Unix(sent, 0))
+	if skew > v.maxSkew || skew < -v.maxSkew {
+		return ErrStaleTimestamp
+	}
+
+	for _, secret := range v.secrets {
+		if hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
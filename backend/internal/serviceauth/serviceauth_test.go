@@ -0,0 +1,79 @@
+package serviceauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func signedRequest(secret string, sentAt time.Time, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/analyze", strings.NewReader(body))
+	signer := NewSigner(secret)
+	signer.now = func() time.Time { return sentAt }
+	signer.SignRequest(req, []byte(body))
+	return req
+}
+
+func newTestVerifier(secrets ...string) *Verifier {
+	verifier := NewVerifier(DefaultMaxSkew, secrets...)
+	verifier.now = func() time.Time { return testNow }
+	return verifier
+}
+
+func TestVerify(t *testing.T) {
+	body := `{"blueprint_id":"b1","s3_key":"a.pdf"}`
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		body    string
+		secrets []string
+		want    error
+	}{
+		{"valid", signedRequest("current", testNow, body), body, []string{"current"}, nil},
+		{"clock skew inside window", signedRequest("current", testNow.Add(4*time.Minute), body), body, []string{"current"}, nil},
+		{"expired", signedRequest("current", testNow.Add(-6*time.Minute), body), body, []string{"current"}, ErrStaleTimestamp},
+		{"from the future", signedRequest("current", testNow.Add(6*time.Minute), body), body, []string{"current"}, ErrStaleTimestamp},
+		{"tampered body", signedRequest("current", testNow, body), `{"blueprint_id":"b2","s3_key":"a.pdf"}`, []string{"current"}, ErrInvalidSignature},
+		{"unknown secret", signedRequest("attacker", testNow, body), body, []string{"current"}, ErrInvalidSignature},
+		{"rotated: signed with previous", signedRequest("old", testNow, body), body, []string{"new", "old"}, nil},
+		{"rotated: signed with current", signedRequest("new", testNow, body), body, []string{"new", "old"}, nil},
+		{"rotation finished", signedRequest("old", testNow, body), body, []string{"new", ""}, ErrInvalidSignature},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/analyze", nil), body, []string{"current"}, ErrMissingSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestVerifier(tt.secrets...).Verify(tt.req.Header, []byte(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify_TamperedTimestamp(t *testing.T) {
+	body := `{}`
+	req := signedRequest("current", testNow.Add(-10*time.Minute), body)
+	// Moving a captured request's timestamp into the window breaks its signature
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(testNow.Unix(), 10))
+
+	if err := newTestVerifier("current").Verify(req.Header, []byte(body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestSignRequest_NilSigner(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	var signer *Signer
+	signer.SignRequest(req, nil)
+	if req.Header.Get(HeaderSignature) != "" {
+		t.Error("a nil signer should leave the request unsigned")
+	}
+}
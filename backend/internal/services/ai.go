@@ -11,94 +11,139 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/serviceauth"
+)
+
+// Response headers the AI service uses to describe the model that served a request
+const (
+	headerModelName      = "X-Model-Name"
+	headerModelVersion   = "X-Model-Version"
+	headerPromptRevision = "X-Prompt-Revision"
 )
 
 type AIService struct {
 	baseURL string
 	client  *http.Client
+	signer  *serviceauth.Signer // nil when no service secret is configured
 }
 
 type AnalyzeRequest struct {
-	BlueprintID uuid.UUID `json:"blueprint_id"`
-	S3Key       string    `json:"s3_key"`
+	BlueprintID   uuid.UUID      `json:"blueprint_id"`
+	S3Key         string         `json:"s3_key"`
+	PriorAnalysis *PriorAnalysis `json:"prior_analysis,omitempty"`
 }
 
 type AnalyzeResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data"`
-	Error   string      `json:"error,omitempty"`
+	Success bool                `json:"success"`
+	Data    interface{}         `json:"data"`
+	Error   string              `json:"error,omitempty"`
+	Model   *models.AIModelInfo `json:"model,omitempty"`
 }
 
 func NewAIService(cfg *config.Config) *AIService {
-	return &AIService{
+	service := &AIService{
 		baseURL: cfg.AI.ServiceURL,
 		client: &http.Client{
 			Timeout: cfg.AI.Timeout,
 		},
 	}
+	if cfg.AI.ServiceSecret != "" {
+		service.signer = serviceauth.NewSigner(cfg.AI.ServiceSecret)
+	}
+	return service
+}
+
+// newRequest builds a request to the AI service, signed when a service
+// secret is configured
+func (s *AIService) newRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	s.signer.SignRequest(req, body)
+	return req, nil
+}
+
+// AnalyzeBlueprint submits a blueprint for analysis and returns the analysis JSON
+// along with the model metadata reported by the AI service, if any.
+func (s *AIService) AnalyzeBlueprint(ctx context.Context, blueprintID uuid.UUID, s3Key string) (string, *models.AIModelInfo, error) {
+	return s.AnalyzeBlueprintWithContext(ctx, blueprintID, s3Key, nil)
 }
 
-func (s *AIService) AnalyzeBlueprint(ctx context.Context, blueprintID uuid.UUID, s3Key string) (string, error) {
+// AnalyzeBlueprintWithContext is AnalyzeBlueprint with the previous analysis
+// sent along, when prior is not nil, so the AI can keep room names stable
+func (s *AIService) AnalyzeBlueprintWithContext(ctx context.Context, blueprintID uuid.UUID, s3Key string, prior *PriorAnalysis) (string, *models.AIModelInfo, error) {
 	reqBody := AnalyzeRequest{
-		BlueprintID: blueprintID,
-		S3Key:       s3Key,
+		BlueprintID:   blueprintID,
+		S3Key:         s3Key,
+		PriorAnalysis: prior,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/analyze", s.baseURL)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	req, err := s.newRequest(ctx, http.MethodPost, "/analyze", jsonData)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to call AI service: %w", err)
+		return "", nil, fmt.Errorf("failed to call AI service: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(body))
+		return "", nil, fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(body))
 	}
 
+	modelInfo := modelInfoFromHeaders(resp.Header)
+
 	var result AnalyzeResponse
 	if err := json.Unmarshal(body, &result); err != nil {
 		// Return raw response if not JSON
-		return string(body), nil
+		return string(body), modelInfo, nil
 	}
 
 	if !result.Success {
-		return "", fmt.Errorf("AI service error: %s", result.Error)
+		return "", nil, fmt.Errorf("AI service error: %s", result.Error)
+	}
+
+	if result.Model != nil {
+		modelInfo = result.Model
 	}
 
 	// Return the result as JSON string
 	resultJSON, err := json.Marshal(result.Data)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal result: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal result: %w", err)
 	}
 
-	return string(resultJSON), nil
+	return string(resultJSON), modelInfo, nil
 }
 
 func (s *AIService) Health(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	url := fmt.Sprintf("%s/health", s.baseURL)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := s.newRequest(ctx, http.MethodGet, "/health", nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
 	resp, err := s.client.Do(req)
@@ -114,35 +159,90 @@ func (s *AIService) Health(ctx context.Context) error {
 	return nil
 }
 
-// GenerateBid calls the AI service to generate a bid
-func (s *AIService) GenerateBid(ctx context.Context, request interface{}) (string, error) {
+// GenerateBid calls the AI service to generate a bid. The returned metadata comes
+// from a top-level "model" object in the body, falling back to response headers.
+func (s *AIService) GenerateBid(ctx context.Context, request interface{}) (string, *models.AIModelInfo, error) {
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/generate-bid", s.baseURL)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	req, err := s.newRequest(ctx, http.MethodPost, "/generate-bid", jsonData)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to call AI service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	modelInfo := modelInfoFromHeaders(resp.Header)
+	var envelope struct {
+		Model *models.AIModelInfo `json:"model"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Model != nil {
+		modelInfo = envelope.Model
+	}
+
+	return string(body), modelInfo, nil
+}
+
+// RenderBlueprintPage asks the AI service to rasterize one page of a stored
+// PDF blueprint, returning PNG bytes
+func (s *AIService) RenderBlueprintPage(ctx context.Context, s3Key string, page int) ([]byte, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"s3_key": s3Key,
+		"page":   page,
+		"format": "png",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPost, "/render-page", jsonData)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to call AI service: %w", err)
+		return nil, fmt.Errorf("failed to call AI service: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("AI service returned status %d", resp.StatusCode)
 	}
 
-	return string(body), nil
+	return body, nil
+}
+
+// modelInfoFromHeaders reads model metadata from AI service response headers,
+// returning nil when none are present
+func modelInfoFromHeaders(h http.Header) *models.AIModelInfo {
+	info := &models.AIModelInfo{
+		Name:           h.Get(headerModelName),
+		Version:        h.Get(headerModelVersion),
+		PromptRevision: h.Get(headerPromptRevision),
+	}
+	if *info == (models.AIModelInfo{}) {
+		return nil
+	}
+	return info
 }
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// AI provider names accepted by AI_PROVIDER
+const (
+	AIProviderHTTP = "http"
+	AIProviderStub = "stub"
+)
+
+// AIProvider is the AI backend used for blueprint analysis and bid generation
+type AIProvider interface {
+	AnalyzeBlueprint(ctx context.Context, blueprintID uuid.UUID, s3Key string) (string, *models.AIModelInfo, error)
+	GenerateBid(ctx context.Context, request interface{}) (string, *models.AIModelInfo, error)
+	Health(ctx context.Context) error
+}
+
+// ContextualAnalyzer is implemented by providers that can take a previous
+// analysis as context when re-analyzing a blueprint
+type ContextualAnalyzer interface {
+	AnalyzeBlueprintWithContext(ctx context.Context, blueprintID uuid.UUID, s3Key string, prior *PriorAnalysis) (string, *models.AIModelInfo, error)
+}
+
+var (
+	_ AIProvider         = (*AIService)(nil)
+	_ AIProvider         = (*StubAIProvider)(nil)
+	_ ContextualAnalyzer = (*AIService)(nil)
+)
+
+// NewAIProvider returns the provider selected by cfg.AI.Provider, defaulting to
+// the HTTP AI service
+func NewAIProvider(cfg *config.Config) AIProvider {
+	if cfg.AI.Provider == AIProviderStub {
+		slog.Warn("Using stub AI provider; analyses and bids are synthetic",
+			"latency", cfg.AI.StubLatency,
+			"failure_rate", cfg.AI.StubFailureRate)
+		return NewStubAIProvider(cfg.AI.StubLatency, cfg.AI.StubFailureRate)
+	}
+	if cfg.AI.ServiceSecret == "" {
+		slog.Warn("AI_SERVICE_SECRET is not set; requests to the AI service are unsigned")
+	}
+	return NewAIService(cfg)
+}
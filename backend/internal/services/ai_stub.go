@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// ErrStubFailure is returned when the stub provider simulates an outage
+var ErrStubFailure = errors.New("stub AI provider: simulated failure")
+
+var stubModelInfo = models.AIModelInfo{
+	Name:           "stub",
+	Version:        "1.0.0",
+	PromptRevision: "stub",
+}
+
+var stubRoomNames = []string{
+	"Living Room", "Kitchen", "Primary Bedroom", "Bedroom", "Bathroom",
+	"Dining Room", "Office", "Laundry", "Garage", "Hallway",
+}
+
+// StubAIProvider returns synthetic but deterministic analyses and bids so the
+// upload, analyze and bid flow can run without the Python AI service. Output is
+// derived from a hash of the blueprint filename and is stable across runs.
+type StubAIProvider struct {
+	latency     time.Duration
+	failureRate float64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewStubAIProvider creates a stub provider. Each call sleeps for latency and
+// fails with probability failureRate (0 to 1) to exercise retry paths.
+func NewStubAIProvider(latency time.Duration, failureRate float64) *StubAIProvider {
+	return &StubAIProvider{
+		latency:     latency,
+		failureRate: failureRate,
+		rng:         rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// AnalyzeBlueprint synthesizes an analysis from the filename at the end of s3Key
+func (s *StubAIProvider) AnalyzeBlueprint(ctx context.Context, blueprintID uuid.UUID, s3Key string) (string, *models.AIModelInfo, error) {
+	if err := s.simulate(ctx); err != nil {
+		return "", nil, err
+	}
+
+	filename := path.Base(s3Key)
+	rng := rand.New(rand.NewSource(stubSeed(filename)))
+
+	result := models.AnalysisResult{
+		BlueprintID:      blueprintID.String(),
+		Status:           "completed",
+		ConfidenceScore:  0.8 + float64(rng.Intn(16))/100,
+		ProcessingTimeMs: int(s.latency / time.Millisecond),
+	}
+
+	roomCount := 3 + rng.Intn(6)
+	var totalArea float64
+	for i := 0; i < roomCount; i++ {
+		width := 8 + rng.Intn(13)
+		length := 8 + rng.Intn(13)
+		area := float64(width * length)
+		totalArea += area
+		result.Rooms = append(result.Rooms, models.Room{
+			Name:       stubRoomNames[(i+rng.Intn(len(stubRoomNames)))%len(stubRoomNames)],
+			Dimensions: fmt.Sprintf("%dx%d", width, length),
+			Area:       area,
+		})
+	}
+
+	result.Openings = []models.Opening{
+		{OpeningType: "door", Count: roomCount + rng.Intn(3), Size: "3x7"},
+		{OpeningType: "window", Count: roomCount + rng.Intn(5), Size: "3x4"},
+	}
+	result.Fixtures = []models.Fixture{
+		{FixtureType: "outlet", Category: "electrical", Count: roomCount*3 + rng.Intn(5)},
+		{FixtureType: "light", Category: "electrical", Count: roomCount + rng.Intn(4)},
+		{FixtureType: "sink", Category: "plumbing", Count: 1 + rng.Intn(3)},
+	}
+	result.Materials = []models.Material{
+		{MaterialName: "drywall", Quantity: math.Round(totalArea * 3.5), Unit: "SF"},
+		{MaterialName: "flooring", Quantity: totalArea, Unit: "SF"},
+		{MaterialName: "paint", Quantity: math.Ceil(totalArea * 3.5 / 350), Unit: "gallon"},
+	}
+
+	ocrText := fmt.Sprintf("Synthetic sheet %s with %d rooms", filename, roomCount)
+	result.RawOCRText = &ocrText
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal stub analysis: %w", err)
+	}
+
+	info := stubModelInfo
+	return string(resultJSON), &info, nil
+}
+
+// GenerateBid prices the request's takeoff data with the regular pricing rules
+// and wraps it in boilerplate bid text
+func (s *StubAIProvider) GenerateBid(ctx context.Context, request interface{}) (string, *models.AIModelInfo, error) {
+	if err := s.simulate(ctx); err != nil {
+		return "", nil, err
+	}
+
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var req struct {
+		ProjectID        string                `json:"project_id"`
+		BlueprintID      string                `json:"blueprint_id"`
+		TakeoffData      models.AnalysisResult `json:"takeoff_data"`
+		MarkupPercentage float64               `json:"markup_percentage"`
+	}
+	if err := json.Unmarshal(requestJSON, &req); err != nil {
+		return "", nil, fmt.Errorf("failed to parse bid request: %w", err)
+	}
+
+	takeoffJSON, err := json.Marshal(req.TakeoffData)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal takeoff data: %w", err)
+	}
+
+	pricingService := NewPricingService()
+	takeoff, analysis, err := pricingService.ParseTakeoffData(string(takeoffJSON))
+	if err != nil {
+		return "", nil, err
+	}
+	summary, err := pricingService.GeneratePricingSummary(takeoff, analysis, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	response := PricedBidResponse(summary, req.MarkupPercentage)
+	response.BidID = fmt.Sprintf("stub-%x", stubSeed(req.ProjectID+req.BlueprintID))
+	response.ProjectID = req.ProjectID
+	response.Status = "completed"
+	response.ScopeOfWork = fmt.Sprintf("Synthetic scope of work covering %d rooms (%.0f SF).", takeoff.RoomCount, takeoff.TotalArea)
+	response.Exclusions = []string{"Permits and fees", "Hazardous material abatement"}
+	response.Inclusions = []string{"All labor and materials listed", "Site cleanup"}
+	response.Schedule = map[string]string{
+		"start":    "Within 2 weeks of acceptance",
+		"duration": "4 weeks",
+	}
+	response.PaymentTerms = "50% deposit, balance on completion"
+	response.WarrantyTerms = "1 year workmanship warranty"
+	response.ClosingStatement = "This is a synthetic bid generated by the stub AI provider."
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal stub bid: %w", err)
+	}
+
+	info := stubModelInfo
+	return string(responseJSON), &info, nil
+}
+
+// Health always succeeds; the stub has no dependencies
+func (s *StubAIProvider) Health(ctx context.Context) error {
+	return nil
+}
+
+// simulate applies the configured latency and failure rate
+func (s *StubAIProvider) simulate(ctx context.Context) error {
+	if s.latency > 0 {
+		select {
+		case <-time.After(s.latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if s.failureRate > 0 {
+		s.mu.Lock()
+		roll := s.rng.Float64()
+		s.mu.Unlock()
+		if roll < s.failureRate {
+			return ErrStubFailure
+		}
+	}
+
+	return nil
+}
+
+// stubSeed hashes a string into a stable random seed
+func stubSeed(value string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestStubAIProvider_AnalyzeBlueprint_Deterministic(t *testing.T) {
+	stub := NewStubAIProvider(0, 0)
+	ctx := context.Background()
+
+	first, info, err := stub.AnalyzeBlueprint(ctx, uuid.New(), "projects/a/blueprints/b/A-101.pdf")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _, err := NewStubAIProvider(0, 0).AnalyzeBlueprint(ctx, uuid.New(), "projects/c/blueprints/d/A-101.pdf")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, _, err := stub.AnalyzeBlueprint(ctx, uuid.New(), "projects/a/blueprints/b/A-102.pdf")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var a, b, c models.AnalysisResult
+	for raw, dst := range map[string]*models.AnalysisResult{first: &a, second: &b, other: &c} {
+		if err := json.Unmarshal([]byte(raw), dst); err != nil {
+			t.Fatalf("stub returned invalid JSON: %v", err)
+		}
+	}
+
+	// Blueprint IDs differ, but the content derived from the filename must match
+	a.BlueprintID, b.BlueprintID, c.BlueprintID = "", "", ""
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	cJSON, _ := json.Marshal(c)
+	if string(aJSON) != string(bJSON) {
+		t.Error("expected identical analyses for the same filename")
+	}
+	if string(aJSON) == string(cJSON) {
+		t.Error("expected different analyses for different filenames")
+	}
+	if len(a.Rooms) == 0 || len(a.Fixtures) == 0 || len(a.Materials) == 0 {
+		t.Errorf("expected a populated analysis, got %+v", a)
+	}
+	if info == nil || info.Name != "stub" {
+		t.Errorf("expected stub model info, got %+v", info)
+	}
+}
+
+func TestStubAIProvider_FailureRate(t *testing.T) {
+	ctx := context.Background()
+
+	if _, _, err := NewStubAIProvider(0, 1).AnalyzeBlueprint(ctx, uuid.New(), "A-101.pdf"); !errors.Is(err, ErrStubFailure) {
+		t.Errorf("expected simulated failure, got %v", err)
+	}
+	if _, _, err := NewStubAIProvider(0, 1).GenerateBid(ctx, map[string]interface{}{}); !errors.Is(err, ErrStubFailure) {
+		t.Errorf("expected simulated failure, got %v", err)
+	}
+	if err := NewStubAIProvider(0, 1).Health(ctx); err != nil {
+		t.Errorf("health should not be affected by failure rate, got %v", err)
+	}
+}
+
+func TestStubAIProvider_LatencyRespectsContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err := NewStubAIProvider(time.Minute, 0).AnalyzeBlueprint(ctx, uuid.New(), "A-101.pdf")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestNewAIProvider_Selection(t *testing.T) {
+	if _, ok := NewAIProvider(&config.Config{AI: config.AIConfig{Provider: AIProviderStub}}).(*StubAIProvider); !ok {
+		t.Error("expected stub provider for AI_PROVIDER=stub")
+	}
+	if _, ok := NewAIProvider(&config.Config{AI: config.AIConfig{Provider: AIProviderHTTP}}).(*AIService); !ok {
+		t.Error("expected HTTP provider by default")
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newTestAIService(handler http.HandlerFunc) (*AIService, func()) {
+	server := httptest.NewServer(handler)
+	return &AIService{baseURL: server.URL, client: server.Client()}, server.Close
+}
+
+func TestAIService_AnalyzeBlueprint_ModelMetadata(t *testing.T) {
+	t.Run("metadata from response body", func(t *testing.T) {
+		service, closeFn := newTestAIService(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(headerModelVersion, "header-version")
+			w.Write([]byte(`{"success":true,"data":{"rooms":[]},"model":{"name":"takeoff","version":"2024.2","prompt_revision":"12"}}`))
+		})
+		defer closeFn()
+
+		data, info, err := service.AnalyzeBlueprint(context.Background(), uuid.New(), "key")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if data != `{"rooms":[]}` {
+			t.Errorf("unexpected data: %s", data)
+		}
+		if info == nil || info.Name != "takeoff" || info.Version != "2024.2" || info.PromptRevision != "12" {
+			t.Errorf("expected body metadata to win, got %+v", info)
+		}
+	})
+
+	t.Run("metadata from headers", func(t *testing.T) {
+		service, closeFn := newTestAIService(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(headerModelName, "takeoff")
+			w.Header().Set(headerModelVersion, "2024.1")
+			w.Write([]byte(`{"success":true,"data":{"rooms":[]}}`))
+		})
+		defer closeFn()
+
+		_, info, err := service.AnalyzeBlueprint(context.Background(), uuid.New(), "key")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if info == nil || info.Name != "takeoff" || info.Version != "2024.1" {
+			t.Errorf("expected header metadata, got %+v", info)
+		}
+	})
+
+	t.Run("no metadata", func(t *testing.T) {
+		service, closeFn := newTestAIService(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"success":true,"data":{}}`))
+		})
+		defer closeFn()
+
+		_, info, err := service.AnalyzeBlueprint(context.Background(), uuid.New(), "key")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if info != nil {
+			t.Errorf("expected nil metadata, got %+v", info)
+		}
+	})
+}
+
+func TestAIService_GenerateBid_ModelMetadata(t *testing.T) {
+	service, closeFn := newTestAIService(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"bid_id":"b1","total_price":1000,"model":{"name":"bidwriter","version":"3"}}`))
+	})
+	defer closeFn()
+
+	body, info, err := service.GenerateBid(context.Background(), map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body == "" {
+		t.Error("expected raw body")
+	}
+	if info == nil || info.Name != "bidwriter" || info.Version != "3" {
+		t.Errorf("unexpected metadata: %+v", info)
+	}
+}
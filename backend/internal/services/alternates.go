@@ -0,0 +1,85 @@
+package services
+
+import (
+	"math"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// defaultAlternateGroup names alternates that arrive without a group
+const defaultAlternateGroup = "Alternate"
+
+// SplitAlternates separates base line items from alternates, grouping the
+// alternates by name in first-seen order. Group costs are unmarked-up.
+func SplitAlternates(items []models.LineItem) ([]models.LineItem, []models.AlternateGroup) {
+	base := make([]models.LineItem, 0, len(items))
+	var groups []models.AlternateGroup
+	index := make(map[string]int)
+
+	for _, item := range items {
+		if !item.IsAlternate {
+			base = append(base, item)
+			continue
+		}
+
+		name := item.AlternateGroup
+		if name == "" {
+			name = defaultAlternateGroup
+			item.AlternateGroup = name
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, models.AlternateGroup{Name: name})
+		}
+		groups[i].LineItems = append(groups[i].LineItems, item)
+		groups[i].Cost += item.Total
+	}
+
+	for i := range groups {
+		groups[i].Cost = math.Round(groups[i].Cost*100) / 100
+	}
+
+	return base, groups
+}
+
+// ApplyAlternates moves alternate line items out of a bid's base scope. The
+// base labor, material, subtotal, markup and total exclude them, and each
+// alternate group is priced with the same markup as a delta on the base total.
+func ApplyAlternates(bid *models.GenerateBidResponse, markupPercentage float64) {
+	base, groups := SplitAlternates(bid.LineItems)
+	if len(groups) == 0 {
+		return
+	}
+
+	for _, group := range groups {
+		for _, item := range group.LineItems {
+			if isLaborLineItem(item) {
+				bid.LaborCost -= item.Total
+			} else {
+				bid.MaterialCost -= item.Total
+			}
+		}
+	}
+
+	bid.LaborCost = math.Round(bid.LaborCost*100) / 100
+	bid.MaterialCost = math.Round(bid.MaterialCost*100) / 100
+	bid.Subtotal = math.Round((bid.LaborCost+bid.MaterialCost)*100) / 100
+	bid.MarkupAmount = math.Round(bid.Subtotal*markupPercentage) / 100
+	bid.TotalPrice = math.Round((bid.Subtotal+bid.MarkupAmount)*100) / 100
+
+	for i := range groups {
+		markup := math.Round(groups[i].Cost*markupPercentage) / 100
+		groups[i].Price = math.Round((groups[i].Cost+markup)*100) / 100
+	}
+
+	bid.LineItems = base
+	bid.Alternates = append(bid.Alternates, groups...)
+	RecomputeBidTax(bid)
+}
+
+// isLaborLineItem reports whether a line item is priced by the hour
+func isLaborLineItem(item models.LineItem) bool {
+	return item.Unit == "hours"
+}
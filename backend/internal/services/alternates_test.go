@@ -0,0 +1,128 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func newAlternatesBid() *models.GenerateBidResponse {
+	// Totals as reported by the AI service, which include the alternates
+	return &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Carpet", Trade: "flooring", Quantity: 1000, Unit: "SF", UnitCost: 4, Total: 4000},
+			{Description: "Labor - flooring", Trade: "flooring", Quantity: 20, Unit: "hours", UnitCost: 50, Total: 1000},
+			{Description: "Hardwood upgrade", Trade: "flooring", Quantity: 1000, Unit: "SF", UnitCost: 6, Total: 6000, IsAlternate: true, AlternateGroup: "Alternate 1: Hardwood"},
+			{Description: "Labor - hardwood", Trade: "flooring", Quantity: 10, Unit: "hours", UnitCost: 50, Total: 500, IsAlternate: true, AlternateGroup: "Alternate 1: Hardwood"},
+			{Description: "Skylight", Trade: "carpentry", Quantity: 1, Unit: "each", UnitCost: 1200, Total: 1200, IsAlternate: true},
+		},
+		MaterialCost: 11200,
+		LaborCost:    1500,
+		Subtotal:     12700,
+		MarkupAmount: 2540,
+		TotalPrice:   15240,
+	}
+}
+
+func TestApplyAlternates_BaseTotalIgnoresAlternates(t *testing.T) {
+	bid := newAlternatesBid()
+	ApplyAlternates(bid, 20)
+
+	if len(bid.LineItems) != 2 {
+		t.Fatalf("expected 2 base line items, got %d", len(bid.LineItems))
+	}
+	if bid.MaterialCost != 4000 || bid.LaborCost != 1000 {
+		t.Errorf("expected base material 4000 and labor 1000, got %.2f and %.2f", bid.MaterialCost, bid.LaborCost)
+	}
+	if bid.Subtotal != 5000 || bid.MarkupAmount != 1000 || bid.TotalPrice != 6000 {
+		t.Errorf("unexpected base totals: subtotal %.2f markup %.2f total %.2f", bid.Subtotal, bid.MarkupAmount, bid.TotalPrice)
+	}
+}
+
+func TestApplyAlternates_GroupDeltas(t *testing.T) {
+	bid := newAlternatesBid()
+	ApplyAlternates(bid, 20)
+
+	if len(bid.Alternates) != 2 {
+		t.Fatalf("expected 2 alternate groups, got %d", len(bid.Alternates))
+	}
+
+	hardwood := bid.Alternates[0]
+	if hardwood.Name != "Alternate 1: Hardwood" || len(hardwood.LineItems) != 2 {
+		t.Errorf("unexpected first group: %+v", hardwood)
+	}
+	if hardwood.Cost != 6500 || hardwood.Price != 7800 {
+		t.Errorf("expected hardwood cost 6500 and price 7800, got %.2f and %.2f", hardwood.Cost, hardwood.Price)
+	}
+
+	ungrouped := bid.Alternates[1]
+	if ungrouped.Name != defaultAlternateGroup || ungrouped.Price != 1440 {
+		t.Errorf("expected default group priced at 1440, got %+v", ungrouped)
+	}
+}
+
+func TestApplyAlternates_NoAlternates(t *testing.T) {
+	bid := &models.GenerateBidResponse{
+		LineItems:  []models.LineItem{{Description: "Carpet", Total: 4000}},
+		Subtotal:   4000,
+		TotalPrice: 4800,
+	}
+	ApplyAlternates(bid, 20)
+
+	if bid.TotalPrice != 4800 || len(bid.Alternates) != 0 {
+		t.Errorf("expected bid without alternates to be unchanged, got %+v", bid)
+	}
+}
+
+func TestGenerateBidResponse_LegacyBidDataDecodes(t *testing.T) {
+	legacy := `{"bid_id":"b1","line_items":[{"description":"Carpet","trade":"flooring","quantity":1,"unit":"SF","unit_cost":4,"total":4}],"total_price":4.8}`
+
+	var bid models.GenerateBidResponse
+	if err := json.Unmarshal([]byte(legacy), &bid); err != nil {
+		t.Fatalf("failed to decode legacy bid data: %v", err)
+	}
+	if len(bid.Alternates) != 0 || bid.LineItems[0].IsAlternate {
+		t.Errorf("expected no alternates in legacy bid data, got %+v", bid)
+	}
+}
+
+func TestGenerateBidCSV_Alternates(t *testing.T) {
+	bidResponse := newAlternatesBid()
+	ApplyAlternates(bidResponse, 20)
+
+	csvData, err := NewExportService().GenerateBidCSV(&models.Bid{ID: uuid.New(), Status: models.BidStatusDraft}, bidResponse, "Test Project")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content := string(csvData)
+	if !strings.Contains(content, "Alternates") || !strings.Contains(content, "Alternate 1: Hardwood Price,7800.00") {
+		t.Errorf("expected alternates section in CSV, got:\n%s", content)
+	}
+}
+
+func TestCompareBidRevisions_Alternates(t *testing.T) {
+	from := &models.GenerateBidResponse{Alternates: []models.AlternateGroup{{Name: "Alt 1", Price: 1000}, {Name: "Alt 2", Price: 500}}}
+	to := &models.GenerateBidResponse{Alternates: []models.AlternateGroup{{Name: "Alt 1", Price: 1200}, {Name: "Alt 3", Price: 300}}}
+	fromJSON, _ := json.Marshal(from)
+	toJSON, _ := json.Marshal(to)
+	fromStr, toStr := string(fromJSON), string(toJSON)
+
+	comparison, err := NewComparisonService().CompareBidRevisions(
+		&models.BidRevision{Version: 1, BidData: &fromStr},
+		&models.BidRevision{Version: 2, BidData: &toStr},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if comparison.Summary.ChangesByCategory["alternate"] != 3 {
+		t.Errorf("expected 3 alternate changes, got %d", comparison.Summary.ChangesByCategory["alternate"])
+	}
+	if comparison.Summary.ChangesByCategory["line_item"] != 0 {
+		t.Errorf("alternates should not appear as line item changes")
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// DefaultMinAnalysisConfidence is the lowest analysis confidence score
+// pricing proceeds from when no threshold is configured
+const DefaultMinAnalysisConfidence = 0.6
+
+// LowConfidenceError reports an analysis whose confidence score is below the
+// threshold pricing needs
+type LowConfidenceError struct {
+	Score         float64
+	MinConfidence float64
+}
+
+func (e *LowConfidenceError) Error() string {
+	return fmt.Sprintf("analysis confidence score %.2f is below the minimum of %.2f", e.Score, e.MinConfidence)
+}
+
+// CheckAnalysisConfidence returns a LowConfidenceError when the analysis's
+// confidence score is below minConfidence. A score of zero means the
+// analysis reported none and is not checked.
+func CheckAnalysisConfidence(analysis *models.AnalysisResult, minConfidence float64) error {
+	if analysis == nil || analysis.ConfidenceScore <= 0 || analysis.ConfidenceScore >= minConfidence {
+		return nil
+	}
+	return &LowConfidenceError{Score: analysis.ConfidenceScore, MinConfidence: minConfidence}
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestCheckAnalysisConfidence(t *testing.T) {
+	err := CheckAnalysisConfidence(&models.AnalysisResult{ConfidenceScore: 0.45}, 0.6)
+	var low *LowConfidenceError
+	if !errors.As(err, &low) || low.Score != 0.45 || low.MinConfidence != 0.6 {
+		t.Errorf("CheckAnalysisConfidence(0.45) = %v, want a LowConfidenceError with the score", err)
+	}
+
+	for _, score := range []float64{0, 0.6, 0.9} {
+		if err := CheckAnalysisConfidence(&models.AnalysisResult{ConfidenceScore: score}, 0.6); err != nil {
+			t.Errorf("CheckAnalysisConfidence(%.2f) = %v, want nil", score, err)
+		}
+	}
+}
+
+func TestMergeSheetAnalyses_LowestConfidence(t *testing.T) {
+	sheet := func(score float64) SheetAnalysis {
+		return SheetAnalysis{
+			Sheet:    models.SheetRef{BlueprintID: uuid.New(), SheetType: models.SheetTypeArchitectural},
+			Analysis: &models.AnalysisResult{ConfidenceScore: score},
+		}
+	}
+	merged, _ := MergeSheetAnalyses([]SheetAnalysis{sheet(0.9), sheet(0), sheet(0.55)})
+	if merged.ConfidenceScore != 0.55 {
+		t.Errorf("merged confidence = %.2f, want the least confident sheet's 0.55", merged.ConfidenceScore)
+	}
+}
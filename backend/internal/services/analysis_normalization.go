@@ -0,0 +1,108 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Units normalized measurements are written in
+const (
+	NormalizedLengthUnit = "ft"
+	NormalizedAreaUnit   = UnitLabelSquareFeet
+)
+
+// measurementUnitFactors convert a measurement unit, keyed by lower-cased
+// label, into feet or square feet
+var measurementUnitFactors = map[string]struct {
+	factor float64
+	unit   string
+}{
+	"in":     {1.0 / 12, NormalizedLengthUnit},
+	"inch":   {1.0 / 12, NormalizedLengthUnit},
+	"inches": {1.0 / 12, NormalizedLengthUnit},
+	`"`:      {1.0 / 12, NormalizedLengthUnit},
+	"ft":     {1, NormalizedLengthUnit},
+	"foot":   {1, NormalizedLengthUnit},
+	"feet":   {1, NormalizedLengthUnit},
+	"'":      {1, NormalizedLengthUnit},
+	"lf":     {1, NormalizedLengthUnit},
+	"m":      {1 / MetersPerFoot, NormalizedLengthUnit},
+	"meter":  {1 / MetersPerFoot, NormalizedLengthUnit},
+	"meters": {1 / MetersPerFoot, NormalizedLengthUnit},
+	"sf":     {1, NormalizedAreaUnit},
+	"sq ft":  {1, NormalizedAreaUnit},
+	"sqft":   {1, NormalizedAreaUnit},
+	"m2":     {1 / SquareMetersPerSquareFoot, NormalizedAreaUnit},
+	"m²":     {1 / SquareMetersPerSquareFoot, NormalizedAreaUnit},
+	"sq m":   {1 / SquareMetersPerSquareFoot, NormalizedAreaUnit},
+}
+
+// AnalysisNormalizationService turns raw AI analysis output into a
+// takeoff-ready shape: measurements in feet or square feet, room dimensions
+// parsed into sides and opening sizes into inches. Entities it converts are
+// flagged normalized; what it cannot convert is passed through and listed in
+// the analysis's normalization warnings.
+type AnalysisNormalizationService struct{}
+
+func NewAnalysisNormalizationService() *AnalysisNormalizationService {
+	return &AnalysisNormalizationService{}
+}
+
+// Normalize normalizes an analysis in place
+func (s *AnalysisNormalizationService) Normalize(analysis *models.AnalysisResult) {
+	if analysis == nil {
+		return
+	}
+	analysis.NormalizationWarnings = nil
+
+	for i := range analysis.Rooms {
+		s.normalizeRoom(analysis, &analysis.Rooms[i])
+	}
+
+	NormalizeOpenings(analysis.Openings)
+	for i := range analysis.Openings {
+		opening := &analysis.Openings[i]
+		opening.Normalized = opening.WidthInches != nil
+		if !opening.Normalized && strings.TrimSpace(opening.Size) != "" {
+			analysis.NormalizationWarnings = append(analysis.NormalizationWarnings,
+				fmt.Sprintf("Opening %q size %q could not be parsed", opening.OpeningType, opening.Size))
+		}
+	}
+
+	for i := range analysis.Measurements {
+		s.normalizeMeasurement(analysis, &analysis.Measurements[i])
+	}
+}
+
+// normalizeRoom parses a room's dimensions into its width and length in feet
+func (s *AnalysisNormalizationService) normalizeRoom(analysis *models.AnalysisResult, room *models.Room) {
+	room.WidthFeet, room.LengthFeet, room.Normalized = nil, nil, false
+	sides, ok := parseRoomSides(room.Dimensions)
+	if !ok || len(sides) != 2 {
+		if strings.TrimSpace(room.Dimensions) != "" {
+			analysis.NormalizationWarnings = append(analysis.NormalizationWarnings,
+				fmt.Sprintf("Room %q dimensions %q could not be parsed", room.Name, room.Dimensions))
+		}
+		return
+	}
+	width, length := math.Round(sides[0]*100)/100, math.Round(sides[1]*100)/100
+	room.WidthFeet, room.LengthFeet, room.Normalized = &width, &length, true
+}
+
+// normalizeMeasurement converts a measurement into feet or square feet,
+// leaving measurements in unknown units as they are
+func (s *AnalysisNormalizationService) normalizeMeasurement(analysis *models.AnalysisResult, measurement *models.Measurement) {
+	conversion, ok := measurementUnitFactors[strings.ToLower(strings.TrimSpace(measurement.Unit))]
+	if !ok {
+		measurement.Normalized = false
+		analysis.NormalizationWarnings = append(analysis.NormalizationWarnings,
+			fmt.Sprintf("Measurement %q has unknown unit %q and was not converted", measurement.MeasurementType, measurement.Unit))
+		return
+	}
+	measurement.Value = math.Round(measurement.Value*conversion.factor*100) / 100
+	measurement.Unit = conversion.unit
+	measurement.Normalized = true
+}
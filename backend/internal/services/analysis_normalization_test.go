@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestAnalysisNormalizationService_MixedUnits(t *testing.T) {
+	analysis := &models.AnalysisResult{
+		Rooms: []models.Room{
+			{Name: "Kitchen", Dimensions: "15 x 12", Area: 180},
+			{Name: "Bedroom", Dimensions: `12'-6" x 10'`, Area: 125},
+		},
+		Openings: []models.Opening{{OpeningType: "door", Count: 2, Size: "36x80"}},
+		Measurements: []models.Measurement{
+			{MeasurementType: "ceiling_height", Value: 96, Unit: "inches"},
+			{MeasurementType: "wall_thickness", Value: 6, Unit: "in"},
+			{MeasurementType: "wall_length", Value: 40, Unit: "ft"},
+			{MeasurementType: "corridor_length", Value: 12, Unit: "feet"},
+			{MeasurementType: "site_width", Value: 10, Unit: "m"},
+			{MeasurementType: "floor_area", Value: 100, Unit: "m2"},
+		},
+	}
+	NewAnalysisNormalizationService().Normalize(analysis)
+
+	wantRooms := [][2]float64{{12, 15}, {10, 12.5}}
+	for i, want := range wantRooms {
+		room := analysis.Rooms[i]
+		if !room.Normalized || room.WidthFeet == nil || room.LengthFeet == nil || *room.WidthFeet != want[0] || *room.LengthFeet != want[1] {
+			t.Errorf("room %s = %v x %v, normalized %v; want %v x %v", room.Name, room.WidthFeet, room.LengthFeet, room.Normalized, want[0], want[1])
+		}
+	}
+	if opening := analysis.Openings[0]; !opening.Normalized || opening.WidthInches == nil || *opening.WidthInches != 36 {
+		t.Errorf("opening = %+v, want a normalized 36\" wide door", opening)
+	}
+
+	want := []struct {
+		value float64
+		unit  string
+	}{{8, "ft"}, {0.5, "ft"}, {40, "ft"}, {12, "ft"}, {32.81, "ft"}, {1076.39, "SF"}}
+	for i, w := range want {
+		m := analysis.Measurements[i]
+		if !m.Normalized || m.Value != w.value || m.Unit != w.unit {
+			t.Errorf("%s = %v %s (normalized %v), want %v %s", m.MeasurementType, m.Value, m.Unit, m.Normalized, w.value, w.unit)
+		}
+	}
+	if len(analysis.NormalizationWarnings) != 0 {
+		t.Errorf("warnings = %v, want none", analysis.NormalizationWarnings)
+	}
+}
+
+func TestAnalysisNormalizationService_Warnings(t *testing.T) {
+	analysis := &models.AnalysisResult{
+		Rooms:        []models.Room{{Name: "Closet", Dimensions: "irregular", Area: 20}},
+		Measurements: []models.Measurement{{MeasurementType: "beam_span", Value: 3, Unit: "cubits"}},
+	}
+	NewAnalysisNormalizationService().Normalize(analysis)
+
+	if room := analysis.Rooms[0]; room.Normalized || room.WidthFeet != nil || room.Dimensions != "irregular" {
+		t.Errorf("room = %+v, want it passed through unnormalized", room)
+	}
+	if m := analysis.Measurements[0]; m.Normalized || m.Value != 3 || m.Unit != "cubits" {
+		t.Errorf("measurement = %+v, want it passed through unnormalized", m)
+	}
+	if len(analysis.NormalizationWarnings) != 2 || !strings.Contains(analysis.NormalizationWarnings[1], `unknown unit "cubits"`) {
+		t.Errorf("warnings = %v, want the room and the unknown unit", analysis.NormalizationWarnings)
+	}
+}
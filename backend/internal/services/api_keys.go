@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+const (
+	// APIKeyPrefix starts every API key, so leaked keys are recognizable
+	APIKeyPrefix = "fom_"
+	// apiKeyIDLength is how many characters after APIKeyPrefix are stored in
+	// the clear to find the key
+	apiKeyIDLength = 8
+	// DefaultAPIKeyCacheTTL is how long a looked-up key is reused, and so how
+	// long a revocation can take to reach another server
+	DefaultAPIKeyCacheTTL = 30 * time.Second
+)
+
+// ErrInvalidAPIKey is returned for unknown, revoked and expired API keys
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyStore reads and writes API keys
+type APIKeyStore interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, id, userID uuid.UUID, at time.Time) error
+	RecordUsage(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type cachedAPIKey struct {
+	key       *models.APIKey
+	fetchedAt time.Time
+}
+
+// APIKeyService issues, revokes and authenticates API keys. Looked-up keys
+// are cached briefly by prefix; the hash is still compared on every request.
+type APIKeyService struct {
+	store    APIKeyStore
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedAPIKey
+}
+
+func NewAPIKeyService(store APIKeyStore, cacheTTL time.Duration) *APIKeyService {
+	return &APIKeyService{
+		store:    store,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    make(map[string]cachedAPIKey),
+	}
+}
+
+// hashAPIKey hashes a key for storage. Keys are long and random, so a fast
+// hash is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create issues a read-only key for a user and returns it with the plain key,
+// which is not stored and cannot be shown again
+func (s *APIKeyService) Create(ctx context.Context, userID uuid.UUID, name string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plain := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &models.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plain[:len(APIKeyPrefix)+apiKeyIDLength],
+		KeyHash:   hashAPIKey(plain),
+		Scope:     models.APIKeyScopeReadOnly,
+		ExpiresAt: models.NewTimestampPtr(expiresAt),
+		CreatedAt: models.NewTimestamp(s.now()),
+	}
+	if err := s.store.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, plain, nil
+}
+
+// List returns a user's keys without their hashes
+func (s *APIKeyService) List(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	return s.store.ListByUser(ctx, userID)
+}
+
+// Revoke revokes a user's key. It stops working here at once, and on other
+// servers once their cached copy expires.
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.store.Revoke(ctx, id, userID, s.now()); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	for prefix, entry := range s.cache {
+		if entry.key.ID == id {
+			delete(s.cache, prefix)
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Authenticate resolves a plain key to its record, returning
+// ErrInvalidAPIKey unless the key is known, unrevoked and unexpired, and
+// records the request against the key
+func (s *APIKeyService) Authenticate(ctx context.Context, plain string) (*models.APIKey, error) {
+	prefixLength := len(APIKeyPrefix) + apiKeyIDLength
+	if !strings.HasPrefix(plain, APIKeyPrefix) || len(plain) <= prefixLength {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.lookup(ctx, plain[:prefixLength])
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(plain)), []byte(key.KeyHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	now := s.now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(key.ExpiresAt.Time)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Non-fatal: a missed count is better than a failed request
+	if err := s.store.RecordUsage(ctx, key.ID, now); err != nil {
+		slog.Error("Failed to record API key usage", "api_key_id", key.ID, "error", err)
+	}
+	return key, nil
+}
+
+// lookup returns the key with a prefix, from the cache while it is fresh
+func (s *APIKeyService) lookup(ctx context.Context, prefix string) (*models.APIKey, error) {
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.cache[prefix]
+	s.mu.Unlock()
+	if ok && now.Sub(entry.fetchedAt) < s.cacheTTL {
+		return entry.key, nil
+	}
+
+	key, err := s.store.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAPIKey, err)
+	}
+	s.mu.Lock()
+	s.cache[prefix] = cachedAPIKey{key: key, fetchedAt: now}
+	s.mu.Unlock()
+	return key, nil
+}
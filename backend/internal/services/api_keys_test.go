@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// fakeAPIKeyStore keeps keys by prefix and counts lookups
+type fakeAPIKeyStore struct {
+	keys    map[string]*models.APIKey
+	lookups int
+}
+
+func (f *fakeAPIKeyStore) Create(ctx context.Context, key *models.APIKey) error {
+	stored := *key
+	f.keys[key.Prefix] = &stored
+	return nil
+}
+
+func (f *fakeAPIKeyStore) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	f.lookups++
+	key, ok := f.keys[prefix]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	found := *key
+	return &found, nil
+}
+
+func (f *fakeAPIKeyStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	return nil, nil
+}
+
+func (f *fakeAPIKeyStore) Revoke(ctx context.Context, id, userID uuid.UUID, at time.Time) error {
+	for _, key := range f.keys {
+		if key.ID == id && key.UserID == userID {
+			revokedAt := models.NewTimestamp(at)
+			key.RevokedAt = &revokedAt
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (f *fakeAPIKeyStore) RecordUsage(ctx context.Context, id uuid.UUID, at time.Time) error {
+	for _, key := range f.keys {
+		if key.ID == id {
+			usedAt := models.NewTimestamp(at)
+			key.LastUsedAt = &usedAt
+			key.RequestCount++
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeAPIKeyStore{keys: map[string]*models.APIKey{}}
+	service := NewAPIKeyService(store, time.Minute)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	userID := uuid.New()
+
+	key, plain, err := service.Create(ctx, userID, "Dashboard", nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := service.Authenticate(ctx, plain)
+	if err != nil || got.ID != key.ID {
+		t.Fatalf("Authenticate() = %v, %v; want the created key", got, err)
+	}
+	if used := store.keys[key.Prefix]; used.LastUsedAt == nil || !used.LastUsedAt.Equal(now) || used.RequestCount != 1 {
+		t.Errorf("usage = %v, %d; want last used now, once", used.LastUsedAt, used.RequestCount)
+	}
+
+	// The prefix alone, or with the wrong secret, is not the key
+	for _, wrong := range []string{key.Prefix, plain[:len(plain)-1] + "x", "Bearer", ""} {
+		if _, err := service.Authenticate(ctx, wrong); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q) error = %v, want ErrInvalidAPIKey", wrong, err)
+		}
+	}
+
+	if store.lookups != 1 {
+		t.Errorf("store looked up %d times, want once with the cache", store.lookups)
+	}
+}
+
+func TestAPIKeyService_Expiry(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeAPIKeyStore{keys: map[string]*models.APIKey{}}
+	service := NewAPIKeyService(store, time.Minute)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	expiresAt := now.Add(time.Hour)
+	_, plain, err := service.Create(ctx, uuid.New(), "Temporary", &expiresAt)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := service.Authenticate(ctx, plain); err != nil {
+		t.Fatalf("Authenticate() before expiry error = %v", err)
+	}
+
+	now = expiresAt
+	if _, err := service.Authenticate(ctx, plain); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() at expiry error = %v, want ErrInvalidAPIKey", err)
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeAPIKeyStore{keys: map[string]*models.APIKey{}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	local := NewAPIKeyService(store, time.Minute)
+	local.now = clock
+	// Another server, whose cache the revocation cannot reach
+	remote := NewAPIKeyService(store, time.Minute)
+	remote.now = clock
+	userID := uuid.New()
+
+	key, plain, err := local.Create(ctx, userID, "Dashboard", nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, service := range []*APIKeyService{local, remote} {
+		if _, err := service.Authenticate(ctx, plain); err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+	}
+
+	if err := local.Revoke(ctx, userID, key.ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := local.Authenticate(ctx, plain); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("revoked key accepted by the revoking server: %v", err)
+	}
+	if _, err := remote.Authenticate(ctx, plain); err != nil {
+		t.Errorf("expected the other server to serve its cached key until it expires, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	if _, err := remote.Authenticate(ctx, plain); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("revoked key accepted after the cache expired: %v", err)
+	}
+}
@@ -1,40 +1,102 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	ErrInvalidToken     = errors.New("invalid token")
-	ErrTokenExpired     = errors.New("token expired")
-	ErrInvalidPassword  = errors.New("invalid password")
+	ErrInvalidToken    = errors.New("invalid token")
+	ErrTokenExpired    = errors.New("token expired")
+	ErrInvalidPassword = errors.New("invalid password")
+	// ErrInvalidRefreshToken is returned for unknown, revoked, rotated and
+	// expired refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
 )
 
+// DefaultBcryptCost is the bcrypt cost used unless WithBcryptCost sets another
+const DefaultBcryptCost = 12
+
+// DefaultRefreshTokenExpiry is how long a refresh token lasts unless
+// WithRefreshTokens sets another expiry
+const DefaultRefreshTokenExpiry = 30 * 24 * time.Hour
+
+// RefreshTokenStore reads and revokes refresh tokens
+type RefreshTokenStore interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	Consume(ctx context.Context, tokenHash string, at time.Time) (*models.RefreshToken, error)
+	Revoke(ctx context.Context, tokenHash string, at time.Time) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
+
 type AuthService struct {
-	jwtSecret []byte
-	tokenExpiry time.Duration
+	jwtSecret          []byte
+	tokenExpiry        time.Duration
+	bcryptCost         int
+	refreshTokens      RefreshTokenStore
+	refreshTokenExpiry time.Duration
+	now                func() time.Time
+}
+
+// TokenPair is a short-lived access token and the refresh token that trades
+// for the next pair. RefreshToken is empty when refresh tokens are not
+// configured.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration // Lifetime of AccessToken
 }
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID string          `json:"user_id"`
+	Email  string          `json:"email"`
+	Role   models.UserRole `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func NewAuthService(jwtSecret string, tokenExpiry time.Duration) *AuthService {
 	return &AuthService{
-		jwtSecret:   []byte(jwtSecret),
-		tokenExpiry: tokenExpiry,
+		jwtSecret:          []byte(jwtSecret),
+		tokenExpiry:        tokenExpiry,
+		bcryptCost:         DefaultBcryptCost,
+		refreshTokenExpiry: DefaultRefreshTokenExpiry,
+		now:                time.Now,
 	}
 }
 
+// WithRefreshTokens issues refresh tokens lasting expiry alongside access
+// tokens, stored in store
+func (s *AuthService) WithRefreshTokens(store RefreshTokenStore, expiry time.Duration) *AuthService {
+	s.refreshTokens = store
+	s.refreshTokenExpiry = expiry
+	return s
+}
+
+// WithBcryptCost sets the cost of new password hashes, which is also the
+// minimum below which NeedsRehash reports true
+func (s *AuthService) WithBcryptCost(cost int) *AuthService {
+	s.bcryptCost = cost
+	return s
+}
+
+// BcryptCost returns the cost of new password hashes
+func (s *AuthService) BcryptCost() int {
+	return s.bcryptCost
+}
+
 // HashPassword hashes a plain text password using bcrypt
 func (s *AuthService) HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
 	if err != nil {
 		return "", err
 	}
@@ -46,11 +108,29 @@ func (s *AuthService) VerifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
-// GenerateToken creates a new JWT token for a user
-func (s *AuthService) GenerateToken(userID, email string) (string, error) {
+// NeedsRehash reports whether a password hash was made below the configured
+// cost and should be replaced once the password is next verified
+func (s *AuthService) NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err == nil && cost < s.bcryptCost
+}
+
+// MeasureHashTime times one hash at the configured cost, for logging at
+// startup so a cost too slow for the host is noticed
+func (s *AuthService) MeasureHashTime() (time.Duration, error) {
+	start := time.Now()
+	if _, err := bcrypt.GenerateFromPassword([]byte("benchmark-password"), s.bcryptCost); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// GenerateToken creates a new JWT token for a user carrying their role
+func (s *AuthService) GenerateToken(userID, email string, role models.UserRole) (string, error) {
 	claims := Claims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.tokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -84,3 +164,79 @@ func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
 
 	return nil, ErrInvalidToken
 }
+
+// hashRefreshToken hashes a refresh token for storage. Like API keys they
+// are long and random, so a fast hash is enough.
+func hashRefreshToken(token string) string {
+	return hashAPIKey(token)
+}
+
+// IssueTokens creates an access token for a user and, when refresh tokens are
+// configured, a refresh token. The plain refresh token is not stored.
+func (s *AuthService) IssueTokens(ctx context.Context, userID uuid.UUID, email string, role models.UserRole) (*TokenPair, error) {
+	access, err := s.GenerateToken(userID.String(), email, role)
+	if err != nil {
+		return nil, err
+	}
+	pair := &TokenPair{AccessToken: access, ExpiresIn: s.tokenExpiry}
+	if s.refreshTokens == nil {
+		return pair, nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	plain := base64.RawURLEncoding.EncodeToString(secret)
+	now := s.now()
+	token := &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hashRefreshToken(plain),
+		ExpiresAt: models.NewTimestamp(now.Add(s.refreshTokenExpiry)),
+		CreatedAt: models.NewTimestamp(now),
+	}
+	if err := s.refreshTokens.Create(ctx, token); err != nil {
+		return nil, err
+	}
+	pair.RefreshToken = plain
+	return pair, nil
+}
+
+// ConsumeRefreshToken revokes a refresh token so it cannot be used again and
+// returns it, for the caller to issue the next pair. Tokens already used,
+// revoked or expired return ErrInvalidRefreshToken.
+func (s *AuthService) ConsumeRefreshToken(ctx context.Context, plain string) (*models.RefreshToken, error) {
+	if s.refreshTokens == nil || plain == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	token, err := s.refreshTokens.Consume(ctx, hashRefreshToken(plain), s.now())
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+// RevokeRefreshToken revokes a refresh token on logout. Unknown and already
+// revoked tokens are not an error.
+func (s *AuthService) RevokeRefreshToken(ctx context.Context, plain string) error {
+	if s.refreshTokens == nil || plain == "" {
+		return nil
+	}
+	err := s.refreshTokens.Revoke(ctx, hashRefreshToken(plain), s.now())
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return nil
+	}
+	return err
+}
+
+// RevokeUserRefreshTokens revokes every refresh token a user holds
+func (s *AuthService) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	if s.refreshTokens == nil {
+		return nil
+	}
+	return s.refreshTokens.RevokeAllForUser(ctx, userID, s.now())
+}
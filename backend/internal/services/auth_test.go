@@ -1,8 +1,13 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
 )
 
 func TestHashPassword(t *testing.T) {
@@ -51,7 +56,7 @@ func TestGenerateToken(t *testing.T) {
 	userID := "user-123"
 	email := "test@example.com"
 
-	token, err := authService.GenerateToken(userID, email)
+	token, err := authService.GenerateToken(userID, email, models.UserRoleUser)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -68,7 +73,7 @@ func TestValidateToken(t *testing.T) {
 	email := "test@example.com"
 
 	// Generate token
-	token, err := authService.GenerateToken(userID, email)
+	token, err := authService.GenerateToken(userID, email, models.UserRoleUser)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -106,7 +111,7 @@ func TestValidateExpiredToken(t *testing.T) {
 	email := "test@example.com"
 
 	// Generate token
-	token, err := authService.GenerateToken(userID, email)
+	token, err := authService.GenerateToken(userID, email, models.UserRoleUser)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -133,7 +138,7 @@ func TestValidateTokenWithWrongSecret(t *testing.T) {
 	email := "test@example.com"
 
 	// Generate token with first service
-	token, err := authService1.GenerateToken(userID, email)
+	token, err := authService1.GenerateToken(userID, email, models.UserRoleUser)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -144,3 +149,138 @@ func TestValidateTokenWithWrongSecret(t *testing.T) {
 		t.Error("Should fail to validate token with wrong secret")
 	}
 }
+
+func TestNeedsRehash(t *testing.T) {
+	weak := NewAuthService("test-secret", time.Hour).WithBcryptCost(4)
+	strong := NewAuthService("test-secret", time.Hour).WithBcryptCost(5)
+
+	hash, err := weak.HashPassword("testpassword123")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	if weak.NeedsRehash(hash) {
+		t.Error("a hash at the configured cost should not need a rehash")
+	}
+	if !strong.NeedsRehash(hash) {
+		t.Error("a hash below the configured cost should need a rehash")
+	}
+	if strong.NeedsRehash("not-a-bcrypt-hash") {
+		t.Error("an unreadable hash should not be reported for rehash")
+	}
+}
+
+// fakeRefreshTokenStore keeps refresh tokens by hash with the repository's
+// consume and revoke semantics
+type fakeRefreshTokenStore struct {
+	tokens map[string]*models.RefreshToken
+}
+
+func (f *fakeRefreshTokenStore) Create(ctx context.Context, token *models.RefreshToken) error {
+	f.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (f *fakeRefreshTokenStore) Consume(ctx context.Context, tokenHash string, at time.Time) (*models.RefreshToken, error) {
+	token, ok := f.tokens[tokenHash]
+	if !ok || token.RevokedAt != nil || !at.Before(token.ExpiresAt.Time) {
+		return nil, repository.ErrRefreshTokenNotFound
+	}
+	token.RevokedAt = models.NewTimestampPtr(&at)
+	return token, nil
+}
+
+func (f *fakeRefreshTokenStore) Revoke(ctx context.Context, tokenHash string, at time.Time) error {
+	token, ok := f.tokens[tokenHash]
+	if !ok {
+		return repository.ErrRefreshTokenNotFound
+	}
+	if token.RevokedAt == nil {
+		token.RevokedAt = models.NewTimestampPtr(&at)
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokenStore) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	for _, token := range f.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = models.NewTimestampPtr(&at)
+		}
+	}
+	return nil
+}
+
+func TestRefreshTokens_RotationRejectsReuse(t *testing.T) {
+	store := &fakeRefreshTokenStore{tokens: make(map[string]*models.RefreshToken)}
+	authService := NewAuthService("test-secret", 15*time.Minute).WithRefreshTokens(store, time.Hour)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	first, err := authService.IssueTokens(ctx, userID, "test@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("IssueTokens failed: %v", err)
+	}
+	if first.RefreshToken == "" || first.ExpiresIn != 15*time.Minute {
+		t.Fatalf("pair = %+v, want a refresh token and a 15 minute access token", first)
+	}
+	if _, ok := store.tokens[first.RefreshToken]; ok {
+		t.Error("the plain refresh token was stored")
+	}
+
+	consumed, err := authService.ConsumeRefreshToken(ctx, first.RefreshToken)
+	if err != nil || consumed.UserID != userID {
+		t.Fatalf("ConsumeRefreshToken = %+v, %v; want the user's token", consumed, err)
+	}
+	second, err := authService.IssueTokens(ctx, userID, "test@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("IssueTokens failed: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("rotation issued the same refresh token")
+	}
+
+	// The rotated token cannot be replayed
+	if _, err := authService.ConsumeRefreshToken(ctx, first.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("reusing a rotated token error = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// Logging out revokes the current token; unknown tokens are no error
+	if err := authService.RevokeRefreshToken(ctx, second.RefreshToken); err != nil {
+		t.Fatalf("RevokeRefreshToken failed: %v", err)
+	}
+	if _, err := authService.ConsumeRefreshToken(ctx, second.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("using a revoked token error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if err := authService.RevokeRefreshToken(ctx, "unknown"); err != nil {
+		t.Errorf("revoking an unknown token error = %v, want nil", err)
+	}
+}
+
+func TestRefreshTokens_Expiry(t *testing.T) {
+	store := &fakeRefreshTokenStore{tokens: make(map[string]*models.RefreshToken)}
+	authService := NewAuthService("test-secret", 15*time.Minute).WithRefreshTokens(store, time.Hour)
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	authService.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	pair, err := authService.IssueTokens(ctx, uuid.New(), "test@example.com", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("IssueTokens failed: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := authService.ConsumeRefreshToken(ctx, pair.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("expired token error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRefreshTokens_NotConfigured(t *testing.T) {
+	authService := NewAuthService("test-secret", time.Hour)
+	pair, err := authService.IssueTokens(context.Background(), uuid.New(), "test@example.com", models.UserRoleUser)
+	if err != nil || pair.AccessToken == "" || pair.RefreshToken != "" {
+		t.Fatalf("IssueTokens = %+v, %v; want only an access token", pair, err)
+	}
+	if _, err := authService.ConsumeRefreshToken(context.Background(), "anything"); err != ErrInvalidRefreshToken {
+		t.Errorf("ConsumeRefreshToken error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxBidBlueprints bounds how many blueprints one combined bid can price
+const MaxBidBlueprints = 20
+
+// BidBlueprintIDs returns the blueprints a bid request prices: blueprintIDs
+// without duplicates, or just primary when the list is empty. A primary
+// given with a list must be in it. With neither it returns no IDs, meaning
+// every analyzed blueprint in the project.
+func BidBlueprintIDs(primary uuid.UUID, blueprintIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(blueprintIDs) == 0 {
+		if primary == uuid.Nil {
+			return nil, nil
+		}
+		return []uuid.UUID{primary}, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(blueprintIDs))
+	seen := make(map[uuid.UUID]bool, len(blueprintIDs))
+	for _, id := range blueprintIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > MaxBidBlueprints {
+		return nil, fmt.Errorf("a bid can be priced from at most %d blueprints", MaxBidBlueprints)
+	}
+	if primary != uuid.Nil && !seen[primary] {
+		return nil, fmt.Errorf("blueprint_id must be one of blueprint_ids")
+	}
+	return ids, nil
+}
+
+// AnalyzedBlueprints returns the blueprints that have an analysis, in order
+func AnalyzedBlueprints(blueprints []*models.Blueprint) []*models.Blueprint {
+	analyzed := make([]*models.Blueprint, 0, len(blueprints))
+	for _, blueprint := range blueprints {
+		if blueprint.AnalysisData != nil && *blueprint.AnalysisData != "" {
+			analyzed = append(analyzed, blueprint)
+		}
+	}
+	return analyzed
+}
+
+// BlueprintTakeoff parses a blueprint's analysis into the takeoff pricing
+// works from, with the estimator's takeoff adjustments and room finishes
+// applied. The stored analysis is not changed.
+func BlueprintTakeoff(blueprint *models.Blueprint) (*models.TakeoffSummary, *models.AnalysisResult, error) {
+	if blueprint.AnalysisData == nil {
+		return nil, nil, fmt.Errorf("blueprint %s has no analysis", blueprint.ID)
+	}
+	analysis, err := NewTakeoffService().ParseAnalysisData(*blueprint.AnalysisData)
+	if err != nil {
+		return nil, nil, err
+	}
+	adjustments := ApplyAdjustments(analysis, blueprint.TakeoffAdjustments)
+	takeoff := PricingTakeoff(analysis)
+	adjustments.Annotate(takeoff)
+	ApplyRoomFinishes(takeoff, blueprint.RoomFinishes)
+	return takeoff, analysis, nil
+}
+
+// BidTakeoff parses the analyses of the blueprints a bid prices into one
+// takeoff. Several blueprints are merged by MergeSheetAnalyses after each
+// blueprint's takeoff adjustments are applied to its own analysis, and each
+// blueprint's room finishes are applied by room name, so a later blueprint's
+// finish wins for a room name both use. Rooms are not de-duplicated: each
+// keeps the blueprint it came from, and names on several blueprints are
+// flagged in the takeoff's warnings. Every blueprint must be analyzed.
+func BidTakeoff(blueprints []*models.Blueprint) (*models.TakeoffSummary, *models.AnalysisResult, error) {
+	if len(blueprints) == 1 {
+		return BlueprintTakeoff(blueprints[0])
+	}
+
+	takeoffService := NewTakeoffService()
+	sheets := make([]SheetAnalysis, 0, len(blueprints))
+	var adjustments TakeoffAdjustmentResult
+	for _, blueprint := range blueprints {
+		if blueprint.AnalysisData == nil {
+			return nil, nil, fmt.Errorf("blueprint %s has no analysis", blueprint.ID)
+		}
+		analysis, err := takeoffService.ParseAnalysisData(*blueprint.AnalysisData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("blueprint %s: %w", blueprint.ID, err)
+		}
+		adjustments.Merge(ApplyAdjustments(analysis, blueprint.TakeoffAdjustments))
+		sheets = append(sheets, SheetAnalysis{
+			Sheet: models.SheetRef{
+				BlueprintID: blueprint.ID,
+				Filename:    blueprint.Filename,
+				SheetType:   InferSheetType(blueprint, analysis),
+			},
+			Analysis: analysis,
+		})
+	}
+
+	merged, _ := MergeSheetAnalyses(sheets)
+	takeoff := PricingTakeoff(merged)
+	flagSharedRoomNames(takeoff)
+	adjustments.Annotate(takeoff)
+	for _, blueprint := range blueprints {
+		ApplyRoomFinishes(takeoff, blueprint.RoomFinishes)
+	}
+	return takeoff, merged, nil
+}
+
+// BidSourceBlueprints describes blueprints for a bid's source list
+func BidSourceBlueprints(blueprints []*models.Blueprint) []models.BidSourceBlueprint {
+	sources := make([]models.BidSourceBlueprint, 0, len(blueprints))
+	for _, blueprint := range blueprints {
+		sources = append(sources, models.BidSourceBlueprint{
+			BlueprintID: blueprint.ID,
+			Filename:    blueprint.Filename,
+			Version:     blueprint.Version,
+		})
+	}
+	return sources
+}
+
+// BlueprintIDs returns the IDs of blueprints, in order
+func BlueprintIDs(blueprints []*models.Blueprint) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(blueprints))
+	for _, blueprint := range blueprints {
+		ids = append(ids, blueprint.ID)
+	}
+	return ids
+}
+
+func containsBlueprint(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SameBlueprintSet reports whether two blueprint lists name the same
+// blueprints, ignoring order
+func SameBlueprintSet(a, b []uuid.UUID) bool {
+	set := make(map[uuid.UUID]bool, len(a))
+	for _, id := range a {
+		set[id] = true
+	}
+	other := make(map[uuid.UUID]bool, len(b))
+	for _, id := range b {
+		if !set[id] {
+			return false
+		}
+		other[id] = true
+	}
+	return len(set) == len(other)
+}
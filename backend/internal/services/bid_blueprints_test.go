@@ -0,0 +1,61 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestBidBlueprintIDs(t *testing.T) {
+	primary, other := uuid.New(), uuid.New()
+
+	if ids, err := BidBlueprintIDs(primary, nil); err != nil || !reflect.DeepEqual(ids, []uuid.UUID{primary}) {
+		t.Errorf("BidBlueprintIDs(primary, nil) = %v, %v; want the primary blueprint", ids, err)
+	}
+	if ids, err := BidBlueprintIDs(uuid.Nil, []uuid.UUID{other, primary, other}); err != nil || !reflect.DeepEqual(ids, []uuid.UUID{other, primary}) {
+		t.Errorf("BidBlueprintIDs() = %v, %v; want the list without duplicates", ids, err)
+	}
+	if _, err := BidBlueprintIDs(primary, []uuid.UUID{other}); err == nil {
+		t.Error("expected an error for a primary blueprint outside the list")
+	}
+
+	tooMany := make([]uuid.UUID, MaxBidBlueprints+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+	if _, err := BidBlueprintIDs(uuid.Nil, tooMany); err == nil {
+		t.Errorf("expected an error for more than %d blueprints", MaxBidBlueprints)
+	}
+}
+
+func TestBidTakeoff(t *testing.T) {
+	analysisA := `{"rooms":[{"name":"Lobby","area":400}]}`
+	analysisB := `{"rooms":[{"name":"Office","area":600}]}`
+	buildingA := &models.Blueprint{ID: uuid.New(), Filename: "a.pdf", AnalysisData: &analysisA,
+		RoomFinishes: map[string]models.FloorFinish{"Lobby": models.FloorFinishTile}}
+	buildingB := &models.Blueprint{ID: uuid.New(), Filename: "b.pdf", AnalysisData: &analysisB}
+
+	single, _, err := BidTakeoff([]*models.Blueprint{buildingA})
+	if err != nil || single.TotalArea != 400 {
+		t.Fatalf("BidTakeoff(A) = %+v, %v; want 400 SF", single, err)
+	}
+
+	combined, analysis, err := BidTakeoff([]*models.Blueprint{buildingA, buildingB})
+	if err != nil {
+		t.Fatalf("BidTakeoff(A, B) error = %v", err)
+	}
+	if combined.TotalArea != 1000 || len(analysis.Rooms) != 2 {
+		t.Errorf("BidTakeoff(A, B) = %v SF over %d rooms, want 1000 SF over 2", combined.TotalArea, len(analysis.Rooms))
+	}
+	for _, room := range combined.RoomBreakdown {
+		if room.Name == "Lobby" && (room.Finish == nil || *room.Finish != models.FloorFinishTile) {
+			t.Errorf("expected building A's lobby finish applied, got %v", room.Finish)
+		}
+	}
+
+	if _, _, err := BidTakeoff([]*models.Blueprint{buildingA, {ID: uuid.New()}}); err == nil {
+		t.Error("expected an error for an unanalyzed blueprint")
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// ApplyBidEdits recalculates edited, a hand-edited copy of original. Line
+// items that differ from every original item get their total recomputed from
+// quantity and unit cost and are flagged manual; the rest keep their original
+// totals. Editing only an item's notes does not count. Labor and material costs
+// move by the change in their line item totals, so the original split of
+// bundled items is kept, and the subtotal, markup, total and alternate group
+// prices are recalculated with markupPercentage. Tax is recharged under the
+// original bid's tax rule.
+func ApplyBidEdits(original, edited *models.GenerateBidResponse, markupPercentage float64) error {
+	if markupPercentage < 0 {
+		return fmt.Errorf("markup percentage cannot be negative")
+	}
+
+	// Totals of the original items, by edit key, so an unchanged item keeps
+	// its original total whatever total the edit carries
+	unchanged := make(map[string][]float64)
+	for _, item := range original.LineItems {
+		unchanged[editKey(item)] = append(unchanged[editKey(item)], item.Total)
+	}
+	for _, group := range original.Alternates {
+		for _, item := range group.LineItems {
+			unchanged[editKey(item)] = append(unchanged[editKey(item)], item.Total)
+		}
+	}
+	applyEdit := func(item *models.LineItem) {
+		key := editKey(*item)
+		if totals := unchanged[key]; len(totals) > 0 {
+			item.Total = totals[0]
+			unchanged[key] = totals[1:]
+			return
+		}
+		item.Total = math.Round(item.Quantity*item.UnitCost*100) / 100
+		item.Provenance = models.LineItemProvenanceManual
+	}
+
+	for i := range edited.LineItems {
+		applyEdit(&edited.LineItems[i])
+	}
+	if err := ValidateLineItems(edited.LineItems); err != nil {
+		return err
+	}
+	if err := ValidateLineItemNotes(edited.LineItems); err != nil {
+		return err
+	}
+	for g := range edited.Alternates {
+		group := &edited.Alternates[g]
+		group.Cost = 0
+		for i := range group.LineItems {
+			applyEdit(&group.LineItems[i])
+			group.Cost += group.LineItems[i].Total
+		}
+		if err := ValidateLineItems(group.LineItems); err != nil {
+			return err
+		}
+		if err := ValidateLineItemNotes(group.LineItems); err != nil {
+			return err
+		}
+		group.Cost = math.Round(group.Cost*100) / 100
+		markup := math.Round(group.Cost*markupPercentage) / 100
+		group.Price = math.Round((group.Cost+markup)*100) / 100
+	}
+
+	oldLabor, oldMaterial := splitLineItemTotals(original.LineItems)
+	newLabor, newMaterial := splitLineItemTotals(edited.LineItems)
+	edited.LaborCost = math.Max(0, math.Round((original.LaborCost+newLabor-oldLabor)*100)/100)
+	edited.MaterialCost = math.Max(0, math.Round((original.MaterialCost+newMaterial-oldMaterial)*100)/100)
+	edited.Subtotal = math.Round((edited.LaborCost+edited.MaterialCost)*100) / 100
+	edited.MarkupAmount = math.Round(edited.Subtotal*markupPercentage) / 100
+	edited.TotalPrice = math.Round((edited.Subtotal+edited.MarkupAmount)*100) / 100
+	edited.Tax = original.Tax
+	RecomputeBidTax(edited)
+
+	SortBidLineItems(edited)
+	return nil
+}
+
+// editKey identifies a line item by everything a bid edit can change other
+// than its total
+func editKey(item models.LineItem) string {
+	return fmt.Sprintf("%s|%s|%g|%g", repriceKey(item), item.AlternateGroup, item.Quantity, item.UnitCost)
+}
+
+// splitLineItemTotals sums line item totals into labor and material
+func splitLineItemTotals(items []models.LineItem) (float64, float64) {
+	var labor, material float64
+	for _, item := range items {
+		if isLaborLineItem(item) {
+			labor += item.Total
+		} else {
+			material += item.Total
+		}
+	}
+	return labor, material
+}
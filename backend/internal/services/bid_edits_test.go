@@ -0,0 +1,96 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestApplyBidEdits(t *testing.T) {
+	// The AI split the bundled fixture item 300 material / 100 labor
+	original := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Fixture install", Trade: "plumbing", Quantity: 2, Unit: "each", UnitCost: 200, Total: 400, Provenance: models.LineItemProvenanceAuto},
+			{Description: "Electrician", Trade: "electrical", Quantity: 10, Unit: "hours", UnitCost: 80, Total: 800, Provenance: models.LineItemProvenanceAuto},
+		},
+		Alternates: []models.AlternateGroup{{
+			Name:      "Upgrade",
+			LineItems: []models.LineItem{{Description: "Tile upgrade", Trade: "flooring", Quantity: 100, Unit: "sq ft", UnitCost: 5, Total: 500, AlternateGroup: "Upgrade"}},
+			Cost:      500,
+			Price:     600,
+		}},
+		LaborCost:    900,
+		MaterialCost: 300,
+		Subtotal:     1200,
+		MarkupAmount: 240,
+		TotalPrice:   1440,
+	}
+	edited := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			original.LineItems[0],
+			{Description: "Electrician", Trade: "electrical", Quantity: 12, Unit: "hours", UnitCost: 80, Total: 800, Provenance: models.LineItemProvenanceAuto},
+		},
+		Alternates: []models.AlternateGroup{{
+			Name:      "Upgrade",
+			LineItems: []models.LineItem{{Description: "Tile upgrade", Trade: "flooring", Quantity: 100, Unit: "sq ft", UnitCost: 6, Total: 500, AlternateGroup: "Upgrade"}},
+		}},
+	}
+
+	if err := ApplyBidEdits(original, edited, 10); err != nil {
+		t.Fatalf("ApplyBidEdits failed: %v", err)
+	}
+
+	electrician := edited.LineItems[0]
+	if electrician.Description != "Electrician" || electrician.Total != 960 || electrician.Provenance != models.LineItemProvenanceManual {
+		t.Errorf("electrician = %+v, want 960.00 and manual", electrician)
+	}
+	if fixture := edited.LineItems[1]; fixture.Total != 400 || fixture.Provenance != models.LineItemProvenanceAuto {
+		t.Errorf("fixture = %+v, want the unedited item kept", fixture)
+	}
+	// Only the labor moved, so the bundled item's split is kept
+	if edited.LaborCost != 1060 || edited.MaterialCost != 300 || edited.Subtotal != 1360 || edited.MarkupAmount != 136 || edited.TotalPrice != 1496 {
+		t.Errorf("totals = labor %v, material %v, subtotal %v, markup %v, total %v; want 1060, 300, 1360, 136, 1496",
+			edited.LaborCost, edited.MaterialCost, edited.Subtotal, edited.MarkupAmount, edited.TotalPrice)
+	}
+	if group := edited.Alternates[0]; group.Cost != 600 || group.Price != 660 || group.LineItems[0].Total != 600 {
+		t.Errorf("alternate = %+v, want 600.00 cost priced at 660.00", group)
+	}
+}
+
+func TestApplyBidEdits_KeepsUnchangedTotals(t *testing.T) {
+	original := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Door install", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 400, Total: 800},
+		},
+		MaterialCost: 800,
+		Subtotal:     800,
+	}
+	edited := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Door install", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 400, Total: 1},
+		},
+		Subtotal:   1,
+		TotalPrice: 1,
+	}
+
+	if err := ApplyBidEdits(original, edited, 20); err != nil {
+		t.Fatalf("ApplyBidEdits failed: %v", err)
+	}
+	if edited.LineItems[0].Total != 800 || edited.Subtotal != 800 || edited.TotalPrice != 960 {
+		t.Errorf("door total %v, subtotal %v, total %v; want the client's totals replaced with 800, 800, 960",
+			edited.LineItems[0].Total, edited.Subtotal, edited.TotalPrice)
+	}
+}
+
+func TestApplyBidEdits_RejectsNegativeItems(t *testing.T) {
+	original := &models.GenerateBidResponse{}
+	edited := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{{Description: "Credit", Trade: "general", Quantity: -1, Unit: "lot", UnitCost: 100}},
+	}
+	if err := ApplyBidEdits(original, edited, 20); err == nil {
+		t.Error("expected a negative quantity to be rejected")
+	}
+	if err := ApplyBidEdits(original, &models.GenerateBidResponse{}, -5); err == nil {
+		t.Error("expected a negative markup to be rejected")
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxBidShareEvents is how many engagement events a share link stores;
+// later events are dropped so a client's page can't grow the table
+// without bound
+const MaxBidShareEvents = 1000
+
+// ParseBidShareEventType validates an engagement event a client's page
+// reports. Views of the link are recorded by the server, not reported.
+func ParseBidShareEventType(eventType string) (models.BidShareEventType, error) {
+	switch parsed := models.BidShareEventType(strings.TrimSpace(eventType)); parsed {
+	case models.BidShareEventViewedSummary, models.BidShareEventViewedLineItems, models.BidShareEventDownloadedPDF:
+		return parsed, nil
+	}
+	return "", fmt.Errorf("type must be one of %s, %s or %s",
+		models.BidShareEventViewedSummary, models.BidShareEventViewedLineItems, models.BidShareEventDownloadedPDF)
+}
+
+// HashViewerIP identifies a share link's viewer without storing their IP
+// address. The share ID salts the hash, so viewers can't be followed from
+// one link to another.
+func HashViewerIP(shareID uuid.UUID, ip string) string {
+	sum := sha256.Sum256([]byte(shareID.String() + ":" + ip))
+	return hex.EncodeToString(sum[:])
+}
+
+// AggregateBidEngagement summarizes the events of a bid's shares per share
+// and for the bid. A viewer is unique per share and day, so the bid's unique
+// viewers are the sum over its shares.
+func AggregateBidEngagement(bidID uuid.UUID, shares []*models.BidShare, events []models.BidShareEvent) *models.BidEngagement {
+	byShare := make(map[uuid.UUID][]models.BidShareEvent, len(shares))
+	for _, event := range events {
+		byShare[event.ShareID] = append(byShare[event.ShareID], event)
+	}
+
+	engagement := &models.BidEngagement{BidID: bidID, Shares: make([]models.BidShareEngagement, 0, len(shares))}
+	for _, share := range shares {
+		summary := aggregateShareEngagement(share, byShare[share.ID])
+		engagement.TotalViews += summary.TotalViews
+		engagement.UniqueViewers += summary.UniqueViewers
+		engagement.PDFDownloads += summary.PDFDownloads
+		engagement.FirstViewedAt = earlierTimestamp(engagement.FirstViewedAt, summary.FirstViewedAt)
+		engagement.LastViewedAt = laterTimestamp(engagement.LastViewedAt, summary.LastViewedAt)
+		engagement.Shares = append(engagement.Shares, summary)
+	}
+	return engagement
+}
+
+// aggregateShareEngagement summarizes one share's events
+func aggregateShareEngagement(share *models.BidShare, events []models.BidShareEvent) models.BidShareEngagement {
+	summary := models.BidShareEngagement{
+		ShareID:   share.ID,
+		CreatedAt: share.CreatedAt,
+		ExpiresAt: share.ExpiresAt,
+		RevokedAt: share.RevokedAt,
+	}
+	viewers := make(map[string]bool)
+	for _, event := range events {
+		switch event.EventType {
+		case models.BidShareEventViewed:
+			at := event.CreatedAt
+			summary.TotalViews++
+			summary.FirstViewedAt = earlierTimestamp(summary.FirstViewedAt, &at)
+			summary.LastViewedAt = laterTimestamp(summary.LastViewedAt, &at)
+			viewers[event.IPHash+"/"+event.CreatedAt.Time.UTC().Format("2006-01-02")] = true
+		case models.BidShareEventViewedSummary:
+			summary.SummaryViews++
+		case models.BidShareEventViewedLineItems:
+			summary.LineItemViews++
+		case models.BidShareEventDownloadedPDF:
+			summary.PDFDownloads++
+		}
+	}
+	summary.UniqueViewers = len(viewers)
+	return summary
+}
+
+// earlierTimestamp returns the earlier of two optional timestamps
+func earlierTimestamp(a, b *models.Timestamp) *models.Timestamp {
+	if a == nil || (b != nil && b.Time.Before(a.Time)) {
+		return b
+	}
+	return a
+}
+
+// laterTimestamp returns the later of two optional timestamps
+func laterTimestamp(a, b *models.Timestamp) *models.Timestamp {
+	if a == nil || (b != nil && b.Time.After(a.Time)) {
+		return b
+	}
+	return a
+}
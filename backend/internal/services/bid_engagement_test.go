@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestParseBidShareEventType(t *testing.T) {
+	for _, valid := range []string{"viewed_summary", "viewed_line_items", " downloaded_pdf "} {
+		if _, err := ParseBidShareEventType(valid); err != nil {
+			t.Errorf("ParseBidShareEventType(%q) error = %v", valid, err)
+		}
+	}
+	// Views are recorded by the server
+	for _, invalid := range []string{"", "viewed", "clicked", "VIEWED_SUMMARY"} {
+		if _, err := ParseBidShareEventType(invalid); err == nil {
+			t.Errorf("ParseBidShareEventType(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestHashViewerIP(t *testing.T) {
+	shareID := uuid.New()
+	hash := HashViewerIP(shareID, "203.0.113.7")
+	if len(hash) != 64 || hash != HashViewerIP(shareID, "203.0.113.7") {
+		t.Errorf("hash = %q, want a stable SHA-256", hash)
+	}
+	if hash == HashViewerIP(uuid.New(), "203.0.113.7") || hash == HashViewerIP(shareID, "203.0.113.8") {
+		t.Error("hash matches another share's or another address's")
+	}
+}
+
+func TestAggregateBidEngagement(t *testing.T) {
+	bidID := uuid.New()
+	day := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	first := &models.BidShare{ID: uuid.New(), BidID: bidID, CreatedAt: models.NewTimestamp(day.Add(-time.Hour))}
+	second := &models.BidShare{ID: uuid.New(), BidID: bidID, CreatedAt: models.NewTimestamp(day)}
+	unviewed := &models.BidShare{ID: uuid.New(), BidID: bidID, CreatedAt: models.NewTimestamp(day)}
+
+	event := func(share *models.BidShare, eventType models.BidShareEventType, ip string, at time.Time) models.BidShareEvent {
+		return models.BidShareEvent{ID: uuid.New(), ShareID: share.ID, BidID: bidID, EventType: eventType, IPHash: HashViewerIP(share.ID, ip), CreatedAt: models.NewTimestamp(at)}
+	}
+	events := []models.BidShareEvent{
+		// One viewer twice in a day, then again the next day, and a second
+		// viewer: three unique viewers
+		event(first, models.BidShareEventViewed, "203.0.113.7", day),
+		event(first, models.BidShareEventViewed, "203.0.113.7", day.Add(2*time.Hour)),
+		event(first, models.BidShareEventViewed, "203.0.113.7", day.Add(26*time.Hour)),
+		event(first, models.BidShareEventViewed, "198.51.100.2", day.Add(3*time.Hour)),
+		event(first, models.BidShareEventViewedSummary, "203.0.113.7", day.Add(time.Minute)),
+		event(first, models.BidShareEventViewedLineItems, "203.0.113.7", day.Add(2*time.Minute)),
+		event(first, models.BidShareEventViewedLineItems, "198.51.100.2", day.Add(3*time.Hour)),
+		event(first, models.BidShareEventDownloadedPDF, "203.0.113.7", day.Add(3*time.Minute)),
+		event(second, models.BidShareEventViewed, "203.0.113.7", day.Add(30*time.Hour)),
+		event(second, models.BidShareEventDownloadedPDF, "203.0.113.7", day.Add(30*time.Hour)),
+	}
+
+	engagement := AggregateBidEngagement(bidID, []*models.BidShare{first, second, unviewed}, events)
+	if len(engagement.Shares) != 3 {
+		t.Fatalf("shares = %d, want one summary per share", len(engagement.Shares))
+	}
+	got := engagement.Shares[0]
+	if got.ShareID != first.ID || got.TotalViews != 4 || got.UniqueViewers != 3 || got.SummaryViews != 1 || got.LineItemViews != 2 || got.PDFDownloads != 1 {
+		t.Errorf("first share = %+v, want 4 views by 3 unique viewers, 1 summary view, 2 line item views and 1 download", got)
+	}
+	if got.FirstViewedAt == nil || !got.FirstViewedAt.Time.Equal(day) || got.LastViewedAt == nil || !got.LastViewedAt.Time.Equal(day.Add(26*time.Hour)) {
+		t.Errorf("first share viewed %v to %v, want the first and last views", got.FirstViewedAt, got.LastViewedAt)
+	}
+	if got := engagement.Shares[2]; got.TotalViews != 0 || got.FirstViewedAt != nil || got.LastViewedAt != nil {
+		t.Errorf("unviewed share = %+v, want no views", got)
+	}
+
+	if engagement.TotalViews != 5 || engagement.UniqueViewers != 4 || engagement.PDFDownloads != 2 {
+		t.Errorf("bid totals = %d views, %d unique viewers, %d downloads; want 5, 4 and 2", engagement.TotalViews, engagement.UniqueViewers, engagement.PDFDownloads)
+	}
+	if !engagement.FirstViewedAt.Time.Equal(day) || !engagement.LastViewedAt.Time.Equal(day.Add(30*time.Hour)) {
+		t.Errorf("bid viewed %v to %v, want the first and last views across shares", engagement.FirstViewedAt, engagement.LastViewedAt)
+	}
+}
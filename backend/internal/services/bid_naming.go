@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxBidNameLength is the longest bid name accepted, in characters
+const MaxBidNameLength = 200
+
+// DefaultBidRegion labels bids priced without a regional adjustment
+const DefaultBidRegion = "National"
+
+// ErrInvalidBidName is returned for bid names that are empty, too long, or
+// contain control characters
+var ErrInvalidBidName = errors.New("invalid bid name")
+
+// ValidateBidName trims a bid name and checks its length and characters
+func ValidateBidName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name must not be empty", ErrInvalidBidName)
+	}
+	if utf8.RuneCountInString(name) > MaxBidNameLength {
+		return "", fmt.Errorf("%w: name must be at most %d characters", ErrInvalidBidName, MaxBidNameLength)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("%w: name must not contain control characters", ErrInvalidBidName)
+		}
+	}
+	return name, nil
+}
+
+// DefaultBidName builds a descriptive name for a generated bid, e.g.
+// "Office Remodel – Bid #3 (National) – January 17, 2024". The project name
+// is shortened when needed to keep the result within MaxBidNameLength.
+func DefaultBidName(projectName string, sequence int, region string, at time.Time) string {
+	if region == "" {
+		region = DefaultBidRegion
+	}
+	suffix := fmt.Sprintf(" – Bid #%d (%s) – %s", sequence, region, at.Format("January 2, 2006"))
+
+	projectName = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(projectName))
+	if projectName == "" {
+		projectName = "Untitled Project"
+	}
+	if available := MaxBidNameLength - utf8.RuneCountInString(suffix); utf8.RuneCountInString(projectName) > available {
+		projectName = strings.TrimSpace(string([]rune(projectName)[:available]))
+	}
+	return projectName + suffix
+}
+
+// UniqueBidName returns name, or name with " (2)", " (3)", ... appended when
+// it matches one of the existing names on the project (case-insensitive)
+func UniqueBidName(name string, existing []string) string {
+	taken := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		taken[strings.ToLower(strings.TrimSpace(e))] = true
+	}
+	if !taken[strings.ToLower(name)] {
+		return name
+	}
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		base := name
+		if runes := []rune(base); len(runes)+utf8.RuneCountInString(suffix) > MaxBidNameLength {
+			base = string(runes[:MaxBidNameLength-utf8.RuneCountInString(suffix)])
+		}
+		candidate := base + suffix
+		if !taken[strings.ToLower(candidate)] {
+			return candidate
+		}
+	}
+}
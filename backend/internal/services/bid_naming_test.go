@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestDefaultBidName(t *testing.T) {
+	at := time.Date(2024, 1, 17, 15, 30, 42, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		projectName string
+		sequence    int
+		region      string
+		want        string
+	}{
+		{"national", "Office Remodel", 3, "", "Office Remodel – Bid #3 (National) – January 17, 2024"},
+		{"regional", "Office Remodel", 1, "california", "Office Remodel – Bid #1 (california) – January 17, 2024"},
+		{"blank project", "  ", 2, "", "Untitled Project – Bid #2 (National) – January 17, 2024"},
+		{"control characters stripped", "Office\tRemodel\n", 1, "", "OfficeRemodel – Bid #1 (National) – January 17, 2024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultBidName(tt.projectName, tt.sequence, tt.region, at); got != tt.want {
+				t.Errorf("DefaultBidName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("long project names are shortened", func(t *testing.T) {
+		got := DefaultBidName(strings.Repeat("x", 300), 12, "", at)
+		if utf8.RuneCountInString(got) > MaxBidNameLength {
+			t.Errorf("DefaultBidName() length = %d, want at most %d", utf8.RuneCountInString(got), MaxBidNameLength)
+		}
+		if !strings.HasSuffix(got, " – Bid #12 (National) – January 17, 2024") {
+			t.Errorf("DefaultBidName() = %q, lost its suffix", got)
+		}
+		if _, err := ValidateBidName(got); err != nil {
+			t.Errorf("DefaultBidName() produced an invalid name: %v", err)
+		}
+	})
+}
+
+func TestUniqueBidName(t *testing.T) {
+	existing := []string{"Base Bid", "base bid (2)", "Base Bid (3)", "Alternate"}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"unused name kept", "Revised Bid", "Revised Bid"},
+		{"duplicate suffixed past taken suffixes", "Base Bid", "Base Bid (4)"},
+		{"case-insensitive match", "ALTERNATE", "ALTERNATE (2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UniqueBidName(tt.in, existing); got != tt.want {
+				t.Errorf("UniqueBidName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("suffix fits within the length limit", func(t *testing.T) {
+		long := strings.Repeat("a", MaxBidNameLength)
+		got := UniqueBidName(long, []string{long})
+		if utf8.RuneCountInString(got) != MaxBidNameLength || !strings.HasSuffix(got, " (2)") {
+			t.Errorf("UniqueBidName() = %q (%d chars)", got, utf8.RuneCountInString(got))
+		}
+	})
+}
+
+func TestValidateBidName(t *testing.T) {
+	if got, err := ValidateBidName("  Base Bid  "); err != nil || got != "Base Bid" {
+		t.Errorf("ValidateBidName() = %q, %v; want trimmed name", got, err)
+	}
+	if _, err := ValidateBidName(strings.Repeat("é", MaxBidNameLength)); err != nil {
+		t.Errorf("Expected %d multi-byte characters to be accepted, got %v", MaxBidNameLength, err)
+	}
+
+	for _, name := range []string{"", "   ", strings.Repeat("a", MaxBidNameLength+1), "Bid\x00One", "Bid\nOne"} {
+		if _, err := ValidateBidName(name); !errors.Is(err, ErrInvalidBidName) {
+			t.Errorf("ValidateBidName(%q) error = %v, want ErrInvalidBidName", name, err)
+		}
+	}
+}
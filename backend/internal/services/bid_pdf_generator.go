@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+)
+
+// ErrInvalidBidData is returned when a bid's stored data can't be parsed to
+// render its PDF. Rendering again can't succeed, so it is not retried.
+var ErrInvalidBidData = errors.New("invalid bid data")
+
+// BidPDFBidStore reads bids and stores their PDF fields
+type BidPDFBidStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Bid, error)
+	UpdatePDF(ctx context.Context, bid *models.Bid) error
+}
+
+// BidPDFProjectStore reads the project a bid belongs to
+type BidPDFProjectStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error)
+}
+
+// BidPDFBlueprintStore reads the blueprint whose pages a PDF embeds
+type BidPDFBlueprintStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Blueprint, error)
+}
+
+// BidPDFUserStore reads the company's bid document layout
+type BidPDFUserStore interface {
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+}
+
+// BidPDFProfileStore reads the company profile bid PDFs are branded with
+type BidPDFProfileStore interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.CompanyProfile, error)
+}
+
+// BidPDFAcceptanceStore reads the online acceptance an accepted bid's PDF
+// is stamped with
+type BidPDFAcceptanceStore interface {
+	GetLatestByBidID(ctx context.Context, bidID uuid.UUID) (*models.BidAcceptance, error)
+}
+
+// BidPDFGenerator renders a bid's PDF with its company's layout and
+// branding and stores it through a BidPDFPublisher. The worker runs it for
+// PDF generation jobs.
+type BidPDFGenerator struct {
+	bids        BidPDFBidStore
+	projects    BidPDFProjectStore
+	blueprints  BidPDFBlueprintStore
+	users       BidPDFUserStore
+	profiles    BidPDFProfileStore
+	acceptances BidPDFAcceptanceStore
+	publisher   *BidPDFPublisher
+	pages       *BlueprintPageRenderer
+	logos       BlueprintFileSource
+}
+
+// NewBidPDFGenerator creates a generator. profiles and logos may be nil, in
+// which case PDFs carry the default branding or no logo.
+func NewBidPDFGenerator(
+	bids BidPDFBidStore,
+	projects BidPDFProjectStore,
+	blueprints BidPDFBlueprintStore,
+	users BidPDFUserStore,
+	profiles BidPDFProfileStore,
+	publisher *BidPDFPublisher,
+	pages *BlueprintPageRenderer,
+	logos BlueprintFileSource,
+) *BidPDFGenerator {
+	return &BidPDFGenerator{
+		bids:       bids,
+		projects:   projects,
+		blueprints: blueprints,
+		users:      users,
+		profiles:   profiles,
+		publisher:  publisher,
+		pages:      pages,
+		logos:      logos,
+	}
+}
+
+// WithAcceptances stamps the PDFs of bids accepted online with who accepted
+// them and when
+func (g *BidPDFGenerator) WithAcceptances(acceptances BidPDFAcceptanceStore) *BidPDFGenerator {
+	g.acceptances = acceptances
+	return g
+}
+
+// Generate renders the current data of a bid to a stored PDF, reusing the
+// stored PDF when its inputs are unchanged, and returns the bid with its PDF
+// fields set. Requested blueprint pages are taken from blueprintID; request
+// may be nil.
+func (g *BidPDFGenerator) Generate(ctx context.Context, bidID, blueprintID uuid.UUID, request *models.BidPDFRequest) (*models.Bid, error) {
+	bid, err := g.bids.GetByID(ctx, bidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bid: %w", err)
+	}
+	if bid.BidData == nil {
+		return nil, fmt.Errorf("%w: bid has no data", ErrInvalidBidData)
+	}
+	bidResponse, err := NewPDFService().ParseBidDataFromJSON(*bid.BidData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBidData, err)
+	}
+
+	project, err := g.projects.GetByID(ctx, bid.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	options, cleanup := g.options(ctx, project.UserID, blueprintID, request)
+	options = g.withAcceptance(ctx, bid, options)
+	changed, err := g.publisher.Publish(ctx, bid, bidResponse, project.Name, options)
+	cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		bid.UpdatedAt = models.Now()
+		if err := g.bids.UpdatePDF(ctx, bid); err != nil {
+			return nil, err
+		}
+	}
+	return bid, nil
+}
+
+// options are the PDF options for a company's bid: the requested content,
+// the company's layout and its profile. The caller runs the returned cleanup
+// once the PDF is rendered.
+func (g *BidPDFGenerator) options(ctx context.Context, ownerID, blueprintID uuid.UUID, request *models.BidPDFRequest) (*PDFOptions, func()) {
+	var options *PDFOptions
+	if request != nil && (len(request.IncludeBlueprintPages) > 0 || request.IncludeEstimateRange || request.IncludeSignatureBlock) {
+		options = &PDFOptions{IncludeEstimateRange: request.IncludeEstimateRange, IncludeSignatureBlock: request.IncludeSignatureBlock}
+		if len(request.IncludeBlueprintPages) > 0 {
+			options.BlueprintPages = g.blueprintPages(ctx, blueprintID, request.IncludeBlueprintPages)
+		}
+	}
+
+	if owner, err := g.users.GetUserByID(ctx, ownerID); err != nil {
+		slog.Warn("Failed to load company PDF layout", "error", err, "user_id", ownerID)
+	} else if owner.PDFLayout != nil {
+		if options == nil {
+			options = &PDFOptions{}
+		}
+		options.Layout = owner.PDFLayout
+	}
+
+	return g.withCompanyBranding(ctx, g.companyProfile(ctx, ownerID), options)
+}
+
+// withAcceptance adds the online acceptance of an accepted bid to its PDF
+// options. Bids accepted another way, or whose acceptance can't be loaded,
+// are rendered without the stamp.
+func (g *BidPDFGenerator) withAcceptance(ctx context.Context, bid *models.Bid, options *PDFOptions) *PDFOptions {
+	if g.acceptances == nil || bid.Status != models.BidStatusAccepted {
+		return options
+	}
+	acceptance, err := g.acceptances.GetLatestByBidID(ctx, bid.ID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrBidAcceptanceNotFound) {
+			slog.Warn("Failed to load bid acceptance", "error", err, "bid_id", bid.ID)
+		}
+		return options
+	}
+	if options == nil {
+		options = &PDFOptions{}
+	}
+	options.Acceptance = acceptance
+	return options
+}
+
+// blueprintPages renders the requested pages of a blueprint; the PDF notes
+// pages that could not be rendered
+func (g *BidPDFGenerator) blueprintPages(ctx context.Context, blueprintID uuid.UUID, pages []int) *BlueprintAttachment {
+	blueprint, err := g.blueprints.GetByID(ctx, blueprintID)
+	if err != nil {
+		slog.Warn("Failed to load blueprint for bid PDF pages", "error", err, "blueprint_id", blueprintID)
+		attachment := &BlueprintAttachment{}
+		for _, page := range pages {
+			attachment.Skipped = append(attachment.Skipped, fmt.Sprintf("Page %d could not be included: blueprint unavailable", page))
+		}
+		return attachment
+	}
+	return g.pages.RenderPages(ctx, blueprint, pages)
+}
+
+// companyProfile is the profile of the company that owns a bid, nil when it
+// has none. PDFs fall back to the default branding when it can't be loaded.
+func (g *BidPDFGenerator) companyProfile(ctx context.Context, ownerID uuid.UUID) *models.CompanyProfile {
+	if g.profiles == nil {
+		return nil
+	}
+	profile, err := g.profiles.GetByUserID(ctx, ownerID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrCompanyProfileNotFound) {
+			slog.Warn("Failed to load company profile", "error", err, "user_id", ownerID)
+		}
+		return nil
+	}
+	return profile
+}
+
+// withCompanyBranding adds a company profile to PDF options, downloading its
+// logo to a temporary file for the renderer. Options are returned unchanged
+// without a profile, and without the logo when it can't be downloaded. The
+// caller runs the returned cleanup once the PDF is rendered.
+func (g *BidPDFGenerator) withCompanyBranding(ctx context.Context, profile *models.CompanyProfile, options *PDFOptions) (*PDFOptions, func()) {
+	cleanup := func() {}
+	if profile == nil {
+		return options, cleanup
+	}
+	if options == nil {
+		options = &PDFOptions{}
+	}
+	options.CompanyInfo = CompanyProfileInfo(profile)
+
+	if profile.LogoS3Key == nil {
+		return options, cleanup
+	}
+	// Without the logo its key is left out, so the PDF is rendered again once
+	// the logo can be downloaded
+	if g.logos == nil {
+		options.CompanyInfo.Logo = nil
+		return options, cleanup
+	}
+	logoPath, removeLogo, err := DownloadCompanyLogo(ctx, g.logos, *profile.LogoS3Key)
+	if err != nil {
+		slog.Warn("Rendering bid PDF without company logo", "error", err, "user_id", profile.UserID, "s3_key", *profile.LogoS3Key)
+		options.CompanyInfo.Logo = nil
+		return options, cleanup
+	}
+	options.IncludeLogo = true
+	options.LogoPath = logoPath
+	return options, removeLogo
+}
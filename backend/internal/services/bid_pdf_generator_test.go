@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+)
+
+type fakePDFBids struct {
+	bids    map[uuid.UUID]*models.Bid
+	updated int
+}
+
+func (f *fakePDFBids) GetByID(ctx context.Context, id uuid.UUID) (*models.Bid, error) {
+	bid, ok := f.bids[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return bid, nil
+}
+
+func (f *fakePDFBids) UpdatePDF(ctx context.Context, bid *models.Bid) error {
+	f.updated++
+	return nil
+}
+
+type fakePDFProjects map[uuid.UUID]*models.Project
+
+func (f fakePDFProjects) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	project, ok := f[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return project, nil
+}
+
+type fakePDFUsers map[uuid.UUID]*models.User
+
+func (f fakePDFUsers) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, ok := f[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return user, nil
+}
+
+type fakePDFAcceptances map[uuid.UUID]*models.BidAcceptance
+
+func (f fakePDFAcceptances) GetLatestByBidID(ctx context.Context, bidID uuid.UUID) (*models.BidAcceptance, error) {
+	acceptance, ok := f[bidID]
+	if !ok {
+		return nil, repository.ErrBidAcceptanceNotFound
+	}
+	return acceptance, nil
+}
+
+// flakyPDFStore fails the first failUploads uploads, like an S3 outage
+type flakyPDFStore struct {
+	fakeObjectStore
+	failUploads int
+	attempts    int
+}
+
+func (f *flakyPDFStore) UploadFile(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	f.attempts++
+	if f.attempts <= f.failUploads {
+		return "", errors.New("s3 unavailable")
+	}
+	return f.fakeObjectStore.UploadFile(ctx, key, data, contentType)
+}
+
+type bidPDFWorkerTest struct {
+	worker  *Worker
+	jobs    *fakeWorkerJobs
+	bids    *fakePDFBids
+	objects *flakyPDFStore
+	bid     *models.Bid
+}
+
+func newBidPDFWorkerTest(bidData string, failUploads, maxRetries int) *bidPDFWorkerTest {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Office Remodel"}
+	blueprintID := uuid.New()
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusDraft, Version: 1, BidData: &bidData, BlueprintIDs: []uuid.UUID{blueprintID}}
+
+	bids := &fakePDFBids{bids: map[uuid.UUID]*models.Bid{bid.ID: bid}}
+	blueprints := &fakeWorkerBlueprints{blueprints: map[uuid.UUID]models.Blueprint{
+		blueprintID: {ID: blueprintID, ProjectID: project.ID, Filename: "plans.pdf", S3Key: "blueprints/plans.pdf"},
+	}}
+	objects := &flakyPDFStore{failUploads: failUploads}
+	generator := NewBidPDFGenerator(bids, fakePDFProjects{project.ID: project}, blueprints, fakePDFUsers{userID: {ID: userID}}, nil,
+		NewBidPDFPublisher(objects, nil, 0), NewBlueprintPageRenderer(nil, nil), nil)
+
+	jobs := &fakeWorkerJobs{jobs: []*models.Job{{
+		ID:          uuid.New(),
+		BlueprintID: blueprintID,
+		JobType:     models.JobTypePDFGeneration,
+		Status:      models.JobStatusQueued,
+		BidID:       &bid.ID,
+		PDFRequest:  &models.BidPDFRequest{IncludeSignatureBlock: true},
+	}}}
+	cfg := &config.Config{Worker: config.WorkerConfig{MaxRetries: maxRetries}}
+	worker := NewWorker(jobs, blueprints, &StubAIProvider{}, cfg).WithBidPDFs(generator)
+	return &bidPDFWorkerTest{worker: worker, jobs: jobs, bids: bids, objects: objects, bid: bid}
+}
+
+const workerTestBidData = `{"scope_of_work": "Remodel", "line_items": [{"description": "Framing", "trade": "framing", "quantity": 1, "unit": "lot", "unit_cost": 1000, "total": 1000}], "total_price": 1200}`
+
+func TestWorker_BidPDFJobStoresPDF(t *testing.T) {
+	test := newBidPDFWorkerTest(workerTestBidData, 0, 3)
+	job := test.jobs.jobs[0]
+
+	if err := test.worker.processJob(context.Background(), job); err != nil {
+		t.Fatalf("processJob() error = %v", err)
+	}
+
+	if job.Status != models.JobStatusCompleted || job.Progress != ProgressComplete {
+		t.Errorf("job = %s at %d%%, want completed at 100%%", job.Status, job.Progress)
+	}
+	bid := test.bid
+	if bid.PDFURL == nil || bid.PDFS3Key == nil || test.objects.objects[*bid.PDFS3Key] == nil || test.bids.updated != 1 {
+		t.Fatalf("bid PDF = %v after %d updates, want an uploaded PDF stored on the bid", bid.PDFURL, test.bids.updated)
+	}
+	// Rendered with the requested options
+	if want := BidPDFHash(bid, "Office Remodel", &PDFOptions{IncludeSignatureBlock: true}); *bid.PDFHash != want {
+		t.Errorf("PDF hash = %s, want the hash of the requested options %s", *bid.PDFHash, want)
+	}
+
+	var result map[string]string
+	if job.ResultData == nil || json.Unmarshal([]byte(*job.ResultData), &result) != nil || result["pdf_url"] != *bid.PDFURL {
+		t.Errorf("job result = %v, want the PDF URL", job.ResultData)
+	}
+}
+
+func TestWorker_BidPDFJobFailsOnUnparseableBidData(t *testing.T) {
+	test := newBidPDFWorkerTest(`{"scope_of_work": "Remodel", "line_items": "not a list"`, 0, 3)
+	job := test.jobs.jobs[0]
+	recorder := &events.Recorder{}
+	test.worker.WithEvents(recorder)
+
+	if err := test.worker.processJob(context.Background(), job); err == nil {
+		t.Fatal("processJob() error = nil, want the parse failure")
+	}
+
+	// Parsing again can't succeed, so the job fails without a retry
+	if job.Status != models.JobStatusFailed || job.RetryCount != 0 || job.ErrorMessage == nil {
+		t.Errorf("job = %s after %d retries, want failed without a retry", job.Status, job.RetryCount)
+	}
+	if test.bid.PDFURL != nil || test.objects.attempts != 0 {
+		t.Errorf("bid PDF = %v after %d uploads, want nothing uploaded", test.bid.PDFURL, test.objects.attempts)
+	}
+	if failed := events.Recorded[events.JobFailed](recorder); len(failed) != 1 || failed[0].JobID != job.ID || failed[0].Error != *job.ErrorMessage {
+		t.Errorf("published %+v, want one JobFailed with the job's error", failed)
+	}
+}
+
+func TestWorker_BidPDFJobRetriesStorageFailures(t *testing.T) {
+	test := newBidPDFWorkerTest(workerTestBidData, 2, 3)
+	job := test.jobs.jobs[0]
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := test.worker.processJob(context.Background(), job); err == nil {
+			t.Fatalf("attempt %d: processJob() error = nil, want the upload failure", attempt)
+		}
+		if job.Status != models.JobStatusQueued || job.RetryCount != attempt || job.StartedAt != nil {
+			t.Fatalf("attempt %d: job = %s after %d retries, want requeued", attempt, job.Status, job.RetryCount)
+		}
+	}
+
+	if err := test.worker.processJob(context.Background(), job); err != nil {
+		t.Fatalf("third attempt: processJob() error = %v", err)
+	}
+	if job.Status != models.JobStatusCompleted || test.bid.PDFURL == nil {
+		t.Errorf("job = %s with PDF %v, want completed once the upload succeeds", job.Status, test.bid.PDFURL)
+	}
+}
+
+func TestWorker_BidPDFJobFailsAfterMaxRetries(t *testing.T) {
+	test := newBidPDFWorkerTest(workerTestBidData, 10, 1)
+	job := test.jobs.jobs[0]
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if err := test.worker.processJob(context.Background(), job); err == nil {
+			t.Fatalf("attempt %d: processJob() error = nil, want the upload failure", attempt)
+		}
+	}
+
+	if job.Status != models.JobStatusFailed || job.RetryCount != 1 || test.objects.attempts != 2 {
+		t.Errorf("job = %s after %d retries and %d uploads, want failed after one retry", job.Status, job.RetryCount, test.objects.attempts)
+	}
+	if test.bid.PDFURL != nil {
+		t.Errorf("bid PDF = %v, want none", *test.bid.PDFURL)
+	}
+}
+
+func TestBidPDFGenerator_StampsOnlineAcceptance(t *testing.T) {
+	userID := uuid.New()
+	project := &models.Project{ID: uuid.New(), UserID: userID, Name: "Office Remodel"}
+	bidData := workerTestBidData
+	bid := &models.Bid{ID: uuid.New(), ProjectID: project.ID, Status: models.BidStatusAccepted, Version: 2, BidData: &bidData}
+	acceptance := &models.BidAcceptance{
+		ID: uuid.New(), BidID: bid.ID, ShareID: uuid.New(), SignerName: "Pat Owner", SignerEmail: "pat@example.com",
+		AcceptedAt: models.NewTimestamp(time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)),
+	}
+	objects := &flakyPDFStore{}
+	generator := NewBidPDFGenerator(&fakePDFBids{bids: map[uuid.UUID]*models.Bid{bid.ID: bid}}, fakePDFProjects{project.ID: project},
+		&fakeWorkerBlueprints{}, fakePDFUsers{userID: {ID: userID}}, nil, NewBidPDFPublisher(objects, nil, 0), NewBlueprintPageRenderer(nil, nil), nil)
+
+	// Rendered before the acceptance was recorded
+	if _, err := generator.Generate(context.Background(), bid.ID, uuid.Nil, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unstamped := *bid.PDFHash
+
+	// Accepting clears the PDF URL, so the next download renders again
+	generator.WithAcceptances(fakePDFAcceptances{bid.ID: acceptance})
+	bid.PDFURL = nil
+	if _, err := generator.Generate(context.Background(), bid.ID, uuid.Nil, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if want := BidPDFHash(bid, project.Name, &PDFOptions{Acceptance: acceptance}); *bid.PDFHash == unstamped || *bid.PDFHash != want {
+		t.Errorf("PDF hash = %s, want the stamped hash %s", *bid.PDFHash, want)
+	}
+	if len(objects.objects) != 2 {
+		t.Errorf("stored %d PDFs, want the stamped PDF uploaded beside the first", len(objects.objects))
+	}
+
+	// A bid that is no longer accepted is not stamped
+	bid.Status = models.BidStatusSent
+	if _, err := generator.Generate(context.Background(), bid.ID, uuid.Nil, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if want := BidPDFHash(bid, project.Name, nil); *bid.PDFHash != want {
+		t.Errorf("PDF hash of a sent bid = %s, want the unstamped hash %s", *bid.PDFHash, want)
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// PricedBidResponse builds a bid's line items and totals straight from a
+// pricing summary, without the AI-written text. Markup is applied to the
+// labor and material subtotal, and the summary's tax rule, if any, is charged
+// on the result.
+func PricedBidResponse(summary *models.PricingSummary, markupPercentage float64) models.GenerateBidResponse {
+	// Pricing builds labor items from a map; sort so repeated calls match exactly
+	lineItems := append([]models.LineItem(nil), summary.LineItems...)
+	sort.SliceStable(lineItems, func(i, j int) bool {
+		if lineItems[i].Unit != lineItems[j].Unit {
+			return lineItems[j].Unit == "hours"
+		}
+		return lineItems[i].Description < lineItems[j].Description
+	})
+
+	subtotal := summary.LaborCost + summary.MaterialCost
+	markup := math.Round(subtotal*markupPercentage) / 100
+
+	bid := models.GenerateBidResponse{
+		LineItems:    lineItems,
+		LaborCost:    summary.LaborCost,
+		MaterialCost: summary.MaterialCost,
+		Subtotal:     subtotal,
+		MarkupAmount: markup,
+		TotalPrice:   math.Round((subtotal+markup)*100) / 100,
+	}
+	ApplyBidTax(&bid, summary.Tax)
+	return bid
+}
+
+// AIUnavailableWarning is the warning on bids written without the AI service
+const AIUnavailableWarning = "The AI service was unavailable, so the scope of work was assembled from the takeoff and the bid has no AI-written terms; review it before sending"
+
+// maxScopeRooms caps how many rooms a deterministic scope of work names
+const maxScopeRooms = 10
+
+// DeterministicBidResponse builds a complete bid without the AI service: the
+// line items and totals of PricedBidResponse, with a generic scope of work
+// listing the takeoff's rooms
+func DeterministicBidResponse(summary *models.PricingSummary, takeoff *models.TakeoffSummary, markupPercentage float64) models.GenerateBidResponse {
+	bid := PricedBidResponse(summary, markupPercentage)
+	bid.ScopeOfWork = deterministicScopeOfWork(takeoff)
+	bid.Inclusions = []string{}
+	bid.Exclusions = []string{}
+	bid.Schedule = map[string]string{}
+	bid.GenerationMode = models.BidGenerationModeDeterministic
+	bid.Warnings = []string{AIUnavailableWarning}
+	return bid
+}
+
+// deterministicScopeOfWork describes the work as the takeoff's rooms and
+// total area
+func deterministicScopeOfWork(takeoff *models.TakeoffSummary) string {
+	if takeoff == nil || len(takeoff.RoomBreakdown) == 0 {
+		return "Furnish labor and materials for the work itemized below."
+	}
+
+	names := make([]string, 0, maxScopeRooms)
+	for _, room := range takeoff.RoomBreakdown {
+		if len(names) == maxScopeRooms {
+			break
+		}
+		if name := strings.TrimSpace(room.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	rooms := strings.Join(names, ", ")
+	if more := len(takeoff.RoomBreakdown) - len(names); more > 0 && len(names) > 0 {
+		rooms = fmt.Sprintf("%s and %d more", rooms, more)
+	}
+
+	areaUnit := takeoff.AreaUnit
+	if areaUnit == "" {
+		areaUnit = UnitLabelSquareFeet
+	}
+	scope := fmt.Sprintf("Furnish labor and materials for the work itemized below across %d rooms", len(takeoff.RoomBreakdown))
+	if rooms != "" {
+		scope += " (" + rooms + ")"
+	}
+	if takeoff.TotalArea > 0 {
+		scope += fmt.Sprintf(", totaling %.0f %s", takeoff.TotalArea, areaUnit)
+	}
+	return scope + "."
+}
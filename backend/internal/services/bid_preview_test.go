@@ -0,0 +1,64 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestDeterministicBidResponse(t *testing.T) {
+	summary := &models.PricingSummary{
+		LineItems: []models.LineItem{
+			{Description: "Interior door", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 450, Total: 900},
+			{Description: "Carpentry labor", Trade: "carpentry", Quantity: 4, Unit: "hours", UnitCost: 75, Total: 300},
+		},
+		LaborCost:    300,
+		MaterialCost: 900,
+	}
+	takeoff := &models.TakeoffSummary{
+		TotalArea: 350,
+		RoomBreakdown: []models.RoomSummary{
+			{Name: "Office", Area: 200},
+			{Name: " Break Room ", Area: 150},
+		},
+	}
+
+	bid := DeterministicBidResponse(summary, takeoff, 20)
+	if bid.GenerationMode != models.BidGenerationModeDeterministic {
+		t.Errorf("generation mode = %q, want deterministic", bid.GenerationMode)
+	}
+	if len(bid.LineItems) != 2 || bid.Subtotal != 1200 || bid.MarkupAmount != 240 || bid.TotalPrice != 1440 {
+		t.Errorf("bid = %d items, subtotal %.2f, markup %.2f, total %.2f; want the priced summary with 20%% markup",
+			len(bid.LineItems), bid.Subtotal, bid.MarkupAmount, bid.TotalPrice)
+	}
+	if want := "Furnish labor and materials for the work itemized below across 2 rooms (Office, Break Room), totaling 350 SF."; bid.ScopeOfWork != want {
+		t.Errorf("scope = %q, want %q", bid.ScopeOfWork, want)
+	}
+	if len(bid.Warnings) != 1 || bid.Warnings[0] != AIUnavailableWarning {
+		t.Errorf("warnings = %v, want the AI unavailable warning", bid.Warnings)
+	}
+
+	// Long room lists are cut short
+	takeoff.RoomBreakdown = nil
+	for i := 0; i < maxScopeRooms+3; i++ {
+		takeoff.RoomBreakdown = append(takeoff.RoomBreakdown, models.RoomSummary{Name: "Room"})
+	}
+	if scope := deterministicScopeOfWork(takeoff); !strings.Contains(scope, "across 13 rooms") || !strings.Contains(scope, "and 3 more") {
+		t.Errorf("scope = %q, want 13 rooms with 3 unnamed", scope)
+	}
+	if scope := deterministicScopeOfWork(nil); scope == "" {
+		t.Error("expected a scope without a takeoff")
+	}
+
+	// The PDF renders without AI-written text
+	finalPrice := bid.TotalPrice
+	pdfBytes, err := NewPDFService().GenerateBidPDF(&models.Bid{ID: uuid.New(), FinalPrice: &finalPrice, Status: models.BidStatusDraft, Version: 1}, &bid, "Test Project")
+	if err != nil {
+		t.Fatalf("GenerateBidPDF() error = %v", err)
+	}
+	if len(pdfBytes) < 4 || string(pdfBytes[:4]) != "%PDF" {
+		t.Error("expected the output to be a PDF")
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+const (
+	// DefaultBidShareExpiry is how long a bid share link works when no
+	// expiry is configured
+	DefaultBidShareExpiry = 14 * 24 * time.Hour
+	// maxResponderNameLength matches the bid_shares.responder_name column
+	maxResponderNameLength = 255
+	// maxSignerEmailLength matches the bid_acceptances.signer_email column
+	maxSignerEmailLength = 255
+)
+
+// NewBidShareToken returns a random token for a bid share link and the hash
+// to store for it
+func NewBidShareToken() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate bid share token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	return token, HashBidShareToken(token), nil
+}
+
+// HashBidShareToken hashes a bid share token for storage and lookup. Tokens
+// are long and random, so the fast API key hash is enough.
+func HashBidShareToken(token string) string {
+	return hashAPIKey(token)
+}
+
+// BidShareURL is the link a client opens to view a shared bid
+func BidShareURL(baseURL, token string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + token
+}
+
+// ParseBidShareResponse validates a client's response to a shared bid: the
+// decision must be accepted or rejected and the responder must give a name.
+// A blank comment is no comment.
+func ParseBidShareResponse(decision string, comment *string, responderName string) (models.BidShareResponse, error) {
+	status := models.BidStatus(strings.TrimSpace(decision))
+	if status != models.BidStatusAccepted && status != models.BidStatusRejected {
+		return models.BidShareResponse{}, fmt.Errorf("decision must be accepted or rejected")
+	}
+
+	name := strings.TrimSpace(responderName)
+	if name == "" {
+		return models.BidShareResponse{}, fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxResponderNameLength {
+		return models.BidShareResponse{}, fmt.Errorf("name must be at most %d characters", maxResponderNameLength)
+	}
+
+	var trimmed *string
+	if comment != nil {
+		if text := strings.TrimSpace(*comment); text != "" {
+			if len(text) > MaxBidStatusNoteLength {
+				return models.BidShareResponse{}, fmt.Errorf("comment must be at most %d characters", MaxBidStatusNoteLength)
+			}
+			trimmed = &text
+		}
+	}
+	return models.BidShareResponse{Status: status, Comment: trimmed, ResponderName: name}, nil
+}
+
+// ParseBidSignerEmail validates the email a client signs an acceptance with
+func ParseBidSignerEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", fmt.Errorf("email is required to accept")
+	}
+	if !strings.Contains(email, "@") {
+		return "", fmt.Errorf("email must be an email address")
+	}
+	if utf8.RuneCountInString(email) > maxSignerEmailLength {
+		return "", fmt.Errorf("email must be at most %d characters", maxSignerEmailLength)
+	}
+	return email, nil
+}
+
+// BidAcceptanceStamp is the line a bid PDF is stamped with once its client
+// accepted it online
+func BidAcceptanceStamp(acceptance *models.BidAcceptance) string {
+	return fmt.Sprintf("Accepted by %s on %s", acceptance.SignerName, acceptance.AcceptedAt.Time.Format("January 2, 2006"))
+}
+
+// NewPublicBid builds the client's view of a shared bid from its stored bid
+// data. Costs, price sources, review flags and estimator warnings stay
+// internal.
+func NewPublicBid(bid *models.Bid, data *models.GenerateBidResponse, share *models.BidShare) *models.PublicBid {
+	lineItems := make([]models.PublicLineItem, 0, len(data.LineItems))
+	for _, item := range data.LineItems {
+		lineItems = append(lineItems, models.PublicLineItem{
+			Description:    item.Description,
+			Trade:          item.Trade,
+			Quantity:       item.Quantity,
+			Unit:           item.Unit,
+			UnitCost:       item.UnitCost,
+			Total:          item.Total,
+			IsAlternate:    item.IsAlternate,
+			AlternateGroup: item.AlternateGroup,
+			Notes:          item.Notes,
+		})
+	}
+
+	return &models.PublicBid{
+		Name:             bid.Name,
+		Status:           bid.Status,
+		ScopeOfWork:      data.ScopeOfWork,
+		LineItems:        lineItems,
+		Subtotal:         data.Subtotal,
+		MarkupAmount:     data.MarkupAmount,
+		TaxAmount:        data.TaxAmount,
+		TotalPrice:       data.TotalPrice,
+		Exclusions:       data.Exclusions,
+		Inclusions:       data.Inclusions,
+		Schedule:         data.Schedule,
+		PaymentTerms:     data.PaymentTerms,
+		WarrantyTerms:    data.WarrantyTerms,
+		ClosingStatement: data.ClosingStatement,
+		PDFURL:           bid.PDFURL,
+		ExpiresAt:        share.ExpiresAt,
+		RespondedAt:      share.RespondedAt,
+		Response:         share.Response,
+	}
+}
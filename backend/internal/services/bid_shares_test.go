@@ -0,0 +1,66 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestNewBidShareToken(t *testing.T) {
+	token, hash, err := NewBidShareToken()
+	if err != nil {
+		t.Fatalf("NewBidShareToken failed: %v", err)
+	}
+	other, _, _ := NewBidShareToken()
+	if len(token) < 40 || token == other {
+		t.Errorf("tokens %q and %q, want long and distinct", token, other)
+	}
+	if hash != HashBidShareToken(token) || strings.Contains(hash, token) {
+		t.Errorf("hash = %q, want the token's hash", hash)
+	}
+	if got := BidShareURL("https://app.example.com/shared/", token); got != "https://app.example.com/shared/"+token {
+		t.Errorf("BidShareURL = %q", got)
+	}
+}
+
+func TestParseBidShareResponse(t *testing.T) {
+	blank := "   "
+	response, err := ParseBidShareResponse(" rejected ", &blank, " Pat Owner ")
+	if err != nil {
+		t.Fatalf("ParseBidShareResponse failed: %v", err)
+	}
+	if response.Status != models.BidStatusRejected || response.Comment != nil || response.ResponderName != "Pat Owner" {
+		t.Errorf("response = %+v, want a rejection by Pat Owner with no comment", response)
+	}
+
+	long := strings.Repeat("x", MaxBidStatusNoteLength+1)
+	invalid := []struct {
+		decision string
+		comment  *string
+		name     string
+	}{
+		{"sent", nil, "Pat Owner"},
+		{"", nil, "Pat Owner"},
+		{"accepted", nil, ""},
+		{"accepted", nil, strings.Repeat("n", maxResponderNameLength+1)},
+		{"accepted", &long, "Pat Owner"},
+	}
+	for _, tt := range invalid {
+		if _, err := ParseBidShareResponse(tt.decision, tt.comment, tt.name); err == nil {
+			t.Errorf("ParseBidShareResponse(%q, %.10q) succeeded, want an error", tt.decision, tt.name)
+		}
+	}
+}
+
+func TestParseBidSignerEmail(t *testing.T) {
+	email, err := ParseBidSignerEmail(" pat@example.com ")
+	if err != nil || email != "pat@example.com" {
+		t.Errorf("ParseBidSignerEmail = %q, %v; want the trimmed address", email, err)
+	}
+	for _, invalid := range []string{"", "   ", "pat.example.com", strings.Repeat("p", maxSignerEmailLength) + "@example.com"} {
+		if _, err := ParseBidSignerEmail(invalid); err == nil {
+			t.Errorf("ParseBidSignerEmail(%.20q) succeeded, want an error", invalid)
+		}
+	}
+}
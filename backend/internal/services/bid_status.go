@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxBidStatusNoteLength bounds the note recorded with a bid status change
+const MaxBidStatusNoteLength = 1000
+
+// bidStatusTransitions lists the statuses each bid status can move to. A bid
+// is sent before the client decides on it, and accepted and rejected bids are
+// final.
+var bidStatusTransitions = map[models.BidStatus][]models.BidStatus{
+	models.BidStatusDraft:    {models.BidStatusSent},
+	models.BidStatusSent:     {models.BidStatusAccepted, models.BidStatusRejected},
+	models.BidStatusAccepted: nil,
+	models.BidStatusRejected: nil,
+}
+
+// ParseBidStatus validates a bid status
+func ParseBidStatus(value string) (models.BidStatus, error) {
+	status := models.BidStatus(strings.TrimSpace(value))
+	if _, ok := bidStatusTransitions[status]; !ok {
+		return "", fmt.Errorf("status must be one of draft, sent, accepted or rejected")
+	}
+	return status, nil
+}
+
+// AllowedBidStatusTransitions returns the statuses a bid can move to from
+// status, none once it is accepted or rejected
+func AllowedBidStatusTransitions(status models.BidStatus) []models.BidStatus {
+	return bidStatusTransitions[status]
+}
+
+// CheckBidStatusTransition reports whether a bid can move from one status to
+// another, naming the allowed transitions when it can't
+func CheckBidStatusTransition(from, to models.BidStatus) error {
+	allowed := AllowedBidStatusTransitions(from)
+	for _, next := range allowed {
+		if next == to {
+			return nil
+		}
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("a %s bid cannot change status", from)
+	}
+	names := make([]string, len(allowed))
+	for i, next := range allowed {
+		names[i] = string(next)
+	}
+	return fmt.Errorf("a %s bid cannot be moved to %s; allowed: %s", from, to, strings.Join(names, ", "))
+}
+
+// ValidateBidStatusNote trims a status note, treating blank as no note
+func ValidateBidStatusNote(note *string) (*string, error) {
+	if note == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*note)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if len(trimmed) > MaxBidStatusNoteLength {
+		return nil, fmt.Errorf("status_note must be at most %d characters", MaxBidStatusNoteLength)
+	}
+	return &trimmed, nil
+}
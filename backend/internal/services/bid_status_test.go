@@ -0,0 +1,65 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestCheckBidStatusTransition(t *testing.T) {
+	allowed := [][2]models.BidStatus{
+		{models.BidStatusDraft, models.BidStatusSent},
+		{models.BidStatusSent, models.BidStatusAccepted},
+		{models.BidStatusSent, models.BidStatusRejected},
+	}
+	for _, tt := range allowed {
+		if err := CheckBidStatusTransition(tt[0], tt[1]); err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", tt[0], tt[1], err)
+		}
+	}
+
+	refused := [][2]models.BidStatus{
+		{models.BidStatusDraft, models.BidStatusDraft},
+		{models.BidStatusDraft, models.BidStatusAccepted},
+		{models.BidStatusSent, models.BidStatusDraft},
+		{models.BidStatusAccepted, models.BidStatusRejected},
+		{models.BidStatusRejected, models.BidStatusSent},
+	}
+	for _, tt := range refused {
+		if err := CheckBidStatusTransition(tt[0], tt[1]); err == nil {
+			t.Errorf("%s -> %s: expected an error", tt[0], tt[1])
+		}
+	}
+
+	err := CheckBidStatusTransition(models.BidStatusSent, models.BidStatusDraft)
+	if err == nil || !strings.Contains(err.Error(), "allowed: accepted, rejected") {
+		t.Errorf("error = %v, want the allowed transitions named", err)
+	}
+}
+
+func TestParseBidStatus(t *testing.T) {
+	if status, err := ParseBidStatus(" sent "); err != nil || status != models.BidStatusSent {
+		t.Errorf("ParseBidStatus(sent) = %q, %v", status, err)
+	}
+	for _, value := range []string{"", "won", "SENT"} {
+		if _, err := ParseBidStatus(value); err == nil {
+			t.Errorf("ParseBidStatus(%q): expected an error", value)
+		}
+	}
+}
+
+func TestValidateBidStatusNote(t *testing.T) {
+	blank := "   "
+	if note, err := ValidateBidStatusNote(&blank); note != nil || err != nil {
+		t.Errorf("blank note = %v, %v; want no note", note, err)
+	}
+	long := strings.Repeat("x", MaxBidStatusNoteLength+1)
+	if _, err := ValidateBidStatusNote(&long); err == nil {
+		t.Error("expected an error for an overlong note")
+	}
+	note := " Emailed to the client "
+	if got, err := ValidateBidStatusNote(&note); err != nil || *got != "Emailed to the client" {
+		t.Errorf("note = %v, %v", got, err)
+	}
+}
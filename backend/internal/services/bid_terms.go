@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// termStopwords are ignored when comparing the topic of two terms. Negations
+// and scope words are included so "No permits" and "Permits included" share
+// the topic "permit".
+var termStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+	"of": true, "on": true, "or": true, "the": true, "to": true, "with": true,
+	"all": true, "any": true, "no": true, "not": true, "none": true,
+	"include": true, "included": true, "includes": true, "including": true,
+	"exclude": true, "excluded": true, "excludes": true, "excluding": true,
+	"owner": true, "others": true, "provided": true, "work": true,
+}
+
+// NormalizeTerm folds a bid inclusion or exclusion to a comparison key so
+// near-identical wording ("Permits & fees." vs "permits and fees") matches
+func NormalizeTerm(term string) string {
+	return strings.Join(termWords(term), " ")
+}
+
+func termWords(term string) []string {
+	term = strings.ReplaceAll(strings.ToLower(term), "&", " and ")
+	words := strings.FieldsFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = singularize(word)
+	}
+	return words
+}
+
+// singularize trims a simple plural "s" so "permits" and "permit" match
+func singularize(word string) string {
+	if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// termTopic returns the significant keywords of a term
+func termTopic(term string) map[string]bool {
+	topic := make(map[string]bool)
+	for _, word := range termWords(term) {
+		if !termStopwords[word] {
+			topic[word] = true
+		}
+	}
+	return topic
+}
+
+// termsConflict reports whether two terms are about the same topic: at least
+// half the keywords of the more specific term appear in the other
+func termsConflict(a, b string) bool {
+	topicA, topicB := termTopic(a), termTopic(b)
+	smaller := len(topicA)
+	if len(topicB) < smaller {
+		smaller = len(topicB)
+	}
+	if smaller == 0 {
+		return false
+	}
+
+	overlap := 0
+	for word := range topicA {
+		if topicB[word] {
+			overlap++
+		}
+	}
+	return overlap > 0 && overlap*2 >= smaller
+}
+
+// mergeTerms returns company terms first, then AI terms that are not
+// near-identical to one already present
+func mergeTerms(company, ai []string) []string {
+	seen := make(map[string]bool, len(company)+len(ai))
+	merged := make([]string, 0, len(company)+len(ai))
+	for _, list := range [][]string{company, ai} {
+		for _, term := range list {
+			key := NormalizeTerm(term)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, strings.TrimSpace(term))
+		}
+	}
+	return merged
+}
+
+// MergeCompanyTerms folds a company's standing inclusions and exclusions into
+// an AI-generated bid. Company items are listed first and AI items that
+// duplicate them are dropped. An AI inclusion on the same topic as a company
+// exclusion is kept for review but reported in the bid's Warnings.
+func MergeCompanyTerms(bid *models.GenerateBidResponse, inclusions, exclusions []string) {
+	if len(inclusions) == 0 && len(exclusions) == 0 {
+		return
+	}
+
+	for _, inclusion := range bid.Inclusions {
+		for _, exclusion := range exclusions {
+			if termsConflict(inclusion, exclusion) {
+				bid.Warnings = append(bid.Warnings,
+					fmt.Sprintf("Inclusion %q conflicts with company exclusion %q", inclusion, exclusion))
+			}
+		}
+	}
+
+	bid.Inclusions = mergeTerms(inclusions, bid.Inclusions)
+	bid.Exclusions = mergeTerms(exclusions, bid.Exclusions)
+}
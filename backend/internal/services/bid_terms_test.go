@@ -0,0 +1,69 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestNormalizeTerm(t *testing.T) {
+	for _, term := range []string{"Permits & fees.", "permits and fees", "  PERMIT and FEE "} {
+		if got := NormalizeTerm(term); got != "permit and fee" {
+			t.Errorf("NormalizeTerm(%q) = %q, want %q", term, got, "permit and fee")
+		}
+	}
+}
+
+func TestMergeCompanyTerms_CompanyFirstAndDeduped(t *testing.T) {
+	bid := &models.GenerateBidResponse{
+		Inclusions: []string{"Final cleanup", "Debris removal."},
+		Exclusions: []string{"Permits & fees", "Landscaping"},
+	}
+
+	MergeCompanyTerms(bid,
+		[]string{"Debris removal", "One-year workmanship warranty"},
+		[]string{"No hazardous material abatement", "Permits and fees"})
+
+	wantInclusions := []string{"Debris removal", "One-year workmanship warranty", "Final cleanup"}
+	if !reflect.DeepEqual(bid.Inclusions, wantInclusions) {
+		t.Errorf("Inclusions = %v, want %v", bid.Inclusions, wantInclusions)
+	}
+	wantExclusions := []string{"No hazardous material abatement", "Permits and fees", "Landscaping"}
+	if !reflect.DeepEqual(bid.Exclusions, wantExclusions) {
+		t.Errorf("Exclusions = %v, want %v", bid.Exclusions, wantExclusions)
+	}
+	if len(bid.Warnings) != 0 {
+		t.Errorf("Expected no conflicts, got %v", bid.Warnings)
+	}
+}
+
+func TestMergeCompanyTerms_DetectsConflict(t *testing.T) {
+	bid := &models.GenerateBidResponse{
+		Inclusions: []string{"All permits and fees", "Interior painting"},
+	}
+
+	MergeCompanyTerms(bid, nil, []string{"Permits by owner"})
+
+	if len(bid.Warnings) != 1 || !strings.Contains(bid.Warnings[0], "All permits and fees") {
+		t.Fatalf("Expected one permit conflict warning, got %v", bid.Warnings)
+	}
+	// The conflicting AI inclusion is kept for the estimator to resolve
+	if len(bid.Inclusions) != 2 {
+		t.Errorf("Expected AI inclusions to be kept, got %v", bid.Inclusions)
+	}
+	if !reflect.DeepEqual(bid.Exclusions, []string{"Permits by owner"}) {
+		t.Errorf("Expected company exclusion to be added, got %v", bid.Exclusions)
+	}
+}
+
+func TestMergeCompanyTerms_NoDefaults(t *testing.T) {
+	bid := &models.GenerateBidResponse{Inclusions: []string{"Framing", "framing"}}
+
+	MergeCompanyTerms(bid, nil, nil)
+
+	if len(bid.Inclusions) != 2 || bid.Warnings != nil {
+		t.Errorf("Expected bid to be untouched without company defaults, got %+v", bid)
+	}
+}
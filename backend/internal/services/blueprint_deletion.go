@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// DeletionBidStore finds the bids that depend on a blueprint
+type DeletionBidStore interface {
+	GetIDsByBlueprint(ctx context.Context, blueprintID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// DeletionBlueprintStore deletes a blueprint and the rows that belong to it
+type DeletionBlueprintStore interface {
+	Delete(ctx context.Context, id uuid.UUID) (*models.BlueprintDeletion, error)
+}
+
+// BlueprintInUseError reports that bids were priced from a blueprint, so
+// deleting it would leave them without their source
+type BlueprintInUseError struct {
+	BidIDs []uuid.UUID
+}
+
+func (e *BlueprintInUseError) Error() string {
+	return fmt.Sprintf("blueprint is referenced by %d bid(s)", len(e.BidIDs))
+}
+
+// BlueprintDeleter removes a mistakenly uploaded blueprint: its row,
+// revisions, assets and unfinished jobs, then the objects they stored.
+// Blueprints that bids were priced from are kept.
+type BlueprintDeleter struct {
+	bids       DeletionBidStore
+	blueprints DeletionBlueprintStore
+	objects    ObjectDeleter
+}
+
+func NewBlueprintDeleter(bids DeletionBidStore, blueprints DeletionBlueprintStore, objects ObjectDeleter) *BlueprintDeleter {
+	return &BlueprintDeleter{bids: bids, blueprints: blueprints, objects: objects}
+}
+
+// Delete deletes a blueprint, returning a *BlueprintInUseError when bids
+// reference it. Objects are deleted after the rows and on a best-effort
+// basis: a failure is logged and leaves the object behind rather than
+// failing a deletion that already happened.
+func (d *BlueprintDeleter) Delete(ctx context.Context, blueprint *models.Blueprint) (*models.BlueprintDeletion, error) {
+	bidIDs, err := d.bids.GetIDsByBlueprint(ctx, blueprint.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(bidIDs) > 0 {
+		return nil, &BlueprintInUseError{BidIDs: bidIDs}
+	}
+
+	deletion, err := d.blueprints.Delete(ctx, blueprint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range deletion.S3Keys {
+		if err := d.objects.DeleteFile(ctx, key); err != nil {
+			slog.Warn("Failed to delete object of deleted blueprint",
+				"blueprint_id", blueprint.ID,
+				"project_id", blueprint.ProjectID,
+				"s3_key", key,
+				"error", err)
+		}
+	}
+	return deletion, nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Bounds on the page tree walk, so a malformed or cyclic tree cannot
+// recurse forever
+const (
+	maxPDFPageTreeDepth = 32
+	maxPDFPageTreeNodes = 100000
+)
+
+// BlueprintMetadata is the page structure read from an uploaded blueprint
+// file before it is analyzed
+type BlueprintMetadata struct {
+	PageCount int
+	PageSizes []models.BlueprintPageSize
+	Title     *string
+	CreatedAt *time.Time
+}
+
+// ExtractBlueprintMetadata reads the page count and page sizes of a blueprint
+// file: PDF pages in points with the embedded title and creation date, or an
+// image as a single page in pixels. Files of other types have no metadata and
+// return nil; a file that cannot be parsed returns an error.
+func ExtractBlueprintMetadata(data []byte, mimeType string) (*BlueprintMetadata, error) {
+	switch {
+	case mimeType == "application/pdf":
+		return extractPDFMetadata(data)
+	case strings.HasPrefix(mimeType, "image/"):
+		return extractImageMetadata(data)
+	default:
+		return nil, nil
+	}
+}
+
+func extractImageMetadata(data []byte) (*BlueprintMetadata, error) {
+	metadata := &BlueprintMetadata{PageCount: 1}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		// Still a single page, just without its dimensions
+		return metadata, fmt.Errorf("unreadable image: %w", err)
+	}
+	metadata.PageSizes = []models.BlueprintPageSize{{
+		Page:   1,
+		Width:  float64(cfg.Width),
+		Height: float64(cfg.Height),
+		Unit:   models.PageSizeUnitPixels,
+	}}
+	return metadata, nil
+}
+
+func extractPDFMetadata(data []byte) (metadata *BlueprintMetadata, err error) {
+	// The reader panics on some malformed objects rather than returning errors
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			metadata, err = nil, fmt.Errorf("malformed PDF: %v", recovered)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	root := reader.Trailer().Key("Root").Key("Pages")
+	if root.Key("Type").Name() != "Pages" {
+		return nil, fmt.Errorf("malformed PDF: no page tree")
+	}
+	walk := &pdfPageWalk{metadata: &BlueprintMetadata{}}
+	walk.collect(root, pdfPageAttributes{}, 0)
+	if walk.nodes > maxPDFPageTreeNodes {
+		return nil, fmt.Errorf("malformed PDF: page tree too large")
+	}
+	metadata = walk.metadata
+	if metadata.PageCount == 0 {
+		return nil, fmt.Errorf("malformed PDF: no pages")
+	}
+
+	info := reader.Trailer().Key("Info")
+	if title := strings.TrimSpace(info.Key("Title").Text()); title != "" {
+		metadata.Title = &title
+	}
+	if created, ok := parsePDFDate(info.Key("CreationDate").Text()); ok {
+		metadata.CreatedAt = &created
+	}
+	return metadata, nil
+}
+
+// pdfPageAttributes are the page attributes a page inherits from the page
+// tree nodes above it
+type pdfPageAttributes struct {
+	mediaBox pdf.Value
+	rotate   int64
+}
+
+// pdfPageWalk collects page sizes from a PDF page tree
+type pdfPageWalk struct {
+	metadata *BlueprintMetadata
+	nodes    int
+}
+
+// collect walks a page tree node in document order, recording each page's
+// size with its inherited media box and rotation applied
+func (w *pdfPageWalk) collect(node pdf.Value, inherited pdfPageAttributes, depth int) {
+	w.nodes++
+	if depth > maxPDFPageTreeDepth || w.nodes > maxPDFPageTreeNodes {
+		return
+	}
+	metadata := w.metadata
+	if box := node.Key("MediaBox"); box.Len() == 4 {
+		inherited.mediaBox = box
+	}
+	if rotate := node.Key("Rotate"); !rotate.IsNull() {
+		inherited.rotate = rotate.Int64()
+	}
+
+	switch node.Key("Type").Name() {
+	case "Pages":
+		kids := node.Key("Kids")
+		for i := 0; i < kids.Len(); i++ {
+			w.collect(kids.Index(i), inherited, depth+1)
+		}
+	case "Page":
+		metadata.PageCount++
+		if inherited.mediaBox.Len() != 4 {
+			return
+		}
+		box := inherited.mediaBox
+		width := math.Abs(box.Index(2).Float64() - box.Index(0).Float64())
+		height := math.Abs(box.Index(3).Float64() - box.Index(1).Float64())
+		if inherited.rotate%180 != 0 {
+			width, height = height, width
+		}
+		metadata.PageSizes = append(metadata.PageSizes, models.BlueprintPageSize{
+			Page:   metadata.PageCount,
+			Width:  math.Round(width*100) / 100,
+			Height: math.Round(height*100) / 100,
+			Unit:   models.PageSizeUnitPoints,
+		})
+	}
+}
+
+// parsePDFDate parses a PDF date string such as D:20240301143000-05'00'.
+// Everything after the year is optional; a missing offset is read as UTC.
+func parsePDFDate(value string) (time.Time, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "D:")
+	value = strings.ReplaceAll(value, "'", "")
+
+	digits := value
+	zone := ""
+	if i := strings.IndexAny(value, "Z+-"); i >= 0 {
+		digits, zone = value[:i], value[i:]
+	}
+	const layout = "20060102150405"
+	if len(digits) < 4 || len(digits) > len(layout) || len(digits)%2 != 0 {
+		return time.Time{}, false
+	}
+	// Pad the missing fields with the earliest valid value
+	digits += "0101000000"[len(digits)-4:]
+
+	location := time.UTC
+	if zone != "" && zone[0] != 'Z' {
+		offset, err := time.Parse("-0700", zone+strings.Repeat("0", max(0, 5-len(zone))))
+		if err != nil {
+			return time.Time{}, false
+		}
+		location = offset.Location()
+	}
+	parsed, err := time.ParseInLocation(layout, digits, location)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed.UTC(), true
+}
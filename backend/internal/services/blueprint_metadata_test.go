@@ -0,0 +1,156 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// fixturePDF builds a small PDF with the given objects, numbered from 1, and
+// a correct cross-reference table. Object 1 must be the catalog.
+func fixturePDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestExtractBlueprintMetadata_PDF(t *testing.T) {
+	t.Run("generated drawing set", func(t *testing.T) {
+		created := time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)
+		doc := gofpdf.New("P", "pt", "Letter", "")
+		doc.SetTitle("Smith Residence - Permit Set", true)
+		doc.SetCreationDate(created)
+		doc.AddPage()
+		doc.AddPageFormat("L", gofpdf.SizeType{Wd: 1728, Ht: 2592}) // 24x36 in
+		doc.AddPage()
+		var buf bytes.Buffer
+		if err := doc.Output(&buf); err != nil {
+			t.Fatalf("failed to build fixture: %v", err)
+		}
+
+		metadata, err := ExtractBlueprintMetadata(buf.Bytes(), "application/pdf")
+		if err != nil {
+			t.Fatalf("ExtractBlueprintMetadata() error = %v", err)
+		}
+		if metadata.PageCount != 3 {
+			t.Errorf("PageCount = %d, want 3", metadata.PageCount)
+		}
+		want := []models.BlueprintPageSize{
+			{Page: 1, Width: 612, Height: 792, Unit: models.PageSizeUnitPoints},
+			{Page: 2, Width: 2592, Height: 1728, Unit: models.PageSizeUnitPoints},
+			{Page: 3, Width: 612, Height: 792, Unit: models.PageSizeUnitPoints},
+		}
+		if fmt.Sprint(metadata.PageSizes) != fmt.Sprint(want) {
+			t.Errorf("PageSizes = %v, want %v", metadata.PageSizes, want)
+		}
+		if metadata.Title == nil || *metadata.Title != "Smith Residence - Permit Set" {
+			t.Errorf("Title = %v, want the document title", metadata.Title)
+		}
+		if metadata.CreatedAt == nil || !metadata.CreatedAt.Equal(created) {
+			t.Errorf("CreatedAt = %v, want %v", metadata.CreatedAt, created)
+		}
+	})
+
+	t.Run("inherited media box and rotation", func(t *testing.T) {
+		data := fixturePDF(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 3 /MediaBox [0 0 612 792] >>",
+			"<< /Type /Page /Parent 2 0 R >>",
+			"<< /Type /Pages /Parent 2 0 R /Kids [5 0 R] /Count 2 /Rotate 90 >>",
+			"<< /Type /Page /Parent 4 0 R /MediaBox [0 0 1224 792] >>",
+		)
+		metadata, err := ExtractBlueprintMetadata(data, "application/pdf")
+		if err != nil {
+			t.Fatalf("ExtractBlueprintMetadata() error = %v", err)
+		}
+		if metadata.PageCount != 2 {
+			t.Errorf("PageCount = %d, want the 2 pages in the tree", metadata.PageCount)
+		}
+		want := []models.BlueprintPageSize{
+			{Page: 1, Width: 612, Height: 792, Unit: models.PageSizeUnitPoints},
+			{Page: 2, Width: 792, Height: 1224, Unit: models.PageSizeUnitPoints},
+		}
+		if fmt.Sprint(metadata.PageSizes) != fmt.Sprint(want) {
+			t.Errorf("PageSizes = %v, want %v", metadata.PageSizes, want)
+		}
+		if metadata.Title != nil || metadata.CreatedAt != nil {
+			t.Errorf("expected no document metadata without an Info dictionary, got %v %v", metadata.Title, metadata.CreatedAt)
+		}
+	})
+
+	corrupt := map[string][]byte{
+		"garbage after the header": []byte("%PDF-1.4\n" + strings.Repeat("not a pdf ", 20)),
+		"truncated":                fixturePDF("<< /Type /Catalog /Pages 2 0 R >>")[:40],
+		"no pages":                 fixturePDF("<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [] /Count 0 >>"),
+	}
+	for name, data := range corrupt {
+		t.Run(name, func(t *testing.T) {
+			if metadata, err := ExtractBlueprintMetadata(data, "application/pdf"); err == nil {
+				t.Errorf("expected an error, got %+v", metadata)
+			}
+		})
+	}
+}
+
+func TestExtractBlueprintMetadata_Image(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 640, 480))); err != nil {
+		t.Fatalf("failed to build fixture: %v", err)
+	}
+
+	metadata, err := ExtractBlueprintMetadata(buf.Bytes(), "image/png")
+	if err != nil {
+		t.Fatalf("ExtractBlueprintMetadata() error = %v", err)
+	}
+	want := []models.BlueprintPageSize{{Page: 1, Width: 640, Height: 480, Unit: models.PageSizeUnitPixels}}
+	if metadata.PageCount != 1 || fmt.Sprint(metadata.PageSizes) != fmt.Sprint(want) {
+		t.Errorf("metadata = %+v, want one 640x480 page", metadata)
+	}
+
+	metadata, err = ExtractBlueprintMetadata([]byte("not an image"), "image/png")
+	if err == nil || metadata == nil || metadata.PageCount != 1 || metadata.PageSizes != nil {
+		t.Errorf("unreadable image: metadata = %+v, error = %v; want one page without a size", metadata, err)
+	}
+
+	if metadata, err := ExtractBlueprintMetadata([]byte("AC1015"), "application/acad"); metadata != nil || err != nil {
+		t.Errorf("CAD file: metadata = %+v, error = %v; want none", metadata, err)
+	}
+}
+
+func TestParsePDFDate(t *testing.T) {
+	tests := map[string]time.Time{
+		"D:20240301143000Z":       time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC),
+		"D:20240301143000-05'00'": time.Date(2024, 3, 1, 19, 30, 0, 0, time.UTC),
+		"D:20240301143000+05'30":  time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		"D:202403":                time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"20240301":                time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for value, want := range tests {
+		if got, ok := parsePDFDate(value); !ok || !got.Equal(want) {
+			t.Errorf("parsePDFDate(%q) = %v, %v; want %v", value, got, ok, want)
+		}
+	}
+	for _, value := range []string{"", "D:", "D:-05", "D:2024030", "yesterday"} {
+		if got, ok := parsePDFDate(value); ok {
+			t.Errorf("parsePDFDate(%q) = %v, want no date", value, got)
+		}
+	}
+}
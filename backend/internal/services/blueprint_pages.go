@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder for DecodeConfig
+	_ "image/jpeg" // register JPEG decoder for DecodeConfig
+	_ "image/png"  // register PNG decoder for DecodeConfig
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxBlueprintPages caps how many drawing pages can be embedded in a bid PDF
+const MaxBlueprintPages = 5
+
+// BlueprintFileSource fetches stored blueprint files
+type BlueprintFileSource interface {
+	DownloadFile(ctx context.Context, key string) ([]byte, error)
+}
+
+// PDFPageRasterizer renders a single page of a stored PDF blueprint to PNG
+type PDFPageRasterizer interface {
+	RenderBlueprintPage(ctx context.Context, s3Key string, page int) ([]byte, error)
+}
+
+// BlueprintPageImage is one rendered drawing page ready to embed in a PDF
+type BlueprintPageImage struct {
+	Page      int
+	Data      []byte
+	ImageType string // gofpdf image type: PNG, JPEG or GIF
+	Width     int
+	Height    int
+}
+
+// BlueprintAttachment holds the drawing pages referenced by a bid, plus a note
+// for each requested page that could not be rendered
+type BlueprintAttachment struct {
+	Filename string
+	Version  int
+	Pages    []BlueprintPageImage
+	Skipped  []string
+}
+
+// BlueprintPageRenderer produces page images for a blueprint. Either
+// dependency may be nil, in which case pages that need it are skipped.
+type BlueprintPageRenderer struct {
+	files      BlueprintFileSource
+	rasterizer PDFPageRasterizer
+}
+
+func NewBlueprintPageRenderer(files BlueprintFileSource, rasterizer PDFPageRasterizer) *BlueprintPageRenderer {
+	return &BlueprintPageRenderer{
+		files:      files,
+		rasterizer: rasterizer,
+	}
+}
+
+// ValidateBlueprintPages checks requested page numbers are positive, unique
+// and within the embedding cap
+func ValidateBlueprintPages(pages []int) error {
+	if len(pages) > MaxBlueprintPages {
+		return fmt.Errorf("at most %d blueprint pages can be included", MaxBlueprintPages)
+	}
+	seen := make(map[int]bool, len(pages))
+	for _, page := range pages {
+		if page < 1 {
+			return fmt.Errorf("blueprint page numbers must be 1 or greater")
+		}
+		if seen[page] {
+			return fmt.Errorf("blueprint page %d is listed more than once", page)
+		}
+		seen[page] = true
+	}
+	return nil
+}
+
+// CheckBlueprintPagesExist rejects page numbers beyond a blueprint's page
+// count. Blueprints whose page count is unknown accept any page; pages that
+// turn out to be missing are skipped when rendered.
+func CheckBlueprintPagesExist(blueprint *models.Blueprint, pages []int) error {
+	if blueprint.PageCount == nil {
+		return nil
+	}
+	for _, page := range pages {
+		if page > *blueprint.PageCount {
+			return fmt.Errorf("blueprint %s has no page %d (page count %d)", blueprint.Filename, page, *blueprint.PageCount)
+		}
+	}
+	return nil
+}
+
+// RenderPages renders the requested pages of a blueprint. Image blueprints
+// are a single page and use the stored file directly; PDF pages go through
+// the rasterizer. Failures never abort the bid: each unrenderable page is
+// recorded in Skipped instead.
+func (r *BlueprintPageRenderer) RenderPages(ctx context.Context, blueprint *models.Blueprint, pages []int) *BlueprintAttachment {
+	attachment := &BlueprintAttachment{
+		Filename: blueprint.Filename,
+		Version:  blueprint.Version,
+	}
+
+	if len(pages) > MaxBlueprintPages {
+		pages = pages[:MaxBlueprintPages]
+	}
+
+	isImage := blueprint.MimeType != nil && strings.HasPrefix(*blueprint.MimeType, "image/")
+
+	var imageFile []byte
+	var imageErr error
+	if isImage {
+		if r.files == nil {
+			imageErr = fmt.Errorf("file storage unavailable")
+		} else {
+			imageFile, imageErr = r.files.DownloadFile(ctx, blueprint.S3Key)
+		}
+	}
+
+	for _, page := range pages {
+		var data []byte
+		var err error
+
+		switch {
+		case isImage && page != 1:
+			err = fmt.Errorf("image blueprints have a single page")
+		case isImage:
+			data, err = imageFile, imageErr
+		case r.rasterizer == nil:
+			err = fmt.Errorf("page rendering unavailable")
+		default:
+			data, err = r.rasterizer.RenderBlueprintPage(ctx, blueprint.S3Key, page)
+		}
+
+		if err == nil {
+			var img BlueprintPageImage
+			img, err = decodePageImage(page, data)
+			if err == nil {
+				attachment.Pages = append(attachment.Pages, img)
+				continue
+			}
+		}
+
+		attachment.Skipped = append(attachment.Skipped, fmt.Sprintf("Page %d could not be included: %v", page, err))
+	}
+
+	return attachment
+}
+
+// decodePageImage reads the image header so unsupported or corrupt data is
+// rejected before it reaches gofpdf, which would otherwise fail the whole PDF
+func decodePageImage(page int, data []byte) (BlueprintPageImage, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return BlueprintPageImage{}, fmt.Errorf("unreadable image: %w", err)
+	}
+	if cfg.Width == 0 || cfg.Height == 0 {
+		return BlueprintPageImage{}, fmt.Errorf("empty image")
+	}
+
+	return BlueprintPageImage{
+		Page:      page,
+		Data:      data,
+		ImageType: strings.ToUpper(format),
+		Width:     cfg.Width,
+		Height:    cfg.Height,
+	}, nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type fakeFileSource struct {
+	files map[string][]byte
+}
+
+func (f *fakeFileSource) DownloadFile(ctx context.Context, key string) ([]byte, error) {
+	data, ok := f.files[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+type fakeRasterizer struct {
+	pages map[int][]byte
+}
+
+func (f *fakeRasterizer) RenderBlueprintPage(ctx context.Context, s3Key string, page int) ([]byte, error) {
+	data, ok := f.pages[page]
+	if !ok {
+		return nil, errors.New("render failed")
+	}
+	return data, nil
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, height/2, color.Black)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testBlueprint(mimeType string) *models.Blueprint {
+	return &models.Blueprint{
+		ID:       uuid.New(),
+		Filename: "A-101 Floor Plan.png",
+		S3Key:    "blueprints/a-101",
+		MimeType: &mimeType,
+		Version:  3,
+	}
+}
+
+func testBidForPDF() (*models.Bid, *models.GenerateBidResponse) {
+	bid := &models.Bid{
+		ID:        uuid.New(),
+		ProjectID: uuid.New(),
+		Status:    models.BidStatusDraft,
+		CreatedAt: models.Now(),
+		UpdatedAt: models.Now(),
+	}
+	response := &models.GenerateBidResponse{
+		LineItems:  []models.LineItem{{Description: "Drywall", Trade: "drywall", Quantity: 100, Unit: "SF", UnitCost: 2, Total: 200}},
+		Subtotal:   200,
+		TotalPrice: 240,
+	}
+	return bid, response
+}
+
+func TestBlueprintPageRenderer_ImageBlueprint(t *testing.T) {
+	blueprint := testBlueprint("image/png")
+	files := &fakeFileSource{files: map[string][]byte{blueprint.S3Key: testPNG(t, 400, 300)}}
+	renderer := NewBlueprintPageRenderer(files, nil)
+
+	attachment := renderer.RenderPages(context.Background(), blueprint, []int{1, 2})
+
+	if attachment.Filename != blueprint.Filename || attachment.Version != 3 {
+		t.Errorf("Unexpected attachment metadata: %+v", attachment)
+	}
+	if len(attachment.Pages) != 1 {
+		t.Fatalf("Expected 1 rendered page, got %d", len(attachment.Pages))
+	}
+	page := attachment.Pages[0]
+	if page.Page != 1 || page.ImageType != "PNG" || page.Width != 400 || page.Height != 300 {
+		t.Errorf("Unexpected page image: page=%d type=%s %dx%d", page.Page, page.ImageType, page.Width, page.Height)
+	}
+	if len(attachment.Skipped) != 1 || !strings.Contains(attachment.Skipped[0], "Page 2") {
+		t.Errorf("Expected page 2 to be skipped, got %v", attachment.Skipped)
+	}
+
+	// End to end: the appendix renders into the bid PDF
+	bid, response := testBidForPDF()
+	pdfService := NewPDFService()
+	withoutPages, err := pdfService.GenerateBidPDF(bid, response, "Test Project")
+	if err != nil {
+		t.Fatalf("GenerateBidPDF() error = %v", err)
+	}
+	withPages, err := pdfService.GenerateBidPDFWithOptions(bid, response, "Test Project", &PDFOptions{BlueprintPages: attachment})
+	if err != nil {
+		t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+	}
+	if len(withPages) <= len(withoutPages) {
+		t.Errorf("Expected PDF with drawings (%d bytes) to be larger than without (%d bytes)", len(withPages), len(withoutPages))
+	}
+}
+
+func TestBlueprintPageRenderer_SkipsFailures(t *testing.T) {
+	t.Run("Missing image file", func(t *testing.T) {
+		renderer := NewBlueprintPageRenderer(&fakeFileSource{}, nil)
+		attachment := renderer.RenderPages(context.Background(), testBlueprint("image/png"), []int{1})
+
+		if len(attachment.Pages) != 0 || len(attachment.Skipped) != 1 {
+			t.Fatalf("Expected the page to be skipped, got %+v", attachment)
+		}
+	})
+
+	t.Run("Corrupt image data", func(t *testing.T) {
+		blueprint := testBlueprint("image/png")
+		files := &fakeFileSource{files: map[string][]byte{blueprint.S3Key: []byte("not an image")}}
+		attachment := NewBlueprintPageRenderer(files, nil).RenderPages(context.Background(), blueprint, []int{1})
+
+		if len(attachment.Pages) != 0 || len(attachment.Skipped) != 1 {
+			t.Fatalf("Expected corrupt image to be skipped, got %+v", attachment)
+		}
+	})
+
+	t.Run("PDF pages that fail to render", func(t *testing.T) {
+		rasterizer := &fakeRasterizer{pages: map[int][]byte{2: testPNG(t, 200, 300)}}
+		attachment := NewBlueprintPageRenderer(nil, rasterizer).RenderPages(context.Background(), testBlueprint("application/pdf"), []int{1, 2, 3})
+
+		if len(attachment.Pages) != 1 || attachment.Pages[0].Page != 2 {
+			t.Fatalf("Expected only page 2 to render, got %+v", attachment.Pages)
+		}
+		if len(attachment.Skipped) != 2 {
+			t.Errorf("Expected 2 skipped pages, got %v", attachment.Skipped)
+		}
+	})
+
+	t.Run("No rasterizer", func(t *testing.T) {
+		attachment := NewBlueprintPageRenderer(nil, nil).RenderPages(context.Background(), testBlueprint("application/pdf"), []int{1})
+		if len(attachment.Skipped) != 1 {
+			t.Fatalf("Expected page to be skipped without a rasterizer, got %+v", attachment)
+		}
+
+		// A bid with only skipped pages still renders
+		bid, response := testBidForPDF()
+		if _, err := NewPDFService().GenerateBidPDFWithOptions(bid, response, "Test Project", &PDFOptions{BlueprintPages: attachment}); err != nil {
+			t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+		}
+	})
+}
+
+func TestValidateBlueprintPages(t *testing.T) {
+	if err := ValidateBlueprintPages([]int{1, 2, 3, 4, 5}); err != nil {
+		t.Errorf("Expected 5 pages to be accepted, got %v", err)
+	}
+	if err := ValidateBlueprintPages([]int{1, 2, 3, 4, 5, 6}); err == nil {
+		t.Error("Expected more than 5 pages to be rejected")
+	}
+	if err := ValidateBlueprintPages([]int{0}); err == nil {
+		t.Error("Expected page 0 to be rejected")
+	}
+	if err := ValidateBlueprintPages([]int{2, 2}); err == nil {
+		t.Error("Expected duplicate pages to be rejected")
+	}
+}
+
+func TestCheckBlueprintPagesExist(t *testing.T) {
+	blueprint := testBlueprint("application/pdf")
+	if err := CheckBlueprintPagesExist(blueprint, []int{1, 40}); err != nil {
+		t.Errorf("Expected pages to be accepted before the page count is known, got %v", err)
+	}
+
+	pageCount := 3
+	blueprint.PageCount = &pageCount
+	if err := CheckBlueprintPagesExist(blueprint, []int{1, 3}); err != nil {
+		t.Errorf("Expected pages within the page count to be accepted, got %v", err)
+	}
+	if err := CheckBlueprintPagesExist(blueprint, []int{2, 4}); err == nil {
+		t.Error("Expected a page beyond the page count to be rejected")
+	}
+}
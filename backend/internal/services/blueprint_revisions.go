@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// BlueprintRevisionWriter numbers and stores blueprint revisions
+type BlueprintRevisionWriter interface {
+	Create(ctx context.Context, revision *models.BlueprintRevision) error
+	GetByVersion(ctx context.Context, blueprintID uuid.UUID, version int) (*models.BlueprintRevision, error)
+	GetLatestVersion(ctx context.Context, blueprintID uuid.UUID) (int, error)
+}
+
+// AutoRevisionSettings reports whether a blueprint's owner keeps automatic
+// snapshots of overwritten analyses
+type AutoRevisionSettings interface {
+	AutoBlueprintRevisionsEnabled(ctx context.Context, blueprintID uuid.UUID) (bool, error)
+}
+
+// CreateBlueprintRevision snapshots the blueprint's current file and analysis
+// as the next revision, with a digest of the changes since the previous one,
+// and advances blueprint.Version to it. Saving the blueprint is left to the
+// caller. reason is empty for manual snapshots.
+func CreateBlueprintRevision(ctx context.Context, revisions BlueprintRevisionWriter, blueprint *models.Blueprint, createdBy *uuid.UUID, reason string) (*models.BlueprintRevision, error) {
+	latestVersion, err := revisions.GetLatestVersion(ctx, blueprint.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest version: %w", err)
+	}
+	return createBlueprintRevision(ctx, revisions, blueprint, latestVersion, createdBy, reason, "")
+}
+
+// createBlueprintRevision stores the blueprint as the revision after
+// latestVersion, noting note in its changes digest
+func createBlueprintRevision(ctx context.Context, revisions BlueprintRevisionWriter, blueprint *models.Blueprint, latestVersion int, createdBy *uuid.UUID, reason, note string) (*models.BlueprintRevision, error) {
+	revision := &models.BlueprintRevision{
+		ID:            uuid.New(),
+		BlueprintID:   blueprint.ID,
+		Version:       latestVersion + 1,
+		Filename:      blueprint.Filename,
+		S3Key:         blueprint.S3Key,
+		FileSize:      blueprint.FileSize,
+		MimeType:      blueprint.MimeType,
+		AnalysisData:  blueprint.AnalysisData,
+		AnalysisModel: blueprint.AnalysisModel,
+		CreatedBy:     createdBy,
+		CreatedAt:     models.Now(),
+	}
+	if reason != "" {
+		revision.Reason = &reason
+	}
+
+	// Compare with previous version if exists
+	var digest *models.ComparisonDigest
+	if latestVersion > 0 {
+		prevRevision, err := revisions.GetByVersion(ctx, blueprint.ID, latestVersion)
+		if err == nil {
+			comparison, err := NewComparisonService().CompareBlueprintRevisions(prevRevision, revision)
+			if err == nil {
+				// Store the compact digest; the full change list can be recomputed
+				digest = comparison.Digest()
+			}
+		}
+	}
+	if digest == nil && note != "" {
+		digest = &models.ComparisonDigest{ToVersion: revision.Version}
+	}
+	if digest != nil {
+		digest.Note = note
+		summaryJSON, _ := json.Marshal(digest)
+		summaryStr := string(summaryJSON)
+		revision.ChangesSummary = &summaryStr
+	}
+
+	if err := revisions.Create(ctx, revision); err != nil {
+		return nil, err
+	}
+
+	blueprint.Version = revision.Version
+	return revision, nil
+}
+
+// ErrRestoreLatestRevision is returned when restoring a blueprint to the
+// latest revision while it still matches it
+var ErrRestoreLatestRevision = errors.New("revision is the latest version")
+
+// RestoreBlueprintRevision rolls the blueprint back to target's file and
+// analysis and records the rollback as the next revision, noted "Restored
+// from version N". A blueprint changed since its latest revision is
+// snapshotted first as a "pre_restore" revision; one unchanged since cannot
+// be restored to that revision and returns ErrRestoreLatestRevision. Saving
+// the blueprint is left to the caller, in the same transaction as the
+// revisions.
+func RestoreBlueprintRevision(ctx context.Context, revisions BlueprintRevisionWriter, blueprint *models.Blueprint, target *models.BlueprintRevision, createdBy *uuid.UUID) (*models.BlueprintRevision, error) {
+	latestVersion, err := revisions.GetLatestVersion(ctx, blueprint.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest version: %w", err)
+	}
+	latest, err := revisions.GetByVersion(ctx, blueprint.ID, latestVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest revision: %w", err)
+	}
+	current := matchesRevision(blueprint, latest)
+	if current && target.Version == latestVersion {
+		return nil, ErrRestoreLatestRevision
+	}
+
+	// Snapshot a file or analysis no revision holds yet, so the restore
+	// doesn't lose it
+	if !current {
+		if _, err := createBlueprintRevision(ctx, revisions, blueprint, latestVersion, createdBy, models.BlueprintRevisionReasonPreRestore, ""); err != nil {
+			return nil, fmt.Errorf("failed to snapshot current blueprint: %w", err)
+		}
+		latestVersion++
+	}
+
+	// Page metadata was read from the current file, not the restored one
+	if target.S3Key != blueprint.S3Key {
+		blueprint.PageCount, blueprint.PageSizes = nil, nil
+		blueprint.DocumentTitle, blueprint.DocumentCreatedAt = nil, nil
+	}
+	blueprint.Filename = target.Filename
+	blueprint.S3Key = target.S3Key
+	blueprint.FileSize = target.FileSize
+	blueprint.MimeType = target.MimeType
+	blueprint.AnalysisData = target.AnalysisData
+	blueprint.AnalysisModel = target.AnalysisModel
+	blueprint.AnalysisStatus = models.AnalysisStatusNotStarted
+	if target.AnalysisData != nil && *target.AnalysisData != "" {
+		blueprint.AnalysisStatus = models.AnalysisStatusCompleted
+	}
+
+	note := fmt.Sprintf("Restored from version %d", target.Version)
+	return createBlueprintRevision(ctx, revisions, blueprint, latestVersion, createdBy, models.BlueprintRevisionReasonRestore, note)
+}
+
+// matchesRevision reports whether the blueprint's file and analysis are the
+// revision's
+func matchesRevision(blueprint *models.Blueprint, revision *models.BlueprintRevision) bool {
+	analysis := func(data *string) string {
+		if data == nil {
+			return ""
+		}
+		return *data
+	}
+	return blueprint.S3Key == revision.S3Key && blueprint.Filename == revision.Filename &&
+		analysis(blueprint.AnalysisData) == analysis(revision.AnalysisData)
+}
+
+// BidsPricedAfterRevision returns the IDs of bids priced from a version of
+// the blueprint newer than version. Bids that did not record which version
+// they were priced from are included when they were priced from the
+// blueprint at all.
+func BidsPricedAfterRevision(bids []*models.Bid, blueprintID uuid.UUID, version int) []uuid.UUID {
+	var affected []uuid.UUID
+	for _, bid := range bids {
+		if bidPricedAfterRevision(bid, blueprintID, version) {
+			affected = append(affected, bid.ID)
+		}
+	}
+	return affected
+}
+
+func bidPricedAfterRevision(bid *models.Bid, blueprintID uuid.UUID, version int) bool {
+	if bid.BidData != nil {
+		var response models.GenerateBidResponse
+		if err := json.Unmarshal([]byte(*bid.BidData), &response); err == nil && len(response.SourceBlueprints) > 0 {
+			for _, source := range response.SourceBlueprints {
+				if source.BlueprintID == blueprintID {
+					return source.Version > version
+				}
+			}
+			return false
+		}
+	}
+	return slices.Contains(bid.BlueprintIDs, blueprintID)
+}
+
+// AutoRevisioner snapshots a blueprint's analysis before a new one replaces
+// it, so every overwrite leaves a revision to compare against
+type AutoRevisioner struct {
+	revisions BlueprintRevisionWriter
+	settings  AutoRevisionSettings
+}
+
+func NewAutoRevisioner(revisions BlueprintRevisionWriter, settings AutoRevisionSettings) *AutoRevisioner {
+	return &AutoRevisioner{revisions: revisions, settings: settings}
+}
+
+// SnapshotBeforeOverwrite creates a "pre_analysis_overwrite" revision of the
+// blueprint's current analysis before newData is written over it. Nothing is
+// created when the blueprint has no analysis yet, when newData is identical
+// to it, or when the owner has turned automatic revisions off; the revision
+// is nil then.
+func (a *AutoRevisioner) SnapshotBeforeOverwrite(ctx context.Context, blueprint *models.Blueprint, newData string) (*models.BlueprintRevision, error) {
+	if blueprint.AnalysisData == nil || *blueprint.AnalysisData == "" || *blueprint.AnalysisData == newData {
+		return nil, nil
+	}
+
+	if a.settings != nil {
+		enabled, err := a.settings.AutoBlueprintRevisionsEnabled(ctx, blueprint.ID)
+		if err != nil {
+			// Snapshot anyway: a spare revision is cheaper than a lost analysis
+			slog.Warn("Failed to get auto revision setting", "blueprint_id", blueprint.ID, "error", err)
+		} else if !enabled {
+			return nil, nil
+		}
+	}
+
+	revision, err := CreateBlueprintRevision(ctx, a.revisions, blueprint, nil, models.BlueprintRevisionReasonPreAnalysisOverwrite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot analysis: %w", err)
+	}
+	return revision, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type fakeBlueprintRevisions struct {
+	revisions []*models.BlueprintRevision
+}
+
+func (f *fakeBlueprintRevisions) Create(ctx context.Context, revision *models.BlueprintRevision) error {
+	f.revisions = append(f.revisions, revision)
+	return nil
+}
+
+func (f *fakeBlueprintRevisions) GetByVersion(ctx context.Context, blueprintID uuid.UUID, version int) (*models.BlueprintRevision, error) {
+	for _, revision := range f.revisions {
+		if revision.BlueprintID == blueprintID && revision.Version == version {
+			return revision, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeBlueprintRevisions) GetLatestVersion(ctx context.Context, blueprintID uuid.UUID) (int, error) {
+	latest := 0
+	for _, revision := range f.revisions {
+		if revision.BlueprintID == blueprintID && revision.Version > latest {
+			latest = revision.Version
+		}
+	}
+	return latest, nil
+}
+
+type fakeAutoRevisionSettings bool
+
+func (f fakeAutoRevisionSettings) AutoBlueprintRevisionsEnabled(ctx context.Context, blueprintID uuid.UUID) (bool, error) {
+	return bool(f), nil
+}
+
+// storeAnalysis runs an analysis result through the worker's store step
+func storeAnalysis(t *testing.T, revisioner *AutoRevisioner, blueprint *models.Blueprint, data string) {
+	t.Helper()
+	if _, err := revisioner.SnapshotBeforeOverwrite(context.Background(), blueprint, data); err != nil {
+		t.Fatalf("SnapshotBeforeOverwrite() error = %v", err)
+	}
+	blueprint.AnalysisData = &data
+}
+
+func TestAutoRevisioner_SnapshotsPreviousAnalysis(t *testing.T) {
+	revisions := &fakeBlueprintRevisions{}
+	revisioner := NewAutoRevisioner(revisions, fakeAutoRevisionSettings(true))
+	model := &models.AIModelInfo{Name: "first-model"}
+	blueprint := &models.Blueprint{ID: uuid.New(), Filename: "plan.pdf", S3Key: "blueprints/plan.pdf", Version: 1}
+
+	first := `{"rooms": [{"name": "Kitchen"}]}`
+	storeAnalysis(t, revisioner, blueprint, first)
+	blueprint.AnalysisModel = model
+	storeAnalysis(t, revisioner, blueprint, `{"rooms": [{"name": "Kitchen"}, {"name": "Bath"}]}`)
+
+	if len(revisions.revisions) != 1 {
+		t.Fatalf("created %d revisions, want exactly one", len(revisions.revisions))
+	}
+	revision := revisions.revisions[0]
+	if revision.AnalysisData == nil || *revision.AnalysisData != first || revision.AnalysisModel != model {
+		t.Errorf("revision analysis = %v, want the first result", revision.AnalysisData)
+	}
+	if revision.Reason == nil || *revision.Reason != models.BlueprintRevisionReasonPreAnalysisOverwrite {
+		t.Errorf("reason = %v, want %q", revision.Reason, models.BlueprintRevisionReasonPreAnalysisOverwrite)
+	}
+	if revision.Version != 1 || blueprint.Version != 1 {
+		t.Errorf("revision version %d, blueprint version %d; want both 1", revision.Version, blueprint.Version)
+	}
+
+	// Re-storing the same result is not a change worth a snapshot
+	storeAnalysis(t, revisioner, blueprint, *blueprint.AnalysisData)
+	if len(revisions.revisions) != 1 {
+		t.Errorf("identical analysis created a revision; have %d", len(revisions.revisions))
+	}
+}
+
+func TestAutoRevisioner_FollowsManualRevisions(t *testing.T) {
+	revisions := &fakeBlueprintRevisions{}
+	revisioner := NewAutoRevisioner(revisions, fakeAutoRevisionSettings(true))
+	first := `{"rooms": []}`
+	blueprint := &models.Blueprint{ID: uuid.New(), AnalysisData: &first}
+
+	manual, err := CreateBlueprintRevision(context.Background(), revisions, blueprint, nil, "")
+	if err != nil {
+		t.Fatalf("CreateBlueprintRevision() error = %v", err)
+	}
+	if manual.Reason != nil || manual.Version != 1 {
+		t.Fatalf("manual revision = %+v, want an unlabelled version 1", manual)
+	}
+
+	storeAnalysis(t, revisioner, blueprint, `{"rooms": [{"name": "Den"}]}`)
+	if len(revisions.revisions) != 2 || revisions.revisions[1].Version != 2 || blueprint.Version != 2 {
+		t.Errorf("auto revision after a manual one: %d revisions, blueprint version %d; want version 2", len(revisions.revisions), blueprint.Version)
+	}
+	if revisions.revisions[1].ChangesSummary == nil {
+		t.Error("auto revision should record the changes since the previous revision")
+	}
+}
+
+func TestAutoRevisioner_Disabled(t *testing.T) {
+	revisions := &fakeBlueprintRevisions{}
+	revisioner := NewAutoRevisioner(revisions, fakeAutoRevisionSettings(false))
+	blueprint := &models.Blueprint{ID: uuid.New()}
+
+	storeAnalysis(t, revisioner, blueprint, `{"rooms": []}`)
+	storeAnalysis(t, revisioner, blueprint, `{"rooms": [{"name": "Den"}]}`)
+	if len(revisions.revisions) != 0 {
+		t.Errorf("created %d revisions with auto revisions off", len(revisions.revisions))
+	}
+}
+
+func TestRestoreBlueprintRevision_SnapshotsUnsavedAnalysis(t *testing.T) {
+	revisions := &fakeBlueprintRevisions{}
+	revisioner := NewAutoRevisioner(revisions, fakeAutoRevisionSettings(true))
+	first, second := `{"rooms": [{"name": "Kitchen"}]}`, `{"rooms": [{"name": "Den"}]}`
+	blueprint := &models.Blueprint{ID: uuid.New(), Filename: "plan.pdf", S3Key: "blueprints/plan.pdf"}
+
+	// The auto revision holds the first analysis; the second is on the blueprint alone
+	storeAnalysis(t, revisioner, blueprint, first)
+	storeAnalysis(t, revisioner, blueprint, second)
+
+	restored, err := RestoreBlueprintRevision(context.Background(), revisions, blueprint, revisions.revisions[0], nil)
+	if err != nil {
+		t.Fatalf("RestoreBlueprintRevision() error = %v", err)
+	}
+	if len(revisions.revisions) != 3 || restored.Version != 3 || blueprint.Version != 3 {
+		t.Fatalf("%d revisions, restored version %d; want the unsaved analysis snapshotted as 2 and the restore as 3", len(revisions.revisions), restored.Version)
+	}
+	if snapshot := revisions.revisions[1]; *snapshot.AnalysisData != second || snapshot.Reason == nil || *snapshot.Reason != models.BlueprintRevisionReasonPreRestore {
+		t.Errorf("snapshot = %s (reason %v), want the unsaved analysis as a pre_restore revision", *snapshot.AnalysisData, snapshot.Reason)
+	}
+	if *blueprint.AnalysisData != first || blueprint.AnalysisStatus != models.AnalysisStatusCompleted {
+		t.Errorf("blueprint analysis = %s (%s), want version 1's", *blueprint.AnalysisData, blueprint.AnalysisStatus)
+	}
+
+	if _, err := RestoreBlueprintRevision(context.Background(), revisions, blueprint, restored, nil); !errors.Is(err, ErrRestoreLatestRevision) {
+		t.Errorf("restoring the latest revision: error = %v, want ErrRestoreLatestRevision", err)
+	}
+}
+
+func TestBidsPricedAfterRevision(t *testing.T) {
+	blueprintID := uuid.New()
+	pricedAt := func(version int) *string {
+		data, _ := json.Marshal(models.GenerateBidResponse{SourceBlueprints: []models.BidSourceBlueprint{{BlueprintID: blueprintID, Version: version}}})
+		s := string(data)
+		return &s
+	}
+	older := &models.Bid{ID: uuid.New(), BidData: pricedAt(1)}
+	newer := &models.Bid{ID: uuid.New(), BidData: pricedAt(3)}
+	unrecorded := &models.Bid{ID: uuid.New(), BlueprintIDs: []uuid.UUID{blueprintID}}
+	other := &models.Bid{ID: uuid.New(), BlueprintIDs: []uuid.UUID{uuid.New()}}
+
+	affected := BidsPricedAfterRevision([]*models.Bid{older, newer, unrecorded, other}, blueprintID, 2)
+	if len(affected) != 2 || affected[0] != newer.ID || affected[1] != unrecorded.ID {
+		t.Errorf("affected = %v, want the bid priced from version 3 and the one with no recorded version", affected)
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"math"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// EvaluateBudget compares a price against an optional project budget. It returns
+// nil when the project has no budget set.
+func EvaluateBudget(budget *float64, price float64) *models.BudgetStatus {
+	if budget == nil || *budget <= 0 {
+		return nil
+	}
+
+	delta := price - *budget
+	status := &models.BudgetStatus{
+		Budget:  *budget,
+		Price:   price,
+		Status:  models.BudgetStateUnder,
+		Delta:   math.Round(delta*100) / 100,
+		Percent: math.Round(delta / *budget * 10000) / 100,
+	}
+	if delta > 0 {
+		status.Status = models.BudgetStateOver
+	}
+
+	return status
+}
+
+// BudgetAckThreshold is the acknowledgement threshold for a company: its
+// profile's setting, else defaultPercent from the configuration
+func BudgetAckThreshold(profile *models.CompanyProfile, defaultPercent float64) float64 {
+	if profile == nil || profile.BudgetAckThresholdPercent == nil {
+		return defaultPercent
+	}
+	return *profile.BudgetAckThresholdPercent
+}
+
+// RequiresBudgetAcknowledgement reports whether a price is far enough over
+// budget that generation must be explicitly acknowledged. A threshold of zero
+// or less disables the requirement.
+func RequiresBudgetAcknowledgement(status *models.BudgetStatus, thresholdPercent float64) bool {
+	if status == nil || thresholdPercent <= 0 {
+		return false
+	}
+	return status.Status == models.BudgetStateOver && status.Percent > thresholdPercent
+}
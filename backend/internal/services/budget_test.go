@@ -0,0 +1,96 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestEvaluateBudget(t *testing.T) {
+	budget := 100000.0
+
+	tests := []struct {
+		name        string
+		budget      *float64
+		price       float64
+		wantNil     bool
+		wantStatus  models.BudgetState
+		wantDelta   float64
+		wantPercent float64
+	}{
+		{name: "no budget", budget: nil, price: 50000, wantNil: true},
+		{name: "under budget", budget: &budget, price: 85000, wantStatus: models.BudgetStateUnder, wantDelta: -15000, wantPercent: -15},
+		{name: "exactly on budget", budget: &budget, price: 100000, wantStatus: models.BudgetStateUnder, wantDelta: 0, wantPercent: 0},
+		{name: "slightly over budget", budget: &budget, price: 102500.55, wantStatus: models.BudgetStateOver, wantDelta: 2500.55, wantPercent: 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := EvaluateBudget(tt.budget, tt.price)
+			if tt.wantNil {
+				if status != nil {
+					t.Errorf("expected nil status, got %+v", status)
+				}
+				return
+			}
+			if status == nil {
+				t.Fatal("expected status, got nil")
+			}
+			if status.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, status.Status)
+			}
+			if status.Delta != tt.wantDelta {
+				t.Errorf("expected delta %.2f, got %.2f", tt.wantDelta, status.Delta)
+			}
+			if status.Percent != tt.wantPercent {
+				t.Errorf("expected percent %.2f, got %.2f", tt.wantPercent, status.Percent)
+			}
+		})
+	}
+}
+
+func TestRequiresBudgetAcknowledgement(t *testing.T) {
+	budget := 100000.0
+
+	tests := []struct {
+		name      string
+		price     float64
+		threshold float64
+		want      bool
+	}{
+		{name: "under budget never requires acknowledgement", price: 90000, threshold: 5, want: false},
+		{name: "slightly over within threshold", price: 103000, threshold: 5, want: false},
+		{name: "over by more than threshold", price: 110000, threshold: 5, want: true},
+		{name: "threshold disabled", price: 150000, threshold: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := EvaluateBudget(&budget, tt.price)
+			if got := RequiresBudgetAcknowledgement(status, tt.threshold); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if RequiresBudgetAcknowledgement(nil, 5) {
+		t.Error("expected no acknowledgement without a budget")
+	}
+}
+
+func TestBudgetAckThreshold(t *testing.T) {
+	strict, disabled := 2.5, 0.0
+
+	if got := BudgetAckThreshold(nil, 10); got != 10 {
+		t.Errorf("without a profile: got %.2f, want the default 10", got)
+	}
+	if got := BudgetAckThreshold(&models.CompanyProfile{}, 10); got != 10 {
+		t.Errorf("profile without a threshold: got %.2f, want the default 10", got)
+	}
+	if got := BudgetAckThreshold(&models.CompanyProfile{BudgetAckThresholdPercent: &strict}, 10); got != strict {
+		t.Errorf("profile threshold: got %.2f, want %.2f", got, strict)
+	}
+	if got := BudgetAckThreshold(&models.CompanyProfile{BudgetAckThresholdPercent: &disabled}, 10); got != 0 {
+		t.Errorf("profile disabling acknowledgement: got %.2f, want 0", got)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
-	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
 )
 
 // CachedCostIntegrationService wraps CostIntegrationService with Redis caching
@@ -23,9 +22,9 @@ type CachedCostIntegrationService struct {
 
 // NewCachedCostIntegrationService creates a new cached cost integration service
 func NewCachedCostIntegrationService(
-	materialRepo *repository.MaterialRepository,
-	laborRateRepo *repository.LaborRateRepository,
-	regionalRepo *repository.RegionalAdjustmentRepository,
+	materialRepo CostMaterialStore,
+	laborRateRepo CostLaborRateStore,
+	regionalRepo CostRegionalStore,
 	cache *RedisClient,
 ) *CachedCostIntegrationService {
 	baseService := NewCostIntegrationService(materialRepo, laborRateRepo, regionalRepo)
@@ -76,6 +75,40 @@ func (s *CachedCostIntegrationService) GetMaterials(ctx context.Context, categor
 	return materials, nil
 }
 
+// ListMaterials retrieves one page of materials with caching
+func (s *CachedCostIntegrationService) ListMaterials(ctx context.Context, filter models.MaterialListFilter) (*models.ListPage[models.MaterialCost], error) {
+	cacheKey := s.buildMaterialsListCacheKey(filter)
+
+	if s.cache != nil && s.cache.IsAvailable() {
+		cached, err := s.cache.Get(ctx, cacheKey)
+		if err == nil {
+			var page models.ListPage[models.MaterialCost]
+			if err := json.Unmarshal([]byte(cached), &page); err == nil {
+				slog.Debug("Materials list cache hit", "key", cacheKey)
+				return &page, nil
+			}
+		}
+	}
+
+	materials, total, err := s.materialRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.ListPage[models.MaterialCost]{Items: materials, Total: total, Limit: filter.Limit, Offset: filter.Offset}
+
+	if s.cache != nil && s.cache.IsAvailable() {
+		if data, err := json.Marshal(page); err == nil {
+			if err := s.cache.Set(ctx, cacheKey, data, s.materialsCacheTTL); err != nil {
+				slog.Warn("Failed to cache materials list", "error", err)
+			}
+		} else {
+			slog.Warn("Failed to marshal materials list for caching", "error", err)
+		}
+	}
+
+	return page, nil
+}
+
 // GetLaborRates retrieves labor rates with caching
 func (s *CachedCostIntegrationService) GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
 	// Build cache key
@@ -150,43 +183,27 @@ func (s *CachedCostIntegrationService) GetRegionalAdjustment(ctx context.Context
 	return adjustment, nil
 }
 
-// SyncMaterials syncs materials and invalidates cache
-func (s *CachedCostIntegrationService) SyncMaterials(ctx context.Context, providerName, region string) error {
-	// Call base implementation
-	if err := s.CostIntegrationService.SyncMaterials(ctx, providerName, region); err != nil {
-		return err
+// Sync syncs the named providers and invalidates the caches they feed
+func (s *CachedCostIntegrationService) Sync(ctx context.Context, providerNames []string, region string) (*SyncReport, error) {
+	report, err := s.CostIntegrationService.Sync(ctx, providerNames, region)
+	if err != nil {
+		return nil, err
 	}
-	
-	// Invalidate materials cache
-	s.invalidateMaterialsCache(ctx)
-	
-	return nil
+	s.invalidateSyncedCaches(ctx, region)
+	return report, nil
 }
 
-// SyncLaborRates syncs labor rates and invalidates cache
-func (s *CachedCostIntegrationService) SyncLaborRates(ctx context.Context, providerName, region string) error {
-	// Call base implementation
-	if err := s.CostIntegrationService.SyncLaborRates(ctx, providerName, region); err != nil {
-		return err
-	}
-	
-	// Invalidate labor rates cache
-	s.invalidateLaborRatesCache(ctx)
-	
-	return nil
+// SyncAll syncs every provider and invalidates the caches they feed
+func (s *CachedCostIntegrationService) SyncAll(ctx context.Context, region string) (*SyncReport, error) {
+	return s.Sync(ctx, s.ProviderNames(), region)
 }
 
-// SyncRegionalAdjustment syncs regional adjustment and invalidates cache
-func (s *CachedCostIntegrationService) SyncRegionalAdjustment(ctx context.Context, providerName, region string) error {
-	// Call base implementation
-	if err := s.CostIntegrationService.SyncRegionalAdjustment(ctx, providerName, region); err != nil {
-		return err
-	}
-	
-	// Invalidate regional adjustment cache
+// invalidateSyncedCaches drops the cached cost data a sync may have changed.
+// A partly failed sync may still have stored rows, so it always runs.
+func (s *CachedCostIntegrationService) invalidateSyncedCaches(ctx context.Context, region string) {
+	s.invalidateMaterialsCache(ctx)
+	s.invalidateLaborRatesCache(ctx)
 	s.invalidateRegionalAdjustmentCache(ctx, region)
-	
-	return nil
 }
 
 // Cache key builders
@@ -201,6 +218,22 @@ func (s *CachedCostIntegrationService) buildMaterialsCacheKey(category, region *
 	return key
 }
 
+// buildMaterialsListCacheKey keys a page of materials by every list
+// parameter. It shares the materials prefix, so syncs invalidate it too.
+func (s *CachedCostIntegrationService) buildMaterialsListCacheKey(filter models.MaterialListFilter) string {
+	key := "cost:materials:list"
+	if filter.Category != nil {
+		key += fmt.Sprintf(":category:%s", *filter.Category)
+	}
+	if filter.Region != nil {
+		key += fmt.Sprintf(":region:%s", *filter.Region)
+	}
+	if filter.Query != nil {
+		key += fmt.Sprintf(":q:%s", *filter.Query)
+	}
+	return key + fmt.Sprintf(":limit:%d:offset:%d", filter.Limit, filter.Offset)
+}
+
 func (s *CachedCostIntegrationService) buildLaborRatesCacheKey(trade, region *string) string {
 	key := "cost:labor_rates"
 	if trade != nil {
@@ -248,6 +281,16 @@ func (s *CachedCostIntegrationService) invalidateRegionalAdjustmentCache(ctx con
 	}
 }
 
+// InvalidateMaterialsCache clears cached material lookups after prices are edited directly
+func (s *CachedCostIntegrationService) InvalidateMaterialsCache(ctx context.Context) {
+	s.invalidateMaterialsCache(ctx)
+}
+
+// InvalidateLaborRatesCache clears cached labor rate lookups after rates are edited directly
+func (s *CachedCostIntegrationService) InvalidateLaborRatesCache(ctx context.Context) {
+	s.invalidateLaborRatesCache(ctx)
+}
+
 // InvalidateAllCache clears all cost-related caches
 func (s *CachedCostIntegrationService) InvalidateAllCache(ctx context.Context) error {
 	if s.cache == nil || !s.cache.IsAvailable() {
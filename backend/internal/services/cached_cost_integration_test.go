@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
 // Helper function for string pointers
@@ -95,6 +97,43 @@ func TestCachedCostIntegrationService_CacheKeyGeneration(t *testing.T) {
 	}
 }
 
+func TestCachedCostIntegrationService_ListCacheKey(t *testing.T) {
+	service := NewCachedCostIntegrationService(nil, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		filter   models.MaterialListFilter
+		expected string
+	}{
+		{
+			name:     "first page",
+			filter:   models.MaterialListFilter{Limit: 25},
+			expected: "cost:materials:list:limit:25:offset:0",
+		},
+		{
+			name:     "all filters",
+			filter:   models.MaterialListFilter{Category: ptrStr("paint"), Region: ptrStr("florida"), Query: ptrStr("primer"), Limit: 50, Offset: 100},
+			expected: "cost:materials:list:category:paint:region:florida:q:primer:limit:50:offset:100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := service.buildMaterialsListCacheKey(tt.filter)
+			if key != tt.expected {
+				t.Errorf("Expected key %s, got %s", tt.expected, key)
+			}
+		})
+	}
+
+	// Different pages must not share a cache entry
+	first := service.buildMaterialsListCacheKey(models.MaterialListFilter{Limit: 25})
+	second := service.buildMaterialsListCacheKey(models.MaterialListFilter{Limit: 25, Offset: 25})
+	if first == second {
+		t.Errorf("Expected distinct keys for different pages, got %s", first)
+	}
+}
+
 func TestCachedCostIntegrationService_InvalidateMethods(t *testing.T) {
 	// Test invalidation methods with nil cache (should not panic)
 	service := NewCachedCostIntegrationService(nil, nil, nil, nil)
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxCompanyProfileFieldLength caps each company profile field
+const MaxCompanyProfileFieldLength = 500
+
+// companyLogoExtensions are the logo image types the PDF renderer can embed
+var companyLogoExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true}
+
+// CompanyLogoKeyPrefix is where a company's logos are stored. Profiles may
+// only name logos under their own prefix, so one company's bids can't embed
+// another's files.
+func CompanyLogoKeyPrefix(userID uuid.UUID) string {
+	return fmt.Sprintf("company-logos/%s/", userID)
+}
+
+// NormalizeCompanyProfile trims a company profile's fields, clearing blank
+// optional ones, and validates them. The name is required.
+func NormalizeCompanyProfile(profile *models.CompanyProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(profile.Name) > MaxCompanyProfileFieldLength {
+		return fmt.Errorf("name must be at most %d characters", MaxCompanyProfileFieldLength)
+	}
+
+	optional := []struct {
+		name  string
+		value **string
+	}{
+		{"address", &profile.Address},
+		{"phone", &profile.Phone},
+		{"email", &profile.Email},
+		{"website", &profile.Website},
+		{"license_number", &profile.LicenseNumber},
+		{"insurance_info", &profile.InsuranceInfo},
+		{"logo_s3_key", &profile.LogoS3Key},
+	}
+	for _, field := range optional {
+		if *field.value == nil {
+			continue
+		}
+		value := strings.TrimSpace(**field.value)
+		if value == "" {
+			*field.value = nil
+			continue
+		}
+		if utf8.RuneCountInString(value) > MaxCompanyProfileFieldLength {
+			return fmt.Errorf("%s must be at most %d characters", field.name, MaxCompanyProfileFieldLength)
+		}
+		*field.value = &value
+	}
+
+	if profile.BudgetAckThresholdPercent != nil && *profile.BudgetAckThresholdPercent < 0 {
+		return fmt.Errorf("budget_ack_threshold_percent must not be negative")
+	}
+	if profile.Email != nil && !strings.Contains(*profile.Email, "@") {
+		return fmt.Errorf("email must be an email address")
+	}
+	if key := profile.LogoS3Key; key != nil {
+		if !strings.HasPrefix(*key, CompanyLogoKeyPrefix(profile.UserID)) || strings.Contains(*key, "..") {
+			return fmt.Errorf("logo_s3_key must be under %s", CompanyLogoKeyPrefix(profile.UserID))
+		}
+		if !companyLogoExtensions[strings.ToLower(path.Ext(*key))] {
+			return fmt.Errorf("logo must be a PNG or JPEG image")
+		}
+	}
+	return nil
+}
+
+// CompanyProfileInfo is the branding a company profile prints on bid
+// documents. The logo is identified by its storage key.
+func CompanyProfileInfo(profile *models.CompanyProfile) *models.CompanyInfo {
+	return &models.CompanyInfo{
+		Name:          profile.Name,
+		Logo:          profile.LogoS3Key,
+		Address:       profile.Address,
+		Phone:         profile.Phone,
+		Email:         profile.Email,
+		Website:       profile.Website,
+		LicenseNumber: profile.LicenseNumber,
+		InsuranceInfo: profile.InsuranceInfo,
+	}
+}
+
+// DownloadCompanyLogo writes a stored logo to a temporary file the PDF
+// renderer can read, keeping its extension so the image type is detected.
+// The caller removes the file with the returned cleanup.
+func DownloadCompanyLogo(ctx context.Context, files BlueprintFileSource, key string) (string, func(), error) {
+	data, err := files.DownloadFile(ctx, key)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download logo: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "company-logo-*"+strings.ToLower(path.Ext(key)))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create logo file: %w", err)
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write logo file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write logo file: %w", err)
+	}
+	return file.Name(), cleanup, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type fakeLogoSource map[string][]byte
+
+func (f fakeLogoSource) DownloadFile(ctx context.Context, key string) ([]byte, error) {
+	data, ok := f[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func TestNormalizeCompanyProfile(t *testing.T) {
+	userID := uuid.New()
+	ptr := func(s string) *string { return &s }
+	negative := -5.0
+	logoKey := CompanyLogoKeyPrefix(userID) + "logo.JPG"
+
+	profile := &models.CompanyProfile{UserID: userID, Name: "  Acme Builders ", Email: ptr(" office@acme.example "), Phone: ptr("   "), LogoS3Key: ptr(logoKey)}
+	if err := NormalizeCompanyProfile(profile); err != nil {
+		t.Fatalf("NormalizeCompanyProfile() error = %v", err)
+	}
+	if profile.Name != "Acme Builders" || *profile.Email != "office@acme.example" || profile.Phone != nil || *profile.LogoS3Key != logoKey {
+		t.Errorf("normalized profile = %+v, want trimmed fields and a blank phone cleared", profile)
+	}
+
+	invalid := map[string]*models.CompanyProfile{
+		"blank name":          {UserID: userID, Name: "  "},
+		"long name":           {UserID: userID, Name: strings.Repeat("a", MaxCompanyProfileFieldLength+1)},
+		"long address":        {UserID: userID, Name: "Acme", Address: ptr(strings.Repeat("a", MaxCompanyProfileFieldLength+1))},
+		"bad email":           {UserID: userID, Name: "Acme", Email: ptr("acme.example")},
+		"another user's logo": {UserID: userID, Name: "Acme", LogoS3Key: ptr(CompanyLogoKeyPrefix(uuid.New()) + "logo.png")},
+		"escaping logo":       {UserID: userID, Name: "Acme", LogoS3Key: ptr(CompanyLogoKeyPrefix(userID) + "../../blueprints/logo.png")},
+		"gif logo":            {UserID: userID, Name: "Acme", LogoS3Key: ptr(CompanyLogoKeyPrefix(userID) + "logo.gif")},
+		"negative threshold":  {UserID: userID, Name: "Acme", BudgetAckThresholdPercent: &negative},
+	}
+	for name, profile := range invalid {
+		if err := NormalizeCompanyProfile(profile); err == nil {
+			t.Errorf("%s: NormalizeCompanyProfile() error = nil, want an error", name)
+		}
+	}
+}
+
+func TestDownloadCompanyLogo(t *testing.T) {
+	key := "company-logos/acme/Logo.PNG"
+	files := fakeLogoSource{key: []byte("logo")}
+
+	path, cleanup, err := DownloadCompanyLogo(context.Background(), files, key)
+	if err != nil {
+		t.Fatalf("DownloadCompanyLogo() error = %v", err)
+	}
+	if filepath.Ext(path) != ".png" {
+		t.Errorf("logo file = %s, want a .png extension", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "logo" {
+		t.Errorf("logo file contents = %q (%v), want the stored logo", data, err)
+	}
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("logo file still exists after cleanup: %v", err)
+	}
+
+	if _, _, err := DownloadCompanyLogo(context.Background(), files, "company-logos/acme/missing.png"); err == nil {
+		t.Error("DownloadCompanyLogo() of a missing logo: error = nil, want an error")
+	}
+}
@@ -4,14 +4,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
-type ComparisonService struct{}
+// DefaultRenameTolerance is how far apart, as a fraction, a removed and an
+// added room's area and sides can be for the pair to be reported as a rename
+const DefaultRenameTolerance = 0.05
+
+type ComparisonService struct {
+	impact          *ImpactClassifier
+	renameTolerance float64
+}
 
 func NewComparisonService() *ComparisonService {
-	return &ComparisonService{}
+	return &ComparisonService{impact: NewImpactClassifier(nil), renameTolerance: DefaultRenameTolerance}
+}
+
+// WithRenameTolerance sets how closely a removed and an added room must agree
+// to be reported as one renamed room. A negative tolerance reports every
+// name change as a removal and an addition.
+func (s *ComparisonService) WithRenameTolerance(tolerance float64) *ComparisonService {
+	s.renameTolerance = tolerance
+	return s
+}
+
+// WithImpactThresholds labels changes by a company's impact thresholds
+// instead of the defaults
+func (s *ComparisonService) WithImpactThresholds(thresholds *models.ImpactThresholds) *ComparisonService {
+	s.impact = NewImpactClassifier(thresholds)
+	return s
 }
 
 // CompareBlueprintRevisions compares two blueprint revisions and returns the differences
@@ -23,42 +47,106 @@ func (s *ComparisonService) CompareBlueprintRevisions(from, to *models.Blueprint
 		Summary: models.ComparisonSummary{
 			ChangesByCategory: make(map[string]int),
 		},
+		ImpactThresholds: s.impact.Thresholds(),
 	}
 
-	// Parse analysis data from both revisions
-	var fromAnalysis, toAnalysis models.AnalysisResult
-	if from.AnalysisData != nil {
-		if err := json.Unmarshal([]byte(*from.AnalysisData), &fromAnalysis); err != nil {
-			return nil, fmt.Errorf("failed to parse from analysis data: %w", err)
-		}
+	// Parse analysis data from both revisions. Rows written before analyses
+	// were validated may not parse; compare what is left of those.
+	fromAnalysis, fromErr := ParseAnalysisData(from.AnalysisData)
+	if fromErr != nil {
+		comparison.ParseErrors = append(comparison.ParseErrors, models.ComparisonParseError{Version: from.Version, Side: "from", Error: fromErr.Error()})
 	}
-	if to.AnalysisData != nil {
-		if err := json.Unmarshal([]byte(*to.AnalysisData), &toAnalysis); err != nil {
-			return nil, fmt.Errorf("failed to parse to analysis data: %w", err)
-		}
+	toAnalysis, toErr := ParseAnalysisData(to.AnalysisData)
+	if toErr != nil {
+		comparison.ParseErrors = append(comparison.ParseErrors, models.ComparisonParseError{Version: to.Version, Side: "to", Error: toErr.Error()})
+	}
+	if len(comparison.ParseErrors) > 0 {
+		s.compareFileMetadata(from, to, comparison)
+		s.calculateSummary(comparison)
+		return comparison, nil
 	}
 
 	// Compare rooms
-	s.compareRooms(&fromAnalysis, &toAnalysis, comparison)
+	s.compareRooms(fromAnalysis, toAnalysis, comparison)
 
 	// Compare openings
-	s.compareOpenings(&fromAnalysis, &toAnalysis, comparison)
+	s.compareOpenings(fromAnalysis, toAnalysis, comparison)
 
 	// Compare fixtures
-	s.compareFixtures(&fromAnalysis, &toAnalysis, comparison)
+	s.compareFixtures(fromAnalysis, toAnalysis, comparison)
 
 	// Compare measurements
-	s.compareMeasurements(&fromAnalysis, &toAnalysis, comparison)
+	s.compareMeasurements(fromAnalysis, toAnalysis, comparison)
 
 	// Compare materials
-	s.compareMaterials(&fromAnalysis, &toAnalysis, comparison)
+	s.compareMaterials(fromAnalysis, toAnalysis, comparison)
+
+	// Note model changes, which often explain otherwise surprising diffs
+	if description, changed := describeModelChange(from.AnalysisModel, to.AnalysisModel); changed {
+		impact := models.ImpactLow
+		comparison.Changes = append(comparison.Changes, models.BlueprintChange{
+			ChangeType:  models.ChangeTypeModified,
+			Category:    "model_changed",
+			Description: description,
+			OldValue:    from.AnalysisModel,
+			NewValue:    to.AnalysisModel,
+			Impact:      &impact,
+		})
+	}
 
 	// Calculate summary
 	s.calculateSummary(comparison)
+	comparison.NetAreaDelta = math.Round((totalRoomArea(toAnalysis)-totalRoomArea(fromAnalysis))*100) / 100
 
 	return comparison, nil
 }
 
+// ParseAnalysisData parses a stored analysis. A revision without one parses
+// as an empty analysis.
+func ParseAnalysisData(data *string) (*models.AnalysisResult, error) {
+	var analysis models.AnalysisResult
+	if data == nil {
+		return &analysis, nil
+	}
+	if err := json.Unmarshal([]byte(*data), &analysis); err != nil {
+		return nil, fmt.Errorf("failed to parse analysis data: %w", err)
+	}
+	return &analysis, nil
+}
+
+func int64OrZero(n *int64) int64 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+// compareFileMetadata compares the uploaded files behind two revisions, for
+// when their analyses cannot be compared
+func (s *ComparisonService) compareFileMetadata(from, to *models.BlueprintRevision, comparison *models.BlueprintComparison) {
+	addChange := func(description string, oldValue, newValue interface{}) {
+		impact := models.ImpactLow
+		comparison.Changes = append(comparison.Changes, models.BlueprintChange{
+			ChangeType:  models.ChangeTypeModified,
+			Category:    "file",
+			Description: description,
+			OldValue:    oldValue,
+			NewValue:    newValue,
+			Impact:      &impact,
+		})
+	}
+
+	if from.Filename != to.Filename {
+		addChange(fmt.Sprintf("Filename changed from %s to %s", from.Filename, to.Filename), from.Filename, to.Filename)
+	}
+	if fromSize, toSize := int64OrZero(from.FileSize), int64OrZero(to.FileSize); fromSize != toSize {
+		addChange(fmt.Sprintf("File size changed from %d to %d bytes", fromSize, toSize), from.FileSize, to.FileSize)
+	}
+	if fromType, toType := stringOrEmpty(from.MimeType), stringOrEmpty(to.MimeType); fromType != toType {
+		addChange(fmt.Sprintf("File type changed from %s to %s", fromType, toType), from.MimeType, to.MimeType)
+	}
+}
+
 func (s *ComparisonService) compareRooms(from, to *models.AnalysisResult, comparison *models.BlueprintComparison) {
 	fromRooms := make(map[string]models.Room)
 	for _, room := range from.Rooms {
@@ -70,53 +158,169 @@ func (s *ComparisonService) compareRooms(from, to *models.AnalysisResult, compar
 		toRooms[room.Name] = room
 	}
 
-	// Find added and modified rooms
+	// Modified rooms keep their name; the rest are paired as renames where
+	// their sizes agree, or reported as added and removed
+	var added, removed []string
 	for name, toRoom := range toRooms {
-		if fromRoom, exists := fromRooms[name]; exists {
-			// Check for modifications
-			if fromRoom.Area != toRoom.Area || fromRoom.Dimensions != toRoom.Dimensions {
-				impact := "Medium"
-				// Only check percentage if fromRoom.Area is not zero
-				if fromRoom.Area > 0 && math.Abs(fromRoom.Area-toRoom.Area) > fromRoom.Area*0.2 { // >20% change
-					impact = "High"
-				} else if fromRoom.Area == 0 && toRoom.Area > 0 {
-					impact = "High"
-				}
-				comparison.Changes = append(comparison.Changes, models.BlueprintChange{
-					ChangeType:  models.ChangeTypeModified,
-					Category:    "room",
-					Description: fmt.Sprintf("Room '%s' dimensions changed from %s (%.2f SF) to %s (%.2f SF)", name, fromRoom.Dimensions, fromRoom.Area, toRoom.Dimensions, toRoom.Area),
-					OldValue:    fromRoom,
-					NewValue:    toRoom,
-					Impact:      &impact,
-				})
-			}
-		} else {
-			// Room added
-			impact := "Medium"
+		fromRoom, exists := fromRooms[name]
+		if !exists {
+			added = append(added, name)
+			continue
+		}
+		if fromRoom.Area != toRoom.Area || fromRoom.Dimensions != toRoom.Dimensions {
+			impact := s.impact.QuantityChange("room", fromRoom.Area, toRoom.Area)
 			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
-				ChangeType:  models.ChangeTypeAdded,
+				ChangeType:  models.ChangeTypeModified,
 				Category:    "room",
-				Description: fmt.Sprintf("Room '%s' added with dimensions %s (%.2f SF)", name, toRoom.Dimensions, toRoom.Area),
+				Description: fmt.Sprintf("Room '%s' dimensions changed from %s (%.2f SF) to %s (%.2f SF)", name, fromRoom.Dimensions, fromRoom.Area, toRoom.Dimensions, toRoom.Area),
+				OldValue:    fromRoom,
 				NewValue:    toRoom,
 				Impact:      &impact,
 			})
 		}
 	}
-
-	// Find removed rooms
-	for name, fromRoom := range fromRooms {
+	for name := range fromRooms {
 		if _, exists := toRooms[name]; !exists {
-			impact := "High"
-			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
-				ChangeType:  models.ChangeTypeRemoved,
-				Category:    "room",
-				Description: fmt.Sprintf("Room '%s' removed (was %s, %.2f SF)", name, fromRoom.Dimensions, fromRoom.Area),
-				OldValue:    fromRoom,
-				Impact:      &impact,
-			})
+			removed = append(removed, name)
+		}
+	}
+
+	renamed := pairRenames(removed, added, func(fromName, toName string) (float64, bool) {
+		return s.similarRooms(fromRooms[fromName], toRooms[toName])
+	})
+	for _, pair := range renamed {
+		fromRoom, toRoom := fromRooms[pair.from], toRooms[pair.to]
+		impact := models.ImpactLow
+		description := fmt.Sprintf("Room renamed from %s to %s", pair.from, pair.to)
+		if fromRoom.Area != toRoom.Area || fromRoom.Dimensions != toRoom.Dimensions {
+			impact = s.impact.QuantityChange("room", fromRoom.Area, toRoom.Area)
+			description += fmt.Sprintf(", dimensions changed from %s (%.2f SF) to %s (%.2f SF)", fromRoom.Dimensions, fromRoom.Area, toRoom.Dimensions, toRoom.Area)
+		}
+		comparison.Changes = append(comparison.Changes, models.BlueprintChange{
+			ChangeType:  models.ChangeTypeModified,
+			Category:    "room",
+			Description: description,
+			OldValue:    fromRoom,
+			NewValue:    toRoom,
+			Impact:      &impact,
+		})
+	}
+
+	for _, name := range added {
+		if renamed.hasTo(name) {
+			continue
+		}
+		toRoom := toRooms[name]
+		impact := models.ImpactMedium
+		comparison.Changes = append(comparison.Changes, models.BlueprintChange{
+			ChangeType:  models.ChangeTypeAdded,
+			Category:    "room",
+			Description: fmt.Sprintf("Room '%s' added with dimensions %s (%.2f SF)", name, toRoom.Dimensions, toRoom.Area),
+			NewValue:    toRoom,
+			Impact:      &impact,
+		})
+	}
+
+	for _, name := range removed {
+		if renamed.hasFrom(name) {
+			continue
+		}
+		fromRoom := fromRooms[name]
+		impact := models.ImpactHigh
+		comparison.Changes = append(comparison.Changes, models.BlueprintChange{
+			ChangeType:  models.ChangeTypeRemoved,
+			Category:    "room",
+			Description: fmt.Sprintf("Room '%s' removed (was %s, %.2f SF)", name, fromRoom.Dimensions, fromRoom.Area),
+			OldValue:    fromRoom,
+			Impact:      &impact,
+		})
+	}
+}
+
+// renamePair is a removed name and the added name taken to be its rename
+type renamePair struct {
+	from, to string
+}
+
+type renamePairs []renamePair
+
+func (p renamePairs) hasFrom(name string) bool {
+	for _, pair := range p {
+		if pair.from == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p renamePairs) hasTo(name string) bool {
+	for _, pair := range p {
+		if pair.to == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pairRenames pairs removed names with added ones that similar accepts,
+// each removed name taking its closest unused match by the distance similar
+// returns. Names are visited in sorted order so the pairing is stable.
+func pairRenames(removed, added []string, similar func(from, to string) (float64, bool)) renamePairs {
+	sort.Strings(removed)
+	sort.Strings(added)
+
+	var pairs renamePairs
+	used := make(map[string]bool, len(added))
+	for _, from := range removed {
+		best, bestDistance := "", math.Inf(1)
+		for _, to := range added {
+			if used[to] {
+				continue
+			}
+			if distance, ok := similar(from, to); ok && distance < bestDistance {
+				best, bestDistance = to, distance
+			}
+		}
+		if best != "" {
+			used[best] = true
+			pairs = append(pairs, renamePair{from: from, to: best})
+		}
+	}
+	return pairs
+}
+
+// withinTolerance reports whether a and b differ by at most tolerance of
+// the larger
+func withinTolerance(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= math.Max(math.Abs(a), math.Abs(b))*tolerance
+}
+
+// similarRooms reports whether two rooms are close enough in size to be one
+// room renamed: their areas within the rename tolerance and, when both
+// dimension strings parse, each side too. Rooms whose dimensions do not
+// parse must have the same dimension string. The distance is the relative
+// area difference.
+func (s *ComparisonService) similarRooms(a, b models.Room) (float64, bool) {
+	if s.renameTolerance < 0 || a.Area <= 0 || b.Area <= 0 || !withinTolerance(a.Area, b.Area, s.renameTolerance) {
+		return 0, false
+	}
+
+	aSides, aOK := parseRoomSides(a.Dimensions)
+	bSides, bOK := parseRoomSides(b.Dimensions)
+	switch {
+	case aOK && bOK:
+		if len(aSides) != len(bSides) {
+			return 0, false
+		}
+		for i := range aSides {
+			if !withinTolerance(aSides[i], bSides[i], s.renameTolerance) {
+				return 0, false
+			}
 		}
+	case !strings.EqualFold(strings.TrimSpace(a.Dimensions), strings.TrimSpace(b.Dimensions)):
+		return 0, false
 	}
+	return math.Abs(a.Area-b.Area) / math.Max(a.Area, b.Area), true
 }
 
 func (s *ComparisonService) compareOpenings(from, to *models.AnalysisResult, comparison *models.BlueprintComparison) {
@@ -136,7 +340,7 @@ func (s *ComparisonService) compareOpenings(from, to *models.AnalysisResult, com
 	for key, toOpening := range toOpenings {
 		if fromOpening, exists := fromOpenings[key]; exists {
 			if fromOpening.Count != toOpening.Count {
-				impact := "Medium"
+				impact := models.ImpactMedium
 				comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 					ChangeType:  models.ChangeTypeModified,
 					Category:    "opening",
@@ -147,7 +351,7 @@ func (s *ComparisonService) compareOpenings(from, to *models.AnalysisResult, com
 				})
 			}
 		} else {
-			impact := "Low"
+			impact := models.ImpactLow
 			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 				ChangeType:  models.ChangeTypeAdded,
 				Category:    "opening",
@@ -160,7 +364,7 @@ func (s *ComparisonService) compareOpenings(from, to *models.AnalysisResult, com
 
 	for key, fromOpening := range fromOpenings {
 		if _, exists := toOpenings[key]; !exists {
-			impact := "Low"
+			impact := models.ImpactLow
 			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 				ChangeType:  models.ChangeTypeRemoved,
 				Category:    "opening",
@@ -189,7 +393,7 @@ func (s *ComparisonService) compareFixtures(from, to *models.AnalysisResult, com
 	for key, toFixture := range toFixtures {
 		if fromFixture, exists := fromFixtures[key]; exists {
 			if fromFixture.Count != toFixture.Count {
-				impact := "Low"
+				impact := models.ImpactLow
 				comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 					ChangeType:  models.ChangeTypeModified,
 					Category:    "fixture",
@@ -200,7 +404,7 @@ func (s *ComparisonService) compareFixtures(from, to *models.AnalysisResult, com
 				})
 			}
 		} else {
-			impact := "Low"
+			impact := models.ImpactLow
 			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 				ChangeType:  models.ChangeTypeAdded,
 				Category:    "fixture",
@@ -213,7 +417,7 @@ func (s *ComparisonService) compareFixtures(from, to *models.AnalysisResult, com
 
 	for key, fromFixture := range fromFixtures {
 		if _, exists := toFixtures[key]; !exists {
-			impact := "Low"
+			impact := models.ImpactLow
 			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 				ChangeType:  models.ChangeTypeRemoved,
 				Category:    "fixture",
@@ -248,13 +452,7 @@ func (s *ComparisonService) compareMeasurements(from, to *models.AnalysisResult,
 	for key, toMeasurement := range toMeasurements {
 		if fromMeasurement, exists := fromMeasurements[key]; exists {
 			if fromMeasurement.Value != toMeasurement.Value {
-				impact := "Medium"
-				// Only check percentage if fromMeasurement.Value is not zero
-				if fromMeasurement.Value > 0 && math.Abs(fromMeasurement.Value-toMeasurement.Value) > fromMeasurement.Value*0.2 {
-					impact = "High"
-				} else if fromMeasurement.Value == 0 && toMeasurement.Value > 0 {
-					impact = "High"
-				}
+				impact := s.impact.QuantityChange("measurement", fromMeasurement.Value, toMeasurement.Value)
 				comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 					ChangeType:  models.ChangeTypeModified,
 					Category:    "measurement",
@@ -265,7 +463,7 @@ func (s *ComparisonService) compareMeasurements(from, to *models.AnalysisResult,
 				})
 			}
 		} else {
-			impact := "Low"
+			impact := models.ImpactLow
 			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 				ChangeType:  models.ChangeTypeAdded,
 				Category:    "measurement",
@@ -278,7 +476,7 @@ func (s *ComparisonService) compareMeasurements(from, to *models.AnalysisResult,
 
 	for key, fromMeasurement := range fromMeasurements {
 		if _, exists := toMeasurements[key]; !exists {
-			impact := "Medium"
+			impact := models.ImpactMedium
 			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
 				ChangeType:  models.ChangeTypeRemoved,
 				Category:    "measurement",
@@ -302,49 +500,88 @@ func (s *ComparisonService) compareMaterials(from, to *models.AnalysisResult, co
 	}
 
 	// Compare materials
+	var added, removed []string
 	for name, toMaterial := range toMaterials {
-		if fromMaterial, exists := fromMaterials[name]; exists {
-			if fromMaterial.Quantity != toMaterial.Quantity {
-				impact := "Medium"
-				// Only check percentage if fromMaterial.Quantity is not zero
-				if fromMaterial.Quantity > 0 && math.Abs(fromMaterial.Quantity-toMaterial.Quantity) > fromMaterial.Quantity*0.2 {
-					impact = "High"
-				} else if fromMaterial.Quantity == 0 && toMaterial.Quantity > 0 {
-					impact = "High"
-				}
-				comparison.Changes = append(comparison.Changes, models.BlueprintChange{
-					ChangeType:  models.ChangeTypeModified,
-					Category:    "material",
-					Description: fmt.Sprintf("%s quantity changed from %.2f %s to %.2f %s", name, fromMaterial.Quantity, fromMaterial.Unit, toMaterial.Quantity, toMaterial.Unit),
-					OldValue:    fromMaterial,
-					NewValue:    toMaterial,
-					Impact:      &impact,
-				})
-			}
-		} else {
-			impact := "Medium"
+		fromMaterial, exists := fromMaterials[name]
+		if !exists {
+			added = append(added, name)
+			continue
+		}
+		if fromMaterial.Quantity != toMaterial.Quantity {
+			impact := s.impact.QuantityChange("material", fromMaterial.Quantity, toMaterial.Quantity)
 			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
-				ChangeType:  models.ChangeTypeAdded,
+				ChangeType:  models.ChangeTypeModified,
 				Category:    "material",
-				Description: fmt.Sprintf("%s added: %.2f %s", name, toMaterial.Quantity, toMaterial.Unit),
+				Description: fmt.Sprintf("%s quantity changed from %.2f %s to %.2f %s", name, fromMaterial.Quantity, fromMaterial.Unit, toMaterial.Quantity, toMaterial.Unit),
+				OldValue:    fromMaterial,
 				NewValue:    toMaterial,
 				Impact:      &impact,
 			})
 		}
 	}
-
-	for name, fromMaterial := range fromMaterials {
+	for name := range fromMaterials {
 		if _, exists := toMaterials[name]; !exists {
-			impact := "Medium"
-			comparison.Changes = append(comparison.Changes, models.BlueprintChange{
-				ChangeType:  models.ChangeTypeRemoved,
-				Category:    "material",
-				Description: fmt.Sprintf("%s removed, was: %.2f %s", name, fromMaterial.Quantity, fromMaterial.Unit),
-				OldValue:    fromMaterial,
-				Impact:      &impact,
-			})
+			removed = append(removed, name)
+		}
+	}
+
+	// A material whose unit and quantity are unchanged was renamed
+	renamed := pairRenames(removed, added, func(fromName, toName string) (float64, bool) {
+		fromMaterial, toMaterial := fromMaterials[fromName], toMaterials[toName]
+		return 0, s.renameTolerance >= 0 &&
+			strings.EqualFold(strings.TrimSpace(fromMaterial.Unit), strings.TrimSpace(toMaterial.Unit)) &&
+			fromMaterial.Quantity == toMaterial.Quantity
+	})
+	for _, pair := range renamed {
+		impact := models.ImpactLow
+		comparison.Changes = append(comparison.Changes, models.BlueprintChange{
+			ChangeType:  models.ChangeTypeModified,
+			Category:    "material",
+			Description: fmt.Sprintf("Material renamed from %s to %s", pair.from, pair.to),
+			OldValue:    fromMaterials[pair.from],
+			NewValue:    toMaterials[pair.to],
+			Impact:      &impact,
+		})
+	}
+
+	for _, name := range added {
+		if renamed.hasTo(name) {
+			continue
 		}
+		toMaterial := toMaterials[name]
+		impact := models.ImpactMedium
+		comparison.Changes = append(comparison.Changes, models.BlueprintChange{
+			ChangeType:  models.ChangeTypeAdded,
+			Category:    "material",
+			Description: fmt.Sprintf("%s added: %.2f %s", name, toMaterial.Quantity, toMaterial.Unit),
+			NewValue:    toMaterial,
+			Impact:      &impact,
+		})
 	}
+
+	for _, name := range removed {
+		if renamed.hasFrom(name) {
+			continue
+		}
+		fromMaterial := fromMaterials[name]
+		impact := models.ImpactMedium
+		comparison.Changes = append(comparison.Changes, models.BlueprintChange{
+			ChangeType:  models.ChangeTypeRemoved,
+			Category:    "material",
+			Description: fmt.Sprintf("%s removed, was: %.2f %s", name, fromMaterial.Quantity, fromMaterial.Unit),
+			OldValue:    fromMaterial,
+			Impact:      &impact,
+		})
+	}
+}
+
+// totalRoomArea sums room areas in an analysis
+func totalRoomArea(analysis *models.AnalysisResult) float64 {
+	var total float64
+	for _, room := range analysis.Rooms {
+		total += room.Area
+	}
+	return total
 }
 
 func (s *ComparisonService) calculateSummary(comparison *models.BlueprintComparison) {
@@ -360,7 +597,7 @@ func (s *ComparisonService) calculateSummary(comparison *models.BlueprintCompari
 			comparison.Summary.ModifiedCount++
 		}
 
-		if change.Impact != nil && *change.Impact == "High" {
+		if change.Impact != nil && *change.Impact == models.ImpactHigh {
 			comparison.Summary.HighImpactCount++
 		}
 
@@ -377,32 +614,67 @@ func (s *ComparisonService) CompareBidRevisions(from, to *models.BidRevision) (*
 		Summary: models.ComparisonSummary{
 			ChangesByCategory: make(map[string]int),
 		},
+		ImpactThresholds: s.impact.Thresholds(),
 	}
 
+	// Dollar changes are weighed against the price the revisions started from
+	finalPrice := referenceFinalPrice(from, to)
+
 	// Compare basic costs
-	s.compareBidCosts(from, to, comparison)
+	s.compareBidCosts(from, to, finalPrice, comparison)
+	s.compareBidName(from, to, comparison)
+	s.compareBidBlueprints(from, to, comparison)
 
 	// Compare bid data if available
 	if from.BidData != nil && to.BidData != nil {
 		var fromBidData, toBidData models.GenerateBidResponse
 		if err := json.Unmarshal([]byte(*from.BidData), &fromBidData); err == nil {
 			if err := json.Unmarshal([]byte(*to.BidData), &toBidData); err == nil {
-				s.compareBidLineItems(&fromBidData, &toBidData, comparison)
+				s.compareBidLineItems(&fromBidData, &toBidData, finalPrice, comparison)
+				s.compareBidTax(&fromBidData, &toBidData, finalPrice, comparison)
+				s.compareBidAlternates(&fromBidData, &toBidData, comparison)
 				s.compareBidTerms(&fromBidData, &toBidData, comparison)
 			}
 		}
 	}
 
+	if description, changed := describeModelChange(from.GenerationModel, to.GenerationModel); changed {
+		impact := models.ImpactLow
+		comparison.Changes = append(comparison.Changes, models.BidChange{
+			ChangeType:  models.ChangeTypeModified,
+			Category:    "model_changed",
+			Description: description,
+			OldValue:    from.GenerationModel,
+			NewValue:    to.GenerationModel,
+			Impact:      &impact,
+		})
+	}
+
 	// Calculate summary
 	s.calculateBidSummary(comparison)
+	if from.FinalPrice != nil && to.FinalPrice != nil {
+		comparison.NetCostDelta = math.Round((*to.FinalPrice-*from.FinalPrice)*100) / 100
+	}
 
 	return comparison, nil
 }
 
-func (s *ComparisonService) compareBidCosts(from, to *models.BidRevision, comparison *models.BidComparison) {
+// referenceFinalPrice is the final price dollar changes are weighed against:
+// the earlier revision's, or the later one's when the earlier has none
+func referenceFinalPrice(from, to *models.BidRevision) float64 {
+	if from.FinalPrice != nil && *from.FinalPrice > 0 {
+		return *from.FinalPrice
+	}
+	if to.FinalPrice != nil {
+		return *to.FinalPrice
+	}
+	return 0
+}
+
+func (s *ComparisonService) compareBidCosts(from, to *models.BidRevision, finalPrice float64, comparison *models.BidComparison) {
 	// Compare total cost
 	if from.TotalCost != nil && to.TotalCost != nil && *from.TotalCost != *to.TotalCost {
-		impact := "High"
+		impact := s.impact.CostChange("cost", models.ImpactHigh, *from.TotalCost, *to.TotalCost, finalPrice)
 		diff := *to.TotalCost - *from.TotalCost
 		var description string
 		if *from.TotalCost > 0 {
@@ -423,7 +695,7 @@ func (s *ComparisonService) compareBidCosts(from, to *models.BidRevision, compar
 
 	// Compare labor cost
 	if from.LaborCost != nil && to.LaborCost != nil && *from.LaborCost != *to.LaborCost {
-		impact := "Medium"
+		impact := s.impact.CostChange("cost", models.ImpactMedium, *from.LaborCost, *to.LaborCost, finalPrice)
 		diff := *to.LaborCost - *from.LaborCost
 		var description string
 		if *from.LaborCost > 0 {
@@ -444,7 +716,7 @@ func (s *ComparisonService) compareBidCosts(from, to *models.BidRevision, compar
 
 	// Compare material cost
 	if from.MaterialCost != nil && to.MaterialCost != nil && *from.MaterialCost != *to.MaterialCost {
-		impact := "Medium"
+		impact := s.impact.CostChange("cost", models.ImpactMedium, *from.MaterialCost, *to.MaterialCost, finalPrice)
 		diff := *to.MaterialCost - *from.MaterialCost
 		var description string
 		if *from.MaterialCost > 0 {
@@ -465,7 +737,7 @@ func (s *ComparisonService) compareBidCosts(from, to *models.BidRevision, compar
 
 	// Compare markup percentage
 	if from.MarkupPercentage != nil && to.MarkupPercentage != nil && *from.MarkupPercentage != *to.MarkupPercentage {
-		impact := "Medium"
+		impact := models.ImpactMedium
 		comparison.Changes = append(comparison.Changes, models.BidChange{
 			ChangeType:  models.ChangeTypeModified,
 			Category:    "terms",
@@ -478,7 +750,7 @@ func (s *ComparisonService) compareBidCosts(from, to *models.BidRevision, compar
 
 	// Compare final price
 	if from.FinalPrice != nil && to.FinalPrice != nil && *from.FinalPrice != *to.FinalPrice {
-		impact := "High"
+		impact := s.impact.CostChange("cost", models.ImpactHigh, *from.FinalPrice, *to.FinalPrice, finalPrice)
 		diff := *to.FinalPrice - *from.FinalPrice
 		var description string
 		if *from.FinalPrice > 0 {
@@ -498,7 +770,70 @@ func (s *ComparisonService) compareBidCosts(from, to *models.BidRevision, compar
 	}
 }
 
-func (s *ComparisonService) compareBidLineItems(from, to *models.GenerateBidResponse, comparison *models.BidComparison) {
+// compareBidTax reports a change in the sales tax charged, e.g. after the
+// project was marked exempt or the company's tax rules changed
+func (s *ComparisonService) compareBidTax(from, to *models.GenerateBidResponse, finalPrice float64, comparison *models.BidComparison) {
+	if from.TaxAmount == to.TaxAmount {
+		return
+	}
+	impact := s.impact.CostChange("cost", models.ImpactMedium, from.TaxAmount, to.TaxAmount, finalPrice)
+	comparison.Changes = append(comparison.Changes, models.BidChange{
+		ChangeType:  models.ChangeTypeModified,
+		Category:    "cost",
+		Description: fmt.Sprintf("Sales tax changed from $%.2f to $%.2f", from.TaxAmount, to.TaxAmount),
+		OldValue:    from.TaxAmount,
+		NewValue:    to.TaxAmount,
+		Impact:      &impact,
+	})
+}
+
+// compareBidBlueprints flags revisions priced from different blueprints.
+// Revisions stored before the linkage was recorded have none and are treated
+// as unknown rather than changed.
+func (s *ComparisonService) compareBidBlueprints(from, to *models.BidRevision, comparison *models.BidComparison) {
+	if len(from.BlueprintIDs) == 0 || len(to.BlueprintIDs) == 0 || SameBlueprintSet(from.BlueprintIDs, to.BlueprintIDs) {
+		return
+	}
+	comparison.BlueprintSetChanged = true
+	added, removed := 0, 0
+	for _, id := range to.BlueprintIDs {
+		if !containsBlueprint(from.BlueprintIDs, id) {
+			added++
+		}
+	}
+	for _, id := range from.BlueprintIDs {
+		if !containsBlueprint(to.BlueprintIDs, id) {
+			removed++
+		}
+	}
+	impact := models.ImpactHigh
+	comparison.Changes = append(comparison.Changes, models.BidChange{
+		ChangeType:  models.ChangeTypeModified,
+		Category:    "blueprints",
+		Description: fmt.Sprintf("Blueprints priced changed (%d added, %d removed); cost changes may reflect a different scope", added, removed),
+		OldValue:    from.BlueprintIDs,
+		NewValue:    to.BlueprintIDs,
+		Impact:      &impact,
+	})
+}
+
+// compareBidName records a rename between revisions
+func (s *ComparisonService) compareBidName(from, to *models.BidRevision, comparison *models.BidComparison) {
+	if from.Name == nil || to.Name == nil || *from.Name == *to.Name {
+		return
+	}
+	impact := models.ImpactLow
+	comparison.Changes = append(comparison.Changes, models.BidChange{
+		ChangeType:  models.ChangeTypeModified,
+		Category:    "name",
+		Description: fmt.Sprintf("Bid renamed from %q to %q", *from.Name, *to.Name),
+		OldValue:    *from.Name,
+		NewValue:    *to.Name,
+		Impact:      &impact,
+	})
+}
+
+func (s *ComparisonService) compareBidLineItems(from, to *models.GenerateBidResponse, finalPrice float64, comparison *models.BidComparison) {
 	fromItems := make(map[string]models.LineItem)
 	for _, item := range from.LineItems {
 		key := fmt.Sprintf("%s-%s", item.Trade, item.Description)
@@ -512,12 +847,13 @@ func (s *ComparisonService) compareBidLineItems(from, to *models.GenerateBidResp
 	}
 
 	// Compare line items
-	for key, toItem := range toItems {
+	for _, key := range sortedKeys(toItems) {
+		toItem := toItems[key]
 		trade := toItem.Trade
 		if fromItem, exists := fromItems[key]; exists {
 			// Check for quantity changes
 			if fromItem.Quantity != toItem.Quantity {
-				impact := "Medium"
+				impact := s.impact.CostChange("quantity", models.ImpactMedium, fromItem.Quantity*toItem.UnitCost, toItem.Quantity*toItem.UnitCost, finalPrice)
 				comparison.Changes = append(comparison.Changes, models.BidChange{
 					ChangeType:  models.ChangeTypeModified,
 					Category:    "quantity",
@@ -530,7 +866,7 @@ func (s *ComparisonService) compareBidLineItems(from, to *models.GenerateBidResp
 			}
 			// Check for unit cost changes
 			if fromItem.UnitCost != toItem.UnitCost {
-				impact := "Low"
+				impact := s.impact.CostChange("cost", models.ImpactLow, fromItem.UnitCost*toItem.Quantity, toItem.UnitCost*toItem.Quantity, finalPrice)
 				comparison.Changes = append(comparison.Changes, models.BidChange{
 					ChangeType:  models.ChangeTypeModified,
 					Category:    "cost",
@@ -543,7 +879,7 @@ func (s *ComparisonService) compareBidLineItems(from, to *models.GenerateBidResp
 			}
 			// Check for total changes
 			if fromItem.Total != toItem.Total {
-				impact := "Medium"
+				impact := s.impact.CostChange("line_item", models.ImpactMedium, fromItem.Total, toItem.Total, finalPrice)
 				comparison.Changes = append(comparison.Changes, models.BidChange{
 					ChangeType:  models.ChangeTypeModified,
 					Category:    "line_item",
@@ -554,8 +890,26 @@ func (s *ComparisonService) compareBidLineItems(from, to *models.GenerateBidResp
 					Impact:      &impact,
 				})
 			}
+			// Notes change what the bid assumes, not what it costs
+			if fromItem.Notes != toItem.Notes {
+				impact := models.ImpactLow
+				comparison.Changes = append(comparison.Changes, models.BidChange{
+					ChangeType:  models.ChangeTypeModified,
+					Category:    "line_item_note",
+					Trade:       &trade,
+					Description: fmt.Sprintf("%s - %s: notes changed", toItem.Trade, toItem.Description),
+					OldValue:    fromItem.Notes,
+					NewValue:    toItem.Notes,
+					Impact:      &impact,
+				})
+			}
+			if fromItem.NeedsReview != toItem.NeedsReview {
+				comparison.Changes = append(comparison.Changes, reviewItemChange(models.ChangeTypeModified, fromItem, toItem))
+			}
+		} else if toItem.NeedsReview {
+			comparison.Changes = append(comparison.Changes, reviewItemChange(models.ChangeTypeAdded, models.LineItem{}, toItem))
 		} else {
-			impact := "Medium"
+			impact := s.impact.CostChange("line_item", models.ImpactMedium, 0, toItem.Total, finalPrice)
 			comparison.Changes = append(comparison.Changes, models.BidChange{
 				ChangeType:  models.ChangeTypeAdded,
 				Category:    "line_item",
@@ -567,10 +921,15 @@ func (s *ComparisonService) compareBidLineItems(from, to *models.GenerateBidResp
 		}
 	}
 
-	for key, fromItem := range fromItems {
+	for _, key := range sortedKeys(fromItems) {
+		fromItem := fromItems[key]
 		if _, exists := toItems[key]; !exists {
+			if fromItem.NeedsReview {
+				comparison.Changes = append(comparison.Changes, reviewItemChange(models.ChangeTypeRemoved, fromItem, models.LineItem{}))
+				continue
+			}
 			trade := fromItem.Trade
-			impact := "High"
+			impact := s.impact.CostChange("line_item", models.ImpactHigh, fromItem.Total, 0, finalPrice)
 			comparison.Changes = append(comparison.Changes, models.BidChange{
 				ChangeType:  models.ChangeTypeRemoved,
 				Category:    "line_item",
@@ -583,10 +942,92 @@ func (s *ComparisonService) compareBidLineItems(from, to *models.GenerateBidResp
 	}
 }
 
+// reviewItemChange records a review placeholder appearing, disappearing or
+// being flagged or cleared under the "review_item" category. Placeholders
+// carry no cost, so they are reported apart from priced line items; pricing
+// a cleared placeholder also shows up as a line item change.
+func reviewItemChange(changeType models.ChangeType, fromItem, toItem models.LineItem) models.BidChange {
+	impact := models.ImpactLow
+	change := models.BidChange{
+		ChangeType: changeType,
+		Category:   "review_item",
+		Impact:     &impact,
+	}
+	switch {
+	case changeType == models.ChangeTypeAdded:
+		change.Trade = &toItem.Trade
+		change.Description = fmt.Sprintf("%s - %s added for review: %.2f %s", toItem.Trade, toItem.Description, toItem.Quantity, toItem.Unit)
+		change.NewValue = toItem
+	case changeType == models.ChangeTypeRemoved:
+		change.Trade = &fromItem.Trade
+		change.Description = fmt.Sprintf("%s - %s removed while awaiting review", fromItem.Trade, fromItem.Description)
+		change.OldValue = fromItem
+	case toItem.NeedsReview:
+		change.Trade = &toItem.Trade
+		change.Description = fmt.Sprintf("%s - %s: flagged for review", toItem.Trade, toItem.Description)
+		change.OldValue, change.NewValue = false, true
+	default:
+		change.Trade = &toItem.Trade
+		change.Description = fmt.Sprintf("%s - %s: reviewed", toItem.Trade, toItem.Description)
+		change.OldValue, change.NewValue = true, false
+	}
+	return change
+}
+
+// compareBidAlternates compares alternate groups by name under their own
+// "alternate" category so they don't read as base scope changes
+func (s *ComparisonService) compareBidAlternates(from, to *models.GenerateBidResponse, comparison *models.BidComparison) {
+	fromGroups := make(map[string]models.AlternateGroup)
+	for _, group := range from.Alternates {
+		fromGroups[group.Name] = group
+	}
+	toGroups := make(map[string]models.AlternateGroup)
+	for _, group := range to.Alternates {
+		toGroups[group.Name] = group
+	}
+
+	impact := models.ImpactLow
+	for _, toGroup := range to.Alternates {
+		fromGroup, exists := fromGroups[toGroup.Name]
+		if !exists {
+			comparison.Changes = append(comparison.Changes, models.BidChange{
+				ChangeType:  models.ChangeTypeAdded,
+				Category:    "alternate",
+				Description: fmt.Sprintf("%s added: +$%.2f", toGroup.Name, toGroup.Price),
+				NewValue:    toGroup.Price,
+				Impact:      &impact,
+			})
+			continue
+		}
+		if fromGroup.Price != toGroup.Price {
+			comparison.Changes = append(comparison.Changes, models.BidChange{
+				ChangeType:  models.ChangeTypeModified,
+				Category:    "alternate",
+				Description: fmt.Sprintf("%s price changed from $%.2f to $%.2f", toGroup.Name, fromGroup.Price, toGroup.Price),
+				OldValue:    fromGroup.Price,
+				NewValue:    toGroup.Price,
+				Impact:      &impact,
+			})
+		}
+	}
+
+	for _, fromGroup := range from.Alternates {
+		if _, exists := toGroups[fromGroup.Name]; !exists {
+			comparison.Changes = append(comparison.Changes, models.BidChange{
+				ChangeType:  models.ChangeTypeRemoved,
+				Category:    "alternate",
+				Description: fmt.Sprintf("%s removed: was +$%.2f", fromGroup.Name, fromGroup.Price),
+				OldValue:    fromGroup.Price,
+				Impact:      &impact,
+			})
+		}
+	}
+}
+
 func (s *ComparisonService) compareBidTerms(from, to *models.GenerateBidResponse, comparison *models.BidComparison) {
 	// Compare payment terms
 	if from.PaymentTerms != to.PaymentTerms {
-		impact := "Medium"
+		impact := models.ImpactMedium
 		comparison.Changes = append(comparison.Changes, models.BidChange{
 			ChangeType:  models.ChangeTypeModified,
 			Category:    "terms",
@@ -599,7 +1040,7 @@ func (s *ComparisonService) compareBidTerms(from, to *models.GenerateBidResponse
 
 	// Compare warranty terms
 	if from.WarrantyTerms != to.WarrantyTerms {
-		impact := "Low"
+		impact := models.ImpactLow
 		comparison.Changes = append(comparison.Changes, models.BidChange{
 			ChangeType:  models.ChangeTypeModified,
 			Category:    "terms",
@@ -620,9 +1061,9 @@ func (s *ComparisonService) compareBidTerms(from, to *models.GenerateBidResponse
 		toInclusions[inc] = true
 	}
 
-	for inc := range toInclusions {
+	for _, inc := range sortedKeys(toInclusions) {
 		if !fromInclusions[inc] {
-			impact := "Low"
+			impact := models.ImpactLow
 			comparison.Changes = append(comparison.Changes, models.BidChange{
 				ChangeType:  models.ChangeTypeAdded,
 				Category:    "scope",
@@ -633,9 +1074,9 @@ func (s *ComparisonService) compareBidTerms(from, to *models.GenerateBidResponse
 		}
 	}
 
-	for inc := range fromInclusions {
+	for _, inc := range sortedKeys(fromInclusions) {
 		if !toInclusions[inc] {
-			impact := "Medium"
+			impact := models.ImpactMedium
 			comparison.Changes = append(comparison.Changes, models.BidChange{
 				ChangeType:  models.ChangeTypeRemoved,
 				Category:    "scope",
@@ -645,6 +1086,42 @@ func (s *ComparisonService) compareBidTerms(from, to *models.GenerateBidResponse
 			})
 		}
 	}
+
+	// An added exclusion narrows the scope, so it weighs like a removed inclusion
+	fromExclusions := make(map[string]bool)
+	for _, exc := range from.Exclusions {
+		fromExclusions[exc] = true
+	}
+	toExclusions := make(map[string]bool)
+	for _, exc := range to.Exclusions {
+		toExclusions[exc] = true
+	}
+
+	for _, exc := range sortedKeys(toExclusions) {
+		if !fromExclusions[exc] {
+			impact := models.ImpactMedium
+			comparison.Changes = append(comparison.Changes, models.BidChange{
+				ChangeType:  models.ChangeTypeAdded,
+				Category:    "scope",
+				Description: fmt.Sprintf("Exclusion added: %s", exc),
+				NewValue:    exc,
+				Impact:      &impact,
+			})
+		}
+	}
+
+	for _, exc := range sortedKeys(fromExclusions) {
+		if !toExclusions[exc] {
+			impact := models.ImpactLow
+			comparison.Changes = append(comparison.Changes, models.BidChange{
+				ChangeType:  models.ChangeTypeRemoved,
+				Category:    "scope",
+				Description: fmt.Sprintf("Exclusion removed: %s", exc),
+				OldValue:    exc,
+				Impact:      &impact,
+			})
+		}
+	}
 }
 
 func (s *ComparisonService) calculateBidSummary(comparison *models.BidComparison) {
@@ -660,10 +1137,31 @@ func (s *ComparisonService) calculateBidSummary(comparison *models.BidComparison
 			comparison.Summary.ModifiedCount++
 		}
 
-		if change.Impact != nil && *change.Impact == "High" {
+		if change.Impact != nil && *change.Impact == models.ImpactHigh {
 			comparison.Summary.HighImpactCount++
 		}
 
 		comparison.Summary.ChangesByCategory[change.Category]++
 	}
 }
+
+// describeModelChange reports whether two revisions were produced by different AI
+// models. Revisions without metadata are treated as unknown rather than changed.
+func describeModelChange(from, to *models.AIModelInfo) (string, bool) {
+	if from == nil || to == nil || *from == *to {
+		return "", false
+	}
+	return fmt.Sprintf("Produced by a different AI model (%s -> %s); some differences may reflect the model change",
+		formatModelInfo(from), formatModelInfo(to)), true
+}
+
+func formatModelInfo(info *models.AIModelInfo) string {
+	label := info.Name
+	if info.Version != "" {
+		label += " " + info.Version
+	}
+	if info.PromptRevision != "" {
+		label += " (prompt " + info.PromptRevision + ")"
+	}
+	return strings.TrimSpace(label)
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+const (
+	comparisonBottomMargin = 20.0
+	comparisonLineHeight   = 5.0
+	comparisonValueLength  = 60 // old/new values are cut to this many characters
+)
+
+// comparisonColumns are the widths of the changes table: type, description,
+// old value, new value and impact
+var comparisonColumns = []float64{18, 78, 30, 30, 14}
+
+// comparisonCategoryOrder lists the change categories in the order they are
+// printed; unknown categories follow alphabetically
+var comparisonCategoryOrder = []string{"cost", "line_item", "quantity", "line_item_note", "review_item", "terms", "alternate", "name", "model_changed"}
+
+var comparisonCategoryLabels = map[string]string{
+	"cost":           "Costs",
+	"line_item":      "Line Items",
+	"quantity":       "Quantities",
+	"line_item_note": "Line Item Notes",
+	"review_item":    "Items Needing Review",
+	"terms":          "Terms",
+	"alternate":      "Alternates",
+	"name":           "Bid Name",
+	"model_changed":  "AI Model",
+}
+
+// tradeDelta is the net change in line item totals for one trade
+type tradeDelta struct {
+	Trade string
+	Delta float64
+}
+
+// GenerateComparisonPDF renders a comparison between two bid revisions:
+// version header, price rollups, the changes grouped by category, and the
+// scope changes as added and removed bullets
+func (s *PDFService) GenerateComparisonPDF(comparison *models.BidComparison, from, to *models.BidRevision, projectName string) ([]byte, error) {
+	pdf := s.renderComparisonPDF(comparison, from, to, projectName)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate comparison PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *PDFService) renderComparisonPDF(comparison *models.BidComparison, from, to *models.BidRevision, projectName string) *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, comparisonBottomMargin)
+	generated := time.Now().Format("January 2, 2006")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Arial", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Generated on %s | Page %d", generated, pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// Header
+	pdf.SetFont("Arial", "B", 20)
+	pdf.CellFormat(0, 10, "Bid Revision Comparison", "", 0, "L", false, 0, "")
+	pdf.Ln(8)
+	pdf.SetFont("Arial", "", 12)
+	pdf.CellFormat(0, 6, projectName, "", 0, "L", false, 0, "")
+	pdf.Ln(10)
+	pdf.SetLineWidth(0.5)
+	pdf.Line(20, pdf.GetY(), 190, pdf.GetY())
+	pdf.Ln(6)
+
+	pdf.SetFont("Arial", "", 10)
+	if to.Name != nil {
+		pdf.CellFormat(40, 6, "Bid:", "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, *to.Name, "", 0, "L", false, 0, "")
+		pdf.Ln(6)
+	}
+	pdf.CellFormat(40, 6, "From:", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Version %d (%s)", from.Version, from.CreatedAt.Format("January 2, 2006")), "", 0, "L", false, 0, "")
+	pdf.Ln(6)
+	pdf.CellFormat(40, 6, "To:", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Version %d (%s)", to.Version, to.CreatedAt.Format("January 2, 2006")), "", 0, "L", false, 0, "")
+	pdf.Ln(10)
+
+	s.addSection(pdf, "Summary")
+	s.addComparisonSummary(pdf, comparison)
+	pdf.Ln(5)
+
+	if deltas := comparisonTradeDeltas(comparison.Changes); len(deltas) > 0 {
+		s.addSection(pdf, "Changes by Trade")
+		s.addTradeDeltas(pdf, deltas)
+		pdf.Ln(5)
+	}
+
+	var scope []models.BidChange
+	groups := make(map[string][]models.BidChange)
+	for _, change := range comparison.Changes {
+		if change.Category == "scope" {
+			scope = append(scope, change)
+			continue
+		}
+		groups[change.Category] = append(groups[change.Category], change)
+	}
+
+	if len(groups) > 0 {
+		s.addSection(pdf, "Changes")
+		for _, category := range comparisonCategories(groups) {
+			s.addChangesTable(pdf, comparisonCategoryLabel(category), groups[category])
+			pdf.Ln(4)
+		}
+	}
+
+	if len(scope) > 0 {
+		s.addSection(pdf, "Inclusions & Exclusions")
+		s.addScopeChanges(pdf, scope)
+	}
+
+	return pdf
+}
+
+func (s *PDFService) addComparisonSummary(pdf *gofpdf.Fpdf, comparison *models.BidComparison) {
+	summary := comparison.Summary
+	rows := [][2]string{
+		{"Net price change:", formatSignedMoney(comparison.NetCostDelta)},
+		{"Total changes:", fmt.Sprintf("%d", summary.TotalChanges)},
+		{"Added / removed / modified:", fmt.Sprintf("%d / %d / %d", summary.AddedCount, summary.RemovedCount, summary.ModifiedCount)},
+		{"High-impact changes:", fmt.Sprintf("%d", summary.HighImpactCount)},
+	}
+	for i, row := range rows {
+		style := ""
+		if i == 0 {
+			style = "B"
+		}
+		pdf.SetFont("Arial", style, 10)
+		pdf.CellFormat(60, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, row[1], "", 0, "L", false, 0, "")
+		pdf.Ln(6)
+	}
+}
+
+func (s *PDFService) addTradeDeltas(pdf *gofpdf.Fpdf, deltas []tradeDelta) {
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(120, 6, "Trade", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(50, 6, "Change", "1", 0, "R", true, 0, "")
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 9)
+	for _, delta := range deltas {
+		pdf.CellFormat(120, 6, delta.Trade, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(50, 6, formatSignedMoney(delta.Delta), "1", 0, "R", false, 0, "")
+		pdf.Ln(-1)
+	}
+}
+
+// addChangesTable prints one category of changes. Rows never split across
+// pages: a row that does not fit starts a new page, which repeats the
+// category heading and column headers.
+func (s *PDFService) addChangesTable(pdf *gofpdf.Fpdf, title string, changes []models.BidChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return impactRank(changes[i].Impact) < impactRank(changes[j].Impact)
+	})
+
+	_, pageHeight := pdf.GetPageSize()
+	limit := pageHeight - comparisonBottomMargin
+
+	s.addChangesTableHeader(pdf, title, len(changes), false)
+	for _, change := range changes {
+		var impact models.ChangeImpact
+		if change.Impact != nil {
+			impact = *change.Impact
+		}
+		cells := []string{
+			string(change.ChangeType),
+			change.Description,
+			formatChangeValue(change.OldValue),
+			formatChangeValue(change.NewValue),
+			string(impact),
+		}
+
+		pdf.SetFont("Arial", "", 8)
+		lines := make([][]string, len(cells))
+		rowLines := 1
+		for i, cell := range cells {
+			for _, line := range pdf.SplitLines([]byte(cell), comparisonColumns[i]-2) {
+				lines[i] = append(lines[i], string(line))
+			}
+			rowLines = max(rowLines, len(lines[i]))
+		}
+		height := float64(rowLines) * comparisonLineHeight
+
+		if pdf.GetY()+height > limit {
+			pdf.AddPage()
+			s.addChangesTableHeader(pdf, title, len(changes), true)
+		}
+
+		fill := false
+		switch impact {
+		case models.ImpactHigh:
+			pdf.SetTextColor(190, 0, 0)
+			pdf.SetFillColor(255, 228, 228)
+			fill = true
+		case models.ImpactMedium:
+			pdf.SetTextColor(170, 100, 0)
+		}
+
+		x, y := pdf.GetXY()
+		for i, width := range comparisonColumns {
+			pdf.SetXY(x, y)
+			pdf.CellFormat(width, height, "", "1", 0, "", fill, 0, "")
+			pdf.SetXY(x, y)
+			pdf.MultiCell(width, comparisonLineHeight, strings.Join(lines[i], "\n"), "", "L", false)
+			x += width
+		}
+		pdf.SetXY(20, y+height)
+		pdf.SetTextColor(0, 0, 0)
+	}
+}
+
+func (s *PDFService) addChangesTableHeader(pdf *gofpdf.Fpdf, title string, count int, continued bool) {
+	heading := fmt.Sprintf("%s (%d)", title, count)
+	if continued {
+		heading += " - continued"
+	}
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, 7, heading, "", 0, "L", false, 0, "")
+	pdf.Ln(7)
+
+	pdf.SetFont("Arial", "B", 8)
+	pdf.SetFillColor(240, 240, 240)
+	for i, header := range []string{"Change", "Description", "Old", "New", "Impact"} {
+		pdf.CellFormat(comparisonColumns[i], 6, header, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// addScopeChanges lists added and removed inclusions and exclusions
+func (s *PDFService) addScopeChanges(pdf *gofpdf.Fpdf, changes []models.BidChange) {
+	var added, removed []string
+	for _, change := range changes {
+		if change.ChangeType == models.ChangeTypeRemoved {
+			removed = append(removed, change.Description)
+		} else {
+			added = append(added, change.Description)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	for _, list := range []struct {
+		title  string
+		marker string
+		items  []string
+	}{{"Added", "+", added}, {"Removed", "-", removed}} {
+		if len(list.items) == 0 {
+			continue
+		}
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(0, 6, list.title, "", 0, "L", false, 0, "")
+		pdf.Ln(6)
+		pdf.SetFont("Arial", "", 10)
+		for _, item := range list.items {
+			pdf.CellFormat(5, 5, "", "", 0, "L", false, 0, "")
+			pdf.CellFormat(5, 5, list.marker, "", 0, "L", false, 0, "")
+			pdf.MultiCell(0, 5, item, "", "", false)
+		}
+		pdf.Ln(2)
+	}
+}
+
+// comparisonTradeDeltas sums the line item total changes per trade, largest
+// change first
+func comparisonTradeDeltas(changes []models.BidChange) []tradeDelta {
+	totals := make(map[string]float64)
+	for _, change := range changes {
+		if change.Category != "line_item" {
+			continue
+		}
+		trade := "General"
+		if change.Trade != nil && *change.Trade != "" {
+			trade = *change.Trade
+		}
+
+		switch change.ChangeType {
+		case models.ChangeTypeAdded:
+			if item, ok := change.NewValue.(models.LineItem); ok {
+				totals[trade] += item.Total
+			}
+		case models.ChangeTypeRemoved:
+			if item, ok := change.OldValue.(models.LineItem); ok {
+				totals[trade] -= item.Total
+			}
+		case models.ChangeTypeModified:
+			oldTotal, oldOK := change.OldValue.(float64)
+			newTotal, newOK := change.NewValue.(float64)
+			if oldOK && newOK {
+				totals[trade] += newTotal - oldTotal
+			}
+		}
+	}
+
+	deltas := make([]tradeDelta, 0, len(totals))
+	for trade, total := range totals {
+		deltas = append(deltas, tradeDelta{Trade: trade, Delta: math.Round(total*100) / 100})
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		if math.Abs(deltas[i].Delta) != math.Abs(deltas[j].Delta) {
+			return math.Abs(deltas[i].Delta) > math.Abs(deltas[j].Delta)
+		}
+		return deltas[i].Trade < deltas[j].Trade
+	})
+	return deltas
+}
+
+// comparisonCategories returns the categories present in print order
+func comparisonCategories(groups map[string][]models.BidChange) []string {
+	var categories []string
+	known := make(map[string]bool, len(comparisonCategoryOrder))
+	for _, category := range comparisonCategoryOrder {
+		known[category] = true
+		if len(groups[category]) > 0 {
+			categories = append(categories, category)
+		}
+	}
+
+	var others []string
+	for category := range groups {
+		if !known[category] {
+			others = append(others, category)
+		}
+	}
+	sort.Strings(others)
+	return append(categories, others...)
+}
+
+func comparisonCategoryLabel(category string) string {
+	if label, ok := comparisonCategoryLabels[category]; ok {
+		return label
+	}
+	label := strings.ReplaceAll(category, "_", " ")
+	if label == "" {
+		return "Other"
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+func impactRank(impact *models.ChangeImpact) int {
+	if impact == nil {
+		return 3
+	}
+	switch *impact {
+	case models.ImpactHigh:
+		return 0
+	case models.ImpactMedium:
+		return 1
+	case models.ImpactLow:
+		return 2
+	}
+	return 3
+}
+
+// formatChangeValue renders an old or new value for the changes table
+func formatChangeValue(value interface{}) string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		text = fmt.Sprintf("%.2f", v)
+	case string:
+		text = v
+	case models.LineItem:
+		text = fmt.Sprintf("%.2f %s @ $%.2f", v.Quantity, v.Unit, v.UnitCost)
+	case *models.AIModelInfo:
+		if v == nil {
+			return ""
+		}
+		text = formatModelInfo(v)
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	if runes := []rune(text); len(runes) > comparisonValueLength {
+		text = string(runes[:comparisonValueLength-3]) + "..."
+	}
+	return text
+}
+
+func formatSignedMoney(amount float64) string {
+	if amount < 0 {
+		return fmt.Sprintf("-$%.2f", -amount)
+	}
+	return fmt.Sprintf("+$%.2f", amount)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func testComparisonRevisions() (*models.BidRevision, *models.BidRevision) {
+	bidID := uuid.New()
+	name := "Base Bid"
+	from := &models.BidRevision{ID: uuid.New(), BidID: bidID, Version: 1, Name: &name, CreatedAt: models.Now()}
+	to := &models.BidRevision{ID: uuid.New(), BidID: bidID, Version: 2, Name: &name, CreatedAt: models.Now()}
+	return from, to
+}
+
+func testComparisonChanges(count int) *models.BidComparison {
+	comparison := &models.BidComparison{
+		FromVersion:  1,
+		ToVersion:    2,
+		NetCostDelta: 1250,
+		Summary:      models.ComparisonSummary{ChangesByCategory: make(map[string]int)},
+	}
+	impacts := []models.ChangeImpact{models.ImpactHigh, models.ImpactMedium, models.ImpactLow}
+	for i := 0; i < count; i++ {
+		impact := impacts[i%len(impacts)]
+		trade := "drywall"
+		comparison.Changes = append(comparison.Changes, models.BidChange{
+			ChangeType:  models.ChangeTypeModified,
+			Category:    "line_item",
+			Trade:       &trade,
+			Description: fmt.Sprintf("drywall - Partition %d: total changed from $100.00 to $110.00", i),
+			OldValue:    100.0,
+			NewValue:    110.0,
+			Impact:      &impact,
+		})
+	}
+	NewComparisonService().calculateBidSummary(comparison)
+	return comparison
+}
+
+func TestGenerateComparisonPDF(t *testing.T) {
+	service := NewPDFService()
+	from, to := testComparisonRevisions()
+	comparison := testComparisonChanges(6)
+	low := models.ImpactLow
+	comparison.Changes = append(comparison.Changes,
+		models.BidChange{ChangeType: models.ChangeTypeAdded, Category: "scope", Description: "Inclusion added: Final cleaning", NewValue: "Final cleaning", Impact: &low},
+		models.BidChange{ChangeType: models.ChangeTypeRemoved, Category: "scope", Description: "Exclusion removed: Permits", OldValue: "Permits", Impact: &low},
+	)
+
+	pdfBytes, err := service.GenerateComparisonPDF(comparison, from, to, "Office Remodel")
+	if err != nil {
+		t.Fatalf("GenerateComparisonPDF() error = %v", err)
+	}
+	if !bytes.HasPrefix(pdfBytes, []byte("%PDF-")) {
+		t.Errorf("expected PDF magic bytes, got %q", pdfBytes[:min(len(pdfBytes), 8)])
+	}
+}
+
+func TestGenerateComparisonPDF_LongChangeListPaginates(t *testing.T) {
+	service := NewPDFService()
+	from, to := testComparisonRevisions()
+	comparison := testComparisonChanges(200)
+
+	pdf := service.renderComparisonPDF(comparison, from, to, "Office Remodel")
+	if pdf.PageNo() < 2 {
+		t.Fatalf("expected 200 changes to span several pages, got %d", pdf.PageNo())
+	}
+
+	// Uncompressed content streams keep the rendered text searchable
+	pdf.SetCompression(false)
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if got, want := bytes.Count(buf.Bytes(), []byte("(High)")), comparison.Summary.HighImpactCount; got < want {
+		t.Errorf("found %d High impact cells, want at least %d", got, want)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`Line Items \(200\) - continued`)) {
+		t.Error("expected the table header to repeat on continuation pages")
+	}
+	for i := 0; i < 200; i++ {
+		row := fmt.Sprintf("Partition %d:", i)
+		if !bytes.Contains(buf.Bytes(), []byte(row)) {
+			t.Fatalf("expected change %d to be rendered", i)
+		}
+	}
+}
+
+func TestComparisonTradeDeltas(t *testing.T) {
+	drywall, electrical := "drywall", "electrical"
+	changes := []models.BidChange{
+		{ChangeType: models.ChangeTypeModified, Category: "line_item", Trade: &drywall, OldValue: 100.0, NewValue: 150.0},
+		{ChangeType: models.ChangeTypeAdded, Category: "line_item", Trade: &electrical, NewValue: models.LineItem{Trade: "electrical", Total: 400}},
+		{ChangeType: models.ChangeTypeRemoved, Category: "line_item", Trade: &drywall, OldValue: models.LineItem{Trade: "drywall", Total: 20}},
+		// Unit cost changes are already reflected in the line total change
+		{ChangeType: models.ChangeTypeModified, Category: "cost", Trade: &drywall, OldValue: 1.0, NewValue: 2.0},
+	}
+
+	deltas := comparisonTradeDeltas(changes)
+	want := []tradeDelta{{Trade: "electrical", Delta: 400}, {Trade: "drywall", Delta: 30}}
+	if len(deltas) != len(want) {
+		t.Fatalf("comparisonTradeDeltas() = %+v, want %+v", deltas, want)
+	}
+	for i := range want {
+		if deltas[i] != want[i] {
+			t.Errorf("delta %d = %+v, want %+v", i, deltas[i], want[i])
+		}
+	}
+}
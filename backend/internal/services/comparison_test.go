@@ -2,6 +2,7 @@ package services
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -332,3 +333,390 @@ func TestComparisonService_MaterialChanges(t *testing.T) {
 		}
 	}
 }
+
+func TestComparisonService_ModelChanged(t *testing.T) {
+	service := NewComparisonService()
+	analysis := `{"rooms":[{"name":"Kitchen","dimensions":"10x12","area":120}]}`
+
+	v1 := &models.AIModelInfo{Name: "takeoff", Version: "2024.1", PromptRevision: "7"}
+	v2 := &models.AIModelInfo{Name: "takeoff", Version: "2024.2", PromptRevision: "7"}
+
+	tests := []struct {
+		name        string
+		from, to    *models.AIModelInfo
+		wantChanged bool
+	}{
+		{name: "different versions", from: v1, to: v2, wantChanged: true},
+		{name: "same model", from: v1, to: &models.AIModelInfo{Name: "takeoff", Version: "2024.1", PromptRevision: "7"}, wantChanged: false},
+		{name: "missing metadata on one side", from: nil, to: v2, wantChanged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := &models.BlueprintRevision{Version: 1, AnalysisData: &analysis, AnalysisModel: tt.from}
+			to := &models.BlueprintRevision{Version: 2, AnalysisData: &analysis, AnalysisModel: tt.to}
+
+			comparison, err := service.CompareBlueprintRevisions(from, to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := comparison.Summary.ChangesByCategory["model_changed"] == 1; got != tt.wantChanged {
+				t.Errorf("expected model_changed=%v, got changes %+v", tt.wantChanged, comparison.Changes)
+			}
+
+			fromBid := &models.BidRevision{Version: 1, GenerationModel: tt.from}
+			toBid := &models.BidRevision{Version: 2, GenerationModel: tt.to}
+			bidComparison, err := service.CompareBidRevisions(fromBid, toBid)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := bidComparison.Summary.ChangesByCategory["model_changed"] == 1; got != tt.wantChanged {
+				t.Errorf("expected bid model_changed=%v, got changes %+v", tt.wantChanged, bidComparison.Changes)
+			}
+		})
+	}
+}
+
+func TestComparisonDigest_CompactShape(t *testing.T) {
+	service := NewComparisonService()
+
+	// A large revision: hundreds of rooms replaced
+	fromAnalysis := models.AnalysisResult{}
+	toAnalysis := models.AnalysisResult{}
+	for i := 0; i < 500; i++ {
+		fromAnalysis.Rooms = append(fromAnalysis.Rooms, models.Room{Name: "Old Room " + uuid.NewString(), Dimensions: "10x10", Area: 100})
+		toAnalysis.Rooms = append(toAnalysis.Rooms, models.Room{Name: "New Room " + uuid.NewString(), Dimensions: "10x12", Area: 120})
+	}
+	fromJSON, _ := json.Marshal(fromAnalysis)
+	toJSON, _ := json.Marshal(toAnalysis)
+	fromStr, toStr := string(fromJSON), string(toJSON)
+
+	comparison, err := service.CompareBlueprintRevisions(
+		&models.BlueprintRevision{Version: 1, AnalysisData: &fromStr},
+		&models.BlueprintRevision{Version: 2, AnalysisData: &toStr},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fullJSON, _ := json.Marshal(comparison)
+	digestJSON, _ := json.Marshal(comparison.Digest())
+
+	if len(digestJSON) > 1024 {
+		t.Errorf("expected compact digest under 1KB, got %d bytes", len(digestJSON))
+	}
+	if len(digestJSON)*50 > len(fullJSON) {
+		t.Errorf("expected digest (%d bytes) to be far smaller than full comparison (%d bytes)", len(digestJSON), len(fullJSON))
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(digestJSON, &payload); err != nil {
+		t.Fatalf("failed to decode digest: %v", err)
+	}
+	if _, ok := payload["changes"]; ok {
+		t.Error("digest should not include the change list")
+	}
+	for _, key := range []string{"from_version", "to_version", "summary", "net_area_delta"} {
+		if _, ok := payload[key]; !ok {
+			t.Errorf("digest missing %q", key)
+		}
+	}
+	if payload["net_area_delta"].(float64) != 10000 {
+		t.Errorf("expected net area delta 10000, got %v", payload["net_area_delta"])
+	}
+	if comparison.Digest().Summary.TotalChanges != 1000 {
+		t.Errorf("expected 1000 changes in summary, got %d", comparison.Digest().Summary.TotalChanges)
+	}
+}
+
+func TestBidComparisonDigest_NetCostDelta(t *testing.T) {
+	service := NewComparisonService()
+
+	fromPrice, toPrice := 10000.0, 12500.5
+	comparison, err := service.CompareBidRevisions(
+		&models.BidRevision{Version: 1, FinalPrice: &fromPrice},
+		&models.BidRevision{Version: 2, FinalPrice: &toPrice},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	digest := comparison.Digest()
+	if digest.NetCostDelta == nil || *digest.NetCostDelta != 2500.5 {
+		t.Errorf("expected net cost delta 2500.5, got %v", digest.NetCostDelta)
+	}
+	if digest.NetAreaDelta != nil {
+		t.Error("bid digest should not carry an area delta")
+	}
+	if digest.Summary.TotalChanges != comparison.Summary.TotalChanges {
+		t.Error("digest summary should match the full comparison")
+	}
+}
+
+func TestCompareBidRevisions_Rename(t *testing.T) {
+	service := NewComparisonService()
+
+	oldName, newName := "Bid-20240117-153042", "Office Remodel – Bid #1 (National) – January 17, 2024"
+	comparison, err := service.CompareBidRevisions(
+		&models.BidRevision{Version: 1, Name: &oldName},
+		&models.BidRevision{Version: 2, Name: &newName},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(comparison.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(comparison.Changes))
+	}
+	change := comparison.Changes[0]
+	if change.Category != "name" || change.OldValue != oldName || change.NewValue != newName {
+		t.Errorf("unexpected rename change: %+v", change)
+	}
+	if !strings.Contains(change.Description, "renamed") {
+		t.Errorf("expected description to note the rename, got %q", change.Description)
+	}
+	if comparison.Summary.ChangesByCategory["name"] != 1 {
+		t.Errorf("expected summary to count the rename, got %v", comparison.Summary.ChangesByCategory)
+	}
+}
+
+func TestCompareBidRevisions_BlueprintSet(t *testing.T) {
+	service := NewComparisonService()
+	buildingA, buildingB := uuid.New(), uuid.New()
+
+	comparison, err := service.CompareBidRevisions(
+		&models.BidRevision{Version: 1, BlueprintIDs: []uuid.UUID{buildingA}},
+		&models.BidRevision{Version: 2, BlueprintIDs: []uuid.UUID{buildingB, buildingA}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !comparison.BlueprintSetChanged || comparison.Summary.ChangesByCategory["blueprints"] != 1 {
+		t.Fatalf("expected the added blueprint flagged, got %+v", comparison)
+	}
+	if !strings.Contains(comparison.Changes[0].Description, "1 added, 0 removed") {
+		t.Errorf("unexpected description %q", comparison.Changes[0].Description)
+	}
+
+	// Order does not matter, and revisions without linkage are unknown
+	for _, ids := range [][]uuid.UUID{{buildingA, buildingB}, nil} {
+		comparison, err = service.CompareBidRevisions(
+			&models.BidRevision{Version: 2, BlueprintIDs: []uuid.UUID{buildingB, buildingA}},
+			&models.BidRevision{Version: 3, BlueprintIDs: ids},
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if comparison.BlueprintSetChanged || len(comparison.Changes) != 0 {
+			t.Errorf("blueprints %v: expected no blueprint change, got %+v", ids, comparison.Changes)
+		}
+	}
+}
+
+func TestCompareBidRevisions_LineItemNotes(t *testing.T) {
+	service := NewComparisonService()
+
+	revision := func(version int, notes string) *models.BidRevision {
+		data, _ := json.Marshal(models.GenerateBidResponse{LineItems: []models.LineItem{
+			{Description: "Panel upgrade", Trade: "electrical", Quantity: 1, Unit: "EA", UnitCost: 2400, Total: 2400, Notes: notes},
+		}})
+		bidData := string(data)
+		return &models.BidRevision{Version: version, BidData: &bidData}
+	}
+
+	comparison, err := service.CompareBidRevisions(
+		revision(1, ""),
+		revision(2, "Assumes existing panel has capacity"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(comparison.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %d: %+v", len(comparison.Changes), comparison.Changes)
+	}
+	change := comparison.Changes[0]
+	if change.Category != "line_item_note" || change.ChangeType != models.ChangeTypeModified {
+		t.Errorf("unexpected note change: %+v", change)
+	}
+	if change.Impact == nil || *change.Impact != "Low" {
+		t.Errorf("expected Low impact, got %v", change.Impact)
+	}
+	if change.OldValue != "" || change.NewValue != "Assumes existing panel has capacity" {
+		t.Errorf("unexpected note values: %v -> %v", change.OldValue, change.NewValue)
+	}
+	if comparison.Summary.HighImpactCount != 0 {
+		t.Errorf("a note change should not count as high impact")
+	}
+}
+
+func TestCompareBlueprintRevisions_CorruptAnalysis(t *testing.T) {
+	service := NewComparisonService()
+	good := `{"rooms":[{"name":"Kitchen","dimensions":"12x15","area":180}]}`
+	corrupt := `{"rooms":"not a list"}`
+	smallSize, largeSize := int64(1024), int64(4096)
+	pdf, png := "application/pdf", "image/png"
+
+	revision := func(version int, data *string) *models.BlueprintRevision {
+		return &models.BlueprintRevision{Version: version, Filename: "plans.pdf", FileSize: &smallSize, MimeType: &pdf, AnalysisData: data}
+	}
+
+	t.Run("one side corrupt", func(t *testing.T) {
+		comparison, err := service.CompareBlueprintRevisions(revision(1, &good), revision(2, &corrupt))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(comparison.ParseErrors) != 1 || comparison.ParseErrors[0] != (models.ComparisonParseError{Version: 2, Side: "to", Error: comparison.ParseErrors[0].Error}) {
+			t.Errorf("parse_errors = %+v, want only version 2 on the to side", comparison.ParseErrors)
+		}
+		if len(comparison.Changes) != 0 || comparison.NetAreaDelta != 0 {
+			t.Errorf("expected no analysis changes against an unreadable side, got %+v", comparison.Changes)
+		}
+	})
+
+	t.Run("both corrupt", func(t *testing.T) {
+		comparison, err := service.CompareBlueprintRevisions(revision(1, &corrupt), revision(2, &corrupt))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(comparison.ParseErrors) != 2 || comparison.ParseErrors[0].Side != "from" || comparison.ParseErrors[1].Side != "to" {
+			t.Errorf("parse_errors = %+v, want both sides", comparison.ParseErrors)
+		}
+		if digest := comparison.Digest(); len(digest.ParseErrors) != 2 {
+			t.Errorf("digest parse_errors = %+v, want both sides", digest.ParseErrors)
+		}
+	})
+
+	t.Run("metadata only fallback", func(t *testing.T) {
+		to := revision(2, &corrupt)
+		to.Filename, to.FileSize, to.MimeType = "plans-v2.png", &largeSize, &png
+
+		comparison, err := service.CompareBlueprintRevisions(revision(1, &good), to)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		descriptions := map[string]bool{}
+		for _, change := range comparison.Changes {
+			if change.Category != "file" || change.ChangeType != models.ChangeTypeModified {
+				t.Errorf("unexpected change %+v", change)
+			}
+			descriptions[change.Description] = true
+		}
+		for _, want := range []string{
+			"Filename changed from plans.pdf to plans-v2.png",
+			"File size changed from 1024 to 4096 bytes",
+			"File type changed from application/pdf to image/png",
+		} {
+			if !descriptions[want] {
+				t.Errorf("missing change %q in %v", want, descriptions)
+			}
+		}
+		if comparison.Summary.TotalChanges != 3 || comparison.Summary.ChangesByCategory["file"] != 3 {
+			t.Errorf("summary = %+v, want 3 file changes", comparison.Summary)
+		}
+	})
+}
+
+func TestCompareBidRevisions_ReviewItems(t *testing.T) {
+	service := NewComparisonService()
+
+	revision := func(version int, items ...models.LineItem) *models.BidRevision {
+		data, _ := json.Marshal(models.GenerateBidResponse{LineItems: items})
+		bidData := string(data)
+		return &models.BidRevision{Version: version, BidData: &bidData}
+	}
+	skylight := models.LineItem{Description: "Unrecognized opening - skylight", Trade: "carpentry", Quantity: 2, Unit: "each", NeedsReview: true}
+
+	comparison, err := service.CompareBidRevisions(revision(1), revision(2, skylight))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(comparison.Changes) != 1 || comparison.Changes[0].Category != "review_item" || comparison.Changes[0].ChangeType != models.ChangeTypeAdded {
+		t.Fatalf("expected the placeholder added for review, got %+v", comparison.Changes)
+	}
+
+	// Pricing the placeholder clears the flag and changes the total
+	priced := skylight
+	priced.NeedsReview, priced.UnitCost, priced.Total = false, 1800, 3600
+	comparison, err = service.CompareBidRevisions(revision(2, skylight), revision(3, priced))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	categories := make(map[string]bool)
+	for _, change := range comparison.Changes {
+		categories[change.Category] = true
+	}
+	if !categories["review_item"] || !categories["line_item"] {
+		t.Errorf("expected a review and a line item change, got %+v", comparison.Changes)
+	}
+}
+
+func TestCompareBlueprintRevisions_RenamedRooms(t *testing.T) {
+	from := `{"rooms":[{"name":"Bedroom 1","dimensions":"12x14","area":168}],
+		"materials":[{"material_name":"Drywall","quantity":40,"unit":"sheets"}]}`
+	compare := func(t *testing.T, service *ComparisonService, to string) *models.BlueprintComparison {
+		t.Helper()
+		comparison, err := service.CompareBlueprintRevisions(
+			&models.BlueprintRevision{Version: 1, AnalysisData: &from},
+			&models.BlueprintRevision{Version: 2, AnalysisData: &to},
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return comparison
+	}
+
+	t.Run("rename only", func(t *testing.T) {
+		comparison := compare(t, NewComparisonService(), `{"rooms":[{"name":"Master Bedroom","dimensions":"12x14","area":168}],
+			"materials":[{"material_name":"Gypsum Board","quantity":40,"unit":"Sheets"}]}`)
+		if len(comparison.Changes) != 2 || comparison.Summary.ModifiedCount != 2 {
+			t.Fatalf("changes = %+v, want the room and material renames", comparison.Changes)
+		}
+		for _, change := range comparison.Changes {
+			if change.Impact == nil || *change.Impact != models.ImpactLow {
+				t.Errorf("%s impact = %v, want Low", change.Description, change.Impact)
+			}
+		}
+		descriptions := map[string]bool{comparison.Changes[0].Description: true, comparison.Changes[1].Description: true}
+		for _, want := range []string{"Room renamed from Bedroom 1 to Master Bedroom", "Material renamed from Drywall to Gypsum Board"} {
+			if !descriptions[want] {
+				t.Errorf("missing change %q in %v", want, descriptions)
+			}
+		}
+		if comparison.NetAreaDelta != 0 {
+			t.Errorf("net area delta = %.2f, want 0", comparison.NetAreaDelta)
+		}
+	})
+
+	t.Run("rename and resize", func(t *testing.T) {
+		comparison := compare(t, NewComparisonService(), `{"rooms":[{"name":"Master Bedroom","dimensions":"12x14.5","area":174}]}`)
+		var rooms []models.BlueprintChange
+		for _, change := range comparison.Changes {
+			if change.Category == "room" {
+				rooms = append(rooms, change)
+			}
+		}
+		if len(rooms) != 1 || rooms[0].ChangeType != models.ChangeTypeModified {
+			t.Fatalf("room changes = %+v, want one modification", rooms)
+		}
+		if want := "Room renamed from Bedroom 1 to Master Bedroom, dimensions changed from 12x14 (168.00 SF) to 12x14.5 (174.00 SF)"; rooms[0].Description != want {
+			t.Errorf("description = %q, want %q", rooms[0].Description, want)
+		}
+	})
+
+	t.Run("different rooms", func(t *testing.T) {
+		comparison := compare(t, NewComparisonService(), `{"rooms":[{"name":"Office","dimensions":"10x12","area":120}],
+			"materials":[{"material_name":"Gypsum Board","quantity":45,"unit":"sheets"}]}`)
+		if comparison.Summary.AddedCount != 2 || comparison.Summary.RemovedCount != 2 || comparison.Summary.ModifiedCount != 0 {
+			t.Errorf("summary = %+v, want each room and material added and removed", comparison.Summary)
+		}
+	})
+
+	t.Run("matching disabled", func(t *testing.T) {
+		comparison := compare(t, NewComparisonService().WithRenameTolerance(-1), `{"rooms":[{"name":"Master Bedroom","dimensions":"12x14","area":168}],
+			"materials":[{"material_name":"Drywall","quantity":40,"unit":"sheets"}]}`)
+		if comparison.Summary.AddedCount != 1 || comparison.Summary.RemovedCount != 1 || comparison.Summary.ModifiedCount != 0 {
+			t.Errorf("summary = %+v, want the room added and removed", comparison.Summary)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Input classes reported in a confidence range's factors_applied list
+const (
+	RangeFactorAnalysisConfidence = "analysis_confidence"
+	RangeFactorDefaultPrice       = "default_price"
+	RangeFactorProviderPrice      = "provider_price"
+	RangeFactorOverridePrice      = "override_price"
+)
+
+// ConfidenceRangeParams are the uncertainty factors behind a confidence
+// range, as fractions of a line item's total (0.15 = ±15%)
+type ConfidenceRangeParams struct {
+	DefaultPriceUncertainty  float64 // Built-in default prices
+	ProviderPriceUncertainty float64 // Prices synced from a cost provider
+	OverridePriceUncertainty float64 // Company overrides; normally exact
+	// MaxQuantityUncertainty applies to every quantity when the analysis
+	// confidence score is zero, scaling down linearly to none at 1.0
+	MaxQuantityUncertainty float64
+}
+
+// DefaultConfidenceRangeParams are used when no parameters are configured
+var DefaultConfidenceRangeParams = ConfidenceRangeParams{
+	DefaultPriceUncertainty:  0.15,
+	ProviderPriceUncertainty: 0.05,
+	OverridePriceUncertainty: 0,
+	MaxQuantityUncertainty:   0.30,
+}
+
+// EstimateConfidenceRange brackets the summary's total price. Each base line
+// item contributes its price uncertainty (by source) plus the quantity
+// uncertainty implied by the analysis confidence score; the total-weighted
+// fraction is applied to either side of the total. It returns nil when the
+// summary has no priced line items.
+func EstimateConfidenceRange(summary *models.PricingSummary, analysis *models.AnalysisResult, params ConfidenceRangeParams) *models.ConfidenceRange {
+	if summary == nil {
+		return nil
+	}
+
+	quantityUncertainty := 0.0
+	if analysis != nil {
+		confidence := math.Max(0, math.Min(1, analysis.ConfidenceScore))
+		quantityUncertainty = params.MaxQuantityUncertainty * (1 - confidence)
+	}
+
+	classUncertainty := map[string]float64{
+		RangeFactorDefaultPrice:  params.DefaultPriceUncertainty,
+		RangeFactorProviderPrice: params.ProviderPriceUncertainty,
+		RangeFactorOverridePrice: params.OverridePriceUncertainty,
+	}
+	classItems := make(map[string]int)
+
+	var weighted, base float64
+	for _, item := range summary.LineItems {
+		if item.IsAlternate || item.Total <= 0 {
+			continue
+		}
+		class := priceClass(item.PriceSource)
+		classItems[class]++
+		weighted += item.Total * (classUncertainty[class] + quantityUncertainty)
+		base += item.Total
+	}
+	if base == 0 {
+		return nil
+	}
+
+	var factors []models.ConfidenceRangeFactor
+	if analysis != nil {
+		factors = append(factors, models.ConfidenceRangeFactor{
+			Input:       RangeFactorAnalysisConfidence,
+			Uncertainty: roundFraction(quantityUncertainty),
+			Detail:      fmt.Sprintf("confidence score %.2f", analysis.ConfidenceScore),
+		})
+	}
+	for _, class := range []string{RangeFactorProviderPrice, RangeFactorDefaultPrice, RangeFactorOverridePrice} {
+		if classItems[class] > 0 {
+			factors = append(factors, models.ConfidenceRangeFactor{
+				Input:       class,
+				Uncertainty: roundFraction(classUncertainty[class]),
+				LineItems:   classItems[class],
+			})
+		}
+	}
+
+	spread := weighted / base
+	total := summary.TotalPrice
+	return &models.ConfidenceRange{
+		Low:            math.Round(math.Max(0, total*(1-spread))*100) / 100,
+		Likely:         total,
+		High:           math.Round(total*(1+spread)*100) / 100,
+		FactorsApplied: factors,
+	}
+}
+
+// priceClass maps a line item's price source to its uncertainty class
+func priceClass(source *models.PriceSource) string {
+	if source == nil {
+		return RangeFactorDefaultPrice
+	}
+	switch source.Source {
+	case PriceSourceCompanyOverride, PriceSourceProjectOverride:
+		return RangeFactorOverridePrice
+	case PriceSourceDefault, "":
+		return RangeFactorDefaultPrice
+	default:
+		return RangeFactorProviderPrice
+	}
+}
+
+func roundFraction(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func rangeSummary(sources ...*models.PriceSource) *models.PricingSummary {
+	summary := &models.PricingSummary{}
+	for _, source := range sources {
+		summary.LineItems = append(summary.LineItems, models.LineItem{Description: "Item", Total: 1000, PriceSource: source})
+		summary.TotalPrice += 1200
+	}
+	return summary
+}
+
+func TestEstimateConfidenceRange_LowConfidenceWidensBand(t *testing.T) {
+	summary := rangeSummary(fixedPriceSource(), &models.PriceSource{Source: "lowes", RegionalFactor: 1})
+
+	confident := EstimateConfidenceRange(summary, &models.AnalysisResult{ConfidenceScore: 0.95}, DefaultConfidenceRangeParams)
+	unsure := EstimateConfidenceRange(summary, &models.AnalysisResult{ConfidenceScore: 0.40}, DefaultConfidenceRangeParams)
+	if confident == nil || unsure == nil {
+		t.Fatal("expected confidence ranges")
+	}
+
+	if confident.Likely != summary.TotalPrice || unsure.Likely != summary.TotalPrice {
+		t.Errorf("Likely = %v / %v, want the total %v", confident.Likely, unsure.Likely, summary.TotalPrice)
+	}
+	if !(unsure.Low < confident.Low && unsure.High > confident.High) {
+		t.Errorf("low confidence range %v-%v should contain %v-%v", unsure.Low, unsure.High, confident.Low, confident.High)
+	}
+
+	// Prices average ±10% across the two items, plus 0.30 * (1 - 0.40) for quantities
+	if unsure.Low != 1728 || unsure.High != 3072 {
+		t.Errorf("range = %v-%v, want 1728-3072", unsure.Low, unsure.High)
+	}
+
+	wantFactors := []models.ConfidenceRangeFactor{
+		{Input: RangeFactorAnalysisConfidence, Uncertainty: 0.18, Detail: "confidence score 0.40"},
+		{Input: RangeFactorProviderPrice, Uncertainty: 0.05, LineItems: 1},
+		{Input: RangeFactorDefaultPrice, Uncertainty: 0.15, LineItems: 1},
+	}
+	if !reflect.DeepEqual(unsure.FactorsApplied, wantFactors) {
+		t.Errorf("FactorsApplied = %+v, want %+v", unsure.FactorsApplied, wantFactors)
+	}
+}
+
+func TestEstimateConfidenceRange_OverridesTightenBand(t *testing.T) {
+	overrideID := uuid.New()
+	override := &models.PriceSource{Source: PriceSourceCompanyOverride, OverrideID: &overrideID, RegionalFactor: 1}
+	analysis := &models.AnalysisResult{ConfidenceScore: 1}
+
+	defaults := EstimateConfidenceRange(rangeSummary(fixedPriceSource(), fixedPriceSource()), analysis, DefaultConfidenceRangeParams)
+	overridden := EstimateConfidenceRange(rangeSummary(fixedPriceSource(), override), analysis, DefaultConfidenceRangeParams)
+	exact := EstimateConfidenceRange(rangeSummary(override, override), analysis, DefaultConfidenceRangeParams)
+
+	if !(overridden.High-overridden.Low < defaults.High-defaults.Low) {
+		t.Errorf("override range %v-%v should be narrower than %v-%v", overridden.Low, overridden.High, defaults.Low, defaults.High)
+	}
+	if exact.Low != exact.Likely || exact.High != exact.Likely {
+		t.Errorf("fully overridden, fully confident range = %v-%v, want exactly %v", exact.Low, exact.High, exact.Likely)
+	}
+}
+
+func TestEstimateConfidenceRange_IgnoresAlternatesAndEmptySummaries(t *testing.T) {
+	if got := EstimateConfidenceRange(&models.PricingSummary{}, nil, DefaultConfidenceRangeParams); got != nil {
+		t.Errorf("expected no range for an empty summary, got %+v", got)
+	}
+
+	summary := rangeSummary(fixedPriceSource())
+	summary.LineItems = append(summary.LineItems, models.LineItem{Total: 5000, IsAlternate: true, AlternateGroup: "Upgrade"})
+	got := EstimateConfidenceRange(summary, nil, DefaultConfidenceRangeParams)
+	if got.Low != 1020 || got.High != 1380 {
+		t.Errorf("range = %v-%v, want the base item's ±15%% (1020-1380)", got.Low, got.High)
+	}
+	if len(got.FactorsApplied) != 1 || got.FactorsApplied[0].Input != RangeFactorDefaultPrice {
+		t.Errorf("FactorsApplied = %+v, want only default prices without an analysis", got.FactorsApplied)
+	}
+}
+
+func TestEnhancedPricingService_GeneratePricingSummary_ConfidenceRange(t *testing.T) {
+	takeoff := &models.TakeoffSummary{TotalArea: 400, OpeningCounts: map[string]int{}, FixtureCounts: map[string]int{}}
+	analysis := &models.AnalysisResult{ConfidenceScore: 0.5}
+
+	params := ConfidenceRangeParams{DefaultPriceUncertainty: 0.10}
+	summary, err := NewEnhancedPricingService(nil, nil, nil, nil).WithConfidenceRange(params).
+		GeneratePricingSummary(context.Background(), takeoff, analysis, nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	if summary.ConfidenceRange == nil {
+		t.Fatal("expected a confidence range on the summary")
+	}
+
+	// All default prices at ±10% and no configured quantity uncertainty
+	if want := summary.TotalPrice * 0.9; summary.ConfidenceRange.Low < want-0.01 || summary.ConfidenceRange.Low > want+0.01 {
+		t.Errorf("Low = %v, want %v", summary.ConfidenceRange.Low, want)
+	}
+
+	again, _ := NewEnhancedPricingService(nil, nil, nil, nil).WithConfidenceRange(params).
+		GeneratePricingSummary(context.Background(), takeoff, analysis, nil, nil)
+	if !reflect.DeepEqual(again.ConfidenceRange, summary.ConfidenceRange) {
+		t.Error("expected the confidence range to be deterministic")
+	}
+}
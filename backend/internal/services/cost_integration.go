@@ -2,12 +2,19 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+	"golang.org/x/sync/errgroup"
 )
 
 // CostProvider defines the interface for external cost data providers
@@ -22,24 +29,56 @@ type CostProvider interface {
 	GetName() string
 }
 
+// DefaultSyncWorkers is how many providers a sync runs at once when no
+// worker count is configured
+const DefaultSyncWorkers = 4
+
+// CostMaterialStore is the material storage cost integration reads and
+// syncs into
+type CostMaterialStore interface {
+	GetAll(ctx context.Context, category, region *string) ([]models.MaterialCost, error)
+	List(ctx context.Context, filter models.MaterialListFilter) ([]models.MaterialCost, int, error)
+	UpsertBatch(ctx context.Context, materials []models.MaterialCost, at time.Time) (created, updated int, err error)
+}
+
+// CostLaborRateStore is the labor rate storage cost integration reads and
+// syncs into
+type CostLaborRateStore interface {
+	GetAll(ctx context.Context, trade, region *string) ([]models.LaborRate, error)
+	UpsertBatch(ctx context.Context, rates []models.LaborRate, at time.Time) (created, updated int, err error)
+}
+
+// CostRegionalStore is the regional adjustment storage cost integration
+// reads and syncs into
+type CostRegionalStore interface {
+	GetByRegion(ctx context.Context, region string) (*models.RegionalAdjustment, error)
+	Create(ctx context.Context, adjustment *models.RegionalAdjustment) error
+	Update(ctx context.Context, adjustment *models.RegionalAdjustment) error
+}
+
 // CostIntegrationService manages integration with external cost data providers
 type CostIntegrationService struct {
-	materialRepo  *repository.MaterialRepository
-	laborRateRepo *repository.LaborRateRepository
-	regionalRepo  *repository.RegionalAdjustmentRepository
+	materialRepo  CostMaterialStore
+	laborRateRepo CostLaborRateStore
+	regionalRepo  CostRegionalStore
 	providers     map[string]CostProvider
+	syncWorkers   int
+	// regionalMu serializes providers' regional adjustment writes, which
+	// read the region's row before creating or updating it
+	regionalMu sync.Mutex
 }
 
 func NewCostIntegrationService(
-	materialRepo *repository.MaterialRepository,
-	laborRateRepo *repository.LaborRateRepository,
-	regionalRepo *repository.RegionalAdjustmentRepository,
+	materialRepo CostMaterialStore,
+	laborRateRepo CostLaborRateStore,
+	regionalRepo CostRegionalStore,
 ) *CostIntegrationService {
 	service := &CostIntegrationService{
 		materialRepo:  materialRepo,
 		laborRateRepo: laborRateRepo,
 		regionalRepo:  regionalRepo,
 		providers:     make(map[string]CostProvider),
+		syncWorkers:   DefaultSyncWorkers,
 	}
 
 	// Register mock providers (replace with real implementations when API keys are available)
@@ -55,137 +94,275 @@ func (s *CostIntegrationService) RegisterProvider(provider CostProvider) {
 	s.providers[provider.GetName()] = provider
 }
 
-// SyncMaterials syncs material data from a provider to the database
-func (s *CostIntegrationService) SyncMaterials(ctx context.Context, providerName, region string) error {
-	provider, ok := s.providers[providerName]
-	if !ok {
-		return fmt.Errorf("provider not found: %s", providerName)
+// ProviderNames returns the registered providers' names, sorted
+func (s *CostIntegrationService) ProviderNames() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
+
+// SyncReport is the outcome of syncing cost data from one or more
+// providers: what each stored and which items or data sets failed
+type SyncReport struct {
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Failed    int                  `json:"failed"`
+	Providers []ProviderSyncReport `json:"providers"`
+}
+
+// ProviderSyncReport counts the rows one provider's sync created, updated
+// and failed to store. A data set the provider could not return counts as
+// one failure.
+type ProviderSyncReport struct {
+	Provider string          `json:"provider"`
+	Created  int             `json:"created"`
+	Updated  int             `json:"updated"`
+	Failed   int             `json:"failed"`
+	Errors   []SyncItemError `json:"errors"`
+}
+
+// SyncItemError explains one failure in a provider sync. Item names the
+// material, trade or region it concerns, and is empty when the whole data
+// set failed.
+type SyncItemError struct {
+	DataSet string `json:"data_set"`
+	Item    string `json:"item,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
 
+// Sync failure codes
+const (
+	SyncErrorInvalidItem = "invalid_item"
+	SyncErrorFailed      = "sync_failed"
+	SyncErrorRateLimited = "rate_limited"
+)
+
+// Sync data set names
+const (
+	SyncDataSetMaterials          = "materials"
+	SyncDataSetLaborRates         = "labor_rates"
+	SyncDataSetRegionalAdjustment = "regional_adjustment"
+)
+
+func (r *ProviderSyncReport) fail(dataSet, item, code, message string) {
+	r.Failed++
+	r.Errors = append(r.Errors, SyncItemError{DataSet: dataSet, Item: item, Code: code, Message: message})
+}
+
+// failDataSet records a data set that could not be fetched or stored
+func (r *ProviderSyncReport) failDataSet(dataSet, name string, err error) {
+	slog.Error("Failed to sync cost data", "provider", r.Provider, "data_set", dataSet, "error", err)
+	if IsCostProviderRateLimit(err) {
+		r.fail(dataSet, "", SyncErrorRateLimited, fmt.Sprintf("%s is rate limiting requests; sync %s again later", r.Provider, name))
+		return
+	}
+	r.fail(dataSet, "", SyncErrorFailed, fmt.Sprintf("Failed to sync %s from %s", name, r.Provider))
+}
+
+// Sync fetches materials, labor rates and the regional adjustment from each
+// named provider and stores them, running up to the configured number of
+// providers at once. Each provider's materials and labor rates are stored
+// in one batch each. Invalid items and failed data sets are reported and
+// the rest still sync; the error is only for an unknown provider.
+func (s *CostIntegrationService) Sync(ctx context.Context, providerNames []string, region string) (*SyncReport, error) {
+	providers := make([]CostProvider, 0, len(providerNames))
+	for _, name := range providerNames {
+		provider, ok := s.providers[name]
+		if !ok {
+			return nil, fmt.Errorf("provider not found: %s", name)
+		}
+		providers = append(providers, provider)
+	}
+
+	reports := make([]ProviderSyncReport, len(providers))
+	var group errgroup.Group
+	group.SetLimit(s.syncWorkers)
+	for i, provider := range providers {
+		group.Go(func() error {
+			reports[i] = s.syncProvider(ctx, provider, region)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	report := &SyncReport{Providers: reports}
+	for _, r := range reports {
+		report.Created += r.Created
+		report.Updated += r.Updated
+		report.Failed += r.Failed
+	}
+	return report, nil
+}
+
+// SyncAll syncs all cost data from all providers
+func (s *CostIntegrationService) SyncAll(ctx context.Context, region string) (*SyncReport, error) {
+	return s.Sync(ctx, s.ProviderNames(), region)
+}
+
+// SetSyncWorkers sets how many providers a sync runs at once
+func (s *CostIntegrationService) SetSyncWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.syncWorkers = n
+}
+
+// syncProvider syncs each of a provider's data sets; a failed data set
+// does not stop the next
+func (s *CostIntegrationService) syncProvider(ctx context.Context, provider CostProvider, region string) ProviderSyncReport {
+	report := ProviderSyncReport{Provider: provider.GetName(), Errors: []SyncItemError{}}
+	s.syncMaterials(ctx, provider, region, &report)
+	s.syncLaborRates(ctx, provider, region, &report)
+	s.syncRegionalAdjustment(ctx, provider, region, &report)
+	return report
+}
+
+func (s *CostIntegrationService) syncMaterials(ctx context.Context, provider CostProvider, region string, report *ProviderSyncReport) {
 	materials, err := provider.GetMaterials(ctx, region)
 	if err != nil {
-		return fmt.Errorf("failed to get materials from provider: %w", err)
+		report.failDataSet(SyncDataSetMaterials, "materials", fmt.Errorf("failed to get materials from provider: %w", err))
+		return
 	}
 
+	valid := make([]models.MaterialCost, 0, len(materials))
+	seen := make(map[string]bool, len(materials))
 	for _, material := range materials {
-		// Check if material already exists, update or create
-		existing, err := s.materialRepo.GetByName(ctx, material.Name, &region)
-		if err == nil && existing != nil {
-			// Update existing
-			existing.BasePrice = material.BasePrice
-			existing.Description = material.Description
-			existing.LastUpdated = time.Now()
-			existing.UpdatedAt = time.Now()
-			if err := s.materialRepo.Update(ctx, existing); err != nil {
-				return fmt.Errorf("failed to update material %s: %w", material.Name, err)
-			}
-		} else {
-			// Create new
-			material.ID = uuid.New()
-			material.CreatedAt = time.Now()
-			material.UpdatedAt = time.Now()
-			material.LastUpdated = time.Now()
-			if err := s.materialRepo.Create(ctx, &material); err != nil {
-				return fmt.Errorf("failed to create material %s: %w", material.Name, err)
-			}
+		if problem := invalidSyncMaterial(material, seen); problem != "" {
+			report.fail(SyncDataSetMaterials, material.Name, SyncErrorInvalidItem, problem)
+			continue
+		}
+		seen[material.Name] = true
+		// Materials are stored under the synced region
+		material.Region = &region
+		if material.Source == "" {
+			material.Source = provider.GetName()
 		}
+		valid = append(valid, material)
 	}
 
-	return nil
+	created, updated, err := s.materialRepo.UpsertBatch(ctx, valid, time.Now())
+	if err != nil {
+		slog.Error("Failed to store synced materials", "provider", report.Provider, "count", len(valid), "error", err)
+		for _, material := range valid {
+			report.fail(SyncDataSetMaterials, material.Name, SyncErrorFailed, "Failed to store material")
+		}
+		return
+	}
+	report.Created += created
+	report.Updated += updated
 }
 
-// SyncLaborRates syncs labor rate data from a provider to the database
-func (s *CostIntegrationService) SyncLaborRates(ctx context.Context, providerName, region string) error {
-	provider, ok := s.providers[providerName]
-	if !ok {
-		return fmt.Errorf("provider not found: %s", providerName)
+// invalidSyncMaterial says what is wrong with a provider's material, or ""
+// when it can be stored. seen holds the names already in the batch.
+func invalidSyncMaterial(material models.MaterialCost, seen map[string]bool) string {
+	switch {
+	case strings.TrimSpace(material.Name) == "":
+		return "Material has no name"
+	case strings.TrimSpace(material.Category) == "" || strings.TrimSpace(material.Unit) == "":
+		return "Material has no category or unit"
+	case material.BasePrice <= 0 || math.IsNaN(material.BasePrice) || math.IsInf(material.BasePrice, 0):
+		return "Material price must be positive"
+	case seen[material.Name]:
+		return "Material is listed more than once"
 	}
+	return ""
+}
 
+func (s *CostIntegrationService) syncLaborRates(ctx context.Context, provider CostProvider, region string, report *ProviderSyncReport) {
 	rates, err := provider.GetLaborRates(ctx, region)
 	if err != nil {
-		return fmt.Errorf("failed to get labor rates from provider: %w", err)
+		report.failDataSet(SyncDataSetLaborRates, "labor rates", fmt.Errorf("failed to get labor rates from provider: %w", err))
+		return
 	}
 
+	valid := make([]models.LaborRate, 0, len(rates))
+	seen := make(map[string]bool, len(rates))
 	for _, rate := range rates {
-		// Check if rate already exists, update or create
-		existing, err := s.laborRateRepo.GetByTrade(ctx, rate.Trade, &region)
-		if err == nil && existing != nil {
-			// Update existing
-			existing.HourlyRate = rate.HourlyRate
-			existing.Description = rate.Description
-			existing.LastUpdated = time.Now()
-			existing.UpdatedAt = time.Now()
-			if err := s.laborRateRepo.Update(ctx, existing); err != nil {
-				return fmt.Errorf("failed to update labor rate %s: %w", rate.Trade, err)
-			}
-		} else {
-			// Create new
-			rate.ID = uuid.New()
-			rate.CreatedAt = time.Now()
-			rate.UpdatedAt = time.Now()
-			rate.LastUpdated = time.Now()
-			if err := s.laborRateRepo.Create(ctx, &rate); err != nil {
-				return fmt.Errorf("failed to create labor rate %s: %w", rate.Trade, err)
-			}
+		if problem := invalidSyncLaborRate(rate, seen); problem != "" {
+			report.fail(SyncDataSetLaborRates, rate.Trade, SyncErrorInvalidItem, problem)
+			continue
+		}
+		seen[rate.Trade] = true
+		rate.Region = &region
+		if rate.Source == "" {
+			rate.Source = provider.GetName()
 		}
+		valid = append(valid, rate)
 	}
 
-	return nil
+	created, updated, err := s.laborRateRepo.UpsertBatch(ctx, valid, time.Now())
+	if err != nil {
+		slog.Error("Failed to store synced labor rates", "provider", report.Provider, "count", len(valid), "error", err)
+		for _, rate := range valid {
+			report.fail(SyncDataSetLaborRates, rate.Trade, SyncErrorFailed, "Failed to store labor rate")
+		}
+		return
+	}
+	report.Created += created
+	report.Updated += updated
 }
 
-// SyncRegionalAdjustment syncs regional adjustment data from a provider to the database
-func (s *CostIntegrationService) SyncRegionalAdjustment(ctx context.Context, providerName, region string) error {
-	provider, ok := s.providers[providerName]
-	if !ok {
-		return fmt.Errorf("provider not found: %s", providerName)
+// invalidSyncLaborRate says what is wrong with a provider's labor rate, or
+// "" when it can be stored. seen holds the trades already in the batch.
+func invalidSyncLaborRate(rate models.LaborRate, seen map[string]bool) string {
+	switch {
+	case strings.TrimSpace(rate.Trade) == "":
+		return "Labor rate has no trade"
+	case rate.HourlyRate <= 0 || math.IsNaN(rate.HourlyRate) || math.IsInf(rate.HourlyRate, 0):
+		return "Hourly rate must be positive"
+	case seen[rate.Trade]:
+		return "Trade is listed more than once"
 	}
+	return ""
+}
 
+func (s *CostIntegrationService) syncRegionalAdjustment(ctx context.Context, provider CostProvider, region string, report *ProviderSyncReport) {
 	adjustment, err := provider.GetRegionalAdjustment(ctx, region)
 	if err != nil {
-		return fmt.Errorf("failed to get regional adjustment from provider: %w", err)
+		report.failDataSet(SyncDataSetRegionalAdjustment, "regional adjustment", fmt.Errorf("failed to get regional adjustment from provider: %w", err))
+		return
 	}
+	if adjustment.AdjustmentFactor <= 0 || math.IsNaN(adjustment.AdjustmentFactor) || math.IsInf(adjustment.AdjustmentFactor, 0) {
+		report.fail(SyncDataSetRegionalAdjustment, region, SyncErrorInvalidItem, "Adjustment factor must be positive")
+		return
+	}
+
+	s.regionalMu.Lock()
+	defer s.regionalMu.Unlock()
 
-	// Check if adjustment already exists, update or create
+	now := models.Now()
 	existing, err := s.regionalRepo.GetByRegion(ctx, region)
-	if err == nil && existing != nil {
-		// Update existing
+	switch {
+	case err == nil:
 		existing.AdjustmentFactor = adjustment.AdjustmentFactor
 		existing.StateCode = adjustment.StateCode
 		existing.City = adjustment.City
 		existing.CostOfLivingIndex = adjustment.CostOfLivingIndex
-		existing.LastUpdated = time.Now()
-		existing.UpdatedAt = time.Now()
+		existing.LastUpdated = now
+		existing.UpdatedAt = now
 		if err := s.regionalRepo.Update(ctx, existing); err != nil {
-			return fmt.Errorf("failed to update regional adjustment for %s: %w", region, err)
+			report.failDataSet(SyncDataSetRegionalAdjustment, "regional adjustment", fmt.Errorf("failed to update regional adjustment for %s: %w", region, err))
+			return
 		}
-	} else {
-		// Create new
+		report.Updated++
+	case errors.Is(err, repository.ErrRegionalAdjustmentNotFound):
 		adjustment.ID = uuid.New()
-		adjustment.CreatedAt = time.Now()
-		adjustment.UpdatedAt = time.Now()
-		adjustment.LastUpdated = time.Now()
+		adjustment.Region = region
+		adjustment.CreatedAt = now
+		adjustment.UpdatedAt = now
+		adjustment.LastUpdated = now
 		if err := s.regionalRepo.Create(ctx, adjustment); err != nil {
-			return fmt.Errorf("failed to create regional adjustment for %s: %w", region, err)
-		}
-	}
-
-	return nil
-}
-
-// SyncAll syncs all cost data from all providers
-func (s *CostIntegrationService) SyncAll(ctx context.Context, region string) error {
-	for name := range s.providers {
-		if err := s.SyncMaterials(ctx, name, region); err != nil {
-			return err
-		}
-		if err := s.SyncLaborRates(ctx, name, region); err != nil {
-			return err
-		}
-		if err := s.SyncRegionalAdjustment(ctx, name, region); err != nil {
-			return err
+			report.failDataSet(SyncDataSetRegionalAdjustment, "regional adjustment", fmt.Errorf("failed to create regional adjustment for %s: %w", region, err))
+			return
 		}
+		report.Created++
+	default:
+		report.failDataSet(SyncDataSetRegionalAdjustment, "regional adjustment", fmt.Errorf("failed to get regional adjustment for %s: %w", region, err))
 	}
-	return nil
 }
 
 // Mock implementations for cost providers
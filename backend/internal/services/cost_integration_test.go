@@ -2,7 +2,12 @@ package services
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
 )
 
 func TestCostIntegrationService_MockProviders(t *testing.T) {
@@ -193,3 +198,192 @@ func TestMockLowesProvider_GetLaborRates(t *testing.T) {
 		t.Errorf("Expected empty labor rates from Lowes, got %d", len(rates))
 	}
 }
+
+// memoryCostStore keeps synced cost data in memory, keyed as UpsertBatch
+// keys the tables
+type memoryCostStore struct {
+	mu        sync.Mutex
+	materials map[string]models.MaterialCost
+	rates     map[string]models.LaborRate
+	regional  map[string]*models.RegionalAdjustment
+}
+
+func newMemoryCostStore() *memoryCostStore {
+	return &memoryCostStore{
+		materials: make(map[string]models.MaterialCost),
+		rates:     make(map[string]models.LaborRate),
+		regional:  make(map[string]*models.RegionalAdjustment),
+	}
+}
+
+type memoryMaterialStore struct{ *memoryCostStore }
+
+func (s memoryMaterialStore) GetAll(ctx context.Context, category, region *string) ([]models.MaterialCost, error) {
+	return nil, nil
+}
+
+func (s memoryMaterialStore) List(ctx context.Context, filter models.MaterialListFilter) ([]models.MaterialCost, int, error) {
+	return nil, 0, nil
+}
+
+func (s memoryMaterialStore) UpsertBatch(ctx context.Context, materials []models.MaterialCost, at time.Time) (created, updated int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range materials {
+		key := m.Name + "/" + *m.Region
+		if _, ok := s.materials[key]; ok {
+			updated++
+		} else {
+			created++
+		}
+		s.materials[key] = m
+	}
+	return created, updated, nil
+}
+
+type memoryLaborRateStore struct{ *memoryCostStore }
+
+func (s memoryLaborRateStore) GetAll(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
+	return nil, nil
+}
+
+func (s memoryLaborRateStore) UpsertBatch(ctx context.Context, rates []models.LaborRate, at time.Time) (created, updated int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range rates {
+		key := r.Trade + "/" + *r.Region
+		if _, ok := s.rates[key]; ok {
+			updated++
+		} else {
+			created++
+		}
+		s.rates[key] = r
+	}
+	return created, updated, nil
+}
+
+type memoryRegionalStore struct{ *memoryCostStore }
+
+func (s memoryRegionalStore) GetByRegion(ctx context.Context, region string) (*models.RegionalAdjustment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if adjustment, ok := s.regional[region]; ok {
+		copied := *adjustment
+		return &copied, nil
+	}
+	return nil, repository.ErrRegionalAdjustmentNotFound
+}
+
+func (s memoryRegionalStore) Create(ctx context.Context, adjustment *models.RegionalAdjustment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.regional[adjustment.Region] = adjustment
+	return nil
+}
+
+func (s memoryRegionalStore) Update(ctx context.Context, adjustment *models.RegionalAdjustment) error {
+	return s.Create(ctx, adjustment)
+}
+
+func newMemoryCostIntegrationService(store *memoryCostStore) *CostIntegrationService {
+	return &CostIntegrationService{
+		materialRepo:  memoryMaterialStore{store},
+		laborRateRepo: memoryLaborRateStore{store},
+		regionalRepo:  memoryRegionalStore{store},
+		providers:     make(map[string]CostProvider),
+		syncWorkers:   DefaultSyncWorkers,
+	}
+}
+
+// badRecordProvider returns RSMeans' data plus a material with no price
+type badRecordProvider struct {
+	MockRSMeansProvider
+}
+
+func (p *badRecordProvider) GetName() string {
+	return "badrecord"
+}
+
+func (p *badRecordProvider) GetMaterials(ctx context.Context, region string) ([]models.MaterialCost, error) {
+	materials, _ := p.MockRSMeansProvider.GetMaterials(ctx, region)
+	return append(materials, models.MaterialCost{Name: "Mystery Trim", Category: "trim", Unit: "each", Source: "badrecord"}), nil
+}
+
+// rateLimitedProvider is rate limited on materials and has nothing else
+type rateLimitedProvider struct {
+	MockLowesProvider
+}
+
+func (p *rateLimitedProvider) GetName() string {
+	return "ratelimited"
+}
+
+func (p *rateLimitedProvider) GetMaterials(ctx context.Context, region string) ([]models.MaterialCost, error) {
+	return nil, &CostProviderRateLimitError{Provider: "ratelimited", RetryAfter: time.Hour}
+}
+
+func TestCostIntegrationService_SyncReportsBadRecords(t *testing.T) {
+	store := newMemoryCostStore()
+	service := newMemoryCostIntegrationService(store)
+	service.RegisterProvider(&badRecordProvider{})
+	service.RegisterProvider(&rateLimitedProvider{})
+	service.RegisterProvider(&MockHomeDepotProvider{})
+	ctx := context.Background()
+
+	report, err := service.SyncAll(ctx, "california")
+	if err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+	if len(report.Providers) != 3 {
+		t.Fatalf("report has %d providers, want 3", len(report.Providers))
+	}
+	byName := make(map[string]ProviderSyncReport)
+	for _, p := range report.Providers {
+		byName[p.Provider] = p
+	}
+
+	// The bad material is reported; the provider's other data still syncs
+	bad := byName["badrecord"]
+	if bad.Failed != 1 || len(bad.Errors) != 1 || bad.Errors[0].Item != "Mystery Trim" || bad.Errors[0].Code != SyncErrorInvalidItem {
+		t.Errorf("badrecord report = %+v, want only Mystery Trim failed", bad)
+	}
+	// Each provider syncs the shared regional adjustment; the first creates it
+	if bad.Created+bad.Updated != 5 {
+		t.Errorf("badrecord stored %d rows, want 2 materials, 2 labor rates and the adjustment", bad.Created+bad.Updated)
+	}
+	if _, ok := store.materials["Drywall 1/2\" - RSMeans/california"]; !ok {
+		t.Error("good materials from the bad provider were not stored")
+	}
+	if _, ok := store.materials["Mystery Trim/california"]; ok {
+		t.Error("the bad material was stored")
+	}
+
+	limited := byName["ratelimited"]
+	if limited.Failed != 1 || limited.Errors[0].DataSet != SyncDataSetMaterials || limited.Errors[0].Code != SyncErrorRateLimited {
+		t.Errorf("ratelimited report = %+v, want its materials rate limited", limited)
+	}
+
+	if hd := byName["homedepot"]; hd.Failed != 0 || hd.Created+hd.Updated != 3 {
+		t.Errorf("homedepot report = %+v, want its materials and the adjustment stored", hd)
+	}
+	if len(store.materials) != 4 || len(store.rates) != 2 || len(store.regional) != 1 {
+		t.Errorf("stored %d materials, %d labor rates and %d adjustments, want 4, 2 and 1",
+			len(store.materials), len(store.rates), len(store.regional))
+	}
+	if report.Created != 7 || report.Updated != 2 || report.Failed != 2 {
+		t.Errorf("report totals = %+v, want 7 created, 2 updated and 2 failed", report)
+	}
+
+	// A second sync updates what the first created
+	report, err = service.Sync(ctx, []string{"homedepot"}, "california")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if report.Created != 0 || report.Updated != 3 || report.Failed != 0 {
+		t.Errorf("resync report = %+v, want 3 updated", report)
+	}
+
+	if _, err := service.Sync(ctx, []string{"acme"}, "california"); err == nil {
+		t.Error("Sync of an unknown provider succeeded")
+	}
+}
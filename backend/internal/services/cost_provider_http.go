@@ -0,0 +1,594 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/trades"
+)
+
+// Defaults for an HTTPCostProviderConfig that leaves them unset
+const (
+	defaultCostProviderMaxRetries   = 3
+	defaultCostProviderRetryBackoff = 500 * time.Millisecond
+	defaultCostProviderMaxPages     = 100
+	defaultMaxRateLimitWait         = 5 * time.Minute
+	// defaultRateLimitPause is used for a 429 without a usable Retry-After
+	defaultRateLimitPause = 30 * time.Second
+	// maxRateLimitPauses bounds the pauses one request may take, so a
+	// provider that always answers 429 fails the sync instead of stalling it
+	maxRateLimitPauses = 10
+)
+
+// CostEndpoint is one provider resource listing records
+type CostEndpoint struct {
+	// Path is appended to the provider's base URL
+	Path string
+	// RegionParam is the query parameter carrying the region; empty sends none
+	RegionParam string
+	// ItemsPath is the dotted path to the records in a response, either an
+	// array or, for the regional adjustment, a single object; empty means the
+	// response itself
+	ItemsPath string
+}
+
+// CostPagination describes how a provider pages its lists. A cursor is used
+// when CursorParam is set, page numbers when PageParam is set, and a single
+// request otherwise.
+type CostPagination struct {
+	CursorParam string
+	// NextCursorPath is the dotted path to the next page's cursor; an empty
+	// or missing cursor ends the list
+	NextCursorPath string
+	PageParam      string
+	PageSizeParam  string
+	// PageSize is requested with PageSizeParam; a shorter page ends the list
+	PageSize int
+	// MaxPages stops a list that never ends
+	MaxPages int
+}
+
+// UnitConversion maps a provider unit to one of ours. A provider price per
+// Unit-multiple (per 100 SF, say) is divided by Per to give a price per unit.
+type UnitConversion struct {
+	Unit string
+	Per  float64
+}
+
+// MaterialFieldMapping holds the dotted path to each material field in a
+// provider record
+type MaterialFieldMapping struct {
+	Name        string
+	Description string
+	Category    string
+	Unit        string
+	Price       string
+	SourceID    string
+}
+
+// LaborRateFieldMapping holds the dotted path to each labor rate field in a
+// provider record. RateUnit names what Rate is paid per (hour, day); it is
+// converted to hours through the provider's unit conversions.
+type LaborRateFieldMapping struct {
+	Trade       string
+	Description string
+	Rate        string
+	RateUnit    string
+	SourceID    string
+}
+
+// RegionalAdjustmentFieldMapping holds the dotted path to each regional
+// adjustment field in a provider record
+type RegionalAdjustmentFieldMapping struct {
+	AdjustmentFactor  string
+	StateCode         string
+	City              string
+	CostOfLivingIndex string
+}
+
+// HTTPCostProviderConfig declares how to read a JSON REST cost API, so a new
+// provider is a new config rather than a new client
+type HTTPCostProviderConfig struct {
+	Name    string
+	BaseURL string
+	APIKey  string
+	// APIKeyHeader carries the API key, prefixed by APIKeyPrefix
+	APIKeyHeader string
+	APIKeyPrefix string
+	Timeout      time.Duration
+
+	Materials                CostEndpoint
+	MaterialFields           MaterialFieldMapping
+	LaborRates               CostEndpoint
+	LaborRateFields          LaborRateFieldMapping
+	RegionalAdjustment       CostEndpoint
+	RegionalAdjustmentFields RegionalAdjustmentFieldMapping
+	Pagination               CostPagination
+
+	// Units maps lowercased provider units to ours; unlisted units pass
+	// through unchanged
+	Units map[string]UnitConversion
+	// Categories maps lowercased provider material categories to ours;
+	// unlisted categories are lowercased
+	Categories map[string]string
+
+	// MaxRetries is how often a network error or 5xx is retried, with
+	// exponential backoff starting at RetryBackoff
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// MaxRateLimitWait is the longest one 429 pauses the sync; a longer
+	// Retry-After fails it with a CostProviderRateLimitError
+	MaxRateLimitWait time.Duration
+}
+
+// OneBuildProviderConfig is the mapping for the 1build cost data API
+func OneBuildProviderConfig(cfg config.CostProviderConfig) HTTPCostProviderConfig {
+	return HTTPCostProviderConfig{
+		Name:         "onebuild",
+		BaseURL:      cfg.BaseURL,
+		APIKey:       cfg.APIKey,
+		APIKeyHeader: "Authorization",
+		APIKeyPrefix: "Bearer ",
+		Timeout:      cfg.Timeout,
+		Materials: CostEndpoint{
+			Path:        "/materials",
+			RegionParam: "region",
+			ItemsPath:   "data",
+		},
+		MaterialFields: MaterialFieldMapping{
+			Name:        "name",
+			Description: "description",
+			Category:    "category",
+			Unit:        "uom",
+			Price:       "pricing.material_unit_cost",
+			SourceID:    "id",
+		},
+		LaborRates: CostEndpoint{
+			Path:        "/labor-rates",
+			RegionParam: "region",
+			ItemsPath:   "data",
+		},
+		LaborRateFields: LaborRateFieldMapping{
+			Trade:       "trade",
+			Description: "description",
+			Rate:        "rate.amount",
+			RateUnit:    "rate.per",
+			SourceID:    "id",
+		},
+		RegionalAdjustment: CostEndpoint{
+			Path:        "/location-factors",
+			RegionParam: "region",
+			ItemsPath:   "data",
+		},
+		RegionalAdjustmentFields: RegionalAdjustmentFieldMapping{
+			AdjustmentFactor:  "factor",
+			StateCode:         "state",
+			City:              "city",
+			CostOfLivingIndex: "cost_of_living_index",
+		},
+		Pagination: CostPagination{
+			CursorParam:    "cursor",
+			NextCursorPath: "meta.next_cursor",
+			PageSizeParam:  "limit",
+			PageSize:       100,
+		},
+		Units: map[string]UnitConversion{
+			"sf":     {Unit: "sq ft", Per: 1},
+			"100 sf": {Unit: "sq ft", Per: 100},
+			"csf":    {Unit: "sq ft", Per: 100},
+			"lf":     {Unit: "linear ft", Per: 1},
+			"clf":    {Unit: "linear ft", Per: 100},
+			"ea":     {Unit: "each", Per: 1},
+			"hr":     {Unit: "hour", Per: 1},
+			"day":    {Unit: "hour", Per: 8},
+		},
+		Categories: map[string]string{
+			"gypsum board":       "drywall",
+			"dimensional lumber": "lumber",
+			"paints & coatings":  "paint",
+		},
+		MaxRateLimitWait: cfg.MaxRateLimitWait,
+	}
+}
+
+// CostProviderRateLimitError is returned when a provider asks the sync to
+// wait longer than it is allowed to pause
+type CostProviderRateLimitError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+func (e *CostProviderRateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limited the sync for %s", e.Provider, e.RetryAfter)
+}
+
+// HTTPCostProvider is a CostProvider reading a JSON REST cost API described
+// by an HTTPCostProviderConfig
+type HTTPCostProvider struct {
+	config HTTPCostProviderConfig
+	client *http.Client
+	// sleep waits out backoffs and rate limit pauses; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewHTTPCostProvider creates a provider from its config, filling in the
+// retry, rate limit and pagination defaults
+func NewHTTPCostProvider(cfg HTTPCostProviderConfig) *HTTPCostProvider {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = defaultCostProviderMaxRetries
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultCostProviderRetryBackoff
+	}
+	if cfg.Pagination.MaxPages <= 0 {
+		cfg.Pagination.MaxPages = defaultCostProviderMaxPages
+	}
+	if cfg.MaxRateLimitWait <= 0 {
+		cfg.MaxRateLimitWait = defaultMaxRateLimitWait
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &HTTPCostProvider{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		sleep:  sleepContext,
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (p *HTTPCostProvider) GetName() string {
+	return p.config.Name
+}
+
+// GetMaterials lists every page of the provider's materials for a region.
+// Records without a name or price are skipped.
+func (p *HTTPCostProvider) GetMaterials(ctx context.Context, region string) ([]models.MaterialCost, error) {
+	records, err := p.list(ctx, p.config.Materials, region)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := p.config.MaterialFields
+	materials := make([]models.MaterialCost, 0, len(records))
+	for _, record := range records {
+		name := lookupString(record, fields.Name)
+		price, ok := lookupFloat(record, fields.Price)
+		if name == "" || !ok || price < 0 {
+			slog.Warn("Skipping unmappable material", "provider", p.config.Name, "source_id", lookupString(record, fields.SourceID))
+			continue
+		}
+
+		unit, per := p.convertUnit(lookupString(record, fields.Unit))
+		category := strings.ToLower(lookupString(record, fields.Category))
+		if mapped, ok := p.config.Categories[category]; ok {
+			category = mapped
+		}
+		materials = append(materials, models.MaterialCost{
+			Name:        name,
+			Description: optionalString(lookupString(record, fields.Description)),
+			Category:    category,
+			Unit:        unit,
+			BasePrice:   roundUnitPrice(price / per),
+			Source:      p.config.Name,
+			SourceID:    optionalString(lookupString(record, fields.SourceID)),
+			Region:      &region,
+		})
+	}
+	return materials, nil
+}
+
+// GetLaborRates lists every page of the provider's labor rates for a region,
+// converted to hourly rates. Records without a trade or rate are skipped.
+func (p *HTTPCostProvider) GetLaborRates(ctx context.Context, region string) ([]models.LaborRate, error) {
+	records, err := p.list(ctx, p.config.LaborRates, region)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := p.config.LaborRateFields
+	rates := make([]models.LaborRate, 0, len(records))
+	for _, record := range records {
+		trade := lookupString(record, fields.Trade)
+		rate, ok := lookupFloat(record, fields.Rate)
+		if trade == "" || !ok || rate < 0 {
+			slog.Warn("Skipping unmappable labor rate", "provider", p.config.Name, "source_id", lookupString(record, fields.SourceID))
+			continue
+		}
+
+		unit, per := p.convertUnit(lookupString(record, fields.RateUnit))
+		if unit := strings.ToLower(unit); unit != "" && unit != "hour" {
+			slog.Warn("Skipping labor rate with an unknown rate unit", "provider", p.config.Name, "unit", unit)
+			continue
+		}
+		if slug, known := trades.Normalize(trade); known {
+			trade = slug
+		} else {
+			trade = strings.ToLower(trade)
+		}
+		rates = append(rates, models.LaborRate{
+			Trade:       trade,
+			Description: optionalString(lookupString(record, fields.Description)),
+			HourlyRate:  roundUnitPrice(rate / per),
+			Source:      p.config.Name,
+			SourceID:    optionalString(lookupString(record, fields.SourceID)),
+			Region:      &region,
+		})
+	}
+	return rates, nil
+}
+
+// GetRegionalAdjustment fetches the provider's cost factor for a region
+func (p *HTTPCostProvider) GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error) {
+	body, err := p.get(ctx, p.pageURL(p.config.RegionalAdjustment, region, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	record, _ := lookup(body, p.config.RegionalAdjustment.ItemsPath).(map[string]any)
+	if items, ok := lookup(body, p.config.RegionalAdjustment.ItemsPath).([]any); ok && len(items) > 0 {
+		record, _ = items[0].(map[string]any)
+	}
+	fields := p.config.RegionalAdjustmentFields
+	factor, ok := lookupFloat(record, fields.AdjustmentFactor)
+	if record == nil || !ok || factor <= 0 {
+		return nil, fmt.Errorf("%s returned no adjustment factor for %s", p.config.Name, region)
+	}
+
+	adjustment := &models.RegionalAdjustment{
+		Region:           region,
+		StateCode:        optionalString(lookupString(record, fields.StateCode)),
+		City:             optionalString(lookupString(record, fields.City)),
+		AdjustmentFactor: factor,
+		Source:           p.config.Name,
+	}
+	if index, ok := lookupFloat(record, fields.CostOfLivingIndex); ok {
+		value := int(math.Round(index))
+		adjustment.CostOfLivingIndex = &value
+	}
+	return adjustment, nil
+}
+
+// convertUnit maps a provider unit to ours and the divisor for its price
+func (p *HTTPCostProvider) convertUnit(unit string) (string, float64) {
+	if conversion, ok := p.config.Units[strings.ToLower(strings.TrimSpace(unit))]; ok && conversion.Per > 0 {
+		return conversion.Unit, conversion.Per
+	}
+	return unit, 1
+}
+
+// list fetches every page of an endpoint and returns its records
+func (p *HTTPCostProvider) list(ctx context.Context, endpoint CostEndpoint, region string) ([]map[string]any, error) {
+	pagination := p.config.Pagination
+	var records []map[string]any
+	cursor := ""
+	for page := 1; page <= pagination.MaxPages; page++ {
+		params := url.Values{}
+		if pagination.PageSizeParam != "" && pagination.PageSize > 0 {
+			params.Set(pagination.PageSizeParam, strconv.Itoa(pagination.PageSize))
+		}
+		switch {
+		case pagination.CursorParam != "" && cursor != "":
+			params.Set(pagination.CursorParam, cursor)
+		case pagination.CursorParam == "" && pagination.PageParam != "":
+			params.Set(pagination.PageParam, strconv.Itoa(page))
+		}
+
+		body, err := p.get(ctx, p.pageURL(endpoint, region, params))
+		if err != nil {
+			return nil, err
+		}
+		items, ok := lookup(body, endpoint.ItemsPath).([]any)
+		if !ok && lookup(body, endpoint.ItemsPath) != nil {
+			return nil, fmt.Errorf("%s%s: %q is not a list", p.config.Name, endpoint.Path, endpoint.ItemsPath)
+		}
+		for _, item := range items {
+			if record, ok := item.(map[string]any); ok {
+				records = append(records, record)
+			}
+		}
+
+		switch {
+		case pagination.CursorParam != "":
+			cursor = lookupString(body, pagination.NextCursorPath)
+			if cursor == "" {
+				return records, nil
+			}
+		case pagination.PageParam != "":
+			if len(items) == 0 || (pagination.PageSize > 0 && len(items) < pagination.PageSize) {
+				return records, nil
+			}
+		default:
+			return records, nil
+		}
+	}
+	return nil, fmt.Errorf("%s%s: more than %d pages", p.config.Name, endpoint.Path, pagination.MaxPages)
+}
+
+func (p *HTTPCostProvider) pageURL(endpoint CostEndpoint, region string, params url.Values) string {
+	if params == nil {
+		params = url.Values{}
+	}
+	if endpoint.RegionParam != "" {
+		params.Set(endpoint.RegionParam, region)
+	}
+	target := strings.TrimRight(p.config.BaseURL, "/") + endpoint.Path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	return target
+}
+
+// get fetches and decodes one JSON response. Network errors and 5xx are
+// retried with backoff; a 429 pauses for its Retry-After and then repeats
+// the request without spending a retry.
+func (p *HTTPCostProvider) get(ctx context.Context, target string) (any, error) {
+	attempt, pauses := 0, 0
+	for {
+		body, status, header, err := p.do(ctx, target)
+		switch {
+		case err == nil && status == http.StatusOK:
+			var decoded any
+			if err := json.Unmarshal(body, &decoded); err != nil {
+				return nil, fmt.Errorf("failed to decode %s response: %w", p.config.Name, err)
+			}
+			return decoded, nil
+
+		case err == nil && status == http.StatusTooManyRequests:
+			wait := retryAfter(header.Get("Retry-After"), time.Now())
+			pauses++
+			if wait > p.config.MaxRateLimitWait || pauses > maxRateLimitPauses {
+				return nil, &CostProviderRateLimitError{Provider: p.config.Name, RetryAfter: wait}
+			}
+			slog.Warn("Cost provider rate limited, pausing sync", "provider", p.config.Name, "retry_after", wait)
+			if err := p.sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+
+		case err == nil && status < http.StatusInternalServerError:
+			return nil, fmt.Errorf("%s returned status %d: %s", p.config.Name, status, truncate(string(body), 200))
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt >= p.config.MaxRetries {
+			if err != nil {
+				return nil, fmt.Errorf("failed to call %s: %w", p.config.Name, err)
+			}
+			return nil, fmt.Errorf("%s returned status %d", p.config.Name, status)
+		}
+		if err := p.sleep(ctx, p.config.RetryBackoff<<attempt); err != nil {
+			return nil, err
+		}
+		attempt++
+	}
+}
+
+func (p *HTTPCostProvider) do(ctx context.Context, target string) ([]byte, int, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.config.APIKey != "" && p.config.APIKeyHeader != "" {
+		req.Header.Set(p.config.APIKeyHeader, p.config.APIKeyPrefix+p.config.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.StatusCode, resp.Header, nil
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return defaultRateLimitPause
+}
+
+// lookup follows a dotted path through decoded JSON objects; an empty path
+// is the value itself
+func lookup(value any, path string) any {
+	if path == "" {
+		return value
+	}
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func lookupString(value any, path string) string {
+	if path == "" {
+		return ""
+	}
+	switch v := lookup(value, path).(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// lookupFloat reads a number, accepting numeric strings as some providers
+// send prices that way
+func lookupFloat(value any, path string) (float64, bool) {
+	if path == "" {
+		return 0, false
+	}
+	switch v := lookup(value, path).(type) {
+	case float64:
+		return v, true
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return parsed, err == nil && !math.IsNaN(parsed) && !math.IsInf(parsed, 0)
+	}
+	return 0, false
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// roundUnitPrice keeps four decimal places, since a price per 100 SF becomes
+// a fraction of a cent per SF
+func roundUnitPrice(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// IsCostProviderRateLimit reports whether err is a provider rate limit
+func IsCostProviderRateLimit(err error) bool {
+	var rateLimitErr *CostProviderRateLimitError
+	return errors.As(err, &rateLimitErr)
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+)
+
+// Fixtures in the shape of 1build API responses
+const (
+	oneBuildMaterialsPage1 = `{
+		"data": [
+			{
+				"id": "ob-mat-0091",
+				"name": "Gypsum Board 1/2\" Regular",
+				"description": "1/2 in. regular gypsum wallboard, 4x8 sheets",
+				"category": "Gypsum Board",
+				"uom": "100 SF",
+				"pricing": {"material_unit_cost": 48.50, "currency": "USD"}
+			},
+			{
+				"id": "ob-mat-0142",
+				"name": "Stud 2x4x8 SPF",
+				"category": "Dimensional Lumber",
+				"uom": "EA",
+				"pricing": {"material_unit_cost": "4.18", "currency": "USD"}
+			}
+		],
+		"meta": {"next_cursor": "c2Vlaz0y", "total": 3}
+	}`
+	oneBuildMaterialsPage2 = `{
+		"data": [
+			{
+				"id": "ob-mat-0311",
+				"name": "Interior Latex Paint, Eggshell",
+				"category": "Paints & Coatings",
+				"uom": "GAL",
+				"pricing": {"material_unit_cost": 32.99, "currency": "USD"}
+			},
+			{
+				"id": "ob-mat-0400",
+				"name": "Discontinued item",
+				"category": "Gypsum Board",
+				"uom": "SF",
+				"pricing": {"material_unit_cost": null}
+			}
+		],
+		"meta": {"next_cursor": null, "total": 3}
+	}`
+	oneBuildLaborRates = `{
+		"data": [
+			{"id": "ob-lab-07", "trade": "Carpenter", "description": "Journeyman carpenter", "rate": {"amount": 624, "per": "DAY"}},
+			{"id": "ob-lab-12", "trade": "Electricians", "rate": {"amount": 96.5, "per": "HR"}}
+		],
+		"meta": {"next_cursor": ""}
+	}`
+	oneBuildLocationFactor = `{
+		"data": {"region": "california", "state": "CA", "city": "Los Angeles", "factor": 1.24, "cost_of_living_index": 142.6}
+	}`
+)
+
+func newTestOneBuildProvider(t *testing.T, handler http.HandlerFunc) (*HTTPCostProvider, *[]time.Duration) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider := NewHTTPCostProvider(OneBuildProviderConfig(config.CostProviderConfig{
+		BaseURL:          server.URL,
+		APIKey:           "test-key",
+		Timeout:          5 * time.Second,
+		MaxRateLimitWait: time.Minute,
+	}))
+	var mu sync.Mutex
+	var slept []time.Duration
+	provider.sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		slept = append(slept, d)
+		return nil
+	}
+	return provider, &slept
+}
+
+func TestHTTPCostProvider_GetMaterialsMapsFieldsAcrossPages(t *testing.T) {
+	var cursors []string
+	provider, _ := newTestOneBuildProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/materials" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("expected bearer API key, got %q", got)
+		}
+		if got := r.URL.Query().Get("region"); got != "california" {
+			t.Errorf("expected region california, got %q", got)
+		}
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		if cursor == "c2Vlaz0y" {
+			w.Write([]byte(oneBuildMaterialsPage2))
+			return
+		}
+		w.Write([]byte(oneBuildMaterialsPage1))
+	})
+
+	materials, err := provider.GetMaterials(context.Background(), "california")
+	if err != nil {
+		t.Fatalf("GetMaterials failed: %v", err)
+	}
+	if len(cursors) != 2 || cursors[0] != "" || cursors[1] != "c2Vlaz0y" {
+		t.Errorf("expected two pages following the cursor, got %q", cursors)
+	}
+	// The record without a price is skipped
+	if len(materials) != 3 {
+		t.Fatalf("expected 3 materials, got %d", len(materials))
+	}
+
+	drywall := materials[0]
+	if drywall.Unit != "sq ft" || drywall.BasePrice != 0.485 {
+		t.Errorf("expected $48.50 per 100 SF to map to 0.485 per sq ft, got %v per %s", drywall.BasePrice, drywall.Unit)
+	}
+	if drywall.Category != "drywall" || drywall.Source != "onebuild" {
+		t.Errorf("unexpected category %q or source %q", drywall.Category, drywall.Source)
+	}
+	if drywall.SourceID == nil || *drywall.SourceID != "ob-mat-0091" {
+		t.Errorf("unexpected source ID %v", drywall.SourceID)
+	}
+	if drywall.Region == nil || *drywall.Region != "california" {
+		t.Errorf("unexpected region %v", drywall.Region)
+	}
+
+	stud := materials[1]
+	if stud.Unit != "each" || stud.BasePrice != 4.18 || stud.Category != "lumber" || stud.Description != nil {
+		t.Errorf("unexpected stud mapping %+v", stud)
+	}
+	paint := materials[2]
+	if paint.Unit != "GAL" || paint.Category != "paint" {
+		t.Errorf("expected unlisted unit to pass through, got %+v", paint)
+	}
+}
+
+func TestHTTPCostProvider_GetLaborRatesConvertsToHourly(t *testing.T) {
+	provider, _ := newTestOneBuildProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(oneBuildLaborRates))
+	})
+
+	rates, err := provider.GetLaborRates(context.Background(), "national")
+	if err != nil {
+		t.Fatalf("GetLaborRates failed: %v", err)
+	}
+	if len(rates) != 2 {
+		t.Fatalf("expected 2 rates, got %d", len(rates))
+	}
+	if rates[0].Trade != "carpentry" || rates[0].HourlyRate != 78 {
+		t.Errorf("expected $624/day carpenter to map to carpentry at 78/hr, got %s at %v", rates[0].Trade, rates[0].HourlyRate)
+	}
+	if rates[1].Trade != "electrical" || rates[1].HourlyRate != 96.5 {
+		t.Errorf("unexpected electrician mapping %s at %v", rates[1].Trade, rates[1].HourlyRate)
+	}
+}
+
+func TestHTTPCostProvider_GetRegionalAdjustment(t *testing.T) {
+	provider, _ := newTestOneBuildProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(oneBuildLocationFactor))
+	})
+
+	adjustment, err := provider.GetRegionalAdjustment(context.Background(), "california")
+	if err != nil {
+		t.Fatalf("GetRegionalAdjustment failed: %v", err)
+	}
+	if adjustment.AdjustmentFactor != 1.24 || adjustment.Region != "california" || adjustment.Source != "onebuild" {
+		t.Errorf("unexpected adjustment %+v", adjustment)
+	}
+	if adjustment.StateCode == nil || *adjustment.StateCode != "CA" || adjustment.City == nil || *adjustment.City != "Los Angeles" {
+		t.Errorf("unexpected location %v %v", adjustment.StateCode, adjustment.City)
+	}
+	if adjustment.CostOfLivingIndex == nil || *adjustment.CostOfLivingIndex != 143 {
+		t.Errorf("expected cost of living index 143, got %v", adjustment.CostOfLivingIndex)
+	}
+}
+
+func TestHTTPCostProvider_PausesOnRateLimit(t *testing.T) {
+	requests := 0
+	provider, slept := newTestOneBuildProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case requests == 2:
+			// Rate limited after the first page; the sync pauses and resumes
+			// from the same cursor
+			if r.URL.Query().Get("cursor") != "c2Vlaz0y" {
+				t.Errorf("expected the rate-limited page to be second, got cursor %q", r.URL.Query().Get("cursor"))
+			}
+			w.Header().Set("Retry-After", "12")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Query().Get("cursor") == "c2Vlaz0y":
+			w.Write([]byte(oneBuildMaterialsPage2))
+		default:
+			w.Write([]byte(oneBuildMaterialsPage1))
+		}
+	})
+
+	materials, err := provider.GetMaterials(context.Background(), "national")
+	if err != nil {
+		t.Fatalf("expected the sync to pause rather than fail, got %v", err)
+	}
+	if len(materials) != 3 || requests != 3 {
+		t.Errorf("expected 3 materials from 3 requests, got %d from %d", len(materials), requests)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 12*time.Second {
+		t.Errorf("expected one 12s pause, got %v", *slept)
+	}
+}
+
+func TestHTTPCostProvider_RateLimitBeyondMaxWaitFails(t *testing.T) {
+	provider, slept := newTestOneBuildProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := provider.GetMaterials(context.Background(), "national")
+	if !IsCostProviderRateLimit(err) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	if len(*slept) != 0 {
+		t.Errorf("expected no pause longer than the maximum wait, got %v", *slept)
+	}
+}
+
+func TestHTTPCostProvider_RetriesServerErrorsWithBackoff(t *testing.T) {
+	requests := 0
+	provider, slept := newTestOneBuildProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(oneBuildLaborRates))
+	})
+
+	if _, err := provider.GetLaborRates(context.Background(), "national"); err != nil {
+		t.Fatalf("GetLaborRates failed: %v", err)
+	}
+	if len(*slept) != 2 || (*slept)[0] != 500*time.Millisecond || (*slept)[1] != time.Second {
+		t.Errorf("expected backoffs of 500ms then 1s, got %v", *slept)
+	}
+}
+
+func TestHTTPCostProvider_DoesNotRetryClientErrors(t *testing.T) {
+	requests := 0
+	provider, _ := newTestOneBuildProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	if _, err := provider.GetLaborRates(context.Background(), "national"); err == nil {
+		t.Fatal("expected an error for a rejected API key")
+	}
+	if requests != 1 {
+		t.Errorf("expected a single request, got %d", requests)
+	}
+}
+
+func TestHTTPCostProvider_PagesByNumber(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"items": [{"sku": "A1", "title": "Tile", "price": 3.5, "unit": "SF"}, {"sku": "A2", "title": "Grout", "price": 12, "unit": "EA"}]}`,
+		"2": `{"items": [{"sku": "A3", "title": "Thinset", "price": 18, "unit": "EA"}]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "2" {
+			t.Errorf("expected page size 2, got %q", r.URL.Query().Get("per_page"))
+		}
+		w.Write([]byte(pages[r.URL.Query().Get("page")]))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPCostProvider(HTTPCostProviderConfig{
+		Name:           "tiles",
+		BaseURL:        server.URL,
+		Materials:      CostEndpoint{Path: "/products", ItemsPath: "items"},
+		MaterialFields: MaterialFieldMapping{Name: "title", Unit: "unit", Price: "price", SourceID: "sku"},
+		Pagination:     CostPagination{PageParam: "page", PageSizeParam: "per_page", PageSize: 2},
+		Units:          map[string]UnitConversion{"sf": {Unit: "sq ft", Per: 1}},
+	})
+
+	materials, err := provider.GetMaterials(context.Background(), "national")
+	if err != nil {
+		t.Fatalf("GetMaterials failed: %v", err)
+	}
+	if len(materials) != 3 || materials[0].Unit != "sq ft" || materials[2].Name != "Thinset" {
+		t.Errorf("unexpected materials %+v", materials)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"30", 30 * time.Second},
+		{"Thu, 15 Oct 2026 12:01:30 GMT", 90 * time.Second},
+		{"Thu, 15 Oct 2026 11:59:00 GMT", 0},
+		{"", defaultRateLimitPause},
+		{"soon", defaultRateLimitPause},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.value, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
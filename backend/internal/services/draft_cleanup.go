@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ExpiredDraftStore deletes bid drafts past their expiry
+type ExpiredDraftStore interface {
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// DraftCleaner deletes bid drafts that have not been saved within their TTL
+type DraftCleaner struct {
+	drafts ExpiredDraftStore
+	now    func() time.Time
+}
+
+func NewDraftCleaner(drafts ExpiredDraftStore) *DraftCleaner {
+	return &DraftCleaner{drafts: drafts, now: time.Now}
+}
+
+// RunOnce deletes the expired drafts and returns how many were removed
+func (c *DraftCleaner) RunOnce(ctx context.Context) int64 {
+	removed, err := c.drafts.DeleteExpired(ctx, c.now())
+	if err != nil {
+		slog.Error("Failed to delete expired bid drafts", "error", err)
+		return 0
+	}
+
+	if removed > 0 {
+		slog.Info("Deleted expired bid drafts", "count", removed)
+	}
+	return removed
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// EffectivePrice is one material price or labor rate as pricing charges it,
+// with the parts of its calculation: the base price, scaled by the regional
+// factor, then adjusted or replaced by a company override
+type EffectivePrice struct {
+	Key            string                  `json:"key"`
+	EffectivePrice float64                 `json:"effective_price"`
+	BasePrice      float64                 `json:"base_price"`
+	RegionalFactor float64                 `json:"regional_factor"`
+	Overridden     bool                    `json:"overridden"`
+	Override       *EffectivePriceOverride `json:"override,omitempty"`
+	// DefaultUsed is set when the database has no price for the key and a
+	// built-in default was used as the base price
+	DefaultUsed bool               `json:"default_used"`
+	Source      models.PriceSource `json:"source"`
+	// OutrankedSources are other providers that price the key, passed over
+	// for Source under the provider precedence
+	OutrankedSources []string `json:"outranked_sources,omitempty"`
+}
+
+// EffectivePriceOverride is the company override applied to an effective price
+type EffectivePriceOverride struct {
+	ID           uuid.UUID `json:"id"`
+	Value        float64   `json:"value"`
+	IsPercentage bool      `json:"is_percentage"`
+}
+
+// EffectivePriceList is every material price or labor rate for a region,
+// ordered by key
+type EffectivePriceList struct {
+	Region         *string          `json:"region,omitempty"`
+	RegionalFactor float64          `json:"regional_factor"`
+	Prices         []EffectivePrice `json:"prices"`
+}
+
+// EffectiveMaterialPrices returns the material prices pricing summaries use
+// for userID in region
+func (s *EnhancedPricingService) EffectiveMaterialPrices(ctx context.Context, userID *uuid.UUID, region *string) (*EffectivePriceList, error) {
+	resolved, err := s.ResolvePricingConfig(ctx, userID, nil, region)
+	if err != nil {
+		return nil, err
+	}
+	return &EffectivePriceList{Region: region, RegionalFactor: resolved.RegionalFactor, Prices: effectivePrices(resolved.Materials)}, nil
+}
+
+// EffectiveLaborRates returns the labor rates pricing summaries use for
+// userID in region
+func (s *EnhancedPricingService) EffectiveLaborRates(ctx context.Context, userID *uuid.UUID, region *string) (*EffectivePriceList, error) {
+	resolved, err := s.ResolvePricingConfig(ctx, userID, nil, region)
+	if err != nil {
+		return nil, err
+	}
+	return &EffectivePriceList{Region: region, RegionalFactor: resolved.RegionalFactor, Prices: effectivePrices(resolved.Labor)}, nil
+}
+
+func effectivePrices(resolved map[string]ResolvedPrice) []EffectivePrice {
+	prices := make([]EffectivePrice, 0, len(resolved))
+	for _, key := range sortedKeys(resolved) {
+		price := resolved[key]
+		effective := EffectivePrice{
+			Key:              key,
+			EffectivePrice:   price.Value,
+			BasePrice:        price.BasePrice,
+			RegionalFactor:   price.Source.RegionalFactor,
+			Overridden:       price.Override != nil,
+			DefaultUsed:      price.IsDefault,
+			Source:           price.Source,
+			OutrankedSources: price.OutrankedSources,
+		}
+		if price.Override != nil {
+			effective.Override = &EffectivePriceOverride{
+				ID:           price.Override.ID,
+				Value:        price.Override.OverrideValue,
+				IsPercentage: price.Override.IsPercentage,
+			}
+		}
+		prices = append(prices, effective)
+	}
+	return prices
+}
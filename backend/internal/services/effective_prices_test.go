@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestEffectivePrices_Components(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	percentID, directID := uuid.New(), uuid.New()
+
+	resolved := resolvePricing(defaults, pricingInputs{
+		materials: []models.MaterialCost{
+			{Category: "door", BasePrice: 400, Source: "lowes"},
+			{Category: "window", BasePrice: 800, Source: "homedepot"},
+		},
+		materialsLoaded: true,
+		laborLoaded:     true,
+		overrides: []models.CompanyPricingOverride{
+			{ID: percentID, OverrideType: "material", ItemKey: "window", OverrideValue: 10, IsPercentage: true},
+			{ID: directID, OverrideType: "material", ItemKey: "outlet", OverrideValue: 140},
+		},
+		regionalFactor: 1.2,
+	})
+
+	prices := map[string]EffectivePrice{}
+	for _, price := range effectivePrices(resolved.Materials) {
+		prices[price.Key] = price
+		if price.EffectivePrice != resolved.Config.MaterialPrices[price.Key] {
+			t.Errorf("%s: effective price %v differs from the pricing config's %v", price.Key, price.EffectivePrice, resolved.Config.MaterialPrices[price.Key])
+		}
+	}
+
+	tests := []struct {
+		key            string
+		effective      float64
+		base           float64
+		regionalFactor float64
+		overrideID     *uuid.UUID
+		defaultUsed    bool
+	}{
+		{"door", 480, 400, 1.2, nil, false},
+		{"window", 1056, 800, 1.2, &percentID, false},
+		{"outlet", 140, 125, 1.0, &directID, true},
+		{"fixture", 240, 200, 1.2, nil, true},
+	}
+	for _, tt := range tests {
+		price := prices[tt.key]
+		if math.Abs(price.EffectivePrice-tt.effective) > 0.001 || price.BasePrice != tt.base || price.RegionalFactor != tt.regionalFactor || price.DefaultUsed != tt.defaultUsed {
+			t.Errorf("%s = %+v, want %v from base %v x%v (default %v)", tt.key, price, tt.effective, tt.base, tt.regionalFactor, tt.defaultUsed)
+		}
+		if tt.overrideID == nil {
+			if price.Overridden || price.Override != nil {
+				t.Errorf("%s: unexpected override %+v", tt.key, price.Override)
+			}
+		} else if !price.Overridden || price.Override == nil || price.Override.ID != *tt.overrideID {
+			t.Errorf("%s: override = %+v, want %s", tt.key, price.Override, *tt.overrideID)
+		}
+	}
+}
+
+// catalogCostData serves one region's database prices
+type catalogCostData struct {
+	region    string
+	factor    float64
+	materials []models.MaterialCost
+	labor     []models.LaborRate
+}
+
+func (f *catalogCostData) GetMaterials(ctx context.Context, category, region *string) ([]models.MaterialCost, error) {
+	return f.materials, nil
+}
+
+func (f *catalogCostData) GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
+	return f.labor, nil
+}
+
+func (f *catalogCostData) GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error) {
+	if region != f.region {
+		return nil, errCostDataNotConfigured
+	}
+	return &models.RegionalAdjustment{Region: region, AdjustmentFactor: f.factor}, nil
+}
+
+func TestEffectivePrices_MatchPricingSummary(t *testing.T) {
+	service := NewEnhancedPricingService(nil, nil, nil, nil).WithCostData(&catalogCostData{
+		region:    "california",
+		factor:    1.25,
+		materials: []models.MaterialCost{{Category: "door", BasePrice: 400, Source: "lowes"}},
+		labor:     []models.LaborRate{{Trade: "carpentry", HourlyRate: 80, Source: "rsmeans"}},
+	})
+	region := "california"
+	analysis := &models.AnalysisResult{
+		Openings: []models.Opening{{OpeningType: "door", Count: 2}, {OpeningType: "window", Count: 1}},
+		Fixtures: []models.Fixture{{FixtureType: "outlet", Count: 4}},
+	}
+
+	summary, err := service.GeneratePricingSummary(context.Background(), nil, analysis, nil, &region)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	materials, err := service.EffectiveMaterialPrices(context.Background(), nil, &region)
+	if err != nil {
+		t.Fatalf("EffectiveMaterialPrices() error = %v", err)
+	}
+	labor, err := service.EffectiveLaborRates(context.Background(), nil, &region)
+	if err != nil {
+		t.Fatalf("EffectiveLaborRates() error = %v", err)
+	}
+	if materials.RegionalFactor != 1.25 || labor.RegionalFactor != 1.25 {
+		t.Errorf("regional factors = %v, %v; want 1.25", materials.RegionalFactor, labor.RegionalFactor)
+	}
+
+	effective := map[string]float64{}
+	for _, price := range materials.Prices {
+		effective["material:"+price.Key] = price.EffectivePrice
+	}
+	for _, rate := range labor.Prices {
+		effective["labor:"+rate.Key] = rate.EffectivePrice
+	}
+	want := map[string]string{
+		"Interior door installation":      "material:door",
+		"Window installation":             "material:window",
+		"Electrical fixtures and outlets": "material:outlet",
+		"Labor - carpentry":               "labor:carpentry",
+		"Labor - electrical":              "labor:electrical",
+	}
+	for _, item := range summary.LineItems {
+		key, ok := want[item.Description]
+		if !ok {
+			continue
+		}
+		delete(want, item.Description)
+		if item.UnitCost != effective[key] {
+			t.Errorf("%s: unit cost %v, effective %s price %v", item.Description, item.UnitCost, key, effective[key])
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("pricing summary is missing line items %v", want)
+	}
+}
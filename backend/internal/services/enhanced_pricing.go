@@ -3,9 +3,9 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"math"
 
 	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
@@ -18,7 +18,58 @@ type EnhancedPricingService struct {
 	laborRateRepo        *repository.LaborRateRepository
 	regionalRepo         *repository.RegionalAdjustmentRepository
 	companyOverrideRepo  *repository.CompanyPricingOverrideRepository
+	projectOverrides     ProjectOverrideSource
+	costData             CostDataSource
 	defaultConfig        *models.PricingConfig
+	rangeParams          ConfidenceRangeParams
+	providerPrecedence   []string
+}
+
+// CostDataSource supplies the database-backed prices used by
+// EnhancedPricingService. CachedCostIntegrationService implements it over
+// Redis; by default the repositories are read directly.
+type CostDataSource interface {
+	GetMaterials(ctx context.Context, category, region *string) ([]models.MaterialCost, error)
+	GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error)
+	GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error)
+}
+
+// ProjectOverrideSource supplies the pricing overrides of one project;
+// ProjectPricingOverrideRepository implements it
+type ProjectOverrideSource interface {
+	GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.ProjectPricingOverride, error)
+}
+
+// errCostDataNotConfigured is returned when a repository is not configured;
+// pricing silently falls back to defaults
+var errCostDataNotConfigured = errors.New("cost data not configured")
+
+// repositoryCostData reads cost data straight from the repositories
+type repositoryCostData struct {
+	materialRepo  *repository.MaterialRepository
+	laborRateRepo *repository.LaborRateRepository
+	regionalRepo  *repository.RegionalAdjustmentRepository
+}
+
+func (d repositoryCostData) GetMaterials(ctx context.Context, category, region *string) ([]models.MaterialCost, error) {
+	if d.materialRepo == nil {
+		return nil, errCostDataNotConfigured
+	}
+	return d.materialRepo.GetAll(ctx, category, region)
+}
+
+func (d repositoryCostData) GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
+	if d.laborRateRepo == nil {
+		return nil, errCostDataNotConfigured
+	}
+	return d.laborRateRepo.GetAll(ctx, trade, region)
+}
+
+func (d repositoryCostData) GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error) {
+	if d.regionalRepo == nil {
+		return nil, errCostDataNotConfigured
+	}
+	return d.regionalRepo.GetByRegion(ctx, region)
 }
 
 func NewEnhancedPricingService(
@@ -32,16 +83,31 @@ func NewEnhancedPricingService(
 		laborRateRepo:       laborRateRepo,
 		regionalRepo:        regionalRepo,
 		companyOverrideRepo: companyOverrideRepo,
+		rangeParams:         DefaultConfidenceRangeParams,
+		costData: repositoryCostData{
+			materialRepo:  materialRepo,
+			laborRateRepo: laborRateRepo,
+			regionalRepo:  regionalRepo,
+		},
 		defaultConfig: &models.PricingConfig{
 			MaterialPrices: map[string]float64{
 				"drywall":  1.50,
 				"lumber":   3.00,
 				"paint":    25.00,
 				"flooring": 8.50,
+				"flooring_tile":     12.00,
+				"flooring_lvp":      6.50,
+				"flooring_carpet":   4.50,
+				"flooring_hardwood": 11.00,
 				"door":     450.00,
+				"door_exterior": 1200.00,
+				"door_sliding":  1800.00,
 				"window":   850.00,
 				"outlet":   125.00,
 				"fixture":  200.00,
+				"wall_framing_lf": 12.00,
+				"top_plate":       2.50,
+				"baseboard":       4.25,
 			},
 			LaborRates: map[string]float64{
 				"carpentry":  75.00,
@@ -53,64 +119,88 @@ func NewEnhancedPricingService(
 			},
 			OverheadRate: 15.0,
 			ProfitMargin: 20.0,
+			FinishLaborSplits: DefaultFinishLaborSplits(),
+			RoomTypeFinishes:  DefaultRoomTypeFinishes(),
+			TradeMinimums:     DefaultTradeMinimums(),
+			ProjectTypeModifiers: DefaultProjectTypeModifiers(),
+			ProductivityRates:    DefaultProductivityRates(),
 		},
 	}
 }
 
-// GetPricingConfig retrieves pricing configuration with database prices, regional adjustments, and user overrides
-func (s *EnhancedPricingService) GetPricingConfig(ctx context.Context, userID *uuid.UUID, region *string) (*models.PricingConfig, error) {
-	config := &models.PricingConfig{
-		MaterialPrices: make(map[string]float64),
-		LaborRates:     make(map[string]float64),
-		OverheadRate:   s.defaultConfig.OverheadRate,
-		ProfitMargin:   s.defaultConfig.ProfitMargin,
+// WithCostData reads materials, labor rates and regional adjustments from
+// source (e.g. the Redis-cached cost service) instead of the repositories
+func (s *EnhancedPricingService) WithCostData(source CostDataSource) *EnhancedPricingService {
+	if source != nil {
+		s.costData = source
 	}
+	return s
+}
+
+// WithProjectOverrides applies the overrides of the project being priced, from
+// source, after company overrides
+func (s *EnhancedPricingService) WithProjectOverrides(source ProjectOverrideSource) *EnhancedPricingService {
+	s.projectOverrides = source
+	return s
+}
+
+// WithConfidenceRange sets the uncertainty factors used for pricing
+// summaries' confidence ranges
+func (s *EnhancedPricingService) WithConfidenceRange(params ConfidenceRangeParams) *EnhancedPricingService {
+	s.rangeParams = params
+	return s
+}
+
+// WithProviderPrecedence sets the order material prices are taken from when
+// several providers price the same category; nil keeps the default order
+func (s *EnhancedPricingService) WithProviderPrecedence(precedence []string) *EnhancedPricingService {
+	s.providerPrecedence = precedence
+	return s
+}
+
+// GetPricingConfig retrieves pricing configuration with database prices,
+// regional adjustments, the user's company overrides and, when projectID is
+// given, the project's overrides. Each step takes precedence over the ones
+// before it: defaults, database prices, regional factor, company overrides,
+// project overrides.
+func (s *EnhancedPricingService) GetPricingConfig(ctx context.Context, userID, projectID *uuid.UUID, region *string) (*models.PricingConfig, error) {
+	resolved, err := s.ResolvePricingConfig(ctx, userID, projectID, region)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.Config, nil
+}
+
+// ResolvePricingConfig is GetPricingConfig with the provenance of every material price and labor rate
+func (s *EnhancedPricingService) ResolvePricingConfig(ctx context.Context, userID, projectID *uuid.UUID, region *string) (*ResolvedPricingConfig, error) {
+	inputs := pricingInputs{regionalFactor: 1.0, providerPrecedence: s.providerPrecedence}
 
 	// Get regional adjustment factor
-	regionalFactor := 1.0
-	if region != nil && s.regionalRepo != nil {
-		adjustment, err := s.regionalRepo.GetByRegion(ctx, *region)
+	if region != nil {
+		adjustment, err := s.costData.GetRegionalAdjustment(ctx, *region)
 		if err == nil && adjustment != nil {
-			regionalFactor = adjustment.AdjustmentFactor
-		} else {
+			inputs.regionalFactor = adjustment.AdjustmentFactor
+		} else if !errors.Is(err, errCostDataNotConfigured) {
 			slog.Warn("Regional adjustment not found, using default", "region", *region)
 		}
 	}
 
-	// Load materials from database
-	if s.materialRepo != nil {
-		materials, err := s.materialRepo.GetAll(ctx, nil, region)
-		if err != nil {
-			slog.Error("Failed to load materials from database", "error", err)
-			// Fall back to default prices
-			config.MaterialPrices = s.defaultConfig.MaterialPrices
-		} else {
-			// Build material price map with regional adjustment
-			for _, m := range materials {
-				config.MaterialPrices[m.Category] = m.BasePrice * regionalFactor
-			}
-		}
-	} else {
-		// No repository, use defaults
-		config.MaterialPrices = s.defaultConfig.MaterialPrices
+	// Load materials from database; on failure fall back to default prices
+	materials, err := s.costData.GetMaterials(ctx, nil, region)
+	if err == nil {
+		inputs.materials = materials
+		inputs.materialsLoaded = true
+	} else if !errors.Is(err, errCostDataNotConfigured) {
+		slog.Error("Failed to load materials from database", "error", err)
 	}
 
-	// Load labor rates from database
-	if s.laborRateRepo != nil {
-		laborRates, err := s.laborRateRepo.GetAll(ctx, nil, region)
-		if err != nil {
-			slog.Error("Failed to load labor rates from database", "error", err)
-			// Fall back to default rates
-			config.LaborRates = s.defaultConfig.LaborRates
-		} else {
-			// Build labor rate map with regional adjustment
-			for _, lr := range laborRates {
-				config.LaborRates[lr.Trade] = lr.HourlyRate * regionalFactor
-			}
-		}
-	} else {
-		// No repository, use defaults
-		config.LaborRates = s.defaultConfig.LaborRates
+	// Load labor rates from database; on failure fall back to default rates
+	laborRates, err := s.costData.GetLaborRates(ctx, nil, region)
+	if err == nil {
+		inputs.laborRates = laborRates
+		inputs.laborLoaded = true
+	} else if !errors.Is(err, errCostDataNotConfigured) {
+		slog.Error("Failed to load labor rates from database", "error", err)
 	}
 
 	// Apply company-specific overrides if userID is provided
@@ -119,227 +209,63 @@ func (s *EnhancedPricingService) GetPricingConfig(ctx context.Context, userID *u
 		if err != nil {
 			slog.Warn("Failed to load company overrides", "user_id", userID, "error", err)
 		} else {
-			for _, override := range overrides {
-				switch override.OverrideType {
-				case "material":
-					if override.IsPercentage {
-						// Apply percentage adjustment
-						if basePrice, exists := config.MaterialPrices[override.ItemKey]; exists {
-							config.MaterialPrices[override.ItemKey] = basePrice * (1 + override.OverrideValue/100)
-						}
-					} else {
-						// Direct override
-						config.MaterialPrices[override.ItemKey] = override.OverrideValue
-					}
-				case "labor":
-					if override.IsPercentage {
-						// Apply percentage adjustment
-						if baseRate, exists := config.LaborRates[override.ItemKey]; exists {
-							config.LaborRates[override.ItemKey] = baseRate * (1 + override.OverrideValue/100)
-						}
-					} else {
-						// Direct override
-						config.LaborRates[override.ItemKey] = override.OverrideValue
-					}
-				case "overhead":
-					if override.IsPercentage {
-						config.OverheadRate = override.OverrideValue
-					}
-				case "profit_margin":
-					if override.IsPercentage {
-						config.ProfitMargin = override.OverrideValue
-					}
-				}
-			}
+			inputs.overrides = overrides
 		}
 	}
 
-	// Ensure we have all required prices (fall back to defaults if missing)
-	for key, price := range s.defaultConfig.MaterialPrices {
-		if _, exists := config.MaterialPrices[key]; !exists {
-			config.MaterialPrices[key] = price * regionalFactor
-		}
-	}
-	for key, rate := range s.defaultConfig.LaborRates {
-		if _, exists := config.LaborRates[key]; !exists {
-			config.LaborRates[key] = rate * regionalFactor
+	// Project overrides apply on top of the company's
+	if projectID != nil && s.projectOverrides != nil {
+		overrides, err := s.projectOverrides.GetByProjectID(ctx, *projectID)
+		if err != nil {
+			slog.Warn("Failed to load project overrides", "project_id", projectID, "error", err)
+		} else {
+			inputs.projectOverrides = overrides
 		}
 	}
 
-	return config, nil
+	return resolvePricing(s.defaultConfig, inputs), nil
 }
 
-// GeneratePricingSummary calculates costs from takeoff data with database-backed pricing
+// GeneratePricingSummary calculates costs from takeoff data with database-backed
+// pricing, as new construction
 func (s *EnhancedPricingService) GeneratePricingSummary(
 	ctx context.Context,
 	takeoffSummary *models.TakeoffSummary,
 	analysisResult *models.AnalysisResult,
 	userID *uuid.UUID,
 	region *string,
+) (*models.PricingSummary, error) {
+	return s.GenerateProjectPricingSummary(ctx, takeoffSummary, analysisResult, userID, nil, region, models.ProjectTypeNewConstruction)
+}
+
+// GenerateProjectPricingSummary is GeneratePricingSummary for a project type,
+// with the overrides of the project when projectID is given. Renovations and
+// additions add demolition and protection line items over the takeoff area
+// and a labor productivity penalty on labor hours.
+func (s *EnhancedPricingService) GenerateProjectPricingSummary(
+	ctx context.Context,
+	takeoffSummary *models.TakeoffSummary,
+	analysisResult *models.AnalysisResult,
+	userID *uuid.UUID,
+	projectID *uuid.UUID,
+	region *string,
+	projectType models.ProjectType,
 ) (*models.PricingSummary, error) {
 	// Get pricing configuration with database prices, regional adjustments, and user overrides
-	config, err := s.GetPricingConfig(ctx, userID, region)
+	resolved, err := s.ResolvePricingConfig(ctx, userID, projectID, region)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pricing config: %w", err)
 	}
+	config := resolved.Config
 
-	var lineItems []models.LineItem
-	var materialCost, laborCost float64
-	costsByTrade := make(map[string]float64)
-
-	// Calculate costs from rooms (framing, drywall, flooring)
-	if takeoffSummary != nil && takeoffSummary.TotalArea > 0 {
-		// Framing and drywall
-		framingItem := models.LineItem{
-			Description: "Framing and drywall installation",
-			Trade:       "framing",
-			Quantity:    takeoffSummary.TotalArea,
-			Unit:        "sq ft",
-			UnitCost:    5.50,
-			Total:       math.Round(takeoffSummary.TotalArea * 5.50 * 100) / 100,
-		}
-		lineItems = append(lineItems, framingItem)
-		materialCost += framingItem.Total * 0.4
-		laborCost += framingItem.Total * 0.6
-		costsByTrade["framing"] += framingItem.Total
-
-		// Flooring
-		flooringItem := models.LineItem{
-			Description: "Flooring installation",
-			Trade:       "general",
-			Quantity:    takeoffSummary.TotalArea,
-			Unit:        "sq ft",
-			UnitCost:    config.MaterialPrices["flooring"],
-			Total:       math.Round(takeoffSummary.TotalArea * config.MaterialPrices["flooring"] * 100) / 100,
-		}
-		lineItems = append(lineItems, flooringItem)
-		materialCost += flooringItem.Total * 0.7
-		laborCost += flooringItem.Total * 0.3
-		costsByTrade["general"] += flooringItem.Total
-
-		// Paint
-		paintItem := models.LineItem{
-			Description: "Paint and finishing",
-			Trade:       "painting",
-			Quantity:    takeoffSummary.TotalArea,
-			Unit:        "sq ft",
-			UnitCost:    3.50,
-			Total:       math.Round(takeoffSummary.TotalArea * 3.50 * 100) / 100,
-		}
-		lineItems = append(lineItems, paintItem)
-		materialCost += paintItem.Total * 0.3
-		laborCost += paintItem.Total * 0.7
-		costsByTrade["painting"] += paintItem.Total
-	}
-
-	// Calculate costs from openings (doors and windows)
-	if analysisResult != nil {
-		doorCount := 0
-		windowCount := 0
-
-		for _, opening := range analysisResult.Openings {
-			if opening.OpeningType == "door" {
-				doorCount += opening.Count
-			} else if opening.OpeningType == "window" {
-				windowCount += opening.Count
-			}
-		}
-
-		if doorCount > 0 {
-			doorItem := models.LineItem{
-				Description: "Interior door installation",
-				Trade:       "carpentry",
-				Quantity:    float64(doorCount),
-				Unit:        "each",
-				UnitCost:    config.MaterialPrices["door"],
-				Total:       math.Round(float64(doorCount) * config.MaterialPrices["door"] * 100) / 100,
-			}
-			lineItems = append(lineItems, doorItem)
-			materialCost += doorItem.Total * 0.75
-			laborCost += doorItem.Total * 0.25
-			costsByTrade["carpentry"] += doorItem.Total
-		}
-
-		if windowCount > 0 {
-			windowItem := models.LineItem{
-				Description: "Window installation",
-				Trade:       "carpentry",
-				Quantity:    float64(windowCount),
-				Unit:        "each",
-				UnitCost:    config.MaterialPrices["window"],
-				Total:       math.Round(float64(windowCount) * config.MaterialPrices["window"] * 100) / 100,
-			}
-			lineItems = append(lineItems, windowItem)
-			materialCost += windowItem.Total * 0.80
-			laborCost += windowItem.Total * 0.20
-			costsByTrade["carpentry"] += windowItem.Total
-		}
-
-		// Calculate costs from fixtures
-		fixtureCount := 0
-		for _, fixture := range analysisResult.Fixtures {
-			fixtureCount += fixture.Count
-		}
-
-		if fixtureCount > 0 {
-			fixtureItem := models.LineItem{
-				Description: "Electrical fixtures and outlets",
-				Trade:       "electrical",
-				Quantity:    float64(fixtureCount),
-				Unit:        "each",
-				UnitCost:    config.MaterialPrices["outlet"],
-				Total:       math.Round(float64(fixtureCount) * config.MaterialPrices["outlet"] * 100) / 100,
-			}
-			lineItems = append(lineItems, fixtureItem)
-			materialCost += fixtureItem.Total * 0.60
-			laborCost += fixtureItem.Total * 0.40
-			costsByTrade["electrical"] += fixtureItem.Total
-		}
-	}
-
-	// Add labor line items by trade
-	for trade, cost := range costsByTrade {
-		if cost > 0 {
-			rate, ok := config.LaborRates[trade]
-			if !ok {
-				rate = config.LaborRates["general"]
-			}
-			hours := math.Round((cost * LaborHoursEstimationFactor) / rate)
-			if hours > 0 {
-				laborItem := models.LineItem{
-					Description: fmt.Sprintf("Labor - %s", trade),
-					Trade:       trade,
-					Quantity:    hours,
-					Unit:        "hours",
-					UnitCost:    rate,
-					Total:       math.Round(hours * rate * 100) / 100,
-				}
-				lineItems = append(lineItems, laborItem)
-				laborCost += laborItem.Total
-			}
-		}
+	summary, err := priceTakeoff(takeoffSummary, analysisResult, config, resolved, projectType)
+	if err != nil {
+		return nil, err
 	}
-
-	// Round costs
-	materialCost = math.Round(materialCost * 100) / 100
-	laborCost = math.Round(laborCost * 100) / 100
-	subtotal := math.Round((materialCost + laborCost) * 100) / 100
-
-	// Calculate overhead and markup
-	overheadAmount := math.Round(subtotal * (config.OverheadRate / 100) * 100) / 100
-	markupAmount := math.Round((subtotal + overheadAmount) * (config.ProfitMargin / 100) * 100) / 100
-	totalPrice := math.Round((subtotal + overheadAmount + markupAmount) * 100) / 100
-
-	return &models.PricingSummary{
-		LineItems:      lineItems,
-		LaborCost:      laborCost,
-		MaterialCost:   materialCost,
-		Subtotal:       subtotal,
-		OverheadAmount: overheadAmount,
-		MarkupAmount:   markupAmount,
-		TotalPrice:     totalPrice,
-		CostsByTrade:   costsByTrade,
-	}, nil
+	summary.AppliedOverrides = config.AppliedOverrides
+	summary.SkippedOverrides = config.SkippedOverrides
+	summary.ConfidenceRange = EstimateConfidenceRange(summary, analysisResult, s.rangeParams)
+	return summary, nil
 }
 
 // GetDefaultPricingConfig returns the default pricing configuration (for backward compatibility)
@@ -361,24 +287,11 @@ func (s *EnhancedPricingService) ParseTakeoffData(jsonData string) (*models.Take
 	}
 
 	for _, room := range analysis.Rooms {
-		takeoff.TotalArea += room.Area
-		takeoff.RoomCount++
-		takeoff.RoomBreakdown = append(takeoff.RoomBreakdown, models.RoomSummary{
-			Name:       room.Name,
-			RoomType:   room.RoomType,
-			Area:       room.Area,
-			Dimensions: room.Dimensions,
-		})
+		addTakeoffRoom(takeoff, room)
 	}
+	flagUnmeasuredRooms(takeoff, DefaultUnmeasuredReviewFraction)
 
-	for _, opening := range analysis.Openings {
-		takeoff.OpeningCounts[opening.OpeningType] += opening.Count
-		takeoff.OpeningBreakdown = append(takeoff.OpeningBreakdown, models.OpeningSummary{
-			OpeningType: opening.OpeningType,
-			Count:       opening.Count,
-			Size:        opening.Size,
-		})
-	}
+	addTakeoffOpenings(takeoff, analysis.Openings)
 
 	for _, fixture := range analysis.Fixtures {
 		takeoff.FixtureCounts[fixture.Category] += fixture.Count
@@ -388,6 +301,7 @@ func (s *EnhancedPricingService) ParseTakeoffData(jsonData string) (*models.Take
 			Count:       fixture.Count,
 		})
 	}
+	addUnrecognizedItems(takeoff, &analysis)
 
 	return takeoff, &analysis, nil
 }
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+)
+
+// AuditSubscriber records published events as audit log lines
+type AuditSubscriber struct {
+	logger *slog.Logger
+}
+
+// NewAuditSubscriber creates an audit subscriber that writes to logger
+func NewAuditSubscriber(logger *slog.Logger) *AuditSubscriber {
+	return &AuditSubscriber{logger: logger}
+}
+
+// Register subscribes the audit log to every event that is audited. Audit
+// lines are written asynchronously so logging never delays a response.
+func (a *AuditSubscriber) Register(bus *events.Bus) {
+	events.Subscribe(bus, "audit", events.Async, a.bidCreated)
+	events.Subscribe(bus, "audit", events.Async, a.bidOverBudget)
+	events.Subscribe(bus, "audit", events.Async, a.bidStatusChanged)
+	events.Subscribe(bus, "audit", events.Async, a.bidAccepted)
+	events.Subscribe(bus, "audit", events.Async, a.bidFirstViewed)
+	events.Subscribe(bus, "audit", events.Async, a.analysisCompleted)
+	events.Subscribe(bus, "audit", events.Async, a.overrideChanged)
+	events.Subscribe(bus, "audit", events.Async, a.projectOverrideChanged)
+	events.Subscribe(bus, "audit", events.Async, a.materialPricesAdjusted)
+	events.Subscribe(bus, "audit", events.Async, a.materialChanged)
+	events.Subscribe(bus, "audit", events.Async, a.laborRateChanged)
+}
+
+func (a *AuditSubscriber) bidCreated(ctx context.Context, event events.BidCreated) {
+	a.logger.Info("Bid created",
+		"audit_event", event.EventName(),
+		"bid_id", event.BidID,
+		"project_id", event.ProjectID,
+		"user_id", event.UserID,
+		"final_price", event.FinalPrice,
+		"correlation_id", event.CorrelationID)
+}
+
+func (a *AuditSubscriber) bidOverBudget(ctx context.Context, event events.BidOverBudget) {
+	a.logger.Warn("Bid exceeds project budget",
+		"audit_event", event.EventName(),
+		"bid_id", event.BidID,
+		"project_id", event.ProjectID,
+		"user_id", event.UserID,
+		"budget", event.BudgetStatus.Budget,
+		"final_price", event.BudgetStatus.Price,
+		"percent_over", event.BudgetStatus.Percent,
+		"correlation_id", event.CorrelationID)
+}
+
+func (a *AuditSubscriber) bidStatusChanged(ctx context.Context, event events.BidStatusChanged) {
+	a.logger.Info("Bid status changed",
+		"audit_event", event.EventName(),
+		"bid_id", event.BidID,
+		"project_id", event.ProjectID,
+		"from", event.From,
+		"to", event.To,
+		"version", event.Version,
+		"user_id", event.UserID,
+		"correlation_id", event.CorrelationID)
+}
+
+func (a *AuditSubscriber) bidAccepted(ctx context.Context, event events.BidAccepted) {
+	a.logger.Info("Bid accepted online",
+		"audit_event", event.EventName(),
+		"bid_id", event.Acceptance.BidID,
+		"project_id", event.ProjectID,
+		"share_id", event.Acceptance.ShareID,
+		"acceptance_id", event.Acceptance.ID,
+		"signer_name", event.Acceptance.SignerName,
+		"signer_email", event.Acceptance.SignerEmail,
+		"ip_address", event.Acceptance.IPAddress,
+		"user_agent", event.Acceptance.UserAgent,
+		"accepted_at", event.Acceptance.AcceptedAt,
+		"correlation_id", event.CorrelationID)
+}
+
+func (a *AuditSubscriber) bidFirstViewed(ctx context.Context, event events.BidFirstViewed) {
+	a.logger.Info("Shared bid first viewed",
+		"audit_event", event.EventName(),
+		"bid_id", event.BidID,
+		"project_id", event.ProjectID,
+		"share_id", event.ShareID,
+		"viewed_at", event.ViewedAt)
+}
+
+func (a *AuditSubscriber) analysisCompleted(ctx context.Context, event events.AnalysisCompleted) {
+	a.logger.Info("Blueprint analysis completed",
+		"audit_event", event.EventName(),
+		"job_id", event.JobID,
+		"blueprint_id", event.BlueprintID,
+		"project_id", event.ProjectID,
+		"job_type", event.JobType)
+}
+
+func (a *AuditSubscriber) overrideChanged(ctx context.Context, event events.OverrideChanged) {
+	a.logger.Info("Company pricing override changed",
+		"audit_event", event.EventName(),
+		"change", event.Change,
+		"override_id", event.Override.ID,
+		"user_id", event.Override.UserID,
+		"override_type", event.Override.OverrideType,
+		"item_key", event.Override.ItemKey,
+		"override_value", event.Override.OverrideValue,
+		"is_percentage", event.Override.IsPercentage,
+		"correlation_id", event.CorrelationID)
+}
+
+func (a *AuditSubscriber) projectOverrideChanged(ctx context.Context, event events.ProjectOverrideChanged) {
+	a.logger.Info("Project pricing override changed",
+		"audit_event", event.EventName(),
+		"change", event.Change,
+		"override_id", event.Override.ID,
+		"project_id", event.Override.ProjectID,
+		"user_id", event.UserID,
+		"override_type", event.Override.OverrideType,
+		"item_key", event.Override.ItemKey,
+		"override_value", event.Override.OverrideValue,
+		"is_percentage", event.Override.IsPercentage,
+		"correlation_id", event.CorrelationID)
+}
+
+func (a *AuditSubscriber) materialPricesAdjusted(ctx context.Context, event events.MaterialPricesAdjusted) {
+	// There is no price history table, so the audit event carries the row count
+	a.logger.Info("Bulk material price adjustment applied",
+		"audit_event", event.EventName(),
+		"user_id", event.UserID,
+		"percent", event.Percent,
+		"category", stringOrEmpty(event.Filter.Category),
+		"region", stringOrEmpty(event.Filter.Region),
+		"source", stringOrEmpty(event.Filter.Source),
+		"rows_affected", event.RowsAffected)
+}
+
+func (a *AuditSubscriber) materialChanged(ctx context.Context, event events.MaterialChanged) {
+	a.logger.Info("Material changed",
+		"audit_event", event.EventName(),
+		"change", event.Change,
+		"material_id", event.Material.ID,
+		"user_id", event.UserID,
+		"name", event.Material.Name,
+		"category", event.Material.Category,
+		"region", stringOrEmpty(event.Material.Region),
+		"base_price", event.Material.BasePrice,
+		"correlation_id", event.CorrelationID)
+}
+
+func (a *AuditSubscriber) laborRateChanged(ctx context.Context, event events.LaborRateChanged) {
+	a.logger.Info("Labor rate changed",
+		"audit_event", event.EventName(),
+		"change", event.Change,
+		"labor_rate_id", event.LaborRate.ID,
+		"user_id", event.UserID,
+		"trade", event.LaborRate.Trade,
+		"region", stringOrEmpty(event.LaborRate.Region),
+		"hourly_rate", event.LaborRate.HourlyRate,
+		"correlation_id", event.CorrelationID)
+}
+
+// RegisterCacheInvalidation drops the materials cache when stored material
+// prices change, and the labor rates cache when stored labor rates change if
+// cache holds them. It runs synchronously so reads after the changing request
+// see the new prices.
+func RegisterCacheInvalidation(bus *events.Bus, cache MaterialsCacheInvalidator) {
+	events.Subscribe(bus, "materials_cache", events.Sync, func(ctx context.Context, event events.MaterialPricesAdjusted) {
+		cache.InvalidateMaterialsCache(ctx)
+	})
+	events.Subscribe(bus, "materials_cache", events.Sync, func(ctx context.Context, event events.MaterialChanged) {
+		cache.InvalidateMaterialsCache(ctx)
+	})
+	if laborRates, ok := cache.(LaborRatesCacheInvalidator); ok {
+		events.Subscribe(bus, "labor_rates_cache", events.Sync, func(ctx context.Context, event events.LaborRateChanged) {
+			laborRates.InvalidateLaborRatesCache(ctx)
+		})
+	}
+}
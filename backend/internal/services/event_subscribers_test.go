@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestAuditSubscriber_LogsEvents(t *testing.T) {
+	var logs bytes.Buffer
+	bus := events.NewBus()
+	NewAuditSubscriber(slog.New(slog.NewJSONHandler(&logs, nil))).Register(bus)
+
+	bidID, shareID, overrideID, projectOverrideID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	bus.Publish(context.Background(), events.BidCreated{BidID: bidID, UserID: "user-1", FinalPrice: 2100, CorrelationID: "req-1"})
+	bus.Publish(context.Background(), events.BidOverBudget{
+		BidID:         bidID,
+		UserID:        "user-1",
+		BudgetStatus:  models.BudgetStatus{Budget: 2000, Price: 2100, Status: models.BudgetStateOver, Delta: 100, Percent: 5},
+		CorrelationID: "req-1",
+	})
+	bus.Publish(context.Background(), events.BidStatusChanged{
+		BidID:         bidID,
+		From:          models.BidStatusSent,
+		To:            models.BidStatusAccepted,
+		Version:       2,
+		CorrelationID: "req-1",
+	})
+	bus.Publish(context.Background(), events.BidAccepted{
+		Acceptance:    models.BidAcceptance{BidID: bidID, SignerName: "Pat Owner", SignerEmail: "pat@example.com", IPAddress: "203.0.113.7"},
+		CorrelationID: "req-1",
+	})
+	bus.Publish(context.Background(), events.BidFirstViewed{BidID: bidID, ShareID: shareID, ViewedAt: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)})
+	bus.Publish(context.Background(), events.OverrideChanged{
+		Change:        events.OverrideDeleted,
+		Override:      models.CompanyPricingOverride{ID: overrideID, OverrideType: "labor", ItemKey: "carpentry", OverrideValue: 95},
+		CorrelationID: "req-2",
+	})
+	bus.Publish(context.Background(), events.ProjectOverrideChanged{
+		Change:        events.OverrideCreated,
+		Override:      models.ProjectPricingOverride{ID: projectOverrideID, OverrideType: "labor", ItemKey: "electrical", OverrideValue: 120},
+		UserID:        "user-1",
+		CorrelationID: "req-3",
+	})
+	bus.Wait()
+
+	// Async delivery does not preserve order, so index lines by event
+	lines := map[string]map[string]interface{}{}
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var line map[string]interface{}
+		if err := decoder.Decode(&line); err != nil {
+			t.Fatalf("failed to decode audit line: %v", err)
+		}
+		lines[line["audit_event"].(string)] = line
+	}
+
+	bid := lines["bid.created"]
+	if bid == nil || bid["bid_id"] != bidID.String() || bid["final_price"] != float64(2100) || bid["correlation_id"] != "req-1" {
+		t.Errorf("bid.created audit line = %v", bid)
+	}
+	overBudget := lines["bid.over_budget"]
+	if overBudget == nil || overBudget["bid_id"] != bidID.String() || overBudget["budget"] != float64(2000) || overBudget["percent_over"] != float64(5) {
+		t.Errorf("bid.over_budget audit line = %v", overBudget)
+	}
+	statusChanged := lines["bid.status_changed"]
+	if statusChanged == nil || statusChanged["bid_id"] != bidID.String() || statusChanged["from"] != "sent" || statusChanged["to"] != "accepted" || statusChanged["version"] != float64(2) {
+		t.Errorf("bid.status_changed audit line = %v", statusChanged)
+	}
+	accepted := lines["bid.accepted"]
+	if accepted == nil || accepted["bid_id"] != bidID.String() || accepted["signer_name"] != "Pat Owner" || accepted["signer_email"] != "pat@example.com" || accepted["ip_address"] != "203.0.113.7" {
+		t.Errorf("bid.accepted audit line = %v", accepted)
+	}
+	firstViewed := lines["bid.first_viewed"]
+	if firstViewed == nil || firstViewed["bid_id"] != bidID.String() || firstViewed["share_id"] != shareID.String() {
+		t.Errorf("bid.first_viewed audit line = %v", firstViewed)
+	}
+	override := lines["pricing.override_changed"]
+	if override == nil || override["change"] != "deleted" || override["override_id"] != overrideID.String() ||
+		override["item_key"] != "carpentry" || override["correlation_id"] != "req-2" {
+		t.Errorf("pricing.override_changed audit line = %v", override)
+	}
+	projectOverride := lines["pricing.project_override_changed"]
+	if projectOverride == nil || projectOverride["change"] != "created" || projectOverride["override_id"] != projectOverrideID.String() ||
+		projectOverride["user_id"] != "user-1" || projectOverride["correlation_id"] != "req-3" {
+		t.Errorf("pricing.project_override_changed audit line = %v", projectOverride)
+	}
+}
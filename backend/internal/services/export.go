@@ -29,28 +29,40 @@ const (
 
 // GenerateBidCSV exports bid data to CSV format
 func (s *ExportService) GenerateBidCSV(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string) ([]byte, error) {
+	return s.GenerateBidCSVWithLayout(bid, bidResponse, projectName, nil)
+}
+
+// GenerateBidCSVWithLayout exports bid data to CSV format, leaving out the
+// sections layout disables. Section order is fixed so the columns stay
+// predictable for spreadsheets.
+func (s *ExportService) GenerateBidCSVWithLayout(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string, layout *models.PDFLayout) ([]byte, error) {
 	var buf bytes.Buffer
 	writer := csv.NewWriter(&buf)
+	enabled := func(section models.PDFSection) bool {
+		return PDFSectionEnabled(layout, section)
+	}
 
 	// Write header section
 	writer.Write([]string{"Construction Bid Export - CSV Format"})
-	writer.Write([]string{"Project", projectName})
-	writer.Write([]string{"Bid ID", bid.ID.String()})
-	writer.Write([]string{"Date", time.Now().Format("2006-01-02")})
-	writer.Write([]string{"Status", string(bid.Status)})
+	if enabled(models.PDFSectionProjectInfo) {
+		writer.Write([]string{"Project", projectName})
+		writer.Write([]string{"Bid ID", bid.ID.String()})
+		writer.Write([]string{"Date", time.Now().Format("2006-01-02")})
+		writer.Write([]string{"Status", string(bid.Status)})
+	}
 	writer.Write([]string{}) // Empty row
 
 	// Scope of Work
-	if bidResponse.ScopeOfWork != "" {
+	if bidResponse.ScopeOfWork != "" && enabled(models.PDFSectionScope) {
 		writer.Write([]string{"Scope of Work"})
 		writer.Write([]string{bidResponse.ScopeOfWork})
 		writer.Write([]string{}) // Empty row
 	}
 
 	// Line Items
-	if len(bidResponse.LineItems) > 0 {
+	if len(bidResponse.LineItems) > 0 && enabled(models.PDFSectionLineItems) {
 		writer.Write([]string{"Line Items"})
-		writer.Write([]string{"Description", "Trade", "Quantity", "Unit", "Unit Cost", "Total"})
+		writer.Write([]string{"Description", "Trade", "Quantity", "Unit", "Unit Cost", "Total", "Price Source", "Notes"})
 		
 		for _, item := range bidResponse.LineItems {
 			writer.Write([]string{
@@ -60,18 +72,21 @@ func (s *ExportService) GenerateBidCSV(bid *models.Bid, bidResponse *models.Gene
 				item.Unit,
 				fmt.Sprintf("%.2f", item.UnitCost),
 				fmt.Sprintf("%.2f", item.Total),
+				FormatPriceSource(item.PriceSource),
+				item.Notes,
 			})
 		}
 		writer.Write([]string{}) // Empty row
 	}
 
 	// Trade Breakdown
-	if len(bidResponse.LineItems) > 0 {
+	if len(bidResponse.LineItems) > 0 && enabled(models.PDFSectionTradeBreakdown) {
 		writer.Write([]string{"Trade Breakdown"})
 		writer.Write([]string{"Trade", "Item Count", "Total Cost"})
 		
 		tradeGroups := s.groupByTrade(bidResponse.LineItems)
-		for trade, items := range tradeGroups {
+		for _, trade := range sortedKeys(tradeGroups) {
+			items := tradeGroups[trade]
 			total := 0.0
 			for _, item := range items {
 				total += item.Total
@@ -85,17 +100,27 @@ func (s *ExportService) GenerateBidCSV(bid *models.Bid, bidResponse *models.Gene
 		writer.Write([]string{}) // Empty row
 	}
 
-	// Cost Summary
-	writer.Write([]string{"Cost Summary"})
-	writer.Write([]string{"Material Cost", fmt.Sprintf("%.2f", bidResponse.MaterialCost)})
-	writer.Write([]string{"Labor Cost", fmt.Sprintf("%.2f", bidResponse.LaborCost)})
-	writer.Write([]string{"Subtotal", fmt.Sprintf("%.2f", bidResponse.Subtotal)})
-	writer.Write([]string{"Markup Amount", fmt.Sprintf("%.2f", bidResponse.MarkupAmount)})
-	writer.Write([]string{"Total Price", fmt.Sprintf("%.2f", bidResponse.TotalPrice)})
-	writer.Write([]string{}) // Empty row
+	// Cost Summary, with the alternates priced against it
+	if enabled(models.PDFSectionCostSummary) {
+		s.writeCostSummary(writer, bidResponse)
+	}
+
+	// Door/Window Schedule
+	if len(bidResponse.OpeningSchedule) > 0 {
+		writer.Write([]string{"Door/Window Schedule"})
+		writer.Write([]string{"Type", "Size", "Count"})
+		for _, entry := range bidResponse.OpeningSchedule {
+			writer.Write([]string{
+				OpeningClassLabel(entry.Classification),
+				entry.Size,
+				strconv.Itoa(entry.Count),
+			})
+		}
+		writer.Write([]string{}) // Empty row
+	}
 
 	// Inclusions
-	if len(bidResponse.Inclusions) > 0 {
+	if len(bidResponse.Inclusions) > 0 && enabled(models.PDFSectionInclusions) {
 		writer.Write([]string{"Inclusions"})
 		for _, inclusion := range bidResponse.Inclusions {
 			writer.Write([]string{inclusion})
@@ -104,7 +129,7 @@ func (s *ExportService) GenerateBidCSV(bid *models.Bid, bidResponse *models.Gene
 	}
 
 	// Exclusions
-	if len(bidResponse.Exclusions) > 0 {
+	if len(bidResponse.Exclusions) > 0 && enabled(models.PDFSectionExclusions) {
 		writer.Write([]string{"Exclusions"})
 		for _, exclusion := range bidResponse.Exclusions {
 			writer.Write([]string{exclusion})
@@ -113,24 +138,24 @@ func (s *ExportService) GenerateBidCSV(bid *models.Bid, bidResponse *models.Gene
 	}
 
 	// Schedule
-	if len(bidResponse.Schedule) > 0 {
+	if len(bidResponse.Schedule) > 0 && enabled(models.PDFSectionSchedule) {
 		writer.Write([]string{"Project Schedule"})
 		writer.Write([]string{"Phase", "Timeline"})
-		for phase, timeline := range bidResponse.Schedule {
-			writer.Write([]string{phase, timeline})
+		for _, phase := range sortedKeys(bidResponse.Schedule) {
+			writer.Write([]string{phase, bidResponse.Schedule[phase]})
 		}
 		writer.Write([]string{}) // Empty row
 	}
 
 	// Payment Terms
-	if bidResponse.PaymentTerms != "" {
+	if bidResponse.PaymentTerms != "" && enabled(models.PDFSectionTerms) {
 		writer.Write([]string{"Payment Terms"})
 		writer.Write([]string{bidResponse.PaymentTerms})
 		writer.Write([]string{}) // Empty row
 	}
 
 	// Warranty Terms
-	if bidResponse.WarrantyTerms != "" {
+	if bidResponse.WarrantyTerms != "" && enabled(models.PDFSectionTerms) {
 		writer.Write([]string{"Warranty Terms"})
 		writer.Write([]string{bidResponse.WarrantyTerms})
 		writer.Write([]string{}) // Empty row
@@ -144,34 +169,65 @@ func (s *ExportService) GenerateBidCSV(bid *models.Bid, bidResponse *models.Gene
 	return buf.Bytes(), nil
 }
 
-// GenerateBidExcel exports bid data to Excel-compatible CSV format (with UTF-8 BOM)
-// Note: This generates a CSV that Excel can open properly. For true .xlsx format,
-// we would need to add the excelize library. This approach keeps dependencies minimal
-// while maintaining Excel compatibility.
-func (s *ExportService) GenerateBidExcel(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string) ([]byte, error) {
-	csvData, err := s.GenerateBidCSV(bid, bidResponse, projectName)
-	if err != nil {
-		return nil, err
+// writeCostSummary writes the totals and the alternate groups
+func (s *ExportService) writeCostSummary(writer *csv.Writer, bidResponse *models.GenerateBidResponse) {
+	writer.Write([]string{"Cost Summary"})
+	writer.Write([]string{"Material Cost", fmt.Sprintf("%.2f", bidResponse.MaterialCost)})
+	writer.Write([]string{"Labor Cost", fmt.Sprintf("%.2f", bidResponse.LaborCost)})
+	writer.Write([]string{"Subtotal", fmt.Sprintf("%.2f", bidResponse.Subtotal)})
+	writer.Write([]string{"Markup Amount", fmt.Sprintf("%.2f", bidResponse.MarkupAmount)})
+	if tax := bidResponse.Tax; tax != nil {
+		row := []string{"Sales Tax", fmt.Sprintf("%.2f", bidResponse.TaxAmount)}
+		if tax.Note != "" {
+			row = append(row, tax.Note)
+		}
+		writer.Write(row)
 	}
+	writer.Write([]string{"Total Price", fmt.Sprintf("%.2f", bidResponse.TotalPrice)})
+	if metrics := bidResponse.UnitMetrics; metrics != nil && metrics.CostPerSF > 0 {
+		writer.Write([]string{"Cost per SF", fmt.Sprintf("%.2f", metrics.CostPerSF)})
+	}
+	writer.Write([]string{}) // Empty row
 
-	// Add UTF-8 BOM for Excel compatibility
-	bom := []byte{0xEF, 0xBB, 0xBF}
-	excelData := append(bom, csvData...)
-	
-	return excelData, nil
+	// Alternates
+	if len(bidResponse.Alternates) > 0 {
+		writer.Write([]string{"Alternates"})
+		writer.Write([]string{"Alternate", "Description", "Trade", "Quantity", "Unit", "Unit Cost", "Total", "Notes"})
+		for _, group := range bidResponse.Alternates {
+			for _, item := range group.LineItems {
+				writer.Write([]string{
+					group.Name,
+					item.Description,
+					item.Trade,
+					fmt.Sprintf("%.2f", item.Quantity),
+					item.Unit,
+					fmt.Sprintf("%.2f", item.UnitCost),
+					fmt.Sprintf("%.2f", item.Total),
+					item.Notes,
+				})
+			}
+			writer.Write([]string{group.Name + " Price", fmt.Sprintf("%.2f", group.Price)})
+		}
+		writer.Write([]string{}) // Empty row
+	}
+}
+
+// GenerateBidExcel exports bid data to an Excel workbook with Summary, Line
+// Items, Trade Breakdown and Terms sheets. Money and quantities are numeric
+// cells, so the sheets can be recalculated and summed.
+func (s *ExportService) GenerateBidExcel(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string) ([]byte, error) {
+	return s.GenerateBidExcelWithLayout(bid, bidResponse, projectName, nil)
 }
 
-// groupByTrade groups line items by their trade
+// GenerateBidExcelWithLayout is GenerateBidExcel leaving out the sections
+// layout disables
+func (s *ExportService) GenerateBidExcelWithLayout(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string, layout *models.PDFLayout) ([]byte, error) {
+	return generateBidXLSX(bid, bidResponse, projectName, layout)
+}
+
+// groupByTrade groups line items by their canonical trade
 func (s *ExportService) groupByTrade(items []models.LineItem) map[string][]models.LineItem {
-	groups := make(map[string][]models.LineItem)
-	for _, item := range items {
-		trade := item.Trade
-		if trade == "" {
-			trade = "General"
-		}
-		groups[trade] = append(groups[trade], item)
-	}
-	return groups
+	return groupByTrade(items)
 }
 
 // GenerateCSVFilename creates a unique filename for the bid CSV
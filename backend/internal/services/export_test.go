@@ -1,13 +1,14 @@
 package services
 
 import (
+	"bytes"
 	"encoding/csv"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestGenerateBidCSV(t *testing.T) {
@@ -35,8 +36,8 @@ func TestGenerateBidCSV(t *testing.T) {
 		Status:           models.BidStatusDraft,
 		Version:          1,
 		IsLatest:         true,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+		CreatedAt:        models.Now(),
+		UpdatedAt:        models.Now(),
 	}
 
 	bidResponse := &models.GenerateBidResponse{
@@ -204,88 +205,117 @@ func TestGenerateBidCSV(t *testing.T) {
 func TestGenerateBidExcel(t *testing.T) {
 	service := NewExportService()
 
-	bidID := uuid.New()
-	projectID := uuid.New()
-	totalCost := 100000.0
-	laborCost := 60000.0
-	materialCost := 40000.0
-	markup := 20.0
-	finalPrice := 120000.0
-	bidName := "Test Bid"
-
-	bid := &models.Bid{
-		ID:               bidID,
-		ProjectID:        projectID,
-		Name:             &bidName,
-		TotalCost:        &totalCost,
-		LaborCost:        &laborCost,
-		MaterialCost:     &materialCost,
-		MarkupPercentage: &markup,
-		FinalPrice:       &finalPrice,
-		Status:           models.BidStatusDraft,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+	bid := &models.Bid{ID: uuid.New(), ProjectID: uuid.New(), Status: models.BidStatusDraft}
+	bidResponse := &models.GenerateBidResponse{
+		ScopeOfWork: "Interior fit-out",
+		LineItems: []models.LineItem{
+			{Description: "Drywall", Trade: "drywall", Quantity: 1200, Unit: "sq ft", UnitCost: 2.5, Total: 3000},
+			{Description: "Paint", Trade: "painting", Quantity: 1200, Unit: "sq ft", UnitCost: 1.25, Total: 1500},
+			{Description: "Labor - drywall", Trade: "drywall", Quantity: 40, Unit: "hours", UnitCost: 62.5, Total: 2500},
+		},
+		LaborCost:    2500,
+		MaterialCost: 4500,
+		Subtotal:     7000,
+		MarkupAmount: 1400,
+		TotalPrice:   8400,
+		Exclusions:   []string{"Permits"},
+		PaymentTerms: "Net 30",
 	}
 
-	bidResponse := &models.GenerateBidResponse{
-		BidID:        bidID.String(),
-		ProjectID:    projectID.String(),
-		Status:       "draft",
-		ScopeOfWork:  "Test scope",
-		LineItems:    []models.LineItem{},
-		LaborCost:    60000,
-		MaterialCost: 40000,
-		Subtotal:     100000,
-		MarkupAmount: 20000,
-		TotalPrice:   120000,
+	data, err := service.GenerateBidExcel(bid, bidResponse, "Main St Clinic")
+	if err != nil {
+		t.Fatalf("GenerateBidExcel() error = %v", err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("export is not an XLSX workbook: %v", err)
 	}
+	defer file.Close()
 
-	projectName := "Test Project"
+	want := []string{XLSXSummarySheet, XLSXLineItemsSheet, XLSXTradeSheet, XLSXTermsSheet}
+	if sheets := file.GetSheetList(); strings.Join(sheets, ",") != strings.Join(want, ",") {
+		t.Errorf("sheets = %v, want %v", sheets, want)
+	}
 
-	t.Run("generate Excel with UTF-8 BOM", func(t *testing.T) {
-		excelBytes, err := service.GenerateBidExcel(bid, bidResponse, projectName)
+	raw := excelize.Options{RawCellValue: true}
+	cell := func(sheet, name string) string {
+		t.Helper()
+		value, err := file.GetCellValue(sheet, name, raw)
 		if err != nil {
-			t.Errorf("GenerateBidExcel() error = %v", err)
-			return
+			t.Fatalf("GetCellValue(%s, %s) error = %v", sheet, name, err)
 		}
+		return value
+	}
 
-		if len(excelBytes) < 3 {
-			t.Error("GenerateBidExcel() returned data too short for BOM")
-			return
-		}
+	if cell(XLSXSummarySheet, "B2") != "Main St Clinic" || cell(XLSXSummarySheet, "B3") != bid.ID.String() {
+		t.Errorf("summary project = %q, bid = %q; want the project and bid ID", cell(XLSXSummarySheet, "B2"), cell(XLSXSummarySheet, "B3"))
+	}
+	if cell(XLSXSummarySheet, "A12") != "Total Price" || cell(XLSXSummarySheet, "B12") != "8400" {
+		t.Errorf("summary total = %q %q, want Total Price 8400", cell(XLSXSummarySheet, "A12"), cell(XLSXSummarySheet, "B12"))
+	}
 
-		// Check for UTF-8 BOM
-		bom := []byte{0xEF, 0xBB, 0xBF}
-		if excelBytes[0] != bom[0] || excelBytes[1] != bom[1] || excelBytes[2] != bom[2] {
-			t.Error("Excel export missing UTF-8 BOM")
-		}
-	})
+	// A header, one row per line item and the totals row
+	rows, err := file.GetRows(XLSXLineItemsSheet)
+	if err != nil {
+		t.Fatalf("GetRows() error = %v", err)
+	}
+	if len(rows) != len(bidResponse.LineItems)+2 {
+		t.Errorf("line items sheet has %d rows, want %d", len(rows), len(bidResponse.LineItems)+2)
+	}
+	if cell(XLSXLineItemsSheet, "A2") != "Drywall" || cell(XLSXLineItemsSheet, "C2") != "1200" || cell(XLSXLineItemsSheet, "E2") != "2.5" {
+		t.Errorf("first line item = %v, want Drywall with numeric quantity and unit cost", rows[1])
+	}
+	if cellType, _ := file.GetCellType(XLSXLineItemsSheet, "F2"); cellType == excelize.CellTypeSharedString || cellType == excelize.CellTypeInlineString {
+		t.Errorf("total cell type = %v, want a number", cellType)
+	}
+	if formula, _ := file.GetCellFormula(XLSXLineItemsSheet, "F5"); formula != "SUM(F2:F4)" {
+		t.Errorf("totals formula = %q, want SUM(F2:F4)", formula)
+	}
+	if total, err := file.CalcCellValue(XLSXLineItemsSheet, "F5", raw); err != nil || total != "7000" {
+		t.Errorf("totals row = %q (%v), want 7000", total, err)
+	}
+	styleID, _ := file.GetCellStyle(XLSXLineItemsSheet, "F2")
+	if style, err := file.GetStyle(styleID); err != nil || style.CustomNumFmt == nil || *style.CustomNumFmt != xlsxCurrencyFormat {
+		t.Errorf("total cell style = %+v (%v), want currency", style, err)
+	}
 
-	t.Run("Excel content matches CSV", func(t *testing.T) {
-		csvBytes, err := service.GenerateBidCSV(bid, bidResponse, projectName)
-		if err != nil {
-			t.Errorf("GenerateBidCSV() error = %v", err)
-			return
-		}
+	// Drywall's two items are grouped
+	if cell(XLSXTradeSheet, "A2") != "Drywall" || cell(XLSXTradeSheet, "B2") != "2" || cell(XLSXTradeSheet, "C2") != "5500" {
+		t.Errorf("drywall breakdown = %q %q %q, want 2 items totaling 5500",
+			cell(XLSXTradeSheet, "A2"), cell(XLSXTradeSheet, "B2"), cell(XLSXTradeSheet, "C2"))
+	}
 
-		excelBytes, err := service.GenerateBidExcel(bid, bidResponse, projectName)
-		if err != nil {
-			t.Errorf("GenerateBidExcel() error = %v", err)
-			return
-		}
+	if cell(XLSXTermsSheet, "A1") != "Scope of Work" || cell(XLSXTermsSheet, "A5") != "Permits" || cell(XLSXTermsSheet, "A8") != "Net 30" {
+		t.Errorf("terms = %q, %q, %q; want the scope, exclusions and payment terms",
+			cell(XLSXTermsSheet, "A1"), cell(XLSXTermsSheet, "A5"), cell(XLSXTermsSheet, "A8"))
+	}
+}
 
-		// Excel should be CSV + 3 bytes for BOM
-		if len(excelBytes) != len(csvBytes)+3 {
-			t.Errorf("Excel size mismatch. Expected %d, got %d", len(csvBytes)+3, len(excelBytes))
-		}
+func TestGenerateBidExcel_LayoutHidesSheets(t *testing.T) {
+	bid := &models.Bid{ID: uuid.New()}
+	bidResponse := &models.GenerateBidResponse{
+		LineItems:    []models.LineItem{{Description: "Drywall", Trade: "drywall", Quantity: 1, UnitCost: 100, Total: 100}},
+		PaymentTerms: "Net 30",
+	}
+	layout := &models.PDFLayout{Sections: []models.PDFSectionLayout{
+		{Section: models.PDFSectionCostSummary, Enabled: true},
+		{Section: models.PDFSectionLineItems, Enabled: false},
+		{Section: models.PDFSectionTradeBreakdown, Enabled: true},
+	}}
+
+	data, err := NewExportService().GenerateBidExcelWithLayout(bid, bidResponse, "Project", layout)
+	if err != nil {
+		t.Fatalf("GenerateBidExcelWithLayout() error = %v", err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("export is not an XLSX workbook: %v", err)
+	}
+	defer file.Close()
 
-		// Content after BOM should match CSV
-		excelContent := string(excelBytes[3:])
-		csvContent := string(csvBytes)
-		if excelContent != csvContent {
-			t.Error("Excel content (after BOM) doesn't match CSV content")
-		}
-	})
+	if sheets := file.GetSheetList(); strings.Join(sheets, ",") != XLSXSummarySheet+","+XLSXTradeSheet {
+		t.Errorf("sheets = %v, want the summary and trade breakdown", sheets)
+	}
 }
 
 func TestGroupByTrade(t *testing.T) {
@@ -408,3 +438,66 @@ func TestExportServiceParseBidDataFromJSON(t *testing.T) {
 		}
 	})
 }
+
+func TestGenerateBidCSV_NotesColumn(t *testing.T) {
+	bid, response := testBidForPDF()
+	response.LineItems[0].Notes = "Quantity per owner-provided fixture count, see RFI #4"
+
+	data, err := NewExportService().GenerateBidCSV(bid, response, "Test Project")
+	if err != nil {
+		t.Fatalf("GenerateBidCSV() error = %v", err)
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1 // sections have different widths
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+
+	var header, row []string
+	for i, record := range records {
+		if len(record) > 0 && record[0] == "Description" {
+			header, row = record, records[i+1]
+			break
+		}
+	}
+	if len(header) == 0 || header[len(header)-1] != "Notes" {
+		t.Fatalf("expected a Notes column in the line items header, got %v", header)
+	}
+	if row[len(row)-1] != response.LineItems[0].Notes {
+		t.Errorf("expected the item's notes in the last column, got %v", row)
+	}
+
+	excel, err := NewExportService().GenerateBidExcel(bid, response, "Test Project")
+	if err != nil {
+		t.Fatalf("GenerateBidExcel() error = %v", err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(excel))
+	if err != nil {
+		t.Fatalf("export is not an XLSX workbook: %v", err)
+	}
+	defer file.Close()
+	if notes, _ := file.GetCellValue(XLSXLineItemsSheet, "H2"); notes != response.LineItems[0].Notes {
+		t.Errorf("expected the item's notes in the Excel export, got %q", notes)
+	}
+}
+
+func TestGenerateBidCSV_CostPerSF(t *testing.T) {
+	bid, response := testBidForPDF()
+	data, err := NewExportService().GenerateBidCSV(bid, response, "Test Project")
+	if err != nil {
+		t.Fatalf("GenerateBidCSV() error = %v", err)
+	}
+	if strings.Contains(string(data), "Cost per SF") {
+		t.Error("expected no cost per SF without unit metrics")
+	}
+
+	response.UnitMetrics = &models.UnitMetrics{AreaSF: 1000, CostPerSF: 142.5}
+	data, err = NewExportService().GenerateBidCSV(bid, response, "Test Project")
+	if err != nil {
+		t.Fatalf("GenerateBidCSV() error = %v", err)
+	}
+	if !strings.Contains(string(data), "Cost per SF,142.50") {
+		t.Errorf("expected the cost per SF in the cost summary, got:\n%s", data)
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXContentType is the content type of Excel workbook exports
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Sheets of an Excel bid export, in workbook order. Sheets for sections the
+// layout disables or the bid doesn't have are left out, except the summary.
+const (
+	XLSXSummarySheet   = "Summary"
+	XLSXLineItemsSheet = "Line Items"
+	XLSXTradeSheet     = "Trade Breakdown"
+	XLSXOpeningsSheet  = "Door-Window Schedule"
+	XLSXTermsSheet     = "Terms"
+)
+
+// Number formats of money and quantity cells
+const (
+	xlsxCurrencyFormat = `"$"#,##0.00`
+	xlsxQuantityFormat = `#,##0.00`
+)
+
+// xlsxWorkbook writes rows of typed cells to an excelize file, keeping the
+// first error so sheets can be written without checking every call
+type xlsxWorkbook struct {
+	file     *excelize.File
+	bold     int
+	currency int
+	quantity int
+	err      error
+}
+
+func newXLSXWorkbook() (*xlsxWorkbook, error) {
+	wb := &xlsxWorkbook{file: excelize.NewFile()}
+	currency, quantity := xlsxCurrencyFormat, xlsxQuantityFormat
+
+	var err error
+	if wb.bold, err = wb.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return nil, err
+	}
+	if wb.currency, err = wb.file.NewStyle(&excelize.Style{CustomNumFmt: &currency}); err != nil {
+		return nil, err
+	}
+	if wb.quantity, err = wb.file.NewStyle(&excelize.Style{CustomNumFmt: &quantity}); err != nil {
+		return nil, err
+	}
+	return wb, nil
+}
+
+// xlsxCell names the cell at a 1-based column and row
+func xlsxCell(col, row int) string {
+	name, _ := excelize.CoordinatesToCellName(col, row)
+	return name
+}
+
+func (wb *xlsxWorkbook) setErr(err error) {
+	if wb.err == nil && err != nil {
+		wb.err = err
+	}
+}
+
+// addSheet adds a sheet after the existing ones; the summary renames the
+// sheet a new workbook starts with
+func (wb *xlsxWorkbook) addSheet(name string) {
+	if name == XLSXSummarySheet {
+		wb.setErr(wb.file.SetSheetName(wb.file.GetSheetName(0), name))
+		return
+	}
+	_, err := wb.file.NewSheet(name)
+	wb.setErr(err)
+}
+
+// setRow writes values to a row starting at column A
+func (wb *xlsxWorkbook) setRow(sheet string, row int, values ...interface{}) {
+	wb.setErr(wb.file.SetSheetRow(sheet, xlsxCell(1, row), &values))
+}
+
+// styleCells applies a style to the cells of a column from one row to another
+func (wb *xlsxWorkbook) styleCells(sheet string, col, fromRow, toRow, style int) {
+	if toRow < fromRow {
+		return
+	}
+	wb.setErr(wb.file.SetCellStyle(sheet, xlsxCell(col, fromRow), xlsxCell(col, toRow), style))
+}
+
+// setSumRow writes a bold label and, under each of cols, the sum of that
+// column's rows from fromRow to toRow
+func (wb *xlsxWorkbook) setSumRow(sheet string, row int, label string, fromRow, toRow int, cols ...int) {
+	wb.setRow(sheet, row, label)
+	for _, col := range cols {
+		formula := fmt.Sprintf("SUM(%s:%s)", xlsxCell(col, fromRow), xlsxCell(col, toRow))
+		wb.setErr(wb.file.SetCellFormula(sheet, xlsxCell(col, row), formula))
+	}
+	wb.styleCells(sheet, 1, row, row, wb.bold)
+}
+
+func (wb *xlsxWorkbook) setColWidth(sheet, from, to string, width float64) {
+	wb.setErr(wb.file.SetColWidth(sheet, from, to, width))
+}
+
+// writeSummary writes the project details, cost summary and alternate prices
+func (wb *xlsxWorkbook) writeSummary(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string, enabled func(models.PDFSection) bool) {
+	sheet := XLSXSummarySheet
+	wb.addSheet(sheet)
+	wb.setColWidth(sheet, "A", "A", 24)
+	wb.setColWidth(sheet, "B", "B", 40)
+
+	wb.setRow(sheet, 1, "Construction Bid Export")
+	wb.styleCells(sheet, 1, 1, 1, wb.bold)
+	row := 2
+	if enabled(models.PDFSectionProjectInfo) {
+		wb.setRow(sheet, row, "Project", projectName)
+		wb.setRow(sheet, row+1, "Bid ID", bid.ID.String())
+		wb.setRow(sheet, row+2, "Date", time.Now().Format("2006-01-02"))
+		wb.setRow(sheet, row+3, "Status", string(bid.Status))
+		row += 4
+	}
+	if !enabled(models.PDFSectionCostSummary) {
+		return
+	}
+
+	row++
+	wb.setRow(sheet, row, "Cost Summary")
+	wb.styleCells(sheet, 1, row, row, wb.bold)
+	first := row + 1
+	costs := [][]interface{}{
+		{"Material Cost", bidResponse.MaterialCost},
+		{"Labor Cost", bidResponse.LaborCost},
+		{"Subtotal", bidResponse.Subtotal},
+		{"Markup Amount", bidResponse.MarkupAmount},
+	}
+	if tax := bidResponse.Tax; tax != nil {
+		costs = append(costs, []interface{}{"Sales Tax", bidResponse.TaxAmount, tax.Note})
+	}
+	costs = append(costs, []interface{}{"Total Price", bidResponse.TotalPrice})
+	if metrics := bidResponse.UnitMetrics; metrics != nil && metrics.CostPerSF > 0 {
+		costs = append(costs, []interface{}{"Cost per SF", metrics.CostPerSF})
+	}
+	for _, values := range costs {
+		row++
+		wb.setRow(sheet, row, values...)
+	}
+	wb.styleCells(sheet, 2, first, row, wb.currency)
+
+	if len(bidResponse.Alternates) == 0 {
+		return
+	}
+	row += 2
+	wb.setRow(sheet, row, "Alternates")
+	wb.styleCells(sheet, 1, row, row, wb.bold)
+	first = row + 1
+	for _, group := range bidResponse.Alternates {
+		row++
+		wb.setRow(sheet, row, group.Name, group.Price)
+	}
+	wb.styleCells(sheet, 2, first, row, wb.currency)
+}
+
+// writeLineItems writes a header, one row per line item and a totals row
+// that sums the Total column
+func (wb *xlsxWorkbook) writeLineItems(items []models.LineItem) {
+	sheet := XLSXLineItemsSheet
+	wb.addSheet(sheet)
+	wb.setColWidth(sheet, "A", "A", 40)
+	wb.setColWidth(sheet, "B", "F", 14)
+	wb.setColWidth(sheet, "G", "H", 30)
+
+	wb.setRow(sheet, 1, "Description", "Trade", "Quantity", "Unit", "Unit Cost", "Total", "Price Source", "Notes")
+	wb.setErr(wb.file.SetCellStyle(sheet, "A1", "H1", wb.bold))
+	for i, item := range items {
+		wb.setRow(sheet, i+2,
+			item.Description,
+			item.Trade,
+			item.Quantity,
+			item.Unit,
+			item.UnitCost,
+			item.Total,
+			FormatPriceSource(item.PriceSource),
+			item.Notes,
+		)
+	}
+
+	last := len(items) + 1
+	wb.styleCells(sheet, 3, 2, last, wb.quantity)
+	wb.styleCells(sheet, 5, 2, last+1, wb.currency)
+	wb.styleCells(sheet, 6, 2, last+1, wb.currency)
+	wb.setSumRow(sheet, last+1, "Total", 2, last, 6)
+}
+
+// writeTradeBreakdown writes each trade's item count and total, in trade
+// order, and a totals row
+func (wb *xlsxWorkbook) writeTradeBreakdown(items []models.LineItem) {
+	sheet := XLSXTradeSheet
+	wb.addSheet(sheet)
+	wb.setColWidth(sheet, "A", "A", 24)
+	wb.setColWidth(sheet, "B", "C", 14)
+
+	wb.setRow(sheet, 1, "Trade", "Item Count", "Total Cost")
+	wb.setErr(wb.file.SetCellStyle(sheet, "A1", "C1", wb.bold))
+	groups := groupByTrade(items)
+	row := 1
+	for _, trade := range sortedKeys(groups) {
+		total := 0.0
+		for _, item := range groups[trade] {
+			total += item.Total
+		}
+		row++
+		wb.setRow(sheet, row, trade, len(groups[trade]), total)
+	}
+
+	wb.styleCells(sheet, 3, 2, row+1, wb.currency)
+	wb.setSumRow(sheet, row+1, "Total", 2, row, 2, 3)
+}
+
+// writeOpenings writes the door and window schedule
+func (wb *xlsxWorkbook) writeOpenings(schedule []models.OpeningScheduleEntry) {
+	sheet := XLSXOpeningsSheet
+	wb.addSheet(sheet)
+	wb.setColWidth(sheet, "A", "B", 20)
+
+	wb.setRow(sheet, 1, "Type", "Size", "Count")
+	wb.setErr(wb.file.SetCellStyle(sheet, "A1", "C1", wb.bold))
+	for i, entry := range schedule {
+		wb.setRow(sheet, i+2, OpeningClassLabel(entry.Classification), entry.Size, entry.Count)
+	}
+}
+
+// writeTerms writes the scope, inclusions, exclusions, schedule and terms
+// the layout enables, a bold heading over each. The sheet is left out when
+// the bid has none of them.
+func (wb *xlsxWorkbook) writeTerms(bidResponse *models.GenerateBidResponse, enabled func(models.PDFSection) bool) {
+	type section struct {
+		heading string
+		rows    [][]interface{}
+	}
+	lines := func(texts ...string) [][]interface{} {
+		var rows [][]interface{}
+		for _, text := range texts {
+			if strings.TrimSpace(text) != "" {
+				rows = append(rows, []interface{}{text})
+			}
+		}
+		return rows
+	}
+
+	var sections []section
+	if enabled(models.PDFSectionScope) {
+		sections = append(sections, section{"Scope of Work", lines(bidResponse.ScopeOfWork)})
+	}
+	if enabled(models.PDFSectionInclusions) {
+		sections = append(sections, section{"Inclusions", lines(bidResponse.Inclusions...)})
+	}
+	if enabled(models.PDFSectionExclusions) {
+		sections = append(sections, section{"Exclusions", lines(bidResponse.Exclusions...)})
+	}
+	if enabled(models.PDFSectionSchedule) {
+		var phases [][]interface{}
+		for _, phase := range sortedKeys(bidResponse.Schedule) {
+			phases = append(phases, []interface{}{phase, bidResponse.Schedule[phase]})
+		}
+		sections = append(sections, section{"Project Schedule", phases})
+	}
+	if enabled(models.PDFSectionTerms) {
+		sections = append(sections,
+			section{"Payment Terms", lines(bidResponse.PaymentTerms)},
+			section{"Warranty Terms", lines(bidResponse.WarrantyTerms)})
+	}
+
+	sheet := XLSXTermsSheet
+	row := 0
+	for _, s := range sections {
+		if len(s.rows) == 0 {
+			continue
+		}
+		if row == 0 {
+			wb.addSheet(sheet)
+			wb.setColWidth(sheet, "A", "A", 100)
+			wb.setColWidth(sheet, "B", "B", 30)
+		} else {
+			row++
+		}
+		row++
+		wb.setRow(sheet, row, s.heading)
+		wb.styleCells(sheet, 1, row, row, wb.bold)
+		for _, values := range s.rows {
+			row++
+			wb.setRow(sheet, row, values...)
+		}
+	}
+}
+
+// generateBidXLSX builds the Excel workbook of a bid, leaving out the
+// sections layout disables
+func generateBidXLSX(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string, layout *models.PDFLayout) ([]byte, error) {
+	wb, err := newXLSXWorkbook()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create XLSX: %w", err)
+	}
+	defer wb.file.Close()
+	enabled := func(section models.PDFSection) bool {
+		return PDFSectionEnabled(layout, section)
+	}
+
+	wb.writeSummary(bid, bidResponse, projectName, enabled)
+	if len(bidResponse.LineItems) > 0 && enabled(models.PDFSectionLineItems) {
+		wb.writeLineItems(bidResponse.LineItems)
+	}
+	if len(bidResponse.LineItems) > 0 && enabled(models.PDFSectionTradeBreakdown) {
+		wb.writeTradeBreakdown(bidResponse.LineItems)
+	}
+	if len(bidResponse.OpeningSchedule) > 0 {
+		wb.writeOpenings(bidResponse.OpeningSchedule)
+	}
+	wb.writeTerms(bidResponse, enabled)
+	if wb.err != nil {
+		return nil, fmt.Errorf("failed to write XLSX: %w", wb.err)
+	}
+
+	buf, err := wb.file.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write XLSX: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Change categories whose impact is banded by how far a value moved
+var impactBandCategories = []string{"room", "measurement", "material", "quantity", "line_item", "cost"}
+
+// defaultImpactBand is the band every category starts with: any change is at
+// least Medium, and a change of more than 20% is High
+var defaultImpactBand = models.ImpactBand{LowPercent: 0, HighPercent: 20}
+
+// DefaultImpactThresholds are the thresholds used for companies that have not
+// set their own
+func DefaultImpactThresholds() models.ImpactThresholds {
+	thresholds := models.ImpactThresholds{Bands: make(map[string]models.ImpactBand, len(impactBandCategories))}
+	for _, category := range impactBandCategories {
+		thresholds.Bands[category] = defaultImpactBand
+	}
+	return thresholds
+}
+
+// ValidateImpactThresholds checks a company's impact thresholds before they
+// are saved
+func ValidateImpactThresholds(thresholds *models.ImpactThresholds) error {
+	if thresholds == nil {
+		return nil
+	}
+	if thresholds.DollarFloor < 0 {
+		return fmt.Errorf("dollar floor cannot be negative")
+	}
+	for category, band := range thresholds.Bands {
+		if !isImpactBandCategory(category) {
+			return fmt.Errorf("unknown impact category %q", category)
+		}
+		if band.LowPercent < 0 || band.HighPercent < 0 {
+			return fmt.Errorf("impact band for %q cannot be negative", category)
+		}
+		if band.LowPercent > band.HighPercent {
+			return fmt.Errorf("impact band for %q has a low percent above its high percent", category)
+		}
+	}
+	return nil
+}
+
+func isImpactBandCategory(category string) bool {
+	for _, known := range impactBandCategories {
+		if category == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ImpactClassifier labels the changes found between revisions
+type ImpactClassifier struct {
+	thresholds models.ImpactThresholds
+}
+
+// NewImpactClassifier classifies with the default thresholds, overridden by a
+// company's own where it has set them; nil uses the defaults
+func NewImpactClassifier(overrides *models.ImpactThresholds) *ImpactClassifier {
+	thresholds := DefaultImpactThresholds()
+	if overrides != nil {
+		for category, band := range overrides.Bands {
+			thresholds.Bands[category] = band
+		}
+		thresholds.DollarFloor = overrides.DollarFloor
+		thresholds.WeighCostsByFinalPrice = overrides.WeighCostsByFinalPrice
+	}
+	return &ImpactClassifier{thresholds: thresholds}
+}
+
+// Thresholds returns the thresholds the classifier labels by, so a comparison
+// can explain its labels
+func (c *ImpactClassifier) Thresholds() models.ImpactThresholds {
+	thresholds := c.thresholds
+	thresholds.Bands = make(map[string]models.ImpactBand, len(c.thresholds.Bands))
+	for category, band := range c.thresholds.Bands {
+		thresholds.Bands[category] = band
+	}
+	return thresholds
+}
+
+// QuantityChange labels a change in a measured amount, such as a room's area,
+// by its percent change in the category's band. An amount appearing from zero
+// is High; one whose percent change can't be measured is Medium.
+func (c *ImpactClassifier) QuantityChange(category string, oldValue, newValue float64) models.ChangeImpact {
+	switch {
+	case oldValue > 0:
+		return c.band(category, math.Abs(newValue-oldValue)/oldValue*100)
+	case oldValue == 0 && newValue > 0:
+		return models.ImpactHigh
+	default:
+		return models.ImpactMedium
+	}
+}
+
+// CostChange labels a change in a dollar amount. Changes under the dollar
+// floor are Low. When costs are weighed by final price, the change is banded
+// by its share of finalPrice; otherwise it keeps level, the impact of its kind
+// of change.
+func (c *ImpactClassifier) CostChange(category string, level models.ChangeImpact, oldValue, newValue, finalPrice float64) models.ChangeImpact {
+	diff := math.Abs(newValue - oldValue)
+	if diff < c.thresholds.DollarFloor {
+		return models.ImpactLow
+	}
+	if c.thresholds.WeighCostsByFinalPrice && finalPrice > 0 {
+		return c.band(category, diff/finalPrice*100)
+	}
+	return level
+}
+
+func (c *ImpactClassifier) band(category string, percent float64) models.ChangeImpact {
+	band, ok := c.thresholds.Bands[category]
+	if !ok {
+		band = defaultImpactBand
+	}
+	switch {
+	case percent > band.HighPercent:
+		return models.ImpactHigh
+	case percent < band.LowPercent:
+		return models.ImpactLow
+	default:
+		return models.ImpactMedium
+	}
+}
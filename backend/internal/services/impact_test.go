@@ -0,0 +1,187 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestImpactClassifier_QuantityBands(t *testing.T) {
+	defaults := NewImpactClassifier(nil)
+	strict := NewImpactClassifier(&models.ImpactThresholds{
+		Bands: map[string]models.ImpactBand{"room": {LowPercent: 5, HighPercent: 10}},
+	})
+
+	tests := []struct {
+		name       string
+		classifier *ImpactClassifier
+		category   string
+		from, to   float64
+		want       models.ChangeImpact
+	}{
+		{"default unchanged area", defaults, "room", 100, 100, models.ImpactMedium},
+		{"default at the high boundary", defaults, "room", 100, 120, models.ImpactMedium},
+		{"default over the high boundary", defaults, "room", 100, 120.5, models.ImpactHigh},
+		{"default shrinking", defaults, "material", 100, 70, models.ImpactHigh},
+		{"default from zero", defaults, "measurement", 0, 10, models.ImpactHigh},
+		{"default from negative", defaults, "measurement", -5, 10, models.ImpactMedium},
+		{"override under the low boundary", strict, "room", 100, 104, models.ImpactLow},
+		{"override at the low boundary", strict, "room", 100, 105, models.ImpactMedium},
+		{"override at the high boundary", strict, "room", 100, 110, models.ImpactMedium},
+		{"override over the high boundary", strict, "room", 100, 111, models.ImpactHigh},
+		{"override leaves other categories", strict, "material", 100, 111, models.ImpactMedium},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.classifier.QuantityChange(tt.category, tt.from, tt.to); got != tt.want {
+				t.Errorf("QuantityChange(%q, %v, %v) = %s, want %s", tt.category, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImpactClassifier_CostChange(t *testing.T) {
+	defaults := NewImpactClassifier(nil)
+	for _, level := range []models.ChangeImpact{models.ImpactHigh, models.ImpactMedium, models.ImpactLow} {
+		if got := defaults.CostChange("cost", level, 100, 100.01, 10000); got != level {
+			t.Errorf("default CostChange kept %s as %s", level, got)
+		}
+	}
+
+	floored := NewImpactClassifier(&models.ImpactThresholds{DollarFloor: 100})
+	if got := floored.CostChange("line_item", models.ImpactHigh, 50, 0, 10000); got != models.ImpactLow {
+		t.Errorf("removing a $50 line under a $100 floor = %s, want Low", got)
+	}
+	if got := floored.CostChange("line_item", models.ImpactHigh, 100, 0, 10000); got != models.ImpactHigh {
+		t.Errorf("removing a $100 line at a $100 floor = %s, want High", got)
+	}
+
+	weighted := NewImpactClassifier(&models.ImpactThresholds{
+		Bands:                  map[string]models.ImpactBand{"cost": {LowPercent: 1, HighPercent: 5}},
+		DollarFloor:            25,
+		WeighCostsByFinalPrice: true,
+	})
+	tests := []struct {
+		name     string
+		level    models.ChangeImpact
+		from, to float64
+		want     models.ChangeImpact
+	}{
+		{"under the floor", models.ImpactHigh, 1000, 1020, models.ImpactLow},
+		{"under 1% of the final price", models.ImpactHigh, 1000, 1050, models.ImpactLow},
+		{"between the bands", models.ImpactLow, 1000, 1300, models.ImpactMedium},
+		{"over 5% of the final price", models.ImpactLow, 1000, 1600, models.ImpactHigh},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weighted.CostChange("cost", tt.level, tt.from, tt.to, 10000); got != tt.want {
+				t.Errorf("CostChange(%v, %v) = %s, want %s", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+	if got := weighted.CostChange("cost", models.ImpactMedium, 0, 5000, 0); got != models.ImpactMedium {
+		t.Errorf("CostChange without a final price = %s, want the change's own level", got)
+	}
+}
+
+func TestImpactClassifier_Thresholds(t *testing.T) {
+	thresholds := NewImpactClassifier(&models.ImpactThresholds{
+		Bands:       map[string]models.ImpactBand{"cost": {LowPercent: 1, HighPercent: 5}},
+		DollarFloor: 50,
+	}).Thresholds()
+
+	if thresholds.Bands["cost"] != (models.ImpactBand{LowPercent: 1, HighPercent: 5}) {
+		t.Errorf("cost band = %+v, want the override", thresholds.Bands["cost"])
+	}
+	if thresholds.Bands["room"] != defaultImpactBand || len(thresholds.Bands) != len(impactBandCategories) {
+		t.Errorf("bands = %+v, want the defaults under the override", thresholds.Bands)
+	}
+	if thresholds.DollarFloor != 50 || thresholds.WeighCostsByFinalPrice {
+		t.Errorf("thresholds = %+v, want a $50 floor without weighting", thresholds)
+	}
+}
+
+func TestValidateImpactThresholds(t *testing.T) {
+	valid := []*models.ImpactThresholds{
+		nil,
+		{},
+		{Bands: map[string]models.ImpactBand{"line_item": {LowPercent: 2, HighPercent: 2}}, DollarFloor: 100},
+	}
+	for _, thresholds := range valid {
+		if err := ValidateImpactThresholds(thresholds); err != nil {
+			t.Errorf("ValidateImpactThresholds(%+v) error = %v", thresholds, err)
+		}
+	}
+
+	invalid := map[string]*models.ImpactThresholds{
+		"negative floor":   {DollarFloor: -1},
+		"unknown category": {Bands: map[string]models.ImpactBand{"fixture": {HighPercent: 10}}},
+		"negative band":    {Bands: map[string]models.ImpactBand{"room": {LowPercent: -1, HighPercent: 10}}},
+		"inverted band":    {Bands: map[string]models.ImpactBand{"room": {LowPercent: 30, HighPercent: 10}}},
+	}
+	for name, thresholds := range invalid {
+		if err := ValidateImpactThresholds(thresholds); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCompareBidRevisions_ImpactThresholds(t *testing.T) {
+	revision := func(version int, finalPrice float64, items []models.LineItem) *models.BidRevision {
+		data, _ := json.Marshal(models.GenerateBidResponse{LineItems: items, TotalPrice: finalPrice})
+		bidData := string(data)
+		return &models.BidRevision{ID: uuid.New(), Version: version, FinalPrice: &finalPrice, BidData: &bidData}
+	}
+	from := revision(1, 20000, []models.LineItem{
+		{Description: "Caulking", Trade: "general", Quantity: 1, Unit: "LS", UnitCost: 50, Total: 50},
+		{Description: "Framing", Trade: "carpentry", Quantity: 100, Unit: "SF", UnitCost: 10, Total: 1000},
+	})
+	to := revision(2, 20030, []models.LineItem{
+		{Description: "Framing", Trade: "carpentry", Quantity: 100, Unit: "SF", UnitCost: 10.8, Total: 1080},
+	})
+
+	impacts := func(comparison *models.BidComparison) map[string]models.ChangeImpact {
+		byDescription := make(map[string]models.ChangeImpact)
+		for _, change := range comparison.Changes {
+			byDescription[change.Description] = *change.Impact
+		}
+		return byDescription
+	}
+
+	comparison, err := NewComparisonService().CompareBidRevisions(from, to)
+	if err != nil {
+		t.Fatalf("CompareBidRevisions() error = %v", err)
+	}
+	defaults := impacts(comparison)
+	if defaults["general - Caulking removed: was 1.00 LS @ $50.00 = $50.00"] != models.ImpactHigh ||
+		defaults["Final price changed from $20000.00 to $20030.00 (0.15%)"] != models.ImpactHigh {
+		t.Errorf("default impacts = %v, want removals and final price changes High", defaults)
+	}
+	if comparison.ImpactThresholds.DollarFloor != 0 || comparison.ImpactThresholds.Bands["cost"] != defaultImpactBand {
+		t.Errorf("impact_thresholds = %+v, want the defaults", comparison.ImpactThresholds)
+	}
+
+	comparison, err = NewComparisonService().WithImpactThresholds(&models.ImpactThresholds{
+		DollarFloor:            75,
+		WeighCostsByFinalPrice: true,
+		Bands:                  map[string]models.ImpactBand{"line_item": {LowPercent: 0.25, HighPercent: 5}},
+	}).CompareBidRevisions(from, to)
+	if err != nil {
+		t.Fatalf("CompareBidRevisions() error = %v", err)
+	}
+	company := impacts(comparison)
+	if got := company["general - Caulking removed: was 1.00 LS @ $50.00 = $50.00"]; got != models.ImpactLow {
+		t.Errorf("$50 removal under a $75 floor = %s, want Low", got)
+	}
+	if got := company["Final price changed from $20000.00 to $20030.00 (0.15%)"]; got != models.ImpactLow {
+		t.Errorf("$30 final price change under a $75 floor = %s, want Low", got)
+	}
+	if got := company["carpentry - Framing: total changed from $1000.00 to $1080.00"]; got != models.ImpactMedium {
+		t.Errorf("$80 line change, 0.4%% of the final price = %s, want Medium", got)
+	}
+	if comparison.Summary.HighImpactCount != 0 || !comparison.ImpactThresholds.WeighCostsByFinalPrice {
+		t.Errorf("summary = %+v, thresholds = %+v; want no High changes under the company thresholds", comparison.Summary, comparison.ImpactThresholds)
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Job progress checkpoints reported by the worker, in percent
+const (
+	ProgressBlueprintLoaded = 10
+	ProgressAISubmitted     = 20
+	ProgressAIMax           = 90
+	ProgressPostProcessing  = 95
+	ProgressComplete        = 100
+)
+
+// DefaultProgressWriteInterval is the minimum time between progress writes
+// for one job
+const DefaultProgressWriteInterval = 2 * time.Second
+
+// DefaultExpectedAnalysisDuration is assumed for AI analysis progress when
+// no completed jobs of the type exist yet
+const DefaultExpectedAnalysisDuration = 90 * time.Second
+
+// JobProgressStore persists a job's progress
+type JobProgressStore interface {
+	UpdateProgress(ctx context.Context, id uuid.UUID, progress int, message string) error
+}
+
+// JobProgress tracks one job's progress and keeps the job's progress fields
+// current, so later job updates persist it. Reported values never decrease,
+// and writes to the store are throttled to one per interval; the latest
+// report inside the interval is kept and written by the next report or Flush.
+type JobProgress struct {
+	store    JobProgressStore
+	job      *models.Job
+	interval time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	pending   bool
+	lastWrite time.Time
+}
+
+// NewJobProgress creates a tracker starting from the job's current progress
+func NewJobProgress(store JobProgressStore, job *models.Job, interval time.Duration) *JobProgress {
+	return &JobProgress{
+		store:    store,
+		job:      job,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Report records progress (clamped to 0-100) with a phase message. Reports
+// below the current progress are ignored. Write failures are logged rather
+// than failing the job.
+func (p *JobProgress) Report(ctx context.Context, progress int, message string) {
+	progress = min(max(progress, 0), ProgressComplete)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if progress < p.job.Progress || (progress == p.job.Progress && p.job.ProgressMessage != nil && message == *p.job.ProgressMessage) {
+		return
+	}
+	p.job.Progress = progress
+	p.job.ProgressMessage = &message
+	p.pending = true
+
+	if !p.lastWrite.IsZero() && p.now().Sub(p.lastWrite) < p.interval {
+		return
+	}
+	p.write(ctx)
+}
+
+// Flush writes a report held back by the throttle
+func (p *JobProgress) Flush(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pending {
+		p.write(ctx)
+	}
+}
+
+func (p *JobProgress) write(ctx context.Context) {
+	p.lastWrite = p.now()
+	p.pending = false
+	if err := p.store.UpdateProgress(ctx, p.job.ID, p.job.Progress, *p.job.ProgressMessage); err != nil {
+		slog.Error("Failed to update job progress", "job_id", p.job.ID, "progress", p.job.Progress, "error", err)
+	}
+}
+
+// TrackAIProgress reports synthesized AI-phase progress every interval until
+// the returned stop function is called. stop waits for the reporter to exit,
+// so the job can be updated safely afterwards.
+func (p *JobProgress) TrackAIProgress(ctx context.Context, expected time.Duration, message string) (stop func()) {
+	started := p.now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Report(ctx, EstimateAIProgress(p.now().Sub(started), expected), message)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// EstimateAIProgress synthesizes progress within the AI phase from the time
+// spent so far against the expected duration, since the AI service reports
+// no incremental progress. It moves linearly from ProgressAISubmitted to
+// ProgressAIMax and holds there once the expected duration is exceeded.
+func EstimateAIProgress(elapsed, expected time.Duration) int {
+	if expected <= 0 {
+		expected = DefaultExpectedAnalysisDuration
+	}
+	fraction := min(max(float64(elapsed)/float64(expected), 0), 1)
+	return ProgressAISubmitted + int(fraction*float64(ProgressAIMax-ProgressAISubmitted))
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type progressWrite struct {
+	at       time.Time
+	progress int
+	message  string
+}
+
+// fakeProgressStore applies the repository's rule that stored progress never
+// decreases and records every accepted write
+type fakeProgressStore struct {
+	clock  *fakeClock
+	writes []progressWrite
+	err    error
+}
+
+func (f *fakeProgressStore) UpdateProgress(ctx context.Context, id uuid.UUID, progress int, message string) error {
+	if f.err != nil {
+		return f.err
+	}
+	if n := len(f.writes); n > 0 && progress < f.writes[n-1].progress {
+		return nil
+	}
+	f.writes = append(f.writes, progressWrite{at: f.clock.Now(), progress: progress, message: message})
+	return nil
+}
+
+func newTestJobProgress() (*JobProgress, *fakeProgressStore, *fakeClock, *models.Job) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	store := &fakeProgressStore{clock: clock}
+	job := &models.Job{ID: uuid.New(), JobType: models.JobTypeTakeoff}
+	progress := NewJobProgress(store, job, DefaultProgressWriteInterval)
+	progress.now = clock.Now
+	return progress, store, clock, job
+}
+
+func TestJobProgress_PhasesPersistNonDecreasingProgress(t *testing.T) {
+	progress, store, clock, job := newTestJobProgress()
+	ctx := context.Background()
+	expected := time.Minute
+
+	// Simulate the worker's phase callbacks, including a stale estimate that
+	// arrives after post-processing started
+	progress.Report(ctx, ProgressBlueprintLoaded, "Blueprint loaded")
+	clock.Advance(500 * time.Millisecond)
+	progress.Report(ctx, ProgressAISubmitted, "Submitted for AI analysis")
+	for elapsed := time.Duration(0); elapsed <= 90*time.Second; elapsed += time.Second {
+		clock.Advance(time.Second)
+		progress.Report(ctx, EstimateAIProgress(elapsed, expected), "Analyzing blueprint")
+	}
+	clock.Advance(time.Second)
+	progress.Report(ctx, ProgressPostProcessing, "Storing analysis results")
+	progress.Report(ctx, EstimateAIProgress(30*time.Second, expected), "Analyzing blueprint")
+	progress.Flush(ctx)
+
+	if len(store.writes) < 2 {
+		t.Fatalf("expected several progress writes, got %d", len(store.writes))
+	}
+	for i := 1; i < len(store.writes); i++ {
+		prev, cur := store.writes[i-1], store.writes[i]
+		if cur.progress < prev.progress {
+			t.Errorf("write %d decreased progress from %d to %d", i, prev.progress, cur.progress)
+		}
+		if gap := cur.at.Sub(prev.at); gap < DefaultProgressWriteInterval {
+			t.Errorf("write %d came %v after the previous one, want at least %v", i, gap, DefaultProgressWriteInterval)
+		}
+	}
+
+	last := store.writes[len(store.writes)-1]
+	if last.progress != ProgressPostProcessing || last.message != "Storing analysis results" {
+		t.Errorf("last write = %d %q, want %d post-processing", last.progress, last.message, ProgressPostProcessing)
+	}
+	if job.Progress != ProgressPostProcessing || job.ProgressMessage == nil || *job.ProgressMessage != "Storing analysis results" {
+		t.Errorf("job progress = %d %v, want the latest report", job.Progress, job.ProgressMessage)
+	}
+}
+
+func TestJobProgress_ThrottlesWrites(t *testing.T) {
+	progress, store, clock, _ := newTestJobProgress()
+	ctx := context.Background()
+
+	progress.Report(ctx, ProgressBlueprintLoaded, "Blueprint loaded")
+	progress.Report(ctx, ProgressAISubmitted, "Submitted for AI analysis")
+	if len(store.writes) != 1 {
+		t.Fatalf("expected the second report to be held back, got %d writes", len(store.writes))
+	}
+
+	clock.Advance(DefaultProgressWriteInterval)
+	progress.Report(ctx, 25, "Analyzing blueprint")
+	if len(store.writes) != 2 || store.writes[1].progress != 25 {
+		t.Fatalf("expected the next report after the interval to be written, got %+v", store.writes)
+	}
+
+	// Repeats and lower values are not reports at all
+	progress.Flush(ctx)
+	clock.Advance(DefaultProgressWriteInterval)
+	progress.Report(ctx, 25, "Analyzing blueprint")
+	progress.Report(ctx, 22, "Analyzing blueprint")
+	progress.Flush(ctx)
+	if len(store.writes) != 2 {
+		t.Errorf("expected no writes for repeated or lower progress, got %+v", store.writes)
+	}
+}
+
+func TestJobProgress_ClampsAndSurvivesStoreErrors(t *testing.T) {
+	progress, store, _, job := newTestJobProgress()
+	store.err = errors.New("db down")
+
+	progress.Report(context.Background(), 140, "Completed")
+	if job.Progress != ProgressComplete {
+		t.Errorf("job progress = %d, want clamped to %d", job.Progress, ProgressComplete)
+	}
+}
+
+func TestEstimateAIProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		expected time.Duration
+		want     int
+	}{
+		{"just submitted", 0, time.Minute, ProgressAISubmitted},
+		{"halfway", 30 * time.Second, time.Minute, 55},
+		{"at expected duration", time.Minute, time.Minute, ProgressAIMax},
+		{"overdue holds", 5 * time.Minute, time.Minute, ProgressAIMax},
+		{"no history uses default", DefaultExpectedAnalysisDuration / 2, 0, 55},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateAIProgress(tt.elapsed, tt.expected); got != tt.want {
+				t.Errorf("EstimateAIProgress(%v, %v) = %d, want %d", tt.elapsed, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxLineItemNotesLength caps the notes on one line item, in characters
+const MaxLineItemNotesLength = 500
+
+// ValidateLineItemNotes rejects line items whose notes are longer than
+// MaxLineItemNotesLength
+func ValidateLineItemNotes(items []models.LineItem) error {
+	for _, item := range items {
+		if utf8.RuneCountInString(item.Notes) > MaxLineItemNotesLength {
+			return fmt.Errorf("notes for line item %q exceed %d characters", item.Description, MaxLineItemNotesLength)
+		}
+	}
+	return nil
+}
+
+// TrimLineItemNotes trims whitespace from the notes on a bid's line items,
+// including alternates, and cuts notes longer than MaxLineItemNotesLength.
+// Generated bids go through it so an overlong AI note never blocks a bid. It
+// reports whether any notes changed.
+func TrimLineItemNotes(bid *models.GenerateBidResponse) bool {
+	changed := false
+	trim := func(items []models.LineItem) {
+		for i := range items {
+			notes := strings.TrimSpace(items[i].Notes)
+			if runes := []rune(notes); len(runes) > MaxLineItemNotesLength {
+				notes = strings.TrimSpace(string(runes[:MaxLineItemNotesLength]))
+			}
+			if notes != items[i].Notes {
+				items[i].Notes = notes
+				changed = true
+			}
+		}
+	}
+
+	trim(bid.LineItems)
+	for g := range bid.Alternates {
+		trim(bid.Alternates[g].LineItems)
+	}
+	return changed
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestValidateLineItemNotes(t *testing.T) {
+	items := []models.LineItem{{Description: "Panel upgrade", Notes: strings.Repeat("é", MaxLineItemNotesLength)}}
+	if err := ValidateLineItemNotes(items); err != nil {
+		t.Errorf("notes of exactly %d characters should pass, got %v", MaxLineItemNotesLength, err)
+	}
+
+	items[0].Notes += "x"
+	if err := ValidateLineItemNotes(items); err == nil {
+		t.Error("expected notes over the limit to be rejected")
+	}
+}
+
+func TestTrimLineItemNotes(t *testing.T) {
+	bid := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Panel upgrade", Notes: "  Assumes existing panel has capacity \n"},
+			{Description: "Outlets"},
+		},
+		Alternates: []models.AlternateGroup{{
+			Name:      "Alt 1",
+			LineItems: []models.LineItem{{Description: "EV charger", Notes: strings.Repeat("a", MaxLineItemNotesLength+20)}},
+		}},
+	}
+
+	if !TrimLineItemNotes(bid) {
+		t.Fatal("expected notes to change")
+	}
+	if bid.LineItems[0].Notes != "Assumes existing panel has capacity" {
+		t.Errorf("expected whitespace trimmed, got %q", bid.LineItems[0].Notes)
+	}
+	if got := len(bid.Alternates[0].LineItems[0].Notes); got != MaxLineItemNotesLength {
+		t.Errorf("expected alternate notes cut to %d characters, got %d", MaxLineItemNotesLength, got)
+	}
+	if TrimLineItemNotes(bid) {
+		t.Error("expected trimmed notes to be left alone")
+	}
+}
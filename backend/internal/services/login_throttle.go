@@ -0,0 +1,163 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginThrottleConfig controls how repeated failed logins lock an account
+type LoginThrottleConfig struct {
+	MaxFailures int           // consecutive failures before a lockout
+	BaseLockout time.Duration // duration of the first lockout
+	MaxLockout  time.Duration // cap for the doubling lockout duration
+}
+
+// DefaultLoginThrottleConfig returns the standard lockout policy:
+// 5 failures locks for 1 minute, doubling on each repeat up to 1 hour.
+func DefaultLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		MaxFailures: 5,
+		BaseLockout: time.Minute,
+		MaxLockout:  time.Hour,
+	}
+}
+
+type loginAttempts struct {
+	failures    int
+	lockouts    int
+	lockedUntil time.Time
+	lastSeen    time.Time
+}
+
+// LoginThrottle tracks failed login attempts per account and applies an
+// exponential lockout. State is kept in memory, keyed by normalized email,
+// so unknown accounts are throttled exactly like real ones.
+type LoginThrottle struct {
+	config    LoginThrottleConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	attempts  map[string]*loginAttempts
+	lastPrune time.Time
+}
+
+// NewLoginThrottle creates a login throttle. A nil clock defaults to time.Now.
+func NewLoginThrottle(config LoginThrottleConfig, now func() time.Time) *LoginThrottle {
+	defaults := DefaultLoginThrottleConfig()
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = defaults.MaxFailures
+	}
+	if config.BaseLockout <= 0 {
+		config.BaseLockout = defaults.BaseLockout
+	}
+	if config.MaxLockout < config.BaseLockout {
+		config.MaxLockout = config.BaseLockout
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	return &LoginThrottle{
+		config:    config,
+		now:       now,
+		attempts:  make(map[string]*loginAttempts),
+		lastPrune: now(),
+	}
+}
+
+// Check reports whether the account is currently locked and for how long
+func (t *LoginThrottle) Check(account string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.attempts[normalizeAccount(account)]
+	if !ok {
+		return 0, false
+	}
+
+	remaining := entry.lockedUntil.Sub(t.now())
+	if remaining <= 0 {
+		return 0, false
+	}
+	return remaining, true
+}
+
+// RecordFailure registers a failed login. It returns the lockout duration
+// when this failure triggers a lockout, or zero otherwise.
+func (t *LoginThrottle) RecordFailure(account string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.pruneLocked(now)
+
+	key := normalizeAccount(account)
+	entry, ok := t.attempts[key]
+	if !ok || t.isStale(entry, now) {
+		entry = &loginAttempts{}
+		t.attempts[key] = entry
+	}
+	entry.lastSeen = now
+
+	if now.Before(entry.lockedUntil) {
+		return 0
+	}
+
+	entry.failures++
+	if entry.failures < t.config.MaxFailures {
+		return 0
+	}
+
+	entry.failures = 0
+	entry.lockouts++
+	lockout := t.lockoutDuration(entry.lockouts)
+	entry.lockedUntil = now.Add(lockout)
+	return lockout
+}
+
+// Reset clears all failure state for the account after a successful login
+func (t *LoginThrottle) Reset(account string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.attempts, normalizeAccount(account))
+}
+
+// lockoutDuration doubles the base lockout for each repeat, up to the cap
+func (t *LoginThrottle) lockoutDuration(lockouts int) time.Duration {
+	duration := t.config.BaseLockout
+	for i := 1; i < lockouts; i++ {
+		duration *= 2
+		if duration >= t.config.MaxLockout {
+			return t.config.MaxLockout
+		}
+	}
+	return duration
+}
+
+// isStale reports whether an entry has been quiet long enough that its
+// escalation history should be forgotten
+func (t *LoginThrottle) isStale(entry *loginAttempts, now time.Time) bool {
+	lastActive := entry.lastSeen
+	if entry.lockedUntil.After(lastActive) {
+		lastActive = entry.lockedUntil
+	}
+	return now.Sub(lastActive) > t.config.MaxLockout
+}
+
+// pruneLocked drops stale entries to bound memory; caller must hold t.mu
+func (t *LoginThrottle) pruneLocked(now time.Time) {
+	if now.Sub(t.lastPrune) < t.config.MaxLockout {
+		return
+	}
+	for key, entry := range t.attempts {
+		if t.isStale(entry, now) {
+			delete(t.attempts, key)
+		}
+	}
+	t.lastPrune = now
+}
+
+func normalizeAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
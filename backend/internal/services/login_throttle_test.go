@@ -0,0 +1,128 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestThrottle() (*LoginThrottle, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	throttle := NewLoginThrottle(LoginThrottleConfig{
+		MaxFailures: 5,
+		BaseLockout: time.Minute,
+		MaxLockout:  4 * time.Minute,
+	}, clock.Now)
+	return throttle, clock
+}
+
+func failN(throttle *LoginThrottle, account string, n int) time.Duration {
+	var lockout time.Duration
+	for i := 0; i < n; i++ {
+		lockout = throttle.RecordFailure(account)
+	}
+	return lockout
+}
+
+func TestLoginThrottle_LocksAfterMaxFailures(t *testing.T) {
+	throttle, clock := newTestThrottle()
+
+	if lockout := failN(throttle, "user@example.com", 4); lockout != 0 {
+		t.Fatalf("Expected no lockout after 4 failures, got %v", lockout)
+	}
+	if _, locked := throttle.Check("user@example.com"); locked {
+		t.Fatal("Account should not be locked after 4 failures")
+	}
+
+	if lockout := throttle.RecordFailure("user@example.com"); lockout != time.Minute {
+		t.Fatalf("Expected 1m lockout on 5th failure, got %v", lockout)
+	}
+
+	remaining, locked := throttle.Check("USER@example.com ")
+	if !locked || remaining != time.Minute {
+		t.Fatalf("Expected account locked for 1m, got locked=%v remaining=%v", locked, remaining)
+	}
+
+	clock.Advance(time.Minute)
+	if _, locked := throttle.Check("user@example.com"); locked {
+		t.Fatal("Lockout should expire after 1m")
+	}
+}
+
+func TestLoginThrottle_LockoutDoublesToCap(t *testing.T) {
+	throttle, clock := newTestThrottle()
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute}
+	for i, want := range expected {
+		if got := failN(throttle, "user@example.com", 5); got != want {
+			t.Fatalf("Lockout %d: expected %v, got %v", i+1, want, got)
+		}
+		clock.Advance(want)
+	}
+}
+
+func TestLoginThrottle_FailuresDuringLockoutIgnored(t *testing.T) {
+	throttle, clock := newTestThrottle()
+
+	failN(throttle, "user@example.com", 5)
+	if lockout := failN(throttle, "user@example.com", 10); lockout != 0 {
+		t.Fatalf("Failures while locked should not extend the lockout, got %v", lockout)
+	}
+
+	clock.Advance(time.Minute)
+	if lockout := failN(throttle, "user@example.com", 4); lockout != 0 {
+		t.Fatalf("Expected failure count to restart after lockout, got %v", lockout)
+	}
+}
+
+func TestLoginThrottle_ResetClearsState(t *testing.T) {
+	throttle, clock := newTestThrottle()
+
+	failN(throttle, "user@example.com", 5)
+	clock.Advance(time.Minute)
+	failN(throttle, "user@example.com", 3)
+
+	throttle.Reset("user@example.com")
+
+	if lockout := failN(throttle, "user@example.com", 4); lockout != 0 {
+		t.Fatalf("Expected no lockout after reset, got %v", lockout)
+	}
+	if lockout := throttle.RecordFailure("user@example.com"); lockout != time.Minute {
+		t.Fatalf("Expected escalation to restart at 1m after reset, got %v", lockout)
+	}
+}
+
+func TestLoginThrottle_AccountsIndependent(t *testing.T) {
+	throttle, _ := newTestThrottle()
+
+	failN(throttle, "a@example.com", 5)
+
+	if _, locked := throttle.Check("a@example.com"); !locked {
+		t.Fatal("Expected a@example.com to be locked")
+	}
+	if _, locked := throttle.Check("b@example.com"); locked {
+		t.Fatal("b@example.com should not be affected by another account's failures")
+	}
+}
+
+func TestLoginThrottle_EscalationForgottenAfterQuietPeriod(t *testing.T) {
+	throttle, clock := newTestThrottle()
+
+	failN(throttle, "user@example.com", 5)
+	clock.Advance(time.Minute)
+	failN(throttle, "user@example.com", 5)
+
+	// Quiet for longer than the cap after the second lockout expires
+	clock.Advance(2*time.Minute + 5*time.Minute)
+
+	if lockout := failN(throttle, "user@example.com", 5); lockout != time.Minute {
+		t.Fatalf("Expected escalation to restart at 1m after quiet period, got %v", lockout)
+	}
+}
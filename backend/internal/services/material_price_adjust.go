@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxBulkAdjustPercent bounds a single bulk price adjustment in either direction
+const MaxBulkAdjustPercent = 50.0
+
+// ErrInvalidAdjustment is returned for a percent that is zero or out of bounds
+var ErrInvalidAdjustment = errors.New("invalid price adjustment")
+
+// MaterialPriceStore applies bulk adjustments to stored material prices
+type MaterialPriceStore interface {
+	BulkAdjustPrices(ctx context.Context, filter models.MaterialPriceFilter, factor float64, dryRun bool) (*models.PriceAdjustmentStats, error)
+}
+
+// MaterialsCacheInvalidator drops cached material lookups
+type MaterialsCacheInvalidator interface {
+	InvalidateMaterialsCache(ctx context.Context)
+}
+
+// LaborRatesCacheInvalidator drops cached labor rate lookups
+type LaborRatesCacheInvalidator interface {
+	InvalidateLaborRatesCache(ctx context.Context)
+}
+
+// MaterialPriceAdjuster applies percentage bumps to material prices in bulk
+type MaterialPriceAdjuster struct {
+	store  MaterialPriceStore
+	events events.Publisher
+}
+
+// NewMaterialPriceAdjuster creates an adjuster that publishes applied
+// adjustments on publisher
+func NewMaterialPriceAdjuster(store MaterialPriceStore, publisher events.Publisher) *MaterialPriceAdjuster {
+	return &MaterialPriceAdjuster{
+		store:  store,
+		events: publisher,
+	}
+}
+
+// ValidateAdjustPercent checks a bulk adjustment percent is non-zero and within ±MaxBulkAdjustPercent
+func ValidateAdjustPercent(percent float64) error {
+	if percent == 0 {
+		return fmt.Errorf("%w: percent must not be zero", ErrInvalidAdjustment)
+	}
+	if percent < -MaxBulkAdjustPercent || percent > MaxBulkAdjustPercent {
+		return fmt.Errorf("%w: percent must be between -%.0f and %.0f", ErrInvalidAdjustment, MaxBulkAdjustPercent, MaxBulkAdjustPercent)
+	}
+	return nil
+}
+
+// Adjust multiplies matching material prices by (1 + percent/100). A dry run
+// returns the same stats without writing. Applied adjustments publish
+// MaterialPricesAdjusted, which invalidates the materials cache and records
+// the audit event.
+func (a *MaterialPriceAdjuster) Adjust(ctx context.Context, actorID string, filter models.MaterialPriceFilter, percent float64, dryRun bool) (*models.PriceAdjustmentStats, error) {
+	if err := ValidateAdjustPercent(percent); err != nil {
+		return nil, err
+	}
+
+	stats, err := a.store.BulkAdjustPrices(ctx, filter, 1+percent/100, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return stats, nil
+	}
+
+	a.events.Publish(ctx, events.MaterialPricesAdjusted{
+		UserID:       actorID,
+		Percent:      percent,
+		Filter:       filter,
+		RowsAffected: stats.RowsAffected,
+	})
+
+	return stats, nil
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
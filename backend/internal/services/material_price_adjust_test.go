@@ -0,0 +1,120 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type fakePriceStore struct {
+	filter models.MaterialPriceFilter
+	factor float64
+	dryRun bool
+	calls  int
+	stats  models.PriceAdjustmentStats
+}
+
+func (f *fakePriceStore) BulkAdjustPrices(ctx context.Context, filter models.MaterialPriceFilter, factor float64, dryRun bool) (*models.PriceAdjustmentStats, error) {
+	f.calls++
+	f.filter, f.factor, f.dryRun = filter, factor, dryRun
+	stats := f.stats
+	stats.DryRun = dryRun
+	return &stats, nil
+}
+
+type fakeMaterialsCache struct {
+	invalidations int
+}
+
+func (f *fakeMaterialsCache) InvalidateMaterialsCache(ctx context.Context) { f.invalidations++ }
+
+// newTestAdjuster wires the adjuster to a bus with the production
+// subscribers. Audit lines are written asynchronously, so tests call
+// bus.Wait before reading logs.
+func newTestAdjuster() (*MaterialPriceAdjuster, *fakePriceStore, *fakeMaterialsCache, *bytes.Buffer, *events.Bus) {
+	store := &fakePriceStore{stats: models.PriceAdjustmentStats{RowsAffected: 3, MinPrice: 1.62, MaxPrice: 486, AvgPrice: 200}}
+	cache := &fakeMaterialsCache{}
+	var logs bytes.Buffer
+	bus := events.NewBus()
+	NewAuditSubscriber(slog.New(slog.NewJSONHandler(&logs, nil))).Register(bus)
+	RegisterCacheInvalidation(bus, cache)
+	return NewMaterialPriceAdjuster(store, bus), store, cache, &logs, bus
+}
+
+func TestMaterialPriceAdjuster_AppliesFilteredAdjustment(t *testing.T) {
+	adjuster, store, cache, logs, bus := newTestAdjuster()
+	filter := models.MaterialPriceFilter{Category: strPtr("lumber"), Source: strPtr("custom")}
+
+	stats, err := adjuster.Adjust(context.Background(), "admin-1", filter, 8, false)
+	if err != nil {
+		t.Fatalf("Adjust() error = %v", err)
+	}
+
+	if store.factor != 1.08 || store.dryRun {
+		t.Errorf("Expected factor 1.08 written, got factor=%v dryRun=%v", store.factor, store.dryRun)
+	}
+	if *store.filter.Category != "lumber" || *store.filter.Source != "custom" || store.filter.Region != nil {
+		t.Errorf("Filter not passed through: %+v", store.filter)
+	}
+	if stats.RowsAffected != 3 || stats.MaxPrice != 486 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	// Invalidation is synchronous, so it has happened before Adjust returns
+	if cache.invalidations != 1 {
+		t.Errorf("Expected materials cache to be invalidated once, got %d", cache.invalidations)
+	}
+
+	bus.Wait()
+	var event map[string]interface{}
+	if err := json.Unmarshal(logs.Bytes(), &event); err != nil {
+		t.Fatalf("Expected one audit log line, got %q", logs.String())
+	}
+	if event["audit_event"] != "materials.bulk_adjust" || event["rows_affected"] != float64(3) || event["user_id"] != "admin-1" {
+		t.Errorf("Unexpected audit event: %v", event)
+	}
+}
+
+func TestMaterialPriceAdjuster_DryRun(t *testing.T) {
+	adjuster, store, cache, logs, bus := newTestAdjuster()
+
+	stats, err := adjuster.Adjust(context.Background(), "admin-1", models.MaterialPriceFilter{}, -10, true)
+	if err != nil {
+		t.Fatalf("Adjust() error = %v", err)
+	}
+
+	if !store.dryRun || store.factor != 0.9 {
+		t.Errorf("Expected dry run preview at factor 0.9, got factor=%v dryRun=%v", store.factor, store.dryRun)
+	}
+	if !stats.DryRun || stats.RowsAffected != 3 {
+		t.Errorf("Expected dry run stats, got %+v", stats)
+	}
+	bus.Wait()
+	if cache.invalidations != 0 || logs.Len() != 0 {
+		t.Error("Dry run must not invalidate caches or record an audit event")
+	}
+}
+
+func TestMaterialPriceAdjuster_PercentBounds(t *testing.T) {
+	adjuster, store, _, _, _ := newTestAdjuster()
+
+	for _, percent := range []float64{0, 50.1, -51, 200} {
+		if _, err := adjuster.Adjust(context.Background(), "admin-1", models.MaterialPriceFilter{}, percent, false); !errors.Is(err, ErrInvalidAdjustment) {
+			t.Errorf("Adjust(%v) error = %v, want ErrInvalidAdjustment", percent, err)
+		}
+	}
+	if store.calls != 0 {
+		t.Errorf("Invalid adjustments must not reach the store, got %d calls", store.calls)
+	}
+
+	for _, percent := range []float64{50, -50} {
+		if _, err := adjuster.Adjust(context.Background(), "admin-1", models.MaterialPriceFilter{}, percent, true); err != nil {
+			t.Errorf("Adjust(%v) error = %v, want nil", percent, err)
+		}
+	}
+}
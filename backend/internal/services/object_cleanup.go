@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// objectCleanupBatchSize bounds the deletions processed per worker poll
+const objectCleanupBatchSize = 50
+
+// ObjectDeletionStore lists and clears scheduled object deletions
+type ObjectDeletionStore interface {
+	GetDue(ctx context.Context, now time.Time, limit int) ([]*models.ObjectDeletion, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// ObjectDeleter deletes objects from storage
+type ObjectDeleter interface {
+	DeleteFile(ctx context.Context, key string) error
+}
+
+// ObjectCleaner deletes scheduled objects whose retention window has passed
+type ObjectCleaner struct {
+	deletions ObjectDeletionStore
+	objects   ObjectDeleter
+	now       func() time.Time
+}
+
+func NewObjectCleaner(deletions ObjectDeletionStore, objects ObjectDeleter) *ObjectCleaner {
+	return &ObjectCleaner{deletions: deletions, objects: objects, now: time.Now}
+}
+
+// RunOnce deletes one batch of due objects and returns how many were removed.
+// Failed deletions stay scheduled and are retried on the next run.
+func (c *ObjectCleaner) RunOnce(ctx context.Context) int {
+	due, err := c.deletions.GetDue(ctx, c.now(), objectCleanupBatchSize)
+	if err != nil {
+		slog.Error("Failed to get due object deletions", "error", err)
+		return 0
+	}
+
+	removed := 0
+	for _, deletion := range due {
+		if err := c.objects.DeleteFile(ctx, deletion.S3Key); err != nil {
+			slog.Error("Failed to delete superseded object", "s3_key", deletion.S3Key, "error", err)
+			continue
+		}
+		if err := c.deletions.Delete(ctx, deletion.ID); err != nil {
+			slog.Error("Failed to clear object deletion", "id", deletion.ID, "error", err)
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		slog.Info("Deleted superseded objects", "count", removed)
+	}
+	return removed
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Widths used to classify doors that are not labelled interior or exterior.
+// Interior doors run up to 36"; anything wider on a single leaf is taken to
+// be an entry door, and 60" or more a sliding patio door.
+const (
+	maxInteriorDoorWidthInches = 36.0
+	minSlidingDoorWidthInches  = 60.0
+)
+
+var (
+	// architecturalSizePattern matches shorthand such as 3068, i.e. 3'0" x 6'8"
+	architecturalSizePattern = regexp.MustCompile(`^(\d)(\d)(\d)(\d)$`)
+
+	slidingDoorWords  = []string{"sliding", "slider", "patio", "glider"}
+	exteriorDoorWords = []string{"exterior", "entry", "front", "back door", "rear", "garage"}
+	interiorDoorWords = []string{"interior", "closet", "bedroom", "bathroom", "pocket", "bifold", "bi-fold"}
+)
+
+// ParseOpeningSize reads an opening size as width and height in inches. It
+// accepts inches ("36x80", `36" x 80"`), feet and inches (`3'-0" x 6'-8"`)
+// and architectural shorthand ("3068"). Bare numbers are inches unless both
+// are under a foot, as in "3x7", when they are feet.
+func ParseOpeningSize(size string) (width, height float64, ok bool) {
+	size = strings.TrimSpace(size)
+	if match := architecturalSizePattern.FindStringSubmatch(size); match != nil {
+		widthFeet, _ := strconv.Atoi(match[1])
+		widthInches, _ := strconv.Atoi(match[2])
+		heightFeet, _ := strconv.Atoi(match[3])
+		heightInches, _ := strconv.Atoi(match[4])
+		return float64(widthFeet*12 + widthInches), float64(heightFeet*12 + heightInches), true
+	}
+
+	parts := dimensionSplit.Split(size, -1)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	bare := barePattern.MatchString(strings.TrimSpace(parts[0])) && barePattern.MatchString(strings.TrimSpace(parts[1]))
+	if bare {
+		width, _ = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		height, _ = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if width < 12 && height < 12 {
+			width, height = width*12, height*12
+		}
+	} else {
+		widthFeet, widthOK := parseFeet(parts[0], false)
+		heightFeet, heightOK := parseFeet(parts[1], false)
+		if !widthOK || !heightOK {
+			return 0, 0, false
+		}
+		width, height = widthFeet*12, heightFeet*12
+	}
+
+	if width <= 0 || height <= 0 {
+		return 0, 0, false
+	}
+	return math.Round(width*100) / 100, math.Round(height*100) / 100, true
+}
+
+// ClassifyOpening classifies an opening from its type and details, falling
+// back to its width for doors that are not labelled. width is nil when the
+// size could not be parsed.
+func ClassifyOpening(opening models.Opening, width *float64) models.OpeningClass {
+	label := strings.ToLower(opening.OpeningType)
+	if opening.Details != nil {
+		label += " " + strings.ToLower(*opening.Details)
+	}
+	containsAny := func(words []string) bool {
+		for _, word := range words {
+			if strings.Contains(label, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case strings.Contains(strings.ToLower(opening.OpeningType), "window"):
+		return models.OpeningClassWindow
+	case !strings.Contains(label, "door"):
+		return models.OpeningClassOther
+	case containsAny(slidingDoorWords):
+		return models.OpeningClassSlidingDoor
+	case containsAny(exteriorDoorWords):
+		return models.OpeningClassExteriorDoor
+	case containsAny(interiorDoorWords) || width == nil:
+		return models.OpeningClassInteriorDoor
+	case *width >= minSlidingDoorWidthInches:
+		return models.OpeningClassSlidingDoor
+	case *width > maxInteriorDoorWidthInches:
+		return models.OpeningClassExteriorDoor
+	default:
+		return models.OpeningClassInteriorDoor
+	}
+}
+
+// NormalizeOpenings fills in each opening's parsed size and classification
+func NormalizeOpenings(openings []models.Opening) {
+	for i := range openings {
+		opening := &openings[i]
+		opening.WidthInches, opening.HeightInches = nil, nil
+		if width, height, ok := ParseOpeningSize(opening.Size); ok {
+			opening.WidthInches, opening.HeightInches = &width, &height
+		}
+		opening.Classification = ClassifyOpening(*opening, opening.WidthInches)
+	}
+}
+
+// addTakeoffOpenings normalizes the openings and adds them to the summary's
+// counts, breakdown and door/window schedule
+func addTakeoffOpenings(summary *models.TakeoffSummary, openings []models.Opening) {
+	NormalizeOpenings(openings)
+	for _, opening := range openings {
+		summary.OpeningCounts[opening.OpeningType] += opening.Count
+		summary.OpeningBreakdown = append(summary.OpeningBreakdown, models.OpeningSummary{
+			OpeningType:    opening.OpeningType,
+			Count:          opening.Count,
+			Size:           opening.Size,
+			WidthInches:    opening.WidthInches,
+			HeightInches:   opening.HeightInches,
+			Classification: opening.Classification,
+		})
+	}
+	summary.OpeningSchedule = BuildOpeningSchedule(openings)
+}
+
+// BuildOpeningSchedule groups normalized openings by classification and size
+// with their counts, ordered by classification then size
+func BuildOpeningSchedule(openings []models.Opening) []models.OpeningScheduleEntry {
+	schedule := []models.OpeningScheduleEntry{}
+	index := make(map[string]int)
+	for _, opening := range openings {
+		size := strings.TrimSpace(opening.Size)
+		if opening.WidthInches != nil && opening.HeightInches != nil {
+			size = formatOpeningSize(*opening.WidthInches, *opening.HeightInches)
+		}
+		key := string(opening.Classification) + "|" + size
+		if i, exists := index[key]; exists {
+			schedule[i].Count += opening.Count
+			continue
+		}
+		index[key] = len(schedule)
+		schedule = append(schedule, models.OpeningScheduleEntry{
+			Classification: opening.Classification,
+			Size:           size,
+			WidthInches:    opening.WidthInches,
+			HeightInches:   opening.HeightInches,
+			Count:          opening.Count,
+		})
+	}
+
+	sort.SliceStable(schedule, func(i, j int) bool {
+		a, b := schedule[i], schedule[j]
+		if a.Classification != b.Classification {
+			return a.Classification < b.Classification
+		}
+		if aw, bw := floatValue(a.WidthInches), floatValue(b.WidthInches); aw != bw {
+			return aw < bw
+		}
+		if ah, bh := floatValue(a.HeightInches), floatValue(b.HeightInches); ah != bh {
+			return ah < bh
+		}
+		return a.Size < b.Size
+	})
+	return schedule
+}
+
+func floatValue(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// formatOpeningSize writes a size in inches, e.g. 36" x 80"
+func formatOpeningSize(width, height float64) string {
+	return fmt.Sprintf(`%s" x %s"`, strconv.FormatFloat(width, 'f', -1, 64), strconv.FormatFloat(height, 'f', -1, 64))
+}
+
+// OpeningClassLabel is the display name of an opening classification
+func OpeningClassLabel(class models.OpeningClass) string {
+	switch class {
+	case models.OpeningClassInteriorDoor:
+		return "Interior door"
+	case models.OpeningClassExteriorDoor:
+		return "Exterior door"
+	case models.OpeningClassSlidingDoor:
+		return "Sliding door"
+	case models.OpeningClassWindow:
+		return "Window"
+	default:
+		return "Other opening"
+	}
+}
+
+// doorPricing is how each door classification is described and priced.
+// Exterior and sliding doors fall back to the generic door price when the
+// pricing config has no price for their own key.
+var doorPricing = []struct {
+	class       models.OpeningClass
+	description string
+	priceKey    string
+}{
+	{models.OpeningClassInteriorDoor, "Interior door installation", "door"},
+	{models.OpeningClassExteriorDoor, "Exterior door installation", "door_exterior"},
+	{models.OpeningClassSlidingDoor, "Sliding door installation", "door_sliding"},
+}
+
+// openingClass returns an opening's classification, classifying it when it
+// has not been normalized
+func openingClass(opening models.Opening) models.OpeningClass {
+	if opening.Classification != "" {
+		return opening.Classification
+	}
+	width := opening.WidthInches
+	if width == nil {
+		if w, _, ok := ParseOpeningSize(opening.Size); ok {
+			width = &w
+		}
+	}
+	return ClassifyOpening(opening, width)
+}
+
+// countOpeningsByClass totals opening counts per classification
+func countOpeningsByClass(openings []models.Opening) map[models.OpeningClass]int {
+	counts := make(map[models.OpeningClass]int)
+	for _, opening := range openings {
+		counts[openingClass(opening)] += opening.Count
+	}
+	return counts
+}
+
+// buildDoorItems prices doors with one line item per door classification.
+// sources may be nil when price provenance is not tracked.
+func buildDoorItems(counts map[models.OpeningClass]int, config *models.PricingConfig, sources *ResolvedPricingConfig) []installedWork {
+	var items []installedWork
+	for _, door := range doorPricing {
+		count := counts[door.class]
+		if count <= 0 {
+			continue
+		}
+		priceKey := door.priceKey
+		price, ok := config.MaterialPrices[priceKey]
+		if !ok {
+			priceKey = "door"
+			price = config.MaterialPrices[priceKey]
+		}
+
+		items = append(items, installWork(models.LineItem{
+			Description: door.description,
+			Trade:       "carpentry",
+			Quantity:    float64(count),
+			Unit:        "each",
+			PriceSource: sources.materialSource(priceKey),
+		}, price, 0.25, config, door.priceKey, "door"))
+	}
+	return items
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestParseOpeningSize(t *testing.T) {
+	tests := []struct {
+		size          string
+		width, height float64
+		ok            bool
+	}{
+		{"36x80", 36, 80, true},
+		{"36 X 80", 36, 80, true},
+		{`36" x 80"`, 36, 80, true},
+		{"30in x 80in", 30, 80, true},
+		{"3068", 36, 80, true},
+		{"2868", 32, 80, true},
+		{"6080", 72, 96, true},
+		{"3050", 36, 60, true},
+		{`3'-0" x 6'-8"`, 36, 80, true},
+		{`3'0" x 6'8"`, 36, 80, true},
+		{`3' x 7'`, 36, 84, true},
+		{"3x7", 36, 84, true},
+		{"", 0, 0, false},
+		{"standard", 0, 0, false},
+		{"3x7x2", 0, 0, false},
+		{"3168", 37, 80, true},
+		{"30680", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			width, height, ok := ParseOpeningSize(tt.size)
+			if ok != tt.ok || width != tt.width || height != tt.height {
+				t.Errorf("ParseOpeningSize(%q) = %v, %v, %v; want %v, %v, %v", tt.size, width, height, ok, tt.width, tt.height, tt.ok)
+			}
+		})
+	}
+}
+
+func TestClassifyOpening(t *testing.T) {
+	details := func(s string) *string { return &s }
+	width := func(w float64) *float64 { return &w }
+
+	tests := []struct {
+		name    string
+		opening models.Opening
+		width   *float64
+		want    models.OpeningClass
+	}{
+		{"window", models.Opening{OpeningType: "casement window"}, width(36), models.OpeningClassWindow},
+		{"narrow door", models.Opening{OpeningType: "door"}, width(30), models.OpeningClassInteriorDoor},
+		{"36 inch door", models.Opening{OpeningType: "door"}, width(36), models.OpeningClassInteriorDoor},
+		{"wide door", models.Opening{OpeningType: "door"}, width(42), models.OpeningClassExteriorDoor},
+		{"patio width", models.Opening{OpeningType: "door"}, width(72), models.OpeningClassSlidingDoor},
+		{"unsized door", models.Opening{OpeningType: "door"}, nil, models.OpeningClassInteriorDoor},
+		{"labelled exterior", models.Opening{OpeningType: "door", Details: details("Front entry")}, width(36), models.OpeningClassExteriorDoor},
+		{"labelled sliding", models.Opening{OpeningType: "sliding glass door"}, width(36), models.OpeningClassSlidingDoor},
+		{"labelled closet", models.Opening{OpeningType: "door", Details: details("closet")}, width(48), models.OpeningClassInteriorDoor},
+		{"archway", models.Opening{OpeningType: "cased opening"}, width(48), models.OpeningClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyOpening(tt.opening, tt.width); got != tt.want {
+				t.Errorf("ClassifyOpening() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateTakeoffSummary_OpeningSchedule(t *testing.T) {
+	analysis := &models.AnalysisResult{Openings: []models.Opening{
+		{OpeningType: "door", Count: 4, Size: "2868"},
+		{OpeningType: "door", Count: 2, Size: `2'-8" x 6'-8"`},
+		{OpeningType: "door", Count: 1, Size: "42x80"},
+		{OpeningType: "door", Count: 1, Size: "6080"},
+		{OpeningType: "window", Count: 5, Size: "3050"},
+		{OpeningType: "window", Count: 1, Size: "bay"},
+	}}
+
+	summary, err := NewTakeoffService().CalculateTakeoffSummary(analysis)
+	if err != nil {
+		t.Fatalf("CalculateTakeoffSummary failed: %v", err)
+	}
+
+	want := []models.OpeningScheduleEntry{
+		{Classification: models.OpeningClassExteriorDoor, Size: `42" x 80"`, Count: 1},
+		{Classification: models.OpeningClassInteriorDoor, Size: `32" x 80"`, Count: 6},
+		{Classification: models.OpeningClassSlidingDoor, Size: `72" x 96"`, Count: 1},
+		{Classification: models.OpeningClassWindow, Size: "bay", Count: 1},
+		{Classification: models.OpeningClassWindow, Size: `36" x 60"`, Count: 5},
+	}
+	if len(summary.OpeningSchedule) != len(want) {
+		t.Fatalf("schedule = %+v, want %d rows", summary.OpeningSchedule, len(want))
+	}
+	for i, entry := range summary.OpeningSchedule {
+		if entry.Classification != want[i].Classification || entry.Size != want[i].Size || entry.Count != want[i].Count {
+			t.Errorf("schedule[%d] = %s %s x%d, want %s %s x%d", i,
+				entry.Classification, entry.Size, entry.Count, want[i].Classification, want[i].Size, want[i].Count)
+		}
+	}
+
+	first := summary.OpeningBreakdown[0]
+	if first.WidthInches == nil || *first.WidthInches != 32 || first.Classification != models.OpeningClassInteriorDoor {
+		t.Errorf("breakdown[0] = %+v, want a 32 inch interior door", first)
+	}
+}
+
+func TestPricing_ExteriorDoorsPricedSeparately(t *testing.T) {
+	analysis := &models.AnalysisResult{Openings: []models.Opening{
+		{OpeningType: "door", Count: 3, Size: "2868"},
+		{OpeningType: "door", Count: 1, Size: "3068", Details: strPtr("exterior entry")},
+	}}
+
+	summary, err := NewPricingService().GeneratePricingSummary(nil, analysis, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	totals := make(map[string]float64)
+	for _, item := range summary.LineItems {
+		totals[item.Description] = item.Total
+	}
+	if totals["Interior door installation"] != 1350 {
+		t.Errorf("interior doors = %v, want 3 x 450", totals["Interior door installation"])
+	}
+	if totals["Exterior door installation"] != 1200 {
+		t.Errorf("exterior door = %v, want 1 x 1200", totals["Exterior door installation"])
+	}
+
+	// Without an exterior door price the generic door price is used
+	config := NewPricingService().GetDefaultPricingConfig()
+	custom := *config
+	custom.MaterialPrices = map[string]float64{"door": 500, "window": 850}
+	summary, err = NewPricingService().GeneratePricingSummary(nil, analysis, &custom)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	for _, item := range summary.LineItems {
+		if item.Description == "Exterior door installation" && item.UnitCost != 500 {
+			t.Errorf("exterior door unit cost = %v, want the 500 door fallback", item.UnitCost)
+		}
+	}
+}
+
+func TestGenerateBidCSV_OpeningSchedule(t *testing.T) {
+	bid := &models.Bid{ID: uuid.New(), Status: models.BidStatusDraft}
+	response := &models.GenerateBidResponse{OpeningSchedule: []models.OpeningScheduleEntry{
+		{Classification: models.OpeningClassExteriorDoor, Size: `36" x 80"`, Count: 2},
+	}}
+
+	csvData, err := NewExportService().GenerateBidCSV(bid, response, "Test")
+	if err != nil {
+		t.Fatalf("GenerateBidCSV failed: %v", err)
+	}
+	if !strings.Contains(string(csvData), "Door/Window Schedule\nType,Size,Count\nExterior door,\"36\"\" x 80\"\"\",2\n") {
+		t.Errorf("schedule section missing from CSV:\n%s", csvData)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// sortedKeys returns a map's keys in ascending order, for loops whose output
+// order must not depend on map iteration
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SortLineItems orders line items by trade, then description, keeping the
+// existing order of equal items. It reports whether the order changed.
+func SortLineItems(items []models.LineItem) bool {
+	less := func(i, j int) bool {
+		if items[i].Trade != items[j].Trade {
+			return items[i].Trade < items[j].Trade
+		}
+		return items[i].Description < items[j].Description
+	}
+	if sort.SliceIsSorted(items, less) {
+		return false
+	}
+	sort.SliceStable(items, less)
+	return true
+}
+
+// SortBidLineItems orders a bid's base and alternate line items so identical
+// bids marshal to identical BidData. It reports whether anything moved.
+func SortBidLineItems(bid *models.GenerateBidResponse) bool {
+	changed := SortLineItems(bid.LineItems)
+	for i := range bid.Alternates {
+		if SortLineItems(bid.Alternates[i].LineItems) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// CanonicalJSON re-encodes a JSON document with object keys sorted and
+// insignificant whitespace removed, so equivalent documents compare equal.
+// Numbers keep their original text.
+func CanonicalJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	// encoding/json writes map keys in sorted order
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return canonical, nil
+}
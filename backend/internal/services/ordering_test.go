@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func orderingTakeoff() (*models.TakeoffSummary, *models.AnalysisResult) {
+	takeoff := &models.TakeoffSummary{TotalArea: 1200, OpeningCounts: map[string]int{}, FixtureCounts: map[string]int{}}
+	analysis := &models.AnalysisResult{
+		ConfidenceScore: 0.9,
+		Openings: []models.Opening{
+			{OpeningType: "door", Count: 6},
+			{OpeningType: "window", Count: 4},
+		},
+		Fixtures: []models.Fixture{
+			{FixtureType: "outlet", Category: "electrical", Count: 20},
+			{FixtureType: "toilet", Category: "plumbing", Count: 2},
+		},
+	}
+	return takeoff, analysis
+}
+
+func TestGeneratePricingSummary_RepeatedRunsMarshalIdentically(t *testing.T) {
+	takeoff, analysis := orderingTakeoff()
+
+	generators := map[string]func() (*models.PricingSummary, error){
+		"basic": func() (*models.PricingSummary, error) {
+			return NewPricingService().GeneratePricingSummary(takeoff, analysis, nil)
+		},
+		"enhanced": func() (*models.PricingSummary, error) {
+			return NewEnhancedPricingService(nil, nil, nil, nil).
+				GeneratePricingSummary(context.Background(), takeoff, analysis, nil, nil)
+		},
+	}
+
+	for name, generate := range generators {
+		t.Run(name, func(t *testing.T) {
+			var first []byte
+			for i := 0; i < 20; i++ {
+				summary, err := generate()
+				if err != nil {
+					t.Fatalf("GeneratePricingSummary() error = %v", err)
+				}
+				data, err := json.Marshal(summary)
+				if err != nil {
+					t.Fatalf("json.Marshal() error = %v", err)
+				}
+				if first == nil {
+					first = data
+					continue
+				}
+				if !bytes.Equal(data, first) {
+					t.Fatalf("run %d marshalled differently:\n%s\nwant\n%s", i, data, first)
+				}
+			}
+		})
+	}
+}
+
+func TestSortBidLineItems(t *testing.T) {
+	bid := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Trade: "painting", Description: "Walls"},
+			{Trade: "electrical", Description: "Outlets"},
+			{Trade: "electrical", Description: "Fixtures"},
+		},
+		Alternates: []models.AlternateGroup{{
+			LineItems: []models.LineItem{
+				{Trade: "flooring", Description: "Tile"},
+				{Trade: "flooring", Description: "Carpet"},
+			},
+		}},
+	}
+
+	if !SortBidLineItems(bid) {
+		t.Fatal("expected unsorted line items to be reordered")
+	}
+	got := []string{bid.LineItems[0].Description, bid.LineItems[1].Description, bid.LineItems[2].Description}
+	want := []string{"Fixtures", "Outlets", "Walls"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line items = %v, want %v", got, want)
+		}
+	}
+	if bid.Alternates[0].LineItems[0].Description != "Carpet" {
+		t.Errorf("expected alternate line items to be sorted, got %+v", bid.Alternates[0].LineItems)
+	}
+
+	if SortBidLineItems(bid) {
+		t.Error("expected sorted line items to be left alone")
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	a, err := CanonicalJSON([]byte(`{"b": 1.50, "a": {"y": [2, 1], "x": null}}`))
+	if err != nil {
+		t.Fatalf("CanonicalJSON() error = %v", err)
+	}
+	b, err := CanonicalJSON([]byte(`{"a":{"x":null,"y":[2,1]},"b":1.50}`))
+	if err != nil {
+		t.Fatalf("CanonicalJSON() error = %v", err)
+	}
+
+	want := `{"a":{"x":null,"y":[2,1]},"b":1.50}`
+	if string(a) != want || string(b) != want {
+		t.Errorf("CanonicalJSON() = %s / %s, want %s", a, b, want)
+	}
+
+	if _, err := CanonicalJSON([]byte(`{"a":`)); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+}
+
+func TestBidPDFHash_IgnoresBidDataFormatting(t *testing.T) {
+	bid, _ := testBidWithData(t)
+	base := BidPDFHash(bid, "Office", nil)
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(*bid.BidData), &data); err != nil {
+		t.Fatalf("failed to decode bid data: %v", err)
+	}
+	indented, err := json.MarshalIndent(data, "", "    ")
+	if err != nil {
+		t.Fatalf("json.MarshalIndent() error = %v", err)
+	}
+	reformatted := string(indented)
+	bid.BidData = &reformatted
+
+	if got := BidPDFHash(bid, "Office", nil); got != base {
+		t.Error("expected reformatted bid data to keep the hash")
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/trades"
+)
+
+// OverrideLister lists every company pricing override across all users
+type OverrideLister interface {
+	GetAll(ctx context.Context) ([]models.CompanyPricingOverride, error)
+}
+
+// PricingKeys are the item keys a company override can currently target
+type PricingKeys struct {
+	Materials map[string]bool
+	Labor     map[string]bool
+}
+
+// keysFor returns the keys for an override type; false for types such as
+// overhead that have no item key
+func (k PricingKeys) keysFor(overrideType string) (map[string]bool, bool) {
+	switch overrideType {
+	case "material":
+		return k.Materials, true
+	case "labor":
+		return k.Labor, true
+	}
+	return nil, false
+}
+
+// OverrideValidator detects company overrides whose item keys no longer
+// exist in the cost data or the default pricing config
+type OverrideValidator struct {
+	costData  CostDataSource
+	overrides OverrideLister
+	defaults  *models.PricingConfig
+}
+
+func NewOverrideValidator(costData CostDataSource, overrides OverrideLister, defaults *models.PricingConfig) *OverrideValidator {
+	return &OverrideValidator{costData: costData, overrides: overrides, defaults: defaults}
+}
+
+// OverrideValidator builds a validator over the service's cost data and defaults
+func (s *EnhancedPricingService) OverrideValidator(overrides OverrideLister) *OverrideValidator {
+	return NewOverrideValidator(s.costData, overrides, s.defaultConfig)
+}
+
+// CurrentKeys returns the material categories and labor trades in any region
+// plus the defaults pricing falls back to
+func (v *OverrideValidator) CurrentKeys(ctx context.Context) (PricingKeys, error) {
+	return v.currentKeys(ctx, uuid.Nil)
+}
+
+// currentKeys returns the current keys as if the stored material or labor
+// rate with ID exclude were removed; uuid.Nil excludes nothing
+func (v *OverrideValidator) currentKeys(ctx context.Context, exclude uuid.UUID) (PricingKeys, error) {
+	keys := PricingKeys{Materials: make(map[string]bool), Labor: make(map[string]bool)}
+	for key := range v.defaults.MaterialPrices {
+		keys.Materials[key] = true
+	}
+	for key := range v.defaults.LaborRates {
+		keys.Labor[key] = true
+	}
+
+	materials, err := v.costData.GetMaterials(ctx, nil, nil)
+	if err != nil {
+		return keys, fmt.Errorf("failed to load material categories: %w", err)
+	}
+	for _, m := range materials {
+		if exclude == uuid.Nil || m.ID != exclude {
+			keys.Materials[m.Category] = true
+		}
+	}
+
+	laborRates, err := v.costData.GetLaborRates(ctx, nil, nil)
+	if err != nil {
+		return keys, fmt.Errorf("failed to load labor trades: %w", err)
+	}
+	for _, lr := range laborRates {
+		if exclude == uuid.Nil || lr.ID != exclude {
+			keys.Labor[laborRateKey(lr.Trade)] = true
+		}
+	}
+	return keys, nil
+}
+
+// Validate reports which of the overrides are orphaned
+func (v *OverrideValidator) Validate(ctx context.Context, overrides []models.CompanyPricingOverride) (*models.OverrideValidationReport, error) {
+	keys, err := v.CurrentKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OverrideValidationReport{
+		Checked:  len(overrides),
+		Orphaned: FindOrphanedOverrides(overrides, keys),
+	}, nil
+}
+
+// FindOrphanedOverrides returns the material and labor overrides whose item
+// key is not a current key, each with the closest current key as a suggestion
+func FindOrphanedOverrides(overrides []models.CompanyPricingOverride, keys PricingKeys) []models.OrphanedOverride {
+	orphaned := []models.OrphanedOverride{}
+	for _, override := range overrides {
+		current, hasKeys := keys.keysFor(override.OverrideType)
+		key := override.ItemKey
+		if override.OverrideType == "labor" {
+			key = laborRateKey(key)
+		}
+		if !hasKeys || current[key] {
+			continue
+		}
+		orphaned = append(orphaned, models.OrphanedOverride{
+			Override:   override,
+			Suggestion: closestKey(override.ItemKey, current),
+		})
+	}
+	return orphaned
+}
+
+// OrphanedByRemoval returns the company overrides that removing the stored
+// material or labor rate with id would orphan: those keyed by its category
+// or trade when no other stored item or default provides the key
+func (v *OverrideValidator) OrphanedByRemoval(ctx context.Context, id uuid.UUID) ([]models.CompanyPricingOverride, error) {
+	overrides, err := v.overrides.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list company overrides: %w", err)
+	}
+	before, err := v.CurrentKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	after, err := v.currentKeys(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	alreadyOrphaned := make(map[uuid.UUID]bool)
+	for _, o := range FindOrphanedOverrides(overrides, before) {
+		alreadyOrphaned[o.Override.ID] = true
+	}
+	var orphaned []models.CompanyPricingOverride
+	for _, o := range FindOrphanedOverrides(overrides, after) {
+		if !alreadyOrphaned[o.Override.ID] {
+			orphaned = append(orphaned, o.Override)
+		}
+	}
+	return orphaned, nil
+}
+
+// closestKey returns the candidate with the smallest edit distance to key,
+// or nil when none is within half the key's length
+func closestKey(key string, candidates map[string]bool) *string {
+	sorted := make([]string, 0, len(candidates))
+	for candidate := range candidates {
+		sorted = append(sorted, candidate)
+	}
+	// Sorted so ties resolve the same way every time
+	sort.Strings(sorted)
+
+	best, bestDistance := "", len(key)/2+1
+	for _, candidate := range sorted {
+		if distance := trades.EditDistance(key, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return &best
+}
+
+// OverrideSnapshot records which overrides were orphaned before a cost sync
+type OverrideSnapshot struct {
+	orphaned map[uuid.UUID]bool
+}
+
+// Snapshot records the currently orphaned overrides. It returns nil when they
+// cannot be determined, which disables ReportNewlyOrphaned.
+func (v *OverrideValidator) Snapshot(ctx context.Context) *OverrideSnapshot {
+	orphaned, err := v.allOrphaned(ctx)
+	if err != nil {
+		slog.Warn("Failed to check company overrides before cost sync", "error", err)
+		return nil
+	}
+	snapshot := &OverrideSnapshot{orphaned: make(map[uuid.UUID]bool, len(orphaned))}
+	for _, o := range orphaned {
+		snapshot.orphaned[o.Override.ID] = true
+	}
+	return snapshot
+}
+
+// ReportNewlyOrphaned returns the overrides orphaned since the snapshot and
+// records them as a warning audit event
+func (v *OverrideValidator) ReportNewlyOrphaned(ctx context.Context, before *OverrideSnapshot) []models.OrphanedOverride {
+	if before == nil {
+		return nil
+	}
+	orphaned, err := v.allOrphaned(ctx)
+	if err != nil {
+		slog.Warn("Failed to check company overrides after cost sync", "error", err)
+		return nil
+	}
+
+	newlyOrphaned := []models.OrphanedOverride{}
+	keySet := make(map[string]bool)
+	for _, o := range orphaned {
+		if !before.orphaned[o.Override.ID] {
+			newlyOrphaned = append(newlyOrphaned, o)
+			keySet[o.Override.OverrideType+":"+o.Override.ItemKey] = true
+		}
+	}
+	if len(newlyOrphaned) == 0 {
+		return newlyOrphaned
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	slog.Warn("Cost sync orphaned company pricing overrides",
+		"audit_event", "pricing.overrides_orphaned",
+		"override_count", len(newlyOrphaned),
+		"item_keys", keys)
+
+	return newlyOrphaned
+}
+
+func (v *OverrideValidator) allOrphaned(ctx context.Context) ([]models.OrphanedOverride, error) {
+	overrides, err := v.overrides.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list company overrides: %w", err)
+	}
+	keys, err := v.CurrentKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return FindOrphanedOverrides(overrides, keys), nil
+}
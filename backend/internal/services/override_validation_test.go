@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// fakeCatalog is cost data whose categories and trades tests can change
+type fakeCatalog struct {
+	categories []string
+	trades     []string
+}
+
+func (f *fakeCatalog) GetMaterials(ctx context.Context, category, region *string) ([]models.MaterialCost, error) {
+	materials := make([]models.MaterialCost, 0, len(f.categories))
+	for _, c := range f.categories {
+		materials = append(materials, models.MaterialCost{Category: c, BasePrice: 10})
+	}
+	return materials, nil
+}
+
+func (f *fakeCatalog) GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
+	rates := make([]models.LaborRate, 0, len(f.trades))
+	for _, t := range f.trades {
+		rates = append(rates, models.LaborRate{Trade: t, HourlyRate: 50})
+	}
+	return rates, nil
+}
+
+func (f *fakeCatalog) GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error) {
+	return nil, errCostDataNotConfigured
+}
+
+type fakeOverrideLister struct {
+	overrides []models.CompanyPricingOverride
+}
+
+func (f *fakeOverrideLister) GetAll(ctx context.Context) ([]models.CompanyPricingOverride, error) {
+	return f.overrides, nil
+}
+
+func TestOverrideValidator_DetectsRemovedCategory(t *testing.T) {
+	catalog := &fakeCatalog{categories: []string{"countertop_quartz"}, trades: []string{"tile_setting"}}
+	service := NewEnhancedPricingService(nil, nil, nil, nil).WithCostData(catalog)
+	overrides := []models.CompanyPricingOverride{
+		{ID: uuid.New(), OverrideType: "material", ItemKey: "countertop_quartz", OverrideValue: 55},
+		{ID: uuid.New(), OverrideType: "labor", ItemKey: "tile_setting", OverrideValue: 80},
+		{ID: uuid.New(), OverrideType: "material", ItemKey: "drywall", OverrideValue: 2},
+		{ID: uuid.New(), OverrideType: "overhead", ItemKey: "", OverrideValue: 12, IsPercentage: true},
+	}
+	validator := service.OverrideValidator(&fakeOverrideLister{overrides: overrides})
+
+	report, err := validator.Validate(context.Background(), overrides)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if report.Checked != 4 || len(report.Orphaned) != 0 {
+		t.Fatalf("expected no orphaned overrides, got %+v", report)
+	}
+
+	// A catalog change renames the quartz category and drops the trade; the
+	// drywall override is still backed by the default price
+	catalog.categories = []string{"countertops_quartz"}
+	catalog.trades = nil
+
+	report, err = validator.Validate(context.Background(), overrides)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(report.Orphaned) != 2 {
+		t.Fatalf("expected 2 orphaned overrides, got %+v", report.Orphaned)
+	}
+	quartz := report.Orphaned[0]
+	if quartz.Override.ItemKey != "countertop_quartz" || quartz.Suggestion == nil || *quartz.Suggestion != "countertops_quartz" {
+		t.Errorf("expected a suggestion for the renamed category, got %+v", quartz)
+	}
+	if tile := report.Orphaned[1]; tile.Override.ItemKey != "tile_setting" || tile.Suggestion != nil {
+		t.Errorf("expected the dropped trade without a close suggestion, got %+v", tile)
+	}
+}
+
+func TestOverrideValidator_ReportsNewlyOrphanedAfterSync(t *testing.T) {
+	catalog := &fakeCatalog{categories: []string{"countertop_quartz", "stone_veneer"}}
+	lister := &fakeOverrideLister{overrides: []models.CompanyPricingOverride{
+		{ID: uuid.New(), UserID: uuid.New(), OverrideType: "material", ItemKey: "countertop_quartz", OverrideValue: 55},
+		{ID: uuid.New(), UserID: uuid.New(), OverrideType: "material", ItemKey: "legacy_trim", OverrideValue: 3},
+	}}
+	validator := NewEnhancedPricingService(nil, nil, nil, nil).WithCostData(catalog).OverrideValidator(lister)
+
+	before := validator.Snapshot(context.Background())
+	if before == nil {
+		t.Fatal("Snapshot() = nil")
+	}
+
+	// The sync removes the quartz category; legacy_trim was already orphaned
+	catalog.categories = []string{"stone_veneer"}
+
+	newlyOrphaned := validator.ReportNewlyOrphaned(context.Background(), before)
+	if len(newlyOrphaned) != 1 || newlyOrphaned[0].Override.ItemKey != "countertop_quartz" {
+		t.Fatalf("expected only the quartz override to be newly orphaned, got %+v", newlyOrphaned)
+	}
+
+	if again := validator.ReportNewlyOrphaned(context.Background(), validator.Snapshot(context.Background())); len(again) != 0 {
+		t.Errorf("expected no newly orphaned overrides without a catalog change, got %+v", again)
+	}
+}
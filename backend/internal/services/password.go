@@ -0,0 +1,103 @@
+package services
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MinPasswordLength is the shortest password accepted
+	MinPasswordLength = 8
+	// StrongPasswordLength is the length from which a password needs no
+	// mix of character classes
+	StrongPasswordLength = 12
+	// maxPasswordBytes is bcrypt's input limit
+	maxPasswordBytes = 72
+	// minPasswordClasses is how many character classes a shorter password mixes
+	minPasswordClasses = 3
+)
+
+// commonPasswordList holds the 1,000 or so most frequently breached
+// passwords, one lowercase password per line. The list is deliberately
+// shorter than a top-10k list: most of the rest are short passwords of one or
+// two character classes, which the length and mix rules already reject. A
+// longer list can replace the file without code changes.
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = func() map[string]struct{} {
+	passwords := make(map[string]struct{})
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			passwords[line] = struct{}{}
+		}
+	}
+	return passwords
+}()
+
+// PasswordStrengthError lists every requirement a password failed
+type PasswordStrengthError struct {
+	Problems []string
+}
+
+func (e *PasswordStrengthError) Error() string {
+	problems := e.Problems
+	if len(problems) == 1 {
+		return "Password must " + problems[0]
+	}
+	return "Password must " + strings.Join(problems[:len(problems)-1], ", ") + " and " + problems[len(problems)-1]
+}
+
+// ValidatePasswordStrength rejects passwords shorter than 8 characters, longer
+// than bcrypt accepts, on the common password list, or of 8 to 11 characters
+// without at least three of lowercase letters, uppercase letters, digits and
+// symbols. It returns a *PasswordStrengthError naming each failed requirement.
+func ValidatePasswordStrength(password string) error {
+	var problems []string
+
+	length := len([]rune(password))
+	if length < MinPasswordLength {
+		problems = append(problems, "be at least 8 characters")
+	}
+	if len(password) > maxPasswordBytes {
+		problems = append(problems, "be at most 72 bytes")
+	}
+	if length >= MinPasswordLength && length < StrongPasswordLength && passwordClasses(password) < minPasswordClasses {
+		problems = append(problems, "be at least 12 characters or mix three of lowercase letters, uppercase letters, digits and symbols")
+	}
+	if _, common := commonPasswords[strings.ToLower(password)]; common {
+		problems = append(problems, "not be a commonly used password")
+	}
+
+	if len(problems) > 0 {
+		return &PasswordStrengthError{Problems: problems}
+	}
+	return nil
+}
+
+// passwordClasses counts the character classes a password draws from
+func passwordClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
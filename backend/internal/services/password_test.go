@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePasswordStrength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		problems []string // Empty when the password is accepted
+	}{
+		{"too short", "Ab1!", []string{"be at least 8 characters"}},
+		{"common", "password1", []string{"be at least 12 characters or mix three of lowercase letters, uppercase letters, digits and symbols", "not be a commonly used password"}},
+		{"common digits", "12345678", []string{"be at least 12 characters or mix three of lowercase letters, uppercase letters, digits and symbols", "not be a commonly used password"}},
+		{"common regardless of case", "Qwerty123", []string{"not be a commonly used password"}},
+		{"short with two classes", "bluehouse7", []string{"be at least 12 characters or mix three of lowercase letters, uppercase letters, digits and symbols"}},
+		{"short with three classes", "BlueHouse7", nil},
+		{"short with symbols", "blue-house7", nil},
+		{"long with one class", "correcthorsebattery", nil},
+		{"too long", strings.Repeat("a", 73), []string{"be at most 72 bytes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePasswordStrength(tt.password)
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Errorf("ValidatePasswordStrength(%q) = %v, want accepted", tt.password, err)
+				}
+				return
+			}
+
+			var strengthErr *PasswordStrengthError
+			if !errors.As(err, &strengthErr) {
+				t.Fatalf("ValidatePasswordStrength(%q) = %v, want a PasswordStrengthError", tt.password, err)
+			}
+			if strings.Join(strengthErr.Problems, "|") != strings.Join(tt.problems, "|") {
+				t.Errorf("problems = %q, want %q", strengthErr.Problems, tt.problems)
+			}
+		})
+	}
+}
+
+func TestPasswordStrengthError_ListsEveryProblem(t *testing.T) {
+	err := &PasswordStrengthError{Problems: []string{"be long", "be mixed", "be rare"}}
+	if got := err.Error(); got != "Password must be long, be mixed and be rare" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestCommonPasswords_Loaded(t *testing.T) {
+	if len(commonPasswords) < 1000 {
+		t.Fatalf("loaded %d common passwords, want at least 1000", len(commonPasswords))
+	}
+	for password := range commonPasswords {
+		if password != strings.ToLower(password) {
+			t.Errorf("common password %q is not lowercase, so it can never match", password)
+		}
+	}
+}
@@ -25,6 +25,11 @@ type PDFOptions struct {
 	IncludeCover  bool
 	IncludeLogo   bool
 	LogoPath      string // Path to downloaded logo file if needed
+	BlueprintPages *BlueprintAttachment // Drawing pages for the Referenced Drawings appendix
+	IncludeEstimateRange bool // Print the bid's confidence range under the total
+	IncludeSignatureBlock bool // Print name, signature and date lines for both parties
+	Layout *models.PDFLayout // Section order and titles; the default layout when nil
+	Acceptance *models.BidAcceptance // Stamp the bid as accepted online by its client
 }
 
 // GenerateBidPDF creates a professional bid PDF from bid data
@@ -34,143 +39,316 @@ func (s *PDFService) GenerateBidPDF(bid *models.Bid, bidResponse *models.Generat
 
 // GenerateBidPDFWithOptions creates a professional bid PDF with custom options
 func (s *PDFService) GenerateBidPDFWithOptions(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string, options *PDFOptions) ([]byte, error) {
+	pdf := s.renderBidPDF(bid, bidResponse, projectName, options)
+
+	// Output to buffer
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// bidPDF is a bid document being rendered section by section
+type bidPDF struct {
+	pdf         *gofpdf.Fpdf
+	bid         *models.Bid
+	response    *models.GenerateBidResponse
+	projectName string
+	options     *PDFOptions
+	started     bool // A body page with the header has been started
+}
+
+// pdfSectionRenderers print each layout section under title, the custom
+// heading or "" for the default
+var pdfSectionRenderers = map[models.PDFSection]func(s *PDFService, doc *bidPDF, title string){
+	models.PDFSectionCover:          (*PDFService).renderCoverSection,
+	models.PDFSectionProjectInfo:    (*PDFService).renderProjectInfoSection,
+	models.PDFSectionScope:          (*PDFService).renderScopeSection,
+	models.PDFSectionLineItems:      (*PDFService).renderLineItemsSection,
+	models.PDFSectionTradeBreakdown: (*PDFService).renderTradeBreakdownSection,
+	models.PDFSectionCostSummary:    (*PDFService).renderCostSummarySection,
+	models.PDFSectionInclusions:     (*PDFService).renderInclusionsSection,
+	models.PDFSectionExclusions:     (*PDFService).renderExclusionsSection,
+	models.PDFSectionSchedule:       (*PDFService).renderScheduleSection,
+	models.PDFSectionTerms:          (*PDFService).renderTermsSection,
+	models.PDFSectionSignature:      (*PDFService).renderSignatureSection,
+}
+
+// renderBidPDF lays out the bid document without writing it out. Sections
+// print in the order of options.Layout, or the default order without one.
+func (s *PDFService) renderBidPDF(bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string, options *PDFOptions) *gofpdf.Fpdf {
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.SetMargins(20, 20, 20)
-	
-	// Add cover page if requested
-	if options != nil && options.IncludeCover && options.CompanyInfo != nil {
-		s.addCoverPage(pdf, projectName, bid, options.CompanyInfo, options.LogoPath)
+
+	doc := &bidPDF{pdf: pdf, bid: bid, response: bidResponse, projectName: projectName, options: options}
+	var layout *models.PDFLayout
+	if options != nil {
+		layout = options.Layout
 	}
-	
-	pdf.AddPage()
+	for _, entry := range enabledPDFSections(layout) {
+		if render, ok := pdfSectionRenderers[entry.Section]; ok {
+			render(s, doc, entry.Title)
+		}
+	}
+	s.startBody(doc)
+
+	// Referenced Drawings appendix
+	if options != nil && options.BlueprintPages != nil {
+		s.addReferencedDrawings(pdf, options.BlueprintPages)
+	}
+
+	// Footer
+	pdf.SetY(-20)
+	pdf.SetFont("Arial", "I", 8)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Generated on %s | Page %d", time.Now().Format("January 2, 2006"), pdf.PageNo()), "", 0, "C", false, 0, "")
+
+	return pdf
+}
 
-	// Header with company branding
-	if options != nil && options.CompanyInfo != nil {
-		s.addHeaderWithBranding(pdf, projectName, options.CompanyInfo, options.LogoPath)
+// startBody starts the first body page, headed with the company branding,
+// unless one is already started
+func (s *PDFService) startBody(doc *bidPDF) {
+	if doc.started {
+		return
+	}
+	doc.started = true
+	doc.pdf.AddPage()
+	if doc.options != nil && doc.options.CompanyInfo != nil {
+		s.addHeaderWithBranding(doc.pdf, doc.projectName, doc.options.CompanyInfo, doc.options.LogoPath)
 	} else {
-		s.addHeader(pdf, projectName)
+		s.addHeader(doc.pdf, doc.projectName)
+	}
+	if doc.options != nil && doc.options.Acceptance != nil {
+		s.addAcceptanceStamp(doc.pdf, doc.options.Acceptance)
+	}
+	if doc.response.GeneratedFromLowConfidence {
+		s.addLowConfidenceCaveat(doc.pdf)
+	}
+}
+
+// addAcceptanceStamp prints a boxed line recording who accepted the bid
+// online and when
+func (s *PDFService) addAcceptanceStamp(pdf *gofpdf.Fpdf, acceptance *models.BidAcceptance) {
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(212, 237, 218)
+	pdf.MultiCell(0, 8, translate(BidAcceptanceStamp(acceptance)), "1", "C", true)
+	pdf.Ln(4)
+}
+
+// addLowConfidenceCaveat prints a boxed warning that the bid's quantities
+// came from an analysis below the confidence threshold
+func (s *PDFService) addLowConfidenceCaveat(pdf *gofpdf.Fpdf) {
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(255, 243, 205)
+	pdf.MultiCell(0, 8, translate("Estimate based on low-confidence analysis — verify quantities"), "1", "C", true)
+	pdf.Ln(4)
+}
+
+// sectionTitle is the custom title, or fallback when none is set
+func sectionTitle(title, fallback string) string {
+	if title != "" {
+		return title
+	}
+	return fallback
+}
+
+func (s *PDFService) renderCoverSection(doc *bidPDF, title string) {
+	// Cover page if requested; the body starts on the page after it
+	if doc.options == nil || !doc.options.IncludeCover || doc.options.CompanyInfo == nil {
+		return
 	}
+	s.addCoverPage(doc.pdf, doc.projectName, doc.bid, doc.options.CompanyInfo, doc.options.LogoPath, sectionTitle(title, "BID PROPOSAL"))
+	doc.started = false
+}
+
+func (s *PDFService) renderProjectInfoSection(doc *bidPDF, title string) {
+	s.startBody(doc)
+	pdf := doc.pdf
 
 	// Company & Project Info
 	pdf.Ln(10)
-	s.addSection(pdf, "Project Information")
+	s.addSection(pdf, sectionTitle(title, "Project Information"))
 	pdf.SetFont("Arial", "", 10)
 	pdf.CellFormat(40, 6, "Project:", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, projectName, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, doc.projectName, "", 0, "L", false, 0, "")
 	pdf.Ln(6)
 	pdf.CellFormat(40, 6, "Bid ID:", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, bid.ID.String()[:8]+"...", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, doc.bid.ID.String()[:8]+"...", "", 0, "L", false, 0, "")
 	pdf.Ln(6)
 	pdf.CellFormat(40, 6, "Date:", "", 0, "L", false, 0, "")
 	pdf.CellFormat(0, 6, time.Now().Format("January 2, 2006"), "", 0, "L", false, 0, "")
 	pdf.Ln(6)
 	pdf.CellFormat(40, 6, "Status:", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, string(bid.Status), "", 0, "L", false, 0, "")
-	pdf.Ln(10)
+	pdf.CellFormat(0, 6, string(doc.bid.Status), "", 0, "L", false, 0, "")
+	pdf.Ln(6)
+	s.addSourceBlueprints(pdf, doc.response.SourceBlueprints)
+	pdf.Ln(4)
+}
 
-	// Scope of Work
-	if bidResponse.ScopeOfWork != "" {
-		s.addSection(pdf, "Scope of Work")
-		pdf.SetFont("Arial", "", 10)
-		pdf.MultiCell(0, 5, bidResponse.ScopeOfWork, "", "", false)
-		pdf.Ln(5)
+func (s *PDFService) renderScopeSection(doc *bidPDF, title string) {
+	if doc.response.ScopeOfWork == "" {
+		return
 	}
+	s.startBody(doc)
+	s.addSection(doc.pdf, sectionTitle(title, "Scope of Work"))
+	doc.pdf.SetFont("Arial", "", 10)
+	doc.pdf.MultiCell(0, 5, doc.response.ScopeOfWork, "", "", false)
+	doc.pdf.Ln(5)
+}
 
-	// Line Items
-	if len(bidResponse.LineItems) > 0 {
-		s.addSection(pdf, "Cost Breakdown")
-		s.addLineItemsTable(pdf, bidResponse.LineItems)
-		pdf.Ln(5)
+func (s *PDFService) renderLineItemsSection(doc *bidPDF, title string) {
+	if len(doc.response.LineItems) == 0 {
+		return
 	}
+	s.startBody(doc)
+	s.keepTableStartTogether(doc.pdf)
+	s.addSection(doc.pdf, sectionTitle(title, "Cost Breakdown"))
+	s.addLineItemsTable(doc.pdf, doc.response.LineItems)
+	doc.pdf.Ln(5)
+}
 
-	// Trade Breakdown
-	if len(bidResponse.LineItems) > 0 {
-		s.addSection(pdf, "Trade Breakdown")
-		s.addTradeBreakdown(pdf, bidResponse.LineItems)
-		pdf.Ln(5)
+func (s *PDFService) renderTradeBreakdownSection(doc *bidPDF, title string) {
+	if len(doc.response.LineItems) == 0 {
+		return
 	}
+	s.startBody(doc)
+	s.keepTableStartTogether(doc.pdf)
+	s.addSection(doc.pdf, sectionTitle(title, "Trade Breakdown"))
+	s.addTradeBreakdown(doc.pdf, doc.response.LineItems)
+	doc.pdf.Ln(5)
+}
 
-	// Cost Summary
-	s.addSection(pdf, "Cost Summary")
-	s.addCostSummary(pdf, bidResponse)
+// renderCostSummarySection prints the totals and, after them, the
+// alternates priced against the base bid
+func (s *PDFService) renderCostSummarySection(doc *bidPDF, title string) {
+	s.startBody(doc)
+	pdf := doc.pdf
+	includeRange := doc.options != nil && doc.options.IncludeEstimateRange && doc.response.ConfidenceRange != nil
+
+	// The totals move to the next page whole rather than splitting
+	height := sectionHeadingHeight + costSummaryHeight(doc.response)
+	if includeRange {
+		height += estimateRangeHeight
+	}
+	if !s.fitsOnPage(pdf, height) {
+		pdf.AddPage()
+	}
+
+	s.addSection(pdf, sectionTitle(title, "Cost Summary"))
+	s.addCostSummary(pdf, doc.response)
+	if includeRange {
+		s.addEstimateRange(pdf, doc.response.ConfidenceRange)
+	}
 	pdf.Ln(5)
 
-	// Inclusions
-	if len(bidResponse.Inclusions) > 0 {
-		s.addSection(pdf, "Inclusions")
-		pdf.SetFont("Arial", "", 10)
-		for _, inclusion := range bidResponse.Inclusions {
-			pdf.CellFormat(5, 5, "", "", 0, "L", false, 0, "")
-			pdf.CellFormat(5, 5, "•", "", 0, "L", false, 0, "")
-			pdf.MultiCell(0, 5, inclusion, "", "", false)
-		}
-		pdf.Ln(3)
+	if len(doc.response.Alternates) > 0 {
+		s.addSection(pdf, "Alternates")
+		s.addAlternates(pdf, doc.response.Alternates)
+		pdf.Ln(5)
 	}
+}
 
-	// Exclusions
-	if len(bidResponse.Exclusions) > 0 {
-		s.addSection(pdf, "Exclusions")
-		pdf.SetFont("Arial", "", 10)
-		for _, exclusion := range bidResponse.Exclusions {
-			pdf.CellFormat(5, 5, "", "", 0, "L", false, 0, "")
-			pdf.CellFormat(5, 5, "•", "", 0, "L", false, 0, "")
-			pdf.MultiCell(0, 5, exclusion, "", "", false)
-		}
-		pdf.Ln(3)
+func (s *PDFService) renderInclusionsSection(doc *bidPDF, title string) {
+	if len(doc.response.Inclusions) == 0 {
+		return
+	}
+	s.startBody(doc)
+	s.addBulletList(doc.pdf, sectionTitle(title, "Inclusions"), doc.response.Inclusions)
+}
+
+func (s *PDFService) renderExclusionsSection(doc *bidPDF, title string) {
+	if len(doc.response.Exclusions) == 0 {
+		return
 	}
+	s.startBody(doc)
+	s.addBulletList(doc.pdf, sectionTitle(title, "Exclusions"), doc.response.Exclusions)
+}
 
-	// Schedule
-	if len(bidResponse.Schedule) > 0 {
-		s.addSection(pdf, "Project Schedule")
-		pdf.SetFont("Arial", "", 10)
-		for phase, timeline := range bidResponse.Schedule {
-			pdf.CellFormat(5, 5, "", "", 0, "L", false, 0, "")
-			pdf.CellFormat(80, 5, phase+":", "", 0, "L", false, 0, "")
-			pdf.CellFormat(0, 5, timeline, "", 0, "L", false, 0, "")
-			pdf.Ln(5)
-		}
-		pdf.Ln(3)
+func (s *PDFService) addBulletList(pdf *gofpdf.Fpdf, title string, entries []string) {
+	s.addSection(pdf, title)
+	pdf.SetFont("Arial", "", 10)
+	for _, entry := range entries {
+		pdf.CellFormat(5, 5, "", "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 5, "•", "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 5, entry, "", "", false)
 	}
+	pdf.Ln(3)
+}
 
-	// Payment Terms
-	if bidResponse.PaymentTerms != "" {
+func (s *PDFService) renderScheduleSection(doc *bidPDF, title string) {
+	if len(doc.response.Schedule) == 0 {
+		return
+	}
+	s.startBody(doc)
+	pdf := doc.pdf
+	s.addSection(pdf, sectionTitle(title, "Project Schedule"))
+	pdf.SetFont("Arial", "", 10)
+	for _, phase := range sortedKeys(doc.response.Schedule) {
+		timeline := doc.response.Schedule[phase]
+		pdf.CellFormat(5, 5, "", "", 0, "L", false, 0, "")
+		pdf.CellFormat(80, 5, phase+":", "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, timeline, "", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+	pdf.Ln(3)
+}
+
+// renderTermsSection prints the payment terms, warranty and closing, each
+// under its own heading; a custom title is printed above them
+func (s *PDFService) renderTermsSection(doc *bidPDF, title string) {
+	response := doc.response
+	if response.PaymentTerms == "" && response.WarrantyTerms == "" && response.ClosingStatement == "" {
+		return
+	}
+	s.startBody(doc)
+	pdf := doc.pdf
+	if title != "" {
+		pdf.SetFont("Arial", "B", 14)
+		pdf.CellFormat(0, 10, title, "", 0, "L", false, 0, "")
+		pdf.Ln(10)
+	}
+
+	if response.PaymentTerms != "" {
 		s.addSection(pdf, "Payment Terms")
 		pdf.SetFont("Arial", "", 10)
-		pdf.MultiCell(0, 5, bidResponse.PaymentTerms, "", "", false)
+		pdf.MultiCell(0, 5, response.PaymentTerms, "", "", false)
 		pdf.Ln(3)
 	}
 
-	// Warranty Terms
-	if bidResponse.WarrantyTerms != "" {
+	if response.WarrantyTerms != "" {
 		s.addSection(pdf, "Warranty")
 		pdf.SetFont("Arial", "", 10)
-		pdf.MultiCell(0, 5, bidResponse.WarrantyTerms, "", "", false)
+		pdf.MultiCell(0, 5, response.WarrantyTerms, "", "", false)
 		pdf.Ln(3)
 	}
 
-	// Closing Statement
-	if bidResponse.ClosingStatement != "" {
+	if response.ClosingStatement != "" {
 		s.addSection(pdf, "Closing")
 		pdf.SetFont("Arial", "", 10)
-		pdf.MultiCell(0, 5, bidResponse.ClosingStatement, "", "", false)
+		pdf.MultiCell(0, 5, response.ClosingStatement, "", "", false)
 	}
+}
 
-	// Footer
-	pdf.SetY(-20)
-	pdf.SetFont("Arial", "I", 8)
-	pdf.CellFormat(0, 10, fmt.Sprintf("Generated on %s | Page %d", time.Now().Format("January 2, 2006"), pdf.PageNo()), "", 0, "C", false, 0, "")
-
-	// Output to buffer
-	var buf bytes.Buffer
-	if err := pdf.Output(&buf); err != nil {
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+// renderSignatureSection prints signatures for both parties when the options
+// ask for them
+func (s *PDFService) renderSignatureSection(doc *bidPDF, title string) {
+	if doc.options == nil || !doc.options.IncludeSignatureBlock {
+		return
 	}
-
-	return buf.Bytes(), nil
+	s.startBody(doc)
+	contractor := "Contractor"
+	if doc.options.CompanyInfo != nil && doc.options.CompanyInfo.Name != "" {
+		contractor = doc.options.CompanyInfo.Name
+	}
+	s.addSignatureBlock(doc.pdf, contractor, sectionTitle(title, "Acceptance"))
 }
 
 // addCoverPage creates a professional cover page with company branding
-func (s *PDFService) addCoverPage(pdf *gofpdf.Fpdf, projectName string, bid *models.Bid, companyInfo *models.CompanyInfo, logoPath string) {
+func (s *PDFService) addCoverPage(pdf *gofpdf.Fpdf, projectName string, bid *models.Bid, companyInfo *models.CompanyInfo, logoPath, title string) {
 	pdf.AddPage()
 	
 	// Add logo if available
@@ -190,7 +368,7 @@ func (s *PDFService) addCoverPage(pdf *gofpdf.Fpdf, projectName string, bid *mod
 	// Title
 	pdf.SetFont("Arial", "B", 28)
 	pdf.SetTextColor(41, 128, 185) // Professional blue
-	pdf.CellFormat(0, 15, "BID PROPOSAL", "", 0, "C", false, 0, "")
+	pdf.CellFormat(0, 15, title, "", 0, "C", false, 0, "")
 	pdf.Ln(20)
 	pdf.SetTextColor(0, 0, 0) // Reset to black
 	
@@ -272,66 +450,167 @@ func (s *PDFService) addHeader(pdf *gofpdf.Fpdf, projectName string) {
 
 func (s *PDFService) addSection(pdf *gofpdf.Fpdf, title string) {
 	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(0, 8, title, "", 0, "L", false, 0, "")
-	pdf.Ln(8)
+	pdf.CellFormat(0, sectionHeadingHeight, title, "", 0, "L", false, 0, "")
+	pdf.Ln(sectionHeadingHeight)
 }
 
+// sectionHeadingHeight is the height addSection prints a heading in
+const sectionHeadingHeight = 8.0
+
+// tableStartMinSpace is the room a table needs below its section heading
+// for its header and first rows; with less, the table starts on the next page
+const tableStartMinSpace = 15.0
+
+// keepTableStartTogether starts a new page when a section heading and the
+// start of its table would not fit above the bottom margin
+func (s *PDFService) keepTableStartTogether(pdf *gofpdf.Fpdf) {
+	if !s.fitsOnPage(pdf, sectionHeadingHeight+tableStartMinSpace) {
+		pdf.AddPage()
+	}
+}
+
+// Line item table layout. Descriptions wider than their column wrap, up to
+// lineItemMaxDescriptionLines lines, and an item's notes print as an
+// indented italic sub-row under it.
+const (
+	lineItemRowHeight             = 6.0
+	lineItemTableWidth            = 170.0
+	lineItemDescriptionWidth      = 80.0
+	lineItemDescriptionLineHeight = 4.5
+	lineItemMaxDescriptionLines   = 4
+	lineItemNoteIndent            = 5.0
+	lineItemNoteLineHeight        = 4.0
+)
+
 func (s *PDFService) addLineItemsTable(pdf *gofpdf.Fpdf, items []models.LineItem) {
+	s.addLineItemsHeader(pdf)
+
+	for _, item := range items {
+		descriptionLines := s.lineItemDescriptionLines(pdf, item.Description)
+		rowHeight := lineItemRowHeight
+		if len(descriptionLines) > 1 {
+			rowHeight = float64(len(descriptionLines))*lineItemDescriptionLineHeight + 1.5
+		}
+		noteLines := s.lineItemNoteLines(pdf, item.Notes)
+		noteHeight := float64(len(noteLines)) * lineItemNoteLineHeight
+
+		// Keep an item and its notes on one page, repeating the header
+		if !s.fitsOnPage(pdf, rowHeight+noteHeight) {
+			pdf.AddPage()
+			s.addLineItemsHeader(pdf)
+		}
+
+		pdf.SetFont("Arial", "", 9)
+		x, y := pdf.GetXY()
+		pdf.Rect(x, y, lineItemDescriptionWidth, rowHeight, "D")
+		if len(descriptionLines) == 1 {
+			pdf.CellFormat(lineItemDescriptionWidth, rowHeight, descriptionLines[0], "", 0, "L", false, 0, "")
+		} else {
+			for i, line := range descriptionLines {
+				pdf.SetXY(x, y+0.75+float64(i)*lineItemDescriptionLineHeight)
+				pdf.CellFormat(lineItemDescriptionWidth, lineItemDescriptionLineHeight, line, "", 0, "L", false, 0, "")
+			}
+		}
+		pdf.SetXY(x+lineItemDescriptionWidth, y)
+		pdf.CellFormat(20, rowHeight, fmt.Sprintf("%.1f", item.Quantity), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(20, rowHeight, item.Unit, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(25, rowHeight, fmt.Sprintf("$%.2f", item.UnitCost), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(25, rowHeight, fmt.Sprintf("$%.2f", item.Total), "1", 0, "R", false, 0, "")
+		pdf.Ln(-1)
+
+		if len(noteLines) > 0 {
+			pdf.SetFont("Arial", "I", 8)
+			x, y := pdf.GetXY()
+			pdf.Rect(x, y, lineItemTableWidth, noteHeight, "D")
+			for _, line := range noteLines {
+				pdf.SetX(x + lineItemNoteIndent)
+				pdf.CellFormat(lineItemTableWidth-lineItemNoteIndent, lineItemNoteLineHeight, line, "", 1, "L", false, 0, "")
+			}
+		}
+	}
+}
+
+func (s *PDFService) addLineItemsHeader(pdf *gofpdf.Fpdf) {
 	pdf.SetFont("Arial", "B", 9)
 	pdf.SetFillColor(240, 240, 240)
-	
-	// Header
-	pdf.CellFormat(80, 6, "Description", "1", 0, "L", true, 0, "")
-	pdf.CellFormat(20, 6, "Qty", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(20, 6, "Unit", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(25, 6, "Unit Cost", "1", 0, "R", true, 0, "")
-	pdf.CellFormat(25, 6, "Total", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(lineItemDescriptionWidth, lineItemRowHeight, "Description", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(20, lineItemRowHeight, "Qty", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(20, lineItemRowHeight, "Unit", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, lineItemRowHeight, "Unit Cost", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(25, lineItemRowHeight, "Total", "1", 0, "R", true, 0, "")
 	pdf.Ln(-1)
+}
 
-	// Items
+// lineItemDescriptionLines wraps a description to its column, cutting it
+// short with an ellipsis past lineItemMaxDescriptionLines lines
+func (s *PDFService) lineItemDescriptionLines(pdf *gofpdf.Fpdf, description string) []string {
 	pdf.SetFont("Arial", "", 9)
-	for _, item := range items {
-		pdf.CellFormat(80, 6, item.Description, "1", 0, "L", false, 0, "")
-		pdf.CellFormat(20, 6, fmt.Sprintf("%.1f", item.Quantity), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(20, 6, item.Unit, "1", 0, "C", false, 0, "")
-		pdf.CellFormat(25, 6, fmt.Sprintf("$%.2f", item.UnitCost), "1", 0, "R", false, 0, "")
-		pdf.CellFormat(25, 6, fmt.Sprintf("$%.2f", item.Total), "1", 0, "R", false, 0, "")
-		pdf.Ln(-1)
+	var lines []string
+	for _, line := range pdf.SplitLines([]byte(strings.TrimSpace(description)), lineItemDescriptionWidth-2) {
+		lines = append(lines, string(line))
+	}
+	if len(lines) == 0 {
+		return []string{""}
+	}
+	if len(lines) > lineItemMaxDescriptionLines {
+		lines = lines[:lineItemMaxDescriptionLines]
+		last := []rune(lines[len(lines)-1])
+		for len(last) > 0 && pdf.GetStringWidth(string(last)+"...") > lineItemDescriptionWidth-2 {
+			last = last[:len(last)-1]
+		}
+		lines[len(lines)-1] = strings.TrimSpace(string(last)) + "..."
+	}
+	return lines
+}
+
+// lineItemNoteLines wraps notes to the width of the notes sub-row
+func (s *PDFService) lineItemNoteLines(pdf *gofpdf.Fpdf, notes string) []string {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return nil
+	}
+	pdf.SetFont("Arial", "I", 8)
+	var lines []string
+	for _, line := range pdf.SplitLines([]byte(notes), lineItemTableWidth-lineItemNoteIndent-2) {
+		lines = append(lines, string(line))
 	}
+	return lines
+}
+
+// fitsOnPage reports whether height more millimetres fit above the bottom
+// margin of the current page
+func (s *PDFService) fitsOnPage(pdf *gofpdf.Fpdf, height float64) bool {
+	_, pageHeight := pdf.GetPageSize()
+	_, bottomMargin := pdf.GetAutoPageBreak()
+	return pdf.GetY()+height <= pageHeight-bottomMargin
 }
 
 // addTradeBreakdown groups line items by trade and shows totals
 func (s *PDFService) addTradeBreakdown(pdf *gofpdf.Fpdf, items []models.LineItem) {
 	// Group items by trade
-	tradeGroups := make(map[string][]models.LineItem)
+	tradeGroups := groupByTrade(items)
 	tradeTotals := make(map[string]float64)
-	
-	for _, item := range items {
-		trade := item.Trade
-		if trade == "" {
-			trade = "General"
+	for trade, groupItems := range tradeGroups {
+		for _, item := range groupItems {
+			tradeTotals[trade] += item.Total
 		}
-		tradeGroups[trade] = append(tradeGroups[trade], item)
-		tradeTotals[trade] += item.Total
 	}
 	
 	// Display trade summary table
-	pdf.SetFont("Arial", "B", 9)
-	pdf.SetFillColor(240, 240, 240)
-	
-	// Header
-	pdf.CellFormat(120, 6, "Trade", "1", 0, "L", true, 0, "")
-	pdf.CellFormat(25, 6, "Items", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(25, 6, "Total", "1", 0, "R", true, 0, "")
-	pdf.Ln(-1)
+	s.addTradeBreakdownHeader(pdf)
 	
-	// Trade rows
-	pdf.SetFont("Arial", "", 9)
+	// Trade rows, repeating the header on each new page
 	var grandTotal float64
-	for trade, items := range tradeGroups {
+	for _, trade := range sortedKeys(tradeGroups) {
+		items := tradeGroups[trade]
 		total := tradeTotals[trade]
 		grandTotal += total
 		
+		if !s.fitsOnPage(pdf, 6) {
+			pdf.AddPage()
+			s.addTradeBreakdownHeader(pdf)
+		}
+		pdf.SetFont("Arial", "", 9)
 		pdf.CellFormat(120, 6, trade, "1", 0, "L", false, 0, "")
 		pdf.CellFormat(25, 6, fmt.Sprintf("%d", len(items)), "1", 0, "C", false, 0, "")
 		pdf.CellFormat(25, 6, fmt.Sprintf("$%.2f", total), "1", 0, "R", false, 0, "")
@@ -339,6 +618,10 @@ func (s *PDFService) addTradeBreakdown(pdf *gofpdf.Fpdf, items []models.LineItem
 	}
 	
 	// Grand total
+	if !s.fitsOnPage(pdf, 6) {
+		pdf.AddPage()
+		s.addTradeBreakdownHeader(pdf)
+	}
 	pdf.SetFont("Arial", "B", 9)
 	pdf.SetFillColor(220, 220, 220)
 	pdf.CellFormat(120, 6, "Total", "1", 0, "L", true, 0, "")
@@ -347,6 +630,30 @@ func (s *PDFService) addTradeBreakdown(pdf *gofpdf.Fpdf, items []models.LineItem
 	pdf.Ln(-1)
 }
 
+func (s *PDFService) addTradeBreakdownHeader(pdf *gofpdf.Fpdf) {
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(120, 6, "Trade", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(25, 6, "Items", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 6, "Total", "1", 0, "R", true, 0, "")
+	pdf.Ln(-1)
+}
+
+// costSummaryHeight is the height addCostSummary prints bidResponse's totals in
+func costSummaryHeight(bidResponse *models.GenerateBidResponse) float64 {
+	height := 4*6.0 + 8
+	if tax := bidResponse.Tax; tax != nil {
+		height += 6
+		if tax.Note != "" {
+			height += 5
+		}
+	}
+	if metrics := bidResponse.UnitMetrics; metrics != nil && metrics.CostPerSF > 0 {
+		height += 6
+	}
+	return height
+}
+
 func (s *PDFService) addCostSummary(pdf *gofpdf.Fpdf, bidResponse *models.GenerateBidResponse) {
 	pdf.SetFont("Arial", "", 10)
 	
@@ -372,6 +679,25 @@ func (s *PDFService) addCostSummary(pdf *gofpdf.Fpdf, bidResponse *models.Genera
 	pdf.CellFormat(40, 6, "Markup:", "", 0, "L", false, 0, "")
 	pdf.CellFormat(30, 6, fmt.Sprintf("$%.2f", bidResponse.MarkupAmount), "", 0, "R", false, 0, "")
 	pdf.Ln(6)
+
+	if tax := bidResponse.Tax; tax != nil {
+		pdf.SetX(x)
+		if tax.Exempt {
+			pdf.CellFormat(40, 6, "Sales Tax:", "", 0, "L", false, 0, "")
+			pdf.CellFormat(30, 6, "Exempt", "", 0, "R", false, 0, "")
+		} else {
+			pdf.CellFormat(40, 6, fmt.Sprintf("Sales Tax (%g%%):", tax.Rate), "", 0, "L", false, 0, "")
+			pdf.CellFormat(30, 6, fmt.Sprintf("$%.2f", bidResponse.TaxAmount), "", 0, "R", false, 0, "")
+		}
+		pdf.Ln(6)
+		if tax.Note != "" {
+			pdf.SetFont("Arial", "I", 8)
+			pdf.SetX(x)
+			pdf.CellFormat(70, 5, tax.Note, "", 0, "L", false, 0, "")
+			pdf.Ln(5)
+			pdf.SetFont("Arial", "", 10)
+		}
+	}
 	
 	// Total with emphasis
 	pdf.SetFont("Arial", "B", 12)
@@ -379,6 +705,151 @@ func (s *PDFService) addCostSummary(pdf *gofpdf.Fpdf, bidResponse *models.Genera
 	pdf.CellFormat(40, 8, "Total Price:", "", 0, "L", false, 0, "")
 	pdf.CellFormat(30, 8, fmt.Sprintf("$%.2f", bidResponse.TotalPrice), "", 0, "R", false, 0, "")
 	pdf.Ln(8)
+
+	if metrics := bidResponse.UnitMetrics; metrics != nil && metrics.CostPerSF > 0 {
+		pdf.SetFont("Arial", "", 10)
+		pdf.SetX(x)
+		pdf.CellFormat(40, 6, "Cost per SF:", "", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 6, fmt.Sprintf("$%.2f", metrics.CostPerSF), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
+}
+
+// estimateRangeHeight is the height addEstimateRange prints in
+const estimateRangeHeight = 6.0
+
+// addEstimateRange prints the low and high estimate under the cost summary
+func (s *PDFService) addEstimateRange(pdf *gofpdf.Fpdf, estimateRange *models.ConfidenceRange) {
+	pdf.SetFont("Arial", "I", 9)
+	pdf.SetX(120)
+	pdf.CellFormat(30, 6, "Estimate range:", "", 0, "L", false, 0, "")
+	pdf.CellFormat(40, 6, fmt.Sprintf("$%.2f - $%.2f", estimateRange.Low, estimateRange.High), "", 0, "R", false, 0, "")
+	pdf.Ln(6)
+}
+
+// signatureBlockHeight is the height of the signature block, which is kept
+// on one page
+const signatureBlockHeight = 70.0
+
+// addSourceBlueprints lists the blueprints a bid was priced from, one per line
+func (s *PDFService) addSourceBlueprints(pdf *gofpdf.Fpdf, sources []models.BidSourceBlueprint) {
+	label := "Blueprints:"
+	if len(sources) == 1 {
+		label = "Blueprint:"
+	}
+	for _, source := range sources {
+		pdf.CellFormat(40, 6, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, fmt.Sprintf("%s (v%d)", source.Filename, source.Version), "", 0, "L", false, 0, "")
+		pdf.Ln(6)
+		label = ""
+	}
+}
+
+// addSignatureBlock prints printed name, signature and date lines for the
+// contractor and the client side by side
+func (s *PDFService) addSignatureBlock(pdf *gofpdf.Fpdf, contractor, title string) {
+	_, pageHeight := pdf.GetPageSize()
+	_, bottomMargin := pdf.GetAutoPageBreak()
+	if pdf.GetY()+signatureBlockHeight > pageHeight-bottomMargin {
+		pdf.AddPage()
+	}
+
+	pdf.Ln(5)
+	s.addSection(pdf, title)
+	pdf.SetFont("Arial", "", 9)
+	pdf.MultiCell(0, 5, "The signatures below accept this proposal, including the scope, price and terms above.", "", "", false)
+	pdf.Ln(4)
+
+	left, _, _, _ := pdf.GetMargins()
+	columnWidth := 80.0
+	parties := []string{contractor, "Client"}
+	pdf.SetFont("Arial", "B", 10)
+	for i, party := range parties {
+		pdf.SetX(left + float64(i)*(columnWidth+10))
+		pdf.CellFormat(columnWidth, 6, party, "", 0, "L", false, 0, "")
+	}
+	pdf.Ln(10)
+
+	pdf.SetFont("Arial", "", 9)
+	for _, label := range []string{"Printed name", "Signature", "Date"} {
+		for i := range parties {
+			x := left + float64(i)*(columnWidth+10)
+			pdf.SetX(x)
+			pdf.CellFormat(columnWidth, 5, "", "B", 0, "L", false, 0, "")
+		}
+		pdf.Ln(5)
+		for i := range parties {
+			pdf.SetX(left + float64(i)*(columnWidth+10))
+			pdf.CellFormat(columnWidth, 5, label, "", 0, "L", false, 0, "")
+		}
+		pdf.Ln(7)
+	}
+}
+
+// addAlternates lists each alternate group with its items and the amount it adds to the base bid
+func (s *PDFService) addAlternates(pdf *gofpdf.Fpdf, alternates []models.AlternateGroup) {
+	for _, group := range alternates {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(145, 6, group.Name, "", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 6, fmt.Sprintf("+$%.2f", group.Price), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+
+		pdf.SetFont("Arial", "", 9)
+		for _, item := range group.LineItems {
+			pdf.CellFormat(5, 5, "", "", 0, "L", false, 0, "")
+			pdf.CellFormat(115, 5, item.Description, "", 0, "L", false, 0, "")
+			pdf.CellFormat(25, 5, fmt.Sprintf("%.1f %s", item.Quantity, item.Unit), "", 0, "R", false, 0, "")
+			pdf.CellFormat(25, 5, fmt.Sprintf("$%.2f", item.Total), "", 0, "R", false, 0, "")
+			pdf.Ln(5)
+		}
+		pdf.Ln(2)
+	}
+}
+
+// addReferencedDrawings appends one page per embedded drawing, scaled to fit
+// the printable area. Notes for skipped pages go under the appendix heading.
+func (s *PDFService) addReferencedDrawings(pdf *gofpdf.Fpdf, attachment *BlueprintAttachment) {
+	if len(attachment.Pages) == 0 && len(attachment.Skipped) == 0 {
+		return
+	}
+
+	pdf.AddPage()
+	s.addSection(pdf, "Referenced Drawings")
+	if len(attachment.Skipped) > 0 {
+		pdf.SetFont("Arial", "I", 9)
+		for _, note := range attachment.Skipped {
+			pdf.MultiCell(0, 5, note, "", "", false)
+		}
+		pdf.Ln(3)
+	}
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	maxWidth := pageWidth - left - right
+
+	for i, page := range attachment.Pages {
+		if i > 0 {
+			pdf.AddPage()
+		}
+
+		pdf.SetFont("Arial", "", 10)
+		pdf.CellFormat(0, 6, fmt.Sprintf("%s - Revision %d, Page %d", attachment.Filename, attachment.Version, page.Page), "", 0, "L", false, 0, "")
+		pdf.Ln(8)
+
+		// Leave room for the footer below the image
+		maxHeight := pageHeight - pdf.GetY() - 25
+		width := maxWidth
+		height := width * float64(page.Height) / float64(page.Width)
+		if height > maxHeight {
+			height = maxHeight
+			width = height * float64(page.Width) / float64(page.Height)
+		}
+
+		name := fmt.Sprintf("blueprint-page-%d", page.Page)
+		imageOptions := gofpdf.ImageOptions{ImageType: page.ImageType}
+		pdf.RegisterImageOptionsReader(name, imageOptions, bytes.NewReader(page.Data))
+		pdf.ImageOptions(name, left+(maxWidth-width)/2, pdf.GetY(), width, height, false, imageOptions, 0, "")
+	}
 }
 
 // ParseBidDataFromJSON parses bid_data JSONB field into GenerateBidResponse
@@ -390,10 +861,10 @@ func (s *PDFService) ParseBidDataFromJSON(bidData string) (*models.GenerateBidRe
 	return &bidResponse, nil
 }
 
-// GeneratePDFFilename creates a unique filename for the bid PDF
-func (s *PDFService) GeneratePDFFilename(projectID uuid.UUID, bidID uuid.UUID) string {
-	timestamp := time.Now().Format("20060102-150405")
-	return fmt.Sprintf("bids/%s/bid-%s-%s.pdf", projectID.String(), bidID.String()[:8], timestamp)
+// GeneratePDFFilename creates the deterministic key for a bid PDF rendered
+// from inputs with the given hash (see BidPDFHash)
+func (s *PDFService) GeneratePDFFilename(projectID uuid.UUID, bidID uuid.UUID, hash string) string {
+	return fmt.Sprintf("bids/%s/%s/%s.pdf", projectID.String(), bidID.String(), hash)
 }
 
 // detectImageType detects image format from file extension
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/errreport"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// PDFTemplateVersion is part of every bid PDF hash; bump it when the PDF
+// layout changes so stored PDFs are re-rendered
+const PDFTemplateVersion = 1
+
+// BidPDFStore uploads bid PDFs, resolves the URL of a stored object and
+// moves superseded PDFs to archive storage
+type BidPDFStore interface {
+	UploadFile(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	ObjectURL(key string) string
+	ArchiveObject(ctx context.Context, key string) error
+}
+
+// ObjectDeletionScheduler schedules an object for deletion by the worker
+type ObjectDeletionScheduler interface {
+	Schedule(ctx context.Context, key string, deleteAfter time.Time) error
+}
+
+// pdfHashInput is everything a bid PDF is rendered from
+type pdfHashInput struct {
+	TemplateVersion int                 `json:"template_version"`
+	BidID           uuid.UUID           `json:"bid_id"`
+	Status          models.BidStatus    `json:"status"`
+	ProjectName     string              `json:"project_name"`
+	BidData         string              `json:"bid_data"`
+	CompanyInfo     *models.CompanyInfo `json:"company_info,omitempty"`
+	IncludeCover    bool                `json:"include_cover"`
+	IncludeLogo     bool                `json:"include_logo"`
+	LogoPath        string              `json:"logo_path,omitempty"`
+	Drawings        *pdfHashDrawings    `json:"drawings,omitempty"`
+	EstimateRange   bool                `json:"estimate_range,omitempty"`
+	SignatureBlock  bool                `json:"signature_block,omitempty"`
+	Layout          *models.PDFLayout   `json:"layout,omitempty"`
+	AcceptanceStamp string              `json:"acceptance_stamp,omitempty"`
+}
+
+type pdfHashDrawings struct {
+	Filename string   `json:"filename"`
+	Version  int      `json:"version"`
+	Pages    []string `json:"pages"` // page number and SHA-256 of the image
+	Skipped  []string `json:"skipped"`
+}
+
+// BidPDFHash returns the SHA-256 (hex) of the bid data, company profile, PDF
+// options and template version a bid PDF is rendered from
+func BidPDFHash(bid *models.Bid, projectName string, options *PDFOptions) string {
+	input := pdfHashInput{
+		TemplateVersion: PDFTemplateVersion,
+		BidID:           bid.ID,
+		Status:          bid.Status,
+		ProjectName:     projectName,
+	}
+	if bid.BidData != nil {
+		// Hash the canonical form so key order and whitespace don't force a re-render
+		input.BidData = *bid.BidData
+		if canonical, err := CanonicalJSON([]byte(*bid.BidData)); err == nil {
+			input.BidData = string(canonical)
+		}
+	}
+	if options != nil {
+		input.CompanyInfo = options.CompanyInfo
+		input.IncludeCover = options.IncludeCover
+		input.IncludeLogo = options.IncludeLogo
+		// A downloaded logo's temporary path changes on every render; the
+		// logo's key in the company info identifies it instead
+		if options.CompanyInfo == nil || options.CompanyInfo.Logo == nil {
+			input.LogoPath = options.LogoPath
+		}
+		input.EstimateRange = options.IncludeEstimateRange
+		input.SignatureBlock = options.IncludeSignatureBlock
+		input.Layout = options.Layout
+		if options.Acceptance != nil {
+			input.AcceptanceStamp = BidAcceptanceStamp(options.Acceptance)
+		}
+		if attachment := options.BlueprintPages; attachment != nil {
+			drawings := &pdfHashDrawings{Filename: attachment.Filename, Version: attachment.Version, Skipped: attachment.Skipped}
+			for _, page := range attachment.Pages {
+				sum := sha256.Sum256(page.Data)
+				drawings.Pages = append(drawings.Pages, fmt.Sprintf("%d:%s", page.Page, hex.EncodeToString(sum[:])))
+			}
+			input.Drawings = drawings
+		}
+	}
+
+	// Marshalling a struct of plain fields cannot fail
+	data, _ := json.Marshal(input)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// BidPDFPublisher renders bid PDFs to object storage, reusing the stored PDF
+// when its inputs are unchanged
+type BidPDFPublisher struct {
+	pdf       *PDFService
+	objects   BidPDFStore
+	deletions ObjectDeletionScheduler
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewBidPDFPublisher creates a publisher. Superseded PDFs are scheduled for
+// deletion after retention; deletions may be nil to keep them.
+func NewBidPDFPublisher(objects BidPDFStore, deletions ObjectDeletionScheduler, retention time.Duration) *BidPDFPublisher {
+	return &BidPDFPublisher{
+		pdf:       NewPDFService(),
+		objects:   objects,
+		deletions: deletions,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Publish makes sure the bid's stored PDF matches its current inputs. When
+// the hash matches the bid's PDFHash the existing object is reused; otherwise
+// the PDF is rendered and uploaded under a key derived from the hash, and the
+// superseded object is scheduled for deletion. It updates the bid's PDF
+// fields and reports whether they changed; the caller persists the bid.
+func (p *BidPDFPublisher) Publish(ctx context.Context, bid *models.Bid, bidResponse *models.GenerateBidResponse, projectName string, options *PDFOptions) (bool, error) {
+	hash := BidPDFHash(bid, projectName, options)
+
+	if bid.PDFHash != nil && *bid.PDFHash == hash && bid.PDFS3Key != nil && *bid.PDFS3Key != "" {
+		if bid.PDFURL != nil && *bid.PDFURL != "" {
+			return false, nil
+		}
+		url := p.objects.ObjectURL(*bid.PDFS3Key)
+		bid.PDFURL = &url
+		return true, nil
+	}
+
+	pdfBytes, err := p.pdf.GenerateBidPDFWithOptions(bid, bidResponse, projectName, options)
+	if err != nil {
+		return false, err
+	}
+
+	key := p.pdf.GeneratePDFFilename(bid.ProjectID, bid.ID, hash)
+	url, err := p.objects.UploadFile(ctx, key, pdfBytes, "application/pdf")
+	if err != nil {
+		errreport.CaptureError(ctx, err, map[string]string{errreport.TagComponent: "s3", "bid_id": bid.ID.String()})
+		return false, err
+	}
+
+	if previous := bid.PDFS3Key; previous != nil && *previous != "" && *previous != key {
+		// Non-fatal: the old PDF keeps its storage class until it is deleted
+		if err := p.objects.ArchiveObject(ctx, *previous); err != nil {
+			slog.Warn("Failed to archive superseded PDF", "bid_id", bid.ID, "s3_key", *previous, "error", err)
+		}
+		if p.deletions != nil {
+			if err := p.deletions.Schedule(ctx, *previous, p.now().Add(p.retention)); err != nil {
+				// The old object is orphaned rather than failing the new PDF
+				slog.Error("Failed to schedule superseded PDF deletion", "bid_id", bid.ID, "s3_key", *previous, "error", err)
+			}
+		}
+	}
+
+	bid.PDFURL = &url
+	bid.PDFS3Key = &key
+	bid.PDFHash = &hash
+	return true, nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func (f *fakeObjectStore) UploadFile(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	if f.objects == nil {
+		f.objects = make(map[string][]byte)
+	}
+	f.objects[key] = data
+	return f.ObjectURL(key), nil
+}
+
+func (f *fakeObjectStore) ObjectURL(key string) string {
+	return "https://s3.example.com/" + key
+}
+
+func (f *fakeObjectStore) ArchiveObject(ctx context.Context, key string) error {
+	f.archived = append(f.archived, key)
+	return nil
+}
+
+type fakeDeletions struct {
+	scheduled []*models.ObjectDeletion
+	deleteErr error
+}
+
+func (f *fakeDeletions) Schedule(ctx context.Context, key string, deleteAfter time.Time) error {
+	f.scheduled = append(f.scheduled, &models.ObjectDeletion{ID: uuid.New(), S3Key: key, DeleteAfter: models.NewTimestamp(deleteAfter)})
+	return nil
+}
+
+func (f *fakeDeletions) GetDue(ctx context.Context, now time.Time, limit int) ([]*models.ObjectDeletion, error) {
+	var due []*models.ObjectDeletion
+	for _, deletion := range f.scheduled {
+		if !deletion.DeleteAfter.After(now) && len(due) < limit {
+			due = append(due, deletion)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeDeletions) Delete(ctx context.Context, id uuid.UUID) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	for i, deletion := range f.scheduled {
+		if deletion.ID == id {
+			f.scheduled = append(f.scheduled[:i], f.scheduled[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func newTestPublisher(clock *fakeClock) (*BidPDFPublisher, *fakeObjectStore, *fakeDeletions) {
+	store := &fakeObjectStore{}
+	deletions := &fakeDeletions{}
+	publisher := NewBidPDFPublisher(store, deletions, 24*time.Hour)
+	publisher.now = clock.Now
+	return publisher, store, deletions
+}
+
+func testBidWithData(t *testing.T) (*models.Bid, *models.GenerateBidResponse) {
+	t.Helper()
+	bid, response := testBidForPDF()
+	data := `{"line_items":[{"description":"Drywall","trade":"drywall","quantity":100,"unit":"SF","unit_cost":2,"total":200}],"subtotal":200,"total_price":240}`
+	bid.BidData = &data
+	return bid, response
+}
+
+func TestBidPDFPublisher_ReusesUnchangedPDF(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	publisher, store, deletions := newTestPublisher(clock)
+	bid, response := testBidWithData(t)
+
+	changed, err := publisher.Publish(context.Background(), bid, response, "Office", nil)
+	if err != nil || !changed {
+		t.Fatalf("Publish() = %v, %v; want a new PDF", changed, err)
+	}
+	wantKey := "bids/" + bid.ProjectID.String() + "/" + bid.ID.String() + "/" + *bid.PDFHash + ".pdf"
+	if bid.PDFS3Key == nil || *bid.PDFS3Key != wantKey {
+		t.Fatalf("PDFS3Key = %v, want %s", bid.PDFS3Key, wantKey)
+	}
+	if len(store.objects) != 1 {
+		t.Fatalf("expected 1 uploaded object, got %d", len(store.objects))
+	}
+
+	// Unchanged inputs reuse the object without uploading
+	changed, err = publisher.Publish(context.Background(), bid, response, "Office", nil)
+	if err != nil || changed {
+		t.Errorf("Publish() = %v, %v; want the stored PDF reused", changed, err)
+	}
+
+	// A bid that lost its URL gets it back from the stored key
+	bid.PDFURL = nil
+	changed, err = publisher.Publish(context.Background(), bid, response, "Office", nil)
+	if err != nil || !changed || bid.PDFURL == nil || *bid.PDFURL != store.ObjectURL(wantKey) {
+		t.Errorf("Publish() = %v, %v, url %v; want the URL restored", changed, err, bid.PDFURL)
+	}
+
+	if len(store.objects) != 1 || len(deletions.scheduled) != 0 {
+		t.Errorf("expected no new objects or deletions, got %d objects and %d deletions", len(store.objects), len(deletions.scheduled))
+	}
+}
+
+func TestBidPDFPublisher_ChangedDataSupersedesPDF(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	publisher, store, deletions := newTestPublisher(clock)
+	bid, response := testBidWithData(t)
+
+	if _, err := publisher.Publish(context.Background(), bid, response, "Office", nil); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	oldKey := *bid.PDFS3Key
+
+	updated := strings.Replace(*bid.BidData, `"total_price":240`, `"total_price":260`, 1)
+	bid.BidData = &updated
+	changed, err := publisher.Publish(context.Background(), bid, response, "Office", nil)
+	if err != nil || !changed {
+		t.Fatalf("Publish() = %v, %v; want a new PDF", changed, err)
+	}
+	if *bid.PDFS3Key == oldKey {
+		t.Fatal("expected changed bid data to produce a new key")
+	}
+	if len(store.objects) != 2 {
+		t.Errorf("expected 2 stored objects until cleanup, got %d", len(store.objects))
+	}
+
+	if len(store.archived) != 1 || store.archived[0] != oldKey {
+		t.Errorf("expected the old PDF to be archived, got %v", store.archived)
+	}
+	if len(deletions.scheduled) != 1 || deletions.scheduled[0].S3Key != oldKey {
+		t.Fatalf("expected the old PDF to be scheduled for deletion, got %+v", deletions.scheduled)
+	}
+	if want := clock.now.Add(24 * time.Hour); !deletions.scheduled[0].DeleteAfter.Equal(want) {
+		t.Errorf("DeleteAfter = %v, want %v", deletions.scheduled[0].DeleteAfter.Time, want)
+	}
+
+	// The worker leaves the object alone until the retention window passes
+	cleaner := NewObjectCleaner(deletions, store)
+	cleaner.now = clock.Now
+	if removed := cleaner.RunOnce(context.Background()); removed != 0 {
+		t.Errorf("RunOnce() removed %d objects inside the retention window", removed)
+	}
+
+	clock.Advance(25 * time.Hour)
+	if removed := cleaner.RunOnce(context.Background()); removed != 1 {
+		t.Fatalf("RunOnce() removed %d objects, want 1", removed)
+	}
+	if _, exists := store.objects[oldKey]; exists {
+		t.Error("expected the superseded PDF to be deleted")
+	}
+	if _, exists := store.objects[*bid.PDFS3Key]; !exists {
+		t.Error("expected the current PDF to be kept")
+	}
+	if len(deletions.scheduled) != 0 {
+		t.Errorf("expected the deletion to be cleared, got %d", len(deletions.scheduled))
+	}
+}
+
+func TestObjectCleaner_KeepsFailedDeletionsScheduled(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	deletions := &fakeDeletions{deleteErr: errors.New("db down")}
+	deletions.Schedule(context.Background(), "bids/old.pdf", clock.now.Add(-time.Hour))
+
+	cleaner := NewObjectCleaner(deletions, &fakeObjectStore{})
+	cleaner.now = clock.Now
+	if removed := cleaner.RunOnce(context.Background()); removed != 0 {
+		t.Errorf("RunOnce() = %d, want 0 when the deletion cannot be cleared", removed)
+	}
+	if len(deletions.scheduled) != 1 {
+		t.Error("expected the deletion to stay scheduled for retry")
+	}
+}
+
+func TestBidPDFHash(t *testing.T) {
+	bid, _ := testBidWithData(t)
+	base := BidPDFHash(bid, "Office", nil)
+
+	if BidPDFHash(bid, "Office", nil) != base {
+		t.Error("expected the hash to be stable")
+	}
+	if len(base) != 64 {
+		t.Errorf("expected a hex SHA-256, got %q", base)
+	}
+
+	variants := map[string]string{
+		"project name":    BidPDFHash(bid, "Warehouse", nil),
+		"company info":    BidPDFHash(bid, "Office", &PDFOptions{CompanyInfo: &models.CompanyInfo{Name: "Acme"}}),
+		"estimate range":  BidPDFHash(bid, "Office", &PDFOptions{IncludeEstimateRange: true}),
+		"signature block": BidPDFHash(bid, "Office", &PDFOptions{IncludeSignatureBlock: true}),
+		"drawings": BidPDFHash(bid, "Office", &PDFOptions{BlueprintPages: &BlueprintAttachment{
+			Filename: "A-101.png", Version: 1, Pages: []BlueprintPageImage{{Page: 1, Data: []byte("png")}},
+		}}),
+	}
+	for name, hash := range variants {
+		if hash == base {
+			t.Errorf("expected %s to change the hash", name)
+		}
+	}
+}
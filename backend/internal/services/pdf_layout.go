@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxPDFSectionTitleLength caps a custom section heading
+const MaxPDFSectionTitleLength = 80
+
+// PDFSections are the bid document's sections in the default print order
+var PDFSections = []models.PDFSection{
+	models.PDFSectionCover,
+	models.PDFSectionProjectInfo,
+	models.PDFSectionScope,
+	models.PDFSectionLineItems,
+	models.PDFSectionTradeBreakdown,
+	models.PDFSectionCostSummary,
+	models.PDFSectionInclusions,
+	models.PDFSectionExclusions,
+	models.PDFSectionSchedule,
+	models.PDFSectionTerms,
+	models.PDFSectionSignature,
+}
+
+// DefaultPDFLayout prints every section in the default order
+func DefaultPDFLayout() *models.PDFLayout {
+	layout := &models.PDFLayout{Sections: make([]models.PDFSectionLayout, len(PDFSections))}
+	for i, section := range PDFSections {
+		layout.Sections[i] = models.PDFSectionLayout{Section: section, Enabled: true}
+	}
+	return layout
+}
+
+// ValidatePDFLayout checks every section is known and listed once, and trims
+// custom titles in place
+func ValidatePDFLayout(layout *models.PDFLayout) error {
+	if layout == nil || len(layout.Sections) == 0 {
+		return fmt.Errorf("layout must list at least one section")
+	}
+
+	seen := make(map[models.PDFSection]bool)
+	for i := range layout.Sections {
+		entry := &layout.Sections[i]
+		if !isPDFSection(entry.Section) {
+			return fmt.Errorf("unknown section %q", entry.Section)
+		}
+		if seen[entry.Section] {
+			return fmt.Errorf("section %q is listed more than once", entry.Section)
+		}
+		seen[entry.Section] = true
+
+		entry.Title = strings.TrimSpace(entry.Title)
+		if len(entry.Title) > MaxPDFSectionTitleLength {
+			return fmt.Errorf("title for section %q must be at most %d characters", entry.Section, MaxPDFSectionTitleLength)
+		}
+	}
+	return nil
+}
+
+func isPDFSection(section models.PDFSection) bool {
+	for _, known := range PDFSections {
+		if section == known {
+			return true
+		}
+	}
+	return false
+}
+
+// enabledPDFSections lists the sections layout prints, in order; nil is the
+// default layout
+func enabledPDFSections(layout *models.PDFLayout) []models.PDFSectionLayout {
+	if layout == nil {
+		layout = DefaultPDFLayout()
+	}
+	var sections []models.PDFSectionLayout
+	for _, entry := range layout.Sections {
+		if entry.Enabled {
+			sections = append(sections, entry)
+		}
+	}
+	return sections
+}
+
+// PDFSectionEnabled reports whether layout prints section; nil is the
+// default layout, which prints everything
+func PDFSectionEnabled(layout *models.PDFLayout, section models.PDFSection) bool {
+	for _, entry := range enabledPDFSections(layout) {
+		if entry.Section == section {
+			return true
+		}
+	}
+	return false
+}
+
+// SamplePDFBid is a made-up bid for previewing a layout, with every section
+// populated
+func SamplePDFBid() (*models.Bid, *models.GenerateBidResponse, string) {
+	bid := &models.Bid{ID: uuid.New(), Status: models.BidStatusDraft}
+	response := &models.GenerateBidResponse{
+		ScopeOfWork: "Interior renovation of a 1,200 sq ft office suite, including framing, drywall, flooring, paint and electrical.",
+		LineItems: []models.LineItem{
+			{Description: "Framing and drywall installation", Trade: "framing", Quantity: 1200, Unit: "sq ft", UnitCost: 5.50, Total: 6600},
+			{Description: "Flooring installation - Carpet", Trade: "general", Quantity: 1200, Unit: "sq ft", UnitCost: 4.25, Total: 5100},
+			{Description: "Paint and finishing", Trade: "painting", Quantity: 1200, Unit: "sq ft", UnitCost: 3.50, Total: 4200},
+			{Description: "Electrical fixtures and outlets", Trade: "electrical", Quantity: 24, Unit: "each", UnitCost: 125, Total: 3000},
+			{Description: "Labor - electrical", Trade: "electrical", Quantity: 16, Unit: "hours", UnitCost: 95, Total: 1520},
+		},
+		LaborCost:    11860,
+		MaterialCost: 8560,
+		Subtotal:     20420,
+		MarkupAmount: 4084,
+		TotalPrice:   24504,
+		Alternates: []models.AlternateGroup{{
+			Name:      "Upgrade to luxury vinyl plank",
+			LineItems: []models.LineItem{{Description: "Luxury vinyl plank upgrade", Trade: "general", Quantity: 1200, Unit: "sq ft", UnitCost: 2.00, Total: 2400, IsAlternate: true}},
+			Cost:      2400,
+			Price:     2880,
+		}},
+		Inclusions:       []string{"All permits and inspections", "Daily cleanup and debris removal"},
+		Exclusions:       []string{"Furniture moving", "Asbestos abatement"},
+		Schedule:         map[string]string{"Demolition": "Week 1", "Framing and drywall": "Weeks 2-3", "Finishes": "Weeks 4-5"},
+		PaymentTerms:     "30% deposit, 40% at drywall completion, 30% on completion.",
+		WarrantyTerms:    "One year on workmanship from the date of completion.",
+		ClosingStatement: "Thank you for the opportunity to bid on this project.",
+	}
+	return bid, response, "Sample Office Renovation"
+}
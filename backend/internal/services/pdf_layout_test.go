@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/csv"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+var pdfStreamPattern = regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`)
+
+// pdfPageText inflates a PDF's page content streams so tests can look for
+// the text drawn on them
+func pdfPageText(t *testing.T, data []byte) string {
+	t.Helper()
+	var text strings.Builder
+	for _, match := range pdfStreamPattern.FindAllSubmatch(data, -1) {
+		reader, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed to inflate PDF stream: %v", err)
+		}
+		text.Write(content)
+	}
+	return text.String()
+}
+
+func TestValidatePDFLayout(t *testing.T) {
+	t.Run("default layout is valid", func(t *testing.T) {
+		if err := ValidatePDFLayout(DefaultPDFLayout()); err != nil {
+			t.Errorf("ValidatePDFLayout() error = %v", err)
+		}
+	})
+
+	t.Run("trims titles", func(t *testing.T) {
+		layout := &models.PDFLayout{Sections: []models.PDFSectionLayout{
+			{Section: models.PDFSectionScope, Enabled: true, Title: "  Our Approach "},
+		}}
+		if err := ValidatePDFLayout(layout); err != nil {
+			t.Fatalf("ValidatePDFLayout() error = %v", err)
+		}
+		if got := layout.Sections[0].Title; got != "Our Approach" {
+			t.Errorf("Title = %q, want %q", got, "Our Approach")
+		}
+	})
+
+	invalid := map[string]*models.PDFLayout{
+		"nil":   nil,
+		"empty": {},
+		"unknown section": {Sections: []models.PDFSectionLayout{
+			{Section: models.PDFSectionScope, Enabled: true},
+			{Section: "appendix", Enabled: true},
+		}},
+		"duplicate section": {Sections: []models.PDFSectionLayout{
+			{Section: models.PDFSectionScope, Enabled: true},
+			{Section: models.PDFSectionScope, Enabled: false},
+		}},
+		"title too long": {Sections: []models.PDFSectionLayout{
+			{Section: models.PDFSectionScope, Enabled: true, Title: strings.Repeat("x", MaxPDFSectionTitleLength+1)},
+		}},
+	}
+	for name, layout := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := ValidatePDFLayout(layout); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestGenerateBidPDF_Layout(t *testing.T) {
+	service := NewPDFService()
+	bid, response, projectName := SamplePDFBid()
+	render := func(t *testing.T, layout *models.PDFLayout) string {
+		t.Helper()
+		pdfBytes, err := service.GenerateBidPDFWithOptions(bid, response, projectName, &PDFOptions{
+			CompanyInfo:           &models.CompanyInfo{Name: "Acme Builders"},
+			IncludeCover:          true,
+			IncludeSignatureBlock: true,
+			Layout:                layout,
+		})
+		if err != nil {
+			t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+		}
+		return pdfPageText(t, pdfBytes)
+	}
+
+	t.Run("default order", func(t *testing.T) {
+		text := render(t, nil)
+		last := -1
+		for _, heading := range []string{"BID PROPOSAL", "Project Information", "Scope of Work", "Cost Breakdown", "Cost Summary", "Inclusions", "Exclusions", "Project Schedule", "Acceptance"} {
+			at := strings.Index(text, "("+heading+")")
+			if at < 0 {
+				t.Fatalf("expected %q in the PDF", heading)
+			}
+			if at < last {
+				t.Errorf("expected %q after the previous section", heading)
+			}
+			last = at
+		}
+	})
+
+	t.Run("reordered with custom titles", func(t *testing.T) {
+		text := render(t, &models.PDFLayout{Sections: []models.PDFSectionLayout{
+			{Section: models.PDFSectionCostSummary, Enabled: true, Title: "Your Investment"},
+			{Section: models.PDFSectionScope, Enabled: true},
+			{Section: models.PDFSectionProjectInfo, Enabled: true},
+		}})
+		investment := strings.Index(text, "(Your Investment)")
+		scope := strings.Index(text, "(Scope of Work)")
+		info := strings.Index(text, "(Project Information)")
+		if investment < 0 || scope < 0 || info < 0 {
+			t.Fatalf("expected all three sections in the PDF")
+		}
+		if !(investment < scope && scope < info) {
+			t.Errorf("sections out of order: cost summary at %d, scope at %d, project info at %d", investment, scope, info)
+		}
+		if strings.Contains(text, "(Cost Summary)") {
+			t.Error("expected the custom title to replace the default heading")
+		}
+	})
+
+	t.Run("disabled and omitted sections are absent", func(t *testing.T) {
+		layout := DefaultPDFLayout()
+		for i := range layout.Sections {
+			switch layout.Sections[i].Section {
+			case models.PDFSectionCover, models.PDFSectionExclusions, models.PDFSectionSignature:
+				layout.Sections[i].Enabled = false
+			}
+		}
+		layout.Sections = layout.Sections[:len(layout.Sections)-2] // drops terms
+		text := render(t, layout)
+
+		for _, heading := range []string{"BID PROPOSAL", "Exclusions", "Acceptance", "Payment Terms", "Warranty"} {
+			if strings.Contains(text, "("+heading+")") {
+				t.Errorf("expected %q to be left out", heading)
+			}
+		}
+		if !strings.Contains(text, "(Inclusions)") {
+			t.Error("expected enabled sections to remain")
+		}
+	})
+}
+
+func TestGenerateBidCSVWithLayout(t *testing.T) {
+	service := NewExportService()
+	bid, response, projectName := SamplePDFBid()
+	layout := &models.PDFLayout{Sections: []models.PDFSectionLayout{
+		{Section: models.PDFSectionScope, Enabled: true},
+		{Section: models.PDFSectionLineItems, Enabled: false},
+		{Section: models.PDFSectionCostSummary, Enabled: true},
+	}}
+
+	csvBytes, err := service.GenerateBidCSVWithLayout(bid, response, projectName, layout)
+	if err != nil {
+		t.Fatalf("GenerateBidCSVWithLayout() error = %v", err)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(csvBytes))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	headings := make(map[string]bool)
+	for _, record := range records {
+		if len(record) > 0 {
+			headings[record[0]] = true
+		}
+	}
+
+	for _, want := range []string{"Scope of Work", "Cost Summary"} {
+		if !headings[want] {
+			t.Errorf("expected %q in the CSV", want)
+		}
+	}
+	for _, absent := range []string{"Project", "Line Items", "Trade Breakdown", "Inclusions", "Exclusions", "Payment Terms"} {
+		if headings[absent] {
+			t.Errorf("expected %q to be left out of the CSV", absent)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package services
 
 import (
+	"fmt"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf/v2"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
@@ -33,8 +37,8 @@ func TestGenerateBidPDF(t *testing.T) {
 		Status:           models.BidStatusDraft,
 		Version:          1,
 		IsLatest:         true,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+		CreatedAt:        models.Now(),
+		UpdatedAt:        models.Now(),
 	}
 
 	// Create test bid response
@@ -251,21 +255,298 @@ func TestGeneratePDFFilename(t *testing.T) {
 	projectID := uuid.New()
 	bidID := uuid.New()
 
-	filename := service.GeneratePDFFilename(projectID, bidID)
+	filename := service.GeneratePDFFilename(projectID, bidID, "abc123")
 
-	// Check filename format
-	if len(filename) == 0 {
-		t.Error("GeneratePDFFilename() returned empty string")
+	// Keys are deterministic per bid and content hash
+	expected := "bids/" + projectID.String() + "/" + bidID.String() + "/abc123.pdf"
+	if filename != expected {
+		t.Errorf("GeneratePDFFilename() = %s, want %s", filename, expected)
+	}
+	if again := service.GeneratePDFFilename(projectID, bidID, "abc123"); again != filename {
+		t.Errorf("GeneratePDFFilename() is not deterministic: %s vs %s", again, filename)
+	}
+}
+
+func TestAddLineItemsTable_Notes(t *testing.T) {
+	service := NewPDFService()
+	longNotes := strings.Repeat("Assumes existing panel has capacity for the new circuits; ", 8)
+	items := []models.LineItem{
+		{Description: "Panel upgrade", Quantity: 1, Unit: "EA", UnitCost: 2400, Total: 2400, Notes: longNotes},
+		{Description: "Outlets", Quantity: 12, Unit: "EA", UnitCost: 85, Total: 1020},
 	}
 
-	// Check it contains expected parts
-	expectedPrefix := "bids/" + projectID.String() + "/bid-"
-	if len(filename) < len(expectedPrefix) || filename[:len(expectedPrefix)] != expectedPrefix {
-		t.Errorf("Filename doesn't start with expected prefix. Got: %s", filename)
+	newPDF := func() *gofpdf.Fpdf {
+		pdf := gofpdf.New("P", "mm", "A4", "")
+		pdf.SetMargins(20, 20, 20)
+		pdf.AddPage()
+		return pdf
 	}
 
-	// Check it ends with .pdf
-	if len(filename) < 4 || filename[len(filename)-4:] != ".pdf" {
-		t.Error("Filename doesn't end with .pdf")
+	t.Run("long notes wrap under the item", func(t *testing.T) {
+		pdf := newPDF()
+		lines := service.lineItemNoteLines(pdf, longNotes)
+		if len(lines) < 3 {
+			t.Fatalf("expected long notes to wrap onto several lines, got %d", len(lines))
+		}
+
+		start := pdf.GetY()
+		service.addLineItemsTable(pdf, items)
+		if err := pdf.Error(); err != nil {
+			t.Fatalf("addLineItemsTable() error = %v", err)
+		}
+		want := start + 3*lineItemRowHeight + float64(len(lines))*lineItemNoteLineHeight
+		if got := pdf.GetY(); math.Abs(got-want) > 0.01 {
+			t.Errorf("table ended at y=%.2f, want %.2f for a header, two rows and %d note lines", got, want, len(lines))
+		}
+	})
+
+	t.Run("an item and its notes move to the next page together", func(t *testing.T) {
+		pdf := newPDF()
+		_, pageHeight := pdf.GetPageSize()
+		_, bottomMargin := pdf.GetAutoPageBreak()
+		// Room for the header and the item row but not its notes
+		pdf.SetY(pageHeight - bottomMargin - 2*lineItemRowHeight - lineItemNoteLineHeight)
+
+		service.addLineItemsTable(pdf, items[:1])
+		if err := pdf.Error(); err != nil {
+			t.Fatalf("addLineItemsTable() error = %v", err)
+		}
+		if pdf.PageNo() != 2 {
+			t.Fatalf("expected the item to move to page 2, on page %d", pdf.PageNo())
+		}
+		lines := service.lineItemNoteLines(pdf, longNotes)
+		_, top, _, _ := pdf.GetMargins()
+		want := top + 2*lineItemRowHeight + float64(len(lines))*lineItemNoteLineHeight
+		if got := pdf.GetY(); math.Abs(got-want) > 0.01 {
+			t.Errorf("expected the header to repeat above the item on page 2: y=%.2f, want %.2f", got, want)
+		}
+	})
+
+	t.Run("rendered in the bid PDF", func(t *testing.T) {
+		bid, response := testBidForPDF()
+		response.LineItems[0].Notes = longNotes
+		pdfBytes, err := service.GenerateBidPDF(bid, response, "Test Project")
+		if err != nil || len(pdfBytes) == 0 {
+			t.Fatalf("GenerateBidPDF() error = %v", err)
+		}
+	})
+}
+
+func TestAddSignatureBlock(t *testing.T) {
+	service := NewPDFService()
+
+	t.Run("moves to the next page rather than splitting", func(t *testing.T) {
+		pdf := gofpdf.New("P", "mm", "A4", "")
+		pdf.SetMargins(20, 20, 20)
+		pdf.AddPage()
+		_, pageHeight := pdf.GetPageSize()
+		_, bottomMargin := pdf.GetAutoPageBreak()
+		pdf.SetY(pageHeight - bottomMargin - signatureBlockHeight/2)
+
+		service.addSignatureBlock(pdf, "Acme Builders", "Acceptance")
+		if err := pdf.Error(); err != nil {
+			t.Fatalf("addSignatureBlock() error = %v", err)
+		}
+		if pdf.PageNo() != 2 {
+			t.Errorf("expected the signature block on page 2, on page %d", pdf.PageNo())
+		}
+		_, top, _, _ := pdf.GetMargins()
+		if used := pdf.GetY() - top; used > signatureBlockHeight {
+			t.Errorf("signature block used %.2fmm, more than the %.2fmm reserved", used, signatureBlockHeight)
+		}
+	})
+
+	t.Run("rendered in the bid PDF", func(t *testing.T) {
+		bid, response := testBidForPDF()
+		without, err := service.GenerateBidPDFWithOptions(bid, response, "Test Project", nil)
+		if err != nil {
+			t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+		}
+		with, err := service.GenerateBidPDFWithOptions(bid, response, "Test Project", &PDFOptions{
+			CompanyInfo:           &models.CompanyInfo{Name: "Acme Builders"},
+			IncludeSignatureBlock: true,
+		})
+		if err != nil {
+			t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+		}
+		if len(with) <= len(without) {
+			t.Errorf("expected the signature block to add to the PDF: %d bytes with, %d without", len(with), len(without))
+		}
+	})
+}
+
+func TestGenerateBidPDF_AcceptanceStamp(t *testing.T) {
+	service := NewPDFService()
+	bid, response := testBidForPDF()
+	acceptance := &models.BidAcceptance{SignerName: "Zoë Owner", AcceptedAt: models.NewTimestamp(time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC))}
+	if got := BidAcceptanceStamp(acceptance); got != "Accepted by Zoë Owner on May 1, 2026" {
+		t.Errorf("BidAcceptanceStamp() = %q", got)
+	}
+
+	without, err := service.GenerateBidPDFWithOptions(bid, response, "Test Project", nil)
+	if err != nil {
+		t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+	}
+	stamped := &PDFOptions{Acceptance: acceptance}
+	with, err := service.GenerateBidPDFWithOptions(bid, response, "Test Project", stamped)
+	if err != nil {
+		t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+	}
+	if len(with) <= len(without) {
+		t.Errorf("expected the stamp to add to the PDF: %d bytes with, %d without", len(with), len(without))
+	}
+	if BidPDFHash(bid, "Test Project", stamped) == BidPDFHash(bid, "Test Project", nil) {
+		t.Error("stamped PDF hash matches the unstamped one")
+	}
+}
+
+func TestAddSourceBlueprints(t *testing.T) {
+	service := NewPDFService()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "", 10)
+	start := pdf.GetY()
+
+	service.addSourceBlueprints(pdf, []models.BidSourceBlueprint{
+		{BlueprintID: uuid.New(), Filename: "building-a.pdf", Version: 2},
+		{BlueprintID: uuid.New(), Filename: "building-b.pdf", Version: 1},
+	})
+	if err := pdf.Error(); err != nil {
+		t.Fatalf("addSourceBlueprints() error = %v", err)
+	}
+	if lines := math.Round((pdf.GetY() - start) / 6); lines != 2 {
+		t.Errorf("expected one line per blueprint, got %v", lines)
+	}
+}
+
+func TestGenerateBidPDF_LowConfidenceCaveat(t *testing.T) {
+	service := NewPDFService()
+	bid, response := testBidForPDF()
+	without, err := service.GenerateBidPDFWithOptions(bid, response, "Test Project", nil)
+	if err != nil {
+		t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+	}
+
+	response.GeneratedFromLowConfidence = true
+	with, err := service.GenerateBidPDFWithOptions(bid, response, "Test Project", nil)
+	if err != nil {
+		t.Fatalf("GenerateBidPDFWithOptions() error = %v", err)
+	}
+	if len(with) <= len(without) {
+		t.Errorf("expected the caveat to add to the PDF: %d bytes with, %d without", len(with), len(without))
+	}
+}
+
+func TestGenerateBidPDF_LongLineItemList(t *testing.T) {
+	service := NewPDFService()
+	bid, response := testBidForPDF()
+	response.LineItems = nil
+	for i := 0; i < 100; i++ {
+		response.LineItems = append(response.LineItems, models.LineItem{
+			Description: fmt.Sprintf("Line item %d", i+1), Trade: fmt.Sprintf("trade %02d", i%40),
+			Quantity: 1, Unit: "EA", UnitCost: 10, Total: 10,
+		})
+	}
+
+	pdf := service.renderBidPDF(bid, response, "Test Project", nil)
+	if err := pdf.Error(); err != nil {
+		t.Fatalf("renderBidPDF() error = %v", err)
+	}
+	if pdf.PageCount() < 2 {
+		t.Errorf("expected 100 line items to span several pages, got %d", pdf.PageCount())
+	}
+
+	pdfBytes, err := service.GenerateBidPDF(bid, response, "Test Project")
+	if err != nil {
+		t.Fatalf("GenerateBidPDF() error = %v", err)
+	}
+	if len(pdfBytes) < 4 || string(pdfBytes[:4]) != "%PDF" {
+		t.Error("expected the output to be a PDF")
+	}
+}
+
+func TestAddLineItemsTable_LongDescriptions(t *testing.T) {
+	service := NewPDFService()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	wrapped := strings.Repeat("Furnish and install gypsum board ", 2)
+	lines := service.lineItemDescriptionLines(pdf, wrapped)
+	if len(lines) != 2 {
+		t.Fatalf("expected the description to wrap onto 2 lines, got %q", lines)
+	}
+	for _, line := range lines {
+		if width := pdf.GetStringWidth(line); width > lineItemDescriptionWidth {
+			t.Errorf("line %q is %.2fmm wide, more than the %.0fmm column", line, width, lineItemDescriptionWidth)
+		}
+	}
+
+	truncated := service.lineItemDescriptionLines(pdf, strings.Repeat(wrapped, 10))
+	if len(truncated) != lineItemMaxDescriptionLines || !strings.HasSuffix(truncated[len(truncated)-1], "...") {
+		t.Errorf("expected a very long description cut at %d lines with an ellipsis, got %q", lineItemMaxDescriptionLines, truncated)
+	}
+
+	start := pdf.GetY()
+	service.addLineItemsTable(pdf, []models.LineItem{{Description: wrapped, Quantity: 1, Unit: "EA", UnitCost: 10, Total: 10}})
+	if err := pdf.Error(); err != nil {
+		t.Fatalf("addLineItemsTable() error = %v", err)
+	}
+	want := start + lineItemRowHeight + 2*lineItemDescriptionLineHeight + 1.5
+	if got := pdf.GetY(); math.Abs(got-want) > 0.01 {
+		t.Errorf("table ended at y=%.2f, want %.2f for a header and a two-line row", got, want)
+	}
+}
+
+func TestRenderCostSummarySection_MovesWhole(t *testing.T) {
+	service := NewPDFService()
+	bid, response := testBidForPDF()
+	doc := &bidPDF{pdf: gofpdf.New("P", "mm", "A4", ""), bid: bid, response: response, projectName: "Test Project"}
+	doc.pdf.SetMargins(20, 20, 20)
+	service.startBody(doc)
+
+	_, pageHeight := doc.pdf.GetPageSize()
+	_, bottomMargin := doc.pdf.GetAutoPageBreak()
+	// Room for the heading and two summary lines
+	doc.pdf.SetY(pageHeight - bottomMargin - sectionHeadingHeight - 12)
+
+	service.renderCostSummarySection(doc, "")
+	if err := doc.pdf.Error(); err != nil {
+		t.Fatalf("renderCostSummarySection() error = %v", err)
+	}
+	if doc.pdf.PageNo() != 2 {
+		t.Fatalf("expected the cost summary to move to page 2, on page %d", doc.pdf.PageNo())
+	}
+	_, top, _, _ := doc.pdf.GetMargins()
+	want := top + sectionHeadingHeight + costSummaryHeight(response) + 5
+	if got := doc.pdf.GetY(); math.Abs(got-want) > 0.01 {
+		t.Errorf("cost summary ended at y=%.2f, want %.2f with all of it on page 2", got, want)
+	}
+}
+
+func TestAddTradeBreakdown_RepeatsHeader(t *testing.T) {
+	service := NewPDFService()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+	_, pageHeight := pdf.GetPageSize()
+	_, bottomMargin := pdf.GetAutoPageBreak()
+	// Room for the header and one trade
+	pdf.SetY(pageHeight - bottomMargin - 12)
+
+	service.addTradeBreakdown(pdf, []models.LineItem{
+		{Trade: "drywall", Total: 200},
+		{Trade: "electrical", Total: 300},
+	})
+	if err := pdf.Error(); err != nil {
+		t.Fatalf("addTradeBreakdown() error = %v", err)
+	}
+	if pdf.PageNo() != 2 {
+		t.Fatalf("expected the breakdown to continue on page 2, on page %d", pdf.PageNo())
+	}
+	_, top, _, _ := pdf.GetMargins()
+	// The header, the second trade and the total
+	if got, want := pdf.GetY(), top+3*6; math.Abs(got-want) > 0.01 {
+		t.Errorf("breakdown ended at y=%.2f, want %.2f with the header repeated", got, want)
 	}
 }
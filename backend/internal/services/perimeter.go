@@ -0,0 +1,74 @@
+package services
+
+import (
+	"math"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// wallLengthMeasurement is the measurement type the analysis reports run
+// lengths of wall under
+const wallLengthMeasurement = "wall_length"
+
+// perimeterPricing is how each linear-foot item along the walls is described
+// and priced. Unit costs come from the pricing config's material prices.
+var perimeterPricing = []struct {
+	description string
+	trade       string
+	priceKey    string
+	laborShare  float64
+}{
+	{"Wall framing", "framing", "wall_framing_lf", 0.6},
+	{"Top plate", "framing", "top_plate", 0.5},
+	{"Baseboard and trim", "carpentry", "baseboard", 0.55},
+}
+
+// pricingWallLength returns the linear feet of wall to price: the takeoff
+// perimeter, or when that is zero the wall_length measurements from the
+// analysis. Measurements in meters are converted to feet.
+func pricingWallLength(takeoff *models.TakeoffSummary, analysis *models.AnalysisResult) float64 {
+	if takeoff != nil && takeoff.TotalPerimeter > 0 {
+		return takeoff.TotalPerimeter
+	}
+	if analysis == nil {
+		return 0
+	}
+	var length float64
+	for _, measurement := range analysis.Measurements {
+		if !strings.EqualFold(strings.TrimSpace(measurement.MeasurementType), wallLengthMeasurement) || measurement.Value <= 0 {
+			continue
+		}
+		value := measurement.Value
+		if metricLengthUnits[strings.ToLower(strings.TrimSpace(measurement.Unit))] {
+			value /= MetersPerFoot
+		}
+		length += value
+	}
+	return math.Round(length*100) / 100
+}
+
+// buildPerimeterItems prices framing, top plate and trim by the linear foot
+// of wall. Items without a configured price are left out. sources may be nil
+// when price provenance is not tracked.
+func buildPerimeterItems(wallLength float64, config *models.PricingConfig, sources *ResolvedPricingConfig) []installedWork {
+	var items []installedWork
+	if wallLength <= 0 {
+		return items
+	}
+	for _, perimeter := range perimeterPricing {
+		price := config.MaterialPrices[perimeter.priceKey]
+		if price <= 0 {
+			continue
+		}
+
+		items = append(items, installWork(models.LineItem{
+			Description: perimeter.description,
+			Trade:       perimeter.trade,
+			Quantity:    wallLength,
+			Unit:        UnitLabelLinearFeet,
+			PriceSource: sources.materialSource(perimeter.priceKey),
+		}, price, perimeter.laborShare, config, perimeter.priceKey))
+	}
+	return items
+}
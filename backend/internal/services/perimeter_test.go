@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func findLineItem(items []models.LineItem, description string) *models.LineItem {
+	for i := range items {
+		if items[i].Description == description {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func TestPricingWallLength(t *testing.T) {
+	analysis := &models.AnalysisResult{Measurements: []models.Measurement{
+		{MeasurementType: "wall_length", Value: 40, Unit: "LF"},
+		{MeasurementType: "Wall_Length", Value: 10, Unit: "m"},
+		{MeasurementType: "ceiling_height", Value: 9, Unit: "ft"},
+	}}
+
+	tests := []struct {
+		name     string
+		takeoff  *models.TakeoffSummary
+		analysis *models.AnalysisResult
+		want     float64
+	}{
+		{"takeoff perimeter", &models.TakeoffSummary{TotalPerimeter: 120}, analysis, 120},
+		{"wall length measurements", &models.TakeoffSummary{}, analysis, 72.81},
+		{"no takeoff", nil, analysis, 72.81},
+		{"nothing to measure", &models.TakeoffSummary{}, &models.AnalysisResult{}, 0},
+		{"no data", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pricingWallLength(tt.takeoff, tt.analysis); got != tt.want {
+				t.Errorf("pricingWallLength() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePricingSummary_PerimeterItems(t *testing.T) {
+	withPerimeter := &models.TakeoffSummary{TotalArea: 400, TotalPerimeter: 80}
+	withoutPerimeter := &models.TakeoffSummary{TotalArea: 400}
+
+	base, err := NewPricingService().GeneratePricingSummary(withoutPerimeter, nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	if item := findLineItem(base.LineItems, "Wall framing"); item != nil {
+		t.Errorf("wall framing priced without a perimeter: %+v", item)
+	}
+
+	summary, err := NewPricingService().GeneratePricingSummary(withPerimeter, nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	want := map[string]struct {
+		trade    string
+		unitCost float64
+	}{
+		"Wall framing":       {"framing", 12.00},
+		"Top plate":          {"framing", 2.50},
+		"Baseboard and trim": {"carpentry", 4.25},
+	}
+	for description, expected := range want {
+		item := findLineItem(summary.LineItems, description)
+		if item == nil {
+			t.Errorf("no %q line item", description)
+			continue
+		}
+		if item.Trade != expected.trade || item.Quantity != 80 || item.Unit != "LF" || item.UnitCost != expected.unitCost || item.Total != 80*expected.unitCost {
+			t.Errorf("%s = %+v, want 80 LF of %s at %.2f", description, item, expected.trade, expected.unitCost)
+		}
+		if item.MaterialCost+item.LaborCost != item.Total {
+			t.Errorf("%s splits %.2f + %.2f, want %.2f", description, item.MaterialCost, item.LaborCost, item.Total)
+		}
+	}
+	if summary.TotalPrice <= base.TotalPrice || summary.CostsByTrade["carpentry"] <= base.CostsByTrade["carpentry"] {
+		t.Errorf("total = %.2f with a perimeter, %.2f without; want it higher", summary.TotalPrice, base.TotalPrice)
+	}
+
+	// A configured price replaces the default; a missing one leaves the item out
+	config := *NewPricingService().defaultConfig
+	config.MaterialPrices = map[string]float64{"baseboard": 6.00}
+	custom, err := NewPricingService().GeneratePricingSummary(withPerimeter, nil, &config)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	if item := findLineItem(custom.LineItems, "Baseboard and trim"); item == nil || item.Total != 480 {
+		t.Errorf("baseboard at a configured 6.00/LF = %+v, want $480", item)
+	}
+	if item := findLineItem(custom.LineItems, "Wall framing"); item != nil {
+		t.Errorf("wall framing priced without a configured price: %+v", item)
+	}
+}
+
+func TestEnhancedPricingSummary_PerimeterFromMeasurements(t *testing.T) {
+	service := NewEnhancedPricingService(nil, nil, nil, nil)
+	takeoff := &models.TakeoffSummary{TotalArea: 400}
+	analysis := &models.AnalysisResult{Measurements: []models.Measurement{
+		{MeasurementType: "wall_length", Value: 60, Unit: "LF"},
+		{MeasurementType: "wall_length", Value: 40, Unit: "LF"},
+	}}
+
+	base, err := service.GenerateProjectPricingSummary(context.Background(), takeoff, &models.AnalysisResult{}, nil, nil, nil, models.ProjectTypeNewConstruction)
+	if err != nil {
+		t.Fatalf("GenerateProjectPricingSummary() error = %v", err)
+	}
+	summary, err := service.GenerateProjectPricingSummary(context.Background(), takeoff, analysis, nil, nil, nil, models.ProjectTypeNewConstruction)
+	if err != nil {
+		t.Fatalf("GenerateProjectPricingSummary() error = %v", err)
+	}
+
+	for _, description := range []string{"Wall framing", "Top plate", "Baseboard and trim"} {
+		if item := findLineItem(base.LineItems, description); item != nil {
+			t.Errorf("%s priced without wall lengths: %+v", description, item)
+		}
+		item := findLineItem(summary.LineItems, description)
+		if item == nil || item.Quantity != 100 {
+			t.Errorf("%s = %+v, want 100 LF from the wall_length measurements", description, item)
+			continue
+		}
+		if item.PriceSource == nil || item.PriceSource.Source != PriceSourceDefault {
+			t.Errorf("%s price source = %+v, want the default", description, item.PriceSource)
+		}
+	}
+	if summary.TotalPrice <= base.TotalPrice {
+		t.Errorf("total = %.2f with wall lengths, %.2f without; want it higher", summary.TotalPrice, base.TotalPrice)
+	}
+}
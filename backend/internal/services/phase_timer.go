@@ -0,0 +1,60 @@
+package services
+
+import "time"
+
+// PhaseTimer records how long each phase of a multi-step operation takes so
+// slow requests can be attributed to pricing, AI, PDF rendering, storage, etc.
+// It is not safe for concurrent use.
+type PhaseTimer struct {
+	now     func() time.Time
+	start   time.Time
+	order   []string
+	elapsed map[string]time.Duration
+}
+
+// NewPhaseTimer starts a timer for an operation
+func NewPhaseTimer() *PhaseTimer {
+	return newPhaseTimerWithClock(time.Now)
+}
+
+func newPhaseTimerWithClock(now func() time.Time) *PhaseTimer {
+	return &PhaseTimer{
+		now:     now,
+		start:   now(),
+		elapsed: make(map[string]time.Duration),
+	}
+}
+
+// Start begins timing a phase and returns a function that stops it. Timing
+// the same phase more than once accumulates its duration.
+func (t *PhaseTimer) Start(phase string) func() {
+	if _, seen := t.elapsed[phase]; !seen {
+		t.order = append(t.order, phase)
+		t.elapsed[phase] = 0
+	}
+	started := t.now()
+	return func() {
+		t.elapsed[phase] += t.now().Sub(started)
+	}
+}
+
+// Timings returns each phase as "<phase>_ms" plus "total_ms" for the whole
+// operation so far
+func (t *PhaseTimer) Timings() map[string]int64 {
+	timings := make(map[string]int64, len(t.order)+1)
+	for _, phase := range t.order {
+		timings[phase+"_ms"] = t.elapsed[phase].Milliseconds()
+	}
+	timings["total_ms"] = t.now().Sub(t.start).Milliseconds()
+	return timings
+}
+
+// LogArgs returns the timings as slog key/value pairs in phase order
+func (t *PhaseTimer) LogArgs() []any {
+	timings := t.Timings()
+	args := make([]any, 0, 2*len(timings))
+	for _, phase := range t.order {
+		args = append(args, phase+"_ms", timings[phase+"_ms"])
+	}
+	return append(args, "total_ms", timings["total_ms"])
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPhaseTimer_Timings(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	timer := newPhaseTimerWithClock(clock.Now)
+
+	phases := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"pricing", 40 * time.Millisecond},
+		{"ai", 900 * time.Millisecond},
+		{"pdf", 120 * time.Millisecond},
+		{"s3", 60 * time.Millisecond},
+	}
+	for _, p := range phases {
+		stop := timer.Start(p.name)
+		clock.Advance(p.duration)
+		stop()
+		clock.Advance(5 * time.Millisecond) // untimed work between phases
+	}
+
+	timings := timer.Timings()
+	for _, key := range []string{"pricing_ms", "ai_ms", "pdf_ms", "s3_ms", "total_ms"} {
+		if _, ok := timings[key]; !ok {
+			t.Errorf("Missing timing key %q", key)
+		}
+	}
+
+	if timings["ai_ms"] != 900 {
+		t.Errorf("Expected ai_ms=900, got %d", timings["ai_ms"])
+	}
+
+	var sum int64
+	for _, p := range phases {
+		sum += timings[p.name+"_ms"]
+	}
+	total := timings["total_ms"]
+	if sum > total || total-sum > 20 {
+		t.Errorf("Expected phases (%dms) to roughly sum to total (%dms)", sum, total)
+	}
+}
+
+func TestPhaseTimer_AccumulatesRepeatedPhase(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	timer := newPhaseTimerWithClock(clock.Now)
+
+	for i := 0; i < 3; i++ {
+		stop := timer.Start("s3")
+		clock.Advance(10 * time.Millisecond)
+		stop()
+	}
+
+	if got := timer.Timings()["s3_ms"]; got != 30 {
+		t.Errorf("Expected s3_ms=30, got %d", got)
+	}
+
+	args := timer.LogArgs()
+	if len(args) != 4 || args[0] != "s3_ms" || args[2] != "total_ms" {
+		t.Errorf("Unexpected log args: %v", args)
+	}
+}
+
+func TestPhaseTimer_RealClock(t *testing.T) {
+	timer := NewPhaseTimer()
+	stop := timer.Start("work")
+	time.Sleep(2 * time.Millisecond)
+	stop()
+
+	timings := timer.Timings()
+	if timings["work_ms"] > timings["total_ms"] {
+		t.Errorf("Phase %dms should not exceed total %dms", timings["work_ms"], timings["total_ms"])
+	}
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Price sources that are not a cost data provider
+const (
+	PriceSourceDefault         = "default"
+	PriceSourceCompanyOverride = "company_override"
+	PriceSourceProjectOverride = "project_override"
+)
+
+// ResolvedPrice is a resolved unit price together with where it came from
+// and the parts it was calculated from
+type ResolvedPrice struct {
+	Value  float64            `json:"value"`
+	Source models.PriceSource `json:"source"`
+	// BasePrice is the database or default price before the regional factor
+	// and any override
+	BasePrice float64 `json:"base_price"`
+	// IsDefault is set when the database has no price for the key
+	IsDefault bool `json:"is_default"`
+	// Override is the last override applied, if any; a project override is
+	// carried in the company override's shape
+	Override *models.CompanyPricingOverride `json:"-"`
+	// OutrankedSources are the other providers with a price for the key,
+	// passed over for Source under the provider precedence
+	OutrankedSources []string `json:"outranked_sources,omitempty"`
+}
+
+// ResolvedPricingConfig is a pricing config plus the provenance of each
+// material price and labor rate in its flat maps
+type ResolvedPricingConfig struct {
+	Config    *models.PricingConfig
+	Materials map[string]ResolvedPrice
+	Labor     map[string]ResolvedPrice
+	// RegionalFactor is the requested region's adjustment, 1.0 without one
+	RegionalFactor float64
+}
+
+// materialSource returns the provenance of a material price; nil-safe so
+// pricing without provenance can share line item builders
+func (r *ResolvedPricingConfig) materialSource(key string) *models.PriceSource {
+	if r == nil {
+		return nil
+	}
+	if resolved, ok := r.Materials[key]; ok {
+		source := resolved.Source
+		return &source
+	}
+	return nil
+}
+
+// laborSource returns the provenance of a trade's labor rate
+func (r *ResolvedPricingConfig) laborSource(trade string) *models.PriceSource {
+	if r == nil {
+		return nil
+	}
+	if resolved, ok := r.Labor[trade]; ok {
+		source := resolved.Source
+		return &source
+	}
+	return nil
+}
+
+// fixedPriceSource marks unit costs hardcoded in the pricing logic
+func fixedPriceSource() *models.PriceSource {
+	return &models.PriceSource{Source: PriceSourceDefault, RegionalFactor: 1.0}
+}
+
+func defaultResolvedPrice(value, regionalFactor float64) ResolvedPrice {
+	return ResolvedPrice{
+		Value:     value * regionalFactor,
+		Source:    models.PriceSource{Source: PriceSourceDefault, RegionalFactor: regionalFactor},
+		BasePrice: value,
+		IsDefault: true,
+	}
+}
+
+// fillDefaultPrices adds default prices for keys that have not been resolved
+func fillDefaultPrices(resolved map[string]ResolvedPrice, defaults map[string]float64, regionalFactor float64) {
+	for key, value := range defaults {
+		if _, exists := resolved[key]; !exists {
+			resolved[key] = defaultResolvedPrice(value, regionalFactor)
+		}
+	}
+}
+
+// Reasons a company or project override is skipped during price resolution
+const (
+	OverrideSkipUnknownKey  = "unknown item key"
+	OverrideSkipUnknownType = "unknown override type"
+	OverrideSkipNotPercent  = "override must be a percentage"
+	OverrideSkipNotDirect   = "override must not be a percentage"
+	OverrideSkipNonPositive = "override must leave a positive value"
+)
+
+// applyPriceOverride applies an override to one price, recording source as
+// its provenance. Percentage overrides adjust the price resolved so far, which
+// may already be overridden, and keep its regional factor; direct overrides
+// replace the price outright. Overrides for keys with neither a loaded nor a
+// default price are skipped, and the reason is returned.
+func applyPriceOverride(resolved map[string]ResolvedPrice, defaults map[string]float64, regionalFactor float64, override models.CompanyPricingOverride, source string) string {
+	base, exists := resolved[override.ItemKey]
+	if !exists {
+		value, isDefault := defaults[override.ItemKey]
+		if !isDefault {
+			return OverrideSkipUnknownKey
+		}
+		base = defaultResolvedPrice(value, regionalFactor)
+	}
+
+	id := override.ID
+	if override.IsPercentage {
+		resolved[override.ItemKey] = ResolvedPrice{
+			Value: base.Value * (1 + override.OverrideValue/100),
+			Source: models.PriceSource{
+				Source:         source,
+				OverrideID:     &id,
+				RegionalFactor: base.Source.RegionalFactor,
+			},
+			BasePrice:        base.BasePrice,
+			IsDefault:        base.IsDefault,
+			Override:         &override,
+			OutrankedSources: base.OutrankedSources,
+		}
+		return ""
+	}
+	resolved[override.ItemKey] = ResolvedPrice{
+		Value: override.OverrideValue,
+		Source: models.PriceSource{
+			Source:         source,
+			OverrideID:     &id,
+			RegionalFactor: 1.0,
+		},
+		BasePrice:        base.BasePrice,
+		IsDefault:        base.IsDefault,
+		Override:         &override,
+		OutrankedSources: base.OutrankedSources,
+	}
+	return ""
+}
+
+// pricingInputs is everything loaded from the database for price resolution.
+// When materials or labor rates could not be loaded, defaults are used
+// without regional adjustment. Categories priced by several providers take
+// the price of the first in providerPrecedence, DefaultProviderPrecedence
+// when nil.
+type pricingInputs struct {
+	materials          []models.MaterialCost
+	materialsLoaded    bool
+	providerPrecedence []string
+	laborRates         []models.LaborRate
+	laborLoaded        bool
+	overrides          []models.CompanyPricingOverride
+	projectOverrides   []models.ProjectPricingOverride
+	regionalFactor     float64
+}
+
+// resolvePricing builds a pricing config with provenance: database prices
+// scaled by the regional factor, then company overrides, then project
+// overrides, then defaults for anything still missing.
+func resolvePricing(defaults *models.PricingConfig, in pricingInputs) *ResolvedPricingConfig {
+	resolved := &ResolvedPricingConfig{
+		Config: &models.PricingConfig{
+			MaterialPrices:    make(map[string]float64),
+			LaborRates:        make(map[string]float64),
+			OverheadRate:      defaults.OverheadRate,
+			ProfitMargin:      defaults.ProfitMargin,
+			FinishLaborSplits: defaults.FinishLaborSplits,
+			RoomTypeFinishes:  defaults.RoomTypeFinishes,
+			TradeMinimums:     make(map[string]float64),
+			ProductivityRates: make(map[string]float64),
+		},
+		Materials:      make(map[string]ResolvedPrice),
+		Labor:          make(map[string]ResolvedPrice),
+		RegionalFactor: in.regionalFactor,
+	}
+
+	for trade, minimum := range defaults.TradeMinimums {
+		resolved.Config.TradeMinimums[trade] = minimum
+	}
+	for key, rate := range defaults.ProductivityRates {
+		resolved.Config.ProductivityRates[key] = rate
+	}
+	if defaults.ProjectTypeModifiers != nil {
+		resolved.Config.ProjectTypeModifiers = make(map[models.ProjectType]models.ProjectTypeModifier)
+		for projectType, modifier := range defaults.ProjectTypeModifiers {
+			resolved.Config.ProjectTypeModifiers[projectType] = modifier
+		}
+	}
+
+	if !in.materialsLoaded {
+		// No database prices: defaults without regional adjustment
+		fillDefaultPrices(resolved.Materials, defaults.MaterialPrices, 1.0)
+	}
+	materials, outranked := preferredMaterials(in.materials, in.providerPrecedence)
+	for _, m := range materials {
+		lastUpdated := m.LastUpdated
+		resolved.Materials[m.Category] = ResolvedPrice{
+			Value: m.BasePrice * in.regionalFactor,
+			Source: models.PriceSource{
+				Source:         m.Source,
+				LastUpdated:    &lastUpdated,
+				RegionalFactor: in.regionalFactor,
+			},
+			BasePrice:        m.BasePrice,
+			OutrankedSources: outrankedSources(m.Source, outranked[m.Category]),
+		}
+	}
+
+	if !in.laborLoaded {
+		fillDefaultPrices(resolved.Labor, defaults.LaborRates, 1.0)
+	}
+	for _, lr := range in.laborRates {
+		lastUpdated := lr.LastUpdated
+		resolved.Labor[laborRateKey(lr.Trade)] = ResolvedPrice{
+			Value: lr.HourlyRate * in.regionalFactor,
+			Source: models.PriceSource{
+				Source:         lr.Source,
+				LastUpdated:    &lastUpdated,
+				RegionalFactor: in.regionalFactor,
+			},
+			BasePrice: lr.HourlyRate,
+		}
+	}
+
+	applyOverrides(resolved, defaults, in.regionalFactor, in.overrides, PriceSourceCompanyOverride)
+	projectOverrides := make([]models.CompanyPricingOverride, len(in.projectOverrides))
+	for i, override := range in.projectOverrides {
+		projectOverrides[i] = companyOverrideShape(override)
+	}
+	applyOverrides(resolved, defaults, in.regionalFactor, projectOverrides, PriceSourceProjectOverride)
+
+	// Ensure we have all required prices
+	fillDefaultPrices(resolved.Materials, defaults.MaterialPrices, in.regionalFactor)
+	fillDefaultPrices(resolved.Labor, defaults.LaborRates, in.regionalFactor)
+
+	for key, price := range resolved.Materials {
+		resolved.Config.MaterialPrices[key] = price.Value
+	}
+	for trade, rate := range resolved.Labor {
+		resolved.Config.LaborRates[trade] = rate.Value
+	}
+
+	return resolved
+}
+
+// companyOverrideShape carries a project override through the resolution
+// shared with company overrides
+func companyOverrideShape(override models.ProjectPricingOverride) models.CompanyPricingOverride {
+	return models.CompanyPricingOverride{
+		ID:            override.ID,
+		OverrideType:  override.OverrideType,
+		ItemKey:       override.ItemKey,
+		OverrideValue: override.OverrideValue,
+		IsPercentage:  override.IsPercentage,
+		Notes:         override.Notes,
+		CreatedAt:     override.CreatedAt,
+		UpdatedAt:     override.UpdatedAt,
+	}
+}
+
+// applyOverrides applies overrides in order over the prices and settings
+// resolved so far, recording each as applied or skipped. Prices they set take
+// source as their provenance.
+func applyOverrides(resolved *ResolvedPricingConfig, defaults *models.PricingConfig, regionalFactor float64, overrides []models.CompanyPricingOverride, source string) {
+	for _, override := range overrides {
+		skipped := ""
+		switch override.OverrideType {
+		case "material":
+			skipped = applyPriceOverride(resolved.Materials, defaults.MaterialPrices, regionalFactor, override, source)
+		case "labor":
+			laborOverride := override
+			laborOverride.ItemKey = laborRateKey(override.ItemKey)
+			skipped = applyPriceOverride(resolved.Labor, defaults.LaborRates, regionalFactor, laborOverride, source)
+		case "trade_minimum":
+			// A minimum charge is a dollar amount, so only direct overrides apply
+			if override.IsPercentage {
+				skipped = OverrideSkipNotDirect
+			} else {
+				resolved.Config.TradeMinimums[laborRateKey(override.ItemKey)] = override.OverrideValue
+			}
+		case "productivity":
+			skipped = applyProductivityOverride(resolved.Config.ProductivityRates, override)
+		case "project_type_modifier":
+			if resolved.Config.ProjectTypeModifiers == nil {
+				resolved.Config.ProjectTypeModifiers = make(map[models.ProjectType]models.ProjectTypeModifier)
+			}
+			skipped = applyProjectTypeModifierOverride(resolved.Config.ProjectTypeModifiers, override)
+		case "overhead":
+			if override.IsPercentage {
+				resolved.Config.OverheadRate = override.OverrideValue
+			} else {
+				skipped = OverrideSkipNotPercent
+			}
+		case "profit_margin":
+			if override.IsPercentage {
+				resolved.Config.ProfitMargin = override.OverrideValue
+			} else {
+				skipped = OverrideSkipNotPercent
+			}
+		default:
+			skipped = OverrideSkipUnknownType
+		}
+
+		if skipped != "" {
+			resolved.Config.SkippedOverrides = append(resolved.Config.SkippedOverrides, models.SkippedOverride{
+				OverrideID:   override.ID,
+				OverrideType: override.OverrideType,
+				ItemKey:      override.ItemKey,
+				Reason:       skipped,
+			})
+		} else {
+			resolved.Config.AppliedOverrides = append(resolved.Config.AppliedOverrides, override.ID)
+		}
+	}
+}
+
+// outrankedSources names the providers of rows passed over for a winning
+// source's price, once each
+func outrankedSources(winner string, rows []models.MaterialCost) []string {
+	var sources []string
+	for _, row := range rows {
+		if row.Source == winner || slices.Contains(sources, row.Source) {
+			continue
+		}
+		sources = append(sources, row.Source)
+	}
+	return sources
+}
+
+// FormatPriceSource renders a price source for exports, e.g.
+// "lowes (updated 2024-03-01, regional x1.15)"
+func FormatPriceSource(source *models.PriceSource) string {
+	if source == nil {
+		return ""
+	}
+	label := source.Source
+	if source.OverrideID != nil {
+		label += " " + source.OverrideID.String()
+	}
+	details := ""
+	if source.LastUpdated != nil {
+		details = "updated " + source.LastUpdated.Format("2006-01-02")
+	}
+	if source.RegionalFactor != 0 && source.RegionalFactor != 1 {
+		if details != "" {
+			details += ", "
+		}
+		details += fmt.Sprintf("regional x%.2f", source.RegionalFactor)
+	}
+	if details != "" {
+		label += " (" + details + ")"
+	}
+	return label
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestResolvePricing_Provenance(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	synced := models.NewTimestamp(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	percentID := uuid.New()
+	directID := uuid.New()
+
+	resolved := resolvePricing(defaults, pricingInputs{
+		materials: []models.MaterialCost{
+			{Category: "door", BasePrice: 400, Source: "lowes", LastUpdated: synced},
+			{Category: "window", BasePrice: 800, Source: "homedepot", LastUpdated: synced},
+		},
+		materialsLoaded: true,
+		laborRates: []models.LaborRate{
+			{Trade: "electrical", HourlyRate: 100, Source: "rsmeans", LastUpdated: synced},
+		},
+		laborLoaded: true,
+		overrides: []models.CompanyPricingOverride{
+			{ID: percentID, OverrideType: "material", ItemKey: "window", OverrideValue: 10, IsPercentage: true},
+			{ID: directID, OverrideType: "labor", ItemKey: "plumbing", OverrideValue: 90},
+		},
+		regionalFactor: 1.2,
+	})
+
+	t.Run("Provider price", func(t *testing.T) {
+		door := resolved.Materials["door"]
+		if door.Value != 480 || door.Source.Source != "lowes" || door.Source.RegionalFactor != 1.2 {
+			t.Errorf("Unexpected door price: %+v", door)
+		}
+		if door.Source.LastUpdated == nil || !door.Source.LastUpdated.Equal(synced.Time) {
+			t.Errorf("Expected provider last_updated, got %v", door.Source.LastUpdated)
+		}
+		if resolved.Config.MaterialPrices["door"] != 480 {
+			t.Errorf("Flat map out of sync with resolved price: %v", resolved.Config.MaterialPrices["door"])
+		}
+	})
+
+	t.Run("Percentage company override", func(t *testing.T) {
+		window := resolved.Materials["window"]
+		if window.Source.Source != PriceSourceCompanyOverride || window.Source.OverrideID == nil || *window.Source.OverrideID != percentID {
+			t.Errorf("Unexpected window source: %+v", window.Source)
+		}
+		if diff := window.Value - 1056; diff > 0.001 || diff < -0.001 {
+			t.Errorf("Expected 800*1.2*1.1 = 1056, got %v", window.Value)
+		}
+		if window.Source.RegionalFactor != 1.2 {
+			t.Errorf("Percentage override should keep the regional factor, got %v", window.Source.RegionalFactor)
+		}
+	})
+
+	t.Run("Direct company override", func(t *testing.T) {
+		plumbing := resolved.Labor["plumbing"]
+		if plumbing.Value != 90 || plumbing.Source.OverrideID == nil || *plumbing.Source.OverrideID != directID {
+			t.Errorf("Unexpected plumbing rate: %+v", plumbing)
+		}
+		if plumbing.Source.RegionalFactor != 1.0 {
+			t.Errorf("Direct override should not be regionally adjusted, got %v", plumbing.Source.RegionalFactor)
+		}
+	})
+
+	t.Run("Default fill-in", func(t *testing.T) {
+		outlet := resolved.Materials["outlet"]
+		if outlet.Source.Source != PriceSourceDefault || outlet.Value != 150 || outlet.Source.RegionalFactor != 1.2 {
+			t.Errorf("Unexpected outlet price: %+v", outlet)
+		}
+		if outlet.Source.LastUpdated != nil {
+			t.Error("Default prices have no last_updated")
+		}
+	})
+}
+
+func TestResolvePricing_SkippedOverrides(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	appliedID, defaultBackedID, orphanedID, overheadID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	resolved := resolvePricing(defaults, pricingInputs{
+		materials:       []models.MaterialCost{{Category: "door", BasePrice: 400, Source: "lowes"}},
+		materialsLoaded: true,
+		laborLoaded:     true,
+		overrides: []models.CompanyPricingOverride{
+			{ID: appliedID, OverrideType: "material", ItemKey: "door", OverrideValue: 500},
+			{ID: defaultBackedID, OverrideType: "material", ItemKey: "window", OverrideValue: 10, IsPercentage: true},
+			{ID: orphanedID, OverrideType: "material", ItemKey: "quartz_countertop", OverrideValue: 55},
+			{ID: overheadID, OverrideType: "overhead", OverrideValue: 12},
+		},
+		regionalFactor: 1.0,
+	})
+
+	config := resolved.Config
+	if len(config.AppliedOverrides) != 2 || config.AppliedOverrides[0] != appliedID || config.AppliedOverrides[1] != defaultBackedID {
+		t.Errorf("Unexpected applied overrides: %v", config.AppliedOverrides)
+	}
+	if diff := config.MaterialPrices["window"] - 935; diff > 0.001 || diff < -0.001 {
+		t.Errorf("Expected a percentage override of a default price, got %v", config.MaterialPrices["window"])
+	}
+	if _, exists := config.MaterialPrices["quartz_countertop"]; exists {
+		t.Error("Orphaned override should not add a price")
+	}
+
+	want := []models.SkippedOverride{
+		{OverrideID: orphanedID, OverrideType: "material", ItemKey: "quartz_countertop", Reason: OverrideSkipUnknownKey},
+		{OverrideID: overheadID, OverrideType: "overhead", Reason: OverrideSkipNotPercent},
+	}
+	if len(config.SkippedOverrides) != len(want) {
+		t.Fatalf("Unexpected skipped overrides: %+v", config.SkippedOverrides)
+	}
+	for i := range want {
+		if config.SkippedOverrides[i] != want[i] {
+			t.Errorf("SkippedOverrides[%d] = %+v, want %+v", i, config.SkippedOverrides[i], want[i])
+		}
+	}
+}
+
+func TestResolvePricing_DatabaseUnavailable(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+
+	resolved := resolvePricing(defaults, pricingInputs{regionalFactor: 1.5})
+
+	door := resolved.Materials["door"]
+	if door.Source.Source != PriceSourceDefault || door.Value != 450 || door.Source.RegionalFactor != 1.0 {
+		t.Errorf("Expected unadjusted default when prices can't be loaded, got %+v", door)
+	}
+	if defaults.MaterialPrices["door"] != 450 {
+		t.Error("Resolving prices must not modify the default config")
+	}
+}
+
+func TestEnhancedPricingService_LineItemPriceSources(t *testing.T) {
+	service := NewEnhancedPricingService(nil, nil, nil, nil)
+	takeoff := &models.TakeoffSummary{TotalArea: 200, RoomCount: 1}
+	analysis := &models.AnalysisResult{
+		Openings: []models.Opening{{OpeningType: "door", Count: 2}},
+	}
+
+	summary, err := service.GeneratePricingSummary(context.Background(), takeoff, analysis, nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+
+	for _, item := range summary.LineItems {
+		if item.PriceSource == nil {
+			t.Errorf("Line item %q has no price source", item.Description)
+			continue
+		}
+		if item.PriceSource.Source != PriceSourceDefault {
+			t.Errorf("Line item %q: expected default source, got %q", item.Description, item.PriceSource.Source)
+		}
+	}
+
+	// The basic pricing service does not track provenance
+	basic, err := NewPricingService().GeneratePricingSummary(takeoff, analysis, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	for _, item := range basic.LineItems {
+		if item.PriceSource != nil {
+			t.Errorf("Basic pricing should not set a price source on %q", item.Description)
+		}
+	}
+}
+
+func TestFormatPriceSource(t *testing.T) {
+	synced := models.NewTimestamp(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	overrideID := uuid.New()
+
+	tests := []struct {
+		source *models.PriceSource
+		want   string
+	}{
+		{nil, ""},
+		{&models.PriceSource{Source: "default", RegionalFactor: 1}, "default"},
+		{&models.PriceSource{Source: "lowes", LastUpdated: &synced, RegionalFactor: 1.15}, "lowes (updated 2024-03-01, regional x1.15)"},
+		{&models.PriceSource{Source: "company_override", OverrideID: &overrideID, RegionalFactor: 1}, "company_override " + overrideID.String()},
+	}
+	for _, tt := range tests {
+		if got := FormatPriceSource(tt.source); got != tt.want {
+			t.Errorf("FormatPriceSource(%+v) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateBidCSV_PriceSourceColumn(t *testing.T) {
+	bid, response := testBidForPDF()
+	response.LineItems[0].PriceSource = &models.PriceSource{Source: "lowes", RegionalFactor: 1}
+
+	data, err := NewExportService().GenerateBidCSV(bid, response, "Test Project")
+	if err != nil {
+		t.Fatalf("GenerateBidCSV() error = %v", err)
+	}
+	csv := string(data)
+	if !strings.Contains(csv, "Price Source") || !strings.Contains(csv, "200.00,lowes") {
+		t.Errorf("Expected price source column in CSV, got:\n%s", csv)
+	}
+}
+
+func TestResolvePricing_ProjectOverridePrecedence(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	companyDirect, companyPercent := uuid.New(), uuid.New()
+	projectPercent, projectDirect, projectOnly := uuid.New(), uuid.New(), uuid.New()
+
+	resolved := resolvePricing(defaults, pricingInputs{
+		materials: []models.MaterialCost{
+			{Category: "door", BasePrice: 400, Source: "lowes"},
+			{Category: "window", BasePrice: 800, Source: "lowes"},
+		},
+		materialsLoaded: true,
+		laborLoaded:     true,
+		overrides: []models.CompanyPricingOverride{
+			{ID: companyDirect, OverrideType: "labor", ItemKey: "electrical", OverrideValue: 100},
+			{ID: companyPercent, OverrideType: "material", ItemKey: "window", OverrideValue: 10, IsPercentage: true},
+			{OverrideType: "overhead", ItemKey: "overhead", OverrideValue: 12, IsPercentage: true},
+		},
+		projectOverrides: []models.ProjectPricingOverride{
+			{ID: projectPercent, OverrideType: "labor", ItemKey: "electrical", OverrideValue: 20, IsPercentage: true},
+			{ID: projectDirect, OverrideType: "material", ItemKey: "window", OverrideValue: 700},
+			{ID: projectOnly, OverrideType: "material", ItemKey: "door", OverrideValue: -25, IsPercentage: true},
+			{OverrideType: "overhead", ItemKey: "overhead", OverrideValue: 18, IsPercentage: true},
+		},
+		regionalFactor: 1.5,
+	})
+
+	tests := []struct {
+		name       string
+		price      ResolvedPrice
+		want       float64
+		overrideID uuid.UUID
+		regional   float64
+	}{
+		// Scales the company's direct rate, which is not regionally adjusted
+		{"project percentage over company direct", resolved.Labor["electrical"], 120, projectPercent, 1.0},
+		// Replaces the company-adjusted price outright
+		{"project direct over company percentage", resolved.Materials["window"], 700, projectDirect, 1.0},
+		// Scales the regionally adjusted database price
+		{"project percentage over database price", resolved.Materials["door"], 450, projectOnly, 1.5},
+	}
+	for _, tt := range tests {
+		if diff := tt.price.Value - tt.want; diff > 0.001 || diff < -0.001 {
+			t.Errorf("%s: value = %v, want %v", tt.name, tt.price.Value, tt.want)
+		}
+		if tt.price.Source.Source != PriceSourceProjectOverride || tt.price.Source.OverrideID == nil || *tt.price.Source.OverrideID != tt.overrideID {
+			t.Errorf("%s: source = %+v, want the project override", tt.name, tt.price.Source)
+		}
+		if tt.price.Source.RegionalFactor != tt.regional {
+			t.Errorf("%s: regional factor = %v, want %v", tt.name, tt.price.Source.RegionalFactor, tt.regional)
+		}
+	}
+
+	if plumbing := resolved.Labor["plumbing"]; plumbing.Source.Source != PriceSourceDefault || plumbing.Value != 85*1.5 {
+		t.Errorf("plumbing = %+v, want the regionally adjusted default", plumbing)
+	}
+	if resolved.Config.OverheadRate != 18 {
+		t.Errorf("overhead = %v, want the project's 18", resolved.Config.OverheadRate)
+	}
+	if resolved.Config.LaborRates["electrical"] != 120 || resolved.Config.MaterialPrices["window"] != 700 {
+		t.Errorf("flat maps = %v / %v, want the project overrides", resolved.Config.LaborRates["electrical"], resolved.Config.MaterialPrices["window"])
+	}
+	applied := resolved.Config.AppliedOverrides
+	if len(applied) != 7 || applied[0] != companyDirect || applied[3] != projectPercent {
+		t.Errorf("applied overrides = %v, want company overrides before project ones", applied)
+	}
+}
+
+// fakeProjectOverrides serves the overrides of one project
+type fakeProjectOverrides struct {
+	projectID uuid.UUID
+	overrides []models.ProjectPricingOverride
+}
+
+func (f fakeProjectOverrides) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.ProjectPricingOverride, error) {
+	if projectID != f.projectID {
+		return nil, nil
+	}
+	return f.overrides, nil
+}
+
+func TestEnhancedPricingService_ProjectOverrides(t *testing.T) {
+	projectID := uuid.New()
+	service := NewEnhancedPricingService(nil, nil, nil, nil).WithProjectOverrides(fakeProjectOverrides{
+		projectID: projectID,
+		overrides: []models.ProjectPricingOverride{{ID: uuid.New(), OverrideType: "labor", ItemKey: "electrical", OverrideValue: 140}},
+	})
+	ctx := context.Background()
+
+	config, err := service.GetPricingConfig(ctx, nil, &projectID, nil)
+	if err != nil {
+		t.Fatalf("GetPricingConfig() error = %v", err)
+	}
+	if config.LaborRates["electrical"] != 140 {
+		t.Errorf("electrical rate = %v, want the project's 140", config.LaborRates["electrical"])
+	}
+
+	for _, other := range []*uuid.UUID{nil, func() *uuid.UUID { id := uuid.New(); return &id }()} {
+		config, err := service.GetPricingConfig(ctx, nil, other, nil)
+		if err != nil {
+			t.Fatalf("GetPricingConfig() error = %v", err)
+		}
+		if config.LaborRates["electrical"] != 95 {
+			t.Errorf("electrical rate for project %v = %v, want the default 95", other, config.LaborRates["electrical"])
+		}
+	}
+}
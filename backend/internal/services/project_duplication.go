@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// DuplicationProjectStore reads and creates projects
+type DuplicationProjectStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error)
+	Create(ctx context.Context, project *models.Project) error
+}
+
+// DuplicationBlueprintStore reads and copies blueprints
+type DuplicationBlueprintStore interface {
+	GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]*models.Blueprint, error)
+	Create(ctx context.Context, blueprint *models.Blueprint) error
+	CopyOCRText(ctx context.Context, fromID, toID uuid.UUID) error
+	GetStorageUsedByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// ObjectCopier copies objects within storage
+type ObjectCopier interface {
+	CopyObject(ctx context.Context, srcKey, dstKey string) error
+}
+
+// StorageQuotaError reports that copying would take a user over their
+// storage quota
+type StorageQuotaError struct {
+	UsedBytes     int64
+	RequiredBytes int64
+	QuotaBytes    int64
+}
+
+func (e *StorageQuotaError) Error() string {
+	return fmt.Sprintf("storage quota exceeded: %d bytes used, %d more needed, quota is %d bytes",
+		e.UsedBytes, e.RequiredBytes, e.QuotaBytes)
+}
+
+// DuplicateProjectOptions are the fields a duplicate changes. A nil region
+// or client keeps the source project's.
+type DuplicateProjectOptions struct {
+	Name       string
+	Region     *string
+	ClientName *string
+}
+
+// ProjectDuplicator copies a project for repeat work: the project row and the
+// latest version of each uploaded blueprint, with its file, analysis and room
+// finishes. Takeoffs are derived from the analysis, so they carry over with
+// it. Bids, jobs and blueprint revisions are not copied. Pricing overrides
+// belong to the user rather than the project, so the duplicate prices the
+// same way without copying them.
+type ProjectDuplicator struct {
+	projects   DuplicationProjectStore
+	blueprints DuplicationBlueprintStore
+	objects    ObjectCopier
+	quotaBytes int64
+}
+
+// NewProjectDuplicator creates a duplicator. quotaBytes caps a user's total
+// blueprint storage; zero means unlimited.
+func NewProjectDuplicator(projects DuplicationProjectStore, blueprints DuplicationBlueprintStore, objects ObjectCopier, quotaBytes int64) *ProjectDuplicator {
+	return &ProjectDuplicator{projects: projects, blueprints: blueprints, objects: objects, quotaBytes: quotaBytes}
+}
+
+// SourceBlueprints returns the blueprints a duplicate of the project copies:
+// the latest version of each blueprint whose upload completed
+func (d *ProjectDuplicator) SourceBlueprints(ctx context.Context, projectID uuid.UUID) ([]*models.Blueprint, error) {
+	blueprints, err := d.blueprints.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []*models.Blueprint
+	for _, blueprint := range blueprints {
+		if blueprint.IsLatest && blueprint.UploadStatus == models.UploadStatusUploaded {
+			sources = append(sources, blueprint)
+		}
+	}
+	return sources, nil
+}
+
+// CheckQuota returns a StorageQuotaError when copying the blueprints would
+// take the user's storage over the quota
+func (d *ProjectDuplicator) CheckQuota(ctx context.Context, userID uuid.UUID, blueprints []*models.Blueprint) error {
+	if d.quotaBytes <= 0 {
+		return nil
+	}
+
+	var required int64
+	for _, blueprint := range blueprints {
+		if blueprint.FileSize != nil {
+			required += *blueprint.FileSize
+		}
+	}
+
+	used, err := d.blueprints.GetStorageUsedByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if used+required > d.quotaBytes {
+		return &StorageQuotaError{UsedBytes: used, RequiredBytes: required, QuotaBytes: d.quotaBytes}
+	}
+	return nil
+}
+
+// CreateProject creates the duplicate's project row, owned by the source
+// project's owner
+func (d *ProjectDuplicator) CreateProject(ctx context.Context, source *models.Project, opts DuplicateProjectOptions) (*models.Project, error) {
+	now := models.Now()
+	project := &models.Project{
+		ID:          uuid.New(),
+		UserID:      source.UserID,
+		Name:        opts.Name,
+		Description: source.Description,
+		Status:      models.ProjectStatusDraft,
+		ProjectType: source.ProjectType,
+		Budget:      source.Budget,
+		Region:      source.Region,
+		ClientName:  source.ClientName,
+		TaxExempt:   source.TaxExempt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if opts.Region != nil {
+		project.Region = opts.Region
+	}
+	if opts.ClientName != nil {
+		project.ClientName = opts.ClientName
+	}
+
+	if err := d.projects.Create(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// CopyBlueprints copies each blueprint's file, row and OCR text into the
+// project. The
+// copies start a new version history. progress, when set, is called after
+// each copy. The first failure stops the copy; blueprints copied before it
+// are kept.
+func (d *ProjectDuplicator) CopyBlueprints(ctx context.Context, project *models.Project, blueprints []*models.Blueprint, progress func(copied, total int)) ([]*models.Blueprint, error) {
+	copies := make([]*models.Blueprint, 0, len(blueprints))
+	for _, source := range blueprints {
+		id := uuid.New()
+		key := fmt.Sprintf("projects/%s/blueprints/%s/%s", project.ID, id, source.Filename)
+		if err := d.objects.CopyObject(ctx, source.S3Key, key); err != nil {
+			return copies, fmt.Errorf("failed to copy %s: %w", source.Filename, err)
+		}
+
+		analysisStatus := models.AnalysisStatusNotStarted
+		if source.AnalysisData != nil {
+			analysisStatus = models.AnalysisStatusCompleted
+		}
+		now := models.Now()
+		blueprint := &models.Blueprint{
+			ID:                 id,
+			ProjectID:          project.ID,
+			Filename:           source.Filename,
+			S3Key:              key,
+			FileSize:           source.FileSize,
+			MimeType:           source.MimeType,
+			UploadStatus:       models.UploadStatusUploaded,
+			AnalysisStatus:     analysisStatus,
+			AnalysisData:       source.AnalysisData,
+			Version:            1,
+			IsLatest:           true,
+			AnalysisModel:      source.AnalysisModel,
+			SheetType:          source.SheetType,
+			RoomFinishes:       source.RoomFinishes,
+			TakeoffAdjustments: source.TakeoffAdjustments,
+			ScanResult:         source.ScanResult,
+			UploadGeneration:   1,
+			PageCount:          source.PageCount,
+			PageSizes:          source.PageSizes,
+			DocumentTitle:      source.DocumentTitle,
+			DocumentCreatedAt:  source.DocumentCreatedAt,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		}
+		if err := d.blueprints.Create(ctx, blueprint); err != nil {
+			return copies, fmt.Errorf("failed to create copy of %s: %w", source.Filename, err)
+		}
+		// The OCR text is copied rather than re-extracted, so a copy is only
+		// complete once its text is searchable
+		if err := d.blueprints.CopyOCRText(ctx, source.ID, blueprint.ID); err != nil {
+			return copies, fmt.Errorf("failed to copy OCR text of %s: %w", source.Filename, err)
+		}
+
+		copies = append(copies, blueprint)
+		if progress != nil {
+			progress(len(copies), len(blueprints))
+		}
+	}
+	return copies, nil
+}
+
+// CopyProject copies the source project's blueprints into a duplicate created
+// earlier by CreateProject, checking the quota again first. It is how a
+// duplication job does the copying queued by a request.
+func (d *ProjectDuplicator) CopyProject(ctx context.Context, sourceProjectID, targetProjectID uuid.UUID, progress func(copied, total int)) ([]*models.Blueprint, error) {
+	target, err := d.projects.GetByID(ctx, targetProjectID)
+	if err != nil {
+		return nil, err
+	}
+	blueprints, err := d.SourceBlueprints(ctx, sourceProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.CheckQuota(ctx, target.UserID, blueprints); err != nil {
+		return nil, err
+	}
+	return d.CopyBlueprints(ctx, target, blueprints, progress)
+}
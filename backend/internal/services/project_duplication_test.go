@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type fakeDuplicationProjects struct {
+	projects map[uuid.UUID]*models.Project
+}
+
+func (f *fakeDuplicationProjects) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	if project, ok := f.projects[id]; ok {
+		return project, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeDuplicationProjects) Create(ctx context.Context, project *models.Project) error {
+	f.projects[project.ID] = project
+	return nil
+}
+
+// fakeDuplicationBlueprints counts every stored blueprint toward the one
+// user's storage
+type fakeDuplicationBlueprints struct {
+	blueprints []*models.Blueprint
+	ocrCopies  map[uuid.UUID]uuid.UUID
+	failOCR    bool
+}
+
+func (f *fakeDuplicationBlueprints) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]*models.Blueprint, error) {
+	var blueprints []*models.Blueprint
+	for _, blueprint := range f.blueprints {
+		if blueprint.ProjectID == projectID {
+			blueprints = append(blueprints, blueprint)
+		}
+	}
+	return blueprints, nil
+}
+
+func (f *fakeDuplicationBlueprints) Create(ctx context.Context, blueprint *models.Blueprint) error {
+	f.blueprints = append(f.blueprints, blueprint)
+	return nil
+}
+
+func (f *fakeDuplicationBlueprints) CopyOCRText(ctx context.Context, fromID, toID uuid.UUID) error {
+	if f.failOCR {
+		return errors.New("db down")
+	}
+	f.ocrCopies[toID] = fromID
+	return nil
+}
+
+func (f *fakeDuplicationBlueprints) GetStorageUsedByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var used int64
+	for _, blueprint := range f.blueprints {
+		if blueprint.FileSize != nil {
+			used += *blueprint.FileSize
+		}
+	}
+	return used, nil
+}
+
+// fakeObjectCopier records copies as destination key -> source key
+type fakeObjectCopier struct {
+	copies map[string]string
+}
+
+func (f *fakeObjectCopier) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	f.copies[dstKey] = srcKey
+	return nil
+}
+
+type duplicationFixture struct {
+	duplicator *ProjectDuplicator
+	projects   *fakeDuplicationProjects
+	blueprints *fakeDuplicationBlueprints
+	objects    *fakeObjectCopier
+	source     *models.Project
+	latest     *models.Blueprint
+}
+
+// newDuplicationFixture builds a source project with an analyzed blueprint
+// (1000 bytes), its superseded first version and a blueprint whose upload
+// never completed
+func newDuplicationFixture(quotaBytes int64) *duplicationFixture {
+	region := "Austin, TX"
+	source := &models.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Prototype store", Status: models.ProjectStatusActive, Region: &region}
+	size := int64(1000)
+	analysis := `{"rooms":[{"name":"Sales floor","area":2400}]}`
+	parentID := uuid.New()
+	latest := &models.Blueprint{
+		ID: uuid.New(), ProjectID: source.ID, Filename: "floor.pdf", S3Key: "projects/src/blueprints/b2/floor.pdf",
+		FileSize: &size, UploadStatus: models.UploadStatusUploaded, AnalysisStatus: models.AnalysisStatusCompleted,
+		AnalysisData: &analysis, Version: 2, ParentBlueprintID: &parentID, IsLatest: true, UploadGeneration: 3,
+		RoomFinishes: map[string]models.FloorFinish{"Sales floor": models.FloorFinishTile},
+	}
+	superseded := &models.Blueprint{
+		ID: parentID, ProjectID: source.ID, Filename: "floor.pdf", S3Key: "projects/src/blueprints/b1/floor.pdf",
+		UploadStatus: models.UploadStatusUploaded, Version: 1, IsLatest: false,
+	}
+	pending := &models.Blueprint{
+		ID: uuid.New(), ProjectID: source.ID, Filename: "site.pdf", S3Key: "projects/src/blueprints/b3/site.pdf",
+		UploadStatus: models.UploadStatusPending, IsLatest: true,
+	}
+
+	fixture := &duplicationFixture{
+		projects:   &fakeDuplicationProjects{projects: map[uuid.UUID]*models.Project{source.ID: source}},
+		blueprints: &fakeDuplicationBlueprints{blueprints: []*models.Blueprint{latest, superseded, pending}, ocrCopies: map[uuid.UUID]uuid.UUID{}},
+		objects:    &fakeObjectCopier{copies: map[string]string{}},
+		source:     source,
+		latest:     latest,
+	}
+	fixture.duplicator = NewProjectDuplicator(fixture.projects, fixture.blueprints, fixture.objects, quotaBytes)
+	return fixture
+}
+
+func TestProjectDuplicator_CopiesLatestUploadedBlueprints(t *testing.T) {
+	f := newDuplicationFixture(0)
+	ctx := context.Background()
+
+	sources, err := f.duplicator.SourceBlueprints(ctx, f.source.ID)
+	if err != nil {
+		t.Fatalf("SourceBlueprints failed: %v", err)
+	}
+	if len(sources) != 1 || sources[0].ID != f.latest.ID {
+		t.Fatalf("expected only the latest uploaded blueprint, got %d", len(sources))
+	}
+
+	client := "Franchisee LLC"
+	project, err := f.duplicator.CreateProject(ctx, f.source, DuplicateProjectOptions{Name: "Store #42", ClientName: &client})
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	if project.Name != "Store #42" || project.UserID != f.source.UserID || project.Status != models.ProjectStatusDraft {
+		t.Errorf("project = %+v, want a draft named Store #42 owned by the source owner", project)
+	}
+	if project.Region == nil || *project.Region != "Austin, TX" || project.ClientName == nil || *project.ClientName != client {
+		t.Errorf("expected the source region kept and the new client set, got %v / %v", project.Region, project.ClientName)
+	}
+
+	var reports int
+	copies, err := f.duplicator.CopyBlueprints(ctx, project, sources, func(copied, total int) { reports++ })
+	if err != nil {
+		t.Fatalf("CopyBlueprints failed: %v", err)
+	}
+	if len(copies) != 1 || reports != 1 {
+		t.Fatalf("expected one copy and one progress report, got %d and %d", len(copies), reports)
+	}
+
+	copied := copies[0]
+	if copied.ID == f.latest.ID || copied.ProjectID != project.ID {
+		t.Errorf("copy should be a new blueprint in the new project, got %+v", copied)
+	}
+	if f.objects.copies[copied.S3Key] != f.latest.S3Key {
+		t.Errorf("object at %s was not copied from %s: %v", copied.S3Key, f.latest.S3Key, f.objects.copies)
+	}
+	if copied.S3Key == f.latest.S3Key {
+		t.Error("copy must not share the source object key")
+	}
+	if copied.AnalysisData == nil || *copied.AnalysisData != *f.latest.AnalysisData || copied.AnalysisStatus != models.AnalysisStatusCompleted {
+		t.Errorf("analysis was not copied: status %s", copied.AnalysisStatus)
+	}
+	if copied.RoomFinishes["Sales floor"] != models.FloorFinishTile {
+		t.Errorf("room finishes were not copied: %v", copied.RoomFinishes)
+	}
+	// Revisions are not copied: the copy starts its own version history
+	if copied.Version != 1 || copied.ParentBlueprintID != nil || !copied.IsLatest {
+		t.Errorf("copy = version %d, parent %v; want a first version", copied.Version, copied.ParentBlueprintID)
+	}
+	if f.blueprints.ocrCopies[copied.ID] != f.latest.ID {
+		t.Error("OCR text was not copied")
+	}
+}
+
+func TestProjectDuplicator_FailsWhenOCRTextIsNotCopied(t *testing.T) {
+	f := newDuplicationFixture(0)
+	ctx := context.Background()
+	f.blueprints.failOCR = true
+
+	sources, _ := f.duplicator.SourceBlueprints(ctx, f.source.ID)
+	project, err := f.duplicator.CreateProject(ctx, f.source, DuplicateProjectOptions{Name: "Store #42"})
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	if _, err := f.duplicator.CopyBlueprints(ctx, project, sources, nil); err == nil {
+		t.Error("CopyBlueprints succeeded, want an error for a copy without its OCR text")
+	}
+}
+
+func TestProjectDuplicator_CheckQuota(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		quota   int64
+		wantErr bool
+	}{
+		{"unlimited", 0, false},
+		{"room for the copy", 2000, false},
+		{"copy would exceed quota", 1999, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDuplicationFixture(tt.quota)
+			sources, _ := f.duplicator.SourceBlueprints(ctx, f.source.ID)
+
+			err := f.duplicator.CheckQuota(ctx, f.source.UserID, sources)
+			var quotaErr *StorageQuotaError
+			if tt.wantErr != errors.As(err, &quotaErr) {
+				t.Fatalf("CheckQuota() = %v, want quota error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (quotaErr.UsedBytes != 1000 || quotaErr.RequiredBytes != 1000) {
+				t.Errorf("quota error = %+v, want 1000 used and 1000 required", quotaErr)
+			}
+		})
+	}
+}
+
+func TestWorker_ProcessesDuplicationJob(t *testing.T) {
+	f := newDuplicationFixture(0)
+	ctx := context.Background()
+	target, err := f.duplicator.CreateProject(ctx, f.source, DuplicateProjectOptions{Name: "Store #42"})
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+
+	job := &models.Job{
+		ID: uuid.New(), BlueprintID: f.latest.ID, JobType: models.JobTypeProjectDuplicate,
+		Status: models.JobStatusQueued, TargetProjectID: &target.ID,
+	}
+	jobs := &fakeWorkerJobs{jobs: []*models.Job{job}}
+	blueprints := &fakeWorkerBlueprints{blueprints: map[uuid.UUID]models.Blueprint{f.latest.ID: *f.latest}}
+	worker := NewWorker(jobs, blueprints, &interleavingAI{}, &config.Config{}).WithProjectDuplication(f.duplicator)
+
+	if err := worker.processJob(ctx, job); err != nil {
+		t.Fatalf("processJob() error = %v", err)
+	}
+	if job.Status != models.JobStatusCompleted || job.Progress != ProgressComplete || job.ResultData == nil {
+		t.Errorf("job = %s at %d%%, want completed with a result", job.Status, job.Progress)
+	}
+	copies, _ := f.blueprints.GetByProjectID(ctx, target.ID)
+	if len(copies) != 1 {
+		t.Errorf("expected one blueprint copied into the target, got %d", len(copies))
+	}
+}
+
+func TestWorker_DuplicationJobEnforcesQuota(t *testing.T) {
+	f := newDuplicationFixture(1500)
+	ctx := context.Background()
+	target, _ := f.duplicator.CreateProject(ctx, f.source, DuplicateProjectOptions{Name: "Store #42"})
+
+	job := &models.Job{
+		ID: uuid.New(), BlueprintID: f.latest.ID, JobType: models.JobTypeProjectDuplicate,
+		Status: models.JobStatusQueued, TargetProjectID: &target.ID,
+	}
+	jobs := &fakeWorkerJobs{jobs: []*models.Job{job}}
+	blueprints := &fakeWorkerBlueprints{blueprints: map[uuid.UUID]models.Blueprint{f.latest.ID: *f.latest}}
+	worker := NewWorker(jobs, blueprints, &interleavingAI{}, &config.Config{}).WithProjectDuplication(f.duplicator)
+
+	if err := worker.processJob(ctx, job); err == nil {
+		t.Fatal("expected the job to fail over quota")
+	}
+	if job.Status != models.JobStatusFailed || len(f.objects.copies) != 0 {
+		t.Errorf("job = %s with %d objects copied, want failed before copying", job.Status, len(f.objects.copies))
+	}
+}
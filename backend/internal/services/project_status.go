@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// projectStatusTransitions lists the statuses each status can move to.
+// Archiving is how projects are deleted; an archived project can be restored
+// to active.
+var projectStatusTransitions = map[models.ProjectStatus][]models.ProjectStatus{
+	models.ProjectStatusDraft:     {models.ProjectStatusActive, models.ProjectStatusArchived},
+	models.ProjectStatusActive:    {models.ProjectStatusCompleted, models.ProjectStatusArchived},
+	models.ProjectStatusCompleted: {models.ProjectStatusActive, models.ProjectStatusArchived},
+	models.ProjectStatusArchived:  {models.ProjectStatusActive},
+}
+
+// ParseProjectStatus validates a project status, treating empty as draft
+func ParseProjectStatus(value string) (models.ProjectStatus, error) {
+	status := models.ProjectStatus(strings.TrimSpace(value))
+	if status == "" {
+		return models.ProjectStatusDraft, nil
+	}
+	if _, ok := projectStatusTransitions[status]; !ok {
+		return "", fmt.Errorf("status must be one of draft, active, completed or archived")
+	}
+	return status, nil
+}
+
+// CheckProjectStatusTransition reports whether a project can move from one
+// status to another. Staying in the same status is always allowed.
+func CheckProjectStatusTransition(from, to models.ProjectStatus) error {
+	if from == to {
+		return nil
+	}
+	for _, next := range projectStatusTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("a %s project cannot be moved to %s", from, to)
+}
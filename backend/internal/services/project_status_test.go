@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestParseProjectStatus(t *testing.T) {
+	if status, err := ParseProjectStatus(""); err != nil || status != models.ProjectStatusDraft {
+		t.Errorf("ParseProjectStatus(\"\") = %q, %v; want draft", status, err)
+	}
+	if status, err := ParseProjectStatus(" completed "); err != nil || status != models.ProjectStatusCompleted {
+		t.Errorf("ParseProjectStatus(\" completed \") = %q, %v; want completed", status, err)
+	}
+	if _, err := ParseProjectStatus("deleted"); err == nil {
+		t.Error("expected an unknown status to be rejected")
+	}
+}
+
+func TestCheckProjectStatusTransition(t *testing.T) {
+	allowed := [][2]models.ProjectStatus{
+		{models.ProjectStatusDraft, models.ProjectStatusActive},
+		{models.ProjectStatusActive, models.ProjectStatusCompleted},
+		{models.ProjectStatusCompleted, models.ProjectStatusActive},
+		{models.ProjectStatusCompleted, models.ProjectStatusArchived},
+		{models.ProjectStatusArchived, models.ProjectStatusActive},
+		{models.ProjectStatusActive, models.ProjectStatusActive},
+	}
+	for _, transition := range allowed {
+		if err := CheckProjectStatusTransition(transition[0], transition[1]); err != nil {
+			t.Errorf("%s -> %s: %v", transition[0], transition[1], err)
+		}
+	}
+
+	rejected := [][2]models.ProjectStatus{
+		{models.ProjectStatusDraft, models.ProjectStatusCompleted},
+		{models.ProjectStatusActive, models.ProjectStatusDraft},
+		{models.ProjectStatusArchived, models.ProjectStatusCompleted},
+	}
+	for _, transition := range rejected {
+		if err := CheckProjectStatusTransition(transition[0], transition[1]); err == nil {
+			t.Errorf("%s -> %s: expected an error", transition[0], transition[1])
+		}
+	}
+}
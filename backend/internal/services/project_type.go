@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Project type modifier fields, named in company override item keys as
+// "<project_type>.<field>", e.g. "renovation.demolition_per_sf"
+const (
+	ModifierDemolitionPerSF = "demolition_per_sf"
+	ModifierLaborMultiplier = "labor_multiplier"
+	ModifierProtectionPerSF = "protection_per_sf"
+)
+
+// DefaultProjectTypeModifiers returns the extra costs of renovations and
+// additions. New construction has none; an addition is priced halfway
+// between new construction and a renovation, since it ties into existing
+// structure over part of its area.
+func DefaultProjectTypeModifiers() map[models.ProjectType]models.ProjectTypeModifier {
+	return map[models.ProjectType]models.ProjectTypeModifier{
+		models.ProjectTypeNewConstruction: {LaborMultiplier: 1.0},
+		models.ProjectTypeRenovation: {
+			DemolitionPerSF: 4.00,
+			LaborMultiplier: 1.20,
+			ProtectionPerSF: 1.50,
+		},
+		models.ProjectTypeAddition: {
+			DemolitionPerSF: 2.00,
+			LaborMultiplier: 1.10,
+			ProtectionPerSF: 0.75,
+		},
+	}
+}
+
+// ParseProjectType validates a project type, treating empty as new construction
+func ParseProjectType(value string) (models.ProjectType, error) {
+	switch projectType := models.ProjectType(strings.TrimSpace(value)); projectType {
+	case "":
+		return models.ProjectTypeNewConstruction, nil
+	case models.ProjectTypeNewConstruction, models.ProjectTypeRenovation, models.ProjectTypeAddition:
+		return projectType, nil
+	default:
+		return "", fmt.Errorf("project_type must be one of new_construction, renovation or addition")
+	}
+}
+
+// ParseProjectTypeModifierKey splits a project_type_modifier override item
+// key into its project type and modifier field
+func ParseProjectTypeModifierKey(key string) (models.ProjectType, string, error) {
+	typeName, field, found := strings.Cut(key, ".")
+	if !found {
+		return "", "", fmt.Errorf("item_key must be <project_type>.<field>, got %q", key)
+	}
+	projectType, err := ParseProjectType(typeName)
+	if err != nil || typeName == "" {
+		return "", "", fmt.Errorf("unknown project type %q", typeName)
+	}
+	switch field {
+	case ModifierDemolitionPerSF, ModifierLaborMultiplier, ModifierProtectionPerSF:
+		return projectType, field, nil
+	default:
+		return "", "", fmt.Errorf("unknown project type modifier %q", field)
+	}
+}
+
+// applyProjectTypeModifierOverride sets one modifier field from a company
+// override, directly or as a percentage of its current value, and returns
+// why the override was skipped, if it was
+func applyProjectTypeModifierOverride(modifiers map[models.ProjectType]models.ProjectTypeModifier, override models.CompanyPricingOverride) string {
+	projectType, field, err := ParseProjectTypeModifierKey(override.ItemKey)
+	if err != nil {
+		return OverrideSkipUnknownKey
+	}
+
+	modifier := modifiers[projectType]
+	var value *float64
+	switch field {
+	case ModifierDemolitionPerSF:
+		value = &modifier.DemolitionPerSF
+	case ModifierLaborMultiplier:
+		value = &modifier.LaborMultiplier
+	case ModifierProtectionPerSF:
+		value = &modifier.ProtectionPerSF
+	}
+	if override.IsPercentage {
+		*value *= 1 + override.OverrideValue/100
+	} else {
+		*value = override.OverrideValue
+	}
+	modifiers[projectType] = modifier
+	return ""
+}
+
+// laborMultiplier is the modifier's labor hour multiplier, 1 when unset
+func laborMultiplier(modifier models.ProjectTypeModifier) float64 {
+	if modifier.LaborMultiplier <= 0 {
+		return 1.0
+	}
+	return modifier.LaborMultiplier
+}
+
+// buildProjectTypeItems prices the demolition and protection line items a
+// project type adds over the affected area. Their per-SF prices include
+// their labor, so they add no labor hours.
+func buildProjectTypeItems(projectType models.ProjectType, modifier models.ProjectTypeModifier, area float64, source *models.PriceSource) []installedWork {
+	if area <= 0 {
+		return nil
+	}
+
+	var items []installedWork
+	label := strings.ReplaceAll(string(projectType), "_", " ")
+
+	if modifier.DemolitionPerSF > 0 {
+		item := models.LineItem{
+			Description: fmt.Sprintf("Selective demolition (%s)", label),
+			Trade:       "demolition",
+			Quantity:    area,
+			Unit:        "sq ft",
+			UnitCost:    modifier.DemolitionPerSF,
+			Total:       math.Round(area*modifier.DemolitionPerSF*100) / 100,
+			PriceSource: source,
+		}
+		splitLineItemCost(&item, 0.8) // The rest is disposal and dumpsters
+		items = append(items, installedWork{item: item})
+	}
+
+	if modifier.ProtectionPerSF > 0 {
+		item := models.LineItem{
+			Description: fmt.Sprintf("Dust protection and general conditions (%s)", label),
+			Trade:       "general",
+			Quantity:    area,
+			Unit:        "sq ft",
+			UnitCost:    modifier.ProtectionPerSF,
+			Total:       math.Round(area*modifier.ProtectionPerSF*100) / 100,
+			PriceSource: source,
+		}
+		splitLineItemCost(&item, 0.5)
+		items = append(items, installedWork{item: item})
+	}
+
+	return items
+}
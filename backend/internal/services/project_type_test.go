@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func priceAsProjectType(t *testing.T, projectType models.ProjectType) *models.PricingSummary {
+	t.Helper()
+	takeoff := &models.TakeoffSummary{TotalArea: 1000, RoomCount: 2}
+	analysis := &models.AnalysisResult{
+		Openings: []models.Opening{{OpeningType: "door", Count: 4, Size: "3x7"}},
+		Fixtures: []models.Fixture{{FixtureType: "outlet", Category: "electrical", Count: 12}},
+	}
+	summary, err := NewEnhancedPricingService(nil, nil, nil, nil).
+		GenerateProjectPricingSummary(context.Background(), takeoff, analysis, nil, nil, nil, projectType)
+	if err != nil {
+		t.Fatalf("GenerateProjectPricingSummary(%s) error = %v", projectType, err)
+	}
+	return summary
+}
+
+func lineItemsByDescription(summary *models.PricingSummary) map[string]models.LineItem {
+	items := make(map[string]models.LineItem)
+	for _, item := range summary.LineItems {
+		items[item.Description] = item
+	}
+	return items
+}
+
+func TestGenerateProjectPricingSummary_ProjectTypes(t *testing.T) {
+	newConstruction := priceAsProjectType(t, models.ProjectTypeNewConstruction)
+	renovation := priceAsProjectType(t, models.ProjectTypeRenovation)
+	addition := priceAsProjectType(t, models.ProjectTypeAddition)
+
+	base := lineItemsByDescription(newConstruction)
+	for description := range base {
+		if strings.Contains(description, "demolition") || strings.Contains(description, "Dust protection") {
+			t.Errorf("new construction priced %q", description)
+		}
+	}
+
+	for _, tc := range []struct {
+		summary         *models.PricingSummary
+		label           string
+		demolition      float64
+		protection      float64
+		laborMultiplier float64
+		wantNote        string
+	}{
+		{renovation, "renovation", 4000, 1500, 1.20, "20% productivity penalty for renovation work"},
+		{addition, "addition", 2000, 750, 1.10, "10% productivity penalty for addition work"},
+	} {
+		items := lineItemsByDescription(tc.summary)
+		demolition := items["Selective demolition ("+tc.label+")"]
+		if demolition.Trade != "demolition" || demolition.Quantity != 1000 || demolition.Total != tc.demolition {
+			t.Errorf("%s demolition = %+v, want 1000 SF totalling %.2f", tc.label, demolition, tc.demolition)
+		}
+		protection := items["Dust protection and general conditions ("+tc.label+")"]
+		if protection.Quantity != 1000 || protection.Total != tc.protection {
+			t.Errorf("%s protection = %+v, want 1000 SF totalling %.2f", tc.label, protection, tc.protection)
+		}
+
+		// The same trades take more labor hours than in new construction
+		for _, trade := range []string{"framing", "painting", "electrical"} {
+			description := "Labor - " + trade
+			baseHours, hours := base[description].Quantity, items[description].Quantity
+			if want := math.Round(baseHours * tc.laborMultiplier); math.Abs(hours-want) > 1 {
+				t.Errorf("%s %s = %.0f hours, want about %.0f (%.0f x %.2f)", tc.label, description, hours, want, baseHours, tc.laborMultiplier)
+			}
+		}
+
+		if tc.summary.LaborCost <= newConstruction.LaborCost || tc.summary.TotalPrice <= newConstruction.TotalPrice {
+			t.Errorf("%s priced at or below new construction: %+v", tc.label, tc.summary)
+		}
+		found := false
+		for _, note := range tc.summary.Notes {
+			found = found || strings.Contains(note, tc.wantNote)
+		}
+		if !found {
+			t.Errorf("%s notes = %v, want %q", tc.label, tc.summary.Notes, tc.wantNote)
+		}
+	}
+
+	if addition.TotalPrice >= renovation.TotalPrice {
+		t.Errorf("addition total %.2f should fall between new construction %.2f and renovation %.2f",
+			addition.TotalPrice, newConstruction.TotalPrice, renovation.TotalPrice)
+	}
+}
+
+func TestResolvePricing_ProjectTypeModifierOverrides(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	demolitionID, multiplierID, unknownID := uuid.New(), uuid.New(), uuid.New()
+
+	resolved := resolvePricing(defaults, pricingInputs{
+		overrides: []models.CompanyPricingOverride{
+			{ID: demolitionID, OverrideType: "project_type_modifier", ItemKey: "renovation.demolition_per_sf", OverrideValue: 6.25},
+			{ID: multiplierID, OverrideType: "project_type_modifier", ItemKey: "addition.labor_multiplier", OverrideValue: 10, IsPercentage: true},
+			{ID: unknownID, OverrideType: "project_type_modifier", ItemKey: "renovation.asbestos_per_sf", OverrideValue: 3},
+		},
+		regionalFactor: 1.0,
+	})
+
+	modifiers := resolved.Config.ProjectTypeModifiers
+	if modifiers[models.ProjectTypeRenovation].DemolitionPerSF != 6.25 {
+		t.Errorf("renovation demolition = %v, want 6.25", modifiers[models.ProjectTypeRenovation].DemolitionPerSF)
+	}
+	if got := modifiers[models.ProjectTypeAddition].LaborMultiplier; math.Abs(got-1.21) > 0.0001 {
+		t.Errorf("addition labor multiplier = %v, want 1.10 raised 10%%", got)
+	}
+	if defaults.ProjectTypeModifiers[models.ProjectTypeRenovation].DemolitionPerSF != 4.00 {
+		t.Error("override changed the default modifiers")
+	}
+	if len(resolved.Config.AppliedOverrides) != 2 || len(resolved.Config.SkippedOverrides) != 1 ||
+		resolved.Config.SkippedOverrides[0].Reason != OverrideSkipUnknownKey {
+		t.Errorf("applied = %v, skipped = %+v", resolved.Config.AppliedOverrides, resolved.Config.SkippedOverrides)
+	}
+}
+
+func TestParseProjectTypeModifierKey(t *testing.T) {
+	projectType, field, err := ParseProjectTypeModifierKey("renovation.protection_per_sf")
+	if err != nil || projectType != models.ProjectTypeRenovation || field != ModifierProtectionPerSF {
+		t.Errorf("got %q %q %v", projectType, field, err)
+	}
+	for _, key := range []string{"renovation", ".labor_multiplier", "remodel.labor_multiplier", "addition.overhead"} {
+		if _, _, err := ParseProjectTypeModifierKey(key); err == nil {
+			t.Errorf("ParseProjectTypeModifierKey(%q) accepted", key)
+		}
+	}
+}
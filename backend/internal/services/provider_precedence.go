@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// DefaultProviderPrecedence is the order material prices are taken from when
+// several providers price the same category, most trusted first
+var DefaultProviderPrecedence = []string{"rsmeans", "lowes", "homedepot"}
+
+// MaterialConflict is a material category priced by more than one provider.
+// Sources are in precedence order, so the first is the price pricing uses.
+type MaterialConflict struct {
+	Category string                   `json:"category"`
+	Winner   string                   `json:"winner"`
+	Sources  []MaterialConflictSource `json:"sources"`
+	MinPrice float64                  `json:"min_price"`
+	MaxPrice float64                  `json:"max_price"`
+	// SpreadPercent is the gap between the highest and lowest price as a
+	// percentage of the lowest
+	SpreadPercent float64 `json:"spread_percent"`
+}
+
+// MaterialConflictSource is one provider's price in a conflict
+type MaterialConflictSource struct {
+	Source    string  `json:"source"`
+	Name      string  `json:"name"`
+	BasePrice float64 `json:"base_price"`
+}
+
+// providerRank is a source's position in precedence; sources not listed rank
+// after every listed one
+func providerRank(precedence []string, source string) int {
+	for i, name := range precedence {
+		if name == source {
+			return i
+		}
+	}
+	return len(precedence)
+}
+
+// sortMaterialsByPrecedence orders materials by category, then provider
+// precedence, then source name, most recently synced and ID, so the same rows
+// always resolve the same way whatever order the database returned them in
+func sortMaterialsByPrecedence(materials []models.MaterialCost, precedence []string) []models.MaterialCost {
+	if precedence == nil {
+		precedence = DefaultProviderPrecedence
+	}
+	sorted := append([]models.MaterialCost(nil), materials...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if rankA, rankB := providerRank(precedence, a.Source), providerRank(precedence, b.Source); rankA != rankB {
+			return rankA < rankB
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if !a.LastUpdated.Equal(b.LastUpdated.Time) {
+			return a.LastUpdated.After(b.LastUpdated.Time)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	return sorted
+}
+
+// preferredMaterials picks each category's price from the highest precedence
+// provider. The rows that lost are returned by category.
+func preferredMaterials(materials []models.MaterialCost, precedence []string) ([]models.MaterialCost, map[string][]models.MaterialCost) {
+	var winners []models.MaterialCost
+	outranked := make(map[string][]models.MaterialCost)
+	for _, m := range sortMaterialsByPrecedence(materials, precedence) {
+		if n := len(winners); n > 0 && winners[n-1].Category == m.Category {
+			outranked[m.Category] = append(outranked[m.Category], m)
+			continue
+		}
+		winners = append(winners, m)
+	}
+	return winners, outranked
+}
+
+// FindMaterialConflicts lists the categories more than one provider prices,
+// ordered by category. Several rows from the same provider are not a
+// conflict; its preferred row stands for it.
+func FindMaterialConflicts(materials []models.MaterialCost, precedence []string) []MaterialConflict {
+	conflicts := []MaterialConflict{}
+	var current *MaterialConflict
+	for _, m := range sortMaterialsByPrecedence(materials, precedence) {
+		if current == nil || current.Category != m.Category {
+			if current != nil && len(current.Sources) > 1 {
+				conflicts = append(conflicts, *current)
+			}
+			current = &MaterialConflict{Category: m.Category, Winner: m.Source, MinPrice: m.BasePrice, MaxPrice: m.BasePrice}
+		} else if current.Sources[len(current.Sources)-1].Source == m.Source {
+			continue
+		}
+		current.Sources = append(current.Sources, MaterialConflictSource{Source: m.Source, Name: m.Name, BasePrice: m.BasePrice})
+		current.MinPrice = min(current.MinPrice, m.BasePrice)
+		current.MaxPrice = max(current.MaxPrice, m.BasePrice)
+	}
+	if current != nil && len(current.Sources) > 1 {
+		conflicts = append(conflicts, *current)
+	}
+
+	for i := range conflicts {
+		if conflicts[i].MinPrice > 0 {
+			spread := (conflicts[i].MaxPrice - conflicts[i].MinPrice) / conflicts[i].MinPrice * 100
+			conflicts[i].SpreadPercent = math.Round(spread*100) / 100
+		}
+	}
+	return conflicts
+}
+
+// MaterialConflicts reports the material categories in region priced by more
+// than one provider, and which provider pricing takes each from
+func (s *EnhancedPricingService) MaterialConflicts(ctx context.Context, region *string) ([]MaterialConflict, error) {
+	materials, err := s.costData.GetMaterials(ctx, nil, region)
+	if errors.Is(err, errCostDataNotConfigured) {
+		return []MaterialConflict{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return FindMaterialConflicts(materials, s.providerPrecedence), nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// conflictingMaterials prices flooring from all three built-in providers and
+// doors from two, with a single-source drywall row
+func conflictingMaterials() []models.MaterialCost {
+	synced := models.NewTimestamp(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	return []models.MaterialCost{
+		{ID: uuid.New(), Name: "Vinyl Flooring - Home Depot", Category: "flooring", BasePrice: 9.25, Source: "homedepot", LastUpdated: synced},
+		{ID: uuid.New(), Name: "Interior Door - Lowes", Category: "door", BasePrice: 475, Source: "lowes", LastUpdated: synced},
+		{ID: uuid.New(), Name: "Flooring - RSMeans", Category: "flooring", BasePrice: 8, Source: "rsmeans", LastUpdated: synced},
+		{ID: uuid.New(), Name: "Drywall 1/2\" - RSMeans", Category: "drywall", BasePrice: 1.65, Source: "rsmeans", LastUpdated: synced},
+		{ID: uuid.New(), Name: "Flooring - Lowes", Category: "flooring", BasePrice: 10, Source: "lowes", LastUpdated: synced},
+		{ID: uuid.New(), Name: "Interior Door - Home Depot", Category: "door", BasePrice: 500, Source: "homedepot", LastUpdated: synced},
+	}
+}
+
+func TestResolvePricing_ProviderPrecedence(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	materials := conflictingMaterials()
+
+	t.Run("default precedence", func(t *testing.T) {
+		resolved := resolvePricing(defaults, pricingInputs{materials: materials, materialsLoaded: true, regionalFactor: 1.0})
+		flooring := resolved.Materials["flooring"]
+		if flooring.Source.Source != "rsmeans" || flooring.Value != 8 {
+			t.Errorf("flooring = %+v, want the rsmeans price", flooring)
+		}
+		if want := []string{"lowes", "homedepot"}; !reflect.DeepEqual(flooring.OutrankedSources, want) {
+			t.Errorf("flooring outranked = %v, want %v", flooring.OutrankedSources, want)
+		}
+		if door := resolved.Materials["door"]; door.Source.Source != "lowes" || door.Value != 475 {
+			t.Errorf("door = %+v, want the lowes price", door)
+		}
+		if drywall := resolved.Materials["drywall"]; drywall.OutrankedSources != nil {
+			t.Errorf("single source drywall outranked %v", drywall.OutrankedSources)
+		}
+	})
+
+	t.Run("configured precedence", func(t *testing.T) {
+		resolved := resolvePricing(defaults, pricingInputs{
+			materials:          materials,
+			materialsLoaded:    true,
+			providerPrecedence: []string{"homedepot", "lowes"},
+			regionalFactor:     1.0,
+		})
+		if flooring := resolved.Materials["flooring"]; flooring.Source.Source != "homedepot" || flooring.Value != 9.25 {
+			t.Errorf("flooring = %+v, want the homedepot price", flooring)
+		}
+		if door := resolved.Materials["door"]; door.Source.Source != "homedepot" {
+			t.Errorf("door = %+v, want the homedepot price", door)
+		}
+	})
+
+	t.Run("independent of row order", func(t *testing.T) {
+		for shift := range materials {
+			rotated := append(append([]models.MaterialCost(nil), materials[shift:]...), materials[:shift]...)
+			resolved := resolvePricing(defaults, pricingInputs{materials: rotated, materialsLoaded: true, regionalFactor: 1.0})
+			if got := resolved.Config.MaterialPrices["flooring"]; got != 8 {
+				t.Fatalf("rotation %d: flooring = %v, want 8", shift, got)
+			}
+			if got := resolved.Config.MaterialPrices["door"]; got != 475 {
+				t.Fatalf("rotation %d: door = %v, want 475", shift, got)
+			}
+		}
+	})
+
+	t.Run("unlisted providers come last", func(t *testing.T) {
+		withUnlisted := append(conflictingMaterials(), models.MaterialCost{ID: uuid.New(), Category: "drywall", BasePrice: 1.2, Source: "onebuild"})
+		resolved := resolvePricing(defaults, pricingInputs{materials: withUnlisted, materialsLoaded: true, regionalFactor: 1.0})
+		if drywall := resolved.Materials["drywall"]; drywall.Source.Source != "rsmeans" {
+			t.Errorf("drywall = %+v, want rsmeans ahead of an unlisted provider", drywall)
+		}
+	})
+}
+
+func TestFindMaterialConflicts(t *testing.T) {
+	materials := append(conflictingMaterials(), models.MaterialCost{ID: uuid.New(), Name: "Drywall 5/8\" - RSMeans", Category: "drywall", BasePrice: 2.1, Source: "rsmeans"})
+
+	conflicts := FindMaterialConflicts(materials, nil)
+	if len(conflicts) != 2 {
+		t.Fatalf("got %d conflicts, want door and flooring: %+v", len(conflicts), conflicts)
+	}
+
+	door := conflicts[0]
+	if door.Category != "door" || door.Winner != "lowes" || door.MinPrice != 475 || door.MaxPrice != 500 || door.SpreadPercent != 5.26 {
+		t.Errorf("door conflict = %+v", door)
+	}
+
+	flooring := conflicts[1]
+	if flooring.Category != "flooring" || flooring.Winner != "rsmeans" {
+		t.Errorf("flooring conflict = %+v", flooring)
+	}
+	var sources []string
+	for _, source := range flooring.Sources {
+		sources = append(sources, source.Source)
+	}
+	if want := []string{"rsmeans", "lowes", "homedepot"}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("flooring sources = %v, want %v in precedence order", sources, want)
+	}
+	if flooring.MinPrice != 8 || flooring.MaxPrice != 10 || flooring.SpreadPercent != 25 {
+		t.Errorf("flooring spread = %v-%v (%v%%), want 8-10 (25%%)", flooring.MinPrice, flooring.MaxPrice, flooring.SpreadPercent)
+	}
+
+	if got := FindMaterialConflicts(materials[:1], nil); len(got) != 0 {
+		t.Errorf("single row reported conflicts: %+v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// workerBatchSize matches the number of jobs the worker claims per poll
+const workerBatchSize = 10
+
+// QueueFullError is returned when enqueueing would exceed a queue ceiling
+type QueueFullError struct {
+	Depth      models.QueueDepth
+	Limit      int
+	Scope      string // "global" or "user"
+	RetryAfter time.Duration
+}
+
+func (e *QueueFullError) Error() string {
+	return fmt.Sprintf("%s job queue is full (%d queued, limit %d)", e.Scope, e.depthForScope(), e.Limit)
+}
+
+func (e *QueueFullError) depthForScope() int {
+	if e.Scope == "user" {
+		return e.Depth.User
+	}
+	return e.Depth.Total
+}
+
+// CheckQueueCapacity reports whether requested more jobs fit under the configured
+// ceilings. It returns nil when there is room.
+func CheckQueueCapacity(depth models.QueueDepth, requested int, cfg *config.WorkerConfig) *QueueFullError {
+	if cfg.MaxQueuedJobs > 0 && depth.Total+requested > cfg.MaxQueuedJobs {
+		return &QueueFullError{Depth: depth, Limit: cfg.MaxQueuedJobs, Scope: "global", RetryAfter: estimateDrainTime(depth.Total+requested-cfg.MaxQueuedJobs, cfg)}
+	}
+	if cfg.MaxQueuedJobsPerUser > 0 && depth.User+requested > cfg.MaxQueuedJobsPerUser {
+		return &QueueFullError{Depth: depth, Limit: cfg.MaxQueuedJobsPerUser, Scope: "user", RetryAfter: estimateDrainTime(depth.User+requested-cfg.MaxQueuedJobsPerUser, cfg)}
+	}
+	return nil
+}
+
+// estimateDrainTime approximates how long the worker needs to work off excess
+// jobs, assuming one batch per poll interval
+func estimateDrainTime(excess int, cfg *config.WorkerConfig) time.Duration {
+	polls := math.Ceil(float64(excess) / workerBatchSize)
+	return time.Duration(math.Max(polls, 1)) * cfg.PollInterval
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestCheckQueueCapacity(t *testing.T) {
+	cfg := &config.WorkerConfig{
+		PollInterval:         5 * time.Second,
+		MaxQueuedJobs:        100,
+		MaxQueuedJobsPerUser: 10,
+	}
+
+	t.Run("room available", func(t *testing.T) {
+		assert.Nil(t, CheckQueueCapacity(models.QueueDepth{Total: 50, User: 5}, 5, cfg))
+	})
+
+	t.Run("user ceiling reached", func(t *testing.T) {
+		err := CheckQueueCapacity(models.QueueDepth{Total: 50, User: 10}, 1, cfg)
+		require.NotNil(t, err)
+		assert.Equal(t, "user", err.Scope)
+		assert.Equal(t, 10, err.Limit)
+		assert.Equal(t, 5*time.Second, err.RetryAfter)
+	})
+
+	t.Run("global ceiling takes precedence", func(t *testing.T) {
+		err := CheckQueueCapacity(models.QueueDepth{Total: 100, User: 10}, 1, cfg)
+		require.NotNil(t, err)
+		assert.Equal(t, "global", err.Scope)
+	})
+
+	t.Run("batch rejected as a whole", func(t *testing.T) {
+		err := CheckQueueCapacity(models.QueueDepth{Total: 75, User: 0}, 30, &config.WorkerConfig{
+			PollInterval:  2 * time.Second,
+			MaxQueuedJobs: 100,
+		})
+		require.NotNil(t, err)
+		// 5 excess jobs drain in one poll
+		assert.Equal(t, 2*time.Second, err.RetryAfter)
+	})
+
+	t.Run("retry-after scales with excess", func(t *testing.T) {
+		err := CheckQueueCapacity(models.QueueDepth{Total: 125}, 1, cfg)
+		require.NotNil(t, err)
+		// 26 excess jobs need three worker batches
+		assert.Equal(t, 15*time.Second, err.RetryAfter)
+	})
+
+	t.Run("zero limits disable checks", func(t *testing.T) {
+		assert.Nil(t, CheckQueueCapacity(models.QueueDepth{Total: 10000, User: 10000}, 100, &config.WorkerConfig{}))
+	})
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/trades"
+)
+
+const (
+	// ExportFormatQuickBooks is a QuickBooks estimate import CSV
+	ExportFormatQuickBooks ExportFormat = "quickbooks"
+
+	// QuickBooksContentType is the content type of QuickBooks exports
+	QuickBooksContentType = "text/csv; charset=utf-8"
+
+	// DefaultQuickBooksItem is the catch-all item for line items whose trade
+	// has no item mapping
+	DefaultQuickBooksItem = "Construction Services"
+
+	// QuickBooksMarkupKey is the item mapping key for the bid's markup line
+	QuickBooksMarkupKey = "markup"
+)
+
+// quickBooksColumns are the estimate import columns, in order
+var quickBooksColumns = []string{
+	"Estimate No", "Customer", "Estimate Date", "Item", "Item Description", "Item Quantity", "Item Rate", "Item Amount",
+}
+
+// QuickBooksWarning reports a line item exported under the catch-all item
+// because its trade has no item mapping
+type QuickBooksWarning struct {
+	Description string `json:"description"`
+	Trade       string `json:"trade"`
+	Item        string `json:"item"`
+}
+
+// DefaultQuickBooksItemNames maps every canonical trade to an item named
+// after it, and the markup line to "Markup"
+func DefaultQuickBooksItemNames() map[string]string {
+	names := map[string]string{QuickBooksMarkupKey: "Markup"}
+	for _, trade := range trades.All() {
+		names[trade.Slug] = trade.DisplayName
+	}
+	return names
+}
+
+// NormalizeQuickBooksItemNames keys a company's item mapping by canonical
+// trade slug, dropping blank item names. It returns an error naming the first
+// key that is neither a known trade nor the markup key.
+func NormalizeQuickBooksItemNames(names map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(names))
+	for _, key := range sortedKeys(names) {
+		item := strings.TrimSpace(names[key])
+		if item == "" {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(key), QuickBooksMarkupKey) {
+			normalized[QuickBooksMarkupKey] = item
+			continue
+		}
+		slug, ok := trades.Normalize(key)
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("unknown trade %q in QuickBooks item mapping", key)
+		}
+		normalized[slug] = item
+	}
+	return normalized, nil
+}
+
+// GenerateBidQuickBooks exports a bid as a QuickBooks estimate import CSV:
+// one row per base line item plus a markup row, so the estimate totals the
+// bid's price. Items are named by trade using the company's mapping over the
+// defaults; line items whose trade is not recognized, or has no mapping,
+// export under defaultItem (DefaultQuickBooksItem when empty) and are listed
+// in the returned warnings. Alternates are not part of the base price and
+// are left out.
+func (s *ExportService) GenerateBidQuickBooks(bid *models.Bid, bidResponse *models.GenerateBidResponse, customer string, itemNames map[string]string, defaultItem string) ([]byte, []QuickBooksWarning, error) {
+	names := DefaultQuickBooksItemNames()
+	for key, item := range itemNames {
+		names[key] = item
+	}
+	if defaultItem == "" {
+		defaultItem = DefaultQuickBooksItem
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(quickBooksColumns)
+
+	estimateNo := bid.ID.String()[:8]
+	date := time.Now().Format("01/02/2006")
+	row := func(item, description string, quantity, rate, amount float64) {
+		writer.Write([]string{
+			estimateNo,
+			customer,
+			date,
+			item,
+			description,
+			fmt.Sprintf("%.2f", quantity),
+			fmt.Sprintf("%.2f", rate),
+			fmt.Sprintf("%.2f", amount),
+		})
+	}
+
+	var warnings []QuickBooksWarning
+	for _, lineItem := range bidResponse.LineItems {
+		if lineItem.IsAlternate {
+			continue
+		}
+		item, mapped := "", false
+		if slug, ok := trades.Normalize(lineItem.Trade); ok {
+			item, mapped = names[slug]
+		}
+		if !mapped {
+			item = defaultItem
+			warnings = append(warnings, QuickBooksWarning{Description: lineItem.Description, Trade: lineItem.Trade, Item: item})
+		}
+		row(item, lineItem.Description, lineItem.Quantity, lineItem.UnitCost, lineItem.Total)
+	}
+
+	if bidResponse.MarkupAmount != 0 {
+		markup := math.Round(bidResponse.MarkupAmount*100) / 100
+		row(names[QuickBooksMarkupKey], "Overhead and profit", 1, markup, markup)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, nil, fmt.Errorf("failed to write QuickBooks CSV: %w", err)
+	}
+
+	return buf.Bytes(), warnings, nil
+}
+
+// GenerateQuickBooksFilename creates the download filename for a bid's
+// QuickBooks estimate
+func (s *ExportService) GenerateQuickBooksFilename(bidID uuid.UUID) string {
+	return fmt.Sprintf("bid-%s-%s-quickbooks.csv", bidID.String()[:8], time.Now().Format("20060102"))
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestGenerateBidQuickBooks(t *testing.T) {
+	service := NewExportService()
+	bid := &models.Bid{ID: uuid.New()}
+	bidResponse := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: `Door, 36" solid "core"`, Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 450, Total: 900},
+			{Description: "Outlets", Trade: "electric", Quantity: 4, Unit: "each", UnitCost: 125, Total: 500},
+			{Description: "Pool enclosure", Trade: "aquatic engineering", Quantity: 1, Unit: "lot", UnitCost: 3000, Total: 3000},
+			{Description: "Skylight", Trade: "roofing", Quantity: 1, Unit: "each", UnitCost: 800, Total: 800, IsAlternate: true},
+		},
+		MarkupAmount: 880,
+	}
+
+	data, warnings, err := service.GenerateBidQuickBooks(bid, bidResponse, "Smith, Kitchen", map[string]string{"carpentry": "Finish Carpentry"}, "")
+	if err != nil {
+		t.Fatalf("GenerateBidQuickBooks() error = %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"Door, 36"" solid ""core"""`)) || !bytes.Contains(data, []byte(`"Smith, Kitchen"`)) {
+		t.Errorf("commas and quotes are not escaped:\n%s", data)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+	wantHeader := "Estimate No|Customer|Estimate Date|Item|Item Description|Item Quantity|Item Rate|Item Amount"
+	if got := strings.Join(rows[0], "|"); got != wantHeader {
+		t.Errorf("header = %q, want %q", got, wantHeader)
+	}
+
+	want := [][]string{
+		{"Finish Carpentry", `Door, 36" solid "core"`, "2.00", "450.00", "900.00"},
+		{"Electrical", "Outlets", "4.00", "125.00", "500.00"},
+		{DefaultQuickBooksItem, "Pool enclosure", "1.00", "3000.00", "3000.00"},
+		{"Markup", "Overhead and profit", "1.00", "880.00", "880.00"},
+	}
+	if len(rows) != len(want)+1 {
+		t.Fatalf("got %d rows, want %d without the alternate: %v", len(rows)-1, len(want), rows)
+	}
+	for i, row := range rows[1:] {
+		if row[0] != bid.ID.String()[:8] || row[1] != "Smith, Kitchen" {
+			t.Errorf("row %d estimate/customer = %q/%q", i, row[0], row[1])
+		}
+		if got := strings.Join(row[3:], "|"); got != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, got, strings.Join(want[i], "|"))
+		}
+	}
+
+	if len(warnings) != 1 || warnings[0].Description != "Pool enclosure" || warnings[0].Item != DefaultQuickBooksItem {
+		t.Errorf("warnings = %+v, want only the unrecognized trade", warnings)
+	}
+}
+
+func TestGenerateBidQuickBooks_CustomCatchAll(t *testing.T) {
+	bidResponse := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{{Description: "Mystery work", Trade: "zzz", Quantity: 1, UnitCost: 10, Total: 10}},
+	}
+	data, warnings, err := NewExportService().GenerateBidQuickBooks(&models.Bid{ID: uuid.New()}, bidResponse, "Project", nil, "Misc Job Costs")
+	if err != nil {
+		t.Fatalf("GenerateBidQuickBooks() error = %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Item != "Misc Job Costs" || !bytes.Contains(data, []byte("Misc Job Costs,Mystery work")) {
+		t.Errorf("warnings = %+v, data:\n%s", warnings, data)
+	}
+}
+
+func TestNormalizeQuickBooksItemNames(t *testing.T) {
+	names, err := NormalizeQuickBooksItemNames(map[string]string{"Electrician": "Electrical Labor", "MARKUP": "O&P", "paint": "  "})
+	if err != nil {
+		t.Fatalf("NormalizeQuickBooksItemNames() error = %v", err)
+	}
+	if len(names) != 2 || names["electrical"] != "Electrical Labor" || names[QuickBooksMarkupKey] != "O&P" {
+		t.Errorf("names = %v, want trade slugs and blank items dropped", names)
+	}
+
+	if _, err := NormalizeQuickBooksItemNames(map[string]string{"aquatic engineering": "Pools"}); err == nil {
+		t.Error("expected an unknown trade to be rejected")
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Tolerances for treating a renamed room as the same room as before
+const (
+	roomAlignAreaTolerance      = 0.02 // relative
+	roomAlignDimensionTolerance = 0.25 // feet
+)
+
+// PriorAnalysis is the part of a blueprint's previous analysis sent to the AI
+// service on re-analysis, so it can keep room names consistent
+type PriorAnalysis struct {
+	Rooms    []models.Room    `json:"rooms"`
+	Openings []models.Opening `json:"openings"`
+	// Truncated is set when rooms or openings were dropped to fit the cap
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// BuildPriorAnalysis takes the rooms and openings of a previous analysis,
+// dropping openings and then rooms from the end until the JSON is at most
+// maxBytes. It returns nil when nothing is left to send.
+func BuildPriorAnalysis(previous *models.AnalysisResult, maxBytes int) *PriorAnalysis {
+	prior := &PriorAnalysis{
+		Rooms:    append([]models.Room{}, previous.Rooms...),
+		Openings: make([]models.Opening, 0, len(previous.Openings)),
+	}
+	// Only what the AI reported; the normalized fields are derived later
+	for _, opening := range previous.Openings {
+		prior.Openings = append(prior.Openings, models.Opening{
+			OpeningType: opening.OpeningType,
+			Count:       opening.Count,
+			Size:        opening.Size,
+		})
+	}
+
+	for maxBytes > 0 && priorAnalysisSize(prior) > maxBytes {
+		prior.Truncated = true
+		if len(prior.Openings) > 0 {
+			prior.Openings = prior.Openings[:len(prior.Openings)-1]
+		} else if len(prior.Rooms) > 0 {
+			prior.Rooms = prior.Rooms[:len(prior.Rooms)-1]
+		} else {
+			break
+		}
+	}
+
+	if len(prior.Rooms) == 0 && len(prior.Openings) == 0 {
+		return nil
+	}
+	return prior
+}
+
+func priorAnalysisSize(prior *PriorAnalysis) int {
+	data, _ := json.Marshal(prior) // plain structs always marshal
+	return len(data)
+}
+
+// RoomRename is a room of a new analysis given the name the same room had in
+// the previous one
+type RoomRename struct {
+	Index int // position in the new analysis's rooms
+	From  string
+	To    string
+}
+
+// AlignRoomNames matches rooms of current whose name is new to rooms of
+// previous whose name is gone, when their areas agree within 2% and their
+// dimensions, where both parse, agree within a quarter foot. Each previous
+// room is used once, taking the closest area. It renames the matched rooms
+// in current, adds a normalization note for each and returns the renames.
+func AlignRoomNames(previous, current *models.AnalysisResult) []RoomRename {
+	currentNames := make(map[string]bool, len(current.Rooms))
+	for _, room := range current.Rooms {
+		currentNames[room.Name] = true
+	}
+	previousNames := make(map[string]bool, len(previous.Rooms))
+	for _, room := range previous.Rooms {
+		previousNames[room.Name] = true
+	}
+
+	// Previous rooms whose name the new analysis no longer uses
+	var candidates []models.Room
+	for _, room := range previous.Rooms {
+		if !currentNames[room.Name] {
+			candidates = append(candidates, room)
+		}
+	}
+
+	var renames []RoomRename
+	used := make(map[int]bool)
+	for i, room := range current.Rooms {
+		if previousNames[room.Name] {
+			continue
+		}
+		best := -1
+		for j, candidate := range candidates {
+			if used[j] || !roomsMatch(candidate, room) {
+				continue
+			}
+			if best < 0 || math.Abs(candidate.Area-room.Area) < math.Abs(candidates[best].Area-room.Area) {
+				best = j
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		used[best] = true
+		renames = append(renames, RoomRename{Index: i, From: room.Name, To: candidates[best].Name})
+	}
+
+	for _, rename := range renames {
+		current.Rooms[rename.Index].Name = rename.To
+		current.NormalizationNotes = append(current.NormalizationNotes, roomRenameNote(rename, current.Rooms[rename.Index]))
+	}
+	return renames
+}
+
+func roomRenameNote(rename RoomRename, room models.Room) string {
+	return fmt.Sprintf("Room %q renamed to %q to match the previous analysis (%.2f SF)", rename.From, rename.To, room.Area)
+}
+
+// roomsMatch reports whether two rooms have the same area and, when both
+// dimension strings parse, the same dimensions in either order
+func roomsMatch(a, b models.Room) bool {
+	if a.Area <= 0 || b.Area <= 0 {
+		return false
+	}
+	if math.Abs(a.Area-b.Area) > math.Max(a.Area, b.Area)*roomAlignAreaTolerance {
+		return false
+	}
+
+	aSides, aOK := parseRoomSides(a.Dimensions)
+	bSides, bOK := parseRoomSides(b.Dimensions)
+	if !aOK || !bOK {
+		return true
+	}
+	if len(aSides) != len(bSides) {
+		return false
+	}
+	for i := range aSides {
+		if math.Abs(aSides[i]-bSides[i]) > roomAlignDimensionTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// parseRoomSides reads a dimension string such as 12'-6" x 10' as sorted
+// lengths in feet
+func parseRoomSides(dimensions string) ([]float64, bool) {
+	parts := dimensionSplit.Split(strings.TrimSpace(dimensions), -1)
+	if len(parts) < 2 {
+		return nil, false
+	}
+	sides := make([]float64, len(parts))
+	for i, part := range parts {
+		feet, ok := parseFeet(part, true)
+		if !ok {
+			return nil, false
+		}
+		sides[i] = feet
+	}
+	sort.Float64s(sides)
+	return sides, true
+}
+
+// ApplyRoomRenames writes renames and notes into the raw analysis JSON,
+// keeping any fields the AI returned that AnalysisResult does not model
+func ApplyRoomRenames(resultData string, renames []RoomRename, notes []string) (string, error) {
+	if len(renames) == 0 {
+		return resultData, nil
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(resultData), &raw); err != nil {
+		return "", fmt.Errorf("failed to parse analysis: %w", err)
+	}
+	rooms, _ := raw["rooms"].([]interface{})
+	for _, rename := range renames {
+		if rename.Index >= len(rooms) {
+			continue
+		}
+		if room, ok := rooms[rename.Index].(map[string]interface{}); ok {
+			room["name"] = rename.To
+		}
+	}
+	raw["normalization_notes"] = notes
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal analysis: %w", err)
+	}
+	return string(data), nil
+}
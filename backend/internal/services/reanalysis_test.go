@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+const previousAnalysisJSON = `{
+	"status": "completed",
+	"rooms": [
+		{"name": "Bedroom 2", "dimensions": "10x12", "area": 120},
+		{"name": "Kitchen", "dimensions": "12x15", "area": 180}
+	],
+	"openings": [{"opening_type": "door", "count": 4, "size": "3x7", "classification": "interior_door"}]
+}`
+
+func TestBuildPriorAnalysis(t *testing.T) {
+	var previous models.AnalysisResult
+	if err := json.Unmarshal([]byte(previousAnalysisJSON), &previous); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("fits", func(t *testing.T) {
+		prior := BuildPriorAnalysis(&previous, 16384)
+		if prior == nil || len(prior.Rooms) != 2 || len(prior.Openings) != 1 || prior.Truncated {
+			t.Fatalf("prior = %+v, want both rooms and the opening", prior)
+		}
+		if prior.Openings[0].Classification != "" {
+			t.Error("expected only the AI's opening fields to be sent")
+		}
+	})
+
+	t.Run("cap drops openings before rooms", func(t *testing.T) {
+		full := len(mustMarshal(t, BuildPriorAnalysis(&previous, 0)))
+		prior := BuildPriorAnalysis(&previous, full-1)
+		if prior == nil || !prior.Truncated || len(prior.Openings) != 0 || len(prior.Rooms) != 2 {
+			t.Fatalf("prior = %+v, want the opening dropped and both rooms kept", prior)
+		}
+		if size := len(mustMarshal(t, prior)); size > full-1 {
+			t.Errorf("prior is %d bytes, over the %d cap", size, full-1)
+		}
+	})
+
+	t.Run("cap smaller than any room", func(t *testing.T) {
+		if prior := BuildPriorAnalysis(&previous, 10); prior != nil {
+			t.Errorf("prior = %+v, want nil when nothing fits", prior)
+		}
+	})
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestAlignRoomNames(t *testing.T) {
+	previous := &models.AnalysisResult{Rooms: []models.Room{
+		{Name: "Bedroom 2", Dimensions: "10x12", Area: 120},
+		{Name: "Bedroom 3", Dimensions: "11x12", Area: 132},
+		{Name: "Kitchen", Dimensions: "12x15", Area: 180},
+		{Name: "Den", Dimensions: "14x14", Area: 196},
+	}}
+	current := &models.AnalysisResult{Rooms: []models.Room{
+		{Name: "Bed 2", Dimensions: "12' x 10'", Area: 121}, // within 2%, sides swapped
+		{Name: "Bed 3", Dimensions: "11x12", Area: 150},     // area changed
+		{Name: "Kitchen", Dimensions: "12x15", Area: 180},
+		{Name: "Family Room", Dimensions: "7x28", Area: 196}, // same area, other shape
+	}}
+
+	renames := AlignRoomNames(previous, current)
+
+	if len(renames) != 1 || renames[0] != (RoomRename{Index: 0, From: "Bed 2", To: "Bedroom 2"}) {
+		t.Fatalf("renames = %+v, want only Bed 2 -> Bedroom 2", renames)
+	}
+	if current.Rooms[0].Name != "Bedroom 2" || current.Rooms[1].Name != "Bed 3" || current.Rooms[3].Name != "Family Room" {
+		t.Errorf("rooms = %+v", current.Rooms)
+	}
+	if len(current.NormalizationNotes) != 1 || !strings.Contains(current.NormalizationNotes[0], `"Bed 2" renamed to "Bedroom 2"`) {
+		t.Errorf("notes = %v", current.NormalizationNotes)
+	}
+}
+
+func TestAlignRoomNames_EachPreviousRoomUsedOnce(t *testing.T) {
+	previous := &models.AnalysisResult{Rooms: []models.Room{
+		{Name: "Bath 1", Area: 50},
+	}}
+	current := &models.AnalysisResult{Rooms: []models.Room{
+		{Name: "Bathroom A", Area: 50.5},
+		{Name: "Bathroom B", Area: 50},
+	}}
+
+	renames := AlignRoomNames(previous, current)
+
+	if len(renames) != 1 || current.Rooms[0].Name != "Bath 1" || current.Rooms[1].Name != "Bathroom B" {
+		t.Errorf("renames = %+v, rooms = %+v; want Bath 1 given once", renames, current.Rooms)
+	}
+}
+
+func TestAlignRoomNames_ReducesComparisonChanges(t *testing.T) {
+	from := previousAnalysisJSON
+	to := `{"status":"completed","rooms":[
+		{"name":"Bed 2","dimensions":"10x12","area":120},
+		{"name":"Kitchen","dimensions":"12x15","area":180}
+	],"extra":{"kept":true}}`
+
+	var previous, current models.AnalysisResult
+	if err := json.Unmarshal([]byte(from), &previous); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(to), &current); err != nil {
+		t.Fatal(err)
+	}
+	renames := AlignRoomNames(&previous, &current)
+	aligned, err := ApplyRoomRenames(to, renames, current.NormalizationNotes)
+	if err != nil {
+		t.Fatalf("ApplyRoomRenames() error = %v", err)
+	}
+	if !strings.Contains(aligned, `"extra":{"kept":true}`) || !strings.Contains(aligned, "normalization_notes") {
+		t.Errorf("aligned analysis lost fields or notes: %s", aligned)
+	}
+
+	roomChanges := func(toData string) int {
+		comparison, err := NewComparisonService().CompareBlueprintRevisions(
+			&models.BlueprintRevision{AnalysisData: &from},
+			&models.BlueprintRevision{AnalysisData: &toData},
+		)
+		if err != nil {
+			t.Fatalf("CompareBlueprintRevisions() error = %v", err)
+		}
+		count := 0
+		for _, change := range comparison.Changes {
+			if change.Category == "room" {
+				count++
+			}
+		}
+		return count
+	}
+	// Unaligned, the comparison still pairs the rename as one change
+	if before, after := roomChanges(to), roomChanges(aligned); before != 1 || after != 0 {
+		t.Errorf("room changes = %d before alignment and %d after, want 1 and 0", before, after)
+	}
+}
+
+// newReanalysisWorkerTest returns a worker whose AI service records each
+// analyze request and answers with response
+func newReanalysisWorkerTest(t *testing.T, cfg config.WorkerConfig, reanalyze bool, response string) (*Worker, *models.Job, *fakeWorkerBlueprints, *[]AnalyzeRequest) {
+	t.Helper()
+	var requests []AnalyzeRequest
+	service, closeFn := newTestAIService(func(w http.ResponseWriter, r *http.Request) {
+		var req AnalyzeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode analyze request: %v", err)
+		}
+		requests = append(requests, req)
+		fmt.Fprintf(w, `{"success":true,"data":%s}`, response)
+	})
+	t.Cleanup(closeFn)
+
+	previous := previousAnalysisJSON
+	blueprintID := uuid.New()
+	blueprints := &fakeWorkerBlueprints{blueprints: map[uuid.UUID]models.Blueprint{
+		blueprintID: {ID: blueprintID, S3Key: "blueprints/rev2.pdf", UploadStatus: models.UploadStatusUploaded, UploadGeneration: 1, AnalysisData: &previous},
+	}}
+	job := &models.Job{ID: uuid.New(), BlueprintID: blueprintID, JobType: models.JobTypeTakeoff, Status: models.JobStatusQueued, UploadGeneration: 1, Reanalyze: reanalyze}
+	jobs := &fakeWorkerJobs{jobs: []*models.Job{job}}
+	return NewWorker(jobs, blueprints, service, &config.Config{Worker: cfg}), job, blueprints, &requests
+}
+
+func TestWorker_Reanalysis(t *testing.T) {
+	response := `{"status":"completed","rooms":[{"name":"Bed 2","dimensions":"10x12","area":120},{"name":"Kitchen","dimensions":"12x15","area":180}]}`
+	enabled := config.WorkerConfig{ReanalyzeContext: true, ReanalyzeContextMaxBytes: 16384}
+
+	t.Run("sends prior analysis and aligns room names", func(t *testing.T) {
+		worker, job, blueprints, requests := newReanalysisWorkerTest(t, enabled, true, response)
+
+		if err := worker.processJob(context.Background(), job); err != nil {
+			t.Fatalf("processJob() error = %v", err)
+		}
+		if len(*requests) != 1 || (*requests)[0].PriorAnalysis == nil || len((*requests)[0].PriorAnalysis.Rooms) != 2 {
+			t.Fatalf("requests = %+v, want the previous rooms sent", *requests)
+		}
+
+		var stored models.AnalysisResult
+		if err := json.Unmarshal([]byte(*blueprints.blueprints[job.BlueprintID].AnalysisData), &stored); err != nil {
+			t.Fatal(err)
+		}
+		if stored.Rooms[0].Name != "Bedroom 2" || len(stored.NormalizationNotes) != 1 {
+			t.Errorf("stored rooms = %+v, notes = %v; want Bed 2 aligned to Bedroom 2", stored.Rooms, stored.NormalizationNotes)
+		}
+	})
+
+	t.Run("context disabled still aligns", func(t *testing.T) {
+		worker, job, blueprints, requests := newReanalysisWorkerTest(t, config.WorkerConfig{}, true, response)
+
+		if err := worker.processJob(context.Background(), job); err != nil {
+			t.Fatalf("processJob() error = %v", err)
+		}
+		if (*requests)[0].PriorAnalysis != nil {
+			t.Error("expected no prior analysis with the context flag off")
+		}
+		if data := *blueprints.blueprints[job.BlueprintID].AnalysisData; !strings.Contains(data, "Bedroom 2") {
+			t.Errorf("expected room names aligned, got %s", data)
+		}
+	})
+
+	t.Run("not a re-analysis", func(t *testing.T) {
+		worker, job, blueprints, requests := newReanalysisWorkerTest(t, enabled, false, response)
+
+		if err := worker.processJob(context.Background(), job); err != nil {
+			t.Fatalf("processJob() error = %v", err)
+		}
+		if (*requests)[0].PriorAnalysis != nil {
+			t.Error("expected no prior analysis for a plain analysis")
+		}
+		if data := *blueprints.blueprints[job.BlueprintID].AnalysisData; !strings.Contains(data, "Bed 2") || strings.Contains(data, "normalization_notes") {
+			t.Errorf("expected the AI's room names kept, got %s", data)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+)
+
+// MaxCompareRegions caps the regions priced in one comparison request
+const MaxCompareRegions = 5
+
+// ErrUnknownRegion is returned when a compared region has no regional
+// adjustment
+var ErrUnknownRegion = errors.New("unknown region")
+
+// ParseCompareRegions splits a comma-separated region list, dropping blanks
+// and duplicates, and enforces 1 to MaxCompareRegions regions
+func ParseCompareRegions(raw string) ([]string, error) {
+	var regions []string
+	seen := make(map[string]bool)
+	for _, region := range strings.Split(raw, ",") {
+		region = strings.ToLower(strings.TrimSpace(region))
+		if region == "" || seen[region] {
+			continue
+		}
+		seen[region] = true
+		regions = append(regions, region)
+	}
+
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("at least one region is required")
+	}
+	if len(regions) > MaxCompareRegions {
+		return nil, fmt.Errorf("at most %d regions can be compared, got %d", MaxCompareRegions, len(regions))
+	}
+	return regions, nil
+}
+
+// ValidateRegions checks that every region has a regional adjustment,
+// returning ErrUnknownRegion for the first that doesn't. Without configured
+// cost data regions can't be checked and every region prices the defaults.
+func (s *EnhancedPricingService) ValidateRegions(ctx context.Context, regions []string) error {
+	for _, region := range regions {
+		_, err := s.costData.GetRegionalAdjustment(ctx, region)
+		switch {
+		case err == nil:
+		case errors.Is(err, errCostDataNotConfigured):
+			return nil
+		case errors.Is(err, repository.ErrRegionalAdjustmentNotFound):
+			return fmt.Errorf("%w: %s", ErrUnknownRegion, region)
+		default:
+			return fmt.Errorf("failed to load region %s: %w", region, err)
+		}
+	}
+	return nil
+}
+
+// CompareRegions prices the same takeoff once per region, each with that
+// region's adjustment, the user's company overrides and the project's
+// overrides, as projectType. Tax
+// is charged under taxSettings' rule for each region unless taxExempt.
+func (s *EnhancedPricingService) CompareRegions(
+	ctx context.Context,
+	takeoffSummary *models.TakeoffSummary,
+	analysisResult *models.AnalysisResult,
+	userID *uuid.UUID,
+	projectID *uuid.UUID,
+	regions []string,
+	projectType models.ProjectType,
+	taxSettings *models.TaxSettings,
+	taxExempt bool,
+) (*models.RegionComparison, error) {
+	summaries := make([]*models.PricingSummary, len(regions))
+	for i, region := range regions {
+		region := region
+		summary, err := s.GenerateProjectPricingSummary(ctx, takeoffSummary, analysisResult, userID, projectID, &region, projectType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price region %s: %w", region, err)
+		}
+		ApplyPricingTax(summary, NewTaxSummary(taxSettings, region, taxExempt))
+		summaries[i] = summary
+	}
+	return BuildRegionComparison(regions, summaries), nil
+}
+
+// BuildRegionComparison aligns per-region pricing summaries by line item
+// description. summaries[i] is the pricing for regions[i]; the first region
+// is the baseline the others' deltas are measured from.
+func BuildRegionComparison(regions []string, summaries []*models.PricingSummary) *models.RegionComparison {
+	comparison := &models.RegionComparison{
+		Regions:   regions,
+		LineItems: []models.RegionLineItemComparison{},
+		Trades:    make(map[string]map[string]float64),
+		Subtotals: make(map[string]float64),
+		Taxes:     make(map[string]float64),
+		Totals:    make(map[string]float64),
+	}
+
+	rows := make(map[string]int)
+	for i, region := range regions {
+		summary := summaries[i]
+		comparison.Subtotals[region] = summary.Subtotal
+		comparison.Taxes[region] = summary.TaxAmount
+		comparison.Totals[region] = summary.TotalPrice
+
+		for _, item := range summary.LineItems {
+			index, ok := rows[item.Description]
+			if !ok {
+				index = len(comparison.LineItems)
+				rows[item.Description] = index
+				comparison.LineItems = append(comparison.LineItems, models.RegionLineItemComparison{
+					Description: item.Description,
+					Trade:       item.Trade,
+					Unit:        item.Unit,
+					Quantity:    make(map[string]float64),
+					UnitCost:    make(map[string]float64),
+					Total:       make(map[string]float64),
+				})
+			}
+			row := &comparison.LineItems[index]
+			row.Quantity[region] = item.Quantity
+			row.UnitCost[region] = item.UnitCost
+			row.Total[region] = item.Total
+
+			if comparison.Trades[item.Trade] == nil {
+				comparison.Trades[item.Trade] = make(map[string]float64)
+			}
+			comparison.Trades[item.Trade][region] = math.Round((comparison.Trades[item.Trade][region]+item.Total)*100) / 100
+		}
+	}
+
+	comparison.Spread = regionSpread(regions, comparison.Totals)
+	setBaselineDeltas(comparison)
+	return comparison
+}
+
+// setBaselineDeltas sets each region's per-trade and percent difference from
+// the first region
+func setBaselineDeltas(comparison *models.RegionComparison) {
+	comparison.TradeDeltas = make(map[string]map[string]float64)
+	comparison.PercentDifferences = make(map[string]float64)
+	if len(comparison.Regions) == 0 {
+		return
+	}
+
+	baseline := comparison.Regions[0]
+	comparison.Baseline = baseline
+	for trade, totals := range comparison.Trades {
+		deltas := make(map[string]float64, len(comparison.Regions))
+		for _, region := range comparison.Regions {
+			deltas[region] = math.Round((totals[region]-totals[baseline])*100) / 100
+		}
+		comparison.TradeDeltas[trade] = deltas
+	}
+
+	base := comparison.Totals[baseline]
+	for _, region := range comparison.Regions {
+		if base > 0 {
+			comparison.PercentDifferences[region] = math.Round((comparison.Totals[region]-base)/base*100*100) / 100
+		}
+	}
+}
+
+// regionSpread finds the lowest and highest total and the percent between them
+func regionSpread(regions []string, totals map[string]float64) models.RegionSpread {
+	var spread models.RegionSpread
+	if len(regions) == 0 {
+		return spread
+	}
+
+	spread.LowestRegion, spread.HighestRegion = regions[0], regions[0]
+	for _, region := range regions[1:] {
+		if totals[region] < totals[spread.LowestRegion] {
+			spread.LowestRegion = region
+		}
+		if totals[region] > totals[spread.HighestRegion] {
+			spread.HighestRegion = region
+		}
+	}
+
+	low, high := totals[spread.LowestRegion], totals[spread.HighestRegion]
+	spread.Difference = math.Round((high-low)*100) / 100
+	if low > 0 {
+		spread.Percent = math.Round((high-low)/low*100*100) / 100
+	}
+	return spread
+}
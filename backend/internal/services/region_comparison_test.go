@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
+)
+
+// fakeCostData serves regional factors with no database prices, so every
+// region prices the defaults scaled by its factor
+type fakeCostData struct {
+	factors map[string]float64
+}
+
+func (f *fakeCostData) GetMaterials(ctx context.Context, category, region *string) ([]models.MaterialCost, error) {
+	return []models.MaterialCost{}, nil
+}
+
+func (f *fakeCostData) GetLaborRates(ctx context.Context, trade, region *string) ([]models.LaborRate, error) {
+	return []models.LaborRate{}, nil
+}
+
+func (f *fakeCostData) GetRegionalAdjustment(ctx context.Context, region string) (*models.RegionalAdjustment, error) {
+	factor, ok := f.factors[region]
+	if !ok {
+		return nil, repository.ErrRegionalAdjustmentNotFound
+	}
+	return &models.RegionalAdjustment{Region: region, AdjustmentFactor: factor}, nil
+}
+
+func TestEnhancedPricingService_CompareRegions(t *testing.T) {
+	service := NewEnhancedPricingService(nil, nil, nil, nil).
+		WithCostData(&fakeCostData{factors: map[string]float64{"california": 1.25, "texas": 0.95}})
+	takeoff := &models.TakeoffSummary{TotalArea: 100, RoomCount: 1}
+	analysis := &models.AnalysisResult{
+		Openings: []models.Opening{{OpeningType: "door", Count: 2}},
+	}
+
+	comparison, err := service.CompareRegions(context.Background(), takeoff, analysis, nil, nil, []string{"california", "texas"}, models.ProjectTypeNewConstruction, nil, false)
+	if err != nil {
+		t.Fatalf("CompareRegions() error = %v", err)
+	}
+
+	var door *models.RegionLineItemComparison
+	for i := range comparison.LineItems {
+		if comparison.LineItems[i].Description == "Interior door installation" {
+			door = &comparison.LineItems[i]
+		}
+	}
+	if door == nil {
+		t.Fatalf("Expected a door row, got %+v", comparison.LineItems)
+	}
+	// Default door price is 450, scaled per region
+	if door.UnitCost["california"] != 562.5 || door.UnitCost["texas"] != 427.5 {
+		t.Errorf("Unexpected door unit costs: %v", door.UnitCost)
+	}
+	if door.Total["california"] != 1125 || door.Total["texas"] != 855 {
+		t.Errorf("Unexpected door totals: %v", door.Total)
+	}
+
+	// Hardcoded framing rate is not regionally adjusted
+	framing := comparison.LineItems[0]
+	if framing.Total["california"] != framing.Total["texas"] {
+		t.Errorf("Expected identical framing totals, got %v", framing.Total)
+	}
+
+	if comparison.Trades["carpentry"]["california"] <= comparison.Trades["carpentry"]["texas"] {
+		t.Errorf("Expected California carpentry to cost more, got %v", comparison.Trades["carpentry"])
+	}
+
+	ca, tx := comparison.Totals["california"], comparison.Totals["texas"]
+	if comparison.Spread.HighestRegion != "california" || comparison.Spread.LowestRegion != "texas" {
+		t.Errorf("Unexpected spread regions: %+v", comparison.Spread)
+	}
+	if comparison.Spread.Difference != roundCents(ca-tx) {
+		t.Errorf("Spread difference = %v, want %v", comparison.Spread.Difference, roundCents(ca-tx))
+	}
+	if want := roundCents((ca - tx) / tx * 100); comparison.Spread.Percent != want {
+		t.Errorf("Spread percent = %v, want %v", comparison.Spread.Percent, want)
+	}
+}
+
+func TestBuildRegionComparison_Spread(t *testing.T) {
+	summaries := []*models.PricingSummary{
+		{TotalPrice: 1200, LineItems: []models.LineItem{{Description: "Drywall", Trade: "drywall", Total: 1000}}},
+		{TotalPrice: 1000, LineItems: []models.LineItem{{Description: "Drywall", Trade: "drywall", Total: 800}}},
+		{TotalPrice: 1100, LineItems: []models.LineItem{{Description: "Drywall", Trade: "drywall", Total: 900}, {Description: "Permit", Trade: "general", Total: 50}}},
+	}
+
+	comparison := BuildRegionComparison([]string{"a", "b", "c"}, summaries)
+
+	if len(comparison.LineItems) != 2 {
+		t.Fatalf("Expected rows aligned by description, got %+v", comparison.LineItems)
+	}
+	if _, ok := comparison.LineItems[1].Total["a"]; ok {
+		t.Error("Regions without a line item should have no value for it")
+	}
+	if comparison.Spread.LowestRegion != "b" || comparison.Spread.HighestRegion != "a" ||
+		comparison.Spread.Difference != 200 || comparison.Spread.Percent != 20 {
+		t.Errorf("Unexpected spread: %+v", comparison.Spread)
+	}
+}
+
+func TestEnhancedPricingService_CompareRegionsDeltas(t *testing.T) {
+	service := NewEnhancedPricingService(nil, nil, nil, nil).
+		WithCostData(&fakeCostData{factors: map[string]float64{"north": 1.0, "south": 0.8}})
+	// Two windows at the 850.00 default and six carpentry hours at 75.00
+	// to install them, scaled per region
+	analysis := &models.AnalysisResult{Openings: []models.Opening{{OpeningType: "window", Count: 2}}}
+
+	comparison, err := service.CompareRegions(context.Background(), nil, analysis, nil, nil, []string{"north", "south"}, models.ProjectTypeNewConstruction, nil, false)
+	if err != nil {
+		t.Fatalf("CompareRegions() error = %v", err)
+	}
+
+	if comparison.Baseline != "north" {
+		t.Errorf("Baseline = %q, want the first region", comparison.Baseline)
+	}
+	for trade, totals := range comparison.Trades {
+		if delta := comparison.TradeDeltas[trade]["south"]; delta != roundCents(totals["south"]-totals["north"]) {
+			t.Errorf("%s delta = %v, want %v", trade, delta, roundCents(totals["south"]-totals["north"]))
+		}
+		if delta := comparison.TradeDeltas[trade]["north"]; delta != 0 {
+			t.Errorf("%s baseline delta = %v, want 0", trade, delta)
+		}
+	}
+	if comparison.TradeDeltas["carpentry"]["south"] != -430 {
+		t.Errorf("south carpentry delta = %v, want -430.00 ((1700.00 + 450.00) x -0.2)", comparison.TradeDeltas["carpentry"]["south"])
+	}
+
+	north, south := comparison.Totals["north"], comparison.Totals["south"]
+	if want := roundCents((south - north) / north * 100); comparison.PercentDifferences["south"] != want || want >= 0 {
+		t.Errorf("south percent difference = %v, want %v below the baseline", comparison.PercentDifferences["south"], want)
+	}
+	if comparison.PercentDifferences["north"] != 0 {
+		t.Errorf("baseline percent difference = %v, want 0", comparison.PercentDifferences["north"])
+	}
+}
+
+func TestEnhancedPricingService_ValidateRegions(t *testing.T) {
+	service := NewEnhancedPricingService(nil, nil, nil, nil).
+		WithCostData(&fakeCostData{factors: map[string]float64{"north": 1.0, "south": 0.8}})
+
+	if err := service.ValidateRegions(context.Background(), []string{"north", "south"}); err != nil {
+		t.Errorf("ValidateRegions() error = %v", err)
+	}
+	err := service.ValidateRegions(context.Background(), []string{"north", "atlantis"})
+	if !errors.Is(err, ErrUnknownRegion) || !strings.Contains(err.Error(), "atlantis") {
+		t.Errorf("ValidateRegions() error = %v, want ErrUnknownRegion naming atlantis", err)
+	}
+
+	// Without cost data every region prices the defaults
+	if err := NewEnhancedPricingService(nil, nil, nil, nil).ValidateRegions(context.Background(), []string{"atlantis"}); err != nil {
+		t.Errorf("ValidateRegions() without cost data error = %v, want nil", err)
+	}
+}
+
+func TestParseCompareRegions(t *testing.T) {
+	regions, err := ParseCompareRegions(" California, texas,,national,TEXAS ")
+	if err != nil {
+		t.Fatalf("ParseCompareRegions() error = %v", err)
+	}
+	if strings.Join(regions, ",") != "california,texas,national" {
+		t.Errorf("Unexpected regions: %v", regions)
+	}
+
+	if _, err := ParseCompareRegions(""); err == nil {
+		t.Error("Expected an empty region list to be rejected")
+	}
+	if _, err := ParseCompareRegions("a,b,c,d,e,f"); err == nil {
+		t.Error("Expected more than 5 regions to be rejected")
+	}
+}
+
+func roundCents(x float64) float64 {
+	return math.Round(x*100) / 100
+}
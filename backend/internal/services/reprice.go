@@ -0,0 +1,163 @@
+package services
+
+import (
+	"math"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/trades"
+)
+
+// LineItemPriceChange is a line item whose price moved when a bid was repriced
+type LineItemPriceChange struct {
+	Description    string  `json:"description"`
+	Trade          string  `json:"trade"`
+	AlternateGroup string  `json:"alternate_group,omitempty"`
+	Quantity       float64 `json:"quantity"`
+	Unit           string  `json:"unit"`
+	OldUnitCost    float64 `json:"old_unit_cost"`
+	NewUnitCost    float64 `json:"new_unit_cost"`
+	OldTotal       float64 `json:"old_total"`
+	NewTotal       float64 `json:"new_total"`
+}
+
+// RepriceResult lists the prices a reprice changed and the line items it
+// could not or would not reprice
+type RepriceResult struct {
+	Changes       []LineItemPriceChange `json:"changes"`
+	SkippedManual int                   `json:"skipped_manual"` // Manual items left alone
+	Unmatched     int                   `json:"unmatched"`      // Items with no takeoff price to apply
+	OldTotalPrice float64               `json:"old_total_price"`
+	NewTotalPrice float64               `json:"new_total_price"`
+}
+
+// MarkLineItemProvenance flags line items that match a takeoff-priced item in
+// summary as auto and the rest as manual, copying the matched item's labor
+// and material split for sales tax. Items already flagged are left alone. It
+// reports whether any item changed.
+func MarkLineItemProvenance(items []models.LineItem, summary *models.PricingSummary) bool {
+	prices := takeoffPrices(summary)
+	changed := false
+	for i := range items {
+		if items[i].Provenance != "" {
+			continue
+		}
+		items[i].Provenance = models.LineItemProvenanceManual
+		if price, ok := prices[repriceKey(items[i])]; ok {
+			items[i].Provenance = models.LineItemProvenanceAuto
+			items[i].MaterialCost = price.MaterialCost
+			items[i].LaborCost = price.LaborCost
+		}
+		changed = true
+	}
+	return changed
+}
+
+// RepriceBid applies the unit costs in summary to the bid's takeoff-priced
+// line items, keeping their quantities, and recalculates the labor, material,
+// subtotal, markup, tax and total along with each alternate group's price. Manual
+// items keep their prices unless overwriteManual is set. Items with no
+// provenance predate the flag and are repriced when they match the takeoff.
+func RepriceBid(bid *models.GenerateBidResponse, summary *models.PricingSummary, markupPercentage float64, overwriteManual bool) RepriceResult {
+	prices := takeoffPrices(summary)
+	result := RepriceResult{Changes: []LineItemPriceChange{}, OldTotalPrice: bid.TotalPrice, NewTotalPrice: bid.TotalPrice}
+
+	reprice := func(item *models.LineItem) (float64, bool) {
+		if item.Provenance == models.LineItemProvenanceManual && !overwriteManual {
+			result.SkippedManual++
+			return 0, false
+		}
+		price, ok := prices[repriceKey(*item)]
+		if !ok {
+			result.Unmatched++
+			return 0, false
+		}
+		if item.Provenance == "" {
+			item.Provenance = models.LineItemProvenanceAuto
+		}
+
+		newTotal := math.Round(item.Quantity*price.UnitCost*100) / 100
+		if newTotal == item.Total && price.UnitCost == item.UnitCost {
+			return 0, false
+		}
+		result.Changes = append(result.Changes, LineItemPriceChange{
+			Description:    item.Description,
+			Trade:          item.Trade,
+			AlternateGroup: item.AlternateGroup,
+			Quantity:       item.Quantity,
+			Unit:           item.Unit,
+			OldUnitCost:    item.UnitCost,
+			NewUnitCost:    price.UnitCost,
+			OldTotal:       item.Total,
+			NewTotal:       newTotal,
+		})
+		delta := newTotal - item.Total
+		item.UnitCost = price.UnitCost
+		item.Total = newTotal
+		item.PriceSource = price.PriceSource
+		return delta, true
+	}
+
+	baseChanged := false
+	for i := range bid.LineItems {
+		delta, ok := reprice(&bid.LineItems[i])
+		if !ok {
+			continue
+		}
+		baseChanged = true
+		if isLaborLineItem(bid.LineItems[i]) {
+			bid.LaborCost += delta
+		} else {
+			bid.MaterialCost += delta
+		}
+	}
+
+	for g := range bid.Alternates {
+		group := &bid.Alternates[g]
+		groupChanged := false
+		for i := range group.LineItems {
+			if delta, ok := reprice(&group.LineItems[i]); ok {
+				group.Cost += delta
+				groupChanged = true
+			}
+		}
+		if groupChanged {
+			group.Cost = math.Round(group.Cost*100) / 100
+			markup := math.Round(group.Cost*markupPercentage) / 100
+			group.Price = math.Round((group.Cost+markup)*100) / 100
+		}
+	}
+
+	// Totals are only recalculated when a base price moved, so a reprice
+	// that changes nothing leaves the AI's totals untouched
+	if baseChanged {
+		bid.LaborCost = math.Round(bid.LaborCost*100) / 100
+		bid.MaterialCost = math.Round(bid.MaterialCost*100) / 100
+		bid.Subtotal = math.Round((bid.LaborCost+bid.MaterialCost)*100) / 100
+		bid.MarkupAmount = math.Round(bid.Subtotal*markupPercentage) / 100
+		bid.TotalPrice = math.Round((bid.Subtotal+bid.MarkupAmount)*100) / 100
+		RecomputeBidTax(bid)
+		result.NewTotalPrice = bid.TotalPrice
+	}
+
+	return result
+}
+
+// takeoffPrices indexes a pricing summary's line items by repriceKey
+func takeoffPrices(summary *models.PricingSummary) map[string]models.LineItem {
+	prices := make(map[string]models.LineItem)
+	if summary == nil {
+		return prices
+	}
+	for _, item := range summary.LineItems {
+		prices[repriceKey(item)] = item
+	}
+	return prices
+}
+
+// repriceKey matches a bid line item to the pricing summary item it was
+// priced from by trade, description and unit
+func repriceKey(item models.LineItem) string {
+	trade, _ := trades.Normalize(item.Trade)
+	return trade + "|" + strings.ToLower(strings.TrimSpace(item.Description)) + "|" + strings.ToLower(item.Unit)
+}
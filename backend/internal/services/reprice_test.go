@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func repriceSummary() *models.PricingSummary {
+	return &models.PricingSummary{LineItems: []models.LineItem{
+		{Description: "Interior door installation", Trade: "carpentry", Quantity: 3, Unit: "each", UnitCost: 450, Total: 1350},
+		{Description: "Labor - carpentry", Trade: "carpentry", Quantity: 10, Unit: "hours", UnitCost: 80, Total: 800},
+	}}
+}
+
+func TestMarkLineItemProvenance(t *testing.T) {
+	items := []models.LineItem{
+		{Description: "Interior Door Installation", Trade: "Carpentry", Unit: "each"},
+		{Description: "Custom millwork", Trade: "carpentry", Unit: "lf"},
+		{Description: "Labor - carpentry", Trade: "carpentry", Unit: "hours", Provenance: models.LineItemProvenanceManual},
+	}
+
+	if !MarkLineItemProvenance(items, repriceSummary()) {
+		t.Fatal("expected unflagged items to be marked")
+	}
+	want := []string{models.LineItemProvenanceAuto, models.LineItemProvenanceManual, models.LineItemProvenanceManual}
+	for i := range want {
+		if items[i].Provenance != want[i] {
+			t.Errorf("item %d provenance = %q, want %q", i, items[i].Provenance, want[i])
+		}
+	}
+	if MarkLineItemProvenance(items, repriceSummary()) {
+		t.Error("expected flagged items to be left alone")
+	}
+}
+
+func TestRepriceBid_PreservesManualItems(t *testing.T) {
+	newBid := func() *models.GenerateBidResponse {
+		return &models.GenerateBidResponse{
+			LineItems: []models.LineItem{
+				{Description: "Interior door installation", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 400, Total: 800, Provenance: models.LineItemProvenanceAuto},
+				{Description: "Labor - carpentry", Trade: "carpentry", Quantity: 12, Unit: "hours", UnitCost: 70, Total: 840, Provenance: models.LineItemProvenanceManual},
+			},
+			Alternates: []models.AlternateGroup{{
+				Name: "Upgrade",
+				LineItems: []models.LineItem{
+					// Predates provenance, so it is repriced when it matches
+					{Description: "Interior door installation", Trade: "carpentry", Quantity: 1, Unit: "each", UnitCost: 400, Total: 400, IsAlternate: true, AlternateGroup: "Upgrade"},
+				},
+				Cost:  400,
+				Price: 480,
+			}},
+			LaborCost:    840,
+			MaterialCost: 800,
+			Subtotal:     1640,
+			MarkupAmount: 328,
+			TotalPrice:   1968,
+		}
+	}
+
+	bid := newBid()
+	result := RepriceBid(bid, repriceSummary(), 20, false)
+	if len(result.Changes) != 2 || result.SkippedManual != 1 || result.Unmatched != 0 {
+		t.Fatalf("result = %+v, want the door and the alternate repriced and the manual labor skipped", result)
+	}
+	if labor := bid.LineItems[1]; labor.UnitCost != 70 || labor.Total != 840 {
+		t.Errorf("manual labor = %+v, want its price preserved", labor)
+	}
+	// Quantities come from the bid, not the new takeoff
+	if door := bid.LineItems[0]; door.Quantity != 2 || door.Total != 900 {
+		t.Errorf("door = %+v, want 2 doors at 450.00", door)
+	}
+	if bid.MaterialCost != 900 || bid.LaborCost != 840 || bid.Subtotal != 1740 || bid.MarkupAmount != 348 || bid.TotalPrice != 2088 {
+		t.Errorf("totals = %v/%v/%v/%v/%v", bid.MaterialCost, bid.LaborCost, bid.Subtotal, bid.MarkupAmount, bid.TotalPrice)
+	}
+	if group := bid.Alternates[0]; group.Cost != 450 || group.Price != 540 || group.LineItems[0].Provenance != models.LineItemProvenanceAuto {
+		t.Errorf("alternate = %+v, want it repriced to 450.00 and flagged auto", group)
+	}
+	if result.OldTotalPrice != 1968 || result.NewTotalPrice != 2088 {
+		t.Errorf("total moved %v -> %v, want 1968 -> 2088", result.OldTotalPrice, result.NewTotalPrice)
+	}
+
+	bid = newBid()
+	result = RepriceBid(bid, repriceSummary(), 20, true)
+	if result.SkippedManual != 0 || bid.LineItems[1].UnitCost != 80 || bid.LaborCost != 960 {
+		t.Errorf("overwrite_manual: result = %+v, labor = %+v", result, bid.LineItems[1])
+	}
+}
+
+func TestRepriceBid_NoChangesLeavesTotals(t *testing.T) {
+	bid := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Interior door installation", Trade: "carpentry", Quantity: 2, Unit: "each", UnitCost: 450, Total: 900, Provenance: models.LineItemProvenanceAuto},
+			{Description: "Dumpster rental", Trade: "general", Quantity: 1, Unit: "each", UnitCost: 600, Total: 600},
+		},
+		Subtotal:   1499.99,
+		TotalPrice: 1799.99,
+	}
+
+	result := RepriceBid(bid, repriceSummary(), 20, false)
+	if len(result.Changes) != 0 || result.Unmatched != 1 {
+		t.Errorf("result = %+v, want no changes and the dumpster unmatched", result)
+	}
+	if bid.Subtotal != 1499.99 || bid.TotalPrice != 1799.99 {
+		t.Errorf("totals = %v/%v, want them untouched", bid.Subtotal, bid.TotalPrice)
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// RetentionStore finds and deletes rows past their retention, deleting at
+// most limit rows per call
+type RetentionStore interface {
+	CountExpiredJobs(ctx context.Context, before time.Time) (int64, error)
+	DeleteExpiredJobs(ctx context.Context, before time.Time, limit int) (int64, error)
+	CountOrphanedBlueprintRevisions(ctx context.Context) (int64, error)
+	DeleteOrphanedBlueprintRevisions(ctx context.Context, limit int) (int64, error)
+	CountOrphanedBidRevisions(ctx context.Context) (int64, error)
+	DeleteOrphanedBidRevisions(ctx context.Context, limit int) (int64, error)
+	CountExpiredDrafts(ctx context.Context, now time.Time) (int64, error)
+	DeleteExpiredDrafts(ctx context.Context, now time.Time, limit int) (int64, error)
+}
+
+// retentionPhase counts or deletes one kind of expired row
+type retentionPhase struct {
+	name   string
+	count  func(ctx context.Context) (int64, error)
+	delete func(ctx context.Context, limit int) (int64, error)
+}
+
+// RetentionSweeper deletes finished jobs past their retention, revisions
+// whose blueprint or bid no longer exists and expired bid drafts. Each phase
+// deletes in batches until a batch comes back short, and reports its count in
+// a log line and an audit event. There is no idempotency key table yet; add a
+// phase here when one exists.
+type RetentionSweeper struct {
+	store RetentionStore
+	cfg   config.RetentionConfig
+	now   func() time.Time
+
+	mu      sync.Mutex
+	lastRun time.Time
+}
+
+func NewRetentionSweeper(store RetentionStore, cfg config.RetentionConfig) *RetentionSweeper {
+	return &RetentionSweeper{store: store, cfg: cfg, now: time.Now}
+}
+
+// RunIfDue sweeps when the configured interval has passed since the last
+// sweep, so the worker can call it on every poll
+func (s *RetentionSweeper) RunIfDue(ctx context.Context) {
+	s.mu.Lock()
+	due := s.lastRun.IsZero() || s.now().Sub(s.lastRun) >= s.cfg.Interval
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+	s.Sweep(ctx, "worker", false)
+}
+
+// Sweep runs every phase and returns what each removed; with dryRun it only
+// counts. A failed phase is reported and the remaining phases still run.
+// actor names who asked for the sweep in the audit events.
+func (s *RetentionSweeper) Sweep(ctx context.Context, actor string, dryRun bool) *models.RetentionReport {
+	now := s.now()
+	before := now.Add(-s.cfg.JobRetention)
+	report := &models.RetentionReport{DryRun: dryRun, JobsBefore: models.NewTimestamp(before)}
+
+	for _, phase := range s.phases(now, before) {
+		result := s.runPhase(ctx, phase, dryRun)
+		report.Phases = append(report.Phases, result)
+
+		if result.Error != "" {
+			slog.Error("Retention phase failed", "phase", phase.name, "rows", result.Rows, "error", result.Error)
+		} else if result.Rows > 0 {
+			slog.Info("Retention phase finished", "phase", phase.name, "rows", result.Rows, "batches", result.Batches, "dry_run", dryRun)
+		}
+		if !dryRun {
+			slog.Info("Retention sweep deleted rows",
+				"audit_event", "retention.rows_deleted",
+				"user_id", actor,
+				"phase", phase.name,
+				"rows", result.Rows,
+				"batches", result.Batches)
+		}
+	}
+
+	if !dryRun {
+		s.mu.Lock()
+		s.lastRun = now
+		s.mu.Unlock()
+	}
+	return report
+}
+
+func (s *RetentionSweeper) phases(now, before time.Time) []retentionPhase {
+	return []retentionPhase{
+		{
+			name: models.RetentionPhaseJobs,
+			count: func(ctx context.Context) (int64, error) {
+				return s.store.CountExpiredJobs(ctx, before)
+			},
+			delete: func(ctx context.Context, limit int) (int64, error) {
+				return s.store.DeleteExpiredJobs(ctx, before, limit)
+			},
+		},
+		{
+			name:   models.RetentionPhaseBlueprintRevisions,
+			count:  s.store.CountOrphanedBlueprintRevisions,
+			delete: s.store.DeleteOrphanedBlueprintRevisions,
+		},
+		{
+			name:   models.RetentionPhaseBidRevisions,
+			count:  s.store.CountOrphanedBidRevisions,
+			delete: s.store.DeleteOrphanedBidRevisions,
+		},
+		{
+			name: models.RetentionPhaseDrafts,
+			count: func(ctx context.Context) (int64, error) {
+				return s.store.CountExpiredDrafts(ctx, now)
+			},
+			delete: func(ctx context.Context, limit int) (int64, error) {
+				return s.store.DeleteExpiredDrafts(ctx, now, limit)
+			},
+		},
+	}
+}
+
+// runPhase counts a phase's rows for a dry run, otherwise deletes them a
+// batch at a time
+func (s *RetentionSweeper) runPhase(ctx context.Context, phase retentionPhase, dryRun bool) models.RetentionPhaseResult {
+	result := models.RetentionPhaseResult{Phase: phase.name}
+	if dryRun {
+		count, err := phase.count(ctx)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Rows = count
+		return result
+	}
+
+	batchSize := s.cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	for {
+		deleted, err := phase.delete(ctx, batchSize)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if deleted > 0 {
+			result.Rows += deleted
+			result.Batches++
+		}
+		if deleted < int64(batchSize) || ctx.Err() != nil {
+			return result
+		}
+	}
+}
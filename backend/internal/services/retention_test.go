@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// fakeRetentionStore holds the ages of finished jobs and counts of orphaned
+// rows, and records the limit of every delete
+type fakeRetentionStore struct {
+	jobs               []time.Time
+	blueprintRevisions int64
+	bidRevisions       int64
+	drafts             int64
+	draftsErr          error
+	limits             []int
+}
+
+func (f *fakeRetentionStore) expiredJobs(before time.Time) []int {
+	var expired []int
+	for i, endedAt := range f.jobs {
+		if endedAt.Before(before) {
+			expired = append(expired, i)
+		}
+	}
+	return expired
+}
+
+func (f *fakeRetentionStore) CountExpiredJobs(ctx context.Context, before time.Time) (int64, error) {
+	return int64(len(f.expiredJobs(before))), nil
+}
+
+func (f *fakeRetentionStore) DeleteExpiredJobs(ctx context.Context, before time.Time, limit int) (int64, error) {
+	f.limits = append(f.limits, limit)
+	expired := f.expiredJobs(before)
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	for i := len(expired) - 1; i >= 0; i-- {
+		f.jobs = append(f.jobs[:expired[i]], f.jobs[expired[i]+1:]...)
+	}
+	return int64(len(expired)), nil
+}
+
+func (f *fakeRetentionStore) deleteCount(rows *int64, limit int) (int64, error) {
+	f.limits = append(f.limits, limit)
+	n := min(*rows, int64(limit))
+	*rows -= n
+	return n, nil
+}
+
+func (f *fakeRetentionStore) CountOrphanedBlueprintRevisions(ctx context.Context) (int64, error) {
+	return f.blueprintRevisions, nil
+}
+
+func (f *fakeRetentionStore) DeleteOrphanedBlueprintRevisions(ctx context.Context, limit int) (int64, error) {
+	return f.deleteCount(&f.blueprintRevisions, limit)
+}
+
+func (f *fakeRetentionStore) CountOrphanedBidRevisions(ctx context.Context) (int64, error) {
+	return f.bidRevisions, nil
+}
+
+func (f *fakeRetentionStore) DeleteOrphanedBidRevisions(ctx context.Context, limit int) (int64, error) {
+	return f.deleteCount(&f.bidRevisions, limit)
+}
+
+func (f *fakeRetentionStore) CountExpiredDrafts(ctx context.Context, now time.Time) (int64, error) {
+	return f.drafts, f.draftsErr
+}
+
+func (f *fakeRetentionStore) DeleteExpiredDrafts(ctx context.Context, now time.Time, limit int) (int64, error) {
+	if f.draftsErr != nil {
+		return 0, f.draftsErr
+	}
+	return f.deleteCount(&f.drafts, limit)
+}
+
+var retentionNow = time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC)
+
+func newTestRetentionSweeper(store *fakeRetentionStore) *RetentionSweeper {
+	sweeper := NewRetentionSweeper(store, config.RetentionConfig{
+		JobRetention: 90 * 24 * time.Hour,
+		Interval:     24 * time.Hour,
+		BatchSize:    2,
+	})
+	sweeper.now = func() time.Time { return retentionNow }
+	return sweeper
+}
+
+func phaseResult(t *testing.T, report *models.RetentionReport, phase string) models.RetentionPhaseResult {
+	t.Helper()
+	for _, result := range report.Phases {
+		if result.Phase == phase {
+			return result
+		}
+	}
+	t.Fatalf("report has no %s phase: %+v", phase, report.Phases)
+	return models.RetentionPhaseResult{}
+}
+
+func TestRetentionSweeper_Sweep(t *testing.T) {
+	store := &fakeRetentionStore{
+		jobs: []time.Time{
+			retentionNow.Add(-200 * 24 * time.Hour),
+			retentionNow.Add(-100 * 24 * time.Hour),
+			retentionNow.Add(-91 * 24 * time.Hour),
+			retentionNow.Add(-89 * 24 * time.Hour),
+			retentionNow.Add(-time.Hour),
+		},
+		blueprintRevisions: 4,
+		bidRevisions:       1,
+	}
+
+	report := newTestRetentionSweeper(store).Sweep(context.Background(), "worker", false)
+
+	if len(store.jobs) != 2 {
+		t.Errorf("expected the 2 jobs inside retention to remain, %d remain", len(store.jobs))
+	}
+	jobs := phaseResult(t, report, models.RetentionPhaseJobs)
+	if jobs.Rows != 3 || jobs.Batches != 2 {
+		t.Errorf("jobs phase = %+v, want 3 rows in 2 batches", jobs)
+	}
+	// An exactly full batch needs one more, empty, batch to know it is done
+	revisions := phaseResult(t, report, models.RetentionPhaseBlueprintRevisions)
+	if revisions.Rows != 4 || revisions.Batches != 2 || store.blueprintRevisions != 0 {
+		t.Errorf("blueprint revision phase = %+v with %d left, want 4 rows in 2 batches", revisions, store.blueprintRevisions)
+	}
+	if bids := phaseResult(t, report, models.RetentionPhaseBidRevisions); bids.Rows != 1 || bids.Batches != 1 {
+		t.Errorf("bid revision phase = %+v, want 1 row in 1 batch", bids)
+	}
+	if drafts := phaseResult(t, report, models.RetentionPhaseDrafts); drafts.Rows != 0 || drafts.Batches != 0 {
+		t.Errorf("drafts phase = %+v, want nothing deleted", drafts)
+	}
+	for _, limit := range store.limits {
+		if limit != 2 {
+			t.Fatalf("delete called with limit %d, want the batch size 2", limit)
+		}
+	}
+	if !report.JobsBefore.Time.Equal(retentionNow.Add(-90 * 24 * time.Hour)) {
+		t.Errorf("JobsBefore = %v, want 90 days before now", report.JobsBefore)
+	}
+}
+
+func TestRetentionSweeper_DryRun(t *testing.T) {
+	store := &fakeRetentionStore{
+		jobs:               []time.Time{retentionNow.Add(-100 * 24 * time.Hour), retentionNow},
+		blueprintRevisions: 3,
+		drafts:             5,
+	}
+
+	report := newTestRetentionSweeper(store).Sweep(context.Background(), "admin", true)
+
+	if !report.DryRun || len(store.limits) != 0 {
+		t.Fatalf("dry run deleted rows: %d delete calls", len(store.limits))
+	}
+	if len(store.jobs) != 2 || store.blueprintRevisions != 3 || store.drafts != 5 {
+		t.Error("dry run changed the store")
+	}
+	if jobs := phaseResult(t, report, models.RetentionPhaseJobs); jobs.Rows != 1 {
+		t.Errorf("dry run jobs = %d, want 1", jobs.Rows)
+	}
+	if drafts := phaseResult(t, report, models.RetentionPhaseDrafts); drafts.Rows != 5 {
+		t.Errorf("dry run drafts = %d, want 5", drafts.Rows)
+	}
+}
+
+func TestRetentionSweeper_FailedPhaseDoesNotStopOthers(t *testing.T) {
+	store := &fakeRetentionStore{bidRevisions: 1, draftsErr: errors.New("connection reset")}
+
+	report := newTestRetentionSweeper(store).Sweep(context.Background(), "worker", false)
+
+	if drafts := phaseResult(t, report, models.RetentionPhaseDrafts); drafts.Error == "" {
+		t.Error("expected the drafts phase to report its error")
+	}
+	if store.bidRevisions != 0 {
+		t.Error("expected the other phases to still run")
+	}
+}
+
+func TestRetentionSweeper_RunIfDue(t *testing.T) {
+	store := &fakeRetentionStore{}
+	sweeper := newTestRetentionSweeper(store)
+	ctx := context.Background()
+
+	sweeper.RunIfDue(ctx)
+	sweeps := len(store.limits)
+	if sweeps == 0 {
+		t.Fatal("expected the first poll to sweep")
+	}
+
+	sweeper.now = func() time.Time { return retentionNow.Add(23 * time.Hour) }
+	sweeper.RunIfDue(ctx)
+	if len(store.limits) != sweeps {
+		t.Error("expected no sweep within the interval")
+	}
+
+	sweeper.now = func() time.Time { return retentionNow.Add(24 * time.Hour) }
+	sweeper.RunIfDue(ctx)
+	if len(store.limits) != 2*sweeps {
+		t.Error("expected a sweep once the interval passed")
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// ReviewReasonUnrecognized is why a placeholder line item is flagged
+const ReviewReasonUnrecognized = "Found in the blueprint analysis but not recognized by pricing; price by hand"
+
+// fixtureRecognized reports whether pricing prices a fixture. Fixtures in a
+// known discipline are priced per unit, as uncategorized fixtures always
+// have been; other categories have no price.
+func fixtureRecognized(fixture models.Fixture) bool {
+	category := strings.ToLower(strings.TrimSpace(fixture.Category))
+	if category == "" {
+		return true
+	}
+	_, ok := fixtureDisciplines[category]
+	return ok
+}
+
+// unrecognizedTally totals counts by type, grouping types that differ only
+// in case or surrounding space under the first spelling seen
+type unrecognizedTally struct {
+	items []models.UnrecognizedItem
+	index map[string]int
+}
+
+func (t *unrecognizedTally) add(itemType string, count int) {
+	itemType = strings.TrimSpace(itemType)
+	if itemType == "" {
+		itemType = "unspecified"
+	}
+	key := strings.ToLower(itemType)
+	if i, ok := t.index[key]; ok {
+		t.items[i].Count += count
+		return
+	}
+	if t.index == nil {
+		t.index = make(map[string]int)
+	}
+	t.index[key] = len(t.items)
+	t.items = append(t.items, models.UnrecognizedItem{Type: itemType, Count: count})
+}
+
+// sorted returns the totals ordered by type, nil when there are none
+func (t *unrecognizedTally) sorted() []models.UnrecognizedItem {
+	sort.Slice(t.items, func(i, j int) bool {
+		return strings.ToLower(t.items[i].Type) < strings.ToLower(t.items[j].Type)
+	})
+	return t.items
+}
+
+// UnrecognizedOpenings totals the openings pricing has no price for, such as
+// skylights, by opening type
+func UnrecognizedOpenings(openings []models.Opening) []models.UnrecognizedItem {
+	var tally unrecognizedTally
+	for _, opening := range openings {
+		if openingClass(opening) == models.OpeningClassOther && opening.Count > 0 {
+			tally.add(opening.OpeningType, opening.Count)
+		}
+	}
+	return tally.sorted()
+}
+
+// UnrecognizedFixtures totals the fixtures in categories pricing has no
+// price for by fixture type, or by category when the type is blank
+func UnrecognizedFixtures(fixtures []models.Fixture) []models.UnrecognizedItem {
+	var tally unrecognizedTally
+	for _, fixture := range fixtures {
+		if fixtureRecognized(fixture) || fixture.Count <= 0 {
+			continue
+		}
+		itemType := fixture.FixtureType
+		if strings.TrimSpace(itemType) == "" {
+			itemType = fixture.Category
+		}
+		tally.add(itemType, fixture.Count)
+	}
+	return tally.sorted()
+}
+
+// addUnrecognizedItems lists the analysis's unrecognized openings and
+// fixtures on the takeoff summary
+func addUnrecognizedItems(summary *models.TakeoffSummary, analysis *models.AnalysisResult) {
+	summary.UnrecognizedOpenings = UnrecognizedOpenings(analysis.Openings)
+	summary.UnrecognizedFixtures = UnrecognizedFixtures(analysis.Fixtures)
+}
+
+// buildReviewItems returns a zero-cost placeholder line item, flagged for
+// review, per unrecognized opening and fixture type so the work still shows
+// on the bid rather than vanishing from it
+func buildReviewItems(analysis *models.AnalysisResult) []models.LineItem {
+	if analysis == nil {
+		return nil
+	}
+	var items []models.LineItem
+	placeholder := func(kind, trade string, item models.UnrecognizedItem) models.LineItem {
+		return models.LineItem{
+			Description: fmt.Sprintf("Unrecognized %s - %s", kind, item.Type),
+			Trade:       trade,
+			Quantity:    float64(item.Count),
+			Unit:        "each",
+			NeedsReview: true,
+		}
+	}
+	for _, opening := range UnrecognizedOpenings(analysis.Openings) {
+		items = append(items, placeholder("opening", "carpentry", opening))
+	}
+	for _, fixture := range UnrecognizedFixtures(analysis.Fixtures) {
+		items = append(items, placeholder("fixture", "general", fixture))
+	}
+	return items
+}
+
+// FlagLineItemsForReview flags the bid's copies of the pricing summary's
+// review placeholders, adds the placeholders its line items left out and
+// lists every flagged item in the bid's review flags. It reports whether the
+// bid changed.
+func FlagLineItemsForReview(bid *models.GenerateBidResponse, summary *models.PricingSummary) bool {
+	changed := false
+	if summary != nil {
+		review := make(map[string]bool)
+		for _, item := range summary.LineItems {
+			if item.NeedsReview {
+				review[repriceKey(item)] = true
+			}
+		}
+		for i := range bid.LineItems {
+			item := &bid.LineItems[i]
+			key := repriceKey(*item)
+			if !review[key] {
+				continue
+			}
+			delete(review, key)
+			if !item.NeedsReview {
+				item.NeedsReview = true
+				changed = true
+			}
+		}
+		for _, item := range summary.LineItems {
+			if item.NeedsReview && review[repriceKey(item)] {
+				bid.LineItems = append(bid.LineItems, item)
+				changed = true
+			}
+		}
+	}
+
+	var flags []models.ReviewFlag
+	for _, item := range bid.LineItems {
+		if item.NeedsReview {
+			flags = append(flags, models.ReviewFlag{
+				LineItem: item.Description,
+				Trade:    item.Trade,
+				Reason:   ReviewReasonUnrecognized,
+			})
+		}
+	}
+	if !slices.Equal(flags, bid.ReviewFlags) {
+		bid.ReviewFlags = flags
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestPriceTakeoff_UnrecognizedItemsNeedReview(t *testing.T) {
+	analysis := &models.AnalysisResult{
+		Openings: []models.Opening{
+			{OpeningType: "window", Count: 4},
+			{OpeningType: "Skylight", Count: 2},
+			{OpeningType: "skylight ", Count: 1},
+		},
+		Fixtures: []models.Fixture{
+			{FixtureType: "outlet", Category: "electrical", Count: 10},
+			{FixtureType: "sprinkler head", Category: "fire_protection", Count: 12},
+		},
+	}
+
+	takeoff := PricingTakeoff(analysis)
+	if got := takeoff.UnrecognizedOpenings; len(got) != 1 || got[0].Type != "Skylight" || got[0].Count != 3 {
+		t.Errorf("unrecognized openings = %+v, want 3 skylights", got)
+	}
+	if got := takeoff.UnrecognizedFixtures; len(got) != 1 || got[0].Type != "sprinkler head" || got[0].Count != 12 {
+		t.Errorf("unrecognized fixtures = %+v, want 12 sprinkler heads", got)
+	}
+
+	summary, err := NewPricingService().GeneratePricingSummary(takeoff, analysis, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	items := lineItemsByDescription(summary)
+	skylight, ok := items["Unrecognized opening - Skylight"]
+	if !ok || !skylight.NeedsReview || skylight.Quantity != 3 || skylight.Total != 0 {
+		t.Errorf("skylight item = %+v (found %v), want a zero-cost placeholder for 3 flagged for review", skylight, ok)
+	}
+	if sprinkler := items["Unrecognized fixture - sprinkler head"]; !sprinkler.NeedsReview {
+		t.Errorf("sprinkler item = %+v, want it flagged for review", sprinkler)
+	}
+	// Sprinklers are no longer priced as outlets
+	if outlets := items["Electrical fixtures and outlets"]; outlets.Quantity != 10 {
+		t.Errorf("outlet quantity = %v, want 10", outlets.Quantity)
+	}
+	if _, charged := items["Minimum service charge - general"]; charged {
+		t.Error("a placeholder brought on a minimum charge")
+	}
+
+	// A bid that left the placeholders out gets them back, with review flags
+	bid := PricedBidResponse(summary, 20)
+	bid.LineItems = bid.LineItems[:0]
+	if !FlagLineItemsForReview(&bid, summary) {
+		t.Fatal("FlagLineItemsForReview reported no change")
+	}
+	if len(bid.LineItems) != 2 || len(bid.ReviewFlags) != 2 {
+		t.Fatalf("bid items = %+v, flags = %+v; want both placeholders flagged", bid.LineItems, bid.ReviewFlags)
+	}
+	if flag := bid.ReviewFlags[0]; flag.LineItem != "Unrecognized opening - Skylight" || flag.Reason != ReviewReasonUnrecognized {
+		t.Errorf("first flag = %+v, want the skylight", flag)
+	}
+}
+
+func TestFlagLineItemsForReview_MarksCopiedPlaceholders(t *testing.T) {
+	summary := &models.PricingSummary{LineItems: []models.LineItem{
+		{Description: "Unrecognized opening - skylight", Trade: "carpentry", Quantity: 1, Unit: "each", NeedsReview: true},
+	}}
+	// The AI copied the placeholder without its flag
+	bid := &models.GenerateBidResponse{LineItems: []models.LineItem{
+		{Description: "Unrecognized Opening - Skylight", Trade: "carpentry", Quantity: 1, Unit: "each"},
+	}}
+
+	if !FlagLineItemsForReview(bid, summary) {
+		t.Fatal("FlagLineItemsForReview reported no change")
+	}
+	if len(bid.LineItems) != 1 || !bid.LineItems[0].NeedsReview || len(bid.ReviewFlags) != 1 {
+		t.Errorf("bid items = %+v, flags = %+v; want the copy flagged, not duplicated", bid.LineItems, bid.ReviewFlags)
+	}
+	if FlagLineItemsForReview(bid, summary) {
+		t.Error("flagging again reported a change")
+	}
+}
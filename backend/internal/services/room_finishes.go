@@ -0,0 +1,165 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// defaultFinishLaborSplit is the labor share used for finishes without a
+// configured split; it matches the legacy blanket flooring split.
+const defaultFinishLaborSplit = 0.3
+
+// DefaultFinishLaborSplits returns the labor share of installed cost per finish
+func DefaultFinishLaborSplits() map[string]float64 {
+	return map[string]float64{
+		string(models.FloorFinishTile):     0.45,
+		string(models.FloorFinishLVP):      0.30,
+		string(models.FloorFinishCarpet):   0.25,
+		string(models.FloorFinishHardwood): 0.40,
+	}
+}
+
+// DefaultRoomTypeFinishes returns the finish assumed for unassigned rooms by room type
+func DefaultRoomTypeFinishes() map[string]models.FloorFinish {
+	return map[string]models.FloorFinish{
+		"bathroom": models.FloorFinishTile,
+		"kitchen":  models.FloorFinishTile,
+		"laundry":  models.FloorFinishTile,
+		"bedroom":  models.FloorFinishCarpet,
+	}
+}
+
+var floorFinishLabels = map[models.FloorFinish]string{
+	models.FloorFinishTile:     "Tile",
+	models.FloorFinishLVP:      "LVP",
+	models.FloorFinishCarpet:   "Carpet",
+	models.FloorFinishHardwood: "Hardwood",
+}
+
+// FinishMaterialKey returns the materials catalog key for a floor finish
+func FinishMaterialKey(finish models.FloorFinish) string {
+	return "flooring_" + string(finish)
+}
+
+// NormalizeRoomName folds a room name to a comparison key: lowercased, with
+// punctuation treated as whitespace and runs of whitespace collapsed, so
+// "Bath #1", "bath 1" and "BATH-1" all match.
+func NormalizeRoomName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// ValidateRoomFinishes checks a room name -> finish map, rejecting unknown
+// finish keys and names that collide once normalized
+func ValidateRoomFinishes(finishes map[string]models.FloorFinish) error {
+	seen := make(map[string]string, len(finishes))
+	for name, finish := range finishes {
+		key := NormalizeRoomName(name)
+		if key == "" {
+			return fmt.Errorf("room name must not be empty")
+		}
+		if !finish.IsValid() {
+			return fmt.Errorf("unknown finish %q for room %q: must be one of tile, lvp, carpet, hardwood, none", finish, name)
+		}
+		if other, exists := seen[key]; exists {
+			return fmt.Errorf("room names %q and %q refer to the same room", other, name)
+		}
+		seen[key] = name
+	}
+	return nil
+}
+
+// ApplyRoomFinishes sets the explicit finish on each takeoff room whose
+// normalized name has an assignment
+func ApplyRoomFinishes(takeoff *models.TakeoffSummary, finishes map[string]models.FloorFinish) {
+	if takeoff == nil || len(finishes) == 0 {
+		return
+	}
+
+	byName := make(map[string]models.FloorFinish, len(finishes))
+	for name, finish := range finishes {
+		byName[NormalizeRoomName(name)] = finish
+	}
+
+	for i := range takeoff.RoomBreakdown {
+		if finish, ok := byName[NormalizeRoomName(takeoff.RoomBreakdown[i].Name)]; ok {
+			f := finish
+			takeoff.RoomBreakdown[i].Finish = &f
+		}
+	}
+}
+
+// resolveRoomFinish returns the finish for a room: the explicit selection,
+// else the configured default for its room type. ok is false when neither
+// applies and the room falls back to blanket flooring.
+func resolveRoomFinish(room models.RoomSummary, roomTypeFinishes map[string]models.FloorFinish) (models.FloorFinish, bool) {
+	if room.Finish != nil {
+		return *room.Finish, true
+	}
+	if room.RoomType != nil {
+		if finish, ok := roomTypeFinishes[NormalizeRoomName(*room.RoomType)]; ok {
+			return finish, true
+		}
+	}
+	return "", false
+}
+
+// buildFlooringItems emits one flooring line item per finish, summing the
+// area of the rooms assigned to it. Rooms with no finish are priced at the
+// blanket flooring rate, and rooms marked "none" are skipped. sources is
+// optional and attaches price provenance to each item.
+func buildFlooringItems(takeoff *models.TakeoffSummary, config *models.PricingConfig, sources *ResolvedPricingConfig) []installedWork {
+	roomTypeFinishes := config.RoomTypeFinishes
+	if roomTypeFinishes == nil {
+		roomTypeFinishes = DefaultRoomTypeFinishes()
+	}
+
+	areaByFinish := make(map[models.FloorFinish]float64)
+	unassignedArea := 0.0
+	if len(takeoff.RoomBreakdown) == 0 {
+		unassignedArea = takeoff.TotalArea
+	}
+	for _, room := range takeoff.RoomBreakdown {
+		finish, ok := resolveRoomFinish(room, roomTypeFinishes)
+		if !ok {
+			unassignedArea += room.Area
+			continue
+		}
+		areaByFinish[finish] += room.Area
+	}
+
+	var items []installedWork
+
+	addItem := func(description, priceKey string, area, laborSplit float64) {
+		if area <= 0 {
+			return
+		}
+		items = append(items, installWork(models.LineItem{
+			Description: description,
+			Trade:       "general",
+			Quantity:    area,
+			Unit:        "sq ft",
+			PriceSource: sources.materialSource(priceKey),
+		}, config.MaterialPrices[priceKey], laborSplit, config, priceKey, "flooring"))
+	}
+
+	for _, finish := range models.FloorFinishes {
+		priceKey := FinishMaterialKey(finish)
+		if _, ok := config.MaterialPrices[priceKey]; !ok {
+			priceKey = "flooring"
+		}
+		laborSplit, ok := config.FinishLaborSplits[string(finish)]
+		if !ok {
+			laborSplit = defaultFinishLaborSplit
+		}
+		addItem("Flooring installation - "+floorFinishLabels[finish], priceKey, areaByFinish[finish], laborSplit)
+	}
+	addItem("Flooring installation", "flooring", unassignedArea, defaultFinishLaborSplit)
+
+	return items
+}
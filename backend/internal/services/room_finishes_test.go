@@ -0,0 +1,145 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func finishTakeoff() *models.TakeoffSummary {
+	rooms := []models.RoomSummary{
+		{Name: "Master Bath", RoomType: strPtr("bathroom"), Area: 80},
+		{Name: "Bedroom 2", RoomType: strPtr("bedroom"), Area: 150},
+		{Name: "Living Room", RoomType: strPtr("living"), Area: 300},
+		{Name: "Garage", Area: 400},
+	}
+	takeoff := &models.TakeoffSummary{RoomBreakdown: rooms, RoomCount: len(rooms)}
+	for _, room := range rooms {
+		takeoff.TotalArea += room.Area
+	}
+	return takeoff
+}
+
+func flooringItemsByDescription(t *testing.T, summary *models.PricingSummary) map[string]models.LineItem {
+	t.Helper()
+	items := make(map[string]models.LineItem)
+	for _, item := range summary.LineItems {
+		if strings.HasPrefix(item.Description, "Flooring installation") {
+			items[item.Description] = item
+		}
+	}
+	return items
+}
+
+func TestNormalizeRoomName(t *testing.T) {
+	for _, name := range []string{"Bath #1", "bath 1", "BATH-1", "  Bath   1 "} {
+		if got := NormalizeRoomName(name); got != "bath 1" {
+			t.Errorf("NormalizeRoomName(%q) = %q, want %q", name, got, "bath 1")
+		}
+	}
+}
+
+func TestRoomFinishes_ExplicitAssignments(t *testing.T) {
+	service := NewPricingService()
+	takeoff := finishTakeoff()
+
+	ApplyRoomFinishes(takeoff, map[string]models.FloorFinish{
+		"living-room": models.FloorFinishHardwood,
+		"BEDROOM 2":   models.FloorFinishLVP,
+		"garage":      models.FloorFinishNone,
+	})
+
+	summary, err := service.GeneratePricingSummary(takeoff, nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+
+	items := flooringItemsByDescription(t, summary)
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 flooring items, got %d: %+v", len(items), items)
+	}
+
+	hardwood := items["Flooring installation - Hardwood"]
+	if hardwood.Quantity != 300 || hardwood.UnitCost != 11.00 || hardwood.Total != 3300 {
+		t.Errorf("Unexpected hardwood item: %+v", hardwood)
+	}
+	lvp := items["Flooring installation - LVP"]
+	if lvp.Quantity != 150 || lvp.Total != 975 {
+		t.Errorf("Unexpected LVP item: %+v", lvp)
+	}
+	// Bath has no explicit selection, so it defaults to tile by room type
+	tile := items["Flooring installation - Tile"]
+	if tile.Quantity != 80 || tile.Total != 960 {
+		t.Errorf("Unexpected tile item: %+v", tile)
+	}
+	if _, ok := items["Flooring installation"]; ok {
+		t.Error("Garage is marked none and should not be priced")
+	}
+}
+
+func TestRoomFinishes_DefaultsByRoomType(t *testing.T) {
+	service := NewPricingService()
+
+	summary, err := service.GeneratePricingSummary(finishTakeoff(), nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+
+	items := flooringItemsByDescription(t, summary)
+	if items["Flooring installation - Tile"].Quantity != 80 {
+		t.Errorf("Expected bathroom to default to tile, got %+v", items)
+	}
+	if items["Flooring installation - Carpet"].Quantity != 150 {
+		t.Errorf("Expected bedroom to default to carpet, got %+v", items)
+	}
+	// Living room and garage have no default and use the blanket rate
+	blanket := items["Flooring installation"]
+	if blanket.Quantity != 700 || blanket.UnitCost != 8.50 {
+		t.Errorf("Unexpected blanket flooring item: %+v", blanket)
+	}
+}
+
+func TestRoomFinishes_ConfigurableRoomTypeDefaults(t *testing.T) {
+	service := NewPricingService()
+	config := *service.GetDefaultPricingConfig()
+	config.RoomTypeFinishes = map[string]models.FloorFinish{"living": models.FloorFinishLVP}
+	config.FinishLaborSplits = map[string]float64{"lvp": 0.5}
+
+	summary, err := service.GeneratePricingSummary(finishTakeoff(), nil, &config)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+
+	items := flooringItemsByDescription(t, summary)
+	if items["Flooring installation - LVP"].Quantity != 300 {
+		t.Errorf("Expected living room to use configured LVP default, got %+v", items)
+	}
+	if _, ok := items["Flooring installation - Tile"]; ok {
+		t.Error("Built-in bathroom default should not apply when defaults are configured")
+	}
+	if items["Flooring installation"].Quantity != 630 {
+		t.Errorf("Expected remaining rooms on blanket flooring, got %+v", items["Flooring installation"])
+	}
+}
+
+func TestValidateRoomFinishes(t *testing.T) {
+	if err := ValidateRoomFinishes(map[string]models.FloorFinish{
+		"Kitchen": models.FloorFinishTile,
+		"Den":     models.FloorFinishNone,
+	}); err != nil {
+		t.Errorf("Expected valid finishes, got %v", err)
+	}
+
+	err := ValidateRoomFinishes(map[string]models.FloorFinish{"Kitchen": "marble"})
+	if err == nil || !strings.Contains(err.Error(), "unknown finish") {
+		t.Errorf("Expected unknown finish error, got %v", err)
+	}
+
+	if err := ValidateRoomFinishes(map[string]models.FloorFinish{
+		"Bath 1": models.FloorFinishTile,
+		"bath-1": models.FloorFinishLVP,
+	}); err == nil {
+		t.Error("Expected names that normalize to the same room to be rejected")
+	}
+}
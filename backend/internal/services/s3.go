@@ -4,19 +4,47 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/url"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
 )
 
+// s3Client is the part of the S3 API the service uses
+type s3Client interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error)
+}
+
+// ArtifactKind selects the storage class an object is written with
+type ArtifactKind string
+
+const (
+	// ArtifactBlueprint is an uploaded or copied blueprint file
+	ArtifactBlueprint ArtifactKind = "blueprint"
+	// ArtifactDocument is a generated PDF in current use
+	ArtifactDocument ArtifactKind = "document"
+	// ArtifactArchive is a superseded PDF or archived copy kept for reference
+	ArtifactArchive ArtifactKind = "archive"
+)
+
 type S3Service struct {
-	client *s3.Client
-	config *config.S3Config
+	client    s3Client
+	presigner *s3.PresignClient
+	config    *config.S3Config
 }
 
 func NewS3Service(cfg *config.Config) (*S3Service, error) {
@@ -54,21 +82,65 @@ func NewS3Service(cfg *config.Config) (*S3Service, error) {
 	slog.Info("S3 service initialized",
 		"endpoint", cfg.S3.Endpoint,
 		"bucket", cfg.S3.Bucket,
-		"region", cfg.S3.Region)
+		"region", cfg.S3.Region,
+		"sse", cfg.S3.SSE,
+		"storage_class", cfg.S3.StorageClass,
+		"archive_storage_class", cfg.S3.ArchiveStorageClass)
 
 	return &S3Service{
-		client: client,
-		config: &cfg.S3,
+		client:    client,
+		presigner: s3.NewPresignClient(client),
+		config:    &cfg.S3,
 	}, nil
 }
 
-func (s *S3Service) GeneratePresignedUploadURL(ctx context.Context, key string, contentType string) (string, error) {
-	presignClient := s3.NewPresignClient(s.client)
+// StorageClass returns the storage class objects of a kind are written with
+func (s *S3Service) StorageClass(kind ArtifactKind) string {
+	if kind == ArtifactArchive {
+		return s.config.ArchiveStorageClass
+	}
+	return s.config.StorageClass
+}
 
-	request, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s.config.Bucket),
-		Key:         aws.String(key),
-		ContentType: aws.String(contentType),
+// sseParams returns the encryption settings for a write, zero when disabled
+func (s *S3Service) sseParams() (types.ServerSideEncryption, *string) {
+	if s.config.SSE == "" {
+		return "", nil
+	}
+	if s.config.SSE == string(types.ServerSideEncryptionAwsKms) && s.config.KMSKeyID != "" {
+		return types.ServerSideEncryption(s.config.SSE), aws.String(s.config.KMSKeyID)
+	}
+	return types.ServerSideEncryption(s.config.SSE), nil
+}
+
+// BlueprintUploadHeaders returns the headers a client must send with the
+// presigned blueprint upload, since they are part of its signature
+func (s *S3Service) BlueprintUploadHeaders() map[string]string {
+	headers := map[string]string{}
+	if class := s.StorageClass(ArtifactBlueprint); class != "" {
+		headers["x-amz-storage-class"] = class
+	}
+	if sse, kmsKeyID := s.sseParams(); sse != "" {
+		headers["x-amz-server-side-encryption"] = string(sse)
+		if kmsKeyID != nil {
+			headers["x-amz-server-side-encryption-aws-kms-key-id"] = *kmsKeyID
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+func (s *S3Service) GeneratePresignedUploadURL(ctx context.Context, key string, contentType string) (string, error) {
+	sse, kmsKeyID := s.sseParams()
+	request, err := s.presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(s.config.Bucket),
+		Key:                  aws.String(key),
+		ContentType:          aws.String(contentType),
+		StorageClass:         types.StorageClass(s.StorageClass(ArtifactBlueprint)),
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
 	}, func(opts *s3.PresignOptions) {
 		opts.Expires = s.config.PresignExpiry
 	})
@@ -81,6 +153,22 @@ func (s *S3Service) GeneratePresignedUploadURL(ctx context.Context, key string,
 }
 
 func (s *S3Service) ObjectExists(ctx context.Context, key string) (bool, int64, error) {
+	stat, err := s.StatObject(ctx, key)
+	if err != nil || stat == nil {
+		return false, 0, err
+	}
+	return true, stat.Size, nil
+}
+
+// ObjectStat is an object's size, checksum and storage class
+type ObjectStat struct {
+	Size         int64
+	ETag         string // Quotes stripped; the MD5 of single-part uploads
+	StorageClass string
+}
+
+// StatObject returns an object's size and ETag, or nil when it does not exist
+func (s *S3Service) StatObject(ctx context.Context, key string) (*ObjectStat, error) {
 	result, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(s.config.Bucket),
 		Key:    aws.String(key),
@@ -91,18 +179,126 @@ func (s *S3Service) ObjectExists(ctx context.Context, key string) (bool, int64,
 		// For AWS SDK v2, NotFound errors contain "NotFound" in the error message
 		errStr := err.Error()
 		if strings.Contains(errStr, "NotFound") || strings.Contains(errStr, "404") {
-			return false, 0, nil
+			return nil, nil
 		}
 		// Return other errors (permissions, network, etc.)
-		return false, 0, fmt.Errorf("failed to check object existence: %w", err)
+		return nil, fmt.Errorf("failed to check object existence: %w", err)
 	}
 
-	fileSize := int64(0)
+	stat := &ObjectStat{}
 	if result.ContentLength != nil {
-		fileSize = *result.ContentLength
+		stat.Size = *result.ContentLength
+	}
+	if result.ETag != nil {
+		stat.ETag = strings.Trim(*result.ETag, `"`)
+	}
+	// HEAD omits the storage class of STANDARD objects
+	stat.StorageClass = string(result.StorageClass)
+	if stat.StorageClass == "" {
+		stat.StorageClass = string(types.StorageClassStandard)
+	}
+
+	return stat, nil
+}
+
+// GeneratePresignedDownloadURL returns a time-limited GET link to an object
+func (s *S3Service) GeneratePresignedDownloadURL(ctx context.Context, key string) (string, error) {
+	request, err := s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = s.config.PresignExpiry
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned download URL: %w", err)
+	}
+
+	return request.URL, nil
+}
+
+// DownloadFile reads an object from S3
+func (s *S3Service) DownloadFile(ctx context.Context, key string) ([]byte, error) {
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+// OpenFile returns a stream of an object's contents; the caller must close it
+func (s *S3Service) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return result.Body, nil
+}
+
+// DeleteFile removes an object from S3
+func (s *S3Service) DeleteFile(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
 	}
 
-	return true, fileSize, nil
+	slog.Info("File deleted from S3", "key", key)
+	return nil
+}
+
+// CopyObject copies a blueprint file to a new key within the bucket. Copies
+// do not inherit the source's encryption or storage class, so both are set.
+func (s *S3Service) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	if err := s.copyAs(ctx, srcKey, dstKey, ArtifactBlueprint); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	slog.Info("File copied in S3", "source_key", srcKey, "key", dstKey)
+	return nil
+}
+
+// ArchiveObject moves an object to the archive storage class by copying it
+// onto itself
+func (s *S3Service) ArchiveObject(ctx context.Context, key string) error {
+	if err := s.copyAs(ctx, key, key, ArtifactArchive); err != nil {
+		return fmt.Errorf("failed to archive file: %w", err)
+	}
+
+	slog.Info("File archived in S3", "key", key, "storage_class", s.StorageClass(ArtifactArchive))
+	return nil
+}
+
+func (s *S3Service) copyAs(ctx context.Context, srcKey, dstKey string, kind ArtifactKind) error {
+	// CopySource is bucket/key and must be URL-encoded
+	source := (&url.URL{Path: s.config.Bucket + "/" + srcKey}).EscapedPath()
+	sse, kmsKeyID := s.sseParams()
+	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:               aws.String(s.config.Bucket),
+		Key:                  aws.String(dstKey),
+		CopySource:           aws.String(source),
+		MetadataDirective:    types.MetadataDirectiveCopy,
+		StorageClass:         types.StorageClass(s.StorageClass(kind)),
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
+	})
+	return err
 }
 
 func (s *S3Service) EnsureBucket(ctx context.Context) error {
@@ -129,26 +325,101 @@ func (s *S3Service) EnsureBucket(ctx context.Context) error {
 	return nil
 }
 
-// UploadFile uploads a file to S3 and returns the public URL
+// UploadFile uploads a generated document to S3 and returns the public URL
 func (s *S3Service) UploadFile(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	return s.UploadArtifact(ctx, ArtifactDocument, key, data, contentType)
+}
+
+// UploadArtifact uploads a file with the storage class for its kind and
+// returns the public URL
+func (s *S3Service) UploadArtifact(ctx context.Context, kind ArtifactKind, key string, data []byte, contentType string) (string, error) {
+	sse, kmsKeyID := s.sseParams()
 	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s.config.Bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String(contentType),
+		Bucket:               aws.String(s.config.Bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(data),
+		ContentType:          aws.String(contentType),
+		StorageClass:         types.StorageClass(s.StorageClass(kind)),
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
 	})
 
 	if err != nil {
 		return "", fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	// Generate URL
-	url := fmt.Sprintf("%s/%s/%s", s.config.Endpoint, s.config.Bucket, key)
+	url := s.ObjectURL(key)
+	slog.Info("File uploaded to S3", "key", key, "url", url)
+	return url, nil
+}
+
+// ObjectURL returns the public URL of the object at key
+func (s *S3Service) ObjectURL(key string) string {
 	if !s.config.UsePathStyle {
-		url = fmt.Sprintf("%s/%s", strings.Replace(s.config.Endpoint, "://", fmt.Sprintf("://%s.", s.config.Bucket), 1), key)
+		return fmt.Sprintf("%s/%s", strings.Replace(s.config.Endpoint, "://", fmt.Sprintf("://%s.", s.config.Bucket), 1), key)
 	}
+	return fmt.Sprintf("%s/%s/%s", s.config.Endpoint, s.config.Bucket, key)
+}
 
-	slog.Info("File uploaded to S3", "key", key, "url", url)
-	return url, nil
+
+// CheckStorageSettings writes a probe object the way blueprints and archived
+// PDFs are written and reads back how the bucket stored it. It returns a
+// description of each setting the bucket did not honor; an error means the
+// check itself could not run.
+func (s *S3Service) CheckStorageSettings(ctx context.Context) ([]string, error) {
+	key := "_storage-check/" + uuid.NewString()
+	if _, err := s.UploadArtifact(ctx, ArtifactBlueprint, key, []byte("storage check"), "text/plain"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.config.Bucket), Key: aws.String(key)}); err != nil {
+			slog.Warn("Failed to delete storage check object", "key", key, "error", err)
+		}
+	}()
+
+	var problems []string
+	check := func(kind ArtifactKind) error {
+		head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(s.config.Bucket), Key: aws.String(key)})
+		if err != nil {
+			return fmt.Errorf("failed to read storage check object: %w", err)
+		}
+		problems = append(problems, s.storageProblems(kind, head)...)
+		return nil
+	}
+
+	if err := check(ArtifactBlueprint); err != nil {
+		return nil, err
+	}
+	if s.StorageClass(ArtifactArchive) != s.StorageClass(ArtifactBlueprint) {
+		if err := s.ArchiveObject(ctx, key); err != nil {
+			return append(problems, fmt.Sprintf("archiving to %s failed: %v", s.StorageClass(ArtifactArchive), err)), nil
+		}
+		if err := check(ArtifactArchive); err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
 }
 
+// storageProblems compares how an object was stored with the settings for
+// its kind
+func (s *S3Service) storageProblems(kind ArtifactKind, head *s3.HeadObjectOutput) []string {
+	var problems []string
+
+	want, got := s.StorageClass(kind), string(head.StorageClass)
+	if got == "" {
+		got = string(types.StorageClassStandard)
+	}
+	if want != "" && got != want {
+		problems = append(problems, fmt.Sprintf("%s objects stored as %s, want %s", kind, got, want))
+	}
+
+	sse, kmsKeyID := s.sseParams()
+	if sse != "" && head.ServerSideEncryption != sse {
+		problems = append(problems, fmt.Sprintf("%s objects encrypted with %q, want %s", kind, head.ServerSideEncryption, sse))
+	}
+	if kmsKeyID != nil && (head.SSEKMSKeyId == nil || !strings.HasSuffix(*head.SSEKMSKeyId, *kmsKeyID)) {
+		problems = append(problems, fmt.Sprintf("%s objects not encrypted with KMS key %s", kind, *kmsKeyID))
+	}
+	return problems
+}
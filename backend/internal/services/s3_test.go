@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+)
+
+type storedS3Object struct {
+	storageClass types.StorageClass
+	sse          types.ServerSideEncryption
+	kmsKeyID     *string
+}
+
+// fakeS3Client records writes and reports them back from HEAD the way S3
+// does. A bucket that ignores settings stores everything as STANDARD without
+// encryption.
+type fakeS3Client struct {
+	ignoreSettings bool
+	puts           []*s3.PutObjectInput
+	copies         []*s3.CopyObjectInput
+	objects        map[string]storedS3Object
+}
+
+func (f *fakeS3Client) store(key string, class types.StorageClass, sse types.ServerSideEncryption, kmsKeyID *string) {
+	if f.objects == nil {
+		f.objects = make(map[string]storedS3Object)
+	}
+	if f.ignoreSettings {
+		class, sse, kmsKeyID = types.StorageClassStandard, "", nil
+	}
+	f.objects[key] = storedS3Object{storageClass: class, sse: sse, kmsKeyID: kmsKeyID}
+}
+
+func (f *fakeS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	f.puts = append(f.puts, params)
+	f.store(*params.Key, params.StorageClass, params.ServerSideEncryption, params.SSEKMSKeyId)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3Client) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	f.copies = append(f.copies, params)
+	f.store(*params.Key, params.StorageClass, params.ServerSideEncryption, params.SSEKMSKeyId)
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (f *fakeS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	object := f.objects[*params.Key]
+	head := &s3.HeadObjectOutput{ServerSideEncryption: object.sse, ContentLength: aws.Int64(13)}
+	if object.kmsKeyID != nil {
+		head.SSEKMSKeyId = aws.String("arn:aws:kms:us-east-1:123456789012:key/" + *object.kmsKeyID)
+	}
+	if object.storageClass != types.StorageClassStandard {
+		head.StorageClass = object.storageClass
+	}
+	return head, nil
+}
+
+func (f *fakeS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{}, nil
+}
+
+func (f *fakeS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(f.objects, *params.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3Client) HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func (f *fakeS3Client) CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+	return &s3.CreateBucketOutput{}, nil
+}
+
+func newTestS3Service(client *fakeS3Client, sse, kmsKeyID string) *S3Service {
+	return &S3Service{
+		client: client,
+		config: &config.S3Config{
+			Bucket:              "blueprints",
+			Endpoint:            "http://localhost:9000",
+			UsePathStyle:        true,
+			SSE:                 sse,
+			KMSKeyID:            kmsKeyID,
+			StorageClass:        "STANDARD",
+			ArchiveStorageClass: "STANDARD_IA",
+		},
+	}
+}
+
+func TestS3Service_StorageSettingsPerArtifactKind(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeS3Client{}
+	service := newTestS3Service(client, "aws:kms", "key-1234")
+
+	if _, err := service.UploadFile(ctx, "bids/p/b/bid.pdf", []byte("%PDF"), "application/pdf"); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	if err := service.CopyObject(ctx, "projects/a/blueprints/x/A-101.pdf", "projects/b/blueprints/y/A-101.pdf"); err != nil {
+		t.Fatalf("CopyObject() error = %v", err)
+	}
+	if err := service.ArchiveObject(ctx, "bids/p/b/bid.pdf"); err != nil {
+		t.Fatalf("ArchiveObject() error = %v", err)
+	}
+
+	put := client.puts[0]
+	if put.StorageClass != types.StorageClassStandard || put.ServerSideEncryption != types.ServerSideEncryptionAwsKms ||
+		aws.ToString(put.SSEKMSKeyId) != "key-1234" {
+		t.Errorf("upload sent class %q, SSE %q, key %q", put.StorageClass, put.ServerSideEncryption, aws.ToString(put.SSEKMSKeyId))
+	}
+
+	copied, archived := client.copies[0], client.copies[1]
+	if copied.StorageClass != types.StorageClassStandard || copied.ServerSideEncryption != types.ServerSideEncryptionAwsKms {
+		t.Errorf("blueprint copy sent class %q, SSE %q", copied.StorageClass, copied.ServerSideEncryption)
+	}
+	if archived.StorageClass != types.StorageClassStandardIa || archived.ServerSideEncryption != types.ServerSideEncryptionAwsKms ||
+		aws.ToString(archived.Key) != "bids/p/b/bid.pdf" || !strings.HasSuffix(aws.ToString(archived.CopySource), "/bids/p/b/bid.pdf") {
+		t.Errorf("archive sent %s -> %s as class %q, SSE %q", aws.ToString(archived.CopySource), aws.ToString(archived.Key),
+			archived.StorageClass, archived.ServerSideEncryption)
+	}
+
+	headers := service.BlueprintUploadHeaders()
+	if headers["x-amz-storage-class"] != "STANDARD" || headers["x-amz-server-side-encryption"] != "aws:kms" ||
+		headers["x-amz-server-side-encryption-aws-kms-key-id"] != "key-1234" {
+		t.Errorf("blueprint upload headers = %v", headers)
+	}
+
+	// Without SSE nothing asks for encryption
+	plain := newTestS3Service(&fakeS3Client{}, "", "")
+	if _, err := plain.UploadFile(ctx, "bids/p/b/bid.pdf", []byte("%PDF"), "application/pdf"); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	if put := plain.client.(*fakeS3Client).puts[0]; put.ServerSideEncryption != "" || put.SSEKMSKeyId != nil {
+		t.Errorf("upload without SSE sent %q, %v", put.ServerSideEncryption, put.SSEKMSKeyId)
+	}
+	if headers := plain.BlueprintUploadHeaders(); len(headers) != 1 || headers["x-amz-server-side-encryption"] != "" {
+		t.Errorf("blueprint upload headers without SSE = %v", headers)
+	}
+}
+
+func TestS3Service_CheckStorageSettings(t *testing.T) {
+	ctx := context.Background()
+
+	honoring := &fakeS3Client{}
+	problems, err := newTestS3Service(honoring, "AES256", "").CheckStorageSettings(ctx)
+	if err != nil || len(problems) != 0 {
+		t.Errorf("CheckStorageSettings() = %v, %v; want no problems", problems, err)
+	}
+	if len(honoring.objects) != 0 {
+		t.Errorf("expected the probe object to be deleted, %d left", len(honoring.objects))
+	}
+
+	ignoring := &fakeS3Client{ignoreSettings: true}
+	problems, err = newTestS3Service(ignoring, "aws:kms", "key-1234").CheckStorageSettings(ctx)
+	if err != nil {
+		t.Fatalf("CheckStorageSettings() error = %v", err)
+	}
+	joined := strings.Join(problems, "\n")
+	for _, want := range []string{
+		`blueprint objects encrypted with "", want aws:kms`,
+		"blueprint objects not encrypted with KMS key key-1234",
+		"archive objects stored as STANDARD, want STANDARD_IA",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("problems = %q, want %q", problems, want)
+		}
+	}
+	if len(ignoring.objects) != 0 {
+		t.Errorf("expected the probe object to be deleted, %d left", len(ignoring.objects))
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// sheetNumberPattern matches drawing numbers such as "E-101", "A2.01" or "P_301"
+// at the start of a filename and captures the discipline prefix
+var sheetNumberPattern = regexp.MustCompile(`^([A-Za-z]{1,2})[-_. ]?\d`)
+
+// disciplinePrefixes maps standard drawing set prefixes to sheet types
+var disciplinePrefixes = map[string]models.SheetType{
+	"A":  models.SheetTypeArchitectural,
+	"AD": models.SheetTypeArchitectural,
+	"E":  models.SheetTypeElectrical,
+	"EP": models.SheetTypeElectrical,
+	"EL": models.SheetTypeElectrical,
+	"P":  models.SheetTypePlumbing,
+	"PL": models.SheetTypePlumbing,
+	"M":  models.SheetTypeMechanical,
+	"MH": models.SheetTypeMechanical,
+	"H":  models.SheetTypeMechanical,
+}
+
+// InferSheetType determines a blueprint's discipline. An explicit value on the
+// blueprint wins, then the AI service's classification, then the filename.
+func InferSheetType(blueprint *models.Blueprint, analysis *models.AnalysisResult) models.SheetType {
+	if blueprint != nil && blueprint.SheetType != nil && *blueprint.SheetType != models.SheetTypeUnknown {
+		return *blueprint.SheetType
+	}
+
+	if analysis != nil && analysis.SheetType != nil {
+		if t := models.SheetType(strings.ToLower(*analysis.SheetType)); t.IsValid() && t != models.SheetTypeUnknown {
+			return t
+		}
+	}
+
+	if blueprint != nil {
+		return SheetTypeFromFilename(blueprint.Filename)
+	}
+	return models.SheetTypeUnknown
+}
+
+// SheetTypeFromFilename applies drawing-number heuristics to a filename
+func SheetTypeFromFilename(filename string) models.SheetType {
+	match := sheetNumberPattern.FindStringSubmatch(filepath.Base(filename))
+	if match == nil {
+		return models.SheetTypeUnknown
+	}
+
+	if t, ok := disciplinePrefixes[strings.ToUpper(match[1])]; ok {
+		return t
+	}
+	return models.SheetTypeUnknown
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestSheetTypeFromFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     models.SheetType
+	}{
+		{"E-101.pdf", models.SheetTypeElectrical},
+		{"e101 lighting.pdf", models.SheetTypeElectrical},
+		{"A2.01 Floor Plan.pdf", models.SheetTypeArchitectural},
+		{"P_301.pdf", models.SheetTypePlumbing},
+		{"M-201.pdf", models.SheetTypeMechanical},
+		{"uploads/A-101.pdf", models.SheetTypeArchitectural},
+		{"Floor Plan.pdf", models.SheetTypeUnknown},
+		{"X-101.pdf", models.SheetTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := SheetTypeFromFilename(tt.filename); got != tt.want {
+				t.Errorf("SheetTypeFromFilename(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferSheetType_Precedence(t *testing.T) {
+	electrical := models.SheetTypeElectrical
+	aiPlumbing := "Plumbing"
+
+	blueprint := &models.Blueprint{Filename: "A-101.pdf"}
+	analysis := &models.AnalysisResult{SheetType: &aiPlumbing}
+
+	if got := InferSheetType(blueprint, nil); got != models.SheetTypeArchitectural {
+		t.Errorf("expected filename heuristic, got %q", got)
+	}
+	if got := InferSheetType(blueprint, analysis); got != models.SheetTypePlumbing {
+		t.Errorf("expected AI classification to beat filename, got %q", got)
+	}
+
+	blueprint.SheetType = &electrical
+	if got := InferSheetType(blueprint, analysis); got != models.SheetTypeElectrical {
+		t.Errorf("expected explicit sheet type to win, got %q", got)
+	}
+}
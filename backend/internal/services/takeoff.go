@@ -3,14 +3,29 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"math"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
-type TakeoffService struct{}
+// DefaultCeilingHeight is the wall height in feet used when the analysis
+// reports no ceiling_height measurement
+const DefaultCeilingHeight = 8.0
+
+// DefaultUnmeasuredReviewFraction is the share of zero-area rooms above which
+// a takeoff is flagged for manual review
+const DefaultUnmeasuredReviewFraction = 0.25
+
+type TakeoffService struct {
+	// UnmeasuredReviewFraction overrides DefaultUnmeasuredReviewFraction when set
+	UnmeasuredReviewFraction float64
+}
 
 func NewTakeoffService() *TakeoffService {
-	return &TakeoffService{}
+	return &TakeoffService{UnmeasuredReviewFraction: DefaultUnmeasuredReviewFraction}
 }
 
 // CalculateTakeoffSummary computes deterministic takeoff summary from analysis data
@@ -27,36 +42,31 @@ func (s *TakeoffService) CalculateTakeoffSummary(analysis *models.AnalysisResult
 		FixtureBreakdown: make([]models.FixtureSummary, 0),
 	}
 
+	summary.CeilingHeight = takeoffCeilingHeight(analysis.Measurements)
+
 	// Calculate room totals
 	for _, room := range analysis.Rooms {
-		summary.TotalArea += room.Area
-		summary.RoomCount++
+		area := addTakeoffRoom(summary, room)
 
 		// Parse dimensions to calculate perimeter if possible
 		// Assuming dimensions are in format "WxL" or similar
 		// For now, we estimate perimeter as 2*(sqrt(area)*2) if dimensions not parseable
 		// In a production system, you'd parse dimensions more robustly
-		perimeter := estimatePerimeter(room.Area, room.Dimensions)
+		perimeter := estimatePerimeter(area, room.Dimensions)
 		summary.TotalPerimeter += perimeter
 
-		summary.RoomBreakdown = append(summary.RoomBreakdown, models.RoomSummary{
-			Name:       room.Name,
-			RoomType:   room.RoomType,
-			Area:       room.Area,
-			Dimensions: room.Dimensions,
-		})
+		addRoomWallArea(summary, room)
 	}
+	summary.WallArea = math.Round(summary.WallArea*100) / 100
 
-	// Count openings by type
-	for _, opening := range analysis.Openings {
-		summary.OpeningCounts[opening.OpeningType] += opening.Count
-
-		summary.OpeningBreakdown = append(summary.OpeningBreakdown, models.OpeningSummary{
-			OpeningType: opening.OpeningType,
-			Count:       opening.Count,
-			Size:        opening.Size,
-		})
+	fraction := s.UnmeasuredReviewFraction
+	if fraction <= 0 {
+		fraction = DefaultUnmeasuredReviewFraction
 	}
+	flagUnmeasuredRooms(summary, fraction)
+
+	// Count openings by type and build the door/window schedule
+	addTakeoffOpenings(summary, analysis.Openings)
 
 	// Count fixtures by category
 	for _, fixture := range analysis.Fixtures {
@@ -68,10 +78,232 @@ func (s *TakeoffService) CalculateTakeoffSummary(analysis *models.AnalysisResult
 			Count:       fixture.Count,
 		})
 	}
+	addUnrecognizedItems(summary, analysis)
 
 	return summary, nil
 }
 
+// addTakeoffRoom adds a room to the summary and returns the area used. OCR
+// errors can produce negative areas; those are clamped to 0 with a warning.
+// Zero-area rooms still count toward RoomCount but are listed as unmeasured.
+func addTakeoffRoom(summary *models.TakeoffSummary, room models.Room) float64 {
+	name := room.Name
+	if name == "" {
+		name = "(unnamed)"
+	}
+
+	area := room.Area
+	if area < 0 {
+		summary.Warnings = append(summary.Warnings,
+			fmt.Sprintf("Room %q has negative area %.2f SF; treated as 0", name, area))
+		area = 0
+	}
+	if area == 0 {
+		summary.UnmeasuredRooms = append(summary.UnmeasuredRooms, name)
+	}
+
+	summary.TotalArea += area
+	summary.RoomCount++
+	summary.RoomBreakdown = append(summary.RoomBreakdown, models.RoomSummary{
+		Name:       room.Name,
+		RoomType:   room.RoomType,
+		Area:       area,
+		Dimensions: room.Dimensions,
+
+		SourceBlueprintID: room.SourceBlueprintID,
+	})
+
+	return area
+}
+
+// takeoffCeilingHeight returns the first ceiling_height measurement in feet,
+// converting meters, or DefaultCeilingHeight when there is none
+func takeoffCeilingHeight(measurements []models.Measurement) float64 {
+	for _, measurement := range measurements {
+		if !strings.EqualFold(strings.TrimSpace(measurement.MeasurementType), "ceiling_height") || measurement.Value <= 0 {
+			continue
+		}
+		if metricLengthUnits[strings.ToLower(strings.TrimSpace(measurement.Unit))] {
+			return math.Round(measurement.Value/MetersPerFoot*100) / 100
+		}
+		return measurement.Value
+	}
+	return DefaultCeilingHeight
+}
+
+// addRoomWallArea adds a room's wall area, its perimeter times the ceiling
+// height, to the summary. The perimeter comes from a "W x L" dimension
+// string; rooms whose dimensions can't be read add nothing, with a warning
+// unless the dimensions are blank.
+func addRoomWallArea(summary *models.TakeoffSummary, room models.Room) {
+	perimeter, ok := roomPerimeter(room.Dimensions)
+	if !ok {
+		if strings.TrimSpace(room.Dimensions) != "" {
+			slog.Warn("Room dimensions could not be parsed for wall area", "room", room.Name, "dimensions", room.Dimensions)
+			summary.Warnings = append(summary.Warnings,
+				fmt.Sprintf("Room %q has unreadable dimensions %q; no wall area counted", room.Name, room.Dimensions))
+		}
+		return
+	}
+	summary.WallArea += perimeter * summary.CeilingHeight
+}
+
+// roomPerimeter reads a rectangular room's dimensions, such as 20x15 or
+// 20' x 15', as its perimeter in feet
+func roomPerimeter(dimensions string) (float64, bool) {
+	sides, ok := parseRoomSides(dimensions)
+	if !ok || len(sides) != 2 || sides[0] <= 0 {
+		return 0, false
+	}
+	return 2 * (sides[0] + sides[1]), true
+}
+
+// flagUnmeasuredRooms marks the takeoff for review when more than fraction
+// of its rooms have no measured area
+func flagUnmeasuredRooms(summary *models.TakeoffSummary, fraction float64) {
+	if summary.RoomCount == 0 || len(summary.UnmeasuredRooms) == 0 {
+		return
+	}
+	if float64(len(summary.UnmeasuredRooms))/float64(summary.RoomCount) > fraction {
+		summary.NeedsReview = true
+		summary.Warnings = append(summary.Warnings,
+			fmt.Sprintf("%d of %d rooms are unmeasured; takeoff needs review", len(summary.UnmeasuredRooms), summary.RoomCount))
+	}
+}
+
+// SheetAnalysis pairs a parsed analysis with the sheet it came from
+type SheetAnalysis struct {
+	Sheet    models.SheetRef
+	Analysis *models.AnalysisResult
+}
+
+// fixtureDisciplines maps fixture categories to the sheet type that owns them
+var fixtureDisciplines = map[string]models.SheetType{
+	"electrical": models.SheetTypeElectrical,
+	"plumbing":   models.SheetTypePlumbing,
+	"hvac":       models.SheetTypeMechanical,
+	"mechanical": models.SheetTypeMechanical,
+}
+
+// MergeAnalyses combines the analyses of a multi-discipline sheet set without
+// double counting items that appear on more than one sheet:
+//   - rooms and openings come only from architectural sheets (or from
+//     unclassified sheets when the set has no architectural sheet)
+//   - fixtures of a discipline come only from that discipline's sheets when the
+//     set has any, falling back to the architectural sheets otherwise
+//   - measurements and materials are taken from every sheet
+func (s *TakeoffService) MergeAnalyses(sheets []SheetAnalysis) (*models.ProjectTakeoffSummary, error) {
+	merged, sources := MergeSheetAnalyses(sheets)
+	summary, err := s.CalculateTakeoffSummary(merged)
+	if err != nil {
+		return nil, err
+	}
+	flagSharedRoomNames(summary)
+
+	count := 0
+	for _, sheet := range sheets {
+		if sheet.Analysis != nil {
+			count++
+		}
+	}
+	return &models.ProjectTakeoffSummary{
+		Takeoff:        summary,
+		Sources:        sources,
+		BlueprintCount: count,
+	}, nil
+}
+
+// flagSharedRoomNames warns about room names that appear on more than one
+// merged blueprint. Merging does not de-duplicate rooms, so each copy is
+// counted; the estimator decides whether they are the same room.
+func flagSharedRoomNames(summary *models.TakeoffSummary) {
+	blueprints := make(map[string]map[uuid.UUID]bool)
+	var names []string
+	for _, room := range summary.RoomBreakdown {
+		if room.SourceBlueprintID == nil || room.Name == "" {
+			continue
+		}
+		if blueprints[room.Name] == nil {
+			blueprints[room.Name] = make(map[uuid.UUID]bool)
+			names = append(names, room.Name)
+		}
+		blueprints[room.Name][*room.SourceBlueprintID] = true
+	}
+	for _, name := range names {
+		if count := len(blueprints[name]); count > 1 {
+			summary.Warnings = append(summary.Warnings,
+				fmt.Sprintf("Room %q appears on %d blueprints and is counted on each", name, count))
+		}
+	}
+}
+
+// MergeSheetAnalyses combines sheet analyses by MergeAnalyses' rules into one
+// analysis, returning the sheets each aggregate was counted from
+func MergeSheetAnalyses(sheets []SheetAnalysis) (*models.AnalysisResult, map[string][]models.SheetRef) {
+	present := make(map[models.SheetType]bool)
+	for _, sheet := range sheets {
+		present[sheet.Sheet.SheetType] = true
+	}
+
+	planType := models.SheetTypeArchitectural
+	if !present[models.SheetTypeArchitectural] {
+		planType = models.SheetTypeUnknown
+	}
+
+	merged := &models.AnalysisResult{Status: "completed"}
+	sources := make(map[string][]models.SheetRef)
+	addSource := func(key string, ref models.SheetRef) {
+		for _, existing := range sources[key] {
+			if existing.BlueprintID == ref.BlueprintID {
+				return
+			}
+		}
+		sources[key] = append(sources[key], ref)
+	}
+
+	for _, sheet := range sheets {
+		if sheet.Analysis == nil {
+			continue
+		}
+		isPlan := sheet.Sheet.SheetType == planType
+
+		if isPlan {
+			blueprintID := sheet.Sheet.BlueprintID
+			for _, room := range sheet.Analysis.Rooms {
+				room.SourceBlueprintID = &blueprintID
+				merged.Rooms = append(merged.Rooms, room)
+			}
+			merged.Openings = append(merged.Openings, sheet.Analysis.Openings...)
+			if len(sheet.Analysis.Rooms) > 0 {
+				addSource("rooms", sheet.Sheet)
+			}
+			if len(sheet.Analysis.Openings) > 0 {
+				addSource("openings", sheet.Sheet)
+			}
+		}
+
+		for _, fixture := range sheet.Analysis.Fixtures {
+			discipline, ok := fixtureDisciplines[fixture.Category]
+			owned := ok && present[discipline]
+			if (owned && sheet.Sheet.SheetType != discipline) || (!owned && !isPlan) {
+				continue
+			}
+			merged.Fixtures = append(merged.Fixtures, fixture)
+			addSource("fixtures."+fixture.Category, sheet.Sheet)
+		}
+
+		merged.Measurements = append(merged.Measurements, sheet.Analysis.Measurements...)
+		merged.Materials = append(merged.Materials, sheet.Analysis.Materials...)
+
+		// The merged analysis is as confident as its least confident sheet
+		if score := sheet.Analysis.ConfidenceScore; score > 0 && (merged.ConfidenceScore == 0 || score < merged.ConfidenceScore) {
+			merged.ConfidenceScore = score
+		}
+	}
+
+	return merged, sources
+}
+
 // Perimeter estimation constants
 const (
 	// For rooms where dimensions aren't parseable, we estimate perimeter
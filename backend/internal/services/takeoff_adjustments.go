@@ -0,0 +1,276 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// MaxTakeoffAdjustments bounds the adjustments stored for one blueprint
+const MaxTakeoffAdjustments = 200
+
+// maxAdjustmentNoteLength bounds an adjustment's note, in characters
+const maxAdjustmentNoteLength = 500
+
+// adjustmentFields is the quantity each entity type's adjustments change
+var adjustmentFields = map[models.TakeoffEntity]string{
+	models.TakeoffEntityRoom:    "area",
+	models.TakeoffEntityOpening: "count",
+	models.TakeoffEntityFixture: "count",
+}
+
+// ValidateTakeoffAdjustments checks adjustments and fills in defaults in
+// place: the field defaults to the entity type's quantity and the operation
+// to set. Counts must be whole numbers, set values must not be negative and
+// no two adjustments may change the same items.
+func ValidateTakeoffAdjustments(adjustments []models.TakeoffAdjustment) error {
+	if len(adjustments) > MaxTakeoffAdjustments {
+		return fmt.Errorf("at most %d takeoff adjustments are allowed", MaxTakeoffAdjustments)
+	}
+
+	seen := make(map[string]bool, len(adjustments))
+	for i := range adjustments {
+		adjustment := &adjustments[i]
+		field, ok := adjustmentFields[adjustment.EntityType]
+		if !ok {
+			return fmt.Errorf("adjustment %d: entity_type must be room, opening or fixture", i+1)
+		}
+		if adjustment.Field == "" {
+			adjustment.Field = field
+		}
+		if adjustment.Field != field {
+			return fmt.Errorf("adjustment %d: a %s adjustment can only change %s", i+1, adjustment.EntityType, field)
+		}
+		switch adjustment.Operation {
+		case "":
+			adjustment.Operation = models.TakeoffAdjustmentSet
+		case models.TakeoffAdjustmentSet, models.TakeoffAdjustmentAdd:
+		default:
+			return fmt.Errorf("adjustment %d: operation must be set or add", i+1)
+		}
+
+		adjustment.Key = strings.TrimSpace(adjustment.Key)
+		key := adjustmentMatchKey(adjustment.EntityType, adjustment.Key)
+		if NormalizeRoomName(adjustment.Key) == "" {
+			return fmt.Errorf("adjustment %d: key must not be empty", i+1)
+		}
+		if math.IsNaN(adjustment.Value) || math.IsInf(adjustment.Value, 0) {
+			return fmt.Errorf("adjustment %d: value must be a number", i+1)
+		}
+		if adjustment.Operation == models.TakeoffAdjustmentSet && adjustment.Value < 0 {
+			return fmt.Errorf("adjustment %d: a set value must not be negative", i+1)
+		}
+		if field == "count" && adjustment.Value != math.Trunc(adjustment.Value) {
+			return fmt.Errorf("adjustment %d: counts must be whole numbers", i+1)
+		}
+		if len([]rune(adjustment.Note)) > maxAdjustmentNoteLength {
+			return fmt.Errorf("adjustment %d: note must be at most %d characters", i+1, maxAdjustmentNoteLength)
+		}
+
+		duplicate := string(adjustment.EntityType) + "|" + key
+		if seen[duplicate] {
+			return fmt.Errorf("adjustment %d: %s %q is already adjusted", i+1, adjustment.EntityType, adjustment.Key)
+		}
+		seen[duplicate] = true
+	}
+	return nil
+}
+
+// adjustmentMatchKey folds an adjustment key so it compares equal to the
+// keys of the items it names
+func adjustmentMatchKey(entity models.TakeoffEntity, key string) string {
+	if entity == models.TakeoffEntityRoom {
+		return NormalizeRoomName(key)
+	}
+	kind, detail, _ := strings.Cut(key, ":")
+	return NormalizeRoomName(kind) + ":" + NormalizeRoomName(detail)
+}
+
+// matchesAdjustment reports whether an item with the given type and detail
+// (an opening's size or a fixture's type) is named by a folded key. A key
+// without a detail matches every item of the type.
+func matchesAdjustment(matchKey, kind, detail string) bool {
+	keyKind, keyDetail, _ := strings.Cut(matchKey, ":")
+	if keyKind != NormalizeRoomName(kind) {
+		return false
+	}
+	return keyDetail == "" || keyDetail == NormalizeRoomName(detail)
+}
+
+// TakeoffAdjustmentResult lists the adjustments ApplyAdjustments applied and
+// those whose key matched nothing, such as a room renamed by a re-analysis
+type TakeoffAdjustmentResult struct {
+	Applied  []models.AppliedTakeoffAdjustment
+	Orphaned []models.TakeoffAdjustment
+}
+
+// ApplyAdjustments applies an estimator's adjustments, in order, to a parsed
+// analysis in place. The analysis should be a fresh parse of the stored one,
+// which is never changed. An adjustment changes the total quantity of every
+// item its key matches: add changes the first match, set gives the first
+// match the whole value and drops the others. Items left with no quantity
+// are dropped, so a room set to zero area is out of scope rather than
+// unmeasured.
+func ApplyAdjustments(analysis *models.AnalysisResult, adjustments []models.TakeoffAdjustment) TakeoffAdjustmentResult {
+	var result TakeoffAdjustmentResult
+	if analysis == nil {
+		return result
+	}
+
+	for _, adjustment := range adjustments {
+		matchKey := adjustmentMatchKey(adjustment.EntityType, adjustment.Key)
+		var original, adjusted float64
+		var matched bool
+		switch adjustment.EntityType {
+		case models.TakeoffEntityRoom:
+			analysis.Rooms, original, adjusted, matched = adjustQuantities(analysis.Rooms, adjustment,
+				func(room models.Room) bool { return NormalizeRoomName(room.Name) == matchKey },
+				func(room *models.Room) *float64 { return &room.Area })
+		case models.TakeoffEntityOpening:
+			analysis.Openings, original, adjusted, matched = adjustCounts(analysis.Openings, adjustment,
+				func(opening models.Opening) bool {
+					return matchesAdjustment(matchKey, opening.OpeningType, opening.Size)
+				},
+				func(opening *models.Opening) *int { return &opening.Count })
+		case models.TakeoffEntityFixture:
+			analysis.Fixtures, original, adjusted, matched = adjustCounts(analysis.Fixtures, adjustment,
+				func(fixture models.Fixture) bool {
+					return matchesAdjustment(matchKey, fixture.Category, fixture.FixtureType)
+				},
+				func(fixture *models.Fixture) *int { return &fixture.Count })
+		}
+
+		if !matched {
+			result.Orphaned = append(result.Orphaned, adjustment)
+			continue
+		}
+		result.Applied = append(result.Applied, models.AppliedTakeoffAdjustment{
+			TakeoffAdjustment: adjustment,
+			OriginalValue:     original,
+			AdjustedValue:     adjusted,
+		})
+	}
+	return result
+}
+
+// adjustQuantities applies an adjustment to the items matching match and
+// returns the items with emptied ones dropped, the matched total before and
+// after, and whether anything matched
+func adjustQuantities[T any](items []T, adjustment models.TakeoffAdjustment, match func(T) bool, quantity func(*T) *float64) ([]T, float64, float64, bool) {
+	first := -1
+	var original float64
+	for i := range items {
+		if match(items[i]) {
+			if first < 0 {
+				first = i
+			}
+			original += math.Max(0, *quantity(&items[i]))
+		}
+	}
+	if first < 0 {
+		return items, 0, 0, false
+	}
+
+	if adjustment.Operation == models.TakeoffAdjustmentAdd {
+		value := quantity(&items[first])
+		*value = math.Max(0, math.Max(0, *value)+adjustment.Value)
+	} else {
+		for i := first + 1; i < len(items); i++ {
+			if match(items[i]) {
+				*quantity(&items[i]) = 0
+			}
+		}
+		*quantity(&items[first]) = adjustment.Value
+	}
+
+	// Only items the adjustment changed are dropped; a matched room that was
+	// already unmeasured stays listed as unmeasured
+	kept := items[:0]
+	var adjusted float64
+	for i := range items {
+		if match(items[i]) {
+			changed := i == first || adjustment.Operation != models.TakeoffAdjustmentAdd
+			if changed && *quantity(&items[i]) <= 0 {
+				continue
+			}
+			adjusted += math.Max(0, *quantity(&items[i]))
+		}
+		kept = append(kept, items[i])
+	}
+	return kept, original, adjusted, true
+}
+
+// adjustCounts is adjustQuantities for items counted in whole numbers
+func adjustCounts[T any](items []T, adjustment models.TakeoffAdjustment, match func(T) bool, count func(*T) *int) ([]T, float64, float64, bool) {
+	type counted struct {
+		item     T
+		quantity float64
+	}
+	wrapped := make([]counted, len(items))
+	for i := range items {
+		wrapped[i] = counted{item: items[i], quantity: float64(*count(&items[i]))}
+	}
+
+	wrapped, original, adjusted, matched := adjustQuantities(wrapped, adjustment,
+		func(c counted) bool { return match(c.item) },
+		func(c *counted) *float64 { return &c.quantity })
+
+	kept := make([]T, 0, len(wrapped))
+	for _, c := range wrapped {
+		*count(&c.item) = int(c.quantity)
+		kept = append(kept, c.item)
+	}
+	return kept, original, adjusted, matched
+}
+
+// Merge adds the adjustments of another blueprint's analysis
+func (r *TakeoffAdjustmentResult) Merge(other TakeoffAdjustmentResult) {
+	r.Applied = append(r.Applied, other.Applied...)
+	r.Orphaned = append(r.Orphaned, other.Orphaned...)
+}
+
+// Annotate records the result on a takeoff computed from the adjusted
+// analysis, marking each adjusted breakdown entry with the quantity it had
+// before and the estimator's note
+func (r TakeoffAdjustmentResult) Annotate(takeoff *models.TakeoffSummary) {
+	if takeoff == nil {
+		return
+	}
+	takeoff.Adjustments = append(takeoff.Adjustments, r.Applied...)
+	takeoff.OrphanedAdjustments = append(takeoff.OrphanedAdjustments, r.Orphaned...)
+
+	for _, applied := range r.Applied {
+		mark := func() *models.AdjustedQuantity {
+			return &models.AdjustedQuantity{
+				Field:         applied.Field,
+				OriginalValue: applied.OriginalValue,
+				Note:          applied.Note,
+			}
+		}
+		matchKey := adjustmentMatchKey(applied.EntityType, applied.Key)
+		switch applied.EntityType {
+		case models.TakeoffEntityRoom:
+			for i := range takeoff.RoomBreakdown {
+				if NormalizeRoomName(takeoff.RoomBreakdown[i].Name) == matchKey {
+					takeoff.RoomBreakdown[i].Adjusted = mark()
+				}
+			}
+		case models.TakeoffEntityOpening:
+			for i := range takeoff.OpeningBreakdown {
+				opening := &takeoff.OpeningBreakdown[i]
+				if matchesAdjustment(matchKey, opening.OpeningType, opening.Size) {
+					opening.Adjusted = mark()
+				}
+			}
+		case models.TakeoffEntityFixture:
+			for i := range takeoff.FixtureBreakdown {
+				fixture := &takeoff.FixtureBreakdown[i]
+				if matchesAdjustment(matchKey, fixture.Category, fixture.FixtureType) {
+					fixture.Adjusted = mark()
+				}
+			}
+		}
+	}
+}
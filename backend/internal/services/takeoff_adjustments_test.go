@@ -0,0 +1,200 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestValidateTakeoffAdjustments(t *testing.T) {
+	valid := []models.TakeoffAdjustment{
+		{EntityType: models.TakeoffEntityRoom, Key: " Bath #1 ", Value: 60},
+		{EntityType: models.TakeoffEntityOpening, Key: "door:36x80", Operation: models.TakeoffAdjustmentAdd, Value: -1},
+		{EntityType: models.TakeoffEntityFixture, Key: "plumbing", Field: "count", Value: 4},
+	}
+	if err := ValidateTakeoffAdjustments(valid); err != nil {
+		t.Fatalf("ValidateTakeoffAdjustments() error = %v", err)
+	}
+	if valid[0].Key != "Bath #1" || valid[0].Field != "area" || valid[0].Operation != models.TakeoffAdjustmentSet {
+		t.Errorf("expected defaults filled in, got %+v", valid[0])
+	}
+
+	invalid := map[string]models.TakeoffAdjustment{
+		"entity":       {EntityType: "wall", Key: "north", Value: 1},
+		"field":        {EntityType: models.TakeoffEntityRoom, Key: "Office", Field: "count", Value: 1},
+		"operation":    {EntityType: models.TakeoffEntityRoom, Key: "Office", Operation: "scale", Value: 1},
+		"empty key":    {EntityType: models.TakeoffEntityRoom, Key: " - ", Value: 1},
+		"negative set": {EntityType: models.TakeoffEntityRoom, Key: "Office", Value: -5},
+		"fractional":   {EntityType: models.TakeoffEntityOpening, Key: "window", Value: 1.5},
+	}
+	for name, adjustment := range invalid {
+		if err := ValidateTakeoffAdjustments([]models.TakeoffAdjustment{adjustment}); err == nil {
+			t.Errorf("%s: expected an error for %+v", name, adjustment)
+		}
+	}
+
+	duplicate := []models.TakeoffAdjustment{
+		{EntityType: models.TakeoffEntityRoom, Key: "Bath 1", Value: 60},
+		{EntityType: models.TakeoffEntityRoom, Key: "BATH-1", Value: 70},
+	}
+	if err := ValidateTakeoffAdjustments(duplicate); err == nil {
+		t.Error("expected an error for two adjustments to the same room")
+	}
+}
+
+func testAdjustmentAnalysis() *models.AnalysisResult {
+	return &models.AnalysisResult{
+		Rooms: []models.Room{
+			{Name: "Office", Area: 200},
+			{Name: "Storage", Area: 80},
+			{Name: "Closet", Area: 20},
+			{Name: "Closet", Area: 0},
+		},
+		Openings: []models.Opening{
+			{OpeningType: "door", Count: 2, Size: "36x80"},
+			{OpeningType: "door", Count: 1, Size: "36x80"},
+			{OpeningType: "window", Count: 4, Size: "48x36"},
+		},
+		Fixtures: []models.Fixture{
+			{FixtureType: "outlet", Category: "electrical", Count: 10},
+			{FixtureType: "toilet", Category: "plumbing", Count: 2},
+		},
+	}
+}
+
+func TestApplyAdjustments(t *testing.T) {
+	analysis := testAdjustmentAnalysis()
+	result := ApplyAdjustments(analysis, []models.TakeoffAdjustment{
+		{EntityType: models.TakeoffEntityRoom, Key: "office", Field: "area", Operation: models.TakeoffAdjustmentAdd, Value: 200, Note: "OCR missed the alcove"},
+		{EntityType: models.TakeoffEntityRoom, Key: "Storage", Field: "area", Operation: models.TakeoffAdjustmentSet, Value: 0, Note: "Out of scope"},
+		{EntityType: models.TakeoffEntityRoom, Key: "Closet", Field: "area", Operation: models.TakeoffAdjustmentAdd, Value: 5},
+		{EntityType: models.TakeoffEntityOpening, Key: "Door: 36x80", Field: "count", Operation: models.TakeoffAdjustmentSet, Value: 5},
+		{EntityType: models.TakeoffEntityFixture, Key: "electrical", Field: "count", Operation: models.TakeoffAdjustmentAdd, Value: -12},
+	})
+
+	if len(result.Orphaned) != 0 || len(result.Applied) != 5 {
+		t.Fatalf("ApplyAdjustments() applied %d and orphaned %v, want 5 applied", len(result.Applied), result.Orphaned)
+	}
+	office := result.Applied[0]
+	if office.OriginalValue != 200 || office.AdjustedValue != 400 || office.Note != "OCR missed the alcove" {
+		t.Errorf("office adjustment = %+v, want 200 -> 400 with its note", office)
+	}
+	if doors := result.Applied[3]; doors.OriginalValue != 3 || doors.AdjustedValue != 5 {
+		t.Errorf("door adjustment = %+v, want the two door entries' 3 -> 5", doors)
+	}
+	if outlets := result.Applied[4]; outlets.OriginalValue != 10 || outlets.AdjustedValue != 0 {
+		t.Errorf("outlet adjustment = %+v, want 10 -> 0 clamped", outlets)
+	}
+
+	// The out-of-scope room is dropped rather than left unmeasured, while the
+	// unmeasured closet an add did not touch is still listed
+	if len(analysis.Rooms) != 3 || analysis.Rooms[0].Area != 400 || analysis.Rooms[1].Area != 25 || analysis.Rooms[2].Area != 0 {
+		t.Errorf("adjusted rooms = %+v, want Office 400, Closet 25 and the unmeasured Closet", analysis.Rooms)
+	}
+	if len(analysis.Openings) != 2 || analysis.Openings[0].Count != 5 || analysis.Openings[1].OpeningType != "window" {
+		t.Errorf("adjusted openings = %+v, want one door entry of 5 and the windows", analysis.Openings)
+	}
+	if len(analysis.Fixtures) != 1 || analysis.Fixtures[0].Category != "plumbing" {
+		t.Errorf("adjusted fixtures = %+v, want only plumbing left", analysis.Fixtures)
+	}
+
+	takeoff := PricingTakeoff(analysis)
+	result.Annotate(takeoff)
+	if takeoff.TotalArea != 425 || takeoff.OpeningCounts["door"] != 5 || takeoff.FixtureCounts["electrical"] != 0 {
+		t.Errorf("takeoff = %v SF, %v openings, %v fixtures", takeoff.TotalArea, takeoff.OpeningCounts, takeoff.FixtureCounts)
+	}
+	if mark := takeoff.RoomBreakdown[0].Adjusted; mark == nil || mark.OriginalValue != 200 || mark.Field != "area" || mark.Note != "OCR missed the alcove" {
+		t.Errorf("office mark = %+v, want the original 200 SF and the note", mark)
+	}
+	if mark := takeoff.OpeningBreakdown[0].Adjusted; mark == nil || mark.OriginalValue != 3 {
+		t.Errorf("door mark = %+v, want the original count of 3", mark)
+	}
+	if takeoff.OpeningBreakdown[1].Adjusted != nil || takeoff.FixtureBreakdown[0].Adjusted != nil {
+		t.Error("expected unadjusted entries left unmarked")
+	}
+	if len(takeoff.Adjustments) != 5 {
+		t.Errorf("takeoff lists %d adjustments, want 5", len(takeoff.Adjustments))
+	}
+}
+
+func TestApplyAdjustments_OrphanedAfterReanalysis(t *testing.T) {
+	adjustments := []models.TakeoffAdjustment{
+		{EntityType: models.TakeoffEntityRoom, Key: "Office", Field: "area", Operation: models.TakeoffAdjustmentAdd, Value: 50},
+		{EntityType: models.TakeoffEntityOpening, Key: "window:48x36", Field: "count", Operation: models.TakeoffAdjustmentSet, Value: 6},
+	}
+
+	if result := ApplyAdjustments(testAdjustmentAnalysis(), adjustments); len(result.Orphaned) != 0 {
+		t.Fatalf("expected every adjustment to match the first analysis, orphaned %v", result.Orphaned)
+	}
+
+	// The re-analysis renames the office and reads the windows' size
+	// differently; the window adjustment no longer matches anything
+	reanalysis := testAdjustmentAnalysis()
+	reanalysis.Rooms[0].Name = "OFFICE"
+	reanalysis.Openings[2].Size = "48x48"
+	result := ApplyAdjustments(reanalysis, adjustments)
+	if len(result.Applied) != 1 || result.Applied[0].Key != "Office" {
+		t.Errorf("applied = %+v, want the office adjustment to survive the rename in case", result.Applied)
+	}
+	if len(result.Orphaned) != 1 || result.Orphaned[0].Key != "window:48x36" {
+		t.Errorf("orphaned = %+v, want the window adjustment", result.Orphaned)
+	}
+	if reanalysis.Openings[2].Count != 4 {
+		t.Errorf("orphaned adjustment changed the windows: %+v", reanalysis.Openings[2])
+	}
+
+	takeoff := PricingTakeoff(reanalysis)
+	result.Annotate(takeoff)
+	if len(takeoff.OrphanedAdjustments) != 1 {
+		t.Errorf("takeoff orphaned adjustments = %v, want the window adjustment", takeoff.OrphanedAdjustments)
+	}
+}
+
+func TestBlueprintTakeoff_AppliesAdjustments(t *testing.T) {
+	analysis := `{"rooms":[{"name":"Office","area":200},{"name":"Garage","area":300}],"openings":[{"opening_type":"door","count":2,"size":"36x80"}]}`
+	blueprint := &models.Blueprint{ID: uuid.New(), AnalysisData: &analysis}
+	baseTakeoff, baseAnalysis, err := BlueprintTakeoff(blueprint)
+	if err != nil {
+		t.Fatalf("BlueprintTakeoff() error = %v", err)
+	}
+
+	blueprint.TakeoffAdjustments = []models.TakeoffAdjustment{
+		{EntityType: models.TakeoffEntityRoom, Key: "Garage", Field: "area", Operation: models.TakeoffAdjustmentSet, Value: 0},
+		{EntityType: models.TakeoffEntityOpening, Key: "door", Field: "count", Operation: models.TakeoffAdjustmentAdd, Value: 2},
+	}
+	takeoff, adjusted, err := BlueprintTakeoff(blueprint)
+	if err != nil {
+		t.Fatalf("BlueprintTakeoff() error = %v", err)
+	}
+	if takeoff.TotalArea != 200 || takeoff.OpeningCounts["door"] != 4 {
+		t.Errorf("adjusted takeoff = %v SF and %v doors, want 200 SF and 4 doors", takeoff.TotalArea, takeoff.OpeningCounts["door"])
+	}
+	if *blueprint.AnalysisData != analysis {
+		t.Error("expected the stored analysis left untouched")
+	}
+
+	pricing := NewPricingService()
+	base, err := pricing.GeneratePricingSummary(baseTakeoff, baseAnalysis, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	priced, err := pricing.GeneratePricingSummary(takeoff, adjusted, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	quantities := func(summary *models.PricingSummary) map[string]float64 {
+		byDescription := map[string]float64{}
+		for _, item := range summary.LineItems {
+			byDescription[item.Description] = item.Quantity
+		}
+		return byDescription
+	}
+	baseQuantities, pricedQuantities := quantities(base), quantities(priced)
+	if baseQuantities["Paint and finishing"] != 500 || pricedQuantities["Paint and finishing"] != 200 {
+		t.Errorf("painted area = %v then %v, want 500 then 200", baseQuantities["Paint and finishing"], pricedQuantities["Paint and finishing"])
+	}
+	if priced.TotalPrice >= base.TotalPrice {
+		t.Errorf("expected dropping the garage to lower the price, got %.2f from %.2f", priced.TotalPrice, base.TotalPrice)
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// DefaultProductivityRates returns how many units of each kind of work a
+// crew installs per labor hour. Area work is keyed by trade and measured in
+// square feet; counted and linear work is keyed like its material price.
+func DefaultProductivityRates() map[string]float64 {
+	return map[string]float64{
+		"framing":           35,      // SF of framing and drywall per hour
+		"painting":          150,     // SF per hour
+		"flooring":          40,      // SF per hour
+		"flooring_tile":     20,      // SF per hour
+		"flooring_lvp":      60,      // SF per hour
+		"flooring_carpet":   80,      // SF per hour
+		"flooring_hardwood": 30,      // SF per hour
+		"wall_framing_lf":   20,      // LF per hour
+		"top_plate":         60,      // LF per hour
+		"baseboard":         40,      // LF per hour
+		"door":              0.5,     // 2 hours each
+		"door_exterior":     0.25,    // 4 hours each
+		"door_sliding":      0.25,    // 4 hours each
+		"window":            1.0 / 3, // 3 hours each
+		"outlet":            2,       // 30 minutes each
+	}
+}
+
+// laborHourIncrement is the unit labor is billed in. Hours are rounded up so
+// small jobs are never priced without labor.
+const laborHourIncrement = 0.25
+
+// installedWork is a line item of installation work and the labor hours it
+// takes. Hours are zero when the item's price includes its labor.
+type installedWork struct {
+	item  models.LineItem
+	hours float64
+}
+
+// productivityRate returns the first positive productivity rate among keys,
+// or zero when the work has none
+func productivityRate(config *models.PricingConfig, keys ...string) float64 {
+	for _, key := range keys {
+		if rate := config.ProductivityRates[key]; rate > 0 {
+			return rate
+		}
+	}
+	return 0
+}
+
+// installWork prices item's quantity at price. When the work has a
+// productivity rate the price is all material and its labor is billed by
+// the hour on the trade's labor line; otherwise laborShare of the price is
+// the labor to install it.
+func installWork(item models.LineItem, price, laborShare float64, config *models.PricingConfig, workKeys ...string) installedWork {
+	item.UnitCost = price
+	item.Total = math.Round(item.Quantity*price*100) / 100
+
+	rate := productivityRate(config, workKeys...)
+	if rate <= 0 {
+		splitLineItemCost(&item, laborShare)
+		return installedWork{item: item}
+	}
+	splitLineItemCost(&item, 0)
+	return installedWork{item: item, hours: item.Quantity / rate}
+}
+
+// buildLaborItems bills the labor hours of installed work with one line
+// item per trade, in trade order so output is stable. multiplier scales
+// hours for less productive work such as renovation. sources may be nil
+// when price provenance is not tracked.
+func buildLaborItems(work []installedWork, config *models.PricingConfig, sources *ResolvedPricingConfig, multiplier float64) []models.LineItem {
+	hoursByTrade := make(map[string]float64)
+	for _, w := range work {
+		if w.hours > 0 {
+			hoursByTrade[w.item.Trade] += w.hours
+		}
+	}
+
+	var items []models.LineItem
+	for _, trade := range sortedKeys(hoursByTrade) {
+		rateKey := trade
+		rate, ok := config.LaborRates[trade]
+		if !ok {
+			rateKey = "general"
+			rate = config.LaborRates["general"]
+		}
+		hours := math.Ceil(hoursByTrade[trade]*multiplier/laborHourIncrement) * laborHourIncrement
+		item := models.LineItem{
+			Description: fmt.Sprintf("Labor - %s", trade),
+			Trade:       trade,
+			Quantity:    hours,
+			Unit:        "hours",
+			UnitCost:    rate,
+			Total:       math.Round(hours*rate*100) / 100,
+			PriceSource: sources.laborSource(rateKey),
+		}
+		splitLineItemCost(&item, 1)
+		items = append(items, item)
+	}
+	return items
+}
+
+// priceTakeoff prices a takeoff with config, as both pricing services do:
+// installation line items for rooms, walls, openings and fixtures, the
+// project type's extra work, labor by the hour for work with productivity
+// rates, trade minimums, placeholders for unrecognized openings and fixtures,
+// then overhead and markup. sources may be nil when price provenance is not
+// tracked.
+func priceTakeoff(
+	takeoffSummary *models.TakeoffSummary,
+	analysisResult *models.AnalysisResult,
+	config *models.PricingConfig,
+	sources *ResolvedPricingConfig,
+	projectType models.ProjectType,
+) (*models.PricingSummary, error) {
+	var fixedSource *models.PriceSource
+	if sources != nil {
+		fixedSource = fixedPriceSource()
+	}
+
+	var work []installedWork
+
+	// Calculate costs from rooms (framing, drywall, flooring)
+	if takeoffSummary != nil && takeoffSummary.TotalArea > 0 {
+		work = append(work, installWork(models.LineItem{
+			Description: "Framing and drywall installation",
+			Trade:       "framing",
+			Quantity:    takeoffSummary.TotalArea,
+			Unit:        "sq ft",
+			PriceSource: fixedSource,
+		}, 5.50, 0.6, config, "framing"))
+
+		// Flooring, one line item per floor finish
+		work = append(work, buildFlooringItems(takeoffSummary, config, sources)...)
+
+		work = append(work, installWork(models.LineItem{
+			Description: "Paint and finishing",
+			Trade:       "painting",
+			Quantity:    takeoffSummary.TotalArea,
+			Unit:        "sq ft",
+			PriceSource: fixedSource,
+		}, 3.50, 0.7, config, "painting"))
+	}
+
+	// Wall framing, top plate and trim by the linear foot of wall
+	work = append(work, buildPerimeterItems(pricingWallLength(takeoffSummary, analysisResult), config, sources)...)
+
+	// Calculate costs from openings (doors and windows)
+	if analysisResult != nil {
+		openingCounts := countOpeningsByClass(analysisResult.Openings)
+
+		// Doors, one line item per classification
+		work = append(work, buildDoorItems(openingCounts, config, sources)...)
+
+		if windowCount := openingCounts[models.OpeningClassWindow]; windowCount > 0 {
+			work = append(work, installWork(models.LineItem{
+				Description: "Window installation",
+				Trade:       "carpentry",
+				Quantity:    float64(windowCount),
+				Unit:        "each",
+				PriceSource: sources.materialSource("window"),
+			}, config.MaterialPrices["window"], 0.20, config, "window"))
+		}
+
+		fixtureCount := 0
+		for _, fixture := range analysisResult.Fixtures {
+			if fixtureRecognized(fixture) {
+				fixtureCount += fixture.Count
+			}
+		}
+		if fixtureCount > 0 {
+			work = append(work, installWork(models.LineItem{
+				Description: "Electrical fixtures and outlets",
+				Trade:       "electrical",
+				Quantity:    float64(fixtureCount),
+				Unit:        "each",
+				PriceSource: sources.materialSource("outlet"),
+			}, config.MaterialPrices["outlet"], 0.40, config, "outlet"))
+		}
+	}
+
+	// Renovation and addition work over the takeoff area
+	modifier := config.ProjectTypeModifiers[projectType]
+	if takeoffSummary != nil {
+		work = append(work, buildProjectTypeItems(projectType, modifier, takeoffSummary.TotalArea, fixedSource)...)
+	}
+	multiplier := laborMultiplier(modifier)
+
+	lineItems := make([]models.LineItem, 0, len(work))
+	for _, w := range work {
+		lineItems = append(lineItems, w.item)
+	}
+	lineItems = append(lineItems, buildLaborItems(work, config, sources, multiplier)...)
+
+	// Small jobs for a trade are billed at least its minimum charge
+	costsByTrade := CostsByTrade(lineItems)
+	lineItems, _, notes := applyTradeMinimums(lineItems, config.TradeMinimums, costsByTrade, fixedSource)
+	// Work pricing doesn't recognize is listed at no cost for review, after
+	// the minimums so a placeholder never brings on a minimum charge
+	lineItems = append(lineItems, buildReviewItems(analysisResult)...)
+	if multiplier != 1.0 {
+		notes = append(notes, fmt.Sprintf("Labor hours include a %.0f%% productivity penalty for %s work",
+			(multiplier-1)*100, strings.ReplaceAll(string(projectType), "_", " ")))
+	}
+
+	if err := ValidateLineItems(lineItems); err != nil {
+		return nil, err
+	}
+
+	var materialCost, laborCost float64
+	for _, item := range lineItems {
+		materialCost += item.MaterialCost
+		laborCost += item.LaborCost
+	}
+	materialCost = math.Round(materialCost*100) / 100
+	laborCost = math.Round(laborCost*100) / 100
+	subtotal := math.Round((materialCost+laborCost)*100) / 100
+
+	// Calculate overhead and markup
+	overheadAmount := math.Round(subtotal*(config.OverheadRate/100)*100) / 100
+	markupAmount := math.Round((subtotal+overheadAmount)*(config.ProfitMargin/100)*100) / 100
+	totalPrice := math.Round((subtotal+overheadAmount+markupAmount)*100) / 100
+
+	return &models.PricingSummary{
+		LineItems:      lineItems,
+		LaborCost:      laborCost,
+		MaterialCost:   materialCost,
+		Subtotal:       subtotal,
+		OverheadAmount: overheadAmount,
+		MarkupAmount:   markupAmount,
+		TotalPrice:     totalPrice,
+		CostsByTrade:   costsByTrade,
+		Notes:          notes,
+	}, nil
+}
+
+// applyProductivityOverride sets a work item's productivity rate from a
+// company override, directly or as a percentage of the default rate, and
+// returns why the override was skipped, if it was
+func applyProductivityOverride(rates map[string]float64, override models.CompanyPricingOverride) string {
+	rate, ok := rates[override.ItemKey]
+	if !ok {
+		return OverrideSkipUnknownKey
+	}
+	if override.IsPercentage {
+		rate *= 1 + override.OverrideValue/100
+	} else {
+		rate = override.OverrideValue
+	}
+	// A crew that installs nothing per hour would make labor unbounded
+	if rate <= 0 {
+		return OverrideSkipNonPositive
+	}
+	rates[override.ItemKey] = rate
+	return ""
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestPriceTakeoff_LaborHoursFromQuantities(t *testing.T) {
+	config := *NewPricingService().GetDefaultPricingConfig()
+	takeoff := &models.TakeoffSummary{TotalArea: 1000}
+	analysis := &models.AnalysisResult{Openings: []models.Opening{{OpeningType: "window", Count: 3}}}
+
+	summary, err := NewPricingService().GeneratePricingSummary(takeoff, analysis, &config)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	items := lineItemsByDescription(summary)
+
+	// 1000 SF at 35 SF/hr is 28.57 hours, billed in quarter hours
+	if framing := items["Labor - framing"]; framing.Quantity != 28.75 || framing.Total != 28.75*config.LaborRates["framing"] {
+		t.Errorf("framing labor = %v hours for %v, want 28.75 hours", framing.Quantity, framing.Total)
+	}
+	// Three windows at three hours each
+	if carpentry := items["Labor - carpentry"]; carpentry.Quantity != 9 {
+		t.Errorf("carpentry labor = %v hours, want 9", carpentry.Quantity)
+	}
+	// Work billed by the hour carries no labor of its own
+	if window := items["Window installation"]; window.LaborCost != 0 || window.MaterialCost != window.Total {
+		t.Errorf("window item = %+v, want all material", window)
+	}
+
+	// Doubling the window price doubles its material, not its labor
+	config.MaterialPrices = maps.Clone(config.MaterialPrices)
+	config.MaterialPrices["window"] *= 2
+	pricier, err := NewPricingService().GeneratePricingSummary(takeoff, analysis, &config)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	if carpentry := lineItemsByDescription(pricier)["Labor - carpentry"]; carpentry.Quantity != 9 {
+		t.Errorf("carpentry labor after a price change = %v hours, want 9", carpentry.Quantity)
+	}
+	if pricier.LaborCost != summary.LaborCost {
+		t.Errorf("labor cost = %v after a price change, want %v", pricier.LaborCost, summary.LaborCost)
+	}
+}
+
+func TestPriceTakeoff_ServicesAgree(t *testing.T) {
+	takeoff := &models.TakeoffSummary{TotalArea: 1200, TotalPerimeter: 140, RoomCount: 4}
+	analysis := &models.AnalysisResult{
+		Openings: []models.Opening{
+			{OpeningType: "door", Count: 5, Size: "3x7"},
+			{OpeningType: "exterior door", Count: 1},
+			{OpeningType: "window", Count: 6},
+		},
+		Fixtures: []models.Fixture{{FixtureType: "outlet", Category: "electrical", Count: 18}},
+	}
+
+	basic, err := NewPricingService().GeneratePricingSummary(takeoff, analysis, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	enhanced, err := NewEnhancedPricingService(nil, nil, nil, nil).GeneratePricingSummary(context.Background(), takeoff, analysis, nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+
+	if basic.TotalPrice != enhanced.TotalPrice || basic.LaborCost != enhanced.LaborCost || basic.MaterialCost != enhanced.MaterialCost {
+		t.Errorf("basic = %v (labor %v, material %v), enhanced = %v (labor %v, material %v); want the same",
+			basic.TotalPrice, basic.LaborCost, basic.MaterialCost, enhanced.TotalPrice, enhanced.LaborCost, enhanced.MaterialCost)
+	}
+	if len(basic.LineItems) != len(enhanced.LineItems) {
+		t.Fatalf("basic has %d line items, enhanced %d", len(basic.LineItems), len(enhanced.LineItems))
+	}
+	for i, item := range basic.LineItems {
+		other := enhanced.LineItems[i]
+		if item.Description != other.Description || item.Quantity != other.Quantity || item.Total != other.Total {
+			t.Errorf("line item %d: basic %s %v = %v, enhanced %s %v = %v",
+				i, item.Description, item.Quantity, item.Total, other.Description, other.Quantity, other.Total)
+		}
+	}
+}
+
+func TestResolvePricing_ProductivityOverrides(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	windowID, framingID, unknownID, zeroID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	resolved := resolvePricing(defaults, pricingInputs{
+		overrides: []models.CompanyPricingOverride{
+			{ID: windowID, OverrideType: "productivity", ItemKey: "window", OverrideValue: 0.5},
+			{ID: framingID, OverrideType: "productivity", ItemKey: "framing", OverrideValue: 20, IsPercentage: true},
+			{ID: unknownID, OverrideType: "productivity", ItemKey: "roofing", OverrideValue: 10},
+			{ID: zeroID, OverrideType: "productivity", ItemKey: "painting", OverrideValue: -100, IsPercentage: true},
+		},
+		regionalFactor: 1.0,
+	})
+
+	rates := resolved.Config.ProductivityRates
+	if rates["window"] != 0.5 || rates["framing"] != 42 || rates["painting"] != 150 {
+		t.Errorf("rates = %v, want window 0.5, framing 42 and painting unchanged", rates)
+	}
+	if defaults.ProductivityRates["framing"] != 35 {
+		t.Error("override changed the default productivity rates")
+	}
+	skipped := make(map[uuid.UUID]string)
+	for _, skip := range resolved.Config.SkippedOverrides {
+		skipped[skip.OverrideID] = skip.Reason
+	}
+	if len(resolved.Config.AppliedOverrides) != 2 || skipped[unknownID] != OverrideSkipUnknownKey || skipped[zeroID] != OverrideSkipNonPositive {
+		t.Errorf("applied = %v, skipped = %+v", resolved.Config.AppliedOverrides, resolved.Config.SkippedOverrides)
+	}
+
+	// Two windows at half a window per hour take four hours
+	analysis := &models.AnalysisResult{Openings: []models.Opening{{OpeningType: "window", Count: 2}}}
+	summary, err := priceTakeoff(nil, analysis, resolved.Config, resolved, models.ProjectTypeNewConstruction)
+	if err != nil {
+		t.Fatalf("priceTakeoff failed: %v", err)
+	}
+	if carpentry := lineItemsByDescription(summary)["Labor - carpentry"]; carpentry.Quantity != 4 {
+		t.Errorf("carpentry labor = %v hours, want 4", carpentry.Quantity)
+	}
+}
@@ -1,8 +1,11 @@
 package services
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
 )
 
@@ -204,3 +207,315 @@ func TestParseAnalysisData(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeAnalyses_DisciplineAware(t *testing.T) {
+	service := NewTakeoffService()
+
+	// The architectural plan shows the lights that the electrical sheet also counts
+	architectural := SheetAnalysis{
+		Sheet: models.SheetRef{BlueprintID: uuid.New(), Filename: "A-101.pdf", SheetType: models.SheetTypeArchitectural},
+		Analysis: &models.AnalysisResult{
+			Rooms: []models.Room{
+				{Name: "Kitchen", Area: 150},
+				{Name: "Living Room", Area: 300},
+			},
+			Openings: []models.Opening{{OpeningType: "door", Count: 3}},
+			Fixtures: []models.Fixture{
+				{FixtureType: "light", Category: "electrical", Count: 8},
+				{FixtureType: "sink", Category: "plumbing", Count: 2},
+			},
+		},
+	}
+	electrical := SheetAnalysis{
+		Sheet: models.SheetRef{BlueprintID: uuid.New(), Filename: "E-101.pdf", SheetType: models.SheetTypeElectrical},
+		Analysis: &models.AnalysisResult{
+			Rooms: []models.Room{
+				{Name: "Kitchen", Area: 150},
+				{Name: "Living Room", Area: 300},
+			},
+			Fixtures: []models.Fixture{
+				{FixtureType: "light", Category: "electrical", Count: 10},
+				{FixtureType: "outlet", Category: "electrical", Count: 12},
+			},
+		},
+	}
+
+	result, err := service.MergeAnalyses([]SheetAnalysis{architectural, electrical})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Takeoff.RoomCount != 2 {
+		t.Errorf("expected 2 rooms, got %d", result.Takeoff.RoomCount)
+	}
+	if result.Takeoff.TotalArea != 450 {
+		t.Errorf("expected total area 450, got %f", result.Takeoff.TotalArea)
+	}
+	if result.Takeoff.FixtureCounts["electrical"] != 22 {
+		t.Errorf("expected 22 electrical fixtures from the electrical sheet, got %d", result.Takeoff.FixtureCounts["electrical"])
+	}
+	// No plumbing sheet, so plumbing falls back to the architectural plan
+	if result.Takeoff.FixtureCounts["plumbing"] != 2 {
+		t.Errorf("expected 2 plumbing fixtures, got %d", result.Takeoff.FixtureCounts["plumbing"])
+	}
+	if result.Takeoff.OpeningCounts["door"] != 3 {
+		t.Errorf("expected 3 doors, got %d", result.Takeoff.OpeningCounts["door"])
+	}
+
+	expectSource := func(key string, want uuid.UUID) {
+		t.Helper()
+		refs := result.Sources[key]
+		if len(refs) != 1 || refs[0].BlueprintID != want {
+			t.Errorf("expected %s to come from %s, got %+v", key, want, refs)
+		}
+	}
+	expectSource("rooms", architectural.Sheet.BlueprintID)
+	expectSource("openings", architectural.Sheet.BlueprintID)
+	expectSource("fixtures.electrical", electrical.Sheet.BlueprintID)
+	expectSource("fixtures.plumbing", architectural.Sheet.BlueprintID)
+}
+
+func TestMergeAnalyses_ElectricalFallsBackToArchitectural(t *testing.T) {
+	service := NewTakeoffService()
+
+	result, err := service.MergeAnalyses([]SheetAnalysis{{
+		Sheet: models.SheetRef{BlueprintID: uuid.New(), Filename: "A-101.pdf", SheetType: models.SheetTypeArchitectural},
+		Analysis: &models.AnalysisResult{
+			Fixtures: []models.Fixture{{FixtureType: "light", Category: "electrical", Count: 8}},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Takeoff.FixtureCounts["electrical"] != 8 {
+		t.Errorf("expected 8 electrical fixtures, got %d", result.Takeoff.FixtureCounts["electrical"])
+	}
+}
+
+func TestMergeAnalyses_OverlappingRoomNames(t *testing.T) {
+	service := NewTakeoffService()
+
+	// Two floor plans that both name a room Kitchen
+	first := SheetAnalysis{
+		Sheet: models.SheetRef{BlueprintID: uuid.New(), Filename: "A-101.pdf", SheetType: models.SheetTypeArchitectural},
+		Analysis: &models.AnalysisResult{
+			Rooms:    []models.Room{{Name: "Kitchen", Area: 150}, {Name: "Den", Area: 200}},
+			Openings: []models.Opening{{OpeningType: "door", Count: 2}},
+		},
+	}
+	second := SheetAnalysis{
+		Sheet: models.SheetRef{BlueprintID: uuid.New(), Filename: "A-102.pdf", SheetType: models.SheetTypeArchitectural},
+		Analysis: &models.AnalysisResult{
+			Rooms:    []models.Room{{Name: "Kitchen", Area: 120}},
+			Openings: []models.Opening{{OpeningType: "door", Count: 1}, {OpeningType: "window", Count: 4}},
+		},
+	}
+
+	result, err := service.MergeAnalyses([]SheetAnalysis{first, second})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Rooms are not de-duplicated
+	takeoff := result.Takeoff
+	if takeoff.RoomCount != 3 || takeoff.TotalArea != 470 || result.BlueprintCount != 2 {
+		t.Errorf("merged = %d rooms, %v SF from %d blueprints; want 3 rooms, 470 SF from 2", takeoff.RoomCount, takeoff.TotalArea, result.BlueprintCount)
+	}
+	if takeoff.OpeningCounts["door"] != 3 || takeoff.OpeningCounts["window"] != 4 {
+		t.Errorf("opening counts = %v, want 3 doors and 4 windows", takeoff.OpeningCounts)
+	}
+	kitchens := make(map[uuid.UUID]float64)
+	for _, room := range takeoff.RoomBreakdown {
+		if room.SourceBlueprintID == nil {
+			t.Fatalf("room %q has no source blueprint", room.Name)
+		}
+		if room.Name == "Kitchen" {
+			kitchens[*room.SourceBlueprintID] = room.Area
+		}
+	}
+	if kitchens[first.Sheet.BlueprintID] != 150 || kitchens[second.Sheet.BlueprintID] != 120 {
+		t.Errorf("kitchens by blueprint = %v, want one from each sheet", kitchens)
+	}
+	if len(takeoff.Warnings) != 1 || !strings.Contains(takeoff.Warnings[0], `"Kitchen" appears on 2 blueprints`) {
+		t.Errorf("warnings = %v, want the shared kitchen flagged", takeoff.Warnings)
+	}
+	if first.Analysis.Rooms[0].SourceBlueprintID != nil {
+		t.Error("merging annotated the sheet's own analysis")
+	}
+}
+
+func poisonedAnalysis() *models.AnalysisResult {
+	return &models.AnalysisResult{
+		BlueprintID: "test-id",
+		Status:      "completed",
+		Rooms: []models.Room{
+			{Name: "Living Room", Dimensions: "20x15", Area: 300},
+			{Name: "Closet", Dimensions: "", Area: 0},
+			{Name: "Hall", Dimensions: "3x10", Area: -30},
+			{Name: "Kitchen", Dimensions: "12x10", Area: 120},
+		},
+	}
+}
+
+func TestCalculateTakeoffSummary_ClampsInvalidAreas(t *testing.T) {
+	summary, err := NewTakeoffService().CalculateTakeoffSummary(poisonedAnalysis())
+	if err != nil {
+		t.Fatalf("CalculateTakeoffSummary() error = %v", err)
+	}
+
+	if summary.TotalArea != 420 {
+		t.Errorf("Expected negative area to be clamped (TotalArea 420), got %v", summary.TotalArea)
+	}
+	if summary.RoomCount != 4 {
+		t.Errorf("Expected zero-area rooms to still count, got RoomCount %d", summary.RoomCount)
+	}
+	for _, room := range summary.RoomBreakdown {
+		if room.Area < 0 {
+			t.Errorf("Room %q kept negative area %v", room.Name, room.Area)
+		}
+	}
+	if summary.TotalPerimeter < 0 {
+		t.Errorf("Expected non-negative perimeter, got %v", summary.TotalPerimeter)
+	}
+
+	if len(summary.Warnings) == 0 || !strings.Contains(summary.Warnings[0], "Hall") {
+		t.Errorf("Expected a warning naming the negative-area room, got %v", summary.Warnings)
+	}
+	if len(summary.UnmeasuredRooms) != 2 {
+		t.Errorf("Expected Closet and Hall to be unmeasured, got %v", summary.UnmeasuredRooms)
+	}
+
+	// 2 of 4 rooms unmeasured exceeds the default 25% threshold
+	if !summary.NeedsReview {
+		t.Error("Expected takeoff to be flagged for review")
+	}
+}
+
+func TestCalculateTakeoffSummary_ReviewFractionConfigurable(t *testing.T) {
+	service := &TakeoffService{UnmeasuredReviewFraction: 0.5}
+
+	summary, err := service.CalculateTakeoffSummary(poisonedAnalysis())
+	if err != nil {
+		t.Fatalf("CalculateTakeoffSummary() error = %v", err)
+	}
+	if summary.NeedsReview {
+		t.Error("Expected 2 of 4 unmeasured rooms not to exceed a 50% threshold")
+	}
+}
+
+func TestRoomPerimeter(t *testing.T) {
+	tests := []struct {
+		dimensions string
+		want       float64
+		ok         bool
+	}{
+		{"20x15", 70, true},
+		{"20 x 15", 70, true},
+		{"20'x15'", 70, true},
+		{`12'-6" x 10'`, 45, true},
+		{"", 0, false},
+		{"20", 0, false},
+		{"twenty by fifteen", 0, false},
+		{"20x", 0, false},
+		{"0x15", 0, false},
+		{"20x15x9", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := roomPerimeter(tt.dimensions)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("roomPerimeter(%q) = %v, %v; want %v, %v", tt.dimensions, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCalculateTakeoffSummary_WallArea(t *testing.T) {
+	rooms := []models.Room{
+		{Name: "Living Room", Dimensions: "20x15", Area: 300},
+		{Name: "Kitchen", Dimensions: "12' x 10'", Area: 120},
+		{Name: "Den", Dimensions: "about twelve square", Area: 144},
+		{Name: "Closet", Area: 20},
+	}
+
+	summary, err := NewTakeoffService().CalculateTakeoffSummary(&models.AnalysisResult{Rooms: rooms})
+	if err != nil {
+		t.Fatalf("CalculateTakeoffSummary() error = %v", err)
+	}
+	// (70 + 44) LF at the default 8 ft
+	if summary.CeilingHeight != DefaultCeilingHeight || summary.WallArea != 912 {
+		t.Errorf("wall area = %v at %v ft, want 912 at the default height", summary.WallArea, summary.CeilingHeight)
+	}
+	if len(summary.Warnings) != 1 || !strings.Contains(summary.Warnings[0], "Den") {
+		t.Errorf("warnings = %v, want one naming the unreadable Den", summary.Warnings)
+	}
+
+	summary, err = NewTakeoffService().CalculateTakeoffSummary(&models.AnalysisResult{
+		Rooms: rooms,
+		Measurements: []models.Measurement{
+			{MeasurementType: "wall_length", Value: 40, Unit: "LF"},
+			{MeasurementType: "ceiling_height", Value: 9, Unit: "ft"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CalculateTakeoffSummary() error = %v", err)
+	}
+	if summary.CeilingHeight != 9 || summary.WallArea != 1026 {
+		t.Errorf("wall area = %v at %v ft, want 1026 at 9 ft", summary.WallArea, summary.CeilingHeight)
+	}
+
+	summary, err = NewTakeoffService().CalculateTakeoffSummary(&models.AnalysisResult{
+		Measurements: []models.Measurement{{MeasurementType: "ceiling_height", Value: 3, Unit: "m"}},
+	})
+	if err != nil {
+		t.Fatalf("CalculateTakeoffSummary() error = %v", err)
+	}
+	if summary.CeilingHeight != 9.84 || summary.WallArea != 0 {
+		t.Errorf("3 m ceiling = %v ft with wall area %v, want 9.84 ft and none", summary.CeilingHeight, summary.WallArea)
+	}
+}
+
+func TestPricing_PoisonedAnalysisNeverNegative(t *testing.T) {
+	analysisJSON, err := json.Marshal(poisonedAnalysis())
+	if err != nil {
+		t.Fatalf("Failed to marshal analysis: %v", err)
+	}
+
+	service := NewPricingService()
+	takeoff, analysis, err := service.ParseTakeoffData(string(analysisJSON))
+	if err != nil {
+		t.Fatalf("ParseTakeoffData() error = %v", err)
+	}
+	if takeoff.TotalArea != 420 || !takeoff.NeedsReview || len(takeoff.Warnings) == 0 {
+		t.Errorf("Expected ParseTakeoffData to clamp and flag, got area=%v review=%v warnings=%v",
+			takeoff.TotalArea, takeoff.NeedsReview, takeoff.Warnings)
+	}
+
+	summary, err := service.GeneratePricingSummary(takeoff, analysis, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary() error = %v", err)
+	}
+	for _, item := range summary.LineItems {
+		if item.Quantity < 0 || item.Total < 0 {
+			t.Errorf("Line item %q is negative: %+v", item.Description, item)
+		}
+	}
+}
+
+func TestPricing_NegativeLineItemInvariant(t *testing.T) {
+	analysis := &models.AnalysisResult{
+		Openings: []models.Opening{{OpeningType: "door", Count: 3}},
+	}
+
+	// A bad price (e.g. from a mistyped override) must not produce a negative bid
+	service := NewPricingService()
+	config := *service.GetDefaultPricingConfig()
+	config.MaterialPrices = map[string]float64{"door": -450}
+
+	if _, err := service.GeneratePricingSummary(nil, analysis, &config); err == nil {
+		t.Error("Expected negative door price to violate the pricing invariant")
+	}
+
+	if err := ValidateLineItems([]models.LineItem{{Description: "Refund", Quantity: 1, Total: -10}}); err == nil {
+		t.Error("Expected negative total to be rejected")
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// TaxExemptNote is printed in place of the tax line on tax-exempt projects
+const TaxExemptNote = "Tax exempt project: no sales tax included"
+
+// ValidateTaxSettings checks a company's tax rules and normalizes them in
+// place: a missing basis defaults to labor and materials, and override
+// regions are trimmed and lowercased so they match the project's region.
+func ValidateTaxSettings(settings *models.TaxSettings) error {
+	if settings == nil {
+		return nil
+	}
+	if settings.Basis == "" {
+		settings.Basis = models.TaxBasisLaborAndMaterials
+	}
+	if err := validateTaxRule(models.TaxRule{Rate: settings.DefaultRate, Basis: settings.Basis}); err != nil {
+		return err
+	}
+
+	if len(settings.RegionOverrides) == 0 {
+		settings.RegionOverrides = nil
+		return nil
+	}
+	overrides := make(map[string]models.TaxRule, len(settings.RegionOverrides))
+	for region, rule := range settings.RegionOverrides {
+		key := normalizeTaxRegion(region)
+		if key == "" {
+			return fmt.Errorf("tax override region cannot be empty")
+		}
+		if _, ok := overrides[key]; ok {
+			return fmt.Errorf("duplicate tax override for region %q", key)
+		}
+		if err := validateTaxRule(rule); err != nil {
+			return fmt.Errorf("tax override for region %q: %w", key, err)
+		}
+		overrides[key] = rule
+	}
+	settings.RegionOverrides = overrides
+	return nil
+}
+
+func validateTaxRule(rule models.TaxRule) error {
+	if rule.Rate < 0 || rule.Rate > 100 {
+		return fmt.Errorf("tax rate must be between 0 and 100")
+	}
+	switch rule.Basis {
+	case "", models.TaxBasisMaterialsOnly, models.TaxBasisLaborAndMaterials, models.TaxBasisTotalContract:
+		return nil
+	default:
+		return fmt.Errorf("invalid tax basis %q: must be materials_only, labor_and_materials or total_contract", rule.Basis)
+	}
+}
+
+func normalizeTaxRegion(region string) string {
+	return strings.ToLower(strings.TrimSpace(region))
+}
+
+// ResolveTaxRule returns the tax rule for a project in region and the
+// override region that supplied it, empty when the default applied
+func ResolveTaxRule(settings *models.TaxSettings, region string) (models.TaxRule, string) {
+	rule := models.TaxRule{Rate: settings.DefaultRate, Basis: settings.Basis}
+	if rule.Basis == "" {
+		rule.Basis = models.TaxBasisLaborAndMaterials
+	}
+
+	key := normalizeTaxRegion(region)
+	override, ok := settings.RegionOverrides[key]
+	if key == "" || !ok {
+		return rule, ""
+	}
+	rule.Rate = override.Rate
+	if override.Basis != "" {
+		rule.Basis = override.Basis
+	}
+	return rule, key
+}
+
+// NewTaxSummary is the tax to charge a project in region. It is nil when the
+// company has no tax settings and the project is not exempt.
+func NewTaxSummary(settings *models.TaxSettings, region string, exempt bool) *models.TaxSummary {
+	if exempt {
+		tax := &models.TaxSummary{Exempt: true, Note: TaxExemptNote}
+		if settings != nil {
+			rule, _ := ResolveTaxRule(settings, region)
+			tax.Basis = rule.Basis
+		}
+		return tax
+	}
+	if settings == nil {
+		return nil
+	}
+
+	rule, matched := ResolveTaxRule(settings, region)
+	return &models.TaxSummary{Rate: rule.Rate, Basis: rule.Basis, Region: matched}
+}
+
+// ApplyPricingTax charges tax on an estimate. Line item bases carry the
+// profit markup but not overhead; a total contract basis taxes the whole
+// price. TotalPrice is recalculated to include the tax and the confidence
+// range moves with it.
+func ApplyPricingTax(summary *models.PricingSummary, tax *models.TaxSummary) {
+	if summary == nil || tax == nil {
+		return
+	}
+	applied := *tax
+	summary.Tax = &applied
+
+	preTax := summary.Subtotal + summary.OverheadAmount + summary.MarkupAmount
+	markupRate := 0.0
+	if base := summary.Subtotal + summary.OverheadAmount; base > 0 {
+		markupRate = summary.MarkupAmount / base
+	}
+	summary.TaxAmount = chargeTax(summary.LineItems, summary.Tax, preTax, markupRate)
+
+	oldTotal := summary.TotalPrice
+	summary.TotalPrice = math.Round((preTax+summary.TaxAmount)*100) / 100
+	if applied.Exempt && applied.Note != "" {
+		summary.Notes = append(summary.Notes, applied.Note)
+	}
+
+	if r := summary.ConfidenceRange; r != nil && oldTotal > 0 && summary.TotalPrice != oldTotal {
+		scale := summary.TotalPrice / oldTotal
+		r.Low = math.Round(r.Low*scale*100) / 100
+		r.Likely = math.Round(r.Likely*scale*100) / 100
+		r.High = math.Round(r.High*scale*100) / 100
+	}
+}
+
+// ApplyBidTax charges tax on a bid, recording how so edits and reprices can
+// recalculate it
+func ApplyBidTax(bid *models.GenerateBidResponse, tax *models.TaxSummary) {
+	if tax == nil {
+		return
+	}
+	applied := *tax
+	bid.Tax = &applied
+	RecomputeBidTax(bid)
+}
+
+// RecomputeBidTax recalculates a bid's tax from its current line items and
+// totals, leaving bids priced without tax alone. Bid markup applies to the
+// subtotal, so line item bases carry it in full.
+func RecomputeBidTax(bid *models.GenerateBidResponse) {
+	if bid.Tax == nil {
+		return
+	}
+	preTax := bid.Subtotal + bid.MarkupAmount
+	markupRate := 0.0
+	if bid.Subtotal > 0 {
+		markupRate = bid.MarkupAmount / bid.Subtotal
+	}
+	bid.TaxAmount = chargeTax(bid.LineItems, bid.Tax, preTax, markupRate)
+	bid.TotalPrice = math.Round((preTax+bid.TaxAmount)*100) / 100
+}
+
+// chargeTax sets each line item's tax and tax.TaxableAmount and returns the
+// tax owed. Line item tax is only itemized for the per-item bases.
+func chargeTax(items []models.LineItem, tax *models.TaxSummary, preTax, markupRate float64) float64 {
+	for i := range items {
+		items[i].TaxAmount = 0
+	}
+	tax.TaxableAmount = 0
+	if tax.Exempt {
+		return 0
+	}
+
+	if tax.Basis == models.TaxBasisTotalContract {
+		tax.TaxableAmount = math.Round(preTax*100) / 100
+		return math.Round(preTax*tax.Rate) / 100
+	}
+
+	var taxable, total float64
+	for i := range items {
+		if items[i].IsAlternate {
+			continue
+		}
+		base := items[i].Total
+		if tax.Basis == models.TaxBasisMaterialsOnly {
+			base = lineItemMaterialCost(items[i])
+		}
+		base *= 1 + markupRate
+		taxable += base
+		items[i].TaxAmount = math.Round(base*tax.Rate) / 100
+		total += items[i].TaxAmount
+	}
+	tax.TaxableAmount = math.Round(taxable*100) / 100
+	return math.Round(total*100) / 100
+}
+
+// lineItemMaterialCost is the material share of a line item's current total.
+// Items without a recorded split are all labor when priced by the hour and
+// all material otherwise, matching the bid's labor and material totals.
+func lineItemMaterialCost(item models.LineItem) float64 {
+	if split := item.MaterialCost + item.LaborCost; split > 0 {
+		return item.Total * item.MaterialCost / split
+	}
+	if isLaborLineItem(item) {
+		return 0
+	}
+	return item.Total
+}
+
+// splitLineItemCost records the labor and material shares of item's total
+// so sales tax can be charged on the material part alone
+func splitLineItemCost(item *models.LineItem, laborShare float64) {
+	item.LaborCost = math.Round(item.Total*laborShare*100) / 100
+	item.MaterialCost = math.Round((item.Total-item.LaborCost)*100) / 100
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func taxedTestSummary(t *testing.T) *models.PricingSummary {
+	t.Helper()
+	summary, err := NewPricingService().GeneratePricingSummary(&models.TakeoffSummary{TotalArea: 200}, fixtureAnalysis(4), nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	return summary
+}
+
+func assertPricingReconciles(t *testing.T, summary *models.PricingSummary) {
+	t.Helper()
+	sum := summary.Subtotal + summary.OverheadAmount + summary.MarkupAmount + summary.TaxAmount
+	if math.Abs(sum-summary.TotalPrice) > 0.005 {
+		t.Errorf("subtotal %.2f + overhead %.2f + markup %.2f + tax %.2f = %.2f, total price %.2f",
+			summary.Subtotal, summary.OverheadAmount, summary.MarkupAmount, summary.TaxAmount, sum, summary.TotalPrice)
+	}
+}
+
+func assertBidReconciles(t *testing.T, bid *models.GenerateBidResponse) {
+	t.Helper()
+	sum := bid.Subtotal + bid.MarkupAmount + bid.TaxAmount
+	if math.Abs(sum-bid.TotalPrice) > 0.005 {
+		t.Errorf("subtotal %.2f + markup %.2f + tax %.2f = %.2f, total price %.2f",
+			bid.Subtotal, bid.MarkupAmount, bid.TaxAmount, sum, bid.TotalPrice)
+	}
+}
+
+func TestApplyPricingTax_Bases(t *testing.T) {
+	untaxed := taxedTestSummary(t)
+	preTax := untaxed.TotalPrice
+	markupRate := untaxed.MarkupAmount / (untaxed.Subtotal + untaxed.OverheadAmount)
+
+	var materials float64
+	for _, item := range untaxed.LineItems {
+		if item.MaterialCost+item.LaborCost == 0 && item.Total > 0 {
+			t.Errorf("line item %q has no labor and material split", item.Description)
+		}
+		materials += item.MaterialCost
+	}
+
+	taxes := make(map[models.TaxBasis]float64)
+	for _, basis := range []models.TaxBasis{models.TaxBasisMaterialsOnly, models.TaxBasisLaborAndMaterials, models.TaxBasisTotalContract} {
+		t.Run(string(basis), func(t *testing.T) {
+			summary := taxedTestSummary(t)
+			ApplyPricingTax(summary, &models.TaxSummary{Rate: 10, Basis: basis})
+
+			assertPricingReconciles(t, summary)
+			if summary.Tax == nil || summary.Tax.Basis != basis {
+				t.Fatalf("Tax = %+v, want basis %s", summary.Tax, basis)
+			}
+			if summary.TaxAmount <= 0 {
+				t.Fatalf("TaxAmount = %.2f, want positive", summary.TaxAmount)
+			}
+			taxes[basis] = summary.TaxAmount
+
+			var itemized float64
+			for _, item := range summary.LineItems {
+				itemized += item.TaxAmount
+			}
+			switch basis {
+			case models.TaxBasisMaterialsOnly:
+				want := materials * (1 + markupRate) * 0.10
+				if math.Abs(summary.TaxAmount-want) > 0.05 {
+					t.Errorf("TaxAmount = %.2f, want about %.2f on marked-up materials", summary.TaxAmount, want)
+				}
+				if math.Abs(itemized-summary.TaxAmount) > 0.005 {
+					t.Errorf("line item taxes sum to %.2f, want %.2f", itemized, summary.TaxAmount)
+				}
+			case models.TaxBasisLaborAndMaterials:
+				want := untaxed.Subtotal * (1 + markupRate) * 0.10
+				if math.Abs(summary.TaxAmount-want) > 0.05 {
+					t.Errorf("TaxAmount = %.2f, want about %.2f on marked-up line items", summary.TaxAmount, want)
+				}
+				if math.Abs(itemized-summary.TaxAmount) > 0.005 {
+					t.Errorf("line item taxes sum to %.2f, want %.2f", itemized, summary.TaxAmount)
+				}
+			case models.TaxBasisTotalContract:
+				if want := math.Round(preTax*10) / 100; summary.TaxAmount != want {
+					t.Errorf("TaxAmount = %.2f, want %.2f on the contract price", summary.TaxAmount, want)
+				}
+				if summary.Tax.TaxableAmount != preTax {
+					t.Errorf("TaxableAmount = %.2f, want %.2f", summary.Tax.TaxableAmount, preTax)
+				}
+			}
+		})
+	}
+
+	if !(taxes[models.TaxBasisMaterialsOnly] < taxes[models.TaxBasisLaborAndMaterials] &&
+		taxes[models.TaxBasisLaborAndMaterials] < taxes[models.TaxBasisTotalContract]) {
+		t.Errorf("taxes by basis = %v, want materials < labor and materials < total contract", taxes)
+	}
+}
+
+func TestApplyPricingTax_ScalesConfidenceRange(t *testing.T) {
+	summary := taxedTestSummary(t)
+	summary.ConfidenceRange = &models.ConfidenceRange{Low: 900, Likely: summary.TotalPrice, High: 1100}
+	ApplyPricingTax(summary, &models.TaxSummary{Rate: 10, Basis: models.TaxBasisTotalContract})
+
+	if math.Abs(summary.ConfidenceRange.Likely-summary.TotalPrice) > 0.01 {
+		t.Errorf("Likely = %.2f, want the taxed total %.2f", summary.ConfidenceRange.Likely, summary.TotalPrice)
+	}
+	if summary.ConfidenceRange.High <= 1100 {
+		t.Errorf("High = %.2f, want it raised by the tax", summary.ConfidenceRange.High)
+	}
+}
+
+func TestResolveTaxRule_RegionalOverride(t *testing.T) {
+	settings := &models.TaxSettings{
+		DefaultRate: 5,
+		RegionOverrides: map[string]models.TaxRule{
+			" Texas ":  {Rate: 8.25},
+			"Oregon":   {Rate: 0},
+			"new_york": {Rate: 8.875, Basis: models.TaxBasisMaterialsOnly},
+		},
+	}
+	if err := ValidateTaxSettings(settings); err != nil {
+		t.Fatalf("ValidateTaxSettings() error = %v", err)
+	}
+	if settings.Basis != models.TaxBasisLaborAndMaterials {
+		t.Errorf("Basis = %q, want the labor and materials default", settings.Basis)
+	}
+
+	tests := []struct {
+		region     string
+		wantRate   float64
+		wantBasis  models.TaxBasis
+		wantRegion string
+	}{
+		{"TEXAS", 8.25, models.TaxBasisLaborAndMaterials, "texas"},
+		{"oregon", 0, models.TaxBasisLaborAndMaterials, "oregon"},
+		{"new_york", 8.875, models.TaxBasisMaterialsOnly, "new_york"},
+		{"california", 5, models.TaxBasisLaborAndMaterials, ""},
+		{"", 5, models.TaxBasisLaborAndMaterials, ""},
+	}
+	for _, tt := range tests {
+		tax := NewTaxSummary(settings, tt.region, false)
+		if tax == nil || tax.Rate != tt.wantRate || tax.Basis != tt.wantBasis || tax.Region != tt.wantRegion {
+			t.Errorf("NewTaxSummary(%q) = %+v, want rate %g basis %s region %q", tt.region, tax, tt.wantRate, tt.wantBasis, tt.wantRegion)
+		}
+	}
+
+	// The override changes the price of the same takeoff
+	texas, california := taxedTestSummary(t), taxedTestSummary(t)
+	ApplyPricingTax(texas, NewTaxSummary(settings, "texas", false))
+	ApplyPricingTax(california, NewTaxSummary(settings, "california", false))
+	assertPricingReconciles(t, texas)
+	assertPricingReconciles(t, california)
+	if texas.TaxAmount <= california.TaxAmount {
+		t.Errorf("texas tax %.2f, want more than the default %.2f", texas.TaxAmount, california.TaxAmount)
+	}
+}
+
+func TestApplyPricingTax_Exempt(t *testing.T) {
+	settings := &models.TaxSettings{DefaultRate: 7, Basis: models.TaxBasisTotalContract}
+	summary := taxedTestSummary(t)
+	preTax := summary.TotalPrice
+
+	ApplyPricingTax(summary, NewTaxSummary(settings, "", true))
+
+	assertPricingReconciles(t, summary)
+	if summary.TaxAmount != 0 || summary.TotalPrice != preTax {
+		t.Errorf("TaxAmount = %.2f, TotalPrice = %.2f; want no tax on %.2f", summary.TaxAmount, summary.TotalPrice, preTax)
+	}
+	if summary.Tax == nil || !summary.Tax.Exempt || summary.Tax.Note != TaxExemptNote {
+		t.Errorf("Tax = %+v, want exempt with the exemption note", summary.Tax)
+	}
+	if !slices.Contains(summary.Notes, TaxExemptNote) {
+		t.Errorf("Notes = %v, want the exemption note", summary.Notes)
+	}
+
+	// Exempt projects carry the note even when the company charges no tax
+	if tax := NewTaxSummary(nil, "", true); tax == nil || !tax.Exempt {
+		t.Errorf("NewTaxSummary(nil, exempt) = %+v, want exempt", tax)
+	}
+	if tax := NewTaxSummary(nil, "texas", false); tax != nil {
+		t.Errorf("NewTaxSummary(nil) = %+v, want nil", tax)
+	}
+}
+
+func TestBidTax_RecomputedWithTotals(t *testing.T) {
+	summary := taxedTestSummary(t)
+	summary.Tax = &models.TaxSummary{Rate: 8, Basis: models.TaxBasisMaterialsOnly}
+
+	bid := PricedBidResponse(summary, 20)
+	assertBidReconciles(t, &bid)
+	if bid.TaxAmount <= 0 || bid.Tax == nil {
+		t.Fatalf("TaxAmount = %.2f, Tax = %+v; want the summary's tax charged", bid.TaxAmount, bid.Tax)
+	}
+
+	// Labor items carry no material and so no materials-only tax
+	for _, item := range bid.LineItems {
+		if isLaborLineItem(item) && item.TaxAmount != 0 {
+			t.Errorf("labor item %q taxed %.2f under materials only", item.Description, item.TaxAmount)
+		}
+	}
+
+	// Moving an item to an alternate takes its tax out of the base bid
+	before := bid.TaxAmount
+	for i := range bid.LineItems {
+		if bid.LineItems[i].Description == "Paint and finishing" {
+			bid.LineItems[i].IsAlternate = true
+			bid.LineItems[i].AlternateGroup = "Paint"
+		}
+	}
+	ApplyAlternates(&bid, 20)
+	assertBidReconciles(t, &bid)
+	if bid.TaxAmount >= before {
+		t.Errorf("TaxAmount = %.2f after removing paint, want less than %.2f", bid.TaxAmount, before)
+	}
+
+	// Editing a quantity recharges tax under the original rule
+	edited := bid
+	edited.Tax = nil
+	edited.LineItems = append([]models.LineItem(nil), bid.LineItems...)
+	for i := range edited.LineItems {
+		if edited.LineItems[i].Description == "Electrical fixtures and outlets" {
+			edited.LineItems[i].Quantity *= 2
+		}
+	}
+	if err := ApplyBidEdits(&bid, &edited, 20); err != nil {
+		t.Fatalf("ApplyBidEdits() error = %v", err)
+	}
+	assertBidReconciles(t, &edited)
+	if edited.Tax == nil || edited.TaxAmount <= bid.TaxAmount {
+		t.Errorf("edited TaxAmount = %.2f, want more than %.2f", edited.TaxAmount, bid.TaxAmount)
+	}
+}
+
+func TestMarkLineItemProvenance_CopiesCostSplit(t *testing.T) {
+	summary := taxedTestSummary(t)
+	items := []models.LineItem{{Description: "Window installation", Trade: "carpentry", Unit: "each", Quantity: 1, Total: 100}}
+	summary.LineItems = append(summary.LineItems, models.LineItem{
+		Description: "Window installation", Trade: "carpentry", Unit: "each", Total: 50, MaterialCost: 40, LaborCost: 10,
+	})
+
+	MarkLineItemProvenance(items, summary)
+	if got := lineItemMaterialCost(items[0]); math.Abs(got-80) > 0.001 {
+		t.Errorf("material share = %.2f, want 80 of 100 from the 80/20 split", got)
+	}
+}
+
+func TestValidateTaxSettings_Invalid(t *testing.T) {
+	tests := map[string]*models.TaxSettings{
+		"negative rate":          {DefaultRate: -1},
+		"rate over 100":          {DefaultRate: 101},
+		"unknown basis":          {DefaultRate: 5, Basis: "labor_only"},
+		"blank region":           {DefaultRate: 5, RegionOverrides: map[string]models.TaxRule{" ": {Rate: 1}}},
+		"duplicate region":       {DefaultRate: 5, RegionOverrides: map[string]models.TaxRule{"Texas": {Rate: 1}, "texas": {Rate: 2}}},
+		"invalid override":       {DefaultRate: 5, RegionOverrides: map[string]models.TaxRule{"texas": {Rate: 200}}},
+		"invalid override basis": {DefaultRate: 5, RegionOverrides: map[string]models.TaxRule{"texas": {Rate: 1, Basis: "gross"}}},
+	}
+	for name, settings := range tests {
+		if err := ValidateTaxSettings(settings); err == nil {
+			t.Errorf("%s: ValidateTaxSettings() = nil, want an error", name)
+		}
+	}
+	if err := ValidateTaxSettings(nil); err != nil {
+		t.Errorf("ValidateTaxSettings(nil) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/trades"
+)
+
+// DefaultTradeMinimums returns the minimum charge per trade for sending a
+// crew out at all, however small the job
+func DefaultTradeMinimums() map[string]float64 {
+	return map[string]float64{
+		"electrical": 350.00,
+		"plumbing":   450.00,
+		"hvac":       500.00,
+	}
+}
+
+// applyTradeMinimums tops up each trade whose line items total less than its
+// minimum charge with a "Minimum service charge" item for the difference.
+// Trades without line items are not charged. costsByTrade is updated in place;
+// the added total and a note per charge are returned.
+func applyTradeMinimums(
+	items []models.LineItem,
+	minimums map[string]float64,
+	costsByTrade map[string]float64,
+	source *models.PriceSource,
+) ([]models.LineItem, float64, []string) {
+	subtotals := CostsByTrade(items)
+
+	var added float64
+	var notes []string
+	for _, trade := range sortedKeys(minimums) {
+		subtotal, hasItems := subtotals[trade]
+		if !hasItems {
+			continue
+		}
+		minimum := minimums[trade]
+		shortfall := math.Round((minimum-subtotal)*100) / 100
+		if shortfall <= 0 {
+			continue
+		}
+
+		items = append(items, models.LineItem{
+			Description: fmt.Sprintf("Minimum service charge - %s", trade),
+			Trade:       trade,
+			Quantity:    1,
+			Unit:        "lot",
+			UnitCost:    shortfall,
+			Total:       shortfall,
+			PriceSource: source,
+			LaborCost:   shortfall,
+		})
+		added += shortfall
+		costsByTrade[trade] += shortfall
+		notes = append(notes, fmt.Sprintf("%s work totals $%.2f, below the $%.2f minimum service charge; $%.2f added",
+			trades.DisplayName(trade), subtotal, minimum, shortfall))
+	}
+	return items, added, notes
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func fixtureAnalysis(count int) *models.AnalysisResult {
+	return &models.AnalysisResult{
+		Fixtures: []models.Fixture{{FixtureType: "outlet", Category: "electrical", Count: count}},
+	}
+}
+
+func minimumChargeItem(summary *models.PricingSummary, trade string) *models.LineItem {
+	for i := range summary.LineItems {
+		item := &summary.LineItems[i]
+		if item.Trade == trade && item.Description == "Minimum service charge - "+trade {
+			return item
+		}
+	}
+	return nil
+}
+
+func TestTradeMinimums_BelowMinimum(t *testing.T) {
+	// One outlet is $125 plus half a $95 labor hour, $177.50 short of the $350
+	// minimum
+	basic, err := NewPricingService().GeneratePricingSummary(nil, fixtureAnalysis(1), nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	enhanced, err := NewEnhancedPricingService(nil, nil, nil, nil).GeneratePricingSummary(context.Background(), nil, fixtureAnalysis(1), nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+
+	for name, summary := range map[string]*models.PricingSummary{"basic": basic, "enhanced": enhanced} {
+		item := minimumChargeItem(summary, "electrical")
+		if item == nil {
+			t.Fatalf("%s: expected an electrical minimum charge, got %+v", name, summary.LineItems)
+		}
+		if item.Total != 177.5 || item.Quantity != 1 {
+			t.Errorf("%s: minimum charge = %v x %v, want 1 x 177.5", name, item.Quantity, item.Total)
+		}
+		if summary.Subtotal != 350 {
+			t.Errorf("%s: subtotal = %v, want the 350 minimum", name, summary.Subtotal)
+		}
+		// Overhead and markup apply on top of the minimum
+		if summary.OverheadAmount != 52.5 {
+			t.Errorf("%s: overhead = %v, want 15%% of 350", name, summary.OverheadAmount)
+		}
+		if len(summary.Notes) != 1 {
+			t.Errorf("%s: expected one note, got %v", name, summary.Notes)
+		}
+	}
+	if item := minimumChargeItem(enhanced, "electrical"); item.PriceSource == nil {
+		t.Error("enhanced minimum charge should have a price source")
+	}
+}
+
+func TestTradeMinimums_AboveMinimum(t *testing.T) {
+	summary, err := NewPricingService().GeneratePricingSummary(nil, fixtureAnalysis(10), nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	if item := minimumChargeItem(summary, "electrical"); item != nil {
+		t.Errorf("unexpected minimum charge above the minimum: %+v", item)
+	}
+	if len(summary.Notes) != 0 {
+		t.Errorf("expected no notes, got %v", summary.Notes)
+	}
+}
+
+func TestTradeMinimums_TradeWithoutItems(t *testing.T) {
+	takeoff := &models.TakeoffSummary{TotalArea: 100, RoomCount: 1}
+	summary, err := NewPricingService().GeneratePricingSummary(takeoff, nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePricingSummary failed: %v", err)
+	}
+	for trade := range DefaultTradeMinimums() {
+		if item := minimumChargeItem(summary, trade); item != nil {
+			t.Errorf("trade %s has no work but was charged a minimum: %+v", trade, item)
+		}
+	}
+}
+
+func TestResolvePricing_TradeMinimumOverrides(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	directID, percentID := uuid.New(), uuid.New()
+
+	resolved := resolvePricing(defaults, pricingInputs{
+		overrides: []models.CompanyPricingOverride{
+			{ID: directID, OverrideType: "trade_minimum", ItemKey: "Plumber", OverrideValue: 275},
+			{ID: percentID, OverrideType: "trade_minimum", ItemKey: "hvac", OverrideValue: 10, IsPercentage: true},
+		},
+		regionalFactor: 1.0,
+	})
+
+	config := resolved.Config
+	if config.TradeMinimums["plumbing"] != 275 {
+		t.Errorf("plumbing minimum = %v, want the 275 override", config.TradeMinimums["plumbing"])
+	}
+	if config.TradeMinimums["hvac"] != 500 {
+		t.Errorf("hvac minimum = %v, want the 500 default", config.TradeMinimums["hvac"])
+	}
+	if defaults.TradeMinimums["plumbing"] != 450 {
+		t.Error("Resolving prices must not modify the default minimums")
+	}
+	if len(config.SkippedOverrides) != 1 || config.SkippedOverrides[0].OverrideID != percentID || config.SkippedOverrides[0].Reason != OverrideSkipNotDirect {
+		t.Errorf("expected the percentage minimum skipped, got %+v", config.SkippedOverrides)
+	}
+}
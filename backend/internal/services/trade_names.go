@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/trades"
+)
+
+// CostsByTrade sums line item totals by canonical trade
+func CostsByTrade(items []models.LineItem) map[string]float64 {
+	costs := make(map[string]float64)
+	for _, item := range items {
+		trade, _ := trades.Normalize(item.Trade)
+		costs[trade] += item.Total
+	}
+	return costs
+}
+
+// NormalizeLineItemTrades rewrites each item's trade to its canonical slug.
+// It returns a warning for each item whose trade was not recognized and was
+// moved to general, and whether any trade changed.
+func NormalizeLineItemTrades(items []models.LineItem) ([]string, bool) {
+	var warnings []string
+	changed := false
+	for i := range items {
+		trade, known := trades.Normalize(items[i].Trade)
+		if !known {
+			warnings = append(warnings, fmt.Sprintf("Unrecognized trade %q on %q was priced as general", items[i].Trade, items[i].Description))
+		}
+		if trade != items[i].Trade {
+			items[i].Trade = trade
+			changed = true
+		}
+	}
+	return warnings, changed
+}
+
+// laborRateKey is the key a labor rate or labor override is stored under:
+// the canonical trade, or the trimmed lowercase name for unregistered trades
+// so they never replace the general rate
+func laborRateKey(trade string) string {
+	if slug, known := trades.Normalize(trade); known {
+		return slug
+	}
+	return strings.ToLower(strings.TrimSpace(trade))
+}
+
+// groupByTrade groups line items by canonical trade, keyed by the trade's
+// display name, so spelling variants share one breakdown row
+func groupByTrade(items []models.LineItem) map[string][]models.LineItem {
+	groups := make(map[string][]models.LineItem)
+	for _, item := range items {
+		trade, _ := trades.Normalize(item.Trade)
+		name := trades.DisplayName(trade)
+		groups[name] = append(groups[name], item)
+	}
+	return groups
+}
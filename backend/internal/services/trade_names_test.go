@@ -0,0 +1,77 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestCostsByTrade_MixedSpellingsShareATrade(t *testing.T) {
+	costs := CostsByTrade([]models.LineItem{
+		{Description: "Walls", Trade: "Drywall", Total: 100},
+		{Description: "Ceilings", Trade: "drywall", Total: 50},
+		{Description: "Soffits", Trade: "Dry Wall", Total: 25},
+		{Description: "Partitions", Trade: "framing/drywall", Total: 25},
+		{Description: "Cleanup", Trade: "", Total: 10},
+	})
+
+	if len(costs) != 2 {
+		t.Fatalf("CostsByTrade() = %v, want drywall and general only", costs)
+	}
+	if costs["drywall"] != 200 || costs["general"] != 10 {
+		t.Errorf("CostsByTrade() = %v, want drywall 200 and general 10", costs)
+	}
+}
+
+func TestNormalizeLineItemTrades(t *testing.T) {
+	items := []models.LineItem{
+		{Description: "Outlets", Trade: "Electrical"},
+		{Description: "Fixtures", Trade: "plumbing"},
+		{Description: "Sculpture", Trade: "Public Art"},
+	}
+
+	warnings, changed := NormalizeLineItemTrades(items)
+	if !changed {
+		t.Error("expected trades to be rewritten")
+	}
+	if items[0].Trade != "electrical" || items[1].Trade != "plumbing" || items[2].Trade != "general" {
+		t.Errorf("trades = %q, %q, %q", items[0].Trade, items[1].Trade, items[2].Trade)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"Public Art"`) {
+		t.Errorf("warnings = %v, want one for the unknown trade", warnings)
+	}
+
+	if warnings, changed := NormalizeLineItemTrades(items); changed || len(warnings) != 0 {
+		t.Errorf("expected normalized items to be left alone, got %v, %v", warnings, changed)
+	}
+}
+
+func TestResolvePricing_NormalizesLaborTrades(t *testing.T) {
+	defaults := NewEnhancedPricingService(nil, nil, nil, nil).GetDefaultPricingConfig()
+	overrideID := uuid.New()
+
+	resolved := resolvePricing(defaults, pricingInputs{
+		laborRates: []models.LaborRate{
+			{Trade: "Electrical", HourlyRate: 100, Source: "rsmeans"},
+			{Trade: "Tile Setting", HourlyRate: 80, Source: "rsmeans"},
+		},
+		laborLoaded: true,
+		overrides: []models.CompanyPricingOverride{
+			{ID: overrideID, OverrideType: "labor", ItemKey: "Plumber", OverrideValue: 90},
+		},
+		regionalFactor: 1.0,
+	})
+
+	rates := resolved.Config.LaborRates
+	if rates["electrical"] != 100 {
+		t.Errorf("electrical rate = %v, want the provider rate", rates["electrical"])
+	}
+	if rates["plumbing"] != 90 || len(resolved.Config.AppliedOverrides) != 1 {
+		t.Errorf("plumbing rate = %v, want the override applied by alias", rates["plumbing"])
+	}
+	if rates["general"] != defaults.LaborRates["general"] || rates["tile setting"] != 80 {
+		t.Errorf("unknown trades must keep their own rate, got general %v and tile setting %v", rates["general"], rates["tile setting"])
+	}
+}
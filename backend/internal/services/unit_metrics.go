@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Reasons unit metrics are omitted
+const (
+	UnitMetricsNoArea    = "takeoff has no measured floor area"
+	UnitMetricsNonFinite = "price is not a finite number"
+)
+
+// DefaultCostPerSFBands returns the cost per square foot expected for each
+// project type; a bid outside its band is flagged for review
+func DefaultCostPerSFBands() map[models.ProjectType]models.CostPerSFBand {
+	return map[models.ProjectType]models.CostPerSFBand{
+		models.ProjectTypeNewConstruction: {Min: 100, Max: 350},
+		models.ProjectTypeRenovation:      {Min: 80, Max: 400},
+		models.ProjectTypeAddition:        {Min: 120, Max: 450},
+	}
+}
+
+// ParseCostPerSFBands parses bands written as
+// "<project_type>=<min>-<max>,...". Project types left out keep their
+// default band; an empty value returns the defaults.
+func ParseCostPerSFBands(value string) (map[models.ProjectType]models.CostPerSFBand, error) {
+	bands := DefaultCostPerSFBands()
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, limits, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("cost per SF band must be <project_type>=<min>-<max>, got %q", entry)
+		}
+		projectType, err := ParseProjectType(name)
+		if err != nil || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("unknown project type %q", name)
+		}
+		low, high, found := strings.Cut(limits, "-")
+		if !found {
+			return nil, fmt.Errorf("cost per SF band for %s must be <min>-<max>, got %q", projectType, limits)
+		}
+		minimum, minErr := strconv.ParseFloat(strings.TrimSpace(low), 64)
+		maximum, maxErr := strconv.ParseFloat(strings.TrimSpace(high), 64)
+		if minErr != nil || maxErr != nil || minimum < 0 || maximum <= minimum {
+			return nil, fmt.Errorf("cost per SF band for %s must be two increasing numbers, got %q", projectType, limits)
+		}
+		bands[projectType] = models.CostPerSFBand{Min: minimum, Max: maximum}
+	}
+	return bands, nil
+}
+
+// UnitMetricsInput are the totals of an estimate and the area they cover
+type UnitMetricsInput struct {
+	Area         float64 // Square feet
+	TotalPrice   float64
+	MaterialCost float64
+	LaborCost    float64
+	CostsByTrade map[string]float64
+}
+
+// ComputeUnitMetrics divides an estimate's totals by its area. Without a
+// usable area the metrics are omitted with a reason rather than reported as
+// infinite. When a band is given and the cost per SF falls outside it, the
+// metrics carry a warning.
+func ComputeUnitMetrics(input UnitMetricsInput, band *models.CostPerSFBand) *models.UnitMetrics {
+	if input.Area <= 0 || math.IsNaN(input.Area) || math.IsInf(input.Area, 0) {
+		return &models.UnitMetrics{OmittedReason: UnitMetricsNoArea}
+	}
+
+	perSF := func(amount float64) float64 {
+		return math.Round(amount/input.Area*100) / 100
+	}
+	metrics := &models.UnitMetrics{
+		AreaSF:        input.Area,
+		CostPerSF:     perSF(input.TotalPrice),
+		MaterialPerSF: perSF(input.MaterialCost),
+		LaborPerSF:    perSF(input.LaborCost),
+	}
+	if len(input.CostsByTrade) > 0 {
+		metrics.TradePerSF = make(map[string]float64, len(input.CostsByTrade))
+		for trade, cost := range input.CostsByTrade {
+			metrics.TradePerSF[trade] = perSF(cost)
+		}
+	}
+
+	// JSON cannot encode NaN or Inf, so a bad total must not reach the response
+	values := []float64{metrics.CostPerSF, metrics.MaterialPerSF, metrics.LaborPerSF}
+	for _, value := range metrics.TradePerSF {
+		values = append(values, value)
+	}
+	for _, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return &models.UnitMetrics{OmittedReason: UnitMetricsNonFinite}
+		}
+	}
+
+	if band != nil {
+		expected := *band
+		metrics.ExpectedRange = &expected
+		if metrics.CostPerSF < band.Min || metrics.CostPerSF > band.Max {
+			metrics.Warning = fmt.Sprintf("Cost of $%.2f/SF is outside the expected $%.0f-$%.0f/SF; review quantities and prices",
+				metrics.CostPerSF, band.Min, band.Max)
+		}
+	}
+	return metrics
+}
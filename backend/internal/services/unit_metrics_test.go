@@ -0,0 +1,90 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestComputeUnitMetrics(t *testing.T) {
+	band := &models.CostPerSFBand{Min: 80, Max: 400}
+	metrics := ComputeUnitMetrics(UnitMetricsInput{
+		Area:         1200,
+		TotalPrice:   186000,
+		MaterialCost: 72000,
+		LaborCost:    83000,
+		CostsByTrade: map[string]float64{"framing": 30000, "electrical": 14500},
+	}, band)
+
+	if metrics.OmittedReason != "" || metrics.AreaSF != 1200 {
+		t.Fatalf("metrics = %+v", metrics)
+	}
+	for name, got := range map[string][2]float64{
+		"cost":       {metrics.CostPerSF, 155},
+		"material":   {metrics.MaterialPerSF, 60},
+		"labor":      {metrics.LaborPerSF, 69.17},
+		"framing":    {metrics.TradePerSF["framing"], 25},
+		"electrical": {metrics.TradePerSF["electrical"], 12.08},
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s per SF = %v, want %v", name, got[0], got[1])
+		}
+	}
+	if metrics.Warning != "" || metrics.ExpectedRange == nil || *metrics.ExpectedRange != *band {
+		t.Errorf("expected no warning inside the band, got %+v", metrics)
+	}
+}
+
+func TestComputeUnitMetrics_OutsideBand(t *testing.T) {
+	band := &models.CostPerSFBand{Min: 80, Max: 400}
+	for name, total := range map[string]float64{"low": 50000, "high": 600000} {
+		metrics := ComputeUnitMetrics(UnitMetricsInput{Area: 1000, TotalPrice: total}, band)
+		if !strings.Contains(metrics.Warning, "outside the expected $80-$400/SF") {
+			t.Errorf("%s: warning = %q", name, metrics.Warning)
+		}
+	}
+
+	if metrics := ComputeUnitMetrics(UnitMetricsInput{Area: 1000, TotalPrice: 600000}, nil); metrics.Warning != "" {
+		t.Errorf("expected no warning without a band, got %q", metrics.Warning)
+	}
+}
+
+func TestComputeUnitMetrics_UnusableArea(t *testing.T) {
+	for name, input := range map[string]UnitMetricsInput{
+		"zero area":     {Area: 0, TotalPrice: 1000},
+		"negative area": {Area: -5, TotalPrice: 1000},
+		"NaN area":      {Area: math.NaN(), TotalPrice: 1000},
+		"NaN price":     {Area: 100, TotalPrice: math.NaN()},
+		"infinite cost": {Area: 100, TotalPrice: 1000, CostsByTrade: map[string]float64{"general": math.Inf(1)}},
+	} {
+		metrics := ComputeUnitMetrics(input, &models.CostPerSFBand{Min: 80, Max: 400})
+		if metrics.OmittedReason == "" || metrics.CostPerSF != 0 || metrics.Warning != "" {
+			t.Errorf("%s: metrics = %+v, want omitted", name, metrics)
+		}
+		// The reason for omitting them is that they would not encode
+		if _, err := json.Marshal(metrics); err != nil {
+			t.Errorf("%s: json.Marshal() error = %v", name, err)
+		}
+	}
+}
+
+func TestParseCostPerSFBands(t *testing.T) {
+	bands, err := ParseCostPerSFBands(" renovation=90-450 , addition=110.5-300")
+	if err != nil {
+		t.Fatalf("ParseCostPerSFBands() error = %v", err)
+	}
+	if bands[models.ProjectTypeRenovation] != (models.CostPerSFBand{Min: 90, Max: 450}) ||
+		bands[models.ProjectTypeAddition] != (models.CostPerSFBand{Min: 110.5, Max: 300}) ||
+		bands[models.ProjectTypeNewConstruction] != DefaultCostPerSFBands()[models.ProjectTypeNewConstruction] {
+		t.Errorf("bands = %v", bands)
+	}
+
+	for _, value := range []string{"renovation", "remodel=80-400", "renovation=400-80", "renovation=80", "renovation=a-b"} {
+		if _, err := ParseCostPerSFBands(value); err == nil {
+			t.Errorf("ParseCostPerSFBands(%q) accepted", value)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+// Conversion factors from the imperial units takeoff and pricing are computed in
+const (
+	SquareMetersPerSquareFoot = 0.09290304
+	MetersPerFoot             = 0.3048
+)
+
+// Unit labels written into converted output. Metric labels stay ASCII so
+// the PDF core fonts can render them.
+const (
+	UnitLabelSquareFeet   = "SF"
+	UnitLabelLinearFeet   = "LF"
+	UnitLabelSquareMeters = "m2"
+	UnitLabelMeters       = "m"
+)
+
+// Line item unit labels that are converted, keyed by lower-cased label
+var (
+	imperialAreaUnits   = map[string]bool{"sf": true, "sq ft": true, "sqft": true, "square feet": true}
+	imperialLengthUnits = map[string]bool{"lf": true, "linear ft": true, "linear feet": true, "ft": true}
+	metricAreaUnits     = map[string]bool{"m2": true, "m²": true, "sq m": true, "sqm": true}
+	metricLengthUnits   = map[string]bool{"m": true, "lm": true, "linear m": true}
+)
+
+// ParseUnitSystem validates a units query parameter. An empty value returns
+// an empty system so the caller can fall back to the company default.
+func ParseUnitSystem(value string) (models.UnitSystem, error) {
+	switch system := models.UnitSystem(strings.ToLower(strings.TrimSpace(value))); system {
+	case "", models.UnitSystemImperial, models.UnitSystemMetric:
+		return system, nil
+	default:
+		return "", fmt.Errorf("units must be %q or %q", models.UnitSystemImperial, models.UnitSystemMetric)
+	}
+}
+
+// UnitConversionService converts takeoff and pricing output into one unit
+// system for display. Quantities, unit costs and labels change together so
+// line item totals are unaffected.
+type UnitConversionService struct {
+	System models.UnitSystem
+}
+
+// NewUnitConversionService creates a converter into system, defaulting to
+// imperial when system is empty
+func NewUnitConversionService(system models.UnitSystem) *UnitConversionService {
+	if system == "" {
+		system = models.UnitSystemImperial
+	}
+	return &UnitConversionService{System: system}
+}
+
+func (s *UnitConversionService) metric() bool {
+	return s.System == models.UnitSystemMetric
+}
+
+// ConvertArea converts an area in square feet into the target system,
+// rounded to two decimals for metric
+func (s *UnitConversionService) ConvertArea(squareFeet float64) float64 {
+	if !s.metric() {
+		return squareFeet
+	}
+	return math.Round(squareFeet*SquareMetersPerSquareFoot*100) / 100
+}
+
+// ConvertLength converts a length in feet into the target system, rounded
+// to two decimals for metric
+func (s *UnitConversionService) ConvertLength(feet float64) float64 {
+	if !s.metric() {
+		return feet
+	}
+	return math.Round(feet*MetersPerFoot*100) / 100
+}
+
+// AreaUnit is the label for areas in the target system
+func (s *UnitConversionService) AreaUnit() string {
+	if s.metric() {
+		return UnitLabelSquareMeters
+	}
+	return UnitLabelSquareFeet
+}
+
+// LengthUnit is the label for lengths in the target system
+func (s *UnitConversionService) LengthUnit() string {
+	if s.metric() {
+		return UnitLabelMeters
+	}
+	return UnitLabelLinearFeet
+}
+
+// ConvertTakeoffSummary converts a summary computed in imperial units in
+// place and labels it with the target system
+func (s *UnitConversionService) ConvertTakeoffSummary(summary *models.TakeoffSummary) {
+	if summary == nil {
+		return
+	}
+	summary.UnitSystem = s.System
+	summary.AreaUnit = s.AreaUnit()
+	summary.LengthUnit = s.LengthUnit()
+	if !s.metric() {
+		return
+	}
+
+	summary.TotalArea = s.ConvertArea(summary.TotalArea)
+	summary.TotalPerimeter = s.ConvertLength(summary.TotalPerimeter)
+	summary.WallArea = s.ConvertArea(summary.WallArea)
+	summary.CeilingHeight = s.ConvertLength(summary.CeilingHeight)
+	for i := range summary.RoomBreakdown {
+		room := &summary.RoomBreakdown[i]
+		room.Area = s.ConvertArea(room.Area)
+		room.Dimensions = s.convertDimensions(room.Dimensions, true)
+		if room.Adjusted != nil {
+			room.Adjusted.OriginalValue = s.ConvertArea(room.Adjusted.OriginalValue)
+		}
+	}
+	for i := range summary.Adjustments {
+		adjustment := &summary.Adjustments[i]
+		if adjustment.EntityType == models.TakeoffEntityRoom {
+			adjustment.OriginalValue = s.ConvertArea(adjustment.OriginalValue)
+			adjustment.AdjustedValue = s.ConvertArea(adjustment.AdjustedValue)
+		}
+	}
+	for i := range summary.OpeningBreakdown {
+		opening := &summary.OpeningBreakdown[i]
+		// Opening sizes are written in feet or inches, so bare numbers are ambiguous
+		opening.Size = s.convertDimensions(opening.Size, false)
+	}
+	s.convertOpeningSchedule(summary.OpeningSchedule)
+}
+
+// convertOpeningSchedule converts schedule sizes in place; the inch fields
+// are left as they are
+func (s *UnitConversionService) convertOpeningSchedule(schedule []models.OpeningScheduleEntry) {
+	if !s.metric() {
+		return
+	}
+	for i := range schedule {
+		schedule[i].Size = s.convertDimensions(schedule[i].Size, false)
+	}
+}
+
+// ConvertPricingSummary converts the line items of a pricing summary in place
+func (s *UnitConversionService) ConvertPricingSummary(summary *models.PricingSummary) {
+	if summary == nil {
+		return
+	}
+	summary.UnitSystem = s.System
+	s.ConvertLineItems(summary.LineItems)
+}
+
+// ConvertBidResponse converts the line items, alternates and opening
+// schedule of a bid in place before it is exported
+func (s *UnitConversionService) ConvertBidResponse(bid *models.GenerateBidResponse) {
+	if bid == nil {
+		return
+	}
+	s.ConvertLineItems(bid.LineItems)
+	for i := range bid.Alternates {
+		s.ConvertLineItems(bid.Alternates[i].LineItems)
+	}
+	s.convertOpeningSchedule(bid.OpeningSchedule)
+}
+
+// ConvertLineItems converts area and length quantities into the target
+// system, scaling unit costs inversely so totals are unchanged. Items priced
+// per each, hour or any other unit are left as they are.
+func (s *UnitConversionService) ConvertLineItems(items []models.LineItem) {
+	for i := range items {
+		item := &items[i]
+		unit := strings.ToLower(strings.TrimSpace(item.Unit))
+
+		var factor float64
+		var label string
+		switch {
+		case s.metric() && imperialAreaUnits[unit]:
+			factor, label = SquareMetersPerSquareFoot, UnitLabelSquareMeters
+		case s.metric() && imperialLengthUnits[unit]:
+			factor, label = MetersPerFoot, UnitLabelMeters
+		case !s.metric() && metricAreaUnits[unit]:
+			factor, label = 1/SquareMetersPerSquareFoot, UnitLabelSquareFeet
+		case !s.metric() && metricLengthUnits[unit]:
+			factor, label = 1/MetersPerFoot, UnitLabelLinearFeet
+		default:
+			continue
+		}
+
+		item.Quantity = math.Round(item.Quantity*factor*100) / 100
+		item.UnitCost = math.Round(item.UnitCost/factor*100) / 100
+		item.Unit = label
+	}
+}
+
+var (
+	// feetInchesPattern matches 12', 12'-6" and 12' 6"
+	feetInchesPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(?:'|ft)(?:\s*-?\s*(\d+(?:\.\d+)?)\s*(?:"|in))?$`)
+	barePattern       = regexp.MustCompile(`^\d+(?:\.\d+)?$`)
+	inchesPattern     = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(?:"|in)$`)
+	metersPattern     = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*m$`)
+	dimensionSplit    = regexp.MustCompile(`\s*[xX×]\s*`)
+)
+
+// ConvertDimensions converts a room dimension string such as 12'-6" x 10'
+// or 20x15 into the target system, reading bare numbers as feet. Strings
+// that cannot be parsed are returned unchanged.
+func (s *UnitConversionService) ConvertDimensions(dimensions string) string {
+	return s.convertDimensions(dimensions, true)
+}
+
+func (s *UnitConversionService) convertDimensions(dimensions string, bareFeet bool) string {
+	parts := dimensionSplit.Split(strings.TrimSpace(dimensions), -1)
+	if len(parts) < 2 {
+		return dimensions
+	}
+
+	converted := make([]string, len(parts))
+	for i, part := range parts {
+		if s.metric() {
+			feet, ok := parseFeet(part, bareFeet)
+			if !ok {
+				return dimensions
+			}
+			converted[i] = strconv.FormatFloat(s.ConvertLength(feet), 'f', 2, 64) + " " + UnitLabelMeters
+			continue
+		}
+
+		match := metersPattern.FindStringSubmatch(part)
+		if match == nil {
+			return dimensions
+		}
+		meters, _ := strconv.ParseFloat(match[1], 64)
+		converted[i] = formatFeetInches(meters / MetersPerFoot)
+	}
+	return strings.Join(converted, " x ")
+}
+
+// parseFeet reads a feet-and-inches measurement as decimal feet
+func parseFeet(value string, bareFeet bool) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if bareFeet && barePattern.MatchString(value) {
+		feet, _ := strconv.ParseFloat(value, 64)
+		return feet, true
+	}
+	if match := inchesPattern.FindStringSubmatch(value); match != nil {
+		inches, _ := strconv.ParseFloat(match[1], 64)
+		return inches / 12, true
+	}
+	match := feetInchesPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+	feet, _ := strconv.ParseFloat(match[1], 64)
+	if match[2] != "" {
+		inches, _ := strconv.ParseFloat(match[2], 64)
+		feet += inches / 12
+	}
+	return feet, true
+}
+
+// formatFeetInches writes decimal feet as feet and whole inches, e.g. 12'-6"
+func formatFeetInches(feet float64) string {
+	totalInches := int(math.Round(feet * 12))
+	whole, inches := totalInches/12, totalInches%12
+	if inches == 0 {
+		return fmt.Sprintf("%d'", whole)
+	}
+	return fmt.Sprintf("%d'-%d\"", whole, inches)
+}
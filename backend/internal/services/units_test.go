@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+func TestParseUnitSystem(t *testing.T) {
+	for value, want := range map[string]models.UnitSystem{"": "", "metric": models.UnitSystemMetric, " Imperial ": models.UnitSystemImperial} {
+		if got, err := ParseUnitSystem(value); err != nil || got != want {
+			t.Errorf("ParseUnitSystem(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseUnitSystem("cubits"); err == nil {
+		t.Error("expected an unknown unit system to be rejected")
+	}
+}
+
+func TestConvertLineItems_RoundTrip(t *testing.T) {
+	original := []models.LineItem{
+		{Description: "Drywall", Quantity: 1200, Unit: "sq ft", UnitCost: 1.75, Total: 2100},
+		{Description: "Baseboard", Quantity: 340, Unit: "LF", UnitCost: 3.2, Total: 1088},
+		{Description: "Outlets", Quantity: 12, Unit: "each", UnitCost: 150, Total: 1800},
+	}
+	items := append([]models.LineItem(nil), original...)
+
+	NewUnitConversionService(models.UnitSystemMetric).ConvertLineItems(items)
+	if items[0].Unit != UnitLabelSquareMeters || items[0].Quantity != 111.48 {
+		t.Errorf("area item = %+v, want 111.48 m2", items[0])
+	}
+	if items[1].Unit != UnitLabelMeters || items[1].Quantity != 103.63 {
+		t.Errorf("length item = %+v, want 103.63 m", items[1])
+	}
+	if items[2] != original[2] {
+		t.Errorf("each item changed: %+v", items[2])
+	}
+	for i, item := range items {
+		if math.Abs(item.Quantity*item.UnitCost-original[i].Total) > original[i].Total*0.001 {
+			t.Errorf("%s: %.2f x %.2f no longer matches total %.2f", item.Description, item.Quantity, item.UnitCost, original[i].Total)
+		}
+	}
+
+	NewUnitConversionService(models.UnitSystemImperial).ConvertLineItems(items)
+	for i, item := range items {
+		if math.Abs(item.Quantity-original[i].Quantity) > 0.05 || math.Abs(item.UnitCost-original[i].UnitCost) > 0.01 {
+			t.Errorf("round trip of %s = %.2f @ %.2f, want %.2f @ %.2f", item.Description, item.Quantity, item.UnitCost, original[i].Quantity, original[i].UnitCost)
+		}
+	}
+	if items[0].Unit != UnitLabelSquareFeet || items[1].Unit != UnitLabelLinearFeet {
+		t.Errorf("round trip units = %q, %q; want SF, LF", items[0].Unit, items[1].Unit)
+	}
+}
+
+func TestConvertTakeoffSummary_Metric(t *testing.T) {
+	summary := &models.TakeoffSummary{
+		TotalArea:      300,
+		TotalPerimeter: 70,
+		WallArea:       560,
+		CeilingHeight:  8,
+		RoomBreakdown: []models.RoomSummary{
+			{Name: "Living Room", Area: 300, Dimensions: "20x15"},
+			{Name: "Den", Area: 0, Dimensions: "see plan"},
+		},
+		OpeningBreakdown: []models.OpeningSummary{
+			{OpeningType: "door", Size: "36x80"},
+			{OpeningType: "door", Size: `3'-0" x 6'-8"`},
+		},
+	}
+
+	NewUnitConversionService(models.UnitSystemMetric).ConvertTakeoffSummary(summary)
+
+	if summary.UnitSystem != models.UnitSystemMetric || summary.AreaUnit != "m2" || summary.LengthUnit != "m" {
+		t.Errorf("labels = %q %q %q, want metric m2 m", summary.UnitSystem, summary.AreaUnit, summary.LengthUnit)
+	}
+	if summary.TotalArea != 27.87 || summary.TotalPerimeter != 21.34 {
+		t.Errorf("totals = %.2f, %.2f; want 27.87, 21.34", summary.TotalArea, summary.TotalPerimeter)
+	}
+	if summary.WallArea != 52.03 || summary.CeilingHeight != 2.44 {
+		t.Errorf("walls = %.2f at %.2f; want 52.03 at 2.44", summary.WallArea, summary.CeilingHeight)
+	}
+	if got := summary.RoomBreakdown[0].Dimensions; got != "6.10 m x 4.57 m" {
+		t.Errorf("room dimensions = %q, want 6.10 m x 4.57 m", got)
+	}
+	if got := summary.RoomBreakdown[1].Dimensions; got != "see plan" {
+		t.Errorf("unparseable dimensions = %q, want them unchanged", got)
+	}
+	// Bare opening sizes may be inches or feet, so only marked sizes convert
+	if got := summary.OpeningBreakdown[0].Size; got != "36x80" {
+		t.Errorf("bare opening size = %q, want it unchanged", got)
+	}
+	if got := summary.OpeningBreakdown[1].Size; got != "0.91 m x 2.03 m" {
+		t.Errorf("opening size = %q, want 0.91 m x 2.03 m", got)
+	}
+}
+
+func TestConvertDimensions_RoundTrip(t *testing.T) {
+	metric := NewUnitConversionService(models.UnitSystemMetric).ConvertDimensions(`12'-6" x 10'`)
+	if metric != "3.81 m x 3.05 m" {
+		t.Fatalf("metric = %q, want 3.81 m x 3.05 m", metric)
+	}
+	if back := NewUnitConversionService(models.UnitSystemImperial).ConvertDimensions(metric); back != `12'-6" x 10'` {
+		t.Errorf("round trip = %q, want 12'-6\" x 10'", back)
+	}
+}
+
+func TestRenderBidPDF_MetricUnitLabels(t *testing.T) {
+	bid := &models.Bid{ID: uuid.New(), ProjectID: uuid.New(), Status: models.BidStatusDraft}
+	bidResponse := &models.GenerateBidResponse{
+		LineItems: []models.LineItem{
+			{Description: "Drywall installation", Trade: "Drywall", Quantity: 1200, Unit: "SF", UnitCost: 1.75, Total: 2100},
+			{Description: "Baseboard", Trade: "Carpentry", Quantity: 340, Unit: "LF", UnitCost: 3.2, Total: 1088},
+		},
+	}
+	NewUnitConversionService(models.UnitSystemMetric).ConvertBidResponse(bidResponse)
+
+	pdf := NewPDFService().renderBidPDF(bid, bidResponse, "Office Remodel", nil)
+	pdf.SetCompression(false)
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	for _, text := range []string{"(111.5)", "(m2)", "(103.6)", "(m)", "($2100.00)"} {
+		if !bytes.Contains(buf.Bytes(), []byte(text)) {
+			t.Errorf("expected %s in the metric PDF", text)
+		}
+	}
+	for _, text := range []string{"(SF)", "(LF)"} {
+		if bytes.Contains(buf.Bytes(), []byte(text)) {
+			t.Errorf("metric PDF still contains imperial label %s", text)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+)
+
+// Scan results recorded on a blueprint's scan_result
+const (
+	ScanResultClean       = "clean"
+	ScanResultSkipped     = "skipped"
+	ScanResultUnavailable = "scanner_unavailable"
+)
+
+// clamdChunkSize is the INSTREAM chunk size; clamd's default StreamMaxLength
+// is far larger, so files are streamed rather than buffered
+const clamdChunkSize = 64 * 1024
+
+// ErrScannerUnavailable is returned when the scanner cannot be reached or
+// does not return a verdict
+var ErrScannerUnavailable = errors.New("virus scanner unavailable")
+
+// ScanVerdict is the outcome of scanning one file
+type ScanVerdict struct {
+	Infected  bool
+	Signature string // Malware signature name when infected
+}
+
+// ScanService scans a file stream for malware
+type ScanService interface {
+	Scan(ctx context.Context, r io.Reader) (ScanVerdict, error)
+}
+
+// NoopScanner accepts every file; used when scanning is disabled for local dev
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(ctx context.Context, r io.Reader) (ScanVerdict, error) {
+	return ScanVerdict{}, nil
+}
+
+// ClamAVScanner scans files with clamd over TCP using the INSTREAM command
+type ClamAVScanner struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner creates a scanner for the clamd instance at address (host:port)
+func NewClamAVScanner(address string, timeout time.Duration) *ClamAVScanner {
+	return &ClamAVScanner{address: address, timeout: timeout}
+}
+
+// Scan streams r to clamd in chunks and parses the verdict
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (ScanVerdict, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return ScanVerdict{}, fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return ScanVerdict{}, fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return ScanVerdict{}, fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return ScanVerdict{}, fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return ScanVerdict{}, fmt.Errorf("failed to read file for scanning: %w", readErr)
+		}
+	}
+
+	// A zero-length chunk terminates the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return ScanVerdict{}, fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return ScanVerdict{}, fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply parses "stream: OK" or "stream: <signature> FOUND"
+func parseClamdReply(reply []byte) (ScanVerdict, error) {
+	result := strings.TrimSpace(string(bytes.TrimRight(reply, "\x00")))
+	result = strings.TrimPrefix(result, "stream: ")
+
+	switch {
+	case result == "OK":
+		return ScanVerdict{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return ScanVerdict{Infected: true, Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		return ScanVerdict{}, fmt.Errorf("%w: unexpected reply %q", ErrScannerUnavailable, result)
+	}
+}
+
+// UploadObjectStore streams and deletes uploaded objects
+type UploadObjectStore interface {
+	OpenFile(ctx context.Context, key string) (io.ReadCloser, error)
+	DeleteFile(ctx context.Context, key string) error
+}
+
+// UploadScanOutcome is the result of scanning an uploaded blueprint
+type UploadScanOutcome struct {
+	Rejected bool
+	Result   string // Value recorded as the blueprint's scan_result
+}
+
+// UploadScanner scans uploaded objects and deletes the infected ones
+type UploadScanner struct {
+	scanner  ScanService
+	objects  UploadObjectStore
+	enabled  bool
+	failOpen bool
+}
+
+// NewUploadScanner creates an upload scanner. When enabled is false uploads
+// are accepted unscanned. failOpen accepts uploads when the scanner is
+// unavailable instead of returning ErrScannerUnavailable.
+func NewUploadScanner(scanner ScanService, objects UploadObjectStore, enabled, failOpen bool) *UploadScanner {
+	if scanner == nil {
+		scanner = NoopScanner{}
+	}
+	return &UploadScanner{
+		scanner:  scanner,
+		objects:  objects,
+		enabled:  enabled,
+		failOpen: failOpen,
+	}
+}
+
+// ScanUpload streams the object at key through the scanner. Infected objects
+// are deleted from storage and the outcome is rejected. When the scanner is
+// unavailable and the scanner fails closed, ErrScannerUnavailable is returned
+// and the object is left in place so completion can be retried.
+func (s *UploadScanner) ScanUpload(ctx context.Context, key string) (UploadScanOutcome, error) {
+	if !s.enabled {
+		return UploadScanOutcome{Result: ScanResultSkipped}, nil
+	}
+
+	verdict, err := s.scanObject(ctx, key)
+	if err != nil {
+		if s.failOpen {
+			slog.Warn("Virus scanner unavailable, accepting upload unscanned", "s3_key", key, "error", err)
+			return UploadScanOutcome{Result: ScanResultUnavailable}, nil
+		}
+		return UploadScanOutcome{}, err
+	}
+
+	if !verdict.Infected {
+		return UploadScanOutcome{Result: ScanResultClean}, nil
+	}
+
+	if err := s.objects.DeleteFile(ctx, key); err != nil {
+		slog.Error("Failed to delete infected upload", "s3_key", key, "error", err)
+	}
+	return UploadScanOutcome{Rejected: true, Result: "infected: " + verdict.Signature}, nil
+}
+
+func (s *UploadScanner) scanObject(ctx context.Context, key string) (ScanVerdict, error) {
+	body, err := s.objects.OpenFile(ctx, key)
+	if err != nil {
+		return ScanVerdict{}, fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+	defer body.Close()
+
+	verdict, err := s.scanner.Scan(ctx, body)
+	if err != nil && !errors.Is(err, ErrScannerUnavailable) {
+		err = fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+	return verdict, err
+}
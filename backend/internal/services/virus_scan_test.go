@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeScanner struct {
+	verdict ScanVerdict
+	err     error
+	scanned []byte
+}
+
+func (f *fakeScanner) Scan(ctx context.Context, r io.Reader) (ScanVerdict, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ScanVerdict{}, err
+	}
+	f.scanned = data
+	return f.verdict, f.err
+}
+
+type fakeObjectStore struct {
+	objects  map[string][]byte
+	deleted  []string
+	archived []string
+}
+
+func (f *fakeObjectStore) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeObjectStore) DeleteFile(ctx context.Context, key string) error {
+	f.deleted = append(f.deleted, key)
+	delete(f.objects, key)
+	return nil
+}
+
+func newScanStore() *fakeObjectStore {
+	return &fakeObjectStore{objects: map[string][]byte{"blueprints/a.pdf": []byte("%PDF-1.4 plan")}}
+}
+
+func TestUploadScanner_Clean(t *testing.T) {
+	scanner := &fakeScanner{}
+	store := newScanStore()
+
+	outcome, err := NewUploadScanner(scanner, store, true, false).ScanUpload(context.Background(), "blueprints/a.pdf")
+	if err != nil {
+		t.Fatalf("ScanUpload() error = %v", err)
+	}
+	if outcome.Rejected || outcome.Result != ScanResultClean {
+		t.Errorf("Expected clean outcome, got %+v", outcome)
+	}
+	if string(scanner.scanned) != "%PDF-1.4 plan" {
+		t.Errorf("Expected object contents to be streamed to the scanner, got %q", scanner.scanned)
+	}
+	if len(store.deleted) != 0 {
+		t.Errorf("Clean upload should not be deleted, got %v", store.deleted)
+	}
+}
+
+func TestUploadScanner_InfectedIsDeleted(t *testing.T) {
+	scanner := &fakeScanner{verdict: ScanVerdict{Infected: true, Signature: "Eicar-Test-Signature"}}
+	store := newScanStore()
+
+	outcome, err := NewUploadScanner(scanner, store, true, false).ScanUpload(context.Background(), "blueprints/a.pdf")
+	if err != nil {
+		t.Fatalf("ScanUpload() error = %v", err)
+	}
+	if !outcome.Rejected || outcome.Result != "infected: Eicar-Test-Signature" {
+		t.Errorf("Expected infected outcome, got %+v", outcome)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "blueprints/a.pdf" {
+		t.Errorf("Expected infected object to be deleted, got %v", store.deleted)
+	}
+}
+
+func TestUploadScanner_ScannerUnavailable(t *testing.T) {
+	down := &fakeScanner{err: ErrScannerUnavailable}
+
+	t.Run("Fail closed", func(t *testing.T) {
+		store := newScanStore()
+		_, err := NewUploadScanner(down, store, true, false).ScanUpload(context.Background(), "blueprints/a.pdf")
+		if !errors.Is(err, ErrScannerUnavailable) {
+			t.Fatalf("Expected ErrScannerUnavailable, got %v", err)
+		}
+		if len(store.deleted) != 0 {
+			t.Error("Object should be kept so completion can be retried")
+		}
+	})
+
+	t.Run("Fail open", func(t *testing.T) {
+		outcome, err := NewUploadScanner(down, newScanStore(), true, true).ScanUpload(context.Background(), "blueprints/a.pdf")
+		if err != nil {
+			t.Fatalf("ScanUpload() error = %v", err)
+		}
+		if outcome.Rejected || outcome.Result != ScanResultUnavailable {
+			t.Errorf("Expected upload accepted unscanned, got %+v", outcome)
+		}
+	})
+
+	t.Run("Object unreadable", func(t *testing.T) {
+		_, err := NewUploadScanner(&fakeScanner{}, &fakeObjectStore{}, true, false).ScanUpload(context.Background(), "missing")
+		if !errors.Is(err, ErrScannerUnavailable) {
+			t.Fatalf("Expected ErrScannerUnavailable, got %v", err)
+		}
+	})
+}
+
+func TestUploadScanner_Disabled(t *testing.T) {
+	outcome, err := NewUploadScanner(nil, nil, false, false).ScanUpload(context.Background(), "blueprints/a.pdf")
+	if err != nil || outcome.Rejected || outcome.Result != ScanResultSkipped {
+		t.Errorf("Expected skipped outcome, got %+v, %v", outcome, err)
+	}
+}
+
+// fakeClamd accepts one INSTREAM session and replies with reply
+func fakeClamd(t *testing.T, reply string) (string, <-chan []byte) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		command := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, command); err != nil {
+			return
+		}
+		var data []byte
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			chunk := make([]byte, n)
+			if _, err := io.ReadFull(conn, chunk); err != nil {
+				return
+			}
+			data = append(data, chunk...)
+		}
+		received <- data
+		conn.Write([]byte(reply + "\x00"))
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestClamAVScanner(t *testing.T) {
+	payload := strings.Repeat("x", clamdChunkSize+100)
+
+	t.Run("Clean stream", func(t *testing.T) {
+		addr, received := fakeClamd(t, "stream: OK")
+		verdict, err := NewClamAVScanner(addr, time.Second).Scan(context.Background(), strings.NewReader(payload))
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if verdict.Infected {
+			t.Errorf("Expected clean verdict, got %+v", verdict)
+		}
+		if data := <-received; string(data) != payload {
+			t.Errorf("clamd received %d bytes, want %d", len(data), len(payload))
+		}
+	})
+
+	t.Run("Infected stream", func(t *testing.T) {
+		addr, _ := fakeClamd(t, "stream: Eicar-Test-Signature FOUND")
+		verdict, err := NewClamAVScanner(addr, time.Second).Scan(context.Background(), strings.NewReader("eicar"))
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if !verdict.Infected || verdict.Signature != "Eicar-Test-Signature" {
+			t.Errorf("Expected infected verdict, got %+v", verdict)
+		}
+	})
+
+	t.Run("clamd error reply", func(t *testing.T) {
+		addr, _ := fakeClamd(t, "INSTREAM size limit exceeded. ERROR")
+		_, err := NewClamAVScanner(addr, time.Second).Scan(context.Background(), strings.NewReader("data"))
+		if !errors.Is(err, ErrScannerUnavailable) {
+			t.Errorf("Expected ErrScannerUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("clamd unreachable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		addr := listener.Addr().String()
+		listener.Close()
+
+		_, err = NewClamAVScanner(addr, time.Second).Scan(context.Background(), strings.NewReader("data"))
+		if !errors.Is(err, ErrScannerUnavailable) {
+			t.Errorf("Expected ErrScannerUnavailable, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// request body, keyed with the webhook's secret
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookEventHeader names the event a delivery carries
+	WebhookEventHeader = "X-Webhook-Event"
+	// WebhookDeliveryHeader identifies a delivery, so receivers can drop
+	// duplicates
+	WebhookDeliveryHeader = "X-Webhook-Delivery"
+
+	// MaxWebhookAttempts is how many times an event is sent before its
+	// delivery is marked failed
+	MaxWebhookAttempts = 5
+	// MaxWebhookURLLength caps a webhook's URL
+	MaxWebhookURLLength = 2048
+	// webhookBaseBackoff is the wait before the second attempt; it doubles
+	// after each further attempt
+	webhookBaseBackoff = 2 * time.Second
+	// webhookTimeout bounds each attempt, so a slow receiver can't hold a
+	// delivery for long
+	webhookTimeout = 10 * time.Second
+	// webhookErrorLength caps the receiver error stored on a delivery
+	webhookErrorLength = 500
+)
+
+// WebhookEventTypes are the events webhooks can subscribe to
+var WebhookEventTypes = []string{
+	models.WebhookEventAnalysisCompleted,
+	models.WebhookEventJobFailed,
+	models.WebhookEventBidCreated,
+	models.WebhookEventBidOverBudget,
+	models.WebhookEventBidStatusChanged,
+	models.WebhookEventBidAccepted,
+	models.WebhookEventBidFirstViewed,
+}
+
+// WebhookStore reads and writes webhooks and their deliveries
+type WebhookStore interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetByID(ctx context.Context, id, userID uuid.UUID) (*models.Webhook, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error)
+	ListByEvent(ctx context.Context, userID uuid.UUID, eventType string) ([]*models.Webhook, error)
+	Delete(ctx context.Context, id, userID uuid.UUID) error
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error)
+}
+
+// WebhookBlueprintStore finds the project a job's blueprint belongs to
+type WebhookBlueprintStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Blueprint, error)
+}
+
+// WebhookProjectStore finds the user that owns a project
+type WebhookProjectStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error)
+}
+
+// WebhookPayload is the JSON body sent to a webhook
+type WebhookPayload struct {
+	DeliveryID uuid.UUID        `json:"delivery_id"`
+	Event      string           `json:"event"`
+	CreatedAt  models.Timestamp `json:"created_at"`
+	Data       map[string]any   `json:"data"`
+}
+
+// WebhookService manages users' webhooks and delivers events to them. It
+// subscribes to the event bus; each delivery runs on the bus's async
+// goroutine and is retried with exponential backoff.
+type WebhookService struct {
+	store      WebhookStore
+	blueprints WebhookBlueprintStore
+	projects   WebhookProjectStore
+	client     *http.Client
+	backoff    time.Duration
+	sleep      func(time.Duration)
+	now        func() time.Time
+}
+
+func NewWebhookService(store WebhookStore, blueprints WebhookBlueprintStore, projects WebhookProjectStore) *WebhookService {
+	return &WebhookService{
+		store:      store,
+		blueprints: blueprints,
+		projects:   projects,
+		client:     &http.Client{Timeout: webhookTimeout},
+		backoff:    webhookBaseBackoff,
+		sleep:      time.Sleep,
+		now:        time.Now,
+	}
+}
+
+// ValidateWebhook checks a webhook's URL and event types. The URL must be an
+// absolute http or https URL, and at least one known event is required.
+func ValidateWebhook(rawURL string, eventTypes []string) error {
+	if rawURL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if len(rawURL) > MaxWebhookURLLength {
+		return fmt.Errorf("url must be at most %d characters", MaxWebhookURLLength)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+
+	if len(eventTypes) == 0 {
+		return fmt.Errorf("event_types is required")
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(WebhookEventTypes, eventType) {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the signature header value for a body
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Create registers a webhook for a user and returns it with its signing
+// secret. The caller validates the URL and event types.
+func (s *WebhookService) Create(ctx context.Context, userID uuid.UUID, rawURL string, eventTypes []string) (*models.Webhook, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	types := slices.Clone(eventTypes)
+	slices.Sort(types)
+	webhook := &models.Webhook{
+		ID:         uuid.New(),
+		UserID:     userID,
+		URL:        rawURL,
+		Secret:     hex.EncodeToString(secret),
+		EventTypes: slices.Compact(types),
+		CreatedAt:  models.NewTimestamp(s.now()),
+	}
+	if err := s.store.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// List returns a user's webhooks without their secrets
+func (s *WebhookService) List(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	return s.store.ListByUser(ctx, userID)
+}
+
+// Delete removes a user's webhook. Deliveries already in progress finish.
+func (s *WebhookService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.store.Delete(ctx, id, userID)
+}
+
+// Deliveries returns the most recent deliveries of a user's webhook
+func (s *WebhookService) Deliveries(ctx context.Context, userID, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	if _, err := s.store.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.store.ListDeliveries(ctx, id, limit)
+}
+
+// Register subscribes webhook delivery to the events webhooks can receive.
+// Deliveries are asynchronous so a slow receiver never delays the worker or
+// a response.
+func (s *WebhookService) Register(bus *events.Bus) {
+	events.Subscribe(bus, "webhooks", events.Async, s.analysisCompleted)
+	events.Subscribe(bus, "webhooks", events.Async, s.jobFailed)
+	events.Subscribe(bus, "webhooks", events.Async, s.bidCreated)
+	events.Subscribe(bus, "webhooks", events.Async, s.bidOverBudget)
+	events.Subscribe(bus, "webhooks", events.Async, s.bidStatusChanged)
+	events.Subscribe(bus, "webhooks", events.Async, s.bidAccepted)
+	events.Subscribe(bus, "webhooks", events.Async, s.bidFirstViewed)
+}
+
+func (s *WebhookService) analysisCompleted(ctx context.Context, event events.AnalysisCompleted) {
+	s.notifyProjectOwner(ctx, event.ProjectID, event.EventName(), map[string]any{
+		"job_id":       event.JobID,
+		"blueprint_id": event.BlueprintID,
+		"project_id":   event.ProjectID,
+		"job_type":     event.JobType,
+	})
+}
+
+func (s *WebhookService) jobFailed(ctx context.Context, event events.JobFailed) {
+	blueprint, err := s.blueprints.GetByID(ctx, event.BlueprintID)
+	if err != nil {
+		slog.Error("Failed to find webhook owner for failed job", "error", err, "job_id", event.JobID, "blueprint_id", event.BlueprintID)
+		return
+	}
+	s.notifyProjectOwner(ctx, blueprint.ProjectID, event.EventName(), map[string]any{
+		"job_id":       event.JobID,
+		"blueprint_id": event.BlueprintID,
+		"project_id":   blueprint.ProjectID,
+		"job_type":     event.JobType,
+		"error":        event.Error,
+	})
+}
+
+func (s *WebhookService) bidCreated(ctx context.Context, event events.BidCreated) {
+	s.notifyProjectOwner(ctx, event.ProjectID, event.EventName(), map[string]any{
+		"bid_id":      event.BidID,
+		"project_id":  event.ProjectID,
+		"final_price": event.FinalPrice,
+	})
+}
+
+func (s *WebhookService) bidOverBudget(ctx context.Context, event events.BidOverBudget) {
+	s.notifyProjectOwner(ctx, event.ProjectID, event.EventName(), map[string]any{
+		"bid_id":       event.BidID,
+		"project_id":   event.ProjectID,
+		"budget":       event.BudgetStatus.Budget,
+		"final_price":  event.BudgetStatus.Price,
+		"delta":        event.BudgetStatus.Delta,
+		"percent_over": event.BudgetStatus.Percent,
+	})
+}
+
+func (s *WebhookService) bidStatusChanged(ctx context.Context, event events.BidStatusChanged) {
+	s.notifyProjectOwner(ctx, event.ProjectID, event.EventName(), map[string]any{
+		"bid_id":     event.BidID,
+		"project_id": event.ProjectID,
+		"from":       event.From,
+		"to":         event.To,
+		"version":    event.Version,
+	})
+}
+
+func (s *WebhookService) bidAccepted(ctx context.Context, event events.BidAccepted) {
+	s.notifyProjectOwner(ctx, event.ProjectID, event.EventName(), map[string]any{
+		"bid_id":       event.Acceptance.BidID,
+		"project_id":   event.ProjectID,
+		"signer_name":  event.Acceptance.SignerName,
+		"signer_email": event.Acceptance.SignerEmail,
+		"accepted_at":  event.Acceptance.AcceptedAt,
+	})
+}
+
+func (s *WebhookService) bidFirstViewed(ctx context.Context, event events.BidFirstViewed) {
+	s.notifyProjectOwner(ctx, event.ProjectID, event.EventName(), map[string]any{
+		"bid_id":     event.BidID,
+		"project_id": event.ProjectID,
+		"share_id":   event.ShareID,
+		"viewed_at":  event.ViewedAt,
+	})
+}
+
+// notifyProjectOwner delivers an event to the webhooks of the user that owns
+// a project, one after another
+func (s *WebhookService) notifyProjectOwner(ctx context.Context, projectID uuid.UUID, eventType string, data map[string]any) {
+	project, err := s.projects.GetByID(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to find webhook owner", "error", err, "project_id", projectID, "event", eventType)
+		return
+	}
+	webhooks, err := s.store.ListByEvent(ctx, project.UserID, eventType)
+	if err != nil {
+		slog.Error("Failed to list webhooks", "error", err, "user_id", project.UserID, "event", eventType)
+		return
+	}
+	for _, webhook := range webhooks {
+		s.deliver(ctx, webhook, eventType, data)
+	}
+}
+
+// deliver sends an event to a webhook until it answers with a 2xx status or
+// MaxWebhookAttempts have failed, recording each attempt on the delivery
+func (s *WebhookService) deliver(ctx context.Context, webhook *models.Webhook, eventType string, data map[string]any) {
+	now := models.NewTimestamp(s.now())
+	delivery := &models.WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhook.ID,
+		EventType: eventType,
+		Status:    models.WebhookDeliveryPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	body, err := json.Marshal(WebhookPayload{DeliveryID: delivery.ID, Event: eventType, CreatedAt: now, Data: data})
+	if err != nil {
+		slog.Error("Failed to encode webhook payload", "error", err, "webhook_id", webhook.ID, "event", eventType)
+		return
+	}
+	delivery.Payload = body
+	if err := s.store.CreateDelivery(ctx, delivery); err != nil {
+		slog.Error("Failed to record webhook delivery", "error", err, "webhook_id", webhook.ID, "event", eventType)
+		return
+	}
+
+	wait := s.backoff
+	for delivery.Attempts < MaxWebhookAttempts {
+		if delivery.Attempts > 0 {
+			s.sleep(wait)
+			wait *= 2
+		}
+		delivery.Attempts++
+		code, err := s.send(ctx, webhook, delivery, body)
+
+		delivery.ResponseCode = code
+		delivery.ErrorMessage = nil
+		if err != nil {
+			message := truncate(err.Error(), webhookErrorLength)
+			delivery.ErrorMessage = &message
+		} else {
+			delivery.Status = models.WebhookDeliverySucceeded
+		}
+		if err != nil && delivery.Attempts == MaxWebhookAttempts {
+			delivery.Status = models.WebhookDeliveryFailed
+		}
+		delivery.UpdatedAt = models.NewTimestamp(s.now())
+		if err := s.store.UpdateDelivery(ctx, delivery); err != nil {
+			slog.Error("Failed to update webhook delivery", "error", err, "delivery_id", delivery.ID)
+		}
+		if delivery.Status == models.WebhookDeliverySucceeded {
+			return
+		}
+	}
+
+	slog.Warn("Webhook delivery failed",
+		"webhook_id", webhook.ID,
+		"delivery_id", delivery.ID,
+		"event", eventType,
+		"attempts", delivery.Attempts,
+		"error", stringOrEmpty(delivery.ErrorMessage))
+}
+
+// send makes one delivery attempt. It returns the response status, if there
+// was a response, and an error unless the status was 2xx.
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery, body []byte) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	code := resp.StatusCode
+	if code < 200 || code >= 300 {
+		return &code, fmt.Errorf("receiver responded with status %d", code)
+	}
+	return &code, nil
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type fakeWebhookStore struct {
+	mu         sync.Mutex
+	webhooks   []*models.Webhook
+	deliveries map[uuid.UUID]*models.WebhookDelivery
+}
+
+func (f *fakeWebhookStore) Create(ctx context.Context, webhook *models.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhooks = append(f.webhooks, webhook)
+	return nil
+}
+
+func (f *fakeWebhookStore) GetByID(ctx context.Context, id, userID uuid.UUID) (*models.Webhook, error) {
+	return nil, nil
+}
+
+func (f *fakeWebhookStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	return nil, nil
+}
+
+func (f *fakeWebhookStore) ListByEvent(ctx context.Context, userID uuid.UUID, eventType string) ([]*models.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matching []*models.Webhook
+	for _, webhook := range f.webhooks {
+		if webhook.UserID == userID && slices.Contains(webhook.EventTypes, eventType) {
+			matching = append(matching, webhook)
+		}
+	}
+	return matching, nil
+}
+
+func (f *fakeWebhookStore) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	return nil
+}
+
+func (f *fakeWebhookStore) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.deliveries == nil {
+		f.deliveries = make(map[uuid.UUID]*models.WebhookDelivery)
+	}
+	stored := *delivery
+	f.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+func (f *fakeWebhookStore) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := *delivery
+	f.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+func (f *fakeWebhookStore) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (f *fakeWebhookStore) deliveriesTo(webhookID uuid.UUID) []*models.WebhookDelivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matching []*models.WebhookDelivery
+	for _, delivery := range f.deliveries {
+		if delivery.WebhookID == webhookID {
+			matching = append(matching, delivery)
+		}
+	}
+	return matching
+}
+
+// webhookReceiver is an endpoint that checks each delivery's signature and
+// fails the first failures requests
+type webhookReceiver struct {
+	t        *testing.T
+	secret   string
+	failures int
+
+	mu       sync.Mutex
+	payloads []WebhookPayload
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	mac := hmac.New(sha256.New, []byte(rc.secret))
+	mac.Write(body)
+	if got, want := r.Header.Get(WebhookSignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		rc.t.Errorf("signature = %q, want %q", got, want)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		rc.t.Errorf("payload is not JSON: %v", err)
+	}
+	if r.Header.Get(WebhookEventHeader) != payload.Event || r.Header.Get(WebhookDeliveryHeader) != payload.DeliveryID.String() {
+		rc.t.Errorf("headers = %v, want the payload's event and delivery", r.Header)
+	}
+
+	rc.mu.Lock()
+	rc.payloads = append(rc.payloads, payload)
+	attempt := len(rc.payloads)
+	rc.mu.Unlock()
+	if attempt <= rc.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type webhookTest struct {
+	service   *WebhookService
+	store     *fakeWebhookStore
+	bus       *events.Bus
+	project   *models.Project
+	blueprint models.Blueprint
+	sleeps    []time.Duration
+}
+
+func newWebhookTest() *webhookTest {
+	project := &models.Project{ID: uuid.New(), UserID: uuid.New()}
+	blueprint := models.Blueprint{ID: uuid.New(), ProjectID: project.ID}
+	test := &webhookTest{store: &fakeWebhookStore{}, bus: events.NewBus(), project: project, blueprint: blueprint}
+
+	test.service = NewWebhookService(test.store,
+		&fakeWorkerBlueprints{blueprints: map[uuid.UUID]models.Blueprint{blueprint.ID: blueprint}},
+		fakePDFProjects{project.ID: project})
+	// Deliveries to one webhook are sequential, so the sleeps are in order
+	test.service.sleep = func(d time.Duration) { test.sleeps = append(test.sleeps, d) }
+	test.service.Register(test.bus)
+	return test
+}
+
+func (test *webhookTest) addWebhook(t *testing.T, userID uuid.UUID, url string, eventTypes ...string) *models.Webhook {
+	t.Helper()
+	webhook, err := test.service.Create(context.Background(), userID, url, eventTypes)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return webhook
+}
+
+func TestWebhookService_DeliversSignedEventsWithRetries(t *testing.T) {
+	test := newWebhookTest()
+	receiver := &webhookReceiver{t: t, failures: 2}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhook := test.addWebhook(t, test.project.UserID, server.URL, models.WebhookEventAnalysisCompleted)
+	receiver.secret = webhook.Secret
+	// Neither receives the event: one is for other events, one another user's
+	test.addWebhook(t, test.project.UserID, server.URL, models.WebhookEventBidCreated)
+	test.addWebhook(t, uuid.New(), server.URL, models.WebhookEventAnalysisCompleted)
+
+	jobID := uuid.New()
+	test.bus.Publish(context.Background(), events.AnalysisCompleted{
+		JobID: jobID, BlueprintID: test.blueprint.ID, ProjectID: test.project.ID, JobType: models.JobTypeTakeoff,
+	})
+	test.bus.Wait()
+
+	if len(receiver.payloads) != 3 {
+		t.Fatalf("receiver got %d requests, want two failures and a success", len(receiver.payloads))
+	}
+	payload := receiver.payloads[2]
+	if payload.Event != models.WebhookEventAnalysisCompleted || payload.Data["job_id"] != jobID.String() {
+		t.Errorf("payload = %+v, want the completed job", payload)
+	}
+	if want := []time.Duration{webhookBaseBackoff, 2 * webhookBaseBackoff}; !slices.Equal(test.sleeps, want) {
+		t.Errorf("backoff = %v, want %v", test.sleeps, want)
+	}
+
+	deliveries := test.store.deliveriesTo(webhook.ID)
+	if len(deliveries) != 1 {
+		t.Fatalf("recorded %d deliveries, want 1", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != models.WebhookDeliverySucceeded || delivery.Attempts != 3 ||
+		delivery.ResponseCode == nil || *delivery.ResponseCode != http.StatusNoContent || delivery.ErrorMessage != nil {
+		t.Errorf("delivery = %+v, want succeeded on the third attempt", delivery)
+	}
+	if delivery.ID != payload.DeliveryID {
+		t.Errorf("delivery ID = %s, want the payload's %s", delivery.ID, payload.DeliveryID)
+	}
+}
+
+func TestWebhookService_FailsAfterMaxAttempts(t *testing.T) {
+	test := newWebhookTest()
+	receiver := &webhookReceiver{t: t, failures: MaxWebhookAttempts}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhook := test.addWebhook(t, test.project.UserID, server.URL, models.WebhookEventJobFailed)
+	receiver.secret = webhook.Secret
+
+	// The owner of a failed job is found through its blueprint
+	test.bus.Publish(context.Background(), events.JobFailed{
+		JobID: uuid.New(), BlueprintID: test.blueprint.ID, JobType: models.JobTypeTakeoff, Error: "AI service unavailable",
+	})
+	test.bus.Wait()
+
+	if len(receiver.payloads) != MaxWebhookAttempts {
+		t.Fatalf("receiver got %d requests, want %d", len(receiver.payloads), MaxWebhookAttempts)
+	}
+	if receiver.payloads[0].Data["error"] != "AI service unavailable" {
+		t.Errorf("payload data = %v, want the job's error", receiver.payloads[0].Data)
+	}
+	if len(test.sleeps) != MaxWebhookAttempts-1 || test.sleeps[3] != 8*webhookBaseBackoff {
+		t.Errorf("backoff = %v, want doubling waits between attempts", test.sleeps)
+	}
+
+	deliveries := test.store.deliveriesTo(webhook.ID)
+	if len(deliveries) != 1 {
+		t.Fatalf("recorded %d deliveries, want 1", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != models.WebhookDeliveryFailed || delivery.Attempts != MaxWebhookAttempts ||
+		delivery.ResponseCode == nil || *delivery.ResponseCode != http.StatusServiceUnavailable ||
+		delivery.ErrorMessage == nil || !strings.Contains(*delivery.ErrorMessage, "503") {
+		t.Errorf("delivery = %+v, want failed with the last response", delivery)
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	if err := ValidateWebhook("https://example.com/hooks", []string{models.WebhookEventBidCreated}); err != nil {
+		t.Errorf("ValidateWebhook() error = %v, want nil", err)
+	}
+
+	invalid := map[string]struct {
+		url    string
+		events []string
+	}{
+		"missing url":     {"", []string{models.WebhookEventBidCreated}},
+		"relative url":    {"/hooks", []string{models.WebhookEventBidCreated}},
+		"other scheme":    {"ftp://example.com/hooks", []string{models.WebhookEventBidCreated}},
+		"long url":        {"https://example.com/" + strings.Repeat("a", MaxWebhookURLLength), []string{models.WebhookEventBidCreated}},
+		"no events":       {"https://example.com/hooks", nil},
+		"unknown event":   {"https://example.com/hooks", []string{"bid.deleted"}},
+		"internal events": {"https://example.com/hooks", []string{"materials.bulk_adjust"}},
+	}
+	for name, tc := range invalid {
+		if err := ValidateWebhook(tc.url, tc.events); err == nil {
+			t.Errorf("%s: ValidateWebhook() error = nil, want an error", name)
+		}
+	}
+}
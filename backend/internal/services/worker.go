@@ -3,28 +3,60 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/errreport"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/events"
 	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
-	"github.com/wonbyte/fantastic-octo-memory/backend/internal/repository"
 )
 
+// WorkerJobStore reads queued jobs and records their outcome
+type WorkerJobStore interface {
+	JobProgressStore
+	Create(ctx context.Context, job *models.Job) error
+	Update(ctx context.Context, job *models.Job) error
+	GetAverageDuration(ctx context.Context, jobType models.JobType) (time.Duration, error)
+	GetQueuedJobs(ctx context.Context, limit int) ([]*models.Job, error)
+	GetStaleJobs(ctx context.Context, startedBefore time.Time, limit int) ([]*models.Job, error)
+}
+
+// staleJobBatch caps the stale jobs recovered per poll
+const staleJobBatch = 50
+
+// WorkerBlueprintStore reads blueprints and stores their analysis
+type WorkerBlueprintStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Blueprint, error)
+	Update(ctx context.Context, blueprint *models.Blueprint) error
+	UpdateOCRText(ctx context.Context, id uuid.UUID, text *string) error
+	GetUploadGeneration(ctx context.Context, id uuid.UUID) (int, error)
+}
+
 type Worker struct {
-	jobRepo       *repository.JobRepository
-	blueprintRepo *repository.BlueprintRepository
-	aiService     *AIService
+	jobRepo       WorkerJobStore
+	blueprintRepo WorkerBlueprintStore
+	aiService     AIProvider
 	config        *config.WorkerConfig
+	objectCleaner *ObjectCleaner
+	draftCleaner  *DraftCleaner
+	revisioner    *AutoRevisioner
+	duplicator    *ProjectDuplicator
+	bidPDFs       *BidPDFGenerator
+	retention     *RetentionSweeper
+	events        events.Publisher
+	now           func() time.Time
 	stopChan      chan struct{}
 	doneChan      chan struct{}
 }
 
 func NewWorker(
-	jobRepo *repository.JobRepository,
-	blueprintRepo *repository.BlueprintRepository,
-	aiService *AIService,
+	jobRepo WorkerJobStore,
+	blueprintRepo WorkerBlueprintStore,
+	aiService AIProvider,
 	cfg *config.Config,
 ) *Worker {
 	return &Worker{
@@ -32,11 +64,58 @@ func NewWorker(
 		blueprintRepo: blueprintRepo,
 		aiService:     aiService,
 		config:        &cfg.Worker,
+		events:        events.Discard,
+		now:           time.Now,
 		stopChan:      make(chan struct{}),
 		doneChan:      make(chan struct{}),
 	}
 }
 
+// WithObjectCleanup makes the worker delete scheduled objects on each poll
+func (w *Worker) WithObjectCleanup(cleaner *ObjectCleaner) *Worker {
+	w.objectCleaner = cleaner
+	return w
+}
+
+// WithDraftCleanup makes the worker delete expired bid drafts on each poll
+func (w *Worker) WithDraftCleanup(cleaner *DraftCleaner) *Worker {
+	w.draftCleaner = cleaner
+	return w
+}
+
+// WithAutoRevisions makes the worker snapshot a blueprint's analysis as a
+// revision before storing a new one over it
+func (w *Worker) WithAutoRevisions(revisioner *AutoRevisioner) *Worker {
+	w.revisioner = revisioner
+	return w
+}
+
+// WithProjectDuplication makes the worker run project duplication jobs
+func (w *Worker) WithProjectDuplication(duplicator *ProjectDuplicator) *Worker {
+	w.duplicator = duplicator
+	return w
+}
+
+// WithBidPDFs makes the worker run bid PDF generation jobs
+func (w *Worker) WithBidPDFs(generator *BidPDFGenerator) *Worker {
+	w.bidPDFs = generator
+	return w
+}
+
+// WithRetentionSweep makes the worker delete old jobs and orphaned rows once
+// per retention interval
+func (w *Worker) WithRetentionSweep(sweeper *RetentionSweeper) *Worker {
+	w.retention = sweeper
+	return w
+}
+
+// WithEvents makes the worker publish AnalysisCompleted when it stores a
+// blueprint's analysis
+func (w *Worker) WithEvents(publisher events.Publisher) *Worker {
+	w.events = publisher
+	return w
+}
+
 func (w *Worker) Start(ctx context.Context) {
 	slog.Info("Worker started", "poll_interval", w.config.PollInterval)
 
@@ -46,6 +125,10 @@ func (w *Worker) Start(ctx context.Context) {
 	go func() {
 		defer close(w.doneChan)
 
+		// Jobs left processing by a previous run are recovered before any
+		// new work is picked up
+		w.recoverStaleJobs(ctx)
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -55,7 +138,17 @@ func (w *Worker) Start(ctx context.Context) {
 				slog.Info("Worker stopping due to stop signal")
 				return
 			case <-ticker.C:
+				w.recoverStaleJobs(ctx)
 				w.processJobs(ctx)
+				if w.objectCleaner != nil {
+					w.objectCleaner.RunOnce(ctx)
+				}
+				if w.draftCleaner != nil {
+					w.draftCleaner.RunOnce(ctx)
+				}
+				if w.retention != nil {
+					w.retention.RunIfDue(ctx)
+				}
 			}
 		}
 	}()
@@ -84,49 +177,62 @@ func (w *Worker) processJobs(ctx context.Context) {
 
 func (w *Worker) processJob(ctx context.Context, job *models.Job) error {
 	slog.Info("Processing job", "job_id", job.ID, "job_type", job.JobType)
+	timer := NewPhaseTimer()
 
 	// Update job to processing
-	now := time.Now()
+	now := models.Now()
 	job.Status = models.JobStatusProcessing
 	job.StartedAt = &now
 	job.UpdatedAt = now
+	job.Progress = 0
+	job.ProgressMessage = nil
 
 	if err := w.jobRepo.Update(ctx, job); err != nil {
 		return fmt.Errorf("failed to update job status: %w", err)
 	}
 
+	if job.JobType == models.JobTypeProjectDuplicate {
+		return w.processDuplicationJob(ctx, job)
+	}
+	if job.JobType == models.JobTypePDFGeneration {
+		return w.processBidPDFJob(ctx, job)
+	}
+
 	// Get blueprint
 	blueprint, err := w.blueprintRepo.GetByID(ctx, job.BlueprintID)
 	if err != nil {
 		return w.failJob(ctx, job, nil, fmt.Sprintf("failed to get blueprint: %v", err))
 	}
+	progress := NewJobProgress(w.jobRepo, job, DefaultProgressWriteInterval)
+	progress.Report(ctx, ProgressBlueprintLoaded, "Blueprint loaded")
 
 	// Update blueprint analysis status to processing
 	blueprint.AnalysisStatus = models.AnalysisStatusProcessing
-	blueprint.UpdatedAt = time.Now()
+	blueprint.UpdatedAt = models.Now()
 	if err := w.blueprintRepo.Update(ctx, blueprint); err != nil {
 		slog.Error("Failed to update blueprint status to processing", "error", err)
 	}
 
-	// Call AI service
-	resultData, err := w.aiService.AnalyzeBlueprint(ctx, blueprint.ID, blueprint.S3Key)
+	// Call AI service, estimating progress from how long this job type usually takes
+	expected, err := w.jobRepo.GetAverageDuration(ctx, job.JobType)
+	if err != nil {
+		slog.Error("Failed to get average job duration", "job_type", job.JobType, "error", err)
+	}
+	progress.Report(ctx, ProgressAISubmitted, "Submitted for AI analysis")
+	stopProgress := progress.TrackAIProgress(ctx, expected, "Analyzing blueprint")
+	stopAI := timer.Start("ai")
+	previous := w.previousAnalysis(ctx, job, blueprint)
+	resultData, modelInfo, err := w.analyze(ctx, blueprint, previous)
+	stopAI()
+	stopProgress()
 	if err != nil {
 		// Check if we should retry
 		if job.RetryCount < w.config.MaxRetries {
-			job.RetryCount++
-			job.Status = models.JobStatusQueued
-			job.StartedAt = nil
-			job.UpdatedAt = time.Now()
-			
-			if updateErr := w.jobRepo.Update(ctx, job); updateErr != nil {
-				slog.Error("Failed to requeue job", "job_id", job.ID, "error", updateErr)
-			} else {
-				slog.Info("Job requeued for retry", "job_id", job.ID, "retry_count", job.RetryCount)
-			}
-			
+			w.requeueJob(ctx, job)
+
 			// Revert blueprint status to queued for retry
 			blueprint.AnalysisStatus = models.AnalysisStatusQueued
-			blueprint.UpdatedAt = time.Now()
+			blueprint.UpdatedAt = models.Now()
 			if updateErr := w.blueprintRepo.Update(ctx, blueprint); updateErr != nil {
 				slog.Error("Failed to revert blueprint status", "error", updateErr)
 			}
@@ -134,6 +240,11 @@ func (w *Worker) processJob(ctx context.Context, job *models.Job) error {
 			return err
 		}
 
+		errreport.CaptureError(ctx, err, map[string]string{
+			errreport.TagComponent: "ai",
+			"job_id":               job.ID.String(),
+			"blueprint_id":         blueprint.ID.String(),
+		})
 		return w.failJob(ctx, job, blueprint, fmt.Sprintf("AI service error: %v", err))
 	}
 
@@ -143,31 +254,341 @@ func (w *Worker) processJob(ctx context.Context, job *models.Job) error {
 		return w.failJob(ctx, job, blueprint, fmt.Sprintf("failed to parse AI response: %v", err))
 	}
 
+	// A re-upload while the AI ran means this result describes a file the
+	// blueprint no longer has
+	generation, err := w.blueprintRepo.GetUploadGeneration(ctx, blueprint.ID)
+	if err != nil {
+		return w.failJob(ctx, job, blueprint, fmt.Sprintf("failed to check upload generation: %v", err))
+	}
+	if generation != job.UploadGeneration {
+		return w.discardStaleJob(ctx, job, generation)
+	}
+
+	// Keep the previous names of rooms the AI renamed without changing them
+	if previous != nil {
+		if renames := AlignRoomNames(previous, &analysisResult); len(renames) > 0 {
+			aligned, err := ApplyRoomRenames(resultData, renames, analysisResult.NormalizationNotes)
+			if err != nil {
+				slog.Error("Failed to align room names", "blueprint_id", blueprint.ID, "error", err)
+			} else {
+				resultData = aligned
+				slog.Info("Aligned room names with previous analysis", "blueprint_id", blueprint.ID, "renamed", len(renames))
+			}
+		}
+	}
+
+	// Classify the sheet discipline unless the user already set it
+	if blueprint.SheetType == nil {
+		sheetType := InferSheetType(blueprint, &analysisResult)
+		blueprint.SheetType = &sheetType
+	}
+
 	// Store normalized analysis in blueprint (resultData is already a JSON string)
+	progress.Report(ctx, ProgressPostProcessing, "Storing analysis results")
+	stopStore := timer.Start("store")
+	if w.revisioner != nil {
+		// Non-fatal: losing the snapshot is better than losing the new analysis
+		if _, err := w.revisioner.SnapshotBeforeOverwrite(ctx, blueprint, resultData); err != nil {
+			slog.Error("Failed to snapshot previous analysis", "blueprint_id", blueprint.ID, "error", err)
+		}
+	}
 	blueprint.AnalysisData = &resultData
+	blueprint.AnalysisModel = modelInfo
 	blueprint.AnalysisStatus = models.AnalysisStatusCompleted
-	blueprint.UpdatedAt = time.Now()
+	blueprint.UpdatedAt = models.Now()
 	if err := w.blueprintRepo.Update(ctx, blueprint); err != nil {
 		return w.failJob(ctx, job, blueprint, fmt.Sprintf("failed to update blueprint with analysis: %v", err))
 	}
 
+	// Index raw OCR text for full-text search (non-fatal: the analysis is already stored).
+	// An analysis without OCR text clears the previous run's text so search
+	// doesn't match words that are no longer on the sheet.
+	if err := w.blueprintRepo.UpdateOCRText(ctx, blueprint.ID, analysisResult.RawOCRText); err != nil {
+		slog.Error("Failed to store OCR text", "blueprint_id", blueprint.ID, "error", err)
+	}
+
+	stopStore()
+
 	// Update job to completed
-	completedAt := time.Now()
+	completedAt := models.Now()
 	job.Status = models.JobStatusCompleted
 	job.CompletedAt = &completedAt
 	job.ResultData = &resultData
 	job.UpdatedAt = completedAt
+	completedMessage := "Completed"
+	job.Progress = ProgressComplete
+	job.ProgressMessage = &completedMessage
+
+	if err := w.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to update job to completed: %w", err)
+	}
+
+	slog.Info("Job completed successfully", append([]any{"job_id", job.ID}, timer.LogArgs()...)...)
+	w.events.Publish(ctx, events.AnalysisCompleted{
+		JobID:       job.ID,
+		BlueprintID: blueprint.ID,
+		ProjectID:   blueprint.ProjectID,
+		JobType:     job.JobType,
+	})
+	return nil
+}
+
+// previousAnalysis returns the analysis a re-analysis replaces: the
+// blueprint's own, or its parent's when it has none yet. It returns nil
+// when the job is not a re-analysis or there is no usable analysis.
+func (w *Worker) previousAnalysis(ctx context.Context, job *models.Job, blueprint *models.Blueprint) *models.AnalysisResult {
+	if !job.Reanalyze {
+		return nil
+	}
+	data := blueprint.AnalysisData
+	if data == nil && blueprint.ParentBlueprintID != nil {
+		parent, err := w.blueprintRepo.GetByID(ctx, *blueprint.ParentBlueprintID)
+		if err != nil {
+			slog.Error("Failed to load parent blueprint", "blueprint_id", blueprint.ID, "parent_id", *blueprint.ParentBlueprintID, "error", err)
+			return nil
+		}
+		data = parent.AnalysisData
+	}
+	if data == nil {
+		return nil
+	}
+
+	var previous models.AnalysisResult
+	if err := json.Unmarshal([]byte(*data), &previous); err != nil {
+		slog.Error("Failed to parse previous analysis", "blueprint_id", blueprint.ID, "error", err)
+		return nil
+	}
+	return &previous
+}
+
+// analyze calls the AI service, sending the previous analysis as context
+// when there is one, the provider accepts it and the config allows it
+func (w *Worker) analyze(ctx context.Context, blueprint *models.Blueprint, previous *models.AnalysisResult) (string, *models.AIModelInfo, error) {
+	contextual, ok := w.aiService.(ContextualAnalyzer)
+	if previous == nil || !ok || !w.config.ReanalyzeContext {
+		return w.aiService.AnalyzeBlueprint(ctx, blueprint.ID, blueprint.S3Key)
+	}
+
+	prior := BuildPriorAnalysis(previous, w.config.ReanalyzeContextMaxBytes)
+	if prior == nil {
+		return w.aiService.AnalyzeBlueprint(ctx, blueprint.ID, blueprint.S3Key)
+	}
+	if prior.Truncated {
+		slog.Warn("Previous analysis truncated to fit the context cap",
+			"blueprint_id", blueprint.ID,
+			"max_bytes", w.config.ReanalyzeContextMaxBytes,
+			"rooms", len(prior.Rooms),
+			"openings", len(prior.Openings))
+	}
+	return contextual.AnalyzeBlueprintWithContext(ctx, blueprint.ID, blueprint.S3Key, prior)
+}
+
+// processDuplicationJob copies a project's blueprints into the duplicate
+// created when the job was queued. The job's blueprint is one of the source
+// project's. A failed copy is not retried, since a retry would copy the
+// blueprints already copied again.
+func (w *Worker) processDuplicationJob(ctx context.Context, job *models.Job) error {
+	if w.duplicator == nil || job.TargetProjectID == nil {
+		return w.failJob(ctx, job, nil, "project duplication is not configured")
+	}
+
+	source, err := w.blueprintRepo.GetByID(ctx, job.BlueprintID)
+	if err != nil {
+		return w.failJob(ctx, job, nil, fmt.Sprintf("failed to get source blueprint: %v", err))
+	}
+
+	progress := NewJobProgress(w.jobRepo, job, DefaultProgressWriteInterval)
+	copies, err := w.duplicator.CopyProject(ctx, source.ProjectID, *job.TargetProjectID, func(copied, total int) {
+		progress.Report(ctx, copied*ProgressPostProcessing/total, fmt.Sprintf("Copied %d of %d blueprints", copied, total))
+	})
+	if err != nil {
+		return w.failJob(ctx, job, nil, fmt.Sprintf("project duplication failed after %d blueprints: %v", len(copies), err))
+	}
+
+	resultData, err := json.Marshal(map[string]interface{}{
+		"project_id":      *job.TargetProjectID,
+		"blueprint_count": len(copies),
+	})
+	if err != nil {
+		return w.failJob(ctx, job, nil, fmt.Sprintf("failed to encode result: %v", err))
+	}
+	result := string(resultData)
+
+	completedAt := models.Now()
+	job.Status = models.JobStatusCompleted
+	job.CompletedAt = &completedAt
+	job.ResultData = &result
+	job.UpdatedAt = completedAt
+	completedMessage := "Completed"
+	job.Progress = ProgressComplete
+	job.ProgressMessage = &completedMessage
 
 	if err := w.jobRepo.Update(ctx, job); err != nil {
 		return fmt.Errorf("failed to update job to completed: %w", err)
 	}
 
-	slog.Info("Job completed successfully", "job_id", job.ID)
+	slog.Info("Project duplication completed", "job_id", job.ID, "project_id", *job.TargetProjectID, "blueprint_count", len(copies))
+	return nil
+}
+
+// processBidPDFJob renders a bid's PDF and uploads it, storing its URL on
+// the bid. Storage failures are retried up to MaxRetries; bid data that
+// can't be parsed fails the job at once.
+func (w *Worker) processBidPDFJob(ctx context.Context, job *models.Job) error {
+	if w.bidPDFs == nil || job.BidID == nil {
+		return w.failJob(ctx, job, nil, "bid PDF generation is not configured")
+	}
+
+	bid, err := w.bidPDFs.Generate(ctx, *job.BidID, job.BlueprintID, job.PDFRequest)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidBidData) && job.RetryCount < w.config.MaxRetries {
+			w.requeueJob(ctx, job)
+			return err
+		}
+		return w.failJob(ctx, job, nil, fmt.Sprintf("PDF generation failed: %v", err))
+	}
+
+	resultData, err := json.Marshal(map[string]interface{}{
+		"bid_id":  bid.ID,
+		"pdf_url": bid.PDFURL,
+	})
+	if err != nil {
+		return w.failJob(ctx, job, nil, fmt.Sprintf("failed to encode result: %v", err))
+	}
+	result := string(resultData)
+
+	completedAt := models.Now()
+	job.Status = models.JobStatusCompleted
+	job.CompletedAt = &completedAt
+	job.ResultData = &result
+	job.UpdatedAt = completedAt
+	completedMessage := "Completed"
+	job.Progress = ProgressComplete
+	job.ProgressMessage = &completedMessage
+
+	if err := w.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to update job to completed: %w", err)
+	}
+
+	slog.Info("Bid PDF generated", "job_id", job.ID, "bid_id", bid.ID, "retry_count", job.RetryCount)
+	return nil
+}
+
+// recoverStaleJobs requeues jobs that have been processing for longer than
+// the stale job timeout, most likely because the worker running them crashed
+// or was restarted. A job that has used up its retries fails instead. It runs
+// between jobs, so this worker's own job is never among them.
+func (w *Worker) recoverStaleJobs(ctx context.Context) {
+	if w.config.StaleJobTimeout <= 0 {
+		return
+	}
+
+	jobs, err := w.jobRepo.GetStaleJobs(ctx, w.now().Add(-w.config.StaleJobTimeout), staleJobBatch)
+	if err != nil {
+		slog.Error("Failed to get stale jobs", "error", err)
+		return
+	}
+
+	for _, job := range jobs {
+		slog.Warn("Recovering stale job",
+			"audit_event", "job.stale_recovered",
+			"job_id", job.ID,
+			"job_type", job.JobType,
+			"started_at", job.StartedAt,
+			"retry_count", job.RetryCount)
+
+		if job.RetryCount < w.config.MaxRetries {
+			w.requeueJob(ctx, job)
+			continue
+		}
+
+		// Analysis jobs also mark their blueprint failed
+		var blueprint *models.Blueprint
+		if job.JobType != models.JobTypeProjectDuplicate && job.JobType != models.JobTypePDFGeneration {
+			if blueprint, err = w.blueprintRepo.GetByID(ctx, job.BlueprintID); err != nil {
+				slog.Error("Failed to load blueprint of stale job", "job_id", job.ID, "blueprint_id", job.BlueprintID, "error", err)
+				blueprint = nil
+			}
+		}
+		message := fmt.Sprintf("job was still processing after %s, most likely interrupted by a server restart; gave up after %d retries",
+			w.config.StaleJobTimeout, job.RetryCount)
+		w.failJob(ctx, job, blueprint, message)
+	}
+}
+
+// requeueJob returns a job to the queue for another attempt
+func (w *Worker) requeueJob(ctx context.Context, job *models.Job) {
+	job.RetryCount++
+	job.Status = models.JobStatusQueued
+	job.StartedAt = nil
+	job.UpdatedAt = models.Now()
+	job.Progress = 0
+	job.ProgressMessage = nil
+
+	if err := w.jobRepo.Update(ctx, job); err != nil {
+		slog.Error("Failed to requeue job", "job_id", job.ID, "error", err)
+	} else {
+		slog.Info("Job requeued for retry", "job_id", job.ID, "retry_count", job.RetryCount)
+	}
+}
+
+// discardStaleJob ends a job whose blueprint was re-uploaded while it ran
+// without storing its result. With auto-analyze on, the new file is queued
+// for analysis in its place.
+func (w *Worker) discardStaleJob(ctx context.Context, job *models.Job, generation int) error {
+	completedAt := models.Now()
+	message := fmt.Sprintf("blueprint was re-uploaded during analysis (upload generation %d, now %d)", job.UploadGeneration, generation)
+	job.Status = models.JobStatusStale
+	job.CompletedAt = &completedAt
+	job.ErrorMessage = &message
+	job.UpdatedAt = completedAt
+
+	if err := w.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to update job to stale: %w", err)
+	}
+
+	slog.Warn("Discarded analysis of a replaced blueprint file",
+		"audit_event", "job.stale_result_discarded",
+		"job_id", job.ID,
+		"blueprint_id", job.BlueprintID,
+		"job_upload_generation", job.UploadGeneration,
+		"current_upload_generation", generation)
+
+	// Reload the blueprint: the copy this job loaded predates the new upload
+	blueprint, err := w.blueprintRepo.GetByID(ctx, job.BlueprintID)
+	if err != nil {
+		return fmt.Errorf("failed to reload blueprint: %w", err)
+	}
+	blueprint.AnalysisStatus = models.AnalysisStatusNotStarted
+
+	if w.config.AutoAnalyze {
+		fresh := &models.Job{
+			ID:               uuid.New(),
+			BlueprintID:      blueprint.ID,
+			JobType:          job.JobType,
+			Status:           models.JobStatusQueued,
+			CreatedAt:        models.Now(),
+			UpdatedAt:        models.Now(),
+			UploadGeneration: blueprint.UploadGeneration,
+			Reanalyze:        job.Reanalyze,
+		}
+		if err := w.jobRepo.Create(ctx, fresh); err != nil {
+			slog.Error("Failed to queue analysis of re-uploaded blueprint", "blueprint_id", blueprint.ID, "error", err)
+		} else {
+			blueprint.AnalysisStatus = models.AnalysisStatusQueued
+			slog.Info("Queued analysis of re-uploaded blueprint", "blueprint_id", blueprint.ID, "job_id", fresh.ID, "stale_job_id", job.ID)
+		}
+	}
+
+	blueprint.UpdatedAt = models.Now()
+	if err := w.blueprintRepo.Update(ctx, blueprint); err != nil {
+		slog.Error("Failed to reset blueprint analysis status", "blueprint_id", blueprint.ID, "error", err)
+	}
 	return nil
 }
 
 func (w *Worker) failJob(ctx context.Context, job *models.Job, blueprint *models.Blueprint, errorMsg string) error {
-	completedAt := time.Now()
+	completedAt := models.Now()
 	job.Status = models.JobStatusFailed
 	job.CompletedAt = &completedAt
 	job.ErrorMessage = &errorMsg
@@ -180,12 +601,18 @@ func (w *Worker) failJob(ctx context.Context, job *models.Job, blueprint *models
 	// Update blueprint analysis status to failed
 	if blueprint != nil {
 		blueprint.AnalysisStatus = models.AnalysisStatusFailed
-		blueprint.UpdatedAt = time.Now()
+		blueprint.UpdatedAt = models.Now()
 		if err := w.blueprintRepo.Update(ctx, blueprint); err != nil {
 			slog.Error("Failed to update blueprint status to failed", "error", err)
 		}
 	}
 
 	slog.Error("Job failed", "job_id", job.ID, "error", errorMsg)
+	w.events.Publish(ctx, events.JobFailed{
+		JobID:       job.ID,
+		BlueprintID: job.BlueprintID,
+		JobType:     job.JobType,
+		Error:       errorMsg,
+	})
 	return fmt.Errorf("job failed: %s", errorMsg)
 }
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/config"
+	"github.com/wonbyte/fantastic-octo-memory/backend/internal/models"
+)
+
+type fakeWorkerJobs struct {
+	jobs []*models.Job
+}
+
+func (f *fakeWorkerJobs) Create(ctx context.Context, job *models.Job) error {
+	f.jobs = append(f.jobs, job)
+	return nil
+}
+
+func (f *fakeWorkerJobs) Update(ctx context.Context, job *models.Job) error { return nil }
+
+func (f *fakeWorkerJobs) UpdateProgress(ctx context.Context, id uuid.UUID, progress int, message string) error {
+	return nil
+}
+
+func (f *fakeWorkerJobs) GetAverageDuration(ctx context.Context, jobType models.JobType) (time.Duration, error) {
+	return 0, nil
+}
+
+func (f *fakeWorkerJobs) GetQueuedJobs(ctx context.Context, limit int) ([]*models.Job, error) {
+	var queued []*models.Job
+	for _, job := range f.jobs {
+		if job.Status == models.JobStatusQueued {
+			queued = append(queued, job)
+		}
+	}
+	return queued, nil
+}
+
+func (f *fakeWorkerJobs) GetStaleJobs(ctx context.Context, startedBefore time.Time, limit int) ([]*models.Job, error) {
+	var stale []*models.Job
+	for _, job := range f.jobs {
+		if job.Status == models.JobStatusProcessing && job.StartedAt != nil && job.StartedAt.Time.Before(startedBefore) {
+			stale = append(stale, job)
+		}
+	}
+	return stale, nil
+}
+
+// fakeWorkerBlueprints hands out copies, as the database does, so the worker
+// cannot see a re-upload through a shared pointer
+type fakeWorkerBlueprints struct {
+	blueprints map[uuid.UUID]models.Blueprint
+	ocrText    map[uuid.UUID]*string
+}
+
+func (f *fakeWorkerBlueprints) GetByID(ctx context.Context, id uuid.UUID) (*models.Blueprint, error) {
+	blueprint, ok := f.blueprints[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &blueprint, nil
+}
+
+func (f *fakeWorkerBlueprints) Update(ctx context.Context, blueprint *models.Blueprint) error {
+	// Like the repository, Update leaves the upload generation alone
+	blueprint.UploadGeneration = f.blueprints[blueprint.ID].UploadGeneration
+	f.blueprints[blueprint.ID] = *blueprint
+	return nil
+}
+
+func (f *fakeWorkerBlueprints) UpdateOCRText(ctx context.Context, id uuid.UUID, text *string) error {
+	if f.ocrText == nil {
+		f.ocrText = make(map[uuid.UUID]*string)
+	}
+	f.ocrText[id] = text
+	return nil
+}
+
+func (f *fakeWorkerBlueprints) GetUploadGeneration(ctx context.Context, id uuid.UUID) (int, error) {
+	return f.blueprints[id].UploadGeneration, nil
+}
+
+// reupload simulates CompleteUpload for a corrected file
+func (f *fakeWorkerBlueprints) reupload(id uuid.UUID) {
+	blueprint := f.blueprints[id]
+	blueprint.S3Key = "blueprints/corrected.pdf"
+	blueprint.UploadGeneration++
+	f.blueprints[id] = blueprint
+}
+
+// interleavingAI calls during before returning its analysis, standing in for
+// whatever happens while the AI service is working
+type interleavingAI struct {
+	StubAIProvider
+	during func()
+}
+
+func (a *interleavingAI) AnalyzeBlueprint(ctx context.Context, blueprintID uuid.UUID, s3Key string) (string, *models.AIModelInfo, error) {
+	if a.during != nil {
+		a.during()
+	}
+	return `{"status":"completed","rooms":[{"name":"Office","area":200}]}`, nil, nil
+}
+
+func newWorkerTest(autoAnalyze bool) (*Worker, *fakeWorkerJobs, *fakeWorkerBlueprints, *interleavingAI, uuid.UUID) {
+	blueprintID := uuid.New()
+	blueprints := &fakeWorkerBlueprints{blueprints: map[uuid.UUID]models.Blueprint{
+		blueprintID: {ID: blueprintID, S3Key: "blueprints/original.pdf", UploadStatus: models.UploadStatusUploaded, UploadGeneration: 1},
+	}}
+	jobs := &fakeWorkerJobs{jobs: []*models.Job{
+		{ID: uuid.New(), BlueprintID: blueprintID, JobType: models.JobTypeTakeoff, Status: models.JobStatusQueued, UploadGeneration: 1},
+	}}
+	ai := &interleavingAI{}
+	cfg := &config.Config{Worker: config.WorkerConfig{AutoAnalyze: autoAnalyze}}
+	return NewWorker(jobs, blueprints, ai, cfg), jobs, blueprints, ai, blueprintID
+}
+
+func TestWorker_StoresAnalysisForCurrentUpload(t *testing.T) {
+	worker, jobs, blueprints, _, blueprintID := newWorkerTest(false)
+
+	if err := worker.processJob(context.Background(), jobs.jobs[0]); err != nil {
+		t.Fatalf("processJob() error = %v", err)
+	}
+
+	if jobs.jobs[0].Status != models.JobStatusCompleted {
+		t.Errorf("job status = %s, want completed", jobs.jobs[0].Status)
+	}
+	if blueprint := blueprints.blueprints[blueprintID]; blueprint.AnalysisData == nil || blueprint.AnalysisStatus != models.AnalysisStatusCompleted {
+		t.Errorf("expected the analysis stored, got status %s", blueprint.AnalysisStatus)
+	}
+}
+
+func TestWorker_ClearsStaleOCRTextWhenReanalysisHasNone(t *testing.T) {
+	worker, jobs, blueprints, _, blueprintID := newWorkerTest(false)
+	stale := "Electrical panel 200A"
+	blueprints.ocrText = map[uuid.UUID]*string{blueprintID: &stale}
+
+	if err := worker.processJob(context.Background(), jobs.jobs[0]); err != nil {
+		t.Fatalf("processJob() error = %v", err)
+	}
+
+	if text, ok := blueprints.ocrText[blueprintID]; !ok || text != nil {
+		t.Errorf("OCR text = %v, want it cleared", text)
+	}
+}
+
+func TestWorker_DiscardsResultAfterReupload(t *testing.T) {
+	worker, jobs, blueprints, ai, blueprintID := newWorkerTest(false)
+	ai.during = func() { blueprints.reupload(blueprintID) }
+
+	if err := worker.processJob(context.Background(), jobs.jobs[0]); err != nil {
+		t.Fatalf("processJob() error = %v", err)
+	}
+
+	job := jobs.jobs[0]
+	if job.Status != models.JobStatusStale || job.CompletedAt == nil || job.ResultData != nil {
+		t.Errorf("job = %s, completed %v, result %v; want a finished stale job without a result", job.Status, job.CompletedAt, job.ResultData)
+	}
+	blueprint := blueprints.blueprints[blueprintID]
+	if blueprint.AnalysisData != nil {
+		t.Errorf("stale analysis landed in AnalysisData: %s", *blueprint.AnalysisData)
+	}
+	if blueprint.S3Key != "blueprints/corrected.pdf" || blueprint.UploadGeneration != 2 {
+		t.Errorf("re-upload was overwritten: key %s, generation %d", blueprint.S3Key, blueprint.UploadGeneration)
+	}
+	if blueprint.AnalysisStatus != models.AnalysisStatusNotStarted {
+		t.Errorf("analysis status = %s, want not_started", blueprint.AnalysisStatus)
+	}
+	if len(jobs.jobs) != 1 {
+		t.Errorf("expected no new job without auto-analyze, got %d jobs", len(jobs.jobs))
+	}
+}
+
+func TestWorker_ReanalyzesReuploadWhenAutoAnalyzeEnabled(t *testing.T) {
+	worker, jobs, blueprints, ai, blueprintID := newWorkerTest(true)
+	ai.during = func() { blueprints.reupload(blueprintID) }
+
+	if err := worker.processJob(context.Background(), jobs.jobs[0]); err != nil {
+		t.Fatalf("processJob() error = %v", err)
+	}
+	if len(jobs.jobs) != 2 {
+		t.Fatalf("expected a fresh job for the new file, got %d jobs", len(jobs.jobs))
+	}
+	fresh := jobs.jobs[1]
+	if fresh.Status != models.JobStatusQueued || fresh.UploadGeneration != 2 {
+		t.Errorf("fresh job = %s at generation %d, want queued at 2", fresh.Status, fresh.UploadGeneration)
+	}
+	if status := blueprints.blueprints[blueprintID].AnalysisStatus; status != models.AnalysisStatusQueued {
+		t.Errorf("analysis status = %s, want queued", status)
+	}
+
+	// The fresh job analyzes the corrected file and its result is kept
+	ai.during = nil
+	if err := worker.processJob(context.Background(), fresh); err != nil {
+		t.Fatalf("processJob(fresh) error = %v", err)
+	}
+	if blueprints.blueprints[blueprintID].AnalysisData == nil || fresh.Status != models.JobStatusCompleted {
+		t.Errorf("expected the fresh job's analysis stored, job status %s", fresh.Status)
+	}
+}
+
+func TestWorker_RecoversStaleJobs(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := func(ago time.Duration) *models.Timestamp {
+		ts := models.NewTimestamp(now.Add(-ago))
+		return &ts
+	}
+	blueprintID := uuid.New()
+	blueprints := &fakeWorkerBlueprints{blueprints: map[uuid.UUID]models.Blueprint{
+		blueprintID: {ID: blueprintID, AnalysisStatus: models.AnalysisStatusProcessing},
+	}}
+	interrupted := &models.Job{ID: uuid.New(), BlueprintID: blueprintID, JobType: models.JobTypeTakeoff, Status: models.JobStatusProcessing,
+		StartedAt: startedAt(11 * time.Minute), Progress: 40, RetryCount: 1}
+	running := &models.Job{ID: uuid.New(), BlueprintID: blueprintID, JobType: models.JobTypeTakeoff, Status: models.JobStatusProcessing,
+		StartedAt: startedAt(9 * time.Minute)}
+	exhausted := &models.Job{ID: uuid.New(), BlueprintID: blueprintID, JobType: models.JobTypeTakeoff, Status: models.JobStatusProcessing,
+		StartedAt: startedAt(time.Hour), RetryCount: 3}
+	jobs := &fakeWorkerJobs{jobs: []*models.Job{interrupted, running, exhausted}}
+
+	cfg := &config.Config{Worker: config.WorkerConfig{MaxRetries: 3, StaleJobTimeout: 10 * time.Minute}}
+	worker := NewWorker(jobs, blueprints, &StubAIProvider{}, cfg)
+	worker.now = func() time.Time { return now }
+
+	worker.recoverStaleJobs(context.Background())
+
+	if interrupted.Status != models.JobStatusQueued || interrupted.RetryCount != 2 || interrupted.StartedAt != nil || interrupted.Progress != 0 {
+		t.Errorf("interrupted job = %s after %d retries, started %v; want requeued with another retry", interrupted.Status, interrupted.RetryCount, interrupted.StartedAt)
+	}
+	if running.Status != models.JobStatusProcessing || running.RetryCount != 0 {
+		t.Errorf("job within the timeout = %s after %d retries, want left processing", running.Status, running.RetryCount)
+	}
+	if exhausted.Status != models.JobStatusFailed || exhausted.ErrorMessage == nil || !strings.Contains(*exhausted.ErrorMessage, "10m0s") {
+		t.Errorf("exhausted job = %s (%v), want failed with an explanation", exhausted.Status, exhausted.ErrorMessage)
+	}
+	if status := blueprints.blueprints[blueprintID].AnalysisStatus; status != models.AnalysisStatusFailed {
+		t.Errorf("blueprint analysis status = %s, want failed with its job", status)
+	}
+
+	// Once the clock passes the timeout the other job is recovered too
+	now = now.Add(2 * time.Minute)
+	worker.recoverStaleJobs(context.Background())
+	if running.Status != models.JobStatusQueued || running.RetryCount != 1 {
+		t.Errorf("job past the timeout = %s after %d retries, want requeued", running.Status, running.RetryCount)
+	}
+}
+
+func TestWorker_StaleRecoveryDisabled(t *testing.T) {
+	started := models.NewTimestamp(time.Now().Add(-24 * time.Hour))
+	job := &models.Job{ID: uuid.New(), JobType: models.JobTypeTakeoff, Status: models.JobStatusProcessing, StartedAt: &started}
+	worker := NewWorker(&fakeWorkerJobs{jobs: []*models.Job{job}}, &fakeWorkerBlueprints{}, &StubAIProvider{}, &config.Config{})
+
+	worker.recoverStaleJobs(context.Background())
+
+	if job.Status != models.JobStatusProcessing {
+		t.Errorf("job = %s, want left alone without a stale job timeout", job.Status)
+	}
+}
@@ -0,0 +1,183 @@
+// Package trades is the registry of canonical trade names. Trades arrive as
+// free text from the AI service, company overrides and cost data; Normalize
+// maps each spelling to one slug so costs, labor rates and exports group on
+// the same key.
+package trades
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// General is the trade unknown and unspecified trades are priced under
+const General = "general"
+
+// Trade is a canonical trade and the spellings that refer to it
+type Trade struct {
+	Slug        string
+	DisplayName string
+	Aliases     []string
+}
+
+// registry seeds the known trades. Slugs match the labor_rates trade column.
+var registry = []Trade{
+	{Slug: General, DisplayName: "General", Aliases: []string{"general labor", "general conditions", "general contractor", "labor", "misc", "miscellaneous", "other"}},
+	{Slug: "carpentry", DisplayName: "Carpentry", Aliases: []string{"carpenter", "finish carpentry", "rough carpentry", "millwork", "trim", "doors", "doors and windows"}},
+	{Slug: "framing", DisplayName: "Framing", Aliases: []string{"framer", "rough framing", "wood framing", "metal framing", "metal studs"}},
+	{Slug: "drywall", DisplayName: "Drywall", Aliases: []string{"gypsum", "gypsum board", "gyp board", "sheetrock", "wallboard", "framing/drywall", "framing and drywall"}},
+	{Slug: "painting", DisplayName: "Painting", Aliases: []string{"paint", "painter", "coatings"}},
+	{Slug: "electrical", DisplayName: "Electrical", Aliases: []string{"electric", "electrician", "lighting"}},
+	{Slug: "plumbing", DisplayName: "Plumbing", Aliases: []string{"plumber", "plumbing fixtures"}},
+	{Slug: "hvac", DisplayName: "HVAC", Aliases: []string{"mechanical", "heating", "air conditioning", "heating and cooling", "ductwork"}},
+	{Slug: "flooring", DisplayName: "Flooring", Aliases: []string{"floor", "floor finishes", "tile", "carpet"}},
+	{Slug: "roofing", DisplayName: "Roofing", Aliases: []string{"roof", "roofer"}},
+	{Slug: "concrete", DisplayName: "Concrete", Aliases: []string{"cement", "foundation", "flatwork"}},
+	{Slug: "masonry", DisplayName: "Masonry", Aliases: []string{"mason", "brick", "block", "stone"}},
+	{Slug: "insulation", DisplayName: "Insulation", Aliases: []string{"insulate"}},
+	{Slug: "demolition", DisplayName: "Demolition", Aliases: []string{"demo"}},
+}
+
+var (
+	bySlug = make(map[string]Trade, len(registry))
+	// byKey maps the compact form of every slug, display name and alias
+	byKey = make(map[string]string)
+	// keys are the byKey keys, sorted so fuzzy ties resolve the same way
+	keys []string
+)
+
+func init() {
+	for _, trade := range registry {
+		bySlug[trade.Slug] = trade
+		for _, name := range append([]string{trade.Slug, trade.DisplayName}, trade.Aliases...) {
+			byKey[compact(name)] = trade.Slug
+		}
+	}
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+}
+
+// Normalize maps a raw trade name to its canonical slug. Matching ignores
+// case, spacing and punctuation, accepts aliases and plurals, then tries each
+// part of a compound name ("framing/electrical") and close misspellings.
+// Blank names are General. Unknown names return General and false.
+func Normalize(raw string) (string, bool) {
+	key := compact(raw)
+	if key == "" {
+		return General, true
+	}
+	if slug, ok := lookup(key); ok {
+		return slug, true
+	}
+
+	// Compound names resolve to their first recognized part
+	if parts := splitCompound(raw); len(parts) > 1 {
+		for _, part := range parts {
+			if slug, ok := lookup(compact(part)); ok {
+				return slug, true
+			}
+		}
+	}
+
+	if slug, ok := fuzzy(key); ok {
+		return slug, true
+	}
+	return General, false
+}
+
+// DisplayName returns the display name for a slug, or the slug itself when it
+// is not registered
+func DisplayName(slug string) string {
+	if trade, ok := bySlug[slug]; ok {
+		return trade.DisplayName
+	}
+	return slug
+}
+
+// All returns the registered trades
+func All() []Trade {
+	return append([]Trade(nil), registry...)
+}
+
+func lookup(key string) (string, bool) {
+	if slug, ok := byKey[key]; ok {
+		return slug, true
+	}
+	if singular, ok := strings.CutSuffix(key, "s"); ok && singular != "" {
+		if slug, ok := byKey[singular]; ok {
+			return slug, true
+		}
+	}
+	return "", false
+}
+
+// fuzzy accepts a key within a small edit distance of a known key. Short keys
+// must match exactly, since one edit changes them too much.
+func fuzzy(key string) (string, bool) {
+	allowed := 0
+	switch n := len([]rune(key)); {
+	case n >= 8:
+		allowed = 2
+	case n >= 5:
+		allowed = 1
+	}
+	if allowed == 0 {
+		return "", false
+	}
+
+	best, bestDistance := "", allowed+1
+	for _, candidate := range keys {
+		if distance := EditDistance(key, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	return byKey[best], true
+}
+
+// compact lowercases a name and drops everything but letters and digits, so
+// "Dry Wall", "dry-wall" and "DRYWALL" share a key. "&" reads as "and".
+func compact(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "&", "and")
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func splitCompound(name string) []string {
+	name = strings.ReplaceAll(strings.ToLower(name), " and ", "/")
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return strings.ContainsRune("/,;&+", r)
+	})
+}
+
+// EditDistance is the Levenshtein distance between two strings, used to
+// suggest the closest known name for a misspelled one
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,73 @@
+package trades
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		want   string
+		wantOK bool
+	}{
+		{"slug", "drywall", "drywall", true},
+		{"case", "Drywall", "drywall", true},
+		{"spacing", "Dry Wall", "drywall", true},
+		{"punctuation", "dry-wall", "drywall", true},
+		{"display name", "HVAC", "hvac", true},
+		{"alias", "Sheetrock", "drywall", true},
+		{"alias with ampersand", "Heating & Cooling", "hvac", true},
+		{"plural", "Electricians", "electrical", true},
+		{"listed compound", "framing/drywall", "drywall", true},
+		{"compound first known part", "Electrical / Low Voltage", "electrical", true},
+		{"misspelling", "Elecrtical", "electrical", true},
+		{"long misspelling", "carpentery", "carpentry", true},
+		{"blank", "  ", General, true},
+		{"unknown", "Underwater welding", General, false},
+		{"short unknown is not fuzzy matched", "demi", General, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Normalize(tt.raw)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Normalize(%q) = %q, %v; want %q, %v", tt.raw, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRegistryAliasesResolveToTheirTrade(t *testing.T) {
+	for _, trade := range All() {
+		for _, name := range append([]string{trade.Slug, trade.DisplayName}, trade.Aliases...) {
+			if got, ok := Normalize(name); !ok || got != trade.Slug {
+				t.Errorf("Normalize(%q) = %q, %v; want %q", name, got, ok, trade.Slug)
+			}
+		}
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	if got := DisplayName("hvac"); got != "HVAC" {
+		t.Errorf("DisplayName(hvac) = %q, want HVAC", got)
+	}
+	if got := DisplayName("tile_setting"); got != "tile_setting" {
+		t.Errorf("DisplayName() = %q, want unregistered slugs unchanged", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"flooring_lvp", "flooring_lvp", 0},
+		{"flooring_lvp", "flooring_vlp", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := EditDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
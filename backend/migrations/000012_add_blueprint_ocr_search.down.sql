@@ -0,0 +1,4 @@
+-- Remove OCR search support from blueprints table
+DROP INDEX IF EXISTS idx_blueprints_ocr_tsv;
+ALTER TABLE blueprints DROP COLUMN IF EXISTS ocr_tsv;
+ALTER TABLE blueprints DROP COLUMN IF EXISTS raw_ocr_text;
@@ -0,0 +1,8 @@
+-- Store raw OCR text from analyses in a dedicated column so it can be searched
+-- without digging through analysis_data JSONB
+ALTER TABLE blueprints
+ADD COLUMN IF NOT EXISTS raw_ocr_text TEXT,
+ADD COLUMN IF NOT EXISTS ocr_tsv tsvector GENERATED ALWAYS AS (to_tsvector('english', COALESCE(raw_ocr_text, ''))) STORED;
+
+-- Full-text index for OCR search
+CREATE INDEX IF NOT EXISTS idx_blueprints_ocr_tsv ON blueprints USING GIN(ocr_tsv);
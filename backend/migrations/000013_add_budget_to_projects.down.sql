@@ -0,0 +1,2 @@
+-- Remove budget from projects table
+ALTER TABLE projects DROP COLUMN IF EXISTS budget;
@@ -0,0 +1,2 @@
+-- Add optional budget to projects for over-budget bid warnings
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS budget DECIMAL(15, 2);
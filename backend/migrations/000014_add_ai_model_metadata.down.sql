@@ -0,0 +1,7 @@
+-- Remove AI model metadata columns
+DROP INDEX IF EXISTS idx_bids_generation_model_version;
+DROP INDEX IF EXISTS idx_blueprints_analysis_model_version;
+ALTER TABLE bid_revisions DROP COLUMN IF EXISTS generation_model;
+ALTER TABLE bids DROP COLUMN IF EXISTS generation_model;
+ALTER TABLE blueprint_revisions DROP COLUMN IF EXISTS analysis_model;
+ALTER TABLE blueprints DROP COLUMN IF EXISTS analysis_model;
@@ -0,0 +1,9 @@
+-- Record which AI model/prompt produced each analysis and bid
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS analysis_model JSONB;
+ALTER TABLE blueprint_revisions ADD COLUMN IF NOT EXISTS analysis_model JSONB;
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS generation_model JSONB;
+ALTER TABLE bid_revisions ADD COLUMN IF NOT EXISTS generation_model JSONB;
+
+-- Support segmenting results by model version
+CREATE INDEX IF NOT EXISTS idx_blueprints_analysis_model_version ON blueprints ((analysis_model->>'version'));
+CREATE INDEX IF NOT EXISTS idx_bids_generation_model_version ON bids ((generation_model->>'version'));
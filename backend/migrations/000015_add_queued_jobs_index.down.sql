@@ -0,0 +1,2 @@
+-- Remove queue depth index
+DROP INDEX IF EXISTS idx_jobs_queued_blueprint_id;
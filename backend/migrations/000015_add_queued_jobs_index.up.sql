@@ -0,0 +1,2 @@
+-- Partial index backing queue depth checks at job creation time
+CREATE INDEX IF NOT EXISTS idx_jobs_queued_blueprint_id ON jobs(blueprint_id) WHERE status = 'queued';
@@ -0,0 +1,2 @@
+-- Remove discipline classification from blueprints
+ALTER TABLE blueprints DROP COLUMN IF EXISTS sheet_type;
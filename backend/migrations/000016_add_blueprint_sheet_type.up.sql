@@ -0,0 +1,3 @@
+-- Add discipline classification to blueprints
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS sheet_type VARCHAR(50)
+    CHECK (sheet_type IN ('architectural', 'electrical', 'plumbing', 'mechanical', 'unknown'));
@@ -0,0 +1,2 @@
+-- Irreversible: dropped change lists can be recomputed via the compare endpoints
+SELECT 1;
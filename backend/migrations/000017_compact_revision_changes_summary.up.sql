@@ -0,0 +1,9 @@
+-- Revisions now store a compact comparison digest; drop the full change list
+-- from rows written before that
+UPDATE blueprint_revisions
+SET changes_summary = changes_summary - 'changes'
+WHERE changes_summary ? 'changes';
+
+UPDATE bid_revisions
+SET changes_summary = changes_summary - 'changes'
+WHERE changes_summary ? 'changes';
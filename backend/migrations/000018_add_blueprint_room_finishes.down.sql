@@ -0,0 +1,5 @@
+-- Remove finish-specific flooring materials and room finish selections
+DELETE FROM materials
+WHERE category IN ('flooring_tile', 'flooring_lvp', 'flooring_carpet', 'flooring_hardwood')
+  AND source = 'custom';
+ALTER TABLE blueprints DROP COLUMN IF EXISTS room_finishes;
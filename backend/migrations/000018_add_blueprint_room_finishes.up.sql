@@ -0,0 +1,15 @@
+-- Per-room floor finish selections (room name -> finish key)
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS room_finishes JSONB;
+
+-- Finish-specific flooring materials
+INSERT INTO materials (name, description, category, unit, base_price, source, region)
+SELECT v.name, v.description, v.category, 'sq ft', v.base_price, 'custom', 'national'
+FROM (VALUES
+    ('Porcelain Tile', 'Porcelain floor tile', 'flooring_tile', 12.00),
+    ('Luxury Vinyl Plank', 'Luxury vinyl plank flooring', 'flooring_lvp', 6.50),
+    ('Carpet', 'Mid-grade carpet with pad', 'flooring_carpet', 4.50),
+    ('Hardwood Flooring', 'Engineered hardwood flooring', 'flooring_hardwood', 11.00)
+) AS v(name, description, category, base_price)
+WHERE NOT EXISTS (
+    SELECT 1 FROM materials m WHERE m.category = v.category AND m.region = 'national'
+);
@@ -0,0 +1,3 @@
+-- Remove standing bid inclusions/exclusions
+ALTER TABLE users DROP COLUMN IF EXISTS default_exclusions;
+ALTER TABLE users DROP COLUMN IF EXISTS default_inclusions;
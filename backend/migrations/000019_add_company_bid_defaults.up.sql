@@ -0,0 +1,3 @@
+-- Standing inclusions/exclusions merged into every generated bid
+ALTER TABLE users ADD COLUMN IF NOT EXISTS default_inclusions TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS default_exclusions TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- Remove recorded virus scan outcomes
+ALTER TABLE blueprints DROP COLUMN IF EXISTS scan_result;
@@ -0,0 +1,2 @@
+-- Virus scan outcome recorded when an upload is completed
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS scan_result TEXT;
@@ -0,0 +1,3 @@
+-- Roles gate admin-only endpoints; promote admins manually with
+-- UPDATE users SET role = 'admin' WHERE email = '...'
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
@@ -0,0 +1,4 @@
+-- Remove bid PDF hashing and scheduled object deletions
+DROP INDEX IF EXISTS idx_object_deletions_delete_after;
+DROP TABLE IF EXISTS object_deletions;
+ALTER TABLE bids DROP COLUMN IF EXISTS pdf_hash;
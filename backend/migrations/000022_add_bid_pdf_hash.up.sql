@@ -0,0 +1,13 @@
+-- Bid PDFs are keyed on a hash of their rendered inputs so unchanged bids reuse
+-- the stored object
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS pdf_hash VARCHAR(64);
+
+-- Superseded S3 objects awaiting deletion by the worker
+CREATE TABLE IF NOT EXISTS object_deletions (
+    id UUID PRIMARY KEY,
+    s3_key TEXT NOT NULL,
+    delete_after TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_object_deletions_delete_after ON object_deletions(delete_after);
@@ -0,0 +1,5 @@
+-- Remove user suspension
+DROP INDEX IF EXISTS idx_users_created_at;
+ALTER TABLE users
+DROP COLUMN IF EXISTS suspended_at,
+DROP COLUMN IF EXISTS suspended;
@@ -0,0 +1,6 @@
+-- Suspended users are rejected by the auth middleware on every request
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS suspended BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at DESC);
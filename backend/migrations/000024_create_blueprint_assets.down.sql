@@ -0,0 +1,2 @@
+-- Drop blueprint asset tracking
+DROP TABLE IF EXISTS blueprint_assets;
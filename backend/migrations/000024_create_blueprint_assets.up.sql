@@ -0,0 +1,28 @@
+-- Every S3 object stored for a blueprint: the original upload, converted
+-- images, archived revision copies and thumbnails
+CREATE TABLE IF NOT EXISTS blueprint_assets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    blueprint_id UUID NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    s3_key VARCHAR(500) NOT NULL,
+    size_bytes BIGINT,
+    checksum VARCHAR(128),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_blueprint_assets_blueprint FOREIGN KEY (blueprint_id) REFERENCES blueprints(id) ON DELETE CASCADE,
+    CONSTRAINT uq_blueprint_assets_key UNIQUE (blueprint_id, s3_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blueprint_assets_blueprint_id ON blueprint_assets(blueprint_id);
+
+-- Backfill uploaded originals, then revision keys that differ from them
+INSERT INTO blueprint_assets (blueprint_id, kind, s3_key, size_bytes, created_at)
+SELECT id, 'original', s3_key, file_size, created_at
+FROM blueprints
+WHERE upload_status = 'uploaded'
+ON CONFLICT (blueprint_id, s3_key) DO NOTHING;
+
+INSERT INTO blueprint_assets (blueprint_id, kind, s3_key, size_bytes, created_at)
+SELECT DISTINCT ON (blueprint_id, s3_key) blueprint_id, 'revision', s3_key, file_size, created_at
+FROM blueprint_revisions
+ORDER BY blueprint_id, s3_key, created_at
+ON CONFLICT (blueprint_id, s3_key) DO NOTHING;
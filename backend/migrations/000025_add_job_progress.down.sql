@@ -0,0 +1,5 @@
+-- Remove job progress
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS chk_jobs_progress;
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS progress_message,
+DROP COLUMN IF EXISTS progress;
@@ -0,0 +1,7 @@
+-- Progress percentage and phase reported by the worker while a job runs
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS progress INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS progress_message TEXT;
+
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS chk_jobs_progress;
+ALTER TABLE jobs ADD CONSTRAINT chk_jobs_progress CHECK (progress BETWEEN 0 AND 100);
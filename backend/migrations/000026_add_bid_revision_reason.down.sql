@@ -0,0 +1,2 @@
+-- Remove bid revision reason
+ALTER TABLE bid_revisions DROP COLUMN IF EXISTS reason;
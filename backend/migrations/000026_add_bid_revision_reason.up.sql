@@ -0,0 +1,2 @@
+-- Why a bid revision was made, e.g. "reprice" or "rename"
+ALTER TABLE bid_revisions ADD COLUMN IF NOT EXISTS reason TEXT;
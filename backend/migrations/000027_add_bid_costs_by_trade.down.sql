@@ -0,0 +1,3 @@
+-- Remove denormalized bid trade costs
+DROP INDEX IF EXISTS idx_bids_created_at;
+ALTER TABLE bids DROP COLUMN IF EXISTS costs_by_trade;
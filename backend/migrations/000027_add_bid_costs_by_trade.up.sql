@@ -0,0 +1,5 @@
+-- Per-trade line item totals, denormalized from bid_data for company analytics.
+-- Existing bids are filled in by cmd/backfill-trade-costs.
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS costs_by_trade JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_bids_created_at ON bids(created_at);
@@ -0,0 +1,3 @@
+-- Remove bid drafts
+DROP INDEX IF EXISTS idx_bid_drafts_expires_at;
+DROP TABLE IF EXISTS bid_drafts;
@@ -0,0 +1,15 @@
+-- Uncommitted bid edits, one per user and bid, removed by the worker once expired
+CREATE TABLE IF NOT EXISTS bid_drafts (
+    id UUID PRIMARY KEY,
+    bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    base_version INTEGER NOT NULL,
+    bid_data JSONB NOT NULL,
+    markup_percentage DECIMAL(5, 2),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    UNIQUE (bid_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bid_drafts_expires_at ON bid_drafts(expires_at);
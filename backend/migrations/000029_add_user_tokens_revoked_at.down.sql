@@ -0,0 +1,2 @@
+-- Remove user token revocation
+ALTER TABLE users DROP COLUMN IF EXISTS tokens_revoked_at;
@@ -0,0 +1,2 @@
+-- Tokens issued before this time are rejected, e.g. after a password change
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_revoked_at TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Remove automatic blueprint revisions
+ALTER TABLE blueprint_revisions DROP COLUMN IF EXISTS reason;
+ALTER TABLE users DROP COLUMN IF EXISTS auto_blueprint_revisions;
@@ -0,0 +1,5 @@
+-- Snapshot a blueprint's analysis before it is overwritten, unless the owner opts out
+ALTER TABLE users ADD COLUMN IF NOT EXISTS auto_blueprint_revisions BOOLEAN NOT NULL DEFAULT true;
+
+-- Why a blueprint revision was made, e.g. "pre_analysis_overwrite"
+ALTER TABLE blueprint_revisions ADD COLUMN IF NOT EXISTS reason TEXT;
@@ -0,0 +1,4 @@
+-- Remove QuickBooks item mapping
+ALTER TABLE users
+DROP COLUMN IF EXISTS quickbooks_default_item,
+DROP COLUMN IF EXISTS quickbooks_items;
@@ -0,0 +1,4 @@
+-- QuickBooks item names bid line items export under, keyed by trade
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS quickbooks_items JSONB,
+ADD COLUMN IF NOT EXISTS quickbooks_default_item TEXT;
@@ -0,0 +1,2 @@
+-- Remove the default unit system
+ALTER TABLE users DROP COLUMN IF EXISTS unit_system;
@@ -0,0 +1,2 @@
+-- Default measurement system for a company's takeoff and pricing output
+ALTER TABLE users ADD COLUMN IF NOT EXISTS unit_system TEXT NOT NULL DEFAULT 'imperial';
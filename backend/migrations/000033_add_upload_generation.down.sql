@@ -0,0 +1,3 @@
+-- Remove upload generation tracking
+ALTER TABLE jobs DROP COLUMN IF EXISTS upload_generation;
+ALTER TABLE blueprints DROP COLUMN IF EXISTS upload_generation;
@@ -0,0 +1,6 @@
+-- Counts completed uploads of a blueprint's file so analysis jobs for a
+-- replaced file can be recognised when they finish
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS upload_generation INTEGER NOT NULL DEFAULT 0;
+
+-- The blueprint upload generation a job was created against
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS upload_generation INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Remove project duplication columns
+ALTER TABLE jobs DROP COLUMN IF EXISTS target_project_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS client_name;
+ALTER TABLE projects DROP COLUMN IF EXISTS region;
@@ -0,0 +1,8 @@
+-- Location and client of a project, changed when a prototype project is
+-- duplicated for a new site
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS region VARCHAR(100);
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS client_name VARCHAR(255);
+
+-- The project a duplication job copies blueprints into. Duplication jobs
+-- reference the source project's first blueprint as their blueprint_id.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS target_project_id UUID REFERENCES projects(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- Remove re-analysis flag from jobs
+ALTER TABLE jobs DROP COLUMN IF EXISTS reanalyze;
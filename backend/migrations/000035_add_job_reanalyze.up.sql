@@ -0,0 +1,2 @@
+-- Re-analysis jobs send the blueprint's previous analysis to the AI as context
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS reanalyze BOOLEAN NOT NULL DEFAULT false;
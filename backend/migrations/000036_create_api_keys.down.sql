@@ -0,0 +1,3 @@
+-- Remove API keys
+DROP INDEX IF EXISTS idx_api_keys_user_id;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,2 @@
+-- Remove project type from projects
+ALTER TABLE projects DROP COLUMN IF EXISTS project_type;
@@ -0,0 +1,3 @@
+-- Renovations and additions are priced with demolition, protection and a
+-- labor penalty that new construction does not carry
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS project_type VARCHAR(32) NOT NULL DEFAULT 'new_construction';
@@ -0,0 +1,2 @@
+-- Remove storage class from blueprint assets
+ALTER TABLE blueprint_assets DROP COLUMN IF EXISTS storage_class;
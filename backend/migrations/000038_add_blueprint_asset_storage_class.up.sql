@@ -0,0 +1,3 @@
+-- The S3 storage class an asset was stored with; NULL for assets recorded
+-- before it was tracked
+ALTER TABLE blueprint_assets ADD COLUMN IF NOT EXISTS storage_class VARCHAR(32);
@@ -0,0 +1,4 @@
+-- Remove blueprint linkage from bids and bid revisions
+DROP INDEX IF EXISTS idx_bids_blueprint_ids;
+ALTER TABLE bid_revisions DROP COLUMN IF EXISTS blueprint_ids;
+ALTER TABLE bids DROP COLUMN IF EXISTS blueprint_ids;
@@ -0,0 +1,12 @@
+-- The blueprints whose takeoffs priced a bid. Existing bids were priced from
+-- the single blueprint recorded in their bid data.
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS blueprint_ids UUID[] NOT NULL DEFAULT '{}';
+ALTER TABLE bid_revisions ADD COLUMN IF NOT EXISTS blueprint_ids UUID[] NOT NULL DEFAULT '{}';
+
+UPDATE bids SET blueprint_ids = ARRAY[(bid_data->>'blueprint_id')::UUID]
+WHERE bid_data->>'blueprint_id' ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+
+UPDATE bid_revisions SET blueprint_ids = ARRAY[(bid_data->>'blueprint_id')::UUID]
+WHERE bid_data->>'blueprint_id' ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+
+CREATE INDEX IF NOT EXISTS idx_bids_blueprint_ids ON bids USING GIN (blueprint_ids);
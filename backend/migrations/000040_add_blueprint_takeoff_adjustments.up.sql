@@ -0,0 +1,3 @@
+-- Estimator adjustments applied on top of the AI takeoff; the analysis
+-- itself is never changed
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS takeoff_adjustments JSONB;
@@ -0,0 +1,2 @@
+ALTER TABLE projects DROP COLUMN IF EXISTS tax_exempt;
+ALTER TABLE users DROP COLUMN IF EXISTS tax_settings;
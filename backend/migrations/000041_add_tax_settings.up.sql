@@ -0,0 +1,5 @@
+-- Company sales tax rules, applied to pricing summaries and bids
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tax_settings JSONB;
+
+-- Tax-exempt projects are priced without sales tax
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
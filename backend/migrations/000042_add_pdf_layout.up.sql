@@ -0,0 +1,2 @@
+-- Company bid document layout: section order, enabled flags and titles
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pdf_layout JSONB;
@@ -0,0 +1,4 @@
+ALTER TABLE blueprints DROP COLUMN IF EXISTS document_created_at;
+ALTER TABLE blueprints DROP COLUMN IF EXISTS document_title;
+ALTER TABLE blueprints DROP COLUMN IF EXISTS page_sizes;
+ALTER TABLE blueprints DROP COLUMN IF EXISTS page_count;
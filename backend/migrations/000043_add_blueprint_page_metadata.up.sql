@@ -0,0 +1,6 @@
+-- Page structure read from the uploaded file when its upload completes;
+-- page_count stays NULL when the file could not be parsed
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS page_count INTEGER;
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS page_sizes JSONB;
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS document_title TEXT;
+ALTER TABLE blueprints ADD COLUMN IF NOT EXISTS document_created_at TIMESTAMPTZ;
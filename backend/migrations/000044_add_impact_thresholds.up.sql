@@ -0,0 +1,2 @@
+-- Company thresholds for labelling revision comparison changes
+ALTER TABLE users ADD COLUMN IF NOT EXISTS impact_thresholds JSONB;
@@ -0,0 +1,2 @@
+ALTER TABLE bids DROP COLUMN IF EXISTS status_note;
+ALTER TABLE bids DROP COLUMN IF EXISTS status_changed_at;
@@ -0,0 +1,3 @@
+-- When a bid's status last changed, and the note given with the change
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP;
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS status_note TEXT;
@@ -0,0 +1,2 @@
+-- Remove company profiles
+DROP TABLE IF EXISTS company_profiles;
@@ -0,0 +1,14 @@
+-- A company's name, contact details and logo, printed on its bid documents
+CREATE TABLE IF NOT EXISTS company_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    address TEXT,
+    phone TEXT,
+    email TEXT,
+    website TEXT,
+    license_number TEXT,
+    insurance_info TEXT,
+    logo_s3_key TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
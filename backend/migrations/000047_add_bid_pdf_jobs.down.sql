@@ -0,0 +1,4 @@
+-- Remove bid PDF job columns
+DROP INDEX IF EXISTS idx_jobs_bid_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS pdf_request;
+ALTER TABLE jobs DROP COLUMN IF EXISTS bid_id;
@@ -0,0 +1,7 @@
+-- The bid a PDF generation job renders, and the PDF options it was
+-- requested with. PDF jobs reference the bid's first blueprint as their
+-- blueprint_id.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS bid_id UUID REFERENCES bids(id) ON DELETE CASCADE;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS pdf_request JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_bid_id ON jobs(bid_id) WHERE bid_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Remove bid shares
+DROP INDEX IF EXISTS idx_bid_shares_bid_id;
+DROP TABLE IF EXISTS bid_shares;
@@ -0,0 +1,16 @@
+-- Bid shares are links that let a client without an account view a bid and
+-- accept or reject it once. Only a hash of each link's token is stored.
+CREATE TABLE IF NOT EXISTS bid_shares (
+    id UUID PRIMARY KEY,
+    bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    response VARCHAR(50),
+    response_comment TEXT,
+    responder_name VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bid_shares_bid_id ON bid_shares(bid_id);
@@ -0,0 +1,3 @@
+-- Allow duplicate cost data keys again
+DROP INDEX IF EXISTS idx_labor_rates_trade_region;
+DROP INDEX IF EXISTS idx_materials_name_region;
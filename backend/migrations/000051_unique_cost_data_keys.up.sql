@@ -0,0 +1,17 @@
+-- Cost data syncs upsert materials by name and region and labor rates by
+-- trade and region, so each key may only be stored once. Duplicates left by
+-- earlier syncs are removed, keeping the most recently updated row.
+DELETE FROM materials m
+USING materials newer
+WHERE m.name = newer.name
+  AND m.region IS NOT DISTINCT FROM newer.region
+  AND (m.updated_at, m.id) < (newer.updated_at, newer.id);
+
+DELETE FROM labor_rates lr
+USING labor_rates newer
+WHERE lr.trade = newer.trade
+  AND lr.region IS NOT DISTINCT FROM newer.region
+  AND (lr.updated_at, lr.id) < (newer.updated_at, newer.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_materials_name_region ON materials(name, region) NULLS NOT DISTINCT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labor_rates_trade_region ON labor_rates(trade, region) NULLS NOT DISTINCT;
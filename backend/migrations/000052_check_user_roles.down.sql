@@ -0,0 +1,2 @@
+-- Allow any user role again
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_role;
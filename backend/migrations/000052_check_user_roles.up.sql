@@ -0,0 +1,3 @@
+-- Admins manage roles through PATCH /api/admin/users/{id}/role, which only
+-- accepts the known roles; the constraint holds manual updates to the same
+ALTER TABLE users ADD CONSTRAINT chk_users_role CHECK (role IN ('user', 'admin'));
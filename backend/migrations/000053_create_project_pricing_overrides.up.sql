@@ -0,0 +1,15 @@
+-- Project pricing overrides are company pricing overrides for one project,
+-- e.g. union labor rates negotiated for a single job. They apply on top of
+-- the owner's company overrides.
+CREATE TABLE IF NOT EXISTS project_pricing_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    override_type VARCHAR(50) NOT NULL,
+    item_key VARCHAR(255) NOT NULL,
+    override_value DECIMAL(10, 2) NOT NULL,
+    is_percentage BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, override_type, item_key)
+);
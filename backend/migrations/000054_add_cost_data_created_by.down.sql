@@ -0,0 +1,3 @@
+-- Remove cost data authorship
+ALTER TABLE labor_rates DROP COLUMN IF EXISTS created_by;
+ALTER TABLE materials DROP COLUMN IF EXISTS created_by;
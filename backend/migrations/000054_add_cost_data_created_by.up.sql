@@ -0,0 +1,4 @@
+-- The admin who added a material or labor rate by hand. Rows stored by
+-- provider syncs have none.
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE labor_rates ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+-- Remove the budget acknowledgement threshold from company profiles
+ALTER TABLE company_profiles DROP COLUMN IF EXISTS budget_ack_threshold_percent;
@@ -0,0 +1,2 @@
+-- Let a company set how far over budget a bid may be before generation must be acknowledged
+ALTER TABLE company_profiles ADD COLUMN IF NOT EXISTS budget_ack_threshold_percent DECIMAL(6, 2);
@@ -0,0 +1,4 @@
+-- Remove bid acceptances and share link revocation
+DROP INDEX IF EXISTS idx_bid_acceptances_bid_id;
+DROP TABLE IF EXISTS bid_acceptances;
+ALTER TABLE bid_shares DROP COLUMN IF EXISTS revoked_at;
@@ -0,0 +1,17 @@
+-- Share links can be revoked before the client answers
+ALTER TABLE bid_shares ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP;
+
+-- A client's signed online acceptance of a shared bid: the name and email
+-- they typed and where and when they accepted from. Each link accepts once.
+CREATE TABLE IF NOT EXISTS bid_acceptances (
+    id UUID PRIMARY KEY,
+    bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+    share_id UUID NOT NULL UNIQUE REFERENCES bid_shares(id) ON DELETE CASCADE,
+    signer_name VARCHAR(255) NOT NULL,
+    signer_email VARCHAR(255) NOT NULL,
+    ip_address TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    accepted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_bid_acceptances_bid_id ON bid_acceptances(bid_id);
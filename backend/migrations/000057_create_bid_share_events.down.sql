@@ -0,0 +1,5 @@
+-- Remove bid share engagement tracking
+ALTER TABLE bid_shares DROP COLUMN IF EXISTS first_viewed_at;
+DROP INDEX IF EXISTS idx_bid_share_events_bid_id;
+DROP INDEX IF EXISTS idx_bid_share_events_share_id;
+DROP TABLE IF EXISTS bid_share_events;
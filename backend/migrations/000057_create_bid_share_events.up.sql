@@ -0,0 +1,17 @@
+-- What clients do with a shared bid: views of the link, sections they read
+-- and PDF downloads. Viewers are identified by a hash of their IP address.
+CREATE TABLE IF NOT EXISTS bid_share_events (
+    id UUID PRIMARY KEY,
+    share_id UUID NOT NULL REFERENCES bid_shares(id) ON DELETE CASCADE,
+    bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    ip_hash VARCHAR(64) NOT NULL,
+    user_agent TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_bid_share_events_share_id ON bid_share_events(share_id);
+CREATE INDEX IF NOT EXISTS idx_bid_share_events_bid_id ON bid_share_events(bid_id);
+
+-- Set by the first view, so the owner is notified of it once
+ALTER TABLE bid_shares ADD COLUMN IF NOT EXISTS first_viewed_at TIMESTAMP;